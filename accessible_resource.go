@@ -0,0 +1,39 @@
+package influxdb
+
+import (
+	"context"
+)
+
+// Ways a user can access a resource.
+const (
+	// AccessViaMapping is the access given by a user resource mapping of the
+	// resource.
+	AccessViaMapping = "mapping"
+	// AccessViaOrg is the access given by the membership of the organization
+	// of the resource.
+	AccessViaOrg = "org"
+	// AccessViaToken is the access given by the permissions of the token of
+	// the request.
+	AccessViaToken = "token"
+)
+
+// AccessibleResource is a resource a user can access.
+type AccessibleResource struct {
+	ID    ID     `json:"id"`
+	OrgID ID     `json:"orgID,omitempty"`
+	Name  string `json:"name"`
+	// Access is write if the token of the user can write the resource,
+	// read otherwise.
+	Access Action `json:"access"`
+	// Via are the ways the user can access the resource.
+	Via []string `json:"via"`
+}
+
+// AccessibleResourceService finds the resources users can access.
+type AccessibleResourceService interface {
+	// FindAccessibleResources returns the resources the permissions ps of
+	// the token of the user allow it to access, grouped by type. The
+	// mappings and organizations of the user are reported as ways it
+	// accesses them, but do not give access beyond ps.
+	FindAccessibleResources(ctx context.Context, userID ID, ps PermissionSet) (map[ResourceType][]*AccessibleResource, error)
+}
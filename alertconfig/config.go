@@ -0,0 +1,101 @@
+package alertconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+	"github.com/influxdata/influxdb/v2"
+)
+
+// Config is the declarative alert configuration that influxd reconciles at
+// startup, read from the YAML or JSON file of its alerts-config flag, so
+// that the alerting of immutable deployments does not need API calls once
+// they are up.
+type Config struct {
+	// Prune deletes the checks, notification rules and endpoints of the
+	// organizations of the configuration that it does not list.
+	Prune         bool        `json:"prune"`
+	Organizations []OrgConfig `json:"organizations"`
+}
+
+// OrgConfig is the alert configuration of the organization named Org. The
+// resources are written as in the API; the rules reference their endpoint
+// with endpointName.
+type OrgConfig struct {
+	Org                   string            `json:"org"`
+	Checks                []json.RawMessage `json:"checks"`
+	NotificationRules     []json.RawMessage `json:"notificationRules"`
+	NotificationEndpoints []json.RawMessage `json:"notificationEndpoints"`
+}
+
+// LoadConfig reads the configuration of the file at path.
+func LoadConfig(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid alert configuration %s: %v", path, err)
+	}
+	for _, o := range cfg.Organizations {
+		if o.Org == "" {
+			return nil, fmt.Errorf("invalid alert configuration %s: an organization has no name", path)
+		}
+	}
+	return &cfg, nil
+}
+
+// ReconcileConfig reconciles the organizations of cfg and returns the
+// results of each organization by name. The resources of an organization
+// are created on behalf of its first owner. An organization that cannot be
+// found fails the reconciliation. The errors of the resources are reported
+// in their results, which are returned with ErrConfigNotApplied once every
+// organization is reconciled.
+func (s *Service) ReconcileConfig(ctx context.Context, cfg *Config, orgSvc influxdb.OrganizationService, urmSvc influxdb.UserResourceMappingService) (map[string][]*ImportResult, error) {
+	results := make(map[string][]*ImportResult, len(cfg.Organizations))
+	for _, o := range cfg.Organizations {
+		name := o.Org
+		org, err := orgSvc.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &name})
+		if err != nil {
+			return nil, err
+		}
+		owners, _, err := urmSvc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.OrgsResourceType,
+			ResourceID:   org.ID,
+			UserType:     influxdb.Owner,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(owners) == 0 {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "organization " + name + " has no owner to reconcile its alert configuration for",
+			}
+		}
+
+		doc := &Document{
+			Version:               DocumentVersion,
+			OrgID:                 org.ID,
+			Checks:                o.Checks,
+			NotificationRules:     o.NotificationRules,
+			NotificationEndpoints: o.NotificationEndpoints,
+		}
+		res, err := s.Reconcile(ctx, org.ID, owners[0].UserID, doc, cfg.Prune)
+		if err != nil {
+			return nil, err
+		}
+		results[name] = res
+	}
+
+	for _, res := range results {
+		if failed(res) {
+			return results, ErrConfigNotApplied
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,209 @@
+package alertconfig_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/alertconfig"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notification/check"
+)
+
+const alertsConfig = `
+prune: true
+organizations:
+  - org: ops
+    notificationEndpoints:
+      - type: slack
+        name: pager
+        url: https://hooks.slack.com/services/y
+        status: active
+      - type: slack
+        name: chat
+        url: https://hooks.slack.com/services/z
+        status: active
+    checks:
+      - type: threshold
+        name: cpu
+        query:
+          text: 'from(bucket: "telegraf") |> range(start: -1m)'
+        status: inactive
+    notificationRules:
+      - type: slack
+        name: page ops
+        endpointName: pager
+        channel: "#ops"
+`
+
+func TestService_ReconcileConfig(t *testing.T) {
+	f, err := ioutil.TempFile("", "alerts-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(alertsConfig); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := alertconfig.LoadConfig(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Prune || len(cfg.Organizations) != 1 {
+		t.Fatalf("unexpected configuration %+v", cfg)
+	}
+
+	var (
+		nextID  = influxdb.ID(0x1000)
+		actions []string
+		rule    influxdb.NotificationRule
+	)
+	checkSvc := mock.NewCheckService()
+	checkSvc.FindChecksFn = func(context.Context, influxdb.CheckFilter, ...influxdb.FindOptions) ([]influxdb.Check, int, error) {
+		return []influxdb.Check{
+			&check.Threshold{Base: check.Base{ID: 0x50, OrgID: targetOrgID, Name: "cpu"}},
+			&check.Threshold{Base: check.Base{ID: 0x51, OrgID: targetOrgID, Name: "stale"}},
+		}, 2, nil
+	}
+	checkSvc.UpdateCheckFn = func(_ context.Context, id influxdb.ID, c influxdb.CheckCreate) (influxdb.Check, error) {
+		if c.Status != influxdb.Inactive {
+			t.Errorf("unexpected status of check %s", c.Status)
+		}
+		actions = append(actions, "update check "+id.String())
+		return c.Check, nil
+	}
+	checkSvc.DeleteCheckFn = func(_ context.Context, id influxdb.ID) error {
+		actions = append(actions, "delete check "+id.String())
+		return nil
+	}
+	ruleSvc := mock.NewNotificationRuleStore()
+	ruleSvc.FindNotificationRulesF = func(context.Context, influxdb.NotificationRuleFilter, ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+		return nil, 0, nil
+	}
+	ruleSvc.CreateNotificationRuleF = func(_ context.Context, r influxdb.NotificationRuleCreate, id influxdb.ID) error {
+		actions = append(actions, "create rule for "+id.String())
+		rule = r.NotificationRule
+		return nil
+	}
+	endpointSvc := mock.NewNotificationEndpointService()
+	endpointSvc.FindNotificationEndpointsF = func(context.Context, influxdb.NotificationEndpointFilter, ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+		return []influxdb.NotificationEndpoint{
+			slackEndpoint(0x40, targetOrgID, "pager"),
+			slackEndpoint(0x41, targetOrgID, "old"),
+		}, 2, nil
+	}
+	endpointSvc.CreateNotificationEndpointF = func(_ context.Context, e influxdb.NotificationEndpoint, _ influxdb.ID) error {
+		nextID++
+		e.SetID(nextID)
+		actions = append(actions, "create endpoint "+e.GetName())
+		return nil
+	}
+	endpointSvc.UpdateNotificationEndpointF = func(_ context.Context, id influxdb.ID, e influxdb.NotificationEndpoint, _ influxdb.ID) (influxdb.NotificationEndpoint, error) {
+		if e.GetID() != id || e.GetOrgID() != targetOrgID {
+			t.Errorf("unexpected endpoint %s of org %s", e.GetID(), e.GetOrgID())
+		}
+		actions = append(actions, "update endpoint "+id.String())
+		return e, nil
+	}
+	endpointSvc.DeleteNotificationEndpointF = func(_ context.Context, id influxdb.ID) ([]influxdb.SecretField, influxdb.ID, error) {
+		actions = append(actions, "delete endpoint "+id.String())
+		return nil, targetOrgID, nil
+	}
+
+	orgSvc := mock.NewOrganizationService()
+	orgSvc.FindOrganizationF = func(_ context.Context, f influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		if *f.Name != "ops" {
+			t.Errorf("unexpected org %s", *f.Name)
+		}
+		return &influxdb.Organization{ID: targetOrgID, Name: *f.Name}, nil
+	}
+	urmSvc := mock.NewUserResourceMappingService()
+	urmSvc.FindMappingsFn = func(_ context.Context, f influxdb.UserResourceMappingFilter) ([]*influxdb.UserResourceMapping, int, error) {
+		if f.ResourceID != targetOrgID || f.UserType != influxdb.Owner {
+			t.Errorf("unexpected filter %+v", f)
+		}
+		return []*influxdb.UserResourceMapping{{UserID: userID, ResourceID: targetOrgID}}, 1, nil
+	}
+
+	svc := alertconfig.NewService(checkSvc, ruleSvc, endpointSvc, mock.NewTaskService())
+	results, err := svc.ReconcileConfig(context.Background(), cfg, orgSvc, urmSvc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, res := range results["ops"] {
+		if res.Error != "" {
+			t.Errorf("unexpected error of %s %s: %s", res.Kind, res.Name, res.Error)
+		}
+	}
+
+	sort.Strings(actions)
+	want := []string{
+		"create endpoint chat",
+		"create rule for " + userID.String(),
+		"delete check " + influxdb.ID(0x51).String(),
+		"delete endpoint " + influxdb.ID(0x41).String(),
+		"update check " + influxdb.ID(0x50).String(),
+		"update endpoint " + influxdb.ID(0x40).String(),
+	}
+	if diff := cmp.Diff(want, actions); diff != "" {
+		t.Errorf("unexpected actions, -want/+got:\n%s", diff)
+	}
+	if rule == nil || rule.GetEndpointID() != 0x40 || rule.GetOrgID() != targetOrgID {
+		t.Errorf("expected the rule to notify the pager endpoint, got %+v", rule)
+	}
+}
+
+func TestService_ReconcileConfigInvalid(t *testing.T) {
+	cfg := &alertconfig.Config{
+		Prune: true,
+		Organizations: []alertconfig.OrgConfig{{
+			Org:    "ops",
+			Checks: []json.RawMessage{json.RawMessage(`{"type": "unknown", "name": "cpu"}`)},
+		}},
+	}
+
+	checkSvc := mock.NewCheckService()
+	checkSvc.FindChecksFn = func(context.Context, influxdb.CheckFilter, ...influxdb.FindOptions) ([]influxdb.Check, int, error) {
+		return []influxdb.Check{
+			&check.Threshold{Base: check.Base{ID: 0x50, OrgID: targetOrgID, Name: "cpu"}},
+		}, 1, nil
+	}
+	checkSvc.DeleteCheckFn = func(_ context.Context, id influxdb.ID) error {
+		t.Errorf("unexpected deletion of check %s", id)
+		return nil
+	}
+	ruleSvc := mock.NewNotificationRuleStore()
+	ruleSvc.FindNotificationRulesF = func(context.Context, influxdb.NotificationRuleFilter, ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+		return nil, 0, nil
+	}
+	endpointSvc := mock.NewNotificationEndpointService()
+	endpointSvc.FindNotificationEndpointsF = func(context.Context, influxdb.NotificationEndpointFilter, ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+		return nil, 0, nil
+	}
+	orgSvc := mock.NewOrganizationService()
+	orgSvc.FindOrganizationF = func(_ context.Context, f influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return &influxdb.Organization{ID: targetOrgID, Name: *f.Name}, nil
+	}
+	urmSvc := mock.NewUserResourceMappingService()
+	urmSvc.FindMappingsFn = func(_ context.Context, f influxdb.UserResourceMappingFilter) ([]*influxdb.UserResourceMapping, int, error) {
+		return []*influxdb.UserResourceMapping{{UserID: userID, ResourceID: targetOrgID}}, 1, nil
+	}
+
+	svc := alertconfig.NewService(checkSvc, ruleSvc, endpointSvc, mock.NewTaskService())
+	results, err := svc.ReconcileConfig(context.Background(), cfg, orgSvc, urmSvc)
+	if err != alertconfig.ErrConfigNotApplied {
+		t.Fatalf("expected %v, got %v", alertconfig.ErrConfigNotApplied, err)
+	}
+	if res := results["ops"]; len(res) != 1 || res[0].Error == "" {
+		t.Errorf("expected the error of the check, got %+v", res)
+	}
+}
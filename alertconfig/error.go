@@ -0,0 +1,62 @@
+package alertconfig
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// ErrConfigNotApplied is used when some resources of an alert configuration
+// cannot be reconciled.
+var ErrConfigNotApplied = &influxdb.Error{
+	Code: influxdb.EInvalid,
+	Msg:  "some resources of the alert configuration could not be reconciled",
+}
+
+// ErrUnsupportedVersion is used when a document has a version this instance
+// cannot import.
+func ErrUnsupportedVersion(version int) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("alert configuration version %d is not supported, expected %d", version, DocumentVersion),
+	}
+}
+
+// ErrInvalidResource is used when a resource of a document cannot be
+// decoded.
+func ErrInvalidResource(kind string, err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  kind + " is invalid",
+		Err:  err,
+	}
+}
+
+// ErrEndpointNotImported is used when the endpoint a notification rule
+// references is neither part of the document nor mapped to an endpoint of
+// the organization.
+func ErrEndpointNotImported(id influxdb.ID) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "notification endpoint " + id.String() + " of the rule was not imported",
+	}
+}
+
+// ErrEndpointNameNotFound is used when the endpoint a notification rule
+// references by name is neither part of the configuration nor an endpoint
+// of the organization.
+func ErrEndpointNameNotFound(name string) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "notification endpoint " + name + " of the rule was not found",
+	}
+}
+
+// ErrEndpointConflict is used when an endpoint of the organization has the
+// name of an endpoint of the document and another type.
+func ErrEndpointConflict(name, typ string) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  "notification endpoint " + name + " exists with type " + typ,
+	}
+}
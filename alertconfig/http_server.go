@@ -0,0 +1,112 @@
+package alertconfig
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixAlertConfig is the prefix of the alert configuration API.
+	PrefixAlertConfig = "/api/v2/alertconfig"
+)
+
+// Handler is the HTTP API handler for the export and import of the alert
+// configuration of organizations.
+type Handler struct {
+	chi.Router
+	api *kithttp.API
+	log *zap.Logger
+	svc *Service
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, svc *Service) *Handler {
+	h := &Handler{
+		api: kithttp.NewAPI(kithttp.WithLog(log)),
+		log: log,
+		svc: svc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Get("/export", h.handleGetExport)
+	r.Post("/import", h.handlePostImport)
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixAlertConfig
+}
+
+func (h *Handler) handleGetExport(w http.ResponseWriter, r *http.Request) {
+	var orgID influxdb.ID
+	if err := orgID.DecodeFromString(r.URL.Query().Get("orgID")); err != nil {
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID is required",
+			Err:  err,
+		})
+		return
+	}
+
+	doc, err := h.svc.Export(r.Context(), orgID)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, doc)
+}
+
+type postImportRequest struct {
+	ImportOptions
+	OrgID    influxdb.ID `json:"orgID"`
+	Document *Document   `json:"document"`
+}
+
+type importResponse struct {
+	Results []*ImportResult `json:"results"`
+}
+
+func (h *Handler) handlePostImport(w http.ResponseWriter, r *http.Request) {
+	var req postImportRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if !req.OrgID.Valid() {
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID is required",
+		})
+		return
+	}
+	if req.Document == nil {
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "document is required",
+		})
+		return
+	}
+
+	results, err := h.svc.Import(r.Context(), req.OrgID, req.Document, req.ImportOptions)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Alert configuration imported", zap.Int("resources", len(results)))
+
+	h.api.Respond(w, r, http.StatusOK, importResponse{Results: results})
+}
@@ -0,0 +1,285 @@
+package alertconfig
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+)
+
+// Reconcile makes the checks, notification rules and endpoints of the
+// organization those of doc, matched by name: the resources of doc are
+// created, or update the resources of the organization with their name. With
+// prune, the resources of the organization that are not in doc are deleted,
+// unless a resource of doc could not be reconciled: its name may be unknown,
+// and its resource in the organization must not be deleted then.
+// The rules reference their endpoint by name with endpointName, or by the ID
+// of an endpoint of doc or of the organization with endpointID. The
+// resources are created on behalf of userID. A resource that cannot be
+// reconciled does not prevent the reconciliation of the others; its error is
+// reported in its result.
+func (s *Service) Reconcile(ctx context.Context, orgID, userID influxdb.ID, doc *Document, prune bool) ([]*ImportResult, error) {
+	if doc.Version != DocumentVersion {
+		return nil, ErrUnsupportedVersion(doc.Version)
+	}
+	endpoints, _, err := s.endpointSvc.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+	checks, _, err := s.checkSvc.FindChecks(ctx, influxdb.CheckFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+	rules, _, err := s.ruleSvc.FindNotificationRules(ctx, influxdb.NotificationRuleFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	rc := &reconciler{
+		importer: &importer{
+			Service:   s,
+			orgID:     orgID,
+			userID:    userID,
+			existing:  endpoints,
+			endpoints: make(map[influxdb.ID]influxdb.ID, len(doc.NotificationEndpoints)),
+		},
+		endpointNames: make(map[string]influxdb.ID, len(endpoints)),
+		checks:        make(map[string]influxdb.Check, len(checks)),
+		rules:         make(map[string]influxdb.NotificationRule, len(rules)),
+		kept:          make(map[string]bool),
+	}
+	for _, e := range endpoints {
+		rc.endpointNames[e.GetName()] = e.GetID()
+		// the rules of the document may reference the endpoints of the
+		// organization by their ID.
+		rc.endpoints[e.GetID()] = e.GetID()
+	}
+	for _, c := range checks {
+		rc.checks[c.GetName()] = c
+	}
+	for _, r := range rules {
+		rc.rules[r.GetName()] = r
+	}
+
+	results := make([]*ImportResult, 0, len(doc.NotificationEndpoints)+len(doc.Checks)+len(doc.NotificationRules))
+	for _, b := range doc.NotificationEndpoints {
+		results = append(results, rc.reconcileEndpoint(ctx, b))
+	}
+	for _, b := range doc.Checks {
+		results = append(results, rc.reconcileCheck(ctx, b))
+	}
+	for _, b := range doc.NotificationRules {
+		results = append(results, rc.reconcileRule(ctx, b))
+	}
+	if !prune || failed(results) {
+		return results, nil
+	}
+
+	// the rules are deleted before the endpoints they reference.
+	for _, r := range rules {
+		if rc.kept[KindNotificationRule+r.GetName()] {
+			continue
+		}
+		res := &ImportResult{Kind: KindNotificationRule, Name: r.GetName(), ID: r.GetID(), Deleted: true}
+		if err := s.ruleSvc.DeleteNotificationRule(ctx, r.GetID()); err != nil {
+			res.Deleted, res.Error = false, err.Error()
+		}
+		results = append(results, res)
+	}
+	for _, c := range checks {
+		if rc.kept[KindCheck+c.GetName()] {
+			continue
+		}
+		res := &ImportResult{Kind: KindCheck, Name: c.GetName(), ID: c.GetID(), Deleted: true}
+		if err := s.checkSvc.DeleteCheck(ctx, c.GetID()); err != nil {
+			res.Deleted, res.Error = false, err.Error()
+		}
+		results = append(results, res)
+	}
+	for _, e := range endpoints {
+		if rc.kept[KindNotificationEndpoint+e.GetName()] {
+			continue
+		}
+		res := &ImportResult{Kind: KindNotificationEndpoint, Name: e.GetName(), ID: e.GetID(), Deleted: true}
+		if _, _, err := s.endpointSvc.DeleteNotificationEndpoint(ctx, e.GetID()); err != nil {
+			res.Deleted, res.Error = false, err.Error()
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// failed returns whether a resource of results could not be reconciled.
+func failed(results []*ImportResult) bool {
+	for _, res := range results {
+		if res.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+type reconciler struct {
+	*importer
+	// endpointNames maps the names of the endpoints of the organization to
+	// their ID, and checks and rules are the checks and rules of the
+	// organization by name.
+	endpointNames map[string]influxdb.ID
+	checks        map[string]influxdb.Check
+	rules         map[string]influxdb.NotificationRule
+	// kept holds the kinds and names of the resources of the document,
+	// which are not pruned.
+	kept map[string]bool
+}
+
+func (rc *reconciler) reconcileEndpoint(ctx context.Context, b json.RawMessage) *ImportResult {
+	res := &ImportResult{Kind: KindNotificationEndpoint}
+	e, err := rc.decodeEndpoint(b)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Name, res.SourceID = e.GetName(), e.GetID()
+	rc.kept[KindNotificationEndpoint+res.Name] = true
+
+	e.SetOrgID(rc.orgID)
+	var current influxdb.NotificationEndpoint
+	for _, ee := range rc.existing {
+		if ee.GetName() == res.Name {
+			current = ee
+			break
+		}
+	}
+	switch {
+	case current == nil:
+		if err := rc.endpointSvc.CreateNotificationEndpoint(ctx, e, rc.userID); err != nil {
+			res.Error = err.Error()
+			return res
+		}
+	case current.Type() != e.Type():
+		res.Error = ErrEndpointConflict(res.Name, current.Type()).Error()
+		return res
+	default:
+		e.SetID(current.GetID())
+		if _, err := rc.endpointSvc.UpdateNotificationEndpoint(ctx, current.GetID(), e, rc.userID); err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		res.Updated = true
+	}
+
+	res.ID = e.GetID()
+	rc.endpointNames[res.Name] = res.ID
+	if res.SourceID.Valid() {
+		rc.endpoints[res.SourceID] = res.ID
+	}
+	return res
+}
+
+func (rc *reconciler) reconcileCheck(ctx context.Context, b json.RawMessage) *ImportResult {
+	res := &ImportResult{Kind: KindCheck}
+	c, err := check.UnmarshalJSON(b)
+	if err != nil {
+		res.Error = ErrInvalidResource(KindCheck, err).Error()
+		return res
+	}
+	res.Name, res.SourceID = c.GetName(), c.GetID()
+	rc.kept[KindCheck+res.Name] = true
+	status, err := decodeStatus(b)
+	if err != nil {
+		res.Error = ErrInvalidResource(KindCheck, err).Error()
+		return res
+	}
+
+	c.SetOrgID(rc.orgID)
+	c.ClearPrivateData()
+	cc := influxdb.CheckCreate{Check: c, Status: status}
+	if current, ok := rc.checks[res.Name]; ok {
+		if _, err := rc.checkSvc.UpdateCheck(ctx, current.GetID(), cc); err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		res.ID, res.Updated = current.GetID(), true
+		return res
+	}
+	if err := rc.checkSvc.CreateCheck(ctx, cc, rc.userID); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.ID = c.GetID()
+	return res
+}
+
+func (rc *reconciler) reconcileRule(ctx context.Context, b json.RawMessage) *ImportResult {
+	res := &ImportResult{Kind: KindNotificationRule}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(b, &obj); err != nil {
+		res.Error = ErrInvalidResource(KindNotificationRule, err).Error()
+		return res
+	}
+	var src struct {
+		ID           influxdb.ID `json:"id"`
+		Name         string      `json:"name"`
+		EndpointID   influxdb.ID `json:"endpointID"`
+		EndpointName string      `json:"endpointName"`
+	}
+	if err := json.Unmarshal(b, &src); err != nil {
+		res.Error = ErrInvalidResource(KindNotificationRule, err).Error()
+		return res
+	}
+	res.Name, res.SourceID = src.Name, src.ID
+	rc.kept[KindNotificationRule+res.Name] = true
+
+	var (
+		endpointID influxdb.ID
+		ok         bool
+	)
+	if src.EndpointName != "" {
+		endpointID, ok = rc.endpointNames[src.EndpointName]
+		if !ok {
+			res.Error = ErrEndpointNameNotFound(src.EndpointName).Error()
+			return res
+		}
+	} else if endpointID, ok = rc.endpoints[src.EndpointID]; !ok {
+		res.Error = ErrEndpointNotImported(src.EndpointID).Error()
+		return res
+	}
+	delete(obj, "endpointName")
+	obj["endpointID"], _ = json.Marshal(endpointID)
+	b, err := json.Marshal(obj)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	r, err := rule.UnmarshalJSON(b)
+	if err != nil {
+		res.Error = ErrInvalidResource(KindNotificationRule, err).Error()
+		return res
+	}
+	status, err := decodeStatus(b)
+	if err != nil {
+		res.Error = ErrInvalidResource(KindNotificationRule, err).Error()
+		return res
+	}
+
+	r.SetOrgID(rc.orgID)
+	r.ClearPrivateData()
+	nrc := influxdb.NotificationRuleCreate{NotificationRule: r, Status: status}
+	if current, ok := rc.rules[res.Name]; ok {
+		if _, err := rc.ruleSvc.UpdateNotificationRule(ctx, current.GetID(), nrc, rc.userID); err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		res.ID, res.Updated = current.GetID(), true
+		return res
+	}
+	if err := rc.ruleSvc.CreateNotificationRule(ctx, nrc, rc.userID); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.ID = r.GetID()
+	return res
+}
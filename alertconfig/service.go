@@ -0,0 +1,401 @@
+// Package alertconfig exports the checks, notification rules and
+// notification endpoints of an organization as a single JSON document, and
+// imports them into another organization or instance, e.g. to promote the
+// alerts of a staging organization to production.
+package alertconfig
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+)
+
+// DocumentVersion is the version of the documents exported.
+const DocumentVersion = 1
+
+// secretPrefix prefixes the keys of the secret fields of the endpoints in
+// their JSON.
+const secretPrefix = "secret: "
+
+// The kinds of the resources of documents.
+const (
+	KindCheck                = "check"
+	KindNotificationRule     = "notificationRule"
+	KindNotificationEndpoint = "notificationEndpoint"
+)
+
+// Document is the alert configuration of an organization. The resources are
+// kept as the JSON of the API, with their status, so that the documents of
+// another instance are imported as they are. The secret fields of the
+// endpoints only hold their keys, never their values.
+type Document struct {
+	Version               int               `json:"version"`
+	OrgID                 influxdb.ID       `json:"orgID"`
+	ExportedAt            time.Time         `json:"exportedAt"`
+	Checks                []json.RawMessage `json:"checks"`
+	NotificationRules     []json.RawMessage `json:"notificationRules"`
+	NotificationEndpoints []json.RawMessage `json:"notificationEndpoints"`
+}
+
+// ImportOptions remaps the IDs and secret keys the resources of a document
+// reference when they are imported.
+type ImportOptions struct {
+	// EndpointIDs maps the IDs of endpoints of the document to the endpoints
+	// of the organization that are used instead of creating them.
+	EndpointIDs map[influxdb.ID]influxdb.ID `json:"endpointIDs,omitempty"`
+	// SecretKeys maps the secret keys of the endpoints of the document to
+	// the secrets of the organization they reference instead.
+	SecretKeys map[string]string `json:"secretKeys,omitempty"`
+	// Secrets are the values of the secret keys of the endpoints of the
+	// document, stored as secrets of the endpoints created. The secret keys
+	// that are neither remapped nor given a value are kept as they are.
+	Secrets map[string]string `json:"secrets,omitempty"`
+}
+
+// ImportResult is the outcome of the import of one resource of a document.
+type ImportResult struct {
+	Kind     string      `json:"kind"`
+	Name     string      `json:"name"`
+	SourceID influxdb.ID `json:"sourceID"`
+	ID       influxdb.ID `json:"id,omitempty"`
+	// Existing is set for the endpoints that were mapped to an endpoint of
+	// the organization instead of being created.
+	Existing bool `json:"existing,omitempty"`
+	// Updated and Deleted are set for the resources of the organization
+	// that a reconciliation updated or pruned.
+	Updated bool   `json:"updated,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Service exports and imports the alert configuration of organizations.
+type Service struct {
+	checkSvc    influxdb.CheckService
+	ruleSvc     influxdb.NotificationRuleStore
+	endpointSvc influxdb.NotificationEndpointService
+	taskSvc     influxdb.TaskService
+}
+
+// NewService constructs a service reading and creating the checks,
+// notification rules and endpoints with checkSvc, ruleSvc and endpointSvc.
+// The status of the checks and rules is read from their tasks with taskSvc.
+func NewService(checkSvc influxdb.CheckService, ruleSvc influxdb.NotificationRuleStore, endpointSvc influxdb.NotificationEndpointService, taskSvc influxdb.TaskService) *Service {
+	return &Service{
+		checkSvc:    checkSvc,
+		ruleSvc:     ruleSvc,
+		endpointSvc: endpointSvc,
+		taskSvc:     taskSvc,
+	}
+}
+
+// Export returns the checks, notification rules and endpoints of the
+// organization.
+func (s *Service) Export(ctx context.Context, orgID influxdb.ID) (*Document, error) {
+	doc := &Document{
+		Version:               DocumentVersion,
+		OrgID:                 orgID,
+		ExportedAt:            time.Now().UTC(),
+		Checks:                []json.RawMessage{},
+		NotificationRules:     []json.RawMessage{},
+		NotificationEndpoints: []json.RawMessage{},
+	}
+
+	checks, _, err := s.checkSvc.FindChecks(ctx, influxdb.CheckFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range checks {
+		status, err := s.taskStatus(ctx, c.GetTaskID())
+		if err != nil {
+			return nil, err
+		}
+		c.ClearPrivateData()
+		b, err := withStatus(c, status)
+		if err != nil {
+			return nil, err
+		}
+		doc.Checks = append(doc.Checks, b)
+	}
+
+	rules, _, err := s.ruleSvc.FindNotificationRules(ctx, influxdb.NotificationRuleFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		status, err := s.taskStatus(ctx, r.GetTaskID())
+		if err != nil {
+			return nil, err
+		}
+		r.ClearPrivateData()
+		b, err := withStatus(r, status)
+		if err != nil {
+			return nil, err
+		}
+		doc.NotificationRules = append(doc.NotificationRules, b)
+	}
+
+	endpoints, _, err := s.endpointSvc.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range endpoints {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		doc.NotificationEndpoints = append(doc.NotificationEndpoints, b)
+	}
+	return doc, nil
+}
+
+func (s *Service) taskStatus(ctx context.Context, taskID influxdb.ID) (influxdb.Status, error) {
+	t, err := s.taskSvc.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return "", err
+	}
+	return influxdb.Status(t.Status), nil
+}
+
+// withStatus returns the JSON of v with its status.
+func withStatus(v json.Marshaler, status influxdb.Status) (json.RawMessage, error) {
+	obj, err := jsonObject(v)
+	if err != nil {
+		return nil, err
+	}
+	if obj["status"], err = json.Marshal(status); err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+func jsonObject(v json.Marshaler) (map[string]json.RawMessage, error) {
+	b, err := v.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Import creates the resources of doc in the organization, the endpoints
+// first so that the rules reference the endpoints created for them. The
+// endpoints named after an endpoint of the organization of the same type
+// are mapped to it. A resource that cannot be created does not prevent the
+// import of the others; its error is reported in its result.
+func (s *Service) Import(ctx context.Context, orgID influxdb.ID, doc *Document, opts ImportOptions) ([]*ImportResult, error) {
+	if doc.Version != DocumentVersion {
+		return nil, ErrUnsupportedVersion(doc.Version)
+	}
+	auth, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing, _, err := s.endpointSvc.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	in := &importer{
+		Service:   s,
+		orgID:     orgID,
+		userID:    auth.GetUserID(),
+		opts:      opts,
+		existing:  existing,
+		endpoints: make(map[influxdb.ID]influxdb.ID, len(doc.NotificationEndpoints)),
+	}
+	for from, to := range opts.EndpointIDs {
+		in.endpoints[from] = to
+	}
+
+	results := make([]*ImportResult, 0, len(doc.NotificationEndpoints)+len(doc.Checks)+len(doc.NotificationRules))
+	for _, b := range doc.NotificationEndpoints {
+		results = append(results, in.importEndpoint(ctx, b))
+	}
+	for _, b := range doc.Checks {
+		results = append(results, in.importCheck(ctx, b))
+	}
+	for _, b := range doc.NotificationRules {
+		results = append(results, in.importRule(ctx, b))
+	}
+	return results, nil
+}
+
+type importer struct {
+	*Service
+	orgID  influxdb.ID
+	userID influxdb.ID
+	opts   ImportOptions
+	// existing are the endpoints of the organization, and endpoints maps
+	// the IDs of the endpoints of the document to their imported endpoint.
+	existing  []influxdb.NotificationEndpoint
+	endpoints map[influxdb.ID]influxdb.ID
+}
+
+func (in *importer) importEndpoint(ctx context.Context, b json.RawMessage) *ImportResult {
+	res := &ImportResult{Kind: KindNotificationEndpoint}
+	e, err := in.decodeEndpoint(b)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Name, res.SourceID = e.GetName(), e.GetID()
+
+	if id, ok := in.endpoints[res.SourceID]; ok {
+		res.ID, res.Existing = id, true
+		return res
+	}
+	for _, ee := range in.existing {
+		if ee.GetName() != res.Name {
+			continue
+		}
+		if ee.Type() != e.Type() {
+			res.Error = ErrEndpointConflict(res.Name, ee.Type()).Error()
+			return res
+		}
+		in.endpoints[res.SourceID] = ee.GetID()
+		res.ID, res.Existing = ee.GetID(), true
+		return res
+	}
+
+	e.SetOrgID(in.orgID)
+	if err := in.endpointSvc.CreateNotificationEndpoint(ctx, e, in.userID); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	in.endpoints[res.SourceID] = e.GetID()
+	res.ID = e.GetID()
+	return res
+}
+
+// decodeEndpoint decodes the endpoint of b, with its secret keys remapped
+// or replaced with their values.
+func (in *importer) decodeEndpoint(b json.RawMessage) (influxdb.NotificationEndpoint, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, ErrInvalidResource(KindNotificationEndpoint, err)
+	}
+	for k, v := range obj {
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil || !strings.HasPrefix(s, secretPrefix) {
+			continue
+		}
+		key := strings.TrimPrefix(s, secretPrefix)
+		if to, ok := in.opts.SecretKeys[key]; ok {
+			s = secretPrefix + to
+		} else if value, ok := in.opts.Secrets[key]; ok {
+			s = value
+		} else {
+			continue
+		}
+		obj[k], _ = json.Marshal(s)
+	}
+
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	e, err := endpoint.UnmarshalJSON(b)
+	if err != nil {
+		return nil, ErrInvalidResource(KindNotificationEndpoint, err)
+	}
+	return e, nil
+}
+
+func (in *importer) importCheck(ctx context.Context, b json.RawMessage) *ImportResult {
+	res := &ImportResult{Kind: KindCheck}
+	c, err := check.UnmarshalJSON(b)
+	if err != nil {
+		res.Error = ErrInvalidResource(KindCheck, err).Error()
+		return res
+	}
+	res.Name, res.SourceID = c.GetName(), c.GetID()
+	status, err := decodeStatus(b)
+	if err != nil {
+		res.Error = ErrInvalidResource(KindCheck, err).Error()
+		return res
+	}
+
+	c.SetOrgID(in.orgID)
+	c.ClearPrivateData()
+	if err := in.checkSvc.CreateCheck(ctx, influxdb.CheckCreate{Check: c, Status: status}, in.userID); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.ID = c.GetID()
+	return res
+}
+
+func (in *importer) importRule(ctx context.Context, b json.RawMessage) *ImportResult {
+	res := &ImportResult{Kind: KindNotificationRule}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(b, &obj); err != nil {
+		res.Error = ErrInvalidResource(KindNotificationRule, err).Error()
+		return res
+	}
+	var src struct {
+		ID         influxdb.ID `json:"id"`
+		Name       string      `json:"name"`
+		EndpointID influxdb.ID `json:"endpointID"`
+	}
+	if err := json.Unmarshal(b, &src); err != nil {
+		res.Error = ErrInvalidResource(KindNotificationRule, err).Error()
+		return res
+	}
+	res.Name, res.SourceID = src.Name, src.ID
+
+	endpointID, ok := in.endpoints[src.EndpointID]
+	if !ok {
+		res.Error = ErrEndpointNotImported(src.EndpointID).Error()
+		return res
+	}
+	obj["endpointID"], _ = json.Marshal(endpointID)
+	b, err := json.Marshal(obj)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	r, err := rule.UnmarshalJSON(b)
+	if err != nil {
+		res.Error = ErrInvalidResource(KindNotificationRule, err).Error()
+		return res
+	}
+	status, err := decodeStatus(b)
+	if err != nil {
+		res.Error = ErrInvalidResource(KindNotificationRule, err).Error()
+		return res
+	}
+
+	r.SetOrgID(in.orgID)
+	r.ClearPrivateData()
+	if err := in.ruleSvc.CreateNotificationRule(ctx, influxdb.NotificationRuleCreate{NotificationRule: r, Status: status}, in.userID); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.ID = r.GetID()
+	return res
+}
+
+// decodeStatus returns the status of the resource of b, active by default.
+func decodeStatus(b json.RawMessage) (influxdb.Status, error) {
+	var s struct {
+		Status influxdb.Status `json:"status"`
+	}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return "", err
+	}
+	if s.Status == "" {
+		return influxdb.Active, nil
+	}
+	return s.Status, s.Status.Valid()
+}
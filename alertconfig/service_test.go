@@ -0,0 +1,211 @@
+package alertconfig_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/alertconfig"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+)
+
+const (
+	sourceOrgID = influxdb.ID(0x10)
+	targetOrgID = influxdb.ID(0x20)
+	userID      = influxdb.ID(0x30)
+)
+
+func idPtr(id influxdb.ID) *influxdb.ID {
+	return &id
+}
+
+func slackEndpoint(id, orgID influxdb.ID, name string) *endpoint.Slack {
+	e := &endpoint.Slack{
+		Base: endpoint.Base{
+			ID:     idPtr(id),
+			OrgID:  idPtr(orgID),
+			Name:   name,
+			Status: influxdb.Active,
+		},
+		URL: "https://hooks.slack.com/services/x",
+	}
+	e.Token.Key = id.String() + "-token"
+	return e
+}
+
+func slackRule(id, endpointID influxdb.ID, name string) *rule.Slack {
+	return &rule.Slack{
+		Base: rule.Base{
+			ID:         id,
+			OrgID:      sourceOrgID,
+			Name:       name,
+			EndpointID: endpointID,
+			TaskID:     id + 0x100,
+		},
+		Channel: "#ops",
+	}
+}
+
+// exportDocument exports the alert configuration of the source
+// organization, as read from mocked services.
+func exportDocument(t *testing.T) *alertconfig.Document {
+	t.Helper()
+
+	checkSvc := mock.NewCheckService()
+	checkSvc.FindChecksFn = func(_ context.Context, f influxdb.CheckFilter, _ ...influxdb.FindOptions) ([]influxdb.Check, int, error) {
+		if *f.OrgID != sourceOrgID {
+			t.Errorf("unexpected org %s", f.OrgID)
+		}
+		return []influxdb.Check{
+			&check.Threshold{Base: check.Base{ID: 1, OrgID: sourceOrgID, Name: "cpu", TaskID: 0x101}},
+		}, 1, nil
+	}
+	ruleSvc := mock.NewNotificationRuleStore()
+	ruleSvc.FindNotificationRulesF = func(context.Context, influxdb.NotificationRuleFilter, ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+		return []influxdb.NotificationRule{
+			slackRule(4, 2, "created"),
+			slackRule(5, 3, "existing"),
+			slackRule(6, 9, "missing"),
+		}, 3, nil
+	}
+	endpointSvc := mock.NewNotificationEndpointService()
+	endpointSvc.FindNotificationEndpointsF = func(context.Context, influxdb.NotificationEndpointFilter, ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+		return []influxdb.NotificationEndpoint{
+			slackEndpoint(2, sourceOrgID, "ops"),
+			slackEndpoint(3, sourceOrgID, "pager"),
+			slackEndpoint(7, sourceOrgID, "dev"),
+		}, 3, nil
+	}
+	taskSvc := mock.NewTaskService()
+	taskSvc.FindTaskByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Task, error) {
+		status := influxdb.TaskStatusActive
+		if id == 0x101 {
+			status = influxdb.TaskStatusInactive
+		}
+		return &influxdb.Task{ID: id, Status: status}, nil
+	}
+
+	svc := alertconfig.NewService(checkSvc, ruleSvc, endpointSvc, taskSvc)
+	doc, err := svc.Export(context.Background(), sourceOrgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The document goes through JSON, as between two instances.
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc = new(alertconfig.Document)
+	if err := json.Unmarshal(b, doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestService_ExportImport(t *testing.T) {
+	doc := exportDocument(t)
+	if doc.Version != alertconfig.DocumentVersion || doc.OrgID != sourceOrgID {
+		t.Fatalf("unexpected document %+v", doc)
+	}
+	if len(doc.Checks) != 1 || len(doc.NotificationRules) != 3 || len(doc.NotificationEndpoints) != 3 {
+		t.Fatalf("unexpected resources %+v", doc)
+	}
+
+	var (
+		nextID    = influxdb.ID(0x1000)
+		checks    []influxdb.CheckCreate
+		rules     []influxdb.NotificationRuleCreate
+		endpoints []influxdb.NotificationEndpoint
+	)
+	checkSvc := mock.NewCheckService()
+	checkSvc.CreateCheckFn = func(_ context.Context, c influxdb.CheckCreate, _ influxdb.ID) error {
+		nextID++
+		c.SetID(nextID)
+		checks = append(checks, c)
+		return nil
+	}
+	ruleSvc := mock.NewNotificationRuleStore()
+	ruleSvc.CreateNotificationRuleF = func(_ context.Context, r influxdb.NotificationRuleCreate, _ influxdb.ID) error {
+		nextID++
+		r.SetID(nextID)
+		rules = append(rules, r)
+		return nil
+	}
+	endpointSvc := mock.NewNotificationEndpointService()
+	endpointSvc.FindNotificationEndpointsF = func(context.Context, influxdb.NotificationEndpointFilter, ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+		return []influxdb.NotificationEndpoint{slackEndpoint(0x40, targetOrgID, "pager")}, 1, nil
+	}
+	endpointSvc.CreateNotificationEndpointF = func(_ context.Context, e influxdb.NotificationEndpoint, _ influxdb.ID) error {
+		nextID++
+		e.SetID(nextID)
+		endpoints = append(endpoints, e)
+		return nil
+	}
+
+	svc := alertconfig.NewService(checkSvc, ruleSvc, endpointSvc, mock.NewTaskService())
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{UserID: userID})
+	results, err := svc.Import(ctx, targetOrgID, doc, alertconfig.ImportOptions{
+		SecretKeys: map[string]string{influxdb.ID(7).String() + "-token": "dev-slack-token"},
+		Secrets:    map[string]string{influxdb.ID(2).String() + "-token": "xoxb-secret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := map[string]string{}
+	for _, res := range results {
+		if res.Error != "" {
+			errs[res.Name] = res.Error
+		}
+	}
+	if len(errs) != 1 || errs["missing"] == "" {
+		t.Errorf("expected only the rule of the missing endpoint to fail, got %v", errs)
+	}
+
+	if len(endpoints) != 2 {
+		t.Fatalf("expected the endpoints ops and dev to be created, got %d", len(endpoints))
+	}
+	ops, dev := endpoints[0].(*endpoint.Slack), endpoints[1].(*endpoint.Slack)
+	if ops.GetOrgID() != targetOrgID {
+		t.Errorf("unexpected org of endpoint %s", ops.GetOrgID())
+	}
+	if ops.Token.Value == nil || *ops.Token.Value != "xoxb-secret" {
+		t.Errorf("expected the secret value of ops to be set, got %+v", ops.Token)
+	}
+	if dev.Token.Key != "dev-slack-token" || dev.Token.Value != nil {
+		t.Errorf("expected the secret key of dev to be remapped, got %+v", dev.Token)
+	}
+
+	if len(checks) != 1 || checks[0].GetOrgID() != targetOrgID || checks[0].Status != influxdb.Inactive {
+		t.Errorf("unexpected checks %+v", checks)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("unexpected rules %+v", rules)
+	}
+	if id := rules[0].GetEndpointID(); id != ops.GetID() {
+		t.Errorf("expected rule created to notify the created endpoint, got %s", id)
+	}
+	if id := rules[1].GetEndpointID(); id != 0x40 {
+		t.Errorf("expected rule existing to notify the existing endpoint, got %s", id)
+	}
+	for _, r := range rules {
+		if r.GetOrgID() != targetOrgID || r.GetTaskID().Valid() || r.Status != influxdb.Active {
+			t.Errorf("unexpected rule %+v", r)
+		}
+	}
+}
+
+func TestService_ImportVersion(t *testing.T) {
+	svc := alertconfig.NewService(mock.NewCheckService(), mock.NewNotificationRuleStore(), mock.NewNotificationEndpointService(), mock.NewTaskService())
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{UserID: userID})
+	_, err := svc.Import(ctx, targetOrgID, &alertconfig.Document{Version: 2}, alertconfig.ImportOptions{})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected an invalid version, got %v", err)
+	}
+}
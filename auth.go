@@ -3,6 +3,7 @@ package influxdb
 import (
 	"context"
 	"fmt"
+	"regexp"
 )
 
 // AuthorizationKind is returned by (*Authorization).Kind().
@@ -23,13 +24,38 @@ type Authorization struct {
 	OrgID       ID           `json:"orgID"`
 	UserID      ID           `json:"userID,omitempty"`
 	Permissions []Permission `json:"permissions"`
+	// RowFilters restricts the series read with the authorization to the
+	// ones matching all the tag rules, e.g. tenant == "acme", so that several
+	// tenants can share a bucket.
+	RowFilters []TagRule `json:"rowFilters,omitempty"`
 	CRUDLog
 }
 
 // AuthorizationUpdate is the authorization update request.
 type AuthorizationUpdate struct {
-	Status      *Status `json:"status,omitempty"`
-	Description *string `json:"description,omitempty"`
+	Status      *Status    `json:"status,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	RowFilters  *[]TagRule `json:"rowFilters,omitempty"`
+}
+
+// ValidRowFilters returns an error if a row filter is not a valid tag rule.
+func ValidRowFilters(filters []TagRule) error {
+	for _, f := range filters {
+		if err := f.Valid(); err != nil {
+			return err
+		}
+		if f.Operator != RegexEqual && f.Operator != NotRegexEqual {
+			continue
+		}
+		if _, err := regexp.Compile(f.Value); err != nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("row filter on tag %s is not a valid regular expression", f.Key),
+				Err:  err,
+			}
+		}
+	}
+	return nil
 }
 
 // Valid ensures that the authorization is valid.
@@ -43,7 +69,7 @@ func (a *Authorization) Valid() error {
 		}
 	}
 
-	return nil
+	return ValidRowFilters(a.RowFilters)
 }
 
 // PermissionSet returns the set of permissions associated with the Authorization.
@@ -104,7 +130,7 @@ type AuthorizationService interface {
 	// Creates a new authorization and sets a.Token and a.UserID with the new identifier.
 	CreateAuthorization(ctx context.Context, a *Authorization) error
 
-	// UpdateAuthorization updates the status and description if available.
+	// UpdateAuthorization updates the status, description and row filters if available.
 	UpdateAuthorization(ctx context.Context, id ID, upd *AuthorizationUpdate) (*Authorization, error)
 
 	// Removes a authorization by token.
@@ -30,16 +30,31 @@ type AuthHandler struct {
 	log           *zap.Logger
 	authSvc       influxdb.AuthorizationService
 	tenantService TenantService
+	presetSvc     influxdb.AuthorizationPresetService
+}
+
+// AuthHandlerOption configures an AuthHandler.
+type AuthHandlerOption func(*AuthHandler)
+
+// WithAuthorizationPresetService lets authorizations be created with the
+// permissions of the presets of their organization.
+func WithAuthorizationPresetService(s influxdb.AuthorizationPresetService) AuthHandlerOption {
+	return func(h *AuthHandler) {
+		h.presetSvc = s
+	}
 }
 
 // NewHTTPAuthHandler constructs a new http server.
-func NewHTTPAuthHandler(log *zap.Logger, authService influxdb.AuthorizationService, tenantService TenantService) *AuthHandler {
+func NewHTTPAuthHandler(log *zap.Logger, authService influxdb.AuthorizationService, tenantService TenantService, opts ...AuthHandlerOption) *AuthHandler {
 	h := &AuthHandler{
 		api:           kithttp.NewAPI(kithttp.WithLog(log)),
 		log:           log,
 		authSvc:       authService,
 		tenantService: tenantService,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
 
 	r := chi.NewRouter()
 	r.Use(
@@ -90,6 +105,14 @@ func (h *AuthHandler) handlePostAuthorization(w http.ResponseWriter, r *http.Req
 	}
 
 	auth := a.toInfluxdb(userID)
+	if len(a.Presets) > 0 {
+		ps, err := h.presetPermissions(ctx, a.OrgID, a.Presets)
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		auth.Permissions = mergePermissions(auth.Permissions, ps)
+	}
 
 	if err := h.authSvc.CreateAuthorization(ctx, auth); err != nil {
 		h.api.Err(w, r, err)
@@ -113,6 +136,54 @@ func (h *AuthHandler) handlePostAuthorization(w http.ResponseWriter, r *http.Req
 	h.api.Respond(w, r, http.StatusCreated, resp)
 }
 
+// presetPermissions returns the permissions of the presets of the
+// organization named names.
+func (h *AuthHandler) presetPermissions(ctx context.Context, orgID influxdb.ID, names []string) ([]influxdb.Permission, error) {
+	if h.presetSvc == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "authorization presets are not enabled",
+		}
+	}
+
+	var ps []influxdb.Permission
+	for _, name := range names {
+		name := name
+		presets, _, err := h.presetSvc.FindAuthorizationPresets(ctx, influxdb.AuthorizationPresetFilter{
+			OrgID: &orgID,
+			Name:  &name,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(presets) == 0 {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("authorization preset %q not found in the organization", name),
+			}
+		}
+		ps = append(ps, presets[0].Permissions...)
+	}
+	return ps, nil
+}
+
+// mergePermissions returns the permissions of ps followed by those of more
+// that are not in ps.
+func mergePermissions(ps, more []influxdb.Permission) []influxdb.Permission {
+	seen := make(map[string]bool, len(ps))
+	for _, p := range ps {
+		seen[p.String()] = true
+	}
+	for _, p := range more {
+		if seen[p.String()] {
+			continue
+		}
+		seen[p.String()] = true
+		ps = append(ps, p)
+	}
+	return ps
+}
+
 func getAuthorizedUser(r *http.Request, ts TenantService) (*influxdb.User, error) {
 	ctx := r.Context()
 
@@ -130,6 +201,10 @@ type postAuthorizationRequest struct {
 	UserID      *influxdb.ID          `json:"userID,omitempty"`
 	Description string                `json:"description"`
 	Permissions []influxdb.Permission `json:"permissions"`
+	RowFilters  []influxdb.TagRule    `json:"rowFilters,omitempty"`
+	// Presets are the names of the presets of the organization whose
+	// permissions are granted in addition to Permissions.
+	Presets []string `json:"presets,omitempty"`
 }
 
 type authResponse struct {
@@ -142,6 +217,7 @@ type authResponse struct {
 	UserID      influxdb.ID          `json:"userID"`
 	User        string               `json:"user"`
 	Permissions []permissionResponse `json:"permissions"`
+	RowFilters  []influxdb.TagRule   `json:"rowFilters,omitempty"`
 	Links       map[string]string    `json:"links"`
 	CreatedAt   time.Time            `json:"createdAt"`
 	UpdatedAt   time.Time            `json:"updatedAt"`
@@ -171,6 +247,7 @@ func (h *AuthHandler) newAuthResponse(ctx context.Context, a *influxdb.Authoriza
 		User:        user.Name,
 		Org:         org.Name,
 		Permissions: ps,
+		RowFilters:  a.RowFilters,
 		Links: map[string]string{
 			"self": fmt.Sprintf("/api/v2/authorizations/%s", a.ID),
 			"user": fmt.Sprintf("/api/v2/users/%s", a.UserID),
@@ -187,6 +264,7 @@ func (p *postAuthorizationRequest) toInfluxdb(userID influxdb.ID) *influxdb.Auth
 		Status:      p.Status,
 		Description: p.Description,
 		Permissions: p.Permissions,
+		RowFilters:  p.RowFilters,
 		UserID:      userID,
 	}
 }
@@ -199,6 +277,7 @@ func (a *authResponse) toInfluxdb() *influxdb.Authorization {
 		Description: a.Description,
 		OrgID:       a.OrgID,
 		UserID:      a.UserID,
+		RowFilters:  a.RowFilters,
 		CRUDLog: influxdb.CRUDLog{
 			CreatedAt: a.CreatedAt,
 			UpdatedAt: a.UpdatedAt,
@@ -230,6 +309,7 @@ func newPostAuthorizationRequest(a *influxdb.Authorization) (*postAuthorizationR
 		OrgID:       a.OrgID,
 		Description: a.Description,
 		Permissions: a.Permissions,
+		RowFilters:  a.RowFilters,
 		Status:      a.Status,
 	}
 
@@ -249,10 +329,10 @@ func (p *postAuthorizationRequest) SetDefaults() {
 }
 
 func (p *postAuthorizationRequest) Validate() error {
-	if len(p.Permissions) == 0 {
+	if len(p.Permissions) == 0 && len(p.Presets) == 0 {
 		return &influxdb.Error{
 			Code: influxdb.EInvalid,
-			Msg:  "authorization must include permissions",
+			Msg:  "authorization must include permissions or presets",
 		}
 	}
 
@@ -264,6 +344,10 @@ func (p *postAuthorizationRequest) Validate() error {
 		}
 	}
 
+	if err := influxdb.ValidRowFilters(p.RowFilters); err != nil {
+		return err
+	}
+
 	if !p.OrgID.Valid() {
 		return &influxdb.Error{
 			Err:  influxdb.ErrInvalidID,
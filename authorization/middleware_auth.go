@@ -32,6 +32,9 @@ func (s *AuthedAuthorizationService) CreateAuthorization(ctx context.Context, a
 	if err := authorizer.VerifyPermissions(ctx, a.Permissions); err != nil {
 		return err
 	}
+	if err := authorizer.VerifyRowFilters(ctx, a.RowFilters); err != nil {
+		return err
+	}
 
 	return s.s.CreateAuthorization(ctx, a)
 }
@@ -85,6 +88,9 @@ func (s *AuthedAuthorizationService) UpdateAuthorization(ctx context.Context, id
 	if _, _, err := authorizer.AuthorizeWriteResource(ctx, influxdb.UsersResourceType, a.UserID); err != nil {
 		return nil, err
 	}
+	if err := authorizer.AuthorizeUpdateRowFilters(ctx, upd); err != nil {
+		return nil, err
+	}
 	return s.s.UpdateAuthorization(ctx, id, upd)
 }
 
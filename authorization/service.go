@@ -170,7 +170,7 @@ func (s *Service) FindAuthorizations(ctx context.Context, filter influxdb.Author
 	return as, len(as), nil
 }
 
-// UpdateAuthorization updates the status and description if available.
+// UpdateAuthorization updates the status, description and row filters if available.
 func (s *Service) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
 	var auth *influxdb.Authorization
 	err := s.store.View(ctx, func(tx kv.Tx) error {
@@ -195,6 +195,12 @@ func (s *Service) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *
 	if upd.Description != nil {
 		auth.Description = *upd.Description
 	}
+	if upd.RowFilters != nil {
+		if err := influxdb.ValidRowFilters(*upd.RowFilters); err != nil {
+			return nil, err
+		}
+		auth.RowFilters = *upd.RowFilters
+	}
 
 	auth.SetUpdatedAt(time.Now())
 
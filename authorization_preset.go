@@ -0,0 +1,90 @@
+package influxdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// AuthorizationPreset is a named set of permissions of an organization, such
+// as "read-only telemetry" or "CI deployer", that authorizations are created
+// with instead of hand-crafted permission arrays.
+type AuthorizationPreset struct {
+	ID          ID           `json:"id,omitempty"`
+	OrgID       ID           `json:"orgID"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Permissions []Permission `json:"permissions"`
+	CRUDLog
+}
+
+// Valid returns an error if the preset is missing required fields or grants
+// a permission outside of its organization.
+func (p *AuthorizationPreset) Valid() error {
+	if !p.OrgID.Valid() {
+		return errors.New("orgID is required")
+	}
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(p.Permissions) == 0 {
+		return errors.New("preset must include permissions")
+	}
+	for _, perm := range p.Permissions {
+		if err := perm.Valid(); err != nil {
+			return err
+		}
+		if perm.Resource.OrgID == nil || *perm.Resource.OrgID != p.OrgID {
+			return fmt.Errorf("permission %s is not scoped to the organization of the preset", perm)
+		}
+	}
+	return nil
+}
+
+// AuthorizationPresetFilter represents a set of filters that restrict the
+// returned presets.
+type AuthorizationPresetFilter struct {
+	OrgID *ID
+	Name  *string
+}
+
+// AuthorizationPresetUpdate is the changeset of a preset.
+type AuthorizationPresetUpdate struct {
+	Name        *string       `json:"name,omitempty"`
+	Description *string       `json:"description,omitempty"`
+	Permissions *[]Permission `json:"permissions,omitempty"`
+}
+
+// Apply applies the changeset to p.
+func (u AuthorizationPresetUpdate) Apply(p *AuthorizationPreset) {
+	if u.Name != nil {
+		p.Name = *u.Name
+	}
+	if u.Description != nil {
+		p.Description = *u.Description
+	}
+	if u.Permissions != nil {
+		p.Permissions = *u.Permissions
+	}
+}
+
+// AuthorizationPresetService stores the authorization presets of organizations.
+type AuthorizationPresetService interface {
+	// CreateAuthorizationPreset creates a preset. Names are unique in an
+	// organization.
+	CreateAuthorizationPreset(ctx context.Context, p *AuthorizationPreset) error
+
+	// FindAuthorizationPresetByID returns a single preset by ID.
+	FindAuthorizationPresetByID(ctx context.Context, id ID) (*AuthorizationPreset, error)
+
+	// FindAuthorizationPresets returns a list of presets that match filter
+	// and the total count of matching presets.
+	FindAuthorizationPresets(ctx context.Context, filter AuthorizationPresetFilter, opt ...FindOptions) ([]*AuthorizationPreset, int, error)
+
+	// UpdateAuthorizationPreset updates a single preset with changeset.
+	// Authorizations already created with the preset keep their permissions.
+	UpdateAuthorizationPreset(ctx context.Context, id ID, upd AuthorizationPresetUpdate) (*AuthorizationPreset, error)
+
+	// DeleteAuthorizationPreset removes a single preset by ID.
+	DeleteAuthorizationPreset(ctx context.Context, id ID) error
+}
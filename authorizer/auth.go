@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 )
 
 var _ influxdb.AuthorizationService = (*AuthorizationService)(nil)
@@ -74,6 +75,9 @@ func (s *AuthorizationService) CreateAuthorization(ctx context.Context, a *influ
 	if err := VerifyPermissions(ctx, a.Permissions); err != nil {
 		return err
 	}
+	if err := VerifyRowFilters(ctx, a.RowFilters); err != nil {
+		return err
+	}
 	return s.s.CreateAuthorization(ctx, a)
 }
 
@@ -89,6 +93,9 @@ func (s *AuthorizationService) UpdateAuthorization(ctx context.Context, id influ
 	if _, _, err := AuthorizeWriteResource(ctx, influxdb.UsersResourceType, a.UserID); err != nil {
 		return nil, err
 	}
+	if err := AuthorizeUpdateRowFilters(ctx, upd); err != nil {
+		return nil, err
+	}
 	return s.s.UpdateAuthorization(ctx, id, upd)
 }
 
@@ -120,3 +127,52 @@ func VerifyPermissions(ctx context.Context, ps []influxdb.Permission) error {
 	}
 	return nil
 }
+
+// VerifyRowFilters ensures that an authorization is restricted by every row
+// filter of the authorizer on context, so that it cannot read rows the
+// authorizer cannot.
+func VerifyRowFilters(ctx context.Context, filters []influxdb.TagRule) error {
+	for _, rf := range authorizerRowFilters(ctx) {
+		if !hasTagRule(filters, rf) {
+			return &influxdb.Error{
+				Msg:  fmt.Sprintf("row filter on tag %s is required", rf.Key),
+				Code: influxdb.EForbidden,
+			}
+		}
+	}
+	return nil
+}
+
+// AuthorizeUpdateRowFilters ensures that the row filters of an authorization
+// are only updated by an authorizer that is not restricted by row filters.
+func AuthorizeUpdateRowFilters(ctx context.Context, upd *influxdb.AuthorizationUpdate) error {
+	if upd.RowFilters == nil || len(authorizerRowFilters(ctx)) == 0 {
+		return nil
+	}
+	return &influxdb.Error{
+		Msg:  "row filters cannot be updated with a token restricted by row filters",
+		Code: influxdb.EForbidden,
+	}
+}
+
+// authorizerRowFilters returns the row filters of the authorizer on context,
+// if any.
+func authorizerRowFilters(ctx context.Context) []influxdb.TagRule {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil
+	}
+	if auth, ok := a.(*influxdb.Authorization); ok {
+		return auth.RowFilters
+	}
+	return nil
+}
+
+func hasTagRule(rules []influxdb.TagRule, rule influxdb.TagRule) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
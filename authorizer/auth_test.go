@@ -436,3 +436,103 @@ func TestAuthorizationService_CreateAuthorization(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthorizationService_RowFilters(t *testing.T) {
+	tenant := influxdb.TagRule{Tag: influxdb.Tag{Key: "tenant", Value: "acme"}}
+	region := influxdb.TagRule{Tag: influxdb.Tag{Key: "region", Value: "eu"}}
+	permissions := []influxdb.Permission{
+		{
+			Action: influxdb.WriteAction,
+			Resource: influxdb.Resource{
+				Type:  influxdb.AuthorizationsResourceType,
+				OrgID: influxdbtesting.IDPtr(1),
+			},
+		},
+		{
+			Action: influxdb.WriteAction,
+			Resource: influxdb.Resource{
+				Type: influxdb.UsersResourceType,
+				ID:   influxdbtesting.IDPtr(1),
+			},
+		},
+	}
+	forbidden := func(msg string) error {
+		return &influxdb.Error{
+			Msg:  msg,
+			Code: influxdb.EForbidden,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		rowFilters []influxdb.TagRule
+		create     []influxdb.TagRule
+		update     *[]influxdb.TagRule
+		wantCreate error
+		wantUpdate error
+	}{
+		{
+			name:   "unrestricted token sets any row filters",
+			create: []influxdb.TagRule{region},
+			update: &[]influxdb.TagRule{},
+		},
+		{
+			name:       "restricted token keeps its row filters",
+			rowFilters: []influxdb.TagRule{tenant},
+			create:     []influxdb.TagRule{region, tenant},
+		},
+		{
+			name:       "restricted token creates token without row filters",
+			rowFilters: []influxdb.TagRule{tenant},
+			wantCreate: forbidden("row filter on tag tenant is required"),
+		},
+		{
+			name:       "restricted token creates token with other row filters",
+			rowFilters: []influxdb.TagRule{tenant},
+			create:     []influxdb.TagRule{region},
+			wantCreate: forbidden("row filter on tag tenant is required"),
+		},
+		{
+			name:       "restricted token removes row filters",
+			rowFilters: []influxdb.TagRule{tenant},
+			create:     []influxdb.TagRule{tenant},
+			update:     &[]influxdb.TagRule{},
+			wantUpdate: forbidden("row filters cannot be updated with a token restricted by row filters"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mock.AuthorizationService{}
+			m.FindAuthorizationByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+				return &influxdb.Authorization{
+					ID:     id,
+					UserID: 1,
+					OrgID:  1,
+				}, nil
+			}
+			m.CreateAuthorizationFn = func(ctx context.Context, a *influxdb.Authorization) error {
+				return nil
+			}
+			m.UpdateAuthorizationFn = func(ctx context.Context, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
+				return nil, nil
+			}
+			s := authorizer.NewAuthorizationService(m)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+				ID:          20,
+				Status:      influxdb.Active,
+				OrgID:       1,
+				UserID:      1,
+				Permissions: permissions,
+				RowFilters:  tt.rowFilters,
+			})
+
+			err := s.CreateAuthorization(ctx, &influxdb.Authorization{OrgID: 1, UserID: 1, RowFilters: tt.create})
+			influxdbtesting.ErrorsEqual(t, err, tt.wantCreate)
+
+			_, err = s.UpdateAuthorization(ctx, 10, &influxdb.AuthorizationUpdate{RowFilters: tt.update})
+			influxdbtesting.ErrorsEqual(t, err, tt.wantUpdate)
+		})
+	}
+}
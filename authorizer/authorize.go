@@ -8,25 +8,26 @@ import (
 	icontext "github.com/influxdata/influxdb/v2/context"
 )
 
-func isAllowedAll(a influxdb.Authorizer, permissions []influxdb.Permission) error {
+func isAllowedAll(ctx context.Context, a influxdb.Authorizer, permissions []influxdb.Permission) error {
 	pset, err := a.PermissionSet()
 	if err != nil {
 		return err
 	}
 
 	for _, p := range permissions {
-		if !pset.Allowed(p) {
-			return &influxdb.Error{
-				Code: influxdb.EUnauthorized,
-				Msg:  fmt.Sprintf("%s is unauthorized", p),
-			}
+		ok, err := allowed(ctx, a, pset, p)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errUnauthorized(p)
 		}
 	}
 	return nil
 }
 
-func isAllowed(a influxdb.Authorizer, p influxdb.Permission) error {
-	return isAllowedAll(a, []influxdb.Permission{p})
+func isAllowed(ctx context.Context, a influxdb.Authorizer, p influxdb.Permission) error {
+	return isAllowedAll(ctx, a, []influxdb.Permission{p})
 }
 
 // IsAllowedAll checks to see if an action is authorized by ALL permissions.
@@ -36,7 +37,7 @@ func IsAllowedAll(ctx context.Context, permissions []influxdb.Permission) error
 	if err != nil {
 		return err
 	}
-	return isAllowedAll(a, permissions)
+	return isAllowedAll(ctx, a, permissions)
 }
 
 // IsAllowed checks to see if an action is authorized by retrieving the authorizer
@@ -57,7 +58,11 @@ func IsAllowedAny(ctx context.Context, permissions []influxdb.Permission) error
 		return err
 	}
 	for _, p := range permissions {
-		if pset.Allowed(p) {
+		ok, err := allowed(ctx, a, pset, p)
+		if err != nil {
+			return err
+		}
+		if ok {
 			return nil
 		}
 	}
@@ -86,12 +91,12 @@ func authorize(ctx context.Context, a influxdb.Action, rt influxdb.ResourceType,
 	if err != nil {
 		return nil, influxdb.Permission{}, err
 	}
-	return auth, *p, isAllowed(auth, *p)
+	return auth, *p, isAllowed(ctx, auth, *p)
 }
 
-func authorizeReadSystemBucket(ctx context.Context, bid, oid influxdb.ID) (influxdb.Authorizer, influxdb.Permission, error) {
+func authorizeReadSystemBucket(ctx context.Context, sb influxdb.SystemBuckets, bid, oid influxdb.ID) (influxdb.Authorizer, influxdb.Permission, error) {
 	// HACK: remove once system buckets are migrated away from hard coded values
-	if !oid.Valid() && (bid == influxdb.TasksSystemBucketID || bid == influxdb.MonitoringSystemBucketID) {
+	if !oid.Valid() && sb.IsSystemBucketID(bid) {
 		a, _ := icontext.GetAuthorizer(ctx)
 		return a, influxdb.Permission{}, nil
 	}
@@ -102,11 +107,12 @@ func authorizeReadSystemBucket(ctx context.Context, bid, oid influxdb.ID) (influ
 // I.e., instead of:
 //  AuthorizeRead(ctx, influxdb.BucketsResourceType, b.ID, b.OrgID)
 // use:
-//  AuthorizeReadBucket(ctx, b.Type, b.ID, b.OrgID)
-func AuthorizeReadBucket(ctx context.Context, bt influxdb.BucketType, bid, oid influxdb.ID) (influxdb.Authorizer, influxdb.Permission, error) {
+//  AuthorizeReadBucket(ctx, sb, b.Type, b.ID, b.OrgID)
+// The system buckets sb are those served to organizations that have none stored.
+func AuthorizeReadBucket(ctx context.Context, sb influxdb.SystemBuckets, bt influxdb.BucketType, bid, oid influxdb.ID) (influxdb.Authorizer, influxdb.Permission, error) {
 	switch bt {
 	case influxdb.BucketTypeSystem:
-		return authorizeReadSystemBucket(ctx, bid, oid)
+		return authorizeReadSystemBucket(ctx, sb, bid, oid)
 	default:
 		return AuthorizeRead(ctx, influxdb.BucketsResourceType, bid, oid)
 	}
@@ -124,6 +130,20 @@ func AuthorizeWrite(ctx context.Context, rt influxdb.ResourceType, rid, oid infl
 	return authorize(ctx, influxdb.WriteAction, rt, &rid, &oid)
 }
 
+// AuthorizeReadRestricted authorizes the user in the context to read the specified resource (identified by its type, ID, and orgID).
+// NOTE: if the resource is restricted, authorization will pass only if the user has a specific permission for the given resource,
+// or may write the resources of the given type in its organization, as its owners and all-access tokens do.
+func AuthorizeReadRestricted(ctx context.Context, rt influxdb.ResourceType, rid, oid influxdb.ID, restricted bool) (influxdb.Authorizer, influxdb.Permission, error) {
+	if !restricted {
+		return AuthorizeRead(ctx, rt, rid, oid)
+	}
+	a, p, err := AuthorizeReadResource(ctx, rt, rid)
+	if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+		return AuthorizeWrite(ctx, rt, rid, oid)
+	}
+	return a, p, err
+}
+
 // AuthorizeRead authorizes the user in the context to read the specified resource (identified by its type, ID).
 // NOTE: authorization will pass only if the user has a specific permission for the given resource.
 func AuthorizeReadResource(ctx context.Context, rt influxdb.ResourceType, rid influxdb.ID) (influxdb.Authorizer, influxdb.Permission, error) {
@@ -24,6 +24,96 @@ func AuthorizeFindDBRPs(ctx context.Context, rs []*influxdb.DBRPMappingV2) ([]*i
 	return rrs, len(rrs), nil
 }
 
+// AuthorizeFindLegalHolds takes the given items and returns only the ones that the user is authorized to read.
+func AuthorizeFindLegalHolds(ctx context.Context, rs []*influxdb.LegalHold) ([]*influxdb.LegalHold, int, error) {
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	rrs := rs[:0]
+	for _, r := range rs {
+		_, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, r.BucketID, r.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		rrs = append(rrs, r)
+	}
+	return rrs, len(rrs), nil
+}
+
+// AuthorizeFindBucketRollups takes the given items and returns only the ones that the user is authorized to read.
+func AuthorizeFindBucketRollups(ctx context.Context, rs []*influxdb.BucketRollup) ([]*influxdb.BucketRollup, int, error) {
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	rrs := rs[:0]
+	for _, r := range rs {
+		_, _, err := AuthorizeRead(ctx, influxdb.BucketsResourceType, r.BucketID, r.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		rrs = append(rrs, r)
+	}
+	return rrs, len(rrs), nil
+}
+
+// AuthorizeFindFluxPackages takes the given items and returns only the ones that the user is authorized to read.
+func AuthorizeFindFluxPackages(ctx context.Context, rs []*influxdb.FluxPackage) ([]*influxdb.FluxPackage, int, error) {
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	rrs := rs[:0]
+	for _, r := range rs {
+		_, _, err := AuthorizeRead(ctx, influxdb.FluxPackagesResourceType, r.ID, r.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		rrs = append(rrs, r)
+	}
+	return rrs, len(rrs), nil
+}
+
+// AuthorizeFindAuthorizationPresets takes the given items and returns only the ones that the user is authorized to read.
+func AuthorizeFindAuthorizationPresets(ctx context.Context, rs []*influxdb.AuthorizationPreset) ([]*influxdb.AuthorizationPreset, int, error) {
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	rrs := rs[:0]
+	for _, r := range rs {
+		_, _, err := AuthorizeReadOrg(ctx, r.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		rrs = append(rrs, r)
+	}
+	return rrs, len(rrs), nil
+}
+
+// AuthorizeFindSavedQueries takes the given items and returns only the ones that the user is authorized to read.
+func AuthorizeFindSavedQueries(ctx context.Context, rs []*influxdb.SavedQuery) ([]*influxdb.SavedQuery, int, error) {
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	rrs := rs[:0]
+	for _, r := range rs {
+		_, _, err := AuthorizeReadRestricted(ctx, influxdb.SavedQueriesResourceType, r.ID, r.OrgID, r.Restricted())
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		rrs = append(rrs, r)
+	}
+	return rrs, len(rrs), nil
+}
+
 // AuthorizeFindAuthorizations takes the given items and returns only the ones that the user is authorized to read.
 func AuthorizeFindAuthorizations(ctx context.Context, rs []*influxdb.Authorization) ([]*influxdb.Authorization, int, error) {
 	// This filters without allocating
@@ -50,12 +140,12 @@ func AuthorizeFindAuthorizations(ctx context.Context, rs []*influxdb.Authorizati
 }
 
 // AuthorizeFindBuckets takes the given items and returns only the ones that the user is authorized to read.
-func AuthorizeFindBuckets(ctx context.Context, rs []*influxdb.Bucket) ([]*influxdb.Bucket, int, error) {
+func AuthorizeFindBuckets(ctx context.Context, sb influxdb.SystemBuckets, rs []*influxdb.Bucket) ([]*influxdb.Bucket, int, error) {
 	// This filters without allocating
 	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
 	rrs := rs[:0]
 	for _, r := range rs {
-		_, _, err := AuthorizeReadBucket(ctx, r.Type, r.ID, r.OrgID)
+		_, _, err := AuthorizeReadBucket(ctx, sb, r.Type, r.ID, r.OrgID)
 		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
 			return nil, 0, err
 		}
@@ -73,7 +163,7 @@ func AuthorizeFindDashboards(ctx context.Context, rs []*influxdb.Dashboard) ([]*
 	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
 	rrs := rs[:0]
 	for _, r := range rs {
-		_, _, err := AuthorizeRead(ctx, influxdb.DashboardsResourceType, r.ID, r.OrganizationID)
+		_, _, err := AuthorizeReadRestricted(ctx, influxdb.DashboardsResourceType, r.ID, r.OrganizationID, r.Restricted)
 		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
 			return nil, 0, err
 		}
@@ -181,7 +271,7 @@ func AuthorizeFindVariables(ctx context.Context, rs []*influxdb.Variable) ([]*in
 	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
 	rrs := rs[:0]
 	for _, r := range rs {
-		_, _, err := AuthorizeRead(ctx, influxdb.VariablesResourceType, r.ID, r.OrganizationID)
+		_, _, err := AuthorizeReadRestricted(ctx, influxdb.VariablesResourceType, r.ID, r.OrganizationID, r.Restricted)
 		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
 			return nil, 0, err
 		}
@@ -12,13 +12,17 @@ var _ influxdb.BucketService = (*BucketService)(nil)
 // BucketService wraps a influxdb.BucketService and authorizes actions
 // against it appropriately.
 type BucketService struct {
-	s influxdb.BucketService
+	s             influxdb.BucketService
+	systemBuckets influxdb.SystemBuckets
 }
 
 // NewBucketService constructs an instance of an authorizing bucket serivce.
-func NewBucketService(s influxdb.BucketService) *BucketService {
+// The system buckets sb are those served by s to organizations that have
+// none stored.
+func NewBucketService(s influxdb.BucketService, sb influxdb.SystemBuckets) *BucketService {
 	return &BucketService{
-		s: s,
+		s:             s,
+		systemBuckets: sb,
 	}
 }
 
@@ -31,7 +35,7 @@ func (s *BucketService) FindBucketByID(ctx context.Context, id influxdb.ID) (*in
 	if err != nil {
 		return nil, err
 	}
-	if _, _, err := AuthorizeReadBucket(ctx, b.Type, b.ID, b.OrgID); err != nil {
+	if _, _, err := AuthorizeReadBucket(ctx, s.systemBuckets, b.Type, b.ID, b.OrgID); err != nil {
 		return nil, err
 	}
 	return b, nil
@@ -46,7 +50,7 @@ func (s *BucketService) FindBucketByName(ctx context.Context, orgID influxdb.ID,
 	if err != nil {
 		return nil, err
 	}
-	if _, _, err := AuthorizeReadBucket(ctx, b.Type, b.ID, b.OrgID); err != nil {
+	if _, _, err := AuthorizeReadBucket(ctx, s.systemBuckets, b.Type, b.ID, b.OrgID); err != nil {
 		return nil, err
 	}
 	return b, nil
@@ -61,7 +65,7 @@ func (s *BucketService) FindBucket(ctx context.Context, filter influxdb.BucketFi
 	if err != nil {
 		return nil, err
 	}
-	if _, _, err := AuthorizeReadBucket(ctx, b.Type, b.ID, b.OrgID); err != nil {
+	if _, _, err := AuthorizeReadBucket(ctx, s.systemBuckets, b.Type, b.ID, b.OrgID); err != nil {
 		return nil, err
 	}
 	return b, nil
@@ -78,7 +82,7 @@ func (s *BucketService) FindBuckets(ctx context.Context, filter influxdb.BucketF
 	if err != nil {
 		return nil, 0, err
 	}
-	return AuthorizeFindBuckets(ctx, bs)
+	return AuthorizeFindBuckets(ctx, s.systemBuckets, bs)
 }
 
 // CreateBucket checks to see if the authorizer on context has write access to the global buckets resource.
@@ -27,7 +27,7 @@ func (s *DashboardService) FindDashboardByID(ctx context.Context, id influxdb.ID
 	if err != nil {
 		return nil, err
 	}
-	if _, _, err := AuthorizeRead(ctx, influxdb.DashboardsResourceType, id, b.OrganizationID); err != nil {
+	if _, _, err := AuthorizeReadRestricted(ctx, influxdb.DashboardsResourceType, id, b.OrganizationID, b.Restricted); err != nil {
 		return nil, err
 	}
 	return b, nil
@@ -114,7 +114,7 @@ func (s *DashboardService) GetDashboardCellView(ctx context.Context, dashboardID
 	if err != nil {
 		return nil, err
 	}
-	if _, _, err := AuthorizeRead(ctx, influxdb.DashboardsResourceType, dashboardID, b.OrganizationID); err != nil {
+	if _, _, err := AuthorizeReadRestricted(ctx, influxdb.DashboardsResourceType, dashboardID, b.OrganizationID, b.Restricted); err != nil {
 		return nil, err
 	}
 	return s.s.GetDashboardCellView(ctx, dashboardID, cellID)
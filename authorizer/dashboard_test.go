@@ -115,6 +115,54 @@ func TestDashboardService_FindDashboardByID(t *testing.T) {
 	}
 }
 
+func TestDashboardService_FindDashboardByID_Restricted(t *testing.T) {
+	orgID := influxdb.ID(10)
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		wantCode   string
+	}{
+		{
+			name: "member of the dashboard can read it",
+			permission: influxdb.Permission{
+				Action:   influxdb.ReadAction,
+				Resource: influxdb.Resource{Type: influxdb.DashboardsResourceType, ID: influxdbtesting.IDPtr(1)},
+			},
+		},
+		{
+			name: "reader of the dashboards of the org cannot read it",
+			permission: influxdb.Permission{
+				Action:   influxdb.ReadAction,
+				Resource: influxdb.Resource{Type: influxdb.DashboardsResourceType, OrgID: &orgID},
+			},
+			wantCode: influxdb.EUnauthorized,
+		},
+		{
+			name: "writer of the dashboards of the org can read it",
+			permission: influxdb.Permission{
+				Action:   influxdb.WriteAction,
+				Resource: influxdb.Resource{Type: influxdb.DashboardsResourceType, OrgID: &orgID},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewDashboardService(&mock.DashboardService{
+				FindDashboardByIDF: func(ctx context.Context, id influxdb.ID) (*influxdb.Dashboard, error) {
+					return &influxdb.Dashboard{ID: id, OrganizationID: orgID, Restricted: true}, nil
+				},
+			})
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, []influxdb.Permission{tt.permission}))
+			_, err := s.FindDashboardByID(ctx, 1)
+			if code := influxdb.ErrorCode(err); code != tt.wantCode {
+				t.Errorf("expected error code %q, got %v", tt.wantCode, err)
+			}
+		})
+	}
+}
+
 func TestDashboardService_FindDashboards(t *testing.T) {
 	type fields struct {
 		DashboardService influxdb.DashboardService
@@ -0,0 +1,59 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// FrozenOrgFinder finds the organizations whose freeze is enforced.
+type FrozenOrgFinder interface {
+	FindOrganizationByID(ctx context.Context, id influxdb.ID) (*influxdb.Organization, error)
+}
+
+type frozenOrgFinderKey struct{}
+
+// WithFrozenOrgFinder returns a context in which the permission checks reject
+// the writes to the resources of the frozen organizations found by f. The
+// writes of points and deletes of data are rejected by the storage instead,
+// whichever path they take.
+func WithFrozenOrgFinder(ctx context.Context, f FrozenOrgFinder) context.Context {
+	return context.WithValue(ctx, frozenOrgFinderKey{}, f)
+}
+
+// checkOrgFrozen returns an error if the write p is to a resource of a
+// frozen organization. The permissions of resources without organization,
+// such as the global permissions of operators, are not rejected, so that
+// operators unfreeze organizations.
+func checkOrgFrozen(ctx context.Context, p influxdb.Permission) error {
+	frozenOrgs, _ := ctx.Value(frozenOrgFinderKey{}).(FrozenOrgFinder)
+	if frozenOrgs == nil {
+		return nil
+	}
+
+	var orgID influxdb.ID
+	switch {
+	case p.Resource.OrgID != nil:
+		orgID = *p.Resource.OrgID
+	case p.Resource.Type == influxdb.OrgsResourceType && p.Resource.ID != nil:
+		orgID = *p.Resource.ID
+	default:
+		return nil
+	}
+	if !orgID.Valid() {
+		return nil
+	}
+
+	o, err := frozenOrgs.FindOrganizationByID(ctx, orgID)
+	if err != nil {
+		// The organization is not found by the write either.
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil
+		}
+		return err
+	}
+	if o.Frozen() {
+		return influxdb.ErrOrgFrozen(o)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+)
+
+func TestFrozenOrg(t *testing.T) {
+	frozenID, activeID := influxdb.ID(1), influxdb.ID(2)
+	orgSvc := mock.NewOrganizationService()
+	orgSvc.FindOrganizationByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.Organization, error) {
+		o := &influxdb.Organization{ID: id, Name: "org"}
+		if id == frozenID {
+			o.Freeze = &influxdb.OrgFreeze{Reason: "unpaid", FrozenAt: time.Now()}
+		}
+		return o, nil
+	}
+
+	var perms []influxdb.Permission
+	for _, id := range []influxdb.ID{frozenID, activeID} {
+		ps, err := influxdb.NewPermissionAtID(id, influxdb.WriteAction, influxdb.OrgsResourceType, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		perms = append(perms, *ps)
+		for _, a := range []influxdb.Action{influxdb.ReadAction, influxdb.WriteAction} {
+			p, err := influxdb.NewPermission(a, influxdb.BucketsResourceType, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			perms = append(perms, *p)
+		}
+	}
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, perms))
+	ctx = authorizer.WithFrozenOrgFinder(ctx, orgSvc)
+
+	tests := []struct {
+		name      string
+		authorize func() error
+		code      string
+	}{
+		{
+			name: "reads of frozen orgs",
+			authorize: func() error {
+				_, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, 3, frozenID)
+				return err
+			},
+		},
+		{
+			name: "writes to frozen orgs",
+			authorize: func() error {
+				_, _, err := authorizer.AuthorizeWrite(ctx, influxdb.BucketsResourceType, 3, frozenID)
+				return err
+			},
+			code: influxdb.EForbidden,
+		},
+		{
+			name: "changes to frozen orgs",
+			authorize: func() error {
+				_, _, err := authorizer.AuthorizeWriteOrg(ctx, frozenID)
+				return err
+			},
+			code: influxdb.EForbidden,
+		},
+		{
+			name: "writes to active orgs",
+			authorize: func() error {
+				_, _, err := authorizer.AuthorizeWrite(ctx, influxdb.BucketsResourceType, 3, activeID)
+				return err
+			},
+		},
+		{
+			name: "unauthorized writes to frozen orgs",
+			authorize: func() error {
+				_, _, err := authorizer.AuthorizeWrite(ctx, influxdb.DashboardsResourceType, 3, frozenID)
+				return err
+			},
+			code: influxdb.EUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := influxdb.ErrorCode(tt.authorize()); code != tt.code {
+				t.Errorf("expected error code %q, got %q", tt.code, code)
+			}
+		})
+	}
+}
@@ -135,3 +135,22 @@ func (s *LabelService) DeleteLabelMapping(ctx context.Context, m *influxdb.Label
 	}
 	return s.s.DeleteLabelMapping(ctx, m)
 }
+
+// ApplyLabelMappings checks to see if the authorizer on context has write access to the label and every resource of the batch.
+func (s *LabelService) ApplyLabelMappings(ctx context.Context, b *influxdb.LabelMappingBatch) error {
+	l, err := s.s.FindLabelByID(ctx, b.LabelID)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWrite(ctx, influxdb.LabelsResourceType, b.LabelID, l.OrgID); err != nil {
+		return err
+	}
+	for _, ms := range [][]influxdb.LabelMapping{b.Add, b.Remove} {
+		for _, m := range ms {
+			if _, _, err := AuthorizeWrite(ctx, m.ResourceType, m.ResourceID, l.OrgID); err != nil {
+				return err
+			}
+		}
+	}
+	return s.s.ApplyLabelMappings(ctx, b)
+}
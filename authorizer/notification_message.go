@@ -0,0 +1,37 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.NotificationMessageCatalogService = (*NotificationMessageCatalogService)(nil)
+
+// NotificationMessageCatalogService wraps a influxdb.NotificationMessageCatalogService
+// and authorizes actions against it appropriately. The message catalog of an
+// organization is authorized as its notification rules.
+type NotificationMessageCatalogService struct {
+	s influxdb.NotificationMessageCatalogService
+}
+
+// NewNotificationMessageCatalogService constructs an instance of an authorizing notification message catalog service.
+func NewNotificationMessageCatalogService(s influxdb.NotificationMessageCatalogService) *NotificationMessageCatalogService {
+	return &NotificationMessageCatalogService{s: s}
+}
+
+// FindNotificationMessageCatalog checks to see if the authorizer on context has read access to the notification rules of the organization.
+func (s *NotificationMessageCatalogService) FindNotificationMessageCatalog(ctx context.Context, orgID influxdb.ID) (*influxdb.NotificationMessageCatalog, error) {
+	if _, _, err := AuthorizeOrgReadResource(ctx, influxdb.NotificationRuleResourceType, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindNotificationMessageCatalog(ctx, orgID)
+}
+
+// PutNotificationMessageCatalog checks to see if the authorizer on context has write access to the notification rules of the organization.
+func (s *NotificationMessageCatalogService) PutNotificationMessageCatalog(ctx context.Context, c *influxdb.NotificationMessageCatalog) error {
+	if _, _, err := AuthorizeOrgWriteResource(ctx, influxdb.NotificationRuleResourceType, c.OrgID); err != nil {
+		return err
+	}
+	return s.s.PutNotificationMessageCatalog(ctx, c)
+}
@@ -0,0 +1,37 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.NotificationRoutingService = (*NotificationRoutingService)(nil)
+
+// NotificationRoutingService wraps a influxdb.NotificationRoutingService and
+// authorizes actions against it appropriately. The routing table of an
+// organization is authorized as its notification rules.
+type NotificationRoutingService struct {
+	s influxdb.NotificationRoutingService
+}
+
+// NewNotificationRoutingService constructs an instance of an authorizing notification routing service.
+func NewNotificationRoutingService(s influxdb.NotificationRoutingService) *NotificationRoutingService {
+	return &NotificationRoutingService{s: s}
+}
+
+// FindNotificationRoutingTable checks to see if the authorizer on context has read access to the notification rules of the organization.
+func (s *NotificationRoutingService) FindNotificationRoutingTable(ctx context.Context, orgID influxdb.ID) (*influxdb.NotificationRoutingTable, error) {
+	if _, _, err := AuthorizeOrgReadResource(ctx, influxdb.NotificationRuleResourceType, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindNotificationRoutingTable(ctx, orgID)
+}
+
+// PutNotificationRoutingTable checks to see if the authorizer on context has write access to the notification rules of the organization.
+func (s *NotificationRoutingService) PutNotificationRoutingTable(ctx context.Context, t *influxdb.NotificationRoutingTable) error {
+	if _, _, err := AuthorizeOrgWriteResource(ctx, influxdb.NotificationRuleResourceType, t.OrgID); err != nil {
+		return err
+	}
+	return s.s.PutNotificationRoutingTable(ctx, t)
+}
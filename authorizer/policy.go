@@ -0,0 +1,86 @@
+package authorizer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// PolicyInput is a permission check delegated to a policy engine.
+type PolicyInput struct {
+	Authorizer influxdb.Authorizer
+	Permission influxdb.Permission
+	// Allowed is true if the permissions of the authorizer allow the
+	// permission, so that a policy can restrict them or decide on its own.
+	Allowed bool
+}
+
+// PolicyEngine decides permission checks with a policy external to
+// InfluxDB, such as an Open Policy Agent or a centralized policy service.
+type PolicyEngine interface {
+	// Allowed returns true if the policy allows the permission of in. An
+	// error is returned if no decision could be made, in which case the
+	// permission is denied.
+	Allowed(ctx context.Context, in PolicyInput) (bool, error)
+}
+
+type policyEngineKey struct{}
+
+// WithPolicyEngine returns a context in which the permission checks are
+// delegated to e. The checks are decided by the permissions of the
+// authorizers in the contexts without a policy engine, and ctx is returned
+// as is if e is nil.
+func WithPolicyEngine(ctx context.Context, e PolicyEngine) context.Context {
+	if e == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, policyEngineKey{}, e)
+}
+
+// ErrPolicyUnavailable is used when the policy engine fails to decide a
+// permission check. The check fails closed.
+func ErrPolicyUnavailable(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EUnavailable,
+		Msg:  "policy engine is unavailable, permission denied",
+		Err:  err,
+	}
+}
+
+// allowed returns true if a, whose permissions are pset, is allowed p by
+// its permissions, or by the policy engine of ctx if any. Allowed writes to
+// the resources of frozen organizations are rejected with an error.
+func allowed(ctx context.Context, a influxdb.Authorizer, pset influxdb.PermissionSet, p influxdb.Permission) (bool, error) {
+	ok := pset.Allowed(p)
+	if e, _ := ctx.Value(policyEngineKey{}).(PolicyEngine); e != nil {
+		var err error
+		ok, err = e.Allowed(ctx, PolicyInput{
+			Authorizer: a,
+			Permission: p,
+			Allowed:    ok,
+		})
+		if err != nil {
+			return false, ErrPolicyUnavailable(err)
+		}
+	}
+	if ok && p.Action == influxdb.WriteAction {
+		if err := checkOrgFrozen(ctx, p); err != nil {
+			return false, err
+		}
+	}
+	return ok, nil
+}
+
+// IsAllowedFor checks to see if the authorizer a, instead of the one of the
+// context, is authorized p.
+func IsAllowedFor(ctx context.Context, a influxdb.Authorizer, p influxdb.Permission) error {
+	return isAllowed(ctx, a, p)
+}
+
+func errUnauthorized(p influxdb.Permission) error {
+	return &influxdb.Error{
+		Code: influxdb.EUnauthorized,
+		Msg:  fmt.Sprintf("%s is unauthorized", p),
+	}
+}
@@ -0,0 +1,71 @@
+package authorizer_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+)
+
+type policyEngineFunc func(ctx context.Context, in authorizer.PolicyInput) (bool, error)
+
+func (f policyEngineFunc) Allowed(ctx context.Context, in authorizer.PolicyInput) (bool, error) {
+	return f(ctx, in)
+}
+
+func TestPolicyEngine(t *testing.T) {
+	orgID := influxdb.ID(1)
+	read := influxdb.Permission{
+		Action:   influxdb.ReadAction,
+		Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &orgID},
+	}
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, []influxdb.Permission{read}))
+
+	tests := []struct {
+		name   string
+		engine policyEngineFunc
+		code   string
+	}{
+		{
+			name: "restricts the permissions",
+			engine: func(_ context.Context, in authorizer.PolicyInput) (bool, error) {
+				return in.Allowed && in.Permission.Action == influxdb.WriteAction, nil
+			},
+			code: influxdb.EUnauthorized,
+		},
+		{
+			name: "grants permissions",
+			engine: func(_ context.Context, in authorizer.PolicyInput) (bool, error) {
+				return true, nil
+			},
+		},
+		{
+			name: "fails closed",
+			engine: func(_ context.Context, in authorizer.PolicyInput) (bool, error) {
+				return true, errors.New("connection refused")
+			},
+			code: influxdb.EUnavailable,
+		},
+	}
+	t.Run("permissions of the authorizer without engine", func(t *testing.T) {
+		if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, 2, orgID); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.BucketsResourceType, 2, orgID); influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			t.Errorf("expected an unauthorized error, got %v", err)
+		}
+	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := authorizer.WithPolicyEngine(ctx, tt.engine)
+			_, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, 2, orgID)
+			if code := influxdb.ErrorCode(err); code != tt.code {
+				t.Errorf("expected error code %q, got %v", tt.code, err)
+			}
+		})
+	}
+}
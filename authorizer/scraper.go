@@ -59,6 +59,9 @@ func (s *ScraperTargetStoreService) AddTarget(ctx context.Context, st *influxdb.
 	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, st.BucketID, st.OrgID); err != nil {
 		return err
 	}
+	if err := authorizeDiscovery(ctx, st); err != nil {
+		return err
+	}
 	return s.s.AddTarget(ctx, st, userID)
 }
 
@@ -74,6 +77,9 @@ func (s *ScraperTargetStoreService) UpdateTarget(ctx context.Context, upd *influ
 	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, st.BucketID, st.OrgID); err != nil {
 		return nil, err
 	}
+	if err := authorizeDiscovery(ctx, upd); err != nil {
+		return nil, err
+	}
 	return s.s.UpdateTarget(ctx, upd, userID)
 }
 
@@ -88,3 +94,20 @@ func (s *ScraperTargetStoreService) RemoveTarget(ctx context.Context, id influxd
 	}
 	return s.s.RemoveTarget(ctx, id)
 }
+
+// authorizeDiscovery checks that only operators configure targets discovered
+// with kubernetes, since the service account of the server can see the
+// workloads of every organization.
+func authorizeDiscovery(ctx context.Context, st *influxdb.ScraperTarget) error {
+	if st.Discovery == nil || st.Discovery.Type != influxdb.ScraperDiscoveryKubernetes {
+		return nil
+	}
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  "only operators can configure kubernetes discovery of scraper targets",
+			Err:  err,
+		}
+	}
+	return nil
+}
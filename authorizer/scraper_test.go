@@ -524,6 +524,7 @@ func TestScraperTargetStoreService_AddTarget(t *testing.T) {
 		permissions []influxdb.Permission
 		orgID       influxdb.ID
 		bucketID    influxdb.ID
+		discovery   *influxdb.ScraperDiscovery
 	}
 	type wants struct {
 		err error
@@ -640,6 +641,68 @@ func TestScraperTargetStoreService_AddTarget(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "unauthorized to discover kubernetes targets",
+			fields: fields{
+				ScraperTargetStoreService: &mock.ScraperTargetStoreService{
+					AddTargetF: func(ctx context.Context, st *influxdb.ScraperTarget, userID influxdb.ID) error {
+						return nil
+					},
+				},
+			},
+			args: args{
+				orgID:    10,
+				bucketID: 100,
+				discovery: &influxdb.ScraperDiscovery{
+					Type: influxdb.ScraperDiscoveryKubernetes,
+					Role: influxdb.KubernetesRolePod,
+				},
+				permissions: []influxdb.Permission{
+					{
+						Action: influxdb.WriteAction,
+						Resource: influxdb.Resource{
+							Type:  influxdb.ScraperResourceType,
+							OrgID: influxdbtesting.IDPtr(10),
+						},
+					},
+					{
+						Action: influxdb.WriteAction,
+						Resource: influxdb.Resource{
+							Type: influxdb.BucketsResourceType,
+							ID:   influxdbtesting.IDPtr(100),
+						},
+					},
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "only operators can configure kubernetes discovery of scraper targets",
+					Code: influxdb.EForbidden,
+				},
+			},
+		},
+		{
+			name: "authorized to discover kubernetes targets",
+			fields: fields{
+				ScraperTargetStoreService: &mock.ScraperTargetStoreService{
+					AddTargetF: func(ctx context.Context, st *influxdb.ScraperTarget, userID influxdb.ID) error {
+						return nil
+					},
+				},
+			},
+			args: args{
+				orgID:    10,
+				bucketID: 100,
+				discovery: &influxdb.ScraperDiscovery{
+					Type: influxdb.ScraperDiscoveryKubernetes,
+					Role: influxdb.KubernetesRolePod,
+				},
+				permissions: influxdb.OperPermissions(),
+			},
+			wants: wants{
+				err: nil,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -650,7 +713,7 @@ func TestScraperTargetStoreService_AddTarget(t *testing.T) {
 			ctx := context.Background()
 			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, tt.args.permissions))
 
-			err := s.AddTarget(ctx, &influxdb.ScraperTarget{OrgID: tt.args.orgID, BucketID: tt.args.bucketID}, influxdb.ID(1))
+			err := s.AddTarget(ctx, &influxdb.ScraperTarget{OrgID: tt.args.orgID, BucketID: tt.args.bucketID, Discovery: tt.args.discovery}, influxdb.ID(1))
 			influxdbtesting.ErrorsEqual(t, err, tt.wants.err)
 		})
 	}
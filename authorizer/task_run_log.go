@@ -0,0 +1,43 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.TaskRunLogSettingsService = (*TaskRunLogSettingsService)(nil)
+
+// TaskRunLogSettingsService wraps a influxdb.TaskRunLogSettingsService and
+// authorizes actions against it appropriately. The run log settings of an
+// organization are authorized as its tasks.
+type TaskRunLogSettingsService struct {
+	s influxdb.TaskRunLogSettingsService
+}
+
+// NewTaskRunLogSettingsService constructs an instance of an authorizing task run log settings service.
+func NewTaskRunLogSettingsService(s influxdb.TaskRunLogSettingsService) *TaskRunLogSettingsService {
+	return &TaskRunLogSettingsService{s: s}
+}
+
+// FindTaskRunLogSettings checks to see if the authorizer on context has read access to the tasks of the organization.
+func (s *TaskRunLogSettingsService) FindTaskRunLogSettings(ctx context.Context, orgID influxdb.ID) (*influxdb.TaskRunLogSettings, error) {
+	if _, _, err := AuthorizeOrgReadResource(ctx, influxdb.TasksResourceType, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindTaskRunLogSettings(ctx, orgID)
+}
+
+// PutTaskRunLogSettings checks to see if the authorizer on context has write access to the tasks of the organization,
+// and write access to the export bucket.
+func (s *TaskRunLogSettingsService) PutTaskRunLogSettings(ctx context.Context, settings *influxdb.TaskRunLogSettings) error {
+	if _, _, err := AuthorizeOrgWriteResource(ctx, influxdb.TasksResourceType, settings.OrgID); err != nil {
+		return err
+	}
+	if settings.ExportBucketID != nil {
+		if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, *settings.ExportBucketID, settings.OrgID); err != nil {
+			return err
+		}
+	}
+	return s.s.PutTaskRunLogSettings(ctx, settings)
+}
@@ -0,0 +1,65 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+)
+
+var _ influxdb.ResourceTransferService = (*ResourceTransferService)(nil)
+
+// ResourceTransferService wraps a influxdb.ResourceTransferService and authorizes actions
+// against it appropriately.
+type ResourceTransferService struct {
+	s          influxdb.ResourceTransferService
+	orgService OrganizationService
+}
+
+// NewResourceTransferService constructs an instance of an authorizing resource transfer service.
+func NewResourceTransferService(orgSvc OrganizationService, s influxdb.ResourceTransferService) *ResourceTransferService {
+	return &ResourceTransferService{
+		s:          s,
+		orgService: orgSvc,
+	}
+}
+
+// TransferResourceOwnership checks to see if the authorizer on context has write access to the resource,
+// and is the user it is transferred to or has write access to that user. Tasks run as their owner, so a
+// resource cannot be handed to a user without their consent.
+func (s *ResourceTransferService) TransferResourceOwnership(ctx context.Context, rt influxdb.ResourceType, id, userID influxdb.ID) error {
+	if err := s.authorizeWrite(ctx, rt, id); err != nil {
+		return err
+	}
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+	if a.GetUserID() != userID {
+		if _, _, err := AuthorizeWriteResource(ctx, influxdb.UsersResourceType, userID); err != nil {
+			return err
+		}
+	}
+	return s.s.TransferResourceOwnership(ctx, rt, id, userID)
+}
+
+// MoveResource checks to see if the authorizer on context has write access to the resource
+// and create access to resources of its type in the organization it is moved to.
+func (s *ResourceTransferService) MoveResource(ctx context.Context, rt influxdb.ResourceType, id, orgID influxdb.ID) error {
+	if err := s.authorizeWrite(ctx, rt, id); err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeCreate(ctx, rt, orgID); err != nil {
+		return err
+	}
+	return s.s.MoveResource(ctx, rt, id, orgID)
+}
+
+func (s *ResourceTransferService) authorizeWrite(ctx context.Context, rt influxdb.ResourceType, id influxdb.ID) error {
+	orgID, err := s.orgService.FindResourceOrganizationID(ctx, rt, id)
+	if err != nil {
+		return err
+	}
+	_, _, err = AuthorizeWrite(ctx, rt, id, orgID)
+	return err
+}
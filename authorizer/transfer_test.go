@@ -0,0 +1,77 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/v2/context"
+)
+
+type transferService struct {
+	influxdb.ResourceTransferService
+	transferred bool
+}
+
+func (s *transferService) TransferResourceOwnership(ctx context.Context, rt influxdb.ResourceType, id, userID influxdb.ID) error {
+	s.transferred = true
+	return nil
+}
+
+func TestResourceTransferService_TransferResourceOwnership(t *testing.T) {
+	orgID := influxdb.ID(10)
+	taskID := influxdb.ID(1)
+	owner, admin := influxdb.ID(2), influxdb.ID(3)
+	writeTask := influxdb.Permission{
+		Action:   influxdb.WriteAction,
+		Resource: influxdb.Resource{Type: influxdb.TasksResourceType, ID: &taskID, OrgID: &orgID},
+	}
+
+	tests := []struct {
+		name        string
+		authorizer  influxdb.Authorizer
+		userID      influxdb.ID
+		transferred bool
+	}{
+		{
+			name:        "to the user of the request",
+			authorizer:  &influxdb.Authorization{UserID: owner, Status: influxdb.Active, Permissions: []influxdb.Permission{writeTask}},
+			userID:      owner,
+			transferred: true,
+		},
+		{
+			name:       "to another user",
+			authorizer: &influxdb.Authorization{UserID: owner, Status: influxdb.Active, Permissions: []influxdb.Permission{writeTask}},
+			userID:     admin,
+		},
+		{
+			name: "by an operator",
+			authorizer: &influxdb.Authorization{
+				UserID:      owner,
+				Status:      influxdb.Active,
+				Permissions: influxdb.OperPermissions(),
+			},
+			userID:      admin,
+			transferred: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &transferService{}
+			s := authorizer.NewResourceTransferService(&OrgService{OrgID: orgID}, inner)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), tt.authorizer)
+			err := s.TransferResourceOwnership(ctx, influxdb.TasksResourceType, taskID, tt.userID)
+			if tt.transferred && err != nil {
+				t.Fatal(err)
+			}
+			if !tt.transferred && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+				t.Fatalf("expected unauthorized error, got %v", err)
+			}
+			if inner.transferred != tt.transferred {
+				t.Errorf("expected transferred to be %v", tt.transferred)
+			}
+		})
+	}
+}
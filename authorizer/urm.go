@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 )
 
 type OrganizationService interface {
@@ -56,9 +57,30 @@ func (s *URMService) DeleteUserResourceMapping(ctx context.Context, resourceID i
 		if _, _, err := AuthorizeWrite(ctx, urm.ResourceType, urm.ResourceID, orgID); err != nil {
 			return err
 		}
+		if err := AuthorizeForceOwnerRemoval(ctx); err != nil {
+			return err
+		}
 		if err := s.s.DeleteUserResourceMapping(ctx, urm.ResourceID, urm.UserID); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// AuthorizeForceOwnerRemoval returns an error if the request on ctx forces
+// the removal of the last owner of a resource without operator permissions,
+// see icontext.SetForceOwnerRemoval. The last owner of a resource is
+// otherwise kept by the user resource mapping service.
+func AuthorizeForceOwnerRemoval(ctx context.Context) error {
+	if !icontext.ForceOwnerRemoval(ctx) {
+		return nil
+	}
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  "only operators can force the removal of the last owner of a resource",
+			Err:  err,
+		}
+	}
+	return nil
+}
@@ -256,3 +256,48 @@ func TestURMService_WriteUserResourceMapping(t *testing.T) {
 		})
 	}
 }
+
+func TestURMService_ForceOwnerRemoval(t *testing.T) {
+	owner := &influxdb.UserResourceMapping{
+		ResourceID:   1,
+		ResourceType: influxdb.BucketsResourceType,
+		UserID:       100,
+		UserType:     influxdb.Owner,
+	}
+
+	var deleted int
+	s := authorizer.NewURMService(&OrgService{OrgID: 10}, &mock.UserResourceMappingService{
+		DeleteMappingFn: func(ctx context.Context, rid, uid influxdb.ID) error {
+			deleted++
+			return nil
+		},
+		FindMappingsFn: func(ctx context.Context, filter influxdb.UserResourceMappingFilter) ([]*influxdb.UserResourceMapping, int, error) {
+			return []*influxdb.UserResourceMapping{owner}, 1, nil
+		},
+	})
+
+	writeBuckets := influxdb.Permission{
+		Action:   influxdb.WriteAction,
+		Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: influxdbtesting.IDPtr(10)},
+	}
+	authorize := func(ctx context.Context, permissions ...influxdb.Permission) context.Context {
+		return influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, permissions))
+	}
+
+	if err := s.DeleteUserResourceMapping(authorize(context.Background(), writeBuckets), 1, 100); err != nil {
+		t.Fatalf("unexpected error removing an owner without forcing it: %v", err)
+	}
+
+	ctx := influxdbcontext.SetForceOwnerRemoval(context.Background())
+	err := s.DeleteUserResourceMapping(authorize(ctx, writeBuckets), 1, 100)
+	if influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("expected a forbidden error, got %v", err)
+	}
+
+	if err := s.DeleteUserResourceMapping(authorize(ctx, influxdb.OperPermissions()...), 1, 100); err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected the owner to be removed twice, got %d", deleted)
+	}
+}
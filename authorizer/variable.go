@@ -27,7 +27,7 @@ func (s *VariableService) FindVariableByID(ctx context.Context, id influxdb.ID)
 	if err != nil {
 		return nil, err
 	}
-	if _, _, err := AuthorizeRead(ctx, influxdb.VariablesResourceType, v.ID, v.OrganizationID); err != nil {
+	if _, _, err := AuthorizeReadRestricted(ctx, influxdb.VariablesResourceType, v.ID, v.OrganizationID, v.Restricted); err != nil {
 		return nil, err
 	}
 	return v, nil
@@ -0,0 +1,43 @@
+package authpreset
+
+import (
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrPresetNotFound is used when the specified preset cannot be found.
+	ErrPresetNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "authorization preset not found",
+	}
+
+	// ErrInvalidPresetID is used when the ID of the preset cannot be encoded.
+	ErrInvalidPresetID = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "authorization preset ID is invalid",
+	}
+
+	// ErrPresetNameConflict is used when the name of a preset is already
+	// used in its organization.
+	ErrPresetNameConflict = &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  "authorization preset name is not unique in the organization",
+	}
+)
+
+// ErrInvalidPreset is used when a service was provided an invalid preset.
+func ErrInvalidPreset(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "authorization preset provided is invalid",
+		Err:  err,
+	}
+}
+
+// ErrInternalService is used when the error comes from an internal system.
+func ErrInternalService(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
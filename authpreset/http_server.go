@@ -0,0 +1,194 @@
+package authpreset
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixAuthorizationPresets is the prefix of the authorization preset API.
+	PrefixAuthorizationPresets = "/api/v2/authorizationPresets"
+)
+
+// Handler is the HTTP API handler for authorization presets.
+type Handler struct {
+	chi.Router
+	api       *kithttp.API
+	log       *zap.Logger
+	presetSvc influxdb.AuthorizationPresetService
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, presetSvc influxdb.AuthorizationPresetService) *Handler {
+	h := &Handler{
+		api:       kithttp.NewAPI(kithttp.WithLog(log)),
+		log:       log,
+		presetSvc: presetSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Post("/", h.handlePostPreset)
+		r.Get("/", h.handleGetPresets)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetPreset)
+			r.Patch("/", h.handlePatchPreset)
+			r.Delete("/", h.handleDeletePreset)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixAuthorizationPresets
+}
+
+type presetResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.AuthorizationPreset
+}
+
+func newPresetResponse(p *influxdb.AuthorizationPreset) *presetResponse {
+	return &presetResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("%s/%s", PrefixAuthorizationPresets, p.ID),
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", p.OrgID),
+		},
+		AuthorizationPreset: p,
+	}
+}
+
+type presetsResponse struct {
+	Links   map[string]string `json:"links"`
+	Presets []*presetResponse `json:"presets"`
+}
+
+type postPresetRequest struct {
+	OrgID       influxdb.ID           `json:"orgID"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Permissions []influxdb.Permission `json:"permissions"`
+}
+
+func (h *Handler) handlePostPreset(w http.ResponseWriter, r *http.Request) {
+	var req postPresetRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	p := &influxdb.AuthorizationPreset{
+		OrgID:       req.OrgID,
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	}
+	if err := h.presetSvc.CreateAuthorizationPreset(r.Context(), p); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Authorization preset created", zap.String("preset", p.Name), zap.String("orgID", p.OrgID.String()))
+
+	h.api.Respond(w, r, http.StatusCreated, newPresetResponse(p))
+}
+
+func (h *Handler) handleGetPresets(w http.ResponseWriter, r *http.Request) {
+	var filter influxdb.AuthorizationPresetFilter
+	q := r.URL.Query()
+	if v := q.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			h.api.Err(w, r, influxdb.ErrCorruptID(err))
+			return
+		}
+		filter.OrgID = id
+	}
+	if v := q.Get("name"); v != "" {
+		filter.Name = &v
+	}
+
+	presets, _, err := h.presetSvc.FindAuthorizationPresets(r.Context(), filter)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	resp := presetsResponse{
+		Links:   map[string]string{"self": PrefixAuthorizationPresets},
+		Presets: make([]*presetResponse, 0, len(presets)),
+	}
+	for _, p := range presets {
+		resp.Presets = append(resp.Presets, newPresetResponse(p))
+	}
+	h.api.Respond(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleGetPreset(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	p, err := h.presetSvc.FindAuthorizationPresetByID(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newPresetResponse(p))
+}
+
+func (h *Handler) handlePatchPreset(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	var upd influxdb.AuthorizationPresetUpdate
+	if err := h.api.DecodeJSON(r.Body, &upd); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	p, err := h.presetSvc.UpdateAuthorizationPreset(r.Context(), *id, upd)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Authorization preset updated", zap.String("presetID", p.ID.String()))
+
+	h.api.Respond(w, r, http.StatusOK, newPresetResponse(p))
+}
+
+func (h *Handler) handleDeletePreset(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	if err := h.presetSvc.DeleteAuthorizationPreset(r.Context(), *id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Authorization preset deleted", zap.String("presetID", id.String()))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
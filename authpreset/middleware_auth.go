@@ -0,0 +1,69 @@
+package authpreset
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+var _ influxdb.AuthorizationPresetService = (*AuthedService)(nil)
+
+// AuthedService requires read access to an organization to view its presets
+// and write access to its authorizations to manage them.
+type AuthedService struct {
+	s influxdb.AuthorizationPresetService
+}
+
+// NewAuthedService wraps s with authorization preset authorization.
+func NewAuthedService(s influxdb.AuthorizationPresetService) *AuthedService {
+	return &AuthedService{s: s}
+}
+
+func (s *AuthedService) CreateAuthorizationPreset(ctx context.Context, p *influxdb.AuthorizationPreset) error {
+	if _, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.AuthorizationsResourceType, p.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateAuthorizationPreset(ctx, p)
+}
+
+func (s *AuthedService) FindAuthorizationPresetByID(ctx context.Context, id influxdb.ID) (*influxdb.AuthorizationPreset, error) {
+	p, err := s.s.FindAuthorizationPresetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeReadOrg(ctx, p.OrgID); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *AuthedService) FindAuthorizationPresets(ctx context.Context, filter influxdb.AuthorizationPresetFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuthorizationPreset, int, error) {
+	presets, _, err := s.s.FindAuthorizationPresets(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return authorizer.AuthorizeFindAuthorizationPresets(ctx, presets)
+}
+
+func (s *AuthedService) UpdateAuthorizationPreset(ctx context.Context, id influxdb.ID, upd influxdb.AuthorizationPresetUpdate) (*influxdb.AuthorizationPreset, error) {
+	p, err := s.s.FindAuthorizationPresetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.AuthorizationsResourceType, p.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateAuthorizationPreset(ctx, id, upd)
+}
+
+func (s *AuthedService) DeleteAuthorizationPreset(ctx context.Context, id influxdb.ID) error {
+	p, err := s.s.FindAuthorizationPresetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.AuthorizationsResourceType, p.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteAuthorizationPreset(ctx, id)
+}
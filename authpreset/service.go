@@ -0,0 +1,341 @@
+// Package authpreset implements authorization presets: named sets of
+// permissions managed per organization that authorizations are created with,
+// so that the tokens of a team grant consistent permissions.
+package authpreset
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/snowflake"
+)
+
+var (
+	presetBucket = []byte("authorizationpresetsv1")
+	indexBucket  = []byte("authorizationpresetindexv1")
+)
+
+var _ influxdb.AuthorizationPresetService = (*Service)(nil)
+
+// Service stores authorization presets in a kv store.
+type Service struct {
+	store         kv.Store
+	IDGen         influxdb.IDGenerator
+	TimeGenerator influxdb.TimeGenerator
+}
+
+// NewService constructs an authorization preset service backed by st.
+func NewService(st kv.Store) *Service {
+	return &Service{
+		store:         st,
+		IDGen:         snowflake.NewDefaultIDGenerator(),
+		TimeGenerator: influxdb.RealTimeGenerator{},
+	}
+}
+
+// CreateAuthorizationPreset creates a preset. Permissions without an
+// organization are scoped to the organization of the preset.
+func (s *Service) CreateAuthorizationPreset(ctx context.Context, p *influxdb.AuthorizationPreset) error {
+	scopePermissions(p)
+	if err := p.Valid(); err != nil {
+		return ErrInvalidPreset(err)
+	}
+
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		if err := s.checkNameAvailable(tx, p.OrgID, p.Name); err != nil {
+			return err
+		}
+
+		now := s.TimeGenerator.Now()
+		p.ID = s.IDGen.ID()
+		p.CreatedAt = now
+		p.UpdatedAt = now
+
+		if err := s.putPreset(tx, p); err != nil {
+			return err
+		}
+		return s.putIndex(tx, p)
+	})
+}
+
+// FindAuthorizationPresetByID returns a single preset by ID.
+func (s *Service) FindAuthorizationPresetByID(ctx context.Context, id influxdb.ID) (*influxdb.AuthorizationPreset, error) {
+	var p *influxdb.AuthorizationPreset
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		p, err = s.getPreset(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FindAuthorizationPresets returns a list of presets that match filter and
+// the total count of matching presets, ordered by name.
+func (s *Service) FindAuthorizationPresets(ctx context.Context, filter influxdb.AuthorizationPresetFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuthorizationPreset, int, error) {
+	presets := []*influxdb.AuthorizationPreset{}
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		if filter.OrgID != nil && filter.Name != nil {
+			p, err := s.findByName(tx, *filter.OrgID, *filter.Name)
+			if err == ErrPresetNotFound {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			presets = append(presets, p)
+			return nil
+		}
+
+		b, err := tx.Bucket(presetBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		return kv.WalkCursor(ctx, cur, func(k, v []byte) error {
+			p := &influxdb.AuthorizationPreset{}
+			if err := json.Unmarshal(v, p); err != nil {
+				return ErrInternalService(err)
+			}
+			if filterFunc(p, filter) {
+				presets = append(presets, p)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.SliceStable(presets, func(i, j int) bool {
+		return presets[i].Name < presets[j].Name
+	})
+
+	total := len(presets)
+	if len(opt) > 0 {
+		presets = paginate(presets, opt[0])
+	}
+	return presets, total, nil
+}
+
+// UpdateAuthorizationPreset updates a single preset with changeset.
+func (s *Service) UpdateAuthorizationPreset(ctx context.Context, id influxdb.ID, upd influxdb.AuthorizationPresetUpdate) (*influxdb.AuthorizationPreset, error) {
+	var p *influxdb.AuthorizationPreset
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		var err error
+		p, err = s.getPreset(tx, id)
+		if err != nil {
+			return err
+		}
+		prevName := p.Name
+
+		upd.Apply(p)
+		scopePermissions(p)
+		if err := p.Valid(); err != nil {
+			return ErrInvalidPreset(err)
+		}
+
+		if p.Name != prevName {
+			if err := s.checkNameAvailable(tx, p.OrgID, p.Name); err != nil {
+				return err
+			}
+			if err := s.deleteIndex(tx, p.OrgID, prevName); err != nil {
+				return err
+			}
+			if err := s.putIndex(tx, p); err != nil {
+				return err
+			}
+		}
+
+		p.UpdatedAt = s.TimeGenerator.Now()
+		return s.putPreset(tx, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// DeleteAuthorizationPreset removes a single preset by ID. Authorizations
+// created with the preset keep their permissions.
+func (s *Service) DeleteAuthorizationPreset(ctx context.Context, id influxdb.ID) error {
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		p, err := s.getPreset(tx, id)
+		if err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return ErrInvalidPresetID
+		}
+		b, err := tx.Bucket(presetBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		if err := b.Delete(encodedID); err != nil {
+			return ErrInternalService(err)
+		}
+		return s.deleteIndex(tx, p.OrgID, p.Name)
+	})
+}
+
+// scopePermissions scopes the permissions of p without an organization to
+// the organization of p.
+func scopePermissions(p *influxdb.AuthorizationPreset) {
+	for i := range p.Permissions {
+		if p.Permissions[i].Resource.OrgID == nil {
+			orgID := p.OrgID
+			p.Permissions[i].Resource.OrgID = &orgID
+		}
+	}
+}
+
+func (s *Service) checkNameAvailable(tx kv.Tx, orgID influxdb.ID, name string) error {
+	_, err := s.findByName(tx, orgID, name)
+	if err == nil {
+		return ErrPresetNameConflict
+	}
+	if err != ErrPresetNotFound {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) findByName(tx kv.Tx, orgID influxdb.ID, name string) (*influxdb.AuthorizationPreset, error) {
+	key, err := indexKey(orgID, name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := tx.Bucket(indexBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(key)
+	if kv.IsNotFound(err) {
+		return nil, ErrPresetNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	var id influxdb.ID
+	if err := id.Decode(v); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return s.getPreset(tx, id)
+}
+
+func (s *Service) getPreset(tx kv.Tx, id influxdb.ID) (*influxdb.AuthorizationPreset, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidPresetID
+	}
+
+	b, err := tx.Bucket(presetBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(encodedID)
+	if kv.IsNotFound(err) {
+		return nil, ErrPresetNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+
+	p := &influxdb.AuthorizationPreset{}
+	if err := json.Unmarshal(v, p); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return p, nil
+}
+
+func (s *Service) putPreset(tx kv.Tx, p *influxdb.AuthorizationPreset) error {
+	encodedID, err := p.ID.Encode()
+	if err != nil {
+		return ErrInvalidPresetID
+	}
+	v, err := json.Marshal(p)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+
+	b, err := tx.Bucket(presetBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(encodedID, v); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+func (s *Service) putIndex(tx kv.Tx, p *influxdb.AuthorizationPreset) error {
+	key, err := indexKey(p.OrgID, p.Name)
+	if err != nil {
+		return err
+	}
+	encodedID, err := p.ID.Encode()
+	if err != nil {
+		return ErrInvalidPresetID
+	}
+	b, err := tx.Bucket(indexBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(key, encodedID); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+func (s *Service) deleteIndex(tx kv.Tx, orgID influxdb.ID, name string) error {
+	key, err := indexKey(orgID, name)
+	if err != nil {
+		return err
+	}
+	b, err := tx.Bucket(indexBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Delete(key); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+// indexKey returns the key of the index of the preset named name in the
+// organization.
+func indexKey(orgID influxdb.ID, name string) ([]byte, error) {
+	encodedOrgID, err := orgID.Encode()
+	if err != nil {
+		return nil, ErrInvalidPreset(err)
+	}
+	return append(encodedOrgID, name...), nil
+}
+
+func filterFunc(p *influxdb.AuthorizationPreset, filter influxdb.AuthorizationPresetFilter) bool {
+	return (filter.OrgID == nil || p.OrgID == *filter.OrgID) &&
+		(filter.Name == nil || p.Name == *filter.Name)
+}
+
+func paginate(presets []*influxdb.AuthorizationPreset, opt influxdb.FindOptions) []*influxdb.AuthorizationPreset {
+	if opt.Offset > 0 {
+		if opt.Offset >= len(presets) {
+			return []*influxdb.AuthorizationPreset{}
+		}
+		presets = presets[opt.Offset:]
+	}
+	if opt.Limit > 0 && opt.Limit < len(presets) {
+		presets = presets[:opt.Limit]
+	}
+	return presets
+}
@@ -0,0 +1,116 @@
+package authpreset_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authpreset"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+func newTestService(t *testing.T) (*authpreset.Service, func()) {
+	t.Helper()
+
+	s, done := influxdbtesting.NewTestBoltStore(t)
+	svc := authpreset.NewService(s)
+	svc.IDGen = mock.NewIncrementingIDGenerator(1)
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)}
+	return svc, done
+}
+
+func readBuckets() []influxdb.Permission {
+	return []influxdb.Permission{
+		{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.BucketsResourceType}},
+	}
+}
+
+func TestService_CreateAuthorizationPreset(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	p := &influxdb.AuthorizationPreset{OrgID: 1, Name: "read-only telemetry", Permissions: readBuckets()}
+	if err := svc.CreateAuthorizationPreset(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	if orgID := p.Permissions[0].Resource.OrgID; orgID == nil || *orgID != 1 {
+		t.Errorf("expected the permissions to be scoped to the organization, got %v", p.Permissions)
+	}
+
+	err := svc.CreateAuthorizationPreset(ctx, &influxdb.AuthorizationPreset{OrgID: 1, Name: "read-only telemetry", Permissions: readBuckets()})
+	if influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Errorf("expected a conflict for a duplicate name, got %v", err)
+	}
+	if err := svc.CreateAuthorizationPreset(ctx, &influxdb.AuthorizationPreset{OrgID: 2, Name: "read-only telemetry", Permissions: readBuckets()}); err != nil {
+		t.Errorf("expected names to be unique per organization: %v", err)
+	}
+
+	otherOrg := influxdb.ID(2)
+	err = svc.CreateAuthorizationPreset(ctx, &influxdb.AuthorizationPreset{OrgID: 1, Name: "other", Permissions: []influxdb.Permission{
+		{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &otherOrg}},
+	}})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected a permission of another organization to be invalid, got %v", err)
+	}
+}
+
+func TestService_UpdateAuthorizationPreset(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	p := &influxdb.AuthorizationPreset{OrgID: 1, Name: "ci", Permissions: readBuckets()}
+	if err := svc.CreateAuthorizationPreset(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	name := "CI deployer"
+	if _, err := svc.UpdateAuthorizationPreset(ctx, p.ID, influxdb.AuthorizationPresetUpdate{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+
+	orgID := influxdb.ID(1)
+	presets, _, err := svc.FindAuthorizationPresets(ctx, influxdb.AuthorizationPresetFilter{OrgID: &orgID, Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(presets) != 1 || presets[0].ID != p.ID {
+		t.Fatalf("expected the renamed preset to be found by its new name, got %v", presets)
+	}
+
+	oldName := "ci"
+	presets, _, err = svc.FindAuthorizationPresets(ctx, influxdb.AuthorizationPresetFilter{OrgID: &orgID, Name: &oldName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(presets) != 0 {
+		t.Errorf("expected the old name to be free, got %v", presets)
+	}
+
+	if err := svc.DeleteAuthorizationPreset(ctx, p.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindAuthorizationPresetByID(ctx, p.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected the preset to be deleted, got %v", err)
+	}
+	if err := svc.CreateAuthorizationPreset(ctx, &influxdb.AuthorizationPreset{OrgID: 1, Name: name, Permissions: readBuckets()}); err != nil {
+		t.Errorf("expected the name of a deleted preset to be reusable: %v", err)
+	}
+}
+
+func TestService_NotFound(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	name := "read-only telemetry"
+	if _, err := svc.UpdateAuthorizationPreset(ctx, 99, influxdb.AuthorizationPresetUpdate{Name: &name}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error updating a missing preset, got %v", err)
+	}
+	if err := svc.DeleteAuthorizationPreset(ctx, 99); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error deleting a missing preset, got %v", err)
+	}
+}
@@ -132,6 +132,10 @@ const (
 	ChecksResourceType = ResourceType("checks") // 16
 	// DBRPType gives permission to one or more DBRPs.
 	DBRPResourceType = ResourceType("dbrp") // 17
+	// FluxPackagesResourceType gives permission to one or more user-defined Flux packages.
+	FluxPackagesResourceType = ResourceType("fluxPackages") // 18
+	// SavedQueriesResourceType gives permission to one or more saved queries.
+	SavedQueriesResourceType = ResourceType("savedQueries") // 19
 )
 
 // AllResourceTypes is the list of all known resource types.
@@ -154,6 +158,8 @@ var AllResourceTypes = []ResourceType{
 	NotificationEndpointResourceType, // 15
 	ChecksResourceType,               // 16
 	DBRPResourceType,                 // 17
+	FluxPackagesResourceType,         // 18
+	SavedQueriesResourceType,         // 19
 	// NOTE: when modifying this list, please update the swagger for components.schemas.Permission resource enum.
 }
 
@@ -172,6 +178,8 @@ var OrgResourceTypes = []ResourceType{
 	NotificationEndpointResourceType, // 15
 	ChecksResourceType,               // 16
 	DBRPResourceType,                 // 17
+	FluxPackagesResourceType,         // 18
+	SavedQueriesResourceType,         // 19
 }
 
 // Valid checks if the resource type is a member of the ResourceType enum.
@@ -200,6 +208,8 @@ func (t ResourceType) Valid() (err error) {
 	case NotificationEndpointResourceType: // 15
 	case ChecksResourceType: // 16
 	case DBRPResourceType: // 17
+	case FluxPackagesResourceType: // 18
+	case SavedQueriesResourceType: // 19
 	default:
 		err = ErrInvalidResourceType
 	}
@@ -21,3 +21,13 @@ type KVBackupService interface {
 	// Backup creates a live backup copy of the metadata database.
 	Backup(ctx context.Context, w io.Writer) error
 }
+
+// BucketArchiveService moves the data of a bucket between instances as an archive
+// of TSM files, without re-ingesting it as line protocol.
+type BucketArchiveService interface {
+	// ExportBucket writes an archive of the data of a bucket to w.
+	ExportBucket(ctx context.Context, orgID, bucketID ID, w io.Writer) error
+	// ImportBucket loads an archive read from r into a bucket, which may belong
+	// to another organization than the exported bucket.
+	ImportBucket(ctx context.Context, orgID, bucketID ID, r io.Reader) error
+}
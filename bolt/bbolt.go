@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	bolt "github.com/coreos/bbolt"
@@ -19,9 +20,12 @@ const DefaultFilename = "influxd.bolt"
 // Client is a client for the boltDB data store.
 type Client struct {
 	Path string
-	db   *bolt.DB
 	log  *zap.Logger
 
+	// mu guards db, which is replaced by compactions.
+	mu sync.RWMutex
+	db *bolt.DB
+
 	IDGenerator    platform.IDGenerator
 	TokenGenerator platform.TokenGenerator
 	platform.TimeGenerator
@@ -39,9 +43,18 @@ func NewClient(log *zap.Logger) *Client {
 
 // DB returns the clients DB.
 func (c *Client) DB() *bolt.DB {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.db
 }
 
+// WithDB replaces the DB of the client, such as once it was compacted.
+func (c *Client) WithDB(db *bolt.DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.db = db
+}
+
 // Open / create boltDB file.
 func (c *Client) Open(ctx context.Context) error {
 	// Ensure the required directory structure exists.
@@ -58,7 +71,7 @@ func (c *Client) Open(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("unable to open boltdb; is there a chronograf already running?  %v", err)
 	}
-	c.db = db
+	c.WithDB(db)
 
 	if err := c.initialize(ctx); err != nil {
 		return err
@@ -70,7 +83,7 @@ func (c *Client) Open(ctx context.Context) error {
 
 // initialize creates Buckets that are missing
 func (c *Client) initialize(ctx context.Context) error {
-	if err := c.db.Update(func(tx *bolt.Tx) error {
+	if err := Update(c.DB, func(tx *bolt.Tx) error {
 		// Always create ID bucket.
 		// TODO: is this still needed?
 		if err := c.initializeID(tx); err != nil {
@@ -103,8 +116,8 @@ func (c *Client) initialize(ctx context.Context) error {
 
 // Close the connection to the bolt database
 func (c *Client) Close() error {
-	if c.db != nil {
-		return c.db.Close()
+	if db := c.DB(); db != nil {
+		return db.Close()
 	}
 	return nil
 }
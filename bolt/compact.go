@@ -0,0 +1,309 @@
+package bolt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// DefaultCompactionFreeRatio is the ratio of free space of a bolt file
+// above which it is compacted.
+const DefaultCompactionFreeRatio = 0.5
+
+// errReplaced is returned by the write transactions begun on a database
+// that was replaced meanwhile, such as by a compaction, so that they are
+// retried on the new database.
+var errReplaced = errors.New("boltdb was replaced")
+
+// View runs fn in a read transaction of the database returned by current,
+// retrying it on the new database if the database was replaced and closed.
+// The users sharing the database of a KVStore run their transactions with
+// View and Update so that they survive its compactions.
+func View(current func() *bolt.DB, fn func(tx *bolt.Tx) error) error {
+	for {
+		db := current()
+		err := db.View(fn)
+		if err == bolt.ErrDatabaseNotOpen && current() != db {
+			continue
+		}
+		return err
+	}
+}
+
+// Update runs fn in a write transaction of the database returned by
+// current. The transactions begun on a database that was replaced are rolled
+// back before fn is called and retried on the new database, so that no write
+// is lost to the replaced file.
+func Update(current func() *bolt.DB, fn func(tx *bolt.Tx) error) error {
+	for {
+		db := current()
+		err := db.Update(func(tx *bolt.Tx) error {
+			if current() != db {
+				return errReplaced
+			}
+			return fn(tx)
+		})
+		if err == errReplaced || (err == bolt.ErrDatabaseNotOpen && current() != db) {
+			continue
+		}
+		return err
+	}
+}
+
+// CompactionStats are the sizes in bytes of a bolt file before and after it
+// was compacted.
+type CompactionStats struct {
+	SizeBefore int64
+	SizeAfter  int64
+}
+
+// Reclaimed returns the number of bytes the compaction reclaimed.
+func (s CompactionStats) Reclaimed() int64 {
+	return s.SizeBefore - s.SizeAfter
+}
+
+// OnCompacted registers fn to be called with the compacted database once the
+// store is compacted. The users sharing the database of the store must
+// register to replace their database with the compacted one, and must
+// run their transactions on the database they are given last.
+func (s *KVStore) OnCompacted(fn func(db *bolt.DB)) {
+	s.compactMu.Lock()
+	defer s.compactMu.Unlock()
+	s.onCompacted = append(s.onCompacted, fn)
+}
+
+// Compact copies the keys of the store into a new bolt file, leaving the
+// free pages of the current file behind, and replaces the file of the store
+// with the copy. The store is usable while it is compacted, but its writes
+// wait until the copy replaced the file. The users sharing its database are
+// given the compacted one with the functions registered with OnCompacted.
+func (s *KVStore) Compact(ctx context.Context) (CompactionStats, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	s.compactMu.Lock()
+	defer s.compactMu.Unlock()
+
+	var stats CompactionStats
+	before, err := os.Stat(s.path)
+	if err != nil {
+		return stats, err
+	}
+	stats.SizeBefore = before.Size()
+
+	// The write transaction blocks the writes to the current database until
+	// the copy replaced its file.
+	old := s.current()
+	tx, err := old.Begin(true)
+	if err != nil {
+		return stats, err
+	}
+	db, err := s.copyTo(tx, s.path+".compact")
+	if err != nil {
+		_ = tx.Rollback()
+		return stats, err
+	}
+
+	s.WithDB(db)
+	for _, fn := range s.onCompacted {
+		fn(db)
+	}
+	// The writes that waited for the transaction are retried on the compacted
+	// database, and the reads in progress are done before the close returns.
+	_ = tx.Rollback()
+	if err := old.Close(); err != nil {
+		s.log.Warn("Failed to close the boltdb replaced by its compaction", zap.Error(err))
+	}
+
+	after, err := os.Stat(s.path)
+	if err != nil {
+		return stats, err
+	}
+	stats.SizeAfter = after.Size()
+	return stats, nil
+}
+
+// copyTo copies the buckets of tx into a new bolt file at path, then renames
+// it to the path of the store. It returns the copy opened.
+func (s *KVStore) copyTo(tx *bolt.Tx, path string) (*bolt.DB, error) {
+	// The file may be left behind by a failed compaction.
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open boltdb file %v", err)
+	}
+
+	err = db.Update(func(dst *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			bkt, err := dst.CreateBucket(name)
+			if err != nil {
+				return err
+			}
+			return copyBucket(bkt, b)
+		})
+	})
+	if err == nil {
+		// The copy replaces the file of the store while it is open, so that
+		// it is not unlocked before the store uses it.
+		err = os.Rename(path, s.path)
+	}
+	if err != nil {
+		_ = db.Close()
+		_ = os.Remove(path)
+		return nil, err
+	}
+
+	db.NoSync = s.noSync
+	return db, nil
+}
+
+// copyBucket copies the keys and nested buckets of src into dst.
+func copyBucket(dst, src *bolt.Bucket) error {
+	// The keys are copied in order, so the pages are filled entirely.
+	dst.FillPercent = 1.0
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+	return src.ForEach(func(k, v []byte) error {
+		if v != nil {
+			return dst.Put(k, v)
+		}
+		bkt, err := dst.CreateBucket(k)
+		if err != nil {
+			return err
+		}
+		return copyBucket(bkt, src.Bucket(k))
+	})
+}
+
+// freeRatio returns the number of bytes of the bolt file of the store in free
+// pages, and their ratio of its size.
+func (s *KVStore) freeRatio() (free int64, ratio float64, err error) {
+	fi, err := os.Stat(s.path)
+	if err != nil || fi.Size() == 0 {
+		return 0, 0, err
+	}
+	free = int64(s.current().Stats().FreeAlloc)
+	return free, float64(free) / float64(fi.Size()), nil
+}
+
+// Compactor compacts a KVStore periodically, once the free space of its
+// bolt file exceeds a ratio of its size.
+type Compactor struct {
+	log       *zap.Logger
+	store     *KVStore
+	interval  time.Duration
+	freeRatio float64
+
+	compactions *prometheus.CounterVec
+	reclaimed   prometheus.Counter
+	duration    prometheus.Histogram
+	free        prometheus.Gauge
+}
+
+// NewCompactor returns a compactor of store that checks every interval
+// whether the free space of its file exceeds freeRatio of its size.
+func NewCompactor(log *zap.Logger, store *KVStore, interval time.Duration, freeRatio float64) *Compactor {
+	const namespace = "boltdb"
+	const subsystem = "compaction"
+
+	return &Compactor{
+		log:       log,
+		store:     store,
+		interval:  interval,
+		freeRatio: freeRatio,
+		compactions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "total",
+			Help:      "Number of compactions of the boltdb file",
+		}, []string{"status"}),
+		reclaimed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "reclaimed_bytes_total",
+			Help:      "Number of bytes reclaimed by the compactions of the boltdb file",
+		}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "duration_seconds",
+			Help:      "Time taken to compact the boltdb file",
+		}),
+		free: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "free_bytes",
+			Help:      "Number of bytes of the boltdb file in free pages, as of the last compaction check",
+		}),
+	}
+}
+
+// PrometheusCollectors returns the metrics of the compactor.
+func (c *Compactor) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.compactions,
+		c.reclaimed,
+		c.duration,
+		c.free,
+	}
+}
+
+// Run compacts the store until ctx is canceled.
+func (c *Compactor) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := c.compact(ctx); err != nil {
+			c.log.Error("Failed to compact boltdb", zap.Error(err))
+		}
+	}
+}
+
+// compact compacts the store if the free space of its file exceeds the
+// ratio of the compactor.
+func (c *Compactor) compact(ctx context.Context) error {
+	free, ratio, err := c.store.freeRatio()
+	if err != nil {
+		return err
+	}
+	c.free.Set(float64(free))
+	if ratio < c.freeRatio {
+		return nil
+	}
+
+	start := time.Now()
+	stats, err := c.store.Compact(ctx)
+	if err != nil {
+		c.compactions.WithLabelValues("error").Inc()
+		return err
+	}
+	c.compactions.WithLabelValues("ok").Inc()
+	c.duration.Observe(time.Since(start).Seconds())
+	if reclaimed := stats.Reclaimed(); reclaimed > 0 {
+		c.reclaimed.Add(float64(reclaimed))
+	}
+	c.free.Set(float64(c.store.current().Stats().FreeAlloc))
+
+	c.log.Info("Compacted boltdb",
+		zap.Int64("size_before", stats.SizeBefore),
+		zap.Int64("size_after", stats.SizeAfter),
+		zap.Duration("took", time.Since(start)))
+	return nil
+}
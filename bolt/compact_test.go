@@ -0,0 +1,110 @@
+package bolt_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	bbolt "github.com/coreos/bbolt"
+	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestKVStore_Compact(t *testing.T) {
+	ctx := context.Background()
+	c, closeFn, err := NewTestClient(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFn()
+
+	// The store shares the database of the client, as in influxd.
+	s := bolt.NewKVStore(zaptest.NewLogger(t), c.Path)
+	s.WithDB(c.DB())
+	s.OnCompacted(c.WithDB)
+	old := c.DB()
+
+	bucket := []byte("compact")
+	mustCreateBucket(t, s, bucket)
+	value := bytes.Repeat([]byte("v"), 1024)
+	put := func(from, to int) {
+		t.Helper()
+		if err := s.Update(ctx, func(tx kv.Tx) error {
+			b, err := tx.Bucket(bucket)
+			if err != nil {
+				return err
+			}
+			for i := from; i < to; i++ {
+				if err := b.Put([]byte(fmt.Sprintf("key%05d", i)), value); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	put(0, 5000)
+	if err := s.Update(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket(bucket)
+		if err != nil {
+			return err
+		}
+		for i := 10; i < 5000; i++ {
+			if err := b.Delete([]byte(fmt.Sprintf("key%05d", i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := s.Compact(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Reclaimed() <= 0 {
+		t.Fatalf("expected the compaction to reclaim space, sizes are %d before and %d after", stats.SizeBefore, stats.SizeAfter)
+	}
+
+	// The store and the client use the compacted database.
+	if c.DB() == old {
+		t.Fatal("expected the client to be given the compacted database")
+	}
+	if _, err := old.Begin(false); err != bbolt.ErrDatabaseNotOpen {
+		t.Fatalf("expected the compacted database to be closed, got %v", err)
+	}
+	put(5000, 5001)
+
+	var keys int
+	if err := s.View(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket(bucket)
+		if err != nil {
+			return err
+		}
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+		for k, _ := cur.Next(); k != nil; k, _ = cur.Next() {
+			keys++
+		}
+		return cur.Close()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if keys != 11 {
+		t.Fatalf("expected 11 keys after the compaction, got %d", keys)
+	}
+	if err := c.DB().View(func(tx *bbolt.Tx) error {
+		if v := tx.Bucket(bucket).Get([]byte("key05000")); !bytes.Equal(v, value) {
+			return fmt.Errorf("expected the client to read the writes of the store, got %q", v)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
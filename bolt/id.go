@@ -41,7 +41,7 @@ func (c *Client) initializeID(tx *bolt.Tx) error {
 func (c *Client) ID() platform.ID {
 	// if any error occurs return a random number
 	id := platform.ID(rand.Int63())
-	err := c.db.View(func(tx *bolt.Tx) error {
+	err := View(c.DB, func(tx *bolt.Tx) error {
 		val, err := c.getID(tx)
 		if err != nil {
 			return err
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	bolt "github.com/coreos/bbolt"
@@ -22,9 +23,17 @@ var _ kv.SchemaStore = (*KVStore)(nil)
 // KVStore is a kv.Store backed by boltdb.
 type KVStore struct {
 	path string
-	db   *bolt.DB
 	log  *zap.Logger
 
+	// mu guards db, which is replaced by compactions.
+	mu sync.RWMutex
+	db *bolt.DB
+
+	// compactMu serializes compactions, and onCompacted are called with the
+	// compacted database.
+	compactMu   sync.Mutex
+	onCompacted []func(*bolt.DB)
+
 	noSync bool
 }
 
@@ -82,15 +91,15 @@ func (s *KVStore) Open(ctx context.Context) error {
 
 // Close the connection to the bolt database
 func (s *KVStore) Close() error {
-	if s.db != nil {
-		return s.db.Close()
+	if db := s.current(); db != nil {
+		return db.Close()
 	}
 	return nil
 }
 
 // Flush removes all bolt keys within each bucket.
 func (s *KVStore) Flush(ctx context.Context) {
-	_ = s.db.Update(
+	_ = s.update(
 		func(tx *bolt.Tx) error {
 			return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
 				s.cleanBucket(tx, b)
@@ -117,15 +126,32 @@ func (s *KVStore) cleanBucket(tx *bolt.Tx, b *bolt.Bucket) {
 
 // WithDB sets the boltdb on the store.
 func (s *KVStore) WithDB(db *bolt.DB) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.db = db
 }
 
+// current returns the boltdb of the store.
+func (s *KVStore) current() *bolt.DB {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.db
+}
+
+func (s *KVStore) view(fn func(tx *bolt.Tx) error) error {
+	return View(s.current, fn)
+}
+
+func (s *KVStore) update(fn func(tx *bolt.Tx) error) error {
+	return Update(s.current, fn)
+}
+
 // View opens up a view transaction against the store.
 func (s *KVStore) View(ctx context.Context, fn func(tx kv.Tx) error) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
-	return s.db.View(func(tx *bolt.Tx) error {
+	return s.view(func(tx *bolt.Tx) error {
 		return fn(&Tx{
 			tx:  tx,
 			ctx: ctx,
@@ -138,7 +164,7 @@ func (s *KVStore) Update(ctx context.Context, fn func(tx kv.Tx) error) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(func(tx *bolt.Tx) error {
 		return fn(&Tx{
 			tx:  tx,
 			ctx: ctx,
@@ -149,7 +175,7 @@ func (s *KVStore) Update(ctx context.Context, fn func(tx kv.Tx) error) error {
 // CreateBucket creates a bucket in the underlying boltdb store if it
 // does not already exist
 func (s *KVStore) CreateBucket(ctx context.Context, name []byte) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(func(tx *bolt.Tx) error {
 		_, err := tx.CreateBucketIfNotExists(name)
 		return err
 	})
@@ -158,7 +184,7 @@ func (s *KVStore) CreateBucket(ctx context.Context, name []byte) error {
 // DeleteBucket creates a bucket in the underlying boltdb store if it
 // does not already exist
 func (s *KVStore) DeleteBucket(ctx context.Context, name []byte) error {
-	return s.db.Update(func(tx *bolt.Tx) error {
+	return s.update(func(tx *bolt.Tx) error {
 		if err := tx.DeleteBucket(name); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
 			return err
 		}
@@ -172,7 +198,7 @@ func (s *KVStore) Backup(ctx context.Context, w io.Writer) error {
 	span, _ := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
-	return s.db.View(func(tx *bolt.Tx) error {
+	return s.view(func(tx *bolt.Tx) error {
 		_, err := tx.WriteTo(w)
 		return err
 	})
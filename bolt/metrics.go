@@ -120,7 +120,7 @@ func init() {
 
 // Collect returns the current state of all metrics of the collector.
 func (c *Client) Collect(ch chan<- prometheus.Metric) {
-	stats := c.db.Stats()
+	stats := c.DB().Stats()
 	writes := stats.TxStats.Write
 	reads := stats.TxN
 
@@ -138,7 +138,7 @@ func (c *Client) Collect(ch chan<- prometheus.Metric) {
 
 	orgs, buckets, users, tokens := 0, 0, 0, 0
 	dashboards, scrapers, telegrafs := 0, 0, 0
-	_ = c.db.View(func(tx *bolt.Tx) error {
+	_ = View(c.DB, func(tx *bolt.Tx) error {
 		buckets = tx.Bucket(bucketBucket).Stats().KeyN
 		dashboards = tx.Bucket(dashboardBucket).Stats().KeyN
 		orgs = tx.Bucket(organizationBucket).Stats().KeyN
@@ -3,14 +3,16 @@ package influxdb
 import (
 	"context"
 	"fmt"
+	"math"
+	"sort"
 	"strings"
 	"time"
 )
 
 const (
-	// TasksSystemBucketID is the fixed ID for our tasks system bucket
+	// TasksSystemBucketID is the default ID for our tasks system bucket, see SystemBuckets
 	TasksSystemBucketID = ID(10)
-	// MonitoringSystemBucketID is the fixed ID for our monitoring system bucket
+	// MonitoringSystemBucketID is the default ID for our monitoring system bucket, see SystemBuckets
 	MonitoringSystemBucketID = ID(11)
 
 	// BucketTypeUser is a user created bucket
@@ -23,10 +25,13 @@ const (
 	TasksSystemBucketRetention = time.Hour * 24 * 3
 )
 
-// Bucket names constants
+// Bucket names constants, the system bucket names being the defaults, see SystemBuckets
 const (
 	TasksSystemBucketName      = "_tasks"
 	MonitoringSystemBucketName = "_monitoring"
+	// MonitoringDownsampledBucketName holds hourly counts of statuses and
+	// notifications once they are downsampled.
+	MonitoringDownsampledBucketName = "_monitoring_downsampled"
 )
 
 // InfiniteRetention is default infinite retention period.
@@ -41,9 +46,193 @@ type Bucket struct {
 	Description         string        `json:"description"`
 	RetentionPolicyName string        `json:"rp,omitempty"` // This to support v1 sources
 	RetentionPeriod     time.Duration `json:"retentionPeriod"`
+	// WritePastWindow and WriteFutureWindow reject the writes of points
+	// older or newer than the time of the write by more than them. Zero
+	// windows accept any point.
+	WritePastWindow   time.Duration `json:"writePastWindow,omitempty"`
+	WriteFutureWindow time.Duration `json:"writeFutureWindow,omitempty"`
+	// DedupWindow drops the writes of exact duplicates, in series,
+	// timestamp and field values, of the points written to the bucket less
+	// than it ago. A zero window writes every point.
+	DedupWindow time.Duration `json:"dedupWindow,omitempty"`
+	// WriteSharding partitions the points written to the bucket, nil when
+	// they are written together.
+	WriteSharding *WriteSharding `json:"writeSharding,omitempty"`
+	// State is the lifecycle state of the bucket, active when empty.
+	State BucketState `json:"state,omitempty"`
+	// Metadata is freeform key/value metadata of the bucket, e.g. its owner
+	// or cost center, for the tooling that governs buckets.
+	Metadata map[string]string `json:"metadata,omitempty"`
 	CRUDLog
 }
 
+// HasMetadata returns true if the metadata of the bucket has every key of m
+// with the same value.
+func (b *Bucket) HasMetadata(m map[string]string) bool {
+	for k, v := range m {
+		if bv, ok := b.Metadata[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Limits of the metadata of a bucket.
+const (
+	MaxBucketMetadataKeys        = 64
+	MaxBucketMetadataKeyLength   = 128
+	MaxBucketMetadataValueLength = 1024
+)
+
+// ValidBucketMetadata returns an error if the metadata of a bucket has too
+// many keys, or a key or value that is too long. Keys must not be empty nor
+// contain a colon, which separates keys from values in filters.
+func ValidBucketMetadata(m map[string]string) error {
+	if len(m) > MaxBucketMetadataKeys {
+		return fmt.Errorf("bucket metadata must have at most %d keys, got %d", MaxBucketMetadataKeys, len(m))
+	}
+	for k, v := range m {
+		switch {
+		case k == "":
+			return fmt.Errorf("bucket metadata keys must not be empty")
+		case strings.Contains(k, ":"):
+			return fmt.Errorf("bucket metadata key %q must not contain a colon", k)
+		case len(k) > MaxBucketMetadataKeyLength:
+			return fmt.Errorf("bucket metadata key %q is longer than %d bytes", k, MaxBucketMetadataKeyLength)
+		case len(v) > MaxBucketMetadataValueLength:
+			return fmt.Errorf("bucket metadata value of %q is longer than %d bytes", k, MaxBucketMetadataValueLength)
+		}
+	}
+	return nil
+}
+
+// ValidBucketOptions returns an unprocessable entity error if the dedup
+// window, the write sharding or the metadata of a bucket, or of an update of
+// a bucket, are invalid. A nil write sharding is valid.
+func ValidBucketOptions(dedupWindow time.Duration, ws *WriteSharding, metadata map[string]string) error {
+	if dedupWindow < 0 {
+		return &Error{
+			Code: EUnprocessableEntity,
+			Msg:  "dedup window seconds must not be negative",
+		}
+	}
+	if ws != nil {
+		if err := ws.Valid(); err != nil {
+			return &Error{
+				Code: EUnprocessableEntity,
+				Msg:  err.Error(),
+			}
+		}
+	}
+	if err := ValidBucketMetadata(metadata); err != nil {
+		return &Error{
+			Code: EUnprocessableEntity,
+			Msg:  err.Error(),
+		}
+	}
+	return nil
+}
+
+// ParseBucketMetadataFilter parses the key:value filters of the metadata of
+// buckets, e.g. owner:ops@example.com.
+func ParseBucketMetadataFilter(filters []string) (map[string]string, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	m := make(map[string]string, len(filters))
+	for _, f := range filters {
+		i := strings.Index(f, ":")
+		if i <= 0 {
+			return nil, &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("invalid bucket metadata filter %q, must be key:value", f),
+			}
+		}
+		m[f[:i]] = f[i+1:]
+	}
+	return m, nil
+}
+
+// Archived returns true if the bucket is archived and read-only.
+func (b *Bucket) Archived() bool {
+	return b.State == BucketStateArchived
+}
+
+// WriteWindow returns the range of timestamps, in nanoseconds since the
+// epoch, of the points that can be written to the bucket at now.
+func (b *Bucket) WriteWindow(now time.Time) (min, max int64) {
+	min, max = math.MinInt64, math.MaxInt64
+	if b.WritePastWindow > 0 {
+		min = now.Add(-b.WritePastWindow).UnixNano()
+	}
+	if b.WriteFutureWindow > 0 {
+		max = now.Add(b.WriteFutureWindow).UnixNano()
+	}
+	return min, max
+}
+
+// BucketState is the lifecycle state of a bucket.
+type BucketState string
+
+const (
+	// BucketStateActive is the state of the buckets that are written to.
+	BucketStateActive BucketState = "active"
+	// BucketStateArchived is the state of the buckets of ended projects:
+	// their data is kept and can be queried, at a lower priority than the
+	// data of active buckets, but it cannot be written or deleted, nor can
+	// the bucket be deleted until it is active again.
+	BucketStateArchived BucketState = "archived"
+)
+
+// Valid returns an error if the state is unknown.
+func (s BucketState) Valid() error {
+	switch s {
+	case BucketStateActive, BucketStateArchived:
+		return nil
+	default:
+		return fmt.Errorf("invalid bucket state %q, must be %q or %q", s, BucketStateActive, BucketStateArchived)
+	}
+}
+
+// MaxWriteShards is the maximum number of write shards of a bucket.
+const MaxWriteShards = 64
+
+// WriteSharding partitions the points of the writes to a bucket by a hash of
+// the value of their Tag tag into Shards partitions, which are written to
+// storage in parallel to use more cores on large writes. Points without the
+// tag are written with the first partition. The points stay in the bucket,
+// so queries are not affected.
+type WriteSharding struct {
+	Tag    string `json:"tag"`
+	Shards int    `json:"shards"`
+}
+
+// Valid returns an error if the number of shards is out of range, or if
+// there is more than one shard and no tag.
+func (s *WriteSharding) Valid() error {
+	if s.Shards < 0 || s.Shards > MaxWriteShards {
+		return fmt.Errorf("write shards must be between 0 and %d, got %d", MaxWriteShards, s.Shards)
+	}
+	if s.Shards > 1 && s.Tag == "" {
+		return fmt.Errorf("write sharding requires a tag")
+	}
+	return nil
+}
+
+// Enabled returns true if the points are partitioned in more than one shard.
+func (s *WriteSharding) Enabled() bool {
+	return s != nil && s.Shards > 1 && s.Tag != ""
+}
+
+// IfEnabled returns s if it partitions the points, and nil otherwise, so
+// that the buckets writing their points together have no write sharding.
+func (s *WriteSharding) IfEnabled() *WriteSharding {
+	if !s.Enabled() {
+		return nil
+	}
+	return s
+}
+
 // BucketType differentiates system buckets from user buckets.
 type BucketType int
 
@@ -98,12 +287,35 @@ type BucketService interface {
 	FindBucketByName(ctx context.Context, orgID ID, name string) (*Bucket, error)
 }
 
+// BucketHistoryService finds the names the buckets of an organization had in
+// the past, so that queries can resolve bucket names as of a point in time.
+type BucketHistoryService interface {
+	// FindBucketNamesAsOf returns the IDs of the buckets of the organization
+	// by the names they had at t.
+	FindBucketNamesAsOf(ctx context.Context, orgID ID, t time.Time) (map[string]ID, error)
+}
+
 // BucketUpdate represents updates to a bucket.
 // Only fields which are set are updated.
 type BucketUpdate struct {
 	Name            *string        `json:"name,omitempty"`
 	Description     *string        `json:"description,omitempty"`
 	RetentionPeriod *time.Duration `json:"retentionPeriod,omitempty"`
+	// WritePastWindow and WriteFutureWindow update the write windows of the
+	// bucket; a zero window accepts any point.
+	WritePastWindow   *time.Duration `json:"writePastWindow,omitempty"`
+	WriteFutureWindow *time.Duration `json:"writeFutureWindow,omitempty"`
+	// DedupWindow updates the dedup window of the bucket; a zero window
+	// writes every point.
+	DedupWindow *time.Duration `json:"dedupWindow,omitempty"`
+	// WriteSharding replaces the write sharding of the bucket; less than two
+	// shards write the points together.
+	WriteSharding *WriteSharding `json:"writeSharding,omitempty"`
+	// State archives the bucket, or makes it active again.
+	State *BucketState `json:"state,omitempty"`
+	// Metadata replaces the metadata of the bucket when it is not nil; an
+	// empty map removes it.
+	Metadata map[string]string `json:"metadata"`
 }
 
 // BucketFilter represents a set of filter that restrict the returned results.
@@ -112,6 +324,9 @@ type BucketFilter struct {
 	Name           *string
 	OrganizationID *ID
 	Org            *string
+	// Metadata restricts the buckets to those whose metadata has every
+	// key with the same value.
+	Metadata map[string]string
 }
 
 // QueryParams Converts BucketFilter fields to url query params.
@@ -133,6 +348,17 @@ func (f BucketFilter) QueryParams() map[string][]string {
 		qp["org"] = []string{*f.Org}
 	}
 
+	if len(f.Metadata) > 0 {
+		keys := make([]string, 0, len(f.Metadata))
+		for k := range f.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			qp["metadata"] = append(qp["metadata"], k+":"+f.Metadata[k])
+		}
+	}
+
 	return qp
 }
 
@@ -156,6 +382,15 @@ func (f BucketFilter) String() string {
 	return "[" + strings.Join(parts, ", ") + "]"
 }
 
+// ErrBucketArchived is the error of an operation writing or deleting the data
+// of the archived bucket named name.
+func ErrBucketArchived(name, op string) *Error {
+	return &Error{
+		Code: EForbidden,
+		Msg:  fmt.Sprintf("bucket %q is archived and read-only, %s refused", name, op),
+	}
+}
+
 func ErrInternalBucketServiceError(op string, err error) *Error {
 	return &Error{
 		Code: EInternal,
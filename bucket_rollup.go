@@ -0,0 +1,69 @@
+package influxdb
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// BucketRollup maps a raw bucket to the bucket its data is downsampled into.
+// Reads of the raw bucket with from() are served from the rollup bucket for
+// the part of their range that is older than Cutoff, so that queries keep
+// working on data the raw bucket no longer retains.
+type BucketRollup struct {
+	OrgID          ID `json:"orgID"`
+	BucketID       ID `json:"bucketID"`
+	RollupBucketID ID `json:"rollupBucketID"`
+	// Cutoff is the age after which data is read from the rollup bucket.
+	// It is usually the retention period of the raw bucket.
+	Cutoff Duration `json:"cutoff"`
+	CRUDLog
+}
+
+// Valid returns an error if the rollup is missing required fields.
+func (r *BucketRollup) Valid() error {
+	if !r.OrgID.Valid() {
+		return errors.New("orgID is required")
+	}
+	if !r.BucketID.Valid() {
+		return errors.New("bucketID is required")
+	}
+	if !r.RollupBucketID.Valid() {
+		return errors.New("rollupBucketID is required")
+	}
+	if r.BucketID == r.RollupBucketID {
+		return errors.New("a bucket cannot be its own rollup")
+	}
+	if r.Cutoff.Duration <= 0 {
+		return errors.New("cutoff must be positive")
+	}
+	return nil
+}
+
+// CutoffTime returns the time before which data is read from the rollup
+// bucket by a read at now.
+func (r *BucketRollup) CutoffTime(now time.Time) time.Time {
+	return now.Add(-r.Cutoff.Duration)
+}
+
+// BucketRollupFilter selects the rollups returned by FindBucketRollups.
+type BucketRollupFilter struct {
+	OrgID    *ID
+	BucketID *ID
+}
+
+// BucketRollupService stores the rollups of raw buckets. A raw bucket has at
+// most one rollup.
+type BucketRollupService interface {
+	// FindBucketRollup returns the rollup of the raw bucket.
+	FindBucketRollup(ctx context.Context, bucketID ID) (*BucketRollup, error)
+
+	// FindBucketRollups returns the rollups matching the filter.
+	FindBucketRollups(ctx context.Context, filter BucketRollupFilter) ([]*BucketRollup, error)
+
+	// PutBucketRollup creates or replaces the rollup of a raw bucket.
+	PutBucketRollup(ctx context.Context, r *BucketRollup) error
+
+	// DeleteBucketRollup removes the rollup of the raw bucket.
+	DeleteBucketRollup(ctx context.Context, bucketID ID) error
+}
@@ -0,0 +1,36 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// SchemaRange restricts a schema lookup to series with data in [Start, Stop].
+// A zero Start or Stop leaves that side of the range unbounded.
+type SchemaRange struct {
+	Start time.Time
+	Stop  time.Time
+}
+
+// FieldKey is a field name and the type of the values stored for it.
+type FieldKey struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// BucketSchemaService exposes the measurements, tags and fields stored in a
+// bucket. Implementations read from the storage index rather than executing
+// queries, making it suitable for UI autocompletion.
+type BucketSchemaService interface {
+	// Measurements returns the measurement names in a bucket.
+	Measurements(ctx context.Context, orgID, bucketID ID, rng SchemaRange) ([]string, error)
+
+	// TagKeys returns the tag keys in a bucket, optionally restricted to a measurement.
+	TagKeys(ctx context.Context, orgID, bucketID ID, measurement string, rng SchemaRange) ([]string, error)
+
+	// TagValues returns the values of a tag key, optionally restricted to a measurement.
+	TagValues(ctx context.Context, orgID, bucketID ID, measurement, tagKey string, rng SchemaRange) ([]string, error)
+
+	// FieldKeys returns the field keys in a bucket, optionally restricted to a measurement.
+	FieldKeys(ctx context.Context, orgID, bucketID ID, measurement string, rng SchemaRange) ([]FieldKey, error)
+}
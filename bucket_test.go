@@ -0,0 +1,77 @@
+package influxdb_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestValidBucketOptions(t *testing.T) {
+	cases := []struct {
+		name     string
+		dedup    time.Duration
+		sharding *influxdb.WriteSharding
+		metadata map[string]string
+		wantErr  bool
+	}{
+		{
+			name: "no options",
+		},
+		{
+			name:     "valid options",
+			dedup:    time.Minute,
+			sharding: &influxdb.WriteSharding{Tag: "host", Shards: 4},
+			metadata: map[string]string{"owner": "team"},
+		},
+		{
+			name:    "negative dedup window",
+			dedup:   -time.Second,
+			wantErr: true,
+		},
+		{
+			name:     "too many shards",
+			sharding: &influxdb.WriteSharding{Tag: "host", Shards: influxdb.MaxWriteShards + 1},
+			wantErr:  true,
+		},
+		{
+			name:     "shards without tag",
+			sharding: &influxdb.WriteSharding{Shards: 2},
+			wantErr:  true,
+		},
+		{
+			name:     "metadata value too long",
+			metadata: map[string]string{"owner": strings.Repeat("a", influxdb.MaxBucketMetadataValueLength+1)},
+			wantErr:  true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := influxdb.ValidBucketOptions(c.dedup, c.sharding, c.metadata)
+			if !c.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if influxdb.ErrorCode(err) != influxdb.EUnprocessableEntity {
+				t.Fatalf("expected an unprocessable entity error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWriteSharding_IfEnabled(t *testing.T) {
+	var none *influxdb.WriteSharding
+	if got := none.IfEnabled(); got != nil {
+		t.Errorf("expected no write sharding, got %+v", got)
+	}
+	if got := (&influxdb.WriteSharding{Tag: "host", Shards: 1}).IfEnabled(); got != nil {
+		t.Errorf("expected a single shard to disable write sharding, got %+v", got)
+	}
+	ws := &influxdb.WriteSharding{Tag: "host", Shards: 2}
+	if got := ws.IfEnabled(); got != ws {
+		t.Errorf("expected the write sharding, got %+v", got)
+	}
+}
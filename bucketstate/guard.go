@@ -0,0 +1,137 @@
+// Package bucketstate enforces the state of buckets. The data of archived
+// buckets cannot be written or deleted, and is read at a lower priority than
+// the data of active buckets.
+package bucketstate
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// BucketFinder finds the buckets whose state is enforced.
+type BucketFinder interface {
+	FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error)
+}
+
+// checkWritable returns an error if the bucket is archived.
+func checkWritable(ctx context.Context, buckets BucketFinder, bucketID influxdb.ID, op string) error {
+	b, err := buckets.FindBucketByID(ctx, bucketID)
+	if err != nil {
+		return err
+	}
+	if b.Archived() {
+		return influxdb.ErrBucketArchived(b.Name, op)
+	}
+	return nil
+}
+
+var _ storage.PointsWriter = (*PointsWriter)(nil)
+
+// PointsWriter refuses the writes of points to archived buckets.
+type PointsWriter struct {
+	next    storage.PointsWriter
+	buckets BucketFinder
+}
+
+// NewPointsWriter wraps next so that archived buckets cannot be written to.
+func NewPointsWriter(next storage.PointsWriter, buckets BucketFinder) *PointsWriter {
+	return &PointsWriter{
+		next:    next,
+		buckets: buckets,
+	}
+}
+
+// WritePoints writes the points unless any of them is in an archived bucket,
+// in which case none of them are written.
+func (w *PointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	checked := make(map[string]bool)
+	for _, p := range points {
+		if checked[string(p.Name())] {
+			continue
+		}
+		_, bucketID := tsdb.DecodeNameSlice(p.Name())
+		if err := checkWritable(ctx, w.buckets, bucketID, "write"); err != nil {
+			return err
+		}
+		checked[string(p.Name())] = true
+	}
+	return w.next.WritePoints(ctx, points)
+}
+
+var _ influxdb.DeleteService = (*DeleteService)(nil)
+
+// DeleteService refuses the deletes of the data of archived buckets.
+type DeleteService struct {
+	next    influxdb.DeleteService
+	buckets BucketFinder
+}
+
+// NewDeleteService wraps next so that the data of archived buckets cannot be
+// deleted.
+func NewDeleteService(next influxdb.DeleteService, buckets BucketFinder) *DeleteService {
+	return &DeleteService{
+		next:    next,
+		buckets: buckets,
+	}
+}
+
+// DeleteBucketRangePredicate deletes data in [min, max] matching pred unless
+// the bucket is archived.
+func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
+	if err := checkWritable(ctx, s.buckets, bucketID, "delete"); err != nil {
+		return err
+	}
+	return s.next.DeleteBucketRangePredicate(ctx, orgID, bucketID, min, max, pred)
+}
+
+var _ influxdb.MeasurementDeleteService = (*MeasurementDeleteService)(nil)
+
+// MeasurementDeleteService refuses the deletes of the measurements of archived
+// buckets.
+type MeasurementDeleteService struct {
+	next    influxdb.MeasurementDeleteService
+	buckets BucketFinder
+}
+
+// NewMeasurementDeleteService wraps next so that the measurements of archived
+// buckets cannot be deleted.
+func NewMeasurementDeleteService(next influxdb.MeasurementDeleteService, buckets BucketFinder) *MeasurementDeleteService {
+	return &MeasurementDeleteService{
+		next:    next,
+		buckets: buckets,
+	}
+}
+
+// DeleteMeasurementRange deletes the measurement data in [min, max] unless the
+// bucket is archived.
+func (s *MeasurementDeleteService) DeleteMeasurementRange(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, min, max int64) error {
+	if err := checkWritable(ctx, s.buckets, bucketID, "delete of measurement "+measurement); err != nil {
+		return err
+	}
+	return s.next.DeleteMeasurementRange(ctx, orgID, bucketID, measurement, min, max)
+}
+
+var _ influxdb.BucketService = (*BucketService)(nil)
+
+// BucketService refuses to delete archived buckets, which must be made active
+// again first.
+type BucketService struct {
+	influxdb.BucketService
+}
+
+// NewBucketService wraps s so that archived buckets cannot be deleted.
+func NewBucketService(s influxdb.BucketService) *BucketService {
+	return &BucketService{BucketService: s}
+}
+
+// DeleteBucket removes a bucket by ID unless it is archived.
+func (s *BucketService) DeleteBucket(ctx context.Context, id influxdb.ID) error {
+	if err := checkWritable(ctx, s.BucketService, id, "bucket deletion"); err != nil {
+		return err
+	}
+	return s.BucketService.DeleteBucket(ctx, id)
+}
@@ -0,0 +1,118 @@
+package bucketstate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+const (
+	orgID      influxdb.ID = 1
+	activeID   influxdb.ID = 2
+	archivedID influxdb.ID = 3
+)
+
+func newBucketService() *mock.BucketService {
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		switch id {
+		case activeID:
+			return &influxdb.Bucket{ID: id, OrgID: orgID, Name: "active"}, nil
+		case archivedID:
+			return &influxdb.Bucket{ID: id, OrgID: orgID, Name: "archived", State: influxdb.BucketStateArchived}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+	}
+	return buckets
+}
+
+func point(bucketID influxdb.ID) models.Point {
+	return models.MustNewPoint(tsdb.EncodeNameString(orgID, bucketID), nil, models.Fields{"f": 1.0}, time.Unix(0, 0))
+}
+
+func TestPointsWriter(t *testing.T) {
+	next := &mock.PointsWriter{}
+	w := NewPointsWriter(next, newBucketService())
+	ctx := context.Background()
+
+	if err := w.WritePoints(ctx, []models.Point{point(activeID)}); err != nil {
+		t.Fatalf("unexpected error writing to an active bucket: %v", err)
+	}
+	err := w.WritePoints(ctx, []models.Point{point(activeID), point(archivedID)})
+	if influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("expected a forbidden error writing to an archived bucket, got %v", err)
+	}
+	if len(next.Points) != 1 {
+		t.Errorf("expected only the point of the active bucket written, got %d points", len(next.Points))
+	}
+}
+
+func TestDeleteService(t *testing.T) {
+	deleted := 0
+	next := &mock.DeleteService{
+		DeleteBucketRangePredicateF: func(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
+			deleted++
+			return nil
+		},
+	}
+	s := NewDeleteService(next, newBucketService())
+	ctx := context.Background()
+
+	if err := s.DeleteBucketRangePredicate(ctx, orgID, activeID, 0, 1, nil); err != nil {
+		t.Fatalf("unexpected error deleting from an active bucket: %v", err)
+	}
+	if err := s.DeleteBucketRangePredicate(ctx, orgID, archivedID, 0, 1, nil); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("expected a forbidden error deleting from an archived bucket, got %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected one delete, got %d", deleted)
+	}
+}
+
+func TestBucketService_DeleteBucket(t *testing.T) {
+	buckets := newBucketService()
+	buckets.DeleteBucketFn = func(context.Context, influxdb.ID) error { return nil }
+	s := NewBucketService(buckets)
+	ctx := context.Background()
+
+	if err := s.DeleteBucket(ctx, activeID); err != nil {
+		t.Fatalf("unexpected error deleting an active bucket: %v", err)
+	}
+	if err := s.DeleteBucket(ctx, archivedID); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("expected a forbidden error deleting an archived bucket, got %v", err)
+	}
+}
+
+func TestReadThrottle(t *testing.T) {
+	throttle := NewReadThrottle(newBucketService(), 1)
+
+	release, err := throttle.Acquire(context.Background(), archivedID)
+	if err != nil {
+		t.Fatalf("unexpected error acquiring a read of an archived bucket: %v", err)
+	}
+
+	// Reads of active buckets are not throttled.
+	activeRelease, err := throttle.Acquire(context.Background(), activeID)
+	if err != nil {
+		t.Fatalf("unexpected error acquiring a read of an active bucket: %v", err)
+	}
+	activeRelease()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := throttle.Acquire(ctx, archivedID); err != context.DeadlineExceeded {
+		t.Fatalf("expected the second read of an archived bucket to wait, got %v", err)
+	}
+
+	release()
+	second, err := throttle.Acquire(context.Background(), archivedID)
+	if err != nil {
+		t.Fatalf("unexpected error acquiring a released read: %v", err)
+	}
+	second()
+}
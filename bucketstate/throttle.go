@@ -0,0 +1,50 @@
+package bucketstate
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// DefaultArchivedReadConcurrency is the default number of reads of archived
+// buckets run at once.
+const DefaultArchivedReadConcurrency = 1
+
+// ReadThrottle lowers the priority of the reads of archived buckets by
+// running at most a given number of them at once, so that they do not take
+// the resources of the reads of active buckets. The reads of active buckets
+// are not throttled.
+type ReadThrottle struct {
+	buckets BucketFinder
+	slots   chan struct{}
+}
+
+// NewReadThrottle returns a throttle running at most concurrency reads of
+// archived buckets at once, or DefaultArchivedReadConcurrency if it is not
+// positive.
+func NewReadThrottle(buckets BucketFinder, concurrency int) *ReadThrottle {
+	if concurrency <= 0 {
+		concurrency = DefaultArchivedReadConcurrency
+	}
+	return &ReadThrottle{
+		buckets: buckets,
+		slots:   make(chan struct{}, concurrency),
+	}
+}
+
+// Acquire blocks until bucketID can be read, or ctx is done. release must be
+// called once the read is done. Buckets that cannot be found are read as
+// active buckets, the read reports the error if need be.
+func (t *ReadThrottle) Acquire(ctx context.Context, bucketID influxdb.ID) (release func(), err error) {
+	b, err := t.buckets.FindBucketByID(ctx, bucketID)
+	if err != nil || !b.Archived() {
+		return func() {}, nil
+	}
+
+	select {
+	case t.slots <- struct{}{}:
+		return func() { <-t.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,217 @@
+// Package celltransform applies the transforms of dashboard cells to the
+// Flux queries of their views. The transforms are appended to every
+// expression statement of a query, before its final yield, so that they are
+// evaluated by the server along with the query.
+package celltransform
+
+import (
+	"sort"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+// Apply returns script with the transforms appended to each of its
+// expression statements. A script that does not parse results in an
+// EInvalid error.
+func Apply(lang influxdb.FluxLanguageService, script string, transforms []influxdb.CellTransform) (string, error) {
+	if len(transforms) == 0 {
+		return script, nil
+	}
+	if err := influxdb.ValidCellTransforms(transforms); err != nil {
+		return "", err
+	}
+
+	pkg, err := query.Parse(lang, script)
+	if err != nil {
+		return "", &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid flux script",
+			Err:  err,
+		}
+	}
+
+	for _, f := range pkg.Files {
+		for _, stmt := range f.Body {
+			es, ok := stmt.(*ast.ExpressionStatement)
+			if !ok {
+				continue
+			}
+			es.Expression = appendTransforms(es.Expression, transforms)
+		}
+	}
+
+	if len(pkg.Files) != 1 {
+		return ast.Format(pkg), nil
+	}
+	return ast.Format(pkg.Files[0]), nil
+}
+
+// ViewQueries returns the queries of the view properties, nil for the views
+// without queries.
+func ViewQueries(p influxdb.ViewProperties) []influxdb.DashboardQuery {
+	switch p := p.(type) {
+	case influxdb.XYViewProperties:
+		return p.Queries
+	case influxdb.LinePlusSingleStatProperties:
+		return p.Queries
+	case influxdb.BandViewProperties:
+		return p.Queries
+	case influxdb.SingleStatViewProperties:
+		return p.Queries
+	case influxdb.GaugeViewProperties:
+		return p.Queries
+	case influxdb.TableViewProperties:
+		return p.Queries
+	case influxdb.HistogramViewProperties:
+		return p.Queries
+	case influxdb.HeatmapViewProperties:
+		return p.Queries
+	case influxdb.ScatterViewProperties:
+		return p.Queries
+	case influxdb.MosaicViewProperties:
+		return p.Queries
+	case influxdb.CheckViewProperties:
+		return p.Queries
+	}
+	return nil
+}
+
+// appendTransforms pipes expr into the transforms. The transforms of an
+// expression ending with a yield are inserted before it, so that the
+// transformed results keep their name.
+func appendTransforms(expr ast.Expression, transforms []influxdb.CellTransform) ast.Expression {
+	if pipe, ok := expr.(*ast.PipeExpression); ok && isYield(pipe.Call) {
+		pipe.Argument = appendTransforms(pipe.Argument, transforms)
+		return pipe
+	}
+	for _, t := range transforms {
+		expr = &ast.PipeExpression{
+			Argument: expr,
+			Call:     transformCall(t),
+		}
+	}
+	return expr
+}
+
+func isYield(call *ast.CallExpression) bool {
+	id, ok := call.Callee.(*ast.Identifier)
+	return ok && id.Name == "yield"
+}
+
+func transformCall(t influxdb.CellTransform) *ast.CallExpression {
+	column := t.Column
+	if column == "" {
+		column = influxdb.DefaultCellTransformColumn
+	}
+
+	switch t.Type {
+	case influxdb.CellTransformRename:
+		return call("rename", property("fn", function("column", renameBody(t.Columns))))
+	case influxdb.CellTransformUnit:
+		var value ast.Expression = &ast.BinaryExpression{
+			Operator: ast.MultiplicationOperator,
+			Left: call("float", property("v", &ast.MemberExpression{
+				Object:   &ast.Identifier{Name: "r"},
+				Property: &ast.StringLiteral{Value: column},
+			})),
+			Right: &ast.FloatLiteral{Value: t.Factor},
+		}
+		if t.Offset != 0 {
+			value = &ast.BinaryExpression{
+				Operator: ast.AdditionOperator,
+				Left:     value,
+				Right:    &ast.FloatLiteral{Value: t.Offset},
+			}
+		}
+		return call("map", property("fn", function("r", &ast.ObjectExpression{
+			With:       &ast.Identifier{Name: "r"},
+			Properties: []*ast.Property{property(column, value)},
+		})))
+	default:
+		name := "top"
+		if t.Bottom {
+			name = "bottom"
+		}
+		return call(name,
+			property("n", &ast.IntegerLiteral{Value: t.N}),
+			property("columns", &ast.ArrayExpression{
+				Elements: []ast.Expression{&ast.StringLiteral{Value: column}},
+			}),
+		)
+	}
+}
+
+// renameBody returns the conditional expression mapping the renamed columns
+// to their new names and the other columns to themselves.
+func renameBody(columns map[string]string) ast.Expression {
+	from := make([]string, 0, len(columns))
+	for k := range columns {
+		from = append(from, k)
+	}
+	sort.Strings(from)
+
+	var body ast.Expression = &ast.Identifier{Name: "column"}
+	for i := len(from) - 1; i >= 0; i-- {
+		body = &ast.ConditionalExpression{
+			Test: &ast.BinaryExpression{
+				Operator: ast.EqualOperator,
+				Left:     &ast.Identifier{Name: "column"},
+				Right:    &ast.StringLiteral{Value: from[i]},
+			},
+			Consequent: &ast.StringLiteral{Value: columns[from[i]]},
+			Alternate:  body,
+		}
+	}
+	return body
+}
+
+func call(name string, properties ...*ast.Property) *ast.CallExpression {
+	return &ast.CallExpression{
+		Callee: &ast.Identifier{Name: name},
+		Arguments: []ast.Expression{
+			&ast.ObjectExpression{Properties: properties},
+		},
+	}
+}
+
+func function(param string, body ast.Expression) *ast.FunctionExpression {
+	return &ast.FunctionExpression{
+		Params: []*ast.Property{{Key: &ast.Identifier{Name: param}}},
+		Body:   body,
+	}
+}
+
+// property returns a property keyed by an identifier, or by a string
+// literal for the keys that are not valid identifiers.
+func property(key string, value ast.Expression) *ast.Property {
+	var k ast.PropertyKey = &ast.StringLiteral{Value: key}
+	if isIdentifier(key) {
+		k = &ast.Identifier{Name: key}
+	}
+	return &ast.Property{Key: k, Value: value}
+}
+
+// keywords are the reserved words of Flux, which are not identifiers.
+var keywords = map[string]bool{
+	"and": true, "or": true, "not": true, "empty": true, "in": true,
+	"import": true, "package": true, "return": true, "option": true,
+	"builtin": true, "test": true, "if": true, "then": true, "else": true,
+	"with": true, "exists": true,
+}
+
+func isIdentifier(s string) bool {
+	if s == "" || keywords[s] {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && c >= '0' && c <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
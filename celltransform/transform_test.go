@@ -0,0 +1,83 @@
+package celltransform_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/celltransform"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		transforms []influxdb.CellTransform
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:   "no transforms",
+			script: "from(bucket:\"b\")   |>range(start:-1h)",
+			want:   "from(bucket:\"b\")   |>range(start:-1h)",
+		},
+		{
+			name:   "rename",
+			script: `from(bucket: "b") |> range(start: -1h)`,
+			transforms: []influxdb.CellTransform{
+				{Type: influxdb.CellTransformRename, Columns: map[string]string{"_value": "temp", "a b": "c"}},
+			},
+			want: "from(bucket: \"b\")\n\t|> range(start: -1h)\n\t|> rename(fn: (column) =>\n\t\t(if column == \"_value\" then \"temp\" else if column == \"a b\" then \"c\" else column))",
+		},
+		{
+			name:   "unit before yield",
+			script: `from(bucket: "b") |> range(start: -1h) |> yield(name: "celsius")`,
+			transforms: []influxdb.CellTransform{
+				{Type: influxdb.CellTransformUnit, Factor: 1.8, Offset: 32},
+			},
+			want: "from(bucket: \"b\")\n\t|> range(start: -1h)\n\t|> map(fn: (r) =>\n\t\t({r with _value: float(v: r[\"_value\"]) * 1.8 + 32.0}))\n\t|> yield(name: \"celsius\")",
+		},
+		{
+			name:   "every expression statement",
+			script: "n = 3\nfrom(bucket: \"a\")\nfrom(bucket: \"b\")",
+			transforms: []influxdb.CellTransform{
+				{Type: influxdb.CellTransformTopN, Column: "used", N: 3, Bottom: true},
+			},
+			want: "n = 3\n\nfrom(bucket: \"a\")\n\t|> bottom(n: 3, columns: [\"used\"])\nfrom(bucket: \"b\")\n\t|> bottom(n: 3, columns: [\"used\"])",
+		},
+		{
+			name:   "invalid transform",
+			script: `from(bucket: "b")`,
+			transforms: []influxdb.CellTransform{
+				{Type: influxdb.CellTransformTopN},
+			},
+			wantErr: true,
+		},
+		{
+			name:   "syntax error",
+			script: "from(bucket: ",
+			transforms: []influxdb.CellTransform{
+				{Type: influxdb.CellTransformTopN, N: 1},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := celltransform.Apply(fluxlang.DefaultService, tt.script, tt.transforms)
+			if tt.wantErr {
+				if influxdb.ErrorCode(err) != influxdb.EInvalid {
+					t.Fatalf("expected invalid error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("unexpected script:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -25,7 +25,7 @@ type BuildStore struct {
 // Get retrieves Chronograf build information from the database
 func (s *BuildStore) Get(ctx context.Context) (chronograf.BuildInfo, error) {
 	var build chronograf.BuildInfo
-	if err := s.client.db.View(func(tx *bolt.Tx) error {
+	if err := s.client.view(func(tx *bolt.Tx) error {
 		var err error
 		build, err = s.get(ctx, tx)
 		if err != nil {
@@ -41,7 +41,7 @@ func (s *BuildStore) Get(ctx context.Context) (chronograf.BuildInfo, error) {
 
 // Update overwrites the current Chronograf build information in the database
 func (s *BuildStore) Update(ctx context.Context, build chronograf.BuildInfo) error {
-	if err := s.client.db.Update(func(tx *bolt.Tx) error {
+	if err := s.client.update(func(tx *bolt.Tx) error {
 		return s.update(ctx, build, tx)
 	}); err != nil {
 		return err
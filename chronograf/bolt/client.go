@@ -6,16 +6,20 @@ import (
 	"io"
 	"os"
 	"path"
+	"sync"
 	"time"
 
 	bolt "github.com/coreos/bbolt"
+	ibolt "github.com/influxdata/influxdb/v2/bolt"
 	"github.com/influxdata/influxdb/v2/chronograf"
 	"github.com/influxdata/influxdb/v2/chronograf/id"
 )
 
 // Client is a client for the boltDB data store.
 type Client struct {
-	Path      string
+	Path string
+	// mu guards db, which is replaced when the database is compacted.
+	mu        sync.RWMutex
 	db        *bolt.DB
 	logger    chronograf.Logger
 	isNew     bool
@@ -56,12 +60,33 @@ func NewClient() *Client {
 	return c
 }
 
-// WithDB sets the boltdb database for a client. It should not be called
-// after a call to Open.
+// WithDB sets the boltdb database for a client. Once the client is open,
+// it replaces its database, such as when the database was compacted.
 func (c *Client) WithDB(db *bolt.DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.db = db
 }
 
+func (c *Client) current() *bolt.DB {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.db
+}
+
+// view runs fn in a read transaction of the database of the client,
+// retrying it on the new database if it was replaced and closed.
+func (c *Client) view(fn func(tx *bolt.Tx) error) error {
+	return ibolt.View(c.current, fn)
+}
+
+// update runs fn in a write transaction of the database of the client. The
+// transactions begun on a database that was replaced are rolled back before
+// fn is called and retried on the new database.
+func (c *Client) update(fn func(tx *bolt.Tx) error) error {
+	return ibolt.Update(c.current, fn)
+}
+
 // Option to change behavior of Open()
 type Option interface {
 	Backup() bool
@@ -119,7 +144,7 @@ func (c *Client) Open(ctx context.Context, logger chronograf.Logger, build chron
 
 // initialize creates Buckets that are missing
 func (c *Client) initialize(ctx context.Context) error {
-	if err := c.db.Update(func(tx *bolt.Tx) error {
+	if err := c.update(func(tx *bolt.Tx) error {
 		// Always create SchemaVersions bucket.
 		if _, err := tx.CreateBucketIfNotExists(SchemaVersionBucket); err != nil {
 			return err
@@ -211,8 +236,8 @@ func (c *Client) migrate(ctx context.Context, build chronograf.BuildInfo) error
 
 // Close the connection to the bolt database
 func (c *Client) Close() error {
-	if c.db != nil {
-		return c.db.Close()
+	if db := c.current(); db != nil {
+		return db.Close()
 	}
 	return nil
 }
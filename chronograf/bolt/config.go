@@ -42,7 +42,7 @@ func (s *ConfigStore) Initialize(ctx context.Context) error {
 
 func (s *ConfigStore) Get(ctx context.Context) (*chronograf.Config, error) {
 	var cfg chronograf.Config
-	err := s.client.db.View(func(tx *bolt.Tx) error {
+	err := s.client.view(func(tx *bolt.Tx) error {
 		v := tx.Bucket(ConfigBucket).Get(configID)
 		if v == nil {
 			return chronograf.ErrConfigNotFound
@@ -60,7 +60,7 @@ func (s *ConfigStore) Update(ctx context.Context, cfg *chronograf.Config) error
 	if cfg == nil {
 		return fmt.Errorf("config provided was nil")
 	}
-	return s.client.db.Update(func(tx *bolt.Tx) error {
+	return s.client.update(func(tx *bolt.Tx) error {
 		if v, err := internal.MarshalConfig(cfg); err != nil {
 			return err
 		} else if err := tx.Bucket(ConfigBucket).Put(configID, v); err != nil {
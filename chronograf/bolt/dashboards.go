@@ -78,7 +78,7 @@ func (d *DashboardsStore) Migrate(ctx context.Context) error {
 // All returns all known dashboards
 func (d *DashboardsStore) All(ctx context.Context) ([]chronograf.Dashboard, error) {
 	var srcs []chronograf.Dashboard
-	if err := d.client.db.View(func(tx *bolt.Tx) error {
+	if err := d.client.view(func(tx *bolt.Tx) error {
 		if err := tx.Bucket(DashboardsBucket).ForEach(func(k, v []byte) error {
 			var src chronograf.Dashboard
 			if err := internal.UnmarshalDashboard(v, &src); err != nil {
@@ -99,7 +99,7 @@ func (d *DashboardsStore) All(ctx context.Context) ([]chronograf.Dashboard, erro
 
 // Add creates a new Dashboard in the DashboardsStore
 func (d *DashboardsStore) Add(ctx context.Context, src chronograf.Dashboard) (chronograf.Dashboard, error) {
-	if err := d.client.db.Update(func(tx *bolt.Tx) error {
+	if err := d.client.update(func(tx *bolt.Tx) error {
 		b := tx.Bucket(DashboardsBucket)
 		id, _ := b.NextSequence()
 
@@ -129,7 +129,7 @@ func (d *DashboardsStore) Add(ctx context.Context, src chronograf.Dashboard) (ch
 // Get returns a Dashboard if the id exists.
 func (d *DashboardsStore) Get(ctx context.Context, id chronograf.DashboardID) (chronograf.Dashboard, error) {
 	var src chronograf.Dashboard
-	if err := d.client.db.View(func(tx *bolt.Tx) error {
+	if err := d.client.view(func(tx *bolt.Tx) error {
 		strID := strconv.Itoa(int(id))
 		if v := tx.Bucket(DashboardsBucket).Get([]byte(strID)); v == nil {
 			return chronograf.ErrDashboardNotFound
@@ -146,7 +146,7 @@ func (d *DashboardsStore) Get(ctx context.Context, id chronograf.DashboardID) (c
 
 // Delete the dashboard from DashboardsStore
 func (d *DashboardsStore) Delete(ctx context.Context, dash chronograf.Dashboard) error {
-	if err := d.client.db.Update(func(tx *bolt.Tx) error {
+	if err := d.client.update(func(tx *bolt.Tx) error {
 		strID := strconv.Itoa(int(dash.ID))
 		if err := tx.Bucket(DashboardsBucket).Delete([]byte(strID)); err != nil {
 			return err
@@ -161,7 +161,7 @@ func (d *DashboardsStore) Delete(ctx context.Context, dash chronograf.Dashboard)
 
 // Update the dashboard in DashboardsStore
 func (d *DashboardsStore) Update(ctx context.Context, dash chronograf.Dashboard) error {
-	if err := d.client.db.Update(func(tx *bolt.Tx) error {
+	if err := d.client.update(func(tx *bolt.Tx) error {
 		// Get an existing dashboard with the same ID.
 		b := tx.Bucket(DashboardsBucket)
 		strID := strconv.Itoa(int(dash.ID))
@@ -27,7 +27,7 @@ func (s *LayoutsStore) Migrate(ctx context.Context) error {
 // All returns all known layouts
 func (s *LayoutsStore) All(ctx context.Context) ([]chronograf.Layout, error) {
 	var srcs []chronograf.Layout
-	if err := s.client.db.View(func(tx *bolt.Tx) error {
+	if err := s.client.view(func(tx *bolt.Tx) error {
 		if err := tx.Bucket(LayoutsBucket).ForEach(func(k, v []byte) error {
 			var src chronograf.Layout
 			if err := internal.UnmarshalLayout(v, &src); err != nil {
@@ -49,7 +49,7 @@ func (s *LayoutsStore) All(ctx context.Context) ([]chronograf.Layout, error) {
 
 // Add creates a new Layout in the LayoutsStore.
 func (s *LayoutsStore) Add(ctx context.Context, src chronograf.Layout) (chronograf.Layout, error) {
-	if err := s.client.db.Update(func(tx *bolt.Tx) error {
+	if err := s.client.update(func(tx *bolt.Tx) error {
 		b := tx.Bucket(LayoutsBucket)
 		id, err := s.IDs.Generate()
 		if err != nil {
@@ -76,7 +76,7 @@ func (s *LayoutsStore) Delete(ctx context.Context, src chronograf.Layout) error
 	if err != nil {
 		return err
 	}
-	if err := s.client.db.Update(func(tx *bolt.Tx) error {
+	if err := s.client.update(func(tx *bolt.Tx) error {
 		if err := tx.Bucket(LayoutsBucket).Delete([]byte(src.ID)); err != nil {
 			return err
 		}
@@ -91,7 +91,7 @@ func (s *LayoutsStore) Delete(ctx context.Context, src chronograf.Layout) error
 // Get returns a Layout if the id exists.
 func (s *LayoutsStore) Get(ctx context.Context, id string) (chronograf.Layout, error) {
 	var src chronograf.Layout
-	if err := s.client.db.View(func(tx *bolt.Tx) error {
+	if err := s.client.view(func(tx *bolt.Tx) error {
 		if v := tx.Bucket(LayoutsBucket).Get([]byte(id)); v == nil {
 			return chronograf.ErrLayoutNotFound
 		} else if err := internal.UnmarshalLayout(v, &src); err != nil {
@@ -107,7 +107,7 @@ func (s *LayoutsStore) Get(ctx context.Context, id string) (chronograf.Layout, e
 
 // Update a Layout
 func (s *LayoutsStore) Update(ctx context.Context, src chronograf.Layout) error {
-	if err := s.client.db.Update(func(tx *bolt.Tx) error {
+	if err := s.client.update(func(tx *bolt.Tx) error {
 		// Get an existing layout with the same ID.
 		b := tx.Bucket(LayoutsBucket)
 		if v := b.Get([]byte(src.ID)); v == nil {
@@ -29,7 +29,7 @@ func (s *MappingsStore) Migrate(ctx context.Context) error {
 
 // Add creates a new Mapping in the MappingsStore
 func (s *MappingsStore) Add(ctx context.Context, o *chronograf.Mapping) (*chronograf.Mapping, error) {
-	err := s.client.db.Update(func(tx *bolt.Tx) error {
+	err := s.client.update(func(tx *bolt.Tx) error {
 		b := tx.Bucket(MappingsBucket)
 		seq, err := b.NextSequence()
 		if err != nil {
@@ -72,7 +72,7 @@ func (s *MappingsStore) Delete(ctx context.Context, o *chronograf.Mapping) error
 	if err != nil {
 		return err
 	}
-	if err := s.client.db.Update(func(tx *bolt.Tx) error {
+	if err := s.client.update(func(tx *bolt.Tx) error {
 		return tx.Bucket(MappingsBucket).Delete([]byte(o.ID))
 	}); err != nil {
 		return err
@@ -82,7 +82,7 @@ func (s *MappingsStore) Delete(ctx context.Context, o *chronograf.Mapping) error
 
 func (s *MappingsStore) get(ctx context.Context, id string) (*chronograf.Mapping, error) {
 	var o chronograf.Mapping
-	err := s.client.db.View(func(tx *bolt.Tx) error {
+	err := s.client.view(func(tx *bolt.Tx) error {
 		v := tx.Bucket(MappingsBucket).Get([]byte(id))
 		if v == nil {
 			return chronograf.ErrMappingNotFound
@@ -98,7 +98,7 @@ func (s *MappingsStore) get(ctx context.Context, id string) (*chronograf.Mapping
 }
 
 func (s *MappingsStore) each(ctx context.Context, fn func(*chronograf.Mapping)) error {
-	return s.client.db.View(func(tx *bolt.Tx) error {
+	return s.client.view(func(tx *bolt.Tx) error {
 		return tx.Bucket(MappingsBucket).ForEach(func(k, v []byte) error {
 			var m chronograf.Mapping
 			if err := internal.UnmarshalMapping(v, &m); err != nil {
@@ -117,7 +117,7 @@ func (s *MappingsStore) Get(ctx context.Context, id string) (*chronograf.Mapping
 
 // Update the organization in MappingsStore
 func (s *MappingsStore) Update(ctx context.Context, o *chronograf.Mapping) error {
-	return s.client.db.Update(func(tx *bolt.Tx) error {
+	return s.client.update(func(tx *bolt.Tx) error {
 		if v, err := internal.MarshalMapping(o); err != nil {
 			return err
 		} else if err := tx.Bucket(MappingsBucket).Put([]byte(o.ID), v); err != nil {
@@ -28,7 +28,7 @@ func (s *OrganizationConfigStore) Migrate(ctx context.Context) error {
 func (s *OrganizationConfigStore) Get(ctx context.Context, orgID string) (*chronograf.OrganizationConfig, error) {
 	var c chronograf.OrganizationConfig
 
-	err := s.client.db.View(func(tx *bolt.Tx) error {
+	err := s.client.view(func(tx *bolt.Tx) error {
 		return s.get(ctx, tx, orgID, &c)
 	})
 
@@ -50,7 +50,7 @@ func (s *OrganizationConfigStore) get(ctx context.Context, tx *bolt.Tx, orgID st
 // FindOrCreate gets an OrganizationConfig from the store or creates one if none exists for this organization
 func (s *OrganizationConfigStore) FindOrCreate(ctx context.Context, orgID string) (*chronograf.OrganizationConfig, error) {
 	var c chronograf.OrganizationConfig
-	err := s.client.db.Update(func(tx *bolt.Tx) error {
+	err := s.client.update(func(tx *bolt.Tx) error {
 		err := s.get(ctx, tx, orgID, &c)
 		if err == chronograf.ErrOrganizationConfigNotFound {
 			c = newOrganizationConfig(orgID)
@@ -67,7 +67,7 @@ func (s *OrganizationConfigStore) FindOrCreate(ctx context.Context, orgID string
 
 // Put replaces the OrganizationConfig in the store
 func (s *OrganizationConfigStore) Put(ctx context.Context, c *chronograf.OrganizationConfig) error {
-	return s.client.db.Update(func(tx *bolt.Tx) error {
+	return s.client.update(func(tx *bolt.Tx) error {
 		return s.put(ctx, tx, c)
 	})
 }
@@ -52,7 +52,7 @@ func (s *OrganizationsStore) CreateDefault(ctx context.Context) error {
 		Scheme:               chronograf.MappingWildcard,
 		ProviderOrganization: chronograf.MappingWildcard,
 	}
-	return s.client.db.Update(func(tx *bolt.Tx) error {
+	return s.client.update(func(tx *bolt.Tx) error {
 		b := tx.Bucket(OrganizationsBucket)
 		v := b.Get(DefaultOrganizationID)
 		if v != nil {
@@ -92,7 +92,7 @@ func (s *OrganizationsStore) nameIsUnique(ctx context.Context, name string) bool
 // DefaultOrganizationID returns the ID of the default organization
 func (s *OrganizationsStore) DefaultOrganization(ctx context.Context) (*chronograf.Organization, error) {
 	var org chronograf.Organization
-	if err := s.client.db.View(func(tx *bolt.Tx) error {
+	if err := s.client.view(func(tx *bolt.Tx) error {
 		v := tx.Bucket(OrganizationsBucket).Get(DefaultOrganizationID)
 		return internal.UnmarshalOrganization(v, &org)
 	}); err != nil {
@@ -107,7 +107,7 @@ func (s *OrganizationsStore) Add(ctx context.Context, o *chronograf.Organization
 	if !s.nameIsUnique(ctx, o.Name) {
 		return nil, chronograf.ErrOrganizationAlreadyExists
 	}
-	err := s.client.db.Update(func(tx *bolt.Tx) error {
+	err := s.client.update(func(tx *bolt.Tx) error {
 		b := tx.Bucket(OrganizationsBucket)
 		seq, err := b.NextSequence()
 		if err != nil {
@@ -149,7 +149,7 @@ func (s *OrganizationsStore) Delete(ctx context.Context, o *chronograf.Organizat
 	if err != nil {
 		return err
 	}
-	if err := s.client.db.Update(func(tx *bolt.Tx) error {
+	if err := s.client.update(func(tx *bolt.Tx) error {
 		return tx.Bucket(OrganizationsBucket).Delete([]byte(o.ID))
 	}); err != nil {
 		return err
@@ -222,7 +222,7 @@ func (s *OrganizationsStore) Delete(ctx context.Context, o *chronograf.Organizat
 
 func (s *OrganizationsStore) get(ctx context.Context, id string) (*chronograf.Organization, error) {
 	var o chronograf.Organization
-	err := s.client.db.View(func(tx *bolt.Tx) error {
+	err := s.client.view(func(tx *bolt.Tx) error {
 		v := tx.Bucket(OrganizationsBucket).Get([]byte(id))
 		if v == nil {
 			return chronograf.ErrOrganizationNotFound
@@ -238,7 +238,7 @@ func (s *OrganizationsStore) get(ctx context.Context, id string) (*chronograf.Or
 }
 
 func (s *OrganizationsStore) each(ctx context.Context, fn func(*chronograf.Organization)) error {
-	return s.client.db.View(func(tx *bolt.Tx) error {
+	return s.client.view(func(tx *bolt.Tx) error {
 		return tx.Bucket(OrganizationsBucket).ForEach(func(k, v []byte) error {
 			var org chronograf.Organization
 			if err := internal.UnmarshalOrganization(v, &org); err != nil {
@@ -293,7 +293,7 @@ func (s *OrganizationsStore) Update(ctx context.Context, o *chronograf.Organizat
 	if o.Name != org.Name && !s.nameIsUnique(ctx, o.Name) {
 		return chronograf.ErrOrganizationAlreadyExists
 	}
-	return s.client.db.Update(func(tx *bolt.Tx) error {
+	return s.client.update(func(tx *bolt.Tx) error {
 		if v, err := internal.MarshalOrganization(o); err != nil {
 			return err
 		} else if err := tx.Bucket(OrganizationsBucket).Put([]byte(o.ID), v); err != nil {
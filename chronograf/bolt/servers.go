@@ -46,7 +46,7 @@ func (s *ServersStore) Migrate(ctx context.Context) error {
 // All returns all known servers
 func (s *ServersStore) All(ctx context.Context) ([]chronograf.Server, error) {
 	var srcs []chronograf.Server
-	if err := s.client.db.View(func(tx *bolt.Tx) error {
+	if err := s.client.view(func(tx *bolt.Tx) error {
 		var err error
 		srcs, err = s.all(ctx, tx)
 		if err != nil {
@@ -63,7 +63,7 @@ func (s *ServersStore) All(ctx context.Context) ([]chronograf.Server, error) {
 
 // Add creates a new Server in the ServerStore.
 func (s *ServersStore) Add(ctx context.Context, src chronograf.Server) (chronograf.Server, error) {
-	if err := s.client.db.Update(func(tx *bolt.Tx) error {
+	if err := s.client.update(func(tx *bolt.Tx) error {
 		b := tx.Bucket(ServersBucket)
 		seq, err := b.NextSequence()
 		if err != nil {
@@ -90,7 +90,7 @@ func (s *ServersStore) Add(ctx context.Context, src chronograf.Server) (chronogr
 
 // Delete removes the Server from the ServersStore
 func (s *ServersStore) Delete(ctx context.Context, src chronograf.Server) error {
-	if err := s.client.db.Update(func(tx *bolt.Tx) error {
+	if err := s.client.update(func(tx *bolt.Tx) error {
 		if err := tx.Bucket(ServersBucket).Delete(itob(src.ID)); err != nil {
 			return err
 		}
@@ -105,7 +105,7 @@ func (s *ServersStore) Delete(ctx context.Context, src chronograf.Server) error
 // Get returns a Server if the id exists.
 func (s *ServersStore) Get(ctx context.Context, id int) (chronograf.Server, error) {
 	var src chronograf.Server
-	if err := s.client.db.View(func(tx *bolt.Tx) error {
+	if err := s.client.view(func(tx *bolt.Tx) error {
 		if v := tx.Bucket(ServersBucket).Get(itob(id)); v == nil {
 			return chronograf.ErrServerNotFound
 		} else if err := internal.UnmarshalServer(v, &src); err != nil {
@@ -121,7 +121,7 @@ func (s *ServersStore) Get(ctx context.Context, id int) (chronograf.Server, erro
 
 // Update a Server
 func (s *ServersStore) Update(ctx context.Context, src chronograf.Server) error {
-	if err := s.client.db.Update(func(tx *bolt.Tx) error {
+	if err := s.client.update(func(tx *bolt.Tx) error {
 		// Get an existing server with the same ID.
 		b := tx.Bucket(ServersBucket)
 		if v := b.Get(itob(src.ID)); v == nil {
@@ -65,7 +65,7 @@ func (s *SourcesStore) Migrate(ctx context.Context) error {
 // All returns all known sources
 func (s *SourcesStore) All(ctx context.Context) ([]chronograf.Source, error) {
 	var srcs []chronograf.Source
-	if err := s.client.db.View(func(tx *bolt.Tx) error {
+	if err := s.client.view(func(tx *bolt.Tx) error {
 		var err error
 		srcs, err = s.all(ctx, tx)
 		if err != nil {
@@ -90,7 +90,7 @@ func (s *SourcesStore) Add(ctx context.Context, src chronograf.Source) (chronogr
 		src.Default = true
 	}
 
-	if err := s.client.db.Update(func(tx *bolt.Tx) error {
+	if err := s.client.update(func(tx *bolt.Tx) error {
 		return s.add(ctx, &src, tx)
 	}); err != nil {
 		return chronograf.Source{}, err
@@ -101,7 +101,7 @@ func (s *SourcesStore) Add(ctx context.Context, src chronograf.Source) (chronogr
 
 // Delete removes the Source from the SourcesStore
 func (s *SourcesStore) Delete(ctx context.Context, src chronograf.Source) error {
-	if err := s.client.db.Update(func(tx *bolt.Tx) error {
+	if err := s.client.update(func(tx *bolt.Tx) error {
 		if err := s.setRandomDefault(ctx, src, tx); err != nil {
 			return err
 		}
@@ -116,7 +116,7 @@ func (s *SourcesStore) Delete(ctx context.Context, src chronograf.Source) error
 // Get returns a Source if the id exists.
 func (s *SourcesStore) Get(ctx context.Context, id int) (chronograf.Source, error) {
 	var src chronograf.Source
-	if err := s.client.db.View(func(tx *bolt.Tx) error {
+	if err := s.client.view(func(tx *bolt.Tx) error {
 		var err error
 		src, err = s.get(ctx, id, tx)
 		if err != nil {
@@ -132,7 +132,7 @@ func (s *SourcesStore) Get(ctx context.Context, id int) (chronograf.Source, erro
 
 // Update a Source
 func (s *SourcesStore) Update(ctx context.Context, src chronograf.Source) error {
-	if err := s.client.db.Update(func(tx *bolt.Tx) error {
+	if err := s.client.update(func(tx *bolt.Tx) error {
 		return s.update(ctx, src, tx)
 	}); err != nil {
 		return err
@@ -158,7 +158,7 @@ func (s *SourcesStore) all(ctx context.Context, tx *bolt.Tx) ([]chronograf.Sourc
 
 // Put updates the source.
 func (s *SourcesStore) Put(ctx context.Context, src *chronograf.Source) error {
-	return s.client.db.Update(func(tx *bolt.Tx) error {
+	return s.client.update(func(tx *bolt.Tx) error {
 		return s.put(ctx, src, tx)
 	})
 }
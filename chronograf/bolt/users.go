@@ -23,7 +23,7 @@ type UsersStore struct {
 // get searches the UsersStore for user with id and returns the bolt representation
 func (s *UsersStore) get(ctx context.Context, id uint64) (*chronograf.User, error) {
 	var u chronograf.User
-	err := s.client.db.View(func(tx *bolt.Tx) error {
+	err := s.client.view(func(tx *bolt.Tx) error {
 		v := tx.Bucket(UsersBucket).Get(u64tob(id))
 		if v == nil {
 			return chronograf.ErrUserNotFound
@@ -39,7 +39,7 @@ func (s *UsersStore) get(ctx context.Context, id uint64) (*chronograf.User, erro
 }
 
 func (s *UsersStore) each(ctx context.Context, fn func(*chronograf.User)) error {
-	return s.client.db.View(func(tx *bolt.Tx) error {
+	return s.client.view(func(tx *bolt.Tx) error {
 		return tx.Bucket(UsersBucket).ForEach(func(k, v []byte) error {
 			var user chronograf.User
 			if err := internal.UnmarshalUser(v, &user); err != nil {
@@ -55,7 +55,7 @@ func (s *UsersStore) each(ctx context.Context, fn func(*chronograf.User)) error
 func (s *UsersStore) Num(ctx context.Context) (int, error) {
 	count := 0
 
-	err := s.client.db.View(func(tx *bolt.Tx) error {
+	err := s.client.view(func(tx *bolt.Tx) error {
 		return tx.Bucket(UsersBucket).ForEach(func(k, v []byte) error {
 			count++
 			return nil
@@ -129,7 +129,7 @@ func (s *UsersStore) Add(ctx context.Context, u *chronograf.User) (*chronograf.U
 	if userExists {
 		return nil, chronograf.ErrUserAlreadyExists
 	}
-	if err := s.client.db.Update(func(tx *bolt.Tx) error {
+	if err := s.client.update(func(tx *bolt.Tx) error {
 		b := tx.Bucket(UsersBucket)
 		seq, err := b.NextSequence()
 		if err != nil {
@@ -155,7 +155,7 @@ func (s *UsersStore) Delete(ctx context.Context, u *chronograf.User) error {
 	if err != nil {
 		return err
 	}
-	return s.client.db.Update(func(tx *bolt.Tx) error {
+	return s.client.update(func(tx *bolt.Tx) error {
 		return tx.Bucket(UsersBucket).Delete(u64tob(u.ID))
 	})
 }
@@ -166,7 +166,7 @@ func (s *UsersStore) Update(ctx context.Context, u *chronograf.User) error {
 	if err != nil {
 		return err
 	}
-	return s.client.db.Update(func(tx *bolt.Tx) error {
+	return s.client.update(func(tx *bolt.Tx) error {
 		if v, err := internal.MarshalUser(u); err != nil {
 			return err
 		} else if err := tx.Bucket(UsersBucket).Put(u64tob(u.ID), v); err != nil {
@@ -179,7 +179,7 @@ func (s *UsersStore) Update(ctx context.Context, u *chronograf.User) error {
 // All returns all users
 func (s *UsersStore) All(ctx context.Context) ([]chronograf.User, error) {
 	var users []chronograf.User
-	if err := s.client.db.View(func(tx *bolt.Tx) error {
+	if err := s.client.view(func(tx *bolt.Tx) error {
 		return tx.Bucket(UsersBucket).ForEach(func(k, v []byte) error {
 			var user chronograf.User
 			if err := internal.UnmarshalUser(v, &user); err != nil {
@@ -16,7 +16,6 @@ import (
 	"strconv"
 	"time"
 
-	bbolt "github.com/coreos/bbolt"
 	"github.com/influxdata/influxdb/v2/chronograf"
 	"github.com/influxdata/influxdb/v2/chronograf/bolt"
 	idgen "github.com/influxdata/influxdb/v2/chronograf/id"
@@ -426,10 +425,9 @@ func (s *Server) Serve(ctx context.Context) error {
 	return nil
 }
 
-func NewServiceV2(ctx context.Context, d *bbolt.DB) (*Service, error) {
-	db := bolt.NewClient()
-	db.WithDB(d)
-
+// NewServiceV2 returns the chronograf service of influxdb, storing its
+// resources with db, which must have been given its database with WithDB.
+func NewServiceV2(ctx context.Context, db *bolt.Client) (*Service, error) {
 	if err := db.Open(ctx, nil, chronograf.BuildInfo{}); err != nil {
 		return nil, err
 	}
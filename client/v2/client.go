@@ -0,0 +1,173 @@
+// Package client is a Go client of the InfluxDB v2 API.
+//
+// It writes points in batches with retries, iterates over the records of the
+// annotated CSV results of flux queries, and manages the resources of the
+// server through the same services the influx CLI uses, e.g.
+//
+//	c, err := client.New("http://localhost:8086", token)
+//	w := c.NewWriter("my-org", "my-bucket", client.WriteOptions{})
+//	defer w.Close(ctx)
+//	w.WritePoint(p)
+//
+//	res, err := c.Query(ctx, "my-org", `from(bucket: "my-bucket") |> range(start: -1h)`)
+//	defer res.Close()
+//	for res.Next() {
+//		fmt.Println(res.Record().Time(), res.Record().Value())
+//	}
+//	if err := res.Err(); err != nil { ... }
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/influxdata/influxdb/v2"
+	ihttp "github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+)
+
+const (
+	prefixWrite = "/api/v2/write"
+	prefixQuery = "/api/v2/query"
+)
+
+// Client is a client of the API of an InfluxDB server. It is safe for
+// concurrent use.
+type Client struct {
+	addr       string
+	token      string
+	httpClient *http.Client
+	service    *ihttp.Service
+}
+
+// Option configures a Client.
+type Option func(*options)
+
+type options struct {
+	insecureSkipVerify bool
+	httpClient         *http.Client
+}
+
+// WithInsecureSkipVerify skips the verification of the TLS certificate of
+// the server.
+func WithInsecureSkipVerify(b bool) Option {
+	return func(o *options) {
+		o.insecureSkipVerify = b
+	}
+}
+
+// WithHTTPClient sets the HTTP client the requests are made with.
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = c
+	}
+}
+
+// New returns a client of the server at addr, e.g. http://localhost:8086,
+// authenticating with token.
+func New(addr, token string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "address is invalid",
+			Err:  err,
+		}
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("address %q must be a URL, e.g. http://localhost:8086", addr),
+		}
+	}
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.httpClient == nil {
+		o.httpClient = ihttp.NewClient(u.Scheme, o.insecureSkipVerify)
+	}
+
+	hc, err := ihttp.NewHTTPClient(addr, token, o.insecureSkipVerify, httpc.WithHTTPClient(o.httpClient))
+	if err != nil {
+		return nil, err
+	}
+	svc, err := ihttp.NewService(hc, addr, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		addr:       addr,
+		token:      token,
+		httpClient: o.httpClient,
+		service:    svc,
+	}, nil
+}
+
+// url returns the URL of the API path with the query parameters.
+func (c *Client) url(path string, params url.Values) (string, error) {
+	u, err := ihttp.NewURL(c.addr, path)
+	if err != nil {
+		return "", err
+	}
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
+// Authorizations returns the service managing the authorizations of the
+// server.
+func (c *Client) Authorizations() influxdb.AuthorizationService {
+	return c.service.AuthorizationService
+}
+
+// Buckets returns the service managing the buckets of the server.
+func (c *Client) Buckets() influxdb.BucketService {
+	return c.service.BucketService
+}
+
+// Dashboards returns the service managing the dashboards of the server.
+func (c *Client) Dashboards() influxdb.DashboardService {
+	return c.service.DashboardService
+}
+
+// Labels returns the service managing the labels of the server.
+func (c *Client) Labels() influxdb.LabelService {
+	return c.service.LabelService
+}
+
+// NotificationEndpoints returns the service managing the notification
+// endpoints of the server.
+func (c *Client) NotificationEndpoints() influxdb.NotificationEndpointService {
+	return c.service.NotificationEndpointService
+}
+
+// Organizations returns the service managing the organizations of the
+// server.
+func (c *Client) Organizations() influxdb.OrganizationService {
+	return c.service.OrganizationService
+}
+
+// Secrets returns the service managing the secrets of the organizations of
+// the server.
+func (c *Client) Secrets() influxdb.SecretService {
+	return c.service.SecretService
+}
+
+// UserResourceMappings returns the service managing the members and owners
+// of the resources of the server.
+func (c *Client) UserResourceMappings() influxdb.UserResourceMappingService {
+	return c.service.UserResourceMappingService
+}
+
+// Users returns the service managing the users of the server.
+func (c *Client) Users() influxdb.UserService {
+	return c.service.UserService
+}
+
+// Variables returns the service managing the variables of the server.
+func (c *Client) Variables() influxdb.VariableService {
+	return c.service.VariableService
+}
@@ -0,0 +1,270 @@
+package client_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb/v2"
+	client "github.com/influxdata/influxdb/v2/client/v2"
+	ihttp "github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/http/metric"
+	httpmock "github.com/influxdata/influxdb/v2/http/mock"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kit/feature"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	querymock "github.com/influxdata/influxdb/v2/query/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	orgID    = "043e0780ee2b1000"
+	bucketID = "04504b356e23b000"
+)
+
+func mustNewClient(t *testing.T, addr string) *client.Client {
+	t.Helper()
+
+	c, err := client.New(addr, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestWriter(t *testing.T) {
+	oid := influxdbtesting.MustIDBase16(orgID)
+	bid := influxdbtesting.MustIDBase16(bucketID)
+
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return &influxdb.Organization{ID: oid, Name: "org"}, nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: bid, OrgID: oid, Name: "bucket"}, nil
+	}
+	pw := &mock.PointsWriter{}
+
+	b := &ihttp.APIBackend{
+		HTTPErrorHandler:    kithttp.ErrorHandler(0),
+		Logger:              zaptest.NewLogger(t),
+		OrganizationService: orgs,
+		BucketService:       buckets,
+		PointsWriter:        pw,
+		WriteEventRecorder:  &metric.NopEventRecorder{},
+	}
+	writeHandler := ihttp.NewWriteHandler(zaptest.NewLogger(t), ihttp.NewWriteBackend(zaptest.NewLogger(t), b))
+	handler := httpmock.NewAuthMiddlewareHandler(writeHandler, &influxdb.Authorization{
+		OrgID:  oid,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{{
+			Action:   influxdb.WriteAction,
+			Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &oid, ID: &bid},
+		}},
+	})
+
+	// The first request fails as if the server was overloaded.
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	c := mustNewClient(t, server.URL)
+	w := c.NewWriter("org", "bucket", client.WriteOptions{
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		RetryInterval: time.Millisecond,
+	})
+	for _, line := range []string{"cpu,host=a usage=1 1", "cpu,host=b usage=2 2", "mem,host=a used=3 3"} {
+		if err := w.WriteRecord(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := len(pw.Points), 3; got != want {
+		t.Errorf("expected %d points written, got %d", want, got)
+	}
+	if got, want := atomic.LoadInt32(&requests), int32(3); got != want {
+		t.Errorf("expected %d requests, got %d", want, got)
+	}
+	if err := w.WriteRecord("cpu usage=1"); err != client.ErrWriterClosed {
+		t.Errorf("expected writes to a closed writer to fail, got %v", err)
+	}
+
+	if err := c.Write(context.Background(), "org", "bucket"); err != nil {
+		t.Fatalf("expected an empty write to succeed, got %v", err)
+	}
+
+	// Invalid points are not retried.
+	w = c.NewWriter("org", "bucket", client.WriteOptions{})
+	if err := w.WriteRecord("cpu usage="); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(context.Background()); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected an invalid point to be rejected, got %v", err)
+	}
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_Query(t *testing.T) {
+	orgs := &mock.OrganizationService{
+		FindOrganizationF: func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+			return &influxdb.Organization{ID: 1, Name: "org"}, nil
+		},
+	}
+	queries := &querymock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			_, _ = io.WriteString(w, `#datatype,string,long,dateTime:RFC3339,double,string,string
+#group,false,false,false,false,true,true
+#default,_result,,,,,
+,result,table,_time,_value,_field,host
+,,0,2020-08-29T13:08:47Z,10.5,usage,a
+,,0,2020-08-29T13:08:57Z,11,usage,a
+,,1,2020-08-29T13:08:47Z,,usage,b
+
+#datatype,string,long,boolean
+#group,false,false,false
+#default,_result,,
+,result,table,ok
+,,2,true
+
+`)
+			return flux.Statistics{}, nil
+		},
+	}
+	fluxHandler := ihttp.NewFluxHandler(zaptest.NewLogger(t), &ihttp.FluxBackend{
+		HTTPErrorHandler:    kithttp.ErrorHandler(0),
+		QueryEventRecorder:  &metric.NopEventRecorder{},
+		OrganizationService: orgs,
+		ProxyQueryService:   queries,
+		FluxLanguageService: fluxlang.DefaultService,
+		Flagger:             feature.DefaultFlagger(),
+	})
+	auth := ihttp.NewAuthenticationHandler(zaptest.NewLogger(t), kithttp.ErrorHandler(0))
+	auth.AuthorizationService = &mock.AuthorizationService{
+		FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*influxdb.Authorization, error) {
+			return &influxdb.Authorization{
+				ID:          1,
+				OrgID:       1,
+				Status:      influxdb.Active,
+				Permissions: influxdb.OperPermissions(),
+			}, nil
+		},
+	}
+	auth.UserService = &mock.UserService{
+		FindUserByIDFn: func(ctx context.Context, id influxdb.ID) (*influxdb.User, error) {
+			return &influxdb.User{}, nil
+		},
+	}
+	auth.Handler = fluxHandler
+	server := httptest.NewServer(auth)
+	defer server.Close()
+
+	c := mustNewClient(t, server.URL)
+	res, err := c.Query(context.Background(), "org", `from(bucket: "bucket") |> range(start: -1h)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Close()
+
+	type record struct {
+		table        int64
+		tableChanged bool
+		value        interface{}
+	}
+	var records []record
+	for res.Next() {
+		r := res.Record()
+		records = append(records, record{table: r.Table(), tableChanged: res.TableChanged(), value: r.Value()})
+		if r.Result() != "_result" {
+			t.Errorf("expected the record to be in _result, got %q", r.Result())
+		}
+	}
+	if err := res.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []record{
+		{table: 0, tableChanged: true, value: 10.5},
+		{table: 0, value: float64(11)},
+		{table: 1, tableChanged: true},
+		{table: 2, tableChanged: true},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("expected %d records, got %d: %v", len(want), len(records), records)
+	}
+	for i := range want {
+		if records[i] != want[i] {
+			t.Errorf("record %d: expected %v, got %v", i, want[i], records[i])
+		}
+	}
+}
+
+func TestClient_Buckets(t *testing.T) {
+	influxdbtesting.BucketService(initBucketService, t)
+}
+
+func initBucketService(f influxdbtesting.BucketFields, t *testing.T) (influxdb.BucketService, string, func()) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+	store := inmem.NewKVStore()
+	if err := all.Up(ctx, logger, store); err != nil {
+		t.Fatal(err)
+	}
+	svc := kv.NewService(logger, store)
+	svc.IDGenerator = f.IDGenerator
+	svc.OrgIDs = f.OrgIDs
+	svc.BucketIDs = f.BucketIDs
+	svc.TimeGenerator = f.TimeGenerator
+	if f.TimeGenerator == nil {
+		svc.TimeGenerator = influxdb.RealTimeGenerator{}
+	}
+
+	for _, o := range f.Organizations {
+		o.ID = svc.OrgIDs.ID()
+		if err := svc.PutOrganization(ctx, o); err != nil {
+			t.Fatalf("failed to populate organizations")
+		}
+	}
+	for _, b := range f.Buckets {
+		b.ID = svc.BucketIDs.ID()
+		if err := svc.PutBucket(ctx, b); err != nil {
+			t.Fatalf("failed to populate buckets")
+		}
+	}
+
+	handler := ihttp.NewBucketHandler(logger, ihttp.NewBucketBackend(logger, &ihttp.APIBackend{
+		HTTPErrorHandler:           kithttp.ErrorHandler(0),
+		BucketService:              svc,
+		OrganizationService:        svc,
+		BucketOperationLogService:  mock.NewBucketOperationLogService(),
+		UserResourceMappingService: mock.NewUserResourceMappingService(),
+		LabelService:               mock.NewLabelService(),
+		UserService:                mock.NewUserService(),
+	}))
+	server := httptest.NewServer(handler)
+	c := mustNewClient(t, server.URL)
+	return c.Buckets(), "", server.Close
+}
@@ -0,0 +1,350 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	ihttp "github.com/influxdata/influxdb/v2/http"
+)
+
+// Query runs the flux query in the organization, given by name or ID, and
+// returns an iterator over the records of its results. The result must be
+// closed.
+func (c *Client) Query(ctx context.Context, org, query string) (*QueryResult, error) {
+	return c.QueryWithParams(ctx, org, query, nil)
+}
+
+// QueryWithParams runs the flux query like Query, binding params to the
+// params option of the query, e.g.
+//
+//	c.QueryWithParams(ctx, org, `from(bucket: params.bucket) |> range(start: params.start)`, map[string]interface{}{
+//		"bucket": "telegraf",
+//		"start":  "-1h",
+//	})
+func (c *Client) QueryWithParams(ctx context.Context, org, query string, params map[string]interface{}) (*QueryResult, error) {
+	header := true
+	qr := ihttp.QueryRequest{
+		Type:   "flux",
+		Query:  query,
+		Params: params,
+		Dialect: ihttp.QueryDialect{
+			Header:      &header,
+			Annotations: []string{"datatype", "group", "default"},
+		},
+	}.WithDefaults()
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(qr); err != nil {
+		return nil, err
+	}
+
+	u, err := c.url(prefixQuery, url.Values{"org": []string{org}})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/csv")
+	ihttp.SetToken(c.token, req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := ihttp.CheckError(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return NewQueryResult(resp.Body), nil
+}
+
+// Column is a column of the tables of a query result.
+type Column struct {
+	Name     string
+	DataType string
+	Group    bool
+	Default  string
+}
+
+// Record is a row of a table of a query result.
+type Record struct {
+	Columns []Column
+	// Values are the values of the columns by name. The values of the
+	// columns typed by the datatype annotation are decoded: long as int64,
+	// unsignedLong as uint64, double as float64, boolean as bool, dateTime
+	// as time.Time, duration as time.Duration and base64Binary as []byte.
+	// The other values are strings. Empty values without default are nil.
+	Values map[string]interface{}
+}
+
+// Result returns the name of the result of the record.
+func (r *Record) Result() string {
+	s, _ := r.Values["result"].(string)
+	return s
+}
+
+// Table returns the index of the table of the record in its result.
+func (r *Record) Table() int64 {
+	t, _ := r.Values["table"].(int64)
+	return t
+}
+
+// Time returns the _time of the record.
+func (r *Record) Time() time.Time {
+	t, _ := r.Values["_time"].(time.Time)
+	return t
+}
+
+// Start returns the _start of the record.
+func (r *Record) Start() time.Time {
+	t, _ := r.Values["_start"].(time.Time)
+	return t
+}
+
+// Stop returns the _stop of the record.
+func (r *Record) Stop() time.Time {
+	t, _ := r.Values["_stop"].(time.Time)
+	return t
+}
+
+// Value returns the _value of the record.
+func (r *Record) Value() interface{} {
+	return r.Values["_value"]
+}
+
+// Field returns the _field of the record.
+func (r *Record) Field() string {
+	s, _ := r.Values["_field"].(string)
+	return s
+}
+
+// Measurement returns the _measurement of the record.
+func (r *Record) Measurement() string {
+	s, _ := r.Values["_measurement"].(string)
+	return s
+}
+
+// QueryResult is an iterator over the records of the annotated CSV results
+// of a query.
+type QueryResult struct {
+	body io.ReadCloser
+	r    *csv.Reader
+
+	// annotations of the next table, by name without #.
+	annotations map[string][]string
+	columns     []Column
+	header      bool
+
+	record       *Record
+	table        string
+	tableChanged bool
+	err          error
+}
+
+// NewQueryResult returns an iterator over the records of the annotated CSV
+// read from body, that is closed with the result.
+func NewQueryResult(body io.ReadCloser) *QueryResult {
+	r := csv.NewReader(body)
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = false
+	return &QueryResult{
+		body: body,
+		r:    r,
+	}
+}
+
+// Next advances to the next record and returns false once there are no more
+// records or reading them failed.
+func (q *QueryResult) Next() bool {
+	if q.err != nil {
+		return false
+	}
+	q.tableChanged = false
+
+	for {
+		row, err := q.r.Read()
+		if err == io.EOF {
+			q.record = nil
+			return false
+		}
+		if err != nil {
+			q.fail(err)
+			return false
+		}
+
+		if strings.HasPrefix(row[0], "#") {
+			if !q.header {
+				// An annotation starts the schema of a new table.
+				q.annotations = make(map[string][]string)
+				q.header = true
+			}
+			q.annotations[strings.TrimPrefix(row[0], "#")] = row[1:]
+			continue
+		}
+
+		if q.header || q.columns == nil {
+			q.header = false
+			q.readHeader(row[1:])
+			if len(q.columns) == 2 && q.columns[0].Name == "error" && q.columns[1].Name == "reference" {
+				q.readError()
+				return false
+			}
+			continue
+		}
+
+		if len(row)-1 != len(q.columns) {
+			q.fail(fmt.Errorf("record has %d values, expected %d", len(row)-1, len(q.columns)))
+			return false
+		}
+		values := make(map[string]interface{}, len(q.columns))
+		for i, col := range q.columns {
+			v, err := decodeValue(col, row[i+1])
+			if err != nil {
+				q.fail(err)
+				return false
+			}
+			values[col.Name] = v
+		}
+
+		if i := q.column("table"); i >= 0 && row[i+1] != q.table {
+			q.table = row[i+1]
+			q.tableChanged = true
+		}
+		q.record = &Record{Columns: q.columns, Values: values}
+		return true
+	}
+}
+
+// readHeader reads the names of the columns of a new table, whose first
+// record is read next.
+func (q *QueryResult) readHeader(names []string) {
+	q.columns = make([]Column, len(names))
+	for i, name := range names {
+		q.columns[i] = Column{
+			Name:     name,
+			DataType: annotation(q.annotations, "datatype", i),
+			Group:    annotation(q.annotations, "group", i) == "true",
+			Default:  annotation(q.annotations, "default", i),
+		}
+	}
+	q.table = ""
+	q.tableChanged = true
+}
+
+// readError reads the error of a failed query from the row of its error
+// table.
+func (q *QueryResult) readError() {
+	row, err := q.r.Read()
+	if err != nil {
+		q.fail(err)
+		return
+	}
+	e := &influxdb.Error{
+		Code: influxdb.EInternal,
+	}
+	if len(row) > 1 {
+		e.Msg = row[1]
+	}
+	if len(row) > 2 && row[2] != "" {
+		e.Msg = fmt.Sprintf("%s (reference %s)", e.Msg, row[2])
+	}
+	q.err = e
+}
+
+func (q *QueryResult) fail(err error) {
+	q.record = nil
+	q.err = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "failed to read query result",
+		Err:  err,
+	}
+}
+
+func (q *QueryResult) column(name string) int {
+	for i, col := range q.columns {
+		if col.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Record returns the current record.
+func (q *QueryResult) Record() *Record {
+	return q.record
+}
+
+// TableChanged returns true if the current record is the first record of a
+// table.
+func (q *QueryResult) TableChanged() bool {
+	return q.tableChanged
+}
+
+// Err returns the error that ended the iteration, if any.
+func (q *QueryResult) Err() error {
+	return q.err
+}
+
+// Close closes the result.
+func (q *QueryResult) Close() error {
+	return q.body.Close()
+}
+
+func annotation(annotations map[string][]string, name string, i int) string {
+	values := annotations[name]
+	if i < len(values) {
+		return values[i]
+	}
+	return ""
+}
+
+// decodeValue decodes the value of the column according to its datatype.
+func decodeValue(col Column, s string) (interface{}, error) {
+	if s == "" {
+		s = col.Default
+	}
+	if s == "" && col.DataType != "string" && col.DataType != "" {
+		return nil, nil
+	}
+
+	var (
+		v   interface{}
+		err error
+	)
+	switch dt := col.DataType; {
+	case dt == "long":
+		v, err = strconv.ParseInt(s, 10, 64)
+	case dt == "unsignedLong":
+		v, err = strconv.ParseUint(s, 10, 64)
+	case dt == "double":
+		v, err = strconv.ParseFloat(s, 64)
+	case dt == "boolean":
+		v, err = strconv.ParseBool(s)
+	case dt == "dateTime" || strings.HasPrefix(dt, "dateTime:"):
+		v, err = time.Parse(time.RFC3339Nano, s)
+	case dt == "duration":
+		v, err = time.ParseDuration(s)
+	case dt == "base64Binary":
+		v, err = base64.StdEncoding.DecodeString(s)
+	default:
+		v = s
+	}
+	if err != nil {
+		return nil, fmt.Errorf("value %q of column %s is not a %s: %v", s, col.Name, col.DataType, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,79 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	client "github.com/influxdata/influxdb/v2/client/v2"
+)
+
+func newQueryResult(csv string) *client.QueryResult {
+	return client.NewQueryResult(ioutil.NopCloser(strings.NewReader(csv)))
+}
+
+func TestQueryResult_values(t *testing.T) {
+	res := newQueryResult(`#datatype,string,long,dateTime:RFC3339Nano,unsignedLong,boolean,duration,string
+#group,false,false,false,false,false,false,true
+#default,_result,,,,,,default
+,result,table,_time,_value,ok,every,host
+,,0,2020-08-29T13:08:47.5Z,18446744073709551615,false,1h30m,
+`)
+	if !res.Next() {
+		t.Fatalf("expected a record, got error %v", res.Err())
+	}
+	r := res.Record()
+
+	if got, want := r.Time(), time.Date(2020, 8, 29, 13, 8, 47, 5e8, time.UTC); !got.Equal(want) {
+		t.Errorf("expected time %v, got %v", want, got)
+	}
+	for name, want := range map[string]interface{}{
+		"_value": uint64(18446744073709551615),
+		"ok":     false,
+		"every":  90 * time.Minute,
+		"host":   "default",
+	} {
+		if got := r.Values[name]; got != want {
+			t.Errorf("expected %s %v, got %v", name, want, got)
+		}
+	}
+	if !r.Columns[6].Group {
+		t.Error("expected host to be a group column")
+	}
+	if res.Next() {
+		t.Error("expected a single record")
+	}
+	if err := res.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryResult_errors(t *testing.T) {
+	res := newQueryResult(`#datatype,string,string
+#group,true,true
+#default,,
+,error,reference
+,"failed to parse query, unexpected token",897
+`)
+	if res.Next() {
+		t.Fatal("expected no record")
+	}
+	if got, want := res.Err().Error(), "failed to parse query, unexpected token (reference 897)"; got != want {
+		t.Errorf("expected error %q, got %q", want, got)
+	}
+
+	res = newQueryResult(`#datatype,string,long,double
+#group,false,false,false
+#default,_result,,
+,result,table,_value
+,,0,abc
+`)
+	if res.Next() {
+		t.Fatal("expected no record")
+	}
+	if influxdb.ErrorCode(res.Err()) != influxdb.EInvalid {
+		t.Errorf("expected an invalid value to fail the result, got %v", res.Err())
+	}
+}
@@ -0,0 +1,332 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	ihttp "github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+)
+
+// ErrWriterClosed is returned when points are written with a closed Writer.
+var ErrWriterClosed = errors.New("writer is closed")
+
+// WriteOptions configures the batches and the retries of writes. Fields
+// with a zero value take the value of DefaultWriteOptions.
+type WriteOptions struct {
+	// BatchSize is the number of points sent in a request.
+	BatchSize int
+	// FlushInterval is the longest a point is buffered before it is sent.
+	FlushInterval time.Duration
+	// Precision is the precision of the timestamps of the points: ns, us,
+	// ms or s.
+	Precision string
+	// MaxRetries is the number of times a batch is retried after it failed
+	// with a network error or a 429, 502, 503 or 504 response. A negative
+	// MaxRetries disables the retries.
+	MaxRetries int
+	// RetryInterval is the wait before the first retry of a batch. It
+	// doubles with every retry up to MaxRetryInterval, unless the server
+	// asks for a wait with a Retry-After header.
+	RetryInterval    time.Duration
+	MaxRetryInterval time.Duration
+	// ErrorFn is called with the error of the batches a Writer sends in the
+	// background that could not be written.
+	ErrorFn func(error)
+}
+
+// DefaultWriteOptions are the options of writes.
+var DefaultWriteOptions = WriteOptions{
+	BatchSize:        5000,
+	FlushInterval:    time.Second,
+	Precision:        "ns",
+	MaxRetries:       3,
+	RetryInterval:    time.Second,
+	MaxRetryInterval: 30 * time.Second,
+}
+
+func (o WriteOptions) withDefaults() WriteOptions {
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultWriteOptions.BatchSize
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = DefaultWriteOptions.FlushInterval
+	}
+	if o.Precision == "" {
+		o.Precision = DefaultWriteOptions.Precision
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = DefaultWriteOptions.MaxRetries
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = DefaultWriteOptions.RetryInterval
+	}
+	if o.MaxRetryInterval <= 0 {
+		o.MaxRetryInterval = DefaultWriteOptions.MaxRetryInterval
+	}
+	return o
+}
+
+// backoff returns the jittered wait before the retry following attempt.
+func (o WriteOptions) backoff(attempt int) time.Duration {
+	d := o.RetryInterval << uint(attempt)
+	if d <= 0 || d > o.MaxRetryInterval {
+		d = o.MaxRetryInterval
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// Write writes the points to the bucket of the organization, both given by
+// name or ID, in a single request that is retried as configured by
+// DefaultWriteOptions.
+func (c *Client) Write(ctx context.Context, org, bucket string, points ...models.Point) error {
+	opts := DefaultWriteOptions
+	lines := make([][]byte, 0, len(points))
+	for _, p := range points {
+		lines = append(lines, []byte(p.PrecisionString(opts.Precision)))
+	}
+	return c.writeBatch(ctx, org, bucket, opts, lines)
+}
+
+// writeBatch writes the lines of line protocol, retrying them as configured
+// by opts.
+func (c *Client) writeBatch(ctx context.Context, org, bucket string, opts WriteOptions, lines [][]byte) error {
+	if !models.ValidPrecision(opts.Precision) {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "precision must be one of ns, us, ms or s",
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	gw := gzip.NewWriter(&body)
+	if _, err := gw.Write(bytes.Join(lines, []byte("\n"))); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+
+	u, err := c.url(prefixWrite, url.Values{
+		"org":       []string{org},
+		"bucket":    []string{bucket},
+		"precision": []string{opts.Precision},
+	})
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		status, retryAfter, err := c.write(ctx, u, body.Bytes())
+		if !httpc.IsTransient(ctx, status, err) || attempt >= opts.MaxRetries {
+			return err
+		}
+
+		wait := opts.backoff(attempt)
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
+
+// write makes a write request with the gzipped body, returning the status
+// of the response, or 0 if there was none, and the wait asked for by its
+// Retry-After header.
+func (c *Client) write(ctx context.Context, u string, body []byte) (int, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("Content-Encoding", "gzip")
+	ihttp.SetToken(c.token, req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	var retryAfter time.Duration
+	if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+		retryAfter = time.Duration(s) * time.Second
+	}
+	return resp.StatusCode, retryAfter, ihttp.CheckError(resp)
+}
+
+// Writer writes points to a bucket in batches. A batch is sent in the
+// background once it holds BatchSize points, or FlushInterval after its
+// first point. A Writer is safe for concurrent use and must be closed.
+type Writer struct {
+	client      *Client
+	org, bucket string
+	opts        WriteOptions
+
+	mu     sync.Mutex
+	batch  [][]byte
+	closed bool
+	// senders are the calls queuing batches, that must be done before
+	// batches is closed.
+	senders sync.WaitGroup
+
+	// batches are sent in order by the background goroutine, that
+	// reports their errors on done if it is not nil.
+	batches chan writeBatch
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+type writeBatch struct {
+	lines [][]byte
+	done  chan error
+}
+
+// NewWriter returns a writer of points to the bucket of the organization,
+// both given by name or ID.
+func (c *Client) NewWriter(org, bucket string, opts WriteOptions) *Writer {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Writer{
+		client:  c,
+		org:     org,
+		bucket:  bucket,
+		opts:    opts.withDefaults(),
+		batches: make(chan writeBatch, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	w.wg.Add(1)
+	go w.run()
+	return w
+}
+
+// WritePoint adds the point to the current batch.
+func (w *Writer) WritePoint(p models.Point) error {
+	return w.WriteRecord(p.PrecisionString(w.opts.Precision))
+}
+
+// WriteRecord adds the line of line protocol to the current batch.
+func (w *Writer) WriteRecord(line string) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrWriterClosed
+	}
+	w.batch = append(w.batch, []byte(line))
+	if len(w.batch) < w.opts.BatchSize {
+		w.mu.Unlock()
+		return nil
+	}
+	full := w.batch
+	w.batch = nil
+	w.senders.Add(1)
+	w.mu.Unlock()
+
+	defer w.senders.Done()
+	w.batches <- writeBatch{lines: full}
+	return nil
+}
+
+// Flush sends the current batch and waits for it to be written.
+func (w *Writer) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrWriterClosed
+	}
+	lines := w.batch
+	w.batch = nil
+	w.senders.Add(1)
+	w.mu.Unlock()
+
+	defer w.senders.Done()
+	return w.send(ctx, lines)
+}
+
+// send queues lines after the batches being sent and waits until it is
+// written.
+func (w *Writer) send(ctx context.Context, lines [][]byte) error {
+	done := make(chan error, 1)
+	select {
+	case w.batches <- writeBatch{lines: lines, done: done}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close flushes the current batch and stops the writer. The batches still
+// being sent when ctx is done are dropped.
+func (w *Writer) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	lines := w.batch
+	w.batch = nil
+	w.mu.Unlock()
+
+	err := w.send(ctx, lines)
+	w.cancel()
+	w.senders.Wait()
+	close(w.batches)
+	w.wg.Wait()
+	return err
+}
+
+func (w *Writer) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case b, ok := <-w.batches:
+			if !ok {
+				return
+			}
+			err := w.client.writeBatch(w.ctx, w.org, w.bucket, w.opts, b.lines)
+			if b.done != nil {
+				b.done <- err
+			} else if err != nil && w.opts.ErrorFn != nil {
+				w.opts.ErrorFn(err)
+			}
+		case <-ticker.C:
+			w.mu.Lock()
+			lines := w.batch
+			w.batch = nil
+			w.mu.Unlock()
+			if err := w.client.writeBatch(w.ctx, w.org, w.bucket, w.opts, lines); err != nil && w.opts.ErrorFn != nil {
+				w.opts.ErrorFn(err)
+			}
+		}
+	}
+}
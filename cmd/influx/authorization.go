@@ -80,6 +80,12 @@ var authCreateFlags struct {
 
 	writeDBRPPermission bool
 	readDBRPPermission  bool
+
+	writeFluxPackagesPermission bool
+	readFluxPackagesPermission  bool
+
+	writeSavedQueriesPermission bool
+	readSavedQueriesPermission  bool
 }
 
 func authCreateCmd(f *globalFlags) *cobra.Command {
@@ -129,6 +135,12 @@ func authCreateCmd(f *globalFlags) *cobra.Command {
 	cmd.Flags().BoolVarP(&authCreateFlags.writeDBRPPermission, "write-dbrps", "", false, "Grants the permission to create database retention policy mappings")
 	cmd.Flags().BoolVarP(&authCreateFlags.readDBRPPermission, "read-dbrps", "", false, "Grants the permission to read database retention policy mappings")
 
+	cmd.Flags().BoolVarP(&authCreateFlags.writeFluxPackagesPermission, "write-flux-packages", "", false, "Grants the permission to create Flux packages")
+	cmd.Flags().BoolVarP(&authCreateFlags.readFluxPackagesPermission, "read-flux-packages", "", false, "Grants the permission to import Flux packages")
+
+	cmd.Flags().BoolVarP(&authCreateFlags.writeSavedQueriesPermission, "write-saved-queries", "", false, "Grants the permission to create saved queries")
+	cmd.Flags().BoolVarP(&authCreateFlags.readSavedQueriesPermission, "read-saved-queries", "", false, "Grants the permission to read saved queries")
+
 	return cmd
 }
 
@@ -232,6 +244,16 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 			writePerm:    authCreateFlags.writeDBRPPermission,
 			ResourceType: platform.DBRPResourceType,
 		},
+		{
+			readPerm:     authCreateFlags.readFluxPackagesPermission,
+			writePerm:    authCreateFlags.writeFluxPackagesPermission,
+			ResourceType: platform.FluxPackagesResourceType,
+		},
+		{
+			readPerm:     authCreateFlags.readSavedQueriesPermission,
+			writePerm:    authCreateFlags.writeSavedQueriesPermission,
+			ResourceType: platform.SavedQueriesResourceType,
+		},
 	}
 
 	for _, provided := range providedPerm {
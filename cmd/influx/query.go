@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -21,19 +22,21 @@ import (
 )
 
 var queryFlags struct {
-	org  organization
-	file string
+	org   organization
+	file  string
+	saved string
 }
 
 func cmdQuery(f *globalFlags, opts genericCLIOpts) *cobra.Command {
 	cmd := opts.newCmd("query [query literal or -f /path/to/query.flux]", fluxQueryF, true)
 	cmd.Short = "Execute a Flux query"
-	cmd.Long = `Execute a Flux query provided via the first argument or a file or stdin`
+	cmd.Long = `Execute a Flux query provided via the first argument, a file, stdin or the name of a saved query`
 	cmd.Args = cobra.MaximumNArgs(1)
 
 	f.registerFlags(cmd)
 	queryFlags.org.register(cmd, true)
 	cmd.Flags().StringVarP(&queryFlags.file, "file", "f", "", "Path to Flux query file")
+	cmd.Flags().StringVar(&queryFlags.saved, "saved", "", "Name of a saved query of the organization to execute")
 
 	return cmd
 }
@@ -76,20 +79,24 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	q, err := readFluxQuery(args, queryFlags.file)
+	var q string
+	var err error
+	if queryFlags.saved != "" {
+		if len(args) > 0 || queryFlags.file != "" {
+			return errors.New("a saved query cannot be combined with a query literal or file")
+		}
+		q, err = readSavedQuery(queryFlags.saved)
+	} else {
+		q, err = readFluxQuery(args, queryFlags.file)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to load query: %v", err)
 	}
 
-	u, err := url.Parse(flags.config().Host)
+	u, err := apiURL("api/v2/query")
 	if err != nil {
-		return fmt.Errorf("unable to parse host: %s", err)
-	}
-
-	if !strings.HasSuffix(u.Path, "/") {
-		u.Path += "/"
+		return err
 	}
-	u.Path += "api/v2/query"
 
 	params := url.Values{}
 	if queryFlags.org.id != "" {
@@ -145,6 +152,63 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 	return results.Err()
 }
 
+// apiURL returns the URL of path on the configured host.
+func apiURL(path string) (*url.URL, error) {
+	u, err := url.Parse(flags.config().Host)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse host: %s", err)
+	}
+
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	u.Path += path
+	return u, nil
+}
+
+// readSavedQuery returns the Flux text of the saved query named name in the
+// organization of the query.
+func readSavedQuery(name string) (string, error) {
+	u, err := apiURL("api/v2/savedqueries")
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{"name": []string{name}}
+	if queryFlags.org.id != "" {
+		params.Set("orgID", queryFlags.org.id)
+	} else {
+		params.Set("org", queryFlags.org.name)
+	}
+	u.RawQuery = params.Encode()
+
+	req, _ := http.NewRequest("GET", u.String(), nil)
+	req.Header.Set("Authorization", "Token "+flags.config().Token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if err := ihttp.CheckError(resp); err != nil {
+		return "", err
+	}
+
+	var body struct {
+		Queries []struct {
+			Query string `json:"query"`
+		} `json:"queries"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if len(body.Queries) == 0 {
+		return "", fmt.Errorf("saved query %q not found", name)
+	}
+	return body.Queries[0].Query, nil
+}
+
 // Below is a copy and trimmed version of the execute/format.go file from flux.
 // It is copied here to avoid requiring a dependency on the execute package which
 // may pull in the flux runtime as a dependency.
@@ -25,6 +25,8 @@ func NewCommand() *cobra.Command {
 		NewVerifySeriesFileCommand(),
 		NewDumpWALCommand(),
 		NewDumpTSICommand(),
+		NewVerifyMetadataCommand(),
+		NewRebalanceCommand(),
 	}
 
 	base.AddCommand(subCommands...)
@@ -0,0 +1,76 @@
+package inspect
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/v2/internal/fs"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// rebalanceFlags defines the `rebalance` Command.
+var rebalanceFlags = struct {
+	enginePath string
+	dataDirs   []string
+	dryRun     bool
+}{}
+
+// NewRebalanceCommand returns a new instance of the rebalance command.
+func NewRebalanceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rebalance",
+		Short: "Moves TSM files across data directories on other volumes",
+		Long: `
+This command moves the TSM files of the engine between its data directory and
+additional data directories on other volumes, so that the volumes are left
+with about the same free space. The largest files are moved first.
+
+The additional data directories must be passed to influxd with
+--storage-data-dirs, so that the engine loads the files moved to them. New
+files are always written to the data directory of the engine, so the command
+may be run again as it fills up.
+
+NOTES:
+
+* The influxd server should not be running when using the rebalance tool
+  as it moves the files of the engine.
+`,
+		Args: cobra.NoArgs,
+		RunE: inspectRebalanceF,
+	}
+
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(err)
+	}
+	dir = filepath.Join(dir, "engine")
+	cmd.Flags().StringVar(&rebalanceFlags.enginePath, "engine-path", dir, fmt.Sprintf("path to the persistent engine files (defaults to %s).", dir))
+	cmd.Flags().StringSliceVar(&rebalanceFlags.dataDirs, "data-dirs", nil, "the additional data directories, as passed to influxd with --storage-data-dirs.")
+	cmd.Flags().BoolVar(&rebalanceFlags.dryRun, "dry-run", false, "only report the files that would be moved.")
+
+	return cmd
+}
+
+// inspectRebalanceF runs the rebalance tool.
+func inspectRebalanceF(cmd *cobra.Command, args []string) error {
+	if len(rebalanceFlags.dataDirs) == 0 {
+		return fmt.Errorf("no additional data directories given")
+	}
+
+	dirs := append([]string{filepath.Join(rebalanceFlags.enginePath, storage.DefaultEngineDirectoryName)}, rebalanceFlags.dataDirs...)
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			return err
+		}
+	}
+
+	rebalance := &tsm1.Rebalance{
+		Stdout: os.Stdout,
+		Dirs:   dirs,
+		DryRun: rebalanceFlags.dryRun,
+	}
+	return rebalance.Run()
+}
@@ -0,0 +1,73 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/internal/fs"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// verifyMetadataFlags defines the `verify-metadata` Command.
+var verifyMetadataFlags = struct {
+	boltPath string
+	repair   bool
+}{}
+
+// NewVerifyMetadataCommand returns the command verifying the metadata store.
+func NewVerifyMetadataCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-metadata",
+		Short: "Checks the consistency of the metadata store",
+		Long: `
+This command will find the records of the metadata store that refer to users,
+labels or resources that no longer exist.
+
+The checks performed by this command are:
+
+* user resource mappings refer to existing users and resources
+* the by user index of user resource mappings refers to existing mappings
+* label mappings refer to existing labels and resources
+
+The metadata store cannot be verified while influxd is running.
+`,
+		Args: cobra.NoArgs,
+		RunE: verifyMetadataF,
+	}
+
+	cmd.Flags().StringVar(&verifyMetadataFlags.boltPath, "bolt-path", "", "Path to the BoltDB file. Defaults to the BoltDB file of the influxd directory.")
+	cmd.Flags().BoolVar(&verifyMetadataFlags.repair, "repair", false, "Delete the inconsistent records.")
+
+	return cmd
+}
+
+func verifyMetadataF(cmd *cobra.Command, args []string) error {
+	path := verifyMetadataFlags.boltPath
+	if path == "" {
+		var err error
+		if path, err = fs.BoltFile(); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	store := bolt.NewKVStore(zap.NewNop(), path)
+	if err := store.Open(ctx); err != nil {
+		return err
+	}
+	defer store.Close()
+
+	issues, err := kv.NewService(zap.NewNop(), store).VerifyMetadata(ctx, verifyMetadataFlags.repair)
+	if err != nil {
+		return err
+	}
+	for _, i := range issues {
+		fmt.Fprintln(os.Stdout, i)
+	}
+	fmt.Fprintf(os.Stdout, "%d inconsistent records found\n", len(issues))
+	return nil
+}
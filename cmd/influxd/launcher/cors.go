@@ -0,0 +1,95 @@
+package launcher
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+)
+
+// parseCORSPolicies parses the default CORS policy of the HTTP API and the
+// policies of the groups of routes overriding it, as prefix;option=value;...
+func parseCORSPolicies(defaultSpec string, specs []string) (*kithttp.CORSPolicies, error) {
+	dflt, err := parseCORSPolicy(kithttp.DefaultCORSPolicy(), defaultSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := &kithttp.CORSPolicies{
+		Default:   dflt,
+		Overrides: make(map[string]kithttp.CORSPolicy, len(specs)),
+	}
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, ";", 2)
+		prefix := parts[0]
+		if !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("CORS policy %q does not start with a path prefix", spec)
+		}
+		var opts string
+		if len(parts) == 2 {
+			opts = parts[1]
+		}
+		policy, err := parseCORSPolicy(dflt, opts)
+		if err != nil {
+			return nil, err
+		}
+		policies.Overrides[prefix] = policy
+	}
+	return policies, nil
+}
+
+// parseCORSPolicy parses the options of a CORS policy, as
+// option=value;..., overriding those of base. The values of the list
+// options are separated by |.
+func parseCORSPolicy(base kithttp.CORSPolicy, spec string) (kithttp.CORSPolicy, error) {
+	policy := base
+	if spec == "" {
+		return policy, nil
+	}
+
+	list := func(v string) []string {
+		if v == "" {
+			return []string{}
+		}
+		return strings.Split(v, "|")
+	}
+	for _, opt := range strings.Split(spec, ";") {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 {
+			return policy, fmt.Errorf("CORS option %q is not a key=value pair", opt)
+		}
+		k, v := kv[0], kv[1]
+
+		var err error
+		switch k {
+		case "origins":
+			policy.AllowedOrigins = list(v)
+		case "methods":
+			policy.AllowedMethods = list(v)
+		case "headers":
+			policy.AllowedHeaders = list(v)
+		case "exposed-headers":
+			policy.ExposedHeaders = list(v)
+		case "credentials":
+			policy.AllowCredentials, err = strconv.ParseBool(v)
+		case "max-age":
+			policy.MaxAge, err = time.ParseDuration(v)
+		default:
+			return policy, fmt.Errorf("unknown CORS option %q", k)
+		}
+		if err != nil {
+			return policy, fmt.Errorf("invalid CORS option %q: %v", opt, err)
+		}
+	}
+
+	for _, origin := range policy.AllowedOrigins {
+		// Browsers reject the credentialed responses to any origin, and a
+		// reflected origin would let any site use the sessions of the users.
+		if origin == "*" && policy.AllowCredentials {
+			return policy, fmt.Errorf("CORS credentials cannot be allowed to any origin")
+		}
+	}
+	return policy, nil
+}
@@ -0,0 +1,53 @@
+package launcher
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+)
+
+func TestParseCORSPolicies(t *testing.T) {
+	policies, err := parseCORSPolicies("origins=https://app.example.com|https://*.example.org;credentials=true;max-age=10m", []string{
+		"/api/v2/write;origins=*;credentials=false",
+		"/api/v2/query",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dflt := kithttp.DefaultCORSPolicy()
+	dflt.AllowedOrigins = []string{"https://app.example.com", "https://*.example.org"}
+	dflt.AllowCredentials = true
+	dflt.MaxAge = 10 * time.Minute
+	if !reflect.DeepEqual(policies.Default, dflt) {
+		t.Errorf("unexpected default policy %+v", policies.Default)
+	}
+
+	write := dflt
+	write.AllowedOrigins = []string{"*"}
+	write.AllowCredentials = false
+	if !reflect.DeepEqual(policies.Overrides["/api/v2/write"], write) {
+		t.Errorf("unexpected write policy %+v", policies.Overrides["/api/v2/write"])
+	}
+	if !reflect.DeepEqual(policies.Overrides["/api/v2/query"], dflt) {
+		t.Errorf("unexpected query policy %+v", policies.Overrides["/api/v2/query"])
+	}
+
+	for _, tt := range []struct {
+		dflt  string
+		specs []string
+	}{
+		{dflt: "origins"},
+		{dflt: "unknown=1"},
+		{dflt: "max-age=1"},
+		{dflt: "origins=*;credentials=true"},
+		{specs: []string{"api/v2/write;origins=*"}},
+		{specs: []string{"/api/v2/write;credentials=yes"}},
+	} {
+		if _, err := parseCORSPolicies(tt.dflt, tt.specs); err == nil {
+			t.Errorf("expected an error parsing %q %q", tt.dflt, tt.specs)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/influxdata/influxdb/v2/http"
 	"github.com/influxdata/influxdb/v2/kit/prom"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/schema"
 	"github.com/influxdata/influxdb/v2/storage"
 	"github.com/influxdata/influxdb/v2/storage/reads"
 	"github.com/influxdata/influxdb/v2/tsdb/cursors"
@@ -25,13 +26,19 @@ var _ Engine = (*storage.Engine)(nil)
 // to facilitate testing.
 type Engine interface {
 	influxdb.DeleteService
+	influxdb.MeasurementDeleteService
 	reads.Viewer
 	storage.PointsWriter
 	storage.BucketDeleter
 	prom.PrometheusCollector
 	influxdb.BackupService
+	schema.Reader
 
 	SeriesCardinality() int64
+	QueueDepth() (depth, capacity int64)
+	IndexCompactionStatus() (storage.IndexCompactionStatus, error)
+	ExportBucket(ctx context.Context, orgID, bucketID influxdb.ID, fn func(storage.BucketExport) error) error
+	ImportBucket(ctx context.Context, orgID, bucketID influxdb.ID, fn func(dir string) error) error
 
 	WithLogger(log *zap.Logger)
 	Open(context.Context) error
@@ -114,12 +121,38 @@ func (t *TemporaryEngine) SeriesCardinality() int64 {
 	return t.engine.SeriesCardinality()
 }
 
+// QueueDepth returns the number of bytes held in the cache awaiting a
+// snapshot, and the number of bytes it holds before rejecting writes.
+func (t *TemporaryEngine) QueueDepth() (depth, capacity int64) {
+	return t.engine.QueueDepth()
+}
+
+// IndexCompactionStatus returns the compaction status of the index and series file.
+func (t *TemporaryEngine) IndexCompactionStatus() (storage.IndexCompactionStatus, error) {
+	return t.engine.IndexCompactionStatus()
+}
+
 // DeleteBucketRangePredicate will delete a bucket from the range and predicate.
 func (t *TemporaryEngine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
 	return t.engine.DeleteBucketRangePredicate(ctx, orgID, bucketID, min, max, pred)
 
 }
 
+// ExportBucket exports the data of a bucket to files.
+func (t *TemporaryEngine) ExportBucket(ctx context.Context, orgID, bucketID influxdb.ID, fn func(storage.BucketExport) error) error {
+	return t.engine.ExportBucket(ctx, orgID, bucketID, fn)
+}
+
+// ImportBucket imports exported files into a bucket.
+func (t *TemporaryEngine) ImportBucket(ctx context.Context, orgID, bucketID influxdb.ID, fn func(dir string) error) error {
+	return t.engine.ImportBucket(ctx, orgID, bucketID, fn)
+}
+
+// DeleteMeasurementRange will delete a measurement of a bucket within the range.
+func (t *TemporaryEngine) DeleteMeasurementRange(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, min, max int64) error {
+	return t.engine.DeleteMeasurementRange(ctx, orgID, bucketID, measurement, min, max)
+}
+
 // DeleteBucket deletes a bucket from the time-series data.
 func (t *TemporaryEngine) DeleteBucket(ctx context.Context, orgID, bucketID influxdb.ID) error {
 	return t.engine.DeleteBucket(ctx, orgID, bucketID)
@@ -156,6 +189,26 @@ func (t *TemporaryEngine) TagValues(ctx context.Context, orgID, bucketID influxd
 	return t.engine.TagValues(ctx, orgID, bucketID, tagKey, start, end, predicate)
 }
 
+// MeasurementNames calls into the underlying engines MeasurementNames.
+func (t *TemporaryEngine) MeasurementNames(ctx context.Context, orgID, bucketID influxdb.ID, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error) {
+	return t.engine.MeasurementNames(ctx, orgID, bucketID, start, end, predicate)
+}
+
+// MeasurementTagKeys calls into the underlying engines MeasurementTagKeys.
+func (t *TemporaryEngine) MeasurementTagKeys(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error) {
+	return t.engine.MeasurementTagKeys(ctx, orgID, bucketID, measurement, start, end, predicate)
+}
+
+// MeasurementTagValues calls into the underlying engines MeasurementTagValues.
+func (t *TemporaryEngine) MeasurementTagValues(ctx context.Context, orgID, bucketID influxdb.ID, measurement, tagKey string, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error) {
+	return t.engine.MeasurementTagValues(ctx, orgID, bucketID, measurement, tagKey, start, end, predicate)
+}
+
+// MeasurementFields calls into the underlying engines MeasurementFields.
+func (t *TemporaryEngine) MeasurementFields(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, start, end int64, predicate influxql.Expr) (cursors.MeasurementFieldsIterator, error) {
+	return t.engine.MeasurementFields(ctx, orgID, bucketID, measurement, start, end, predicate)
+}
+
 // Flush will remove the time-series files and re-open the engine.
 func (t *TemporaryEngine) Flush(ctx context.Context) {
 	if err := t.Close(); err != nil {
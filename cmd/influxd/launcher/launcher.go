@@ -2,7 +2,7 @@ package launcher
 
 import (
 	"context"
-	"crypto/tls"
+	crand "crypto/rand"
 	"errors"
 	"fmt"
 	"io"
@@ -12,23 +12,41 @@ import (
 	_ "net/http/pprof" // needed to add pprof to our binary.
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/influxdata/flux"
 	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/alertconfig"
 	"github.com/influxdata/influxdb/v2/authorization"
 	"github.com/influxdata/influxdb/v2/authorizer"
+	"github.com/influxdata/influxdb/v2/authpreset"
 	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/bucketstate"
 	"github.com/influxdata/influxdb/v2/checks"
+	chronografbolt "github.com/influxdata/influxdb/v2/chronograf/bolt"
 	"github.com/influxdata/influxdb/v2/chronograf/server"
 	"github.com/influxdata/influxdb/v2/cmd/influxd/inspect"
+	"github.com/influxdata/influxdb/v2/connection"
+	"github.com/influxdata/influxdb/v2/cq"
 	"github.com/influxdata/influxdb/v2/dbrp"
+	"github.com/influxdata/influxdb/v2/dedup"
+	"github.com/influxdata/influxdb/v2/edge"
 	"github.com/influxdata/influxdb/v2/endpoints"
+	"github.com/influxdata/influxdb/v2/featureflag"
+	"github.com/influxdata/influxdb/v2/fluxfmt"
+	"github.com/influxdata/influxdb/v2/fluxlint"
+	"github.com/influxdata/influxdb/v2/fluxpkg"
+	"github.com/influxdata/influxdb/v2/forecast"
 	"github.com/influxdata/influxdb/v2/gather"
+	"github.com/influxdata/influxdb/v2/grafana"
 	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/ingest"
 	"github.com/influxdata/influxdb/v2/inmem"
 	"github.com/influxdata/influxdb/v2/internal/fs"
+	"github.com/influxdata/influxdb/v2/job"
+	"github.com/influxdata/influxdb/v2/kafkaingest"
 	"github.com/influxdata/influxdb/v2/kit/cli"
 	"github.com/influxdata/influxdb/v2/kit/feature"
 	overrideflagger "github.com/influxdata/influxdb/v2/kit/feature/override"
@@ -41,31 +59,58 @@ import (
 	"github.com/influxdata/influxdb/v2/kv/migration"
 	"github.com/influxdata/influxdb/v2/kv/migration/all"
 	"github.com/influxdata/influxdb/v2/label"
+	"github.com/influxdata/influxdb/v2/legalhold"
 	influxlogger "github.com/influxdata/influxdb/v2/logger"
+	"github.com/influxdata/influxdb/v2/monitor"
+	"github.com/influxdata/influxdb/v2/mqttingest"
 	"github.com/influxdata/influxdb/v2/nats"
+	"github.com/influxdata/influxdb/v2/notification/delivery"
+	"github.com/influxdata/influxdb/v2/notification/message"
+	"github.com/influxdata/influxdb/v2/notification/pagerduty"
+	"github.com/influxdata/influxdb/v2/notification/routing"
+	"github.com/influxdata/influxdb/v2/notification/status"
+	"github.com/influxdata/influxdb/v2/orgfreeze"
+	"github.com/influxdata/influxdb/v2/pkg/tlscert"
 	"github.com/influxdata/influxdb/v2/pkger"
+	"github.com/influxdata/influxdb/v2/policy"
 	infprom "github.com/influxdata/influxdb/v2/prometheus"
 	"github.com/influxdata/influxdb/v2/query"
 	"github.com/influxdata/influxdb/v2/query/control"
 	"github.com/influxdata/influxdb/v2/query/fluxlang"
 	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/v2/rollup"
+	"github.com/influxdata/influxdb/v2/rpc"
+	"github.com/influxdata/influxdb/v2/savedquery"
+	"github.com/influxdata/influxdb/v2/schema"
 	"github.com/influxdata/influxdb/v2/secret"
 	"github.com/influxdata/influxdb/v2/session"
 	"github.com/influxdata/influxdb/v2/snowflake"
 	"github.com/influxdata/influxdb/v2/source"
 	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/bucketarchive"
 	storageflux "github.com/influxdata/influxdb/v2/storage/flux"
+	"github.com/influxdata/influxdb/v2/storage/indexstatus"
 	"github.com/influxdata/influxdb/v2/storage/readservice"
+	"github.com/influxdata/influxdb/v2/subscription"
 	taskbackend "github.com/influxdata/influxdb/v2/task/backend"
 	"github.com/influxdata/influxdb/v2/task/backend/coordinator"
 	"github.com/influxdata/influxdb/v2/task/backend/executor"
 	"github.com/influxdata/influxdb/v2/task/backend/middleware"
+	"github.com/influxdata/influxdb/v2/task/backend/remote"
 	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxdb/v2/task/runlog"
+	"github.com/influxdata/influxdb/v2/task/taskauth"
 	"github.com/influxdata/influxdb/v2/telemetry"
 	"github.com/influxdata/influxdb/v2/tenant"
+	"github.com/influxdata/influxdb/v2/toml"
+	"github.com/influxdata/influxdb/v2/transfer"
+	"github.com/influxdata/influxdb/v2/trash"
 	_ "github.com/influxdata/influxdb/v2/tsdb/tsi1" // needed for tsi1
-	_ "github.com/influxdata/influxdb/v2/tsdb/tsm1" // needed for tsm1
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+	"github.com/influxdata/influxdb/v2/usersettings"
 	"github.com/influxdata/influxdb/v2/vault"
+	"github.com/influxdata/influxdb/v2/writerouting"
+	"github.com/influxdata/influxdb/v2/writewindow"
 	pzap "github.com/influxdata/influxdb/v2/zap"
 	"github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus"
@@ -73,7 +118,10 @@ import (
 	jaegerconfig "github.com/uber/jaeger-client-go/config"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/crypto/acme/autocert"
 	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 const (
@@ -185,18 +233,284 @@ func launcherOpts(l *Launcher) []cli.Opt {
 			Default: "",
 			Desc:    fmt.Sprintf("supported tracing types are %s, %s", LogTracing, JaegerTracing),
 		},
+		{
+			DestP: &l.sentryDSN,
+			Flag:  "sentry-dsn",
+			Desc:  "DSN of the Sentry compatible project the panics of the HTTP handlers are reported to, as https://<key>@<host>/<project>",
+		},
+		{
+			DestP: &l.sentryTags,
+			Flag:  "sentry-tags",
+			Desc:  "tags of the panics reported to Sentry, as key=value pairs",
+		},
 		{
 			DestP:   &l.httpBindAddress,
 			Flag:    "http-bind-address",
 			Default: ":9999",
 			Desc:    "bind address for the REST HTTP API",
 		},
+		{
+			DestP: &l.httpListenerSpecs,
+			Flag:  "http-listeners",
+			Desc:  "additional listeners of the REST HTTP API, as address;option=value;... The options are network (tcp, tcp4 or tcp6), tls-cert, tls-key, tls-min-version, tls-strict-ciphers, tls-client-ca, tls-acme and proxy-protocol",
+		},
+		{
+			DestP:   &l.httpProxyProtocol,
+			Flag:    "http-proxy-protocol",
+			Default: false,
+			Desc:    "read the addresses of the clients from the PROXY protocol v2 headers sent by the load balancers in front of http-bind-address",
+		},
+		{
+			DestP: &l.httpProxyProtocolNetworks,
+			Flag:  "http-proxy-protocol-networks",
+			Desc:  "networks of the load balancers trusted to send PROXY protocol headers, in CIDR notation. The connections of other networks are accepted without header. All networks are trusted when empty",
+		},
+		{
+			DestP:   &l.httpMaxRequestBodyBytes,
+			Flag:    "http-max-request-body-bytes",
+			Default: 10 * 1024 * 1024,
+			Desc:    "the maximum size of the body of a request to the metadata endpoints of the REST HTTP API. 0 disables the limit",
+		},
+		{
+			DestP:   &l.httpMaxWriteBodyBytes,
+			Flag:    "http-max-write-body-bytes",
+			Default: 0,
+			Desc:    "the maximum size of the body of a write request, before decompression. 0 disables the limit",
+		},
+		{
+			DestP: &l.httpMaxBodyBytesOverrides,
+			Flag:  "http-max-body-bytes-overrides",
+			Desc:  "the maximum size of the body of the requests to the paths starting with a prefix, as a list of prefix=bytes pairs overriding the limits of the metadata and write endpoints",
+		},
+		{
+			DestP:   &l.httpCORSPolicy,
+			Flag:    "http-cors-policy",
+			Default: "",
+			Desc:    "CORS policy of the REST HTTP API, as option=value;... overriding the default policy allowing any origin without credentials. The options are origins, methods, headers and exposed-headers, whose values are lists separated by |, credentials and max-age",
+		},
+		{
+			DestP: &l.httpCORSPolicyOverrides,
+			Flag:  "http-cors-policy-overrides",
+			Desc:  "CORS policies of the paths starting with a prefix, such as /api/v2/write, as a list of prefix;option=value;... overriding the options of http-cors-policy",
+		},
+		{
+			DestP:   &l.httpAccessLog,
+			Flag:    "http-access-log",
+			Default: "",
+			Desc:    "destination of the access log of the REST HTTP API: stdout, stderr, syslog or the path of a file. Disabled when empty, unless log-level is debug, which logs requests to the server log",
+		},
+		{
+			DestP:   &l.httpAccessLogFormat,
+			Flag:    "http-access-log-format",
+			Default: "logfmt",
+			Desc:    "format of the access log, logfmt or json",
+		},
+		{
+			DestP: &l.httpAccessLogSampleRates,
+			Flag:  "http-access-log-sample-rates",
+			Desc:  "the fraction of the requests to the paths starting with a prefix that are logged, as a list of prefix=rate pairs. Requests matching no prefix and server errors are always logged",
+		},
+		{
+			DestP: &l.httpAccessLogRedact,
+			Flag:  "http-access-log-redact",
+			Desc:  "URL query parameters whose values are redacted from the access log, in addition to tokens and passwords",
+		},
+		{
+			DestP:   &l.httpAccessLogQueryText,
+			Flag:    "http-access-log-query-text",
+			Default: false,
+			Desc:    "log the text of queries in the access log; it is redacted by default",
+		},
+		{
+			DestP:   &l.grpcBindAddress,
+			Flag:    "grpc-bind-address",
+			Default: "",
+			Desc:    "bind address for the internal gRPC API; disabled when empty",
+		},
+		{
+			DestP:   &l.grpcTLSCert,
+			Flag:    "grpc-tls-cert",
+			Default: "",
+			Desc:    "TLS certificate of the gRPC APIs; they are served with TLS when both grpc-tls-cert and grpc-tls-key are set",
+		},
+		{
+			DestP:   &l.grpcTLSKey,
+			Flag:    "grpc-tls-key",
+			Default: "",
+			Desc:    "TLS key of the gRPC APIs",
+		},
+		{
+			DestP:   &l.storageGRPCBindAddress,
+			Flag:    "storage-grpc-bind-address",
+			Default: "",
+			Desc:    "bind address of the gRPC storage read API (ReadFilter, ReadGroup, TagKeys and TagValues), streaming the raw series of the buckets a token can read; disabled when empty",
+		},
+		{
+			DestP:   &l.unauthenticatedWriteBindAddress,
+			Flag:    "unauthenticated-write-bind-address",
+			Default: "",
+			Desc:    "bind address of a listener accepting writes WITHOUT A TOKEN to the buckets of unauthenticated-write-buckets, for edge devices where distributing tokens is impractical. Bind it to an interface only reachable by the devices; disabled when empty",
+		},
+		{
+			DestP: &l.unauthenticatedWriteBuckets,
+			Flag:  "unauthenticated-write-buckets",
+			Desc:  "the IDs of the buckets that can be written to without a token on unauthenticated-write-bind-address",
+		},
+		{
+			DestP:   &l.unauthenticatedWriteNetworks,
+			Flag:    "unauthenticated-write-allowed-networks",
+			Default: []string{"127.0.0.0/8", "::1/128"},
+			Desc:    "the networks, in CIDR notation, of the clients allowed to write without a token on unauthenticated-write-bind-address",
+		},
+		{
+			DestP: &l.profile,
+			Flag:  "profile",
+			Desc:  "configuration profile of the node: edge keeps the points for at most edge-retention and forwards those of edge-buckets, downsampled, to a central instance; disabled when empty",
+		},
+		{
+			DestP: &l.edge.CentralURL,
+			Flag:  "edge-central-url",
+			Desc:  "URL of the central instance the edge profile forwards the points to",
+		},
+		{
+			DestP: &l.edge.Token,
+			Flag:  "edge-central-token",
+			Desc:  "token of the central instance the edge profile writes the points with",
+		},
+		{
+			DestP: &l.edge.Org,
+			Flag:  "edge-central-org",
+			Desc:  "name of the organization of the buckets of the central instance",
+		},
+		{
+			DestP: &l.edgeBuckets,
+			Flag:  "edge-buckets",
+			Desc:  "buckets whose points the edge profile forwards, as bucketID=central-bucket-name pairs",
+		},
+		{
+			DestP: &l.edge.NodeID,
+			Flag:  "edge-node-id",
+			Desc:  "ID of the edge node, the value of the edge tag of the points forwarded; the hostname when empty",
+		},
+		{
+			DestP:   &l.edge.Retention,
+			Flag:    "edge-retention",
+			Default: edge.DefaultRetention,
+			Desc:    "maximum retention period of the buckets of the edge profile",
+		},
+		{
+			DestP:   &l.edge.DownsampleInterval,
+			Flag:    "edge-downsample-interval",
+			Default: edge.DefaultDownsampleInterval,
+			Desc:    "interval of which the edge profile forwards the last value of each field of each series. 0 forwards all the points",
+		},
+		{
+			DestP:   &l.edge.QueuePath,
+			Flag:    "edge-queue-path",
+			Default: filepath.Join(dir, "edge"),
+			Desc:    "path to the queue of the points not yet forwarded to the central instance",
+		},
+		{
+			DestP:   &l.edgeMaxQueueSize,
+			Flag:    "edge-max-queue-size",
+			Default: edge.DefaultMaxQueueSize,
+			Desc:    "maximum size in bytes of the queue of the points not yet forwarded, above which the oldest are dropped",
+		},
+		{
+			DestP: &l.kafkaIngest.Brokers,
+			Flag:  "kafka-ingest-brokers",
+			Desc:  "the addresses of the Kafka brokers whose kafka-ingest-topics are written to buckets; disabled when empty",
+		},
+		{
+			DestP: &l.kafkaIngestTopics,
+			Flag:  "kafka-ingest-topics",
+			Desc:  "the Kafka topics consumed and the IDs of the buckets their messages are written to, as topic=bucketID pairs",
+		},
+		{
+			DestP:   &l.kafkaIngest.GroupID,
+			Flag:    "kafka-ingest-group-id",
+			Default: kafkaingest.DefaultGroupID,
+			Desc:    "the consumer group the Kafka topics are consumed and their offsets committed as",
+		},
+		{
+			DestP:   &l.kafkaIngestFormat,
+			Flag:    "kafka-ingest-format",
+			Default: string(ingest.FormatLineProtocol),
+			Desc:    "the format of the messages of the Kafka topics: line for line protocol, or json",
+		},
+		{
+			DestP:   &l.kafkaIngest.Precision,
+			Flag:    "kafka-ingest-precision",
+			Default: "ns",
+			Desc:    "the precision of the times of the points of the Kafka topics: ns, us, ms or s",
+		},
+		{
+			DestP: &l.kafkaIngest.DeadLetterTopic,
+			Flag:  "kafka-ingest-dead-letter-topic",
+			Desc:  "the Kafka topic the messages that cannot be written are produced to; they are dropped when empty",
+		},
+		{
+			DestP: &l.mqttIngest.Broker,
+			Flag:  "mqtt-ingest-broker",
+			Desc:  "the URL of the MQTT broker whose mqtt-ingest-topics are written to buckets, such as tcp://localhost:1883; disabled when empty",
+		},
+		{
+			DestP: &l.mqttIngestTopics,
+			Flag:  "mqtt-ingest-topics",
+			Desc:  "the MQTT topic templates subscribed to and the IDs of the buckets their messages are written to, as template=bucketID pairs. Named levels of a template, such as {device}, tag the points with the level, and {_measurement} is their measurement",
+		},
+		{
+			DestP:   &l.mqttIngest.ClientID,
+			Flag:    "mqtt-ingest-client-id",
+			Default: mqttingest.DefaultClientID,
+			Desc:    "the client ID influxd connects to the MQTT broker as, whose session keeps the messages published while it is disconnected",
+		},
+		{
+			DestP: &l.mqttIngest.Username,
+			Flag:  "mqtt-ingest-username",
+			Desc:  "the username influxd connects to the MQTT broker with",
+		},
+		{
+			DestP: &l.mqttIngest.Password,
+			Flag:  "mqtt-ingest-password",
+			Desc:  "the password influxd connects to the MQTT broker with",
+		},
+		{
+			DestP:   &l.mqttIngest.QoS,
+			Flag:    "mqtt-ingest-qos",
+			Default: 1,
+			Desc:    "the quality of service of the MQTT subscriptions: 0, 1 or 2",
+		},
+		{
+			DestP:   &l.mqttIngestFormat,
+			Flag:    "mqtt-ingest-format",
+			Default: string(ingest.FormatLineProtocol),
+			Desc:    "the format of the payloads of the MQTT messages: line for line protocol, or json for objects of fields",
+		},
+		{
+			DestP:   &l.mqttIngest.Precision,
+			Flag:    "mqtt-ingest-precision",
+			Default: "ns",
+			Desc:    "the precision of the times of the points of the MQTT messages: ns, us, ms or s",
+		},
 		{
 			DestP:   &l.boltPath,
 			Flag:    "bolt-path",
 			Default: filepath.Join(dir, bolt.DefaultFilename),
 			Desc:    "path to boltdb database",
 		},
+		{
+			DestP:   &l.boltCompactionInterval,
+			Flag:    "bolt-compaction-interval",
+			Default: 24 * time.Hour,
+			Desc:    "how often the boltdb file is checked for compaction. 0 disables compactions",
+		},
+		{
+			DestP:   &l.boltCompactionFreeRatio,
+			Flag:    "bolt-compaction-free-ratio",
+			Default: bolt.DefaultCompactionFreeRatio,
+			Desc:    "ratio of the boltdb file in free pages above which it is compacted",
+		},
 		{
 			DestP: &l.assetsPath,
 			Flag:  "assets-path",
@@ -220,12 +534,92 @@ func launcherOpts(l *Launcher) []cli.Opt {
 			Default: filepath.Join(dir, "engine"),
 			Desc:    "path to persistent engine files",
 		},
+		{
+			DestP:   &l.subscriptionsPath,
+			Flag:    "subscriptions-path",
+			Default: filepath.Join(dir, "subscriptions"),
+			Desc:    "path to the queues of the points written to the buckets of subscriptions, not yet sent to their destinations",
+		},
+		{
+			DestP:   &l.subscriptionsMaxQueueSize,
+			Flag:    "subscriptions-max-queue-size",
+			Default: subscription.DefaultMaxQueueSize,
+			Desc:    "maximum size in bytes of the queue of each subscription, above which its oldest points are dropped",
+		},
+		{
+			DestP: &l.subscriptionsMirrorToken,
+			Flag:  "subscriptions-mirror-token",
+			Desc:  "token of the other instance authorizing the writes of the mirror subscriptions",
+		},
+		{
+			DestP: &l.subscriptionsMirrorWriteToken,
+			Flag:  "subscriptions-mirror-write-token",
+			Desc:  "token of this instance the mirror subscription of the other instance writes with, the only one allowed to send the write time of mirrored points",
+		},
+		{
+			DestP:   &l.subscriptionsMirrorMaxPoints,
+			Flag:    "subscriptions-mirror-max-points",
+			Default: subscription.DefaultMirrorMaxPoints,
+			Desc:    "maximum number of writes to mirrored buckets remembered to resolve their conflicts, above which the oldest are forgotten",
+		},
+		{
+			DestP:   &l.subscriptionsMirrorWindow,
+			Flag:    "subscriptions-mirror-window",
+			Default: subscription.DefaultMirrorWindow,
+			Desc:    "time during which the writes to mirrored buckets are remembered to resolve their conflicts with the points mirrored from the other instance",
+		},
+		{
+			DestP:   &l.systemBucketTasksID,
+			Flag:    "system-bucket-tasks-id",
+			Default: platform.TasksSystemBucketID.String(),
+			Desc:    "ID of the tasks system bucket served to the organizations that have none stored. The previous ID keeps working as a redirect when it is the default",
+		},
+		{
+			DestP:   &l.systemBucketTasksName,
+			Flag:    "system-bucket-tasks-name",
+			Default: platform.TasksSystemBucketName,
+			Desc:    "name the tasks system buckets are created and looked up with. Buckets stored with the default name are still found",
+		},
+		{
+			DestP:   &l.systemBucketMonitoringID,
+			Flag:    "system-bucket-monitoring-id",
+			Default: platform.MonitoringSystemBucketID.String(),
+			Desc:    "ID of the monitoring system bucket served to the organizations that have none stored. The previous ID keeps working as a redirect when it is the default",
+		},
+		{
+			DestP:   &l.systemBucketMonitoringName,
+			Flag:    "system-bucket-monitoring-name",
+			Default: platform.MonitoringSystemBucketName,
+			Desc:    "name the monitoring system buckets are created and looked up with. Buckets stored with the default name are still found",
+		},
+		{
+			DestP: &l.systemBucketRedirects,
+			Flag:  "system-bucket-redirects",
+			Desc:  "IDs buckets were relocated from, as a list of oldID=newID pairs, so that the references to the old IDs find the buckets",
+		},
+		{
+			DestP:   &l.jobsPath,
+			Flag:    "jobs-path",
+			Default: filepath.Join(dir, "jobs"),
+			Desc:    "path to the results of the background jobs, such as backups, bucket exports and deletes",
+		},
 		{
 			DestP:   &l.secretStore,
 			Flag:    "secret-store",
 			Default: "bolt",
 			Desc:    "data store for secrets (bolt or vault)",
 		},
+		{
+			DestP: &l.secretEncryptionKMS,
+			Flag:  "secret-encryption-kms",
+			Desc:  "key management service the organizations register the keys encrypting their secrets and backups with: vault for the transit secrets engine of Vault. Requires the bolt secret-store; disabled when empty",
+		},
+		{
+			DestP:   &l.vaultTransitMount,
+			Flag:    "vault-transit-mount",
+			Default: vault.DefaultTransitMount,
+			Desc:    "path the transit secrets engine of Vault is mounted at",
+		},
 		{
 			DestP:   &l.reportingDisabled,
 			Flag:    "reporting-disabled",
@@ -244,6 +638,17 @@ func launcherOpts(l *Launcher) []cli.Opt {
 			Default: false,
 			Desc:    "disables automatically extending session ttl on request",
 		},
+		{
+			DestP:   &l.querySigningKey,
+			Flag:    "query-signing-key",
+			Default: "",
+			Desc:    "the key signing the urls of the queries fetched without a token. The urls signed with a random key when empty are invalidated on restart",
+		},
+		{
+			DestP: &l.onboardingTemplates,
+			Flag:  "onboarding-templates",
+			Desc:  "the templates applied to the organizations created by onboarding, as a list of name=source pairs. The source is the path or http(s) url of a template",
+		},
 		{
 			DestP: &vaultConfig.Address,
 			Flag:  "vault-addr",
@@ -318,12 +723,134 @@ func launcherOpts(l *Launcher) []cli.Opt {
 			Default: false,
 			Desc:    "Restrict accept ciphers to: ECDHE_RSA_WITH_AES_256_GCM_SHA384, ECDHE_RSA_WITH_AES_256_CBC_SHA, RSA_WITH_AES_256_GCM_SHA384, RSA_WITH_AES_256_CBC_SHA",
 		},
+		{
+			DestP:   &l.httpTLSClientCA,
+			Flag:    "tls-client-ca",
+			Default: "",
+			Desc:    "path to the PEM-encoded certificates of the authorities of the client certificates accepted by the HTTPs API. Clients without a token or session are authenticated by their certificate",
+		},
+		{
+			DestP: &l.httpTLSClientCertUsers,
+			Flag:  "tls-client-cert-users",
+			Desc:  "the users of client certificates, as a list of identity=user pairs. The identity is the common name or a subject alternative name of the certificates",
+		},
+		{
+			DestP:   &l.tlsReloadInterval,
+			Flag:    "tls-reload-interval",
+			Default: tlscert.DefaultReloadInterval,
+			Desc:    "interval the TLS certificate and key files are checked for changes at, to reload them without a restart. They are also reloaded on SIGHUP. 0 only reloads them on SIGHUP",
+		},
+		{
+			DestP: &l.tlsACMEDomains,
+			Flag:  "tls-acme-domains",
+			Desc:  "domains whose certificates are provisioned and renewed with ACME, e.g. Let's Encrypt, for the HTTPs API instead of tls-cert and tls-key",
+		},
+		{
+			DestP:   &l.tlsACMEEmail,
+			Flag:    "tls-acme-email",
+			Default: "",
+			Desc:    "contact email of the ACME account, notified of the problems with the certificates",
+		},
+		{
+			DestP:   &l.tlsACMEDirectoryURL,
+			Flag:    "tls-acme-directory-url",
+			Default: autocert.DefaultACMEDirectory,
+			Desc:    "directory URL of the ACME server",
+		},
+		{
+			DestP:   &l.tlsACMECacheDir,
+			Flag:    "tls-acme-cache-dir",
+			Default: filepath.Join(dir, "acme"),
+			Desc:    "path to the directory the ACME account and certificates are stored in",
+		},
+		{
+			DestP:   &l.tlsACMEHTTPBindAddress,
+			Flag:    "tls-acme-http-bind-address",
+			Default: "",
+			Desc:    "bind address answering the HTTP-01 challenges of the ACME server, usually :80, and redirecting other requests to HTTPs. Only TLS-ALPN-01 challenges are answered by the HTTPs API when empty",
+		},
+		{
+			DestP:   &l.authorizerPolicy,
+			Flag:    "authorizer-policy",
+			Default: "",
+			Desc:    "the address of a policy engine deciding the permission checks: the http(s) URL of an Open Policy Agent rule, a grpc(s)://host:port policy service or a plugin:///path/to/engine.so Go plugin. Checks are denied when it fails; disabled when empty",
+		},
+		{
+			DestP:   &l.authorizerPolicyTimeout,
+			Flag:    "authorizer-policy-timeout",
+			Default: 2 * time.Second,
+			Desc:    "the time the policy engine has to decide a permission check",
+		},
+		{
+			DestP:   &l.authorizerPolicyCacheTTL,
+			Flag:    "authorizer-policy-cache-ttl",
+			Default: 30 * time.Second,
+			Desc:    "the time the decisions of the policy engine are cached for; not cached when 0",
+		},
 		{
 			DestP:   &l.noTasks,
 			Flag:    "no-tasks",
 			Default: false,
 			Desc:    "disables the task scheduler",
 		},
+		{
+			DestP:   &l.taskRemoteWorkers,
+			Flag:    "task-remote-workers",
+			Default: false,
+			Desc:    "executes the runs of tasks on remote workers started with 'influxd task-worker' instead of in process",
+		},
+		{
+			DestP:   &l.fluxFormatOnSave,
+			Flag:    "flux-format-on-save",
+			Default: false,
+			Desc:    "stores the scripts of tasks and the queries of checks in the canonical form of the Flux formatter. Scripts with comments are stored as they are",
+		},
+		{
+			DestP: &l.alertsConfig,
+			Flag:  "alerts-config",
+			Desc:  "YAML or JSON file of checks, notification rules and endpoints by organization, reconciled at startup. Resources are matched by name; with prune: true, those of the organizations that the file does not list are deleted. Startup fails if a resource cannot be reconciled, and nothing is pruned then",
+		},
+		{
+			DestP: &l.scraperFileSDDir,
+			Flag:  "scraper-file-sd-dir",
+			Desc:  "directory containing file based service discovery files for scrapers, in a subdirectory per organization named by its ID. If unset, file discovery is disabled",
+		},
+		{
+			DestP:   &l.scraperKubernetesSD,
+			Flag:    "scraper-kubernetes-sd",
+			Default: false,
+			Desc:    "enables kubernetes service discovery for scrapers using the in-cluster service account",
+		},
+		{
+			DestP:   &l.monitoringRetention,
+			Flag:    "monitoring-retention",
+			Default: "",
+			Desc:    "retention period of the _monitoring bucket of every organization. 0 keeps monitoring data forever. If unset, the retention periods of the buckets are left as they are",
+		},
+		{
+			DestP:   &l.monitoring.DownsampleAfter,
+			Flag:    "monitoring-downsample-after",
+			Default: time.Duration(0),
+			Desc:    "age after which statuses and notifications are counted by hour into the _monitoring_downsampled bucket. If unset, monitoring data is not downsampled",
+		},
+		{
+			DestP:   &l.monitoring.DownsampledRetention,
+			Flag:    "monitoring-downsampled-retention",
+			Default: time.Duration(0),
+			Desc:    "retention period of the _monitoring_downsampled bucket of every organization. 0 keeps downsampled data forever",
+		},
+		{
+			DestP:   &l.metadataVerifyInterval,
+			Flag:    "metadata-verify-interval",
+			Default: 24 * time.Hour,
+			Desc:    "how often the metadata store is checked for mappings of deleted users, labels and resources. 0 disables the check",
+		},
+		{
+			DestP:   &l.metadataRepair,
+			Flag:    "metadata-repair",
+			Default: false,
+			Desc:    "delete the mappings of deleted users, labels and resources found by the metadata check",
+		},
 		{
 			DestP:   &l.concurrencyQuota,
 			Flag:    "query-concurrency",
@@ -354,17 +881,91 @@ func launcherOpts(l *Launcher) []cli.Opt {
 			Default: 10,
 			Desc:    "the number of queries that are allowed to be awaiting execution before new queries are rejected",
 		},
+		{
+			DestP:   &l.queueSizePerTenant,
+			Flag:    "query-queue-size-per-tenant",
+			Default: 0,
+			Desc:    "the number of queries of a single org, or token with query-queue-by-token, that are allowed to be awaiting execution before its new queries are rejected. If this is unset, then query-queue-size will be used",
+		},
+		{
+			DestP: &l.queueShares,
+			Flag:  "query-queue-shares",
+			Desc:  "the share of the query queue of orgs, or tokens with query-queue-by-token, as a list of ID=share pairs. Queries awaiting execution are executed in proportion to the shares, which default to 1",
+		},
+		{
+			DestP:   &l.queueByToken,
+			Flag:    "query-queue-by-token",
+			Default: false,
+			Desc:    "share the query queue between tokens rather than orgs",
+		},
+		{
+			DestP:   &l.archivedReadConcurrency,
+			Flag:    "query-archived-bucket-concurrency",
+			Default: bucketstate.DefaultArchivedReadConcurrency,
+			Desc:    "the number of reads of archived buckets that are allowed to execute concurrently, so that they run at a lower priority than the reads of active buckets",
+		},
 		{
 			DestP:   &l.pageFaultRate,
 			Flag:    "page-fault-rate",
 			Default: 0,
 			Desc:    "the number of page faults allowed per second in the storage engine",
 		},
+		{
+			DestP:   &l.storageWriteTimeout,
+			Flag:    "storage-write-timeout",
+			Default: 10 * time.Second,
+			Desc:    "the maximum duration of the write of a points batch to the storage engine. 0 disables the timeout",
+		},
+		{
+			DestP:   &l.storageCacheAutoTune,
+			Flag:    "storage-cache-auto-tune",
+			Default: false,
+			Desc:    "adjust the maximum size of the write cache and its snapshot size to the write rate and the available memory",
+		},
+		{
+			DestP:   &l.storageCacheMinMemoryBytes,
+			Flag:    "storage-cache-min-memory-bytes",
+			Default: int(tsm1.DefaultCacheAutoTuneMinMemorySize),
+			Desc:    "the lower bound of the maximum size of the write cache when storage-cache-auto-tune is set",
+		},
+		{
+			DestP:   &l.storageCacheMaxMemoryBytes,
+			Flag:    "storage-cache-max-memory-bytes",
+			Default: int(tsm1.DefaultCacheAutoTuneMaxMemorySize),
+			Desc:    "the upper bound of the maximum size of the write cache when storage-cache-auto-tune is set",
+		},
+		{
+			DestP:   &l.storageMaxOpenFiles,
+			Flag:    "storage-max-open-files",
+			Default: tsm1.DefaultMaxOpenFiles,
+			Desc:    "the maximum number of file descriptors held open by TSM files. The descriptors of the least recently used files are closed past it. 0 means no limit",
+		},
+		{
+			DestP: &l.storageDataDirs,
+			Flag:  "storage-data-dirs",
+			Desc:  "additional directories TSM files are loaded from, such as on other volumes, which files of the engine path are moved to by influxd inspect rebalance",
+		},
+		{
+			DestP:   &l.storageRetentionDeleteRate,
+			Flag:    "storage-retention-delete-rate",
+			Default: 0,
+			Desc:    "the number of bytes per second freed on disk the deletion of expired data is paced to, one bucket at a time. 0 deletes expired data without pacing",
+		},
+		{
+			DestP: &l.storageRetentionWindow,
+			Flag:  "storage-retention-window",
+			Desc:  "the daily window, in UTC, in which expired data is deleted, such as 01:00-05:00. Expired data is deleted at any time if unset",
+		},
 		{
 			DestP: &l.featureFlags,
 			Flag:  "feature-flags",
 			Desc:  "feature flag overrides",
 		},
+		{
+			DestP: &l.featureFlagRequestOverrides,
+			Flag:  "feature-flag-request-overrides",
+			Desc:  "allow requests to override feature flags with their X-Influxdb-Feature-Flags header, for development environments only",
+		},
 	}
 }
 
@@ -379,18 +980,71 @@ type Launcher struct {
 	testing              bool
 	sessionLength        int // in minutes
 	sessionRenewDisabled bool
+	querySigningKey      string
+
+	onboardingTemplates map[string]string
 
 	logLevel          string
 	tracingType       string
 	reportingDisabled bool
 
+	sentryDSN  string
+	sentryTags map[string]string
+
 	httpBindAddress string
+
+	httpListenerSpecs         []string
+	httpListeners             []*httpListener
+	httpProxyProtocol         bool
+	httpProxyProtocolNetworks []string
+
+	grpcBindAddress string
+	grpcTLSCert     string
+	grpcTLSKey      string
 	boltPath        string
 	enginePath      string
 	secretStore     string
 
-	featureFlags map[string]string
-	flagger      feature.Flagger
+	secretEncryptionKMS string
+	vaultTransitMount   string
+
+	subscriptionsPath             string
+	subscriptionsMaxQueueSize     int
+	subscriptionsMirrorToken      string
+	subscriptionsMirrorWriteToken string
+	subscriptionsMirrorWindow     time.Duration
+	subscriptionsMirrorMaxPoints  int
+
+	jobsPath string
+
+	systemBucketTasksID        string
+	systemBucketTasksName      string
+	systemBucketMonitoringID   string
+	systemBucketMonitoringName string
+	systemBucketRedirects      map[string]string
+
+	// boltCompactionInterval is how often the bolt file is checked for
+	// compaction, which it is once boltCompactionFreeRatio of it is free.
+	boltCompactionInterval  time.Duration
+	boltCompactionFreeRatio float64
+
+	httpMaxRequestBodyBytes   int
+	httpMaxWriteBodyBytes     int
+	httpMaxBodyBytesOverrides map[string]string
+
+	httpCORSPolicy          string
+	httpCORSPolicyOverrides []string
+
+	httpAccessLog            string
+	httpAccessLogFormat      string
+	httpAccessLogSampleRates map[string]string
+	httpAccessLogRedact      []string
+	httpAccessLogQueryText   bool
+	accessLogCloser          io.Closer
+
+	featureFlags                map[string]string
+	featureFlagRequestOverrides bool
+	flagger                     feature.Flagger
 
 	// Query options.
 	concurrencyQuota                int
@@ -398,6 +1052,10 @@ type Launcher struct {
 	memoryBytesQuotaPerQuery        int
 	maxMemoryBytes                  int
 	queueSize                       int
+	queueSizePerTenant              int
+	queueShares                     map[string]string
+	queueByToken                    bool
+	archivedReadConcurrency         int
 
 	boltClient    *bolt.Client
 	kvStore       kv.SchemaStore
@@ -405,7 +1063,20 @@ type Launcher struct {
 	engine        Engine
 	StorageConfig storage.Config
 
+	storageWriteTimeout time.Duration
+
+	storageCacheAutoTune       bool
+	storageCacheMinMemoryBytes int
+	storageCacheMaxMemoryBytes int
+	storageMaxOpenFiles        int
+	storageDataDirs            []string
+	storageRetentionDeleteRate int
+	storageRetentionWindow     string
+
 	queryController *control.Controller
+	// queryService resolves user-defined flux package imports before
+	// queries are passed to the queryController.
+	queryService query.AsyncQueryService
 
 	httpPort             int
 	httpServer           *nethttp.Server
@@ -414,10 +1085,62 @@ type Launcher struct {
 	httpTLSMinVersion    string
 	httpTLSStrictCiphers bool
 
+	httpTLSClientCA        string
+	httpTLSClientCertUsers map[string]string
+
+	tlsReloadInterval      time.Duration
+	tlsACMEDomains         []string
+	tlsACMEEmail           string
+	tlsACMEDirectoryURL    string
+	tlsACMECacheDir        string
+	tlsACMEHTTPBindAddress string
+	acmeHTTPServer         *nethttp.Server
+
+	authorizerPolicy         string
+	authorizerPolicyTimeout  time.Duration
+	authorizerPolicyCacheTTL time.Duration
+	policyEngine             authorizer.PolicyEngine
+
+	grpcServer             *grpc.Server
+	storageGRPCBindAddress string
+	storageGRPCServer      *grpc.Server
+
+	profile          string
+	edge             edge.Config
+	edgeBuckets      map[string]string
+	edgeMaxQueueSize int
+
+	kafkaIngest       kafkaingest.Config
+	kafkaIngestTopics map[string]string
+	kafkaIngestFormat string
+
+	mqttIngest       mqttingest.Config
+	mqttIngestTopics map[string]string
+	mqttIngestFormat string
+
+	unauthenticatedWriteBindAddress string
+	unauthenticatedWriteBuckets     []string
+	unauthenticatedWriteNetworks    []string
+	unauthenticatedWriteServer      *nethttp.Server
+
 	natsServer *nats.Server
 	natsPort   int
 
+	scraperFileSDDir    string
+	scraperKubernetesSD bool
+
+	alertsConfig string
+
+	monitoring          monitor.Config
+	monitoringRetention string
+
+	metadataVerifyInterval time.Duration
+	metadataRepair         bool
+
 	noTasks            bool
+	taskRemoteWorkers  bool
+	fluxFormatOnSave   bool
+	taskDispatcher     *remote.Dispatcher
 	scheduler          stoppingScheduler
 	executor           *executor.Executor
 	taskControlService taskbackend.TaskControlService
@@ -489,6 +1212,26 @@ func (m *Launcher) Engine() Engine {
 func (m *Launcher) Shutdown(ctx context.Context) {
 	m.httpServer.Shutdown(ctx)
 
+	if m.grpcServer != nil {
+		m.log.Info("Stopping", zap.String("service", "grpc"))
+		m.grpcServer.GracefulStop()
+	}
+
+	if m.storageGRPCServer != nil {
+		m.log.Info("Stopping", zap.String("service", "storage-grpc"))
+		m.storageGRPCServer.GracefulStop()
+	}
+
+	if m.unauthenticatedWriteServer != nil {
+		m.log.Info("Stopping", zap.String("service", "unauthenticated-write"))
+		m.unauthenticatedWriteServer.Shutdown(ctx)
+	}
+
+	if m.acmeHTTPServer != nil {
+		m.log.Info("Stopping", zap.String("service", "acme-http"))
+		m.acmeHTTPServer.Shutdown(ctx)
+	}
+
 	m.log.Info("Stopping", zap.String("service", "task"))
 
 	m.scheduler.Stop()
@@ -513,8 +1256,14 @@ func (m *Launcher) Shutdown(ctx context.Context) {
 
 	m.wg.Wait()
 
-	if m.jaegerTracerCloser != nil {
-		if err := m.jaegerTracerCloser.Close(); err != nil {
+	if m.accessLogCloser != nil {
+		if err := m.accessLogCloser.Close(); err != nil {
+			m.log.Warn("Failed to close access log", zap.Error(err))
+		}
+	}
+
+	if m.jaegerTracerCloser != nil {
+		if err := m.jaegerTracerCloser.Close(); err != nil {
 			m.log.Warn("Failed to closer Jaeger tracer", zap.Error(err))
 		}
 	}
@@ -570,6 +1319,14 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		zap.String("build_date", info.Date),
 	)
 
+	systemBuckets, err := m.systemBuckets()
+	if err != nil {
+		return err
+	}
+	if err := systemBuckets.Valid(); err != nil {
+		return fmt.Errorf("invalid system buckets: %v", err)
+	}
+
 	switch m.tracingType {
 	case LogTracing:
 		m.log.Info("Tracing via zap logging")
@@ -592,6 +1349,18 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		m.jaegerTracerCloser = closer
 	}
 
+	if m.authorizerPolicy != "" {
+		m.policyEngine, err = policy.Open(m.authorizerPolicy, m.authorizerPolicyTimeout)
+		if err != nil {
+			m.log.Error("Failed opening authorizer policy engine", zap.Error(err))
+			return err
+		}
+		if m.authorizerPolicyCacheTTL > 0 {
+			m.policyEngine = policy.NewCache(m.policyEngine, m.authorizerPolicyCacheTTL, policy.DefaultCacheSize)
+		}
+		m.log.Info("Delegating permission checks to policy engine")
+	}
+
 	m.boltClient = bolt.NewClient(m.log.With(zap.String("service", "bolt")))
 	m.boltClient.Path = m.boltPath
 
@@ -603,13 +1372,16 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	serviceConfig := kv.ServiceConfig{
 		SessionLength:       time.Duration(m.sessionLength) * time.Minute,
 		FluxLanguageService: fluxlang.DefaultService,
+		SystemBuckets:       systemBuckets,
 	}
 
 	flushers := flushers{}
+	var boltStore *bolt.KVStore
 	switch m.storeType {
 	case BoltStore:
 		store := bolt.NewKVStore(m.log.With(zap.String("service", "kvstore-bolt")), m.boltPath)
 		store.WithDB(m.boltClient.DB())
+		boltStore = store
 		m.kvStore = store
 		m.kvService = kv.NewService(m.log.With(zap.String("store", "kv")), store, serviceConfig)
 		if m.testing {
@@ -666,7 +1438,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		notificationEndpointStore platform.NotificationEndpointService     = m.kvService
 	)
 
-	tenantStore := tenant.NewStore(m.kvStore)
+	tenantStore := tenant.NewStore(m.kvStore, tenant.WithSystemBuckets(systemBuckets))
 	ts := tenant.NewSystem(tenantStore, m.log.With(zap.String("store", "new")), m.reg, metric.WithSuffix("new"))
 
 	secretStore, err := secret.NewStore(m.kvStore)
@@ -675,12 +1447,41 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
-	var secretSvc platform.SecretService = secret.NewMetricService(m.reg, secret.NewLogger(m.log.With(zap.String("service", "secret")), secret.NewService(secretStore)))
+	// Organizations may register a key of the key management service to
+	// encrypt their secrets and the archives of their buckets.
+	var keyManager platform.KeyManager
+	switch m.secretEncryptionKMS {
+	case "":
+	case "vault":
+		km, err := vault.NewKeyManager(m.vaultTransitMount, vault.WithConfig(vaultConfig))
+		if err != nil {
+			m.log.Error("Failed initializing vault key manager", zap.Error(err))
+			return err
+		}
+		keyManager = km
+	default:
+		err := fmt.Errorf("unknown secret encryption kms %q, expected \"vault\"", m.secretEncryptionKMS)
+		m.log.Error("Failed setting secret encryption", zap.Error(err))
+		return err
+	}
 
+	boltSecretSvc := secret.NewService(secretStore, secret.WithKeyManager(keyManager))
+	var secretSvc platform.SecretService = secret.NewMetricService(m.reg, secret.NewLogger(m.log.With(zap.String("service", "secret")), boltSecretSvc))
+
+	// secretKeySvc is nil when the secrets cannot be encrypted.
+	var secretKeySvc *secret.Service
 	switch m.secretStore {
 	case "bolt":
 		// If it is bolt, then we already set it above.
+		if keyManager != nil {
+			secretKeySvc = boltSecretSvc
+		}
 	case "vault":
+		if keyManager != nil {
+			err := errors.New("secret encryption requires the bolt secret store")
+			m.log.Error("Failed setting secret encryption", zap.Error(err))
+			return err
+		}
 		// The vault secret service is configured using the standard vault environment variables.
 		// https://www.vaultproject.io/docs/commands/index.html#environment-variables
 		svc, err := vault.NewSecretService(vault.WithConfig(vaultConfig))
@@ -695,28 +1496,86 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
-	chronografSvc, err := server.NewServiceV2(ctx, m.boltClient.DB())
+	// The secrets of connection profiles are only written through their
+	// profiles, which check them against the hosts they allow.
+	connectionSvc := connection.NewService(m.kvStore, secretSvc)
+	secretSvc = connection.NewSecretService(secretSvc)
+
+	chronografDB := chronografbolt.NewClient()
+	chronografDB.WithDB(m.boltClient.DB())
+	chronografSvc, err := server.NewServiceV2(ctx, chronografDB)
 	if err != nil {
 		m.log.Error("Failed creating chronograf service", zap.Error(err))
 		return err
 	}
 
+	// The bolt file is compacted once its free pages exceed a ratio of its
+	// size. The users sharing its database are given the compacted one.
+	if boltStore != nil && m.boltCompactionInterval > 0 {
+		boltStore.OnCompacted(m.boltClient.WithDB)
+		boltStore.OnCompacted(chronografDB.WithDB)
+
+		log := m.log.With(zap.String("service", "bolt-compactor"))
+		compactor := bolt.NewCompactor(log, boltStore, m.boltCompactionInterval, m.boltCompactionFreeRatio)
+		m.reg.MustRegister(compactor.PrometheusCollectors()...)
+		m.wg.Add(1)
+		go func(log *zap.Logger) {
+			defer m.wg.Done()
+			compactor.Run(ctx)
+			log.Info("Stopping")
+		}(log)
+	}
+
 	// Enable storage layer page fault limiting if rate set above zero.
 	var pageFaultLimiter *rate.Limiter
 	if m.pageFaultRate > 0 {
 		pageFaultLimiter = rate.NewLimiter(rate.Limit(m.pageFaultRate), 1)
 	}
 
+	legalHoldSvc := legalhold.NewService(m.kvStore, ts.BucketService)
+
+	if m.storageCacheAutoTune {
+		m.StorageConfig.Engine.Cache.AutoTune = true
+		m.StorageConfig.Engine.Cache.AutoTuneMinMemorySize = toml.Size(m.storageCacheMinMemoryBytes)
+		m.StorageConfig.Engine.Cache.AutoTuneMaxMemorySize = toml.Size(m.storageCacheMaxMemoryBytes)
+	}
+	if m.storageMaxOpenFiles > 0 {
+		m.StorageConfig.Engine.MaxOpenFiles = m.storageMaxOpenFiles
+	}
+	if len(m.storageDataDirs) > 0 {
+		m.StorageConfig.Engine.DataDirs = m.storageDataDirs
+	}
+	if m.storageRetentionDeleteRate > 0 {
+		m.StorageConfig.RetentionDeleteRate = toml.Size(m.storageRetentionDeleteRate)
+	}
+	if m.storageRetentionWindow != "" {
+		m.StorageConfig.RetentionWindow = m.storageRetentionWindow
+	}
+
+	// Edge nodes keep the points of their buckets for a short time, as they
+	// are forwarded to the central instance.
+	var retentionFinder storage.BucketFinder = ts.BucketService
+	switch m.profile {
+	case "":
+	case edge.Profile:
+		retentionFinder = edge.NewRetentionFinder(ts.BucketService, m.edge.Retention)
+	default:
+		err := fmt.Errorf("unknown profile %q", m.profile)
+		m.log.Error("Invalid configuration profile", zap.Error(err))
+		return err
+	}
+
 	if m.testing {
 		// the testing engine will write/read into a temporary directory
-		engine := NewTemporaryEngine(m.StorageConfig, storage.WithRetentionEnforcer(ts.BucketService))
+		engine := NewTemporaryEngine(m.StorageConfig, storage.WithRetentionEnforcer(retentionFinder), storage.WithLegalHolds(legalHoldSvc))
 		flushers = append(flushers, engine)
 		m.engine = engine
 	} else {
 		m.engine = storage.NewEngine(
 			m.enginePath,
 			m.StorageConfig,
-			storage.WithRetentionEnforcer(ts.BucketService),
+			storage.WithRetentionEnforcer(retentionFinder),
+			storage.WithLegalHolds(legalHoldSvc),
 			storage.WithPageFaultLimiter(pageFaultLimiter),
 		)
 	}
@@ -728,16 +1587,66 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	// The Engine's metrics must be registered after it opens.
 	m.reg.MustRegister(m.engine.PrometheusCollectors()...)
 
+	// The points written to the buckets of subscriptions are copied to their
+	// queues once the engine stores them.
+	subscriptionSvc := subscription.NewService(m.kvStore, ts.BucketService)
+	var subscriptionManager *subscription.Manager
+	{
+		log := m.log.With(zap.String("service", "subscriptions"))
+		subscriptionManager = subscription.NewManager(log, subscriptionSvc, m.subscriptionsPath, int64(m.subscriptionsMaxQueueSize))
+		subscriptionManager.MirrorToken = m.subscriptionsMirrorToken
+		subscriptionManager.MirrorWindow = m.subscriptionsMirrorWindow
+		subscriptionManager.MirrorMaxPoints = m.subscriptionsMirrorMaxPoints
+		m.reg.MustRegister(subscriptionManager.PrometheusCollectors()...)
+		m.wg.Add(1)
+		go func(log *zap.Logger) {
+			defer m.wg.Done()
+			subscriptionManager.Run(ctx)
+			log.Info("Stopping")
+		}(log)
+	}
+
+	// The buckets and organizations of the points written are cached for the
+	// points writers of the write path.
+	writeCache := storage.NewWriteCache(ts.BucketService, ts.OrganizationService, storage.DefaultWriteCacheTTL)
+
+	// The writes to buckets with a write sharding are partitioned and written
+	// to the engine in parallel.
+	shardedWriter := storage.NewShardedPointsWriter(m.engine, writeCache)
+
+	// The statuses of checks with a status heartbeat are deduplicated before
+	// they are stored or forwarded to subscriptions.
+	statusWriter := status.NewPointsWriter(subscription.NewPointsWriter(shardedWriter, subscriptionManager))
+	m.reg.MustRegister(statusWriter.PrometheusCollectors()...)
+
+	// Notification rules log the notifications they send through the points
+	// writer, which records the metrics of their delivery.
+	notificationsWriter := delivery.NewPointsWriter(statusWriter, notificationEndpointStore)
+	m.reg.MustRegister(notificationsWriter.PrometheusCollectors()...)
+
+	// Edge nodes forward the points written to the central instance.
+	var forwardingWriter storage.PointsWriter = notificationsWriter
+	if m.profile == edge.Profile {
+		forwarder, err := m.runEdgeForwarder(ctx, notificationsWriter)
+		if err != nil {
+			return err
+		}
+		forwardingWriter = forwarder
+	}
+
+	dedupWriter := dedup.NewPointsWriter(forwardingWriter, writeCache, dedup.DefaultMaxPoints)
+	m.reg.MustRegister(dedupWriter.PrometheusCollectors()...)
+
 	var (
-		deleteService platform.DeleteService = m.engine
-		pointsWriter  storage.PointsWriter   = m.engine
+		deleteService platform.DeleteService = orgfreeze.NewDeleteService(bucketstate.NewDeleteService(legalhold.NewDeleteService(m.engine, legalHoldSvc), ts.BucketService), ts.OrganizationService)
+		pointsWriter  storage.PointsWriter   = orgfreeze.NewPointsWriter(bucketstate.NewPointsWriter(writewindow.NewPointsWriter(dedupWriter, writeCache), writeCache), writeCache)
 		backupService platform.BackupService = m.engine
 	)
 
 	deps, err := influxdb.NewDependencies(
-		storageflux.NewReader(readservice.NewStore(m.engine)),
-		m.engine,
-		authorizer.NewBucketService(ts.BucketService),
+		storageflux.NewReader(readservice.NewRowFilterStore(readservice.NewStore(m.engine))),
+		pointsWriter,
+		authorizer.NewBucketService(ts.BucketService, systemBuckets),
 		authorizer.NewOrgService(ts.OrganizationService),
 		authorizer.NewSecretService(secretSvc),
 		nil,
@@ -746,13 +1655,33 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		m.log.Error("Failed to get query controller dependencies", zap.Error(err))
 		return err
 	}
+	if bucketLookup, ok := deps.StorageDeps.BucketDeps.(*query.BucketLookup); ok {
+		// queries with asOf set resolve bucket names as they were then
+		bucketLookup.BucketHistoryService = tenant.NewBucketSvc(tenantStore, ts)
+		bucketLookup.SystemBuckets = systemBuckets
+	}
+	bucketRollupSvc := rollup.NewService(m.kvStore, ts.BucketService)
+	deps.StorageDeps.FromDeps.RollupLookup = query.FromBucketRollupService(rollup.NewAuthedService(bucketRollupSvc))
+	deps.StorageDeps.FromDeps.ReadThrottle = bucketstate.NewReadThrottle(ts.BucketService, m.archivedReadConcurrency)
 
+	queueShares := make(map[string]int, len(m.queueShares))
+	for tenant, v := range m.queueShares {
+		share, err := strconv.Atoi(v)
+		if err != nil {
+			m.log.Error("Invalid query queue share", zap.String("tenant", tenant), zap.Error(err))
+			return err
+		}
+		queueShares[tenant] = share
+	}
 	m.queryController, err = control.New(control.Config{
 		ConcurrencyQuota:                m.concurrencyQuota,
 		InitialMemoryBytesQuotaPerQuery: int64(m.initialMemoryBytesQuotaPerQuery),
 		MemoryBytesQuotaPerQuery:        int64(m.memoryBytesQuotaPerQuery),
 		MaxMemoryBytes:                  int64(m.maxMemoryBytes),
 		QueueSize:                       m.queueSize,
+		MaxQueueSizePerTenant:           m.queueSizePerTenant,
+		QueueShares:                     queueShares,
+		QueueByAuthorization:            m.queueByToken,
 		Logger:                          m.log.With(zap.String("service", "storage-reads")),
 		ExecutorDependencies:            []flux.Dependency{deps},
 	})
@@ -763,19 +1692,50 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 
 	m.reg.MustRegister(m.queryController.PrometheusCollectors()...)
 
-	var storageQueryService = readservice.NewProxyQueryService(m.queryController)
-	var taskSvc platform.TaskService
+	fluxPackageSvc := fluxpkg.NewService(m.kvStore)
+	// Imports of user-defined flux packages are resolved before queries reach the controller.
+	m.queryService = fluxpkg.NewAsyncQueryService(m.queryController, fluxpkg.NewAuthedService(fluxPackageSvc))
+
+	var storageQueryService = readservice.NewProxyQueryService(m.queryService)
+	var (
+		taskSvc     platform.TaskService
+		taskSLASvc  platform.TaskSLAService
+		taskAuthSvc *taskauth.TaskService
+	)
 	{
 		// create the task stack
-		combinedTaskService := taskbackend.NewAnalyticalStorage(m.log.With(zap.String("service", "task-analytical-store")), m.kvService, m.kvService, m.kvService, pointsWriter, query.QueryServiceBridge{AsyncQueryService: m.queryController})
+		analyticalLogger := m.log.With(zap.String("service", "task-analytical-store"))
+		runLogLogger := m.log.With(zap.String("service", "task-run-logs"))
+		runLogExporter := runlog.NewExporter(runLogLogger, taskbackend.NewStoragePointsWriterRecorder(analyticalLogger, pointsWriter), m.kvService, pointsWriter)
+		combinedTaskService := taskbackend.NewAnalyticalRunStorage(analyticalLogger, m.kvService, m.kvService, m.kvService, runLogExporter, query.QueryServiceBridge{AsyncQueryService: m.queryService})
+
+		// the logs of the runs in progress are removed past the retention
+		// of their organization.
+		runLogPruner := runlog.NewPruner(runLogLogger, m.kvService, runLogExporter, runlog.DefaultPruneInterval)
+		m.wg.Add(1)
+		go func(log *zap.Logger) {
+			defer m.wg.Done()
+			runLogPruner.Run(ctx)
+			log.Info("Stopping")
+		}(runLogLogger)
+
+		m.taskDispatcher = remote.NewDispatcher(m.log.With(zap.String("service", "task-dispatcher")), m.kvService)
+		var runDispatcher executor.RunDispatcher
+		if m.taskRemoteWorkers {
+			runDispatcher = m.taskDispatcher
+		}
 
+		slaTracker := scheduler.NewSLATracker()
 		executor, executorMetrics := executor.NewExecutor(
 			m.log.With(zap.String("service", "task-executor")),
-			query.QueryServiceBridge{AsyncQueryService: m.queryController},
+			query.QueryServiceBridge{AsyncQueryService: m.queryService},
 			ts.UserService,
 			combinedTaskService,
 			combinedTaskService,
 			executor.WithFlagger(m.flagger),
+			executor.WithRunDispatcher(runDispatcher),
+			executor.WithSLATracker(slaTracker),
+			executor.WithPolicyEngine(m.policyEngine),
 		)
 		m.executor = executor
 		m.reg.MustRegister(executorMetrics.PrometheusCollectors()...)
@@ -797,11 +1757,13 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 						zap.Time("scheduledAt", scheduledAt),
 						zap.Error(err))
 				}),
+				scheduler.WithSLATracker(slaTracker),
 			)
 			if err != nil {
 				m.log.Fatal("could not start task scheduler", zap.Error(err))
 			}
 			m.reg.MustRegister(sm.PrometheusCollectors()...)
+			taskSLASvc = taskbackend.NewSLAService(slaTracker)
 		}
 
 		m.scheduler = sch
@@ -826,9 +1788,13 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			coordLogger); err != nil {
 			m.log.Error("Failed to resume existing tasks", zap.Error(err))
 		}
+
+		// Tasks run with service tokens scoped to the buckets they access.
+		taskAuthSvc = taskauth.NewTaskService(m.log.With(zap.String("service", "task-auth")), taskSvc, authSvc, ts.BucketService, systemBuckets, fluxlang.DefaultService, fluxPackageSvc)
+		taskSvc = taskAuthSvc
 	}
 
-	dbrpSvc := dbrp.NewService(ctx, authorizer.NewBucketService(ts.BucketService), m.kvStore)
+	dbrpSvc := dbrp.NewService(ctx, authorizer.NewBucketService(ts.BucketService, systemBuckets), m.kvStore)
 	dbrpSvc = dbrp.NewAuthorizedService(dbrpSvc)
 
 	var checkSvc platform.CheckService
@@ -844,6 +1810,99 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		notificationRuleSvc = middleware.NewNotificationRuleStore(m.kvService, m.kvService, coordinator)
 	}
 
+	// Scripts that would fail on their first run are rejected when saved.
+	{
+		linter := fluxlint.NewLinter(fluxlang.DefaultService, fluxPackageSvc, ts.BucketService, systemBuckets, secretSvc, connectionSvc)
+		taskSvc = fluxlint.NewTaskService(linter, taskSvc)
+		checkSvc = fluxlint.NewCheckService(linter, checkSvc)
+		notificationRuleSvc = fluxlint.NewNotificationRuleStore(linter, notificationRuleSvc, notificationEndpointStore)
+	}
+
+	// Scripts are formatted before they are linted and saved.
+	if m.fluxFormatOnSave {
+		taskSvc = fluxfmt.NewTaskService(fluxlang.DefaultService, taskSvc)
+		checkSvc = fluxfmt.NewCheckService(fluxlang.DefaultService, checkSvc)
+	}
+
+	// Deleted resources are kept in the trash so they can be restored.
+	{
+		log := m.log.With(zap.String("service", "trash"))
+		dashboardSvc = trash.NewDashboardService(log, dashboardSvc, m.kvService)
+		taskSvc = trash.NewTaskService(log, taskSvc, m.kvService)
+		checkSvc = trash.NewCheckService(log, checkSvc, m.kvService)
+		notificationRuleSvc = trash.NewNotificationRuleStore(log, notificationRuleSvc, m.kvService)
+		variableSvc = trash.NewVariableService(log, variableSvc, m.kvService)
+
+		purger := trash.NewPurger(log, m.kvService, trash.DefaultPurgeInterval)
+		m.wg.Add(1)
+		go func(log *zap.Logger) {
+			defer m.wg.Done()
+			purger.Run(ctx)
+			log.Info("Stopping")
+		}(log)
+	}
+
+	// Acknowledgements and resolutions of PagerDuty incidents are recorded in
+	// the notification history.
+	{
+		log := m.log.With(zap.String("service", "pagerduty-sync"))
+		syncer := pagerduty.NewSyncer(log, notificationEndpointStore, secretSvc, ts.BucketService, systemBuckets, pointsWriter, pagerduty.DefaultSyncInterval)
+		m.wg.Add(1)
+		go func(log *zap.Logger) {
+			defer m.wg.Done()
+			syncer.Run(ctx)
+			log.Info("Stopping")
+		}(log)
+	}
+
+	if len(m.kafkaIngest.Brokers) > 0 {
+		if err := m.runKafkaIngest(ctx, ts.BucketService, pointsWriter); err != nil {
+			return err
+		}
+	}
+
+	if m.mqttIngest.Broker != "" {
+		if err := m.runMQTTIngest(ctx, ts.BucketService, pointsWriter); err != nil {
+			return err
+		}
+	}
+
+	// The monitor subsystem applies the retention and downsampling of
+	// monitoring data to every organization.
+	{
+		if m.monitoringRetention != "" {
+			d, err := time.ParseDuration(m.monitoringRetention)
+			if err != nil {
+				return fmt.Errorf("invalid monitoring retention: %v", err)
+			}
+			m.monitoring.Retention = &d
+		}
+		if err := m.monitoring.Valid(); err != nil {
+			return err
+		}
+		log := m.log.With(zap.String("service", "monitor"))
+		manager := monitor.NewManager(log, m.monitoring, systemBuckets, ts.OrganizationService, ts.BucketService, taskSvc, ts.UserResourceMappingService)
+		m.wg.Add(1)
+		go func(log *zap.Logger) {
+			defer m.wg.Done()
+			manager.Run(ctx)
+			log.Info("Stopping")
+		}(log)
+	}
+
+	// Mappings left behind by deleted users, labels and resources are
+	// reported, and deleted if repairs are enabled.
+	if m.metadataVerifyInterval > 0 {
+		log := m.log.With(zap.String("service", "metadata-verifier"))
+		verifier := kv.NewMetadataVerifier(log, m.kvService, m.metadataVerifyInterval, m.metadataRepair)
+		m.wg.Add(1)
+		go func(log *zap.Logger) {
+			defer m.wg.Done()
+			verifier.Run(ctx)
+			log.Info("Stopping")
+		}(log)
+	}
+
 	// NATS streaming server
 	natsOpts := nats.NewDefaultServerOptions()
 
@@ -902,7 +1961,19 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	}
 
 	subscriber.Subscribe(gather.MetricsSubject, "metrics", gather.NewRecorderHandler(m.log, gather.PointWriter{Writer: pointsWriter}))
-	scraperScheduler, err := gather.NewScheduler(m.log, 10, scraperTargetSvc, publisher, subscriber, 10*time.Second, 30*time.Second)
+	var scraperOpts []gather.SchedulerOption
+	if m.scraperFileSDDir != "" {
+		scraperOpts = append(scraperOpts, gather.WithDiscoverer(platform.ScraperDiscoveryFile, gather.NewFileDiscoverer(m.scraperFileSDDir)))
+	}
+	if m.scraperKubernetesSD {
+		k8s, err := gather.NewInClusterKubernetesDiscoverer()
+		if err != nil {
+			m.log.Error("Failed to configure kubernetes scraper discovery", zap.Error(err))
+			return err
+		}
+		scraperOpts = append(scraperOpts, gather.WithDiscoverer(platform.ScraperDiscoveryKubernetes, k8s))
+	}
+	scraperScheduler, err := gather.NewScheduler(m.log, 10, scraperTargetSvc, publisher, subscriber, 10*time.Second, 30*time.Second, scraperOpts...)
 	if err != nil {
 		m.log.Error("Failed to create scraper subscriber", zap.Error(err))
 		return err
@@ -922,6 +1993,21 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		Addr: m.httpBindAddress,
 	}
 
+	if len(m.tlsACMEDomains) > 0 && m.httpTLSCert != "" {
+		err := errors.New("tls-acme-domains cannot be combined with tls-cert and tls-key")
+		m.log.Error("Invalid http listener", zap.Error(err))
+		return err
+	}
+	m.httpListeners = []*httpListener{m.mainHTTPListener()}
+	for _, spec := range m.httpListenerSpecs {
+		l, err := parseHTTPListener(spec)
+		if err != nil {
+			m.log.Error("Invalid http listener", zap.String("listener", spec), zap.Error(err))
+			return err
+		}
+		m.httpListeners = append(m.httpListeners, l)
+	}
+
 	if m.flagger == nil {
 		m.flagger = feature.DefaultFlagger()
 		if len(m.featureFlags) > 0 {
@@ -935,17 +2021,34 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			m.flagger = f
 		}
 	}
+	// The overrides set at runtime through the API apply over those of the
+	// flagger configured at startup.
+	featureFlagSvc := featureflag.NewService(m.log.With(zap.String("service", "feature_flags")), m.kvStore, m.flagger, feature.ByKey)
+	if err := featureFlagSvc.Load(ctx); err != nil {
+		m.log.Error("Failed to load feature flag overrides", zap.Error(err))
+		return err
+	}
+	m.flagger = featureFlagSvc
 
-	var sessionSvc platform.SessionService
+	var (
+		sessionSvc platform.SessionService
+		certAuth   http.CertificateAuthenticator
+	)
 	{
-		sessionSvc = session.NewService(
+		svc := session.NewService(
 			session.NewStorage(inmem.NewSessionStore()),
 			ts.UserService,
 			ts.UserResourceMappingService,
 			authSvc,
 			session.WithSessionLength(time.Duration(m.sessionLength)*time.Minute),
 		)
-		sessionSvc = session.NewSessionMetrics(m.reg, sessionSvc)
+		for _, l := range m.httpListeners {
+			if l.tlsClientCA != "" {
+				certAuth = session.NewCertificateAuthenticator(svc, m.httpTLSClientCertUsers)
+				break
+			}
+		}
+		sessionSvc = session.NewSessionMetrics(m.reg, svc)
 		sessionSvc = session.NewSessionLogger(m.log.With(zap.String("service", "session")), sessionSvc)
 	}
 
@@ -962,18 +2065,67 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 
 	ts.BucketService = storage.NewBucketService(ts.BucketService, m.engine)
 	ts.BucketService = dbrp.NewBucketService(m.log, ts.BucketService, dbrpSvc)
+	ts.BucketService = legalhold.NewBucketService(ts.BucketService, legalHoldSvc)
+	ts.BucketService = bucketstate.NewBucketService(ts.BucketService)
+
+	writeRoutingSvc := writerouting.NewService(m.kvStore, ts.BucketService)
+
+	querySigningKey := []byte(m.querySigningKey)
+	if len(querySigningKey) == 0 {
+		querySigningKey = make([]byte, 32)
+		if _, err := crand.Read(querySigningKey); err != nil {
+			m.log.Error("Failed to generate query signing key", zap.Error(err))
+			return err
+		}
+	}
+
+	bodyBytesOverrides := make(map[string]int64, len(m.httpMaxBodyBytesOverrides))
+	for prefix, v := range m.httpMaxBodyBytesOverrides {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			m.log.Error("Invalid maximum request body size", zap.String("prefix", prefix), zap.Error(err))
+			return err
+		}
+		bodyBytesOverrides[prefix] = n
+	}
+
+	corsPolicies, err := parseCORSPolicies(m.httpCORSPolicy, m.httpCORSPolicyOverrides)
+	if err != nil {
+		m.log.Error("Invalid CORS policy", zap.Error(err))
+		return err
+	}
+
+	endpointSvc := endpoints.NewService(notificationEndpointStore, secretSvc, ts.UserResourceMappingService, ts.OrganizationService)
 
 	m.apibackend = &http.APIBackend{
 		AssetsPath:           m.assetsPath,
 		HTTPErrorHandler:     kithttp.ErrorHandler(0),
 		Logger:               m.log,
 		SessionRenewDisabled: m.sessionRenewDisabled,
+		WriteTimeout:         m.storageWriteTimeout,
+		MirrorWriteToken:     m.subscriptionsMirrorWriteToken,
 		NewBucketService:     source.NewBucketService,
 		NewQueryService:      source.NewQueryService,
+
+		FeatureFlagRequestOverrides: m.featureFlagRequestOverrides,
+
+		MaxRequestBodyBytes:          int64(m.httpMaxRequestBodyBytes),
+		MaxWriteBodyBytes:            int64(m.httpMaxWriteBodyBytes),
+		MaxRequestBodyBytesOverrides: bodyBytesOverrides,
+		CORSPolicies:                 corsPolicies,
+
+		CertificateAuthenticator: certAuth,
+		FrozenOrgFinder:          ts.OrganizationService,
+		PolicyEngine:             m.policyEngine,
+		QuerySigner:              http.NewQuerySigner(querySigningKey, authSvc, ts.UserService, ts.UserResourceMappingService),
+
+		WriteBackpressure: m.engine,
+		QueryBackpressure: m.queryController,
+
 		PointsWriter: &storage.LoggingPointsWriter{
 			Underlying:    pointsWriter,
 			BucketFinder:  ts.BucketService,
-			LogBucketName: platform.MonitoringSystemBucketName,
+			LogBucketName: systemBuckets.MonitoringName,
 		},
 		DeleteService:        deleteService,
 		BackupService:        backupService,
@@ -982,6 +2134,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		AlgoWProxy:           &http.NoopProxyHandler{},
 		// Wrap the BucketService in a storage backed one that will ensure deleted buckets are removed from the storage engine.
 		BucketService:                   ts.BucketService,
+		SystemBuckets:                   systemBuckets,
 		SessionService:                  sessionSvc,
 		UserService:                     ts.UserService,
 		DBRPService:                     dbrpSvc,
@@ -1000,10 +2153,12 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		FluxService:                     storageQueryService,
 		FluxLanguageService:             fluxlang.DefaultService,
 		TaskService:                     taskSvc,
+		TaskSLAService:                  taskSLASvc,
 		TelegrafService:                 telegrafSvc,
 		NotificationRuleStore:           notificationRuleSvc,
-		NotificationEndpointService:     endpoints.NewService(notificationEndpointStore, secretSvc, ts.UserResourceMappingService, ts.OrganizationService),
+		NotificationEndpointService:     endpointSvc,
 		CheckService:                    checkSvc,
+		WriteRoutingService:             writeRoutingSvc,
 		ScraperTargetStoreService:       scraperTargetSvc,
 		ChronografService:               chronografSvc,
 		SecretService:                   secretSvc,
@@ -1029,7 +2184,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		pkgSVC = pkger.NewService(
 			pkger.WithLogger(pkgerLogger),
 			pkger.WithStore(pkger.NewStoreKV(m.kvStore)),
-			pkger.WithBucketSVC(authorizer.NewBucketService(b.BucketService)),
+			pkger.WithBucketSVC(authorizer.NewBucketService(b.BucketService, b.SystemBuckets)),
 			pkger.WithCheckSVC(authorizer.NewCheckService(b.CheckService, authedUrmSVC, authedOrgSVC)),
 			pkger.WithDashboardSVC(authorizer.NewDashboardService(b.DashboardService)),
 			pkger.WithLabelSVC(authorizer.NewLabelServiceWithOrg(b.LabelService, b.OrgLookupService)),
@@ -1059,11 +2214,19 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		templatesHTTPServer = pkger.NewHTTPServerTemplates(tLogger, pkgSVC)
 	}
 
-	userHTTPServer := ts.NewUserHTTPHandler(m.log)
+	userSettingsSvc := usersettings.NewAuthedService(usersettings.NewService(m.kvStore, ts.BucketService))
+	userHTTPServer := ts.NewUserHTTPHandler(m.log, tenant.WithUserSettingsService(userSettingsSvc))
+	userSettingsHTTPServer := usersettings.NewHTTPHandler(m.log.With(zap.String("handler", "user_settings")), userSettingsSvc)
 
 	var onboardHTTPServer *tenant.OnboardHandler
 	{
-		onboardSvc := tenant.NewOnboardService(ts, authSvc)                                               // basic service
+		orgTemplates, err := pkger.NewOrgTemplates(pkgSVC, m.onboardingTemplates)
+		if err != nil {
+			m.log.Error("Failed to load onboarding templates", zap.Error(err))
+			return err
+		}
+
+		onboardSvc := tenant.NewOnboardService(ts, authSvc, tenant.WithOrgTemplater(orgTemplates))        // basic service
 		onboardSvc = tenant.NewAuthedOnboardSvc(onboardSvc)                                               // with auth
 		onboardSvc = tenant.NewOnboardingMetrics(m.reg, onboardSvc, metric.WithSuffix("new"))             // with metrics
 		onboardSvc = tenant.NewOnboardingLogger(m.log.With(zap.String("handler", "onboard")), onboardSvc) // with logging
@@ -1086,6 +2249,9 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	}
 
 	// feature flagging for new authorization service
+	authPresetSvc := authpreset.NewAuthedService(authpreset.NewService(m.kvStore))
+	authPresetHTTPServer := authpreset.NewHTTPHandler(m.log.With(zap.String("handler", "authpreset")), authPresetSvc)
+
 	var authHTTPServer *kithttp.FeatureHandler
 	{
 		authLogger := m.log.With(zap.String("handler", "authorization"))
@@ -1104,7 +2270,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		authService = authorization.NewAuthMetrics(m.reg, authService)
 		authService = authorization.NewAuthLogger(authLogger, authService)
 
-		newHandler := authorization.NewHTTPAuthHandler(m.log, authService, ts)
+		newHandler := authorization.NewHTTPAuthHandler(m.log, authService, ts, authorization.WithAuthorizationPresetService(authPresetSvc))
 		authHTTPServer = kithttp.NewFeatureHandler(feature.NewAuthPackage(), m.flagger, oldHandler, newHandler, newHandler.Prefix())
 	}
 
@@ -1113,9 +2279,147 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		sessionHTTPServer = session.NewSessionHandler(m.log.With(zap.String("handler", "session")), sessionSvc, ts.UserService, ts.PasswordsService)
 	}
 
-	orgHTTPServer := ts.NewOrgHTTPHandler(m.log, secret.NewAuthedService(secretSvc))
+	secretHandlerOpts := []secret.HandlerOption{secret.WithOrphanedSecretFinder(endpointSvc)}
+	if secretKeySvc != nil {
+		secretHandlerOpts = append(secretHandlerOpts, secret.WithEncryptionKeyService(secret.NewAuthedEncryptionKeyService(secretKeySvc)))
+	}
+	orgHTTPServer := ts.NewOrgHTTPHandler(m.log, secret.NewAuthedService(secretSvc), secretHandlerOpts...)
+
+	schemaHTTPServer := schema.NewHTTPEmbeddedHandler(m.log.With(zap.String("handler", "schema")), schema.NewAuthedService(schema.NewService(m.engine)))
+	measurementHTTPServer := schema.NewHTTPMeasurementHandler(m.log.With(zap.String("handler", "measurement")), schema.NewAuthedDeleteService(orgfreeze.NewMeasurementDeleteService(bucketstate.NewMeasurementDeleteService(legalhold.NewMeasurementDeleteService(m.engine, legalHoldSvc), ts.BucketService), ts.OrganizationService)))
+	var bucketArchiveSvc platform.BucketArchiveService = bucketarchive.NewService(m.engine)
+	if secretKeySvc != nil {
+		// The archives of organizations whose key encrypts backups are
+		// encrypted.
+		bucketArchiveSvc = secret.NewBucketArchiveService(bucketArchiveSvc, secretKeySvc, keyManager)
+	}
+	bucketArchiveHTTPServer := bucketarchive.NewHTTPEmbeddedHandler(m.log.With(zap.String("handler", "bucket_archive")), bucketarchive.NewAuthedService(bucketArchiveSvc))
+
+	// Backups, bucket exports and deletes also run as background jobs, whose
+	// results outlive the requests creating them.
+	var jobHTTPServer *job.Handler
+	{
+		log := m.log.With(zap.String("service", "jobs"))
+		jobSvc := job.NewService(log, m.kvStore, m.jobsPath, map[job.Kind]job.Runner{
+			job.KindBackup:       job.NewBackupRunner(backupService, m.kvService),
+			job.KindBucketExport: job.NewBucketExportRunner(bucketArchiveSvc, ts.BucketService),
+			job.KindDelete:       job.NewDeleteRunner(deleteService, ts.BucketService),
+		})
+		jobSvc.PolicyEngine = m.policyEngine
+		m.wg.Add(1)
+		go func(log *zap.Logger) {
+			defer m.wg.Done()
+			jobSvc.Run(ctx)
+			log.Info("Stopping")
+		}(log)
+		jobHTTPServer = job.NewHTTPHandler(m.log.With(zap.String("handler", "jobs")), jobSvc)
+	}
+
+	bucketHTTPServer := ts.NewBucketHTTPHandler(m.log, labelSvc,
+		tenant.WithEmbeddedBucketHandler("/schema", schemaHTTPServer),
+		tenant.WithEmbeddedBucketHandler("/measurements", measurementHTTPServer),
+		tenant.WithEmbeddedBucketHandler("/archive", bucketArchiveHTTPServer),
+	)
+
+	legalHoldHTTPServer := legalhold.NewHTTPHandler(m.log.With(zap.String("handler", "legalhold")), legalhold.NewAuthedService(legalHoldSvc, ts.BucketService))
+
+	fluxPackageHTTPServer := fluxpkg.NewHTTPHandler(m.log.With(zap.String("handler", "fluxpkg")), fluxpkg.NewAuthedService(fluxPackageSvc))
+
+	var savedQueryHTTPServer *savedquery.Handler
+	{
+		savedQueryLogger := m.log.With(zap.String("handler", "savedquery"))
+		savedQuerySvc := savedquery.NewAuthedService(savedquery.NewService(m.kvStore, ts.UserResourceMappingService))
+		savedQueryURMSvc := http.NewRestrictedURMService(tenant.NewAuthedURMService(ts.OrganizationService, ts.UserResourceMappingService), func(ctx context.Context, id platform.ID) (bool, error) {
+			q, err := savedQuerySvc.FindSavedQueryByID(ctx, id)
+			if err != nil {
+				return false, err
+			}
+			return q.Restricted(), nil
+		})
+		urmHandler := tenant.NewURMHandler(savedQueryLogger.With(zap.String("handler", "urm")), platform.SavedQueriesResourceType, "id", ts.UserService, savedQueryURMSvc)
+		savedQueryLabelHandler := label.NewHTTPEmbeddedHandler(savedQueryLogger.With(zap.String("handler", "label")), platform.SavedQueriesResourceType, labelSvc)
+		savedQueryHTTPServer = savedquery.NewHTTPHandler(savedQueryLogger, savedQuerySvc, labelSvc, tenant.NewAuthedOrgService(ts.OrganizationService), urmHandler, savedQueryLabelHandler)
+	}
+
+	connectionHTTPServer := connection.NewHTTPHandler(m.log.With(zap.String("handler", "connection")), connection.NewAuthedService(connectionSvc), tenant.NewAuthedOrgService(ts.OrganizationService))
+
+	subscriptionHTTPServer := subscription.NewHTTPHandler(m.log.With(zap.String("handler", "subscription")), subscription.NewAuthedService(subscriptionSvc), tenant.NewAuthedOrgService(ts.OrganizationService))
+
+	trashHTTPServer := trash.NewHTTPHandler(m.log.With(zap.String("handler", "trash")), trash.NewAuthedService(taskauth.NewTrashService(m.kvService, taskAuthSvc)))
+
+	transferHTTPServer := transfer.NewHTTPHandler(m.log.With(zap.String("handler", "transfer")), authorizer.NewResourceTransferService(m.apibackend.OrgLookupService, taskauth.NewResourceTransferService(m.kvService, taskAuthSvc)))
+
+	cqLogger := m.log.With(zap.String("handler", "cq"))
+	taskWorkersHTTPServer := remote.NewHTTPHandler(m.log.With(zap.String("handler", "task_workers")), m.taskDispatcher)
+
+	indexStatusHTTPServer := indexstatus.NewHTTPHandler(m.log.With(zap.String("handler", "index_status")), m.engine)
+
+	cqHTTPServer := cq.NewHTTPHandler(cqLogger, cq.NewService(authorizer.NewTaskService(cqLogger, m.apibackend.TaskService), dbrpSvc))
+
+	forecastLogger := m.log.With(zap.String("handler", "forecast"))
+	forecastHTTPServer := forecast.NewHTTPHandler(forecastLogger, forecast.NewService(authorizer.NewTaskService(forecastLogger, m.apibackend.TaskService), fluxlang.DefaultService))
+
+	var grafanaHTTPServer *grafana.Handler
+	{
+		authedOrgSvc := authorizer.NewOrgService(m.apibackend.OrganizationService)
+		authedURMSvc := authorizer.NewURMService(m.apibackend.OrgLookupService, m.apibackend.UserResourceMappingService)
+		grafanaHTTPServer = grafana.NewHTTPHandler(m.log.With(zap.String("handler", "grafana")), grafana.NewService(
+			authorizer.NewCheckService(m.apibackend.CheckService, authedURMSvc, authedOrgSvc),
+			authorizer.NewNotificationRuleStore(m.apibackend.NotificationRuleStore, authedURMSvc, authedOrgSvc),
+			authorizer.NewNotificationEndpointService(m.apibackend.NotificationEndpointService, authedURMSvc, authedOrgSvc),
+			fluxlang.DefaultService,
+		))
+	}
+
+	var alertConfigHTTPServer *alertconfig.Handler
+	{
+		alertConfigLogger := m.log.With(zap.String("handler", "alertconfig"))
+		authedOrgSvc := authorizer.NewOrgService(m.apibackend.OrganizationService)
+		authedURMSvc := authorizer.NewURMService(m.apibackend.OrgLookupService, m.apibackend.UserResourceMappingService)
+		alertConfigHTTPServer = alertconfig.NewHTTPHandler(alertConfigLogger, alertconfig.NewService(
+			authorizer.NewCheckService(m.apibackend.CheckService, authedURMSvc, authedOrgSvc),
+			authorizer.NewNotificationRuleStore(m.apibackend.NotificationRuleStore, authedURMSvc, authedOrgSvc),
+			authorizer.NewNotificationEndpointService(m.apibackend.NotificationEndpointService, authedURMSvc, authedOrgSvc),
+			authorizer.NewTaskService(alertConfigLogger, m.apibackend.TaskService),
+		))
+
+		if m.alertsConfig != "" {
+			cfg, err := alertconfig.LoadConfig(m.alertsConfig)
+			if err != nil {
+				m.log.Error("Failed loading alert configuration", zap.Error(err))
+				return err
+			}
+			svc := alertconfig.NewService(m.apibackend.CheckService, m.apibackend.NotificationRuleStore, m.apibackend.NotificationEndpointService, m.apibackend.TaskService)
+			results, err := svc.ReconcileConfig(ctx, cfg, m.apibackend.OrganizationService, m.apibackend.UserResourceMappingService)
+			for org, res := range results {
+				for _, r := range res {
+					log := alertConfigLogger.With(zap.String("org", org), zap.String("kind", r.Kind), zap.String("name", r.Name))
+					if r.Error != "" {
+						log.Error("Failed reconciling alert resource", zap.String("error", r.Error))
+						continue
+					}
+					log.Debug("Alert resource reconciled", zap.Bool("updated", r.Updated), zap.Bool("deleted", r.Deleted))
+				}
+			}
+			if err != nil {
+				m.log.Error("Failed reconciling alert configuration", zap.Error(err))
+				return err
+			}
+			alertConfigLogger.Info("Alert configuration reconciled", zap.String("path", m.alertsConfig))
+		}
+	}
+
+	bucketRollupHTTPServer := rollup.NewHTTPHandler(m.log.With(zap.String("handler", "rollup")), rollup.NewAuthedService(bucketRollupSvc))
 
-	bucketHTTPServer := ts.NewBucketHTTPHandler(m.log, labelSvc)
+	writeRoutingHTTPServer := writerouting.NewHTTPHandler(m.log.With(zap.String("handler", "writerouting")), writerouting.NewAuthedService(writeRoutingSvc))
+
+	notificationRoutingHTTPServer := routing.NewHTTPHandler(m.log.With(zap.String("handler", "notification_routing")), authorizer.NewNotificationRoutingService(m.kvService))
+
+	notificationMessagesHTTPServer := message.NewHTTPHandler(m.log.With(zap.String("handler", "notification_messages")), authorizer.NewNotificationMessageCatalogService(m.kvService))
+
+	taskRunLogSettingsHTTPServer := runlog.NewHTTPHandler(m.log.With(zap.String("handler", "task_run_log_settings")), authorizer.NewTaskRunLogSettingsService(m.kvService))
+
+	meResourcesHTTPServer := tenant.NewHTTPMeResourcesHandler(m.log.With(zap.String("handler", "me_resources")), m.kvService)
 
 	{
 		platformHandler := http.NewPlatformHandler(m.apibackend,
@@ -1123,6 +2427,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			http.WithResourceHandler(templatesHTTPServer),
 			http.WithResourceHandler(onboardHTTPServer),
 			http.WithResourceHandler(authHTTPServer),
+			http.WithResourceHandler(authPresetHTTPServer),
 			http.WithResourceHandler(kithttp.NewFeatureHandler(feature.NewLabelPackage(), m.flagger, oldLabelHandler, labelHandler, labelHandler.Prefix())),
 			http.WithResourceHandler(sessionHTTPServer.SignInResourceHandler()),
 			http.WithResourceHandler(sessionHTTPServer.SignOutResourceHandler()),
@@ -1130,18 +2435,54 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			http.WithResourceHandler(userHTTPServer.UserResourceHandler()),
 			http.WithResourceHandler(orgHTTPServer),
 			http.WithResourceHandler(bucketHTTPServer),
+			http.WithResourceHandler(legalHoldHTTPServer),
+			http.WithResourceHandler(fluxPackageHTTPServer),
+			http.WithResourceHandler(savedQueryHTTPServer),
+			http.WithResourceHandler(connectionHTTPServer),
+			http.WithResourceHandler(subscriptionHTTPServer),
+			http.WithResourceHandler(transferHTTPServer),
+			http.WithResourceHandler(meResourcesHTTPServer),
+			http.WithResourceHandler(userSettingsHTTPServer),
+			http.WithResourceHandler(writeRoutingHTTPServer),
+			http.WithResourceHandler(notificationRoutingHTTPServer),
+			http.WithResourceHandler(notificationMessagesHTTPServer),
+			http.WithResourceHandler(taskRunLogSettingsHTTPServer),
+			http.WithResourceHandler(bucketRollupHTTPServer),
+			http.WithResourceHandler(cqHTTPServer),
+			http.WithResourceHandler(forecastHTTPServer),
+			http.WithResourceHandler(grafanaHTTPServer),
+			http.WithResourceHandler(alertConfigHTTPServer),
+			http.WithResourceHandler(trashHTTPServer),
+			http.WithResourceHandler(taskWorkersHTTPServer),
+			http.WithResourceHandler(indexStatusHTTPServer),
+			http.WithResourceHandler(jobHTTPServer),
+			http.WithResourceHandler(featureflag.NewHTTPHandler(m.log.With(zap.String("handler", "feature_flags")), featureFlagSvc)),
 		)
 
 		httpLogger := m.log.With(zap.String("service", "http"))
-		m.httpServer.Handler = http.NewHandlerFromRegistry(
-			"platform",
-			m.reg,
+		handlerOpts := []http.HandlerOptFn{
 			http.WithLog(httpLogger),
 			http.WithAPIHandler(platformHandler),
-		)
+		}
+		if m.sentryDSN != "" {
+			tags := map[string]string{"version": platform.GetBuildInfo().Version}
+			for k, v := range m.sentryTags {
+				tags[k] = v
+			}
+			reporter, err := kithttp.NewSentryReporter(httpLogger, m.sentryDSN, tags)
+			if err != nil {
+				return err
+			}
+			handlerOpts = append(handlerOpts, http.WithPanicReporter(reporter))
+		}
+		m.httpServer.Handler = http.NewHandlerFromRegistry("platform", m.reg, handlerOpts...)
 
-		if logconf.Level == zap.DebugLevel {
-			m.httpServer.Handler = http.LoggingMW(httpLogger)(m.httpServer.Handler)
+		accessLogMW, err := m.accessLogMW(httpLogger, logconf.Level == zap.DebugLevel)
+		if err != nil {
+			return err
+		}
+		if accessLogMW != nil {
+			m.httpServer.Handler = accessLogMW(m.httpServer.Handler)
 		}
 		// If we are in testing mode we allow all data to be flushed and removed.
 		if m.testing {
@@ -1149,87 +2490,443 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		}
 	}
 
-	ln, err := net.Listen("tcp", m.httpBindAddress)
-	if err != nil {
-		m.log.Error("failed http listener", zap.Error(err))
-		m.log.Info("Stopping")
-		return err
+	if m.grpcBindAddress != "" {
+		if err := m.runGRPC(); err != nil {
+			return err
+		}
 	}
 
-	var cer tls.Certificate
-	transport := "http"
+	if m.storageGRPCBindAddress != "" {
+		if err := m.runStorageGRPC(); err != nil {
+			return err
+		}
+	}
 
-	if m.httpTLSCert != "" && m.httpTLSKey != "" {
-		var err error
-		cer, err = tls.LoadX509KeyPair(m.httpTLSCert, m.httpTLSKey)
+	if m.unauthenticatedWriteBindAddress != "" {
+		if err := m.runUnauthenticatedWrite(); err != nil {
+			return err
+		}
+	}
 
+	proxyNetworks := make([]*net.IPNet, 0, len(m.httpProxyProtocolNetworks))
+	for _, s := range m.httpProxyProtocolNetworks {
+		_, n, err := net.ParseCIDR(s)
 		if err != nil {
-			m.log.Error("failed to load x509 key pair", zap.Error(err))
+			m.log.Error("Invalid PROXY protocol network", zap.String("network", s), zap.Error(err))
+			return err
+		}
+		proxyNetworks = append(proxyNetworks, n)
+	}
+
+	acm := m.acmeManager()
+
+	var (
+		listeners = make([]net.Listener, 0, len(m.httpListeners))
+		reloaders []*tlscert.Reloader
+	)
+	for _, l := range m.httpListeners {
+		ln, reloader, err := l.listen(m.log, proxyNetworks, acm)
+		if err != nil {
+			for _, ln := range listeners {
+				ln.Close()
+			}
+			m.log.Error("failed http listener", zap.String("addr", l.addr), zap.Error(err))
 			m.log.Info("Stopping")
 			return err
 		}
-		transport = "https"
+		listeners = append(listeners, ln)
+		if reloader != nil {
+			reloaders = append(reloaders, reloader)
+		}
+	}
+
+	// The certificates are reloaded without a restart, so that the
+	// connections of the clients, such as long write streams, are kept.
+	for _, r := range reloaders {
+		m.wg.Add(1)
+		go func(r *tlscert.Reloader) {
+			defer m.wg.Done()
+			r.Run(ctx, m.tlsReloadInterval)
+		}(r)
+	}
+
+	if acm != nil && m.tlsACMEHTTPBindAddress != "" {
+		if err := m.runACMEHTTP(acm); err != nil {
+			return err
+		}
+	}
+
+	if addr, ok := listeners[0].Addr().(*net.TCPAddr); ok {
+		m.httpPort = addr.Port
+	}
+
+	for i, ln := range listeners {
+		l, ln := m.httpListeners[i], ln
+		m.wg.Add(1)
+		go func(log *zap.Logger) {
+			defer m.wg.Done()
+			log.Info("Listening",
+				zap.String("transport", l.transport()),
+				zap.String("network", l.network),
+				zap.String("addr", ln.Addr().String()),
+				zap.Bool("proxy_protocol", l.proxyProtocol),
+			)
+
+			if err := m.httpServer.Serve(ln); err != nethttp.ErrServerClosed {
+				log.Error("Failed "+l.transport()+" service", zap.Error(err))
+			}
+			log.Info("Stopping")
+		}(m.log)
+	}
+
+	return nil
+}
+
+// mainHTTPListener returns the listener of http-bind-address, which serves
+// HTTPs when both tls-cert and tls-key, or tls-acme-domains are set.
+func (m *Launcher) mainHTTPListener() *httpListener {
+	l := &httpListener{
+		network:          "tcp",
+		addr:             m.httpBindAddress,
+		tlsMinVersion:    m.httpTLSMinVersion,
+		tlsStrictCiphers: m.httpTLSStrictCiphers,
+		tlsClientCA:      m.httpTLSClientCA,
+		proxyProtocol:    m.httpProxyProtocol,
+	}
+	if m.httpTLSCert != "" && m.httpTLSKey != "" {
+		l.tlsCert, l.tlsKey = m.httpTLSCert, m.httpTLSKey
+	} else if len(m.tlsACMEDomains) > 0 {
+		l.tlsACME = true
+	} else {
+		l.tlsClientCA = ""
+	}
+	return l
+}
+
+// systemBuckets returns the system buckets configured by the flags. The
+// default IDs of relocated system buckets redirect to their new IDs.
+func (m *Launcher) systemBuckets() (platform.SystemBuckets, error) {
+	sb := platform.SystemBuckets{
+		TasksName:      m.systemBucketTasksName,
+		MonitoringName: m.systemBucketMonitoringName,
+		Redirects:      make(map[platform.ID]platform.ID),
+	}
+	if err := sb.TasksID.DecodeFromString(m.systemBucketTasksID); err != nil {
+		return sb, fmt.Errorf("invalid tasks system bucket ID %q: %v", m.systemBucketTasksID, err)
+	}
+	if err := sb.MonitoringID.DecodeFromString(m.systemBucketMonitoringID); err != nil {
+		return sb, fmt.Errorf("invalid monitoring system bucket ID %q: %v", m.systemBucketMonitoringID, err)
+	}
+	if sb.TasksID != platform.TasksSystemBucketID {
+		sb.Redirects[platform.TasksSystemBucketID] = sb.TasksID
+	}
+	if sb.MonitoringID != platform.MonitoringSystemBucketID {
+		sb.Redirects[platform.MonitoringSystemBucketID] = sb.MonitoringID
+	}
+	for from, to := range m.systemBucketRedirects {
+		var fromID, toID platform.ID
+		if err := fromID.DecodeFromString(from); err != nil {
+			return sb, fmt.Errorf("invalid system bucket redirect %s=%s: %v", from, to, err)
+		}
+		if err := toID.DecodeFromString(to); err != nil {
+			return sb, fmt.Errorf("invalid system bucket redirect %s=%s: %v", from, to, err)
+		}
+		sb.Redirects[fromID] = toID
+	}
+	return sb, nil
+}
+
+// grpcServerOptions returns the options of the gRPC servers, which serve TLS
+// when both grpc-tls-cert and grpc-tls-key are set.
+func (m *Launcher) grpcServerOptions() ([]grpc.ServerOption, error) {
+	if m.grpcTLSCert == "" && m.grpcTLSKey == "" {
+		return nil, nil
+	}
+	if m.grpcTLSCert == "" || m.grpcTLSKey == "" {
+		return nil, errors.New("grpc-tls-cert and grpc-tls-key must be set together")
+	}
+	creds, err := credentials.NewServerTLSFromFile(m.grpcTLSCert, m.grpcTLSKey)
+	if err != nil {
+		return nil, err
+	}
+	return []grpc.ServerOption{grpc.Creds(creds)}, nil
+}
+
+// runGRPC starts the internal gRPC API on the configured bind address.
+func (m *Launcher) runGRPC() error {
+	opts, err := m.grpcServerOptions()
+	if err != nil {
+		m.log.Error("failed to configure grpc TLS", zap.Error(err))
+		return err
+	}
+
+	ln, err := net.Listen("tcp", m.grpcBindAddress)
+	if err != nil {
+		m.log.Error("failed grpc listener", zap.Error(err))
+		return err
+	}
+
+	b := m.apibackend
+	rpcServer := rpc.NewServer(
+		m.log.With(zap.String("service", "grpc")),
+		b.AuthorizationService,
+		b.BucketService,
+		b.SystemBuckets,
+		b.PointsWriter,
+		query.QueryServiceBridge{AsyncQueryService: m.queryService},
+	)
+	rpcServer.PolicyEngine = b.PolicyEngine
+	m.grpcServer = rpcServer.GRPCServer(opts...)
+
+	m.wg.Add(1)
+	go func(log *zap.Logger) {
+		defer m.wg.Done()
+		log.Info("Listening", zap.String("transport", "grpc"), zap.String("addr", m.grpcBindAddress))
+		if err := m.grpcServer.Serve(ln); err != nil && err != grpc.ErrServerStopped {
+			log.Error("Failed grpc service", zap.Error(err))
+		}
+		log.Info("Stopping")
+	}(m.log.With(zap.String("service", "grpc")))
+	return nil
+}
+
+// runStorageGRPC starts the gRPC storage read API on the configured bind
+// address.
+func (m *Launcher) runStorageGRPC() error {
+	opts, err := m.grpcServerOptions()
+	if err != nil {
+		m.log.Error("failed to configure storage grpc TLS", zap.Error(err))
+		return err
+	}
+
+	ln, err := net.Listen("tcp", m.storageGRPCBindAddress)
+	if err != nil {
+		m.log.Error("failed storage grpc listener", zap.Error(err))
+		return err
+	}
+
+	b := m.apibackend
+	log := m.log.With(zap.String("service", "storage-grpc"))
+	rpcServer := rpc.NewServer(log, b.AuthorizationService, b.BucketService, b.SystemBuckets, nil, nil)
+	rpcServer.PolicyEngine = b.PolicyEngine
+	m.storageGRPCServer = rpcServer.StorageGRPCServer(readservice.NewRowFilterStore(readservice.NewStore(m.engine)), opts...)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		log.Info("Listening", zap.String("transport", "grpc"), zap.String("addr", m.storageGRPCBindAddress))
+		if err := m.storageGRPCServer.Serve(ln); err != nil && err != grpc.ErrServerStopped {
+			log.Error("Failed storage grpc service", zap.Error(err))
+		}
+		log.Info("Stopping")
+	}()
+	return nil
+}
 
-		// Sensible default
-		var tlsMinVersion uint16 = tls.VersionTLS12
+// accessLogMW returns the middleware writing the access log of the HTTP API
+// to its configured destination, or to log in debug mode when none is
+// configured. It returns nil when the access log is disabled.
+func (m *Launcher) accessLogMW(log *zap.Logger, debug bool) (kithttp.Middleware, error) {
+	if m.httpAccessLog == "" && !debug {
+		return nil, nil
+	}
 
-		switch m.httpTLSMinVersion {
-		case "1.0":
-			m.log.Warn("Setting the minimum version of TLS to 1.0 - this is discouraged. Please use 1.2 or 1.3")
-			tlsMinVersion = tls.VersionTLS10
-		case "1.1":
-			m.log.Warn("Setting the minimum version of TLS to 1.1 - this is discouraged. Please use 1.2 or 1.3")
-			tlsMinVersion = tls.VersionTLS11
-		case "1.2":
-			tlsMinVersion = tls.VersionTLS12
-		case "1.3":
-			tlsMinVersion = tls.VersionTLS13
+	conf := http.AccessLogConfig{
+		SampleRates:  make(map[string]float64, len(m.httpAccessLogSampleRates)),
+		RedactParams: m.httpAccessLogRedact,
+		LogQueryText: m.httpAccessLogQueryText,
+	}
+	for prefix, v := range m.httpAccessLogSampleRates {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			m.log.Error("Invalid access log sample rate, must be between 0 and 1", zap.String("prefix", prefix), zap.String("rate", v))
+			return nil, fmt.Errorf("invalid access log sample rate %q for %q", v, prefix)
 		}
+		conf.SampleRates[prefix] = rate
+	}
 
-		strictCiphers := []uint16{
-			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
-			tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
-			tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
-			tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+	if m.httpAccessLog != "" {
+		w, err := http.NewAccessLogWriter(m.httpAccessLog)
+		if err != nil {
+			m.log.Error("Failed to open access log", zap.String("destination", m.httpAccessLog), zap.Error(err))
+			return nil, err
 		}
+		m.accessLogCloser = w
 
-		// nil uses the default cipher suite
-		var cipherConfig []uint16 = nil
+		logconf := &influxlogger.Config{
+			Format: m.httpAccessLogFormat,
+			Level:  zapcore.InfoLevel,
+		}
+		if log, err = logconf.New(w); err != nil {
+			return nil, err
+		}
+		log = log.With(zap.String("service", "http"))
+	}
+	return http.AccessLogMW(log, conf), nil
+}
+
+// runUnauthenticatedWrite starts the listener of the writes without a token
+// on the configured bind address.
+func (m *Launcher) runUnauthenticatedWrite() error {
+	log := m.log.With(zap.String("service", "unauthenticated-write"))
 
-		// TLS 1.3 does not support configuring the Cipher suites
-		if tlsMinVersion != tls.VersionTLS13 && m.httpTLSStrictCiphers {
-			cipherConfig = strictCiphers
+	buckets := make([]platform.ID, 0, len(m.unauthenticatedWriteBuckets))
+	for _, s := range m.unauthenticatedWriteBuckets {
+		id, err := platform.IDFromString(s)
+		if err != nil {
+			log.Error("Invalid unauthenticated write bucket", zap.String("bucket", s), zap.Error(err))
+			return err
 		}
+		buckets = append(buckets, *id)
+	}
+	if len(buckets) == 0 {
+		err := errors.New("unauthenticated writes require at least one bucket")
+		log.Error("Failed to configure unauthenticated writes", zap.Error(err))
+		return err
+	}
 
-		m.httpServer.TLSConfig = &tls.Config{
-			CurvePreferences:         []tls.CurveID{tls.CurveP521, tls.CurveP384, tls.CurveP256},
-			PreferServerCipherSuites: true,
-			MinVersion:               tlsMinVersion,
-			CipherSuites:             cipherConfig,
+	networks := make([]*net.IPNet, 0, len(m.unauthenticatedWriteNetworks))
+	for _, s := range m.unauthenticatedWriteNetworks {
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			log.Error("Invalid unauthenticated write network", zap.String("network", s), zap.Error(err))
+			return err
 		}
+		networks = append(networks, n)
 	}
 
-	if addr, ok := ln.Addr().(*net.TCPAddr); ok {
-		m.httpPort = addr.Port
+	ln, err := net.Listen("tcp", m.unauthenticatedWriteBindAddress)
+	if err != nil {
+		log.Error("failed unauthenticated write listener", zap.Error(err))
+		return err
 	}
 
+	h := http.NewUnauthenticatedWriteHandler(log, m.apibackend, buckets, networks)
+	m.reg.MustRegister(h.PrometheusCollectors()...)
+	m.unauthenticatedWriteServer = &nethttp.Server{Handler: h}
+
 	m.wg.Add(1)
 	go func(log *zap.Logger) {
 		defer m.wg.Done()
-		log.Info("Listening", zap.String("transport", transport), zap.String("addr", m.httpBindAddress), zap.Int("port", m.httpPort))
+		log.Warn("Accepting writes WITHOUT A TOKEN",
+			zap.String("addr", m.unauthenticatedWriteBindAddress),
+			zap.Strings("buckets", m.unauthenticatedWriteBuckets),
+			zap.Strings("networks", m.unauthenticatedWriteNetworks))
+		if err := m.unauthenticatedWriteServer.Serve(ln); err != nethttp.ErrServerClosed {
+			log.Error("Failed unauthenticated write service", zap.Error(err))
+		}
+		log.Info("Stopping")
+	}(log)
+	return nil
+}
 
-		if cer.Certificate != nil {
-			if err := m.httpServer.ServeTLS(ln, m.httpTLSCert, m.httpTLSKey); err != nethttp.ErrServerClosed {
-				log.Error("Failed https service", zap.Error(err))
-			}
-		} else {
-			if err := m.httpServer.Serve(ln); err != nethttp.ErrServerClosed {
-				log.Error("Failed http service", zap.Error(err))
-			}
+// runEdgeForwarder starts the forwarding of the points written with pw to
+// the central instance of the edge profile.
+func (m *Launcher) runEdgeForwarder(ctx context.Context, pw storage.PointsWriter) (*edge.Forwarder, error) {
+	log := m.log.With(zap.String("service", "edge-forwarder"))
+
+	config := m.edge
+	config.MaxQueueSize = int64(m.edgeMaxQueueSize)
+	if config.NodeID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			log.Error("Failed to get hostname as edge node ID", zap.Error(err))
+			return nil, err
 		}
+		config.NodeID = hostname
+	}
+	config.Buckets = make(map[platform.ID]string, len(m.edgeBuckets))
+	for s, bucket := range m.edgeBuckets {
+		id, err := platform.IDFromString(s)
+		if err != nil {
+			log.Error("Invalid edge bucket", zap.String("bucket", s), zap.Error(err))
+			return nil, err
+		}
+		config.Buckets[*id] = bucket
+	}
+
+	forwarder, err := edge.NewForwarder(log, config, pw)
+	if err != nil {
+		log.Error("Failed to configure edge forwarding", zap.Error(err))
+		return nil, err
+	}
+	m.reg.MustRegister(forwarder.PrometheusCollectors()...)
+
+	m.wg.Add(1)
+	go func(log *zap.Logger) {
+		defer m.wg.Done()
+		log.Info("Forwarding points to central instance", zap.String("url", config.CentralURL), zap.String("nodeID", config.NodeID))
+		forwarder.Run(ctx)
 		log.Info("Stopping")
-	}(m.log)
+	}(log)
+	return forwarder, nil
+}
+
+// runKafkaIngest starts the consumer of the configured Kafka topics, writing
+// their messages to buckets with pw.
+func (m *Launcher) runKafkaIngest(ctx context.Context, buckets kafkaingest.BucketFinder, pw storage.PointsWriter) error {
+	log := m.log.With(zap.String("service", "kafka-ingest"))
+
+	config := m.kafkaIngest
+	config.Format = ingest.Format(m.kafkaIngestFormat)
+	config.Topics = make(map[string]platform.ID, len(m.kafkaIngestTopics))
+	for topic, s := range m.kafkaIngestTopics {
+		id, err := platform.IDFromString(s)
+		if err != nil {
+			log.Error("Invalid Kafka ingest bucket", zap.String("topic", topic), zap.String("bucket", s), zap.Error(err))
+			return err
+		}
+		config.Topics[topic] = *id
+	}
+
+	consumer, err := kafkaingest.NewConsumer(log, config, buckets, pw)
+	if err != nil {
+		log.Error("Failed to configure Kafka ingest", zap.Error(err))
+		return err
+	}
+	m.reg.MustRegister(consumer.PrometheusCollectors()...)
+
+	m.wg.Add(1)
+	go func(log *zap.Logger) {
+		defer m.wg.Done()
+		log.Info("Consuming Kafka topics", zap.Strings("brokers", config.Brokers), zap.String("groupID", config.GroupID))
+		consumer.Run(ctx)
+		log.Info("Stopping")
+	}(log)
+	return nil
+}
+
+// runMQTTIngest starts the consumer of the configured MQTT topics, writing
+// their messages to buckets with pw.
+func (m *Launcher) runMQTTIngest(ctx context.Context, buckets mqttingest.BucketFinder, pw storage.PointsWriter) error {
+	log := m.log.With(zap.String("service", "mqtt-ingest"))
+
+	config := m.mqttIngest
+	config.Format = ingest.Format(m.mqttIngestFormat)
+	config.Routes = make(map[string]platform.ID, len(m.mqttIngestTopics))
+	for template, s := range m.mqttIngestTopics {
+		id, err := platform.IDFromString(s)
+		if err != nil {
+			log.Error("Invalid MQTT ingest bucket", zap.String("topic", template), zap.String("bucket", s), zap.Error(err))
+			return err
+		}
+		config.Routes[template] = *id
+	}
 
+	consumer, err := mqttingest.NewConsumer(log, config, buckets, pw)
+	if err != nil {
+		log.Error("Failed to configure MQTT ingest", zap.Error(err))
+		return err
+	}
+	m.reg.MustRegister(consumer.PrometheusCollectors()...)
+
+	m.wg.Add(1)
+	go func(log *zap.Logger) {
+		defer m.wg.Done()
+		log.Info("Consuming MQTT topics", zap.String("broker", config.Broker), zap.String("clientID", config.ClientID))
+		consumer.Run(ctx)
+		log.Info("Stopping")
+	}(log)
 	return nil
 }
 
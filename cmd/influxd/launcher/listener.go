@@ -0,0 +1,271 @@
+package launcher
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2/pkg/proxyproto"
+	"github.com/influxdata/influxdb/v2/pkg/tlscert"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// httpListener is a listener of the HTTP API. The first one is configured by
+// http-bind-address and the tls-* flags, the others by http-listeners.
+type httpListener struct {
+	// network is tcp for dual-stack listeners, or tcp4 or tcp6 to only
+	// accept IPv4 or IPv6 connections.
+	network string
+	addr    string
+
+	tlsCert          string
+	tlsKey           string
+	tlsMinVersion    string
+	tlsStrictCiphers bool
+	tlsClientCA      string
+	// tlsACME provisions the certificates of the listener with ACME
+	// instead of tlsCert and tlsKey.
+	tlsACME bool
+
+	// proxyProtocol reads the addresses of the clients from the PROXY
+	// protocol headers sent by the load balancers.
+	proxyProtocol bool
+}
+
+// parseHTTPListener parses the listener spec, as an address followed by
+// options separated by semicolons, e.g.
+// [::]:8087;network=tcp6;tls-cert=cert.pem;tls-key=key.pem;proxy-protocol=true.
+// Listeners with tls-acme=true get their certificates from the ACME server
+// configured by the tls-acme-* flags.
+func parseHTTPListener(spec string) (*httpListener, error) {
+	parts := strings.Split(spec, ";")
+	l := &httpListener{
+		network:       "tcp",
+		addr:          parts[0],
+		tlsMinVersion: "1.2",
+	}
+	if l.addr == "" {
+		return nil, fmt.Errorf("listener %q has no address", spec)
+	}
+
+	for _, opt := range parts[1:] {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("listener option %q is not a key=value pair", opt)
+		}
+		k, v := kv[0], kv[1]
+
+		var err error
+		switch k {
+		case "network":
+			switch v {
+			case "tcp", "tcp4", "tcp6":
+				l.network = v
+			default:
+				return nil, fmt.Errorf("listener network %q is not one of tcp, tcp4 or tcp6", v)
+			}
+		case "tls-cert":
+			l.tlsCert = v
+		case "tls-key":
+			l.tlsKey = v
+		case "tls-min-version":
+			l.tlsMinVersion = v
+		case "tls-strict-ciphers":
+			l.tlsStrictCiphers, err = strconv.ParseBool(v)
+		case "tls-client-ca":
+			l.tlsClientCA = v
+		case "tls-acme":
+			l.tlsACME, err = strconv.ParseBool(v)
+		case "proxy-protocol":
+			l.proxyProtocol, err = strconv.ParseBool(v)
+		default:
+			return nil, fmt.Errorf("unknown listener option %q", k)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid listener option %q: %v", opt, err)
+		}
+	}
+
+	if (l.tlsCert == "") != (l.tlsKey == "") {
+		return nil, fmt.Errorf("listener %s requires both tls-cert and tls-key", l.addr)
+	}
+	if l.tlsACME && l.tlsCert != "" {
+		return nil, fmt.Errorf("listener %s cannot combine tls-acme with tls-cert and tls-key", l.addr)
+	}
+	if l.tlsClientCA != "" && l.transport() != "https" {
+		return nil, fmt.Errorf("listener %s requires tls-cert and tls-key or tls-acme for tls-client-ca", l.addr)
+	}
+	switch l.tlsMinVersion {
+	case "1.0", "1.1", "1.2", "1.3":
+	default:
+		return nil, fmt.Errorf("listener %s has unsupported tls-min-version %q", l.addr, l.tlsMinVersion)
+	}
+	return l, nil
+}
+
+func (l *httpListener) transport() string {
+	if l.tlsCert != "" || l.tlsACME {
+		return "https"
+	}
+	return "http"
+}
+
+// listen listens on the address of l. The addresses of the clients are read
+// from the PROXY protocol headers of the load balancers of proxyNetworks,
+// before the TLS handshake. The certificates are provisioned by acm if the
+// listener uses ACME, or by the returned reloader of the certificate files.
+func (l *httpListener) listen(log *zap.Logger, proxyNetworks []*net.IPNet, acm *autocert.Manager) (net.Listener, *tlscert.Reloader, error) {
+	var (
+		config   *tls.Config
+		reloader *tlscert.Reloader
+	)
+	if l.transport() == "https" {
+		var err error
+		if config, reloader, err = l.tlsConfig(log, acm); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	ln, err := net.Listen(l.network, l.addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if l.proxyProtocol {
+		ln = proxyproto.NewListener(ln, proxyNetworks)
+	}
+	if config != nil {
+		ln = tls.NewListener(ln, config)
+	}
+	return ln, reloader, nil
+}
+
+func (l *httpListener) tlsConfig(log *zap.Logger, acm *autocert.Manager) (*tls.Config, *tlscert.Reloader, error) {
+	var (
+		getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)
+		reloader       *tlscert.Reloader
+		nextProtos     = []string{"h2", "http/1.1"}
+	)
+	if l.tlsACME {
+		if acm == nil {
+			err := fmt.Errorf("listener %s requires tls-acme-domains for tls-acme", l.addr)
+			log.Error("failed to configure ACME", zap.Error(err))
+			return nil, nil, err
+		}
+		getCertificate = acm.GetCertificate
+		// The TLS-ALPN-01 challenges are answered by the listener itself.
+		nextProtos = append(nextProtos, acme.ALPNProto)
+	} else {
+		var err error
+		reloader, err = tlscert.NewReloader(log, l.tlsCert, l.tlsKey)
+		if err != nil {
+			log.Error("failed to load x509 key pair", zap.Error(err))
+			return nil, nil, err
+		}
+		getCertificate = reloader.GetCertificate
+	}
+
+	// Sensible default
+	var tlsMinVersion uint16 = tls.VersionTLS12
+
+	switch l.tlsMinVersion {
+	case "1.0":
+		log.Warn("Setting the minimum version of TLS to 1.0 - this is discouraged. Please use 1.2 or 1.3")
+		tlsMinVersion = tls.VersionTLS10
+	case "1.1":
+		log.Warn("Setting the minimum version of TLS to 1.1 - this is discouraged. Please use 1.2 or 1.3")
+		tlsMinVersion = tls.VersionTLS11
+	case "1.2":
+		tlsMinVersion = tls.VersionTLS12
+	case "1.3":
+		tlsMinVersion = tls.VersionTLS13
+	}
+
+	strictCiphers := []uint16{
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+		tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+	}
+
+	// nil uses the default cipher suite
+	var cipherConfig []uint16 = nil
+
+	// TLS 1.3 does not support configuring the Cipher suites
+	if tlsMinVersion != tls.VersionTLS13 && l.tlsStrictCiphers {
+		cipherConfig = strictCiphers
+	}
+
+	config := &tls.Config{
+		GetCertificate:           getCertificate,
+		CurvePreferences:         []tls.CurveID{tls.CurveP521, tls.CurveP384, tls.CurveP256},
+		PreferServerCipherSuites: true,
+		MinVersion:               tlsMinVersion,
+		CipherSuites:             cipherConfig,
+		NextProtos:               nextProtos,
+	}
+
+	// Client certificates are optional: the clients without one keep
+	// authenticating with a token or session.
+	if l.tlsClientCA != "" {
+		pem, err := ioutil.ReadFile(l.tlsClientCA)
+		if err != nil {
+			log.Error("failed to read client certificate authorities", zap.Error(err))
+			return nil, nil, err
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			err := fmt.Errorf("no certificate found in %s", l.tlsClientCA)
+			log.Error("failed to load client certificate authorities", zap.Error(err))
+			return nil, nil, err
+		}
+		config.ClientCAs = clientCAs
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return config, reloader, nil
+}
+
+// acmeManager returns the manager of the certificates of tls-acme-domains,
+// provisioned and renewed with ACME, or nil if there are none.
+func (m *Launcher) acmeManager() *autocert.Manager {
+	if len(m.tlsACMEDomains) == 0 {
+		return nil
+	}
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(m.tlsACMEDomains...),
+		Cache:      autocert.DirCache(m.tlsACMECacheDir),
+		Email:      m.tlsACMEEmail,
+		Client:     &acme.Client{DirectoryURL: m.tlsACMEDirectoryURL},
+	}
+}
+
+// runACMEHTTP answers the HTTP-01 challenges of the ACME server on
+// tls-acme-http-bind-address, and redirects the other requests to HTTPs.
+func (m *Launcher) runACMEHTTP(acm *autocert.Manager) error {
+	log := m.log.With(zap.String("service", "acme-http"))
+
+	ln, err := net.Listen("tcp", m.tlsACMEHTTPBindAddress)
+	if err != nil {
+		log.Error("failed ACME http listener", zap.Error(err))
+		return err
+	}
+	m.acmeHTTPServer = &nethttp.Server{Handler: acm.HTTPHandler(nil)}
+
+	m.wg.Add(1)
+	go func(log *zap.Logger) {
+		defer m.wg.Done()
+		log.Info("Listening", zap.String("transport", "http"), zap.String("addr", ln.Addr().String()))
+		if err := m.acmeHTTPServer.Serve(ln); err != nethttp.ErrServerClosed {
+			log.Error("Failed ACME http service", zap.Error(err))
+		}
+		log.Info("Stopping")
+	}(log)
+	return nil
+}
@@ -0,0 +1,60 @@
+package launcher
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHTTPListener(t *testing.T) {
+	l, err := parseHTTPListener("[::]:8087;network=tcp6;tls-cert=cert.pem;tls-key=key.pem;tls-min-version=1.3;proxy-protocol=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &httpListener{
+		network:       "tcp6",
+		addr:          "[::]:8087",
+		tlsCert:       "cert.pem",
+		tlsKey:        "key.pem",
+		tlsMinVersion: "1.3",
+		proxyProtocol: true,
+	}
+	if !reflect.DeepEqual(l, want) {
+		t.Errorf("unexpected listener %+v, want %+v", l, want)
+	}
+	if l.transport() != "https" {
+		t.Errorf("unexpected transport %q", l.transport())
+	}
+
+	l, err = parseHTTPListener("127.0.0.1:8088")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.network != "tcp" || l.transport() != "http" || l.proxyProtocol {
+		t.Errorf("unexpected defaults %+v", l)
+	}
+
+	l, err = parseHTTPListener(":443;tls-acme=true;tls-client-ca=ca.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.tlsACME || l.transport() != "https" {
+		t.Errorf("expected the listener to serve HTTPs with ACME, got %+v", l)
+	}
+
+	for _, spec := range []string{
+		"",
+		";proxy-protocol=true",
+		":8087;network=udp",
+		":8087;tls-cert=cert.pem",
+		":8087;tls-client-ca=ca.pem",
+		":8087;tls-cert=cert.pem;tls-key=key.pem;tls-min-version=1.4",
+		":8087;proxy-protocol=maybe",
+		":8087;proxy-protocol",
+		":8087;unknown=1",
+		":8087;tls-acme=true;tls-cert=cert.pem;tls-key=key.pem",
+	} {
+		if _, err := parseHTTPListener(spec); err == nil {
+			t.Errorf("expected listener %q to be invalid", spec)
+		}
+	}
+}
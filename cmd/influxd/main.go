@@ -12,6 +12,8 @@ import (
 	"github.com/influxdata/influxdb/v2/cmd/influxd/generate"
 	"github.com/influxdata/influxdb/v2/cmd/influxd/launcher"
 	"github.com/influxdata/influxdb/v2/cmd/influxd/restore"
+	"github.com/influxdata/influxdb/v2/cmd/influxd/taskworker"
+	"github.com/influxdata/influxdb/v2/cmd/influxd/upgrade"
 	_ "github.com/influxdata/influxdb/v2/query/builtin"
 	_ "github.com/influxdata/influxdb/v2/tsdb/tsi1"
 	_ "github.com/influxdata/influxdb/v2/tsdb/tsm1"
@@ -34,6 +36,8 @@ func main() {
 	rootCmd := launcher.NewInfluxdCommand(context.Background(),
 		generate.Command,
 		restore.Command,
+		taskworker.Command,
+		upgrade.Command,
 		&cobra.Command{
 			Use:   "version",
 			Short: "Print the influxd server version",
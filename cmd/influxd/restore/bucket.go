@@ -0,0 +1,156 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/bolt"
+	ihttp "github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/storage/bucketarchive"
+	"github.com/influxdata/influxdb/v2/tenant"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+	"go.uber.org/zap"
+)
+
+// MetadataRestoredFrom is the metadata key of a restored bucket holding the
+// ID of the bucket of the backup it was restored from.
+const MetadataRestoredFrom = "restoredFrom"
+
+// restoreBucket restores a bucket of the backup into the running instance of
+// the host flag. The bucket is created in the metadata of the instance, and
+// the keys of its data are remapped from the IDs of the organization and
+// bucket of the backup to those of the new bucket as its archive is written,
+// so that the instance imports it like any bucket archive.
+func restoreBucket(ctx context.Context) error {
+	src, srcOrg, err := findBackupBucket(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find bucket in backup: %v", err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(flags.backupPath, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return err
+	}
+	// The names of the files start with their generation.
+	sort.Strings(paths)
+
+	client, err := ihttp.NewHTTPClient(flags.host, flags.token, flags.skipVerify)
+	if err != nil {
+		return err
+	}
+	orgSvc := &ihttp.OrganizationService{Client: client}
+	bucketSvc := &ihttp.BucketService{Client: client}
+
+	orgName := flags.newOrg
+	if orgName == "" {
+		orgName = srcOrg.Name
+	}
+	org, err := orgSvc.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &orgName})
+	if err != nil {
+		return fmt.Errorf("failed to find organization %q: %v", orgName, err)
+	}
+
+	b := &influxdb.Bucket{
+		OrgID:             org.ID,
+		Name:              flags.newBucket,
+		Description:       src.Description,
+		RetentionPeriod:   src.RetentionPeriod,
+		WritePastWindow:   src.WritePastWindow,
+		WriteFutureWindow: src.WriteFutureWindow,
+		DedupWindow:       src.DedupWindow,
+		Metadata:          map[string]string{},
+	}
+	if b.Name == "" {
+		b.Name = src.Name
+	}
+	for k, v := range src.Metadata {
+		b.Metadata[k] = v
+	}
+	b.Metadata[MetadataRestoredFrom] = src.ID.String()
+	if err := bucketSvc.CreateBucket(ctx, b); err != nil {
+		return fmt.Errorf("failed to create bucket %q: %v", b.Name, err)
+	}
+
+	if err := importBackupBucket(ctx, paths, src, b); err != nil {
+		// The bucket is removed with the data imported before the failure.
+		if derr := bucketSvc.DeleteBucket(ctx, b.ID); derr != nil {
+			return fmt.Errorf("failed to restore bucket %q: %v; failed to remove it: %v", b.Name, err, derr)
+		}
+		return fmt.Errorf("failed to restore bucket %q: %v", b.Name, err)
+	}
+
+	fmt.Printf("Restored bucket %q (%s) of organization %q to bucket %q (%s) of organization %q\n",
+		src.Name, src.ID, srcOrg.Name, b.Name, b.ID, org.Name)
+	return nil
+}
+
+// findBackupBucket returns the bucket to restore and its organization from
+// the metadata of the backup.
+func findBackupBucket(ctx context.Context) (*influxdb.Bucket, *influxdb.Organization, error) {
+	path := filepath.Join(flags.backupPath, bolt.DefaultFilename)
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil, fmt.Errorf("no bolt file in backup: %v", err)
+	}
+
+	store := bolt.NewKVStore(zap.NewNop(), path)
+	if err := store.Open(ctx); err != nil {
+		return nil, nil, err
+	}
+	defer store.Close()
+	svc := tenant.NewService(tenant.NewStore(store))
+
+	var filter influxdb.BucketFilter
+	if flags.bucketID != "" {
+		id, err := influxdb.IDFromString(flags.bucketID)
+		if err != nil {
+			return nil, nil, err
+		}
+		filter.ID = id
+	} else {
+		if flags.org == "" {
+			return nil, nil, fmt.Errorf("the organization of the bucket is required with its name")
+		}
+		filter.Name = &flags.bucket
+		filter.Org = &flags.org
+	}
+
+	b, err := svc.FindBucket(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	org, err := svc.FindOrganizationByID(ctx, b.OrgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, org, nil
+}
+
+// importBackupBucket streams the archive of the bucket src of the backup to
+// the instance, which imports it into the bucket b.
+func importBackupBucket(ctx context.Context, paths []string, src, b *influxdb.Bucket) error {
+	pr, pw := io.Pipe()
+	errc := make(chan error, 1)
+	go func() {
+		err := bucketarchive.ExportBackupBucket(ctx, paths, src.OrgID, src.ID, pw)
+		pw.CloseWithError(err)
+		errc <- err
+	}()
+
+	archiveSvc := &bucketarchive.Client{
+		Addr:               flags.host,
+		Token:              flags.token,
+		InsecureSkipVerify: flags.skipVerify,
+	}
+	err := archiveSvc.ImportBucket(ctx, b.OrgID, b.ID, pr)
+	// Closing the reader stops the archive when the import failed early.
+	pr.Close()
+	if xerr := <-errc; xerr != nil && xerr != io.ErrClosedPipe {
+		return fmt.Errorf("failed to read backup: %v", xerr)
+	}
+	return err
+}
@@ -1,6 +1,7 @@
 package restore
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -29,10 +30,16 @@ Rebuilding the index and series file uses default options as in
 For additional performance options, run restore with "-rebuild-index false"
 and build-tsi afterwards.
 
+A single bucket of a backup is restored into a running instance instead
+with the "bucket" or "bucket-id" flag. The bucket is created in the
+instance under a new ID, and under another name with "new-bucket", and its
+data is remapped to the new bucket and imported without restarting the
+instance. Other buckets and the metadata of the instance are kept.
+
 NOTES:
 
 * The influxd server should not be running when using the restore tool
-  as it replaces all data and metadata.
+  to restore all data and metadata, as it replaces them.
 `,
 	Args: cobra.ExactArgs(0),
 	RunE: restoreE,
@@ -44,6 +51,15 @@ var flags struct {
 	credPath   string
 	backupPath string
 	rebuildTSI bool
+
+	bucketID   string
+	bucket     string
+	org        string
+	newBucket  string
+	newOrg     string
+	host       string
+	token      string
+	skipVerify bool
 }
 
 func init() {
@@ -88,6 +104,54 @@ func init() {
 			Default: true,
 			Desc:    "if true, rebuild the TSI index and series file based on the given engine path (equivalent to influxd inspect build-tsi)",
 		},
+		{
+			DestP:   &flags.bucketID,
+			Flag:    "bucket-id",
+			Default: "",
+			Desc:    "the ID of the bucket of the backup to restore into the running instance of host",
+		},
+		{
+			DestP:   &flags.bucket,
+			Flag:    "bucket",
+			Default: "",
+			Desc:    "the name of the bucket of the backup to restore into the running instance of host, with org",
+		},
+		{
+			DestP:   &flags.org,
+			Flag:    "org",
+			Default: "",
+			Desc:    "the name of the organization of the bucket in the backup",
+		},
+		{
+			DestP:   &flags.newBucket,
+			Flag:    "new-bucket",
+			Default: "",
+			Desc:    "the name of the restored bucket, defaults to the name of the bucket in the backup",
+		},
+		{
+			DestP:   &flags.newOrg,
+			Flag:    "new-org",
+			Default: "",
+			Desc:    "the name of the organization of the restored bucket, defaults to the name of the organization in the backup",
+		},
+		{
+			DestP:   &flags.host,
+			Flag:    "host",
+			Default: "http://localhost:8086",
+			Desc:    "the HTTP address of the instance to restore a bucket into",
+		},
+		{
+			DestP:   &flags.token,
+			Flag:    "token",
+			Default: "",
+			Desc:    "the token of the instance to restore a bucket into",
+		},
+		{
+			DestP:   &flags.skipVerify,
+			Flag:    "skip-verify",
+			Default: false,
+			Desc:    "skip TLS certificate verification when restoring a bucket",
+		},
 	}
 
 	cli.BindOptions(Command, opts)
@@ -98,6 +162,10 @@ func restoreE(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no backup path given")
 	}
 
+	if flags.bucketID != "" || flags.bucket != "" {
+		return restoreBucket(context.Background())
+	}
+
 	if err := moveBolt(); err != nil {
 		return fmt.Errorf("failed to move existing bolt file: %v", err)
 	}
@@ -0,0 +1,134 @@
+package taskworker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/kit/cli"
+	"github.com/influxdata/influxdb/v2/logger"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/task/backend/remote"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var Command = &cobra.Command{
+	Use:   "task-worker",
+	Short: "Execute task runs dispatched by influxd",
+	Long: `
+This command starts a worker executing the runs of tasks dispatched by an
+influxd server started with "--task-remote-workers".
+
+The worker claims runs over the internal API of the server, which requires an
+operator token. The queries of the runs are executed against the query host,
+which defaults to the server, with a token scoped to the permissions of the
+task of each run.
+
+Runs of a worker that stops sending heartbeats are reassigned to other
+workers.
+`,
+	Args: cobra.ExactArgs(0),
+	RunE: taskWorkerE,
+}
+
+var flags struct {
+	host               string
+	queryHost          string
+	token              string
+	workerID           string
+	concurrency        int
+	insecureSkipVerify bool
+}
+
+func init() {
+	hostname, _ := os.Hostname()
+
+	Command.Flags().SortFlags = false
+
+	opts := []cli.Opt{
+		{
+			DestP:   &flags.host,
+			Flag:    "host",
+			Default: "http://localhost:8086",
+			Desc:    "HTTP address of the influxd server dispatching the runs",
+		},
+		{
+			DestP: &flags.queryHost,
+			Flag:  "query-host",
+			Desc:  "HTTP address the queries of the runs are executed against, defaults to the host",
+		},
+		{
+			DestP: &flags.token,
+			Flag:  "token",
+			Desc:  "operator token to claim runs with",
+		},
+		{
+			DestP:   &flags.workerID,
+			Flag:    "worker-id",
+			Default: fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+			Desc:    "ID identifying the worker, unique among the workers of the server",
+		},
+		{
+			DestP:   &flags.concurrency,
+			Flag:    "concurrency",
+			Default: 4,
+			Desc:    "number of runs executed at the same time",
+		},
+		{
+			DestP:   &flags.insecureSkipVerify,
+			Flag:    "skip-verify",
+			Default: false,
+			Desc:    "skip TLS certificate verification",
+		},
+	}
+
+	cli.BindOptions(Command, opts)
+}
+
+func taskWorkerE(cmd *cobra.Command, args []string) error {
+	if flags.token == "" {
+		return fmt.Errorf("no token given")
+	}
+	if flags.concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1")
+	}
+	queryHost := flags.queryHost
+	if queryHost == "" {
+		queryHost = flags.host
+	}
+
+	log := logger.New(os.Stdout).With(zap.String("worker", flags.workerID))
+
+	client, err := http.NewHTTPClient(flags.host, flags.token, flags.insecureSkipVerify)
+	if err != nil {
+		return err
+	}
+
+	querySvc := func(token string) query.QueryService {
+		return &http.FluxQueryService{
+			Addr:               queryHost,
+			Token:              token,
+			Name:               "task-worker",
+			InsecureSkipVerify: flags.insecureSkipVerify,
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		log.Info("Stopping task worker")
+		cancel()
+	}()
+
+	log.Info("Starting task worker", zap.String("host", flags.host), zap.String("queryHost", queryHost), zap.Int("concurrency", flags.concurrency))
+	worker := remote.NewWorker(log, remote.NewClient(client, flags.workerID), querySvc, flags.concurrency)
+	return worker.Run(ctx)
+}
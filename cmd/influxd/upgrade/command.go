@@ -0,0 +1,211 @@
+package upgrade
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/internal/fs"
+	"github.com/influxdata/influxdb/v2/kit/cli"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb/migrate"
+	"github.com/spf13/cobra"
+)
+
+var Command = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Migrate the TSM data of an InfluxDB 1.x instance",
+	Long: `
+This command migrates the TSM data of an InfluxDB 1.x data directory into
+the buckets of an organization of an InfluxDB 2.x instance.
+
+The shards of every database except "_internal" are migrated unless
+databases or retention policies are selected with "select", formatted as
+"db" or "db/rp". The shards of a retention policy are migrated into a bucket
+named "db/rp" unless another name is given with "bucket-name", formatted as
+"db/rp=bucket".
+
+With "state-path", the shards already migrated are recorded in the given
+file and skipped when the command runs again, so that an interrupted
+migration can be resumed.
+
+NOTES:
+
+* The influxd server should not be running when using the upgrade tool.
+`,
+	Args: cobra.ExactArgs(0),
+	RunE: upgradeE,
+}
+
+var flags struct {
+	sourcePath string
+	destPath   string
+	destOrg    string
+
+	from            string
+	to              string
+	migrateHotShard bool
+
+	selectors   []string
+	bucketNames []string
+	statePath   string
+
+	dryRun  bool
+	verbose bool
+}
+
+func init() {
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(fmt.Errorf("failed to determine influx directory: %s", err))
+	}
+
+	Command.Flags().SortFlags = false
+
+	pfs := Command.PersistentFlags()
+	pfs.SortFlags = false
+
+	opts := []cli.Opt{
+		{
+			DestP:   &flags.sourcePath,
+			Flag:    "source-path",
+			Default: "",
+			Desc:    "path to the data directory of the 1.x instance",
+		},
+		{
+			DestP:   &flags.destPath,
+			Flag:    "dest-path",
+			Default: dir,
+			Desc:    "path to the directory of the 2.x instance",
+		},
+		{
+			DestP:   &flags.destOrg,
+			Flag:    "dest-org",
+			Default: "",
+			Desc:    "the ID of the organization the buckets are created in",
+		},
+		{
+			DestP:   &flags.from,
+			Flag:    "from",
+			Default: "",
+			Desc:    "migrate only the data from this time, formatted as RFC3339",
+		},
+		{
+			DestP:   &flags.to,
+			Flag:    "to",
+			Default: "",
+			Desc:    "migrate only the data until this time, formatted as RFC3339",
+		},
+		{
+			DestP:   &flags.migrateHotShard,
+			Flag:    "migrate-hot-shard",
+			Default: false,
+			Desc:    "migrate the hot shard of each retention policy",
+		},
+		{
+			DestP:   &flags.selectors,
+			Flag:    "select",
+			Default: []string{},
+			Desc:    "migrate only the selected database or retention policy, formatted as db or db/rp; may be repeated",
+		},
+		{
+			DestP:   &flags.bucketNames,
+			Flag:    "bucket-name",
+			Default: []string{},
+			Desc:    "the name of the bucket a retention policy is migrated to, formatted as db/rp=bucket; may be repeated",
+		},
+		{
+			DestP:   &flags.statePath,
+			Flag:    "state-path",
+			Default: "",
+			Desc:    "path to the file recording the migrated shards, to resume an interrupted migration",
+		},
+		{
+			DestP:   &flags.dryRun,
+			Flag:    "dry-run",
+			Default: false,
+			Desc:    "print the migration without migrating any data",
+		},
+		{
+			DestP:   &flags.verbose,
+			Flag:    "verbose",
+			Default: false,
+			Desc:    "print every step of the migration",
+		},
+	}
+
+	cli.BindOptions(Command, opts)
+}
+
+func upgradeE(cmd *cobra.Command, args []string) error {
+	config, err := newConfig()
+	if err != nil {
+		return err
+	}
+	return migrate.NewMigrator(config).Process1xShards()
+}
+
+// newConfig returns the configuration of the migration given by the flags.
+func newConfig() (migrate.Config, error) {
+	if flags.sourcePath == "" {
+		return migrate.Config{}, fmt.Errorf("no source path given")
+	}
+
+	var destOrg influxdb.ID
+	if err := destOrg.DecodeFromString(flags.destOrg); err != nil {
+		return migrate.Config{}, fmt.Errorf("invalid destination organization ID %q: %v", flags.destOrg, err)
+	}
+
+	from, err := parseTime(flags.from, models.MinNanoTime)
+	if err != nil {
+		return migrate.Config{}, fmt.Errorf("invalid from time: %v", err)
+	}
+	to, err := parseTime(flags.to, models.MaxNanoTime)
+	if err != nil {
+		return migrate.Config{}, fmt.Errorf("invalid to time: %v", err)
+	}
+
+	selectors := make([]migrate.Selector, 0, len(flags.selectors))
+	for _, s := range flags.selectors {
+		sel, err := migrate.ParseSelector(s)
+		if err != nil {
+			return migrate.Config{}, err
+		}
+		selectors = append(selectors, sel)
+	}
+
+	bucketNames, err := migrate.ParseBucketNames(flags.bucketNames)
+	if err != nil {
+		return migrate.Config{}, err
+	}
+
+	return migrate.Config{
+		SourcePath:      filepath.Clean(flags.sourcePath),
+		DestPath:        filepath.Clean(flags.destPath),
+		DestOrg:         destOrg,
+		From:            from,
+		To:              to,
+		MigrateHotShard: flags.migrateHotShard,
+		DryRun:          flags.dryRun,
+		Selectors:       selectors,
+		BucketNames:     bucketNames,
+		StatePath:       flags.statePath,
+		Stdout:          os.Stdout,
+		VerboseLogging:  flags.verbose,
+	}, nil
+}
+
+// parseTime parses an RFC3339 time as Unix nanoseconds, returning def if s
+// is empty.
+func parseTime(s string, def int64) (int64, error) {
+	if s == "" {
+		return def, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return 0, err
+	}
+	return t.UnixNano(), nil
+}
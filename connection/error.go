@@ -0,0 +1,57 @@
+package connection
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrConnectionProfileNotFound is used when the specified connection
+	// profile cannot be found.
+	ErrConnectionProfileNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "connection profile not found",
+	}
+
+	// ErrInvalidConnectionProfileID is used when the ID of the connection
+	// profile cannot be encoded.
+	ErrInvalidConnectionProfileID = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "connection profile ID is invalid",
+	}
+
+	// ErrConnectionProfileNameConflict is used when the name of a
+	// connection profile is already used in its organization.
+	ErrConnectionProfileNameConflict = &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  "connection profile name is not unique in the organization",
+	}
+)
+
+// ErrInvalidConnectionProfile is used when a service was provided an invalid
+// connection profile.
+func ErrInvalidConnectionProfile(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "connection profile provided is invalid",
+		Err:  err,
+	}
+}
+
+// ErrManagedSecret is used when a secret of a connection profile is written
+// through the secret service rather than its profile.
+func ErrManagedSecret(key string) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EForbidden,
+		Msg:  fmt.Sprintf("secret %q is managed by its connection profile", key),
+	}
+}
+
+// ErrInternalService is used when the error comes from an internal system.
+func ErrInternalService(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
@@ -0,0 +1,233 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixConnections is the prefix of the connection profile API.
+	PrefixConnections = "/api/v2/connections"
+)
+
+// Handler is the HTTP API handler for connection profiles.
+type Handler struct {
+	chi.Router
+	api            *kithttp.API
+	log            *zap.Logger
+	connectionsSvc influxdb.ConnectionProfileService
+	orgSvc         influxdb.OrganizationService
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, connectionsSvc influxdb.ConnectionProfileService, orgSvc influxdb.OrganizationService) *Handler {
+	h := &Handler{
+		api:            kithttp.NewAPI(kithttp.WithLog(log)),
+		log:            log,
+		connectionsSvc: connectionsSvc,
+		orgSvc:         orgSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Post("/", h.handlePostConnectionProfile)
+		r.Get("/", h.handleGetConnectionProfiles)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetConnectionProfile)
+			r.Patch("/", h.handlePatchConnectionProfile)
+			r.Delete("/", h.handleDeleteConnectionProfile)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixConnections
+}
+
+type connectionProfileResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.ConnectionProfile
+	SecretKey string `json:"secretKey"`
+}
+
+func newConnectionProfileResponse(p *influxdb.ConnectionProfile) *connectionProfileResponse {
+	return &connectionProfileResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("%s/%s", PrefixConnections, p.ID),
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", p.OrgID),
+		},
+		ConnectionProfile: p,
+		SecretKey:         p.SecretKey(),
+	}
+}
+
+type connectionProfilesResponse struct {
+	Links       map[string]string            `json:"links"`
+	Connections []*connectionProfileResponse `json:"connections"`
+}
+
+type postConnectionProfileRequest struct {
+	OrgID        influxdb.ID             `json:"orgID"`
+	Org          string                  `json:"org,omitempty"` // the ID or name of the organization, when OrgID is not set
+	Name         string                  `json:"name"`
+	Description  string                  `json:"description"`
+	Kind         influxdb.ConnectionKind `json:"kind"`
+	Driver       string                  `json:"driver"`
+	AllowedHosts []string                `json:"allowedHosts"`
+	Secret       string                  `json:"secret"`
+}
+
+// resolveOrgID returns the ID of the organization identified by orgID, or
+// else by org.
+func (h *Handler) resolveOrgID(ctx context.Context, orgID, org string) (influxdb.ID, error) {
+	o, err := influxdb.ResolveOrganization(ctx, h.orgSvc, orgID, org)
+	if err != nil {
+		return 0, err
+	}
+	return o.ID, nil
+}
+
+func (h *Handler) handlePostConnectionProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req postConnectionProfileRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if !req.OrgID.Valid() {
+		orgID, err := h.resolveOrgID(ctx, "", req.Org)
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		req.OrgID = orgID
+	}
+
+	p := &influxdb.ConnectionProfile{
+		OrgID:        req.OrgID,
+		Name:         req.Name,
+		Description:  req.Description,
+		Kind:         req.Kind,
+		Driver:       req.Driver,
+		AllowedHosts: req.AllowedHosts,
+	}
+	if err := h.connectionsSvc.CreateConnectionProfile(ctx, p, req.Secret); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Connection profile created", zap.String("connection", p.Name), zap.String("orgID", p.OrgID.String()))
+
+	h.api.Respond(w, r, http.StatusCreated, newConnectionProfileResponse(p))
+}
+
+func (h *Handler) handleGetConnectionProfiles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var filter influxdb.ConnectionProfileFilter
+	q := r.URL.Query()
+	if orgID, org := q.Get("orgID"), q.Get("org"); orgID != "" || org != "" {
+		id, err := h.resolveOrgID(ctx, orgID, org)
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		filter.OrgID = &id
+	}
+	if v := q.Get("name"); v != "" {
+		filter.Name = &v
+	}
+	if v := q.Get("kind"); v != "" {
+		kind := influxdb.ConnectionKind(v)
+		if err := kind.Valid(); err != nil {
+			h.api.Err(w, r, &influxdb.Error{Code: influxdb.EInvalid, Err: err})
+			return
+		}
+		filter.Kind = &kind
+	}
+
+	profiles, _, err := h.connectionsSvc.FindConnectionProfiles(ctx, filter)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	resp := connectionProfilesResponse{
+		Links:       map[string]string{"self": PrefixConnections},
+		Connections: make([]*connectionProfileResponse, 0, len(profiles)),
+	}
+	for _, p := range profiles {
+		resp.Connections = append(resp.Connections, newConnectionProfileResponse(p))
+	}
+	h.api.Respond(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleGetConnectionProfile(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	p, err := h.connectionsSvc.FindConnectionProfileByID(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newConnectionProfileResponse(p))
+}
+
+func (h *Handler) handlePatchConnectionProfile(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	var upd influxdb.ConnectionProfileUpdate
+	if err := h.api.DecodeJSON(r.Body, &upd); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	p, err := h.connectionsSvc.UpdateConnectionProfile(r.Context(), *id, upd)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Connection profile updated", zap.String("connectionID", p.ID.String()))
+
+	h.api.Respond(w, r, http.StatusOK, newConnectionProfileResponse(p))
+}
+
+func (h *Handler) handleDeleteConnectionProfile(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	if err := h.connectionsSvc.DeleteConnectionProfile(r.Context(), *id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Connection profile deleted", zap.String("connectionID", id.String()))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
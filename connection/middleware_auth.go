@@ -0,0 +1,83 @@
+package connection
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+var _ influxdb.ConnectionProfileService = (*AuthedService)(nil)
+
+// AuthedService authorizes connection profiles with the permissions on the
+// secrets of their organization, as their credentials are secrets of the
+// organization.
+type AuthedService struct {
+	s influxdb.ConnectionProfileService
+}
+
+// NewAuthedService wraps s with connection profile authorization.
+func NewAuthedService(s influxdb.ConnectionProfileService) *AuthedService {
+	return &AuthedService{s: s}
+}
+
+func (s *AuthedService) CreateConnectionProfile(ctx context.Context, p *influxdb.ConnectionProfile, secret string) error {
+	if _, _, err := authorizer.AuthorizeCreate(ctx, influxdb.SecretsResourceType, p.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateConnectionProfile(ctx, p, secret)
+}
+
+func (s *AuthedService) FindConnectionProfileByID(ctx context.Context, id influxdb.ID) (*influxdb.ConnectionProfile, error) {
+	p, err := s.s.FindConnectionProfileByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.SecretsResourceType, p.OrgID); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *AuthedService) FindConnectionProfiles(ctx context.Context, filter influxdb.ConnectionProfileFilter, opt ...influxdb.FindOptions) ([]*influxdb.ConnectionProfile, int, error) {
+	profiles, _, err := s.s.FindConnectionProfiles(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	authorized := profiles[:0]
+	for _, p := range profiles {
+		_, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.SecretsResourceType, p.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		authorized = append(authorized, p)
+	}
+	return authorized, len(authorized), nil
+}
+
+func (s *AuthedService) UpdateConnectionProfile(ctx context.Context, id influxdb.ID, upd influxdb.ConnectionProfileUpdate) (*influxdb.ConnectionProfile, error) {
+	p, err := s.s.FindConnectionProfileByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.SecretsResourceType, p.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateConnectionProfile(ctx, id, upd)
+}
+
+func (s *AuthedService) DeleteConnectionProfile(ctx context.Context, id influxdb.ID) error {
+	p, err := s.s.FindConnectionProfileByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.SecretsResourceType, p.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteConnectionProfile(ctx, id)
+}
@@ -0,0 +1,90 @@
+package connection
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.SecretService = (*SecretService)(nil)
+
+// SecretService refuses the writes of the secrets of connection profiles,
+// which are only written through their profiles so that their data sources
+// are checked against the allowed hosts of the profiles.
+type SecretService struct {
+	influxdb.SecretService
+}
+
+// NewSecretService wraps s so that the secrets of connection profiles
+// cannot be written.
+func NewSecretService(s influxdb.SecretService) *SecretService {
+	return &SecretService{SecretService: s}
+}
+
+// PutSecret stores the secret pair (k,v) unless k is the key of the secret
+// of a connection profile.
+func (s *SecretService) PutSecret(ctx context.Context, orgID influxdb.ID, k string, v string) error {
+	if err := checkUnmanaged(k); err != nil {
+		return err
+	}
+	return s.SecretService.PutSecret(ctx, orgID, k, v)
+}
+
+// PutSecrets replaces the secrets of the organization with m, keeping the
+// secrets of its connection profiles.
+func (s *SecretService) PutSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+	secrets := make(map[string]string, len(m))
+	for k, v := range m {
+		if err := checkUnmanaged(k); err != nil {
+			return err
+		}
+		secrets[k] = v
+	}
+
+	keys, err := s.SecretService.GetSecretKeys(ctx, orgID)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	for _, k := range keys {
+		if _, ok := influxdb.ConnectionProfileIDFromSecretKey(k); !ok {
+			continue
+		}
+		v, err := s.SecretService.LoadSecret(ctx, orgID, k)
+		if err != nil {
+			return err
+		}
+		secrets[k] = v
+	}
+	return s.SecretService.PutSecrets(ctx, orgID, secrets)
+}
+
+// PatchSecrets updates the secrets in m unless one of them is the secret of
+// a connection profile.
+func (s *SecretService) PatchSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+	for k := range m {
+		if err := checkUnmanaged(k); err != nil {
+			return err
+		}
+	}
+	return s.SecretService.PatchSecrets(ctx, orgID, m)
+}
+
+// DeleteSecret removes the secrets ks unless one of them is the secret of a
+// connection profile.
+func (s *SecretService) DeleteSecret(ctx context.Context, orgID influxdb.ID, ks ...string) error {
+	for _, k := range ks {
+		if err := checkUnmanaged(k); err != nil {
+			return err
+		}
+	}
+	return s.SecretService.DeleteSecret(ctx, orgID, ks...)
+}
+
+// checkUnmanaged returns an error if k is the key of the secret of a
+// connection profile.
+func checkUnmanaged(k string) error {
+	if _, ok := influxdb.ConnectionProfileIDFromSecretKey(k); ok {
+		return ErrManagedSecret(k)
+	}
+	return nil
+}
@@ -0,0 +1,417 @@
+// Package connection implements connection profiles: the credentials Flux
+// scripts use to connect to SQL databases and HTTP APIs, stored as secrets
+// of their organization rather than embedded in the scripts. A profile
+// allows its credentials to be sent to a list of hosts only.
+//
+// Scripts read the credentials of a profile with secrets.get, and are
+// checked against the profile when they are saved, see fluxlint.
+package connection
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/snowflake"
+)
+
+var (
+	connectionProfileBucket = []byte("connectionprofilesv1")
+	indexBucket             = []byte("connectionprofileindexv1")
+)
+
+var _ influxdb.ConnectionProfileService = (*Service)(nil)
+
+// Service stores connection profiles in a kv store, and their credentials
+// in a secret service.
+type Service struct {
+	store         kv.Store
+	secrets       influxdb.SecretService
+	IDGen         influxdb.IDGenerator
+	TimeGenerator influxdb.TimeGenerator
+}
+
+// NewService constructs a connection profile service backed by st. The
+// secret service must accept writes of the secrets of profiles.
+func NewService(st kv.Store, secrets influxdb.SecretService) *Service {
+	return &Service{
+		store:         st,
+		secrets:       secrets,
+		IDGen:         snowflake.NewDefaultIDGenerator(),
+		TimeGenerator: influxdb.RealTimeGenerator{},
+	}
+}
+
+// CreateConnectionProfile creates a connection profile, and then stores its
+// secret.
+func (s *Service) CreateConnectionProfile(ctx context.Context, p *influxdb.ConnectionProfile, secret string) error {
+	if err := valid(p, secret); err != nil {
+		return err
+	}
+	if err := checkDataSource(p, secret); err != nil {
+		return err
+	}
+
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		if err := s.checkNameAvailable(tx, p.OrgID, p.Name); err != nil {
+			return err
+		}
+
+		now := s.TimeGenerator.Now()
+		p.ID = s.IDGen.ID()
+		p.CreatedAt = now
+		p.UpdatedAt = now
+
+		if err := s.putConnectionProfile(tx, p); err != nil {
+			return err
+		}
+		return s.putIndex(tx, p)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.secrets.PutSecret(ctx, p.OrgID, p.SecretKey(), secret); err != nil {
+		_ = s.deleteConnectionProfile(ctx, p.ID)
+		return err
+	}
+	return nil
+}
+
+// FindConnectionProfileByID returns a single connection profile by ID.
+func (s *Service) FindConnectionProfileByID(ctx context.Context, id influxdb.ID) (*influxdb.ConnectionProfile, error) {
+	var p *influxdb.ConnectionProfile
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		p, err = s.getConnectionProfile(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FindConnectionProfiles returns a list of connection profiles that match
+// filter and the total count of matching profiles, ordered by name.
+func (s *Service) FindConnectionProfiles(ctx context.Context, filter influxdb.ConnectionProfileFilter, opt ...influxdb.FindOptions) ([]*influxdb.ConnectionProfile, int, error) {
+	profiles := []*influxdb.ConnectionProfile{}
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		if filter.OrgID != nil && filter.Name != nil {
+			p, err := s.findByName(tx, *filter.OrgID, *filter.Name)
+			if err == ErrConnectionProfileNotFound {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if filterFunc(p, filter) {
+				profiles = append(profiles, p)
+			}
+			return nil
+		}
+
+		b, err := tx.Bucket(connectionProfileBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		return kv.WalkCursor(ctx, cur, func(k, v []byte) error {
+			p := &influxdb.ConnectionProfile{}
+			if err := json.Unmarshal(v, p); err != nil {
+				return ErrInternalService(err)
+			}
+			if filterFunc(p, filter) {
+				profiles = append(profiles, p)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.SliceStable(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+
+	total := len(profiles)
+	if len(opt) > 0 {
+		profiles = paginate(profiles, opt[0])
+	}
+	return profiles, total, nil
+}
+
+// UpdateConnectionProfile updates a single connection profile with
+// changeset, and then its secret if the changeset replaces it. The data
+// source of sql profiles is checked against their allowed hosts again.
+func (s *Service) UpdateConnectionProfile(ctx context.Context, id influxdb.ID, upd influxdb.ConnectionProfileUpdate) (*influxdb.ConnectionProfile, error) {
+	p, err := s.FindConnectionProfileByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret string
+	if upd.Secret != nil {
+		secret = *upd.Secret
+	} else if secret, err = s.secrets.LoadSecret(ctx, p.OrgID, p.SecretKey()); err != nil {
+		return nil, err
+	}
+
+	err = s.store.Update(ctx, func(tx kv.Tx) error {
+		var err error
+		p, err = s.getConnectionProfile(tx, id)
+		if err != nil {
+			return err
+		}
+		prevName := p.Name
+
+		upd.Apply(p)
+		if err := valid(p, secret); err != nil {
+			return err
+		}
+		if err := checkDataSource(p, secret); err != nil {
+			return err
+		}
+
+		if p.Name != prevName {
+			if err := s.checkNameAvailable(tx, p.OrgID, p.Name); err != nil {
+				return err
+			}
+			if err := s.deleteIndex(tx, p.OrgID, prevName); err != nil {
+				return err
+			}
+			if err := s.putIndex(tx, p); err != nil {
+				return err
+			}
+		}
+
+		p.UpdatedAt = s.TimeGenerator.Now()
+		return s.putConnectionProfile(tx, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if upd.Secret != nil {
+		if err := s.secrets.PutSecret(ctx, p.OrgID, p.SecretKey(), *upd.Secret); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// DeleteConnectionProfile removes a single connection profile by ID, and
+// then its secret.
+func (s *Service) DeleteConnectionProfile(ctx context.Context, id influxdb.ID) error {
+	p, err := s.FindConnectionProfileByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.deleteConnectionProfile(ctx, id); err != nil {
+		return err
+	}
+	if err := s.secrets.DeleteSecret(ctx, p.OrgID, p.SecretKey()); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	return nil
+}
+
+// valid returns an error if p is invalid or has no secret.
+func valid(p *influxdb.ConnectionProfile, secret string) error {
+	if err := p.Valid(); err != nil {
+		return ErrInvalidConnectionProfile(err)
+	}
+	if secret == "" {
+		return ErrInvalidConnectionProfile(errors.New("secret is required"))
+	}
+	return nil
+}
+
+// checkDataSource returns an error if the host of the data source in the
+// secret of a sql profile is not allowed by the profile.
+func checkDataSource(p *influxdb.ConnectionProfile, secret string) error {
+	if p.Kind != influxdb.ConnectionKindSQL {
+		return nil
+	}
+
+	u, err := ParseDataSource(p.Driver, secret)
+	if err != nil {
+		return ErrInvalidConnectionProfile(fmt.Errorf("invalid data source name: %v", err))
+	}
+	if !p.AllowsHost(u.Hostname()) {
+		return ErrInvalidConnectionProfile(fmt.Errorf("data source host %q is not allowed", u.Hostname()))
+	}
+	return nil
+}
+
+func (s *Service) deleteConnectionProfile(ctx context.Context, id influxdb.ID) error {
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		p, err := s.getConnectionProfile(tx, id)
+		if err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return ErrInvalidConnectionProfileID
+		}
+		b, err := tx.Bucket(connectionProfileBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		if err := b.Delete(encodedID); err != nil {
+			return ErrInternalService(err)
+		}
+		return s.deleteIndex(tx, p.OrgID, p.Name)
+	})
+}
+
+func (s *Service) checkNameAvailable(tx kv.Tx, orgID influxdb.ID, name string) error {
+	_, err := s.findByName(tx, orgID, name)
+	if err == nil {
+		return ErrConnectionProfileNameConflict
+	}
+	if err != ErrConnectionProfileNotFound {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) findByName(tx kv.Tx, orgID influxdb.ID, name string) (*influxdb.ConnectionProfile, error) {
+	key, err := indexKey(orgID, name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := tx.Bucket(indexBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(key)
+	if kv.IsNotFound(err) {
+		return nil, ErrConnectionProfileNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	var id influxdb.ID
+	if err := id.Decode(v); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return s.getConnectionProfile(tx, id)
+}
+
+func (s *Service) getConnectionProfile(tx kv.Tx, id influxdb.ID) (*influxdb.ConnectionProfile, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidConnectionProfileID
+	}
+
+	b, err := tx.Bucket(connectionProfileBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(encodedID)
+	if kv.IsNotFound(err) {
+		return nil, ErrConnectionProfileNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+
+	p := &influxdb.ConnectionProfile{}
+	if err := json.Unmarshal(v, p); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return p, nil
+}
+
+func (s *Service) putConnectionProfile(tx kv.Tx, p *influxdb.ConnectionProfile) error {
+	encodedID, err := p.ID.Encode()
+	if err != nil {
+		return ErrInvalidConnectionProfileID
+	}
+	v, err := json.Marshal(p)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+
+	b, err := tx.Bucket(connectionProfileBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(encodedID, v); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+func (s *Service) putIndex(tx kv.Tx, p *influxdb.ConnectionProfile) error {
+	key, err := indexKey(p.OrgID, p.Name)
+	if err != nil {
+		return err
+	}
+	encodedID, err := p.ID.Encode()
+	if err != nil {
+		return ErrInvalidConnectionProfileID
+	}
+	b, err := tx.Bucket(indexBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(key, encodedID); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+func (s *Service) deleteIndex(tx kv.Tx, orgID influxdb.ID, name string) error {
+	key, err := indexKey(orgID, name)
+	if err != nil {
+		return err
+	}
+	b, err := tx.Bucket(indexBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Delete(key); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+// indexKey returns the key of the index of the connection profile named name
+// in the organization.
+func indexKey(orgID influxdb.ID, name string) ([]byte, error) {
+	encodedOrgID, err := orgID.Encode()
+	if err != nil {
+		return nil, ErrInvalidConnectionProfile(err)
+	}
+	return append(encodedOrgID, name...), nil
+}
+
+func filterFunc(p *influxdb.ConnectionProfile, filter influxdb.ConnectionProfileFilter) bool {
+	return (filter.OrgID == nil || p.OrgID == *filter.OrgID) &&
+		(filter.Name == nil || p.Name == *filter.Name) &&
+		(filter.Kind == nil || p.Kind == *filter.Kind)
+}
+
+func paginate(profiles []*influxdb.ConnectionProfile, opt influxdb.FindOptions) []*influxdb.ConnectionProfile {
+	if opt.Offset > 0 {
+		if opt.Offset >= len(profiles) {
+			return []*influxdb.ConnectionProfile{}
+		}
+		profiles = profiles[opt.Offset:]
+	}
+	if opt.Limit > 0 && opt.Limit < len(profiles) {
+		profiles = profiles[:opt.Limit]
+	}
+	return profiles
+}
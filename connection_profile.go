@@ -0,0 +1,188 @@
+package influxdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ConnectionSecretPrefix prefixes the keys of the secrets holding the
+// credentials of connection profiles. The secrets are managed through their
+// profiles only.
+const ConnectionSecretPrefix = "connection-"
+
+// ConnectionKind is the kind of the external systems a connection profile
+// connects to.
+type ConnectionKind string
+
+const (
+	// ConnectionKindSQL profiles hold the data source name of a SQL
+	// database, passed to sql.from and sql.to.
+	ConnectionKindSQL ConnectionKind = "sql"
+	// ConnectionKindHTTP profiles hold the value of a header of HTTP
+	// requests, such as Authorization, passed to http.get and http.post.
+	ConnectionKindHTTP ConnectionKind = "http"
+)
+
+// Valid returns an error if the kind is unknown.
+func (k ConnectionKind) Valid() error {
+	switch k {
+	case ConnectionKindSQL, ConnectionKindHTTP:
+		return nil
+	default:
+		return fmt.Errorf("invalid kind %q, must be %q or %q", k, ConnectionKindSQL, ConnectionKindHTTP)
+	}
+}
+
+// ConnectionSQLDrivers are the SQL drivers whose data sources connection
+// profiles can hold.
+var ConnectionSQLDrivers = []string{"mssql", "mysql", "postgres", "snowflake", "sqlserver"}
+
+// ConnectionProfile holds the credentials Flux scripts use to connect to an
+// external system, so that they are stored as a secret rather than embedded
+// in the scripts. The credentials are only sent to the allowed hosts.
+type ConnectionProfile struct {
+	ID          ID             `json:"id,omitempty"`
+	OrgID       ID             `json:"orgID"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Kind        ConnectionKind `json:"kind"`
+	// Driver is the SQL driver of the data source of sql profiles.
+	Driver string `json:"driver,omitempty"`
+	// AllowedHosts are the hosts the credentials can be sent to. Hosts
+	// starting with "*." allow their subdomains.
+	AllowedHosts []string `json:"allowedHosts"`
+	CRUDLog
+}
+
+// SecretKey returns the key of the secret holding the credentials of the
+// profile, which scripts read with secrets.get.
+func (p *ConnectionProfile) SecretKey() string {
+	return ConnectionSecretPrefix + p.ID.String()
+}
+
+// ConnectionProfileIDFromSecretKey returns the ID of the profile whose
+// secret is stored at key, and false if key is not the key of the secret of
+// a profile.
+func ConnectionProfileIDFromSecretKey(key string) (ID, bool) {
+	if !strings.HasPrefix(key, ConnectionSecretPrefix) {
+		return 0, false
+	}
+	id, err := IDFromString(strings.TrimPrefix(key, ConnectionSecretPrefix))
+	if err != nil {
+		return 0, false
+	}
+	return *id, true
+}
+
+// AllowsHost returns true if the credentials of the profile can be sent to
+// host.
+func (p *ConnectionProfile) AllowsHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, h := range p.AllowedHosts {
+		h = strings.ToLower(h)
+		if h == host || (strings.HasPrefix(h, "*.") && strings.HasSuffix(host, h[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
+// Valid returns an error if the profile is missing required fields.
+func (p *ConnectionProfile) Valid() error {
+	if !p.OrgID.Valid() {
+		return errors.New("orgID is required")
+	}
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	if err := p.Kind.Valid(); err != nil {
+		return err
+	}
+	switch p.Kind {
+	case ConnectionKindSQL:
+		if !containsString(ConnectionSQLDrivers, p.Driver) {
+			return fmt.Errorf("invalid driver %q, must be one of %s", p.Driver, strings.Join(ConnectionSQLDrivers, ", "))
+		}
+	case ConnectionKindHTTP:
+		if p.Driver != "" {
+			return errors.New("driver is only allowed for sql profiles")
+		}
+	}
+	if len(p.AllowedHosts) == 0 {
+		return errors.New("allowedHosts is required")
+	}
+	for _, h := range p.AllowedHosts {
+		if h == "" || h == "*." || strings.ContainsAny(h, "/:@ ") {
+			return fmt.Errorf("invalid allowed host %q", h)
+		}
+	}
+	return nil
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ConnectionProfileFilter represents a set of filters that restrict the
+// returned connection profiles.
+type ConnectionProfileFilter struct {
+	OrgID *ID
+	Name  *string
+	Kind  *ConnectionKind
+}
+
+// ConnectionProfileUpdate is the changeset of a connection profile.
+type ConnectionProfileUpdate struct {
+	Name         *string   `json:"name,omitempty"`
+	Description  *string   `json:"description,omitempty"`
+	Driver       *string   `json:"driver,omitempty"`
+	AllowedHosts *[]string `json:"allowedHosts,omitempty"`
+	// Secret replaces the credentials of the profile.
+	Secret *string `json:"secret,omitempty"`
+}
+
+// Apply applies the changeset to p. The secret is stored separately.
+func (u ConnectionProfileUpdate) Apply(p *ConnectionProfile) {
+	if u.Name != nil {
+		p.Name = *u.Name
+	}
+	if u.Description != nil {
+		p.Description = *u.Description
+	}
+	if u.Driver != nil {
+		p.Driver = *u.Driver
+	}
+	if u.AllowedHosts != nil {
+		p.AllowedHosts = *u.AllowedHosts
+	}
+}
+
+// ConnectionProfileService stores the connection profiles of organizations
+// and their secrets.
+type ConnectionProfileService interface {
+	// CreateConnectionProfile creates a connection profile whose
+	// credentials are secret. Names are unique in an organization.
+	CreateConnectionProfile(ctx context.Context, p *ConnectionProfile, secret string) error
+
+	// FindConnectionProfileByID returns a single connection profile by ID.
+	FindConnectionProfileByID(ctx context.Context, id ID) (*ConnectionProfile, error)
+
+	// FindConnectionProfiles returns a list of connection profiles that
+	// match filter and the total count of matching profiles.
+	FindConnectionProfiles(ctx context.Context, filter ConnectionProfileFilter, opt ...FindOptions) ([]*ConnectionProfile, int, error)
+
+	// UpdateConnectionProfile updates a single connection profile with
+	// changeset.
+	UpdateConnectionProfile(ctx context.Context, id ID, upd ConnectionProfileUpdate) (*ConnectionProfile, error)
+
+	// DeleteConnectionProfile removes a single connection profile by ID,
+	// along with its secret.
+	DeleteConnectionProfile(ctx context.Context, id ID) error
+}
@@ -0,0 +1,18 @@
+package context
+
+import "context"
+
+const forceOwnerRemovalCtxKey contextKey = "influx/force-owner-removal/v1"
+
+// SetForceOwnerRemoval marks the request on context as removing owners even
+// if they are the last owner of their resource.
+func SetForceOwnerRemoval(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceOwnerRemovalCtxKey, true)
+}
+
+// ForceOwnerRemoval returns true if the request on context removes owners
+// even if they are the last owner of their resource.
+func ForceOwnerRemoval(ctx context.Context) bool {
+	force, _ := ctx.Value(forceOwnerRemovalCtxKey).(bool)
+	return force
+}
@@ -0,0 +1,404 @@
+// Package cq converts InfluxDB 1.x continuous queries into Flux tasks.
+//
+// A continuous query computes the aggregates of the GROUP BY time windows
+// that ended since its last run and writes them, stamped with the start of
+// their window, into its INTO measurement. The converted task runs on the
+// schedule of the query, reads the same range of windows and writes the
+// aggregates the same way.
+package cq
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxql"
+)
+
+// fluxAggregates maps the InfluxQL functions supported in continuous queries
+// to the Flux functions computing them.
+var fluxAggregates = map[string]string{
+	"count":  "count",
+	"first":  "first",
+	"last":   "last",
+	"max":    "max",
+	"mean":   "mean",
+	"median": "median",
+	"min":    "min",
+	"mode":   "mode",
+	"spread": "spread",
+	"stddev": "stddev",
+	"sum":    "sum",
+}
+
+// selectors are the functions that depend on the order of the points.
+var selectors = map[string]bool{
+	"first": true,
+	"last":  true,
+}
+
+// BucketResolver returns the bucket a database and retention policy are
+// mapped to. An empty retention policy selects the default one.
+type BucketResolver func(ctx context.Context, database, retentionPolicy string) (influxdb.ID, error)
+
+// Converted is a continuous query converted into a Flux task.
+type Converted struct {
+	Name     string
+	Database string
+	Flux     string
+}
+
+// Convert converts the CREATE CONTINUOUS QUERY statement q into the Flux of
+// a task of the organization orgID.
+func Convert(ctx context.Context, q string, orgID influxdb.ID, resolve BucketResolver) (*Converted, error) {
+	stmt, err := influxql.ParseStatement(q)
+	if err != nil {
+		return nil, ErrInvalidQuery(err)
+	}
+	cq, ok := stmt.(*influxql.CreateContinuousQueryStatement)
+	if !ok {
+		return nil, ErrNotContinuousQuery
+	}
+
+	c := &converter{cq: cq, orgID: orgID, resolve: resolve}
+	flux, err := c.convert(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Converted{Name: cq.Name, Database: cq.Database, Flux: flux}, nil
+}
+
+type converter struct {
+	cq      *influxql.CreateContinuousQueryStatement
+	orgID   influxdb.ID
+	resolve BucketResolver
+
+	interval time.Duration
+	offset   time.Duration
+	// tags are the tags of the GROUP BY clause. They are nil if the query
+	// groups by all tags.
+	tags []string
+}
+
+func (c *converter) convert(ctx context.Context) (string, error) {
+	sel := c.cq.Source
+	if err := c.parseDimensions(sel.Dimensions); err != nil {
+		return "", err
+	}
+	if sel.Fill != influxql.NullFill && sel.Fill != influxql.NoFill {
+		return "", ErrUnsupported("fill options other than fill(null) and fill(none)")
+	}
+
+	source, err := c.source(ctx, sel.Sources)
+	if err != nil {
+		return "", err
+	}
+	target, err := c.target(ctx, sel.Target)
+	if err != nil {
+		return "", err
+	}
+	where, err := c.predicate(sel.Condition)
+	if err != nil {
+		return "", err
+	}
+	fields, err := c.fields(sel.Fields)
+	if err != nil {
+		return "", err
+	}
+
+	every, period := c.interval, c.interval
+	if c.cq.ResampleEvery > 0 {
+		every = c.cq.ResampleEvery
+	}
+	if c.cq.ResampleFor > 0 {
+		period = c.cq.ResampleFor
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "option task = {name: %s, every: %s", fluxString(c.cq.Name), fluxDuration(every))
+	if c.offset != 0 {
+		// The windows of the query end at the offset past the schedule.
+		fmt.Fprintf(&b, ", offset: %s", fluxDuration(c.offset))
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "data = from(bucketID: %s)\n", fluxString(source.bucketID.String()))
+	fmt.Fprintf(&b, "\t|> range(start: %s", fluxDuration(c.offset-period))
+	if c.offset != 0 {
+		fmt.Fprintf(&b, ", stop: %s", fluxDuration(c.offset))
+	}
+	b.WriteString(")\n")
+	fmt.Fprintf(&b, "\t|> filter(fn: (r) => r._measurement == %s", fluxString(source.measurement))
+	if where != "" {
+		fmt.Fprintf(&b, " and (%s)", where)
+	}
+	b.WriteString(")\n")
+
+	for _, f := range fields {
+		b.WriteString("\ndata\n")
+		if f.field != "" {
+			fmt.Fprintf(&b, "\t|> filter(fn: (r) => r._field == %s)\n", fluxString(f.field))
+		}
+		if c.tags != nil {
+			cols := append([]string{"_measurement", "_field"}, c.tags...)
+			quoted := make([]string, len(cols))
+			for i, col := range cols {
+				quoted[i] = fluxString(col)
+			}
+			fmt.Fprintf(&b, "\t|> group(columns: [%s])\n", strings.Join(quoted, ", "))
+			if selectors[f.fn] {
+				b.WriteString("\t|> sort(columns: [\"_time\"])\n")
+			}
+		}
+		fmt.Fprintf(&b, "\t|> window(every: %s", fluxDuration(c.interval))
+		if c.offset != 0 {
+			fmt.Fprintf(&b, ", offset: %s", fluxDuration(c.offset))
+		}
+		b.WriteString(", createEmpty: false)\n")
+		fmt.Fprintf(&b, "\t|> %s()\n", fluxAggregates[f.fn])
+		b.WriteString("\t|> duplicate(column: \"_start\", as: \"_time\")\n")
+		b.WriteString("\t|> window(every: inf)\n")
+		fmt.Fprintf(&b, "\t|> set(key: \"_measurement\", value: %s)\n", fluxString(target.measurement))
+		if f.field != "" {
+			fmt.Fprintf(&b, "\t|> set(key: \"_field\", value: %s)\n", fluxString(f.name))
+		} else {
+			fmt.Fprintf(&b, "\t|> map(fn: (r) => ({r with _field: %s + r._field}))\n", fluxString(f.name+"_"))
+		}
+		fmt.Fprintf(&b, "\t|> to(bucketID: %s, orgID: %s)\n", fluxString(target.bucketID.String()), fluxString(c.orgID.String()))
+	}
+	return b.String(), nil
+}
+
+// parseDimensions reads the GROUP BY clause. Continuous queries must group
+// by time.
+func (c *converter) parseDimensions(dims influxql.Dimensions) error {
+	var tags []string
+	all := false
+	seen := make(map[string]bool)
+	for _, d := range dims {
+		switch expr := influxql.Reduce(d.Expr, nil).(type) {
+		case *influxql.Wildcard:
+			all = true
+		case *influxql.VarRef:
+			if !seen[expr.Val] {
+				tags = append(tags, expr.Val)
+				seen[expr.Val] = true
+			}
+		case *influxql.Call:
+			if expr.Name != "time" || len(expr.Args) < 1 || len(expr.Args) > 2 {
+				return ErrUnsupported(fmt.Sprintf("GROUP BY %s", expr))
+			}
+			if c.interval != 0 {
+				return ErrInvalidQuery(fmt.Errorf("multiple GROUP BY time() dimensions"))
+			}
+			lit, ok := expr.Args[0].(*influxql.DurationLiteral)
+			if !ok || lit.Val <= 0 {
+				return ErrInvalidQuery(fmt.Errorf("GROUP BY time() requires a positive duration"))
+			}
+			c.interval = lit.Val
+			if len(expr.Args) == 2 {
+				switch off := expr.Args[1].(type) {
+				case *influxql.DurationLiteral:
+					c.offset = off.Val % c.interval
+				case *influxql.TimeLiteral:
+					c.offset = off.Val.Sub(off.Val.Truncate(c.interval))
+				default:
+					return ErrInvalidQuery(fmt.Errorf("GROUP BY time() offset must be a duration or a time"))
+				}
+				if c.offset < 0 {
+					c.offset += c.interval
+				}
+			}
+		default:
+			return ErrUnsupported(fmt.Sprintf("GROUP BY %s", expr))
+		}
+	}
+	if c.interval == 0 {
+		return ErrInvalidQuery(fmt.Errorf("continuous queries require a GROUP BY time() clause"))
+	}
+	if !all {
+		if tags == nil {
+			tags = []string{}
+		}
+		sort.Strings(tags)
+		c.tags = tags
+	}
+	return nil
+}
+
+type measurement struct {
+	bucketID    influxdb.ID
+	measurement string
+}
+
+func (c *converter) source(ctx context.Context, sources influxql.Sources) (*measurement, error) {
+	if len(sources) != 1 {
+		return nil, ErrUnsupported("continuous queries reading several measurements")
+	}
+	m, ok := sources[0].(*influxql.Measurement)
+	if !ok || m.Regex != nil || m.Name == "" {
+		return nil, ErrUnsupported("continuous queries reading subqueries or regular expressions of measurements")
+	}
+	return c.measurement(ctx, m)
+}
+
+func (c *converter) target(ctx context.Context, target *influxql.Target) (*measurement, error) {
+	if target == nil || target.Measurement == nil {
+		return nil, ErrInvalidQuery(fmt.Errorf("continuous queries require an INTO clause"))
+	}
+	if target.Measurement.Name == "" {
+		return nil, ErrUnsupported("INTO :MEASUREMENT back references")
+	}
+	return c.measurement(ctx, target.Measurement)
+}
+
+func (c *converter) measurement(ctx context.Context, m *influxql.Measurement) (*measurement, error) {
+	db := m.Database
+	if db == "" {
+		db = c.cq.Database
+	}
+	bucketID, err := c.resolve(ctx, db, m.RetentionPolicy)
+	if err != nil {
+		return nil, err
+	}
+	return &measurement{bucketID: bucketID, measurement: m.Name}, nil
+}
+
+// predicate converts the WHERE clause into the body of a Flux predicate.
+// Only comparisons of tags are supported.
+func (c *converter) predicate(expr influxql.Expr) (string, error) {
+	if expr == nil {
+		return "", nil
+	}
+	switch expr := expr.(type) {
+	case *influxql.ParenExpr:
+		s, err := c.predicate(expr.Expr)
+		if err != nil {
+			return "", err
+		}
+		return "(" + s + ")", nil
+	case *influxql.BinaryExpr:
+		switch expr.Op {
+		case influxql.AND, influxql.OR:
+			lhs, err := c.predicate(expr.LHS)
+			if err != nil {
+				return "", err
+			}
+			rhs, err := c.predicate(expr.RHS)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%s %s %s", lhs, strings.ToLower(expr.Op.String()), rhs), nil
+		case influxql.EQ, influxql.NEQ, influxql.EQREGEX, influxql.NEQREGEX:
+			ref, ok := expr.LHS.(*influxql.VarRef)
+			if !ok {
+				break
+			}
+			col := "r[" + fluxString(ref.Val) + "]"
+			switch rhs := expr.RHS.(type) {
+			case *influxql.StringLiteral:
+				if expr.Op == influxql.EQ {
+					return col + " == " + fluxString(rhs.Val), nil
+				} else if expr.Op == influxql.NEQ {
+					return col + " != " + fluxString(rhs.Val), nil
+				}
+			case *influxql.RegexLiteral:
+				re := "/" + strings.ReplaceAll(rhs.Val.String(), "/", `\/`) + "/"
+				if expr.Op == influxql.EQREGEX {
+					return col + " =~ " + re, nil
+				} else if expr.Op == influxql.NEQREGEX {
+					return col + " !~ " + re, nil
+				}
+			}
+		}
+	}
+	return "", ErrUnsupported(fmt.Sprintf("WHERE %s", expr))
+}
+
+type field struct {
+	fn string
+	// field is the field aggregated by fn. It is empty for fn(*).
+	field string
+	// name is the field the aggregate is written to, or the prefix of the
+	// fields for fn(*).
+	name string
+}
+
+func (c *converter) fields(fields influxql.Fields) ([]field, error) {
+	var fs []field
+	names := make(map[string]int)
+	for _, f := range fields {
+		call, ok := f.Expr.(*influxql.Call)
+		if !ok || len(call.Args) != 1 {
+			return nil, ErrUnsupported(fmt.Sprintf("SELECT %s", f.Expr))
+		}
+		fn := strings.ToLower(call.Name)
+		if _, ok := fluxAggregates[fn]; !ok {
+			return nil, ErrUnsupported(fmt.Sprintf("the %s() function", call.Name))
+		}
+
+		cf := field{fn: fn, name: f.Alias}
+		switch arg := call.Args[0].(type) {
+		case *influxql.VarRef:
+			cf.field = arg.Val
+		case *influxql.Wildcard:
+		default:
+			return nil, ErrUnsupported(fmt.Sprintf("SELECT %s", f.Expr))
+		}
+
+		// Like InfluxQL, name the columns without an alias after their
+		// function and deduplicate them with a suffix.
+		if cf.name == "" {
+			cf.name = fn
+			if n := names[fn]; n > 0 {
+				cf.name = fn + "_" + strconv.Itoa(n)
+			}
+			names[fn]++
+		}
+		fs = append(fs, cf)
+	}
+	if len(fs) == 0 {
+		return nil, ErrInvalidQuery(fmt.Errorf("continuous queries require an aggregate"))
+	}
+	return fs, nil
+}
+
+// fluxString quotes s as a Flux string literal.
+func fluxString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "${", `\${`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// fluxDuration formats d as a Flux duration literal.
+func fluxDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+	for _, u := range []struct {
+		unit string
+		d    time.Duration
+	}{
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+		{"ms", time.Millisecond},
+		{"us", time.Microsecond},
+		{"ns", time.Nanosecond},
+	} {
+		if n := d / u.d; n > 0 {
+			b.WriteString(strconv.FormatInt(int64(n), 10))
+			b.WriteString(u.unit)
+			d -= n * u.d
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,141 @@
+package cq_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/cq"
+)
+
+// resolve maps the default retention policy of the telegraf database to
+// bucket 1 and its yearly retention policy to bucket 2.
+func resolve(_ context.Context, db, rp string) (influxdb.ID, error) {
+	switch {
+	case db == "telegraf" && rp == "":
+		return 1, nil
+	case db == "telegraf" && rp == "yearly":
+		return 2, nil
+	}
+	return 0, cq.ErrNoBucket(db, rp)
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		flux  string
+	}{
+		{
+			name: "aggregates grouped by tags",
+			query: `CREATE CONTINUOUS QUERY cq_1h ON telegraf BEGIN
+				SELECT mean(usage_idle) AS usage_idle, max(usage_idle)
+				INTO telegraf.yearly.cpu_1h FROM cpu
+				WHERE cpu = 'cpu-total'
+				GROUP BY time(1h), host
+			END`,
+			flux: `option task = {name: "cq_1h", every: 1h}
+
+data = from(bucketID: "0000000000000001")
+	|> range(start: -1h)
+	|> filter(fn: (r) => r._measurement == "cpu" and (r["cpu"] == "cpu-total"))
+
+data
+	|> filter(fn: (r) => r._field == "usage_idle")
+	|> group(columns: ["_measurement", "_field", "host"])
+	|> window(every: 1h, createEmpty: false)
+	|> mean()
+	|> duplicate(column: "_start", as: "_time")
+	|> window(every: inf)
+	|> set(key: "_measurement", value: "cpu_1h")
+	|> set(key: "_field", value: "usage_idle")
+	|> to(bucketID: "0000000000000002", orgID: "0000000000000010")
+
+data
+	|> filter(fn: (r) => r._field == "usage_idle")
+	|> group(columns: ["_measurement", "_field", "host"])
+	|> window(every: 1h, createEmpty: false)
+	|> max()
+	|> duplicate(column: "_start", as: "_time")
+	|> window(every: inf)
+	|> set(key: "_measurement", value: "cpu_1h")
+	|> set(key: "_field", value: "max")
+	|> to(bucketID: "0000000000000002", orgID: "0000000000000010")
+`,
+		},
+		{
+			name: "resampled wildcard with offset",
+			query: `CREATE CONTINUOUS QUERY cq_all ON telegraf RESAMPLE EVERY 30m FOR 2h BEGIN
+				SELECT mean(*) INTO mem_1h FROM mem GROUP BY time(1h, 15m), *
+			END`,
+			flux: `option task = {name: "cq_all", every: 30m, offset: 15m}
+
+data = from(bucketID: "0000000000000001")
+	|> range(start: -1h45m, stop: 15m)
+	|> filter(fn: (r) => r._measurement == "mem")
+
+data
+	|> window(every: 1h, offset: 15m, createEmpty: false)
+	|> mean()
+	|> duplicate(column: "_start", as: "_time")
+	|> window(every: inf)
+	|> set(key: "_measurement", value: "mem_1h")
+	|> map(fn: (r) => ({r with _field: "mean_" + r._field}))
+	|> to(bucketID: "0000000000000001", orgID: "0000000000000010")
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := cq.Convert(context.Background(), tt.query, 0x10, resolve)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.flux, c.Flux); diff != "" {
+				t.Errorf("unexpected flux (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConvert_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{
+			name:  "not a continuous query",
+			query: `SELECT mean(usage_idle) FROM cpu`,
+			code:  influxdb.EInvalid,
+		},
+		{
+			name:  "missing group by time",
+			query: `CREATE CONTINUOUS QUERY cq ON telegraf BEGIN SELECT mean(usage_idle) INTO cpu_1h FROM cpu GROUP BY host END`,
+			code:  influxdb.EInvalid,
+		},
+		{
+			name:  "unsupported function",
+			query: `CREATE CONTINUOUS QUERY cq ON telegraf BEGIN SELECT percentile(usage_idle, 95) INTO cpu_1h FROM cpu GROUP BY time(1h) END`,
+			code:  influxdb.EInvalid,
+		},
+		{
+			name:  "unsupported fill",
+			query: `CREATE CONTINUOUS QUERY cq ON telegraf BEGIN SELECT mean(usage_idle) INTO cpu_1h FROM cpu GROUP BY time(1h) fill(0) END`,
+			code:  influxdb.EInvalid,
+		},
+		{
+			name:  "unmapped database",
+			query: `CREATE CONTINUOUS QUERY cq ON other BEGIN SELECT mean(usage_idle) INTO cpu_1h FROM cpu GROUP BY time(1h) END`,
+			code:  influxdb.ENotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := cq.Convert(context.Background(), tt.query, 0x10, resolve); influxdb.ErrorCode(err) != tt.code {
+				t.Fatalf("expected %s error, got %v", tt.code, err)
+			}
+		})
+	}
+}
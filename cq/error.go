@@ -0,0 +1,48 @@
+package cq
+
+import (
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrNotContinuousQuery is used when a statement to convert is not a
+	// CREATE CONTINUOUS QUERY statement.
+	ErrNotContinuousQuery = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "statement is not a CREATE CONTINUOUS QUERY statement",
+	}
+)
+
+// ErrInvalidQuery is used when a continuous query cannot be parsed or is not
+// a valid continuous query.
+func ErrInvalidQuery(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "continuous query is invalid",
+		Err:  err,
+	}
+}
+
+// ErrUnsupported is used when a continuous query uses a feature that has no
+// equivalent in the converted tasks.
+func ErrUnsupported(feature string) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "continuous queries with " + feature + " cannot be converted",
+	}
+}
+
+// ErrNoBucket is used when the database and retention policy of a
+// continuous query are not mapped to a bucket.
+func ErrNoBucket(db, rp string) *influxdb.Error {
+	msg := "no bucket is mapped to database " + db
+	if rp == "" {
+		msg += " and its default retention policy"
+	} else {
+		msg += " and retention policy " + rp
+	}
+	return &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  msg,
+	}
+}
@@ -0,0 +1,103 @@
+package cq
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixContinuousQueries is the prefix of the continuous query API.
+	PrefixContinuousQueries = "/api/v2/continuousQueries"
+)
+
+// Handler is the HTTP API handler for the import of continuous queries.
+type Handler struct {
+	chi.Router
+	api *kithttp.API
+	log *zap.Logger
+	svc *Service
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, svc *Service) *Handler {
+	h := &Handler{
+		api: kithttp.NewAPI(kithttp.WithLog(log)),
+		log: log,
+		svc: svc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Post("/import", h.handlePostImport)
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixContinuousQueries
+}
+
+type postImportRequest struct {
+	OrgID   influxdb.ID         `json:"orgID"`
+	Queries []string            `json:"queries"`
+	Status  influxdb.TaskStatus `json:"status"`
+	DryRun  bool                `json:"dryRun"`
+}
+
+type importResponse struct {
+	Results []*ImportResult `json:"results"`
+}
+
+func (h *Handler) handlePostImport(w http.ResponseWriter, r *http.Request) {
+	var req postImportRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if !req.OrgID.Valid() {
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID is required",
+		})
+		return
+	}
+	if len(req.Queries) == 0 {
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "at least one continuous query is required",
+		})
+		return
+	}
+	switch req.Status {
+	case "":
+		req.Status = influxdb.DefaultTaskStatus
+	case influxdb.TaskActive, influxdb.TaskInactive:
+	default:
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "status must be active or inactive",
+		})
+		return
+	}
+
+	results, err := h.svc.Import(r.Context(), req.OrgID, req.Queries, req.Status, req.DryRun)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Continuous queries imported", zap.Int("queries", len(results)), zap.Bool("dryRun", req.DryRun))
+
+	h.api.Respond(w, r, http.StatusOK, importResponse{Results: results})
+}
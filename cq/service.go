@@ -0,0 +1,98 @@
+package cq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+)
+
+// ImportResult is the outcome of the import of one continuous query.
+type ImportResult struct {
+	Query string         `json:"query"`
+	Name  string         `json:"name,omitempty"`
+	Flux  string         `json:"flux,omitempty"`
+	Task  *influxdb.Task `json:"task,omitempty"`
+	Error string         `json:"error,omitempty"`
+}
+
+// Service imports continuous queries as tasks.
+type Service struct {
+	taskSvc influxdb.TaskService
+	dbrpSvc influxdb.DBRPMappingServiceV2
+}
+
+// NewService constructs a service creating the tasks with taskSvc. The
+// databases and retention policies of the queries are resolved to buckets
+// with dbrpSvc.
+func NewService(taskSvc influxdb.TaskService, dbrpSvc influxdb.DBRPMappingServiceV2) *Service {
+	return &Service{
+		taskSvc: taskSvc,
+		dbrpSvc: dbrpSvc,
+	}
+}
+
+// Import converts the continuous queries into tasks of the organization and
+// creates them with status, unless dryRun is set. A query that cannot be
+// converted or created does not prevent the import of the others; its error
+// is reported in its result.
+func (s *Service) Import(ctx context.Context, orgID influxdb.ID, queries []string, status influxdb.TaskStatus, dryRun bool) ([]*ImportResult, error) {
+	auth, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resolve := func(ctx context.Context, db, rp string) (influxdb.ID, error) {
+		return s.findBucket(ctx, orgID, db, rp)
+	}
+
+	results := make([]*ImportResult, 0, len(queries))
+	for _, q := range queries {
+		r := &ImportResult{Query: q}
+		results = append(results, r)
+
+		c, err := Convert(ctx, q, orgID, resolve)
+		if err != nil {
+			r.Error = err.Error()
+			continue
+		}
+		r.Name, r.Flux = c.Name, c.Flux
+		if dryRun {
+			continue
+		}
+
+		t, err := s.taskSvc.CreateTask(ctx, influxdb.TaskCreate{
+			Flux:           c.Flux,
+			Description:    fmt.Sprintf("Imported from continuous query %s on %s", c.Name, c.Database),
+			Status:         string(status),
+			OrganizationID: orgID,
+			OwnerID:        auth.GetUserID(),
+		})
+		if err != nil {
+			r.Error = err.Error()
+			continue
+		}
+		r.Task = t
+	}
+	return results, nil
+}
+
+func (s *Service) findBucket(ctx context.Context, orgID influxdb.ID, db, rp string) (influxdb.ID, error) {
+	filter := influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db}
+	if rp != "" {
+		filter.RetentionPolicy = &rp
+	} else {
+		def := true
+		filter.Default = &def
+	}
+
+	mappings, _, err := s.dbrpSvc.FindMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	if len(mappings) == 0 {
+		return 0, ErrNoBucket(db, rp)
+	}
+	return mappings[0].BucketID, nil
+}
@@ -79,6 +79,9 @@ type Dashboard struct {
 	Description    string        `json:"description"`
 	Cells          []*Cell       `json:"cells"`
 	Meta           DashboardMeta `json:"meta"`
+	// Restricted dashboards are only accessible to their members, who can
+	// read them, and their owners, who can edit them, instead of to their whole organization.
+	Restricted bool `json:"restricted,omitempty"`
 }
 
 // DashboardMeta contains meta information about dashboards
@@ -124,7 +127,10 @@ func SortDashboards(opts FindOptions, ds []*Dashboard) {
 type Cell struct {
 	ID ID `json:"id,omitempty"`
 	CellProperty
-	View *View `json:"-"`
+	// Transforms are applied in order to the results of the queries of the
+	// view of the cell when it is queried through the cell.
+	Transforms []CellTransform `json:"transforms,omitempty"`
+	View       *View           `json:"-"`
 }
 
 // Marshals the cell
@@ -133,10 +139,12 @@ func (c *Cell) MarshalJSON() ([]byte, error) {
 		ID             *ID             `json:"id,omitempty"`
 		Name           string          `json:"name,omitempty"`
 		ViewProperties json.RawMessage `json:"properties,omitempty"`
+		Transforms     []CellTransform `json:"transforms,omitempty"`
 		CellProperty
 	}
 	response := resp{
 		CellProperty: c.CellProperty,
+		Transforms:   c.Transforms,
 	}
 	if c.ID != 0 {
 		response.ID = &c.ID
@@ -157,6 +165,7 @@ func (c *Cell) UnmarshalJSON(b []byte) error {
 		ID             ID              `json:"id,omitempty"`
 		Name           string          `json:"name,omitempty"`
 		ViewProperties json.RawMessage `json:"properties,omitempty"`
+		Transforms     []CellTransform `json:"transforms,omitempty"`
 		CellProperty
 	}
 	if err := json.Unmarshal(b, &newCell); err != nil {
@@ -165,6 +174,7 @@ func (c *Cell) UnmarshalJSON(b []byte) error {
 
 	c.ID = newCell.ID
 	c.CellProperty = newCell.CellProperty
+	c.Transforms = newCell.Transforms
 
 	if newCell.Name != "" {
 		if c.View == nil {
@@ -192,6 +202,89 @@ type CellProperty struct {
 	H int32 `json:"h"`
 }
 
+// Types of cell transforms.
+const (
+	CellTransformRename = "rename"
+	CellTransformUnit   = "unit"
+	CellTransformTopN   = "topN"
+)
+
+// DefaultCellTransformColumn is the column converted or ranked by the
+// transforms that do not name one.
+const DefaultCellTransformColumn = "_value"
+
+// CellTransform is a transform of the results of the queries of a cell,
+// evaluated by the server after the queries, so that a cell can tweak
+// results without the Flux of its queries being edited.
+type CellTransform struct {
+	Type string `json:"type"`
+	// Columns maps the columns renamed by a rename transform to their new
+	// names.
+	Columns map[string]string `json:"columns,omitempty"`
+	// Column is the column converted by a unit transform or ranked by a
+	// topN transform.
+	Column string `json:"column,omitempty"`
+	// Factor and Offset convert the values of a unit transform to
+	// value*Factor + Offset.
+	Factor float64 `json:"factor,omitempty"`
+	Offset float64 `json:"offset,omitempty"`
+	// N is the number of rows kept by a topN transform, the lowest ones
+	// when Bottom is true.
+	N      int64 `json:"n,omitempty"`
+	Bottom bool  `json:"bottom,omitempty"`
+}
+
+// Valid returns an error if the transform is invalid.
+func (t CellTransform) Valid() *Error {
+	switch t.Type {
+	case CellTransformRename:
+		if len(t.Columns) == 0 {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "rename transform must rename at least one column",
+			}
+		}
+		for from, to := range t.Columns {
+			if from == "" || to == "" {
+				return &Error{
+					Code: EInvalid,
+					Msg:  "rename transform column names must not be empty",
+				}
+			}
+		}
+	case CellTransformUnit:
+		if t.Factor == 0 {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "unit transform factor must not be zero",
+			}
+		}
+	case CellTransformTopN:
+		if t.N <= 0 {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "topN transform n must be greater than zero",
+			}
+		}
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("invalid cell transform type %q", t.Type),
+		}
+	}
+	return nil
+}
+
+// ValidCellTransforms returns an error if any of the transforms is invalid.
+func ValidCellTransforms(ts []CellTransform) *Error {
+	for _, t := range ts {
+		if err := t.Valid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // DashboardFilter is a filter for dashboards.
 type DashboardFilter struct {
 	IDs            []*ID
@@ -226,6 +319,7 @@ type DashboardUpdate struct {
 	Name        *string  `json:"name"`
 	Description *string  `json:"description"`
 	Cells       *[]*Cell `json:"cells"`
+	Restricted  *bool    `json:"restricted"`
 }
 
 // Apply applies an update to a dashboard.
@@ -242,12 +336,16 @@ func (u DashboardUpdate) Apply(d *Dashboard) error {
 		d.Cells = *u.Cells
 	}
 
+	if u.Restricted != nil {
+		d.Restricted = *u.Restricted
+	}
+
 	return nil
 }
 
 // Valid returns an error if the dashboard update is invalid.
 func (u DashboardUpdate) Valid() *Error {
-	if u.Name == nil && u.Description == nil {
+	if u.Name == nil && u.Description == nil && u.Restricted == nil {
 		return &Error{
 			Code: EInvalid,
 			Msg:  "must update at least one attribute",
@@ -268,6 +366,9 @@ type CellUpdate struct {
 	Y *int32 `json:"y"`
 	W *int32 `json:"w"`
 	H *int32 `json:"h"`
+	// Transforms replace the transforms of the cell, an empty list
+	// removes them.
+	Transforms []CellTransform `json:"transforms"`
 }
 
 // Apply applies an update to a Cell.
@@ -288,19 +389,26 @@ func (u CellUpdate) Apply(c *Cell) error {
 		c.H = *u.H
 	}
 
+	if u.Transforms != nil {
+		c.Transforms = nil
+		if len(u.Transforms) > 0 {
+			c.Transforms = u.Transforms
+		}
+	}
+
 	return nil
 }
 
 // Valid returns an error if the cell update is invalid.
 func (u CellUpdate) Valid() *Error {
-	if u.H == nil && u.W == nil && u.Y == nil && u.X == nil {
+	if u.H == nil && u.W == nil && u.Y == nil && u.X == nil && u.Transforms == nil {
 		return &Error{
 			Code: EInvalid,
 			Msg:  "must update at least one attribute",
 		}
 	}
 
-	return nil
+	return ValidCellTransforms(u.Transforms)
 }
 
 // ViewUpdate is a struct for updating Views.
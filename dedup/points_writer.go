@@ -0,0 +1,338 @@
+// Package dedup drops the exact duplicates of the points written to buckets
+// with a dedup window, whichever path they take to the storage engine: the
+// API, tasks, ingestion or replication.
+//
+// A point is a duplicate of a point written to the same bucket within the
+// dedup window of the bucket when their series, field values and timestamp
+// are the same. The points written are tracked in memory, up to a maximum
+// shared by all the buckets: the duplicates of the points written before a
+// restart are written.
+package dedup
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultMaxPoints is the default maximum number of points remembered for
+// all the buckets.
+const DefaultMaxPoints = 1000000
+
+// sweepInterval is how often the points of the buckets that are not written
+// to are expired.
+const sweepInterval = time.Minute
+
+// BucketFinder finds the buckets of the points written.
+type BucketFinder interface {
+	FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error)
+}
+
+// key identifies a point by a 128-bit hash of its line protocol, which
+// includes its bucket, series, field values and timestamp.
+type key [16]byte
+
+type entry struct {
+	key     key
+	expires int64
+}
+
+// bucketPoints holds the points written to a bucket within its dedup window,
+// in the order of their writes.
+type bucketPoints struct {
+	mu    sync.Mutex
+	seen  map[key]int64
+	queue []entry
+	// head is the index of the oldest point of the queue.
+	head int
+}
+
+// expire forgets the points written before now minus the dedup window, and
+// the oldest points above max. It returns the number of points forgotten.
+// b.mu must be held.
+func (b *bucketPoints) expire(now int64, max int) int {
+	n := len(b.seen)
+	for b.head < len(b.queue) && (b.queue[b.head].expires <= now || len(b.seen) > max) {
+		e := b.queue[b.head]
+		if b.seen[e.key] == e.expires {
+			delete(b.seen, e.key)
+		}
+		b.head++
+	}
+	switch {
+	case len(b.seen) == 0:
+		// Release the memory of the buckets no longer written to.
+		b.seen = make(map[key]int64)
+		b.queue = nil
+		b.head = 0
+	case b.head > len(b.queue)/2:
+		// Reclaim the space of the forgotten points once they are most of
+		// the queue.
+		b.queue = append(b.queue[:0], b.queue[b.head:]...)
+		b.head = 0
+	}
+	return n - len(b.seen)
+}
+
+var _ storage.PointsWriter = (*PointsWriter)(nil)
+
+// PointsWriter writes points with an underlying points writer, dropping the
+// exact duplicates of the points written to the same bucket within the dedup
+// window of the bucket. A point is only remembered once its write succeeds,
+// so that a failed write can be retried.
+type PointsWriter struct {
+	next      storage.PointsWriter
+	buckets   BucketFinder
+	maxPoints int
+	now       func() time.Time
+
+	mu        sync.Mutex
+	byID      map[influxdb.ID]*bucketPoints
+	size      int // the number of points remembered.
+	lastSweep time.Time
+
+	points  prometheus.Gauge
+	dropped prometheus.Counter
+}
+
+// NewPointsWriter wraps next so that the duplicates of the points written to
+// buckets with a dedup window are dropped. At most maxPoints points are
+// remembered, DefaultMaxPoints if it is not positive; once reached, the
+// buckets forget their oldest points before the end of their dedup window,
+// down to an equal share of maxPoints.
+func NewPointsWriter(next storage.PointsWriter, buckets BucketFinder, maxPoints int) *PointsWriter {
+	if maxPoints <= 0 {
+		maxPoints = DefaultMaxPoints
+	}
+	return &PointsWriter{
+		next:      next,
+		buckets:   buckets,
+		maxPoints: maxPoints,
+		now:       time.Now,
+		byID:      make(map[influxdb.ID]*bucketPoints),
+		lastSweep: time.Now(),
+		points: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "storage",
+			Subsystem: "dedup",
+			Name:      "points",
+			Help:      "Number of points remembered to drop their duplicates.",
+		}),
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "storage",
+			Subsystem: "dedup",
+			Name:      "dropped_total",
+			Help:      "Number of duplicate points not written.",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (w *PointsWriter) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{w.points, w.dropped}
+}
+
+// WritePoints writes the points that are not duplicates of points written
+// within the dedup windows of their buckets, nor of earlier points of the
+// same write.
+func (w *PointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	windows, err := w.dedupWindows(ctx, points)
+	if err != nil {
+		return err
+	}
+	if len(windows) == 0 {
+		return w.next.WritePoints(ctx, points)
+	}
+
+	now := w.now()
+	w.sweep(now)
+
+	// The points are hashed before any lock is held, and their buckets are
+	// locked once per write.
+	var (
+		keys     = make([]key, len(points))
+		byBucket = make(map[*bucketPoints][]int)
+		windowOf = make(map[*bucketPoints]time.Duration)
+		buf      []byte
+	)
+	for i, p := range points {
+		b, ok := windows[string(p.Name())]
+		if !ok {
+			continue
+		}
+		buf = p.AppendString(buf[:0])
+		h := fnv.New128a()
+		h.Write(buf)
+		h.Sum(keys[i][:0])
+
+		bp := w.bucketPoints(b.ID)
+		byBucket[bp] = append(byBucket[bp], i)
+		windowOf[bp] = b.DedupWindow
+	}
+
+	var (
+		nanos   = now.UnixNano()
+		drop    = make([]bool, len(points))
+		pending = make(map[*bucketPoints][]entry, len(byBucket))
+		dropped int
+	)
+	for bp, indexes := range byBucket {
+		inWrite := make(map[key]bool, len(indexes))
+		expires := nanos + int64(windowOf[bp])
+		bp.mu.Lock()
+		for _, i := range indexes {
+			k := keys[i]
+			if exp, ok := bp.seen[k]; inWrite[k] || ok && exp > nanos {
+				drop[i] = true
+				dropped++
+				continue
+			}
+			inWrite[k] = true
+			pending[bp] = append(pending[bp], entry{key: k, expires: expires})
+		}
+		bp.mu.Unlock()
+	}
+
+	kept := points
+	if dropped > 0 {
+		w.dropped.Add(float64(dropped))
+		kept = make([]models.Point, 0, len(points)-dropped)
+		for i, p := range points {
+			if !drop[i] {
+				kept = append(kept, p)
+			}
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	if err := w.next.WritePoints(ctx, kept); err != nil {
+		return err
+	}
+	w.commit(nanos, pending)
+	return nil
+}
+
+// dedupWindows returns the buckets with a dedup window of the points, by the
+// encoded name of the points.
+func (w *PointsWriter) dedupWindows(ctx context.Context, points []models.Point) (map[string]*influxdb.Bucket, error) {
+	var (
+		checked = make(map[string]bool)
+		windows map[string]*influxdb.Bucket
+	)
+	for _, p := range points {
+		name := string(p.Name())
+		if checked[name] {
+			continue
+		}
+		checked[name] = true
+
+		_, bucketID := tsdb.DecodeNameSlice(p.Name())
+		b, err := w.buckets.FindBucketByID(ctx, bucketID)
+		if err != nil {
+			return nil, err
+		}
+		if b.DedupWindow <= 0 {
+			continue
+		}
+		if windows == nil {
+			windows = make(map[string]*influxdb.Bucket)
+		}
+		windows[name] = b
+	}
+	return windows, nil
+}
+
+// bucketPoints returns the points remembered for the bucket id.
+func (w *PointsWriter) bucketPoints(id influxdb.ID) *bucketPoints {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	bp, ok := w.byID[id]
+	if !ok {
+		bp = &bucketPoints{seen: make(map[key]int64)}
+		w.byID[id] = bp
+	}
+	return bp
+}
+
+// commit remembers the points written, and expires the points of every
+// bucket once maxPoints is exceeded.
+func (w *PointsWriter) commit(now int64, pending map[*bucketPoints][]entry) {
+	max := w.bucketMax()
+	for bp, entries := range pending {
+		bp.mu.Lock()
+		n := len(bp.seen)
+		for _, e := range entries {
+			bp.seen[e.key] = e.expires
+			bp.queue = append(bp.queue, e)
+		}
+		added := len(bp.seen) - n
+		removed := bp.expire(now, max)
+		bp.mu.Unlock()
+		w.resize(added - removed)
+	}
+
+	w.mu.Lock()
+	over := w.size > w.maxPoints
+	w.mu.Unlock()
+	if over {
+		w.expireAll(now)
+	}
+}
+
+// bucketMax returns the number of points a bucket keeps: all of them until
+// maxPoints is reached, and then an equal share of maxPoints.
+func (w *PointsWriter) bucketMax() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size < w.maxPoints {
+		return w.maxPoints
+	}
+	return w.maxPoints / len(w.byID)
+}
+
+func (w *PointsWriter) resize(delta int) {
+	w.mu.Lock()
+	w.size += delta
+	size := w.size
+	w.mu.Unlock()
+	w.points.Set(float64(size))
+}
+
+// sweep expires the points of every bucket once per sweepInterval, so that
+// the points of the buckets that are no longer written to are forgotten.
+func (w *PointsWriter) sweep(now time.Time) {
+	w.mu.Lock()
+	due := now.Sub(w.lastSweep) >= sweepInterval
+	if due {
+		w.lastSweep = now
+	}
+	w.mu.Unlock()
+	if due {
+		w.expireAll(now.UnixNano())
+	}
+}
+
+// expireAll expires the points of every bucket.
+func (w *PointsWriter) expireAll(now int64) {
+	w.mu.Lock()
+	all := make([]*bucketPoints, 0, len(w.byID))
+	for _, bp := range w.byID {
+		all = append(all, bp)
+	}
+	w.mu.Unlock()
+
+	max := w.bucketMax()
+	for _, bp := range all {
+		bp.mu.Lock()
+		removed := bp.expire(now, max)
+		bp.mu.Unlock()
+		w.resize(-removed)
+	}
+}
@@ -0,0 +1,144 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+const (
+	orgID     influxdb.ID = 1
+	dedupID   influxdb.ID = 2
+	otherID   influxdb.ID = 3
+	secondID  influxdb.ID = 4
+	unknownID influxdb.ID = 5
+)
+
+func newBucketService() *mock.BucketService {
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		switch id {
+		case dedupID, secondID:
+			return &influxdb.Bucket{ID: id, OrgID: orgID, Name: "dedup", DedupWindow: time.Minute}, nil
+		case otherID:
+			return &influxdb.Bucket{ID: id, OrgID: orgID, Name: "other"}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+	}
+	return buckets
+}
+
+func parse(t *testing.T, bucketID influxdb.ID, lp string) []models.Point {
+	t.Helper()
+	points, err := models.ParsePointsString(lp, tsdb.EncodeNameString(orgID, bucketID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return points
+}
+
+func TestPointsWriter(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	next := &mock.PointsWriter{}
+	w := NewPointsWriter(next, newBucketService(), 0)
+	w.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	write := func(bucketID influxdb.ID, lp string) []models.Point {
+		t.Helper()
+		next.Points = nil
+		if err := w.WritePoints(ctx, parse(t, bucketID, lp)); err != nil {
+			t.Fatalf("unexpected error writing points: %v", err)
+		}
+		return next.Points
+	}
+
+	if got := write(dedupID, "m,t=a f=1 1\nm,t=a f=1 1\nm,t=a f=2 1"); len(got) != 2 {
+		t.Fatalf("expected the duplicate of the write dropped, got %v", got)
+	}
+	if got := write(dedupID, "m,t=a f=1 1\nm,t=b f=1 1\nm,t=a f=1 2"); len(got) != 2 || got[0].Tags().GetString("t") != "b" {
+		t.Fatalf("expected the duplicate of the first write dropped, got %v", got)
+	}
+	if got := write(secondID, "m,t=a f=1 1"); len(got) != 1 {
+		t.Fatalf("expected the points of another bucket written, got %v", got)
+	}
+	if got := write(otherID, "m,t=a f=1 1\nm,t=a f=1 1"); len(got) != 2 {
+		t.Fatalf("expected the points of buckets without dedup window written, got %v", got)
+	}
+	if got := write(dedupID, "m,t=a f=1 1"); len(got) != 0 {
+		t.Fatalf("expected the write of duplicates only not written, got %v", got)
+	}
+
+	now = now.Add(time.Minute)
+	if got := write(dedupID, "m,t=a f=1 1"); len(got) != 1 {
+		t.Fatalf("expected the duplicate after the dedup window written, got %v", got)
+	}
+}
+
+func TestPointsWriter_FailedWrite(t *testing.T) {
+	next := &mock.PointsWriter{}
+	w := NewPointsWriter(next, newBucketService(), 0)
+	ctx := context.Background()
+	points := parse(t, dedupID, "m f=1 1")
+
+	// A failed write is not remembered, so that it can be retried.
+	next.ForceError(errors.New("write failed"))
+	if err := w.WritePoints(ctx, points); err == nil {
+		t.Fatal("expected the error of the write")
+	}
+	next.ForceError(nil)
+	next.Points = nil
+	if err := w.WritePoints(ctx, points); err != nil {
+		t.Fatalf("unexpected error retrying the write: %v", err)
+	}
+	if len(next.Points) != 1 {
+		t.Fatalf("expected the retried point written, got %d points", len(next.Points))
+	}
+}
+
+func TestPointsWriter_UnknownBucket(t *testing.T) {
+	next := &mock.PointsWriter{}
+	w := NewPointsWriter(next, newBucketService(), 0)
+
+	err := w.WritePoints(context.Background(), parse(t, unknownID, "m f=1 1"))
+	if influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected a not found error writing to an unknown bucket, got %v", err)
+	}
+	if len(next.Points) != 0 {
+		t.Fatalf("expected no points written, got %d points", len(next.Points))
+	}
+}
+
+func TestPointsWriter_MaxPoints(t *testing.T) {
+	next := &mock.PointsWriter{}
+	w := NewPointsWriter(next, newBucketService(), 4)
+	ctx := context.Background()
+
+	if err := w.WritePoints(ctx, parse(t, dedupID, "m f=1 1\nm f=1 2\nm f=1 3\nm f=1 4\nm f=1 5\nm f=1 6")); err != nil {
+		t.Fatal(err)
+	}
+	if w.size != 4 {
+		t.Fatalf("expected the points remembered capped to 4, got %d", w.size)
+	}
+	if err := w.WritePoints(ctx, parse(t, secondID, "m f=1 1\nm f=1 2")); err != nil {
+		t.Fatal(err)
+	}
+	if w.size > 4 {
+		t.Fatalf("expected the points remembered by all the buckets capped to 4, got %d", w.size)
+	}
+
+	// The oldest points are forgotten first.
+	next.Points = nil
+	if err := w.WritePoints(ctx, parse(t, dedupID, "m f=1 1\nm f=1 6")); err != nil {
+		t.Fatal(err)
+	}
+	if len(next.Points) != 1 || next.Points[0].UnixNano() != 1 {
+		t.Fatalf("expected only the forgotten oldest point written, got %v", next.Points)
+	}
+}
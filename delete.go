@@ -13,3 +13,8 @@ type Predicate interface {
 type DeleteService interface {
 	DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID ID, min, max int64, pred Predicate) error
 }
+
+// MeasurementDeleteService will delete a measurement of a bucket within a range.
+type MeasurementDeleteService interface {
+	DeleteMeasurementRange(ctx context.Context, orgID, bucketID ID, measurement string, min, max int64) error
+}
@@ -0,0 +1,113 @@
+package edge
+
+import (
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+// windows downsample the forwarded points to the last value of each field of
+// each series in every interval. The value is kept rather than aggregated so
+// that the types of the fields do not change. The points of a window are
+// timestamped with its start, so that forwarding a window again overwrites
+// its points rather than adding to them.
+type windows struct {
+	interval int64
+	samples  map[windowKey]*sample
+}
+
+type windowKey struct {
+	bucket string
+	series string
+	start  int64
+}
+
+// sample is the last value of the fields of a series in a window.
+type sample struct {
+	measurement string
+	tags        models.Tags
+	fields      map[string]fieldValue
+}
+
+type fieldValue struct {
+	time  int64
+	value interface{}
+}
+
+func newWindows(interval time.Duration) *windows {
+	return &windows{
+		interval: int64(interval),
+		samples:  make(map[windowKey]*sample),
+	}
+}
+
+// add adds the point p of the central bucket to its window.
+func (w *windows) add(bucket string, p models.Point) {
+	t := p.UnixNano()
+	start := t - t%w.interval
+	if t < 0 && t%w.interval != 0 {
+		start -= w.interval
+	}
+	k := windowKey{bucket: bucket, series: string(p.Key()), start: start}
+	s, ok := w.samples[k]
+	if !ok {
+		s = &sample{
+			measurement: string(p.Name()),
+			tags:        p.Tags().Clone(),
+			fields:      make(map[string]fieldValue),
+		}
+		w.samples[k] = s
+	}
+
+	iter := p.FieldIterator()
+	for iter.Next() {
+		key := string(iter.FieldKey())
+		if v, ok := s.fields[key]; ok && v.time > t {
+			continue
+		}
+		var value interface{}
+		var err error
+		switch iter.Type() {
+		case models.Float:
+			value, err = iter.FloatValue()
+		case models.Integer:
+			value, err = iter.IntegerValue()
+		case models.Unsigned:
+			value, err = iter.UnsignedValue()
+		case models.String:
+			value = iter.StringValue()
+		case models.Boolean:
+			value, err = iter.BooleanValue()
+		default:
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		s.fields[key] = fieldValue{time: t, value: value}
+	}
+}
+
+// flush removes the windows that ended before now, or all of them if all is
+// true, and returns their points by central bucket.
+func (w *windows) flush(now time.Time, all bool) map[string][]models.Point {
+	end := now.UnixNano()
+	flushed := make(map[string][]models.Point)
+	for k, s := range w.samples {
+		if !all && k.start+w.interval > end {
+			continue
+		}
+		delete(w.samples, k)
+
+		fields := make(models.Fields, len(s.fields))
+		for key, v := range s.fields {
+			fields[key] = v.value
+		}
+		p, err := models.NewPoint(s.measurement, s.tags, fields, time.Unix(0, k.start))
+		if err != nil {
+			continue
+		}
+		flushed[k.bucket] = append(flushed[k.bucket], p)
+	}
+	return flushed
+}
@@ -0,0 +1,60 @@
+package edge
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+func cpu(host string, fields models.Fields, sec int64) models.Point {
+	return models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": host}), fields, time.Unix(sec, 0))
+}
+
+func lines(points []models.Point) []string {
+	var lines []string
+	for _, p := range points {
+		lines = append(lines, p.String())
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func TestWindows(t *testing.T) {
+	w := newWindows(time.Minute)
+	for _, p := range []models.Point{
+		cpu("a", models.Fields{"usage": 0.5}, 0),
+		cpu("a", models.Fields{"usage": 0.7}, 50),
+		// A point written late does not replace the last value.
+		cpu("a", models.Fields{"usage": 0.6}, 40),
+		cpu("a", models.Fields{"state": "idle"}, 10),
+		cpu("b", models.Fields{"usage": int64(1)}, 30),
+		cpu("a", models.Fields{"usage": 0.2}, 70),
+	} {
+		w.add("factories", p)
+	}
+
+	// Only the windows that ended are flushed.
+	flushed := w.flush(time.Unix(90, 0), false)
+	want := []string{
+		`cpu,host=a state="idle",usage=0.7 0`,
+		`cpu,host=b usage=1i 0`,
+	}
+	if diff := cmp.Diff(want, lines(flushed["factories"])); diff != "" {
+		t.Errorf("unexpected points of the ended windows (-want +got):\n%s", diff)
+	}
+
+	flushed = w.flush(time.Unix(90, 0), true)
+	want = []string{
+		`cpu,host=a usage=0.2 60000000000`,
+	}
+	if diff := cmp.Diff(want, lines(flushed["factories"])); diff != "" {
+		t.Errorf("unexpected points of the open windows (-want +got):\n%s", diff)
+	}
+
+	if flushed := w.flush(time.Unix(90, 0), true); len(flushed) != 0 {
+		t.Errorf("expected the windows to be removed once flushed, got %v", flushed)
+	}
+}
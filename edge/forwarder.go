@@ -0,0 +1,447 @@
+// Package edge runs influxd at the edge of a network, such as in a factory
+// or a store, where the points written are kept for a short time and
+// forwarded to a central instance.
+//
+// The points written to the forwarded buckets are downsampled and appended
+// to a queue on disk, which is sent to the write API of the central instance
+// whenever it is reachable, so that no point is lost while the edge is
+// disconnected. The points are tagged with the ID of the edge node, so that
+// the points of any number of edges merge at the central instance without
+// overwriting each other, and sending a batch again after a failure only
+// overwrites its points with the same values.
+package edge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/durablequeue"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// Profile is the name of the configuration profile of edge nodes.
+	Profile = "edge"
+
+	// NodeTagKey is the key of the tag of the forwarded points whose value
+	// is the ID of the edge node.
+	NodeTagKey = "edge"
+
+	// DefaultRetention is the default maximum retention period of the
+	// buckets of edge nodes.
+	DefaultRetention = 72 * time.Hour
+
+	// DefaultDownsampleInterval is the default interval the forwarded points
+	// are downsampled to.
+	DefaultDownsampleInterval = time.Minute
+
+	// DefaultMaxQueueSize is the default maximum size in bytes of the queue
+	// of the points not yet forwarded.
+	DefaultMaxQueueSize = 1 << 30
+
+	// sendTimeout is the time after which a batch that was not accepted by
+	// the central instance is sent again.
+	sendTimeout = 30 * time.Second
+
+	minRetryInterval = time.Second
+	maxRetryInterval = time.Minute
+)
+
+// Config is the configuration of the forwarding of an edge node.
+type Config struct {
+	// CentralURL is the URL of the central instance, such as
+	// https://influxdb.example.com:8086.
+	CentralURL string
+	// Token is the token the points are written to the central instance
+	// with.
+	Token string
+	// Org is the name of the organization of the buckets of the central
+	// instance.
+	Org string
+	// Buckets are the names of the buckets of the central instance the
+	// points written to each local bucket are forwarded to.
+	Buckets map[influxdb.ID]string
+	// NodeID identifies the edge node among those forwarding to the central
+	// instance.
+	NodeID string
+	// Retention is the maximum retention period of the local buckets.
+	Retention time.Duration
+	// DownsampleInterval is the interval of which the last value of each
+	// field of each series is forwarded; all the points are forwarded when
+	// it is zero.
+	DownsampleInterval time.Duration
+	// QueuePath is the directory of the queue of the points not yet
+	// forwarded.
+	QueuePath    string
+	MaxQueueSize int64
+}
+
+// Valid returns an error if the configuration is incomplete.
+func (c *Config) Valid() error {
+	u, err := url.Parse(c.CentralURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid central URL %q, must be an http or https URL", c.CentralURL)
+	}
+	if c.Token == "" {
+		return errors.New("central token is required")
+	}
+	if c.Org == "" {
+		return errors.New("central organization is required")
+	}
+	if len(c.Buckets) == 0 {
+		return errors.New("at least one bucket is required")
+	}
+	for id, name := range c.Buckets {
+		if !id.Valid() {
+			return fmt.Errorf("invalid bucket ID forwarded to central bucket %q", name)
+		}
+		if name == "" || strings.Contains(name, "\n") {
+			return fmt.Errorf("invalid central bucket of bucket %s", id)
+		}
+	}
+	if c.NodeID == "" {
+		return errors.New("node ID is required")
+	}
+	if c.Retention <= 0 {
+		return errors.New("retention must be positive")
+	}
+	if c.DownsampleInterval < 0 {
+		return errors.New("downsample interval must not be negative")
+	}
+	if c.QueuePath == "" {
+		return errors.New("queue path is required")
+	}
+	if c.MaxQueueSize <= 0 {
+		return errors.New("max queue size must be positive")
+	}
+	return nil
+}
+
+// Forwarder is a storage.PointsWriter that forwards the points written to
+// the buckets of its configuration to the central instance, once they are
+// written locally.
+type Forwarder struct {
+	storage.PointsWriter
+
+	log    *zap.Logger
+	config Config
+	client *http.Client
+	queue  *durablequeue.Queue
+	now    func() time.Time
+
+	mu      sync.Mutex
+	windows *windows
+
+	points     prometheus.Counter
+	sent       prometheus.Counter
+	failed     prometheus.Counter
+	dropped    prometheus.Counter
+	queueBytes prometheus.Gauge
+}
+
+// NewForwarder returns a Forwarder of the points written with pw, whose
+// queue is opened in the queue path of config.
+func NewForwarder(log *zap.Logger, config Config, pw storage.PointsWriter) (*Forwarder, error) {
+	if err := config.Valid(); err != nil {
+		return nil, err
+	}
+	q, err := durablequeue.Open(config.QueuePath, config.MaxQueueSize)
+	if err != nil {
+		return nil, err
+	}
+
+	const namespace = "edge"
+	const subsystem = "forward"
+	f := &Forwarder{
+		PointsWriter: pw,
+		log:          log,
+		config:       config,
+		client:       &http.Client{Timeout: sendTimeout},
+		queue:        q,
+		now:          time.Now,
+		windows:      newWindows(config.DownsampleInterval),
+
+		points: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "points_total",
+			Help:      "Number of points queued to be forwarded to the central instance, once downsampled.",
+		}),
+		sent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "sent_total",
+			Help:      "Number of batches of points written to the central instance.",
+		}),
+		failed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "failed_total",
+			Help:      "Number of attempts to write a batch of points to the central instance that failed and are retried.",
+		}),
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "dropped_bytes_total",
+			Help:      "Number of bytes of line protocol dropped because the queue was full or the central instance rejected them.",
+		}),
+		queueBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "queue_bytes",
+			Help:      "Number of bytes of the queue not yet written to the central instance.",
+		}),
+	}
+	f.queueBytes.Set(float64(q.Pending()))
+	return f, nil
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (f *Forwarder) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		f.points,
+		f.sent,
+		f.failed,
+		f.dropped,
+		f.queueBytes,
+	}
+}
+
+// WritePoints writes the points and queues those of the forwarded buckets,
+// or adds them to their downsampling windows.
+func (f *Forwarder) WritePoints(ctx context.Context, points []models.Point) error {
+	if err := f.PointsWriter.WritePoints(ctx, points); err != nil {
+		return err
+	}
+
+	batches := make(map[string][]byte)
+	counts := make(map[string]int)
+	f.mu.Lock()
+	for _, p := range points {
+		_, bucketID := tsdb.DecodeNameSlice(p.Name())
+		bucket, ok := f.config.Buckets[bucketID]
+		if !ok {
+			continue
+		}
+		pt, err := f.forwardedPoint(p)
+		if err != nil {
+			f.log.Warn("Failed to forward point", zap.Error(err))
+			continue
+		}
+		if f.config.DownsampleInterval == 0 {
+			batches[bucket] = append(pt.AppendString(batches[bucket]), '\n')
+			counts[bucket]++
+			continue
+		}
+		f.windows.add(bucket, pt)
+	}
+	f.mu.Unlock()
+
+	for bucket, b := range batches {
+		f.enqueue(bucket, b, counts[bucket])
+	}
+	return nil
+}
+
+// forwardedPoint returns the point p, written to the storage engine, as it
+// was written to its bucket and tagged with the ID of the edge node.
+func (f *Forwarder) forwardedPoint(p models.Point) (models.Point, error) {
+	fields, err := p.Fields()
+	if err != nil {
+		return nil, err
+	}
+	var measurement []byte
+	tags := make(models.Tags, 0, len(p.Tags()))
+	for _, t := range p.Tags() {
+		switch string(t.Key) {
+		case models.MeasurementTagKey:
+			measurement = t.Value
+		case models.FieldKeyTagKey:
+		default:
+			tags = append(tags, t)
+		}
+	}
+	tags.SetString(NodeTagKey, f.config.NodeID)
+	return models.NewPoint(string(measurement), tags, fields, p.Time())
+}
+
+// enqueue appends the batch of lines of the central bucket to the queue.
+// The first line of the records of the queue is the name of their bucket.
+func (f *Forwarder) enqueue(bucket string, lines []byte, points int) {
+	b := make([]byte, 0, len(bucket)+1+len(lines))
+	b = append(append(append(b, bucket...), '\n'), lines...)
+
+	dropped, err := f.queue.Append(b)
+	if err != nil {
+		if err != durablequeue.ErrFull {
+			f.log.Error("Failed to queue points to forward", zap.Error(err))
+		}
+		dropped += int64(len(b))
+	} else {
+		f.points.Add(float64(points))
+	}
+	if dropped > 0 {
+		f.dropped.Add(float64(dropped))
+	}
+	f.queueBytes.Set(float64(f.queue.Pending()))
+}
+
+// flush queues the points of the downsampling windows that ended before
+// now, or of all of them if all is true.
+func (f *Forwarder) flush(now time.Time, all bool) {
+	f.mu.Lock()
+	flushed := f.windows.flush(now, all)
+	f.mu.Unlock()
+
+	for bucket, points := range flushed {
+		var b []byte
+		for _, p := range points {
+			b = append(p.AppendString(b), '\n')
+		}
+		f.enqueue(bucket, b, len(points))
+	}
+}
+
+// Run sends the queued points to the central instance until ctx is done.
+// The downsampling windows are flushed once they end, and when ctx is done.
+func (f *Forwarder) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	if f.config.DownsampleInterval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(f.config.DownsampleInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					f.flush(f.now(), true)
+					return
+				case <-ticker.C:
+					f.flush(f.now(), false)
+				}
+			}
+		}()
+	}
+
+	f.send(ctx)
+	wg.Wait()
+
+	f.client.CloseIdleConnections()
+	if err := f.queue.Close(); err != nil {
+		f.log.Error("Failed to close forwarding queue", zap.Error(err))
+	}
+}
+
+// send writes the batches of the queue to the central instance. A batch
+// stays in the queue until the central instance accepts or rejects it.
+func (f *Forwarder) send(ctx context.Context) {
+	retry := minRetryInterval
+	for {
+		b, err := f.queue.Peek()
+		if err == io.EOF {
+			select {
+			case <-ctx.Done():
+				return
+			case <-f.queue.Wait():
+				continue
+			}
+		}
+		if err == nil {
+			err = f.write(ctx, b)
+			if ctx.Err() != nil {
+				return
+			}
+			if perr, ok := err.(*permanentError); ok {
+				f.log.Warn("Central instance rejected forwarded points", zap.Error(perr.err))
+				f.dropped.Add(float64(len(b)))
+				err = nil
+			} else if err == nil {
+				f.sent.Inc()
+			} else {
+				f.failed.Inc()
+			}
+		}
+		if err == nil {
+			err = f.queue.Advance()
+			f.queueBytes.Set(float64(f.queue.Pending()))
+		}
+		if err == nil {
+			retry = minRetryInterval
+			continue
+		}
+
+		f.log.Warn("Failed to forward points to central instance", zap.Error(err), zap.Duration("retry", retry))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		if retry *= 2; retry > maxRetryInterval {
+			retry = maxRetryInterval
+		}
+	}
+}
+
+// permanentError is returned when the central instance will never accept
+// a batch, which is dropped rather than sent again.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+// write writes the queued record b to its bucket of the central instance.
+func (f *Forwarder) write(ctx context.Context, b []byte) error {
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		return &permanentError{err: errors.New("queued points have no bucket")}
+	}
+	bucket, lines := string(b[:i]), b[i+1:]
+
+	u := strings.TrimSuffix(f.config.CentralURL, "/") + "/api/v2/write?" + url.Values{
+		"org":       {f.config.Org},
+		"bucket":    {bucket},
+		"precision": {"ns"},
+	}.Encode()
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(lines))
+	if err != nil {
+		return &permanentError{err: err}
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("Authorization", "Token "+f.config.Token)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<10))
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusOK:
+		return nil
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		// The points are rejected, and would be rejected again. The other
+		// errors, such as a missing bucket or an invalid token, are fixed at
+		// the central instance, so the points are kept until then.
+		return &permanentError{err: fmt.Errorf("central instance responded %s", resp.Status)}
+	default:
+		return fmt.Errorf("central instance responded %s", resp.Status)
+	}
+}
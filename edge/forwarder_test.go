@@ -0,0 +1,160 @@
+package edge
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+// explodedPoints returns the points of the line protocol lp, exploded to be
+// written to the bucket of the organization 1.
+func explodedPoints(t *testing.T, bucketID influxdb.ID, lp string) []models.Point {
+	t.Helper()
+
+	encoded := tsdb.EncodeName(1, bucketID)
+	points, err := models.ParsePointsWithPrecision([]byte(lp), models.EscapeMeasurement(encoded[:]), time.Now(), "ns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return points
+}
+
+type centralWrite struct {
+	query url.Values
+	auth  string
+	body  string
+}
+
+func TestForwarder(t *testing.T) {
+	writes := make(chan centralWrite, 10)
+	status := make(chan int, 10)
+	central := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/write" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		code := <-status
+		if code == http.StatusNoContent {
+			writes <- centralWrite{query: r.URL.Query(), auth: r.Header.Get("Authorization"), body: string(b)}
+		}
+		w.WriteHeader(code)
+	}))
+	defer central.Close()
+
+	dir, err := ioutil.TempDir("", "edge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pw := &mock.PointsWriter{}
+	f, err := NewForwarder(zaptest.NewLogger(t), Config{
+		CentralURL:   central.URL,
+		Token:        "secret",
+		Org:          "central",
+		Buckets:      map[influxdb.ID]string{10: "factories"},
+		NodeID:       "plant-a",
+		Retention:    DefaultRetention,
+		QueuePath:    dir,
+		MaxQueueSize: 1 << 20,
+	}, pw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := f.WritePoints(ctx, explodedPoints(t, 10, "cpu,host=a usage=0.5 10")); err != nil {
+		t.Fatal(err)
+	}
+	// The points of the buckets not forwarded are only written locally.
+	if err := f.WritePoints(ctx, explodedPoints(t, 11, "cpu,host=b usage=0.5 10")); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(pw.Points); got != 2 {
+		t.Fatalf("expected the points to be written locally, got %d points", got)
+	}
+
+	// The first attempt fails, and the batch is sent again.
+	status <- http.StatusServiceUnavailable
+	status <- http.StatusNoContent
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	select {
+	case w := <-writes:
+		if w.query.Get("org") != "central" || w.query.Get("bucket") != "factories" || w.query.Get("precision") != "ns" {
+			t.Errorf("unexpected query %v", w.query)
+		}
+		if w.auth != "Token secret" {
+			t.Errorf("unexpected authorization %q", w.auth)
+		}
+		if want := "cpu,edge=plant-a,host=a usage=0.5 10\n"; w.body != want {
+			t.Errorf("unexpected points %q, want %q", w.body, want)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the points to be forwarded")
+	}
+
+	select {
+	case w := <-writes:
+		t.Errorf("unexpected write of %q", w.body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestConfig_Valid(t *testing.T) {
+	valid := func() Config {
+		return Config{
+			CentralURL:   "https://central:8086",
+			Token:        "secret",
+			Org:          "central",
+			Buckets:      map[influxdb.ID]string{10: "factories"},
+			NodeID:       "plant-a",
+			Retention:    DefaultRetention,
+			QueuePath:    "/var/lib/influxdb/edge",
+			MaxQueueSize: DefaultMaxQueueSize,
+		}
+	}
+	if c := valid(); c.Valid() != nil {
+		t.Fatalf("expected a valid configuration, got %v", c.Valid())
+	}
+
+	for name, fn := range map[string]func(*Config){
+		"url":       func(c *Config) { c.CentralURL = "central:8086" },
+		"token":     func(c *Config) { c.Token = "" },
+		"org":       func(c *Config) { c.Org = "" },
+		"buckets":   func(c *Config) { c.Buckets = nil },
+		"bucket":    func(c *Config) { c.Buckets[10] = "" },
+		"node":      func(c *Config) { c.NodeID = "" },
+		"retention": func(c *Config) { c.Retention = 0 },
+		"interval":  func(c *Config) { c.DownsampleInterval = -time.Minute },
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := valid()
+			fn(&c)
+			if c.Valid() == nil {
+				t.Error("expected an invalid configuration")
+			}
+		})
+	}
+}
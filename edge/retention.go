@@ -0,0 +1,45 @@
+package edge
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/storage"
+)
+
+// RetentionFinder is a storage.BucketFinder whose buckets are retained for
+// at most a maximum retention period, so that the retention enforcer of an
+// edge node deletes their points once they are forwarded, whatever the
+// retention period of the buckets.
+type RetentionFinder struct {
+	storage.BucketFinder
+	max time.Duration
+}
+
+// NewRetentionFinder returns a RetentionFinder of the buckets of finder,
+// retained for at most max.
+func NewRetentionFinder(finder storage.BucketFinder, max time.Duration) *RetentionFinder {
+	return &RetentionFinder{
+		BucketFinder: finder,
+		max:          max,
+	}
+}
+
+// FindBuckets returns the buckets matching the filter, with their retention
+// period shortened to the maximum one.
+func (f *RetentionFinder) FindBuckets(ctx context.Context, filter influxdb.BucketFilter, opts ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+	buckets, n, err := f.BucketFinder.FindBuckets(ctx, filter, opts...)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i, b := range buckets {
+		if b.RetentionPeriod != 0 && b.RetentionPeriod <= f.max {
+			continue
+		}
+		capped := *b
+		capped.RetentionPeriod = f.max
+		buckets[i] = &capped
+	}
+	return buckets, n, nil
+}
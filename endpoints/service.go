@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
 )
 
 // Service provides all the notification endpoint service behavior.
@@ -60,8 +61,14 @@ func (s *Service) CreateNotificationEndpoint(ctx context.Context, edp influxdb.N
 }
 
 // UpdateNotificationEndpoint updates a single notification endpoint.
-// Returns the new notification endpoint after update.
+// Returns the new notification endpoint after update. The secrets owned by
+// the endpoint that it does not reference anymore are deleted.
 func (s *Service) UpdateNotificationEndpoint(ctx context.Context, id influxdb.ID, nr influxdb.NotificationEndpoint, userID influxdb.ID) (influxdb.NotificationEndpoint, error) {
+	prevEndpoint, err := s.endpointStore.FindNotificationEndpointByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
 	nr.BackfillSecretKeys() // :sadpanda:
 	updatedEndpoint, err := s.endpointStore.UpdateNotificationEndpoint(ctx, id, nr, userID)
 	if err != nil {
@@ -75,24 +82,89 @@ func (s *Service) UpdateNotificationEndpoint(ctx context.Context, id influxdb.ID
 		}
 	}
 
-	if len(secrets) == 0 {
-		return updatedEndpoint, nil
+	if len(secrets) > 0 {
+		if err := s.secretSVC.PatchSecrets(ctx, updatedEndpoint.GetOrgID(), secrets); err != nil {
+			return nil, err
+		}
 	}
 
-	if err := s.secretSVC.PatchSecrets(ctx, updatedEndpoint.GetOrgID(), secrets); err != nil {
-		return nil, err
+	var released []string
+	for _, k := range endpoint.OwnedSecretKeys(prevEndpoint) {
+		if !hasSecretKey(updatedEndpoint, k) {
+			released = append(released, k)
+		}
+	}
+	if len(released) > 0 {
+		if err := s.secretSVC.DeleteSecret(ctx, prevEndpoint.GetOrgID(), released...); err != nil {
+			return nil, err
+		}
 	}
 
 	return updatedEndpoint, nil
 }
 
+func hasSecretKey(edp influxdb.NotificationEndpoint, k string) bool {
+	for _, fld := range edp.SecretFields() {
+		if fld.Key == k {
+			return true
+		}
+	}
+	return false
+}
+
 // PatchNotificationEndpoint updates a single  notification endpoint with changeset.
 // Returns the new notification endpoint state after update.
 func (s *Service) PatchNotificationEndpoint(ctx context.Context, id influxdb.ID, upd influxdb.NotificationEndpointUpdate) (influxdb.NotificationEndpoint, error) {
 	return s.endpointStore.PatchNotificationEndpoint(ctx, id, upd)
 }
 
-// DeleteNotificationEndpoint removes a notification endpoint by ID, returns secret fields, orgID for further deletion.
+// DeleteNotificationEndpoint removes a notification endpoint by ID, and the
+// secrets it owns. The secret fields are returned with the orgID, for the
+// deletion of the secrets it referenced without owning them.
 func (s *Service) DeleteNotificationEndpoint(ctx context.Context, id influxdb.ID) ([]influxdb.SecretField, influxdb.ID, error) {
-	return s.endpointStore.DeleteNotificationEndpoint(ctx, id)
+	flds, orgID, err := s.endpointStore.DeleteNotificationEndpoint(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var owned []string
+	for _, fld := range flds {
+		if owner, ok := endpoint.SecretKeyOwner(fld.Key); ok && owner == id {
+			owned = append(owned, fld.Key)
+		}
+	}
+	if len(owned) > 0 {
+		if err := s.secretSVC.DeleteSecret(ctx, orgID, owned...); err != nil {
+			return nil, 0, err
+		}
+	}
+	return flds, orgID, nil
+}
+
+// OrphanedSecrets returns the keys among the secret keys of the organization
+// that are owned by endpoints that do not exist anymore, as left by the
+// endpoints deleted before their secrets were deleted with them.
+func (s *Service) OrphanedSecrets(ctx context.Context, orgID influxdb.ID, keys []string) ([]string, error) {
+	exists := make(map[influxdb.ID]bool)
+	orphaned := make([]string, 0)
+	for _, k := range keys {
+		id, ok := endpoint.SecretKeyOwner(k)
+		if !ok {
+			continue
+		}
+		found, ok := exists[id]
+		if !ok {
+			edp, err := s.endpointStore.FindNotificationEndpointByID(ctx, id)
+			if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+				return nil, err
+			}
+			// Endpoints only own the secrets of their organization.
+			found = err == nil && edp.GetOrgID() == orgID
+			exists[id] = found
+		}
+		if !found {
+			orphaned = append(orphaned, k)
+		}
+	}
+	return orphaned, nil
 }
@@ -176,6 +176,106 @@ func TestEndpointService_cummulativeSecrets(t *testing.T) {
 	}
 }
 
+// TestEndpointService_ownedSecrets tests that the secrets generated for endpoints are deleted with them
+func TestEndpointService_ownedSecrets(t *testing.T) {
+	inMemService := newInmemService(t)
+	endpointService := endpoints.NewService(inMemService, inMemService, inMemService, inMemService)
+	secretService := inMemService
+	ctx := context.Background()
+
+	if err := secretService.PutSecret(ctx, *orgID, "shared-token", "shared"); err != nil {
+		t.Fatal(err)
+	}
+
+	owner := &endpoint.Slack{
+		Base:  endpoint.Base{Name: "owner", OrgID: orgID, Status: influxdb.Active},
+		URL:   "http://example.com",
+		Token: influxdb.SecretField{Value: strPtr("generated")},
+	}
+	shared := &endpoint.Slack{
+		Base:  endpoint.Base{Name: "shared", OrgID: orgID, Status: influxdb.Active},
+		URL:   "http://example.com",
+		Token: influxdb.SecretField{Key: "shared-token"},
+	}
+	for _, e := range []*endpoint.Slack{owner, shared} {
+		if err := endpointService.CreateNotificationEndpoint(ctx, e, *userID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ownedKey := owner.GetID().String() + "-token"
+	if owner.Token.Key != ownedKey {
+		t.Fatalf("unexpected secret key %q, want %q", owner.Token.Key, ownedKey)
+	}
+
+	// A secret left by an endpoint deleted before its secrets were deleted with it.
+	orphanedKey := influxTesting.MustIDBase16("020f755c3c08ffff").String() + "-token"
+	if err := secretService.PutSecret(ctx, *orgID, orphanedKey, "orphaned"); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := secretService.GetSecretKeys(ctx, *orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphaned, err := endpointService.OrphanedSecrets(ctx, *orgID, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphaned) != 1 || orphaned[0] != orphanedKey {
+		t.Errorf("orphaned secrets = %v, want %v", orphaned, []string{orphanedKey})
+	}
+
+	for _, id := range []influxdb.ID{owner.GetID(), shared.GetID()} {
+		if _, _, err := endpointService.DeleteNotificationEndpoint(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	keys, err = secretService.GetSecretKeys(ctx, *orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"shared-token": true, orphanedKey: true}
+	if len(keys) != len(want) {
+		t.Errorf("secrets after deleting the endpoints = %v, want the shared and orphaned secrets", keys)
+	}
+	for _, k := range keys {
+		if !want[k] {
+			t.Errorf("unexpected secret %q after deleting the endpoints", k)
+		}
+	}
+}
+
+// TestEndpointService_releasedSecrets tests that the secrets of an endpoint are deleted once it references others
+func TestEndpointService_releasedSecrets(t *testing.T) {
+	inMemService := newInmemService(t)
+	endpointService := endpoints.NewService(inMemService, inMemService, inMemService, inMemService)
+	secretService := inMemService
+	ctx := context.Background()
+
+	e := &endpoint.Slack{
+		Base:  endpoint.Base{Name: "owner", OrgID: orgID, Status: influxdb.Active},
+		URL:   "http://example.com",
+		Token: influxdb.SecretField{Value: strPtr("generated")},
+	}
+	if err := endpointService.CreateNotificationEndpoint(ctx, e, *userID); err != nil {
+		t.Fatal(err)
+	}
+	if err := secretService.PutSecret(ctx, *orgID, "shared-token", "shared"); err != nil {
+		t.Fatal(err)
+	}
+
+	e.Token = influxdb.SecretField{Key: "shared-token"}
+	if _, err := endpointService.UpdateNotificationEndpoint(ctx, e.GetID(), e, *userID); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := secretService.GetSecretKeys(ctx, *orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "shared-token" {
+		t.Errorf("secrets after updating the endpoint = %v, want %v", keys, []string{"shared-token"})
+	}
+}
+
 // strPtr returns string pointer
 func strPtr(s string) *string {
 	return &s
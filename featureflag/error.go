@@ -0,0 +1,48 @@
+package featureflag
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrOverrideNotFound is used when the flag has no override.
+	ErrOverrideNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "feature flag override not found",
+	}
+
+	// ErrInvalidOrgID is used when the organization of an override is not a
+	// valid ID.
+	ErrInvalidOrgID = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "orgID is invalid",
+	}
+)
+
+// ErrFlagNotFound is used when no feature flag has the key.
+func ErrFlagNotFound(key string) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  fmt.Sprintf("feature flag %q not found", key),
+	}
+}
+
+// ErrInvalidValue is used when the value of an override is not of the type
+// of its flag.
+func ErrInvalidValue(key string, err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("invalid value of feature flag %q", key),
+		Err:  err,
+	}
+}
+
+// ErrInternalService is used when the error comes from an internal system.
+func ErrInternalService(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
@@ -0,0 +1,155 @@
+package featureflag
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixOverrides is the prefix of the feature flag override API.
+	PrefixOverrides = "/api/v2/flags/overrides"
+)
+
+// Handler is the HTTP API handler for the overrides of feature flags.
+type Handler struct {
+	chi.Router
+	api *kithttp.API
+	log *zap.Logger
+	svc *Service
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, svc *Service) *Handler {
+	h := &Handler{
+		api: kithttp.NewAPI(kithttp.WithLog(log)),
+		log: log,
+		svc: svc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Get("/", h.handleGetOverrides)
+
+		r.Route("/{key}", func(r chi.Router) {
+			r.Put("/", h.handlePutOverride)
+			r.Delete("/", h.handleDeleteOverride)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixOverrides
+}
+
+type overridesResponse struct {
+	Links     map[string]string `json:"links"`
+	Overrides []*Override       `json:"overrides"`
+}
+
+type putOverrideRequest struct {
+	OrgID *influxdb.ID    `json:"orgID,omitempty"`
+	Value json.RawMessage `json:"value"`
+}
+
+// orgIDParam returns the orgID query parameter, nil if it is not set.
+func orgIDParam(r *http.Request) (*influxdb.ID, error) {
+	v := r.URL.Query().Get("orgID")
+	if v == "" {
+		return nil, nil
+	}
+	id, err := influxdb.IDFromString(v)
+	if err != nil {
+		return nil, influxdb.ErrCorruptID(err)
+	}
+	return id, nil
+}
+
+func (h *Handler) handleGetOverrides(w http.ResponseWriter, r *http.Request) {
+	orgID, err := orgIDParam(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	overrides, err := h.svc.FindOverrides(r.Context(), orgID)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, overridesResponse{
+		Links:     map[string]string{"self": PrefixOverrides},
+		Overrides: overrides,
+	})
+}
+
+func (h *Handler) handlePutOverride(w http.ResponseWriter, r *http.Request) {
+	var req putOverrideRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	o := &Override{
+		Key:   chi.URLParam(r, "key"),
+		OrgID: req.OrgID,
+	}
+	if len(req.Value) == 0 {
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "value is required",
+		})
+		return
+	}
+	if err := json.Unmarshal(req.Value, &o.Value); err != nil {
+		h.api.Err(w, r, ErrInvalidValue(o.Key, err))
+		return
+	}
+
+	if err := h.svc.PutOverride(r.Context(), o); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Info("Feature flag overridden", zap.String("key", o.Key), zap.Any("value", o.Value), zap.Stringp("orgID", orgString(o.OrgID)))
+
+	h.api.Respond(w, r, http.StatusOK, o)
+}
+
+func (h *Handler) handleDeleteOverride(w http.ResponseWriter, r *http.Request) {
+	orgID, err := orgIDParam(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	key := chi.URLParam(r, "key")
+	if err := h.svc.DeleteOverride(r.Context(), key, orgID); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Info("Feature flag override removed", zap.String("key", key), zap.Stringp("orgID", orgString(orgID)))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
+
+func orgString(id *influxdb.ID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
+}
@@ -0,0 +1,372 @@
+// Package featureflag overrides the values of feature flags at runtime, for
+// all the requests or for those of the authorizations of an organization, so
+// that features are canaried and rolled back without restarting influxd. The
+// overrides are set by operators through the HTTP API, stored in the kv
+// store, and applied over the values of the flagger configured at startup.
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/feature"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap"
+)
+
+var overrideBucket = []byte("featureflagoverridesv1")
+
+// Override is the value of a feature flag set at runtime. It applies to the
+// requests of the authorizations of the organization OrgID, or to all the
+// requests when OrgID is not set. The overrides of an organization take
+// precedence over those of all the requests.
+type Override struct {
+	Key   string       `json:"key"`
+	OrgID *influxdb.ID `json:"orgID,omitempty"`
+	Value interface{}  `json:"value"`
+	influxdb.CRUDLog
+}
+
+var _ feature.Flagger = (*Service)(nil)
+
+// Service stores the overrides of feature flags in a kv store, and computes
+// the flags of requests with them. It keeps the overrides in memory, so that
+// computing flags does not read the store.
+type Service struct {
+	log   *zap.Logger
+	store kv.Store
+	base  feature.Flagger
+	byKey feature.ByKeyFn
+
+	TimeGenerator influxdb.TimeGenerator
+
+	mu     sync.RWMutex
+	global map[string]interface{}
+	orgs   map[influxdb.ID]map[string]interface{}
+}
+
+// NewService constructs a service storing the overrides in st and applying
+// them over the flags computed by base. The flags are looked up with byKey.
+func NewService(log *zap.Logger, st kv.Store, base feature.Flagger, byKey feature.ByKeyFn) *Service {
+	if byKey == nil {
+		byKey = feature.ByKey
+	}
+	return &Service{
+		log:           log,
+		store:         st,
+		base:          base,
+		byKey:         byKey,
+		TimeGenerator: influxdb.RealTimeGenerator{},
+		global:        make(map[string]interface{}),
+		orgs:          make(map[influxdb.ID]map[string]interface{}),
+	}
+}
+
+// Load reads the overrides of the store. The overrides of flags that no
+// longer exist, or whose type changed, are ignored.
+func (s *Service) Load(ctx context.Context) error {
+	var overrides []*Override
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		overrides, err = findOverrides(tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, o := range overrides {
+		flag, ok := s.byKey(o.Key)
+		if !ok {
+			s.log.Warn("Ignoring override of unknown feature flag", zap.String("key", o.Key))
+			continue
+		}
+		v, err := coerce(flag, o.Value)
+		if err != nil {
+			s.log.Warn("Ignoring invalid override of feature flag", zap.String("key", o.Key), zap.Error(err))
+			continue
+		}
+		s.set(o.Key, o.OrgID, v)
+	}
+	return nil
+}
+
+// Flags returns the flags computed by the base flagger, with the overrides
+// of all the requests and of the organization of the authorization of ctx
+// applied.
+func (s *Service) Flags(ctx context.Context, flags ...feature.Flag) (map[string]interface{}, error) {
+	m, err := s.base.Flags(ctx, flags...)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	apply(m, s.global)
+	if orgID, ok := orgFromContext(ctx); ok {
+		apply(m, s.orgs[orgID])
+	}
+	return m, nil
+}
+
+// apply sets the values of the flags of m that are overridden.
+func apply(m, overrides map[string]interface{}) {
+	for k, v := range overrides {
+		if _, ok := m[k]; ok {
+			m[k] = v
+		}
+	}
+}
+
+// orgFromContext returns the organization of the authorization of ctx. The
+// requests of sessions have none.
+func orgFromContext(ctx context.Context) (influxdb.ID, bool) {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return 0, false
+	}
+	auth, ok := a.(*influxdb.Authorization)
+	if !ok || !auth.OrgID.Valid() {
+		return 0, false
+	}
+	return auth.OrgID, true
+}
+
+// FindOverrides returns the overrides, only those of the organization when
+// orgID is set. Overrides are visible to operators.
+func (s *Service) FindOverrides(ctx context.Context, orgID *influxdb.ID) ([]*Override, error) {
+	if err := authorizer.IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+
+	var overrides []*Override
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		overrides, err = findOverrides(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if orgID == nil {
+		return overrides, nil
+	}
+
+	filtered := overrides[:0]
+	for _, o := range overrides {
+		if o.OrgID != nil && *o.OrgID == *orgID {
+			filtered = append(filtered, o)
+		}
+	}
+	return filtered, nil
+}
+
+// PutOverride sets the override of the flag of o, which takes effect on the
+// following requests. The value must be of the type of the flag. Only
+// operators set overrides.
+func (s *Service) PutOverride(ctx context.Context, o *Override) error {
+	if err := authorizer.IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	flag, ok := s.byKey(o.Key)
+	if !ok {
+		return ErrFlagNotFound(o.Key)
+	}
+	if o.OrgID != nil && !o.OrgID.Valid() {
+		return ErrInvalidOrgID
+	}
+	v, err := coerce(flag, o.Value)
+	if err != nil {
+		return ErrInvalidValue(o.Key, err)
+	}
+	o.Value = v
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err = s.store.Update(ctx, func(tx kv.Tx) error {
+		key := overrideKey(o.Key, o.OrgID)
+		now := s.TimeGenerator.Now().UTC()
+		o.CreatedAt, o.UpdatedAt = now, now
+		if current, err := findOverride(tx, key); err == nil {
+			o.CreatedAt = current.CreatedAt
+		} else if err != ErrOverrideNotFound {
+			return err
+		}
+		return putOverride(tx, key, o)
+	})
+	if err != nil {
+		return err
+	}
+	s.set(o.Key, o.OrgID, v)
+	return nil
+}
+
+// DeleteOverride removes the override of the flag, of the organization when
+// orgID is set. Only operators remove overrides.
+func (s *Service) DeleteOverride(ctx context.Context, key string, orgID *influxdb.ID) error {
+	if err := authorizer.IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		k := overrideKey(key, orgID)
+		if _, err := findOverride(tx, k); err != nil {
+			return err
+		}
+		b, err := tx.Bucket(overrideBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		if err := b.Delete(k); err != nil {
+			return ErrInternalService(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if orgID == nil {
+		delete(s.global, key)
+		return nil
+	}
+	delete(s.orgs[*orgID], key)
+	if len(s.orgs[*orgID]) == 0 {
+		delete(s.orgs, *orgID)
+	}
+	return nil
+}
+
+// set sets the override in memory. The caller must hold the lock of s.
+func (s *Service) set(key string, orgID *influxdb.ID, v interface{}) {
+	if orgID == nil {
+		s.global[key] = v
+		return
+	}
+	m, ok := s.orgs[*orgID]
+	if !ok {
+		m = make(map[string]interface{})
+		s.orgs[*orgID] = m
+	}
+	m[key] = v
+}
+
+// coerce returns v as a value of the type of the values of flag. Numbers
+// decoded from JSON are converted to the 32-bit integers of integer flags.
+func coerce(flag feature.Flag, v interface{}) (interface{}, error) {
+	switch flag.Default().(type) {
+	case bool:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+		return nil, fmt.Errorf("expected a boolean, got %T", v)
+	case int, int32:
+		switch n := v.(type) {
+		case int32:
+			return n, nil
+		case float64:
+			if n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32 {
+				return int32(n), nil
+			}
+		}
+		return nil, fmt.Errorf("expected a 32-bit integer, got %v", v)
+	case float64:
+		if n, ok := v.(float64); ok {
+			return n, nil
+		}
+		return nil, fmt.Errorf("expected a number, got %T", v)
+	default:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("expected a string, got %T", v)
+	}
+}
+
+// overrideKey returns the key of the override of a flag, <key> for all the
+// requests and <key>/<orgID> for those of an organization. Flag keys do not
+// contain slashes.
+func overrideKey(key string, orgID *influxdb.ID) []byte {
+	if orgID == nil {
+		return []byte(key)
+	}
+	return []byte(key + "/" + orgID.String())
+}
+
+func findOverride(tx kv.Tx, key []byte) (*Override, error) {
+	b, err := tx.Bucket(overrideBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(key)
+	if kv.IsNotFound(err) {
+		return nil, ErrOverrideNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	var o Override
+	if err := json.Unmarshal(v, &o); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return &o, nil
+}
+
+func findOverrides(tx kv.Tx) ([]*Override, error) {
+	b, err := tx.Bucket(overrideBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	defer cur.Close()
+
+	overrides := []*Override{}
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		var o Override
+		if err := json.Unmarshal(v, &o); err != nil {
+			return nil, ErrInternalService(err)
+		}
+		overrides = append(overrides, &o)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, ErrInternalService(err)
+	}
+
+	// the overrides of all the requests are listed first.
+	sort.SliceStable(overrides, func(i, j int) bool {
+		if (overrides[i].OrgID == nil) != (overrides[j].OrgID == nil) {
+			return overrides[i].OrgID == nil
+		}
+		return overrides[i].Key < overrides[j].Key
+	})
+	return overrides, nil
+}
+
+func putOverride(tx kv.Tx, key []byte, o *Override) error {
+	v, err := json.Marshal(o)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	b, err := tx.Bucket(overrideBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(key, v); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package featureflag_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/featureflag"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kit/feature"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"go.uber.org/zap/zaptest"
+)
+
+var (
+	boolFlag = feature.MakeBoolFlag("Bool", "boolFlag", "", false, feature.Temporary, false)
+	intFlag  = feature.MakeIntFlag("Int", "intFlag", "", 1, feature.Temporary, false)
+)
+
+func byKey(k string) (feature.Flag, bool) {
+	switch k {
+	case boolFlag.Key():
+		return boolFlag, true
+	case intFlag.Key():
+		return intFlag, true
+	}
+	return nil, false
+}
+
+// defaultFlagger returns the defaults of the test flags.
+type defaultFlagger struct{}
+
+func (defaultFlagger) Flags(_ context.Context, flags ...feature.Flag) (map[string]interface{}, error) {
+	if len(flags) == 0 {
+		flags = []feature.Flag{boolFlag, intFlag}
+	}
+	m := make(map[string]interface{}, len(flags))
+	for _, f := range flags {
+		m[f.Key()] = f.Default()
+	}
+	return m, nil
+}
+
+func newTestStore(t *testing.T) kv.Store {
+	t.Helper()
+
+	s := inmem.NewKVStore()
+	if err := all.Up(context.Background(), zaptest.NewLogger(t), s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func authContext(orgID influxdb.ID, permissions ...influxdb.Permission) context.Context {
+	return icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		OrgID:       orgID,
+		UserID:      1,
+		Status:      influxdb.Active,
+		Permissions: permissions,
+	})
+}
+
+func TestService_Overrides(t *testing.T) {
+	var (
+		store    = newTestStore(t)
+		orgID    = influxdb.ID(0x10)
+		operator = authContext(0x20, influxdb.OperPermissions()...)
+		svc      = featureflag.NewService(zaptest.NewLogger(t), store, defaultFlagger{}, byKey)
+	)
+
+	if err := svc.PutOverride(authContext(orgID), &featureflag.Override{Key: boolFlag.Key(), Value: true}); influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+		t.Fatalf("expected only operators to override flags, got %v", err)
+	}
+	if err := svc.PutOverride(operator, &featureflag.Override{Key: intFlag.Key(), Value: "two"}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected a value of another type to be rejected, got %v", err)
+	}
+	if err := svc.PutOverride(operator, &featureflag.Override{Key: "unknown", Value: true}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected an unknown flag to be rejected, got %v", err)
+	}
+
+	if err := svc.PutOverride(operator, &featureflag.Override{Key: boolFlag.Key(), Value: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.PutOverride(operator, &featureflag.Override{Key: intFlag.Key(), OrgID: &orgID, Value: float64(3)}); err != nil {
+		t.Fatal(err)
+	}
+
+	flags := func(ctx context.Context, svc feature.Flagger) map[string]interface{} {
+		t.Helper()
+		m, err := svc.Flags(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	want := map[string]interface{}{"boolFlag": true, "intFlag": int32(3)}
+	if diff := cmp.Diff(want, flags(authContext(orgID), svc)); diff != "" {
+		t.Errorf("unexpected flags of the organization, -want/+got:\n%s", diff)
+	}
+	if got := intFlag.Int(authContext(orgID), svc); got != 3 {
+		t.Errorf("expected the integer flag of the organization to be 3, got %d", got)
+	}
+	want = map[string]interface{}{"boolFlag": true, "intFlag": int32(1)}
+	if diff := cmp.Diff(want, flags(context.Background(), svc)); diff != "" {
+		t.Errorf("unexpected flags of other requests, -want/+got:\n%s", diff)
+	}
+
+	// the overrides are loaded by another service of the store.
+	reloaded := featureflag.NewService(zaptest.NewLogger(t), store, defaultFlagger{}, byKey)
+	if err := reloaded.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]interface{}{"boolFlag": true, "intFlag": int32(3)}
+	if diff := cmp.Diff(want, flags(authContext(orgID), reloaded)); diff != "" {
+		t.Errorf("unexpected flags once reloaded, -want/+got:\n%s", diff)
+	}
+
+	overrides, err := svc.FindOverrides(operator, &orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overrides) != 1 || overrides[0].Key != intFlag.Key() {
+		t.Errorf("unexpected overrides of the organization %+v", overrides)
+	}
+
+	if err := svc.DeleteOverride(operator, intFlag.Key(), &orgID); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DeleteOverride(operator, intFlag.Key(), &orgID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected the deleted override not to be found, got %v", err)
+	}
+	want = map[string]interface{}{"boolFlag": true, "intFlag": int32(1)}
+	if diff := cmp.Diff(want, flags(authContext(orgID), svc)); diff != "" {
+		t.Errorf("unexpected flags once the override is deleted, -want/+got:\n%s", diff)
+	}
+}
@@ -0,0 +1,66 @@
+package influxdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// fluxPackageNameRegexp matches names usable as Flux identifiers, so a
+// package can be imported without an alias.
+var fluxPackageNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// FluxPackage is a versioned package of reusable Flux definitions belonging
+// to an organization. Versions are immutable; publishing a package with the
+// name of an existing one creates its next version.
+type FluxPackage struct {
+	ID          ID     `json:"id,omitempty"`
+	OrgID       ID     `json:"orgID"`
+	Name        string `json:"name"`
+	Version     int    `json:"version"`
+	Description string `json:"description,omitempty"`
+	Source      string `json:"source"`
+	CRUDLog
+}
+
+// Valid returns an error if the package is missing required fields or its
+// name is not a valid Flux identifier.
+func (p *FluxPackage) Valid() error {
+	if !p.OrgID.Valid() {
+		return errors.New("orgID is required")
+	}
+	if !fluxPackageNameRegexp.MatchString(p.Name) {
+		return fmt.Errorf("invalid package name %q, must be a valid Flux identifier", p.Name)
+	}
+	if p.Source == "" {
+		return errors.New("source is required")
+	}
+	return nil
+}
+
+// FluxPackageFilter represents a set of filters that restrict the returned packages.
+type FluxPackageFilter struct {
+	OrgID *ID
+	Name  *string
+}
+
+// FluxPackageService stores user-defined Flux packages.
+type FluxPackageService interface {
+	// CreateFluxPackage publishes the next version of the package named p.Name.
+	CreateFluxPackage(ctx context.Context, p *FluxPackage) error
+
+	// FindFluxPackageByID returns a single package version by ID.
+	FindFluxPackageByID(ctx context.Context, id ID) (*FluxPackage, error)
+
+	// FindFluxPackage returns the given version of a package, or its latest
+	// version if version is 0.
+	FindFluxPackage(ctx context.Context, orgID ID, name string, version int) (*FluxPackage, error)
+
+	// FindFluxPackages returns a list of package versions that match filter
+	// and the total count of matching versions.
+	FindFluxPackages(ctx context.Context, filter FluxPackageFilter, opt ...FindOptions) ([]*FluxPackage, int, error)
+
+	// DeleteFluxPackage removes a single package version by ID.
+	DeleteFluxPackage(ctx context.Context, id ID) error
+}
@@ -0,0 +1,58 @@
+// Package fluxfmt formats the Flux scripts of tasks and checks into their
+// canonical form when they are saved, so that the diffs of the scripts kept
+// in version control are not dominated by whitespace.
+//
+// The scripts of notification rules and the tasks of checks are generated
+// from their fields and are always stored in the canonical form.
+package fluxfmt
+
+import (
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+// Format returns the canonical form of script. The formatter does not keep
+// comments, so scripts with comments are not formatted and an EInvalid
+// error is returned, as it is for scripts that do not parse.
+func Format(lang influxdb.FluxLanguageService, script string) (string, error) {
+	pkg, err := query.Parse(lang, script)
+	if err != nil {
+		return "", &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid flux script",
+			Err:  err,
+		}
+	}
+	if hasComments(script) {
+		return "", &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "flux scripts with comments cannot be formatted without losing them",
+		}
+	}
+	if len(pkg.Files) != 1 {
+		return ast.Format(pkg), nil
+	}
+	return ast.Format(pkg.Files[0]), nil
+}
+
+// hasComments returns true if script has a comment, that is two slashes
+// outside of a string literal. Slashes in regular expressions are taken for
+// comments, so that a script is never formatted at the expense of one.
+func hasComments(script string) bool {
+	inString, escaped := false, false
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case inString && escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case !inString && c == '/' && i+1 < len(script) && script[i+1] == '/':
+			return true
+		}
+	}
+	return false
+}
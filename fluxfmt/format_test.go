@@ -0,0 +1,62 @@
+package fluxfmt_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/fluxfmt"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "whitespace",
+			script: "from(bucket:\"b\")   |>range(start:-1h)\n\n\n",
+			want:   "from(bucket: \"b\")\n\t|> range(start: -1h)",
+		},
+		{
+			name:   "slashes in strings",
+			script: `import "http" http.post(url:"http://example.com")`,
+			want:   "import \"http\"\n\nhttp.post(url: \"http://example.com\")",
+		},
+		{
+			name:    "comments",
+			script:  "// every hour\nfrom(bucket: \"b\")",
+			wantErr: true,
+		},
+		{
+			name:    "syntax error",
+			script:  "from(bucket: ",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fluxfmt.Format(fluxlang.DefaultService, tt.script)
+			if tt.wantErr {
+				if influxdb.ErrorCode(err) != influxdb.EInvalid {
+					t.Fatalf("expected invalid error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("unexpected script:\n%s\nwant:\n%s", got, tt.want)
+			}
+
+			again, err := fluxfmt.Format(fluxlang.DefaultService, got)
+			if err != nil || again != got {
+				t.Errorf("formatting is not idempotent: %q, %v", again, err)
+			}
+		})
+	}
+}
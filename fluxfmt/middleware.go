@@ -0,0 +1,94 @@
+package fluxfmt
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/check"
+)
+
+var _ influxdb.TaskService = (*TaskService)(nil)
+
+// TaskService formats the scripts of tasks before they are saved.
+type TaskService struct {
+	influxdb.TaskService
+	lang influxdb.FluxLanguageService
+}
+
+// NewTaskService wraps s.
+func NewTaskService(lang influxdb.FluxLanguageService, s influxdb.TaskService) *TaskService {
+	return &TaskService{TaskService: s, lang: lang}
+}
+
+// CreateTask creates a task with the canonical form of its script.
+func (s *TaskService) CreateTask(ctx context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+	tc.Flux = s.format(tc.Flux)
+	return s.TaskService.CreateTask(ctx, tc)
+}
+
+// UpdateTask updates a task with the canonical form of its new script.
+func (s *TaskService) UpdateTask(ctx context.Context, id influxdb.ID, upd influxdb.TaskUpdate) (*influxdb.Task, error) {
+	if upd.Flux != nil {
+		script := s.format(*upd.Flux)
+		upd.Flux = &script
+	}
+	return s.TaskService.UpdateTask(ctx, id, upd)
+}
+
+// format returns the canonical form of script. Scripts that cannot be
+// formatted are saved as they are and left to the validation of the wrapped
+// service.
+func (s *TaskService) format(script string) string {
+	formatted, err := Format(s.lang, script)
+	if err != nil {
+		return script
+	}
+	return formatted
+}
+
+var _ influxdb.CheckService = (*CheckService)(nil)
+
+// CheckService formats the queries of checks before they are saved.
+type CheckService struct {
+	influxdb.CheckService
+	lang influxdb.FluxLanguageService
+}
+
+// NewCheckService wraps s.
+func NewCheckService(lang influxdb.FluxLanguageService, s influxdb.CheckService) *CheckService {
+	return &CheckService{CheckService: s, lang: lang}
+}
+
+// CreateCheck creates a check with the canonical form of its query.
+func (s *CheckService) CreateCheck(ctx context.Context, c influxdb.CheckCreate, userID influxdb.ID) error {
+	s.format(c.Check)
+	return s.CheckService.CreateCheck(ctx, c, userID)
+}
+
+// UpdateCheck updates a check with the canonical form of its new query.
+func (s *CheckService) UpdateCheck(ctx context.Context, id influxdb.ID, c influxdb.CheckCreate) (influxdb.Check, error) {
+	s.format(c.Check)
+	return s.CheckService.UpdateCheck(ctx, id, c)
+}
+
+// format replaces the query of c with its canonical form. Queries that
+// cannot be formatted are saved as they are.
+func (s *CheckService) format(c influxdb.Check) {
+	var q *influxdb.DashboardQuery
+	switch c := c.(type) {
+	case *check.Custom:
+		q = &c.Query
+	case *check.Deadman:
+		q = &c.Query
+	case *check.Threshold:
+		q = &c.Query
+	default:
+		return
+	}
+
+	formatted, err := Format(s.lang, q.Text)
+	if err != nil {
+		return
+	}
+	q.Text = formatted
+}
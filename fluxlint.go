@@ -0,0 +1,38 @@
+package influxdb
+
+import "strings"
+
+// Kinds of the problems found in Flux scripts when they are saved.
+const (
+	FluxProblemSyntax = "syntax"
+	FluxProblemType   = "type"
+	FluxProblemBucket = "bucket"
+	FluxProblemSecret = "secret"
+	// FluxProblemImport problems are imports of user-defined packages that
+	// cannot be resolved.
+	FluxProblemImport = "import"
+	// FluxProblemConnection problems are calls to external systems with
+	// credentials embedded in the script, or with connection profiles they
+	// cannot use.
+	FluxProblemConnection = "connection"
+)
+
+// FluxProblem is a problem found in a Flux script that would make it fail
+// when it runs.
+type FluxProblem struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// FluxProblems is the error returned when a Flux script with problems is
+// saved. It is wrapped in an *Error with the EInvalid code.
+type FluxProblems []FluxProblem
+
+// Error joins the messages of the problems.
+func (p FluxProblems) Error() string {
+	msgs := make([]string, 0, len(p))
+	for _, pr := range p {
+		msgs = append(msgs, pr.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
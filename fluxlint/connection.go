@@ -0,0 +1,149 @@
+package fluxlint
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/connection"
+	"github.com/influxdata/influxdb/v2/task/taskauth"
+)
+
+// lintConnections returns the problems of the calls of the script in pkg to
+// external systems.
+func (l *Linter) lintConnections(ctx context.Context, orgID influxdb.ID, pkg *ast.Package) (influxdb.FluxProblems, error) {
+	ext := taskauth.AnalyzeExternal(pkg)
+	c := &connectionLinter{
+		Linter:   l,
+		orgID:    orgID,
+		profiles: make(map[string]*influxdb.ConnectionProfile),
+	}
+	for _, call := range ext.Calls {
+		if err := c.call(ctx, call); err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range ext.StraySecretKeys {
+		p, err := c.profile(ctx, k)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			c.problemf("the secret of connection profile %q can only be passed to the calls of sql and http functions", p.Name)
+		}
+	}
+	return c.problems, nil
+}
+
+type connectionLinter struct {
+	*Linter
+	orgID influxdb.ID
+	// profiles are the profiles of the organization found by the keys of
+	// their secrets, nil if they do not exist.
+	profiles map[string]*influxdb.ConnectionProfile
+	problems influxdb.FluxProblems
+}
+
+func (c *connectionLinter) call(ctx context.Context, call taskauth.ExternalCall) error {
+	if embedsCredentials(call) {
+		c.problemf("the credentials passed to %s are embedded in the script, store them in a connection profile", call.Func)
+	}
+	for name, v := range call.Headers {
+		if strings.EqualFold(name, "Authorization") && v != "" {
+			c.problemf("the Authorization header passed to %s is embedded in the script, store it in a connection profile", call.Func)
+		}
+	}
+
+	if call.TargetSecret != "" {
+		p, err := c.profile(ctx, call.TargetSecret)
+		if err != nil {
+			return err
+		}
+		switch {
+		case p == nil:
+		case call.Kind != influxdb.ConnectionKindSQL || p.Kind != influxdb.ConnectionKindSQL:
+			c.problemf("connection profile %q of kind %s cannot be the target of %s", p.Name, p.Kind, call.Func)
+		case call.Driver != p.Driver:
+			c.problemf("%s must use the driver %q of connection profile %q", call.Func, p.Driver, p.Name)
+		}
+	}
+
+	for _, k := range call.HeaderSecrets {
+		p, err := c.profile(ctx, k)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			continue
+		}
+		if p.Kind != influxdb.ConnectionKindHTTP {
+			c.problemf("connection profile %q of kind %s cannot be a header of %s", p.Name, p.Kind, call.Func)
+			continue
+		}
+		u, err := url.Parse(call.Target)
+		if call.Target == "" || err != nil {
+			c.problemf("the url of %s must be a string literal to use connection profile %q", call.Func, p.Name)
+			continue
+		}
+		if !p.AllowsHost(u.Hostname()) {
+			c.problemf("connection profile %q does not allow host %q", p.Name, u.Hostname())
+		}
+	}
+	return nil
+}
+
+// profile returns the connection profile of the organization whose secret is
+// stored at key. A problem is recorded if the key is the key of the secret
+// of a profile that does not exist, and nil is returned.
+func (c *connectionLinter) profile(ctx context.Context, key string) (*influxdb.ConnectionProfile, error) {
+	id, ok := influxdb.ConnectionProfileIDFromSecretKey(key)
+	if !ok {
+		return nil, nil
+	}
+	if p, ok := c.profiles[key]; ok {
+		return p, nil
+	}
+
+	p, err := c.connections.FindConnectionProfileByID(ctx, id)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound || (err == nil && p.OrgID != c.orgID) {
+		c.problemf("connection profile %s does not exist", id)
+		p, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.profiles[key] = p
+	return p, nil
+}
+
+func (c *connectionLinter) problemf(format string, args ...interface{}) {
+	c.problems = append(c.problems, influxdb.FluxProblem{
+		Kind:    influxdb.FluxProblemConnection,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// embedsCredentials returns true if the literal data source name or URL of
+// call holds a password.
+func embedsCredentials(call taskauth.ExternalCall) bool {
+	if call.Target == "" {
+		return false
+	}
+	var (
+		u   *url.URL
+		err error
+	)
+	if call.Kind == influxdb.ConnectionKindSQL {
+		u, err = connection.ParseDataSource(call.Driver, call.Target)
+	} else {
+		u, err = url.Parse(call.Target)
+	}
+	if err != nil || u.User == nil {
+		return false
+	}
+	_, ok := u.User.Password()
+	return ok
+}
@@ -0,0 +1,166 @@
+// Package fluxlint checks the Flux scripts of tasks, checks and notification
+// rules when they are saved, so that scripts that would fail on their first
+// scheduled run are rejected with the problems found in them.
+//
+// Scripts are parsed and type checked, and the buckets and secrets they
+// reference must exist in the organization. The user-defined packages they
+// import are resolved first, so that the definitions of the packages are
+// checked along with the script. Buckets and secrets are only
+// checked when they can be determined statically, see taskauth.Analyze.
+//
+// Scripts connecting to SQL databases or HTTP APIs must not embed
+// credentials, and must use the connection profiles whose secrets they read
+// as the profiles allow, see taskauth.AnalyzeExternal.
+package fluxlint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/fluxpkg"
+	"github.com/influxdata/influxdb/v2/task/taskauth"
+)
+
+// Linter finds the problems of Flux scripts.
+type Linter struct {
+	lang        influxdb.FluxLanguageService
+	resolver    *fluxpkg.Resolver
+	buckets     influxdb.BucketService
+	sb          influxdb.SystemBuckets
+	secrets     influxdb.SecretService
+	connections influxdb.ConnectionProfileService
+}
+
+// NewLinter returns a linter. The package, bucket, secret and connection
+// profile services must not perform authorization themselves. The bucket
+// service serves the system buckets sb.
+func NewLinter(lang influxdb.FluxLanguageService, pkgs influxdb.FluxPackageService, buckets influxdb.BucketService, sb influxdb.SystemBuckets, secrets influxdb.SecretService, connections influxdb.ConnectionProfileService) *Linter {
+	return &Linter{
+		lang:        lang,
+		resolver:    fluxpkg.NewResolver(pkgs),
+		buckets:     buckets,
+		sb:          sb,
+		secrets:     secrets,
+		connections: connections,
+	}
+}
+
+// Lint returns an EInvalid error wrapping influxdb.FluxProblems if script
+// would fail to run in the organization.
+func (l *Linter) Lint(ctx context.Context, orgID influxdb.ID, script string) error {
+	pkg, err := l.lang.Parse(script)
+	if err != nil {
+		return problemsError(influxdb.FluxProblems{{Kind: influxdb.FluxProblemSyntax, Message: err.Error()}})
+	}
+
+	if importsPackages(pkg) {
+		if err := l.resolver.Resolve(ctx, orgID, pkg); err != nil {
+			return problemsError(influxdb.FluxProblems{{Kind: influxdb.FluxProblemImport, Message: err.Error()}})
+		}
+		script = ast.Format(pkg)
+	}
+
+	var problems influxdb.FluxProblems
+	if err := l.lang.Analyze(script); err != nil {
+		problems = append(problems, influxdb.FluxProblem{Kind: influxdb.FluxProblemType, Message: err.Error()})
+	}
+
+	connProblems, err := l.lintConnections(ctx, orgID, pkg)
+	if err != nil {
+		return err
+	}
+	problems = append(problems, connProblems...)
+
+	scope, err := taskauth.Analyze(pkg, l.sb)
+	if errors.Is(err, taskauth.ErrUnscopable) {
+		return problemsError(problems)
+	}
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[taskauth.BucketRef]bool)
+	for _, ref := range append(scope.Read, scope.Write...) {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+
+		msg, err := l.findBucket(ctx, orgID, ref)
+		if err != nil {
+			return err
+		}
+		if msg != "" {
+			problems = append(problems, influxdb.FluxProblem{Kind: influxdb.FluxProblemBucket, Message: msg})
+		}
+	}
+
+	if len(scope.SecretKeys) > 0 {
+		keys, err := l.secrets.GetSecretKeys(ctx, orgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+		exists := make(map[string]bool, len(keys))
+		for _, k := range keys {
+			exists[k] = true
+		}
+		for _, k := range scope.SecretKeys {
+			if !exists[k] {
+				problems = append(problems, influxdb.FluxProblem{
+					Kind:    influxdb.FluxProblemSecret,
+					Message: fmt.Sprintf("secret %q does not exist", k),
+				})
+			}
+		}
+	}
+	return problemsError(problems)
+}
+
+// findBucket returns why the referenced bucket cannot be accessed by the
+// organization, or an empty string if it can.
+func (l *Linter) findBucket(ctx context.Context, orgID influxdb.ID, ref taskauth.BucketRef) (string, error) {
+	if ref.Name != "" {
+		_, err := l.buckets.FindBucketByName(ctx, orgID, ref.Name)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return fmt.Sprintf("bucket %q does not exist", ref.Name), nil
+		}
+		return "", err
+	}
+
+	var id influxdb.ID
+	if err := id.DecodeFromString(ref.ID); err != nil {
+		return fmt.Sprintf("bucket ID %q is invalid", ref.ID), nil
+	}
+	b, err := l.buckets.FindBucketByID(ctx, id)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound || (err == nil && b.OrgID != orgID) {
+		return fmt.Sprintf("bucket %s does not exist", ref.ID), nil
+	}
+	return "", err
+}
+
+// importsPackages returns whether pkg imports user-defined packages.
+func importsPackages(pkg *ast.Package) bool {
+	for _, file := range pkg.Files {
+		for _, imp := range file.Imports {
+			if strings.HasPrefix(imp.Path.Value, fluxpkg.ImportPrefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func problemsError(problems influxdb.FluxProblems) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "flux script would fail to run",
+		Err:  problems,
+	}
+}
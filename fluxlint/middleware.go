@@ -0,0 +1,138 @@
+package fluxlint
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.TaskService = (*TaskService)(nil)
+
+// TaskService lints the scripts of tasks before they are saved.
+type TaskService struct {
+	influxdb.TaskService
+	linter *Linter
+}
+
+// NewTaskService wraps s.
+func NewTaskService(linter *Linter, s influxdb.TaskService) *TaskService {
+	return &TaskService{TaskService: s, linter: linter}
+}
+
+// CreateTask creates a task if its script has no problems.
+func (s *TaskService) CreateTask(ctx context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+	if err := s.linter.Lint(ctx, tc.OrganizationID, tc.Flux); err != nil {
+		return nil, err
+	}
+	return s.TaskService.CreateTask(ctx, tc)
+}
+
+// UpdateTask updates a task if its new script has no problems.
+func (s *TaskService) UpdateTask(ctx context.Context, id influxdb.ID, upd influxdb.TaskUpdate) (*influxdb.Task, error) {
+	if upd.Flux != nil {
+		t, err := s.TaskService.FindTaskByID(influxdb.FindTaskWithoutAuth(ctx), id)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.linter.Lint(ctx, t.OrganizationID, *upd.Flux); err != nil {
+			return nil, err
+		}
+	}
+	return s.TaskService.UpdateTask(ctx, id, upd)
+}
+
+var _ influxdb.CheckService = (*CheckService)(nil)
+
+// CheckService lints the scripts generated for checks before they are saved.
+type CheckService struct {
+	influxdb.CheckService
+	linter *Linter
+}
+
+// NewCheckService wraps s.
+func NewCheckService(linter *Linter, s influxdb.CheckService) *CheckService {
+	return &CheckService{CheckService: s, linter: linter}
+}
+
+// CreateCheck creates a check if its script has no problems.
+func (s *CheckService) CreateCheck(ctx context.Context, c influxdb.CheckCreate, userID influxdb.ID) error {
+	if err := s.lint(ctx, c.GetOrgID(), c); err != nil {
+		return err
+	}
+	return s.CheckService.CreateCheck(ctx, c, userID)
+}
+
+// UpdateCheck updates a check if its new script has no problems.
+func (s *CheckService) UpdateCheck(ctx context.Context, id influxdb.ID, c influxdb.CheckCreate) (influxdb.Check, error) {
+	prev, err := s.CheckService.FindCheckByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.lint(ctx, prev.GetOrgID(), c); err != nil {
+		return nil, err
+	}
+	return s.CheckService.UpdateCheck(ctx, id, c)
+}
+
+// lint lints the script of c. Checks whose script cannot be generated are
+// left to the validation of the wrapped service.
+func (s *CheckService) lint(ctx context.Context, orgID influxdb.ID, c influxdb.CheckCreate) error {
+	script, err := c.GenerateFlux(s.linter.lang)
+	if err != nil {
+		return nil
+	}
+	return s.linter.Lint(ctx, orgID, script)
+}
+
+var _ influxdb.NotificationRuleStore = (*NotificationRuleStore)(nil)
+
+// NotificationRuleStore lints the scripts generated for notification rules
+// before they are saved.
+type NotificationRuleStore struct {
+	influxdb.NotificationRuleStore
+	linter    *Linter
+	endpoints influxdb.NotificationEndpointService
+}
+
+// NewNotificationRuleStore wraps s. The endpoint service must not perform
+// authorization itself.
+func NewNotificationRuleStore(linter *Linter, s influxdb.NotificationRuleStore, endpoints influxdb.NotificationEndpointService) *NotificationRuleStore {
+	return &NotificationRuleStore{NotificationRuleStore: s, linter: linter, endpoints: endpoints}
+}
+
+// CreateNotificationRule creates a notification rule if its script has no
+// problems.
+func (s *NotificationRuleStore) CreateNotificationRule(ctx context.Context, nr influxdb.NotificationRuleCreate, userID influxdb.ID) error {
+	if err := s.lint(ctx, nr.GetOrgID(), nr); err != nil {
+		return err
+	}
+	return s.NotificationRuleStore.CreateNotificationRule(ctx, nr, userID)
+}
+
+// UpdateNotificationRule updates a notification rule if its new script has
+// no problems.
+func (s *NotificationRuleStore) UpdateNotificationRule(ctx context.Context, id influxdb.ID, nr influxdb.NotificationRuleCreate, userID influxdb.ID) (influxdb.NotificationRule, error) {
+	prev, err := s.NotificationRuleStore.FindNotificationRuleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.lint(ctx, prev.GetOrgID(), nr); err != nil {
+		return nil, err
+	}
+	return s.NotificationRuleStore.UpdateNotificationRule(ctx, id, nr, userID)
+}
+
+// lint lints the script of nr. Rules whose endpoint cannot be found or
+// whose script cannot be generated are left to the validation of the
+// wrapped store.
+func (s *NotificationRuleStore) lint(ctx context.Context, orgID influxdb.ID, nr influxdb.NotificationRuleCreate) error {
+	e, err := s.endpoints.FindNotificationEndpointByID(ctx, nr.GetEndpointID())
+	if err != nil {
+		return nil
+	}
+	script, err := nr.GenerateFlux(e)
+	if err != nil {
+		return nil
+	}
+	return s.linter.Lint(ctx, orgID, script)
+}
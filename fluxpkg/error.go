@@ -0,0 +1,45 @@
+package fluxpkg
+
+import (
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrPackageNotFound is used when the specified package cannot be found.
+	ErrPackageNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "flux package not found",
+	}
+
+	// ErrInvalidPackageID is used when the ID of the package cannot be encoded.
+	ErrInvalidPackageID = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "flux package ID is invalid",
+	}
+)
+
+// ErrInvalidPackage is used when a service was provided an invalid package.
+func ErrInvalidPackage(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "flux package provided is invalid",
+		Err:  err,
+	}
+}
+
+// ErrInvalidImport is used when a query imports a package that cannot be resolved.
+func ErrInvalidImport(path string, err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "unable to import flux package " + path,
+		Err:  err,
+	}
+}
+
+// ErrInternalService is used when the error comes from an internal system.
+func ErrInternalService(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
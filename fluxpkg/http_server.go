@@ -0,0 +1,170 @@
+package fluxpkg
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixFluxPackages is the prefix of the flux package API.
+	PrefixFluxPackages = "/api/v2/fluxpackages"
+)
+
+// Handler is the HTTP API handler for flux packages.
+type Handler struct {
+	chi.Router
+	api    *kithttp.API
+	log    *zap.Logger
+	pkgSvc influxdb.FluxPackageService
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, pkgSvc influxdb.FluxPackageService) *Handler {
+	h := &Handler{
+		api:    kithttp.NewAPI(kithttp.WithLog(log)),
+		log:    log,
+		pkgSvc: pkgSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Post("/", h.handlePostPackage)
+		r.Get("/", h.handleGetPackages)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetPackage)
+			r.Delete("/", h.handleDeletePackage)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixFluxPackages
+}
+
+type packageResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.FluxPackage
+}
+
+func newPackageResponse(p *influxdb.FluxPackage) *packageResponse {
+	return &packageResponse{
+		Links: map[string]string{
+			"self":     fmt.Sprintf("%s/%s", PrefixFluxPackages, p.ID),
+			"versions": fmt.Sprintf("%s?orgID=%s&name=%s", PrefixFluxPackages, p.OrgID, p.Name),
+		},
+		FluxPackage: p,
+	}
+}
+
+type packagesResponse struct {
+	Links    map[string]string  `json:"links"`
+	Packages []*packageResponse `json:"packages"`
+}
+
+type postPackageRequest struct {
+	OrgID       influxdb.ID `json:"orgID"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Source      string      `json:"source"`
+}
+
+func (h *Handler) handlePostPackage(w http.ResponseWriter, r *http.Request) {
+	var req postPackageRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	p := &influxdb.FluxPackage{
+		OrgID:       req.OrgID,
+		Name:        req.Name,
+		Description: req.Description,
+		Source:      req.Source,
+	}
+	if err := h.pkgSvc.CreateFluxPackage(r.Context(), p); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Flux package published", zap.String("package", p.Name), zap.Int("version", p.Version))
+
+	h.api.Respond(w, r, http.StatusCreated, newPackageResponse(p))
+}
+
+func (h *Handler) handleGetPackages(w http.ResponseWriter, r *http.Request) {
+	var filter influxdb.FluxPackageFilter
+	q := r.URL.Query()
+	if v := q.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			h.api.Err(w, r, influxdb.ErrCorruptID(err))
+			return
+		}
+		filter.OrgID = id
+	}
+	if v := q.Get("name"); v != "" {
+		filter.Name = &v
+	}
+
+	pkgs, _, err := h.pkgSvc.FindFluxPackages(r.Context(), filter)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	resp := packagesResponse{
+		Links:    map[string]string{"self": PrefixFluxPackages},
+		Packages: make([]*packageResponse, 0, len(pkgs)),
+	}
+	for _, p := range pkgs {
+		resp.Packages = append(resp.Packages, newPackageResponse(p))
+	}
+	h.api.Respond(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleGetPackage(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	p, err := h.pkgSvc.FindFluxPackageByID(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newPackageResponse(p))
+}
+
+func (h *Handler) handleDeletePackage(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	if err := h.pkgSvc.DeleteFluxPackage(r.Context(), *id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Flux package deleted", zap.String("packageID", id.String()))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
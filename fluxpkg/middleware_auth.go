@@ -0,0 +1,69 @@
+package fluxpkg
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+var _ influxdb.FluxPackageService = (*AuthedService)(nil)
+
+// AuthedService requires read access to a package to import or view it and
+// write access to publish or delete it.
+type AuthedService struct {
+	s influxdb.FluxPackageService
+}
+
+// NewAuthedService wraps s with flux package authorization.
+func NewAuthedService(s influxdb.FluxPackageService) *AuthedService {
+	return &AuthedService{s: s}
+}
+
+func (s *AuthedService) CreateFluxPackage(ctx context.Context, p *influxdb.FluxPackage) error {
+	if _, _, err := authorizer.AuthorizeCreate(ctx, influxdb.FluxPackagesResourceType, p.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateFluxPackage(ctx, p)
+}
+
+func (s *AuthedService) FindFluxPackageByID(ctx context.Context, id influxdb.ID) (*influxdb.FluxPackage, error) {
+	p, err := s.s.FindFluxPackageByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.FluxPackagesResourceType, p.ID, p.OrgID); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *AuthedService) FindFluxPackage(ctx context.Context, orgID influxdb.ID, name string, version int) (*influxdb.FluxPackage, error) {
+	p, err := s.s.FindFluxPackage(ctx, orgID, name, version)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.FluxPackagesResourceType, p.ID, p.OrgID); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *AuthedService) FindFluxPackages(ctx context.Context, filter influxdb.FluxPackageFilter, opt ...influxdb.FindOptions) ([]*influxdb.FluxPackage, int, error) {
+	pkgs, _, err := s.s.FindFluxPackages(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return authorizer.AuthorizeFindFluxPackages(ctx, pkgs)
+}
+
+func (s *AuthedService) DeleteFluxPackage(ctx context.Context, id influxdb.ID) error {
+	p, err := s.s.FindFluxPackageByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.FluxPackagesResourceType, p.ID, p.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteFluxPackage(ctx, id)
+}
@@ -0,0 +1,255 @@
+package fluxpkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+// ImportPrefix is the import path prefix of user-defined packages.
+const ImportPrefix = "org/"
+
+// parsePackage parses the source of a package. A package may import standard
+// library packages and may only contain variable assignments, all of which
+// are exported.
+func parsePackage(source string) (*ast.File, error) {
+	pkg := parser.ParseSource(source)
+	if ast.Check(pkg) > 0 {
+		return nil, ast.GetError(pkg)
+	}
+	if len(pkg.Files) != 1 {
+		return nil, errors.New("package source must contain a single file")
+	}
+
+	file := pkg.Files[0]
+	for _, imp := range file.Imports {
+		if strings.HasPrefix(imp.Path.Value, ImportPrefix) {
+			return nil, fmt.Errorf("packages cannot import other user-defined packages, found %q", imp.Path.Value)
+		}
+	}
+	for _, stmt := range file.Body {
+		if _, ok := stmt.(*ast.VariableAssignment); !ok {
+			return nil, fmt.Errorf("packages may only contain variable assignments, found %s", stmt.Type())
+		}
+	}
+	return file, nil
+}
+
+// parseImportPath returns the name and version of the package imported by
+// path, which must start with ImportPrefix. Version 0 means the latest version.
+func parseImportPath(path string) (name string, version int, err error) {
+	name = strings.TrimPrefix(path, ImportPrefix)
+	if i := strings.LastIndex(name, "@"); i >= 0 {
+		version, err = strconv.Atoi(name[i+1:])
+		if err != nil || version <= 0 {
+			return "", 0, fmt.Errorf("invalid version %q", name[i+1:])
+		}
+		name = name[:i]
+	}
+	if name == "" {
+		return "", 0, errors.New("package name is required")
+	}
+	return name, version, nil
+}
+
+// Resolver replaces imports of user-defined packages with their definitions.
+type Resolver struct {
+	pkgs influxdb.FluxPackageService
+}
+
+// NewResolver returns a resolver looking packages up in pkgs.
+func NewResolver(pkgs influxdb.FluxPackageService) *Resolver {
+	return &Resolver{pkgs: pkgs}
+}
+
+// Resolve rewrites every file of pkg that imports user-defined packages of
+// orgID. An import such as
+//
+//	import alerts "org/alerts@2"
+//
+// is replaced with an assignment of a record holding the package's definitions
+//
+//	alerts = (() => {
+//	    crit = ...
+//	    return {crit: crit}
+//	})()
+//
+// so that alerts.crit refers to the same value it would in a real package.
+// The standard library packages imported by a package are added to the imports of the file.
+func (r *Resolver) Resolve(ctx context.Context, orgID influxdb.ID, pkg *ast.Package) error {
+	for _, file := range pkg.Files {
+		if err := r.resolveFile(ctx, orgID, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) resolveFile(ctx context.Context, orgID influxdb.ID, file *ast.File) error {
+	var (
+		imports []*ast.ImportDeclaration
+		defs    []ast.Statement
+		seen    = make(map[string]bool)
+	)
+	addImport := func(imp *ast.ImportDeclaration) {
+		key := imp.Path.Value
+		if imp.As != nil {
+			key = imp.As.Name + "=" + key
+		}
+		if !seen[key] {
+			seen[key] = true
+			imports = append(imports, imp)
+		}
+	}
+
+	for _, imp := range file.Imports {
+		path := imp.Path.Value
+		if !strings.HasPrefix(path, ImportPrefix) {
+			addImport(imp)
+			continue
+		}
+
+		name, version, err := parseImportPath(path)
+		if err != nil {
+			return ErrInvalidImport(path, err)
+		}
+		p, err := r.pkgs.FindFluxPackage(ctx, orgID, name, version)
+		if err != nil {
+			return ErrInvalidImport(path, err)
+		}
+		src, err := parsePackage(p.Source)
+		if err != nil {
+			return ErrInvalidImport(path, err)
+		}
+
+		for _, dep := range src.Imports {
+			addImport(dep)
+		}
+
+		alias := name
+		if imp.As != nil {
+			alias = imp.As.Name
+		}
+		defs = append(defs, packageRecord(alias, src))
+	}
+
+	if len(defs) == 0 {
+		return nil
+	}
+	file.Imports = imports
+	file.Body = append(defs, file.Body...)
+	return nil
+}
+
+// packageRecord returns the assignment of the definitions in src to a record named alias.
+func packageRecord(alias string, src *ast.File) *ast.VariableAssignment {
+	body := make([]ast.Statement, 0, len(src.Body)+1)
+	exports := make([]*ast.Property, 0, len(src.Body))
+	for _, stmt := range src.Body {
+		va := stmt.(*ast.VariableAssignment)
+		body = append(body, va)
+		exports = append(exports, &ast.Property{
+			Key:   &ast.Identifier{Name: va.ID.Name},
+			Value: &ast.Identifier{Name: va.ID.Name},
+		})
+	}
+	body = append(body, &ast.ReturnStatement{
+		Argument: &ast.ObjectExpression{Properties: exports},
+	})
+
+	return &ast.VariableAssignment{
+		ID: &ast.Identifier{Name: alias},
+		Init: &ast.CallExpression{
+			Callee: &ast.ParenExpression{
+				Expression: &ast.FunctionExpression{
+					Body: &ast.Block{Body: body},
+				},
+			},
+		},
+	}
+}
+
+// resolveCompiler returns a compiler for c with user-defined package imports resolved.
+// Compilers for languages other than Flux are returned unchanged.
+func (r *Resolver) resolveCompiler(ctx context.Context, orgID influxdb.ID, c flux.Compiler) (flux.Compiler, error) {
+	switch c := c.(type) {
+	case lang.FluxCompiler:
+		if !strings.Contains(c.Query, `"`+ImportPrefix) {
+			return c, nil
+		}
+		pkg := parser.ParseSource(c.Query)
+		if ast.Check(pkg) > 0 {
+			// Let the query fail to compile with the usual error.
+			return c, nil
+		}
+		if err := r.Resolve(ctx, orgID, pkg); err != nil {
+			return nil, err
+		}
+		c.Query = ast.Format(pkg.Files[0])
+		return c, nil
+	case lang.ASTCompiler:
+		if !strings.Contains(string(c.AST), `"`+ImportPrefix) {
+			return c, nil
+		}
+		pkg := &ast.Package{}
+		if err := json.Unmarshal(c.AST, pkg); err != nil {
+			return c, nil
+		}
+		if err := r.Resolve(ctx, orgID, pkg); err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(pkg)
+		if err != nil {
+			return nil, ErrInternalService(err)
+		}
+		c.AST = b
+		return c, nil
+	}
+	return c, nil
+}
+
+var _ query.AsyncQueryService = (*AsyncQueryService)(nil)
+
+// AsyncQueryService resolves imports of user-defined packages before passing
+// queries to the next service. Packages are looked up with the authorization
+// of the query.
+type AsyncQueryService struct {
+	next     query.AsyncQueryService
+	resolver *Resolver
+}
+
+// NewAsyncQueryService wraps next with package resolution using pkgs.
+func NewAsyncQueryService(next query.AsyncQueryService, pkgs influxdb.FluxPackageService) *AsyncQueryService {
+	return &AsyncQueryService{
+		next:     next,
+		resolver: NewResolver(pkgs),
+	}
+}
+
+// Query resolves package imports in req and submits it to the next service.
+func (s *AsyncQueryService) Query(ctx context.Context, req *query.Request) (flux.Query, error) {
+	lookupCtx := ctx
+	if req.Authorization != nil {
+		lookupCtx = icontext.SetAuthorizer(ctx, req.Authorization)
+	}
+
+	c, err := s.resolver.resolveCompiler(lookupCtx, req.OrganizationID, req.Compiler)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := *req
+	resolved.Compiler = c
+	return s.next.Query(ctx, &resolved)
+}
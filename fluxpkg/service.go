@@ -0,0 +1,383 @@
+// Package fluxpkg implements user-defined Flux packages. A package is a set of
+// Flux definitions stored per organization that queries and tasks import with
+//
+//	import "org/alerts"
+//
+// for its latest version, or "org/alerts@2" for a specific one. Imports of
+// these packages are resolved before a query is compiled.
+package fluxpkg
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/snowflake"
+)
+
+var (
+	packageBucket = []byte("fluxpackagesv1")
+	indexBucket   = []byte("fluxpackageindexv1")
+)
+
+var _ influxdb.FluxPackageService = (*Service)(nil)
+
+// Service stores flux packages in a kv store.
+type Service struct {
+	store         kv.Store
+	IDGen         influxdb.IDGenerator
+	TimeGenerator influxdb.TimeGenerator
+}
+
+// NewService constructs a flux package service backed by st.
+func NewService(st kv.Store) *Service {
+	return &Service{
+		store:         st,
+		IDGen:         snowflake.NewDefaultIDGenerator(),
+		TimeGenerator: influxdb.RealTimeGenerator{},
+	}
+}
+
+// CreateFluxPackage publishes the next version of the package named p.Name.
+func (s *Service) CreateFluxPackage(ctx context.Context, p *influxdb.FluxPackage) error {
+	if err := p.Valid(); err != nil {
+		return ErrInvalidPackage(err)
+	}
+	if _, err := parsePackage(p.Source); err != nil {
+		return ErrInvalidPackage(err)
+	}
+
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		latest, err := s.findLatestIndex(ctx, tx, p.OrgID, p.Name)
+		if err != nil {
+			return err
+		}
+
+		now := s.TimeGenerator.Now()
+		p.ID = s.IDGen.ID()
+		p.Version = latest + 1
+		p.CreatedAt = now
+		p.UpdatedAt = now
+
+		if err := s.putPackage(tx, p); err != nil {
+			return err
+		}
+		key, err := indexKey(p.OrgID, p.Name, p.Version)
+		if err != nil {
+			return err
+		}
+		return s.putIndex(tx, key, p.ID)
+	})
+}
+
+// FindFluxPackageByID returns a single package version by ID.
+func (s *Service) FindFluxPackageByID(ctx context.Context, id influxdb.ID) (*influxdb.FluxPackage, error) {
+	var p *influxdb.FluxPackage
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		p, err = s.getPackage(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FindFluxPackage returns the given version of a package, or its latest
+// version if version is 0.
+func (s *Service) FindFluxPackage(ctx context.Context, orgID influxdb.ID, name string, version int) (*influxdb.FluxPackage, error) {
+	var p *influxdb.FluxPackage
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		if version == 0 {
+			var err error
+			p, err = s.findLatest(ctx, tx, orgID, name)
+			return err
+		}
+
+		key, err := indexKey(orgID, name, version)
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(indexBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		v, err := b.Get(key)
+		if kv.IsNotFound(err) {
+			return ErrPackageNotFound
+		}
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		var id influxdb.ID
+		if err := id.Decode(v); err != nil {
+			return ErrInternalService(err)
+		}
+		p, err = s.getPackage(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FindFluxPackages returns a list of package versions that match filter and
+// the total count of matching versions. Versions of a package are ordered
+// oldest first.
+func (s *Service) FindFluxPackages(ctx context.Context, filter influxdb.FluxPackageFilter, opt ...influxdb.FindOptions) ([]*influxdb.FluxPackage, int, error) {
+	pkgs := []*influxdb.FluxPackage{}
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket(packageBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		return kv.WalkCursor(ctx, cur, func(k, v []byte) error {
+			p := &influxdb.FluxPackage{}
+			if err := json.Unmarshal(v, p); err != nil {
+				return ErrInternalService(err)
+			}
+			if filterFunc(p, filter) {
+				pkgs = append(pkgs, p)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		if pkgs[i].Name != pkgs[j].Name {
+			return pkgs[i].Name < pkgs[j].Name
+		}
+		return pkgs[i].Version < pkgs[j].Version
+	})
+
+	total := len(pkgs)
+	if len(opt) > 0 {
+		pkgs = paginate(pkgs, opt[0])
+	}
+	return pkgs, total, nil
+}
+
+// DeleteFluxPackage removes a single package version by ID. Version numbers
+// are never reused, so queries pinned to a deleted version fail rather than
+// silently importing different code.
+func (s *Service) DeleteFluxPackage(ctx context.Context, id influxdb.ID) error {
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		p, err := s.getPackage(tx, id)
+		if err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return ErrInvalidPackageID
+		}
+		b, err := tx.Bucket(packageBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		if err := b.Delete(encodedID); err != nil {
+			return ErrInternalService(err)
+		}
+
+		// The latest version stays in the index as a tombstone so the next
+		// version published is numbered after it.
+		latest, err := s.findLatestIndex(ctx, tx, p.OrgID, p.Name)
+		if err != nil {
+			return err
+		}
+		if latest == p.Version {
+			return nil
+		}
+		key, err := indexKey(p.OrgID, p.Name, p.Version)
+		if err != nil {
+			return err
+		}
+		ib, err := tx.Bucket(indexBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		if err := ib.Delete(key); err != nil {
+			return ErrInternalService(err)
+		}
+		return nil
+	})
+}
+
+// findLatest returns the latest version of a package that has not been deleted.
+func (s *Service) findLatest(ctx context.Context, tx kv.Tx, orgID influxdb.ID, name string) (*influxdb.FluxPackage, error) {
+	prefix, err := indexPrefix(orgID, name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := tx.Bucket(indexBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	cur, err := b.ForwardCursor(prefix, kv.WithCursorPrefix(prefix))
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+
+	var latest *influxdb.FluxPackage
+	err = kv.WalkCursor(ctx, cur, func(k, v []byte) error {
+		var id influxdb.ID
+		if err := id.Decode(v); err != nil {
+			return ErrInternalService(err)
+		}
+		p, err := s.getPackage(tx, id)
+		if err == ErrPackageNotFound {
+			// A deleted version kept as a tombstone.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		latest = p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return nil, ErrPackageNotFound
+	}
+	return latest, nil
+}
+
+// findLatestIndex returns the highest version number ever published for a package.
+func (s *Service) findLatestIndex(ctx context.Context, tx kv.Tx, orgID influxdb.ID, name string) (int, error) {
+	prefix, err := indexPrefix(orgID, name)
+	if err != nil {
+		return 0, err
+	}
+	b, err := tx.Bucket(indexBucket)
+	if err != nil {
+		return 0, ErrInternalService(err)
+	}
+	cur, err := b.ForwardCursor(prefix, kv.WithCursorPrefix(prefix))
+	if err != nil {
+		return 0, ErrInternalService(err)
+	}
+
+	var latest int
+	err = kv.WalkCursor(ctx, cur, func(k, v []byte) error {
+		latest = int(binary.BigEndian.Uint32(k[len(prefix):]))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return latest, nil
+}
+
+func (s *Service) getPackage(tx kv.Tx, id influxdb.ID) (*influxdb.FluxPackage, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidPackageID
+	}
+
+	b, err := tx.Bucket(packageBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(encodedID)
+	if kv.IsNotFound(err) {
+		return nil, ErrPackageNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+
+	p := &influxdb.FluxPackage{}
+	if err := json.Unmarshal(v, p); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return p, nil
+}
+
+func (s *Service) putPackage(tx kv.Tx, p *influxdb.FluxPackage) error {
+	encodedID, err := p.ID.Encode()
+	if err != nil {
+		return ErrInvalidPackageID
+	}
+	v, err := json.Marshal(p)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+
+	b, err := tx.Bucket(packageBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(encodedID, v); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+func (s *Service) putIndex(tx kv.Tx, key []byte, id influxdb.ID) error {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return ErrInvalidPackageID
+	}
+	b, err := tx.Bucket(indexBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(key, encodedID); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+// indexPrefix returns the prefix of the index keys of all versions of a package.
+func indexPrefix(orgID influxdb.ID, name string) ([]byte, error) {
+	encodedOrgID, err := orgID.Encode()
+	if err != nil {
+		return nil, ErrInvalidPackage(err)
+	}
+	prefix := append(encodedOrgID, name...)
+	return append(prefix, '/'), nil
+}
+
+// indexKey returns the index key of a package version. Versions are encoded
+// big endian so that cursors walk them in order.
+func indexKey(orgID influxdb.ID, name string, version int) ([]byte, error) {
+	prefix, err := indexPrefix(orgID, name)
+	if err != nil {
+		return nil, err
+	}
+	var v [4]byte
+	binary.BigEndian.PutUint32(v[:], uint32(version))
+	return append(prefix, v[:]...), nil
+}
+
+func filterFunc(p *influxdb.FluxPackage, filter influxdb.FluxPackageFilter) bool {
+	return (filter.OrgID == nil || p.OrgID == *filter.OrgID) &&
+		(filter.Name == nil || p.Name == *filter.Name)
+}
+
+func paginate(pkgs []*influxdb.FluxPackage, opt influxdb.FindOptions) []*influxdb.FluxPackage {
+	if opt.Offset > 0 {
+		if opt.Offset >= len(pkgs) {
+			return []*influxdb.FluxPackage{}
+		}
+		pkgs = pkgs[opt.Offset:]
+	}
+	if opt.Limit > 0 && opt.Limit < len(pkgs) {
+		pkgs = pkgs[:opt.Limit]
+	}
+	return pkgs
+}
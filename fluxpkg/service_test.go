@@ -0,0 +1,165 @@
+package fluxpkg_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/fluxpkg"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+const alertsSource = `
+import "strings"
+
+crit = (r) => r._value > 90.0
+label = (name) => strings.toUpper(v: name)
+`
+
+func newTestService(t *testing.T) (*fluxpkg.Service, func()) {
+	t.Helper()
+
+	s, done := influxdbtesting.NewTestBoltStore(t)
+	svc := fluxpkg.NewService(s)
+	svc.IDGen = mock.NewIncrementingIDGenerator(1)
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)}
+	return svc, done
+}
+
+func TestService_Versions(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	v1 := &influxdb.FluxPackage{OrgID: 1, Name: "alerts", Source: "crit = (r) => r._value > 80.0"}
+	if err := svc.CreateFluxPackage(ctx, v1); err != nil {
+		t.Fatal(err)
+	}
+	v2 := &influxdb.FluxPackage{OrgID: 1, Name: "alerts", Source: alertsSource}
+	if err := svc.CreateFluxPackage(ctx, v2); err != nil {
+		t.Fatal(err)
+	}
+	if v1.Version != 1 || v2.Version != 2 {
+		t.Fatalf("expected versions 1 and 2, got %d and %d", v1.Version, v2.Version)
+	}
+
+	latest, err := svc.FindFluxPackage(ctx, 1, "alerts", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.ID != v2.ID {
+		t.Fatalf("expected latest version to be %d, got %d", v2.Version, latest.Version)
+	}
+
+	// Deleting the latest version must not allow its number to be reused.
+	if err := svc.DeleteFluxPackage(ctx, v2.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindFluxPackage(ctx, 1, "alerts", 2); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected deleted version not to be found, got %v", err)
+	}
+	latest, err = svc.FindFluxPackage(ctx, 1, "alerts", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.ID != v1.ID {
+		t.Fatalf("expected latest version to fall back to 1, got %d", latest.Version)
+	}
+
+	v3 := &influxdb.FluxPackage{OrgID: 1, Name: "alerts", Source: alertsSource}
+	if err := svc.CreateFluxPackage(ctx, v3); err != nil {
+		t.Fatal(err)
+	}
+	if v3.Version != 3 {
+		t.Fatalf("expected version 3, got %d", v3.Version)
+	}
+
+	name := "alerts"
+	pkgs, n, err := svc.FindFluxPackages(ctx, influxdb.FluxPackageFilter{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || pkgs[0].Version != 1 || pkgs[1].Version != 3 {
+		t.Fatalf("unexpected versions %+v", pkgs)
+	}
+}
+
+func TestService_CreateInvalid(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+
+	for _, p := range []*influxdb.FluxPackage{
+		{OrgID: 1, Name: "not-an-identifier", Source: "x = 1"},
+		{OrgID: 1, Name: "alerts", Source: "x = "},
+		{OrgID: 1, Name: "alerts", Source: `from(bucket: "b")`},
+		{OrgID: 1, Name: "alerts", Source: `option now = () => 2020-01-01T00:00:00Z`},
+		{OrgID: 1, Name: "alerts", Source: "import \"org/other\"\nx = other.y"},
+	} {
+		if err := svc.CreateFluxPackage(context.Background(), p); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Errorf("expected invalid error creating %q, got %v", p.Source, err)
+		}
+	}
+}
+
+func TestService_NotFound(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	if _, err := svc.FindFluxPackageByID(ctx, 99); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error finding a missing package, got %v", err)
+	}
+	if _, err := svc.FindFluxPackage(ctx, 1, "alerts", 0); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error finding a package never published, got %v", err)
+	}
+	if err := svc.DeleteFluxPackage(ctx, 99); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error deleting a missing package, got %v", err)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	if err := svc.CreateFluxPackage(ctx, &influxdb.FluxPackage{OrgID: 1, Name: "alerts", Source: alertsSource}); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg := parser.ParseSource(`
+import "strings"
+import a "org/alerts@1"
+
+from(bucket: "b") |> range(start: -1h) |> filter(fn: a.crit)
+`)
+	if err := fluxpkg.NewResolver(svc).Resolve(ctx, 1, pkg); err != nil {
+		t.Fatal(err)
+	}
+
+	file := pkg.Files[0]
+	if len(file.Imports) != 1 || file.Imports[0].Path.Value != "strings" {
+		t.Fatalf("expected only the strings import to remain, got %d imports", len(file.Imports))
+	}
+	src := ast.Format(file)
+	if strings.Contains(src, "org/alerts") {
+		t.Fatalf("expected the package import to be resolved:\n%s", src)
+	}
+	if !strings.Contains(src, "a = (() =>") {
+		t.Fatalf("expected the package to be bound to its alias:\n%s", src)
+	}
+
+	// The rewritten query must be valid Flux.
+	if reparsed := parser.ParseSource(src); ast.Check(reparsed) > 0 {
+		t.Fatalf("rewritten query is invalid: %v\n%s", ast.GetError(reparsed), src)
+	}
+
+	missing := parser.ParseSource(`import "org/missing"`)
+	if err := fluxpkg.NewResolver(svc).Resolve(ctx, 1, missing); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid error importing a missing package, got %v", err)
+	}
+}
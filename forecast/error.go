@@ -0,0 +1,41 @@
+package forecast
+
+import (
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrForecastNotFound is used when a forecast is not found, or the task
+	// it is looked up by does not maintain a forecast.
+	ErrForecastNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "forecast not found",
+	}
+)
+
+// ErrInvalidForecast is used when a forecast is missing or has an invalid
+// setting.
+func ErrInvalidForecast(msg string) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "forecast is invalid: " + msg,
+	}
+}
+
+// ErrInvalidQuery is used when the source query of a forecast cannot be
+// parsed or does not end with the expression of its series.
+func ErrInvalidQuery(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "forecast query is invalid",
+		Err:  err,
+	}
+}
+
+// ErrInternalService is used when the task of a forecast cannot be read.
+func ErrInternalService(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
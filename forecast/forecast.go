@@ -0,0 +1,239 @@
+// Package forecast maintains forecasts of series as tasks. A forecast is
+// configured with a source query and a horizon, and its task computes the
+// forecast of the series of the query with the Holt-Winters method on a
+// schedule, and writes it to a target bucket. The configuration of a forecast
+// is kept in the metadata of its task.
+package forecast
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+const (
+	// TaskType is the type of the tasks maintaining forecasts.
+	TaskType = "forecast"
+
+	// DefaultMeasurement is the measurement forecasts are written to when
+	// none is set.
+	DefaultMeasurement = "forecast"
+
+	// MaxPoints is the maximum number of points of a forecast, its horizon
+	// divided by its interval.
+	MaxPoints = 10000
+
+	// metadataKey is the key of the metadata of tasks holding the
+	// configuration of their forecast.
+	metadataKey = "forecast"
+)
+
+// Forecast is the forecast of the series of a query, written to a bucket.
+// The source series are aggregated by Interval, and their next points up to
+// Horizon are predicted every Every. Forecasts are written to Measurement,
+// keeping the fields and tags of the source series, whose tables must keep
+// the _field column.
+type Forecast struct {
+	ID             influxdb.ID           `json:"id,omitempty"`
+	OrgID          influxdb.ID           `json:"orgID"`
+	Name           string                `json:"name"`
+	Description    string                `json:"description,omitempty"`
+	Query          string                `json:"query"`
+	Horizon        notification.Duration `json:"horizon"`
+	Interval       notification.Duration `json:"interval"`
+	Every          notification.Duration `json:"every"`
+	Seasonality    int64                 `json:"seasonality"`
+	TargetBucketID influxdb.ID           `json:"targetBucketID"`
+	Measurement    string                `json:"measurement"`
+	Status         string                `json:"status"`
+
+	LatestCompleted time.Time `json:"latestCompleted,omitempty"`
+	LastRunStatus   string    `json:"lastRunStatus,omitempty"`
+	LastRunError    string    `json:"lastRunError,omitempty"`
+	CreatedAt       time.Time `json:"createdAt,omitempty"`
+	UpdatedAt       time.Time `json:"updatedAt,omitempty"`
+}
+
+// spec is the configuration of a forecast kept in the metadata of its task.
+type spec struct {
+	Query          string                `json:"query"`
+	Horizon        notification.Duration `json:"horizon"`
+	Interval       notification.Duration `json:"interval"`
+	Every          notification.Duration `json:"every"`
+	Seasonality    int64                 `json:"seasonality"`
+	TargetBucketID influxdb.ID           `json:"targetBucketID"`
+	Measurement    string                `json:"measurement"`
+}
+
+// setDefaults sets the measurement and schedule of f when they are not set.
+func (f *Forecast) setDefaults() {
+	if f.Measurement == "" {
+		f.Measurement = DefaultMeasurement
+	}
+	if len(f.Every.Values) == 0 {
+		f.Every = f.Interval
+	}
+	if f.Status == "" {
+		f.Status = influxdb.TaskStatusActive
+	}
+}
+
+// Valid returns an error if the forecast cannot be computed.
+func (f *Forecast) Valid() error {
+	interval := f.Interval.TimeDuration()
+	switch {
+	case !f.OrgID.Valid():
+		return ErrInvalidForecast("orgID is required")
+	case f.Name == "":
+		return ErrInvalidForecast("name is required")
+	case f.Query == "":
+		return ErrInvalidForecast("query is required")
+	case !f.TargetBucketID.Valid():
+		return ErrInvalidForecast("targetBucketID is required")
+	case interval <= 0:
+		return ErrInvalidForecast("interval must be positive")
+	case f.Every.TimeDuration() <= 0:
+		return ErrInvalidForecast("every must be positive")
+	case f.Horizon.TimeDuration() < interval:
+		return ErrInvalidForecast("horizon must be at least the interval")
+	case f.Horizon.TimeDuration()/interval > MaxPoints:
+		return ErrInvalidForecast(fmt.Sprintf("horizon must be at most %d intervals", MaxPoints))
+	case f.Seasonality < 0:
+		return ErrInvalidForecast("seasonality must not be negative")
+	case f.Status != influxdb.TaskStatusActive && f.Status != influxdb.TaskStatusInactive:
+		return ErrInvalidForecast("status must be active or inactive")
+	}
+	return nil
+}
+
+// GenerateFlux returns the script of the task of the forecast.
+func (f *Forecast) GenerateFlux(lang influxdb.FluxLanguageService) (string, error) {
+	p, err := f.GenerateFluxAST(lang)
+	if err != nil {
+		return "", err
+	}
+	return ast.Format(p), nil
+}
+
+// GenerateFluxAST returns the AST of the script of the task of the forecast.
+// The last statement of the query is assigned to data, which is aggregated
+// by the interval of the forecast, forecast and written to its bucket.
+func (f *Forecast) GenerateFluxAST(lang influxdb.FluxLanguageService) (*ast.Package, error) {
+	p, err := query.Parse(lang, f.Query)
+	if p == nil {
+		return nil, err
+	} else if err != nil {
+		return nil, ErrInvalidQuery(err)
+	}
+	if len(p.Files) != 1 {
+		return nil, ErrInvalidQuery(fmt.Errorf("expected a single file, got %d", len(p.Files)))
+	}
+
+	file := p.Files[0]
+	if len(file.Body) == 0 {
+		return nil, ErrInvalidQuery(fmt.Errorf("query has no statements"))
+	}
+	last, ok := file.Body[len(file.Body)-1].(*ast.ExpressionStatement)
+	if !ok {
+		return nil, ErrInvalidQuery(fmt.Errorf("the last statement of the query must be an expression"))
+	}
+	data := last.Expression
+	if pipe, ok := data.(*ast.PipeExpression); ok {
+		if id, ok := pipe.Call.Callee.(*ast.Identifier); ok && id.Name == "yield" {
+			data = pipe.Argument
+		}
+	}
+
+	interval := ast.DurationLiteral(f.Interval)
+	every := ast.DurationLiteral(f.Every)
+	n := int64(f.Horizon.TimeDuration() / f.Interval.TimeDuration())
+
+	body := []ast.Statement{
+		flux.DefineTaskOption(flux.Object(
+			flux.Property("name", flux.String(f.Name)),
+			flux.Property("every", &every),
+		)),
+	}
+	body = append(body, file.Body[:len(file.Body)-1]...)
+	body = append(body,
+		flux.DefineVariable("data", data),
+		flux.ExpressionStatement(flux.Pipe(
+			flux.Identifier("data"),
+			flux.Call(flux.Identifier("aggregateWindow"), flux.Object(
+				flux.Property("every", &interval),
+				flux.Property("fn", flux.Identifier("mean")),
+				flux.Property("createEmpty", flux.Bool(false)),
+			)),
+			flux.Call(flux.Identifier("holtWinters"), flux.Object(
+				flux.Property("n", flux.Integer(n)),
+				flux.Property("seasonality", flux.Integer(f.Seasonality)),
+				flux.Property("interval", &interval),
+			)),
+			flux.Call(flux.Identifier("set"), flux.Object(
+				flux.Property("key", flux.String("_measurement")),
+				flux.Property("value", flux.String(f.Measurement)),
+			)),
+			flux.Call(flux.Identifier("to"), flux.Object(
+				flux.Property("bucketID", flux.String(f.TargetBucketID.String())),
+				flux.Property("orgID", flux.String(f.OrgID.String())),
+			)),
+		)),
+	)
+	file.Body = body
+	return p, nil
+}
+
+// metadata returns the metadata of the task of the forecast.
+func (f *Forecast) metadata() (map[string]interface{}, error) {
+	b, err := json.Marshal(spec{
+		Query:          f.Query,
+		Horizon:        f.Horizon,
+		Interval:       f.Interval,
+		Every:          f.Every,
+		Seasonality:    f.Seasonality,
+		TargetBucketID: f.TargetBucketID,
+		Measurement:    f.Measurement,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{metadataKey: string(b)}, nil
+}
+
+// fromTask returns the forecast maintained by t.
+func fromTask(t *influxdb.Task) (*Forecast, error) {
+	if t.Type != TaskType {
+		return nil, ErrForecastNotFound
+	}
+	v, _ := t.Metadata[metadataKey].(string)
+	var s spec
+	if err := json.Unmarshal([]byte(v), &s); err != nil {
+		return nil, ErrInternalService(fmt.Errorf("invalid forecast of task %s: %v", t.ID, err))
+	}
+
+	return &Forecast{
+		ID:              t.ID,
+		OrgID:           t.OrganizationID,
+		Name:            t.Name,
+		Description:     t.Description,
+		Query:           s.Query,
+		Horizon:         s.Horizon,
+		Interval:        s.Interval,
+		Every:           s.Every,
+		Seasonality:     s.Seasonality,
+		TargetBucketID:  s.TargetBucketID,
+		Measurement:     s.Measurement,
+		Status:          t.Status,
+		LatestCompleted: t.LatestCompleted,
+		LastRunStatus:   t.LastRunStatus,
+		LastRunError:    t.LastRunError,
+		CreatedAt:       t.CreatedAt,
+		UpdatedAt:       t.UpdatedAt,
+	}, nil
+}
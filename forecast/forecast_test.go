@@ -0,0 +1,146 @@
+package forecast_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/forecast"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+)
+
+func TestForecast_GenerateFlux(t *testing.T) {
+	tests := []struct {
+		name     string
+		forecast string
+		flux     string
+	}{
+		{
+			name: "hourly forecast of a day",
+			forecast: `{
+				"orgID": "0000000000000001",
+				"name": "cpu",
+				"query": "from(bucket: \"telegraf\") |> range(start: -7d) |> filter(fn: (r) => r._measurement == \"cpu\") |> yield()",
+				"horizon": "1d",
+				"interval": "1h",
+				"targetBucketID": "0000000000000002"
+			}`,
+			flux: `option task = {name: "cpu", every: 1h}
+
+data = from(bucket: "telegraf")
+	|> range(start: -7d)
+	|> filter(fn: (r) => r._measurement == "cpu")
+
+data
+	|> aggregateWindow(every: 1h, fn: mean, createEmpty: false)
+	|> holtWinters(n: 24, seasonality: 0, interval: 1h)
+	|> set(key: "_measurement", value: "forecast")
+	|> to(bucketID: "0000000000000002", orgID: "0000000000000001")
+`,
+		},
+		{
+			name: "seasonal forecast with variables",
+			forecast: `{
+				"orgID": "0000000000000001",
+				"name": "mem",
+				"query": "start = -30d\nfrom(bucket: \"telegraf\") |> range(start: start)",
+				"horizon": "7d",
+				"interval": "1d",
+				"every": "6h",
+				"seasonality": 7,
+				"targetBucketID": "0000000000000002",
+				"measurement": "mem_forecast"
+			}`,
+			flux: `option task = {name: "mem", every: 6h}
+
+start = -30d
+data = from(bucket: "telegraf")
+	|> range(start: start)
+
+data
+	|> aggregateWindow(every: 1d, fn: mean, createEmpty: false)
+	|> holtWinters(n: 7, seasonality: 7, interval: 1d)
+	|> set(key: "_measurement", value: "mem_forecast")
+	|> to(bucketID: "0000000000000002", orgID: "0000000000000001")
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f forecast.Forecast
+			if err := json.Unmarshal([]byte(tt.forecast), &f); err != nil {
+				t.Fatal(err)
+			}
+			if f.Measurement == "" {
+				f.Measurement = forecast.DefaultMeasurement
+			}
+			if len(f.Every.Values) == 0 {
+				f.Every = f.Interval
+			}
+			f.Status = influxdb.TaskStatusActive
+			if err := f.Valid(); err != nil {
+				t.Fatal(err)
+			}
+
+			flux, err := f.GenerateFlux(fluxlang.DefaultService)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.flux, flux); diff != "" {
+				t.Errorf("unexpected flux (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestForecast_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		forecast string
+	}{
+		{
+			name:     "horizon shorter than the interval",
+			forecast: `{"horizon": "30m", "interval": "1h", "every": "1h"}`,
+		},
+		{
+			name:     "too many points",
+			forecast: `{"horizon": "365d", "interval": "1m", "every": "1m"}`,
+		},
+		{
+			name:     "negative seasonality",
+			forecast: `{"horizon": "1d", "interval": "1h", "every": "1h", "seasonality": -1}`,
+		},
+		{
+			name:     "unparsable query",
+			forecast: `{"horizon": "1d", "interval": "1h", "every": "1h", "query": "from(bucket: "}`,
+		},
+		{
+			name:     "query without an expression",
+			forecast: `{"horizon": "1d", "interval": "1h", "every": "1h", "query": "data = from(bucket: \"telegraf\")"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := forecast.Forecast{
+				OrgID:          1,
+				Name:           "invalid",
+				Query:          `from(bucket: "telegraf") |> range(start: -1d)`,
+				TargetBucketID: 2,
+				Measurement:    forecast.DefaultMeasurement,
+				Status:         influxdb.TaskStatusActive,
+			}
+			if err := json.Unmarshal([]byte(tt.forecast), &f); err != nil {
+				t.Fatal(err)
+			}
+
+			err := f.Valid()
+			if err == nil {
+				_, err = f.GenerateFlux(fluxlang.DefaultService)
+			}
+			if influxdb.ErrorCode(err) != influxdb.EInvalid {
+				t.Fatalf("expected %s error, got %v", influxdb.EInvalid, err)
+			}
+		})
+	}
+}
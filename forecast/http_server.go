@@ -0,0 +1,180 @@
+package forecast
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixForecasts is the prefix of the forecast API.
+	PrefixForecasts = "/api/v2/forecasts"
+)
+
+// Handler is the HTTP API handler of forecasts.
+type Handler struct {
+	chi.Router
+	api *kithttp.API
+	log *zap.Logger
+	svc *Service
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, svc *Service) *Handler {
+	h := &Handler{
+		api: kithttp.NewAPI(kithttp.WithLog(log)),
+		log: log,
+		svc: svc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Get("/", h.handleGetForecasts)
+		r.Post("/", h.handlePostForecast)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetForecast)
+			r.Patch("/", h.handlePatchForecast)
+			r.Delete("/", h.handleDeleteForecast)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixForecasts
+}
+
+type forecastsResponse struct {
+	Forecasts []*Forecast `json:"forecasts"`
+}
+
+func (h *Handler) handleGetForecasts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var filter ForecastFilter
+	if err := filter.OrgID.DecodeFromString(q.Get("orgID")); err != nil {
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID is required",
+			Err:  err,
+		})
+		return
+	}
+	if after := q.Get("after"); after != "" {
+		id, err := influxdb.IDFromString(after)
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		filter.After = id
+	}
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > influxdb.TaskMaxPageSize {
+			h.api.Err(w, r, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "limit must be between 1 and " + strconv.Itoa(influxdb.TaskMaxPageSize),
+			})
+			return
+		}
+		filter.Limit = n
+	}
+
+	forecasts, err := h.svc.FindForecasts(r.Context(), filter)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, forecastsResponse{Forecasts: forecasts})
+}
+
+func (h *Handler) handlePostForecast(w http.ResponseWriter, r *http.Request) {
+	var f Forecast
+	if err := h.api.DecodeJSON(r.Body, &f); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if err := h.svc.CreateForecast(r.Context(), &f); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Forecast created", zap.String("forecast", f.ID.String()))
+
+	h.api.Respond(w, r, http.StatusCreated, f)
+}
+
+func (h *Handler) handleGetForecast(w http.ResponseWriter, r *http.Request) {
+	id, err := h.forecastID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	f, err := h.svc.FindForecastByID(r.Context(), id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, f)
+}
+
+func (h *Handler) handlePatchForecast(w http.ResponseWriter, r *http.Request) {
+	id, err := h.forecastID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	var upd ForecastUpdate
+	if err := h.api.DecodeJSON(r.Body, &upd); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	f, err := h.svc.UpdateForecast(r.Context(), id, upd)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Forecast updated", zap.String("forecast", id.String()))
+
+	h.api.Respond(w, r, http.StatusOK, f)
+}
+
+func (h *Handler) handleDeleteForecast(w http.ResponseWriter, r *http.Request) {
+	id, err := h.forecastID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if err := h.svc.DeleteForecast(r.Context(), id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Forecast deleted", zap.String("forecast", id.String()))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
+
+func (h *Handler) forecastID(r *http.Request) (influxdb.ID, error) {
+	var id influxdb.ID
+	if err := id.DecodeFromString(chi.URLParam(r, "id")); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid forecast id",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
@@ -0,0 +1,217 @@
+package forecast
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/notification"
+)
+
+// ForecastFilter selects the forecasts of an organization, a page at a time.
+type ForecastFilter struct {
+	OrgID influxdb.ID
+	After *influxdb.ID
+	Limit int
+}
+
+// ForecastUpdate is the change of the settings of a forecast. The task of the
+// forecast is regenerated from its updated settings.
+type ForecastUpdate struct {
+	Name           *string                `json:"name,omitempty"`
+	Description    *string                `json:"description,omitempty"`
+	Query          *string                `json:"query,omitempty"`
+	Horizon        *notification.Duration `json:"horizon,omitempty"`
+	Interval       *notification.Duration `json:"interval,omitempty"`
+	Every          *notification.Duration `json:"every,omitempty"`
+	Seasonality    *int64                 `json:"seasonality,omitempty"`
+	TargetBucketID *influxdb.ID           `json:"targetBucketID,omitempty"`
+	Measurement    *string                `json:"measurement,omitempty"`
+	Status         *string                `json:"status,omitempty"`
+}
+
+// apply sets the settings of upd on f.
+func (upd ForecastUpdate) apply(f *Forecast) {
+	if upd.Name != nil {
+		f.Name = *upd.Name
+	}
+	if upd.Description != nil {
+		f.Description = *upd.Description
+	}
+	if upd.Query != nil {
+		f.Query = *upd.Query
+	}
+	if upd.Horizon != nil {
+		f.Horizon = *upd.Horizon
+	}
+	if upd.Interval != nil {
+		f.Interval = *upd.Interval
+	}
+	if upd.Every != nil {
+		f.Every = *upd.Every
+	}
+	if upd.Seasonality != nil {
+		f.Seasonality = *upd.Seasonality
+	}
+	if upd.TargetBucketID != nil {
+		f.TargetBucketID = *upd.TargetBucketID
+	}
+	if upd.Measurement != nil {
+		f.Measurement = *upd.Measurement
+	}
+	if upd.Status != nil {
+		f.Status = *upd.Status
+	}
+}
+
+// Service manages forecasts as the tasks maintaining them.
+type Service struct {
+	taskSvc influxdb.TaskService
+	lang    influxdb.FluxLanguageService
+}
+
+// NewService constructs a service managing the tasks of forecasts with
+// taskSvc, and parsing their queries with lang.
+func NewService(taskSvc influxdb.TaskService, lang influxdb.FluxLanguageService) *Service {
+	return &Service{
+		taskSvc: taskSvc,
+		lang:    lang,
+	}
+}
+
+// FindForecasts returns the forecasts of the organization of filter.
+func (s *Service) FindForecasts(ctx context.Context, filter ForecastFilter) ([]*Forecast, error) {
+	typ := TaskType
+	tasks, _, err := s.taskSvc.FindTasks(ctx, influxdb.TaskFilter{
+		Type:           &typ,
+		OrganizationID: &filter.OrgID,
+		After:          filter.After,
+		Limit:          filter.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	forecasts := make([]*Forecast, 0, len(tasks))
+	for _, t := range tasks {
+		f, err := fromTask(t)
+		if err != nil {
+			return nil, err
+		}
+		forecasts = append(forecasts, f)
+	}
+	return forecasts, nil
+}
+
+// FindForecastByID returns the forecast maintained by the task id.
+func (s *Service) FindForecastByID(ctx context.Context, id influxdb.ID) (*Forecast, error) {
+	t, err := s.findTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return fromTask(t)
+}
+
+// CreateForecast creates the task maintaining f, owned by the user of the
+// request, and sets the ID of f to the ID of the task.
+func (s *Service) CreateForecast(ctx context.Context, f *Forecast) error {
+	auth, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+
+	f.setDefaults()
+	if err := f.Valid(); err != nil {
+		return err
+	}
+	script, err := f.GenerateFlux(s.lang)
+	if err != nil {
+		return err
+	}
+	metadata, err := f.metadata()
+	if err != nil {
+		return err
+	}
+
+	t, err := s.taskSvc.CreateTask(ctx, influxdb.TaskCreate{
+		Type:           TaskType,
+		Flux:           script,
+		Description:    f.Description,
+		Status:         f.Status,
+		OrganizationID: f.OrgID,
+		OwnerID:        auth.GetUserID(),
+		Metadata:       metadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	created, err := fromTask(t)
+	if err != nil {
+		return err
+	}
+	*f = *created
+	return nil
+}
+
+// UpdateForecast applies upd to the forecast maintained by the task id, and
+// updates the task.
+func (s *Service) UpdateForecast(ctx context.Context, id influxdb.ID, upd ForecastUpdate) (*Forecast, error) {
+	t, err := s.findTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	f, err := fromTask(t)
+	if err != nil {
+		return nil, err
+	}
+
+	upd.apply(f)
+	f.setDefaults()
+	if err := f.Valid(); err != nil {
+		return nil, err
+	}
+	script, err := f.GenerateFlux(s.lang)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := f.metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err = s.taskSvc.UpdateTask(ctx, id, influxdb.TaskUpdate{
+		Flux:        &script,
+		Description: &f.Description,
+		Status:      &f.Status,
+		Metadata:    metadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromTask(t)
+}
+
+// DeleteForecast deletes the task id maintaining a forecast. The forecasts
+// written to its bucket are kept.
+func (s *Service) DeleteForecast(ctx context.Context, id influxdb.ID) error {
+	if _, err := s.findTask(ctx, id); err != nil {
+		return err
+	}
+	return s.taskSvc.DeleteTask(ctx, id)
+}
+
+// findTask returns the task id if it maintains a forecast.
+func (s *Service) findTask(ctx context.Context, id influxdb.ID) (*influxdb.Task, error) {
+	t, err := s.taskSvc.FindTaskByID(ctx, id)
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil, ErrForecastNotFound
+		}
+		return nil, err
+	}
+	if t.Type != TaskType {
+		return nil, ErrForecastNotFound
+	}
+	return t, nil
+}
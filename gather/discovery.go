@@ -0,0 +1,140 @@
+package gather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+// Labels set by discoverers that control how a discovered target is scraped.
+const (
+	addressLabel     = "__address__"
+	schemeLabel      = "__scheme__"
+	metricsPathLabel = "__metrics_path__"
+)
+
+// instanceLabel is added to every metric gathered from a discovered target.
+const instanceLabel = "instance"
+
+// DiscoveredTarget is an endpoint found by a Discoverer.
+type DiscoveredTarget struct {
+	// Labels describe the endpoint. The __address__ label is required, the
+	// __scheme__ and __metrics_path__ labels override the scraper target URL.
+	// Other labels starting with __ are dropped, the rest are added as tags to
+	// every metric gathered from the endpoint.
+	Labels map[string]string
+}
+
+// Discoverer resolves the endpoints of a scraper target of the organization
+// orgID.
+type Discoverer interface {
+	Discover(ctx context.Context, orgID influxdb.ID, cfg influxdb.ScraperDiscovery) ([]DiscoveredTarget, error)
+}
+
+// ErrDiscoveryDisabled is returned when a target uses a discovery type the
+// server has not enabled.
+var ErrDiscoveryDisabled = errors.New("scraper discovery type is not enabled")
+
+// discoveryScraper scrapes every endpoint of targets that use service
+// discovery, and the URL of all others.
+type discoveryScraper struct {
+	scraper     *prometheusScraper
+	discoverers map[string]Discoverer
+	log         *zap.Logger
+}
+
+// Gather implements Scraper.
+func (d *discoveryScraper) Gather(ctx context.Context, target influxdb.ScraperTarget) (MetricsCollection, error) {
+	if target.Discovery == nil {
+		return d.scraper.Gather(ctx, target)
+	}
+
+	collected := MetricsCollection{
+		OrgID:        target.OrgID,
+		BucketID:     target.BucketID,
+		MetricsSlice: make(MetricsSlice, 0),
+	}
+
+	disc, ok := d.discoverers[target.Discovery.Type]
+	if !ok {
+		return collected, fmt.Errorf("%v: %s", ErrDiscoveryDisabled, target.Discovery.Type)
+	}
+
+	endpoints, err := disc.Discover(ctx, target.OrgID, *target.Discovery)
+	if err != nil {
+		return collected, err
+	}
+
+	for _, ep := range endpoints {
+		t := target
+		t.Discovery = nil
+		t.URL, err = ep.url(target.URL)
+		if err != nil {
+			d.log.Warn("Invalid discovered target", zap.String("target", target.Name), zap.Error(err))
+			continue
+		}
+
+		// A single unreachable endpoint must not prevent scraping the others.
+		ms, err := d.scraper.gather(ctx, t, ep.tags())
+		if err != nil {
+			d.log.Warn("Unable to gather discovered target", zap.String("target", target.Name), zap.String("url", t.URL), zap.Error(err))
+			continue
+		}
+		collected.MetricsSlice = append(collected.MetricsSlice, ms.MetricsSlice...)
+	}
+	return collected, nil
+}
+
+// url returns the URL to scrape. The scheme and path default to those of
+// base, or http and /metrics if base is empty.
+func (t DiscoveredTarget) url(base string) (string, error) {
+	addr := t.Labels[addressLabel]
+	if addr == "" {
+		return "", fmt.Errorf("discovered target has no %s label", addressLabel)
+	}
+
+	u := &url.URL{Scheme: "http", Path: "/metrics"}
+	if base != "" {
+		b, err := url.Parse(base)
+		if err != nil {
+			return "", err
+		}
+		if b.Scheme != "" {
+			u.Scheme = b.Scheme
+		}
+		if b.Path != "" {
+			u.Path = b.Path
+		}
+	}
+	if v := t.Labels[schemeLabel]; v != "" {
+		u.Scheme = v
+	}
+	if v := t.Labels[metricsPathLabel]; v != "" {
+		u.Path = v
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	u.Host = addr
+	return u.String(), nil
+}
+
+// tags returns the labels to add to the metrics gathered from the target.
+func (t DiscoveredTarget) tags() map[string]string {
+	tags := make(map[string]string, len(t.Labels)+1)
+	for k, v := range t.Labels {
+		if strings.HasPrefix(k, "__") || v == "" {
+			continue
+		}
+		tags[k] = v
+	}
+	if _, ok := tags[instanceLabel]; !ok {
+		tags[instanceLabel] = t.Labels[addressLabel]
+	}
+	return tags
+}
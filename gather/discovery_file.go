@@ -0,0 +1,142 @@
+package gather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/influxdata/influxdb/v2"
+)
+
+// fileTargetGroup is a group of targets in a prometheus file_sd file.
+type fileTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+type cachedFile struct {
+	modTime time.Time
+	size    int64
+	targets []DiscoveredTarget
+}
+
+// FileDiscoverer discovers targets from prometheus file_sd files, in JSON or
+// YAML. Files are re-read whenever they change on disk.
+type FileDiscoverer struct {
+	dir string
+
+	mu    sync.Mutex
+	cache map[string]cachedFile
+}
+
+// NewFileDiscoverer returns a discoverer that reads the files of each
+// organization relative to its own subdirectory of dir, named by its ID.
+// Paths that resolve outside of the directory of the organization are
+// refused.
+func NewFileDiscoverer(dir string) *FileDiscoverer {
+	return &FileDiscoverer{
+		dir:   filepath.Clean(dir),
+		cache: make(map[string]cachedFile),
+	}
+}
+
+// Discover returns the targets listed in the files of the organization orgID
+// matching cfg.Files, which may be globs.
+func (d *FileDiscoverer) Discover(ctx context.Context, orgID influxdb.ID, cfg influxdb.ScraperDiscovery) ([]DiscoveredTarget, error) {
+	var targets []DiscoveredTarget
+	for _, pattern := range cfg.Files {
+		path, err := d.resolve(orgID, pattern)
+		if err != nil {
+			return nil, err
+		}
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			ts, err := d.read(m)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, ts...)
+		}
+	}
+	return targets, nil
+}
+
+// resolve returns the path of file inside the discovery directory of the
+// organization orgID.
+func (d *FileDiscoverer) resolve(orgID influxdb.ID, file string) (string, error) {
+	if !orgID.Valid() {
+		return "", fmt.Errorf("discovery file %q has no organization", file)
+	}
+	dir := filepath.Join(d.dir, orgID.String())
+	path := filepath.Join(dir, filepath.Clean("/"+file))
+	if path != dir && !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+		return "", fmt.Errorf("discovery file %q is outside of the discovery directory", file)
+	}
+	return path, nil
+}
+
+// read returns the targets in path, from the cache if the file is unchanged.
+func (d *FileDiscoverer) read(path string) ([]DiscoveredTarget, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if c, ok := d.cache[path]; ok && c.modTime.Equal(fi.ModTime()) && c.size == fi.Size() {
+		return c.targets, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := parseFileTargets(b)
+	if err != nil {
+		return nil, fmt.Errorf("reading discovery file %s: %v", path, err)
+	}
+
+	d.cache[path] = cachedFile{
+		modTime: fi.ModTime(),
+		size:    fi.Size(),
+		targets: targets,
+	}
+	return targets, nil
+}
+
+// parseFileTargets parses the contents of a file_sd file. JSON is a subset of
+// YAML, so both are handled by the YAML parser.
+func parseFileTargets(b []byte) ([]DiscoveredTarget, error) {
+	var groups []fileTargetGroup
+	if err := yaml.Unmarshal(b, &groups); err != nil {
+		return nil, err
+	}
+
+	var targets []DiscoveredTarget
+	for _, g := range groups {
+		for _, addr := range g.Targets {
+			if addr == "" {
+				return nil, errors.New("empty target address")
+			}
+			labels := make(map[string]string, len(g.Labels)+1)
+			for k, v := range g.Labels {
+				labels[k] = v
+			}
+			labels[addressLabel] = addr
+			targets = append(targets, DiscoveredTarget{Labels: labels})
+		}
+	}
+	return targets, nil
+}
@@ -0,0 +1,313 @@
+package gather
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// Annotations controlling how kubernetes workloads are scraped.
+const (
+	scrapeAnnotation = "prometheus.io/scrape"
+	portAnnotation   = "prometheus.io/port"
+	pathAnnotation   = "prometheus.io/path"
+	schemeAnnotation = "prometheus.io/scheme"
+)
+
+// Labels added to metrics gathered from kubernetes targets.
+const (
+	kubernetesNamespaceLabel = "namespace"
+	kubernetesPodLabel       = "pod"
+	kubernetesServiceLabel   = "service"
+)
+
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// KubernetesDiscoverer discovers annotated pods and service endpoints using
+// the kubernetes API. The workloads are listed on every scrape, so targets
+// follow pods as they are scaled and rescheduled.
+type KubernetesDiscoverer struct {
+	apiURL    string
+	tokenFile string
+	client    *http.Client
+}
+
+// NewKubernetesDiscoverer returns a discoverer using the kubernetes API at
+// apiURL. If tokenFile is set, its contents are sent as a bearer token with
+// every request; it is re-read each time since service account tokens rotate.
+func NewKubernetesDiscoverer(apiURL, tokenFile string, client *http.Client) *KubernetesDiscoverer {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &KubernetesDiscoverer{
+		apiURL:    strings.TrimSuffix(apiURL, "/"),
+		tokenFile: tokenFile,
+		client:    client,
+	}
+}
+
+// NewInClusterKubernetesDiscoverer returns a discoverer authenticating with
+// the service account of the pod influxd runs in.
+func NewInClusterKubernetesDiscoverer() (*KubernetesDiscoverer, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("unable to load in-cluster configuration, KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be defined")
+	}
+
+	ca, err := ioutil.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("unable to parse kubernetes ca certificate")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+
+	apiURL := "https://" + net.JoinHostPort(host, port)
+	return NewKubernetesDiscoverer(apiURL, serviceAccountDir+"/token", &http.Client{Transport: transport}), nil
+}
+
+type k8sObjectMeta struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type k8sPodList struct {
+	Items []struct {
+		Metadata k8sObjectMeta `json:"metadata"`
+		Spec     struct {
+			Containers []struct {
+				Ports []struct {
+					ContainerPort int    `json:"containerPort"`
+					Protocol      string `json:"protocol"`
+				} `json:"ports"`
+			} `json:"containers"`
+		} `json:"spec"`
+		Status struct {
+			Phase string `json:"phase"`
+			PodIP string `json:"podIP"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+type k8sServiceList struct {
+	Items []struct {
+		Metadata k8sObjectMeta `json:"metadata"`
+	} `json:"items"`
+}
+
+type k8sEndpoints struct {
+	Subsets []struct {
+		Addresses []struct {
+			IP        string `json:"ip"`
+			TargetRef *struct {
+				Kind string `json:"kind"`
+				Name string `json:"name"`
+			} `json:"targetRef"`
+		} `json:"addresses"`
+		Ports []struct {
+			Port     int    `json:"port"`
+			Protocol string `json:"protocol"`
+		} `json:"ports"`
+	} `json:"subsets"`
+}
+
+// Discover lists the annotated pods, or the endpoints of annotated services,
+// in the configured namespaces, or in all namespaces if there are none. The
+// workloads are not restricted by organization, so only operators may
+// configure kubernetes discovery.
+func (d *KubernetesDiscoverer) Discover(ctx context.Context, orgID influxdb.ID, cfg influxdb.ScraperDiscovery) ([]DiscoveredTarget, error) {
+	namespaces := cfg.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+
+	var targets []DiscoveredTarget
+	for _, ns := range namespaces {
+		var (
+			ts  []DiscoveredTarget
+			err error
+		)
+		switch cfg.Role {
+		case influxdb.KubernetesRolePod:
+			ts, err = d.discoverPods(ctx, ns, cfg.LabelSelector)
+		case influxdb.KubernetesRoleEndpoints:
+			ts, err = d.discoverEndpoints(ctx, ns, cfg.LabelSelector)
+		default:
+			err = fmt.Errorf("unsupported kubernetes role %q", cfg.Role)
+		}
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, ts...)
+	}
+	return targets, nil
+}
+
+func (d *KubernetesDiscoverer) discoverPods(ctx context.Context, ns, selector string) ([]DiscoveredTarget, error) {
+	var pods k8sPodList
+	if err := d.get(ctx, resourcePath(ns, "pods", ""), selector, &pods); err != nil {
+		return nil, err
+	}
+
+	var targets []DiscoveredTarget
+	for _, p := range pods.Items {
+		if p.Status.Phase != "Running" || p.Status.PodIP == "" || !scrapeEnabled(p.Metadata.Annotations) {
+			continue
+		}
+
+		var ports []int
+		if port, ok := annotatedPort(p.Metadata.Annotations); ok {
+			ports = []int{port}
+		} else {
+			for _, c := range p.Spec.Containers {
+				for _, cp := range c.Ports {
+					if cp.Protocol == "" || cp.Protocol == "TCP" {
+						ports = append(ports, cp.ContainerPort)
+					}
+				}
+			}
+		}
+
+		for _, port := range ports {
+			labels := annotationLabels(p.Metadata.Annotations)
+			labels[addressLabel] = net.JoinHostPort(p.Status.PodIP, strconv.Itoa(port))
+			labels[kubernetesNamespaceLabel] = p.Metadata.Namespace
+			labels[kubernetesPodLabel] = p.Metadata.Name
+			targets = append(targets, DiscoveredTarget{Labels: labels})
+		}
+	}
+	return targets, nil
+}
+
+func (d *KubernetesDiscoverer) discoverEndpoints(ctx context.Context, ns, selector string) ([]DiscoveredTarget, error) {
+	var services k8sServiceList
+	if err := d.get(ctx, resourcePath(ns, "services", ""), selector, &services); err != nil {
+		return nil, err
+	}
+
+	var targets []DiscoveredTarget
+	for _, s := range services.Items {
+		if !scrapeEnabled(s.Metadata.Annotations) {
+			continue
+		}
+
+		var eps k8sEndpoints
+		if err := d.get(ctx, resourcePath(s.Metadata.Namespace, "endpoints", s.Metadata.Name), "", &eps); err != nil {
+			return nil, err
+		}
+
+		port, annotated := annotatedPort(s.Metadata.Annotations)
+		for _, ss := range eps.Subsets {
+			var ports []int
+			if annotated {
+				ports = []int{port}
+			} else {
+				for _, p := range ss.Ports {
+					if p.Protocol == "" || p.Protocol == "TCP" {
+						ports = append(ports, p.Port)
+					}
+				}
+			}
+
+			for _, addr := range ss.Addresses {
+				for _, port := range ports {
+					labels := annotationLabels(s.Metadata.Annotations)
+					labels[addressLabel] = net.JoinHostPort(addr.IP, strconv.Itoa(port))
+					labels[kubernetesNamespaceLabel] = s.Metadata.Namespace
+					labels[kubernetesServiceLabel] = s.Metadata.Name
+					if addr.TargetRef != nil && addr.TargetRef.Kind == "Pod" {
+						labels[kubernetesPodLabel] = addr.TargetRef.Name
+					}
+					targets = append(targets, DiscoveredTarget{Labels: labels})
+				}
+			}
+		}
+	}
+	return targets, nil
+}
+
+// get decodes the kubernetes API resource at path into v.
+func (d *KubernetesDiscoverer) get(ctx context.Context, path, selector string, v interface{}) error {
+	u := d.apiURL + path
+	if selector != "" {
+		u += "?" + url.Values{"labelSelector": []string{selector}}.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if d.tokenFile != "" {
+		token, err := ioutil.ReadFile(d.tokenFile)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kubernetes API request %s returned status %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// resourcePath returns the API path of a core resource, in all namespaces if ns is empty.
+func resourcePath(ns, resource, name string) string {
+	p := "/api/v1"
+	if ns != "" {
+		p += "/namespaces/" + url.PathEscape(ns)
+	}
+	p += "/" + resource
+	if name != "" {
+		p += "/" + url.PathEscape(name)
+	}
+	return p
+}
+
+func scrapeEnabled(annotations map[string]string) bool {
+	return annotations[scrapeAnnotation] == "true"
+}
+
+func annotatedPort(annotations map[string]string) (int, bool) {
+	port, err := strconv.Atoi(annotations[portAnnotation])
+	if err != nil || port <= 0 {
+		return 0, false
+	}
+	return port, true
+}
+
+// annotationLabels returns the scrape labels set by annotations.
+func annotationLabels(annotations map[string]string) map[string]string {
+	labels := make(map[string]string)
+	if v := annotations[schemeAnnotation]; v != "" {
+		labels[schemeLabel] = v
+	}
+	if v := annotations[pathAnnotation]; v != "" {
+		labels[metricsPathLabel] = v
+	}
+	return labels
+}
@@ -0,0 +1,203 @@
+package gather
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestFileDiscoverer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influxdb-file-sd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const orgID, otherOrgID influxdb.ID = 1, 2
+	if err := os.Mkdir(filepath.Join(dir, orgID.String()), 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, orgID.String(), "targets.yml")
+	write := func(contents string, mtime time.Time) {
+		t.Helper()
+		if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`
+- targets: ["10.0.0.1:9100", "10.0.0.2:9100"]
+  labels:
+    env: prod
+`, time.Unix(1000, 0))
+
+	d := NewFileDiscoverer(dir)
+	cfg := influxdb.ScraperDiscovery{Type: influxdb.ScraperDiscoveryFile, Files: []string{"*.yml"}}
+
+	got, err := d.Discover(context.Background(), orgID, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiscoveredTarget{
+		{Labels: map[string]string{addressLabel: "10.0.0.1:9100", "env": "prod"}},
+		{Labels: map[string]string{addressLabel: "10.0.0.2:9100", "env": "prod"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected targets -want/+got:\n%s", diff)
+	}
+
+	// The file is reloaded once it changes.
+	write(`[{"targets": ["10.0.0.3:9100"]}]`, time.Unix(2000, 0))
+	got, err = d.Discover(context.Background(), orgID, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []DiscoveredTarget{
+		{Labels: map[string]string{addressLabel: "10.0.0.3:9100"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected targets after reload -want/+got:\n%s", diff)
+	}
+
+	// The files of another organization cannot be read.
+	got, err = d.Discover(context.Background(), otherOrgID, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no targets of another organization, got %v", got)
+	}
+	cfg.Files = []string{"../" + orgID.String() + "/*.yml"}
+	got, err = d.Discover(context.Background(), otherOrgID, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no targets outside of the discovery directory of the organization, got %v", got)
+	}
+
+	// Files outside of the discovery directory cannot be read.
+	cfg.Files = []string{"../../etc/passwd"}
+	got, err = d.Discover(context.Background(), orgID, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no targets outside of the discovery directory, got %v", got)
+	}
+}
+
+func TestKubernetesDiscoverer_Pods(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/default/pods" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if got := r.URL.Query().Get("labelSelector"); got != "app=web" {
+			t.Errorf("unexpected label selector %q", got)
+		}
+		w.Write([]byte(`{"items": [
+			{
+				"metadata": {"name": "web-1", "namespace": "default", "annotations": {"prometheus.io/scrape": "true", "prometheus.io/port": "8080", "prometheus.io/path": "/stats"}},
+				"status": {"phase": "Running", "podIP": "10.1.0.1"}
+			},
+			{
+				"metadata": {"name": "web-2", "namespace": "default", "annotations": {"prometheus.io/scrape": "true"}},
+				"spec": {"containers": [{"ports": [{"containerPort": 9090}, {"containerPort": 53, "protocol": "UDP"}]}]},
+				"status": {"phase": "Running", "podIP": "10.1.0.2"}
+			},
+			{
+				"metadata": {"name": "web-3", "namespace": "default", "annotations": {"prometheus.io/scrape": "true"}},
+				"status": {"phase": "Pending"}
+			},
+			{
+				"metadata": {"name": "db-1", "namespace": "default"},
+				"status": {"phase": "Running", "podIP": "10.1.0.4"}
+			}
+		]}`))
+	}))
+	defer api.Close()
+
+	d := NewKubernetesDiscoverer(api.URL, "", nil)
+	got, err := d.Discover(context.Background(), 1, influxdb.ScraperDiscovery{
+		Type:          influxdb.ScraperDiscoveryKubernetes,
+		Role:          influxdb.KubernetesRolePod,
+		Namespaces:    []string{"default"},
+		LabelSelector: "app=web",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []DiscoveredTarget{
+		{Labels: map[string]string{addressLabel: "10.1.0.1:8080", metricsPathLabel: "/stats", "namespace": "default", "pod": "web-1"}},
+		{Labels: map[string]string{addressLabel: "10.1.0.2:9090", "namespace": "default", "pod": "web-2"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected targets -want/+got:\n%s", diff)
+	}
+}
+
+type staticDiscoverer []DiscoveredTarget
+
+func (d staticDiscoverer) Discover(context.Context, influxdb.ID, influxdb.ScraperDiscovery) ([]DiscoveredTarget, error) {
+	return d, nil
+}
+
+func TestDiscoveryScraper(t *testing.T) {
+	ts := httptest.NewServer(&mockHTTPHandler{
+		responseMap: map[string]string{
+			"/metrics": sampleRespSmall,
+		},
+	})
+	defer ts.Close()
+	addr := strings.TrimPrefix(ts.URL, "http://")
+
+	s := &discoveryScraper{
+		scraper: new(prometheusScraper),
+		discoverers: map[string]Discoverer{
+			influxdb.ScraperDiscoveryFile: staticDiscoverer{
+				{Labels: map[string]string{addressLabel: addr, "env": "prod"}},
+				{Labels: map[string]string{addressLabel: "127.0.0.1:1"}},
+			},
+		},
+		log: zaptest.NewLogger(t),
+	}
+
+	collected, err := s.Gather(context.Background(), influxdb.ScraperTarget{
+		Type:      influxdb.PrometheusScraperType,
+		Discovery: &influxdb.ScraperDiscovery{Type: influxdb.ScraperDiscoveryFile, Files: []string{"targets.json"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collected.MetricsSlice) == 0 {
+		t.Fatal("expected metrics from the reachable target")
+	}
+	for _, m := range collected.MetricsSlice {
+		if m.Tags["env"] != "prod" || m.Tags[instanceLabel] != addr {
+			t.Fatalf("expected discovered labels as tags, got %v", m.Tags)
+		}
+	}
+
+	_, err = s.Gather(context.Background(), influxdb.ScraperTarget{
+		Type:      influxdb.PrometheusScraperType,
+		Discovery: &influxdb.ScraperDiscovery{Type: influxdb.ScraperDiscoveryKubernetes, Role: influxdb.KubernetesRolePod},
+	})
+	if err == nil {
+		t.Fatal("expected an error for a disabled discovery type")
+	}
+}
@@ -2,6 +2,9 @@ package gather
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -21,13 +24,98 @@ type prometheusScraper struct{}
 
 // Gather parse metrics from a scraper target url.
 func (p *prometheusScraper) Gather(ctx context.Context, target influxdb.ScraperTarget) (collected MetricsCollection, err error) {
-	resp, err := http.Get(target.URL)
+	return p.gather(ctx, target, nil)
+}
+
+// gather scrapes target.URL, adding labels to the tags of every metric that
+// does not already have them before relabeling.
+func (p *prometheusScraper) gather(ctx context.Context, target influxdb.ScraperTarget, labels map[string]string) (collected MetricsCollection, err error) {
+	rl, err := newRelabeler(target.MetricRelabelConfigs)
+	if err != nil {
+		return collected, err
+	}
+
+	client, err := newHTTPClient(target.TLS)
+	if err != nil {
+		return collected, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, target.URL, nil)
+	if err != nil {
+		return collected, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", acceptHeader)
+	for k, v := range target.Headers {
+		req.Header.Set(k, v)
+	}
+	if a := target.Auth; a != nil {
+		switch a.Type {
+		case influxdb.ScraperAuthBasic:
+			req.SetBasicAuth(a.Username, a.Password)
+		case influxdb.ScraperAuthBearer:
+			req.Header.Set("Authorization", "Bearer "+a.Token)
+		}
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return collected, err
 	}
 	defer resp.Body.Close()
 
-	return p.parse(resp.Body, resp.Header, target)
+	if resp.StatusCode != http.StatusOK {
+		return collected, fmt.Errorf("scrape of %s returned status %s", target.URL, resp.Status)
+	}
+
+	collected, err = p.parse(resp.Body, resp.Header, target)
+	if err != nil {
+		return collected, err
+	}
+	for _, m := range collected.MetricsSlice {
+		for k, v := range labels {
+			if _, ok := m.Tags[k]; !ok {
+				m.Tags[k] = v
+			}
+		}
+	}
+	collected.MetricsSlice = rl.Relabel(collected.MetricsSlice)
+	return collected, nil
+}
+
+// acceptHeader prefers the protobuf exposition format and falls back to text.
+const acceptHeader = `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,text/plain;version=0.0.4;q=0.3`
+
+// newHTTPClient returns a client for the target's TLS configuration.
+func newHTTPClient(cfg *influxdb.ScraperTLSConfig) (*http.Client, error) {
+	if cfg == nil {
+		return http.DefaultClient, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(cfg.CACert)) {
+			return nil, errors.New("unable to parse tls ca certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.ClientCert != "" {
+		cert, err := tls.X509KeyPair([]byte(cfg.ClientCert), []byte(cfg.ClientKey))
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	// The client is not reused between scrapes.
+	transport.DisableKeepAlives = true
+	return &http.Client{Transport: transport}, nil
 }
 
 func (p *prometheusScraper) parse(r io.Reader, header http.Header, target influxdb.ScraperTarget) (collected MetricsCollection, err error) {
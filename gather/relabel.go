@@ -0,0 +1,132 @@
+package gather
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// metricNameLabel is the label holding the metric name during relabeling.
+const metricNameLabel = "__name__"
+
+type relabelRule struct {
+	influxdb.ScraperRelabelConfig
+	regex *regexp.Regexp
+}
+
+// relabeler applies prometheus style relabel rules to scraped metrics.
+type relabeler []relabelRule
+
+// newRelabeler compiles the relabel configs, filling in prometheus defaults.
+func newRelabeler(cfgs []influxdb.ScraperRelabelConfig) (relabeler, error) {
+	rs := make(relabeler, 0, len(cfgs))
+	for _, c := range cfgs {
+		if c.Action == "" {
+			c.Action = influxdb.RelabelReplace
+		}
+		if c.Separator == "" {
+			c.Separator = ";"
+		}
+		if c.Regex == "" {
+			c.Regex = "(.*)"
+		}
+		if c.Replacement == "" {
+			c.Replacement = "$1"
+		}
+		// Like prometheus, regexes must match the whole value.
+		re, err := regexp.Compile("^(?:" + c.Regex + ")$")
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, relabelRule{ScraperRelabelConfig: c, regex: re})
+	}
+	return rs, nil
+}
+
+// Relabel applies the rules to every metric, removing the ones that are dropped.
+func (rs relabeler) Relabel(ms MetricsSlice) MetricsSlice {
+	if len(rs) == 0 {
+		return ms
+	}
+
+	out := ms[:0]
+	for _, m := range ms {
+		if rs.relabel(&m) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// relabel applies the rules to m and reports whether it should be kept.
+func (rs relabeler) relabel(m *Metrics) bool {
+	labels := make(map[string]string, len(m.Tags)+1)
+	for k, v := range m.Tags {
+		labels[k] = v
+	}
+	labels[metricNameLabel] = m.Name
+
+	for _, r := range rs {
+		if !r.apply(labels) {
+			return false
+		}
+	}
+
+	name := labels[metricNameLabel]
+	if name == "" {
+		return false
+	}
+
+	tags := make(map[string]string, len(labels))
+	for k, v := range labels {
+		// Labels starting with __ are reserved for internal use.
+		if strings.HasPrefix(k, "__") || v == "" {
+			continue
+		}
+		tags[k] = v
+	}
+	m.Name = name
+	m.Tags = tags
+	return true
+}
+
+// apply runs a single rule against labels and reports whether the metric is kept.
+func (r relabelRule) apply(labels map[string]string) bool {
+	values := make([]string, 0, len(r.SourceLabels))
+	for _, l := range r.SourceLabels {
+		values = append(values, labels[l])
+	}
+	val := strings.Join(values, r.Separator)
+
+	switch r.Action {
+	case influxdb.RelabelKeep:
+		return r.regex.MatchString(val)
+	case influxdb.RelabelDrop:
+		return !r.regex.MatchString(val)
+	case influxdb.RelabelLabelDrop:
+		for k := range labels {
+			if k != metricNameLabel && r.regex.MatchString(k) {
+				delete(labels, k)
+			}
+		}
+	case influxdb.RelabelLabelKeep:
+		for k := range labels {
+			if k != metricNameLabel && !r.regex.MatchString(k) {
+				delete(labels, k)
+			}
+		}
+	case influxdb.RelabelReplace:
+		idx := r.regex.FindStringSubmatchIndex(val)
+		if idx == nil {
+			return true
+		}
+		res := string(r.regex.ExpandString(nil, r.Replacement, val, idx))
+		if res == "" {
+			delete(labels, r.TargetLabel)
+		} else {
+			labels[r.TargetLabel] = res
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package gather
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestRelabeler_Relabel(t *testing.T) {
+	ms := MetricsSlice{
+		{Name: "http_requests_total", Tags: map[string]string{"instance": "a:9100", "path": "/"}},
+		{Name: "go_gc_duration_seconds", Tags: map[string]string{"instance": "a:9100"}},
+		{Name: "process_cpu_seconds_total", Tags: map[string]string{"instance": "b:9100", "secret": "x"}},
+	}
+
+	rl, err := newRelabeler([]influxdb.ScraperRelabelConfig{
+		{
+			SourceLabels: []string{"__name__"},
+			Regex:        "go_.*",
+			Action:       influxdb.RelabelDrop,
+		},
+		{
+			SourceLabels: []string{"instance"},
+			Regex:        "(.*):\\d+",
+			TargetLabel:  "host",
+		},
+		{
+			Regex:  "instance|secret",
+			Action: influxdb.RelabelLabelDrop,
+		},
+		{
+			SourceLabels: []string{"__name__"},
+			Regex:        "(.*)_total",
+			TargetLabel:  "__name__",
+			Replacement:  "${1}",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := rl.Relabel(ms)
+	want := MetricsSlice{
+		{Name: "http_requests", Tags: map[string]string{"host": "a", "path": "/"}},
+		{Name: "process_cpu_seconds", Tags: map[string]string{"host": "b"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected metrics -want/+got:\n%s", diff)
+	}
+}
+
+func TestRelabeler_Keep(t *testing.T) {
+	rl, err := newRelabeler([]influxdb.ScraperRelabelConfig{
+		{
+			SourceLabels: []string{"job", "__name__"},
+			Regex:        "node;up",
+			Action:       influxdb.RelabelKeep,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := rl.Relabel(MetricsSlice{
+		{Name: "up", Tags: map[string]string{"job": "node"}},
+		{Name: "up", Tags: map[string]string{"job": "db"}},
+		{Name: "down", Tags: map[string]string{"job": "node"}},
+	})
+	if len(got) != 1 || got[0].Tags["job"] != "node" || got[0].Name != "up" {
+		t.Fatalf("unexpected metrics %+v", got)
+	}
+}
@@ -35,6 +35,21 @@ type Scheduler struct {
 	gather chan struct{}
 }
 
+// SchedulerOption configures the scrapers of a Scheduler.
+type SchedulerOption func(*schedulerConfig)
+
+type schedulerConfig struct {
+	discoverers map[string]Discoverer
+}
+
+// WithDiscoverer enables service discovery of the given type, one of
+// influxdb.ScraperDiscoveryKubernetes or influxdb.ScraperDiscoveryFile.
+func WithDiscoverer(typ string, d Discoverer) SchedulerOption {
+	return func(c *schedulerConfig) {
+		c.discoverers[typ] = d
+	}
+}
+
 // NewScheduler creates a new Scheduler and subscriptions for scraper jobs.
 func NewScheduler(
 	log *zap.Logger,
@@ -44,6 +59,7 @@ func NewScheduler(
 	s nats.Subscriber,
 	interval time.Duration,
 	timeout time.Duration,
+	opts ...SchedulerOption,
 ) (*Scheduler, error) {
 	if interval == 0 {
 		interval = 60 * time.Second
@@ -60,9 +76,18 @@ func NewScheduler(
 		gather:    make(chan struct{}, 100),
 	}
 
+	cfg := schedulerConfig{discoverers: make(map[string]Discoverer)}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	for i := 0; i < numScrapers; i++ {
 		err := s.Subscribe(promTargetSubject, "metrics", &handler{
-			Scraper:   new(prometheusScraper),
+			Scraper: &discoveryScraper{
+				scraper:     new(prometheusScraper),
+				discoverers: cfg.discoverers,
+				log:         log,
+			},
 			Publisher: p,
 			log:       log,
 		})
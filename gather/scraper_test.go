@@ -155,6 +155,39 @@ func TestPrometheusScraper(t *testing.T) {
 	}
 }
 
+func TestPrometheusScraper_Auth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("X-Scope") != "infra" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write([]byte("up 1\n"))
+	}))
+	defer ts.Close()
+
+	target := influxdb.ScraperTarget{
+		URL:      ts.URL + "/metrics",
+		OrgID:    *orgID,
+		BucketID: *bucketID,
+	}
+
+	scraper := new(prometheusScraper)
+	if _, err := scraper.Gather(context.Background(), target); err == nil {
+		t.Fatal("expected unauthenticated scrape to fail")
+	}
+
+	target.Auth = &influxdb.ScraperAuth{Type: influxdb.ScraperAuthBearer, Token: "secret"}
+	target.Headers = map[string]string{"X-Scope": "infra"}
+	results, err := scraper.Gather(context.Background(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results.MetricsSlice) != 1 || results.MetricsSlice[0].Name != "up" {
+		t.Fatalf("unexpected metrics %+v", results.MetricsSlice)
+	}
+}
+
 const sampleResp = `
 # 	HELP go_gc_duration_seconds A summary of the GC invocation durations.
 # TYPE go_gc_duration_seconds summary
@@ -0,0 +1,467 @@
+// Package grafana converts Grafana alert rules into InfluxDB checks and
+// notification rules.
+//
+// A Grafana alert rule queries a data source, reduces the series it returns
+// to a value and fires when the value crosses a threshold. The supported
+// rules query InfluxDB with Flux and compare the reduced value with a single
+// threshold or range. They are converted into a threshold check running on
+// the interval of their group, and the contact point they notify into a
+// notification endpoint with a notification rule sending the statuses of the
+// check to it.
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+)
+
+// DefaultInterval is the interval of the rule groups that do not set one.
+const DefaultInterval = time.Minute
+
+// RuleTag is the tag of the statuses of a converted check identifying the
+// alert rule it was converted from. The notification rule of the alert rule
+// matches it.
+const RuleTag = "grafana_rule"
+
+const (
+	defaultStatusMessageTemplate       = "Check: ${ r._check_name } is: ${ r._level }"
+	defaultNotificationMessageTemplate = "Notification Rule: ${ r._notification_rule_name } triggered by check: ${ r._check_name }: ${ r._message }"
+)
+
+// expressionDatasources are the UIDs of the data source of the server side
+// expressions of Grafana.
+var expressionDatasources = map[string]bool{
+	"__expr__": true,
+	"-100":     true,
+}
+
+// fluxReducers maps the Grafana reducers, of reduce expressions and classic
+// conditions, to the Flux functions computing them.
+var fluxReducers = map[string]string{
+	"avg":    "mean",
+	"count":  "count",
+	"last":   "last",
+	"max":    "max",
+	"mean":   "mean",
+	"median": "median",
+	"min":    "min",
+	"sum":    "sum",
+}
+
+// severityLevels maps the values of the severity label of alert rules to the
+// levels of the statuses of the converted checks. Rules without one are
+// critical.
+var severityLevels = map[string]notification.CheckLevel{
+	"critical": notification.Critical,
+	"crit":     notification.Critical,
+	"error":    notification.Critical,
+	"warning":  notification.Warn,
+	"warn":     notification.Warn,
+	"info":     notification.Info,
+}
+
+// RuleGroup is a group of alert rules evaluated on the same interval, as
+// exported by Grafana.
+type RuleGroup struct {
+	Name     string      `json:"name"`
+	Folder   string      `json:"folder"`
+	Interval string      `json:"interval"`
+	Rules    []AlertRule `json:"rules"`
+}
+
+// AlertRule is a Grafana alert rule.
+type AlertRule struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+	// Condition is the RefID of the query or expression of Data deciding
+	// whether the rule fires.
+	Condition            string                `json:"condition"`
+	Data                 []AlertQuery          `json:"data"`
+	For                  string                `json:"for"`
+	Annotations          map[string]string     `json:"annotations"`
+	Labels               map[string]string     `json:"labels"`
+	IsPaused             bool                  `json:"isPaused"`
+	NotificationSettings *NotificationSettings `json:"notification_settings,omitempty"`
+}
+
+// NotificationSettings select the contact point an alert rule notifies,
+// instead of the notification policies.
+type NotificationSettings struct {
+	Receiver string `json:"receiver"`
+}
+
+// AlertQuery is a query of a data source, or an expression over other
+// queries, of an alert rule.
+type AlertQuery struct {
+	RefID             string             `json:"refId"`
+	DatasourceUID     string             `json:"datasourceUid"`
+	RelativeTimeRange *RelativeTimeRange `json:"relativeTimeRange,omitempty"`
+	Model             json.RawMessage    `json:"model"`
+}
+
+// RelativeTimeRange is the time range of a query in seconds before the
+// evaluation.
+type RelativeTimeRange struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// ContactPoint is a Grafana contact point, as returned by the provisioning
+// API.
+type ContactPoint struct {
+	UID                   string                 `json:"uid"`
+	Name                  string                 `json:"name"`
+	Type                  string                 `json:"type"`
+	Settings              map[string]interface{} `json:"settings"`
+	DisableResolveMessage bool                   `json:"disableResolveMessage"`
+}
+
+// Converted is an alert rule converted into a check and, if it notifies a
+// contact point, the notification rule and endpoint sending its statuses.
+type Converted struct {
+	Check    *check.Threshold
+	Rule     influxdb.NotificationRule
+	Endpoint influxdb.NotificationEndpoint
+	// Paused is true if the alert rule is paused.
+	Paused bool
+	// Warnings describe the parts of the alert rule that are not converted.
+	Warnings []string
+}
+
+type queryModel struct {
+	Query    string `json:"query"`
+	RawQuery bool   `json:"rawQuery"`
+}
+
+type expressionModel struct {
+	Type       string `json:"type"`
+	Expression string `json:"expression"`
+	Reducer    string `json:"reducer"`
+	Conditions []struct {
+		Evaluator evaluator `json:"evaluator"`
+		Query     struct {
+			Params []string `json:"params"`
+		} `json:"query"`
+		Reducer struct {
+			Type string `json:"type"`
+		} `json:"reducer"`
+	} `json:"conditions"`
+}
+
+type evaluator struct {
+	Type   string    `json:"type"`
+	Params []float64 `json:"params"`
+}
+
+// Convert converts the alert rule r of a group evaluated on the interval
+// every. The notification rule and endpoint are only set if cp, the contact
+// point r notifies, is not nil. The IDs of the organization and of the
+// endpoint are left to be set.
+func Convert(r AlertRule, every time.Duration, cp *ContactPoint) (*Converted, error) {
+	if r.Title == "" {
+		return nil, ErrInvalidRule("title is empty")
+	}
+	c := &converter{
+		rule:    r,
+		every:   every,
+		queries: make(map[string]AlertQuery, len(r.Data)),
+	}
+	for _, q := range r.Data {
+		c.queries[q.RefID] = q
+	}
+	return c.convert(cp)
+}
+
+type converter struct {
+	rule     AlertRule
+	every    time.Duration
+	queries  map[string]AlertQuery
+	warnings []string
+}
+
+func (c *converter) warnf(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+func (c *converter) convert(cp *ContactPoint) (*Converted, error) {
+	query, reducer, ev, err := c.condition()
+	if err != nil {
+		return nil, err
+	}
+	text, err := c.fluxQuery(query, reducer)
+	if err != nil {
+		return nil, err
+	}
+
+	level := notification.Critical
+	if s, ok := c.rule.Labels["severity"]; ok {
+		l, ok := severityLevels[strings.ToLower(s)]
+		if !ok {
+			c.warnf("severity %q is not a level of checks, the check is critical", s)
+		} else {
+			level = l
+		}
+	}
+	thresholds, err := thresholds(ev, level)
+	if err != nil {
+		return nil, err
+	}
+
+	every, err := notification.FromTimeDuration(c.every)
+	if err != nil {
+		return nil, ErrInvalidRule(err.Error())
+	}
+	if f := c.rule.For; f != "" && f != "0s" {
+		c.warnf("the pending period of %s is not converted, the check changes status on the first evaluation crossing the threshold", f)
+	}
+
+	chk := &check.Threshold{
+		Base: check.Base{
+			Name:                  c.rule.Title,
+			Description:           c.rule.Annotations["description"],
+			Query:                 influxdb.DashboardQuery{Text: text, EditMode: "advanced"},
+			StatusMessageTemplate: c.messageTemplate(c.rule.Annotations["summary"]),
+			Every:                 &every,
+			Tags:                  c.tags(),
+		},
+		Thresholds: thresholds,
+	}
+	conv := &Converted{
+		Check:  chk,
+		Paused: c.rule.IsPaused,
+	}
+	if cp != nil {
+		if conv.Endpoint, err = convertContactPoint(*cp); err != nil {
+			return nil, err
+		}
+		conv.Rule = c.notificationRule(*cp, level, &every)
+	}
+	conv.Warnings = c.warnings
+	return conv, nil
+}
+
+// condition returns the data query the condition of the rule is computed
+// from, the Grafana reducer applied to it and the evaluator of the reduced
+// value.
+func (c *converter) condition() (AlertQuery, string, evaluator, error) {
+	cond, ok := c.queries[c.rule.Condition]
+	if !ok {
+		return AlertQuery{}, "", evaluator{}, ErrInvalidRule(fmt.Sprintf("condition %q is not a query of the rule", c.rule.Condition))
+	}
+	if !expressionDatasources[cond.DatasourceUID] {
+		return AlertQuery{}, "", evaluator{}, ErrUnsupported("alert rules without a threshold")
+	}
+	m, err := expression(cond)
+	if err != nil {
+		return AlertQuery{}, "", evaluator{}, err
+	}
+
+	switch m.Type {
+	case "threshold":
+		if len(m.Conditions) != 1 {
+			return AlertQuery{}, "", evaluator{}, ErrUnsupported("threshold expressions with several conditions")
+		}
+		input, ok := c.queries[m.Expression]
+		if !ok {
+			return AlertQuery{}, "", evaluator{}, ErrInvalidRule(fmt.Sprintf("expression %q is not a query of the rule", m.Expression))
+		}
+		if !expressionDatasources[input.DatasourceUID] {
+			return input, "", m.Conditions[0].Evaluator, nil
+		}
+		reduce, err := expression(input)
+		if err != nil {
+			return AlertQuery{}, "", evaluator{}, err
+		}
+		if reduce.Type != "reduce" {
+			return AlertQuery{}, "", evaluator{}, ErrUnsupported(reduce.Type + " expressions")
+		}
+		query, ok := c.queries[reduce.Expression]
+		if !ok || expressionDatasources[query.DatasourceUID] {
+			return AlertQuery{}, "", evaluator{}, ErrUnsupported("reductions of expressions")
+		}
+		return query, reduce.Reducer, m.Conditions[0].Evaluator, nil
+	case "classic_conditions":
+		if len(m.Conditions) != 1 {
+			return AlertQuery{}, "", evaluator{}, ErrUnsupported("classic conditions combining several conditions")
+		}
+		cc := m.Conditions[0]
+		if len(cc.Query.Params) == 0 {
+			return AlertQuery{}, "", evaluator{}, ErrInvalidRule("classic condition has no query")
+		}
+		query, ok := c.queries[cc.Query.Params[0]]
+		if !ok || expressionDatasources[query.DatasourceUID] {
+			return AlertQuery{}, "", evaluator{}, ErrInvalidRule(fmt.Sprintf("classic condition query %q is not a data source query of the rule", cc.Query.Params[0]))
+		}
+		return query, cc.Reducer.Type, cc.Evaluator, nil
+	default:
+		return AlertQuery{}, "", evaluator{}, ErrUnsupported(m.Type + " expressions")
+	}
+}
+
+func expression(q AlertQuery) (*expressionModel, error) {
+	var m expressionModel
+	if err := json.Unmarshal(q.Model, &m); err != nil {
+		return nil, ErrInvalidRule(fmt.Sprintf("expression %q: %v", q.RefID, err))
+	}
+	return &m, nil
+}
+
+// fluxQuery returns the Flux of the check computing the value of the data
+// query q reduced with the Grafana reducer.
+func (c *converter) fluxQuery(q AlertQuery, reducer string) (string, error) {
+	var m queryModel
+	if err := json.Unmarshal(q.Model, &m); err != nil {
+		return "", ErrInvalidRule(fmt.Sprintf("query %q: %v", q.RefID, err))
+	}
+	text := strings.TrimSpace(m.Query)
+	if text == "" || m.RawQuery || strings.HasPrefix(strings.ToUpper(text), "SELECT") {
+		return "", ErrUnsupported("alert rules querying with InfluxQL")
+	}
+	if tr := q.RelativeTimeRange; tr != nil && tr.From > 0 && time.Duration(tr.From-tr.To)*time.Second != c.every {
+		c.warnf("the time range of query %s is not converted, the check reads the points of the last %s", q.RefID, c.every)
+	}
+
+	if reducer == "" {
+		return text, nil
+	}
+	fn, ok := fluxReducers[reducer]
+	if !ok {
+		return "", ErrUnsupported(reducer + " reducers")
+	}
+	// The every of the window is replaced by the one of the check.
+	if strings.Contains(text, "aggregateWindow(") {
+		c.warnf("the %s reducer is not applied, the values of the aggregateWindow of query %s are compared", reducer, q.RefID)
+		return text, nil
+	}
+	return fmt.Sprintf("%s\n  |> aggregateWindow(every: %s, fn: %s)", text, c.every, fn), nil
+}
+
+func thresholds(ev evaluator, level notification.CheckLevel) ([]check.ThresholdConfig, error) {
+	base := check.ThresholdConfigBase{Level: level}
+	switch ev.Type {
+	case "gt", "lt":
+		if len(ev.Params) < 1 {
+			return nil, ErrInvalidRule("evaluator " + ev.Type + " has no threshold")
+		}
+		if ev.Type == "gt" {
+			return []check.ThresholdConfig{&check.Greater{ThresholdConfigBase: base, Value: ev.Params[0]}}, nil
+		}
+		return []check.ThresholdConfig{&check.Lesser{ThresholdConfigBase: base, Value: ev.Params[0]}}, nil
+	case "within_range", "outside_range":
+		if len(ev.Params) < 2 {
+			return nil, ErrInvalidRule("evaluator " + ev.Type + " has no range")
+		}
+		min, max := ev.Params[0], ev.Params[1]
+		if min > max {
+			min, max = max, min
+		}
+		return []check.ThresholdConfig{&check.Range{
+			ThresholdConfigBase: base,
+			Min:                 min,
+			Max:                 max,
+			Within:              ev.Type == "within_range",
+		}}, nil
+	default:
+		return nil, ErrUnsupported(ev.Type + " evaluators")
+	}
+}
+
+// tags returns the tags of the check: the labels of the rule, other than its
+// severity, and the RuleTag.
+func (c *converter) tags() []influxdb.Tag {
+	tags := make([]influxdb.Tag, 0, len(c.rule.Labels)+1)
+	for k, v := range c.rule.Labels {
+		if k == "severity" || k == RuleTag || v == "" {
+			continue
+		}
+		tags = append(tags, influxdb.Tag{Key: k, Value: v})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Key < tags[j].Key
+	})
+	return append(tags, influxdb.Tag{Key: RuleTag, Value: c.ruleTagValue()})
+}
+
+func (c *converter) ruleTagValue() string {
+	if c.rule.UID != "" {
+		return c.rule.UID
+	}
+	return c.rule.Title
+}
+
+// labelRef matches the references to the labels of a series in Grafana
+// templates.
+var labelRef = regexp.MustCompile(`{{\s*\$labels\.(\w+)\s*}}`)
+
+// messageTemplate converts the Grafana template of the summary of the rule
+// into the template of the status messages of the check. Only the references
+// to labels are converted, the default template is used for the others.
+func (c *converter) messageTemplate(summary string) string {
+	if summary == "" {
+		return defaultStatusMessageTemplate
+	}
+	tmpl := labelRef.ReplaceAllString(summary, "$${ r.${1} }")
+	if strings.Contains(tmpl, "{{") {
+		c.warnf("the summary template is not converted, the default message of checks is used")
+		return defaultStatusMessageTemplate
+	}
+	return tmpl
+}
+
+// notificationRule returns the notification rule sending the statuses of
+// the check at level to the endpoint of cp, and the return to ok unless cp
+// disables resolve messages.
+func (c *converter) notificationRule(cp ContactPoint, level notification.CheckLevel, every *notification.Duration) influxdb.NotificationRule {
+	base := rule.Base{
+		Name:        c.rule.Title,
+		Description: c.rule.Annotations["description"],
+		Every:       every,
+		RunbookLink: c.rule.Annotations["runbook_url"],
+		TagRules: []notification.TagRule{{
+			Tag:      influxdb.Tag{Key: RuleTag, Value: c.ruleTagValue()},
+			Operator: influxdb.Equal,
+		}},
+		StatusRules: []notification.StatusRule{{CurrentLevel: level}},
+	}
+	if !cp.DisableResolveMessage {
+		base.StatusRules = append(base.StatusRules, notification.StatusRule{
+			CurrentLevel:  notification.Ok,
+			PreviousLevel: &level,
+		})
+	}
+
+	switch cp.Type {
+	case "slack":
+		return &rule.Slack{Base: base, Channel: setting(&cp, "recipient"), MessageTemplate: defaultNotificationMessageTemplate}
+	case "pagerduty":
+		return &rule.PagerDuty{Base: base, MessageTemplate: defaultNotificationMessageTemplate}
+	case "telegram":
+		return &rule.Telegram{Base: base, MessageTemplate: defaultNotificationMessageTemplate}
+	default:
+		return &rule.HTTP{Base: base}
+	}
+}
+
+// setEndpointID sets the endpoint of the notification rule nr, converted by
+// Convert, to id.
+func setEndpointID(nr influxdb.NotificationRule, id influxdb.ID) {
+	switch nr := nr.(type) {
+	case *rule.Slack:
+		nr.EndpointID = id
+	case *rule.PagerDuty:
+		nr.EndpointID = id
+	case *rule.Telegram:
+		nr.EndpointID = id
+	case *rule.HTTP:
+		nr.EndpointID = id
+	}
+}
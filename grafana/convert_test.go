@@ -0,0 +1,228 @@
+package grafana_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/grafana"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+)
+
+const cpuQuery = `from(bucket: "telegraf")
+  |> range(start: v.timeRangeStart, stop: v.timeRangeStop)
+  |> filter(fn: (r) => r._measurement == "cpu" and r._field == "usage_user")`
+
+func alertRule(t *testing.T, s string) grafana.AlertRule {
+	t.Helper()
+	var r grafana.AlertRule
+	if err := json.Unmarshal([]byte(s), &r); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name       string
+		rule       string
+		query      string
+		thresholds []check.ThresholdConfig
+		tags       []influxdb.Tag
+		message    string
+		warnings   int
+	}{
+		{
+			name: "threshold of a reduced query",
+			rule: `{
+				"uid": "cpu-high",
+				"title": "High CPU",
+				"condition": "C",
+				"data": [
+					{"refId": "A", "datasourceUid": "influx", "relativeTimeRange": {"from": 60, "to": 0}, "model": {"query": ` + jsonString(cpuQuery) + `}},
+					{"refId": "B", "datasourceUid": "__expr__", "model": {"type": "reduce", "expression": "A", "reducer": "mean"}},
+					{"refId": "C", "datasourceUid": "__expr__", "model": {"type": "threshold", "expression": "B", "conditions": [{"evaluator": {"type": "gt", "params": [80]}}]}}
+				],
+				"labels": {"team": "ops", "severity": "warning"},
+				"annotations": {"summary": "CPU of {{ $labels.host }} is high"}
+			}`,
+			query: cpuQuery + "\n  |> aggregateWindow(every: 1m0s, fn: mean)",
+			thresholds: []check.ThresholdConfig{
+				&check.Greater{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Warn}, Value: 80},
+			},
+			tags: []influxdb.Tag{
+				{Key: "team", Value: "ops"},
+				{Key: grafana.RuleTag, Value: "cpu-high"},
+			},
+			message: "CPU of ${ r.host } is high",
+		},
+		{
+			name: "classic condition",
+			rule: `{
+				"title": "CPU out of range",
+				"condition": "B",
+				"for": "5m",
+				"data": [
+					{"refId": "A", "datasourceUid": "influx", "relativeTimeRange": {"from": 600, "to": 0}, "model": {"query": ` + jsonString(cpuQuery) + `}},
+					{"refId": "B", "datasourceUid": "-100", "model": {"type": "classic_conditions", "conditions": [{"evaluator": {"type": "outside_range", "params": [90, 10]}, "query": {"params": ["A"]}, "reducer": {"type": "avg"}}]}}
+				],
+				"annotations": {"summary": "CPU is {{ $values.A }}"}
+			}`,
+			query: cpuQuery + "\n  |> aggregateWindow(every: 1m0s, fn: mean)",
+			thresholds: []check.ThresholdConfig{
+				&check.Range{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Critical}, Min: 10, Max: 90},
+			},
+			tags: []influxdb.Tag{
+				{Key: grafana.RuleTag, Value: "CPU out of range"},
+			},
+			message: "Check: ${ r._check_name } is: ${ r._level }",
+			// The pending period, time range and summary are not converted.
+			warnings: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := grafana.Convert(alertRule(t, tt.rule), time.Minute, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.query, c.Check.Query.Text); diff != "" {
+				t.Errorf("unexpected query (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.thresholds, c.Check.Thresholds); diff != "" {
+				t.Errorf("unexpected thresholds (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.tags, c.Check.Tags); diff != "" {
+				t.Errorf("unexpected tags (-want +got):\n%s", diff)
+			}
+			if c.Check.StatusMessageTemplate != tt.message {
+				t.Errorf("unexpected message template %q, want %q", c.Check.StatusMessageTemplate, tt.message)
+			}
+			if len(c.Warnings) != tt.warnings {
+				t.Errorf("expected %d warnings, got %q", tt.warnings, c.Warnings)
+			}
+			if c.Rule != nil || c.Endpoint != nil {
+				t.Errorf("expected no notification rule without a contact point")
+			}
+		})
+	}
+}
+
+func TestConvert_ContactPoint(t *testing.T) {
+	r := alertRule(t, `{
+		"uid": "cpu-high",
+		"title": "High CPU",
+		"condition": "B",
+		"data": [
+			{"refId": "A", "datasourceUid": "influx", "model": {"query": `+jsonString(cpuQuery)+`}},
+			{"refId": "B", "datasourceUid": "__expr__", "model": {"type": "threshold", "expression": "A", "conditions": [{"evaluator": {"type": "lt", "params": [5]}}]}}
+		],
+		"annotations": {"runbook_url": "https://runbooks.example.com/cpu"}
+	}`)
+	cp := &grafana.ContactPoint{
+		Name:     "ops",
+		Type:     "slack",
+		Settings: map[string]interface{}{"recipient": "#ops", "token": "xoxb-1"},
+	}
+
+	c, err := grafana.Convert(r, 5*time.Minute, cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, ok := c.Endpoint.(*endpoint.Slack)
+	if !ok {
+		t.Fatalf("expected a slack endpoint, got %T", c.Endpoint)
+	}
+	if e.Name != "ops" || e.URL != "https://slack.com/api/chat.postMessage" || e.Token.Value == nil || *e.Token.Value != "xoxb-1" {
+		t.Errorf("unexpected endpoint %+v", e)
+	}
+
+	nr, ok := c.Rule.(*rule.Slack)
+	if !ok {
+		t.Fatalf("expected a slack notification rule, got %T", c.Rule)
+	}
+	if nr.Channel != "#ops" || nr.RunbookLink != "https://runbooks.example.com/cpu" {
+		t.Errorf("unexpected notification rule %+v", nr)
+	}
+	crit := notification.Critical
+	wantStatusRules := []notification.StatusRule{
+		{CurrentLevel: notification.Critical},
+		{CurrentLevel: notification.Ok, PreviousLevel: &crit},
+	}
+	if diff := cmp.Diff(wantStatusRules, nr.StatusRules); diff != "" {
+		t.Errorf("unexpected status rules (-want +got):\n%s", diff)
+	}
+	wantTagRules := []notification.TagRule{{Tag: influxdb.Tag{Key: grafana.RuleTag, Value: "cpu-high"}, Operator: influxdb.Equal}}
+	if diff := cmp.Diff(wantTagRules, nr.TagRules); diff != "" {
+		t.Errorf("unexpected tag rules (-want +got):\n%s", diff)
+	}
+	if got := nr.Every.TimeDuration(); got != 5*time.Minute {
+		t.Errorf("unexpected every %s", got)
+	}
+}
+
+func TestConvert_Invalid(t *testing.T) {
+	query := `{"refId": "A", "datasourceUid": "influx", "model": {"query": ` + jsonString(cpuQuery) + `}}`
+	tests := []struct {
+		name string
+		rule string
+		cp   *grafana.ContactPoint
+	}{
+		{
+			name: "influxql query",
+			rule: `{"title": "t", "condition": "B", "data": [
+				{"refId": "A", "datasourceUid": "influx", "model": {"query": "SELECT mean(usage_user) FROM cpu", "rawQuery": true}},
+				{"refId": "B", "datasourceUid": "__expr__", "model": {"type": "threshold", "expression": "A", "conditions": [{"evaluator": {"type": "gt", "params": [80]}}]}}
+			]}`,
+		},
+		{
+			name: "math expression",
+			rule: `{"title": "t", "condition": "C", "data": [` + query + `,
+				{"refId": "B", "datasourceUid": "__expr__", "model": {"type": "math", "expression": "$A * 2"}},
+				{"refId": "C", "datasourceUid": "__expr__", "model": {"type": "threshold", "expression": "B", "conditions": [{"evaluator": {"type": "gt", "params": [80]}}]}}
+			]}`,
+		},
+		{
+			name: "unknown condition",
+			rule: `{"title": "t", "condition": "Z", "data": [` + query + `]}`,
+		},
+		{
+			name: "unsupported reducer",
+			rule: `{"title": "t", "condition": "B", "data": [` + query + `,
+				{"refId": "B", "datasourceUid": "__expr__", "model": {"type": "classic_conditions", "conditions": [{"evaluator": {"type": "gt", "params": [1]}, "query": {"params": ["A"]}, "reducer": {"type": "percent_diff"}}]}}
+			]}`,
+		},
+		{
+			name: "redacted contact point",
+			rule: `{"title": "t", "condition": "B", "data": [` + query + `,
+				{"refId": "B", "datasourceUid": "__expr__", "model": {"type": "threshold", "expression": "A", "conditions": [{"evaluator": {"type": "gt", "params": [80]}}]}}
+			]}`,
+			cp: &grafana.ContactPoint{Name: "pd", Type: "pagerduty", Settings: map[string]interface{}{"integrationKey": "[REDACTED]"}},
+		},
+		{
+			name: "unsupported contact point",
+			rule: `{"title": "t", "condition": "B", "data": [` + query + `,
+				{"refId": "B", "datasourceUid": "__expr__", "model": {"type": "threshold", "expression": "A", "conditions": [{"evaluator": {"type": "gt", "params": [80]}}]}}
+			]}`,
+			cp: &grafana.ContactPoint{Name: "mail", Type: "email", Settings: map[string]interface{}{"addresses": "ops@example.com"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := grafana.Convert(alertRule(t, tt.rule), time.Minute, tt.cp); influxdb.ErrorCode(err) != influxdb.EInvalid {
+				t.Fatalf("expected invalid error, got %v", err)
+			}
+		})
+	}
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,118 @@
+package grafana
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+)
+
+// slackPostMessageURL is the URL of the Slack endpoints of the contact points
+// posting with a token instead of a webhook.
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// redacted is the value of the secure settings of the contact points
+// exported without their secrets.
+const redacted = "[REDACTED]"
+
+// convertContactPoint converts cp into a notification endpoint named after
+// it.
+func convertContactPoint(cp ContactPoint) (influxdb.NotificationEndpoint, error) {
+	for k, v := range cp.Settings {
+		if v == redacted {
+			return nil, ErrInvalidContactPoint(cp.Name, fmt.Sprintf("setting %s is redacted, export the contact point with its secrets", k))
+		}
+	}
+
+	base := endpoint.Base{
+		Name:        cp.Name,
+		Description: "Imported from Grafana contact point " + cp.Name,
+		Status:      influxdb.Active,
+	}
+	switch cp.Type {
+	case "slack":
+		e := &endpoint.Slack{Base: base, URL: setting(&cp, "url")}
+		if token := setting(&cp, "token"); token != "" {
+			e.Token = influxdb.SecretField{Value: &token}
+			if e.URL == "" {
+				e.URL = slackPostMessageURL
+			}
+		}
+		if e.URL == "" {
+			return nil, ErrInvalidContactPoint(cp.Name, "url or token is required")
+		}
+		return e, nil
+	case "pagerduty":
+		key := setting(&cp, "integrationKey")
+		if key == "" {
+			return nil, ErrInvalidContactPoint(cp.Name, "integrationKey is required")
+		}
+		return &endpoint.PagerDuty{
+			Base:       base,
+			ClientURL:  setting(&cp, "client_url"),
+			RoutingKey: influxdb.SecretField{Value: &key},
+		}, nil
+	case "telegram":
+		token, chat := setting(&cp, "bottoken"), setting(&cp, "chatid")
+		if token == "" || chat == "" {
+			return nil, ErrInvalidContactPoint(cp.Name, "bottoken and chatid are required")
+		}
+		return &endpoint.Telegram{
+			Base:    base,
+			Token:   influxdb.SecretField{Value: &token},
+			Channel: chat,
+		}, nil
+	case "webhook":
+		return convertWebhook(cp, base)
+	default:
+		return nil, ErrUnsupported("contact points of type " + cp.Type)
+	}
+}
+
+func convertWebhook(cp ContactPoint, base endpoint.Base) (influxdb.NotificationEndpoint, error) {
+	e := &endpoint.HTTP{
+		Base:       base,
+		URL:        setting(&cp, "url"),
+		Method:     strings.ToUpper(setting(&cp, "httpMethod")),
+		AuthMethod: "none",
+	}
+	if e.URL == "" {
+		return nil, ErrInvalidContactPoint(cp.Name, "url is required")
+	}
+	switch e.Method {
+	case "":
+		e.Method = http.MethodPost
+	case http.MethodPost, http.MethodPut:
+	default:
+		return nil, ErrUnsupported("webhooks with method " + e.Method)
+	}
+
+	if user := setting(&cp, "username"); user != "" {
+		password := setting(&cp, "password")
+		e.AuthMethod = "basic"
+		e.Username = influxdb.SecretField{Value: &user}
+		e.Password = influxdb.SecretField{Value: &password}
+	}
+	if credentials := setting(&cp, "authorization_credentials"); credentials != "" {
+		if scheme := setting(&cp, "authorization_scheme"); scheme != "" && !strings.EqualFold(scheme, "Bearer") {
+			return nil, ErrUnsupported("webhooks with authorization scheme " + scheme)
+		}
+		e.AuthMethod = "bearer"
+		e.Token = influxdb.SecretField{Value: &credentials}
+	}
+	return e, nil
+}
+
+// setting returns the setting key of cp, formatted as a string.
+func setting(cp *ContactPoint, key string) string {
+	v, ok := cp.Settings[key]
+	if !ok || v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,50 @@
+package grafana
+
+import (
+	"github.com/influxdata/influxdb/v2"
+)
+
+// ErrInvalidRule is used when an alert rule is not a valid Grafana alert
+// rule.
+func ErrInvalidRule(msg string) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "alert rule is invalid: " + msg,
+	}
+}
+
+// ErrUnsupported is used when an alert rule or a contact point uses a
+// feature that has no equivalent in checks and notification rules.
+func ErrUnsupported(feature string) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  feature + " cannot be converted",
+	}
+}
+
+// ErrContactPointNotFound is used when the contact point an alert rule
+// notifies is not part of the import.
+func ErrContactPointNotFound(name string) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "contact point " + name + " is not part of the import",
+	}
+}
+
+// ErrEndpointConflict is used when a notification endpoint named after a
+// contact point exists with another type.
+func ErrEndpointConflict(name, typ string) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  "notification endpoint " + name + " exists with type " + typ,
+	}
+}
+
+// ErrInvalidContactPoint is used when a contact point is missing the
+// settings of its notification endpoint.
+func ErrInvalidContactPoint(name, msg string) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "contact point " + name + " is invalid: " + msg,
+	}
+}
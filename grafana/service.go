@@ -0,0 +1,209 @@
+package grafana
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+)
+
+// Import is a set of Grafana alert rules and the contact points they notify.
+type Import struct {
+	Groups        []RuleGroup    `json:"groups"`
+	ContactPoints []ContactPoint `json:"contactPoints"`
+	// DefaultContactPoint is the name of the contact point notified by the
+	// rules that do not select one, as the default notification policy of
+	// Grafana. When it is empty, those rules are only converted into checks.
+	DefaultContactPoint string `json:"defaultContactPoint"`
+}
+
+// ImportResult is the outcome of the import of one alert rule.
+type ImportResult struct {
+	Rule             string                        `json:"rule"`
+	UID              string                        `json:"uid,omitempty"`
+	Group            string                        `json:"group,omitempty"`
+	Flux             string                        `json:"flux,omitempty"`
+	Check            influxdb.Check                `json:"check,omitempty"`
+	NotificationRule influxdb.NotificationRule     `json:"notificationRule,omitempty"`
+	Endpoint         influxdb.NotificationEndpoint `json:"notificationEndpoint,omitempty"`
+	Warnings         []string                      `json:"warnings,omitempty"`
+	Error            string                        `json:"error,omitempty"`
+}
+
+// Service imports Grafana alert rules as checks and notification rules.
+type Service struct {
+	checkSvc    influxdb.CheckService
+	ruleSvc     influxdb.NotificationRuleStore
+	endpointSvc influxdb.NotificationEndpointService
+	lang        influxdb.FluxLanguageService
+}
+
+// NewService constructs a service creating the checks, notification rules
+// and endpoints with checkSvc, ruleSvc and endpointSvc. The Flux of the
+// checks is generated with lang.
+func NewService(checkSvc influxdb.CheckService, ruleSvc influxdb.NotificationRuleStore, endpointSvc influxdb.NotificationEndpointService, lang influxdb.FluxLanguageService) *Service {
+	return &Service{
+		checkSvc:    checkSvc,
+		ruleSvc:     ruleSvc,
+		endpointSvc: endpointSvc,
+		lang:        lang,
+	}
+}
+
+// Import converts the alert rules of imp into checks and notification rules
+// of the organization and creates them with status, unless dryRun is set.
+// The paused rules are created inactive. The contact points are created as
+// notification endpoints once, or the endpoints of the organization named
+// after them are used. A rule that cannot be converted or created does not
+// prevent the import of the others; its error is reported in its result.
+func (s *Service) Import(ctx context.Context, orgID influxdb.ID, imp *Import, status influxdb.Status, dryRun bool) ([]*ImportResult, error) {
+	auth, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	in := &importer{
+		Service:       s,
+		orgID:         orgID,
+		userID:        auth.GetUserID(),
+		status:        status,
+		dryRun:        dryRun,
+		contactPoints: make(map[string]*ContactPoint, len(imp.ContactPoints)),
+		endpoints:     make(map[string]influxdb.NotificationEndpoint),
+	}
+	for i := range imp.ContactPoints {
+		in.contactPoints[imp.ContactPoints[i].Name] = &imp.ContactPoints[i]
+	}
+
+	results := make([]*ImportResult, 0)
+	for _, g := range imp.Groups {
+		every, everyErr := groupInterval(g)
+		for _, r := range g.Rules {
+			res := &ImportResult{Rule: r.Title, UID: r.UID, Group: g.Name}
+			results = append(results, res)
+
+			err := everyErr
+			if err == nil {
+				err = in.importRule(ctx, r, every, imp.DefaultContactPoint, res)
+			}
+			if err != nil {
+				res.Error = err.Error()
+			}
+		}
+	}
+	return results, nil
+}
+
+func groupInterval(g RuleGroup) (time.Duration, error) {
+	if g.Interval == "" {
+		return DefaultInterval, nil
+	}
+	every, err := time.ParseDuration(g.Interval)
+	if err != nil || every <= 0 {
+		return 0, ErrInvalidRule("interval " + g.Interval + " of group " + g.Name + " is not a positive duration")
+	}
+	return every, nil
+}
+
+type importer struct {
+	*Service
+	orgID  influxdb.ID
+	userID influxdb.ID
+	status influxdb.Status
+	dryRun bool
+	// contactPoints are the contact points of the import by name, and
+	// endpoints the endpoints of the organization they were resolved to.
+	contactPoints map[string]*ContactPoint
+	endpoints     map[string]influxdb.NotificationEndpoint
+}
+
+func (in *importer) importRule(ctx context.Context, r AlertRule, every time.Duration, defaultContactPoint string, res *ImportResult) error {
+	receiver := defaultContactPoint
+	if r.NotificationSettings != nil && r.NotificationSettings.Receiver != "" {
+		receiver = r.NotificationSettings.Receiver
+	}
+	var cp *ContactPoint
+	if receiver != "" {
+		if cp = in.contactPoints[receiver]; cp == nil {
+			return ErrContactPointNotFound(receiver)
+		}
+	}
+
+	c, err := Convert(r, every, cp)
+	if err != nil {
+		return err
+	}
+	c.Check.OrgID = in.orgID
+	res.Check, res.Warnings = c.Check, c.Warnings
+	if c.Rule != nil {
+		c.Rule.SetOrgID(in.orgID)
+		res.NotificationRule, res.Endpoint = c.Rule, c.Endpoint
+	}
+	if res.Flux, err = c.Check.GenerateFlux(in.lang); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "the query of the alert rule is not supported by checks",
+			Err:  err,
+		}
+	}
+	if in.dryRun {
+		return nil
+	}
+
+	status := in.status
+	if c.Paused {
+		status = influxdb.Inactive
+	}
+	if err := in.checkSvc.CreateCheck(ctx, influxdb.CheckCreate{Check: c.Check, Status: status}, in.userID); err != nil {
+		return err
+	}
+	c.Check.ClearPrivateData()
+	if c.Rule == nil {
+		return nil
+	}
+
+	e, err := in.endpoint(ctx, c.Endpoint)
+	if err != nil {
+		return err
+	}
+	res.Endpoint = e
+	setEndpointID(c.Rule, e.GetID())
+	if err := in.ruleSvc.CreateNotificationRule(ctx, influxdb.NotificationRuleCreate{NotificationRule: c.Rule, Status: status}, in.userID); err != nil {
+		return err
+	}
+	c.Rule.ClearPrivateData()
+	return nil
+}
+
+// endpoint returns the endpoint of the organization named after the
+// converted endpoint e, creating e if there is none.
+func (in *importer) endpoint(ctx context.Context, e influxdb.NotificationEndpoint) (influxdb.NotificationEndpoint, error) {
+	existing, ok := in.endpoints[e.GetName()]
+	if !ok {
+		endpoints, _, err := in.endpointSvc.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{OrgID: &in.orgID})
+		if err != nil {
+			return nil, err
+		}
+		for _, ee := range endpoints {
+			if ee.GetName() == e.GetName() {
+				existing = ee
+				break
+			}
+		}
+	}
+	if existing != nil {
+		if existing.Type() != e.Type() {
+			return nil, ErrEndpointConflict(e.GetName(), existing.Type())
+		}
+		in.endpoints[e.GetName()] = existing
+		return existing, nil
+	}
+
+	e.SetOrgID(in.orgID)
+	if err := in.endpointSvc.CreateNotificationEndpoint(ctx, e, in.userID); err != nil {
+		return nil, err
+	}
+	in.endpoints[e.GetName()] = e
+	return e, nil
+}
@@ -0,0 +1,226 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// redacted replaces the values of redacted fields in the access log.
+	redacted = "[REDACTED]"
+
+	// maxLoggedQueryBytes is the maximum size of the body of a query logged
+	// in the access log.
+	maxLoggedQueryBytes = 64 * 1024
+)
+
+// credentialParams are the URL query parameters holding credentials, which
+// are always redacted from the access log.
+var credentialParams = []string{"token", "p", "password"}
+
+// queryTextParams are the URL query parameters holding query text, which are
+// redacted from the access log unless query text is logged.
+var queryTextParams = []string{"q", "query"}
+
+// AccessLogConfig configures the access log of the HTTP API.
+type AccessLogConfig struct {
+	// SampleRates are the fractions, between 0 and 1, of the requests to the
+	// paths starting with a prefix that are logged. The longest matching
+	// prefix applies, and the requests matching no prefix are all logged.
+	// Requests failing with a server error are logged regardless of their
+	// sample rate.
+	SampleRates map[string]float64
+
+	// RedactParams are the URL query parameters whose values are redacted,
+	// in addition to the credentials that always are.
+	RedactParams []string
+
+	// LogQueryText logs the text of queries. It is redacted by default, as
+	// queries may hold sensitive values.
+	LogQueryText bool
+}
+
+// AccessLogMW middleware writes a structured access log entry for the
+// requests sampled by conf.
+func AccessLogMW(log *zap.Logger, conf AccessLogConfig) kithttp.Middleware {
+	return accessLogMW(log, conf, rand.Float64)
+}
+
+func accessLogMW(log *zap.Logger, conf AccessLogConfig, random func() float64) kithttp.Middleware {
+	redact := make(map[string]bool)
+	for _, p := range credentialParams {
+		redact[p] = true
+	}
+	for _, p := range conf.RedactParams {
+		redact[p] = true
+	}
+	if !conf.LogQueryText {
+		for _, p := range queryTextParams {
+			redact[p] = true
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			srw := kithttp.NewStatusResponseWriter(w)
+
+			rate := sampleRate(conf.SampleRates, r.URL.Path)
+			sampled := rate >= 1 || random() < rate
+
+			var buf *limitedBuffer
+			if conf.LogQueryText && r.Method == http.MethodPost && r.URL.Path == prefixQuery && r.Body != nil {
+				buf = &limitedBuffer{limit: maxLoggedQueryBytes}
+				r.Body = &bodyEchoer{
+					rc:    r.Body,
+					teedR: io.TeeReader(r.Body, buf),
+				}
+			}
+
+			defer func(start time.Time) {
+				if !sampled && srw.Code() < http.StatusInternalServerError {
+					return
+				}
+
+				errField := zap.Skip()
+				if errStr := w.Header().Get(kithttp.PlatformErrorCodeHeader); errStr != "" {
+					errField = zap.Error(errors.New(errStr))
+				}
+
+				fields := []zap.Field{
+					zap.String("method", r.Method),
+					zap.String("host", r.Host),
+					zap.String("path", r.URL.Path),
+					zap.String("query", redactQuery(r.URL.Query(), redact)),
+					zap.String("proto", r.Proto),
+					zap.Int("status_code", srw.Code()),
+					zap.Int("response_size", srw.ResponseBytes()),
+					zap.Int64("content_length", r.ContentLength),
+					zap.String("referrer", r.Referer()),
+					zap.String("remote", r.RemoteAddr),
+					zap.String("user_agent", kithttp.UserAgent(r)),
+					zap.Duration("took", time.Since(start)),
+					zap.Float64("sample_rate", rate),
+					errField,
+				}
+				if buf != nil {
+					if text, ok := queryText(r.Header.Get("Content-Type"), buf); ok {
+						fields = append(fields, zap.String("query_text", text))
+					}
+				}
+
+				log.Info("Request", fields...)
+			}(time.Now())
+
+			next.ServeHTTP(srw, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// sampleRate returns the sample rate of the longest prefix of path in rates,
+// or 1 if none matches.
+func sampleRate(rates map[string]float64, path string) float64 {
+	rate, matched := 1.0, -1
+	for prefix, r := range rates {
+		if len(prefix) > matched && strings.HasPrefix(path, prefix) {
+			rate, matched = r, len(prefix)
+		}
+	}
+	return rate
+}
+
+// redactQuery encodes params with the values of the parameters in redact
+// replaced.
+func redactQuery(params url.Values, redact map[string]bool) string {
+	for k, vs := range params {
+		if !redact[k] {
+			continue
+		}
+		for i := range vs {
+			vs[i] = redacted
+		}
+	}
+	return params.Encode()
+}
+
+// queryText returns the text of the query in the body of a query request.
+// Bodies larger than the logged limit are not decoded.
+func queryText(contentType string, buf *limitedBuffer) (string, bool) {
+	if buf.truncated || buf.Len() == 0 {
+		return "", false
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mt = "application/json"
+	}
+	switch mt {
+	case "application/vnd.flux":
+		return buf.String(), true
+	case "application/json":
+		var req struct {
+			Query string `json:"query"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &req); err != nil {
+			return "", false
+		}
+		return req.Query, true
+	default:
+		return "", false
+	}
+}
+
+// limitedBuffer is a buffer dropping the bytes written past its limit.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if n := b.limit - b.Len(); len(p) > n {
+		b.truncated = true
+		b.Buffer.Write(p[:n])
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// NewAccessLogWriter opens the destination of an access log: stdout, stderr,
+// syslog or the path of a file, to which the log is appended.
+func NewAccessLogWriter(dest string) (io.WriteCloser, error) {
+	switch dest {
+	case "":
+		return nil, errors.New("access log destination is required")
+	case "stdout":
+		return nopWriteCloser{os.Stdout}, nil
+	case "stderr":
+		return nopWriteCloser{os.Stderr}, nil
+	case "syslog":
+		return newSyslogWriter()
+	default:
+		f, err := os.OpenFile(dest, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open access log: %v", err)
+		}
+		return f, nil
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAccessLogMW(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(ioutil.Discard, r.Body)
+		_, _ = w.Write([]byte("ack"))
+	})
+	fail := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	serve := func(conf AccessLogConfig, random float64, h http.Handler, req *http.Request) []observer.LoggedEntry {
+		t.Helper()
+		core, logs := observer.New(zapcore.InfoLevel)
+		accessLogMW(zap.New(core), conf, func() float64 { return random })(h).ServeHTTP(httptest.NewRecorder(), req)
+		return logs.AllUntimed()
+	}
+
+	t.Run("redacts credentials and query text", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v2/buckets?token=secret&q=select&orgID=1&name=b", nil)
+		entries := serve(AccessLogConfig{RedactParams: []string{"name"}}, 0, ok, req)
+		if len(entries) != 1 {
+			t.Fatalf("expected one entry, got %d", len(entries))
+		}
+		query := entries[0].ContextMap()["query"].(string)
+		if strings.Contains(query, "secret") || strings.Contains(query, "select") || strings.Contains(query, "name=b") {
+			t.Errorf("expected the token, query text and name redacted, got %q", query)
+		}
+		if !strings.Contains(query, "orgID=1") {
+			t.Errorf("expected the other parameters logged, got %q", query)
+		}
+	})
+
+	t.Run("logs query text when opted in", func(t *testing.T) {
+		req := httptest.NewRequest("POST", prefixQuery+"?q=select", strings.NewReader(`{"query":"from(bucket: \"b\")"}`))
+		req.Header.Set("Content-Type", "application/json")
+		entries := serve(AccessLogConfig{LogQueryText: true}, 0, ok, req)
+		if len(entries) != 1 {
+			t.Fatalf("expected one entry, got %d", len(entries))
+		}
+		fields := entries[0].ContextMap()
+		if got := fields["query_text"]; got != `from(bucket: "b")` {
+			t.Errorf("unexpected query text %q", got)
+		}
+		if got := fields["query"]; got != "q=select" {
+			t.Errorf("expected the query parameter logged, got %q", got)
+		}
+	})
+
+	t.Run("samples requests by route", func(t *testing.T) {
+		conf := AccessLogConfig{SampleRates: map[string]float64{"/": 0.5, "/api/v2/write": 0.01}}
+		if entries := serve(conf, 0.2, ok, httptest.NewRequest("POST", "/api/v2/write", nil)); len(entries) != 0 {
+			t.Errorf("expected the write not sampled, got %d entries", len(entries))
+		}
+		if entries := serve(conf, 0.2, ok, httptest.NewRequest("GET", "/api/v2/buckets", nil)); len(entries) != 1 {
+			t.Errorf("expected the request sampled, got %d entries", len(entries))
+		}
+		if entries := serve(conf, 0.9, fail, httptest.NewRequest("POST", "/api/v2/write", nil)); len(entries) != 1 {
+			t.Errorf("expected the server error logged, got %d entries", len(entries))
+		}
+	})
+}
@@ -0,0 +1,13 @@
+// +build !windows
+
+package http
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// newSyslogWriter returns a writer to the local syslog daemon.
+func newSyslogWriter() (io.WriteCloser, error) {
+	return syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, "influxd")
+}
@@ -0,0 +1,11 @@
+package http
+
+import (
+	"errors"
+	"io"
+)
+
+// newSyslogWriter returns an error, as syslog is not supported on Windows.
+func newSyslogWriter() (io.WriteCloser, error) {
+	return nil, errors.New("syslog access log is not supported on windows")
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/influxdata/influxdb/v2"
@@ -31,6 +32,24 @@ type APIBackend struct {
 	Logger     *zap.Logger
 	influxdb.HTTPErrorHandler
 	SessionRenewDisabled bool
+	// FeatureFlagRequestOverrides allows requests to override feature flags
+	// with their X-Influxdb-Feature-Flags header. It is meant for development
+	// environments.
+	FeatureFlagRequestOverrides bool
+	// CertificateAuthenticator authenticates the requests by the verified
+	// certificates of their clients. Client certificates are not accepted
+	// when it is nil.
+	CertificateAuthenticator CertificateAuthenticator
+	// FrozenOrgFinder finds the organizations whose freeze is enforced by
+	// the permission checks of the requests. Freezes are not enforced when
+	// it is nil.
+	FrozenOrgFinder authorizer.FrozenOrgFinder
+	// PolicyEngine decides the permission checks of the requests, which are
+	// decided by the permissions of their authorizers when it is nil.
+	PolicyEngine authorizer.PolicyEngine
+	// QuerySigner signs the queries that can be fetched without a token.
+	// Queries are not signed when it is nil.
+	QuerySigner *QuerySigner
 	// MaxBatchSizeBytes is the maximum number of bytes which can be written
 	// in a single points batch
 	MaxBatchSizeBytes int64
@@ -47,6 +66,43 @@ type APIBackend struct {
 	// write request. A value of zero specifies there is no limit.
 	WriteParserMaxValues int
 
+	// WriteTimeout is the maximum duration of the write of a points batch to
+	// storage. A value of zero specifies there is no limit.
+	WriteTimeout time.Duration
+
+	// MirrorWriteToken is the token the mirror subscription of the other
+	// instance writes with, the only one allowed to set the mirror time of
+	// its points. The mirror time is rejected when it is empty.
+	MirrorWriteToken string
+
+	// MaxRequestBodyBytes is the maximum size of the body of a request to the
+	// metadata endpoints of the API. A value of zero specifies there is no limit.
+	MaxRequestBodyBytes int64
+
+	// MaxWriteBodyBytes is the maximum size of the body of a write request,
+	// before any decompression. A value of zero specifies there is no limit.
+	MaxWriteBodyBytes int64
+
+	// MaxRequestBodyBytesOverrides are the maximum sizes of the bodies of the
+	// requests to the paths starting with their prefix, overriding the limit
+	// of their endpoint class. A value of zero specifies there is no limit.
+	MaxRequestBodyBytesOverrides map[string]int64
+
+	// CORSPolicies are the CORS policies of the groups of routes of the API.
+	// Any origin is allowed to request any route, without credentials, when
+	// nil.
+	CORSPolicies *kithttp.CORSPolicies
+
+	// APIDeprecations are the deprecated routes of the API, reported in the
+	// headers of their responses.
+	APIDeprecations []kithttp.Deprecation
+
+	// WriteBackpressure and QueryBackpressure report the load of the storage
+	// and of the queue of queries in the headers of the responses to writes
+	// and queries. They are not reported when nil.
+	WriteBackpressure kithttp.Backpressure
+	QueryBackpressure kithttp.Backpressure
+
 	NewBucketService func(*influxdb.Source) (influxdb.BucketService, error)
 	NewQueryService  func(*influxdb.Source) (query.ProxyQueryService, error)
 
@@ -62,6 +118,7 @@ type APIBackend struct {
 	AuthorizationService            influxdb.AuthorizationService
 	DBRPService                     influxdb.DBRPMappingServiceV2
 	BucketService                   influxdb.BucketService
+	SystemBuckets                   influxdb.SystemBuckets
 	SessionService                  influxdb.SessionService
 	UserService                     influxdb.UserService
 	OrganizationService             influxdb.OrganizationService
@@ -79,6 +136,7 @@ type APIBackend struct {
 	FluxService                     query.ProxyQueryService
 	FluxLanguageService             influxdb.FluxLanguageService
 	TaskService                     influxdb.TaskService
+	TaskSLAService                  influxdb.TaskSLAService
 	CheckService                    influxdb.CheckService
 	TelegrafService                 influxdb.TelegrafConfigStore
 	ScraperTargetStoreService       influxdb.ScraperTargetStoreService
@@ -89,6 +147,7 @@ type APIBackend struct {
 	DocumentService                 influxdb.DocumentService
 	NotificationRuleStore           influxdb.NotificationRuleStore
 	NotificationEndpointService     influxdb.NotificationEndpointService
+	WriteRoutingService             influxdb.WriteRoutingService
 	Flagger                         feature.Flagger
 	FlagsHandler                    http.Handler
 }
@@ -108,6 +167,56 @@ func (b *APIBackend) PrometheusCollectors() []prometheus.Collector {
 	return cs
 }
 
+// bodyLimits returns the limits of the bodies of the requests to the API.
+func (b *APIBackend) bodyLimits() kithttp.BodyLimits {
+	limits := kithttp.BodyLimits{
+		Default: b.MaxRequestBodyBytes,
+		Overrides: map[string]int64{
+			prefixWrite: b.MaxWriteBodyBytes,
+		},
+	}
+	for prefix, n := range b.MaxRequestBodyBytesOverrides {
+		limits.Overrides[prefix] = n
+	}
+	return limits
+}
+
+// corsPolicies returns the CORS policies of the routes of the API.
+func (b *APIBackend) corsPolicies() kithttp.CORSPolicies {
+	if b.CORSPolicies == nil {
+		return kithttp.DefaultCORSPolicies()
+	}
+	return *b.CORSPolicies
+}
+
+// writeHandlerOptions returns the options of the handlers of writes.
+func (b *APIBackend) writeHandlerOptions() []WriteHandlerOption {
+	return []WriteHandlerOption{
+		WithMaxBatchSizeBytes(b.MaxBatchSizeBytes),
+		WithWriteTimeout(b.WriteTimeout),
+		WithParserOptions(
+			models.WithParserMaxBytes(b.WriteParserMaxBytes),
+			models.WithParserMaxLines(b.WriteParserMaxLines),
+			models.WithParserMaxValues(b.WriteParserMaxValues),
+		),
+		WithBackpressure(b.WriteBackpressure),
+		WithMirrorToken(b.MirrorWriteToken),
+	}
+}
+
+// APIVersions are the versions of the shapes of the responses of the API,
+// oldest first. A breaking change to a response appends a version, and the
+// handler keeps the previous shape for the clients that do not accept it.
+var APIVersions = []string{"2.0"}
+
+// versioning returns the versioning of the API.
+func (b *APIBackend) versioning() kithttp.Versioning {
+	return kithttp.Versioning{
+		Versions:     APIVersions,
+		Deprecations: b.APIDeprecations,
+	}
+}
+
 // APIHandlerOptFn is a functional input param to set parameters on
 // the APIHandler.
 type APIHandlerOptFn func(chi.Router)
@@ -121,9 +230,12 @@ func WithResourceHandler(resHandler kithttp.ResourceHandler) APIHandlerOptFn {
 
 // NewAPIHandler constructs all api handlers beneath it and returns an APIHandler
 func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
+	api := kithttp.NewAPI(kithttp.WithLog(b.Logger))
 	h := &APIHandler{
-		Router: NewBaseChiRouter(kithttp.NewAPI(kithttp.WithLog(b.Logger))),
+		Router: NewBaseChiRouter(api),
 	}
+	h.Use(kithttp.LimitBody(api, b.bodyLimits()))
+	h.Use(kithttp.Version(api, b.versioning()))
 
 	b.UserResourceMappingService = authorizer.NewURMService(b.OrgLookupService, b.UserResourceMappingService)
 
@@ -138,6 +250,8 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 
 	dashboardBackend := NewDashboardBackend(b.Logger.With(zap.String("handler", "dashboard")), b)
 	dashboardBackend.DashboardService = authorizer.NewDashboardService(b.DashboardService)
+	dashboardBackend.CheckService = authorizer.NewCheckService(b.CheckService,
+		b.UserResourceMappingService, b.OrganizationService)
 	h.Mount(prefixDashboards, NewDashboardHandler(b.Logger, dashboardBackend))
 
 	deleteBackend := NewDeleteBackend(b.Logger.With(zap.String("handler", "delete")), b)
@@ -168,7 +282,7 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 
 	sourceBackend := NewSourceBackend(b.Logger.With(zap.String("handler", "source")), b)
 	sourceBackend.SourceService = authorizer.NewSourceService(b.SourceService)
-	sourceBackend.BucketService = authorizer.NewBucketService(b.BucketService)
+	sourceBackend.BucketService = authorizer.NewBucketService(b.BucketService, b.SystemBuckets)
 	h.Mount(prefixSources, NewSourceHandler(b.Logger, sourceBackend))
 
 	h.Mount("/api/v2/swagger.json", newSwaggerLoader(b.Logger.With(zap.String("service", "swagger-loader")), b.HTTPErrorHandler))
@@ -197,14 +311,7 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	h.Mount(dbrp.PrefixDBRP, dbrp.NewHTTPHandler(b.Logger, b.DBRPService, b.OrganizationService))
 
 	writeBackend := NewWriteBackend(b.Logger.With(zap.String("handler", "write")), b)
-	h.Mount(prefixWrite, NewWriteHandler(b.Logger, writeBackend,
-		WithMaxBatchSizeBytes(b.MaxBatchSizeBytes),
-		WithParserOptions(
-			models.WithParserMaxBytes(b.WriteParserMaxBytes),
-			models.WithParserMaxLines(b.WriteParserMaxLines),
-			models.WithParserMaxValues(b.WriteParserMaxValues),
-		),
-	))
+	h.Mount(prefixWrite, NewWriteHandler(b.Logger, writeBackend, b.writeHandlerOptions()...))
 
 	for _, o := range opts {
 		o(h)
@@ -218,29 +325,34 @@ var apiLinks = map[string]interface{}{
 	"authorizations": "/api/v2/authorizations",
 	"backup":         "/api/v2/backup",
 	"buckets":        "/api/v2/buckets",
+	"connections":    "/api/v2/connections",
 	"dashboards":     "/api/v2/dashboards",
 	"external": map[string]string{
 		"statusFeed": "https://www.influxdata.com/feed/json",
 	},
 	"flags":                 "/api/v2/flags",
+	"fluxpackages":          "/api/v2/fluxpackages",
 	"labels":                "/api/v2/labels",
+	"legalholds":            "/api/v2/legalholds",
 	"variables":             "/api/v2/variables",
 	"me":                    "/api/v2/me",
 	"notificationRules":     "/api/v2/notificationRules",
 	"notificationEndpoints": "/api/v2/notificationEndpoints",
 	"orgs":                  "/api/v2/orgs",
+	"savedqueries":          "/api/v2/savedqueries",
 	"query": map[string]string{
 		"self":        "/api/v2/query",
 		"ast":         "/api/v2/query/ast",
 		"analyze":     "/api/v2/query/analyze",
 		"suggestions": "/api/v2/query/suggestions",
 	},
-	"setup":    "/api/v2/setup",
-	"signin":   "/api/v2/signin",
-	"signout":  "/api/v2/signout",
-	"sources":  "/api/v2/sources",
-	"scrapers": "/api/v2/scrapers",
-	"swagger":  "/api/v2/swagger.json",
+	"setup":         "/api/v2/setup",
+	"signin":        "/api/v2/signin",
+	"signout":       "/api/v2/signout",
+	"sources":       "/api/v2/sources",
+	"scrapers":      "/api/v2/scrapers",
+	"subscriptions": "/api/v2/subscriptions",
+	"swagger":       "/api/v2/swagger.json",
 	"system": map[string]string{
 		"metrics": "/metrics",
 		"debug":   "/debug/pprof",
@@ -250,6 +362,8 @@ var apiLinks = map[string]interface{}{
 	"checks":    "/api/v2/checks",
 	"telegrafs": "/api/v2/telegrafs",
 	"plugins":   "/api/v2/telegraf/plugins",
+	"transfers": "/api/v2/transfers",
+	"trash":     "/api/v2/trash",
 	"users":     "/api/v2/users",
 	"write":     "/api/v2/write",
 	"delete":    "/api/v2/delete",
@@ -85,6 +85,7 @@ type authResponse struct {
 	UserID      influxdb.ID          `json:"userID"`
 	User        string               `json:"user"`
 	Permissions []permissionResponse `json:"permissions"`
+	RowFilters  []influxdb.TagRule   `json:"rowFilters,omitempty"`
 	Links       map[string]string    `json:"links"`
 	CreatedAt   time.Time            `json:"createdAt"`
 	UpdatedAt   time.Time            `json:"updatedAt"`
@@ -101,6 +102,7 @@ func newAuthResponse(a *influxdb.Authorization, org *influxdb.Organization, user
 		User:        user.Name,
 		Org:         org.Name,
 		Permissions: ps,
+		RowFilters:  a.RowFilters,
 		Links: map[string]string{
 			"self": fmt.Sprintf("/api/v2/authorizations/%s", a.ID),
 			"user": fmt.Sprintf("/api/v2/users/%s", a.UserID),
@@ -119,6 +121,7 @@ func (a *authResponse) toPlatform() *influxdb.Authorization {
 		Description: a.Description,
 		OrgID:       a.OrgID,
 		UserID:      a.UserID,
+		RowFilters:  a.RowFilters,
 		CRUDLog: influxdb.CRUDLog{
 			CreatedAt: a.CreatedAt,
 			UpdatedAt: a.UpdatedAt,
@@ -245,6 +248,7 @@ type postAuthorizationRequest struct {
 	UserID      *influxdb.ID          `json:"userID,omitempty"`
 	Description string                `json:"description"`
 	Permissions []influxdb.Permission `json:"permissions"`
+	RowFilters  []influxdb.TagRule    `json:"rowFilters,omitempty"`
 }
 
 func (p *postAuthorizationRequest) toPlatform(userID influxdb.ID) *influxdb.Authorization {
@@ -253,6 +257,7 @@ func (p *postAuthorizationRequest) toPlatform(userID influxdb.ID) *influxdb.Auth
 		Status:      p.Status,
 		Description: p.Description,
 		Permissions: p.Permissions,
+		RowFilters:  p.RowFilters,
 		UserID:      userID,
 	}
 }
@@ -262,6 +267,7 @@ func newPostAuthorizationRequest(a *influxdb.Authorization) (*postAuthorizationR
 		OrgID:       a.OrgID,
 		Description: a.Description,
 		Permissions: a.Permissions,
+		RowFilters:  a.RowFilters,
 		Status:      a.Status,
 	}
 
@@ -296,6 +302,10 @@ func (p *postAuthorizationRequest) Validate() error {
 		}
 	}
 
+	if err := influxdb.ValidRowFilters(p.RowFilters); err != nil {
+		return err
+	}
+
 	if !p.OrgID.Valid() {
 		return &influxdb.Error{
 			Err:  influxdb.ErrInvalidID,
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/influxdata/httprouter"
 	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
 	platcontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/jsonweb"
 	"github.com/opentracing/opentracing-go"
@@ -26,6 +28,20 @@ type AuthenticationHandler struct {
 	TokenParser          *jsonweb.TokenParser
 	SessionRenewDisabled bool
 
+	// CertificateAuthenticator authenticates the requests without a token
+	// or session by the verified certificates of their clients. Client
+	// certificates are not accepted when it is nil.
+	CertificateAuthenticator CertificateAuthenticator
+
+	// FrozenOrgFinder finds the organizations whose freeze is enforced by the
+	// permission checks of the requests. Freezes are not enforced when it is
+	// nil.
+	FrozenOrgFinder authorizer.FrozenOrgFinder
+
+	// PolicyEngine decides the permission checks of the requests, which are
+	// decided by the permissions of their authorizers when it is nil.
+	PolicyEngine authorizer.PolicyEngine
+
 	// This is only really used for it's lookup method the specific http
 	// handler used to register routes does not matter.
 	noAuthRouter *httprouter.Router
@@ -33,6 +49,11 @@ type AuthenticationHandler struct {
 	Handler http.Handler
 }
 
+// CertificateAuthenticator authenticates clients by their certificates.
+type CertificateAuthenticator interface {
+	AuthenticateCertificate(ctx context.Context, cert *x509.Certificate) (*platform.Session, error)
+}
+
 // NewAuthenticationHandler creates an authentication handler.
 func NewAuthenticationHandler(log *zap.Logger, h platform.HTTPErrorHandler) *AuthenticationHandler {
 	return &AuthenticationHandler{
@@ -51,8 +72,9 @@ func (h *AuthenticationHandler) RegisterNoAuthRoute(method, path string) {
 }
 
 const (
-	tokenAuthScheme   = "token"
-	sessionAuthScheme = "session"
+	tokenAuthScheme       = "token"
+	sessionAuthScheme     = "session"
+	certificateAuthScheme = "certificate"
 )
 
 // ProbeAuthScheme probes the http request for the requests for token or cookie session.
@@ -78,6 +100,11 @@ func (h *AuthenticationHandler) unauthorized(ctx context.Context, w http.Respons
 
 // ServeHTTP extracts the session or token from the http request and places the resulting authorizer on the request context.
 func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The requests authorized otherwise, such as signed queries, also
+	// delegate their permission checks to the policy engine.
+	if h.PolicyEngine != nil {
+		r = r.WithContext(authorizer.WithPolicyEngine(r.Context(), h.PolicyEngine))
+	}
 	if handler, _, _ := h.noAuthRouter.Lookup(r.Method, r.URL.Path); handler != nil {
 		h.Handler.ServeHTTP(w, r)
 		return
@@ -85,6 +112,9 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 
 	ctx := r.Context()
 	scheme, err := ProbeAuthScheme(r)
+	if err != nil && h.CertificateAuthenticator != nil && clientCertificate(r) != nil {
+		scheme, err = certificateAuthScheme, nil
+	}
 	if err != nil {
 		h.unauthorized(ctx, w, err)
 		return
@@ -96,6 +126,8 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 		auth, err = h.extractAuthorization(ctx, r)
 	case sessionAuthScheme:
 		auth, err = h.extractSession(ctx, r)
+	case certificateAuthScheme:
+		auth, err = h.CertificateAuthenticator.AuthenticateCertificate(ctx, clientCertificate(r))
 	default:
 		// TODO: this error will be nil if it gets here, this should be remedied with some
 		//  sentinel error I'm thinking
@@ -117,6 +149,9 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	}
 
 	ctx = platcontext.SetAuthorizer(ctx, auth)
+	if h.FrozenOrgFinder != nil {
+		ctx = authorizer.WithFrozenOrgFinder(ctx, h.FrozenOrgFinder)
+	}
 
 	if span := opentracing.SpanFromContext(ctx); span != nil {
 		span.SetTag("user_id", auth.GetUserID().String())
@@ -160,6 +195,14 @@ func (h *AuthenticationHandler) extractAuthorization(ctx context.Context, r *htt
 	return h.AuthorizationService.FindAuthorizationByToken(ctx, t)
 }
 
+// clientCertificate returns the verified certificate of the client of r, if any.
+func clientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return r.TLS.VerifiedChains[0][0]
+}
+
 func (h *AuthenticationHandler) extractSession(ctx context.Context, r *http.Request) (*platform.Session, error) {
 	k, err := decodeCookieSession(ctx, r)
 	if err != nil {
@@ -2,6 +2,9 @@ package http_test
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"errors"
 	"fmt"
 	"net/http"
@@ -248,6 +251,102 @@ func TestAuthenticationHandler(t *testing.T) {
 	}
 }
 
+type certificateAuthenticator func(ctx context.Context, cert *x509.Certificate) (*influxdb.Session, error)
+
+func (fn certificateAuthenticator) AuthenticateCertificate(ctx context.Context, cert *x509.Certificate) (*influxdb.Session, error) {
+	return fn(ctx, cert)
+}
+
+func TestAuthenticationHandler_Certificate(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "agent.example.com"}}
+
+	tests := []struct {
+		name          string
+		authenticator platformhttp.CertificateAuthenticator
+		verified      bool
+		token         string
+		code          int
+	}{
+		{
+			name:     "certificates not accepted",
+			verified: true,
+			code:     http.StatusUnauthorized,
+		},
+		{
+			name: "certificate mapped to a user",
+			authenticator: certificateAuthenticator(func(ctx context.Context, c *x509.Certificate) (*influxdb.Session, error) {
+				if c != cert {
+					t.Errorf("unexpected certificate %v", c.Subject)
+				}
+				return &influxdb.Session{UserID: one, ExpiresAt: time.Now().Add(time.Hour)}, nil
+			}),
+			verified: true,
+			code:     http.StatusOK,
+		},
+		{
+			name: "certificate not mapped to a user",
+			authenticator: certificateAuthenticator(func(ctx context.Context, c *x509.Certificate) (*influxdb.Session, error) {
+				return nil, &influxdb.Error{Code: influxdb.EUnauthorized, Msg: "client certificate is not mapped to a user"}
+			}),
+			verified: true,
+			code:     http.StatusUnauthorized,
+		},
+		{
+			name: "no verified certificate",
+			authenticator: certificateAuthenticator(func(ctx context.Context, c *x509.Certificate) (*influxdb.Session, error) {
+				t.Error("unexpected certificate authentication")
+				return nil, nil
+			}),
+			code: http.StatusUnauthorized,
+		},
+		{
+			name: "token takes precedence",
+			authenticator: certificateAuthenticator(func(ctx context.Context, c *x509.Certificate) (*influxdb.Session, error) {
+				t.Error("unexpected certificate authentication")
+				return nil, nil
+			}),
+			verified: true,
+			token:    "abc123",
+			code:     http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := platformhttp.NewAuthenticationHandler(zaptest.NewLogger(t), kithttp.ErrorHandler(0))
+			h.AuthorizationService = &mock.AuthorizationService{
+				FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*influxdb.Authorization, error) {
+					return &influxdb.Authorization{}, nil
+				},
+			}
+			h.UserService = &mock.UserService{
+				FindUserByIDFn: func(ctx context.Context, id influxdb.ID) (*influxdb.User, error) {
+					return &influxdb.User{}, nil
+				},
+			}
+			h.CertificateAuthenticator = tt.authenticator
+			h.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "https://any.url", nil)
+			if tt.verified {
+				r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+			}
+			if tt.token != "" {
+				platformhttp.SetToken(tt.token, r)
+			}
+
+			h.ServeHTTP(w, r)
+
+			if got, want := w.Code, tt.code; got != want {
+				t.Errorf("expected status code to be %d got %d", want, got)
+			}
+		})
+	}
+}
+
 func TestProbeAuthScheme(t *testing.T) {
 	type args struct {
 		token   string
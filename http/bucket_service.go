@@ -130,13 +130,18 @@ func NewBucketHandler(log *zap.Logger, b *BucketBackend) *BucketHandler {
 
 // bucket is used for serialization/deserialization with duration string syntax.
 type bucket struct {
-	ID                  influxdb.ID     `json:"id,omitempty"`
-	OrgID               influxdb.ID     `json:"orgID,omitempty"`
-	Type                string          `json:"type"`
-	Description         string          `json:"description,omitempty"`
-	Name                string          `json:"name"`
-	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
-	RetentionRules      []retentionRule `json:"retentionRules"`
+	ID                  influxdb.ID             `json:"id,omitempty"`
+	OrgID               influxdb.ID             `json:"orgID,omitempty"`
+	Type                string                  `json:"type"`
+	Description         string                  `json:"description,omitempty"`
+	Name                string                  `json:"name"`
+	RetentionPolicyName string                  `json:"rp,omitempty"` // This to support v1 sources
+	RetentionRules      []retentionRule         `json:"retentionRules"`
+	WriteWindow         *writeWindow            `json:"writeWindow,omitempty"`
+	DedupWindowSeconds  int64                   `json:"dedupWindowSeconds,omitempty"`
+	WriteSharding       *influxdb.WriteSharding `json:"writeSharding,omitempty"`
+	State               influxdb.BucketState    `json:"state,omitempty"`
+	Metadata            map[string]string       `json:"metadata,omitempty"`
 	influxdb.CRUDLog
 }
 
@@ -158,6 +163,42 @@ func (rr *retentionRule) RetentionPeriod() (time.Duration, error) {
 	return t, nil
 }
 
+// writeWindow is the range of timestamps, around the time of a write, of the
+// points that can be written to a bucket. A zero bound accepts any point.
+type writeWindow struct {
+	PastSeconds   int64 `json:"pastSeconds,omitempty"`
+	FutureSeconds int64 `json:"futureSeconds,omitempty"`
+}
+
+func newWriteWindow(past, future time.Duration) *writeWindow {
+	if past <= 0 && future <= 0 {
+		return nil
+	}
+	return &writeWindow{
+		PastSeconds:   int64(past.Round(time.Second) / time.Second),
+		FutureSeconds: int64(future.Round(time.Second) / time.Second),
+	}
+}
+
+func (ww *writeWindow) OK() error {
+	if ww != nil && (ww.PastSeconds < 0 || ww.FutureSeconds < 0) {
+		return &influxdb.Error{
+			Code: influxdb.EUnprocessableEntity,
+			Msg:  "write window seconds must not be negative",
+		}
+	}
+	return nil
+}
+
+// Windows returns the past and future windows, which are zero for a nil
+// write window.
+func (ww *writeWindow) Windows() (past, future time.Duration) {
+	if ww == nil {
+		return 0, 0
+	}
+	return time.Duration(ww.PastSeconds) * time.Second, time.Duration(ww.FutureSeconds) * time.Second
+}
+
 func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 	if b == nil {
 		return nil, nil
@@ -176,6 +217,14 @@ func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 		}
 	}
 
+	if err := b.WriteWindow.OK(); err != nil {
+		return nil, err
+	}
+	if err := influxdb.ValidBucketOptions(time.Duration(b.DedupWindowSeconds)*time.Second, b.WriteSharding, b.Metadata); err != nil {
+		return nil, err
+	}
+	past, future := b.WriteWindow.Windows()
+
 	return &influxdb.Bucket{
 		ID:                  b.ID,
 		OrgID:               b.OrgID,
@@ -184,6 +233,12 @@ func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 		Name:                b.Name,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     d,
+		WritePastWindow:     past,
+		WriteFutureWindow:   future,
+		DedupWindow:         time.Duration(b.DedupWindowSeconds) * time.Second,
+		WriteSharding:       b.WriteSharding.IfEnabled(),
+		State:               b.State,
+		Metadata:            b.Metadata,
 		CRUDLog:             b.CRUDLog,
 	}, nil
 }
@@ -210,6 +265,11 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 		Description:         pb.Description,
 		RetentionPolicyName: pb.RetentionPolicyName,
 		RetentionRules:      rules,
+		WriteWindow:         newWriteWindow(pb.WritePastWindow, pb.WriteFutureWindow),
+		DedupWindowSeconds:  int64(pb.DedupWindow.Round(time.Second) / time.Second),
+		WriteSharding:       pb.WriteSharding,
+		State:               pb.State,
+		Metadata:            pb.Metadata,
 		CRUDLog:             pb.CRUDLog,
 	}
 }
@@ -219,6 +279,38 @@ type bucketUpdate struct {
 	Name           *string         `json:"name,omitempty"`
 	Description    *string         `json:"description,omitempty"`
 	RetentionRules []retentionRule `json:"retentionRules,omitempty"`
+	// WriteWindow replaces the bounds of the write window of the bucket
+	// that are set.
+	WriteWindow *writeWindowUpdate `json:"writeWindow,omitempty"`
+	// DedupWindowSeconds replaces the dedup window of the bucket when set.
+	DedupWindowSeconds *int64 `json:"dedupWindowSeconds,omitempty"`
+	// WriteSharding replaces the write sharding of the bucket when set.
+	WriteSharding *influxdb.WriteSharding `json:"writeSharding,omitempty"`
+	// State archives the bucket, or makes it active again, when set.
+	State *influxdb.BucketState `json:"state,omitempty"`
+	// Metadata replaces the metadata of the bucket when set; an empty
+	// object removes it.
+	Metadata map[string]string `json:"metadata"`
+}
+
+// writeWindowUpdate updates the bounds of a write window that are set; a
+// zero bound removes it.
+type writeWindowUpdate struct {
+	PastSeconds   *int64 `json:"pastSeconds,omitempty"`
+	FutureSeconds *int64 `json:"futureSeconds,omitempty"`
+}
+
+func (ww *writeWindowUpdate) OK() error {
+	if ww == nil {
+		return nil
+	}
+	if (ww.PastSeconds != nil && *ww.PastSeconds < 0) || (ww.FutureSeconds != nil && *ww.FutureSeconds < 0) {
+		return &influxdb.Error{
+			Code: influxdb.EUnprocessableEntity,
+			Msg:  "write window seconds must not be negative",
+		}
+	}
+	return nil
 }
 
 func (b *bucketUpdate) OK() error {
@@ -228,7 +320,22 @@ func (b *bucketUpdate) OK() error {
 			return err
 		}
 	}
-	return nil
+	var dedup time.Duration
+	if b.DedupWindowSeconds != nil {
+		dedup = time.Duration(*b.DedupWindowSeconds) * time.Second
+	}
+	if err := influxdb.ValidBucketOptions(dedup, b.WriteSharding, b.Metadata); err != nil {
+		return err
+	}
+	if b.State != nil {
+		if err := b.State.Valid(); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EUnprocessableEntity,
+				Msg:  err.Error(),
+			}
+		}
+	}
+	return b.WriteWindow.OK()
 }
 
 func (b *bucketUpdate) toInfluxDB() *influxdb.BucketUpdate {
@@ -242,11 +349,29 @@ func (b *bucketUpdate) toInfluxDB() *influxdb.BucketUpdate {
 		d, _ = b.RetentionRules[0].RetentionPeriod()
 	}
 
-	return &influxdb.BucketUpdate{
+	upd := &influxdb.BucketUpdate{
 		Name:            b.Name,
 		Description:     b.Description,
 		RetentionPeriod: &d,
 	}
+	if ww := b.WriteWindow; ww != nil {
+		if ww.PastSeconds != nil {
+			past := time.Duration(*ww.PastSeconds) * time.Second
+			upd.WritePastWindow = &past
+		}
+		if ww.FutureSeconds != nil {
+			future := time.Duration(*ww.FutureSeconds) * time.Second
+			upd.WriteFutureWindow = &future
+		}
+	}
+	if b.DedupWindowSeconds != nil {
+		dedup := time.Duration(*b.DedupWindowSeconds) * time.Second
+		upd.DedupWindow = &dedup
+	}
+	upd.WriteSharding = b.WriteSharding
+	upd.State = b.State
+	upd.Metadata = b.Metadata
+	return upd
 }
 
 func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
@@ -267,6 +392,25 @@ func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
 			EverySeconds: d,
 		})
 	}
+
+	if pb.WritePastWindow != nil || pb.WriteFutureWindow != nil {
+		up.WriteWindow = &writeWindowUpdate{}
+		if pb.WritePastWindow != nil {
+			past := int64(pb.WritePastWindow.Round(time.Second) / time.Second)
+			up.WriteWindow.PastSeconds = &past
+		}
+		if pb.WriteFutureWindow != nil {
+			future := int64(pb.WriteFutureWindow.Round(time.Second) / time.Second)
+			up.WriteWindow.FutureSeconds = &future
+		}
+	}
+	if pb.DedupWindow != nil {
+		seconds := int64(pb.DedupWindow.Round(time.Second) / time.Second)
+		up.DedupWindowSeconds = &seconds
+	}
+	up.WriteSharding = pb.WriteSharding
+	up.State = pb.State
+	up.Metadata = pb.Metadata
 	return up
 }
 
@@ -334,11 +478,15 @@ func (h *BucketHandler) handlePostBucket(w http.ResponseWriter, r *http.Request)
 }
 
 type postBucketRequest struct {
-	OrgID               influxdb.ID     `json:"orgID,omitempty"`
-	Name                string          `json:"name"`
-	Description         string          `json:"description"`
-	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
-	RetentionRules      []retentionRule `json:"retentionRules"`
+	OrgID               influxdb.ID             `json:"orgID,omitempty"`
+	Name                string                  `json:"name"`
+	Description         string                  `json:"description"`
+	RetentionPolicyName string                  `json:"rp,omitempty"` // This to support v1 sources
+	RetentionRules      []retentionRule         `json:"retentionRules"`
+	WriteWindow         *writeWindow            `json:"writeWindow,omitempty"`
+	DedupWindowSeconds  int64                   `json:"dedupWindowSeconds,omitempty"`
+	WriteSharding       *influxdb.WriteSharding `json:"writeSharding,omitempty"`
+	Metadata            map[string]string       `json:"metadata,omitempty"`
 }
 
 func (b *postBucketRequest) OK() error {
@@ -359,6 +507,13 @@ func (b *postBucketRequest) OK() error {
 		}
 	}
 
+	if err := b.WriteWindow.OK(); err != nil {
+		return err
+	}
+	if err := influxdb.ValidBucketOptions(time.Duration(b.DedupWindowSeconds)*time.Second, b.WriteSharding, b.Metadata); err != nil {
+		return err
+	}
+
 	// names starting with an underscore are reserved for system buckets
 	if err := validBucketName(b.toInfluxDB()); err != nil {
 		return err
@@ -373,6 +528,7 @@ func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
 	if len(b.RetentionRules) > 0 {
 		dur, _ = b.RetentionRules[0].RetentionPeriod()
 	}
+	past, future := b.WriteWindow.Windows()
 
 	return &influxdb.Bucket{
 		OrgID:               b.OrgID,
@@ -381,6 +537,11 @@ func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
 		Type:                influxdb.BucketTypeUser,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     dur,
+		WritePastWindow:     past,
+		WriteFutureWindow:   future,
+		DedupWindow:         time.Duration(b.DedupWindowSeconds) * time.Second,
+		WriteSharding:       b.WriteSharding.IfEnabled(),
+		Metadata:            b.Metadata,
 	}
 }
 
@@ -510,6 +671,12 @@ func decodeGetBucketsRequest(r *http.Request) (*getBucketsRequest, error) {
 		req.filter.Name = &name
 	}
 
+	metadata, err := influxdb.ParseBucketMetadataFilter(qp["metadata"])
+	if err != nil {
+		return nil, err
+	}
+	req.filter.Metadata = metadata
+
 	if bucketID := qp.Get("id"); bucketID != "" {
 		id, err := influxdb.IDFromString(bucketID)
 		if err != nil {
@@ -671,6 +838,9 @@ func (s *BucketService) FindBuckets(ctx context.Context, filter influxdb.BucketF
 	if filter.Name != nil {
 		params = append(params, [2]string{"name", (*filter.Name)})
 	}
+	for _, m := range filter.QueryParams()["metadata"] {
+		params = append(params, [2]string{"metadata", m})
+	}
 
 	var bs bucketsResponse
 	err := s.Client.
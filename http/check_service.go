@@ -201,6 +201,16 @@ type checksResponse struct {
 	Links  *influxdb.PagingLinks `json:"links"`
 }
 
+func newCheckLinks(id influxdb.ID) checkLinks {
+	return checkLinks{
+		Self:    fmt.Sprintf("/api/v2/checks/%s", id),
+		Labels:  fmt.Sprintf("/api/v2/checks/%s/labels", id),
+		Members: fmt.Sprintf("/api/v2/checks/%s/members", id),
+		Owners:  fmt.Sprintf("/api/v2/checks/%s/owners", id),
+		Query:   fmt.Sprintf("/api/v2/checks/%s/query", id),
+	}
+}
+
 func (h *CheckHandler) newCheckResponse(ctx context.Context, chk influxdb.Check, labels []*influxdb.Label) (*checkResponse, error) {
 	// TODO(desa): this should be handled in the check and not exposed in http land, but is currently blocking the FE. https://github.com/influxdata/influxdb/issues/15259
 	task, err := h.TaskService.FindTaskByID(ctx, chk.GetTaskID())
@@ -212,14 +222,8 @@ func (h *CheckHandler) newCheckResponse(ctx context.Context, chk influxdb.Check,
 	chk.ClearPrivateData()
 
 	res := &checkResponse{
-		Check: chk,
-		Links: checkLinks{
-			Self:    fmt.Sprintf("/api/v2/checks/%s", chk.GetID()),
-			Labels:  fmt.Sprintf("/api/v2/checks/%s/labels", chk.GetID()),
-			Members: fmt.Sprintf("/api/v2/checks/%s/members", chk.GetID()),
-			Owners:  fmt.Sprintf("/api/v2/checks/%s/owners", chk.GetID()),
-			Query:   fmt.Sprintf("/api/v2/checks/%s/query", chk.GetID()),
-		},
+		Check:           chk,
+		Links:           newCheckLinks(chk.GetID()),
 		Labels:          []influxdb.Label{},
 		LatestCompleted: task.LatestCompleted,
 		LatestScheduled: task.LatestScheduled,
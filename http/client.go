@@ -28,6 +28,9 @@ func NewHTTPClient(addr, token string, insecureSkipVerify bool, opts ...httpc.Cl
 		httpc.WithHTTPClient(NewClient(u.Scheme, insecureSkipVerify)),
 		httpc.WithInsecureSkipVerify(insecureSkipVerify),
 		httpc.WithStatusFn(CheckError),
+		httpc.WithRetryPolicy(httpc.DefaultRetryPolicy),
+		httpc.WithCircuitBreaker(httpc.DefaultCircuitBreakerPolicy),
+		httpc.WithTimeout(httpc.DefaultTimeout),
 	}
 	if token != "" {
 		defaultOpts = append(defaultOpts, httpc.WithAuthToken(token))
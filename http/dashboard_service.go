@@ -6,10 +6,18 @@ import (
 	"fmt"
 	"net/http"
 	"path"
+	"time"
 
+	"github.com/influxdata/flux/iocounter"
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	"github.com/influxdata/influxdb/v2/celltransform"
+	pctx "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
 	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"github.com/influxdata/influxdb/v2/query"
 	"go.uber.org/zap"
 )
 
@@ -25,6 +33,9 @@ type DashboardBackend struct {
 	LabelService                 influxdb.LabelService
 	UserService                  influxdb.UserService
 	OrganizationService          influxdb.OrganizationService
+	CheckService                 influxdb.CheckService
+	FluxService                  query.ProxyQueryService
+	FluxLanguageService          influxdb.FluxLanguageService
 }
 
 // NewDashboardBackend creates a backend used by the dashboard handler.
@@ -39,6 +50,9 @@ func NewDashboardBackend(log *zap.Logger, b *APIBackend) *DashboardBackend {
 		LabelService:                 b.LabelService,
 		UserService:                  b.UserService,
 		OrganizationService:          b.OrganizationService,
+		CheckService:                 b.CheckService,
+		FluxService:                  b.FluxService,
+		FluxLanguageService:          b.FluxLanguageService,
 	}
 }
 
@@ -55,6 +69,9 @@ type DashboardHandler struct {
 	LabelService                 influxdb.LabelService
 	UserService                  influxdb.UserService
 	OrganizationService          influxdb.OrganizationService
+	CheckService                 influxdb.CheckService
+	FluxService                  query.ProxyQueryService
+	FluxLanguageService          influxdb.FluxLanguageService
 }
 
 const (
@@ -63,6 +80,8 @@ const (
 	dashboardsIDCellsPath       = "/api/v2/dashboards/:id/cells"
 	dashboardsIDCellsIDPath     = "/api/v2/dashboards/:id/cells/:cellID"
 	dashboardsIDCellsIDViewPath = "/api/v2/dashboards/:id/cells/:cellID/view"
+	dashboardsIDCellsIDCheck    = "/api/v2/dashboards/:id/cells/:cellID/check"
+	dashboardsIDCellsIDQuery    = "/api/v2/dashboards/:id/cells/:cellID/query"
 	dashboardsIDMembersPath     = "/api/v2/dashboards/:id/members"
 	dashboardsIDMembersIDPath   = "/api/v2/dashboards/:id/members/:userID"
 	dashboardsIDOwnersPath      = "/api/v2/dashboards/:id/owners"
@@ -84,6 +103,9 @@ func NewDashboardHandler(log *zap.Logger, b *DashboardBackend) *DashboardHandler
 		LabelService:                 b.LabelService,
 		UserService:                  b.UserService,
 		OrganizationService:          b.OrganizationService,
+		CheckService:                 b.CheckService,
+		FluxService:                  b.FluxService,
+		FluxLanguageService:          b.FluxLanguageService,
 	}
 
 	h.HandlerFunc("POST", prefixDashboards, h.handlePostDashboard)
@@ -99,30 +121,40 @@ func NewDashboardHandler(log *zap.Logger, b *DashboardBackend) *DashboardHandler
 
 	h.HandlerFunc("GET", dashboardsIDCellsIDViewPath, h.handleGetDashboardCellView)
 	h.HandlerFunc("PATCH", dashboardsIDCellsIDViewPath, h.handlePatchDashboardCellView)
+	h.HandlerFunc("POST", dashboardsIDCellsIDCheck, h.handlePostDashboardCellCheck)
+	h.HandlerFunc("POST", dashboardsIDCellsIDQuery, h.handlePostDashboardCellQuery)
+
+	urmService := NewRestrictedURMService(b.UserResourceMappingService, func(ctx context.Context, id influxdb.ID) (bool, error) {
+		d, err := b.DashboardService.FindDashboardByID(ctx, id)
+		if err != nil || d == nil {
+			return false, err
+		}
+		return d.Restricted, nil
+	})
 
 	memberBackend := MemberBackend{
 		HTTPErrorHandler:           b.HTTPErrorHandler,
 		log:                        b.log.With(zap.String("handler", "member")),
 		ResourceType:               influxdb.DashboardsResourceType,
 		UserType:                   influxdb.Member,
-		UserResourceMappingService: b.UserResourceMappingService,
+		UserResourceMappingService: urmService,
 		UserService:                b.UserService,
 	}
-	h.HandlerFunc("POST", dashboardsIDMembersPath, newPostMemberHandler(memberBackend))
-	h.HandlerFunc("GET", dashboardsIDMembersPath, newGetMembersHandler(memberBackend))
-	h.HandlerFunc("DELETE", dashboardsIDMembersIDPath, newDeleteMemberHandler(memberBackend))
+	h.HandlerFunc("POST", dashboardsIDMembersPath, h.authorizeRestricted(newPostMemberHandler(memberBackend)))
+	h.HandlerFunc("GET", dashboardsIDMembersPath, h.authorizeRestricted(newGetMembersHandler(memberBackend)))
+	h.HandlerFunc("DELETE", dashboardsIDMembersIDPath, h.authorizeRestricted(newDeleteMemberHandler(memberBackend)))
 
 	ownerBackend := MemberBackend{
 		HTTPErrorHandler:           b.HTTPErrorHandler,
 		log:                        b.log.With(zap.String("handler", "member")),
 		ResourceType:               influxdb.DashboardsResourceType,
 		UserType:                   influxdb.Owner,
-		UserResourceMappingService: b.UserResourceMappingService,
+		UserResourceMappingService: urmService,
 		UserService:                b.UserService,
 	}
-	h.HandlerFunc("POST", dashboardsIDOwnersPath, newPostMemberHandler(ownerBackend))
-	h.HandlerFunc("GET", dashboardsIDOwnersPath, newGetMembersHandler(ownerBackend))
-	h.HandlerFunc("DELETE", dashboardsIDOwnersIDPath, newDeleteMemberHandler(ownerBackend))
+	h.HandlerFunc("POST", dashboardsIDOwnersPath, h.authorizeRestricted(newPostMemberHandler(ownerBackend)))
+	h.HandlerFunc("GET", dashboardsIDOwnersPath, h.authorizeRestricted(newGetMembersHandler(ownerBackend)))
+	h.HandlerFunc("DELETE", dashboardsIDOwnersIDPath, h.authorizeRestricted(newDeleteMemberHandler(ownerBackend)))
 
 	labelBackend := &LabelBackend{
 		HTTPErrorHandler: b.HTTPErrorHandler,
@@ -130,13 +162,40 @@ func NewDashboardHandler(log *zap.Logger, b *DashboardBackend) *DashboardHandler
 		LabelService:     b.LabelService,
 		ResourceType:     influxdb.DashboardsResourceType,
 	}
-	h.HandlerFunc("GET", dashboardsIDLabelsPath, newGetLabelsHandler(labelBackend))
-	h.HandlerFunc("POST", dashboardsIDLabelsPath, newPostLabelHandler(labelBackend))
-	h.HandlerFunc("DELETE", dashboardsIDLabelsIDPath, newDeleteLabelHandler(labelBackend))
+	h.HandlerFunc("GET", dashboardsIDLabelsPath, h.authorizeRestricted(newGetLabelsHandler(labelBackend)))
+	h.HandlerFunc("POST", dashboardsIDLabelsPath, h.authorizeRestricted(newPostLabelHandler(labelBackend)))
+	h.HandlerFunc("DELETE", dashboardsIDLabelsIDPath, h.authorizeRestricted(newDeleteLabelHandler(labelBackend)))
 
 	return h
 }
 
+// authorizeRestricted wraps a handler of the members, owners or labels of a
+// dashboard, which are authorized against its organization by the services
+// they are handled with. Those of a restricted dashboard are only visible to
+// the users who can read it, and only changed by the users who can edit it.
+func (h *DashboardHandler) authorizeRestricted(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		req, err := decodeGetDashboardRequest(ctx, r)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		d, err := h.DashboardService.FindDashboardByID(ctx, req.DashboardID)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		if d != nil && d.Restricted && r.Method != http.MethodGet {
+			if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.DashboardsResourceType, d.ID, d.OrganizationID); err != nil {
+				h.HandleHTTPError(ctx, err, w)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
 type dashboardLinks struct {
 	Self         string `json:"self"`
 	Members      string `json:"members"`
@@ -153,6 +212,7 @@ type dashboardResponse struct {
 	Description    string                  `json:"description"`
 	Meta           influxdb.DashboardMeta  `json:"meta"`
 	Cells          []dashboardCellResponse `json:"cells"`
+	Restricted     bool                    `json:"restricted,omitempty"`
 	Labels         []influxdb.Label        `json:"labels"`
 	Links          dashboardLinks          `json:"links"`
 }
@@ -172,6 +232,7 @@ func (d dashboardResponse) toinfluxdb() *influxdb.Dashboard {
 		Description:    d.Description,
 		Meta:           d.Meta,
 		Cells:          cells,
+		Restricted:     d.Restricted,
 	}
 }
 
@@ -190,6 +251,7 @@ func newDashboardResponse(d *influxdb.Dashboard, labels []*influxdb.Label) dashb
 		Name:           d.Name,
 		Description:    d.Description,
 		Meta:           d.Meta,
+		Restricted:     d.Restricted,
 		Labels:         []influxdb.Label{},
 		Cells:          []dashboardCellResponse{},
 	}
@@ -656,8 +718,9 @@ func (r *patchDashboardRequest) Valid() error {
 type postDashboardCellRequest struct {
 	dashboardID influxdb.ID
 	*influxdb.CellProperty
-	UsingView *influxdb.ID `json:"usingView"`
-	Name      *string      `json:"name"`
+	UsingView  *influxdb.ID             `json:"usingView"`
+	Name       *string                  `json:"name"`
+	Transforms []influxdb.CellTransform `json:"transforms"`
 }
 
 func decodePostDashboardCellRequest(ctx context.Context, r *http.Request) (*postDashboardCellRequest, error) {
@@ -710,7 +773,7 @@ func (h *DashboardHandler) handlePostDashboardCell(w http.ResponseWriter, r *htt
 		if req.Name != nil {
 			opts.View.Name = *req.Name
 		}
-	} else if req.CellProperty == nil {
+	} else if req.CellProperty == nil && req.Transforms == nil {
 		h.HandleHTTPError(ctx, &influxdb.Error{
 			Code: influxdb.EInvalid,
 			Msg:  "req body is empty",
@@ -721,6 +784,7 @@ func (h *DashboardHandler) handlePostDashboardCell(w http.ResponseWriter, r *htt
 	if req.CellProperty != nil {
 		cell.CellProperty = *req.CellProperty
 	}
+	cell.Transforms = req.Transforms
 
 	if err := h.DashboardService.AddDashboardCell(ctx, req.dashboardID, cell, *opts); err != nil {
 		h.HandleHTTPError(ctx, err, w)
@@ -869,6 +933,264 @@ func (h *DashboardHandler) handleGetDashboardCellView(w http.ResponseWriter, r *
 	}
 }
 
+// defaultCellCheckMessageTemplate is the status message of the checks created from dashboard cells.
+const defaultCellCheckMessageTemplate = "Check: ${ r._check_name } is: ${ r._level }"
+
+type postDashboardCellCheckRequest struct {
+	dashboardID influxdb.ID
+	cellID      influxdb.ID
+
+	Name                  string                 `json:"name"`
+	Description           string                 `json:"description"`
+	Every                 *notification.Duration `json:"every"`
+	Offset                *notification.Duration `json:"offset"`
+	StatusMessageTemplate string                 `json:"statusMessageTemplate"`
+	Status                influxdb.Status        `json:"status"`
+}
+
+func decodePostDashboardCellCheckRequest(ctx context.Context, r *http.Request) (*postDashboardCellCheckRequest, error) {
+	ids, err := decodeGetDashboardCellViewRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	req := &postDashboardCellCheckRequest{
+		dashboardID: ids.dashboardID,
+		cellID:      ids.cellID,
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid check from cell request",
+				Err:  err,
+			}
+		}
+	}
+
+	if req.Every == nil {
+		every, err := notification.FromTimeDuration(time.Minute)
+		if err != nil {
+			return nil, err
+		}
+		req.Every = &every
+	}
+	if req.StatusMessageTemplate == "" {
+		req.StatusMessageTemplate = defaultCellCheckMessageTemplate
+	}
+	if req.Status == "" {
+		req.Status = influxdb.Active
+	}
+	return req, nil
+}
+
+// handlePostDashboardCellCheck creates a threshold check seeded with the
+// query and ranges of a dashboard cell.
+func (h *DashboardHandler) handlePostDashboardCellCheck(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodePostDashboardCellCheckRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	auth, err := pctx.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	dashboard, err := h.DashboardService.FindDashboardByID(ctx, req.dashboardID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	view, err := h.DashboardService.GetDashboardCellView(ctx, req.dashboardID, req.cellID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	chk, err := check.NewThresholdFromView(view)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	chk.Name = req.Name
+	if chk.Name == "" {
+		chk.Name = view.Name
+	}
+	if chk.Name == "" {
+		chk.Name = dashboard.Name + " Check"
+	}
+	chk.Description = req.Description
+	chk.OrgID = dashboard.OrganizationID
+	chk.Every = req.Every
+	chk.Offset = req.Offset
+	chk.StatusMessageTemplate = req.StatusMessageTemplate
+
+	cc := influxdb.CheckCreate{Check: chk, Status: req.Status}
+	if err := h.CheckService.CreateCheck(ctx, cc, auth.GetUserID()); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Check created from dashboard cell", zap.String("dashboardID", req.dashboardID.String()), zap.String("cellID", req.cellID.String()), zap.String("checkID", chk.ID.String()))
+
+	// Ensure that we don't expose that this creates a task behind the scene
+	chk.ClearPrivateData()
+	res := &checkResponse{
+		Check:  chk,
+		Status: string(req.Status),
+		Labels: []influxdb.Label{},
+		Links:  newCheckLinks(chk.ID),
+	}
+	if err := encodeResponse(ctx, w, http.StatusCreated, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+type postDashboardCellQueryRequest struct {
+	dashboardID influxdb.ID
+	cellID      influxdb.ID
+
+	// QueryIndex is the index of the query of the view of the cell.
+	QueryIndex int                    `json:"queryIndex"`
+	Extern     json.RawMessage        `json:"extern,omitempty"`
+	Now        time.Time              `json:"now"`
+	Params     map[string]interface{} `json:"params,omitempty"`
+	Dialect    QueryDialect           `json:"dialect"`
+}
+
+func decodePostDashboardCellQueryRequest(ctx context.Context, r *http.Request) (*postDashboardCellQueryRequest, error) {
+	ids, err := decodeGetDashboardCellViewRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	req := &postDashboardCellQueryRequest{
+		dashboardID: ids.dashboardID,
+		cellID:      ids.cellID,
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid cell query request",
+				Err:  err,
+			}
+		}
+	}
+
+	if req.QueryIndex < 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "queryIndex must not be negative",
+		}
+	}
+	return req, nil
+}
+
+// handlePostDashboardCellQuery runs a query of the view of a dashboard cell
+// with the transforms of the cell applied to its results.
+func (h *DashboardHandler) handlePostDashboardCellQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodePostDashboardCellQueryRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	auth, err := pctx.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	dashboard, err := h.DashboardService.FindDashboardByID(ctx, req.dashboardID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	var cell *influxdb.Cell
+	for _, c := range dashboard.Cells {
+		if c.ID == req.cellID {
+			cell = c
+			break
+		}
+	}
+	if cell == nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrCellNotFound,
+		}, w)
+		return
+	}
+	view, err := h.DashboardService.GetDashboardCellView(ctx, req.dashboardID, req.cellID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	queries := celltransform.ViewQueries(view.Properties)
+	if req.QueryIndex >= len(queries) {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("cell has no query at index %d", req.QueryIndex),
+		}, w)
+		return
+	}
+	script, err := celltransform.Apply(h.FluxLanguageService, queries[req.QueryIndex].Text, cell.Transforms)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	qr := QueryRequest{
+		Type:    "flux",
+		Query:   script,
+		Extern:  req.Extern,
+		Now:     req.Now,
+		Params:  req.Params,
+		Dialect: req.Dialect,
+		Org:     &influxdb.Organization{ID: dashboard.OrganizationID},
+	}.WithDefaults()
+	pr, err := qr.ProxyRequest()
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid cell query request",
+			Err:  err,
+		}, w)
+		return
+	}
+	token, err := queryAuthorization(auth, dashboard.OrganizationID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	pr.Request.Authorization = token
+	pr.Request.Source = r.Header.Get("User-Agent")
+	ctx = pctx.SetAuthorizer(ctx, token)
+
+	hd, ok := pr.Dialect.(HTTPDialect)
+	if !ok {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unsupported dialect over HTTP: %T", pr.Dialect),
+		}, w)
+		return
+	}
+	hd.SetHeaders(w)
+
+	cw := iocounter.Writer{Writer: w}
+	if _, err := h.FluxService.Query(ctx, &cw, pr); err != nil {
+		if cw.Count() == 0 {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		h.log.Info("Error writing cell query response to client", zap.String("dashboardID", req.dashboardID.String()), zap.String("cellID", req.cellID.String()), zap.Error(err))
+	}
+}
+
 type patchDashboardCellViewRequest struct {
 	dashboardID influxdb.ID
 	cellID      influxdb.ID
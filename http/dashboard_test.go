@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -12,11 +13,19 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/httprouter"
 	platform "github.com/influxdata/influxdb/v2"
+	pcontext "github.com/influxdata/influxdb/v2/context"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/kv"
 	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	querymock "github.com/influxdata/influxdb/v2/query/mock"
 	platformtesting "github.com/influxdata/influxdb/v2/testing"
 	"github.com/yudai/gojsondiff"
 	"github.com/yudai/gojsondiff/formatter"
@@ -1730,6 +1739,216 @@ func TestService_handlePatchDashboardCell(t *testing.T) {
 	}
 }
 
+func TestService_handlePostDashboardCellCheck(t *testing.T) {
+	query := platform.DashboardQuery{
+		Text: `from(bucket: "telegraf") |> range(start: v.timeRangeStart) |> filter(fn: (r) => r._field == "usage_user")`,
+	}
+	views := map[string]platform.ViewProperties{
+		"020f755c3c082000": platform.SingleStatViewProperties{
+			Type:    platform.ViewPropertyTypeSingleStat,
+			Queries: []platform.DashboardQuery{query},
+			ViewColors: []platform.ViewColor{
+				{ID: "base", Type: "text", Value: 0},
+				{ID: "1", Type: "text", Value: 80},
+			},
+		},
+		"020f755c3c082001": platform.XYViewProperties{
+			Type:    platform.ViewPropertyTypeXY,
+			Queries: []platform.DashboardQuery{query},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		cellID     string
+		body       string
+		statusCode int
+		check      func(t *testing.T, cc platform.CheckCreate)
+	}{
+		{
+			name:       "seeds the check from the cell",
+			cellID:     "020f755c3c082000",
+			body:       `{"name": "cpu high", "every": "5m"}`,
+			statusCode: http.StatusCreated,
+			check: func(t *testing.T, cc platform.CheckCreate) {
+				chk := cc.Check.(*check.Threshold)
+				if chk.Name != "cpu high" || chk.OrgID != platformtesting.MustIDBase16("020f755c3c082003") || chk.Every.TimeDuration() != 5*time.Minute {
+					t.Errorf("unexpected check %+v", chk.Base)
+				}
+				if chk.Query.Text != query.Text || cc.Status != platform.Active {
+					t.Errorf("unexpected check query %q or status %q", chk.Query.Text, cc.Status)
+				}
+				want := []check.ThresholdConfig{
+					&check.Greater{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Critical}, Value: 80},
+				}
+				if diff := cmp.Diff(want, chk.Thresholds); diff != "" {
+					t.Errorf("unexpected thresholds, -want/+got:\n%s", diff)
+				}
+			},
+		},
+		{
+			name:       "cell without ranges",
+			cellID:     "020f755c3c082001",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created *platform.CheckCreate
+			checkService := mock.NewCheckService()
+			checkService.CreateCheckFn = func(ctx context.Context, cc platform.CheckCreate, userID platform.ID) error {
+				cc.SetID(platformtesting.MustIDBase16("020f755c3c082004"))
+				created = &cc
+				return nil
+			}
+
+			dashboardBackend := NewMockDashboardBackend(t)
+			dashboardBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			dashboardBackend.CheckService = checkService
+			dashboardBackend.DashboardService = &mock.DashboardService{
+				FindDashboardByIDF: func(ctx context.Context, id platform.ID) (*platform.Dashboard, error) {
+					return &platform.Dashboard{
+						ID:             id,
+						OrganizationID: platformtesting.MustIDBase16("020f755c3c082003"),
+						Name:           "hosts",
+					}, nil
+				},
+				GetDashboardCellViewF: func(ctx context.Context, dashboardID, cellID platform.ID) (*platform.View, error) {
+					return &platform.View{Properties: views[cellID.String()]}, nil
+				},
+			}
+			h := NewDashboardHandler(zaptest.NewLogger(t), dashboardBackend)
+
+			r := httptest.NewRequest("POST", "http://any.url", bytes.NewBufferString(tt.body))
+			r = r.WithContext(context.WithValue(
+				pcontext.SetAuthorizer(context.Background(), &platform.Session{UserID: platformtesting.MustIDBase16("020f755c3c082005")}),
+				httprouter.ParamsKey,
+				httprouter.Params{
+					{Key: "id", Value: "020f755c3c082002"},
+					{Key: "cellID", Value: tt.cellID},
+				}))
+			w := httptest.NewRecorder()
+
+			h.handlePostDashboardCellCheck(w, r)
+
+			if w.Code != tt.statusCode {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.statusCode, w.Body.String())
+			}
+			if tt.check == nil {
+				return
+			}
+			if created == nil {
+				t.Fatal("expected a check to be created")
+			}
+			tt.check(t, *created)
+		})
+	}
+}
+
+func TestService_handlePostDashboardCellQuery(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("020f755c3c082003")
+	cellID := platformtesting.MustIDBase16("020f755c3c082000")
+	dashboard := &platform.Dashboard{
+		ID:             platformtesting.MustIDBase16("020f755c3c082002"),
+		OrganizationID: orgID,
+		Cells: []*platform.Cell{
+			{
+				ID: cellID,
+				Transforms: []platform.CellTransform{
+					{Type: platform.CellTransformTopN, N: 5},
+				},
+			},
+		},
+	}
+	view := &platform.View{
+		Properties: platform.XYViewProperties{
+			Type:    platform.ViewPropertyTypeXY,
+			Queries: []platform.DashboardQuery{{Text: `from(bucket: "telegraf") |> range(start: -1h)`}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		cellID     string
+		body       string
+		statusCode int
+		query      string
+	}{
+		{
+			name:       "runs the transformed query",
+			cellID:     cellID.String(),
+			statusCode: http.StatusOK,
+			query:      "from(bucket: \"telegraf\")\n\t|> range(start: -1h)\n\t|> top(n: 5, columns: [\"_value\"])",
+		},
+		{
+			name:       "query index out of range",
+			cellID:     cellID.String(),
+			body:       `{"queryIndex": 1}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "cell not found",
+			cellID:     "020f755c3c082001",
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *query.ProxyRequest
+			dashboardBackend := NewMockDashboardBackend(t)
+			dashboardBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			dashboardBackend.FluxLanguageService = fluxlang.DefaultService
+			dashboardBackend.FluxService = &querymock.ProxyQueryService{
+				QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+					got = req
+					_, err := io.WriteString(w, "#result\n")
+					return flux.Statistics{}, err
+				},
+			}
+			dashboardBackend.DashboardService = &mock.DashboardService{
+				FindDashboardByIDF: func(ctx context.Context, id platform.ID) (*platform.Dashboard, error) {
+					return dashboard, nil
+				},
+				GetDashboardCellViewF: func(ctx context.Context, dashboardID, cellID platform.ID) (*platform.View, error) {
+					return view, nil
+				},
+			}
+			h := NewDashboardHandler(zaptest.NewLogger(t), dashboardBackend)
+
+			auth := &platform.Authorization{ID: platformtesting.MustIDBase16("020f755c3c082006"), OrgID: orgID}
+			r := httptest.NewRequest("POST", "http://any.url", bytes.NewBufferString(tt.body))
+			r = r.WithContext(context.WithValue(
+				pcontext.SetAuthorizer(context.Background(), auth),
+				httprouter.ParamsKey,
+				httprouter.Params{
+					{Key: "id", Value: dashboard.ID.String()},
+					{Key: "cellID", Value: tt.cellID},
+				}))
+			w := httptest.NewRecorder()
+
+			h.handlePostDashboardCellQuery(w, r)
+
+			if w.Code != tt.statusCode {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.statusCode, w.Body.String())
+			}
+			if tt.query == "" {
+				return
+			}
+			if got == nil {
+				t.Fatal("expected the query to run")
+			}
+			if c, ok := got.Request.Compiler.(lang.FluxCompiler); !ok || c.Query != tt.query {
+				t.Errorf("unexpected compiler %#v", got.Request.Compiler)
+			}
+			if got.Request.OrganizationID != orgID || got.Request.Authorization != auth {
+				t.Errorf("unexpected organization %s or authorization %v", got.Request.OrganizationID, got.Request.Authorization)
+			}
+		})
+	}
+}
+
 func Test_dashboardCellIDPath(t *testing.T) {
 	t.Parallel()
 	dashboard, err := platform.IDFromString("deadbeefdeadbeef")
@@ -1874,6 +2093,66 @@ func TestService_handlePostDashboardLabel(t *testing.T) {
 	}
 }
 
+func TestService_handlePostDashboardLabel_Restricted(t *testing.T) {
+	orgID := platform.ID(1)
+	dashboardID := platform.ID(100)
+
+	tests := []struct {
+		name        string
+		permissions []platform.Permission
+		statusCode  int
+	}{
+		{
+			name:        "org member cannot label a restricted dashboard",
+			permissions: platform.MemberPermissions(orgID),
+			statusCode:  http.StatusUnauthorized,
+		},
+		{
+			name:        "org owner can label a restricted dashboard",
+			permissions: platform.OwnerPermissions(orgID),
+			statusCode:  http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dashboardBackend := NewMockDashboardBackend(t)
+			dashboardBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			dashboardBackend.DashboardService = &mock.DashboardService{
+				FindDashboardByIDF: func(ctx context.Context, id platform.ID) (*platform.Dashboard, error) {
+					return &platform.Dashboard{ID: id, OrganizationID: orgID, Restricted: true}, nil
+				},
+			}
+			dashboardBackend.LabelService = &mock.LabelService{
+				FindLabelByIDFn: func(ctx context.Context, id platform.ID) (*platform.Label, error) {
+					return &platform.Label{ID: id, Name: "label"}, nil
+				},
+				CreateLabelMappingFn: func(ctx context.Context, m *platform.LabelMapping) error { return nil },
+			}
+			h := NewDashboardHandler(zaptest.NewLogger(t), dashboardBackend)
+
+			b, err := json.Marshal(&platform.LabelMapping{ResourceID: dashboardID, LabelID: 1})
+			if err != nil {
+				t.Fatalf("failed to marshal label mapping: %v", err)
+			}
+
+			url := fmt.Sprintf("http://localhost:9999/api/v2/dashboards/%s/labels", dashboardID)
+			r := httptest.NewRequest("POST", url, bytes.NewReader(b))
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{
+				Status:      platform.Active,
+				Permissions: tt.permissions,
+			}))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if res := w.Result(); res.StatusCode != tt.statusCode {
+				t.Errorf("got %v, want %v", res.StatusCode, tt.statusCode)
+			}
+		})
+	}
+}
+
 func jsonEqual(s1, s2 string) (eq bool, diff string, err error) {
 	if s1 == s2 {
 		return true, "", nil
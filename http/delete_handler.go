@@ -10,6 +10,7 @@ import (
 
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
 	pcontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/predicate"
@@ -105,7 +106,11 @@ func (h *DeleteHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if pset, err := a.PermissionSet(); err != nil || !pset.Allowed(*p) {
+	if err := authorizer.IsAllowedFor(ctx, a, *p); err != nil {
+		if influxdb.ErrorCode(err) == influxdb.EUnavailable {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
 		h.HandleHTTPError(ctx, &influxdb.Error{
 			Code: influxdb.EForbidden,
 			Op:   "http/handleDelete",
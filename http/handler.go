@@ -32,6 +32,7 @@ type Handler struct {
 
 	requests   *prometheus.CounterVec
 	requestDur *prometheus.HistogramVec
+	recoverer  *kithttp.Recoverer
 
 	// log logs all HTTP requests as they are served
 	log *zap.Logger
@@ -45,6 +46,7 @@ type (
 		healthHandler  http.Handler
 		metricsHandler http.Handler
 		readyHandler   http.Handler
+		panicReporters []kithttp.PanicReporter
 	}
 
 	HandlerOptFn func(opts *handlerOpts)
@@ -86,6 +88,13 @@ func WithReadyHandler(h http.Handler) HandlerOptFn {
 	}
 }
 
+// WithPanicReporter reports the panics of the handlers to r.
+func WithPanicReporter(r kithttp.PanicReporter) HandlerOptFn {
+	return func(opts *handlerOpts) {
+		opts.panicReporters = append(opts.panicReporters, r)
+	}
+}
+
 // NewHandlerFromRegistry creates a new handler with the given name,
 // and sets the /metrics endpoint to use the metrics from the given registry,
 // after self-registering h's metrics.
@@ -102,12 +111,15 @@ func NewHandlerFromRegistry(name string, reg *prom.Registry, opts ...HandlerOptF
 	}
 
 	h := &Handler{
-		name: name,
-		log:  opt.log,
+		name:      name,
+		log:       opt.log,
+		recoverer: kithttp.NewRecoverer(opt.log, opt.panicReporters...),
 	}
 	h.initMetrics()
 
 	r := chi.NewRouter()
+	// panics are answered with an error instead of closing the connection
+	r.Use(h.recoverer.Middleware)
 	// only gather metrics for system handlers
 	r.Group(func(r chi.Router) {
 		r.Use(
@@ -145,10 +157,10 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // PrometheusCollectors satisifies prom.PrometheusCollector.
 func (h *Handler) PrometheusCollectors() []prometheus.Collector {
-	return []prometheus.Collector{
+	return append([]prometheus.Collector{
 		h.requests,
 		h.requestDur,
-	}
+	}, h.recoverer.PrometheusCollectors()...)
 }
 
 func (h *Handler) initMetrics() {
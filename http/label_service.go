@@ -26,6 +26,9 @@ type LabelHandler struct {
 const (
 	prefixLabels = "/api/v2/labels"
 	labelsIDPath = "/api/v2/labels/:id"
+	// labelsIDActionPath matches the custom methods of a label such as
+	// mappings:batch, which the router cannot match literally.
+	labelsIDActionPath = "/api/v2/labels/:id/:action"
 )
 
 // NewLabelHandler returns a new instance of LabelHandler
@@ -44,6 +47,8 @@ func NewLabelHandler(log *zap.Logger, s influxdb.LabelService, he influxdb.HTTPE
 	h.HandlerFunc("PATCH", labelsIDPath, h.handlePatchLabel)
 	h.HandlerFunc("DELETE", labelsIDPath, h.handleDeleteLabel)
 
+	h.HandlerFunc("POST", labelsIDActionPath, h.handlePostLabelAction)
+
 	return h
 }
 
@@ -216,6 +221,57 @@ func (h *LabelHandler) handleDeleteLabel(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handlePostLabelAction is the HTTP handler for the POST /api/v2/labels/:id/:action route.
+func (h *LabelHandler) handlePostLabelAction(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if httprouter.ParamsFromContext(ctx).ByName("action") != "mappings:batch" {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "path not found",
+		}, w)
+		return
+	}
+
+	req, err := decodePostLabelMappingsBatchRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.LabelService.ApplyLabelMappings(ctx, req.Batch); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Label mappings applied", zap.String("labelID", fmt.Sprint(req.Batch.LabelID)), zap.Int("added", len(req.Batch.Add)), zap.Int("removed", len(req.Batch.Remove)))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type postLabelMappingsBatchRequest struct {
+	Batch *influxdb.LabelMappingBatch
+}
+
+func decodePostLabelMappingsBatchRequest(ctx context.Context, r *http.Request) (*postLabelMappingsBatchRequest, error) {
+	var i influxdb.ID
+	if err := i.DecodeFromString(httprouter.ParamsFromContext(ctx).ByName("id")); err != nil {
+		return nil, err
+	}
+
+	b := &influxdb.LabelMappingBatch{}
+	if err := json.NewDecoder(r.Body).Decode(b); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid label mapping batch",
+			Err:  err,
+		}
+	}
+	b.SetLabelID(i)
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &postLabelMappingsBatchRequest{Batch: b}, nil
+}
+
 type deleteLabelRequest struct {
 	LabelID influxdb.ID
 }
@@ -640,3 +696,14 @@ func (s *LabelService) DeleteLabelMapping(ctx context.Context, m *influxdb.Label
 		Delete(resourceIDPath(m.ResourceType, m.ResourceID, "labels")).
 		Do(ctx)
 }
+
+// ApplyLabelMappings adds and removes many label mappings at once.
+func (s *LabelService) ApplyLabelMappings(ctx context.Context, b *influxdb.LabelMappingBatch) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+
+	return s.Client.
+		PostJSON(b, labelIDPath(b.LabelID)+"/mappings:batch").
+		Do(ctx)
+}
@@ -18,6 +18,9 @@ type Event struct {
 	RequestBytes  int
 	ResponseBytes int
 	Status        int
+	// BatchID is the ID of the batch of points written by the request, if
+	// the client provided one.
+	BatchID string
 }
 
 // NopEventRecorder never records events.
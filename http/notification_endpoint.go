@@ -10,6 +10,7 @@ import (
 
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
 	pctx "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/notification/endpoint"
 	"github.com/influxdata/influxdb/v2/pkg/httpc"
@@ -27,6 +28,7 @@ type NotificationEndpointBackend struct {
 	LabelService                influxdb.LabelService
 	UserService                 influxdb.UserService
 	OrganizationService         influxdb.OrganizationService
+	SecretService               influxdb.SecretService
 }
 
 // NewNotificationEndpointBackend returns a new instance of NotificationEndpointBackend.
@@ -39,6 +41,7 @@ func NewNotificationEndpointBackend(log *zap.Logger, b *APIBackend) *Notificatio
 		LabelService:                b.LabelService,
 		UserService:                 b.UserService,
 		OrganizationService:         b.OrganizationService,
+		SecretService:               b.SecretService,
 	}
 }
 
@@ -57,11 +60,13 @@ type NotificationEndpointHandler struct {
 	LabelService                influxdb.LabelService
 	UserService                 influxdb.UserService
 	OrganizationService         influxdb.OrganizationService
+	Tester                      *endpoint.Tester
 }
 
 const (
 	prefixNotificationEndpoints          = "/api/v2/notificationEndpoints"
 	notificationEndpointsIDPath          = "/api/v2/notificationEndpoints/:id"
+	notificationEndpointsIDTestPath      = "/api/v2/notificationEndpoints/:id/test"
 	notificationEndpointsIDMembersPath   = "/api/v2/notificationEndpoints/:id/members"
 	notificationEndpointsIDMembersIDPath = "/api/v2/notificationEndpoints/:id/members/:userID"
 	notificationEndpointsIDOwnersPath    = "/api/v2/notificationEndpoints/:id/owners"
@@ -82,6 +87,7 @@ func NewNotificationEndpointHandler(log *zap.Logger, b *NotificationEndpointBack
 		LabelService:                b.LabelService,
 		UserService:                 b.UserService,
 		OrganizationService:         b.OrganizationService,
+		Tester:                      endpoint.NewTester(b.SecretService),
 	}
 	h.HandlerFunc("POST", prefixNotificationEndpoints, h.handlePostNotificationEndpoint)
 	h.HandlerFunc("GET", prefixNotificationEndpoints, h.handleGetNotificationEndpoints)
@@ -89,6 +95,7 @@ func NewNotificationEndpointHandler(log *zap.Logger, b *NotificationEndpointBack
 	h.HandlerFunc("DELETE", notificationEndpointsIDPath, h.handleDeleteNotificationEndpoint)
 	h.HandlerFunc("PUT", notificationEndpointsIDPath, h.handlePutNotificationEndpoint)
 	h.HandlerFunc("PATCH", notificationEndpointsIDPath, h.handlePatchNotificationEndpoint)
+	h.HandlerFunc("POST", notificationEndpointsIDTestPath, h.handleTestNotificationEndpoint)
 
 	memberBackend := MemberBackend{
 		HTTPErrorHandler:           b.HTTPErrorHandler,
@@ -548,6 +555,62 @@ func (h *NotificationEndpointHandler) handleDeleteNotificationEndpoint(w http.Re
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func decodeTestNotificationEndpointRequest(ctx context.Context, r *http.Request) (influxdb.ID, endpoint.TestEvent, error) {
+	var ev endpoint.TestEvent
+	id, err := decodeGetNotificationEndpointRequest(ctx)
+	if err != nil {
+		return id, ev, err
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			return id, ev, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid test event",
+				Err:  err,
+			}
+		}
+	}
+	return id, ev, nil
+}
+
+// handleTestNotificationEndpoint sends a test event through an endpoint
+// and responds with the status of the response of the provider. Sending
+// with the credentials of the endpoint requires write access to the
+// endpoint and read access to the secrets of its organization.
+func (h *NotificationEndpointHandler) handleTestNotificationEndpoint(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, ev, err := decodeTestNotificationEndpointRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	edp, err := h.NotificationEndpointService.FindNotificationEndpointByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.NotificationEndpointResourceType, id, edp.GetOrgID()); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.SecretsResourceType, edp.GetOrgID()); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	res, err := h.Tester.Test(ctx, edp, ev)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("NotificationEndpoint tested", zap.String("notificationEndpointID", fmt.Sprint(id)), zap.Int("statusCode", res.StatusCode))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
 // NotificationEndpointService is an http client for the influxdb.NotificationEndpointService server implementation.
 type NotificationEndpointService struct {
 	Client *httpc.Client
@@ -560,7 +623,8 @@ func NewNotificationEndpointService(client *httpc.Client) *NotificationEndpointS
 	return &NotificationEndpointService{
 		Client: client,
 		UserResourceMappingService: &UserResourceMappingService{
-			Client: client,
+			Client:       client,
+			ResourceType: influxdb.NotificationEndpointResourceType,
 		},
 		OrganizationService: &OrganizationService{
 			Client: client,
@@ -33,6 +33,7 @@ func NewMockNotificationEndpointBackend(t *testing.T) *NotificationEndpointBacke
 		LabelService:                mock.NewLabelService(),
 		UserService:                 mock.NewUserService(),
 		OrganizationService:         mock.NewOrganizationService(),
+		SecretService:               mock.NewSecretService(),
 	}
 }
 
@@ -595,6 +596,85 @@ func TestService_handleDeleteNotificationEndpoint(t *testing.T) {
 	}
 }
 
+func TestService_handleTestNotificationEndpoint(t *testing.T) {
+	var received map[string]interface{}
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("unable to decode test event: %v", err)
+		}
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("upstream down"))
+	}))
+	defer provider.Close()
+
+	notificationEndpointBackend := NewMockNotificationEndpointBackend(t)
+	notificationEndpointBackend.NotificationEndpointService = &mock.NotificationEndpointService{
+		FindNotificationEndpointByIDF: func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+			if id != influxTesting.MustIDBase16("020f755c3c082000") {
+				return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "notification endpoint not found"}
+			}
+			return &endpoint.HTTP{
+				Base: endpoint.Base{
+					ID:    influxTesting.IDPtr(id),
+					OrgID: influxTesting.IDPtr(influxTesting.MustIDBase16("020f755c3c082001")),
+					Name:  "hook",
+				},
+				URL:        provider.URL,
+				Method:     "POST",
+				AuthMethod: "none",
+			}, nil
+		},
+	}
+	h := NewNotificationEndpointHandler(zaptest.NewLogger(t), notificationEndpointBackend)
+
+	orgID := influxTesting.MustIDBase16("020f755c3c082001")
+	var (
+		readEndpoint  = influxdb.Permission{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.NotificationEndpointResourceType, OrgID: &orgID}}
+		writeEndpoint = influxdb.Permission{Action: influxdb.WriteAction, Resource: influxdb.Resource{Type: influxdb.NotificationEndpointResourceType, OrgID: &orgID}}
+		readSecrets   = influxdb.Permission{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.SecretsResourceType, OrgID: &orgID}}
+	)
+	withPerms := func(ps ...influxdb.Permission) context.Context {
+		return pcontext.SetAuthorizer(context.Background(), &influxdb.Authorization{Status: influxdb.Active, Permissions: ps})
+	}
+
+	// Reading an endpoint does not allow sending with its credentials.
+	testttp.
+		PostJSON(t, path.Join(prefixNotificationEndpoints, "020f755c3c082000", "test"), endpoint.TestEvent{Message: "hello"}).
+		WithCtx(withPerms(readEndpoint, readSecrets)).
+		Do(h).
+		ExpectStatus(http.StatusUnauthorized)
+	testttp.
+		PostJSON(t, path.Join(prefixNotificationEndpoints, "020f755c3c082000", "test"), endpoint.TestEvent{Message: "hello"}).
+		WithCtx(withPerms(readEndpoint, writeEndpoint)).
+		Do(h).
+		ExpectStatus(http.StatusUnauthorized)
+	if received != nil {
+		t.Fatalf("unexpected test event %v", received)
+	}
+
+	// The body of the response of the provider is not returned.
+	testttp.
+		PostJSON(t, path.Join(prefixNotificationEndpoints, "020f755c3c082000", "test"), endpoint.TestEvent{Message: "hello"}).
+		WithCtx(withPerms(readEndpoint, writeEndpoint, readSecrets)).
+		Do(h).
+		ExpectStatus(http.StatusOK).
+		ExpectBody(func(body *bytes.Buffer) {
+			want := `{"success": false, "statusCode": 502}`
+			if eq, diff, err := jsonEqual(body.String(), want); err != nil || !eq {
+				t.Errorf("unexpected test result, diff: %s, err: %v", diff, err)
+			}
+		})
+	if received["_message"] != "hello" || received["_notification_endpoint_name"] != "hook" {
+		t.Errorf("unexpected test event %v", received)
+	}
+
+	testttp.
+		Post(t, path.Join(prefixNotificationEndpoints, "020f755c3c082002", "test"), nil).
+		WithCtx(withPerms(readEndpoint, writeEndpoint, readSecrets)).
+		Do(h).
+		ExpectStatus(http.StatusNotFound)
+}
+
 func TestService_handlePatchNotificationEndpoint(t *testing.T) {
 	type fields struct {
 		NotificationEndpointService influxdb.NotificationEndpointService
@@ -720,7 +720,8 @@ func NewNotificationRuleService(client *httpc.Client) *NotificationRuleService {
 	return &NotificationRuleService{
 		Client: client,
 		UserResourceMappingService: &UserResourceMappingService{
-			Client: client,
+			Client:       client,
+			ResourceType: influxdb.NotificationRuleResourceType,
 		},
 		OrganizationService: &OrganizationService{
 			Client: client,
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/influxdata/influxdb/v2/kit/feature"
+	"github.com/influxdata/influxdb/v2/kit/feature/override"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 )
 
@@ -18,11 +19,18 @@ type PlatformHandler struct {
 // NewPlatformHandler returns a platform handler that serves the API and associated assets.
 func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler {
 	h := NewAuthenticationHandler(b.Logger, b.HTTPErrorHandler)
-	h.Handler = feature.NewHandler(b.Logger, b.Flagger, feature.Flags(), NewAPIHandler(b, opts...))
+	var apiHandler http.Handler = NewAPIHandler(b, opts...)
+	if b.FeatureFlagRequestOverrides {
+		apiHandler = override.NewRequestHandler(b.HTTPErrorHandler, feature.ByKey, apiHandler)
+	}
+	h.Handler = feature.NewHandler(b.Logger, b.Flagger, feature.Flags(), apiHandler)
 	h.AuthorizationService = b.AuthorizationService
 	h.SessionService = b.SessionService
 	h.SessionRenewDisabled = b.SessionRenewDisabled
 	h.UserService = b.UserService
+	h.CertificateAuthenticator = b.CertificateAuthenticator
+	h.FrozenOrgFinder = b.FrozenOrgFinder
+	h.PolicyEngine = b.PolicyEngine
 
 	h.RegisterNoAuthRoute("GET", "/api/v2")
 	h.RegisterNoAuthRoute("POST", "/api/v2/signin")
@@ -30,11 +38,12 @@ func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler
 	h.RegisterNoAuthRoute("POST", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/swagger.json")
+	h.RegisterNoAuthRoute("GET", prefixSignedQuery)
 
 	assetHandler := NewAssetHandler()
 	assetHandler.Path = b.AssetsPath
 
-	wrappedHandler := kithttp.SetCORS(h)
+	wrappedHandler := kithttp.CORS(b.corsPolicies())(h)
 	wrappedHandler = kithttp.SkipOptions(wrappedHandler)
 
 	return &PlatformHandler{
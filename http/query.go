@@ -35,6 +35,23 @@ type QueryRequest struct {
 	AST     json.RawMessage `json:"ast,omitempty"`
 	Dialect QueryDialect    `json:"dialect"`
 	Now     time.Time       `json:"now"`
+	// AsOf resolves the names of buckets as they were at that time. Along
+	// with Now, a query runs the way it ran then, e.g. the way a task ran
+	// before its buckets were renamed.
+	AsOf *time.Time `json:"asOf,omitempty"`
+
+	// Params are bound to the params option of the query, e.g. a query
+	// can filter by params.host, so that values are passed to queries
+	// without being spliced into them.
+	Params map[string]interface{} `json:"params,omitempty"`
+
+	// MaxResultSize is the size in bytes of the response after which no more
+	// results are written, and ChunkRowCount the number of rows of a chunk of
+	// results, Chunk being the index of the chunk to return. A response that
+	// reaches either limit ends with a partial result annotation.
+	MaxResultSize int64 `json:"maxResultSize,omitempty"`
+	ChunkRowCount int64 `json:"chunkRowCount,omitempty"`
+	Chunk         int64 `json:"chunk,omitempty"`
 
 	// InfluxQL fields
 	Bucket string `json:"bucket,omitempty"`
@@ -66,8 +83,17 @@ type QueryDialect struct {
 	CommentPrefix  string   `json:"commentPrefix"`
 	DateTimeFormat string   `json:"dateTimeFormat"`
 	Annotations    []string `json:"annotations"`
+	// Format is the format of the response, csv when empty. The other
+	// options of the dialect only apply to csv.
+	Format string `json:"format,omitempty"`
 }
 
+// Formats of the responses to queries.
+const (
+	queryFormatCSV     = "csv"
+	queryFormatParquet = "parquet"
+)
+
 // WithDefaults adds default values to the request.
 func (r QueryRequest) WithDefaults() QueryRequest {
 	if r.Type == "" {
@@ -100,6 +126,14 @@ func (r QueryRequest) Validate() error {
 		return fmt.Errorf("bucket parameter is required for influxql queries")
 	}
 
+	if r.Type == "influxql" && len(r.Params) > 0 {
+		return fmt.Errorf("params are not supported for influxql queries")
+	}
+
+	if r.Type == "influxql" && r.AsOf != nil {
+		return fmt.Errorf("asOf is not supported for influxql queries")
+	}
+
 	if len(r.Dialect.CommentPrefix) > 1 {
 		return fmt.Errorf("invalid dialect comment prefix: must be length 0 or 1")
 	}
@@ -127,9 +161,43 @@ func (r QueryRequest) Validate() error {
 		return fmt.Errorf(`unknown dialect date time format: %s`, r.Dialect.DateTimeFormat)
 	}
 
+	if r.MaxResultSize < 0 {
+		return fmt.Errorf("maxResultSize must not be negative")
+	}
+	if r.ChunkRowCount < 0 {
+		return fmt.Errorf("chunkRowCount must not be negative")
+	}
+	if r.Chunk < 0 {
+		return fmt.Errorf("chunk must not be negative")
+	}
+	if r.Chunk > 0 && r.ChunkRowCount == 0 {
+		return fmt.Errorf("chunk requires chunkRowCount")
+	}
+	if r.Type == "influxql" && r.limited() {
+		return fmt.Errorf("maxResultSize and chunkRowCount are not supported for influxql queries")
+	}
+
+	switch r.Dialect.Format {
+	case "", queryFormatCSV:
+	case queryFormatParquet:
+		if r.Type == "influxql" {
+			return fmt.Errorf("the parquet format is not supported for influxql queries")
+		}
+		if r.limited() {
+			return fmt.Errorf("maxResultSize and chunkRowCount are not supported by the parquet format")
+		}
+	default:
+		return fmt.Errorf(`unknown dialect format: %s`, r.Dialect.Format)
+	}
+
 	return nil
 }
 
+// limited reports whether the results of the request are limited.
+func (r QueryRequest) limited() bool {
+	return r.MaxResultSize > 0 || r.ChunkRowCount > 0
+}
+
 // QueryAnalysis is a structured response of errors.
 type QueryAnalysis struct {
 	Errors []queryParseError `json:"errors"`
@@ -250,6 +318,14 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 		n = now()
 	}
 
+	extern := r.Extern
+	if len(r.Params) > 0 {
+		var err error
+		if extern, err = paramsExtern(r.Extern, r.Params); err != nil {
+			return nil, err
+		}
+	}
+
 	// Query is preferred over AST
 	var compiler flux.Compiler
 	if r.Query != "" {
@@ -265,13 +341,13 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 		default:
 			compiler = lang.FluxCompiler{
 				Now:    n,
-				Extern: r.Extern,
+				Extern: extern,
 				Query:  r.Query,
 			}
 		}
 	} else if len(r.AST) > 0 {
 		c := lang.ASTCompiler{
-			Extern: r.Extern,
+			Extern: extern,
 			AST:    r.AST,
 			Now:    n,
 		}
@@ -304,6 +380,17 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 				dialect = &query.NoContentWithErrorDialect{
 					ResultEncoderConfig: encConfig,
 				}
+			} else if r.Dialect.Format == queryFormatParquet {
+				dialect = &query.ParquetDialect{}
+			} else if r.limited() {
+				dialect = &query.LimitedDialect{
+					ResultEncoderConfig: encConfig,
+					Limits: query.ResultLimits{
+						MaxBytes:  r.MaxResultSize,
+						ChunkRows: r.ChunkRowCount,
+						Chunk:     r.Chunk,
+					},
+				}
 			} else {
 				dialect = &csv.Dialect{
 					ResultEncoderConfig: encConfig,
@@ -316,6 +403,7 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 		Request: query.Request{
 			OrganizationID: r.Org.ID,
 			Compiler:       compiler,
+			AsOf:           r.AsOf,
 		},
 		Dialect: dialect,
 	}, nil
@@ -338,6 +426,7 @@ func QueryRequestFromProxyRequest(req *query.ProxyRequest) (*QueryRequest, error
 	default:
 		return nil, fmt.Errorf("unsupported compiler %T", c)
 	}
+	qr.AsOf = req.Request.AsOf
 	switch d := req.Dialect.(type) {
 	case *csv.Dialect:
 		var header = !d.ResultEncoderConfig.NoHeader
@@ -346,6 +435,18 @@ func QueryRequestFromProxyRequest(req *query.ProxyRequest) (*QueryRequest, error
 		qr.Dialect.CommentPrefix = "#"
 		qr.Dialect.DateTimeFormat = "RFC3339"
 		qr.Dialect.Annotations = d.ResultEncoderConfig.Annotations
+	case *query.LimitedDialect:
+		var header = !d.ResultEncoderConfig.NoHeader
+		qr.Dialect.Header = &header
+		qr.Dialect.Delimiter = string(d.ResultEncoderConfig.Delimiter)
+		qr.Dialect.CommentPrefix = "#"
+		qr.Dialect.DateTimeFormat = "RFC3339"
+		qr.Dialect.Annotations = d.ResultEncoderConfig.Annotations
+		qr.MaxResultSize = d.Limits.MaxBytes
+		qr.ChunkRowCount = d.Limits.ChunkRows
+		qr.Chunk = d.Limits.Chunk
+	case *query.ParquetDialect:
+		qr.Dialect.Format = queryFormatParquet
 	case *query.NoContentDialect:
 		qr.PreferNoContent = true
 	case *query.NoContentWithErrorDialect:
@@ -378,7 +479,11 @@ func decodeQueryRequest(ctx context.Context, r *http.Request, svc influxdb.Organ
 	case "application/json":
 		fallthrough
 	default:
-		if err := json.NewDecoder(body).Decode(&req); err != nil {
+		// Numbers are decoded as json.Number so that integer params are
+		// bound as integers.
+		dec := json.NewDecoder(body)
+		dec.UseNumber()
+		if err := dec.Decode(&req); err != nil {
 			return nil, body.bytesRead, err
 		}
 	}
@@ -421,18 +526,26 @@ func decodeProxyQueryRequest(ctx context.Context, r *http.Request, auth influxdb
 		return nil, n, err
 	}
 
-	var token *influxdb.Authorization
+	token, err := queryAuthorization(auth, req.Org.ID)
+	if err != nil {
+		return pr, n, err
+	}
+
+	pr.Request.Authorization = token
+	return pr, n, nil
+}
+
+// queryAuthorization returns the authorization a query of the organization
+// runs with on behalf of auth.
+func queryAuthorization(auth influxdb.Authorizer, orgID influxdb.ID) (*influxdb.Authorization, error) {
 	switch a := auth.(type) {
 	case *influxdb.Authorization:
-		token = a
+		return a, nil
 	case *influxdb.Session:
-		token = a.EphemeralAuth(req.Org.ID)
+		return a.EphemeralAuth(orgID), nil
 	case *jsonweb.Token:
-		token = a.EphemeralAuth(req.Org.ID)
+		return a.EphemeralAuth(orgID), nil
 	default:
-		return pr, n, influxdb.ErrAuthorizerNotSupported
+		return nil, influxdb.ErrAuthorizerNotSupported
 	}
-
-	pr.Request.Authorization = token
-	return pr, n, nil
 }
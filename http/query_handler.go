@@ -20,6 +20,7 @@ import (
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
 	pcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/fluxfmt"
 	"github.com/influxdata/influxdb/v2/http/metric"
 	"github.com/influxdata/influxdb/v2/kit/check"
 	"github.com/influxdata/influxdb/v2/kit/feature"
@@ -50,6 +51,8 @@ type FluxBackend struct {
 	ProxyQueryService   query.ProxyQueryService
 	FluxLanguageService influxdb.FluxLanguageService
 	Flagger             feature.Flagger
+	QuerySigner         *QuerySigner
+	Backpressure        kithttp.Backpressure
 }
 
 // NewFluxBackend returns a new instance of FluxBackend.
@@ -66,6 +69,8 @@ func NewFluxBackend(log *zap.Logger, b *APIBackend) *FluxBackend {
 		OrganizationService: b.OrganizationService,
 		FluxLanguageService: b.FluxLanguageService,
 		Flagger:             b.Flagger,
+		QuerySigner:         b.QuerySigner,
+		Backpressure:        b.QueryBackpressure,
 	}
 }
 
@@ -88,6 +93,14 @@ type FluxHandler struct {
 	EventRecorder metric.EventRecorder
 
 	Flagger feature.Flagger
+
+	// QuerySigner signs the queries fetched without a token. Queries are
+	// not signed when it is nil.
+	QuerySigner *QuerySigner
+
+	// Backpressure reports the queries awaiting execution in the headers of
+	// the responses to queries. They are not reported when it is nil.
+	Backpressure kithttp.Backpressure
 }
 
 // Prefix provides the route prefix.
@@ -108,6 +121,8 @@ func NewFluxHandler(log *zap.Logger, b *FluxBackend) *FluxHandler {
 		EventRecorder:       b.QueryEventRecorder,
 		FluxLanguageService: b.FluxLanguageService,
 		Flagger:             b.Flagger,
+		QuerySigner:         b.QuerySigner,
+		Backpressure:        b.Backpressure,
 	}
 
 	// query reponses can optionally be gzip encoded
@@ -115,8 +130,13 @@ func NewFluxHandler(log *zap.Logger, b *FluxBackend) *FluxHandler {
 	h.Handler("POST", prefixQuery, withFeatureProxy(b.AlgoWProxy, qh))
 	h.Handler("POST", "/api/v2/query/ast", withFeatureProxy(b.AlgoWProxy, http.HandlerFunc(h.postFluxAST)))
 	h.Handler("POST", "/api/v2/query/analyze", withFeatureProxy(b.AlgoWProxy, http.HandlerFunc(h.postQueryAnalyze)))
+	h.Handler("POST", "/api/v2/query/format", withFeatureProxy(b.AlgoWProxy, http.HandlerFunc(h.postQueryFormat)))
 	h.Handler("GET", "/api/v2/query/suggestions", withFeatureProxy(b.AlgoWProxy, http.HandlerFunc(h.getFluxSuggestions)))
 	h.Handler("GET", "/api/v2/query/suggestions/:name", withFeatureProxy(b.AlgoWProxy, http.HandlerFunc(h.getFluxSuggestion)))
+	if h.QuerySigner != nil {
+		h.HandlerFunc("POST", prefixSignedQuery, h.handlePostSignedQuery)
+		h.Handler("GET", prefixSignedQuery, gziphandler.GzipHandler(http.HandlerFunc(h.handleGetSignedQuery)))
+	}
 	return h
 }
 
@@ -130,6 +150,9 @@ func (h *FluxHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
 	if id, _, found := tracing.InfoFromContext(ctx); found {
 		w.Header().Set(traceIDHeader, id)
 	}
+	if h.Backpressure != nil {
+		kithttp.SetBackpressureHeaders(w, h.Backpressure)
+	}
 
 	// TODO(desa): I really don't like how we're recording the usage metrics here
 	// Ideally this will be moved when we solve https://github.com/influxdata/influxdb/issues/13403
@@ -281,6 +304,36 @@ func (h *FluxHandler) postQueryAnalyze(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// postQueryFormat returns the canonical form of a flux script, as stored by
+// the format on save of tasks and checks.
+func (h *FluxHandler) postQueryFormat(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "FluxHandler")
+	defer span.Finish()
+
+	var request langRequest
+	ctx := r.Context()
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid json",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	formatted, err := fluxfmt.Format(h.FluxLanguageService, request.Query)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, langRequest{Query: formatted}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
 // fluxParams contain flux funciton parameters as defined by the semantic graph
 type fluxParams map[string]string
 
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/v2"
+)
+
+// paramsOption is the option the params of a query are bound to.
+const paramsOption = "params"
+
+// paramsExtern returns extern with the params option set to the record of
+// params. The params are bound as literals rather than spliced into the
+// query, so their values cannot change the meaning of the query.
+func paramsExtern(extern json.RawMessage, params map[string]interface{}) (json.RawMessage, error) {
+	record, err := paramExpression(params)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid query params",
+			Err:  err,
+		}
+	}
+
+	file := &ast.File{}
+	if len(extern) > 0 {
+		if err := json.Unmarshal(extern, file); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid extern",
+				Err:  err,
+			}
+		}
+	}
+	for _, stmt := range file.Body {
+		if opt, ok := stmt.(*ast.OptionStatement); ok {
+			if a, ok := opt.Assignment.(*ast.VariableAssignment); ok && a.ID.Name == paramsOption {
+				return nil, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "the params option cannot be set by both extern and params",
+				}
+			}
+		}
+	}
+
+	file.Body = append(file.Body, &ast.OptionStatement{
+		Assignment: &ast.VariableAssignment{
+			ID:   &ast.Identifier{Name: paramsOption},
+			Init: record,
+		},
+	})
+	return json.Marshal(file)
+}
+
+// paramExpression returns the literal of a value of a param decoded from
+// JSON. Numbers without a fraction or exponent are integers, strings that
+// are RFC3339 times or durations, e.g. 2020-06-01T00:00:00Z or -1h, are
+// times and durations, and objects are records.
+func paramExpression(v interface{}) (ast.Expression, error) {
+	switch v := v.(type) {
+	case bool:
+		return &ast.BooleanLiteral{Value: v}, nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return &ast.IntegerLiteral{Value: i}, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return &ast.FloatLiteral{Value: f}, nil
+	case int:
+		return &ast.IntegerLiteral{Value: int64(v)}, nil
+	case int64:
+		return &ast.IntegerLiteral{Value: v}, nil
+	case uint64:
+		return &ast.UnsignedIntegerLiteral{Value: v}, nil
+	case float64:
+		return &ast.FloatLiteral{Value: v}, nil
+	case string:
+		if t, err := parser.ParseTime(v); err == nil {
+			t.BaseNode = ast.BaseNode{}
+			return t, nil
+		}
+		if d, err := parser.ParseSignedDuration(v); err == nil {
+			d.BaseNode = ast.BaseNode{}
+			return d, nil
+		}
+		return &ast.StringLiteral{Value: v}, nil
+	case []interface{}:
+		arr := &ast.ArrayExpression{Elements: make([]ast.Expression, 0, len(v))}
+		for i, e := range v {
+			elem, err := paramExpression(e)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %v", i, err)
+			}
+			arr.Elements = append(arr.Elements, elem)
+		}
+		return arr, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		obj := &ast.ObjectExpression{Properties: make([]*ast.Property, 0, len(v))}
+		for _, k := range keys {
+			value, err := paramExpression(v[k])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", k, err)
+			}
+			obj.Properties = append(obj.Properties, &ast.Property{
+				Key:   propertyKey(k),
+				Value: value,
+			})
+		}
+		return obj, nil
+	case nil:
+		return nil, fmt.Errorf("null is not a valid value")
+	default:
+		return nil, fmt.Errorf("unsupported value of type %T", v)
+	}
+}
+
+// propertyKey returns the key of a record property: an identifier, so that
+// it can be referenced as params.name, or else a string literal, that can
+// be referenced as params["name"].
+func propertyKey(k string) ast.PropertyKey {
+	for i, r := range k {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return &ast.StringLiteral{Value: k}
+		}
+	}
+	if k == "" {
+		return &ast.StringLiteral{Value: k}
+	}
+	return &ast.Identifier{Name: k}
+}
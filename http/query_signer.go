@@ -0,0 +1,431 @@
+package http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/influxdb/v2"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixSignedQuery = "/api/v2/query/signed"
+
+	// defaultSignedQueryLifetime is the lifetime of a signed query without
+	// an expiry.
+	defaultSignedQueryLifetime = 24 * time.Hour
+
+	// maxSignedQueryLifetime is the longest lifetime of a signed query.
+	maxSignedQueryLifetime = 30 * 24 * time.Hour
+)
+
+// errSignerUnauthorized is used when the signer of a query is no longer
+// allowed to run it.
+var errSignerUnauthorized = &influxdb.Error{
+	Code: influxdb.EUnauthorized,
+	Msg:  "the signer of the query is no longer allowed to run it",
+}
+
+// QuerySigner signs flux queries into urls that can be fetched without a
+// token until they expire, so that a chart can be embedded in an external
+// tool without distributing tokens. A signed query runs with the bucket read
+// permissions its signer had in its organization when signing it, as long as
+// the signer remains an active member of the organization and the token it
+// was signed with is not revoked. A query signed with a token keeps only the
+// permissions the token still has.
+type QuerySigner struct {
+	key []byte
+	now func() time.Time
+
+	auths influxdb.AuthorizationService
+	users influxdb.UserService
+	urms  influxdb.UserResourceMappingService
+}
+
+// NewQuerySigner returns a signer of queries with key. Changing the key
+// invalidates the urls signed with the previous one. The signers of queries
+// are checked in the services, which must not perform authorization
+// themselves.
+func NewQuerySigner(key []byte, auths influxdb.AuthorizationService, users influxdb.UserService, urms influxdb.UserResourceMappingService) *QuerySigner {
+	return &QuerySigner{
+		key:   key,
+		now:   time.Now,
+		auths: auths,
+		users: users,
+		urms:  urms,
+	}
+}
+
+// signedQuery is the query encapsulated by a signed url.
+type signedQuery struct {
+	OrgID  influxdb.ID `json:"orgID"`
+	UserID influxdb.ID `json:"userID,omitempty"`
+	// AuthorizationID is the ID of the token the query was signed with, if
+	// it was not signed in a session.
+	AuthorizationID influxdb.ID `json:"authorizationID,omitempty"`
+	Query           string      `json:"query"`
+	// Start and Stop are the bounds of the time range of the query, as
+	// times or as durations relative to the time the url is fetched.
+	Start       string                `json:"start"`
+	Stop        string                `json:"stop,omitempty"`
+	ExpiresAt   time.Time             `json:"expiresAt"`
+	Permissions []influxdb.Permission `json:"permissions"`
+	// RowFilters are the row filters of the authorization of the signer.
+	RowFilters []influxdb.TagRule `json:"rowFilters,omitempty"`
+}
+
+// timeRange returns the bounds of the time range of q fetched at now.
+func (q signedQuery) timeRange(now time.Time) (start, stop time.Time, err error) {
+	if start, err = rangeBound(q.Start, now); err != nil {
+		return start, stop, err
+	}
+	if q.Stop == "" {
+		return start, now, nil
+	}
+	stop, err = rangeBound(q.Stop, now)
+	return start, stop, err
+}
+
+func rangeBound(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	d, err := ParseDuration(s)
+	if err != nil {
+		return time.Time{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid time range bound %q: must be an RFC3339 time or a duration", s),
+		}
+	}
+	return now.Add(d), nil
+}
+
+// extern returns the option v of the dashboard queries with the time range
+// of q, so that the query can use v.timeRangeStart and v.timeRangeStop.
+func (q signedQuery) extern(now time.Time) (json.RawMessage, error) {
+	start, stop, err := q.timeRange(now)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&ast.File{
+		Body: []ast.Statement{
+			&ast.OptionStatement{
+				Assignment: &ast.VariableAssignment{
+					ID: &ast.Identifier{Name: "v"},
+					Init: &ast.ObjectExpression{
+						Properties: []*ast.Property{
+							{Key: &ast.Identifier{Name: "timeRangeStart"}, Value: &ast.DateTimeLiteral{Value: start}},
+							{Key: &ast.Identifier{Name: "timeRangeStop"}, Value: &ast.DateTimeLiteral{Value: stop}},
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// sign returns the query string of the signed url of q.
+func (s *QuerySigner) sign(q signedQuery) (string, error) {
+	b, err := json.Marshal(q)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(b)
+	v := url.Values{}
+	v.Set("query", payload)
+	v.Set("signature", base64.RawURLEncoding.EncodeToString(s.signature(payload)))
+	return v.Encode(), nil
+}
+
+// verify returns the query of the signed url with query string v. It fails
+// if the url has been tampered with or is expired.
+func (s *QuerySigner) verify(v url.Values) (*signedQuery, error) {
+	payload := v.Get("query")
+	signature, err := base64.RawURLEncoding.DecodeString(v.Get("signature"))
+	if err != nil || payload == "" || !hmac.Equal(signature, s.signature(payload)) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "invalid signed query",
+		}
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid signed query",
+			Err:  err,
+		}
+	}
+	var q signedQuery
+	if err := json.Unmarshal(b, &q); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid signed query",
+			Err:  err,
+		}
+	}
+
+	if !s.now().Before(q.ExpiresAt) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "signed query has expired",
+		}
+	}
+	return &q, nil
+}
+
+// authorize returns the authorization the signed query q runs with. It fails
+// if the token q was signed with is removed or inactive, or if its signer is
+// no longer an active member of the organization of q.
+func (s *QuerySigner) authorize(ctx context.Context, q *signedQuery) (*influxdb.Authorization, error) {
+	auth := &influxdb.Authorization{
+		OrgID:       q.OrgID,
+		UserID:      q.UserID,
+		Status:      influxdb.Active,
+		Permissions: q.Permissions,
+		RowFilters:  q.RowFilters,
+	}
+
+	if q.AuthorizationID.Valid() {
+		a, err := s.auths.FindAuthorizationByID(ctx, q.AuthorizationID)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil, errSignerUnauthorized
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !a.IsActive() {
+			return nil, errSignerUnauthorized
+		}
+
+		ps := influxdb.PermissionSet(a.Permissions)
+		auth.Permissions = nil
+		for _, p := range q.Permissions {
+			if ps.Allowed(p) {
+				auth.Permissions = append(auth.Permissions, p)
+			}
+		}
+		if len(auth.Permissions) == 0 {
+			return nil, errSignerUnauthorized
+		}
+		auth.RowFilters = a.RowFilters
+	}
+
+	if !q.UserID.Valid() {
+		return auth, nil
+	}
+	u, err := s.users.FindUserByID(ctx, q.UserID)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return nil, errSignerUnauthorized
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u.Status != influxdb.Active {
+		return nil, errSignerUnauthorized
+	}
+
+	_, n, err := s.urms.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		UserID:       q.UserID,
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   q.OrgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errSignerUnauthorized
+	}
+	return auth, nil
+}
+
+func (s *QuerySigner) signature(payload string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// bucketReadPermissions returns the permissions of ps to read the buckets of orgID.
+func bucketReadPermissions(ps []influxdb.Permission, orgID influxdb.ID) []influxdb.Permission {
+	var read []influxdb.Permission
+	for _, p := range ps {
+		if p.Action != influxdb.ReadAction || p.Resource.Type != influxdb.BucketsResourceType {
+			continue
+		}
+		if p.Resource.OrgID != nil && *p.Resource.OrgID != orgID {
+			continue
+		}
+		read = append(read, p)
+	}
+	return read
+}
+
+type postSignedQueryRequest struct {
+	OrgID     influxdb.ID `json:"orgID"`
+	Query     string      `json:"query"`
+	Start     string      `json:"start"`
+	Stop      string      `json:"stop"`
+	ExpiresIn string      `json:"expiresIn"`
+}
+
+type signedQueryResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// handlePostSignedQuery is the HTTP handler for the POST /api/v2/query/signed route.
+func (h *FluxHandler) handlePostSignedQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postSignedQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode request body",
+			Err:  err,
+		}, w)
+		return
+	}
+	if !req.OrgID.Valid() || req.Query == "" || req.Start == "" {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID, query and start are required",
+		}, w)
+		return
+	}
+
+	lifetime := defaultSignedQueryLifetime
+	if req.ExpiresIn != "" {
+		d, err := ParseDuration(req.ExpiresIn)
+		if err != nil || d <= 0 || d > maxSignedQueryLifetime {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("invalid expiresIn %q: must be a positive duration of at most %dd", req.ExpiresIn, maxSignedQueryLifetime/(24*time.Hour)),
+			}, w)
+			return
+		}
+		lifetime = d
+	}
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	ps, err := a.PermissionSet()
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	now := h.QuerySigner.now()
+	q := signedQuery{
+		OrgID:       req.OrgID,
+		UserID:      a.GetUserID(),
+		Query:       req.Query,
+		Start:       req.Start,
+		Stop:        req.Stop,
+		ExpiresAt:   now.Add(lifetime).UTC(),
+		Permissions: bucketReadPermissions(ps, req.OrgID),
+	}
+	if auth, ok := a.(*influxdb.Authorization); ok {
+		q.AuthorizationID = auth.ID
+		q.RowFilters = auth.RowFilters
+	}
+	if len(q.Permissions) == 0 {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  "signing a query requires read access to the buckets of its organization",
+		}, w)
+		return
+	}
+	if _, _, err := q.timeRange(now); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	qs, err := h.QuerySigner.sign(q)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Query signed", zap.String("orgID", q.OrgID.String()), zap.Time("expiresAt", q.ExpiresAt))
+	if err := encodeResponse(ctx, w, http.StatusCreated, signedQueryResponse{
+		URL:       prefixSignedQuery + "?" + qs,
+		ExpiresAt: q.ExpiresAt,
+	}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetSignedQuery is the HTTP handler for the GET /api/v2/query/signed
+// route. It does not require a token: the signature of the url authorizes
+// the query.
+func (h *FluxHandler) handleGetSignedQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	q, err := h.QuerySigner.verify(r.URL.Query())
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	auth, err := h.QuerySigner.authorize(ctx, q)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	now := h.Now()
+	extern, err := q.extern(now)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	req := QueryRequest{
+		Type:   "flux",
+		Query:  q.Query,
+		Extern: extern,
+		Now:    now,
+		Org:    &influxdb.Organization{ID: q.OrgID},
+	}.WithDefaults()
+	pr, err := req.ProxyRequest()
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid signed query",
+			Err:  err,
+		}, w)
+		return
+	}
+	pr.Request.Authorization = auth
+	pr.Request.Source = r.Header.Get("User-Agent")
+
+	ctx = pcontext.SetAuthorizer(ctx, auth)
+	if hd, ok := pr.Dialect.(HTTPDialect); ok {
+		hd.SetHeaders(w)
+	}
+	cw := iocounter.Writer{Writer: w}
+	if _, err := h.ProxyQueryService.Query(ctx, &cw, pr); err != nil {
+		if cw.Count() == 0 {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		h.log.Info("Error writing response to client",
+			zap.String("handler", "flux"),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,198 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	influxmock "github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestFluxHandler_SignedQuery(t *testing.T) {
+	orgID, otherOrgID := influxdb.ID(1), influxdb.ID(2)
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	signerAuth := &influxdb.Authorization{
+		ID:     10,
+		UserID: 3,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &orgID}},
+			{Action: influxdb.WriteAction, Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &orgID}},
+			{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &otherOrgID}},
+		},
+	}
+	var (
+		auth   = *signerAuth
+		user   = influxdb.User{ID: 3, Status: influxdb.Active}
+		member = true
+	)
+	auths := influxmock.NewAuthorizationService()
+	auths.FindAuthorizationByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+		if id != auth.ID {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound}
+		}
+		a := auth
+		return &a, nil
+	}
+	users := influxmock.NewUserService()
+	users.FindUserByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.User, error) {
+		u := user
+		return &u, nil
+	}
+	urms := influxmock.NewUserResourceMappingService()
+	urms.FindMappingsFn = func(ctx context.Context, filter influxdb.UserResourceMappingFilter) ([]*influxdb.UserResourceMapping, int, error) {
+		if !member || filter.UserID != user.ID || filter.ResourceID != orgID {
+			return nil, 0, nil
+		}
+		return []*influxdb.UserResourceMapping{{UserID: user.ID, ResourceID: orgID, ResourceType: influxdb.OrgsResourceType}}, 1, nil
+	}
+
+	var got *query.ProxyRequest
+	signer := NewQuerySigner([]byte("secret"), auths, users, urms)
+	signer.now = func() time.Time { return now }
+	h := NewFluxHandler(zaptest.NewLogger(t), &FluxBackend{
+		HTTPErrorHandler:   kithttp.ErrorHandler(0),
+		log:                zaptest.NewLogger(t),
+		QueryEventRecorder: noopEventRecorder{},
+		ProxyQueryService: &mock.ProxyQueryService{
+			QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+				got = req
+				_, err := w.Write([]byte("#datatype,string,long\n"))
+				return flux.Statistics{}, err
+			},
+		},
+		QuerySigner: signer,
+	})
+	h.Now = func() time.Time { return now }
+
+	sign := func(t *testing.T, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest("POST", prefixSignedQuery, strings.NewReader(body))
+		r = r.WithContext(icontext.SetAuthorizer(r.Context(), signerAuth))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := sign(t, `{"orgID":"0000000000000001","query":"from(bucket: \"b\") |> range(start: v.timeRangeStart, stop: v.timeRangeStop)","start":"-1h","expiresIn":"1h"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+	var res signedQueryResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if !res.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("unexpected expiry %s", res.ExpiresAt)
+	}
+
+	t.Run("fetch without a token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", res.URL, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+		}
+
+		if got.Request.OrganizationID != orgID {
+			t.Errorf("unexpected organization %s", got.Request.OrganizationID)
+		}
+		// Only the permissions to read the buckets of the organization are signed.
+		if ps := got.Request.Authorization.Permissions; len(ps) != 1 || ps[0].Action != influxdb.ReadAction || *ps[0].Resource.OrgID != orgID {
+			t.Errorf("unexpected permissions %v", ps)
+		}
+		c, ok := got.Request.Compiler.(lang.FluxCompiler)
+		if !ok {
+			t.Fatalf("unexpected compiler %T", got.Request.Compiler)
+		}
+		if !strings.Contains(string(c.Extern), "timeRangeStart") || !strings.Contains(string(c.Extern), "2020-06-01T11:00:00Z") {
+			t.Errorf("unexpected extern %s", c.Extern)
+		}
+	})
+
+	t.Run("tampered url", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", strings.Replace(res.URL, "signature=", "signature=A", 1), nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("unexpected status %d", w.Code)
+		}
+	})
+
+	t.Run("expired url", func(t *testing.T) {
+		defer func() { signer.now = func() time.Time { return now } }()
+		signer.now = func() time.Time { return now.Add(2 * time.Hour) }
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", res.URL, nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("unexpected status %d", w.Code)
+		}
+	})
+
+	fetchUnauthorized := func(t *testing.T) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", res.URL, nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("unexpected status %d", w.Code)
+		}
+	}
+
+	t.Run("revoked token", func(t *testing.T) {
+		defer func() { auth = *signerAuth }()
+
+		auth.Status = influxdb.Inactive
+		fetchUnauthorized(t)
+
+		auth.ID = 11
+		fetchUnauthorized(t)
+	})
+
+	t.Run("token without read access", func(t *testing.T) {
+		defer func() { auth = *signerAuth }()
+		auth.Permissions = auth.Permissions[1:2]
+
+		fetchUnauthorized(t)
+	})
+
+	t.Run("inactive user", func(t *testing.T) {
+		defer func() { user.Status = influxdb.Active }()
+		user.Status = influxdb.Inactive
+
+		fetchUnauthorized(t)
+	})
+
+	t.Run("removed member", func(t *testing.T) {
+		defer func() { member = true }()
+		member = false
+
+		fetchUnauthorized(t)
+	})
+
+	t.Run("lifetime too long", func(t *testing.T) {
+		w := sign(t, `{"orgID":"0000000000000001","query":"buckets()","start":"-1h","expiresIn":"31d"}`)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("unexpected status %d", w.Code)
+		}
+	})
+
+	t.Run("no read access", func(t *testing.T) {
+		w := sign(t, `{"orgID":"0000000000000003","query":"buckets()","start":"-1h"}`)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("unexpected status %d", w.Code)
+		}
+	})
+}
@@ -81,6 +81,10 @@ func TestQueryRequest_Validate(t *testing.T) {
 		Type    string
 		Dialect QueryDialect
 		org     *platform.Organization
+
+		MaxResultSize int64
+		ChunkRowCount int64
+		Chunk         int64
 	}
 	tests := []struct {
 		name    string
@@ -159,6 +163,45 @@ func TestQueryRequest_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "negative max result size",
+			fields: fields{
+				Query: "from()",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				MaxResultSize: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "chunk without chunk row count",
+			fields: fields{
+				Query: "from()",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				Chunk: 1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative chunk row count",
+			fields: fields{
+				Query: "from()",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				ChunkRowCount: -10,
+			},
+			wantErr: true,
+		},
 		{
 			name: "valid query",
 			fields: fields{
@@ -170,16 +213,72 @@ func TestQueryRequest_Validate(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "valid limited query",
+			fields: fields{
+				Query: "from()",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				MaxResultSize: 1 << 20,
+				ChunkRowCount: 1000,
+				Chunk:         2,
+			},
+		},
+		{
+			name: "valid parquet query",
+			fields: fields{
+				Query: "from()",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+					Format:         "parquet",
+				},
+			},
+		},
+		{
+			name: "unknown format",
+			fields: fields{
+				Query: "from()",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+					Format:         "xlsx",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "parquet results are not limited",
+			fields: fields{
+				Query: "from()",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+					Format:         "parquet",
+				},
+				MaxResultSize: 1 << 20,
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := QueryRequest{
-				Extern:  tt.fields.Extern,
-				AST:     tt.fields.AST,
-				Query:   tt.fields.Query,
-				Type:    tt.fields.Type,
-				Dialect: tt.fields.Dialect,
-				Org:     tt.fields.org,
+				Extern:        tt.fields.Extern,
+				AST:           tt.fields.AST,
+				Query:         tt.fields.Query,
+				Type:          tt.fields.Type,
+				Dialect:       tt.fields.Dialect,
+				Org:           tt.fields.org,
+				MaxResultSize: tt.fields.MaxResultSize,
+				ChunkRowCount: tt.fields.ChunkRowCount,
+				Chunk:         tt.fields.Chunk,
 			}
 			if err := r.Validate(); (err != nil) != tt.wantErr {
 				t.Errorf("QueryRequest.Validate() error = %v, wantErr %v", err, tt.wantErr)
@@ -198,6 +297,9 @@ func TestQueryRequest_proxyRequest(t *testing.T) {
 		Dialect QueryDialect
 		Now     time.Time
 		org     *platform.Organization
+
+		MaxResultSize int64
+		ChunkRowCount int64
 	}
 	tests := []struct {
 		name    string
@@ -240,6 +342,62 @@ func TestQueryRequest_proxyRequest(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "valid limited query",
+			fields: fields{
+				Query: "howdy",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				org:           &platform.Organization{},
+				MaxResultSize: 1 << 20,
+				ChunkRowCount: 1000,
+			},
+			now: func() time.Time { return time.Unix(1, 1) },
+			want: &query.ProxyRequest{
+				Request: query.Request{
+					Compiler: lang.FluxCompiler{
+						Now:   time.Unix(1, 1),
+						Query: `howdy`,
+					},
+				},
+				Dialect: &query.LimitedDialect{
+					ResultEncoderConfig: csv.ResultEncoderConfig{
+						NoHeader:  false,
+						Delimiter: ',',
+					},
+					Limits: query.ResultLimits{
+						MaxBytes:  1 << 20,
+						ChunkRows: 1000,
+					},
+				},
+			},
+		},
+		{
+			name: "valid parquet query",
+			fields: fields{
+				Query: "howdy",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+					Format:         "parquet",
+				},
+				org: &platform.Organization{},
+			},
+			now: func() time.Time { return time.Unix(1, 1) },
+			want: &query.ProxyRequest{
+				Request: query.Request{
+					Compiler: lang.FluxCompiler{
+						Now:   time.Unix(1, 1),
+						Query: `howdy`,
+					},
+				},
+				Dialect: &query.ParquetDialect{},
+			},
+		},
 		{
 			name: "valid AST",
 			fields: fields{
@@ -346,13 +504,15 @@ func TestQueryRequest_proxyRequest(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := QueryRequest{
-				Extern:  tt.fields.Extern,
-				AST:     tt.fields.AST,
-				Query:   tt.fields.Query,
-				Type:    tt.fields.Type,
-				Dialect: tt.fields.Dialect,
-				Now:     tt.fields.Now,
-				Org:     tt.fields.org,
+				Extern:        tt.fields.Extern,
+				AST:           tt.fields.AST,
+				Query:         tt.fields.Query,
+				Type:          tt.fields.Type,
+				Dialect:       tt.fields.Dialect,
+				Now:           tt.fields.Now,
+				Org:           tt.fields.org,
+				MaxResultSize: tt.fields.MaxResultSize,
+				ChunkRowCount: tt.fields.ChunkRowCount,
 			}
 			got, err := r.proxyRequest(tt.now)
 			if (err != nil) != tt.wantErr {
@@ -374,6 +534,152 @@ func mustMarshal(p ast.Node) []byte {
 	return bs
 }
 
+func TestQueryRequest_proxyRequest_params(t *testing.T) {
+	svc := &mock.OrganizationService{
+		FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+			return &platform.Organization{ID: 1}, nil
+		},
+	}
+	extern := func(name string) string {
+		return string(mustMarshal(&ast.File{
+			Body: []ast.Statement{
+				&ast.OptionStatement{
+					Assignment: &ast.VariableAssignment{
+						ID:   &ast.Identifier{Name: name},
+						Init: &ast.IntegerLiteral{Value: 0},
+					},
+				},
+			},
+		}))
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "params",
+			body: `{"query": "from(bucket: params.bucket)", "params": {"bucket": "telegraf", "host": "a\") |> drop("}}`,
+			want: `option params = {bucket: "telegraf", host: "a\") |> drop("}`,
+		},
+		{
+			name: "numbers and booleans",
+			body: `{"query": "from(bucket: \"b\")", "params": {"n": 3, "f": 1.0, "ok": true}}`,
+			want: `option params = {f: 1.0, n: 3, ok: true}`,
+		},
+		{
+			name: "times and durations",
+			body: `{"query": "from(bucket: \"b\")", "params": {"start": "2020-06-01T00:00:00Z", "stop": "-1h"}}`,
+			want: `option params = {start: 2020-06-01T00:00:00Z, stop: -1h}`,
+		},
+		{
+			name: "arrays and records",
+			body: `{"query": "from(bucket: \"b\")", "params": {"hosts": ["a", "b"], "tags": {"region": "eu", "data-center": "x"}}}`,
+			want: `option params = {hosts: ["a", "b"], tags: {"data-center": "x", region: "eu"}}`,
+		},
+		{
+			name: "params and extern",
+			body: `{"query": "from(bucket: \"b\")", "extern": ` + extern("x") + `, "params": {"n": 1}}`,
+			want: "option x = 0\noption params = {n: 1}",
+		},
+		{
+			name:    "params set by extern",
+			body:    `{"query": "from(bucket: \"b\")", "extern": ` + extern("params") + `, "params": {"n": 1}}`,
+			wantErr: true,
+		},
+		{
+			name:    "null param",
+			body:    `{"query": "from(bucket: \"b\")", "params": {"n": null}}`,
+			wantErr: true,
+		},
+		{
+			name:    "influxql",
+			body:    `{"query": "SELECT * FROM cpu", "type": "influxql", "bucket": "b", "params": {"n": 1}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			req, _, err := decodeQueryRequest(context.Background(), r, svc)
+			if err != nil {
+				if !tt.wantErr {
+					t.Fatal(err)
+				}
+				return
+			}
+			pr, err := req.ProxyRequest()
+			if tt.wantErr {
+				if platform.ErrorCode(err) != platform.EInvalid {
+					t.Fatalf("expected an invalid request, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c, ok := pr.Request.Compiler.(lang.FluxCompiler)
+			if !ok {
+				t.Fatalf("unexpected compiler %T", pr.Request.Compiler)
+			}
+			var file ast.File
+			if err := json.Unmarshal(c.Extern, &file); err != nil {
+				t.Fatal(err)
+			}
+			if got := ast.Format(&file); got != tt.want {
+				t.Errorf("unexpected extern -want/+got:\n\t- %s\n\t+ %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestQueryRequest_proxyRequest_asOf(t *testing.T) {
+	svc := &mock.OrganizationService{
+		FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+			return &platform.Organization{ID: 1}, nil
+		},
+	}
+
+	body := `{"query": "from(bucket: \"b\")", "now": "2020-09-01T02:00:00Z", "asOf": "2020-09-01T02:00:05Z"}`
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req, _, err := decodeQueryRequest(context.Background(), r, svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := req.ProxyRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := time.Date(2020, 9, 1, 2, 0, 5, 0, time.UTC); pr.Request.AsOf == nil || !pr.Request.AsOf.Equal(want) {
+		t.Errorf("expected bucket names to be resolved as of %v, got %v", want, pr.Request.AsOf)
+	}
+	c, ok := pr.Request.Compiler.(lang.FluxCompiler)
+	if !ok {
+		t.Fatalf("unexpected compiler %T", pr.Request.Compiler)
+	}
+	if want := time.Date(2020, 9, 1, 2, 0, 0, 0, time.UTC); !c.Now.Equal(want) {
+		t.Errorf("expected now to be %v, got %v", want, c.Now)
+	}
+
+	qr, err := QueryRequestFromProxyRequest(pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(qr.AsOf, req.AsOf) {
+		t.Errorf("expected asOf to be copied, got %v", qr.AsOf)
+	}
+
+	body = `{"query": "SELECT * FROM cpu", "type": "influxql", "bucket": "b", "asOf": "2020-09-01T02:00:05Z"}`
+	r = httptest.NewRequest("POST", "/", strings.NewReader(body))
+	if _, _, err := decodeQueryRequest(context.Background(), r, svc); err == nil {
+		t.Error("expected asOf to be rejected for influxql queries")
+	}
+}
+
 func Test_decodeQueryRequest(t *testing.T) {
 	type args struct {
 		ctx context.Context
@@ -83,7 +83,13 @@ func (h baseHandler) methodNotAllowed(w http.ResponseWriter, r *http.Request) {
 
 // panic handles panics recovered from http handlers.
 // It returns a json response with http status code 500 and the recovered error message.
+// The panic is handled by the Recoverer of the request if there is one.
 func (h baseHandler) panic(w http.ResponseWriter, r *http.Request, rcv interface{}) {
+	if rc, ok := kithttp.RecovererFromContext(r.Context()); ok {
+		rc.Recover(w, r, rcv)
+		return
+	}
+
 	ctx := r.Context()
 	pe := &platform.Error{
 		Code: platform.EInternal,
@@ -108,6 +114,10 @@ func panicMW(api *kithttp.API) func(http.Handler) http.Handler {
 				if panicErr == nil {
 					return
 				}
+				if rc, ok := kithttp.RecovererFromContext(r.Context()); ok {
+					rc.Recover(w, r, panicErr)
+					return
+				}
 
 				pe := &platform.Error{
 					Code: platform.EInternal,
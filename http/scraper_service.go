@@ -606,7 +606,7 @@ func (h *ScraperHandler) newTargetResponse(ctx context.Context, target influxdb.
 			Members: fmt.Sprintf("/api/v2/scrapers/%s/members", target.ID),
 			Owners:  fmt.Sprintf("/api/v2/scrapers/%s/owners", target.ID),
 		},
-		ScraperTarget: target,
+		ScraperTarget: target.Redacted(),
 	}
 	bucket, err := h.BucketService.FindBucketByID(ctx, target.BucketID)
 	if err == nil {
@@ -15,7 +15,6 @@ import (
 	"github.com/influxdata/influxdb/v2"
 	pcontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
-	"github.com/influxdata/influxdb/v2/kv"
 	"github.com/influxdata/influxdb/v2/pkg/httpc"
 	"go.uber.org/zap"
 )
@@ -34,6 +33,9 @@ type TaskBackend struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	BucketService              influxdb.BucketService
+	// TaskSLAService reports the scheduling service level of the tasks.
+	// It is not reported when nil.
+	TaskSLAService influxdb.TaskSLAService
 }
 
 // NewTaskBackend returns a new instance of TaskBackend.
@@ -49,6 +51,7 @@ func NewTaskBackend(log *zap.Logger, b *APIBackend) *TaskBackend {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		BucketService:              b.BucketService,
+		TaskSLAService:             b.TaskSLAService,
 	}
 }
 
@@ -65,6 +68,7 @@ type TaskHandler struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	BucketService              influxdb.BucketService
+	TaskSLAService             influxdb.TaskSLAService
 }
 
 const (
@@ -81,6 +85,7 @@ const (
 	tasksIDRunsIDRetryPath = "/api/v2/tasks/:id/runs/:rid/retry"
 	tasksIDLabelsPath      = "/api/v2/tasks/:id/labels"
 	tasksIDLabelsIDPath    = "/api/v2/tasks/:id/labels/:lid"
+	tasksIDSLAPath         = "/api/v2/tasks/:id/sla"
 )
 
 // NewTaskHandler returns a new instance of TaskHandler.
@@ -97,6 +102,7 @@ func NewTaskHandler(log *zap.Logger, b *TaskBackend) *TaskHandler {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		BucketService:              b.BucketService,
+		TaskSLAService:             b.TaskSLAService,
 	}
 
 	h.HandlerFunc("GET", prefixTasks, h.handleGetTasks)
@@ -139,6 +145,10 @@ func NewTaskHandler(log *zap.Logger, b *TaskBackend) *TaskHandler {
 	h.HandlerFunc("POST", tasksIDRunsIDRetryPath, h.handleRetryRun)
 	h.HandlerFunc("DELETE", tasksIDRunsIDPath, h.handleCancelRun)
 
+	if h.TaskSLAService != nil {
+		h.HandlerFunc("GET", tasksIDSLAPath, h.handleGetTaskSLA)
+	}
+
 	labelBackend := &LabelBackend{
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              b.log.With(zap.String("handler", "label")),
@@ -465,15 +475,16 @@ func decodeGetTasksRequest(ctx context.Context, r *http.Request, orgs influxdb.O
 		req.filter.After = id
 	}
 
-	if orgName := qp.Get("org"); orgName != "" {
-		o, err := orgs.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &orgName})
+	if orgID, org := qp.Get("orgID"), qp.Get("org"); orgID != "" || org != "" {
+		o, err := influxdb.ResolveOrganization(ctx, orgs, orgID, org)
 		if err != nil {
-			if pErr, ok := err.(*influxdb.Error); ok && pErr != nil {
-				if kv.IsNotFound(err) || pErr.Code == influxdb.EUnauthorized {
-					return nil, &influxdb.Error{
-						Err: errors.New("org not found or unauthorized"),
-						Msg: "org " + orgName + " not found or unauthorized",
-					}
+			if code := influxdb.ErrorCode(err); code == influxdb.ENotFound || code == influxdb.EUnauthorized {
+				if orgID != "" {
+					org = orgID
+				}
+				return nil, &influxdb.Error{
+					Err: errors.New("org not found or unauthorized"),
+					Msg: "org " + org + " not found or unauthorized",
 				}
 			}
 			return nil, err
@@ -481,13 +492,6 @@ func decodeGetTasksRequest(ctx context.Context, r *http.Request, orgs influxdb.O
 		req.filter.Organization = o.Name
 		req.filter.OrganizationID = &o.ID
 	}
-	if oid := qp.Get("orgID"); oid != "" {
-		orgID, err := influxdb.IDFromString(oid)
-		if err != nil {
-			return nil, err
-		}
-		req.filter.OrganizationID = orgID
-	}
 
 	if userID := qp.Get("user"); userID != "" {
 		id, err := influxdb.IDFromString(userID)
@@ -684,6 +688,64 @@ func decodeGetTaskRequest(ctx context.Context, r *http.Request) (*getTaskRequest
 	return req, nil
 }
 
+type taskSLAResponse struct {
+	TaskID             influxdb.ID `json:"taskID"`
+	LastScheduledFor   time.Time   `json:"lastScheduledFor"`
+	Runs               int64       `json:"runs"`
+	LatenessSeconds    float64     `json:"latenessSeconds"`
+	MaxLatenessSeconds float64     `json:"maxLatenessSeconds"`
+	MissedIntervals    int64       `json:"missedIntervals"`
+	QueueWaitSeconds   float64     `json:"queueWaitSeconds"`
+}
+
+func newTaskSLAResponse(sla *influxdb.TaskSLA) taskSLAResponse {
+	return taskSLAResponse{
+		TaskID:             sla.TaskID,
+		LastScheduledFor:   sla.LastScheduledFor,
+		Runs:               sla.Runs,
+		LatenessSeconds:    sla.Lateness.Seconds(),
+		MaxLatenessSeconds: sla.MaxLateness.Seconds(),
+		MissedIntervals:    sla.MissedIntervals,
+		QueueWaitSeconds:   sla.QueueWait.Seconds(),
+	}
+}
+
+// handleGetTaskSLA is the HTTP handler for the GET /api/v2/tasks/:id/sla route.
+func (h *TaskHandler) handleGetTaskSLA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeGetTaskRequest(ctx, r)
+	if err != nil {
+		err = &influxdb.Error{
+			Err:  err,
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	// finding the task authorizes reading its service level
+	if _, err := h.TaskService.FindTaskByID(ctx, req.TaskID); err != nil {
+		err = &influxdb.Error{
+			Err:  err,
+			Code: influxdb.ENotFound,
+			Msg:  "failed to find task",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	sla, err := h.TaskSLAService.FindTaskSLA(ctx, req.TaskID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskSLAResponse(sla)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
 func (h *TaskHandler) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	req, err := decodeUpdateTaskRequest(ctx, r)
@@ -901,6 +963,32 @@ func decodeGetLogsRequest(ctx context.Context, r *http.Request) (*getLogsRequest
 		req.filter.Run = id
 	}
 
+	qp := r.URL.Query()
+	if offset := qp.Get("offset"); offset != "" {
+		i, err := strconv.Atoi(offset)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "offset must not be negative",
+			}
+		}
+		req.filter.Offset = i
+	}
+
+	if limit := qp.Get("limit"); limit != "" {
+		i, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, err
+		}
+		if i < 1 || i > influxdb.TaskMaxPageSize {
+			return nil, influxdb.ErrOutOfBoundsLimit
+		}
+		req.filter.Limit = i
+	}
+
 	return req, nil
 }
 
@@ -1363,7 +1451,7 @@ func (h *TaskHandler) populateTaskCreateOrg(ctx context.Context, tc *influxdb.Ta
 	}
 
 	if !tc.OrganizationID.Valid() && tc.Organization == "" {
-		return errors.New("missing orgID and organization name")
+		return influxdb.ErrInvalidOrgFilter
 	}
 
 	if tc.OrganizationID.Valid() {
@@ -1373,10 +1461,11 @@ func (h *TaskHandler) populateTaskCreateOrg(ctx context.Context, tc *influxdb.Ta
 		}
 		tc.Organization = o.Name
 	} else {
-		o, err := h.OrganizationService.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &tc.Organization})
+		o, err := influxdb.ResolveOrganization(ctx, h.OrganizationService, "", tc.Organization)
 		if err != nil {
 			return err
 		}
+		tc.Organization = o.Name
 		tc.OrganizationID = o.ID
 	}
 	return nil
@@ -1536,9 +1625,22 @@ func (t TaskService) FindLogs(ctx context.Context, filter influxdb.LogFilter) ([
 		urlPath = path.Join(taskIDRunIDPath(filter.Task, *filter.Run), "logs")
 	}
 
+	if filter.Limit < 0 || filter.Limit > influxdb.TaskMaxPageSize {
+		return nil, 0, influxdb.ErrOutOfBoundsLimit
+	}
+
+	var params [][2]string
+	if filter.Offset > 0 {
+		params = append(params, [2]string{"offset", strconv.Itoa(filter.Offset)})
+	}
+	if filter.Limit > 0 {
+		params = append(params, [2]string{"limit", strconv.Itoa(filter.Limit)})
+	}
+
 	var logs getLogsResponse
 	err := t.Client.
 		Get(urlPath).
+		QueryParams(params...).
 		DecodeJSON(&logs).
 		Do(ctx)
 
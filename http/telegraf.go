@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"strings"
 
@@ -216,7 +217,7 @@ func decodeGetTelegrafRequest(ctx context.Context) (i influxdb.ID, err error) {
 
 func (h *TelegrafHandler) handleGetTelegrafs(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	filter, err := decodeTelegrafConfigFilter(ctx, r)
+	filter, err := decodeTelegrafConfigFilter(ctx, r, h.OrganizationService)
 	if err != nil {
 		h.log.Debug("Failed to decode request", zap.Error(err))
 		h.HandleHTTPError(ctx, err, w)
@@ -276,7 +277,10 @@ func (h *TelegrafHandler) handleGetTelegraf(w http.ResponseWriter, r *http.Reque
 	}
 }
 
-func decodeTelegrafConfigFilter(ctx context.Context, r *http.Request) (*influxdb.TelegrafConfigFilter, error) {
+// decodeTelegrafConfigFilter decodes the filter of the telegraf configs to
+// list. Their organization is identified by orgID, or else by org, its ID or
+// name.
+func decodeTelegrafConfigFilter(ctx context.Context, r *http.Request, orgs influxdb.OrganizationService) (*influxdb.TelegrafConfigFilter, error) {
 	f := &influxdb.TelegrafConfigFilter{}
 	urm, err := decodeUserResourceMappingFilter(ctx, r, influxdb.TelegrafsResourceType)
 	if err == nil {
@@ -284,18 +288,13 @@ func decodeTelegrafConfigFilter(ctx context.Context, r *http.Request) (*influxdb
 	}
 
 	q := r.URL.Query()
-	if orgIDStr := q.Get("orgID"); orgIDStr != "" {
-		orgID, err := influxdb.IDFromString(orgIDStr)
+	if orgID, org := q.Get("orgID"), q.Get("org"); orgID != "" || org != "" {
+		o, err := influxdb.ResolveOrganization(ctx, orgs, orgID, org)
 		if err != nil {
-			return f, &influxdb.Error{
-				Code: influxdb.EInvalid,
-				Msg:  "orgID is invalid",
-				Err:  err,
-			}
+			return f, err
 		}
-		f.OrgID = orgID
-	} else if orgNameStr := q.Get("org"); orgNameStr != "" {
-		f.Organization = &orgNameStr
+		f.OrgID = &o.ID
+		f.Organization = &o.Name
 	}
 	return f, err
 }
@@ -304,8 +303,8 @@ func decodeTelegrafConfigFilter(ctx context.Context, r *http.Request) (*influxdb
 func (h *TelegrafHandler) handlePostTelegraf(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	tc := new(influxdb.TelegrafConfig)
-	if err := json.NewDecoder(r.Body).Decode(tc); err != nil {
+	tc, err := h.decodePostTelegrafRequest(ctx, r)
+	if err != nil {
 		h.log.Debug("Failed to decode request", zap.Error(err))
 		h.HandleHTTPError(ctx, err, w)
 		return
@@ -329,6 +328,36 @@ func (h *TelegrafHandler) handlePostTelegraf(w http.ResponseWriter, r *http.Requ
 	}
 }
 
+// decodePostTelegrafRequest decodes a telegraf config to create. Its
+// organization is identified by orgID, or else by org, its ID or name.
+func (h *TelegrafHandler) decodePostTelegrafRequest(ctx context.Context, r *http.Request) (*influxdb.TelegrafConfig, error) {
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	tc := new(influxdb.TelegrafConfig)
+	if err := json.Unmarshal(b, tc); err != nil {
+		return nil, err
+	}
+	if tc.OrgID.Valid() {
+		return tc, nil
+	}
+
+	var req struct {
+		Org string `json:"org"`
+	}
+	if err := json.Unmarshal(b, &req); err != nil {
+		return nil, err
+	}
+	org, err := influxdb.ResolveOrganization(ctx, h.OrganizationService, "", req.Org)
+	if err != nil {
+		return nil, err
+	}
+	tc.OrgID = org.ID
+	return tc, nil
+}
+
 func decodePutTelegrafRequest(ctx context.Context, r *http.Request) (*influxdb.TelegrafConfig, error) {
 	tc := new(influxdb.TelegrafConfig)
 	if err := json.NewDecoder(r.Body).Decode(tc); err != nil {
@@ -414,7 +443,8 @@ func NewTelegrafService(httpClient *httpc.Client) *TelegrafService {
 	return &TelegrafService{
 		client: httpClient,
 		UserResourceMappingService: &UserResourceMappingService{
-			Client: httpClient,
+			Client:       httpClient,
+			ResourceType: influxdb.TelegrafsResourceType,
 		},
 	}
 }
@@ -24,7 +24,14 @@ func NewMockTelegrafBackend(t *testing.T) *TelegrafBackend {
 		UserResourceMappingService: mock.NewUserResourceMappingService(),
 		LabelService:               mock.NewLabelService(),
 		UserService:                mock.NewUserService(),
-		OrganizationService:        mock.NewOrganizationService(),
+		OrganizationService: &mock.OrganizationService{
+			FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+				if (filter.ID != nil && *filter.ID == platform.ID(2)) || (filter.Name != nil && *filter.Name == "tc1") {
+					return &platform.Organization{ID: platform.ID(2), Name: "tc1"}, nil
+				}
+				return nil, &platform.Error{Code: platform.ENotFound, Msg: "organization not found"}
+			},
+		},
 	}
 }
 
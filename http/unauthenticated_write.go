@@ -0,0 +1,107 @@
+package http
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// UnauthenticatedWriteHandler accepts writes without a token, for the edge
+// devices where distributing tokens is impractical. It is meant to be served
+// on its own listener, bound to an interface only reachable by the devices:
+// the writes are only accepted from the allowed networks and to the allowed
+// buckets, and every write is counted.
+type UnauthenticatedWriteHandler struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	write    *WriteHandler
+	auth     *influxdb.Authorization
+	networks []*net.IPNet
+	policy   authorizer.PolicyEngine
+
+	requests *prometheus.CounterVec
+}
+
+// NewUnauthenticatedWriteHandler returns a handler of the writes without a
+// token from networks to buckets.
+func NewUnauthenticatedWriteHandler(log *zap.Logger, b *APIBackend, buckets []influxdb.ID, networks []*net.IPNet) *UnauthenticatedWriteHandler {
+	permissions := make([]influxdb.Permission, 0, len(buckets))
+	for i := range buckets {
+		permissions = append(permissions, influxdb.Permission{
+			Action: influxdb.WriteAction,
+			Resource: influxdb.Resource{
+				Type: influxdb.BucketsResourceType,
+				ID:   &buckets[i],
+			},
+		})
+	}
+
+	return &UnauthenticatedWriteHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+		write:            NewWriteHandler(log, NewWriteBackend(log, b), b.writeHandlerOptions()...),
+		auth: &influxdb.Authorization{
+			Status:      influxdb.Active,
+			Description: "unauthenticated write",
+			Permissions: permissions,
+		},
+		networks: networks,
+		policy:   b.PolicyEngine,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "http",
+			Subsystem: "unauthenticated_write",
+			Name:      "requests_total",
+			Help:      "Number of writes without a token, by status code class.",
+		}, []string{"status"}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (h *UnauthenticatedWriteHandler) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{h.requests}
+}
+
+func (h *UnauthenticatedWriteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	sw := kithttp.NewStatusResponseWriter(w)
+	defer func() {
+		h.requests.WithLabelValues(sw.StatusCodeClass()).Inc()
+	}()
+
+	ctx := r.Context()
+	if !h.allowed(r.RemoteAddr) {
+		h.log.Warn("Rejected unauthenticated write from a network that is not allowed", zap.String("remoteAddr", r.RemoteAddr))
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  "writes without a token are not allowed from this address",
+		}, sw)
+		return
+	}
+
+	ctx = authorizer.WithPolicyEngine(pcontext.SetAuthorizer(ctx, h.auth), h.policy)
+	h.write.ServeHTTP(sw, r.WithContext(ctx))
+}
+
+// allowed returns whether writes are allowed from the remote address addr.
+func (h *UnauthenticatedWriteHandler) allowed(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, n := range h.networks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http/metric"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxtesting "github.com/influxdata/influxdb/v2/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestUnauthenticatedWriteHandler(t *testing.T) {
+	const (
+		org     = "043e0780ee2b1000"
+		allowed = "04504b356e23b000"
+		denied  = "04504b356e23b001"
+	)
+
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return testOrg(org), nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(_ context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		return testBucket(org, filter.ID.String()), nil
+	}
+	pw := &mock.PointsWriter{}
+
+	_, loopback, err := net.ParseCIDR("127.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewUnauthenticatedWriteHandler(zaptest.NewLogger(t), &APIBackend{
+		HTTPErrorHandler:    DefaultErrorHandler,
+		Logger:              zaptest.NewLogger(t),
+		OrganizationService: orgs,
+		BucketService:       buckets,
+		PointsWriter:        pw,
+		WriteEventRecorder:  &metric.NopEventRecorder{},
+	}, []influxdb.ID{influxtesting.MustIDBase16(allowed)}, []*net.IPNet{loopback})
+
+	tests := []struct {
+		name       string
+		bucket     string
+		remoteAddr string
+		code       int
+	}{
+		{
+			name:       "allowed bucket from an allowed network",
+			bucket:     allowed,
+			remoteAddr: "127.0.0.1:52000",
+			code:       204,
+		},
+		{
+			name:       "bucket that is not allowed",
+			bucket:     denied,
+			remoteAddr: "127.0.0.1:52000",
+			code:       403,
+		},
+		{
+			name:       "network that is not allowed",
+			bucket:     allowed,
+			remoteAddr: "10.0.0.1:52000",
+			code:       403,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/v2/write?org="+org+"&bucket="+tt.bucket, strings.NewReader("cpu,host=a usage=1"))
+			r.RemoteAddr = tt.remoteAddr
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.code {
+				t.Errorf("unexpected status code: got %d want %d: %s", w.Code, tt.code, w.Body.String())
+			}
+		})
+	}
+
+	if len(pw.Points) != 1 {
+		t.Errorf("expected only the allowed write to be written, got %d points", len(pw.Points))
+	}
+}
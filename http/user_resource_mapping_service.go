@@ -9,6 +9,7 @@ import (
 
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/pkg/httpc"
 	"go.uber.org/zap"
 )
@@ -58,6 +59,34 @@ type MemberBackend struct {
 }
 
 // newPostMemberHandler returns a handler func for a POST to /members or /owners endpoints
+// RestrictedURMService marks the user resource mappings it creates on the
+// resources restricted to their members and owners, which give permissions on
+// the resources themselves.
+type RestrictedURMService struct {
+	influxdb.UserResourceMappingService
+	restricted func(ctx context.Context, id influxdb.ID) (bool, error)
+}
+
+// NewRestrictedURMService wraps s. restricted returns whether the resource
+// with the given ID is restricted.
+func NewRestrictedURMService(s influxdb.UserResourceMappingService, restricted func(ctx context.Context, id influxdb.ID) (bool, error)) *RestrictedURMService {
+	return &RestrictedURMService{
+		UserResourceMappingService: s,
+		restricted:                 restricted,
+	}
+}
+
+// CreateUserResourceMapping creates a user resource mapping, restricted if its
+// resource is.
+func (s *RestrictedURMService) CreateUserResourceMapping(ctx context.Context, m *influxdb.UserResourceMapping) error {
+	restricted, err := s.restricted(ctx, m.ResourceID)
+	if err != nil {
+		return err
+	}
+	m.Restricted = restricted
+	return s.UserResourceMappingService.CreateUserResourceMapping(ctx, m)
+}
+
 func newPostMemberHandler(b MemberBackend) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
@@ -213,6 +242,9 @@ func newDeleteMemberHandler(b MemberBackend) http.HandlerFunc {
 			return
 		}
 
+		if req.Force {
+			ctx = icontext.SetForceOwnerRemoval(ctx)
+		}
 		if err := b.UserResourceMappingService.DeleteUserResourceMapping(ctx, req.ResourceID, req.MemberID); err != nil {
 			b.HandleHTTPError(ctx, err, w)
 			return
@@ -226,6 +258,7 @@ func newDeleteMemberHandler(b MemberBackend) http.HandlerFunc {
 type deleteMemberRequest struct {
 	MemberID   influxdb.ID
 	ResourceID influxdb.ID
+	Force      bool
 }
 
 func decodeDeleteMemberRequest(ctx context.Context, r *http.Request) (*deleteMemberRequest, error) {
@@ -259,12 +292,16 @@ func decodeDeleteMemberRequest(ctx context.Context, r *http.Request) (*deleteMem
 	return &deleteMemberRequest{
 		MemberID:   mid,
 		ResourceID: rid,
+		Force:      r.URL.Query().Get("force") == "true",
 	}, nil
 }
 
 // UserResourceMappingService is the struct of urm service
 type UserResourceMappingService struct {
 	Client *httpc.Client
+	// ResourceType is the type of the resources of the mappings deleted by
+	// DeleteUserResourceMapping. It defaults to organizations.
+	ResourceType influxdb.ResourceType
 }
 
 // FindUserResourceMappings returns the user resource mappings
@@ -303,9 +340,32 @@ func (s *UserResourceMappingService) CreateUserResourceMapping(ctx context.Conte
 		Do(ctx)
 }
 
-// DeleteUserResourceMapping will delete user resource mapping based in criteria.
+// DeleteUserResourceMapping deletes the mapping of the user to the resource
+// of type s.ResourceType. Use DeleteMapping to delete the mappings of
+// resources of other types.
 func (s *UserResourceMappingService) DeleteUserResourceMapping(ctx context.Context, resourceID influxdb.ID, userID influxdb.ID) error {
-	urlPath := resourceIDUserPath(influxdb.OrgsResourceType, resourceID, influxdb.Member, userID)
+	rt := s.ResourceType
+	if rt == "" {
+		rt = influxdb.OrgsResourceType
+	}
+	// The mapping is deleted whatever the user type of the path, so owners
+	// are deleted through the members path too.
+	return s.DeleteMapping(ctx, &influxdb.UserResourceMapping{
+		ResourceType: rt,
+		ResourceID:   resourceID,
+		UserType:     influxdb.Member,
+		UserID:       userID,
+	})
+}
+
+// DeleteMapping deletes the mapping m of the user to the resource, using its
+// resource type and user type.
+func (s *UserResourceMappingService) DeleteMapping(ctx context.Context, m *influxdb.UserResourceMapping) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	urlPath := resourceIDUserPath(m.ResourceType, m.ResourceID, m.UserType, m.UserID)
 	return s.Client.
 		Delete(urlPath).
 		Do(ctx)
@@ -8,6 +8,7 @@ import (
 
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
 	"github.com/influxdata/influxdb/v2/pkg/httpc"
 	"go.uber.org/zap"
 )
@@ -23,6 +24,9 @@ type VariableBackend struct {
 	log             *zap.Logger
 	VariableService influxdb.VariableService
 	LabelService    influxdb.LabelService
+
+	UserResourceMappingService influxdb.UserResourceMappingService
+	UserService                influxdb.UserService
 }
 
 // NewVariableBackend creates a backend used by the variable handler.
@@ -32,6 +36,9 @@ func NewVariableBackend(log *zap.Logger, b *APIBackend) *VariableBackend {
 		log:              log,
 		VariableService:  b.VariableService,
 		LabelService:     b.LabelService,
+
+		UserResourceMappingService: b.UserResourceMappingService,
+		UserService:                b.UserService,
 	}
 }
 
@@ -44,6 +51,9 @@ type VariableHandler struct {
 
 	VariableService influxdb.VariableService
 	LabelService    influxdb.LabelService
+
+	UserResourceMappingService influxdb.UserResourceMappingService
+	UserService                influxdb.UserService
 }
 
 // NewVariableHandler creates a new VariableHandler
@@ -55,11 +65,18 @@ func NewVariableHandler(log *zap.Logger, b *VariableBackend) *VariableHandler {
 
 		VariableService: b.VariableService,
 		LabelService:    b.LabelService,
+
+		UserResourceMappingService: b.UserResourceMappingService,
+		UserService:                b.UserService,
 	}
 
 	entityPath := fmt.Sprintf("%s/:id", prefixVariables)
 	entityLabelsPath := fmt.Sprintf("%s/labels", entityPath)
 	entityLabelsIDPath := fmt.Sprintf("%s/:lid", entityLabelsPath)
+	entityMembersPath := fmt.Sprintf("%s/members", entityPath)
+	entityMembersIDPath := fmt.Sprintf("%s/:userID", entityMembersPath)
+	entityOwnersPath := fmt.Sprintf("%s/owners", entityPath)
+	entityOwnersIDPath := fmt.Sprintf("%s/:userID", entityOwnersPath)
 
 	h.HandlerFunc("GET", prefixVariables, h.handleGetVariables)
 	h.HandlerFunc("POST", prefixVariables, h.handlePostVariable)
@@ -74,13 +91,71 @@ func NewVariableHandler(log *zap.Logger, b *VariableBackend) *VariableHandler {
 		LabelService:     b.LabelService,
 		ResourceType:     influxdb.VariablesResourceType,
 	}
-	h.HandlerFunc("GET", entityLabelsPath, newGetLabelsHandler(labelBackend))
-	h.HandlerFunc("POST", entityLabelsPath, newPostLabelHandler(labelBackend))
-	h.HandlerFunc("DELETE", entityLabelsIDPath, newDeleteLabelHandler(labelBackend))
+	h.HandlerFunc("GET", entityLabelsPath, h.authorizeRestricted(newGetLabelsHandler(labelBackend)))
+	h.HandlerFunc("POST", entityLabelsPath, h.authorizeRestricted(newPostLabelHandler(labelBackend)))
+	h.HandlerFunc("DELETE", entityLabelsIDPath, h.authorizeRestricted(newDeleteLabelHandler(labelBackend)))
+
+	urmService := NewRestrictedURMService(b.UserResourceMappingService, func(ctx context.Context, id influxdb.ID) (bool, error) {
+		v, err := b.VariableService.FindVariableByID(ctx, id)
+		if err != nil || v == nil {
+			return false, err
+		}
+		return v.Restricted, nil
+	})
+
+	memberBackend := MemberBackend{
+		HTTPErrorHandler:           b.HTTPErrorHandler,
+		log:                        b.log.With(zap.String("handler", "member")),
+		ResourceType:               influxdb.VariablesResourceType,
+		UserType:                   influxdb.Member,
+		UserResourceMappingService: urmService,
+		UserService:                b.UserService,
+	}
+	h.HandlerFunc("POST", entityMembersPath, h.authorizeRestricted(newPostMemberHandler(memberBackend)))
+	h.HandlerFunc("GET", entityMembersPath, h.authorizeRestricted(newGetMembersHandler(memberBackend)))
+	h.HandlerFunc("DELETE", entityMembersIDPath, h.authorizeRestricted(newDeleteMemberHandler(memberBackend)))
+
+	ownerBackend := MemberBackend{
+		HTTPErrorHandler:           b.HTTPErrorHandler,
+		log:                        b.log.With(zap.String("handler", "member")),
+		ResourceType:               influxdb.VariablesResourceType,
+		UserType:                   influxdb.Owner,
+		UserResourceMappingService: urmService,
+		UserService:                b.UserService,
+	}
+	h.HandlerFunc("POST", entityOwnersPath, h.authorizeRestricted(newPostMemberHandler(ownerBackend)))
+	h.HandlerFunc("GET", entityOwnersPath, h.authorizeRestricted(newGetMembersHandler(ownerBackend)))
+	h.HandlerFunc("DELETE", entityOwnersIDPath, h.authorizeRestricted(newDeleteMemberHandler(ownerBackend)))
 
 	return h
 }
 
+// authorizeRestricted wraps a handler of the members, owners or labels of a
+// variable. Those of a restricted variable are only visible to the users who
+// can read it, and only changed by the users who can edit it.
+func (h *VariableHandler) authorizeRestricted(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := requestVariableID(ctx)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		v, err := h.VariableService.FindVariableByID(ctx, id)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		if v != nil && v.Restricted && r.Method != http.MethodGet {
+			if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.VariablesResourceType, v.ID, v.OrganizationID); err != nil {
+				h.HandleHTTPError(ctx, err, w)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
 type getVariablesResponse struct {
 	Variables []variableResponse    `json:"variables"`
 	Links     *influxdb.PagingLinks `json:"links"`
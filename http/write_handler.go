@@ -3,20 +3,27 @@ package http
 import (
 	"compress/gzip"
 	"context"
+	"crypto/subtle"
+	"encoding/csv"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
 	pcontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/http/metric"
 	kitio "github.com/influxdata/influxdb/v2/kit/io"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/csv2lp"
 	"github.com/influxdata/influxdb/v2/storage"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	"github.com/opentracing/opentracing-go"
@@ -41,6 +48,7 @@ type WriteBackend struct {
 	PointsWriter        storage.PointsWriter
 	BucketService       influxdb.BucketService
 	OrganizationService influxdb.OrganizationService
+	WriteRoutingService influxdb.WriteRoutingService
 }
 
 // NewWriteBackend returns a new instance of WriteBackend.
@@ -53,6 +61,7 @@ func NewWriteBackend(log *zap.Logger, b *APIBackend) *WriteBackend {
 		PointsWriter:        b.PointsWriter,
 		BucketService:       b.BucketService,
 		OrganizationService: b.OrganizationService,
+		WriteRoutingService: b.WriteRoutingService,
 	}
 }
 
@@ -61,13 +70,17 @@ type WriteHandler struct {
 	influxdb.HTTPErrorHandler
 	BucketService       influxdb.BucketService
 	OrganizationService influxdb.OrganizationService
+	WriteRoutingService influxdb.WriteRoutingService
 	PointsWriter        storage.PointsWriter
 	EventRecorder       metric.EventRecorder
 
 	router            *httprouter.Router
 	log               *zap.Logger
 	maxBatchSizeBytes int64
+	writeTimeout      time.Duration
 	parserOptions     []models.ParserOption
+	backpressure      kithttp.Backpressure
+	mirrorToken       string
 }
 
 // WriteHandlerOption is a functional option for a *WriteHandler
@@ -81,12 +94,38 @@ func WithMaxBatchSizeBytes(n int64) WriteHandlerOption {
 	}
 }
 
+// WithWriteTimeout configures the maximum duration of the write of a points
+// batch to storage. A zero duration does not limit it.
+func WithWriteTimeout(d time.Duration) WriteHandlerOption {
+	return func(w *WriteHandler) {
+		w.writeTimeout = d
+	}
+}
+
 func WithParserOptions(opts ...models.ParserOption) WriteHandlerOption {
 	return func(w *WriteHandler) {
 		w.parserOptions = opts
 	}
 }
 
+// WithBackpressure configures the write handler to report the load of the
+// storage in the headers of its responses, so that clients adapt the size
+// of their batches.
+func WithBackpressure(b kithttp.Backpressure) WriteHandlerOption {
+	return func(w *WriteHandler) {
+		w.backpressure = b
+	}
+}
+
+// WithMirrorToken configures the token the mirror subscription of the other
+// instance writes with, the only one allowed to set the mirror time header.
+// The header is rejected when the token is empty.
+func WithMirrorToken(token string) WriteHandlerOption {
+	return func(w *WriteHandler) {
+		w.mirrorToken = token
+	}
+}
+
 // Prefix provides the route prefix.
 func (*WriteHandler) Prefix() string {
 	return prefixWrite
@@ -100,6 +139,31 @@ const (
 	msgWritingRequiresPoints = "writing requires points"
 	msgUnexpectedWriteError  = "unexpected error writing points to database"
 
+	// routeMeasurement is the value of the route parameter that routes the
+	// points to buckets by the write routing of the organization.
+	routeMeasurement = "measurement"
+	// routeBucket is the value of the route parameter that routes the points
+	// to the bucket named by their bucketTagKey tag.
+	routeBucket = "bucket"
+	// bucketTagKey is the key of the tag naming the bucket of a point routed
+	// by bucket. The tag is not written.
+	bucketTagKey = "_bucket"
+
+	// HeaderBatchID is the request header of the ID of a batch of points,
+	// provided by the client to trace the batch through the write path. It
+	// is recorded in the logs and metrics of the write and returned in the
+	// response.
+	HeaderBatchID = "X-Influxdb-Batch-Id"
+	// maxBatchIDLength is the maximum length of a batch ID.
+	maxBatchIDLength = 128
+	// HeaderMirrorTime is the request header of the time, in RFC3339 format,
+	// the points of a batch mirrored by the subscription of another instance
+	// were written at. It resolves the conflicts between the mirrored points
+	// and those written locally at the same timestamps, and prevents the
+	// points from being mirrored back. It is only accepted from the token of
+	// the mirror subscription.
+	HeaderMirrorTime = "X-Influxdb-Mirror-Time"
+
 	opPointsWriter = "http/pointsWriter"
 	opWriteHandler = "http/writeHandler"
 )
@@ -111,6 +175,7 @@ func NewWriteHandler(log *zap.Logger, b *WriteBackend, opts ...WriteHandlerOptio
 		PointsWriter:        b.PointsWriter,
 		BucketService:       b.BucketService,
 		OrganizationService: b.OrganizationService,
+		WriteRoutingService: b.WriteRoutingService,
 		EventRecorder:       b.WriteEventRecorder,
 
 		router: NewRouter(b.HTTPErrorHandler),
@@ -152,22 +217,60 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 	span, r := tracing.ExtractFromHTTPRequest(r, "WriteHandler")
 	defer span.Finish()
 
+	if h.backpressure != nil {
+		kithttp.SetBackpressureHeaders(w, h.backpressure)
+	}
+
 	ctx := r.Context()
+	if batchID := r.Header.Get(HeaderBatchID); batchID != "" {
+		if err := validBatchID(batchID); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		// The batch ID is returned in every response, errors included.
+		w.Header().Set(HeaderBatchID, batchID)
+		ctx = storage.WithBatchID(ctx, batchID)
+		span.LogKV("batch_id", batchID)
+	}
+
 	auth, err := pcontext.GetAuthorizer(ctx)
 	if err != nil {
-		h.HandleHTTPError(ctx, err, w)
+		h.handleWriteError(ctx, err, w)
 		return
 	}
 
+	if mirrorTime := r.Header.Get(HeaderMirrorTime); mirrorTime != "" {
+		if !h.isMirrorToken(auth) {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EForbidden,
+				Op:   opWriteHandler,
+				Msg:  fmt.Sprintf("the %s header is only accepted from the token of the mirror subscription", HeaderMirrorTime),
+			}, w)
+			return
+		}
+		t, err := time.Parse(time.RFC3339Nano, mirrorTime)
+		if err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Op:   opWriteHandler,
+				Msg:  fmt.Sprintf("invalid %s header, must be an RFC3339 time", HeaderMirrorTime),
+				Err:  err,
+			}, w)
+			return
+		}
+		ctx = storage.WithMirrorWriteTime(ctx, t)
+		span.LogKV("mirror_time", mirrorTime)
+	}
+
 	req, err := decodeWriteRequest(ctx, r, h.maxBatchSizeBytes)
 	if err != nil {
-		h.HandleHTTPError(ctx, err, w)
+		h.handleWriteError(ctx, err, w)
 		return
 	}
 
 	org, err := queryOrganization(ctx, r, h.OrganizationService)
 	if err != nil {
-		h.HandleHTTPError(ctx, err, w)
+		h.handleWriteError(ctx, err, w)
 		return
 	}
 	span.LogKV("org_id", org.ID)
@@ -182,13 +285,13 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 
 	bucket, err := h.findBucket(ctx, org.ID, req.Bucket)
 	if err != nil {
-		h.HandleHTTPError(ctx, err, sw)
+		h.handleWriteError(ctx, err, sw)
 		return
 	}
 	span.LogKV("bucket_id", bucket.ID)
 
-	if err := checkBucketWritePermissions(auth, org.ID, bucket.ID); err != nil {
-		h.HandleHTTPError(ctx, err, sw)
+	if err := checkBucketWritePermissions(ctx, auth, org.ID, bucket.ID); err != nil {
+		h.handleWriteError(ctx, err, sw)
 		return
 	}
 
@@ -196,13 +299,60 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 	opts = append(opts, models.WithParserPrecision(req.Precision))
 	parsed, err := NewPointsParser(opts...).ParsePoints(ctx, org.ID, bucket.ID, req.Body)
 	if err != nil {
-		h.HandleHTTPError(ctx, err, sw)
+		h.handleWriteError(ctx, err, sw)
 		return
 	}
 	requestBytes = parsed.RawSize
 
-	if err := h.PointsWriter.WritePoints(ctx, parsed.Points); err != nil {
-		h.HandleHTTPError(ctx, &influxdb.Error{
+	switch req.Route {
+	case routeMeasurement:
+		if err := h.routePoints(ctx, auth, org.ID, parsed.Points); err != nil {
+			h.handleWriteError(ctx, err, sw)
+			return
+		}
+	case routeBucket:
+		if err := h.routePointsByBucket(ctx, auth, org.ID, parsed.Points); err != nil {
+			h.handleWriteError(ctx, err, sw)
+			return
+		}
+	}
+
+	writeCtx := ctx
+	if h.writeTimeout > 0 {
+		var cancel context.CancelFunc
+		writeCtx, cancel = context.WithTimeout(ctx, h.writeTimeout)
+		defer cancel()
+	}
+	if req.Atomic {
+		writeCtx = storage.WithAtomicWrite(writeCtx)
+	}
+	if err := h.PointsWriter.WritePoints(writeCtx, parsed.Points); err != nil {
+		var atomicErr storage.AtomicWriteError
+		if errors.As(err, &atomicErr) {
+			h.handleWriteError(ctx, &influxdb.Error{
+				Code: influxdb.EUnprocessableEntity,
+				Op:   opWriteHandler,
+				Msg:  "atomic write rejected, no points were written",
+				Err:  atomicErr.Err,
+			}, sw)
+			return
+		}
+		// The points refused by the storage, such as the points outside
+		// the write windows of their buckets, are rejected as a whole.
+		if code := influxdb.ErrorCode(err); code == influxdb.EUnprocessableEntity || code == influxdb.EForbidden {
+			h.handleWriteError(ctx, err, sw)
+			return
+		}
+		if writeCtx.Err() == context.DeadlineExceeded {
+			h.handleWriteError(ctx, &influxdb.Error{
+				Code: influxdb.EUnavailable,
+				Op:   opWriteHandler,
+				Msg:  fmt.Sprintf("writing points to database timed out after %s", h.writeTimeout),
+				Err:  err,
+			}, sw)
+			return
+		}
+		h.handleWriteError(ctx, &influxdb.Error{
 			Code: influxdb.EInternal,
 			Op:   opWriteHandler,
 			Msg:  "unexpected error writing points to database",
@@ -210,13 +360,129 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		}, sw)
 		return
 	}
-
 	sw.WriteHeader(http.StatusNoContent)
 }
 
+// isMirrorToken returns whether auth is the token of the mirror subscription
+// of the other instance.
+func (h *WriteHandler) isMirrorToken(auth influxdb.Authorizer) bool {
+	a, ok := auth.(*influxdb.Authorization)
+	if !ok || h.mirrorToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(a.Token), []byte(h.mirrorToken)) == 1
+}
+
+// handleWriteError writes err in the response to the write of a batch. The
+// errors of batches with an ID are logged with it, so that they can be traced.
+func (h *WriteHandler) handleWriteError(ctx context.Context, err error, w http.ResponseWriter) {
+	if batchID := storage.BatchIDFromContext(ctx); batchID != "" {
+		h.log.Info("Write batch rejected",
+			zap.String("batch_id", batchID),
+			zap.String("code", influxdb.ErrorCode(err)),
+			zap.Error(err))
+	}
+	h.HandleHTTPError(ctx, err, w)
+}
+
+// validBatchID returns an error if id is not a valid batch ID: up to
+// maxBatchIDLength printable ASCII characters, without spaces.
+func validBatchID(id string) error {
+	if len(id) > maxBatchIDLength {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   opWriteHandler,
+			Msg:  fmt.Sprintf("batch ID is longer than %d characters", maxBatchIDLength),
+		}
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Op:   opWriteHandler,
+				Msg:  "batch ID must only contain printable ASCII characters without spaces",
+			}
+		}
+	}
+	return nil
+}
+
+// routePoints moves the points of the measurements routed by the write
+// routing of the organization to their buckets. The authorizer must be
+// allowed to write to every bucket a point is routed to.
+func (h *WriteHandler) routePoints(ctx context.Context, auth influxdb.Authorizer, orgID influxdb.ID, points models.Points) error {
+	if h.WriteRoutingService == nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   opWriteHandler,
+			Msg:  "write routing is not supported",
+		}
+	}
+
+	routing, err := h.WriteRoutingService.FindWriteRouting(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if len(routing.Routes) == 0 {
+		return nil
+	}
+
+	names := make(map[influxdb.ID]string)
+	for _, p := range points {
+		bucketID, ok := routing.BucketOf(string(p.Tags().Get(models.MeasurementTagKeyBytes)))
+		if !ok {
+			continue
+		}
+		name, ok := names[bucketID]
+		if !ok {
+			if err := checkBucketWritePermissions(ctx, auth, orgID, bucketID); err != nil {
+				return err
+			}
+			encoded := tsdb.EncodeName(orgID, bucketID)
+			name = string(models.EscapeMeasurement(encoded[:]))
+			names[bucketID] = name
+		}
+		p.SetName(name)
+	}
+	return nil
+}
+
+// routePointsByBucket moves the points with a bucketTagKey tag to the bucket
+// it names, by name or ID, and removes the tag. The authorizer must be
+// allowed to write to every bucket a point is routed to.
+func (h *WriteHandler) routePointsByBucket(ctx context.Context, auth influxdb.Authorizer, orgID influxdb.ID, points models.Points) error {
+	key := []byte(bucketTagKey)
+	names := make(map[string]string)
+	for _, p := range points {
+		bucket := p.Tags().Get(key)
+		if bucket == nil {
+			continue
+		}
+		name, ok := names[string(bucket)]
+		if !ok {
+			b, err := h.findBucket(ctx, orgID, string(bucket))
+			if err != nil {
+				return err
+			}
+			if err := checkBucketWritePermissions(ctx, auth, orgID, b.ID); err != nil {
+				return err
+			}
+			encoded := tsdb.EncodeName(orgID, b.ID)
+			name = string(models.EscapeMeasurement(encoded[:]))
+			names[string(bucket)] = name
+		}
+
+		tags := p.Tags().Clone()
+		tags.Delete(key)
+		p.SetTags(tags)
+		p.SetName(name)
+	}
+	return nil
+}
+
 // checkBucketWritePermissions checks an Authorizer for write permissions to a
 // specific Bucket.
-func checkBucketWritePermissions(auth influxdb.Authorizer, orgID, bucketID influxdb.ID) error {
+func checkBucketWritePermissions(ctx context.Context, auth influxdb.Authorizer, orgID, bucketID influxdb.ID) error {
 	p, err := influxdb.NewPermissionAtID(bucketID, influxdb.WriteAction, influxdb.BucketsResourceType, orgID)
 	if err != nil {
 		return &influxdb.Error{
@@ -226,7 +492,12 @@ func checkBucketWritePermissions(auth influxdb.Authorizer, orgID, bucketID influ
 			Err:  err,
 		}
 	}
-	if pset, err := auth.PermissionSet(); err != nil || !pset.Allowed(*p) {
+	if err := authorizer.IsAllowedFor(ctx, auth, *p); err != nil {
+		// The writes to frozen organizations are forbidden with an error
+		// of their own.
+		if code := influxdb.ErrorCode(err); code == influxdb.EUnavailable || code == influxdb.EForbidden {
+			return err
+		}
 		return &influxdb.Error{
 			Code: influxdb.EForbidden,
 			Op:   opWriteHandler,
@@ -284,10 +555,17 @@ func (pw *PointsParser) parsePoints(ctx context.Context, orgID, bucketID influxd
 	data, err := readAll(ctx, rc)
 	if err != nil {
 		code := influxdb.EInternal
+		var (
+			lineErr  csv2lp.CsvLineError
+			parseErr *csv.ParseError
+		)
 		if errors.Is(err, ErrMaxBatchSizeExceeded) {
 			code = influxdb.ETooLarge
 		} else if errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) {
 			code = influxdb.EInvalid
+		} else if errors.As(err, &lineErr) || errors.As(err, &parseErr) {
+			// The CSV body could not be converted to line protocol.
+			code = influxdb.EInvalid
 		}
 		return nil, &influxdb.Error{
 			Code: code,
@@ -368,6 +646,8 @@ type writeRequest struct {
 	Org       string
 	Bucket    string
 	Precision string
+	Route     string
+	Atomic    bool
 	Body      io.ReadCloser
 }
 
@@ -397,16 +677,48 @@ func decodeWriteRequest(ctx context.Context, r *http.Request, maxBatchSizeBytes
 		}
 	}
 
+	route := qp.Get("route")
+	if route != "" && route != routeMeasurement && route != routeBucket {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   "http/newWriteRequest",
+			Msg:  fmt.Sprintf("invalid route %q; valid routes are %s and %s", route, routeMeasurement, routeBucket),
+		}
+	}
+
+	var atomic bool
+	if s := qp.Get("atomic"); s != "" {
+		var err error
+		if atomic, err = strconv.ParseBool(s); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Op:   "http/newWriteRequest",
+				Msg:  fmt.Sprintf("invalid atomic %q; expected true or false", s),
+			}
+		}
+	}
+
 	encoding := r.Header.Get("Content-Encoding")
 	body, err := PointBatchReadCloser(r.Body, encoding, maxBatchSizeBytes)
 	if err != nil {
 		return nil, err
 	}
 
+	// CSV bodies are converted to line protocol as they are read, see the csv2lp package
+	// for the annotations that drive the conversion.
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "text/csv" {
+		body = struct {
+			io.Reader
+			io.Closer
+		}{csv2lp.CsvToLineProtocol(body), body}
+	}
+
 	return &writeRequest{
 		Bucket:    qp.Get("bucket"),
 		Org:       qp.Get("org"),
 		Precision: precision,
+		Route:     route,
+		Atomic:    atomic,
 		Body:      body,
 	}, nil
 }
@@ -8,8 +8,10 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/http/metric"
@@ -17,7 +19,10 @@ import (
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/mock"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
 	influxtesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/writewindow"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -97,10 +102,11 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 
 	// request is sent to the HTTP endpoint
 	type request struct {
-		auth   influxdb.Authorizer
-		org    string
-		bucket string
-		body   string
+		auth        influxdb.Authorizer
+		org         string
+		bucket      string
+		body        string
+		contentType string
 	}
 
 	tests := []struct {
@@ -242,6 +248,41 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 				body: `{"code":"invalid","message":"unable to parse 'invalid': missing fields"}`,
 			},
 		},
+		{
+			name: "csv body is converted to line protocol",
+			request: request{
+				org:         "043e0780ee2b1000",
+				bucket:      "04504b356e23b000",
+				auth:        bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+				body:        "m|measurement,t1|tag,f1|double\nm1,v1,1\n",
+				contentType: "text/csv; charset=utf-8",
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 204,
+			},
+		},
+		{
+			name: "invalid csv returns 400",
+			request: request{
+				org:         "043e0780ee2b1000",
+				bucket:      "04504b356e23b000",
+				auth:        bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+				body:        "m|measurement,f1|long\nm1,abc\n",
+				contentType: "text/csv",
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"unable to read data: line 2: column 'f1': strconv.ParseInt: parsing \"abc\": invalid syntax"}`,
+			},
+		},
 		{
 			name: "forbidden to write with insufficient permission",
 			request: request{
@@ -380,6 +421,9 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 			params.Set("org", tt.request.org)
 			params.Set("bucket", tt.request.bucket)
 			r.URL.RawQuery = params.Encode()
+			if tt.request.contentType != "" {
+				r.Header.Set("Content-Type", tt.request.contentType)
+			}
 
 			w := httptest.NewRecorder()
 			handler.ServeHTTP(w, r)
@@ -394,25 +438,381 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 	}
 }
 
+func TestWriteHandler_handleWriteRoute(t *testing.T) {
+	const (
+		org    = "043e0780ee2b1000"
+		bucket = "04504b356e23b000"
+		routed = "04504b356e23b001"
+	)
+
+	tests := []struct {
+		name  string
+		route string
+		auth  *influxdb.Authorization
+		code  int
+		// names are the measurement names of the written points.
+		names []string
+	}{
+		{
+			name:  "points are routed to the bucket of their measurement",
+			route: "measurement",
+			auth:  bucketWritePermission(org, bucket, routed),
+			code:  204,
+			names: []string{routed, bucket},
+		},
+		{
+			name:  "routing requires write permission to the routed bucket",
+			route: "measurement",
+			auth:  bucketWritePermission(org, bucket),
+			code:  403,
+		},
+		{
+			name:  "points are not routed without the route parameter",
+			auth:  bucketWritePermission(org, bucket),
+			code:  204,
+			names: []string{bucket, bucket},
+		},
+		{
+			name:  "unknown route is invalid",
+			route: "tag",
+			auth:  bucketWritePermission(org, bucket, routed),
+			code:  400,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgs := mock.NewOrganizationService()
+			orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+				return testOrg(org), nil
+			}
+			buckets := mock.NewBucketService()
+			buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+				return testBucket(org, bucket), nil
+			}
+			buckets.FindBucketByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+				return testBucket(org, id.String()), nil
+			}
+			routing := mock.NewWriteRoutingService()
+			routing.FindWriteRoutingFn = func(ctx context.Context, orgID influxdb.ID) (*influxdb.WriteRouting, error) {
+				return &influxdb.WriteRouting{
+					OrgID:  orgID,
+					Routes: map[string]influxdb.ID{"cpu": influxtesting.MustIDBase16(routed)},
+				}, nil
+			}
+			pw := &mock.PointsWriter{}
+
+			b := &APIBackend{
+				HTTPErrorHandler:    DefaultErrorHandler,
+				Logger:              zaptest.NewLogger(t),
+				OrganizationService: orgs,
+				BucketService:       buckets,
+				WriteRoutingService: routing,
+				PointsWriter:        pw,
+				WriteEventRecorder:  &metric.NopEventRecorder{},
+			}
+			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+			handler := httpmock.NewAuthMiddlewareHandler(writeHandler, tt.auth)
+
+			r := httptest.NewRequest(
+				"POST",
+				"http://localhost:9999/api/v2/write",
+				strings.NewReader("cpu,host=a usage=1\nmem,host=a used=1"),
+			)
+			params := r.URL.Query()
+			params.Set("org", org)
+			params.Set("bucket", bucket)
+			if tt.route != "" {
+				params.Set("route", tt.route)
+			}
+			r.URL.RawQuery = params.Encode()
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if got, want := w.Code, tt.code; got != want {
+				t.Fatalf("unexpected status code: got %d want %d: %s", got, want, w.Body.String())
+			}
+
+			var names []string
+			for _, p := range pw.Points {
+				_, bucketID := tsdb.DecodeNameSlice(p.Name())
+				names = append(names, bucketID.String())
+			}
+			if !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("unexpected buckets of points: got %v want %v", names, tt.names)
+			}
+		})
+	}
+}
+
+func TestWriteHandler_handleWriteAtomic(t *testing.T) {
+	const (
+		org    = "043e0780ee2b1000"
+		bucket = "04504b356e23b000"
+		routed = "04504b356e23b001"
+	)
+
+	tests := []struct {
+		name   string
+		route  string
+		atomic string
+		auth   *influxdb.Authorization
+		err    error
+		code   int
+		// names are the measurement names of the written points.
+		names []string
+		// atomic is true if the points were written atomically.
+		wantAtomic bool
+	}{
+		{
+			name:       "points are routed to the bucket of their tag",
+			route:      "bucket",
+			atomic:     "true",
+			auth:       bucketWritePermission(org, bucket, routed),
+			code:       204,
+			names:      []string{routed, bucket},
+			wantAtomic: true,
+		},
+		{
+			name:  "routing by bucket requires write permission to the routed bucket",
+			route: "bucket",
+			auth:  bucketWritePermission(org, bucket),
+			code:  403,
+		},
+		{
+			name:   "rejected atomic writes are unprocessable",
+			route:  "bucket",
+			atomic: "true",
+			auth:   bucketWritePermission(org, bucket, routed),
+			err:    storage.AtomicWriteError{Err: fmt.Errorf("field type conflict")},
+			code:   422,
+		},
+		{
+			name:   "invalid atomic is invalid",
+			atomic: "sometimes",
+			auth:   bucketWritePermission(org, bucket, routed),
+			code:   400,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgs := mock.NewOrganizationService()
+			orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+				return testOrg(org), nil
+			}
+			buckets := mock.NewBucketService()
+			buckets.FindBucketFn = func(_ context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error) {
+				if filter.Name != nil && *filter.Name == "routed" {
+					return testBucket(org, routed), nil
+				}
+				return testBucket(org, bucket), nil
+			}
+			buckets.FindBucketByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+				return testBucket(org, id.String()), nil
+			}
+			var (
+				points    []models.Point
+				gotAtomic bool
+			)
+			pw := &mock.PointsWriter{
+				WritePointsFn: func(ctx context.Context, p []models.Point) error {
+					if tt.err != nil {
+						return tt.err
+					}
+					points, gotAtomic = p, storage.AtomicWriteFromContext(ctx)
+					return nil
+				},
+			}
+
+			b := &APIBackend{
+				HTTPErrorHandler:    DefaultErrorHandler,
+				Logger:              zaptest.NewLogger(t),
+				OrganizationService: orgs,
+				BucketService:       buckets,
+				PointsWriter:        pw,
+				WriteEventRecorder:  &metric.NopEventRecorder{},
+			}
+			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+			handler := httpmock.NewAuthMiddlewareHandler(writeHandler, tt.auth)
+
+			r := httptest.NewRequest(
+				"POST",
+				"http://localhost:9999/api/v2/write",
+				strings.NewReader("cpu,_bucket=routed,host=a usage=1\nmem,host=a used=1"),
+			)
+			params := r.URL.Query()
+			params.Set("org", org)
+			params.Set("bucket", bucket)
+			if tt.route != "" {
+				params.Set("route", tt.route)
+			}
+			if tt.atomic != "" {
+				params.Set("atomic", tt.atomic)
+			}
+			r.URL.RawQuery = params.Encode()
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if got, want := w.Code, tt.code; got != want {
+				t.Fatalf("unexpected status code: got %d want %d: %s", got, want, w.Body.String())
+			}
+
+			var names []string
+			for _, p := range points {
+				_, bucketID := tsdb.DecodeNameSlice(p.Name())
+				names = append(names, bucketID.String())
+				if p.Tags().Get([]byte("_bucket")) != nil {
+					t.Errorf("unexpected bucket tag in point %s", p.Key())
+				}
+			}
+			if !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("unexpected buckets of points: got %v want %v", names, tt.names)
+			}
+			if gotAtomic != tt.wantAtomic {
+				t.Errorf("unexpected atomic write: got %t want %t", gotAtomic, tt.wantAtomic)
+			}
+		})
+	}
+}
+
+func TestWriteHandler_handleWriteWindow(t *testing.T) {
+	const (
+		org    = "043e0780ee2b1000"
+		bucket = "04504b356e23b000"
+		routed = "04504b356e23b001"
+	)
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		route  string
+		past   time.Duration
+		future time.Duration
+		// routedPast is the past window of the bucket cpu is routed to.
+		routedPast time.Duration
+		times      []time.Time
+		code       int
+	}{
+		{
+			name:  "points are accepted without write window",
+			times: []time.Time{now.Add(-24 * time.Hour), now.Add(24 * time.Hour)},
+			code:  204,
+		},
+		{
+			name:   "points within the write window are accepted",
+			past:   time.Hour,
+			future: time.Hour,
+			times:  []time.Time{now.Add(-time.Minute), now.Add(time.Minute)},
+			code:   204,
+		},
+		{
+			name:  "points older than the past window are rejected",
+			past:  time.Hour,
+			times: []time.Time{now.Add(-2 * time.Hour), now},
+			code:  422,
+		},
+		{
+			name:   "points newer than the future window are rejected",
+			future: time.Hour,
+			times:  []time.Time{now, now.Add(2 * time.Hour)},
+			code:   422,
+		},
+		{
+			name:       "routed points are checked against the window of their bucket",
+			route:      "measurement",
+			routedPast: time.Hour,
+			times:      []time.Time{now.Add(-2 * time.Hour), now},
+			code:       422,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgs := mock.NewOrganizationService()
+			orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+				return testOrg(org), nil
+			}
+			buckets := mock.NewBucketService()
+			buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+				b := testBucket(org, bucket)
+				b.WritePastWindow, b.WriteFutureWindow = tt.past, tt.future
+				return b, nil
+			}
+			buckets.FindBucketByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+				b := testBucket(org, id.String())
+				if id == influxtesting.MustIDBase16(bucket) {
+					b.WritePastWindow, b.WriteFutureWindow = tt.past, tt.future
+				} else {
+					b.WritePastWindow = tt.routedPast
+				}
+				return b, nil
+			}
+			routing := mock.NewWriteRoutingService()
+			routing.FindWriteRoutingFn = func(ctx context.Context, orgID influxdb.ID) (*influxdb.WriteRouting, error) {
+				return &influxdb.WriteRouting{
+					OrgID:  orgID,
+					Routes: map[string]influxdb.ID{"cpu": influxtesting.MustIDBase16(routed)},
+				}, nil
+			}
+			pw := &mock.PointsWriter{}
+
+			b := &APIBackend{
+				HTTPErrorHandler:    DefaultErrorHandler,
+				Logger:              zaptest.NewLogger(t),
+				OrganizationService: orgs,
+				BucketService:       buckets,
+				WriteRoutingService: routing,
+				PointsWriter:        writewindow.NewPointsWriter(pw, buckets),
+				WriteEventRecorder:  &metric.NopEventRecorder{},
+			}
+			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+			handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission(org, bucket, routed))
+
+			body := fmt.Sprintf("cpu,host=a usage=1 %d\nmem,host=a used=1 %d", tt.times[0].UnixNano(), tt.times[1].UnixNano())
+			r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write", strings.NewReader(body))
+			params := r.URL.Query()
+			params.Set("org", org)
+			params.Set("bucket", bucket)
+			if tt.route != "" {
+				params.Set("route", tt.route)
+			}
+			r.URL.RawQuery = params.Encode()
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if got, want := w.Code, tt.code; got != want {
+				t.Fatalf("unexpected status code: got %d want %d: %s", got, want, w.Body.String())
+			}
+			if tt.code != 204 {
+				if len(pw.Points) != 0 {
+					t.Errorf("expected no point to be written, got %d", len(pw.Points))
+				}
+				if !strings.Contains(w.Body.String(), "points outside write window") {
+					t.Errorf("expected the error to be categorized, got %s", w.Body.String())
+				}
+			}
+		})
+	}
+}
+
 var DefaultErrorHandler = kithttp.ErrorHandler(0)
 
-func bucketWritePermission(org, bucket string) *influxdb.Authorization {
+func bucketWritePermission(org string, buckets ...string) *influxdb.Authorization {
 	oid := influxtesting.MustIDBase16(org)
-	bid := influxtesting.MustIDBase16(bucket)
-	return &influxdb.Authorization{
+	a := &influxdb.Authorization{
 		OrgID:  oid,
 		Status: influxdb.Active,
-		Permissions: []influxdb.Permission{
-			{
-				Action: influxdb.WriteAction,
-				Resource: influxdb.Resource{
-					Type:  influxdb.BucketsResourceType,
-					OrgID: &oid,
-					ID:    &bid,
-				},
+	}
+	for _, bucket := range buckets {
+		bid := influxtesting.MustIDBase16(bucket)
+		a.Permissions = append(a.Permissions, influxdb.Permission{
+			Action: influxdb.WriteAction,
+			Resource: influxdb.Resource{
+				Type:  influxdb.BucketsResourceType,
+				OrgID: &oid,
+				ID:    &bid,
 			},
-		},
+		})
 	}
+	return a
 }
 
 func testOrg(org string) *influxdb.Organization {
@@ -431,3 +831,204 @@ func testBucket(org, bucket string) *influxdb.Bucket {
 		OrgID: oid,
 	}
 }
+
+type eventRecorderFunc func(ctx context.Context, e metric.Event)
+
+func (f eventRecorderFunc) Record(ctx context.Context, e metric.Event) {
+	f(ctx, e)
+}
+
+func TestWriteHandler_handleWriteBatchID(t *testing.T) {
+	const (
+		org    = "043e0780ee2b1000"
+		bucket = "04504b356e23b000"
+	)
+
+	tests := []struct {
+		name    string
+		batchID string
+		err     error
+		code    int
+		// wantBatchID is the batch ID of the write, its response and its
+		// recorded event.
+		wantBatchID string
+	}{
+		{
+			name:        "batch ID is traced",
+			batchID:     "telegraf-0001",
+			code:        204,
+			wantBatchID: "telegraf-0001",
+		},
+		{
+			name:        "batch ID is returned with errors",
+			batchID:     "telegraf-0002",
+			err:         fmt.Errorf("engine failure"),
+			code:        500,
+			wantBatchID: "telegraf-0002",
+		},
+		{
+			name: "batch ID is optional",
+			code: 204,
+		},
+		{
+			name:    "invalid batch ID",
+			batchID: "telegraf 0003",
+			code:    400,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgs := mock.NewOrganizationService()
+			orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+				return testOrg(org), nil
+			}
+			buckets := mock.NewBucketService()
+			buckets.FindBucketFn = func(_ context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error) {
+				return testBucket(org, bucket), nil
+			}
+			var writeBatchID, eventBatchID string
+			pw := &mock.PointsWriter{
+				WritePointsFn: func(ctx context.Context, p []models.Point) error {
+					writeBatchID = storage.BatchIDFromContext(ctx)
+					return tt.err
+				},
+			}
+
+			b := &APIBackend{
+				HTTPErrorHandler:    DefaultErrorHandler,
+				Logger:              zaptest.NewLogger(t),
+				OrganizationService: orgs,
+				BucketService:       buckets,
+				PointsWriter:        pw,
+				WriteEventRecorder: eventRecorderFunc(func(ctx context.Context, e metric.Event) {
+					eventBatchID = e.BatchID
+				}),
+			}
+			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+			handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission(org, bucket))
+
+			r := httptest.NewRequest(
+				"POST",
+				"http://localhost:9999/api/v2/write",
+				strings.NewReader("cpu,host=a usage=1"),
+			)
+			params := r.URL.Query()
+			params.Set("org", org)
+			params.Set("bucket", bucket)
+			r.URL.RawQuery = params.Encode()
+			if tt.batchID != "" {
+				r.Header.Set(HeaderBatchID, tt.batchID)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if got, want := w.Code, tt.code; got != want {
+				t.Fatalf("unexpected status code: got %d want %d: %s", got, want, w.Body.String())
+			}
+			if got := w.Header().Get(HeaderBatchID); got != tt.wantBatchID {
+				t.Errorf("unexpected batch ID of the response: got %q want %q", got, tt.wantBatchID)
+			}
+			if writeBatchID != tt.wantBatchID {
+				t.Errorf("unexpected batch ID of the write: got %q want %q", writeBatchID, tt.wantBatchID)
+			}
+			if eventBatchID != tt.wantBatchID {
+				t.Errorf("unexpected batch ID of the event: got %q want %q", eventBatchID, tt.wantBatchID)
+			}
+		})
+	}
+}
+
+func TestWriteHandler_handleWriteMirrorTime(t *testing.T) {
+	const (
+		org         = "043e0780ee2b1000"
+		bucket      = "04504b356e23b000"
+		mirrorToken = "mirror-token"
+		mirrorTime  = "2020-06-01T12:00:00Z"
+	)
+
+	tests := []struct {
+		name       string
+		token      string
+		mirrorTime string
+		code       int
+		mirrored   bool
+	}{
+		{
+			name:       "mirror time of the mirror token",
+			token:      mirrorToken,
+			mirrorTime: mirrorTime,
+			code:       204,
+			mirrored:   true,
+		},
+		{
+			name:       "mirror time of another token",
+			token:      "other-token",
+			mirrorTime: mirrorTime,
+			code:       403,
+		},
+		{
+			name:       "invalid mirror time",
+			token:      mirrorToken,
+			mirrorTime: "yesterday",
+			code:       400,
+		},
+		{
+			name:  "no mirror time",
+			token: "other-token",
+			code:  204,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgs := mock.NewOrganizationService()
+			orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+				return testOrg(org), nil
+			}
+			buckets := mock.NewBucketService()
+			buckets.FindBucketFn = func(_ context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error) {
+				return testBucket(org, bucket), nil
+			}
+			var mirrored bool
+			pw := &mock.PointsWriter{
+				WritePointsFn: func(ctx context.Context, p []models.Point) error {
+					_, mirrored = storage.MirrorWriteTimeFromContext(ctx)
+					return nil
+				},
+			}
+
+			b := &APIBackend{
+				HTTPErrorHandler:    DefaultErrorHandler,
+				Logger:              zaptest.NewLogger(t),
+				OrganizationService: orgs,
+				BucketService:       buckets,
+				PointsWriter:        pw,
+			}
+			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b), WithMirrorToken(mirrorToken))
+			auth := bucketWritePermission(org, bucket)
+			auth.Token = tt.token
+			handler := httpmock.NewAuthMiddlewareHandler(writeHandler, auth)
+
+			r := httptest.NewRequest(
+				"POST",
+				"http://localhost:9999/api/v2/write",
+				strings.NewReader("cpu,host=a usage=1"),
+			)
+			params := r.URL.Query()
+			params.Set("org", org)
+			params.Set("bucket", bucket)
+			r.URL.RawQuery = params.Encode()
+			if tt.mirrorTime != "" {
+				r.Header.Set(HeaderMirrorTime, tt.mirrorTime)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if got, want := w.Code, tt.code; got != want {
+				t.Fatalf("unexpected status code: got %d want %d: %s", got, want, w.Body.String())
+			}
+			if mirrored != tt.mirrored {
+				t.Errorf("unexpected mirrored write: got %t want %t", mirrored, tt.mirrored)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/http/metric"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/storage"
 )
 
 func NewWriteUsageRecorder(w *kithttp.StatusResponseWriter, recorder metric.EventRecorder) *WriteUsageRecorder {
@@ -31,5 +32,6 @@ func (w *WriteUsageRecorder) Record(ctx context.Context, requestBytes int, orgID
 		RequestBytes:  requestBytes,
 		ResponseBytes: w.Writer.ResponseBytes(),
 		Status:        w.Writer.Code(),
+		BatchID:       storage.BatchIDFromContext(ctx),
 	})
 }
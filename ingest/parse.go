@@ -0,0 +1,93 @@
+// Package ingest parses the messages consumed from message brokers, such as
+// Kafka and MQTT, into the points written to buckets.
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// Format is the format of the messages.
+type Format string
+
+const (
+	// FormatLineProtocol messages are batches of line protocol.
+	FormatLineProtocol Format = "line"
+	// FormatJSON messages are JSON, whose layout is given by the consumer.
+	FormatJSON Format = "json"
+)
+
+// Valid returns an error if the format is unknown.
+func (f Format) Valid() error {
+	switch f {
+	case FormatLineProtocol, FormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid format %q, must be %q or %q", f, FormatLineProtocol, FormatJSON)
+	}
+}
+
+// ValidPrecision returns an error if precision is not one of ns, us, ms and s.
+func ValidPrecision(precision string) error {
+	switch precision {
+	case "ns", "us", "ms", "s":
+		return nil
+	default:
+		return fmt.Errorf("invalid precision %q, must be ns, us, ms or s", precision)
+	}
+}
+
+// ParseLineProtocol returns the points of a batch of line protocol, exploded
+// to be written to the bucket. Points without a time are given the time now,
+// and the times of the others are in units of precision.
+func ParseLineProtocol(data []byte, orgID, bucketID influxdb.ID, precision string, now time.Time) ([]models.Point, error) {
+	encoded := tsdb.EncodeName(orgID, bucketID)
+	return models.ParsePointsWithPrecision(data, models.EscapeMeasurement(encoded[:]), now, precision)
+}
+
+// Fields returns the fields of values decoded from JSON, which must be
+// numbers, strings or booleans. Numbers are float fields, as JSON does not
+// tell integers apart.
+func Fields(values map[string]interface{}) (models.Fields, error) {
+	fields := make(models.Fields, len(values))
+	for k, v := range values {
+		switch v.(type) {
+		case float64, string, bool:
+			fields[k] = v
+		default:
+			return nil, fmt.Errorf("field %q must be a number, string or boolean", k)
+		}
+	}
+	return fields, nil
+}
+
+// Time returns the time of a JSON number in units of precision, decoded as
+// an integer so that nanosecond times are not rounded, or of an RFC3339
+// string. A missing or null time is the time now.
+func Time(raw json.RawMessage, precision string, now time.Time) (time.Time, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return now, nil
+	}
+	if raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return time.Time{}, err
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return time.Time{}, errors.New("must be a number or an RFC3339 string")
+	}
+	v, err := n.Int64()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, v*models.GetPrecisionMultiplier(precision)).UTC(), nil
+}
@@ -0,0 +1,93 @@
+package ingest_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/ingest"
+)
+
+func TestTime(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		raw       string
+		precision string
+		want      time.Time
+		wantErr   bool
+	}{
+		{
+			name:      "missing",
+			precision: "ns",
+			want:      now,
+		},
+		{
+			name:      "null",
+			raw:       "null",
+			precision: "ns",
+			want:      now,
+		},
+		{
+			name:      "nanoseconds",
+			raw:       "1600000000123456789",
+			precision: "ns",
+			want:      time.Unix(0, 1600000000123456789).UTC(),
+		},
+		{
+			name:      "precision",
+			raw:       "1600000000",
+			precision: "s",
+			want:      time.Unix(1600000000, 0).UTC(),
+		},
+		{
+			name:      "RFC3339",
+			raw:       `"2020-09-13T12:26:40Z"`,
+			precision: "ns",
+			want:      time.Unix(1600000000, 0).UTC(),
+		},
+		{
+			name:      "float",
+			raw:       "1600000000.5",
+			precision: "s",
+			wantErr:   true,
+		},
+		{
+			name:      "boolean",
+			raw:       "true",
+			precision: "ns",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ingest.Time(json.RawMessage(tt.raw), tt.precision, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("unexpected time %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	fields, err := ingest.Fields(map[string]interface{}{"usage": 0.5, "state": "ok", "up": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 3 {
+		t.Errorf("expected 3 fields, got %v", fields)
+	}
+
+	if _, err := ingest.Fields(map[string]interface{}{"usage": []interface{}{1.0}}); err == nil {
+		t.Error("expected an error for an array field")
+	}
+}
@@ -0,0 +1,62 @@
+package job
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrJobNotFound is used when the job does not exist.
+	ErrJobNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "job not found",
+	}
+
+	// ErrInvalidJobID is used when the ID of the job cannot be encoded.
+	ErrInvalidJobID = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "job ID is invalid",
+	}
+
+	// ErrOrgRequired is used when a job of a kind scoped to an organization
+	// is created without one.
+	ErrOrgRequired = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "orgID is required",
+	}
+)
+
+// ErrUnknownKind is used when no runner runs the jobs of a kind.
+func ErrUnknownKind(kind Kind) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("unknown job kind %q", kind),
+	}
+}
+
+// ErrInvalidParams is used when the parameters of a job are invalid.
+func ErrInvalidParams(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "job parameters are invalid",
+		Err:  err,
+	}
+}
+
+// ErrResultNotReady is used when the result of a job is fetched before it
+// succeeded.
+func ErrResultNotReady(status Status) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  fmt.Sprintf("job is %s, its result is only available once it succeeded", status),
+	}
+}
+
+// ErrInternalService is used when the error comes from an internal system.
+func ErrInternalService(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
@@ -0,0 +1,196 @@
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixJobs is the prefix of the job API.
+	PrefixJobs = "/api/v2/jobs"
+)
+
+// Handler is the HTTP API handler for jobs.
+type Handler struct {
+	chi.Router
+	api *kithttp.API
+	log *zap.Logger
+	svc *Service
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, svc *Service) *Handler {
+	h := &Handler{
+		api: kithttp.NewAPI(kithttp.WithLog(log)),
+		log: log,
+		svc: svc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Get("/", h.handleGetJobs)
+		r.Post("/", h.handlePostJob)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetJob)
+			r.Delete("/", h.handleDeleteJob)
+			r.Get("/result", h.handleGetResult)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixJobs
+}
+
+type jobResponse struct {
+	Links map[string]string `json:"links"`
+	*Job
+}
+
+func newJobResponse(j *Job) *jobResponse {
+	return &jobResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("%s/%s", PrefixJobs, j.ID),
+			"result": fmt.Sprintf("%s/%s/result", PrefixJobs, j.ID),
+		},
+		Job: j,
+	}
+}
+
+type jobsResponse struct {
+	Links map[string]string `json:"links"`
+	Jobs  []*jobResponse    `json:"jobs"`
+}
+
+type postJobRequest struct {
+	OrgID  influxdb.ID     `json:"orgID,omitempty"`
+	Kind   Kind            `json:"kind"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+func (h *Handler) handleGetJobs(w http.ResponseWriter, r *http.Request) {
+	var filter Filter
+	q := r.URL.Query()
+	if v := q.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			h.api.Err(w, r, influxdb.ErrCorruptID(err))
+			return
+		}
+		filter.OrgID = id
+	}
+	if v := q.Get("kind"); v != "" {
+		kind := Kind(v)
+		filter.Kind = &kind
+	}
+
+	jobs, err := h.svc.FindJobs(r.Context(), filter)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	resp := jobsResponse{
+		Links: map[string]string{"self": PrefixJobs},
+		Jobs:  make([]*jobResponse, 0, len(jobs)),
+	}
+	for _, j := range jobs {
+		resp.Jobs = append(resp.Jobs, newJobResponse(j))
+	}
+	h.api.Respond(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handlePostJob(w http.ResponseWriter, r *http.Request) {
+	var req postJobRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if len(req.Params) == 0 {
+		req.Params = json.RawMessage("{}")
+	}
+
+	j, err := h.svc.CreateJob(r.Context(), req.OrgID, req.Kind, req.Params)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Job created", zap.String("id", j.ID.String()), zap.String("kind", string(j.Kind)))
+
+	h.api.Respond(w, r, http.StatusAccepted, newJobResponse(j))
+}
+
+func (h *Handler) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	j, err := h.svc.FindJobByID(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newJobResponse(j))
+}
+
+func (h *Handler) handleDeleteJob(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	if err := h.svc.DeleteJob(r.Context(), *id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Job deleted", zap.String("id", id.String()))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
+
+// handleGetResult serves the result of a job. Clients fetch it in chunks, or
+// resume an interrupted download, with range requests.
+func (h *Handler) handleGetResult(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	j, f, err := h.svc.OpenResult(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	defer f.Close()
+
+	var modtime time.Time
+	if j.FinishedAt != nil {
+		modtime = *j.FinishedAt
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s", j.Kind, j.ID))
+	http.ServeContent(w, r, "", modtime, f)
+}
@@ -0,0 +1,216 @@
+package job
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/predicate"
+)
+
+// progressWriter reports the bytes written to w as done.
+type progressWriter struct {
+	w io.Writer
+	p Reporter
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.p.Add(int64(n))
+	return n, err
+}
+
+type backupRunner struct {
+	backupSvc   influxdb.BackupService
+	kvBackupSvc influxdb.KVBackupService
+}
+
+// NewBackupRunner returns the runner of the backups of the instance, which
+// require read access to all resources. The result of a backup is a tar
+// archive of the files the influx backup command downloads, which can be
+// extracted and restored with influx restore. Its progress is in bytes.
+func NewBackupRunner(backupSvc influxdb.BackupService, kvBackupSvc influxdb.KVBackupService) Runner {
+	return &backupRunner{
+		backupSvc:   backupSvc,
+		kvBackupSvc: kvBackupSvc,
+	}
+}
+
+func (r *backupRunner) Prepare(ctx context.Context, _ influxdb.ID, _ json.RawMessage) (Func, error) {
+	if err := authorizer.IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return r.run, nil
+}
+
+func (r *backupRunner) run(ctx context.Context, w io.Writer, p Reporter) error {
+	id, files, err := r.backupSvc.CreateBackup(ctx)
+	if err != nil {
+		return err
+	}
+	dir := r.backupSvc.InternalBackupPath(id)
+	defer os.RemoveAll(dir)
+
+	boltFile, err := os.OpenFile(filepath.Join(dir, bolt.DefaultFilename), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err := r.kvBackupSvc.Backup(ctx, boltFile); err != nil {
+		boltFile.Close()
+		return err
+	}
+	if err := boltFile.Close(); err != nil {
+		return err
+	}
+	files = append(files, bolt.DefaultFilename)
+
+	infos := make([]os.FileInfo, len(files))
+	var total int64
+	for i, name := range files {
+		if infos[i], err = os.Stat(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+		total += infos[i].Size()
+	}
+	p.SetTotal(total)
+
+	tw := tar.NewWriter(w)
+	for i, name := range files {
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0644,
+			Size:     infos[i].Size(),
+			ModTime:  infos[i].ModTime(),
+		}); err != nil {
+			return err
+		}
+		if err := r.backupSvc.FetchBackupFile(ctx, id, name, &progressWriter{w: tw, p: p}); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+type bucketParams struct {
+	BucketID influxdb.ID `json:"bucketID"`
+}
+
+func (p bucketParams) valid(orgID influxdb.ID) error {
+	if !orgID.Valid() {
+		return ErrOrgRequired
+	}
+	if !p.BucketID.Valid() {
+		return ErrInvalidParams(errors.New("bucketID is required"))
+	}
+	return nil
+}
+
+// findBucket checks that the bucket of the parameters of a job belongs to
+// the organization of the job.
+func findBucket(ctx context.Context, bucketSvc influxdb.BucketService, orgID, bucketID influxdb.ID) error {
+	_, err := bucketSvc.FindBucket(ctx, influxdb.BucketFilter{ID: &bucketID, OrganizationID: &orgID})
+	return err
+}
+
+type bucketExportRunner struct {
+	archiveSvc influxdb.BucketArchiveService
+	bucketSvc  influxdb.BucketService
+}
+
+// NewBucketExportRunner returns the runner of the exports of buckets, which
+// require read access to the bucket. The parameters are the bucketID, and
+// the result is the archive of the bucket. Its progress is in bytes written,
+// without a total.
+func NewBucketExportRunner(archiveSvc influxdb.BucketArchiveService, bucketSvc influxdb.BucketService) Runner {
+	return &bucketExportRunner{
+		archiveSvc: archiveSvc,
+		bucketSvc:  bucketSvc,
+	}
+}
+
+func (r *bucketExportRunner) Prepare(ctx context.Context, orgID influxdb.ID, params json.RawMessage) (Func, error) {
+	var bp bucketParams
+	if err := json.Unmarshal(params, &bp); err != nil {
+		return nil, ErrInvalidParams(err)
+	}
+	if err := bp.valid(orgID); err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, bp.BucketID, orgID); err != nil {
+		return nil, err
+	}
+	if err := findBucket(ctx, r.bucketSvc, orgID, bp.BucketID); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, w io.Writer, p Reporter) error {
+		return r.archiveSvc.ExportBucket(ctx, orgID, bp.BucketID, &progressWriter{w: w, p: p})
+	}, nil
+}
+
+type deleteParams struct {
+	bucketParams
+	Start     time.Time `json:"start"`
+	Stop      time.Time `json:"stop"`
+	Predicate string    `json:"predicate"`
+}
+
+type deleteRunner struct {
+	deleteSvc influxdb.DeleteService
+	bucketSvc influxdb.BucketService
+}
+
+// NewDeleteRunner returns the runner of the deletes of points, which require
+// write access to the bucket. The parameters are those of the /api/v2/delete
+// request with the bucketID, and the result is empty.
+func NewDeleteRunner(deleteSvc influxdb.DeleteService, bucketSvc influxdb.BucketService) Runner {
+	return &deleteRunner{
+		deleteSvc: deleteSvc,
+		bucketSvc: bucketSvc,
+	}
+}
+
+func (r *deleteRunner) Prepare(ctx context.Context, orgID influxdb.ID, params json.RawMessage) (Func, error) {
+	var dp deleteParams
+	if err := json.Unmarshal(params, &dp); err != nil {
+		return nil, ErrInvalidParams(err)
+	}
+	if err := dp.valid(orgID); err != nil {
+		return nil, err
+	}
+	if dp.Start.IsZero() || dp.Stop.IsZero() {
+		return nil, ErrInvalidParams(errors.New("start and stop are required"))
+	}
+	node, err := predicate.Parse(dp.Predicate)
+	if err != nil {
+		return nil, ErrInvalidParams(err)
+	}
+	pred, err := predicate.New(node)
+	if err != nil {
+		return nil, ErrInvalidParams(err)
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.BucketsResourceType, dp.BucketID, orgID); err != nil {
+		return nil, err
+	}
+	if err := findBucket(ctx, r.bucketSvc, orgID, dp.BucketID); err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context, _ io.Writer, p Reporter) error {
+		p.SetTotal(1)
+		if err := r.deleteSvc.DeleteBucketRangePredicate(ctx, orgID, dp.BucketID, dp.Start.UnixNano(), dp.Stop.UnixNano(), pred); err != nil {
+			return err
+		}
+		p.Add(1)
+		return nil
+	}, nil
+}
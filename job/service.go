@@ -0,0 +1,617 @@
+// Package job runs long operations, such as backups, bucket exports and large
+// deletes, in the background of the instance rather than within the lifetime
+// of an HTTP request. Clients create a job, poll its status and progress, and
+// fetch its result in chunks once it succeeded, resuming interrupted
+// downloads with HTTP range requests.
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/snowflake"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultConcurrency is how many jobs run at the same time.
+	DefaultConcurrency = 2
+
+	// DefaultRetention is how long finished jobs and their results are kept.
+	DefaultRetention = 24 * time.Hour
+
+	// DefaultPurgeInterval is how often expired jobs are removed.
+	DefaultPurgeInterval = time.Hour
+)
+
+var jobBucket = []byte("jobsv1")
+
+// Kind is the kind of operation a job runs.
+type Kind string
+
+const (
+	// KindBackup is the backup of the data and metadata of the instance.
+	KindBackup Kind = "backup"
+	// KindBucketExport is the export of the data of a bucket as an archive.
+	KindBucketExport Kind = "bucketExport"
+	// KindDelete is the deletion of the points of a bucket matching a predicate.
+	KindDelete Kind = "delete"
+)
+
+// Status is the state of a job.
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusSuccess Status = "success"
+	StatusFailed  Status = "failed"
+)
+
+// Progress is how much of the work of a job is done, in units chosen by its
+// runner. Total is zero until the runner knows it.
+type Progress struct {
+	Done  int64 `json:"done"`
+	Total int64 `json:"total,omitempty"`
+}
+
+// Job is an operation running in the background.
+type Job struct {
+	ID         influxdb.ID     `json:"id"`
+	OrgID      influxdb.ID     `json:"orgID,omitempty"`
+	UserID     influxdb.ID     `json:"userID"`
+	Kind       Kind            `json:"kind"`
+	Params     json.RawMessage `json:"params,omitempty"`
+	Status     Status          `json:"status"`
+	Progress   Progress        `json:"progress"`
+	Error      string          `json:"error,omitempty"`
+	ResultSize int64           `json:"resultSize"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	StartedAt  *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+	ExpiresAt  *time.Time      `json:"expiresAt,omitempty"`
+}
+
+// Finished reports whether the job is done running.
+func (j *Job) Finished() bool {
+	return j.Status == StatusSuccess || j.Status == StatusFailed
+}
+
+// Filter selects jobs.
+type Filter struct {
+	OrgID *influxdb.ID
+	Kind  *Kind
+}
+
+// Reporter records the progress of a running job.
+type Reporter interface {
+	// SetTotal sets the amount of work of the job, once known.
+	SetTotal(n int64)
+	// Add records n more units of work as done.
+	Add(n int64)
+}
+
+// Func runs a job, writing its result to w and reporting its progress to p.
+type Func func(ctx context.Context, w io.Writer, p Reporter) error
+
+// Runner prepares the jobs of a kind.
+type Runner interface {
+	// Prepare validates the parameters of a job and authorizes its creation
+	// with the authorizer of ctx. It returns the function running the job,
+	// which is called with the same authorizer.
+	Prepare(ctx context.Context, orgID influxdb.ID, params json.RawMessage) (Func, error)
+}
+
+// run is a job that is pending or running in this process.
+type run struct {
+	s      *Service
+	job    *Job
+	auth   influxdb.Authorizer
+	fn     Func
+	cancel context.CancelFunc
+
+	// deleted is set when the job is deleted while it runs.
+	deleted bool
+}
+
+func (r *run) SetTotal(n int64) {
+	r.s.mu.Lock()
+	r.job.Progress.Total = n
+	r.s.mu.Unlock()
+}
+
+func (r *run) Add(n int64) {
+	r.s.mu.Lock()
+	r.job.Progress.Done += n
+	r.s.mu.Unlock()
+}
+
+// Service stores jobs in a kv store and runs them, writing their results to
+// files of a directory.
+type Service struct {
+	log     *zap.Logger
+	store   kv.Store
+	dir     string
+	runners map[Kind]Runner
+
+	IDGenerator   influxdb.IDGenerator
+	TimeGenerator influxdb.TimeGenerator
+	Concurrency   int
+	Retention     time.Duration
+	PurgeInterval time.Duration
+	// PolicyEngine decides the permission checks of the runs, which are
+	// decided by the permissions of the authorizers of the jobs when it is
+	// nil.
+	PolicyEngine authorizer.PolicyEngine
+
+	// mu guards the runs, and orders their updates in the store.
+	mu      sync.Mutex
+	runs    map[influxdb.ID]*run
+	queue   []*run
+	running int
+	wake    chan struct{}
+}
+
+// NewService constructs a job service storing the jobs in st and their
+// results in dir. The jobs of each kind are run by its runner.
+func NewService(log *zap.Logger, st kv.Store, dir string, runners map[Kind]Runner) *Service {
+	return &Service{
+		log:           log,
+		store:         st,
+		dir:           dir,
+		runners:       runners,
+		IDGenerator:   snowflake.NewIDGenerator(),
+		TimeGenerator: influxdb.RealTimeGenerator{},
+		Concurrency:   DefaultConcurrency,
+		Retention:     DefaultRetention,
+		PurgeInterval: DefaultPurgeInterval,
+		runs:          make(map[influxdb.ID]*run),
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// CreateJob creates a job of a kind, which runs once Run picks it up. The
+// orgID is ignored by the kinds that are not scoped to an organization.
+func (s *Service) CreateJob(ctx context.Context, orgID influxdb.ID, kind Kind, params json.RawMessage) (*Job, error) {
+	runner, ok := s.runners[kind]
+	if !ok {
+		return nil, ErrUnknownKind(kind)
+	}
+	auth, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fn, err := runner.Prepare(ctx, orgID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &Job{
+		ID:        s.IDGenerator.ID(),
+		OrgID:     orgID,
+		UserID:    auth.GetUserID(),
+		Kind:      kind,
+		Params:    params,
+		Status:    StatusPending,
+		CreatedAt: s.TimeGenerator.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.putJob(ctx, j); err != nil {
+		return nil, err
+	}
+	r := &run{s: s, job: j, auth: auth, fn: fn}
+	s.runs[j.ID] = r
+	s.queue = append(s.queue, r)
+	s.signal()
+
+	cp := *j
+	return &cp, nil
+}
+
+// FindJobByID returns a job, with its current progress when it is running.
+// Jobs are visible to the user who created them and to operators.
+func (s *Service) FindJobByID(ctx context.Context, id influxdb.ID) (*Job, error) {
+	var j *Job
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		j, err = findJob(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeJob(ctx, j); err != nil {
+		return nil, err
+	}
+	s.overlay(j)
+	return j, nil
+}
+
+// FindJobs returns the jobs matching the filter that are visible to the user.
+func (s *Service) FindJobs(ctx context.Context, filter Filter) ([]*Job, error) {
+	jobs := []*Job{}
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		return walkJobs(ctx, tx, func(j *Job) error {
+			if filter.OrgID != nil && j.OrgID != *filter.OrgID {
+				return nil
+			}
+			if filter.Kind != nil && j.Kind != *filter.Kind {
+				return nil
+			}
+			if authorizeJob(ctx, j) != nil {
+				return nil
+			}
+			jobs = append(jobs, j)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, j := range jobs {
+		s.overlay(j)
+	}
+	return jobs, nil
+}
+
+// DeleteJob deletes a job and its result, canceling it when it is pending or
+// running.
+func (s *Service) DeleteJob(ctx context.Context, id influxdb.ID) error {
+	if _, err := s.FindJobByID(ctx, id); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.runs[id]; ok {
+		if r.cancel != nil {
+			// The result is removed once the job returns.
+			r.deleted = true
+			r.cancel()
+		} else {
+			s.dequeue(r)
+		}
+	} else if err := os.Remove(s.resultPath(id)); err != nil && !os.IsNotExist(err) {
+		return ErrInternalService(err)
+	}
+	return s.deleteJob(ctx, id)
+}
+
+// OpenResult opens the result of a job that succeeded.
+func (s *Service) OpenResult(ctx context.Context, id influxdb.ID) (*Job, *os.File, error) {
+	j, err := s.FindJobByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if j.Status != StatusSuccess {
+		return nil, nil, ErrResultNotReady(j.Status)
+	}
+	f, err := os.Open(s.resultPath(id))
+	if err != nil {
+		return nil, nil, ErrInternalService(err)
+	}
+	return j, f, nil
+}
+
+// Run runs the jobs until ctx is canceled, and periodically removes the
+// expired ones. Jobs left pending or running by a previous process are
+// marked as failed, since their runs cannot be resumed.
+func (s *Service) Run(ctx context.Context) {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		s.log.Error("Failed to create the directory of job results", zap.String("path", s.dir), zap.Error(err))
+		return
+	}
+	if err := s.failInterrupted(ctx); err != nil {
+		s.log.Error("Failed to mark interrupted jobs as failed", zap.Error(err))
+	}
+
+	ticker := time.NewTicker(s.PurgeInterval)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	s.purge(ctx)
+	for {
+		s.start(ctx, &wg)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+			s.purge(ctx)
+		}
+	}
+}
+
+func (s *Service) start(ctx context.Context, wg *sync.WaitGroup) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.running < s.Concurrency && len(s.queue) > 0 {
+		r := s.queue[0]
+		s.queue = s.queue[1:]
+
+		runCtx, cancel := context.WithCancel(authorizer.WithPolicyEngine(icontext.SetAuthorizer(ctx, r.auth), s.PolicyEngine))
+		r.cancel = cancel
+		s.running++
+
+		now := s.TimeGenerator.Now().UTC()
+		r.job.Status = StatusRunning
+		r.job.StartedAt = &now
+		if err := s.putJob(ctx, r.job); err != nil {
+			s.log.Error("Failed to update job", zap.Stringer("id", r.job.ID), zap.Error(err))
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer cancel()
+			s.execute(runCtx, r)
+		}()
+	}
+}
+
+func (s *Service) execute(ctx context.Context, r *run) {
+	log := s.log.With(zap.Stringer("id", r.job.ID), zap.String("kind", string(r.job.Kind)))
+	log.Info("Job started")
+
+	path := s.resultPath(r.job.ID)
+	err := s.runFunc(ctx, r, path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.signal()
+	delete(s.runs, r.job.ID)
+	s.running--
+
+	if r.deleted {
+		log.Info("Job canceled")
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Error("Failed to remove job result", zap.Error(err))
+		}
+		return
+	}
+
+	now := s.TimeGenerator.Now().UTC()
+	expires := now.Add(s.Retention)
+	r.job.FinishedAt, r.job.ExpiresAt = &now, &expires
+	if err != nil {
+		log.Error("Job failed", zap.Error(err))
+		r.job.Status = StatusFailed
+		r.job.Error = err.Error()
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Error("Failed to remove job result", zap.Error(err))
+		}
+	} else {
+		log.Info("Job succeeded")
+		r.job.Status = StatusSuccess
+		if fi, err := os.Stat(path); err == nil {
+			r.job.ResultSize = fi.Size()
+		}
+	}
+	// The store is updated even when ctx was canceled by a shutdown.
+	if err := s.putJob(context.Background(), r.job); err != nil {
+		log.Error("Failed to update job", zap.Error(err))
+	}
+}
+
+func (s *Service) runFunc(ctx context.Context, r *run, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err := r.fn(ctx, f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// overlay sets the progress of a job that is running in this process.
+func (s *Service) overlay(j *Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.runs[j.ID]; ok {
+		j.Progress = r.job.Progress
+	}
+}
+
+func (s *Service) dequeue(r *run) {
+	delete(s.runs, r.job.ID)
+	for i, q := range s.queue {
+		if q == r {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *Service) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Service) failInterrupted(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		var interrupted []*Job
+		err := walkJobs(ctx, tx, func(j *Job) error {
+			if _, ok := s.runs[j.ID]; !ok && !j.Finished() {
+				interrupted = append(interrupted, j)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		now := s.TimeGenerator.Now().UTC()
+		expires := now.Add(s.Retention)
+		for _, j := range interrupted {
+			j.Status = StatusFailed
+			j.Error = "job was interrupted by a restart of the instance"
+			j.FinishedAt, j.ExpiresAt = &now, &expires
+			if err := putJob(tx, j); err != nil {
+				return err
+			}
+			os.Remove(s.resultPath(j.ID))
+		}
+		return nil
+	})
+}
+
+func (s *Service) purge(ctx context.Context) {
+	now := s.TimeGenerator.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		var expired []influxdb.ID
+		err := walkJobs(ctx, tx, func(j *Job) error {
+			if j.ExpiresAt != nil && j.ExpiresAt.Before(now) {
+				expired = append(expired, j.ID)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, id := range expired {
+			if err := deleteJob(tx, id); err != nil {
+				return err
+			}
+			if err := os.Remove(s.resultPath(id)); err != nil && !os.IsNotExist(err) {
+				return ErrInternalService(err)
+			}
+		}
+		n = len(expired)
+		return nil
+	})
+	if err != nil {
+		s.log.Error("Failed to purge expired jobs", zap.Error(err))
+		return
+	}
+	if n > 0 {
+		s.log.Info("Purged expired jobs", zap.Int("count", n))
+	}
+}
+
+func (s *Service) resultPath(id influxdb.ID) string {
+	return filepath.Join(s.dir, id.String())
+}
+
+func (s *Service) putJob(ctx context.Context, j *Job) error {
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		return putJob(tx, j)
+	})
+}
+
+func (s *Service) deleteJob(ctx context.Context, id influxdb.ID) error {
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		return deleteJob(tx, id)
+	})
+}
+
+// authorizeJob allows the user who created a job and operators to access it.
+func authorizeJob(ctx context.Context, j *Job) error {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+	if a.GetUserID() == j.UserID {
+		return nil
+	}
+	return authorizer.IsAllowedAll(ctx, influxdb.OperPermissions())
+}
+
+func findJob(tx kv.Tx, id influxdb.ID) (*Job, error) {
+	key, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidJobID
+	}
+	b, err := tx.Bucket(jobBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(key)
+	if kv.IsNotFound(err) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+
+	j := &Job{}
+	if err := json.Unmarshal(v, j); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return j, nil
+}
+
+func walkJobs(ctx context.Context, tx kv.Tx, fn func(*Job) error) error {
+	b, err := tx.Bucket(jobBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	return kv.WalkCursor(ctx, cur, func(k, v []byte) error {
+		j := &Job{}
+		if err := json.Unmarshal(v, j); err != nil {
+			return ErrInternalService(err)
+		}
+		return fn(j)
+	})
+}
+
+func putJob(tx kv.Tx, j *Job) error {
+	key, err := j.ID.Encode()
+	if err != nil {
+		return ErrInvalidJobID
+	}
+	v, err := json.Marshal(j)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	b, err := tx.Bucket(jobBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(key, v); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+func deleteJob(tx kv.Tx, id influxdb.ID) error {
+	key, err := id.Encode()
+	if err != nil {
+		return ErrInvalidJobID
+	}
+	b, err := tx.Bucket(jobBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Delete(key); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
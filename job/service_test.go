@@ -0,0 +1,242 @@
+package job_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/job"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"go.uber.org/zap/zaptest"
+)
+
+const testKind = job.Kind("test")
+
+// runnerFunc runs the test jobs with fn.
+type runnerFunc func(ctx context.Context, w io.Writer, p job.Reporter) error
+
+func (fn runnerFunc) Prepare(context.Context, influxdb.ID, json.RawMessage) (job.Func, error) {
+	return job.Func(fn), nil
+}
+
+func newTestStore(t *testing.T) kv.Store {
+	t.Helper()
+
+	s := inmem.NewKVStore()
+	if err := all.Up(context.Background(), zaptest.NewLogger(t), s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func userContext(userID influxdb.ID) context.Context {
+	return icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		UserID: userID,
+		Status: influxdb.Active,
+	})
+}
+
+// waitFor polls the job until it has the status.
+func waitFor(t *testing.T, svc *job.Service, id influxdb.ID, status job.Status) *job.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		j, err := svc.FindJobByID(userContext(1), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if j.Status == status {
+			return j
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job is %s, expected %s", j.Status, status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestService_RunJob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	svc := job.NewService(zaptest.NewLogger(t), newTestStore(t), dir, map[job.Kind]job.Runner{
+		testKind: runnerFunc(func(ctx context.Context, w io.Writer, p job.Reporter) error {
+			p.SetTotal(2)
+			p.Add(1)
+			close(started)
+			<-release
+			p.Add(1)
+			_, err := io.WriteString(w, "result")
+			return err
+		}),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	if _, err := svc.CreateJob(userContext(1), 0, "unknown", nil); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected an unknown kind, got %v", err)
+	}
+
+	j, err := svc.CreateJob(userContext(1), 0, testKind, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.UserID != 1 || j.Status != job.StatusPending {
+		t.Errorf("unexpected job %+v", j)
+	}
+
+	<-started
+	running := waitFor(t, svc, j.ID, job.StatusRunning)
+	if running.Progress != (job.Progress{Done: 1, Total: 2}) {
+		t.Errorf("unexpected progress %+v", running.Progress)
+	}
+	if _, _, err := svc.OpenResult(userContext(1), j.ID); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Errorf("expected the result not to be ready, got %v", err)
+	}
+	if _, err := svc.FindJobByID(userContext(2), j.ID); err == nil {
+		t.Error("expected the job not to be visible to another user")
+	}
+
+	close(release)
+	finished := waitFor(t, svc, j.ID, job.StatusSuccess)
+	if finished.ResultSize != 6 || finished.Progress.Done != 2 || finished.ExpiresAt == nil {
+		t.Errorf("unexpected job %+v", finished)
+	}
+
+	_, f, err := svc.OpenResult(userContext(1), j.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "result" {
+		t.Errorf("unexpected result %q", b)
+	}
+
+	jobs, err := svc.FindJobs(userContext(2), job.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 0 {
+		t.Errorf("expected no jobs of another user, got %d", len(jobs))
+	}
+
+	if err := svc.DeleteJob(userContext(1), j.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindJobByID(userContext(1), j.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected the job to be deleted, got %v", err)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("expected the result to be removed, got %d files", len(files))
+	}
+}
+
+func TestService_CancelJob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	canceled := make(chan struct{})
+	svc := job.NewService(zaptest.NewLogger(t), newTestStore(t), dir, map[job.Kind]job.Runner{
+		testKind: runnerFunc(func(ctx context.Context, w io.Writer, p job.Reporter) error {
+			<-ctx.Done()
+			close(canceled)
+			return ctx.Err()
+		}),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	j, err := svc.CreateJob(userContext(1), 0, testKind, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, svc, j.ID, job.StatusRunning)
+
+	if err := svc.DeleteJob(userContext(1), j.ID); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the job to be canceled")
+	}
+	if _, err := svc.FindJobByID(userContext(1), j.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected the job to be deleted, got %v", err)
+	}
+}
+
+func TestService_FailInterrupted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := newTestStore(t)
+	runners := map[job.Kind]job.Runner{
+		testKind: runnerFunc(func(context.Context, io.Writer, job.Reporter) error {
+			return nil
+		}),
+	}
+
+	// The job is created by a process that stops before running it.
+	j, err := job.NewService(zaptest.NewLogger(t), store, dir, runners).CreateJob(userContext(1), 0, testKind, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := job.NewService(zaptest.NewLogger(t), store, dir, runners)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	failed := waitFor(t, svc, j.ID, job.StatusFailed)
+	if failed.Error == "" || failed.FinishedAt == nil {
+		t.Errorf("unexpected job %+v", failed)
+	}
+}
@@ -0,0 +1,284 @@
+// Package kafkaingest writes the messages of Kafka topics to buckets, so
+// that points published to Kafka are ingested without running Telegraf to
+// bridge them.
+//
+// The topics are consumed as a consumer group, whose offsets are committed
+// once the points of a message are written. Messages that cannot be written,
+// because they do not parse or their bucket rejects them, are produced to a
+// dead letter topic, if one is configured, and are otherwise dropped.
+package kafkaingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/ingest"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/prometheus/client_golang/prometheus"
+	kafka "github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultGroupID is the default consumer group of influxd.
+	DefaultGroupID = "influxd"
+
+	minRetryInterval = time.Second
+	maxRetryInterval = time.Minute
+)
+
+// Config is the configuration of the topics consumed.
+type Config struct {
+	Brokers []string
+	GroupID string
+	// Topics are the buckets the messages of each topic are written to.
+	Topics map[string]influxdb.ID
+	// Format is the format of the messages; JSON messages are a JSON point,
+	// or an array of them.
+	Format ingest.Format
+	// Precision is the precision of the times of the points, one of ns, us,
+	// ms and s.
+	Precision string
+	// DeadLetterTopic is the topic the messages that cannot be written are
+	// produced to; they are dropped when it is empty.
+	DeadLetterTopic string
+}
+
+// Valid returns an error if the configuration is incomplete.
+func (c *Config) Valid() error {
+	if len(c.Brokers) == 0 {
+		return errors.New("at least one broker is required")
+	}
+	if c.GroupID == "" {
+		return errors.New("group ID is required")
+	}
+	if len(c.Topics) == 0 {
+		return errors.New("at least one topic is required")
+	}
+	for topic, id := range c.Topics {
+		if !id.Valid() {
+			return fmt.Errorf("invalid bucket ID of topic %q", topic)
+		}
+		if topic == c.DeadLetterTopic {
+			return fmt.Errorf("topic %q cannot be the dead letter topic", topic)
+		}
+	}
+	if err := c.Format.Valid(); err != nil {
+		return err
+	}
+	return ingest.ValidPrecision(c.Precision)
+}
+
+// BucketFinder finds the buckets the topics are written to.
+type BucketFinder interface {
+	FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error)
+}
+
+// reader reads the messages of a topic as a member of the consumer group.
+type reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// writer produces the messages of the dead letter topic.
+type writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// Consumer writes the messages of the topics to their buckets.
+type Consumer struct {
+	log     *zap.Logger
+	config  Config
+	buckets BucketFinder
+	pw      storage.PointsWriter
+
+	newReader   func(topic string) reader
+	deadLetters writer
+	now         func() time.Time
+
+	messages *prometheus.CounterVec
+	points   *prometheus.CounterVec
+}
+
+// NewConsumer returns a Consumer of the topics of config, writing their
+// points with pw to the buckets found with buckets.
+func NewConsumer(log *zap.Logger, config Config, buckets BucketFinder, pw storage.PointsWriter) (*Consumer, error) {
+	if err := config.Valid(); err != nil {
+		return nil, err
+	}
+
+	const namespace = "kafka"
+	const subsystem = "ingest"
+	c := &Consumer{
+		log:     log,
+		config:  config,
+		buckets: buckets,
+		pw:      pw,
+		newReader: func(topic string) reader {
+			return kafka.NewReader(kafka.ReaderConfig{
+				Brokers: config.Brokers,
+				GroupID: config.GroupID,
+				Topic:   topic,
+			})
+		},
+		now: func() time.Time { return time.Now().UTC() },
+
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "messages_total",
+			Help:      "Number of messages consumed, by topic and result: written, dead_lettered or dropped.",
+		}, []string{"topic", "result"}),
+		points: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "points_total",
+			Help:      "Number of points written from the messages, by topic.",
+		}, []string{"topic"}),
+	}
+	if config.DeadLetterTopic != "" {
+		c.deadLetters = kafka.NewWriter(kafka.WriterConfig{
+			Brokers: config.Brokers,
+			Topic:   config.DeadLetterTopic,
+		})
+	}
+	return c, nil
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (c *Consumer) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.messages,
+		c.points,
+	}
+}
+
+// Run consumes the topics until ctx is done.
+func (c *Consumer) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for topic, bucketID := range c.config.Topics {
+		wg.Add(1)
+		go func(topic string, bucketID influxdb.ID) {
+			defer wg.Done()
+			c.consume(ctx, topic, bucketID)
+		}(topic, bucketID)
+	}
+	wg.Wait()
+
+	if c.deadLetters != nil {
+		if err := c.deadLetters.Close(); err != nil {
+			c.log.Warn("Failed to close dead letter writer", zap.Error(err))
+		}
+	}
+}
+
+// consume writes the messages of topic to the bucket. The offset of a
+// message is committed once it is written or dead lettered, so that it is
+// read again if influxd stops before.
+func (c *Consumer) consume(ctx context.Context, topic string, bucketID influxdb.ID) {
+	log := c.log.With(zap.String("topic", topic), zap.String("bucketID", bucketID.String()))
+	r := c.newReader(topic)
+	defer func() {
+		if r != nil {
+			r.Close()
+		}
+	}()
+
+	retry := minRetryInterval
+	for {
+		msg, err := r.FetchMessage(ctx)
+		if err == nil {
+			err = c.handle(ctx, topic, msg, bucketID)
+		}
+		if err == nil {
+			err = r.CommitMessages(ctx, msg)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			retry = minRetryInterval
+			continue
+		}
+
+		// The message is fetched again once the reader is recreated, as its
+		// offset was not committed.
+		log.Warn("Failed to consume message", zap.Error(err), zap.Duration("retry", retry))
+		r.Close()
+		r = nil
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		if retry *= 2; retry > maxRetryInterval {
+			retry = maxRetryInterval
+		}
+		r = c.newReader(topic)
+	}
+}
+
+// handle writes the points of msg to the bucket. It returns an error if the
+// message should be written again, and otherwise dead letters the messages
+// that cannot be written.
+func (c *Consumer) handle(ctx context.Context, topic string, msg kafka.Message, bucketID influxdb.ID) error {
+	b, err := c.buckets.FindBucketByID(ctx, bucketID)
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return c.deadLetter(ctx, topic, msg, err)
+		}
+		return err
+	}
+
+	points, err := parse(c.config.Format, msg.Value, b.OrgID, b.ID, c.config.Precision, c.now())
+	if err != nil {
+		return c.deadLetter(ctx, topic, msg, err)
+	}
+	if len(points) > 0 {
+		if err := c.pw.WritePoints(ctx, points); err != nil {
+			if retryable(err) {
+				return err
+			}
+			return c.deadLetter(ctx, topic, msg, err)
+		}
+	}
+
+	c.messages.WithLabelValues(topic, "written").Inc()
+	c.points.WithLabelValues(topic).Add(float64(len(points)))
+	return nil
+}
+
+// retryable returns whether the write failing with err may succeed later.
+func retryable(err error) bool {
+	switch influxdb.ErrorCode(err) {
+	case influxdb.EInvalid, influxdb.EUnprocessableEntity, influxdb.ETooLarge,
+		influxdb.ENotFound, influxdb.EForbidden, influxdb.EUnauthorized:
+		return false
+	default:
+		return true
+	}
+}
+
+// deadLetter produces msg, which failed to be written with err, to the
+// dead letter topic, or drops it if there is none.
+func (c *Consumer) deadLetter(ctx context.Context, topic string, msg kafka.Message, err error) error {
+	log := c.log.With(zap.String("topic", topic), zap.Int("partition", msg.Partition), zap.Int64("offset", msg.Offset), zap.NamedError("reason", err))
+	if c.deadLetters == nil {
+		log.Warn("Dropping message that cannot be written")
+		c.messages.WithLabelValues(topic, "dropped").Inc()
+		return nil
+	}
+
+	if err := c.deadLetters.WriteMessages(ctx, kafka.Message{Key: msg.Key, Value: msg.Value}); err != nil {
+		return fmt.Errorf("failed to produce message to dead letter topic: %v", err)
+	}
+	log.Warn("Produced message that cannot be written to the dead letter topic", zap.String("deadLetterTopic", c.config.DeadLetterTopic))
+	c.messages.WithLabelValues(topic, "dead_lettered").Inc()
+	return nil
+}
@@ -0,0 +1,187 @@
+package kafkaingest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/ingest"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	kafka "github.com/segmentio/kafka-go"
+	"go.uber.org/zap/zaptest"
+)
+
+// testWriter records the messages produced to the dead letter topic.
+type testWriter struct {
+	mu   sync.Mutex
+	msgs []kafka.Message
+}
+
+func (w *testWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func (w *testWriter) Close() error { return nil }
+
+// testReader returns its messages, and then blocks until the context is
+// done.
+type testReader struct {
+	msgs      chan kafka.Message
+	committed chan kafka.Message
+}
+
+func (r *testReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case msg := <-r.msgs:
+		return msg, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *testReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	for _, msg := range msgs {
+		r.committed <- msg
+	}
+	return nil
+}
+
+func (r *testReader) Close() error { return nil }
+
+// newTestConsumer returns a consumer of the topic t1, written to the bucket
+// 10 of the organization 1, whose points are written with pw.
+func newTestConsumer(t *testing.T, pw *mock.PointsWriter, deadLetters *testWriter) *Consumer {
+	t.Helper()
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		if id != 10 {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+		}
+		return &influxdb.Bucket{ID: id, OrgID: 1}, nil
+	}
+	config := Config{
+		Brokers:   []string{"localhost:9092"},
+		GroupID:   DefaultGroupID,
+		Topics:    map[string]influxdb.ID{"t1": 10},
+		Format:    ingest.FormatLineProtocol,
+		Precision: "ns",
+	}
+	c, err := NewConsumer(zaptest.NewLogger(t), config, buckets, pw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deadLetters != nil {
+		c.deadLetters = deadLetters
+	}
+	return c
+}
+
+func TestConsumer_Handle(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          string
+		bucketID       influxdb.ID
+		writeErr       error
+		deadLetters    bool
+		wantErr        bool
+		wantPoints     int
+		wantDeadLetter bool
+	}{
+		{name: "written", value: "cpu,host=a usage=1,idle=2 10\nmem used=3 10", bucketID: 10, wantPoints: 3},
+		{name: "invalid", value: "cpu usage=", bucketID: 10, deadLetters: true, wantDeadLetter: true},
+		{name: "invalid without dead letter topic", value: "cpu usage=", bucketID: 10},
+		{name: "bucket not found", value: "cpu usage=1", bucketID: 20, deadLetters: true, wantDeadLetter: true},
+		{
+			name:           "rejected write",
+			value:          "cpu usage=1",
+			bucketID:       10,
+			writeErr:       &influxdb.Error{Code: influxdb.EForbidden, Msg: "bucket is read only"},
+			deadLetters:    true,
+			wantDeadLetter: true,
+		},
+		{
+			name:        "failed write",
+			value:       "cpu usage=1",
+			bucketID:    10,
+			writeErr:    errors.New("engine unavailable"),
+			deadLetters: true,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var written []models.Point
+			pw := &mock.PointsWriter{WritePointsFn: func(ctx context.Context, points []models.Point) error {
+				if tt.writeErr != nil {
+					return tt.writeErr
+				}
+				written = append(written, points...)
+				return nil
+			}}
+			var deadLetters *testWriter
+			if tt.deadLetters {
+				deadLetters = &testWriter{}
+			}
+			c := newTestConsumer(t, pw, deadLetters)
+
+			err := c.handle(context.Background(), "t1", kafka.Message{Key: []byte("k"), Value: []byte(tt.value)}, tt.bucketID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if len(written) != tt.wantPoints {
+				t.Errorf("expected %d points written, got %d", tt.wantPoints, len(written))
+			}
+			if tt.deadLetters {
+				if got := len(deadLetters.msgs) == 1; got != tt.wantDeadLetter {
+					t.Errorf("expected dead letter %v, got %d messages", tt.wantDeadLetter, len(deadLetters.msgs))
+				}
+				if tt.wantDeadLetter && string(deadLetters.msgs[0].Value) != tt.value {
+					t.Errorf("unexpected dead letter %q", deadLetters.msgs[0].Value)
+				}
+			}
+		})
+	}
+}
+
+func TestConsumer_Consume(t *testing.T) {
+	pw := &mock.PointsWriter{}
+	c := newTestConsumer(t, pw, &testWriter{})
+	r := &testReader{msgs: make(chan kafka.Message, 2), committed: make(chan kafka.Message, 2)}
+	c.newReader = func(topic string) reader { return r }
+
+	r.msgs <- kafka.Message{Offset: 1, Value: []byte("cpu usage=1 10")}
+	r.msgs <- kafka.Message{Offset: 2, Value: []byte("not line protocol")}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.consume(ctx, "t1", 10)
+	}()
+
+	// Both the written and the dead lettered messages are committed.
+	for _, want := range []int64{1, 2} {
+		select {
+		case msg := <-r.committed:
+			if msg.Offset != want {
+				t.Errorf("expected offset %d committed, got %d", want, msg.Offset)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for offset %d to be committed", want)
+		}
+	}
+	cancel()
+	<-done
+
+	if got := pw.WritePointsCalled(); got != 1 {
+		t.Errorf("expected 1 write, got %d", got)
+	}
+}
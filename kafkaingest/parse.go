@@ -0,0 +1,83 @@
+package kafkaingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/ingest"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// parse returns the points of the message data, exploded to be written to
+// the bucket. Points without a time are given the time now, and the times
+// of the others are in units of precision.
+func parse(format ingest.Format, data []byte, orgID, bucketID influxdb.ID, precision string, now time.Time) ([]models.Point, error) {
+	if format == ingest.FormatJSON {
+		points, err := parseJSON(data, precision, now)
+		if err != nil {
+			return nil, err
+		}
+		return tsdb.ExplodePoints(orgID, bucketID, points)
+	}
+	return ingest.ParseLineProtocol(data, orgID, bucketID, precision, now)
+}
+
+type jsonPoint struct {
+	Measurement string                 `json:"measurement"`
+	Tags        map[string]string      `json:"tags"`
+	Fields      map[string]interface{} `json:"fields"`
+	Time        json.RawMessage        `json:"time"`
+}
+
+// parseJSON returns the points of a JSON point or array of points, such as
+// {"measurement": "cpu", "tags": {"host": "a"}, "fields": {"usage": 0.5}, "time": 1600000000}.
+func parseJSON(data []byte, precision string, now time.Time) ([]models.Point, error) {
+	var jps []jsonPoint
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &jps); err != nil {
+			return nil, err
+		}
+	} else {
+		var jp jsonPoint
+		if err := json.Unmarshal(data, &jp); err != nil {
+			return nil, err
+		}
+		jps = append(jps, jp)
+	}
+
+	points := make([]models.Point, 0, len(jps))
+	for _, jp := range jps {
+		p, err := jp.point(precision, now)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+func (jp *jsonPoint) point(precision string, now time.Time) (models.Point, error) {
+	if jp.Measurement == "" {
+		return nil, errors.New("measurement is required")
+	}
+	if len(jp.Fields) == 0 {
+		return nil, fmt.Errorf("point of measurement %q has no fields", jp.Measurement)
+	}
+
+	fields, err := ingest.Fields(jp.Fields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid point of measurement %q: %v", jp.Measurement, err)
+	}
+
+	t, err := ingest.Time(jp.Time, precision, now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time of measurement %q: %v", jp.Measurement, err)
+	}
+
+	return models.NewPoint(jp.Measurement, models.NewTags(jp.Tags), fields, t)
+}
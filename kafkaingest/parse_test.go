@@ -0,0 +1,92 @@
+package kafkaingest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseJSON(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		data      string
+		precision string
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "point",
+			data:      `{"measurement": "cpu", "tags": {"host": "a"}, "fields": {"usage": 0.5, "ok": true}, "time": 1600000000123456789}`,
+			precision: "ns",
+			want:      []string{"cpu,host=a ok=true,usage=0.5 1600000000123456789"},
+		},
+		{
+			name:      "array without times",
+			data:      ` [{"measurement": "cpu", "fields": {"usage": 1}}, {"measurement": "mem", "fields": {"state": "ok"}}]`,
+			precision: "ns",
+			want: []string{
+				"cpu usage=1 1590969600000000000",
+				`mem state="ok" 1590969600000000000`,
+			},
+		},
+		{
+			name:      "time in precision",
+			data:      `{"measurement": "cpu", "fields": {"usage": 1}, "time": 1600000000}`,
+			precision: "s",
+			want:      []string{"cpu usage=1 1600000000000000000"},
+		},
+		{
+			name:      "RFC3339 time",
+			data:      `{"measurement": "cpu", "fields": {"usage": 1}, "time": "2020-09-13T12:26:40Z"}`,
+			precision: "ns",
+			want:      []string{"cpu usage=1 1600000000000000000"},
+		},
+		{
+			name:    "missing measurement",
+			data:    `{"fields": {"usage": 1}}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing fields",
+			data:    `{"measurement": "cpu"}`,
+			wantErr: true,
+		},
+		{
+			name:    "object field",
+			data:    `{"measurement": "cpu", "fields": {"usage": {"user": 1}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid time",
+			data:    `{"measurement": "cpu", "fields": {"usage": 1}, "time": true}`,
+			wantErr: true,
+		},
+		{
+			name:    "not JSON",
+			data:    `cpu usage=1`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := parseJSON([]byte(tt.data), tt.precision, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(points) != len(tt.want) {
+				t.Fatalf("expected %d points, got %d", len(tt.want), len(points))
+			}
+			for i, p := range points {
+				if got := p.String(); got != tt.want[i] {
+					t.Errorf("unexpected point %q, want %q", got, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -13,7 +13,13 @@
 // The default implementation always returns the flag default configured
 // in `flags.yml`. The override implementation allows an operator to
 // override feature flag defaults at startup. Changing these overrides
-// requires a restart.
+// requires a restart, unlike the overrides set through the
+// `/api/v2/flags/overrides` endpoint, which apply at runtime to all the
+// requests or to those of the tokens of an organization.
+//
+// With `--feature-flag-request-overrides`, meant for development
+// environments, a request overrides the flags it is served with by its
+// `X-Influxdb-Feature-Flags` header, such as `myFeature=true,other=2`.
 //
 // In `influxd`, a `Flagger` instance is provided to a `Handler` middleware
 // configured to intercept all API requests and annotate their request context
@@ -0,0 +1,90 @@
+package override
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/feature"
+)
+
+// HeaderOverrides is the request header of the overrides of feature flags
+// for a single request, as comma-separated key=value pairs.
+const HeaderOverrides = "X-Influxdb-Feature-Flags"
+
+// NewRequestHandler returns a middleware that applies the overrides of the
+// HeaderOverrides header of requests over the flags annotated on their
+// context, so that the requests of development environments try features
+// out without changing the flags of the other requests. It must be executed
+// after the feature.Handler middleware. Invalid overrides are rejected.
+func NewRequestHandler(errorHandler influxdb.HTTPErrorHandler, byKey feature.ByKeyFn, next http.Handler) http.Handler {
+	if byKey == nil {
+		byKey = feature.ByKey
+	}
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get(HeaderOverrides)
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, err := annotateOverrides(r.Context(), header, byKey)
+		if err != nil {
+			errorHandler.HandleHTTPError(r.Context(), &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid " + HeaderOverrides + " header",
+				Err:  err,
+			}, w)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+	return http.HandlerFunc(fn)
+}
+
+// annotateOverrides annotates ctx with its flags, overridden by those of the
+// header.
+func annotateOverrides(ctx context.Context, header string, byKey feature.ByKeyFn) (context.Context, error) {
+	overrides := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "overrides must be comma-separated key=value pairs",
+			}
+		}
+		overrides[kv[0]] = kv[1]
+	}
+
+	f, err := Make(overrides, byKey)
+	if err != nil {
+		return nil, err
+	}
+	flags := make([]feature.Flag, 0, len(overrides))
+	for k := range overrides {
+		flag, _ := byKey(k)
+		flags = append(flags, flag)
+	}
+	values, err := f.Flags(ctx, flags...)
+	if err != nil {
+		return nil, err
+	}
+
+	computed := make(staticFlagger, len(values))
+	for k, v := range feature.FlagsFromContext(ctx) {
+		computed[k] = v
+	}
+	for k, v := range values {
+		computed[k] = v
+	}
+	return feature.Annotate(ctx, computed)
+}
+
+// staticFlagger returns the same flags for all the requests.
+type staticFlagger map[string]interface{}
+
+func (f staticFlagger) Flags(context.Context, ...feature.Flag) (map[string]interface{}, error) {
+	return f, nil
+}
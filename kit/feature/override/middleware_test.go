@@ -0,0 +1,89 @@
+package override
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/kit/feature"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRequestHandler(t *testing.T) {
+	var (
+		boolFlag   = newFlag("boolflag", false)
+		stringFlag = newFlag("stringflag", "original")
+		byKey      = newByKey(map[string]feature.Flag{
+			"boolflag":   boolFlag,
+			"stringflag": stringFlag,
+		})
+	)
+
+	cases := []struct {
+		name     string
+		header   string
+		expected map[string]interface{}
+		status   int
+	}{
+		{
+			name:     "no header",
+			expected: map[string]interface{}{"boolflag": false, "stringflag": "original"},
+			status:   http.StatusOK,
+		},
+		{
+			name:     "overrides",
+			header:   "boolflag=true, stringflag=new",
+			expected: map[string]interface{}{"boolflag": true, "stringflag": "new"},
+			status:   http.StatusOK,
+		},
+		{
+			name:   "unknown flag",
+			header: "missing=true",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid value",
+			header: "boolflag=maybe",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid pair",
+			header: "boolflag",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			var got map[string]interface{}
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = feature.FlagsFromContext(r.Context())
+			})
+			handler := feature.NewHandler(zaptest.NewLogger(t), feature.DefaultFlagger(), []feature.Flag{boolFlag, stringFlag},
+				NewRequestHandler(kithttp.ErrorHandler(0), byKey, next))
+
+			r := httptest.NewRequest(http.MethodGet, "http://nowhere.test", nil)
+			if test.header != "" {
+				r.Header.Set(HeaderOverrides, test.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != test.status {
+				t.Fatalf("expected status %d, got %d: %s", test.status, w.Code, w.Body.String())
+			}
+			if test.status != http.StatusOK {
+				return
+			}
+			if len(got) != len(test.expected) {
+				t.Fatalf("expected flags %v, got %v", test.expected, got)
+			}
+			for k, v := range test.expected {
+				if got[k] != v {
+					t.Errorf("expected %s to be %v, got %v", k, v, got[k])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// HeaderQueueDepth is the response header of the work queued ahead of
+	// the requests to a handler.
+	HeaderQueueDepth = "X-Influxdb-Queue-Depth"
+	// HeaderQueueCapacity is the response header of the work a handler
+	// queues before rejecting requests.
+	HeaderQueueCapacity = "X-Influxdb-Queue-Capacity"
+
+	// backpressureThreshold is the load of a queue from which the clients
+	// are asked to retry later.
+	backpressureThreshold = 0.8
+	// maxRetryAfter is the delay suggested to the clients of a full queue.
+	maxRetryAfter = 30 * time.Second
+)
+
+// Backpressure reports the load of the queue of a handler.
+type Backpressure interface {
+	// QueueDepth returns the work currently queued and the work queued
+	// before rejecting requests. The capacity is zero when unbounded.
+	QueueDepth() (depth, capacity int64)
+}
+
+// SetBackpressureHeaders sets the headers reporting the load of the queue of
+// b to the clients, so that they adapt the size and pace of their requests.
+// A Retry-After header suggests a delay once the queue is filling up.
+func SetBackpressureHeaders(w http.ResponseWriter, b Backpressure) {
+	if b == nil {
+		return
+	}
+
+	depth, capacity := b.QueueDepth()
+	w.Header().Set(HeaderQueueDepth, strconv.FormatInt(depth, 10))
+	if capacity <= 0 {
+		return
+	}
+	w.Header().Set(HeaderQueueCapacity, strconv.FormatInt(capacity, 10))
+
+	if retryAfter := RetryAfter(depth, capacity); retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter/time.Second)))
+	}
+}
+
+// RetryAfter returns the delay suggested to the clients of a queue holding
+// depth out of capacity, or zero if the queue is not filling up. The delay
+// grows linearly from a second at the threshold to maxRetryAfter when full,
+// rounded up to the second.
+func RetryAfter(depth, capacity int64) time.Duration {
+	if capacity <= 0 {
+		return 0
+	}
+	load := float64(depth) / float64(capacity)
+	if load < backpressureThreshold {
+		return 0
+	}
+	load = math.Min(load, 1)
+
+	seconds := 1 + (load-backpressureThreshold)/(1-backpressureThreshold)*(maxRetryAfter.Seconds()-1)
+	return time.Duration(math.Ceil(seconds)) * time.Second
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type backpressure struct {
+	depth, capacity int64
+}
+
+func (b backpressure) QueueDepth() (int64, int64) {
+	return b.depth, b.capacity
+}
+
+func TestSetBackpressureHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		b          Backpressure
+		depth      string
+		capacity   string
+		retryAfter string
+	}{
+		{name: "none"},
+		{name: "unbounded", b: backpressure{depth: 100}, depth: "100"},
+		{name: "idle", b: backpressure{depth: 0, capacity: 10}, depth: "0", capacity: "10"},
+		{name: "below threshold", b: backpressure{depth: 7, capacity: 10}, depth: "7", capacity: "10"},
+		{name: "at threshold", b: backpressure{depth: 8, capacity: 10}, depth: "8", capacity: "10", retryAfter: "1"},
+		{name: "full", b: backpressure{depth: 10, capacity: 10}, depth: "10", capacity: "10", retryAfter: "30"},
+		{name: "overflowing", b: backpressure{depth: 25, capacity: 10}, depth: "25", capacity: "10", retryAfter: "30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			SetBackpressureHeaders(rec, tt.b)
+
+			assert.Equal(t, tt.depth, rec.Header().Get(HeaderQueueDepth))
+			assert.Equal(t, tt.capacity, rec.Header().Get(HeaderQueueCapacity))
+			assert.Equal(t, tt.retryAfter, rec.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), RetryAfter(79, 100))
+	assert.Equal(t, time.Second, RetryAfter(80, 100))
+	assert.Equal(t, 16*time.Second, RetryAfter(90, 100))
+	assert.Equal(t, 30*time.Second, RetryAfter(100, 100))
+}
@@ -25,12 +25,14 @@ func (h ErrorHandler) HandleHTTPError(ctx context.Context, err error, w http.Res
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(ErrorCodeToStatusCode(ctx, code))
 	var e struct {
-		Code    string `json:"code"`
-		Message string `json:"message"`
+		Code     string                `json:"code"`
+		Message  string                `json:"message"`
+		Problems influxdb.FluxProblems `json:"problems,omitempty"`
 	}
 	e.Code = influxdb.ErrorCode(err)
 	if err, ok := err.(*influxdb.Error); ok {
 		e.Message = err.Error()
+		e.Problems, _ = err.Err.(influxdb.FluxProblems)
 	} else {
 		e.Message = "An internal error has occurred"
 	}
@@ -83,6 +85,7 @@ var influxDBErrorToStatusCode = map[string]int{
 	influxdb.EUnauthorized:        http.StatusUnauthorized,
 	influxdb.EMethodNotAllowed:    http.StatusMethodNotAllowed,
 	influxdb.ETooLarge:            http.StatusRequestEntityTooLarge,
+	influxdb.ENotAcceptable:       http.StatusNotAcceptable,
 }
 
 var httpStatusCodeToInfluxDBError = map[int]string{}
@@ -2,8 +2,12 @@ package http
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"path"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,22 +21,137 @@ import (
 // Middleware constructor.
 type Middleware func(http.Handler) http.Handler
 
-func SetCORS(next http.Handler) http.Handler {
-	fn := func(w http.ResponseWriter, r *http.Request) {
-		if origin := r.Header.Get("Origin"); origin != "" {
-			// Access-Control-Allow-Origin must be present in every response
-			w.Header().Set("Access-Control-Allow-Origin", origin)
+// CORSPolicy is the cross-origin resource sharing policy of a group of
+// routes.
+type CORSPolicy struct {
+	// AllowedOrigins are the origins allowed to request the routes. "*"
+	// allows any origin, and an origin with a "*" allows the origins matching
+	// it, such as https://*.example.com.
+	AllowedOrigins []string
+	// AllowedMethods and AllowedHeaders are the methods and headers of the
+	// requests allowed by the responses to preflight requests.
+	AllowedMethods []string
+	AllowedHeaders []string
+	// ExposedHeaders are the headers of the responses readable by the
+	// scripts of the origins.
+	ExposedHeaders []string
+	// AllowCredentials allows the requests with cookies, so that the web
+	// apps of the origins use the sessions of the users.
+	AllowCredentials bool
+	// MaxAge is the duration browsers cache the responses to preflight
+	// requests for; browsers use their default when zero.
+	MaxAge time.Duration
+}
+
+// DefaultCORSPolicy returns the policy allowing any origin to send the
+// requests of the API, without credentials.
+func DefaultCORSPolicy() CORSPolicy {
+	return CORSPolicy{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"POST", "GET", "OPTIONS", "PUT", "DELETE"},
+		AllowedHeaders: []string{"Accept", "Accept-Version", "Content-Type", "Content-Length", "Accept-Encoding", "Authorization", "User-Agent"},
+	}
+}
+
+// AllowOrigin returns true if the policy allows the origin.
+func (p CORSPolicy) AllowOrigin(origin string) bool {
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
 		}
-		if r.Method == http.MethodOptions {
-			// allow and stop processing in pre-flight requests
-			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, User-Agent")
-			w.WriteHeader(http.StatusNoContent)
-			return
+		if i := strings.Index(allowed, "*"); i >= 0 {
+			prefix, suffix := strings.ToLower(allowed[:i]), strings.ToLower(allowed[i+1:])
+			o := strings.ToLower(origin)
+			if len(o) > len(prefix)+len(suffix) && strings.HasPrefix(o, prefix) && strings.HasSuffix(o, suffix) {
+				return true
+			}
 		}
-		next.ServeHTTP(w, r)
 	}
-	return http.HandlerFunc(fn)
+	return false
+}
+
+// CORSPolicies are the CORS policies of the groups of routes of an API.
+type CORSPolicies struct {
+	// Default is the policy of the paths without an override.
+	Default CORSPolicy
+	// Overrides are the policies of the paths starting with their prefix.
+	// The longest matching prefix overrides the others.
+	Overrides map[string]CORSPolicy
+}
+
+// DefaultCORSPolicies returns the policies applying DefaultCORSPolicy to
+// every route.
+func DefaultCORSPolicies() CORSPolicies {
+	return CORSPolicies{Default: DefaultCORSPolicy()}
+}
+
+// Policy returns the policy of the requests to path p.
+func (ps CORSPolicies) Policy(p string) CORSPolicy {
+	policy, matched := ps.Default, ""
+	for prefix, override := range ps.Overrides {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if p != prefix && !strings.HasPrefix(p, prefix+"/") {
+			continue
+		}
+		if len(prefix) >= len(matched) {
+			policy, matched = override, prefix
+		}
+	}
+	return policy
+}
+
+type corsContextKey struct{}
+
+// CORS sets the CORS headers of the responses by the policy of their path,
+// and responds to preflight requests. The headers are only set for the
+// allowed origins, so that browsers deny the requests of the others. The
+// CORS middlewares of the handlers the requests are passed to leave the
+// headers set.
+func CORS(policies CORSPolicies) Middleware {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if handled, _ := r.Context().Value(corsContextKey{}).(bool); handled {
+				next.ServeHTTP(w, r)
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), corsContextKey{}, true))
+
+			policy := policies.Policy(r.URL.Path)
+			origin := r.Header.Get("Origin")
+			allowed := origin != "" && policy.AllowOrigin(origin)
+			if allowed {
+				// Access-Control-Allow-Origin must be present in every response
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Add("Vary", "Origin")
+				if policy.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+				if len(policy.ExposedHeaders) > 0 {
+					w.Header().Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+				}
+			}
+			if r.Method == http.MethodOptions {
+				// allow and stop processing in pre-flight requests
+				if allowed {
+					w.Header().Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+					w.Header().Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+					if policy.MaxAge > 0 {
+						w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(policy.MaxAge/time.Second)))
+					}
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// SetCORS applies DefaultCORSPolicy to the requests whose CORS headers are
+// not set by a CORS middleware already.
+func SetCORS(next http.Handler) http.Handler {
+	return CORS(DefaultCORSPolicies())(next)
 }
 
 func Metrics(name string, reqMetric *prometheus.CounterVec, durMetric *prometheus.HistogramVec) Middleware {
@@ -44,12 +163,11 @@ func Metrics(name string, reqMetric *prometheus.CounterVec, durMetric *prometheu
 				label := prometheus.Labels{
 					"handler":    name,
 					"method":     r.Method,
-					"path":       normalizePath(r.URL.Path),
+					"path":       RouteTemplate(r),
 					"status":     statusW.StatusCodeClass(),
 					"user_agent": UserAgent(r),
 				}
-				durMetric.With(label).Observe(time.Since(start).Seconds())
-				reqMetric.With(label).Inc()
+				observe(r.Context(), durMetric.With(label), reqMetric.With(label), time.Since(start).Seconds())
 			}(time.Now())
 
 			next.ServeHTTP(statusW, r)
@@ -58,6 +176,73 @@ func Metrics(name string, reqMetric *prometheus.CounterVec, durMetric *prometheu
 	}
 }
 
+// exemplarObserver and exemplarAdder are implemented by the metrics of the
+// prometheus clients supporting exemplars.
+type (
+	exemplarObserver interface {
+		ObserveWithExemplar(value float64, exemplar prometheus.Labels)
+	}
+	exemplarAdder interface {
+		AddWithExemplar(value float64, exemplar prometheus.Labels)
+	}
+)
+
+// observe records a request of duration dur, with the ID of its trace as
+// exemplar if the trace is sampled and the metrics support exemplars.
+func observe(ctx context.Context, durMetric prometheus.Observer, reqMetric prometheus.Counter, dur float64) {
+	traceID, sampled, found := tracing.InfoFromContext(ctx)
+	if !found || !sampled {
+		durMetric.Observe(dur)
+		reqMetric.Inc()
+		return
+	}
+
+	exemplar := prometheus.Labels{"trace_id": traceID}
+	if o, ok := durMetric.(exemplarObserver); ok {
+		o.ObserveWithExemplar(dur, exemplar)
+	} else {
+		durMetric.Observe(dur)
+	}
+	if a, ok := reqMetric.(exemplarAdder); ok {
+		a.AddWithExemplar(1, exemplar)
+	} else {
+		reqMetric.Inc()
+	}
+}
+
+// chiParam matches the parameters of chi route patterns, such as {id} or
+// {id:[0-9]+}.
+var chiParam = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// RouteTemplate returns the template of the route r was served by, such as
+// /api/v2/buckets/:id, so that requests can be grouped by route regardless
+// of the resources they address. It must be called once r was served. The
+// routes of chi routers are used as they are registered; the remainder of
+// the path of a handler mounted on a chi router, or the path of a request
+// no chi router served, is templated by replacing the IDs it contains.
+func RouteTemplate(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return normalizePath(r.URL.Path)
+	}
+
+	pattern := rctx.RoutePattern()
+	if pattern == "" {
+		return normalizePath(r.URL.Path)
+	}
+	pattern = chiParam.ReplaceAllString(pattern, ":$1")
+	if !strings.HasSuffix(pattern, "/*") {
+		return pattern
+	}
+
+	prefix := strings.TrimSuffix(pattern, "/*")
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		// the prefix has parameters, so the path is templated as a whole.
+		return normalizePath(r.URL.Path)
+	}
+	return path.Join(prefix, normalizePath(strings.TrimPrefix(r.URL.Path, prefix)))
+}
+
 func SkipOptions(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		// Preflight CORS requests from the browser will send an options request,
@@ -93,11 +278,95 @@ func Trace(name string) Middleware {
 			}
 
 			next.ServeHTTP(w, r)
+			span.SetTag("route", RouteTemplate(r))
 		}
 		return http.HandlerFunc(fn)
 	}
 }
 
+// BodyLimits are the maximum sizes in bytes of the bodies of the requests to
+// an API. A limit of zero does not limit the bodies.
+type BodyLimits struct {
+	// Default is the limit of the requests to the paths without an override.
+	Default int64
+	// Overrides are the limits of the requests to the paths starting with
+	// their prefix. The longest matching prefix overrides the others.
+	Overrides map[string]int64
+}
+
+// Limit returns the limit of the bodies of the requests to path p.
+func (l BodyLimits) Limit(p string) int64 {
+	limit, matched := l.Default, ""
+	for prefix, n := range l.Overrides {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if p != prefix && !strings.HasPrefix(p, prefix+"/") {
+			continue
+		}
+		if len(prefix) >= len(matched) {
+			limit, matched = n, prefix
+		}
+	}
+	return limit
+}
+
+// LimitBody limits the size of the bodies of the requests to the limit of
+// their path. The requests announcing a larger body are rejected with a 413
+// before any of it is read, and reading the body of the other requests fails
+// with a request too large error once the limit is exceeded, so that no
+// handler buffers more than the limit.
+func LimitBody(api *API, limits BodyLimits) Middleware {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			limit := limits.Limit(r.URL.Path)
+			if limit <= 0 || r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.ContentLength > limit {
+				api.Err(w, r, errBodyTooLarge(limit))
+				return
+			}
+
+			r.Body = &limitedBody{ReadCloser: r.Body, limit: limit, remaining: limit}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func errBodyTooLarge(limit int64) error {
+	return &influxdb.Error{
+		Code: influxdb.ETooLarge,
+		Msg:  fmt.Sprintf("request body exceeds the limit of %d bytes", limit),
+	}
+}
+
+// limitedBody is a request body failing once more than limit bytes are read.
+type limitedBody struct {
+	io.ReadCloser
+	limit     int64
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, errBodyTooLarge(b.limit)
+	}
+	// Read one more byte than remaining to tell a body of exactly the limit
+	// from a larger one.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) <= b.remaining {
+		b.remaining -= int64(n)
+		return n, err
+	}
+	n, b.remaining = int(b.remaining), -1
+	return n, errBodyTooLarge(b.limit)
+}
+
 func UserAgent(r *http.Request) string {
 	header := r.Header.Get("User-Agent")
 	if header == "" {
@@ -1,10 +1,15 @@
 package http
 
 import (
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"path"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi"
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/pkg/testttp"
 	"github.com/stretchr/testify/assert"
@@ -46,6 +51,64 @@ func Test_normalizePath(t *testing.T) {
 	}
 }
 
+func TestRouteTemplate(t *testing.T) {
+	id := influxdb.ID(2).String()
+	var template string
+	record := func(w http.ResponseWriter, r *http.Request) {}
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			template = RouteTemplate(r)
+		})
+	})
+	r.Get("/api/v2/buckets/{id}", record)
+	r.Route("/api/v2/orgs/{orgID}", func(r chi.Router) {
+		r.Get("/members/{userID:[0-9a-f]+}", record)
+	})
+	r.Mount("/api/v2/checks", http.HandlerFunc(record))
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "chi route",
+			path:     "/api/v2/buckets/" + id,
+			expected: "/api/v2/buckets/:id",
+		},
+		{
+			name:     "nested chi route",
+			path:     path.Join("/api/v2/orgs", id, "members", id),
+			expected: "/api/v2/orgs/:orgID/members/:userID",
+		},
+		{
+			name:     "mounted handler",
+			path:     path.Join("/api/v2/checks", id, "labels"),
+			expected: "/api/v2/checks/:id/labels",
+		},
+		{
+			name:     "mounted handler root",
+			path:     "/api/v2/checks",
+			expected: "/api/v2/checks",
+		},
+		{
+			name:     "no route",
+			path:     path.Join("/api/v2/unknown", id),
+			expected: "/api/v2/unknown/:id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template = ""
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.expected, template)
+		})
+	}
+}
+
 func TestCors(t *testing.T) {
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("nextHandler"))
@@ -92,3 +155,172 @@ func TestCors(t *testing.T) {
 		})
 	}
 }
+
+func TestCORSPolicies(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("nextHandler"))
+	})
+	policies := CORSPolicies{
+		Default: CORSPolicy{
+			AllowedOrigins:   []string{"https://*.example.com"},
+			AllowedMethods:   []string{"GET", "POST"},
+			AllowedHeaders:   []string{"Content-Type"},
+			AllowCredentials: true,
+			MaxAge:           10 * time.Minute,
+		},
+		Overrides: map[string]CORSPolicy{
+			"/api/v2/write": DefaultCORSPolicy(),
+		},
+	}
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		headers         []string
+		expectedStatus  int
+		expectedHeaders map[string]string
+		absentHeaders   []string
+	}{
+		{
+			name:           "preflight of allowed origin",
+			method:         "OPTIONS",
+			path:           "/api/v2/dashboards",
+			headers:        []string{"Origin", "https://app.example.com"},
+			expectedStatus: http.StatusNoContent,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Methods":     "GET, POST",
+				"Access-Control-Allow-Headers":     "Content-Type",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Max-Age":           "600",
+			},
+		},
+		{
+			name:           "preflight of denied origin",
+			method:         "OPTIONS",
+			path:           "/api/v2/dashboards",
+			headers:        []string{"Origin", "https://example.org"},
+			expectedStatus: http.StatusNoContent,
+			absentHeaders:  []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Methods"},
+		},
+		{
+			name:           "GET of denied origin",
+			method:         "GET",
+			path:           "/api/v2/dashboards",
+			headers:        []string{"Origin", "https://example.com.evil.org"},
+			expectedStatus: http.StatusOK,
+			absentHeaders:  []string{"Access-Control-Allow-Origin"},
+		},
+		{
+			name:           "POST to override",
+			method:         "POST",
+			path:           "/api/v2/write",
+			headers:        []string{"Origin", "https://example.org"},
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "https://example.org",
+			},
+			absentHeaders: []string{"Access-Control-Allow-Credentials"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The default policy of the inner middleware leaves the headers
+			// of the outer one.
+			svr := SkipOptions(CORS(policies)(SetCORS(nextHandler)))
+
+			resp := testttp.
+				HTTP(t, tt.method, tt.path, nil).
+				Headers("", "", tt.headers...).
+				Do(svr).
+				ExpectStatus(tt.expectedStatus).
+				ExpectHeaders(tt.expectedHeaders)
+			for _, k := range tt.absentHeaders {
+				if v := resp.Rec.Header().Get(k); v != "" {
+					t.Errorf("unexpected header %s: %q", k, v)
+				}
+			}
+		})
+	}
+}
+
+func TestBodyLimits_Limit(t *testing.T) {
+	limits := BodyLimits{
+		Default: 10,
+		Overrides: map[string]int64{
+			"/api/v2/write":           0,
+			"/api/v2/templates/":      100,
+			"/api/v2/templates/apply": 1000,
+		},
+	}
+
+	tests := []struct {
+		path     string
+		expected int64
+	}{
+		{path: "/api/v2/dashboards", expected: 10},
+		{path: "/api/v2/write", expected: 0},
+		{path: "/api/v2/writes", expected: 10},
+		{path: "/api/v2/templates", expected: 100},
+		{path: "/api/v2/templates/export", expected: 100},
+		{path: "/api/v2/templates/apply", expected: 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, limits.Limit(tt.path))
+		})
+	}
+}
+
+func TestLimitBody(t *testing.T) {
+	api := NewAPI()
+	svr := LimitBody(api, BodyLimits{Default: 5})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			api.Err(w, r, err)
+			return
+		}
+		w.Write(b)
+	}))
+
+	tests := []struct {
+		name           string
+		body           string
+		contentLength  int64
+		expectedStatus int
+	}{
+		{
+			name:           "body within the limit",
+			body:           "12345",
+			contentLength:  5,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "announced body over the limit",
+			body:           "123456",
+			contentLength:  6,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "streamed body over the limit",
+			body:           "123456",
+			contentLength:  -1,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v2/dashboards", strings.NewReader(tt.body))
+			req.ContentLength = tt.contentLength
+			w := httptest.NewRecorder()
+
+			svr.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.body, w.Body.String())
+			}
+		})
+	}
+}
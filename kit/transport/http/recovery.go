@@ -0,0 +1,223 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// HeaderCorrelationID is the response header of the ID correlating a
+	// response to the logs and reports of the panic that caused it.
+	HeaderCorrelationID = "X-Influxdb-Correlation-Id"
+
+	// fingerprintFrames is the number of frames from the site of a panic
+	// that identify it.
+	fingerprintFrames = 8
+	// maxPanicFrames is the maximum number of frames of a panic reported.
+	maxPanicFrames = 64
+)
+
+// Panic is a panic recovered from an HTTP handler.
+type Panic struct {
+	// CorrelationID is returned to the client in the response to the
+	// request.
+	CorrelationID string
+	// Fingerprint identifies the site of the panic, so that the panics of
+	// the same bug are grouped.
+	Fingerprint string
+	Value       interface{}
+	// Frames is the stack of the panic, starting from its site.
+	Frames []runtime.Frame
+	Method string
+	URL    string
+	Time   time.Time
+}
+
+// PanicReporter reports the panics recovered from HTTP handlers, to an error
+// tracking service for instance. ReportPanic must not block.
+type PanicReporter interface {
+	ReportPanic(p *Panic)
+}
+
+type recovererContextKey struct{}
+
+// Recoverer converts the panics of HTTP handlers into responses with status
+// code 500, instead of closing the connections of their requests. Each panic
+// is logged with a correlation ID returned to the client, counted by the
+// fingerprint of its stack and reported.
+type Recoverer struct {
+	log          *zap.Logger
+	errorHandler influxdb.HTTPErrorHandler
+	reporters    []PanicReporter
+
+	panics *prometheus.CounterVec
+}
+
+// NewRecoverer creates a Recoverer reporting the panics to reporters.
+func NewRecoverer(log *zap.Logger, reporters ...PanicReporter) *Recoverer {
+	return &Recoverer{
+		log:          log.With(zap.String("handler", "panic")),
+		errorHandler: ErrorHandler(0),
+		reporters:    reporters,
+		panics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "http",
+			Subsystem: "api",
+			Name:      "panics_total",
+			Help:      "Number of panics recovered from http handlers, by fingerprint of their stack",
+		}, []string{"fingerprint"}),
+	}
+}
+
+// PrometheusCollectors satisfies prom.PrometheusCollector.
+func (rc *Recoverer) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{rc.panics}
+}
+
+// Middleware recovers the panics of next. The handlers of next that recover
+// panics themselves delegate to the Recoverer found with RecovererFromContext.
+func (rc *Recoverer) Middleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rcv := recover()
+			if rcv == nil {
+				return
+			}
+			// The handler aborted the response on purpose.
+			if rcv == http.ErrAbortHandler {
+				panic(rcv)
+			}
+			rc.Recover(w, r, rcv)
+		}()
+		ctx := context.WithValue(r.Context(), recovererContextKey{}, rc)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+	return http.HandlerFunc(fn)
+}
+
+// RecovererFromContext returns the Recoverer of the middleware serving the
+// request of ctx, if any.
+func RecovererFromContext(ctx context.Context) (*Recoverer, bool) {
+	rc, ok := ctx.Value(recovererContextKey{}).(*Recoverer)
+	return rc, ok
+}
+
+// Recover handles the value rcv recovered from the handler of r. It must be
+// called by the deferred function that recovered the panic, so that the
+// stack of the panic is captured.
+func (rc *Recoverer) Recover(w http.ResponseWriter, r *http.Request, rcv interface{}) {
+	p := &Panic{
+		CorrelationID: correlationID(r.Context()),
+		Value:         rcv,
+		Frames:        panicFrames(),
+		Method:        r.Method,
+		URL:           r.URL.String(),
+		Time:          time.Now(),
+	}
+	p.Fingerprint = fingerprint(p.Frames)
+
+	rc.panics.WithLabelValues(p.Fingerprint).Inc()
+
+	pe := &influxdb.Error{
+		Code: influxdb.EInternal,
+		Msg:  fmt.Sprintf("a panic has occurred, correlation ID %s", p.CorrelationID),
+		Err:  fmt.Errorf("%s: %v", p.URL, rcv),
+	}
+	if entry := rc.log.Check(zapcore.ErrorLevel, "A panic has occurred"); entry != nil {
+		entry.Stack = formatFrames(p.Frames)
+		entry.Write(
+			zap.String("correlation_id", p.CorrelationID),
+			zap.String("fingerprint", p.Fingerprint),
+			zap.Error(pe.Err),
+		)
+	}
+	for _, reporter := range rc.reporters {
+		reporter.ReportPanic(p)
+	}
+
+	w.Header().Set(HeaderCorrelationID, p.CorrelationID)
+	rc.errorHandler.HandleHTTPError(r.Context(), pe, w)
+}
+
+// correlationID returns the ID of the trace of ctx if it is sampled, so that
+// the panic is found with the trace of its request, or a random ID.
+func correlationID(ctx context.Context) string {
+	if traceID, sampled, found := tracing.InfoFromContext(ctx); found && sampled {
+		return traceID
+	}
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// panicFrames returns the frames of the stack of the current goroutine from
+// the site of the panic being recovered, or all of them if there is none.
+func panicFrames() []runtime.Frame {
+	pcs := make([]uintptr, 128)
+	n := runtime.Callers(1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var all []runtime.Frame
+	site := -1
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" && site < 0 {
+			site = len(all) + 1
+		}
+		all = append(all, frame)
+		if !more {
+			break
+		}
+	}
+	if site < 0 || site >= len(all) {
+		site = 0
+	}
+	all = all[site:]
+
+	// The runtime functions raising panics, such as the ones of nil
+	// dereferences, are not part of the site of the panic.
+	for len(all) > 1 && strings.HasPrefix(all[0].Function, "runtime.") {
+		all = all[1:]
+	}
+	if len(all) > maxPanicFrames {
+		all = all[:maxPanicFrames]
+	}
+	return all
+}
+
+// fingerprint returns the hash of the functions of the frames closest to the
+// site of a panic. The lines are left out, so that the fingerprint of a bug
+// does not change when unrelated code moves.
+func fingerprint(frames []runtime.Frame) string {
+	h := sha256.New()
+	for i, frame := range frames {
+		if i == fingerprintFrames {
+			break
+		}
+		h.Write([]byte(frame.Function))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+func formatFrames(frames []runtime.Frame) string {
+	var b strings.Builder
+	for _, frame := range frames {
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+	return b.String()
+}
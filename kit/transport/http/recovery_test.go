@@ -0,0 +1,138 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap/zaptest"
+)
+
+type reporterFunc func(p *Panic)
+
+func (fn reporterFunc) ReportPanic(p *Panic) { fn(p) }
+
+func panicking(w http.ResponseWriter, r *http.Request) {
+	var m map[string]int
+	m[r.URL.Path]++
+}
+
+func TestRecoverer(t *testing.T) {
+	var reported []*Panic
+	rc := NewRecoverer(zaptest.NewLogger(t), reporterFunc(func(p *Panic) {
+		reported = append(reported, p)
+	}))
+	h := rc.Middleware(http.HandlerFunc(panicking))
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v2/buckets", nil))
+
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("expected status 500, got %d", w.Code)
+		}
+		var body struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		id := w.Header().Get(HeaderCorrelationID)
+		if id == "" || !strings.Contains(body.Message, id) {
+			t.Errorf("expected the correlation ID %q in the message %q", id, body.Message)
+		}
+		if body.Code != "internal error" {
+			t.Errorf("unexpected code %q", body.Code)
+		}
+	}
+
+	if len(reported) != 2 {
+		t.Fatalf("expected 2 panics to be reported, got %d", len(reported))
+	}
+	p := reported[0]
+	if p.CorrelationID == reported[1].CorrelationID {
+		t.Error("expected each panic to have its own correlation ID")
+	}
+	if p.Fingerprint != reported[1].Fingerprint {
+		t.Error("expected the panics of the same site to have the same fingerprint")
+	}
+	if len(p.Frames) == 0 || !strings.HasSuffix(p.Frames[0].Function, ".panicking") {
+		t.Errorf("expected the stack to start at the site of the panic, got %+v", p.Frames)
+	}
+	if p.Method != http.MethodGet || p.URL != "/api/v2/buckets" {
+		t.Errorf("unexpected request %s %s", p.Method, p.URL)
+	}
+
+	if got := testutil.ToFloat64(rc.panics.WithLabelValues(p.Fingerprint)); got != 2 {
+		t.Errorf("expected 2 panics counted, got %v", got)
+	}
+}
+
+func TestRecoverer_Abort(t *testing.T) {
+	rc := NewRecoverer(zaptest.NewLogger(t))
+	h := rc.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if rcv := recover(); rcv != http.ErrAbortHandler {
+			t.Errorf("expected the abort of the handler to be left to the server, got %v", rcv)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestSentryReporter(t *testing.T) {
+	events := make(chan sentryEvent, 1)
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sentry/api/42/store/" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		auth = r.Header.Get("X-Sentry-Auth")
+		var e sentryEvent
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Error(err)
+		}
+		events <- e
+	}))
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "http://", "http://public@", 1) + "/sentry/42"
+	s, err := NewSentryReporter(zaptest.NewLogger(t), dsn, map[string]string{"version": "2.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reported *Panic
+	rc := NewRecoverer(zaptest.NewLogger(t), reporterFunc(func(p *Panic) {
+		reported = p
+		s.ReportPanic(p)
+	}))
+	rc.Middleware(http.HandlerFunc(panicking)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v2/buckets", nil))
+
+	e := <-events
+	if !strings.Contains(auth, "sentry_key=public") {
+		t.Errorf("unexpected auth %q", auth)
+	}
+	if len(e.EventID) != 32 || e.Tags["correlation_id"] != reported.CorrelationID || e.Tags["version"] != "2.0.0" {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if len(e.Fingerprint) != 1 || e.Fingerprint[0] != reported.Fingerprint {
+		t.Errorf("unexpected fingerprint %v", e.Fingerprint)
+	}
+	frames := e.Exception.Values[0].Stacktrace.Frames
+	if len(frames) == 0 || !strings.HasSuffix(frames[len(frames)-1].Function, ".panicking") {
+		t.Errorf("expected the site of the panic to be the last frame, got %+v", frames)
+	}
+
+	for _, dsn := range []string{"", "https://sentry.io/42", "https://public@sentry.io/", "sentry.io/42"} {
+		if _, err := NewSentryReporter(zaptest.NewLogger(t), dsn, nil); err == nil {
+			t.Errorf("expected DSN %q to be invalid", dsn)
+		}
+	}
+}
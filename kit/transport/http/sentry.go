@@ -0,0 +1,211 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// maxSentryReports is the number of reports sent concurrently, beyond
+	// which the panics are not reported.
+	maxSentryReports = 4
+	sentryTimeout    = 10 * time.Second
+)
+
+// SentryReporter reports panics to the store API of Sentry, or of the error
+// tracking services compatible with it. Panics are reported in the
+// background, and dropped when too many reports are pending.
+type SentryReporter struct {
+	log      *zap.Logger
+	client   *http.Client
+	storeURL string
+	auth     string
+	tags     map[string]string
+
+	reports chan struct{}
+}
+
+// NewSentryReporter creates a SentryReporter sending the panics to the
+// project of dsn, as https://<key>@<host>/<project>. The reports are tagged
+// with tags.
+func NewSentryReporter(log *zap.Logger, dsn string, tags map[string]string) (*SentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sentry DSN: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid sentry DSN: scheme and host are required")
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("invalid sentry DSN: key is required")
+	}
+	prefix, project := path.Split(strings.TrimSuffix(u.Path, "/"))
+	if project == "" {
+		return nil, fmt.Errorf("invalid sentry DSN: project is required")
+	}
+
+	auth := fmt.Sprintf("Sentry sentry_version=7, sentry_client=influxdb/1.0, sentry_key=%s", u.User.Username())
+	if secret, ok := u.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+
+	store := url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   path.Join(prefix, "api", project, "store") + "/",
+	}
+	return &SentryReporter{
+		log:      log,
+		client:   &http.Client{Timeout: sentryTimeout},
+		storeURL: store.String(),
+		auth:     auth,
+		tags:     tags,
+		reports:  make(chan struct{}, maxSentryReports),
+	}, nil
+}
+
+// ReportPanic sends p to Sentry in the background.
+func (s *SentryReporter) ReportPanic(p *Panic) {
+	select {
+	case s.reports <- struct{}{}:
+	default:
+		s.log.Warn("Too many panics being reported, dropping panic", zap.String("correlation_id", p.CorrelationID))
+		return
+	}
+
+	event := s.event(p)
+	go func() {
+		defer func() { <-s.reports }()
+		if err := s.send(event); err != nil {
+			s.log.Warn("Failed to report panic", zap.String("correlation_id", p.CorrelationID), zap.Error(err))
+		}
+	}()
+}
+
+type (
+	sentryEvent struct {
+		EventID     string            `json:"event_id"`
+		Timestamp   string            `json:"timestamp"`
+		Level       string            `json:"level"`
+		Platform    string            `json:"platform"`
+		Logger      string            `json:"logger"`
+		Message     string            `json:"message"`
+		Fingerprint []string          `json:"fingerprint"`
+		Tags        map[string]string `json:"tags"`
+		Request     sentryRequest     `json:"request"`
+		Exception   sentryExceptions  `json:"exception"`
+	}
+
+	sentryRequest struct {
+		URL    string `json:"url"`
+		Method string `json:"method"`
+	}
+
+	sentryExceptions struct {
+		Values []sentryException `json:"values"`
+	}
+
+	sentryException struct {
+		Type       string           `json:"type"`
+		Value      string           `json:"value"`
+		Stacktrace sentryStacktrace `json:"stacktrace"`
+	}
+
+	sentryStacktrace struct {
+		Frames []sentryFrame `json:"frames"`
+	}
+
+	sentryFrame struct {
+		Function string `json:"function"`
+		Filename string `json:"filename"`
+		Lineno   int    `json:"lineno"`
+	}
+)
+
+func (s *SentryReporter) event(p *Panic) *sentryEvent {
+	tags := map[string]string{"correlation_id": p.CorrelationID}
+	for k, v := range s.tags {
+		tags[k] = v
+	}
+
+	// Sentry expects the frames from the outermost call.
+	frames := make([]sentryFrame, len(p.Frames))
+	for i, frame := range p.Frames {
+		frames[len(frames)-1-i] = sentryFrame{
+			Function: frame.Function,
+			Filename: frame.File,
+			Lineno:   frame.Line,
+		}
+	}
+
+	value := fmt.Sprint(p.Value)
+	return &sentryEvent{
+		EventID:     sentryEventID(p.CorrelationID),
+		Timestamp:   p.Time.UTC().Format("2006-01-02T15:04:05"),
+		Level:       "error",
+		Platform:    "go",
+		Logger:      "http",
+		Message:     "a panic has occurred: " + value,
+		Fingerprint: []string{p.Fingerprint},
+		Tags:        tags,
+		Request: sentryRequest{
+			URL:    p.URL,
+			Method: p.Method,
+		},
+		Exception: sentryExceptions{
+			Values: []sentryException{{
+				Type:       fmt.Sprintf("panic(%T)", p.Value),
+				Value:      value,
+				Stacktrace: sentryStacktrace{Frames: frames},
+			}},
+		},
+	}
+}
+
+// sentryEventID returns the correlation ID id as the ID of an event, which
+// is 32 hexadecimal characters. The IDs of traces may be shorter.
+func sentryEventID(id string) string {
+	if _, err := hex.DecodeString(id); err != nil || len(id) > 32 {
+		b := sha256.Sum256([]byte(id))
+		return hex.EncodeToString(b[:16])
+	}
+	return strings.Repeat("0", 32-len(id)) + id
+}
+
+func (s *SentryReporter) send(event *sentryEvent) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sentryTimeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodPost, s.storeURL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
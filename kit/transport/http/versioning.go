@@ -0,0 +1,139 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+const (
+	// HeaderAcceptVersion is the request header listing the versions of the
+	// API a client accepts, most preferred first.
+	HeaderAcceptVersion = "Accept-Version"
+	// HeaderContentVersion is the response header of the version of the API
+	// a response is shaped by.
+	HeaderContentVersion = "Content-Version"
+)
+
+// Deprecation deprecates the routes starting with a prefix, or only the
+// versions of their responses shaped by Version.
+type Deprecation struct {
+	// Prefix is the prefix of the paths of the deprecated routes.
+	Prefix string
+	// Version is the deprecated version of the responses of the routes.
+	// Every version is deprecated when empty.
+	Version string
+	// Since is when the routes were deprecated. It is only reported as
+	// deprecated when zero.
+	Since time.Time
+	// Sunset is when the routes stop being served, if known.
+	Sunset time.Time
+	// Link is the documentation of the deprecation or of the routes
+	// succeeding the deprecated ones.
+	Link string
+}
+
+// matches returns true if the response of a request to path p shaped by
+// version is deprecated.
+func (d Deprecation) matches(p, version string) bool {
+	prefix := strings.TrimSuffix(d.Prefix, "/")
+	if p != prefix && !strings.HasPrefix(p, prefix+"/") {
+		return false
+	}
+	return d.Version == "" || d.Version == version
+}
+
+// Versioning is the versions of the shapes of the responses of an API and
+// its deprecated routes, so that breaking changes to the responses are opted
+// into by clients instead of breaking the existing ones.
+type Versioning struct {
+	// Versions are the versions of the responses, oldest first. The requests
+	// without an Accept-Version header get the oldest version.
+	Versions []string
+	// Deprecations are the deprecated routes.
+	Deprecations []Deprecation
+}
+
+// negotiate returns the version of the response to a request accepting
+// accept, the value of its Accept-Version header.
+func (v Versioning) negotiate(accept string) (string, error) {
+	if len(v.Versions) == 0 {
+		return "", nil
+	}
+	if strings.TrimSpace(accept) == "" {
+		return v.Versions[0], nil
+	}
+
+	for _, a := range strings.Split(accept, ",") {
+		a = strings.TrimSpace(a)
+		if a == "*" {
+			return v.Versions[len(v.Versions)-1], nil
+		}
+		for _, version := range v.Versions {
+			if a == version {
+				return version, nil
+			}
+		}
+	}
+	return "", &influxdb.Error{
+		Code: influxdb.ENotAcceptable,
+		Msg:  fmt.Sprintf("none of the versions %q is supported, expected one of %s", accept, strings.Join(v.Versions, ", ")),
+	}
+}
+
+// Version negotiates the version of the responses with the Accept-Version
+// header of the requests, and reports the deprecated routes with the
+// Deprecation, Sunset and Link headers of their responses. The handlers get
+// the negotiated version with APIVersionFromContext.
+func Version(api *API, v Versioning) Middleware {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", HeaderAcceptVersion)
+
+			version, err := v.negotiate(r.Header.Get(HeaderAcceptVersion))
+			if err != nil {
+				api.Err(w, r, err)
+				return
+			}
+			if version != "" {
+				w.Header().Set(HeaderContentVersion, version)
+			}
+
+			for _, d := range v.Deprecations {
+				if !d.matches(r.URL.Path, version) {
+					continue
+				}
+				deprecation := "true"
+				if !d.Since.IsZero() {
+					deprecation = d.Since.UTC().Format(http.TimeFormat)
+				}
+				w.Header().Set("Deprecation", deprecation)
+				if !d.Sunset.IsZero() {
+					w.Header().Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+				}
+				if d.Link != "" {
+					w.Header().Add("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"", d.Link))
+				}
+				break
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxAPIVersionKey, version)))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+type apiVersionContext string
+
+const ctxAPIVersionKey apiVersionContext = "apiVersion"
+
+// APIVersionFromContext returns the version of the API negotiated for a
+// request, or the empty string if the API is not versioned.
+func APIVersionFromContext(ctx context.Context) string {
+	v, _ := ctx.Value(ctxAPIVersionKey).(string)
+	return v
+}
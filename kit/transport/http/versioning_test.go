@@ -0,0 +1,108 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion(t *testing.T) {
+	sunset := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	versioning := Versioning{
+		Versions: []string{"2.0", "2.1"},
+		Deprecations: []Deprecation{
+			{Prefix: "/api/v2/old", Sunset: sunset, Link: "https://docs.example.com/new"},
+			{Prefix: "/api/v2/members", Version: "2.0"},
+		},
+	}
+
+	var gotVersion string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotVersion = APIVersionFromContext(r.Context())
+	})
+	h := Version(NewAPI(), versioning)(next)
+
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		status      int
+		version     string
+		deprecation string
+		sunset      string
+		link        string
+	}{
+		{
+			name:    "oldest version by default",
+			path:    "/api/v2/buckets",
+			status:  http.StatusOK,
+			version: "2.0",
+		},
+		{
+			name:    "first accepted version",
+			path:    "/api/v2/buckets",
+			accept:  "3.0, 2.1, 2.0",
+			status:  http.StatusOK,
+			version: "2.1",
+		},
+		{
+			name:    "any version",
+			path:    "/api/v2/buckets",
+			accept:  "*",
+			status:  http.StatusOK,
+			version: "2.1",
+		},
+		{
+			name:   "unsupported version",
+			path:   "/api/v2/buckets",
+			accept: "3.0",
+			status: http.StatusNotAcceptable,
+		},
+		{
+			name:        "deprecated route",
+			path:        "/api/v2/old/123",
+			status:      http.StatusOK,
+			version:     "2.0",
+			deprecation: "true",
+			sunset:      "Tue, 01 Jun 2021 00:00:00 GMT",
+			link:        `<https://docs.example.com/new>; rel="deprecation"`,
+		},
+		{
+			name:        "deprecated version",
+			path:        "/api/v2/members",
+			status:      http.StatusOK,
+			version:     "2.0",
+			deprecation: "true",
+		},
+		{
+			name:    "current version of deprecated version",
+			path:    "/api/v2/members",
+			accept:  "2.1",
+			status:  http.StatusOK,
+			version: "2.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotVersion = ""
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set(HeaderAcceptVersion, tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.version, gotVersion)
+			assert.Equal(t, tt.version, rec.Header().Get(HeaderContentVersion))
+			assert.Equal(t, tt.deprecation, rec.Header().Get("Deprecation"))
+			assert.Equal(t, tt.sunset, rec.Header().Get("Sunset"))
+			assert.Equal(t, tt.link, rec.Header().Get("Link"))
+			assert.Equal(t, HeaderAcceptVersion, rec.Header().Get("Vary"))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.AccessibleResourceService = (*Service)(nil)
+
+// accessibleResourceBuckets are the buckets of the resources whose access is
+// reported by FindAccessibleResources.
+var accessibleResourceBuckets = map[influxdb.ResourceType][]byte{
+	influxdb.OrgsResourceType:                 organizationBucket,
+	influxdb.BucketsResourceType:              bucketBucket,
+	influxdb.DashboardsResourceType:           dashboardBucket,
+	influxdb.TasksResourceType:                taskBucket,
+	influxdb.TelegrafsResourceType:            telegrafBucket,
+	influxdb.ChecksResourceType:               checkBucket,
+	influxdb.NotificationRuleResourceType:     notificationRuleBucket,
+	influxdb.NotificationEndpointResourceType: notificationEndpointBucket,
+	influxdb.VariablesResourceType:            variableBucket,
+	influxdb.LabelsResourceType:               labelBucket,
+}
+
+// resourceRecord are the fields shared by the records of all resources. The
+// IDs are decoded as strings since some records have an empty organization.
+type resourceRecord struct {
+	ID    string `json:"id"`
+	OrgID string `json:"orgID"`
+	Name  string `json:"name"`
+}
+
+// FindAccessibleResources returns the resources the permissions ps of the
+// token of the user allow it to access, grouped by type, along with the user
+// resource mappings and organizations it accesses them through. Resources of types that are not stored in the
+// metadata store are not reported.
+func (s *Service) FindAccessibleResources(ctx context.Context, userID influxdb.ID, ps influxdb.PermissionSet) (map[influxdb.ResourceType][]*influxdb.AccessibleResource, error) {
+	resources := make(map[influxdb.ResourceType][]*influxdb.AccessibleResource)
+	err := s.kv.View(ctx, func(tx Tx) error {
+		mapped := make(map[influxdb.ID]influxdb.UserType)
+		if userID.Valid() {
+			urms, err := s.findUserResourceMappings(ctx, tx, influxdb.UserResourceMappingFilter{UserID: userID})
+			if err != nil {
+				return err
+			}
+			for _, m := range urms {
+				mapped[m.ResourceID] = m.UserType
+			}
+		}
+
+		for rt, bucket := range accessibleResourceBuckets {
+			b, err := tx.Bucket(bucket)
+			if err != nil {
+				return err
+			}
+			if err := forEach(b, func(k, v []byte) error {
+				var rec resourceRecord
+				if err := json.Unmarshal(v, &rec); err != nil {
+					return &influxdb.Error{Code: influxdb.EInternal, Err: err}
+				}
+				r := &influxdb.AccessibleResource{Name: rec.Name}
+				if err := r.ID.DecodeFromString(rec.ID); err != nil {
+					return nil
+				}
+				// Organizations do not belong to an organization.
+				if rt != influxdb.OrgsResourceType {
+					_ = r.OrgID.DecodeFromString(rec.OrgID)
+				}
+				if grantAccess(rt, r, mapped, ps) {
+					resources[rt] = append(resources[rt], r)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// grantAccess sets the access to the resource r of type rt given by the
+// mappings of the user, limited to what the permissions ps of its token
+// allow. It returns false if there is none. The mappings of a user do not
+// give a token more access than its permissions.
+func grantAccess(rt influxdb.ResourceType, r *influxdb.AccessibleResource, mapped map[influxdb.ID]influxdb.UserType, ps influxdb.PermissionSet) bool {
+	perm := func(a influxdb.Action) influxdb.Permission {
+		p := influxdb.Permission{
+			Action:   a,
+			Resource: influxdb.Resource{Type: rt, ID: &r.ID},
+		}
+		if r.OrgID.Valid() {
+			p.Resource.OrgID = &r.OrgID
+		}
+		return p
+	}
+	switch {
+	case ps.Allowed(perm(influxdb.WriteAction)):
+		r.Access = influxdb.WriteAction
+	case ps.Allowed(perm(influxdb.ReadAction)):
+		r.Access = influxdb.ReadAction
+	default:
+		return false
+	}
+
+	r.Via = []string{influxdb.AccessViaToken}
+	if _, ok := mapped[r.ID]; ok {
+		r.Via = append(r.Via, influxdb.AccessViaMapping)
+	}
+	if _, ok := mapped[r.OrgID]; ok && r.OrgID.Valid() {
+		r.Via = append(r.Via, influxdb.AccessViaOrg)
+	}
+	sort.Strings(r.Via)
+	return true
+}
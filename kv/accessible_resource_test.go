@@ -0,0 +1,87 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_FindAccessibleResources(t *testing.T) {
+	store, closeStore, err := NewTestInmemStore(t)
+	require.NoError(t, err)
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+
+	user := &influxdb.User{Name: "alice"}
+	require.NoError(t, svc.CreateUser(ctx, user))
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+	other := &influxdb.Organization{Name: "other"}
+	require.NoError(t, svc.CreateOrganization(ctx, other))
+
+	bucket := &influxdb.Bucket{Name: "bucket", OrgID: org.ID}
+	require.NoError(t, svc.CreateBucket(ctx, bucket))
+	dash := &influxdb.Dashboard{Name: "dash", OrganizationID: other.ID}
+	require.NoError(t, svc.CreateDashboard(ctx, dash))
+	label := &influxdb.Label{Name: "label", OrgID: other.ID}
+	require.NoError(t, svc.CreateLabel(ctx, label))
+	hidden := &influxdb.Label{Name: "hidden", OrgID: other.ID}
+	require.NoError(t, svc.CreateLabel(ctx, hidden))
+
+	for _, m := range []*influxdb.UserResourceMapping{
+		{UserID: user.ID, UserType: influxdb.Owner, ResourceType: influxdb.OrgsResourceType, ResourceID: org.ID},
+		{UserID: user.ID, UserType: influxdb.Member, ResourceType: influxdb.DashboardsResourceType, ResourceID: dash.ID},
+	} {
+		require.NoError(t, svc.CreateUserResourceMapping(ctx, m))
+	}
+
+	ps := influxdb.PermissionSet{{
+		Action:   influxdb.ReadAction,
+		Resource: influxdb.Resource{Type: influxdb.OrgsResourceType, ID: &org.ID},
+	}, {
+		Action:   influxdb.WriteAction,
+		Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &org.ID},
+	}, {
+		Action:   influxdb.ReadAction,
+		Resource: influxdb.Resource{Type: influxdb.LabelsResourceType, ID: &label.ID, OrgID: &other.ID},
+	}, {
+		Action:   influxdb.WriteAction,
+		Resource: influxdb.Resource{Type: influxdb.DashboardsResourceType, OrgID: &other.ID},
+	}}
+
+	resources, err := svc.FindAccessibleResources(ctx, user.ID, ps)
+	require.NoError(t, err)
+
+	// The buckets of the organization include its system buckets.
+	var found bool
+	for _, b := range resources[influxdb.BucketsResourceType] {
+		assert.Equal(t, org.ID, b.OrgID)
+		assert.Equal(t, influxdb.WriteAction, b.Access)
+		assert.Equal(t, []string{influxdb.AccessViaOrg, influxdb.AccessViaToken}, b.Via)
+		found = found || b.ID == bucket.ID
+	}
+	assert.True(t, found, "the bucket of the organization is accessible")
+	delete(resources, influxdb.BucketsResourceType)
+
+	// The access given by the mappings of the user is limited to the
+	// permissions of its token: the owner of org only reads it with a read
+	// token.
+	assert.Equal(t, map[influxdb.ResourceType][]*influxdb.AccessibleResource{
+		influxdb.OrgsResourceType: {
+			{ID: org.ID, Name: "org", Access: influxdb.ReadAction, Via: []string{influxdb.AccessViaMapping, influxdb.AccessViaToken}},
+		},
+		influxdb.DashboardsResourceType: {
+			{ID: dash.ID, OrgID: other.ID, Name: "dash", Access: influxdb.WriteAction, Via: []string{influxdb.AccessViaMapping, influxdb.AccessViaToken}},
+		},
+		influxdb.LabelsResourceType: {
+			{ID: label.ID, OrgID: other.ID, Name: "label", Access: influxdb.ReadAction, Via: []string{influxdb.AccessViaToken}},
+		},
+	}, resources)
+}
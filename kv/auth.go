@@ -496,7 +496,7 @@ func (s *Service) deleteAuthorization(ctx context.Context, tx Tx, id influxdb.ID
 	return nil
 }
 
-// UpdateAuthorization updates the status and description if available.
+// UpdateAuthorization updates the status, description and row filters if available.
 func (s *Service) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
 	var a *influxdb.Authorization
 	var err error
@@ -519,6 +519,12 @@ func (s *Service) updateAuthorization(ctx context.Context, tx Tx, id influxdb.ID
 	if upd.Description != nil {
 		a.Description = *upd.Description
 	}
+	if upd.RowFilters != nil {
+		if err := influxdb.ValidRowFilters(*upd.RowFilters); err != nil {
+			return nil, err
+		}
+		a.RowFilters = *upd.RowFilters
+	}
 
 	now := s.TimeGenerator.Now()
 	a.SetUpdatedAt(now)
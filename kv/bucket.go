@@ -133,10 +133,11 @@ func (s *Service) CreateSystemBuckets(ctx context.Context, o *influxdb.Organizat
 
 // createSystemBuckets creates the task and monitoring system buckets for an organization
 func (s *Service) createSystemBuckets(ctx context.Context, tx Tx, o *influxdb.Organization) error {
+	sb := s.Config.SystemBuckets
 	tb := &influxdb.Bucket{
 		OrgID:           o.ID,
 		Type:            influxdb.BucketTypeSystem,
-		Name:            influxdb.TasksSystemBucketName,
+		Name:            sb.TasksName,
 		RetentionPeriod: influxdb.TasksSystemBucketRetention,
 		Description:     "System bucket for task logs",
 	}
@@ -148,7 +149,7 @@ func (s *Service) createSystemBuckets(ctx context.Context, tx Tx, o *influxdb.Or
 	mb := &influxdb.Bucket{
 		OrgID:           o.ID,
 		Type:            influxdb.BucketTypeSystem,
-		Name:            influxdb.MonitoringSystemBucketName,
+		Name:            sb.MonitoringName,
 		RetentionPeriod: influxdb.MonitoringSystemBucketRetention,
 		Description:     "System bucket for monitoring logs",
 	}
@@ -179,30 +180,12 @@ func (s *Service) findBucketByName(ctx context.Context, tx Tx, orgID influxdb.ID
 
 	buf, err := idx.Get(key)
 	if IsNotFound(err) {
-		switch n {
-		case influxdb.TasksSystemBucketName:
-			return &influxdb.Bucket{
-				ID:              influxdb.TasksSystemBucketID,
-				Type:            influxdb.BucketTypeSystem,
-				Name:            influxdb.TasksSystemBucketName,
-				RetentionPeriod: influxdb.TasksSystemBucketRetention,
-				Description:     "System bucket for task logs",
-				OrgID:           orgID,
-			}, nil
-		case influxdb.MonitoringSystemBucketName:
-			return &influxdb.Bucket{
-				ID:              influxdb.MonitoringSystemBucketID,
-				Type:            influxdb.BucketTypeSystem,
-				Name:            influxdb.MonitoringSystemBucketName,
-				RetentionPeriod: influxdb.MonitoringSystemBucketRetention,
-				Description:     "System bucket for monitoring logs",
-				OrgID:           orgID,
-			}, nil
-		default:
-			return nil, &influxdb.Error{
-				Code: influxdb.ENotFound,
-				Msg:  fmt.Sprintf("bucket %q not found", n),
-			}
+		if b, ok := s.Config.SystemBuckets.BucketByName(orgID, n); ok {
+			return b, nil
+		}
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  fmt.Sprintf("bucket %q not found", n),
 		}
 	}
 
@@ -279,6 +262,15 @@ func (s *Service) FindBucket(ctx context.Context, filter influxdb.BucketFilter)
 }
 
 func filterBucketsFn(filter influxdb.BucketFilter) func(b *influxdb.Bucket) bool {
+	if len(filter.Metadata) > 0 {
+		metadata := filter.Metadata
+		filter.Metadata = nil
+		fn := filterBucketsFn(filter)
+		return func(b *influxdb.Bucket) bool {
+			return fn(b) && b.HasMetadata(metadata)
+		}
+	}
+
 	if filter.ID != nil {
 		return func(b *influxdb.Bucket) bool {
 			return b.ID == *filter.ID
@@ -318,6 +310,9 @@ func (s *Service) FindBuckets(ctx context.Context, filter influxdb.BucketFilter,
 		if err != nil {
 			return nil, 0, err
 		}
+		if !b.HasMetadata(filter.Metadata) {
+			return []*influxdb.Bucket{}, 0, nil
+		}
 
 		return []*influxdb.Bucket{b}, 1, nil
 	}
@@ -327,6 +322,9 @@ func (s *Service) FindBuckets(ctx context.Context, filter influxdb.BucketFilter,
 		if err != nil {
 			return nil, 0, err
 		}
+		if !b.HasMetadata(filter.Metadata) {
+			return []*influxdb.Bucket{}, 0, nil
+		}
 
 		return []*influxdb.Bucket{b}, 1, nil
 	}
@@ -351,6 +349,14 @@ func (s *Service) FindBuckets(ctx context.Context, filter influxdb.BucketFilter,
 		return bs, len(bs), nil
 	}
 
+	// The mocked system buckets have no metadata.
+	if len(filter.Metadata) > 0 {
+		if err != nil {
+			return nil, 0, err
+		}
+		return bs, len(bs), nil
+	}
+
 	needsSystemBuckets := true
 	for _, b := range bs {
 		if b.Type == influxdb.BucketTypeSystem {
@@ -360,25 +366,8 @@ func (s *Service) FindBuckets(ctx context.Context, filter influxdb.BucketFilter,
 	}
 
 	if needsSystemBuckets {
-		tb := &influxdb.Bucket{
-			ID:              influxdb.TasksSystemBucketID,
-			Type:            influxdb.BucketTypeSystem,
-			Name:            influxdb.TasksSystemBucketName,
-			RetentionPeriod: influxdb.TasksSystemBucketRetention,
-			Description:     "System bucket for task logs",
-		}
-
-		bs = append(bs, tb)
-
-		mb := &influxdb.Bucket{
-			ID:              influxdb.MonitoringSystemBucketID,
-			Type:            influxdb.BucketTypeSystem,
-			Name:            influxdb.MonitoringSystemBucketName,
-			RetentionPeriod: influxdb.MonitoringSystemBucketRetention,
-			Description:     "System bucket for monitoring logs",
-		}
-
-		bs = append(bs, mb)
+		sb := s.Config.SystemBuckets
+		bs = append(bs, sb.TasksBucket(0), sb.MonitoringBucket(0))
 	}
 
 	if err != nil {
@@ -472,6 +461,10 @@ func (s *Service) createBucket(ctx context.Context, tx Tx, b *influxdb.Bucket) (
 		return err
 	}
 
+	if err := influxdb.ValidBucketMetadata(b.Metadata); err != nil {
+		return &influxdb.Error{Code: influxdb.EInvalid, Err: err}
+	}
+
 	if b.ID, err = s.generateBucketID(ctx, tx); err != nil {
 		return err
 	}
@@ -700,6 +693,52 @@ func (s *Service) updateBucket(ctx context.Context, tx Tx, id influxdb.ID, upd i
 		b.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.WritePastWindow != nil {
+		b.WritePastWindow = *upd.WritePastWindow
+	}
+
+	if upd.WriteFutureWindow != nil {
+		b.WriteFutureWindow = *upd.WriteFutureWindow
+	}
+
+	if upd.DedupWindow != nil {
+		b.DedupWindow = *upd.DedupWindow
+	}
+
+	if upd.WriteSharding != nil {
+		if err := upd.WriteSharding.Valid(); err != nil {
+			return nil, &influxdb.Error{Code: influxdb.EInvalid, Err: err}
+		}
+		b.WriteSharding = nil
+		if upd.WriteSharding.Enabled() {
+			ws := *upd.WriteSharding
+			b.WriteSharding = &ws
+		}
+	}
+
+	if upd.State != nil {
+		if err := upd.State.Valid(); err != nil {
+			return nil, &influxdb.Error{Code: influxdb.EInvalid, Err: err}
+		}
+		if *upd.State == influxdb.BucketStateArchived && b.Type == influxdb.BucketTypeSystem {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "system buckets cannot be archived",
+			}
+		}
+		b.State = *upd.State
+	}
+
+	if upd.Metadata != nil {
+		if err := influxdb.ValidBucketMetadata(upd.Metadata); err != nil {
+			return nil, &influxdb.Error{Code: influxdb.EInvalid, Err: err}
+		}
+		b.Metadata = nil
+		if len(upd.Metadata) > 0 {
+			b.Metadata = upd.Metadata
+		}
+	}
+
 	if upd.Description != nil {
 		b.Description = *upd.Description
 	}
@@ -264,6 +264,9 @@ func (s *Service) CreateDashboard(ctx context.Context, d *influxdb.Dashboard) er
 		d.ID = s.IDGenerator.ID()
 
 		for _, cell := range d.Cells {
+			if err := influxdb.ValidCellTransforms(cell.Transforms); err != nil {
+				return err
+			}
 			cell.ID = s.IDGenerator.ID()
 
 			if err := s.createCellView(ctx, tx, d.ID, cell.ID, cell.View); err != nil {
@@ -290,6 +293,9 @@ func (s *Service) CreateDashboard(ctx context.Context, d *influxdb.Dashboard) er
 			s.log.Info("Failed to make user owner of organization", zap.Error(err))
 		}
 
+		if d.Restricted {
+			return s.restrictResourceMappings(ctx, tx, influxdb.DashboardsResourceType, d.ID, true)
+		}
 		return nil
 	})
 	if err != nil {
@@ -343,6 +349,10 @@ func (s *Service) ReplaceDashboardCells(ctx context.Context, id influxdb.ID, cs
 					Msg:  "cannot replace cells that were not already present",
 				}
 			}
+
+			if err := influxdb.ValidCellTransforms(cell.Transforms); err != nil {
+				return err
+			}
 		}
 
 		d.Cells = cs
@@ -361,6 +371,9 @@ func (s *Service) ReplaceDashboardCells(ctx context.Context, id influxdb.ID, cs
 }
 
 func (s *Service) addDashboardCell(ctx context.Context, tx Tx, id influxdb.ID, cell *influxdb.Cell, opts influxdb.AddDashboardCellOptions) error {
+	if err := influxdb.ValidCellTransforms(cell.Transforms); err != nil {
+		return err
+	}
 	d, err := s.findDashboardByID(ctx, tx, id)
 	if err != nil {
 		return err
@@ -833,6 +846,12 @@ func (s *Service) updateDashboard(ctx context.Context, tx Tx, id influxdb.ID, up
 		}
 	}
 
+	if upd.Restricted != nil {
+		if err := s.restrictResourceMappings(ctx, tx, influxdb.DashboardsResourceType, d.ID, d.Restricted); err != nil {
+			return nil, err
+		}
+	}
+
 	return d, nil
 }
 
@@ -5,8 +5,10 @@ import (
 	"testing"
 
 	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kv"
 	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -51,3 +53,37 @@ func initDashboardService(s kv.SchemaStore, f influxdbtesting.DashboardFields, t
 		}
 	}
 }
+
+func TestService_RestrictedDashboardMappings(t *testing.T) {
+	store, closeStore, err := NewTestInmemStore(t)
+	require.NoError(t, err)
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+
+	user := &influxdb.User{Name: "alice"}
+	require.NoError(t, svc.CreateUser(ctx, user))
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	ctx = icontext.SetAuthorizer(ctx, &influxdb.Authorization{UserID: user.ID})
+	dash := &influxdb.Dashboard{Name: "dash", OrganizationID: org.ID, Restricted: true}
+	require.NoError(t, svc.CreateDashboard(ctx, dash))
+
+	restricted := func() bool {
+		ms, _, err := svc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.DashboardsResourceType,
+			ResourceID:   dash.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, ms, 1)
+		return ms[0].Restricted
+	}
+	require.True(t, restricted(), "the owner of a restricted dashboard gets permissions on it")
+
+	unrestricted := false
+	_, err = svc.UpdateDashboard(ctx, dash.ID, influxdb.DashboardUpdate{Restricted: &unrestricted})
+	require.NoError(t, err)
+	require.False(t, restricted(), "the owner of a dashboard that is no longer restricted only gets permissions from its organization")
+}
@@ -211,6 +211,37 @@ func (s *Service) createLabelMapping(ctx context.Context, tx Tx, m *influxdb.Lab
 	return nil
 }
 
+// ApplyLabelMappings attaches a label to and detaches it from many resources
+// in one transaction. Resources that already have the label are skipped.
+func (s *Service) ApplyLabelMappings(ctx context.Context, b *influxdb.LabelMappingBatch) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findLabelByID(ctx, tx, b.LabelID); err != nil {
+			return err
+		}
+
+		for i := range b.Add {
+			err := s.createLabelMapping(ctx, tx, &b.Add[i])
+			if err == influxdb.ErrLabelExistsOnResource {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		for i := range b.Remove {
+			if err := s.deleteLabelMapping(ctx, tx, &b.Remove[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // DeleteLabelMapping deletes a label mapping.
 func (s *Service) DeleteLabelMapping(ctx context.Context, m *influxdb.LabelMapping) error {
 	err := s.kv.Update(ctx, func(tx Tx) error {
@@ -0,0 +1,339 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+// Kinds of the inconsistencies found in the metadata store.
+const (
+	// OrphanedURM is a user resource mapping of a resource that no longer exists.
+	OrphanedURM = "orphaned user resource mapping"
+	// DanglingURM is a user resource mapping of a user that no longer exists.
+	DanglingURM = "dangling user resource mapping"
+	// DanglingURMIndexEntry is an entry of the by user index of user resource
+	// mappings without a mapping.
+	DanglingURMIndexEntry = "dangling user resource mapping index entry"
+	// OrphanedLabelMapping is a label mapping of a label or a resource that
+	// no longer exists.
+	OrphanedLabelMapping = "orphaned label mapping"
+)
+
+// MetadataIssue is a record of the metadata store that refers to a user,
+// label or resource that no longer exists.
+type MetadataIssue struct {
+	Kind        string
+	Description string
+	// Repaired is true if the record was deleted.
+	Repaired bool
+}
+
+func (i MetadataIssue) String() string {
+	s := i.Kind + ": " + i.Description
+	if i.Repaired {
+		s += " (deleted)"
+	}
+	return s
+}
+
+// VerifyMetadata returns the user resource mappings and label mappings that
+// refer to users, labels or resources that no longer exist. If repair is
+// true, those records are deleted.
+//
+// Mappings of resource types that are not stored in the metadata store are
+// not verified.
+func (s *Service) VerifyMetadata(ctx context.Context, repair bool) ([]MetadataIssue, error) {
+	run := s.kv.View
+	if repair {
+		run = s.kv.Update
+	}
+
+	var issues []MetadataIssue
+	if err := run(ctx, func(tx Tx) error {
+		urmIssues, err := s.verifyURMs(ctx, tx, repair)
+		if err != nil {
+			return err
+		}
+		labelIssues, err := s.verifyLabelMappings(ctx, tx, repair)
+		if err != nil {
+			return err
+		}
+		issues = append(urmIssues, labelIssues...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// The index is verified once the mappings it refers to are repaired.
+	indexIssues, err := s.verifyURMIndex(ctx, repair)
+	if err != nil {
+		return nil, err
+	}
+	return append(issues, indexIssues...), nil
+}
+
+func (s *Service) verifyURMs(ctx context.Context, tx Tx, repair bool) ([]MetadataIssue, error) {
+	b, err := tx.Bucket(urmBucket)
+	if err != nil {
+		return nil, UnavailableURMServiceError(err)
+	}
+
+	var (
+		issues []MetadataIssue
+		broken []*influxdb.UserResourceMapping
+	)
+	if err := forEach(b, func(k, v []byte) error {
+		m := &influxdb.UserResourceMapping{}
+		if err := json.Unmarshal(v, m); err != nil {
+			return CorruptURMError(err)
+		}
+
+		kind := ""
+		if ok, err := s.resourceExists(ctx, tx, influxdb.UsersResourceType, m.UserID); err != nil {
+			return err
+		} else if !ok {
+			kind = DanglingURM
+		} else if ok, err := s.resourceExists(ctx, tx, m.ResourceType, m.ResourceID); err != nil {
+			return err
+		} else if !ok {
+			kind = OrphanedURM
+		}
+		if kind == "" {
+			return nil
+		}
+
+		issues = append(issues, MetadataIssue{
+			Kind:        kind,
+			Description: fmt.Sprintf("user %s is %s of %s %s", m.UserID, m.UserType, m.ResourceType, m.ResourceID),
+			Repaired:    repair,
+		})
+		broken = append(broken, m)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if !repair {
+		return issues, nil
+	}
+	for _, m := range broken {
+		key, err := userResourceKey(m)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.Delete(key); err != nil {
+			return nil, UnavailableURMServiceError(err)
+		}
+		userID, err := m.UserID.Encode()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.urmByUserIndex.Delete(tx, userID, key); err != nil {
+			return nil, err
+		}
+	}
+	return issues, nil
+}
+
+func (s *Service) verifyLabelMappings(ctx context.Context, tx Tx, repair bool) ([]MetadataIssue, error) {
+	b, err := tx.Bucket(labelMappingBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		issues []MetadataIssue
+		broken [][]byte
+	)
+	if err := forEach(b, func(k, v []byte) error {
+		resourceID, labelID, err := decodeLabelMappingKey(k)
+		if err != nil {
+			return err
+		}
+		m := &influxdb.LabelMapping{}
+		if err := json.Unmarshal(v, m); err != nil {
+			return &influxdb.Error{Code: influxdb.EInternal, Err: err}
+		}
+
+		var desc string
+		if ok, err := s.resourceExists(ctx, tx, influxdb.LabelsResourceType, labelID); err != nil {
+			return err
+		} else if !ok {
+			desc = fmt.Sprintf("label %s no longer exists", labelID)
+		} else if ok, err := s.resourceExists(ctx, tx, m.ResourceType, resourceID); err != nil {
+			return err
+		} else if !ok {
+			desc = fmt.Sprintf("%s %s no longer exists", m.ResourceType, resourceID)
+		}
+		if desc == "" {
+			return nil
+		}
+
+		issues = append(issues, MetadataIssue{
+			Kind:        OrphanedLabelMapping,
+			Description: fmt.Sprintf("label %s on %s %s: %s", labelID, m.ResourceType, resourceID, desc),
+			Repaired:    repair,
+		})
+		broken = append(broken, append([]byte(nil), k...))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if !repair {
+		return issues, nil
+	}
+	for _, k := range broken {
+		if err := b.Delete(k); err != nil {
+			return nil, err
+		}
+	}
+	return issues, nil
+}
+
+func (s *Service) verifyURMIndex(ctx context.Context, repair bool) ([]MetadataIssue, error) {
+	diff, err := s.urmByUserIndex.Verify(ctx, s.kv)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []MetadataIssue
+	for fk, pks := range diff.MissingFromSource {
+		for pk := range pks {
+			var userID influxdb.ID
+			if err := userID.Decode([]byte(fk)); err != nil {
+				return nil, err
+			}
+			issues = append(issues, MetadataIssue{
+				Kind:        DanglingURMIndexEntry,
+				Description: fmt.Sprintf("user %s refers to mapping %x", userID, pk),
+				Repaired:    repair,
+			})
+		}
+	}
+
+	if !repair || len(issues) == 0 {
+		return issues, nil
+	}
+	err = s.kv.Update(ctx, func(tx Tx) error {
+		for fk, pks := range diff.MissingFromSource {
+			for pk := range pks {
+				if err := s.urmByUserIndex.Delete(tx, []byte(fk), []byte(pk)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	return issues, err
+}
+
+// resourceExists returns false if the resource is known to no longer exist.
+// Resources of types that are not stored in the metadata store are assumed
+// to exist.
+func (s *Service) resourceExists(ctx context.Context, tx Tx, rt influxdb.ResourceType, id influxdb.ID) (bool, error) {
+	if !id.Valid() {
+		return false, nil
+	}
+
+	var err error
+	switch rt {
+	case influxdb.UsersResourceType:
+		_, err = s.findUserByID(ctx, tx, id)
+	case influxdb.OrgsResourceType:
+		_, err = s.findOrganizationByID(ctx, tx, id)
+	case influxdb.BucketsResourceType:
+		_, err = s.findBucketByID(ctx, tx, id)
+	case influxdb.DashboardsResourceType:
+		_, err = s.findDashboardByID(ctx, tx, id)
+	case influxdb.TasksResourceType:
+		_, err = s.findTaskByID(ctx, tx, id)
+	case influxdb.TelegrafsResourceType:
+		_, err = s.findTelegrafConfigByID(ctx, tx, id)
+	case influxdb.ChecksResourceType:
+		_, err = s.findCheckByID(ctx, tx, id)
+	case influxdb.NotificationRuleResourceType:
+		_, err = s.findNotificationRuleByID(ctx, tx, id)
+	case influxdb.NotificationEndpointResourceType:
+		_, err = s.findNotificationEndpointByID(ctx, tx, id)
+	case influxdb.VariablesResourceType:
+		_, err = s.findVariableByID(ctx, tx, id)
+	case influxdb.SourcesResourceType:
+		_, err = s.findSourceByID(ctx, tx, id)
+	case influxdb.ScraperResourceType:
+		_, err = s.findTargetByID(ctx, tx, id)
+	case influxdb.LabelsResourceType:
+		_, err = s.findLabelByID(ctx, tx, id)
+	default:
+		return true, nil
+	}
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// forEach calls fn with every key and value of b.
+func forEach(b Bucket, fn func(k, v []byte) error) error {
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return err
+	}
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		if err := fn(k, v); err != nil {
+			cur.Close()
+			return err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return err
+	}
+	return cur.Close()
+}
+
+// MetadataVerifier verifies the metadata store periodically.
+type MetadataVerifier struct {
+	log      *zap.Logger
+	svc      *Service
+	interval time.Duration
+	repair   bool
+}
+
+// NewMetadataVerifier returns a verifier of the metadata store of svc that
+// runs every interval, deleting the inconsistent records if repair is true.
+func NewMetadataVerifier(log *zap.Logger, svc *Service, interval time.Duration, repair bool) *MetadataVerifier {
+	return &MetadataVerifier{
+		log:      log,
+		svc:      svc,
+		interval: interval,
+		repair:   repair,
+	}
+}
+
+// Run verifies the metadata store until ctx is canceled.
+func (v *MetadataVerifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(v.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		issues, err := v.svc.VerifyMetadata(ctx, v.repair)
+		if err != nil {
+			v.log.Error("Failed to verify metadata", zap.Error(err))
+			continue
+		}
+		for _, i := range issues {
+			v.log.Warn("Inconsistent metadata", zap.String("kind", i.Kind), zap.String("record", i.Description), zap.Bool("deleted", i.Repaired))
+		}
+	}
+}
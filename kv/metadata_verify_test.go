@@ -0,0 +1,76 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_VerifyMetadata(t *testing.T) {
+	store, closeStore, err := NewTestInmemStore(t)
+	require.NoError(t, err)
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	ctx := context.Background()
+
+	user := &influxdb.User{Name: "alice"}
+	require.NoError(t, svc.CreateUser(ctx, user))
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+	dash := &influxdb.Dashboard{Name: "dash", OrganizationID: org.ID}
+	require.NoError(t, svc.CreateDashboard(ctx, dash))
+	label := &influxdb.Label{Name: "label", OrgID: org.ID}
+	require.NoError(t, svc.CreateLabel(ctx, label))
+
+	deleted := influxdb.ID(99)
+	for _, id := range []influxdb.ID{dash.ID, deleted} {
+		require.NoError(t, svc.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+			UserID:       user.ID,
+			UserType:     influxdb.Owner,
+			ResourceType: influxdb.DashboardsResourceType,
+			ResourceID:   id,
+		}))
+		require.NoError(t, svc.CreateLabelMapping(ctx, &influxdb.LabelMapping{
+			LabelID:      label.ID,
+			ResourceType: influxdb.DashboardsResourceType,
+			ResourceID:   id,
+		}))
+	}
+
+	kinds := func(issues []kv.MetadataIssue) []string {
+		var ks []string
+		for _, i := range issues {
+			ks = append(ks, i.Kind)
+		}
+		return ks
+	}
+
+	issues, err := svc.VerifyMetadata(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{kv.OrphanedURM, kv.OrphanedLabelMapping}, kinds(issues))
+	assert.False(t, issues[0].Repaired)
+
+	issues, err = svc.VerifyMetadata(ctx, true)
+	require.NoError(t, err)
+	assert.Len(t, issues, 2)
+	assert.True(t, issues[0].Repaired)
+
+	issues, err = svc.VerifyMetadata(ctx, false)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	urms, _, err := svc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{UserID: user.ID})
+	require.NoError(t, err)
+	for _, m := range urms {
+		assert.NotEqual(t, deleted, m.ResourceID)
+	}
+	labels, err := svc.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: dash.ID, ResourceType: influxdb.DashboardsResourceType})
+	require.NoError(t, err)
+	assert.Len(t, labels, 1)
+}
@@ -0,0 +1,15 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var (
+	legalHoldBucket      = []byte("legalholdsv1")
+	legalHoldEventBucket = []byte("legalholdeventsv1")
+)
+
+// Migration0007_AddLegalHoldBuckets creates the buckets necessary for the legal hold service to operate.
+var Migration0007_AddLegalHoldBuckets = migration.CreateBuckets(
+	"create legal hold buckets",
+	legalHoldBucket,
+	legalHoldEventBucket,
+)
@@ -0,0 +1,15 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var (
+	fluxPackageBucket      = []byte("fluxpackagesv1")
+	fluxPackageIndexBucket = []byte("fluxpackageindexv1")
+)
+
+// Migration0008_AddFluxPackageBuckets creates the buckets necessary for the flux package service to operate.
+var Migration0008_AddFluxPackageBuckets = migration.CreateBuckets(
+	"create flux package buckets",
+	fluxPackageBucket,
+	fluxPackageIndexBucket,
+)
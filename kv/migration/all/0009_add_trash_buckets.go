@@ -0,0 +1,11 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var trashBucket = []byte("trashv1")
+
+// Migration0009_AddTrashBuckets creates the buckets necessary for the trash service to operate.
+var Migration0009_AddTrashBuckets = migration.CreateBuckets(
+	"create trash buckets",
+	trashBucket,
+)
@@ -0,0 +1,11 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var writeRoutingBucket = []byte("writeroutingsv1")
+
+// Migration0010_AddWriteRoutingBuckets creates the buckets necessary for the write routing service to operate.
+var Migration0010_AddWriteRoutingBuckets = migration.CreateBuckets(
+	"create write routing buckets",
+	writeRoutingBucket,
+)
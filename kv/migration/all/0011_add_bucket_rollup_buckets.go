@@ -0,0 +1,11 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var bucketRollupBucket = []byte("bucketrollupsv1")
+
+// Migration0011_AddBucketRollupBuckets creates the buckets necessary for the bucket rollup service to operate.
+var Migration0011_AddBucketRollupBuckets = migration.CreateBuckets(
+	"create bucket rollup buckets",
+	bucketRollupBucket,
+)
@@ -0,0 +1,11 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var userSettingsBucket = []byte("usersettingsv1")
+
+// Migration0012_AddUserSettingsBuckets creates the buckets necessary for the user settings service to operate.
+var Migration0012_AddUserSettingsBuckets = migration.CreateBuckets(
+	"create user settings buckets",
+	userSettingsBucket,
+)
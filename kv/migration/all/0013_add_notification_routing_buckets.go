@@ -0,0 +1,11 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var notificationRoutingBucket = []byte("notificationroutingv1")
+
+// Migration0013_AddNotificationRoutingBuckets creates the buckets necessary for the notification routing tables to operate.
+var Migration0013_AddNotificationRoutingBuckets = migration.CreateBuckets(
+	"create notification routing buckets",
+	notificationRoutingBucket,
+)
@@ -0,0 +1,15 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var (
+	authorizationPresetBucket      = []byte("authorizationpresetsv1")
+	authorizationPresetIndexBucket = []byte("authorizationpresetindexv1")
+)
+
+// Migration0014_AddAuthorizationPresetBuckets creates the buckets necessary for the authorization preset service to operate.
+var Migration0014_AddAuthorizationPresetBuckets = migration.CreateBuckets(
+	"create authorization preset buckets",
+	authorizationPresetBucket,
+	authorizationPresetIndexBucket,
+)
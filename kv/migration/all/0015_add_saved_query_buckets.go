@@ -0,0 +1,15 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var (
+	savedQueryBucket      = []byte("savedqueriesv1")
+	savedQueryIndexBucket = []byte("savedqueryindexv1")
+)
+
+// Migration0015_AddSavedQueryBuckets creates the buckets necessary for the saved query service to operate.
+var Migration0015_AddSavedQueryBuckets = migration.CreateBuckets(
+	"create saved query buckets",
+	savedQueryBucket,
+	savedQueryIndexBucket,
+)
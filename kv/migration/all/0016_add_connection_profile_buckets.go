@@ -0,0 +1,15 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var (
+	connectionProfileBucket      = []byte("connectionprofilesv1")
+	connectionProfileIndexBucket = []byte("connectionprofileindexv1")
+)
+
+// Migration0016_AddConnectionProfileBuckets creates the buckets necessary for the connection profile service to operate.
+var Migration0016_AddConnectionProfileBuckets = migration.CreateBuckets(
+	"create connection profile buckets",
+	connectionProfileBucket,
+	connectionProfileIndexBucket,
+)
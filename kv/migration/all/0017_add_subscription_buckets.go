@@ -0,0 +1,15 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var (
+	subscriptionBucket      = []byte("subscriptionsv1")
+	subscriptionIndexBucket = []byte("subscriptionindexv1")
+)
+
+// Migration0017_AddSubscriptionBuckets creates the buckets necessary for the subscription service to operate.
+var Migration0017_AddSubscriptionBuckets = migration.CreateBuckets(
+	"create subscription buckets",
+	subscriptionBucket,
+	subscriptionIndexBucket,
+)
@@ -0,0 +1,11 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var secretEncryptionKeyBucket = []byte("secretencryptionkeysv1")
+
+// Migration0018_AddSecretEncryptionKeyBuckets creates the buckets necessary for the secret encryption keys of organizations.
+var Migration0018_AddSecretEncryptionKeyBuckets = migration.CreateBuckets(
+	"create secret encryption key buckets",
+	secretEncryptionKeyBucket,
+)
@@ -0,0 +1,11 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var jobBucket = []byte("jobsv1")
+
+// Migration0019_AddJobBuckets creates the buckets necessary for the background jobs.
+var Migration0019_AddJobBuckets = migration.CreateBuckets(
+	"create job buckets",
+	jobBucket,
+)
@@ -0,0 +1,11 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var notificationMessageBucket = []byte("notificationmessagesv1")
+
+// Migration0020_AddNotificationMessageBuckets creates the buckets necessary for the notification message catalogs to operate.
+var Migration0020_AddNotificationMessageBuckets = migration.CreateBuckets(
+	"create notification message buckets",
+	notificationMessageBucket,
+)
@@ -0,0 +1,70 @@
+package all
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+var bucketNameHistoryBucket = []byte("bucketnamehistoryv1")
+
+// Migration0021_AddBucketNameHistoryBuckets creates the bucket that records the
+// names of buckets over time, and records the names of the existing buckets as
+// taken when the buckets were created.
+var Migration0021_AddBucketNameHistoryBuckets = &Migration{
+	name: "create bucket name history buckets",
+	up: func(ctx context.Context, store kv.SchemaStore) error {
+		if err := store.CreateBucket(ctx, bucketNameHistoryBucket); err != nil {
+			return err
+		}
+
+		return store.Update(ctx, func(tx kv.Tx) error {
+			buckets, err := tx.Bucket([]byte("bucketsv1"))
+			if err != nil {
+				return err
+			}
+			history, err := tx.Bucket(bucketNameHistoryBucket)
+			if err != nil {
+				return err
+			}
+
+			cur, err := buckets.ForwardCursor(nil)
+			if err != nil {
+				return err
+			}
+			defer cur.Close()
+
+			for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+				b := &influxdb.Bucket{}
+				if err := json.Unmarshal(v, b); err != nil {
+					return err
+				}
+				orgID, err := b.OrgID.Encode()
+				if err != nil {
+					continue
+				}
+				// buckets created before their creation was recorded have
+				// always had their name.
+				var createdAt int64
+				if !b.CreatedAt.IsZero() {
+					createdAt = b.CreatedAt.UnixNano()
+				}
+
+				key := make([]byte, influxdb.IDLength+8+len(b.Name))
+				copy(key, orgID)
+				binary.BigEndian.PutUint64(key[influxdb.IDLength:], uint64(createdAt))
+				copy(key[influxdb.IDLength+8:], b.Name)
+				if err := history.Put(key, k); err != nil {
+					return err
+				}
+			}
+			return cur.Err()
+		})
+	},
+	down: func(ctx context.Context, store kv.SchemaStore) error {
+		return store.DeleteBucket(ctx, bucketNameHistoryBucket)
+	},
+}
@@ -0,0 +1,11 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var taskRunLogSettingsBucket = []byte("taskrunlogsettingsv1")
+
+// Migration0022_AddTaskRunLogSettingsBuckets creates the buckets necessary for the run log settings of tasks to operate.
+var Migration0022_AddTaskRunLogSettingsBuckets = migration.CreateBuckets(
+	"create task run log settings buckets",
+	taskRunLogSettingsBucket,
+)
@@ -0,0 +1,11 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var featureFlagOverrideBucket = []byte("featureflagoverridesv1")
+
+// Migration0023_AddFeatureFlagOverrideBuckets creates the buckets necessary for the runtime overrides of feature flags to operate.
+var Migration0023_AddFeatureFlagOverrideBuckets = migration.CreateBuckets(
+	"create feature flag override buckets",
+	featureFlagOverrideBucket,
+)
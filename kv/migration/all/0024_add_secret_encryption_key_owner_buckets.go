@@ -0,0 +1,52 @@
+package all
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+var secretEncryptionKeyOwnerBucket = []byte("secretencryptionkeyownersv1")
+
+// Migration0024_AddSecretEncryptionKeyOwnerBuckets creates the bucket of the organizations owning the keys in the KMS,
+// and records the owners of the keys already registered.
+var Migration0024_AddSecretEncryptionKeyOwnerBuckets = UpOnlyMigration(
+	"create secret encryption key owner buckets",
+	func(ctx context.Context, store kv.SchemaStore) error {
+		if err := store.CreateBucket(ctx, secretEncryptionKeyOwnerBucket); err != nil {
+			return err
+		}
+
+		return store.Update(ctx, func(tx kv.Tx) error {
+			keys, err := tx.Bucket(secretEncryptionKeyBucket)
+			if err != nil {
+				return err
+			}
+			owners, err := tx.Bucket(secretEncryptionKeyOwnerBucket)
+			if err != nil {
+				return err
+			}
+
+			c, err := keys.ForwardCursor(nil)
+			if err != nil {
+				return err
+			}
+			for k, v := c.Next(); k != nil; k, v = c.Next() {
+				var key struct {
+					KeyID string `json:"keyID"`
+				}
+				if err := json.Unmarshal(v, &key); err != nil {
+					return err
+				}
+				if err := owners.Put([]byte(key.KeyID), k); err != nil {
+					return err
+				}
+			}
+			if err := c.Err(); err != nil {
+				return err
+			}
+			return c.Close()
+		})
+	},
+)
@@ -19,5 +19,41 @@ var Migrations = [...]migration.Spec{
 	Migration0005_AddPkgerBuckets,
 	// delete bucket sessionsv1
 	Migration0006_DeleteBucketSessionsv1,
+	// add legal hold buckets
+	Migration0007_AddLegalHoldBuckets,
+	// add flux package buckets
+	Migration0008_AddFluxPackageBuckets,
+	// add trash buckets
+	Migration0009_AddTrashBuckets,
+	// add write routing buckets
+	Migration0010_AddWriteRoutingBuckets,
+	// add bucket rollup buckets
+	Migration0011_AddBucketRollupBuckets,
+	// add user settings buckets
+	Migration0012_AddUserSettingsBuckets,
+	// add notification routing buckets
+	Migration0013_AddNotificationRoutingBuckets,
+	// add authorization preset buckets
+	Migration0014_AddAuthorizationPresetBuckets,
+	// add saved query buckets
+	Migration0015_AddSavedQueryBuckets,
+	// add connection profile buckets
+	Migration0016_AddConnectionProfileBuckets,
+	// add subscription buckets
+	Migration0017_AddSubscriptionBuckets,
+	// add secret encryption key buckets
+	Migration0018_AddSecretEncryptionKeyBuckets,
+	// add job buckets
+	Migration0019_AddJobBuckets,
+	// add notification message buckets
+	Migration0020_AddNotificationMessageBuckets,
+	// add bucket name history buckets
+	Migration0021_AddBucketNameHistoryBuckets,
+	// add task run log settings buckets
+	Migration0022_AddTaskRunLogSettingsBuckets,
+	// add feature flag override buckets
+	Migration0023_AddFeatureFlagOverrideBuckets,
+	// add secret encryption key owner buckets
+	Migration0024_AddSecretEncryptionKeyOwnerBuckets,
 	// {{ do_not_edit . }}
 }
@@ -0,0 +1,161 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+)
+
+var notificationMessageBucket = []byte("notificationmessagesv1")
+
+var _ influxdb.NotificationMessageCatalogService = (*Service)(nil)
+
+// FindNotificationMessageCatalog returns the message catalog of the
+// organization. An organization without messages has an empty catalog.
+func (s *Service) FindNotificationMessageCatalog(ctx context.Context, orgID influxdb.ID) (*influxdb.NotificationMessageCatalog, error) {
+	var c *influxdb.NotificationMessageCatalog
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		c, err = s.findNotificationMessageCatalog(ctx, tx, orgID)
+		return err
+	})
+	return c, err
+}
+
+func (s *Service) findNotificationMessageCatalog(ctx context.Context, tx Tx, orgID influxdb.ID) (*influxdb.NotificationMessageCatalog, error) {
+	encodedID, err := orgID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(notificationMessageBucket)
+	if err != nil {
+		return nil, UnavailableNotificationRuleStoreError(err)
+	}
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return &influxdb.NotificationMessageCatalog{
+			OrgID:    orgID,
+			Messages: []influxdb.NotificationMessage{},
+		}, nil
+	}
+	if err != nil {
+		return nil, InternalNotificationRuleStoreError(err)
+	}
+
+	var c influxdb.NotificationMessageCatalog
+	if err := json.Unmarshal(v, &c); err != nil {
+		return nil, InternalNotificationRuleStoreError(err)
+	}
+	return &c, nil
+}
+
+// PutNotificationMessageCatalog replaces the message catalog of the
+// organization and regenerates the tasks of the rules sending its messages.
+// The messages sent by rules cannot be removed.
+func (s *Service) PutNotificationMessageCatalog(ctx context.Context, c *influxdb.NotificationMessageCatalog) error {
+	if err := c.Valid(); err != nil {
+		return err
+	}
+	for _, m := range c.Messages {
+		for locale, tmpl := range m.Templates {
+			if err := rule.ValidMessageTemplate(tmpl); err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("template %s of message %q is invalid", locale, m.Name),
+					Err:  err,
+				}
+			}
+		}
+	}
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		localized, err := s.findLocalizedNotificationRules(ctx, tx, c.OrgID)
+		if err != nil {
+			return err
+		}
+		for _, nr := range localized {
+			if _, ok := c.Message(nr.CatalogMessage()); !ok {
+				return &influxdb.Error{
+					Code: influxdb.EConflict,
+					Msg:  fmt.Sprintf("message %q is sent by notification rule %s", nr.CatalogMessage(), nr.GetID()),
+				}
+			}
+		}
+
+		current, err := s.findNotificationMessageCatalog(ctx, tx, c.OrgID)
+		if err != nil {
+			return err
+		}
+		now := s.TimeGenerator.Now()
+		c.CreatedAt = current.CreatedAt
+		if c.CreatedAt.IsZero() {
+			c.CreatedAt = now
+		}
+		c.UpdatedAt = now
+
+		encodedID, err := c.OrgID.Encode()
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(c)
+		if err != nil {
+			return InternalNotificationRuleStoreError(err)
+		}
+		b, err := tx.Bucket(notificationMessageBucket)
+		if err != nil {
+			return UnavailableNotificationRuleStoreError(err)
+		}
+		if err := b.Put(encodedID, v); err != nil {
+			return UnavailableNotificationRuleStoreError(err)
+		}
+
+		for _, nr := range localized {
+			if _, err := s.updateNotificationTask(ctx, tx, nr, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// findLocalizedNotificationRules returns the rules of the organization
+// sending a message of its catalog.
+func (s *Service) findLocalizedNotificationRules(ctx context.Context, tx Tx, orgID influxdb.ID) ([]rule.LocalizedRule, error) {
+	var localized []rule.LocalizedRule
+	err := s.forEachNotificationRule(ctx, tx, false, func(nr influxdb.NotificationRule) bool {
+		if r, ok := nr.(rule.LocalizedRule); ok && r.CatalogMessage() != "" && nr.GetOrgID() == orgID {
+			localized = append(localized, r)
+		}
+		return true
+	})
+	return localized, err
+}
+
+// setNotificationMessageCatalog sets the message catalog of the organization
+// of r on r if r sends a message of the catalog, which must have it.
+func (s *Service) setNotificationMessageCatalog(ctx context.Context, tx Tx, r influxdb.NotificationRule) error {
+	lr, ok := r.(rule.LocalizedRule)
+	if !ok || lr.CatalogMessage() == "" {
+		return nil
+	}
+
+	c, err := s.findNotificationMessageCatalog(ctx, tx, r.GetOrgID())
+	if err != nil {
+		return err
+	}
+	if _, ok := c.Message(lr.CatalogMessage()); !ok {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("message %q is not in the message catalog of the organization", lr.CatalogMessage()),
+		}
+	}
+	lr.SetMessageCatalog(c)
+	return nil
+}
@@ -0,0 +1,174 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+)
+
+var notificationRoutingBucket = []byte("notificationroutingv1")
+
+var _ influxdb.NotificationRoutingService = (*Service)(nil)
+
+// FindNotificationRoutingTable returns the routing table of the organization.
+// An organization without routing has an empty table.
+func (s *Service) FindNotificationRoutingTable(ctx context.Context, orgID influxdb.ID) (*influxdb.NotificationRoutingTable, error) {
+	var t *influxdb.NotificationRoutingTable
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		t, err = s.findNotificationRoutingTable(ctx, tx, orgID)
+		return err
+	})
+	return t, err
+}
+
+func (s *Service) findNotificationRoutingTable(ctx context.Context, tx Tx, orgID influxdb.ID) (*influxdb.NotificationRoutingTable, error) {
+	encodedID, err := orgID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(notificationRoutingBucket)
+	if err != nil {
+		return nil, UnavailableNotificationRuleStoreError(err)
+	}
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return &influxdb.NotificationRoutingTable{
+			OrgID:  orgID,
+			Routes: []influxdb.NotificationRoute{},
+		}, nil
+	}
+	if err != nil {
+		return nil, InternalNotificationRuleStoreError(err)
+	}
+
+	var t influxdb.NotificationRoutingTable
+	if err := json.Unmarshal(v, &t); err != nil {
+		return nil, InternalNotificationRuleStoreError(err)
+	}
+	return &t, nil
+}
+
+// PutNotificationRoutingTable replaces the routing table of the organization
+// and regenerates the tasks of its routed rules. The endpoints of the routes
+// must belong to the organization.
+func (s *Service) PutNotificationRoutingTable(ctx context.Context, t *influxdb.NotificationRoutingTable) error {
+	if err := t.Valid(); err != nil {
+		return err
+	}
+	return s.kv.Update(ctx, func(tx Tx) error {
+		for _, r := range t.Routes {
+			ep, err := s.findNotificationEndpointByID(ctx, tx, r.EndpointID)
+			if err != nil {
+				return err
+			}
+			if ep.GetOrgID() != t.OrgID {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("endpoint %s does not belong to the organization", r.EndpointID),
+				}
+			}
+		}
+
+		current, err := s.findNotificationRoutingTable(ctx, tx, t.OrgID)
+		if err != nil {
+			return err
+		}
+		now := s.TimeGenerator.Now()
+		t.CreatedAt = current.CreatedAt
+		if t.CreatedAt.IsZero() {
+			t.CreatedAt = now
+		}
+		t.UpdatedAt = now
+
+		encodedID, err := t.OrgID.Encode()
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(t)
+		if err != nil {
+			return InternalNotificationRuleStoreError(err)
+		}
+		b, err := tx.Bucket(notificationRoutingBucket)
+		if err != nil {
+			return UnavailableNotificationRuleStoreError(err)
+		}
+		if err := b.Put(encodedID, v); err != nil {
+			return UnavailableNotificationRuleStoreError(err)
+		}
+
+		return s.updateRoutedNotificationTasks(ctx, tx, t.OrgID)
+	})
+}
+
+// updateRoutedNotificationTasks regenerates the tasks of the routed rules of
+// the organization after its routing table changed.
+func (s *Service) updateRoutedNotificationTasks(ctx context.Context, tx Tx, orgID influxdb.ID) error {
+	var routed []influxdb.NotificationRule
+	err := s.forEachNotificationRule(ctx, tx, false, func(nr influxdb.NotificationRule) bool {
+		if r, ok := nr.(rule.RoutedRule); ok && r.IsRouted() && nr.GetOrgID() == orgID {
+			routed = append(routed, nr)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, nr := range routed {
+		if _, err := s.updateNotificationTask(ctx, tx, nr, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateNotificationFlux generates the script of the task of r, routing its
+// statuses with the routing table of its organization if r is routed. The
+// routes to endpoints of another type than the endpoint of r, or to deleted
+// endpoints, do not apply to r. The message of r is resolved from the message
+// catalog of its organization if r sends one of its messages.
+func (s *Service) generateNotificationFlux(ctx context.Context, tx Tx, r influxdb.NotificationRule) (string, error) {
+	ep, err := s.findNotificationEndpointByID(ctx, tx, r.GetEndpointID())
+	if err != nil {
+		return "", err
+	}
+	if err := s.setNotificationMessageCatalog(ctx, tx, r); err != nil {
+		return "", err
+	}
+
+	rr, ok := r.(rule.RoutedRule)
+	if !ok || !rr.IsRouted() {
+		return r.GenerateFlux(ep)
+	}
+
+	t, err := s.findNotificationRoutingTable(ctx, tx, r.GetOrgID())
+	if err != nil {
+		return "", err
+	}
+	routes := make([]rule.Route, 0, len(t.Routes))
+	for _, route := range t.Routes {
+		rep, err := s.findNotificationEndpointByID(ctx, tx, route.EndpointID)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if rep.Type() != ep.Type() {
+			continue
+		}
+		routes = append(routes, rule.Route{
+			Matchers: route.Matchers,
+			Endpoint: rep,
+		})
+	}
+	return rr.GenerateRoutedFlux(ep, routes)
+}
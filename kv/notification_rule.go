@@ -102,12 +102,7 @@ func (s *Service) createNotificationRule(ctx context.Context, tx Tx, nr influxdb
 }
 
 func (s *Service) createNotificationTask(ctx context.Context, tx Tx, r influxdb.NotificationRuleCreate) (*influxdb.Task, error) {
-	ep, err := s.findNotificationEndpointByID(ctx, tx, r.GetEndpointID())
-	if err != nil {
-		return nil, err
-	}
-
-	script, err := r.GenerateFlux(ep)
+	script, err := s.generateNotificationFlux(ctx, tx, r.NotificationRule)
 	if err != nil {
 		return nil, err
 	}
@@ -131,12 +126,7 @@ func (s *Service) createNotificationTask(ctx context.Context, tx Tx, r influxdb.
 }
 
 func (s *Service) updateNotificationTask(ctx context.Context, tx Tx, r influxdb.NotificationRule, status *string) (*influxdb.Task, error) {
-	ep, err := s.findNotificationEndpointByID(ctx, tx, r.GetEndpointID())
-	if err != nil {
-		return nil, err
-	}
-
-	script, err := r.GenerateFlux(ep)
+	script, err := s.generateNotificationFlux(ctx, tx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -762,6 +762,8 @@ func (s *Service) FindResourceOrganizationID(ctx context.Context, rt influxdb.Re
 			return influxdb.InvalidID(), err
 		}
 		return r.GetOrgID(), nil
+	case influxdb.SavedQueriesResourceType:
+		return s.findSavedQueryOrganizationID(ctx, id)
 	}
 
 	return influxdb.InvalidID(), &influxdb.Error{
@@ -769,6 +771,40 @@ func (s *Service) FindResourceOrganizationID(ctx context.Context, rt influxdb.Re
 	}
 }
 
+// savedQueryBucket is the bucket of the saved queries stored by the
+// savedquery package.
+var savedQueryBucket = []byte("savedqueriesv1")
+
+func (s *Service) findSavedQueryOrganizationID(ctx context.Context, id influxdb.ID) (influxdb.ID, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return influxdb.InvalidID(), &influxdb.Error{Code: influxdb.EInvalid, Err: err}
+	}
+
+	var q influxdb.SavedQuery
+	err = s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(savedQueryBucket)
+		if err != nil {
+			return err
+		}
+		v, err := b.Get(encodedID)
+		if IsNotFound(err) {
+			return &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  "saved query not found",
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(v, &q)
+	})
+	if err != nil {
+		return influxdb.InvalidID(), err
+	}
+	return q.OrgID, nil
+}
+
 // OrgAlreadyExistsError is used when creating a new organization with
 // a name that has already been used. Organization names must be unique.
 func OrgAlreadyExistsError(o *influxdb.Organization) error {
@@ -37,6 +37,15 @@ var (
 	}
 )
 
+// ErrInvalidScraperConfig is used when the authentication, TLS or relabel
+// configuration of a scraper target is invalid.
+func ErrInvalidScraperConfig(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("invalid scraper target configuration: %v", err),
+	}
+}
+
 // UnexpectedScrapersBucketError is used when the error comes from an internal system.
 func UnexpectedScrapersBucketError(err error) *influxdb.Error {
 	return &influxdb.Error{
@@ -167,6 +176,10 @@ func (s *Service) addTarget(ctx context.Context, tx Tx, target *influxdb.Scraper
 		return ErrInvalidScrapersBucketID
 	}
 
+	if err := target.Valid(); err != nil {
+		return ErrInvalidScraperConfig(err)
+	}
+
 	target.ID = s.IDGenerator.ID()
 	if err := s.putTarget(ctx, tx, target); err != nil {
 		return err
@@ -250,6 +263,12 @@ func (s *Service) updateTarget(ctx context.Context, tx Tx, update *influxdb.Scra
 	if !update.OrgID.Valid() {
 		update.OrgID = target.OrgID
 	}
+	// Secrets are redacted when targets are read, so keep the stored ones
+	// unless new values are provided.
+	update.KeepSecrets(target)
+	if err := update.Valid(); err != nil {
+		return nil, ErrInvalidScraperConfig(err)
+	}
 	target = update
 	return target, s.putTarget(ctx, tx, target)
 }
@@ -84,6 +84,10 @@ func NewService(log *zap.Logger, kv Store, configs ...ServiceConfig) *Service {
 		s.Config.SessionLength = influxdb.DefaultSessionLength
 	}
 
+	if s.Config.SystemBuckets.TasksName == "" {
+		s.Config.SystemBuckets = influxdb.DefaultSystemBuckets()
+	}
+
 	s.clock = s.Config.Clock
 	if s.clock == nil {
 		s.clock = clock.New()
@@ -98,6 +102,9 @@ type ServiceConfig struct {
 	SessionLength       time.Duration
 	Clock               clock.Clock
 	FluxLanguageService influxdb.FluxLanguageService
+	// SystemBuckets are the system buckets created for and served to
+	// organizations, the built-in ones if unset.
+	SystemBuckets influxdb.SystemBuckets
 }
 
 // WithResourceLogger sets the resource audit logger for the service.
@@ -55,6 +55,22 @@ type kvTask struct {
 	CreatedAt       time.Time              `json:"createdAt,omitempty"`
 	UpdatedAt       time.Time              `json:"updatedAt,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	// AuthorizationID is the task's service token. It is stored apart from
+	// the authorizationID of legacy tasks, which referenced a token of the
+	// owner and must never be deleted with the task.
+	AuthorizationID influxdb.ID `json:"serviceAuthorizationID,omitempty"`
+}
+
+// marshalTask encodes a task for storage. Unlike its API representation, the
+// stored task includes the ID of the task's service token.
+func marshalTask(t *influxdb.Task) ([]byte, error) {
+	return json.Marshal(struct {
+		*influxdb.Task
+		AuthorizationID influxdb.ID `json:"serviceAuthorizationID,omitempty"`
+	}{
+		Task:            t,
+		AuthorizationID: t.AuthorizationID,
+	})
 }
 
 func kvToInfluxTask(k *kvTask) *influxdb.Task {
@@ -78,6 +94,7 @@ func kvToInfluxTask(k *kvTask) *influxdb.Task {
 		CreatedAt:       k.CreatedAt,
 		UpdatedAt:       k.UpdatedAt,
 		Metadata:        k.Metadata,
+		AuthorizationID: k.AuthorizationID,
 	}
 }
 
@@ -116,6 +133,35 @@ func (s *Service) findTaskByIDWithAuth(ctx context.Context, tx Tx, id influxdb.I
 		return nil, err
 	}
 
+	// Tasks with a service token run with the token's permissions. A task
+	// whose token was deleted cannot run.
+	if t.AuthorizationID.Valid() {
+		auth, err := s.findAuthorizationByID(ctx, tx, t.AuthorizationID)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			t.Authorization = &influxdb.Authorization{
+				Status: influxdb.Inactive,
+				ID:     t.AuthorizationID,
+				OrgID:  t.OrganizationID,
+				UserID: t.OwnerID,
+			}
+			return t, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		t.Authorization = &influxdb.Authorization{
+			Status: auth.Status,
+			ID:     auth.ID,
+			OrgID:  t.OrganizationID,
+			UserID: t.OwnerID,
+		}
+		if auth.IsActive() {
+			t.Authorization.Permissions = auth.Permissions
+			t.Authorization.RowFilters = auth.RowFilters
+		}
+		return t, nil
+	}
+
 	t.Authorization = &influxdb.Authorization{
 		Status: influxdb.Active,
 		ID:     influxdb.ID(1),
@@ -626,7 +672,7 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
 	}
 
-	taskBytes, err := json.Marshal(task)
+	taskBytes, err := marshalTask(task)
 	if err != nil {
 		return nil, influxdb.ErrInternalTaskServiceError(err)
 	}
@@ -754,6 +800,10 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		task.UpdatedAt = updatedAt
 	}
 
+	if upd.AuthorizationID != nil {
+		task.AuthorizationID = *upd.AuthorizationID
+	}
+
 	if upd.LatestCompleted != nil {
 		// make sure we only update latest completed one way
 		tlc := task.LatestCompleted
@@ -790,7 +840,7 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		return nil, err
 	}
 
-	taskBytes, err := json.Marshal(task)
+	taskBytes, err := marshalTask(task)
 	if err != nil {
 		return nil, influxdb.ErrInternalTaskServiceError(err)
 	}
@@ -925,7 +975,7 @@ func (s *Service) FindLogs(ctx context.Context, filter influxdb.LogFilter) ([]*i
 		if err != nil {
 			return err
 		}
-		logs = ls
+		logs = filter.Page(ls)
 		return nil
 	})
 	if err != nil {
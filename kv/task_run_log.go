@@ -0,0 +1,217 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var taskRunLogSettingsBucket = []byte("taskrunlogsettingsv1")
+
+var _ influxdb.TaskRunLogSettingsService = (*Service)(nil)
+
+// FindTaskRunLogSettings returns the run log settings of the organization.
+func (s *Service) FindTaskRunLogSettings(ctx context.Context, orgID influxdb.ID) (*influxdb.TaskRunLogSettings, error) {
+	var settings *influxdb.TaskRunLogSettings
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		settings, err = s.findTaskRunLogSettings(ctx, tx, orgID)
+		return err
+	})
+	return settings, err
+}
+
+func (s *Service) findTaskRunLogSettings(ctx context.Context, tx Tx, orgID influxdb.ID) (*influxdb.TaskRunLogSettings, error) {
+	encodedID, err := orgID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(taskRunLogSettingsBucket)
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+	v, err := b.Get(encodedID)
+	if IsNotFound(err) {
+		return &influxdb.TaskRunLogSettings{OrgID: orgID}, nil
+	}
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	var settings influxdb.TaskRunLogSettings
+	if err := json.Unmarshal(v, &settings); err != nil {
+		return nil, influxdb.ErrInternalTaskServiceError(err)
+	}
+	return &settings, nil
+}
+
+// PutTaskRunLogSettings replaces the run log settings of the organization.
+// The export bucket must be a bucket of the organization.
+func (s *Service) PutTaskRunLogSettings(ctx context.Context, settings *influxdb.TaskRunLogSettings) error {
+	if err := settings.Valid(); err != nil {
+		return err
+	}
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		if settings.ExportBucketID != nil {
+			b, err := s.findBucketByID(ctx, tx, *settings.ExportBucketID)
+			if err != nil {
+				return err
+			}
+			if b.OrgID != settings.OrgID {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "the export bucket must be a bucket of the organization",
+				}
+			}
+		}
+
+		current, err := s.findTaskRunLogSettings(ctx, tx, settings.OrgID)
+		if err != nil {
+			return err
+		}
+		now := s.TimeGenerator.Now()
+		settings.CreatedAt = current.CreatedAt
+		if settings.CreatedAt.IsZero() {
+			settings.CreatedAt = now
+		}
+		settings.UpdatedAt = now
+
+		encodedID, err := settings.OrgID.Encode()
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(settings)
+		if err != nil {
+			return influxdb.ErrInternalTaskServiceError(err)
+		}
+		b, err := tx.Bucket(taskRunLogSettingsBucket)
+		if err != nil {
+			return influxdb.ErrUnexpectedTaskBucketErr(err)
+		}
+		if err := b.Put(encodedID, v); err != nil {
+			return influxdb.ErrUnexpectedTaskBucketErr(err)
+		}
+		return nil
+	})
+}
+
+// PruneRunLogs removes the logs of the runs in progress that are older than
+// the retention of the organizations of their tasks, and returns them.
+func (s *Service) PruneRunLogs(ctx context.Context, now time.Time) ([]*influxdb.RunLogs, error) {
+	var pruned []*influxdb.RunLogs
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var err error
+		pruned, err = s.pruneRunLogs(ctx, tx, now)
+		return err
+	})
+	return pruned, err
+}
+
+func (s *Service) pruneRunLogs(ctx context.Context, tx Tx, now time.Time) ([]*influxdb.RunLogs, error) {
+	bucket, err := tx.Bucket(taskRunBucket)
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	cursor, err := bucket.ForwardCursor(nil)
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+	defer cursor.Close()
+
+	// the runs are rewritten once the cursor is closed.
+	var (
+		runs      []*influxdb.Run
+		pruned    []*influxdb.RunLogs
+		orgs      = map[influxdb.ID]influxdb.ID{}
+		retention = map[influxdb.ID]time.Duration{}
+	)
+	for k, v := cursor.Next(); k != nil; k, v = cursor.Next() {
+		// the run keys are <taskID>/<runID>, the other keys of the bucket
+		// are not runs.
+		if len(k) != 2*influxdb.IDLength+1 {
+			continue
+		}
+
+		run := &influxdb.Run{}
+		if err := json.Unmarshal(v, run); err != nil {
+			return nil, influxdb.ErrInternalTaskServiceError(err)
+		}
+		if len(run.Log) == 0 {
+			continue
+		}
+
+		orgID, ok := orgs[run.TaskID]
+		if !ok {
+			task, err := s.findTaskByID(ctx, tx, run.TaskID)
+			if err != nil {
+				// the runs of deleted tasks are not pruned.
+				continue
+			}
+			orgID = task.OrganizationID
+			orgs[run.TaskID] = orgID
+		}
+		d, ok := retention[orgID]
+		if !ok {
+			settings, err := s.findTaskRunLogSettings(ctx, tx, orgID)
+			if err != nil {
+				return nil, err
+			}
+			d = settings.Retention
+			retention[orgID] = d
+		}
+		if d == 0 {
+			continue
+		}
+
+		cutoff := now.Add(-d)
+		var keep, removed []influxdb.Log
+		for _, l := range run.Log {
+			if t, err := time.Parse(time.RFC3339Nano, l.Time); err == nil && t.Before(cutoff) {
+				removed = append(removed, l)
+				continue
+			}
+			keep = append(keep, l)
+		}
+		if len(removed) == 0 {
+			continue
+		}
+
+		run.Log = keep
+		runs = append(runs, run)
+		pruned = append(pruned, &influxdb.RunLogs{
+			OrgID:  orgID,
+			TaskID: run.TaskID,
+			RunID:  run.ID,
+			Logs:   removed,
+		})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+	if err := cursor.Close(); err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	for _, run := range runs {
+		v, err := json.Marshal(run)
+		if err != nil {
+			return nil, influxdb.ErrInternalTaskServiceError(err)
+		}
+		key, err := taskRunKey(run.TaskID, run.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := bucket.Put(key, v); err != nil {
+			return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+		}
+	}
+	return pruned, nil
+}
@@ -0,0 +1,61 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_PruneRunLogs(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	settings, err := ts.Service.FindTaskRunLogSettings(ctx, ts.Org.ID)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), settings.Retention, "expected the logs to be kept by default")
+
+	err = ts.Service.PutTaskRunLogSettings(ctx, &influxdb.TaskRunLogSettings{OrgID: ts.Org.ID, Retention: -time.Hour})
+	assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+
+	task, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           `option task = {name: "a task", every: 1h} from(bucket:"test") |> range(start:-1h)`,
+		OrganizationID: ts.Org.ID,
+		OwnerID:        ts.User.ID,
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	run, err := ts.Service.CreateRun(ctx, task.ID, now, now)
+	require.NoError(t, err)
+	require.NoError(t, ts.Service.AddRunLog(ctx, task.ID, run.ID, now.Add(-2*time.Hour), "old"))
+	require.NoError(t, ts.Service.AddRunLog(ctx, task.ID, run.ID, now, "new"))
+
+	// without retention nothing is pruned
+	pruned, err := ts.Service.PruneRunLogs(ctx, now)
+	require.NoError(t, err)
+	assert.Empty(t, pruned)
+
+	require.NoError(t, ts.Service.PutTaskRunLogSettings(ctx, &influxdb.TaskRunLogSettings{OrgID: ts.Org.ID, Retention: time.Hour}))
+
+	pruned, err = ts.Service.PruneRunLogs(ctx, now)
+	require.NoError(t, err)
+	require.Len(t, pruned, 1)
+	assert.Equal(t, ts.Org.ID, pruned[0].OrgID)
+	assert.Equal(t, run.ID, pruned[0].RunID)
+	require.Len(t, pruned[0].Logs, 1)
+	assert.Equal(t, "old", pruned[0].Logs[0].Message)
+
+	logs, _, err := ts.Service.FindLogs(ctx, influxdb.LogFilter{Task: task.ID, Run: &run.ID})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "new", logs[0].Message)
+}
@@ -210,6 +210,50 @@ func TestRetrieveTaskWithBadAuth(t *testing.T) {
 	}
 }
 
+func TestFindTaskByID_DeletedServiceToken(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	task, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           `option task = {name: "a task",every: 1h} from(bucket:"test") |> range(start:-1h)`,
+		OrganizationID: ts.Org.ID,
+		OwnerID:        ts.User.ID,
+		Status:         string(influxdb.TaskActive),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := &influxdb.Authorization{
+		OrgID:       ts.Org.ID,
+		UserID:      ts.User.ID,
+		Permissions: []influxdb.Permission{{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.OrgsResourceType, ID: &ts.Org.ID}}},
+	}
+	if err := ts.Service.CreateAuthorization(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Service.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{AuthorizationID: &token.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.Service.DeleteAuthorization(ctx, token.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The task must not fall back to the permissions of its owner.
+	found, err := ts.Service.FindTaskByID(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Authorization.IsActive() || len(found.Authorization.Permissions) != 0 {
+		t.Fatalf("expected an inactive authorization without permissions, got %+v", found.Authorization)
+	}
+}
+
 func TestService_UpdateTask_InactiveToActive(t *testing.T) {
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	defer cancelFunc()
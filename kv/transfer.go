@@ -0,0 +1,444 @@
+package kv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.ResourceTransferService = (*Service)(nil)
+
+// TransferResourceOwnership makes the user the owner of the resource.
+func (s *Service) TransferResourceOwnership(ctx context.Context, rt influxdb.ResourceType, id, userID influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.transferResourceOwnership(ctx, tx, rt, id, userID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpTransferResourceOwnership,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) transferResourceOwnership(ctx context.Context, tx Tx, rt influxdb.ResourceType, id, userID influxdb.ID) error {
+	if _, err := s.findUserByID(ctx, tx, userID); err != nil {
+		return err
+	}
+
+	switch rt {
+	case influxdb.TasksResourceType:
+		t, err := s.findTaskByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.requireOrgMember(ctx, tx, t.OrganizationID, userID); err != nil {
+			return err
+		}
+		return s.setTaskOwner(ctx, tx, t, userID)
+
+	case influxdb.DashboardsResourceType:
+		d, err := s.findDashboardByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.requireOrgMember(ctx, tx, d.OrganizationID, userID); err != nil {
+			return err
+		}
+		return s.replaceResourceOwner(ctx, tx, rt, id, userID)
+
+	case influxdb.ChecksResourceType:
+		c, err := s.findCheckByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.requireOrgMember(ctx, tx, c.GetOrgID(), userID); err != nil {
+			return err
+		}
+		c.SetOwnerID(userID)
+		c.SetUpdatedAt(s.Now())
+		if err := s.putCheck(ctx, tx, c, PutUpdate()); err != nil {
+			return err
+		}
+		return s.setBackingTaskOwner(ctx, tx, c.GetTaskID(), userID)
+
+	case influxdb.NotificationRuleResourceType:
+		nr, err := s.findNotificationRuleByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.requireOrgMember(ctx, tx, nr.GetOrgID(), userID); err != nil {
+			return err
+		}
+		nr.SetOwnerID(userID)
+		nr.SetUpdatedAt(s.Now())
+		if err := s.putNotificationRule(ctx, tx, nr); err != nil {
+			return err
+		}
+		return s.setBackingTaskOwner(ctx, tx, nr.GetTaskID(), userID)
+	}
+
+	return errNotTransferable(rt)
+}
+
+// MoveResource moves a resource, its labels and its user resource mappings
+// to another organization.
+func (s *Service) MoveResource(ctx context.Context, rt influxdb.ResourceType, id, orgID influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.moveResource(ctx, tx, rt, id, orgID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpMoveResource,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) moveResource(ctx context.Context, tx Tx, rt influxdb.ResourceType, id, orgID influxdb.ID) error {
+	org, err := s.findOrganizationByID(ctx, tx, orgID)
+	if err != nil {
+		return err
+	}
+
+	switch rt {
+	case influxdb.TasksResourceType:
+		t, err := s.findTaskByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if t.OrganizationID == orgID {
+			return nil
+		}
+		if t.Type != "" && t.Type != influxdb.TaskSystemType {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "tasks of checks and notification rules are moved with them",
+			}
+		}
+		if err := s.requireOrgMember(ctx, tx, orgID, t.OwnerID); err != nil {
+			return err
+		}
+		if err := s.moveTask(ctx, tx, t, org); err != nil {
+			return err
+		}
+
+	case influxdb.DashboardsResourceType:
+		d, err := s.findDashboardByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if d.OrganizationID == orgID {
+			return nil
+		}
+		if err := s.removeOrganizationDashboardIndex(ctx, tx, d); err != nil {
+			return err
+		}
+		d.OrganizationID = orgID
+		if err := s.putOrganizationDashboardIndex(ctx, tx, d); err != nil {
+			return err
+		}
+		if err := s.putDashboardWithMeta(ctx, tx, d); err != nil {
+			return err
+		}
+		if err := s.removeNonMemberMappings(ctx, tx, rt, id, orgID); err != nil {
+			return err
+		}
+
+	case influxdb.ChecksResourceType:
+		c, err := s.findCheckByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if c.GetOrgID() == orgID {
+			return nil
+		}
+		if err := s.requireOrgMember(ctx, tx, orgID, c.GetOwnerID()); err != nil {
+			return err
+		}
+		c.SetOrgID(orgID)
+		c.SetUpdatedAt(s.Now())
+		// The update fails if the organization has a check of the same name.
+		if err := s.putCheck(ctx, tx, c, PutUpdate()); err != nil {
+			return err
+		}
+		t, err := s.findTaskByID(ctx, tx, c.GetTaskID())
+		if err != nil {
+			return err
+		}
+		if err := s.moveTask(ctx, tx, t, org); err != nil {
+			return err
+		}
+		if err := s.deleteUserResourceMappings(ctx, tx, influxdb.UserResourceMappingFilter{
+			ResourceType: rt,
+			ResourceID:   id,
+		}); err != nil {
+			return err
+		}
+		if err := s.createUserResourceMappingForOrg(ctx, tx, orgID, id, rt); err != nil {
+			return err
+		}
+
+	case influxdb.NotificationRuleResourceType:
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "notification rules cannot be moved, their endpoint belongs to their organization",
+		}
+
+	default:
+		return errNotTransferable(rt)
+	}
+
+	return s.moveResourceLabels(ctx, tx, rt, id, orgID)
+}
+
+// requireOrgMember returns an error if the user is neither a member nor an
+// owner of the organization.
+func (s *Service) requireOrgMember(ctx context.Context, tx Tx, orgID, userID influxdb.ID) error {
+	ms, err := s.findUserResourceMappings(ctx, tx, influxdb.UserResourceMappingFilter{
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   orgID,
+		UserID:       userID,
+	})
+	if err != nil {
+		return err
+	}
+	if len(ms) == 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("user %s is not a member of organization %s", userID, orgID),
+		}
+	}
+	return nil
+}
+
+// replaceResourceOwner makes the user the only owner of the resource. The
+// previous owners remain members of it.
+func (s *Service) replaceResourceOwner(ctx context.Context, tx Tx, rt influxdb.ResourceType, id, userID influxdb.ID) error {
+	ms, err := s.findUserResourceMappings(ctx, tx, influxdb.UserResourceMappingFilter{
+		ResourceType: rt,
+		ResourceID:   id,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, m := range ms {
+		if m.UserID != userID && m.UserType != influxdb.Owner {
+			continue
+		}
+		if err := s.deleteUserResourceMapping(ctx, tx, influxdb.UserResourceMappingFilter{
+			ResourceType: rt,
+			ResourceID:   id,
+			UserID:       m.UserID,
+		}); err != nil {
+			return err
+		}
+		if m.UserID == userID {
+			continue
+		}
+		if err := s.createUserResourceMapping(ctx, tx, &influxdb.UserResourceMapping{
+			ResourceType: rt,
+			ResourceID:   id,
+			UserID:       m.UserID,
+			UserType:     influxdb.Member,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return s.createUserResourceMapping(ctx, tx, &influxdb.UserResourceMapping{
+		ResourceType: rt,
+		ResourceID:   id,
+		UserID:       userID,
+		UserType:     influxdb.Owner,
+	})
+}
+
+// removeNonMemberMappings removes the resource mappings of users who are not
+// members of the organization.
+func (s *Service) removeNonMemberMappings(ctx context.Context, tx Tx, rt influxdb.ResourceType, id, orgID influxdb.ID) error {
+	ms, err := s.findUserResourceMappings(ctx, tx, influxdb.UserResourceMappingFilter{
+		ResourceType: rt,
+		ResourceID:   id,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, m := range ms {
+		err := s.requireOrgMember(ctx, tx, orgID, m.UserID)
+		if err == nil {
+			continue
+		}
+		if influxdb.ErrorCode(err) != influxdb.EInvalid {
+			return err
+		}
+		if err := s.deleteUserResourceMapping(ctx, tx, influxdb.UserResourceMappingFilter{
+			ResourceType: rt,
+			ResourceID:   id,
+			UserID:       m.UserID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveResourceLabels replaces the labels of a resource with the labels of the
+// same name in the organization, creating those that do not exist.
+func (s *Service) moveResourceLabels(ctx context.Context, tx Tx, rt influxdb.ResourceType, id, orgID influxdb.ID) error {
+	ls := []*influxdb.Label{}
+	if err := s.findResourceLabels(ctx, tx, influxdb.LabelMappingFilter{ResourceID: id, ResourceType: rt}, &ls); err != nil {
+		return err
+	}
+
+	for _, l := range ls {
+		if l.OrgID == orgID {
+			continue
+		}
+
+		if err := s.deleteLabelMapping(ctx, tx, &influxdb.LabelMapping{
+			LabelID:      l.ID,
+			ResourceID:   id,
+			ResourceType: rt,
+		}); err != nil {
+			return err
+		}
+
+		existing, err := s.findLabels(ctx, tx, influxdb.LabelFilter{Name: l.Name, OrgID: &orgID})
+		if err != nil {
+			return err
+		}
+
+		var target *influxdb.Label
+		if len(existing) > 0 {
+			target = existing[0]
+		} else {
+			target = &influxdb.Label{
+				ID:         s.IDGenerator.ID(),
+				OrgID:      orgID,
+				Name:       l.Name,
+				Properties: l.Properties,
+			}
+			if err := s.putLabel(ctx, tx, target); err != nil {
+				return err
+			}
+			if err := s.createUserResourceMappingForOrg(ctx, tx, orgID, target.ID, influxdb.LabelsResourceType); err != nil {
+				return err
+			}
+		}
+
+		if err := s.putLabelMapping(ctx, tx, &influxdb.LabelMapping{
+			LabelID:      target.ID,
+			ResourceID:   id,
+			ResourceType: rt,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setBackingTaskOwner changes the owner of the task of a check or
+// notification rule.
+func (s *Service) setBackingTaskOwner(ctx context.Context, tx Tx, taskID, userID influxdb.ID) error {
+	t, err := s.findTaskByID(ctx, tx, taskID)
+	if err != nil {
+		return err
+	}
+	return s.setTaskOwner(ctx, tx, t, userID)
+}
+
+// setTaskOwner changes the owner of a task. The service token of the task,
+// if any, was granted by the previous owner, so it is revoked.
+func (s *Service) setTaskOwner(ctx context.Context, tx Tx, t *influxdb.Task, userID influxdb.ID) error {
+	if err := s.revokeTaskToken(ctx, tx, t); err != nil {
+		return err
+	}
+
+	t.OwnerID = userID
+	t.UpdatedAt = s.clock.Now().UTC()
+	return s.putTask(ctx, tx, t)
+}
+
+// moveTask moves a task to the organization. The service token of the task
+// grants access to the buckets of its previous organization, so it is
+// revoked.
+func (s *Service) moveTask(ctx context.Context, tx Tx, t *influxdb.Task, org *influxdb.Organization) error {
+	idx, err := tx.Bucket(taskIndexBucket)
+	if err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	oldKey, err := taskOrgKey(t.OrganizationID, t.ID)
+	if err != nil {
+		return err
+	}
+	if err := idx.Delete(oldKey); err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	newKey, err := taskOrgKey(org.ID, t.ID)
+	if err != nil {
+		return err
+	}
+	key, err := taskKey(t.ID)
+	if err != nil {
+		return err
+	}
+	if err := idx.Put(newKey, key); err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	if err := s.revokeTaskToken(ctx, tx, t); err != nil {
+		return err
+	}
+
+	t.OrganizationID = org.ID
+	t.Organization = org.Name
+	t.UpdatedAt = s.clock.Now().UTC()
+	return s.putTask(ctx, tx, t)
+}
+
+// revokeTaskToken deletes the service token of a task, if any. The task keeps
+// the ID of the deleted token, so that it does not run until a new token is
+// issued to it rather than falling back to the permissions of its owner.
+func (s *Service) revokeTaskToken(ctx context.Context, tx Tx, t *influxdb.Task) error {
+	if !t.AuthorizationID.Valid() {
+		return nil
+	}
+	if err := s.deleteAuthorization(ctx, tx, t.AuthorizationID); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) putTask(ctx context.Context, tx Tx, t *influxdb.Task) error {
+	bucket, err := tx.Bucket(taskBucket)
+	if err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+	key, err := taskKey(t.ID)
+	if err != nil {
+		return err
+	}
+	taskBytes, err := marshalTask(t)
+	if err != nil {
+		return influxdb.ErrInternalTaskServiceError(err)
+	}
+	if err := bucket.Put(key, taskBytes); err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+	return nil
+}
+
+func errNotTransferable(rt influxdb.ResourceType) error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("resources of type %q cannot be transferred", rt),
+	}
+}
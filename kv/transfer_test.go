@@ -0,0 +1,156 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_TransferResources(t *testing.T) {
+	store, closeStore, err := NewTestInmemStore(t)
+	require.NoError(t, err)
+	defer closeStore()
+
+	svc := kv.NewService(zaptest.NewLogger(t), store, kv.ServiceConfig{
+		FluxLanguageService: fluxlang.DefaultService,
+	})
+	ctx := context.Background()
+
+	var users [3]*influxdb.User
+	for i, name := range []string{"alice", "bob", "eve"} {
+		users[i] = &influxdb.User{Name: name}
+		require.NoError(t, svc.CreateUser(ctx, users[i]))
+	}
+	alice, bob, eve := users[0], users[1], users[2]
+
+	from := &influxdb.Organization{Name: "from"}
+	require.NoError(t, svc.CreateOrganization(ctx, from))
+	to := &influxdb.Organization{Name: "to"}
+	require.NoError(t, svc.CreateOrganization(ctx, to))
+
+	for _, m := range []*influxdb.UserResourceMapping{
+		{UserID: alice.ID, UserType: influxdb.Owner, ResourceType: influxdb.OrgsResourceType, ResourceID: from.ID},
+		{UserID: bob.ID, UserType: influxdb.Member, ResourceType: influxdb.OrgsResourceType, ResourceID: from.ID},
+		{UserID: bob.ID, UserType: influxdb.Owner, ResourceType: influxdb.OrgsResourceType, ResourceID: to.ID},
+	} {
+		require.NoError(t, svc.CreateUserResourceMapping(ctx, m))
+	}
+
+	ctx = icontext.SetAuthorizer(ctx, &influxdb.Authorization{UserID: alice.ID})
+
+	t.Run("dashboard", func(t *testing.T) {
+		d := &influxdb.Dashboard{OrganizationID: from.ID, Name: "overview"}
+		require.NoError(t, svc.CreateDashboard(ctx, d))
+
+		l := &influxdb.Label{OrgID: from.ID, Name: "prod", Properties: map[string]string{"color": "ffb3b3"}}
+		require.NoError(t, svc.CreateLabel(ctx, l))
+		require.NoError(t, svc.CreateLabelMapping(ctx, &influxdb.LabelMapping{
+			LabelID:      l.ID,
+			ResourceID:   d.ID,
+			ResourceType: influxdb.DashboardsResourceType,
+		}))
+
+		err := svc.TransferResourceOwnership(ctx, influxdb.DashboardsResourceType, d.ID, eve.ID)
+		assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err), "users outside the organization cannot own its resources")
+
+		require.NoError(t, svc.TransferResourceOwnership(ctx, influxdb.DashboardsResourceType, d.ID, bob.ID))
+		urms, _, err := svc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.DashboardsResourceType,
+			ResourceID:   d.ID,
+		})
+		require.NoError(t, err)
+		userTypes := make(map[influxdb.ID]influxdb.UserType)
+		for _, m := range urms {
+			userTypes[m.UserID] = m.UserType
+		}
+		assert.Equal(t, map[influxdb.ID]influxdb.UserType{
+			alice.ID: influxdb.Member,
+			bob.ID:   influxdb.Owner,
+		}, userTypes)
+
+		require.NoError(t, svc.MoveResource(ctx, influxdb.DashboardsResourceType, d.ID, to.ID))
+		moved, err := svc.FindDashboardByID(ctx, d.ID)
+		require.NoError(t, err)
+		assert.Equal(t, to.ID, moved.OrganizationID)
+
+		ls, err := svc.FindResourceLabels(ctx, influxdb.LabelMappingFilter{
+			ResourceID:   d.ID,
+			ResourceType: influxdb.DashboardsResourceType,
+		})
+		require.NoError(t, err)
+		require.Len(t, ls, 1)
+		assert.Equal(t, to.ID, ls[0].OrgID)
+		assert.Equal(t, "prod", ls[0].Name)
+		assert.Equal(t, l.Properties, ls[0].Properties)
+
+		// alice is not a member of the organization the dashboard moved to.
+		urms, _, err = svc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.DashboardsResourceType,
+			ResourceID:   d.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, urms, 1)
+		assert.Equal(t, bob.ID, urms[0].UserID)
+	})
+
+	t.Run("task", func(t *testing.T) {
+		task, err := svc.CreateTask(ctx, influxdb.TaskCreate{
+			OrganizationID: from.ID,
+			OwnerID:        alice.ID,
+			Type:           influxdb.TaskSystemType,
+			Flux: `option task = {name: "downsample", every: 1h}
+from(bucket: "raw") |> range(start: -1h) |> to(bucket: "downsampled")`,
+		})
+		require.NoError(t, err)
+
+		err = svc.MoveResource(ctx, influxdb.TasksResourceType, task.ID, to.ID)
+		assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err), "the owner of a task must be a member of its organization")
+
+		require.NoError(t, svc.TransferResourceOwnership(ctx, influxdb.TasksResourceType, task.ID, bob.ID))
+		require.NoError(t, svc.MoveResource(ctx, influxdb.TasksResourceType, task.ID, to.ID))
+
+		tasks, _, err := svc.FindTasks(ctx, influxdb.TaskFilter{OrganizationID: &to.ID})
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, task.ID, tasks[0].ID)
+		assert.Equal(t, bob.ID, tasks[0].OwnerID)
+		assert.Equal(t, to.Name, tasks[0].Organization)
+
+		tasks, _, err = svc.FindTasks(ctx, influxdb.TaskFilter{OrganizationID: &from.ID})
+		require.NoError(t, err)
+		assert.Empty(t, tasks)
+	})
+
+	t.Run("task service token", func(t *testing.T) {
+		task, err := svc.CreateTask(ctx, influxdb.TaskCreate{
+			OrganizationID: from.ID,
+			OwnerID:        alice.ID,
+			Type:           influxdb.TaskSystemType,
+			Flux: `option task = {name: "scoped", every: 1h}
+from(bucket: "raw") |> range(start: -1h) |> to(bucket: "downsampled")`,
+		})
+		require.NoError(t, err)
+		token := &influxdb.Authorization{OrgID: from.ID, UserID: alice.ID, Permissions: influxdb.OwnerPermissions(from.ID)}
+		require.NoError(t, svc.CreateAuthorization(ctx, token))
+		_, err = svc.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{AuthorizationID: &token.ID})
+		require.NoError(t, err)
+
+		require.NoError(t, svc.TransferResourceOwnership(ctx, influxdb.TasksResourceType, task.ID, bob.ID))
+
+		// The token granted by alice is revoked, and the task does not run
+		// until a token is issued to bob.
+		_, err = svc.FindAuthorizationByID(ctx, token.ID)
+		assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+		found, err := svc.FindTaskByID(ctx, task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, token.ID, found.AuthorizationID)
+		assert.False(t, found.Authorization.IsActive())
+	})
+}
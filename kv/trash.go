@@ -0,0 +1,524 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+)
+
+var trashBucket = []byte("trashv1")
+
+var _ influxdb.TrashService = (*Service)(nil)
+
+// ErrTrashedResourceNotFound is used when a trashed resource is not found.
+var ErrTrashedResourceNotFound = &influxdb.Error{
+	Code: influxdb.ENotFound,
+	Msg:  "trashed resource not found",
+}
+
+// trashRecord is a trashed resource together with everything needed to
+// restore it.
+type trashRecord struct {
+	influxdb.TrashedResource
+
+	// Resource is the resource as it was stored.
+	Resource json.RawMessage `json:"resource"`
+	// Task is the task of a check or notification rule.
+	Task     json.RawMessage                 `json:"task,omitempty"`
+	Views    []trashedView                   `json:"views,omitempty"`
+	Labels   []influxdb.ID                   `json:"labels,omitempty"`
+	Mappings []*influxdb.UserResourceMapping `json:"mappings,omitempty"`
+}
+
+// trashedView is the view of a dashboard cell.
+type trashedView struct {
+	CellID influxdb.ID    `json:"cellID"`
+	View   *influxdb.View `json:"view"`
+}
+
+// TrashResource saves a copy of a resource that is about to be deleted.
+func (s *Service) TrashResource(ctx context.Context, rt influxdb.ResourceType, id influxdb.ID) (*influxdb.TrashedResource, error) {
+	var r *trashRecord
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var err error
+		r, err = s.trashResource(ctx, tx, rt, id)
+		return err
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpTrashResource,
+			Err: err,
+		}
+	}
+	return &r.TrashedResource, nil
+}
+
+func (s *Service) trashResource(ctx context.Context, tx Tx, rt influxdb.ResourceType, id influxdb.ID) (*trashRecord, error) {
+	r := &trashRecord{
+		TrashedResource: influxdb.TrashedResource{
+			ID:           s.IDGenerator.ID(),
+			ResourceType: rt,
+			ResourceID:   id,
+		},
+	}
+
+	// resource is the stored representation of the resource, tasks are
+	// encoded with marshalTask to keep their service token.
+	var resource interface{}
+	switch rt {
+	case influxdb.DashboardsResourceType:
+		d, err := s.findDashboardByID(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range d.Cells {
+			v, err := s.findDashboardCellView(ctx, tx, d.ID, c.ID)
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			r.Views = append(r.Views, trashedView{CellID: c.ID, View: v})
+		}
+		r.OrgID, r.Name = d.OrganizationID, d.Name
+		resource = d
+
+	case influxdb.TasksResourceType:
+		t, err := s.findTaskByID(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if r.Resource, err = marshalTask(t); err != nil {
+			return nil, err
+		}
+		r.OrgID, r.Name = t.OrganizationID, t.Name
+
+	case influxdb.ChecksResourceType:
+		c, err := s.findCheckByID(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if r.Task, err = s.trashedTask(ctx, tx, c.GetTaskID()); err != nil {
+			return nil, err
+		}
+		r.OrgID, r.Name = c.GetOrgID(), c.GetName()
+		resource = c
+
+	case influxdb.NotificationRuleResourceType:
+		nr, err := s.findNotificationRuleByID(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if r.Task, err = s.trashedTask(ctx, tx, nr.GetTaskID()); err != nil {
+			return nil, err
+		}
+		r.OrgID, r.Name = nr.GetOrgID(), nr.GetName()
+		resource = nr
+
+	case influxdb.VariablesResourceType:
+		v, err := s.findVariableByID(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		r.OrgID, r.Name = v.OrganizationID, v.Name
+		resource = v
+
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("resources of type %q cannot be trashed", rt),
+		}
+	}
+
+	var err error
+	if resource != nil {
+		if r.Resource, err = json.Marshal(resource); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+	}
+
+	ls := []*influxdb.Label{}
+	if err := s.findResourceLabels(ctx, tx, influxdb.LabelMappingFilter{ResourceID: id, ResourceType: rt}, &ls); err != nil {
+		return nil, err
+	}
+	for _, l := range ls {
+		r.Labels = append(r.Labels, l.ID)
+	}
+
+	if r.Mappings, err = s.findUserResourceMappings(ctx, tx, influxdb.UserResourceMappingFilter{
+		ResourceType: rt,
+		ResourceID:   id,
+	}); err != nil {
+		return nil, err
+	}
+
+	r.DeletedBy, _ = icontext.GetUserID(ctx)
+	r.DeletedAt = s.Now()
+	r.ExpiresAt = r.DeletedAt.Add(influxdb.TrashRetention)
+
+	if err := s.putTrashRecord(tx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *Service) trashedTask(ctx context.Context, tx Tx, id influxdb.ID) (json.RawMessage, error) {
+	t, err := s.findTaskByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	return marshalTask(t)
+}
+
+// FindTrashedResourceByID returns a single trashed resource by ID.
+func (s *Service) FindTrashedResourceByID(ctx context.Context, id influxdb.ID) (*influxdb.TrashedResource, error) {
+	var r *trashRecord
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		r, err = s.findTrashRecord(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindTrashedResourceByID,
+			Err: err,
+		}
+	}
+	return &r.TrashedResource, nil
+}
+
+// FindTrashedResources returns the trashed resources that match the filter,
+// most recently deleted first.
+func (s *Service) FindTrashedResources(ctx context.Context, filter influxdb.TrashFilter) ([]*influxdb.TrashedResource, int, error) {
+	var trs []*influxdb.TrashedResource
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.forEachTrashRecord(tx, func(r *trashRecord) error {
+			if filter.OrgID != nil && r.OrgID != *filter.OrgID {
+				return nil
+			}
+			if filter.ResourceType != nil && r.ResourceType != *filter.ResourceType {
+				return nil
+			}
+			trs = append(trs, &r.TrashedResource)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  influxdb.OpFindTrashedResources,
+			Err: err,
+		}
+	}
+
+	sort.Slice(trs, func(i, j int) bool {
+		return trs[i].DeletedAt.After(trs[j].DeletedAt)
+	})
+	return trs, len(trs), nil
+}
+
+// RestoreResource recreates a trashed resource with its original ID. Restored
+// tasks, and the tasks of restored checks and notification rules, are
+// inactive until they are enabled again.
+func (s *Service) RestoreResource(ctx context.Context, id influxdb.ID) (*influxdb.TrashedResource, error) {
+	var r *trashRecord
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var err error
+		if r, err = s.findTrashRecord(tx, id); err != nil {
+			return err
+		}
+		if err := s.restoreResource(ctx, tx, r); err != nil {
+			return err
+		}
+		return s.deleteTrashRecord(tx, id)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpRestoreResource,
+			Err: err,
+		}
+	}
+	return &r.TrashedResource, nil
+}
+
+func (s *Service) restoreResource(ctx context.Context, tx Tx, r *trashRecord) error {
+	if _, err := s.findOrganizationByID(ctx, tx, r.OrgID); err != nil {
+		return err
+	}
+
+	switch r.ResourceType {
+	case influxdb.DashboardsResourceType:
+		if _, err := s.findDashboardByID(ctx, tx, r.ResourceID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return errResourceExists(r, err)
+		}
+		d := &influxdb.Dashboard{}
+		if err := json.Unmarshal(r.Resource, d); err != nil {
+			return err
+		}
+		if err := s.putOrganizationDashboardIndex(ctx, tx, d); err != nil {
+			return err
+		}
+		if err := s.putDashboard(ctx, tx, d); err != nil {
+			return err
+		}
+		for _, v := range r.Views {
+			if err := s.putDashboardCellView(ctx, tx, d.ID, v.CellID, v.View); err != nil {
+				return err
+			}
+		}
+
+	case influxdb.TasksResourceType:
+		if err := s.restoreTask(ctx, tx, r.Resource); err != nil {
+			return err
+		}
+
+	case influxdb.ChecksResourceType:
+		c, err := check.UnmarshalJSON(r.Resource)
+		if err != nil {
+			return err
+		}
+		if err := s.restoreTask(ctx, tx, r.Task); err != nil {
+			return err
+		}
+		// Fails if the check exists or another check has taken its name.
+		if err := s.putCheck(ctx, tx, c, PutNew()); err != nil {
+			return err
+		}
+
+	case influxdb.NotificationRuleResourceType:
+		if _, err := s.findNotificationRuleByID(ctx, tx, r.ResourceID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return errResourceExists(r, err)
+		}
+		nr, err := rule.UnmarshalJSON(r.Resource)
+		if err != nil {
+			return err
+		}
+		if err := s.restoreTask(ctx, tx, r.Task); err != nil {
+			return err
+		}
+		if err := s.putNotificationRule(ctx, tx, nr); err != nil {
+			return err
+		}
+
+	case influxdb.VariablesResourceType:
+		v := &influxdb.Variable{}
+		if err := json.Unmarshal(r.Resource, v); err != nil {
+			return err
+		}
+		// Fails if the variable exists or another variable has taken its name.
+		if err := s.putVariable(ctx, tx, v, PutNew()); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range r.Mappings {
+		// Mappings of some resources outlive them.
+		if err := s.createUserResourceMapping(ctx, tx, m); err != nil && influxdb.ErrorCode(err) != influxdb.EConflict {
+			return err
+		}
+	}
+
+	for _, id := range r.Labels {
+		if _, err := s.findLabelByID(ctx, tx, id); err != nil {
+			// The label was deleted with the resource in the trash.
+			continue
+		}
+		if err := s.putLabelMapping(ctx, tx, &influxdb.LabelMapping{
+			LabelID:      id,
+			ResourceID:   r.ResourceID,
+			ResourceType: r.ResourceType,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreTask recreates a trashed task. It is restored inactive, with the ID
+// of its service token, which was deleted with it, so that it does not run
+// until a new token is issued to it.
+func (s *Service) restoreTask(ctx context.Context, tx Tx, v json.RawMessage) error {
+	kt := &kvTask{}
+	if err := json.Unmarshal(v, kt); err != nil {
+		return influxdb.ErrInternalTaskServiceError(err)
+	}
+	t := kvToInfluxTask(kt)
+
+	if _, err := s.findTaskByID(ctx, tx, t.ID); err == nil {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  fmt.Sprintf("task %s already exists", t.ID),
+		}
+	} else if influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+
+	t.Status = string(influxdb.TaskInactive)
+	t.UpdatedAt = s.clock.Now().UTC()
+
+	idx, err := tx.Bucket(taskIndexBucket)
+	if err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+	orgKey, err := taskOrgKey(t.OrganizationID, t.ID)
+	if err != nil {
+		return err
+	}
+	key, err := taskKey(t.ID)
+	if err != nil {
+		return err
+	}
+	if err := idx.Put(orgKey, key); err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+	return s.putTask(ctx, tx, t)
+}
+
+// DeleteTrashedResource permanently removes a resource from the trash.
+func (s *Service) DeleteTrashedResource(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findTrashRecord(tx, id); err != nil {
+			return err
+		}
+		return s.deleteTrashRecord(tx, id)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteTrashedResource,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// PurgeTrash permanently removes the resources that expired before t.
+func (s *Service) PurgeTrash(ctx context.Context, t time.Time) (int, error) {
+	var n int
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var expired []influxdb.ID
+		err := s.forEachTrashRecord(tx, func(r *trashRecord) error {
+			if r.ExpiresAt.Before(t) {
+				expired = append(expired, r.ID)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, id := range expired {
+			if err := s.deleteTrashRecord(tx, id); err != nil {
+				return err
+			}
+		}
+		n = len(expired)
+		return nil
+	})
+	return n, err
+}
+
+func (s *Service) findTrashRecord(tx Tx, id influxdb.ID) (*trashRecord, error) {
+	key, err := id.Encode()
+	if err != nil {
+		return nil, influxdb.ErrInvalidID
+	}
+
+	b, err := tx.Bucket(trashBucket)
+	if err != nil {
+		return nil, err
+	}
+	v, err := b.Get(key)
+	if IsNotFound(err) {
+		return nil, ErrTrashedResourceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := &trashRecord{}
+	if err := json.Unmarshal(v, r); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return r, nil
+}
+
+func (s *Service) forEachTrashRecord(tx Tx, fn func(*trashRecord) error) error {
+	b, err := tx.Bucket(trashBucket)
+	if err != nil {
+		return err
+	}
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return err
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		r := &trashRecord{}
+		if err := json.Unmarshal(v, r); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
+
+func (s *Service) putTrashRecord(tx Tx, r *trashRecord) error {
+	key, err := r.ID.Encode()
+	if err != nil {
+		return influxdb.ErrInvalidID
+	}
+	v, err := json.Marshal(r)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(trashBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, v)
+}
+
+func (s *Service) deleteTrashRecord(tx Tx, id influxdb.ID) error {
+	key, err := id.Encode()
+	if err != nil {
+		return influxdb.ErrInvalidID
+	}
+	b, err := tx.Bucket(trashBucket)
+	if err != nil {
+		return err
+	}
+	return b.Delete(key)
+}
+
+func errResourceExists(r *trashRecord, err error) error {
+	if err != nil {
+		return err
+	}
+	return &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  fmt.Sprintf("%s %s already exists", r.ResourceType, r.ResourceID),
+	}
+}
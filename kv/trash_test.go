@@ -0,0 +1,148 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_Trash(t *testing.T) {
+	store, closeStore, err := NewTestInmemStore(t)
+	require.NoError(t, err)
+	defer closeStore()
+
+	deletedAt := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	svc := kv.NewService(zaptest.NewLogger(t), store, kv.ServiceConfig{
+		FluxLanguageService: fluxlang.DefaultService,
+	})
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: deletedAt}
+
+	ctx := context.Background()
+	user := &influxdb.User{Name: "alice"}
+	require.NoError(t, svc.CreateUser(ctx, user))
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+	ctx = icontext.SetAuthorizer(ctx, &influxdb.Authorization{UserID: user.ID})
+
+	t.Run("dashboard", func(t *testing.T) {
+		d := &influxdb.Dashboard{
+			OrganizationID: org.ID,
+			Name:           "overview",
+			Cells: []*influxdb.Cell{{
+				CellProperty: influxdb.CellProperty{W: 4, H: 4},
+				View: &influxdb.View{
+					ViewContents: influxdb.ViewContents{Name: "cpu"},
+					Properties:   influxdb.EmptyViewProperties{},
+				},
+			}},
+		}
+		require.NoError(t, svc.CreateDashboard(ctx, d))
+		l := &influxdb.Label{OrgID: org.ID, Name: "prod"}
+		require.NoError(t, svc.CreateLabel(ctx, l))
+		require.NoError(t, svc.CreateLabelMapping(ctx, &influxdb.LabelMapping{
+			LabelID:      l.ID,
+			ResourceID:   d.ID,
+			ResourceType: influxdb.DashboardsResourceType,
+		}))
+
+		tr, err := svc.TrashResource(ctx, influxdb.DashboardsResourceType, d.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "overview", tr.Name)
+		assert.Equal(t, user.ID, tr.DeletedBy)
+		assert.Equal(t, deletedAt.Add(influxdb.TrashRetention), tr.ExpiresAt)
+		require.NoError(t, svc.DeleteDashboard(ctx, d.ID))
+
+		rt := influxdb.DashboardsResourceType
+		trs, n, err := svc.FindTrashedResources(ctx, influxdb.TrashFilter{OrgID: &org.ID, ResourceType: &rt})
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		assert.Equal(t, tr.ID, trs[0].ID)
+
+		_, err = svc.RestoreResource(ctx, tr.ID)
+		require.NoError(t, err)
+
+		restored, err := svc.FindDashboardByID(ctx, d.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "overview", restored.Name)
+		require.Len(t, restored.Cells, 1)
+		v, err := svc.GetDashboardCellView(ctx, d.ID, restored.Cells[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "cpu", v.Name)
+
+		ls, err := svc.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: d.ID, ResourceType: rt})
+		require.NoError(t, err)
+		require.Len(t, ls, 1)
+		assert.Equal(t, l.ID, ls[0].ID)
+
+		_, err = svc.FindTrashedResourceByID(ctx, tr.ID)
+		assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err), "restored resources are removed from the trash")
+	})
+
+	t.Run("task is restored inactive", func(t *testing.T) {
+		task, err := svc.CreateTask(ctx, influxdb.TaskCreate{
+			OrganizationID: org.ID,
+			OwnerID:        user.ID,
+			Flux:           `option task = {name: "downsample", every: 1h} from(bucket: "raw") |> range(start: -1h)`,
+		})
+		require.NoError(t, err)
+
+		tr, err := svc.TrashResource(ctx, influxdb.TasksResourceType, task.ID)
+		require.NoError(t, err)
+		require.NoError(t, svc.DeleteTask(ctx, task.ID))
+
+		_, err = svc.RestoreResource(ctx, tr.ID)
+		require.NoError(t, err)
+		restored, err := svc.FindTaskByID(ctx, task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, string(influxdb.TaskInactive), restored.Status)
+		assert.Equal(t, task.Flux, restored.Flux)
+	})
+
+	t.Run("variable name conflict", func(t *testing.T) {
+		newVariable := func() *influxdb.Variable {
+			return &influxdb.Variable{
+				OrganizationID: org.ID,
+				Name:           "host",
+				Arguments: &influxdb.VariableArguments{
+					Type:   "constant",
+					Values: influxdb.VariableConstantValues{"a", "b"},
+				},
+			}
+		}
+		v := newVariable()
+		require.NoError(t, svc.CreateVariable(ctx, v))
+		tr, err := svc.TrashResource(ctx, influxdb.VariablesResourceType, v.ID)
+		require.NoError(t, err)
+		require.NoError(t, svc.DeleteVariable(ctx, v.ID))
+
+		require.NoError(t, svc.CreateVariable(ctx, newVariable()))
+		_, err = svc.RestoreResource(ctx, tr.ID)
+		assert.Equal(t, influxdb.EConflict, influxdb.ErrorCode(err))
+
+		_, err = svc.FindTrashedResourceByID(ctx, tr.ID)
+		assert.NoError(t, err, "resources that cannot be restored stay in the trash")
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		n, err := svc.PurgeTrash(ctx, deletedAt.Add(influxdb.TrashRetention-time.Second))
+		require.NoError(t, err)
+		assert.Zero(t, n)
+
+		n, err = svc.PurgeTrash(ctx, deletedAt.Add(influxdb.TrashRetention+time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		_, n, err = svc.FindTrashedResources(ctx, influxdb.TrashFilter{})
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+}
@@ -495,3 +495,40 @@ func (s *Service) addResourceOwner(ctx context.Context, tx Tx, rt influxdb.Resou
 
 	return nil
 }
+
+// restrictResourceMappings marks the user resource mappings of the resource as
+// restricted or not, so that they give permissions on the resource only while
+// it is restricted.
+func (s *Service) restrictResourceMappings(ctx context.Context, tx Tx, rt influxdb.ResourceType, id influxdb.ID, restricted bool) error {
+	ms, err := s.findUserResourceMappings(ctx, tx, influxdb.UserResourceMappingFilter{
+		ResourceType: rt,
+		ResourceID:   id,
+	})
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(urmBucket)
+	if err != nil {
+		return UnavailableURMServiceError(err)
+	}
+	for _, m := range ms {
+		if m.Restricted == restricted {
+			continue
+		}
+		m.Restricted = restricted
+
+		v, err := json.Marshal(m)
+		if err != nil {
+			return ErrUnprocessableMapping(err)
+		}
+		key, err := userResourceKey(m)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(key, v); err != nil {
+			return UnavailableURMServiceError(err)
+		}
+	}
+	return nil
+}
@@ -212,14 +212,28 @@ func (s *Service) CreateVariable(ctx context.Context, v *influxdb.Variable) erro
 		now := s.Now()
 		v.CreatedAt = now
 		v.UpdatedAt = now
-		return s.putVariable(ctx, tx, v, PutNew())
+		if err := s.putVariable(ctx, tx, v, PutNew()); err != nil {
+			return err
+		}
+
+		// Only the owners of a restricted variable can edit it, starting with its creator.
+		if v.Restricted {
+			if err := s.addResourceOwner(ctx, tx, influxdb.VariablesResourceType, v.ID); err != nil {
+				return err
+			}
+			return s.restrictResourceMappings(ctx, tx, influxdb.VariablesResourceType, v.ID, true)
+		}
+		return nil
 	})
 }
 
 // ReplaceVariable puts a variable in the store
 func (s *Service) ReplaceVariable(ctx context.Context, v *influxdb.Variable) error {
 	return s.kv.Update(ctx, func(tx Tx) error {
-		return s.putVariable(ctx, tx, v, PutNew())
+		if err := s.putVariable(ctx, tx, v, PutNew()); err != nil {
+			return err
+		}
+		return s.restrictResourceMappings(ctx, tx, influxdb.VariablesResourceType, v.ID, v.Restricted)
 	})
 }
 
@@ -251,7 +265,13 @@ func (s *Service) UpdateVariable(ctx context.Context, id influxdb.ID, update *in
 		update.Name = strings.TrimSpace(update.Name)
 		update.Apply(m)
 
-		return s.putVariable(ctx, tx, v, PutUpdate())
+		if err := s.putVariable(ctx, tx, v, PutUpdate()); err != nil {
+			return err
+		}
+		if update.Restricted != nil {
+			return s.restrictResourceMappings(ctx, tx, influxdb.VariablesResourceType, v.ID, v.Restricted)
+		}
+		return nil
 	})
 
 	return v, err
@@ -2,6 +2,7 @@ package influxdb
 
 import (
 	"context"
+	"fmt"
 )
 
 // ErrLabelNotFound is the error for a missing Label.
@@ -16,8 +17,12 @@ const (
 	OpUpdateLabel        = "UpdateLabel"
 	OpDeleteLabel        = "DeleteLabel"
 	OpDeleteLabelMapping = "DeleteLabelMapping"
+	OpApplyLabelMappings = "ApplyLabelMappings"
 )
 
+// MaxLabelMappingBatchSize is the maximum number of mappings in a LabelMappingBatch.
+const MaxLabelMappingBatchSize = 1000
+
 // errors on label
 var (
 	// ErrLabelNameisEmpty is error when org name is empty
@@ -59,6 +64,10 @@ type LabelService interface {
 
 	// DeleteLabelMapping deletes a label mapping
 	DeleteLabelMapping(ctx context.Context, m *LabelMapping) error
+
+	// ApplyLabelMappings attaches a label to and detaches it from many
+	// resources in one transaction.
+	ApplyLabelMappings(ctx context.Context, b *LabelMappingBatch) error
 }
 
 // Label is a tag set on a resource, typically used for filtering on a UI.
@@ -120,6 +129,55 @@ func (l *LabelMapping) Validate() error {
 	return nil
 }
 
+// LabelMappingBatch is a set of resources a label is attached to or
+// detached from at once. The LabelID of every mapping must be the LabelID of
+// the batch.
+type LabelMappingBatch struct {
+	LabelID ID             `json:"labelID"`
+	Add     []LabelMapping `json:"add,omitempty"`
+	Remove  []LabelMapping `json:"remove,omitempty"`
+}
+
+// SetLabelID sets the label of the batch and of all its mappings.
+func (b *LabelMappingBatch) SetLabelID(id ID) {
+	b.LabelID = id
+	for i := range b.Add {
+		b.Add[i].LabelID = id
+	}
+	for i := range b.Remove {
+		b.Remove[i].LabelID = id
+	}
+}
+
+// Validate returns an error if the batch or any of its mappings is invalid.
+func (b *LabelMappingBatch) Validate() error {
+	if n := len(b.Add) + len(b.Remove); n == 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "label mapping batch is empty",
+		}
+	} else if n > MaxLabelMappingBatchSize {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("label mapping batch has %d mappings, the maximum is %d", n, MaxLabelMappingBatchSize),
+		}
+	}
+	for _, ms := range [][]LabelMapping{b.Add, b.Remove} {
+		for i := range ms {
+			if err := ms[i].Validate(); err != nil {
+				return err
+			}
+			if ms[i].LabelID != b.LabelID {
+				return &Error{
+					Code: EInvalid,
+					Msg:  "label mappings of a batch must all be for the label of the batch",
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // LabelUpdate represents a changeset for a label.
 // Only the properties specified are updated.
 type LabelUpdate struct {
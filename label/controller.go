@@ -92,3 +92,11 @@ func (s *LabelController) DeleteLabelMapping(ctx context.Context, m *influxdb.La
 	return s.oldLabelService.DeleteLabelMapping(ctx, m)
 
 }
+
+func (s *LabelController) ApplyLabelMappings(ctx context.Context, b *influxdb.LabelMappingBatch) error {
+	if s.useNew(ctx) {
+		return s.newLabelService.ApplyLabelMappings(ctx, b)
+	}
+	return s.oldLabelService.ApplyLabelMappings(ctx, b)
+
+}
@@ -133,3 +133,14 @@ func (s *LabelClientService) DeleteLabelMapping(ctx context.Context, m *influxdb
 		Delete(resourceIDPath(m.ResourceType, m.ResourceID, "labels")).
 		Do(ctx)
 }
+
+// ApplyLabelMappings adds and removes many label mappings at once.
+func (s *LabelClientService) ApplyLabelMappings(ctx context.Context, b *influxdb.LabelMappingBatch) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+
+	return s.Client.
+		PostJSON(b, labelIDPath(b.LabelID)+"/mappings:batch").
+		Do(ctx)
+}
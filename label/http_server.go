@@ -49,6 +49,7 @@ func NewHTTPLabelHandler(log *zap.Logger, ls influxdb.LabelService) *LabelHandle
 			r.Get("/", h.handleGetLabel)
 			r.Patch("/", h.handlePatchLabel)
 			r.Delete("/", h.handleDeleteLabel)
+			r.Post("/mappings:batch", h.handlePostLabelMappingsBatch)
 		})
 	})
 
@@ -190,3 +191,31 @@ func (h *LabelHandler) handleDeleteLabel(w http.ResponseWriter, r *http.Request)
 
 	h.api.Respond(w, r, http.StatusNoContent, nil)
 }
+
+// handlePostLabelMappingsBatch is the HTTP handler for the POST /api/v2/labels/:id/mappings:batch route.
+func (h *LabelHandler) handlePostLabelMappingsBatch(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var b influxdb.LabelMappingBatch
+	if err := h.api.DecodeJSON(r.Body, &b); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	b.SetLabelID(*id)
+	if err := b.Validate(); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.labelSvc.ApplyLabelMappings(r.Context(), &b); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Label mappings applied", zap.String("labelID", fmt.Sprint(id)), zap.Int("added", len(b.Add)), zap.Int("removed", len(b.Remove)))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
@@ -125,3 +125,16 @@ func (l *LabelLogger) DeleteLabelMapping(ctx context.Context, m *influxdb.LabelM
 	}(time.Now())
 	return l.labelService.DeleteLabelMapping(ctx, m)
 }
+
+func (l *LabelLogger) ApplyLabelMappings(ctx context.Context, b *influxdb.LabelMappingBatch) (err error) {
+	defer func(start time.Time) {
+		dur := zap.Duration("took", time.Since(start))
+		if err != nil {
+			l.logger.Debug("failed to apply label mappings", zap.Error(err), dur)
+			return
+		}
+		l.logger.Debug("label mappings apply", dur)
+
+	}(time.Now())
+	return l.labelService.ApplyLabelMappings(ctx, b)
+}
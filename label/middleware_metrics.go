@@ -72,3 +72,9 @@ func (m *LabelMetrics) DeleteLabelMapping(ctx context.Context, lm *influxdb.Labe
 	err = m.labelService.DeleteLabelMapping(ctx, lm)
 	return rec(err)
 }
+
+func (m *LabelMetrics) ApplyLabelMappings(ctx context.Context, b *influxdb.LabelMappingBatch) (err error) {
+	rec := m.rec.Record("apply_label_mappings")
+	err = m.labelService.ApplyLabelMappings(ctx, b)
+	return rec(err)
+}
@@ -161,6 +161,51 @@ func (s *Service) CreateLabelMapping(ctx context.Context, m *influxdb.LabelMappi
 	})
 }
 
+// ApplyLabelMappings attaches a label to and detaches it from many resources
+// in one transaction. Resources that already have the label are skipped.
+func (s *Service) ApplyLabelMappings(ctx context.Context, b *influxdb.LabelMappingBatch) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		if _, err := s.store.GetLabel(ctx, tx, b.LabelID); err != nil {
+			return err
+		}
+
+		for i := range b.Add {
+			m := &b.Add[i]
+			ls := []*influxdb.Label{}
+			err := s.store.FindResourceLabels(ctx, tx, influxdb.LabelMappingFilter{ResourceID: m.ResourceID, ResourceType: m.ResourceType}, &ls)
+			if err != nil {
+				return err
+			}
+			if hasLabel(ls, m.LabelID) {
+				continue
+			}
+			if err := s.store.CreateLabelMapping(ctx, tx, m); err != nil {
+				return err
+			}
+		}
+
+		for i := range b.Remove {
+			if err := s.store.DeleteLabelMapping(ctx, tx, &b.Remove[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func hasLabel(ls []*influxdb.Label, id influxdb.ID) bool {
+	for _, l := range ls {
+		if l.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
 // DeleteLabelMapping deletes a label mapping.
 func (s *Service) DeleteLabelMapping(ctx context.Context, m *influxdb.LabelMapping) error {
 	err := s.store.Update(ctx, func(tx kv.Tx) error {
@@ -0,0 +1,143 @@
+package influxdb
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// LegalHoldStatus is the state of a legal hold.
+type LegalHoldStatus string
+
+const (
+	// LegalHoldActive holds exempt data from retention and the delete API.
+	LegalHoldActive LegalHoldStatus = "active"
+	// LegalHoldReleased holds no longer exempt any data. They are kept for auditing.
+	LegalHoldReleased LegalHoldStatus = "released"
+)
+
+// LegalHoldAction is the action recorded by a legal hold audit event.
+type LegalHoldAction string
+
+const (
+	// LegalHoldCreated is recorded when a hold is placed.
+	LegalHoldCreated LegalHoldAction = "created"
+	// LegalHoldReleasedAction is recorded when a hold is released.
+	LegalHoldReleasedAction LegalHoldAction = "released"
+	// LegalHoldDeleteBlocked is recorded when a delete request was refused because of a hold.
+	LegalHoldDeleteBlocked LegalHoldAction = "delete_blocked"
+)
+
+// LegalHold exempts data in a bucket from retention enforcement and the
+// delete API until it is released. A hold may be narrowed to a set of
+// measurements and to a time range; a nil Start or Stop leaves that side
+// of the range unbounded.
+type LegalHold struct {
+	ID           ID              `json:"id,omitempty"`
+	OrgID        ID              `json:"orgID"`
+	BucketID     ID              `json:"bucketID"`
+	Name         string          `json:"name"`
+	Description  string          `json:"description,omitempty"`
+	Measurements []string        `json:"measurements,omitempty"`
+	Start        *time.Time      `json:"start,omitempty"`
+	Stop         *time.Time      `json:"stop,omitempty"`
+	Status       LegalHoldStatus `json:"status"`
+	CRUDLog
+}
+
+// Valid returns an error if the hold is missing required fields or has an
+// inverted time range.
+func (h *LegalHold) Valid() error {
+	if !h.OrgID.Valid() {
+		return errors.New("orgID is required")
+	}
+	if !h.BucketID.Valid() {
+		return errors.New("bucketID is required")
+	}
+	if h.Name == "" {
+		return errors.New("name is required")
+	}
+	if h.Start != nil && h.Stop != nil && h.Stop.Before(*h.Start) {
+		return errors.New("stop must not be before start")
+	}
+	for _, m := range h.Measurements {
+		if m == "" {
+			return errors.New("measurements must not be empty")
+		}
+	}
+	return nil
+}
+
+// Active reports whether the hold currently exempts data.
+func (h *LegalHold) Active() bool {
+	return h.Status == LegalHoldActive
+}
+
+// Bounds returns the inclusive range of nanosecond timestamps covered by the hold.
+func (h *LegalHold) Bounds() (min, max int64) {
+	min, max = math.MinInt64, math.MaxInt64
+	if h.Start != nil {
+		min = h.Start.UnixNano()
+	}
+	if h.Stop != nil {
+		max = h.Stop.UnixNano()
+	}
+	return min, max
+}
+
+// Overlaps reports whether the hold covers any timestamp in [min, max].
+func (h *LegalHold) Overlaps(min, max int64) bool {
+	hmin, hmax := h.Bounds()
+	return hmin <= max && min <= hmax
+}
+
+// Holds reports whether the hold covers the measurement, which it does when it
+// is not narrowed to measurements.
+func (h *LegalHold) Holds(measurement string) bool {
+	if len(h.Measurements) == 0 {
+		return true
+	}
+	for _, m := range h.Measurements {
+		if m == measurement {
+			return true
+		}
+	}
+	return false
+}
+
+// LegalHoldFilter represents a set of filters that restrict the returned holds.
+type LegalHoldFilter struct {
+	OrgID    *ID
+	BucketID *ID
+	Status   *LegalHoldStatus
+}
+
+// LegalHoldEvent is an entry in the audit trail of a legal hold.
+type LegalHoldEvent struct {
+	ID     ID              `json:"id"`
+	HoldID ID              `json:"holdID"`
+	Action LegalHoldAction `json:"action"`
+	UserID ID              `json:"userID,omitempty"`
+	Reason string          `json:"reason,omitempty"`
+	Time   time.Time       `json:"time"`
+}
+
+// LegalHoldService manages legal holds. Holds cannot be updated or deleted;
+// they are only released, so that their audit trail is preserved.
+type LegalHoldService interface {
+	// CreateLegalHold places a new active hold.
+	CreateLegalHold(ctx context.Context, h *LegalHold) error
+
+	// FindLegalHoldByID returns a single hold by ID.
+	FindLegalHoldByID(ctx context.Context, id ID) (*LegalHold, error)
+
+	// FindLegalHolds returns a list of holds that match filter and the total count of matching holds.
+	FindLegalHolds(ctx context.Context, filter LegalHoldFilter, opt ...FindOptions) ([]*LegalHold, int, error)
+
+	// ReleaseLegalHold releases an active hold, recording the reason in its audit trail.
+	ReleaseLegalHold(ctx context.Context, id ID, reason string) (*LegalHold, error)
+
+	// FindLegalHoldEvents returns the audit trail of a hold, oldest first.
+	FindLegalHoldEvents(ctx context.Context, id ID) ([]*LegalHoldEvent, error)
+}
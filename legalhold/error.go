@@ -0,0 +1,63 @@
+package legalhold
+
+import (
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrHoldNotFound is used when the specified hold cannot be found.
+	ErrHoldNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "legal hold not found",
+	}
+
+	// ErrInvalidHoldID is used when the ID of the hold cannot be encoded.
+	ErrInvalidHoldID = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "legal hold ID is invalid",
+	}
+
+	// ErrHoldReleased is used when releasing a hold that is no longer active.
+	ErrHoldReleased = &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  "legal hold is already released",
+	}
+
+	// ErrReleaseReasonRequired is used when a hold is released without a reason.
+	ErrReleaseReasonRequired = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "a reason is required to release a legal hold",
+	}
+
+	// ErrHoldBucket is used when the bucket of a hold is not found in its
+	// organization.
+	ErrHoldBucket = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "bucket of the legal hold not found in its organization",
+	}
+)
+
+// ErrInvalidHold is used when a service was provided an invalid hold.
+func ErrInvalidHold(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "legal hold provided is invalid",
+		Err:  err,
+	}
+}
+
+// ErrDataUnderHold is used when a delete request overlaps an active hold.
+func ErrDataUnderHold(h *influxdb.LegalHold) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EForbidden,
+		Msg:  "data is under legal hold " + h.ID.String() + " (" + h.Name + ") and cannot be deleted",
+	}
+}
+
+// ErrInternalService is used when the error comes from an internal system.
+func ErrInternalService(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
@@ -0,0 +1,135 @@
+package legalhold
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.DeleteService = (*DeleteService)(nil)
+
+// DeleteService refuses delete requests that overlap an active legal hold.
+// A delete predicate cannot be inspected for the measurements it matches, so
+// a hold narrowed to measurements blocks every overlapping delete on the
+// bucket.
+type DeleteService struct {
+	next  influxdb.DeleteService
+	holds *Service
+}
+
+// NewDeleteService wraps next so that held data cannot be deleted.
+func NewDeleteService(next influxdb.DeleteService, holds *Service) *DeleteService {
+	return &DeleteService{
+		next:  next,
+		holds: holds,
+	}
+}
+
+// DeleteBucketRangePredicate deletes data in [min, max] matching pred unless
+// the range overlaps an active hold on the bucket.
+func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
+	h, err := s.holds.findOverlapping(ctx, bucketID, "", min, max)
+	if err != nil {
+		return err
+	}
+	if h != nil {
+		reason := fmt.Sprintf("delete of range [%s, %s] refused",
+			time.Unix(0, min).UTC().Format(time.RFC3339Nano),
+			time.Unix(0, max).UTC().Format(time.RFC3339Nano))
+		if err := s.holds.RecordBlockedDelete(ctx, h, reason); err != nil {
+			return err
+		}
+		return ErrDataUnderHold(h)
+	}
+	return s.next.DeleteBucketRangePredicate(ctx, orgID, bucketID, min, max, pred)
+}
+
+var _ influxdb.MeasurementDeleteService = (*MeasurementDeleteService)(nil)
+
+// MeasurementDeleteService refuses measurement delete requests that overlap an
+// active legal hold covering the measurement.
+type MeasurementDeleteService struct {
+	next  influxdb.MeasurementDeleteService
+	holds *Service
+}
+
+// NewMeasurementDeleteService wraps next so that held measurements cannot be deleted.
+func NewMeasurementDeleteService(next influxdb.MeasurementDeleteService, holds *Service) *MeasurementDeleteService {
+	return &MeasurementDeleteService{
+		next:  next,
+		holds: holds,
+	}
+}
+
+// DeleteMeasurementRange deletes the measurement data in [min, max] unless the
+// range overlaps an active hold on the measurement.
+func (s *MeasurementDeleteService) DeleteMeasurementRange(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, min, max int64) error {
+	h, err := s.holds.findOverlapping(ctx, bucketID, measurement, min, max)
+	if err != nil {
+		return err
+	}
+	if h != nil {
+		reason := fmt.Sprintf("delete of measurement %q in range [%s, %s] refused", measurement,
+			time.Unix(0, min).UTC().Format(time.RFC3339Nano),
+			time.Unix(0, max).UTC().Format(time.RFC3339Nano))
+		if err := s.holds.RecordBlockedDelete(ctx, h, reason); err != nil {
+			return err
+		}
+		return ErrDataUnderHold(h)
+	}
+	return s.next.DeleteMeasurementRange(ctx, orgID, bucketID, measurement, min, max)
+}
+
+var _ influxdb.BucketService = (*BucketService)(nil)
+
+// BucketService refuses to delete buckets that have an active legal hold.
+type BucketService struct {
+	influxdb.BucketService
+	holds *Service
+}
+
+// NewBucketService wraps s so that buckets under a hold cannot be deleted.
+func NewBucketService(s influxdb.BucketService, holds *Service) *BucketService {
+	return &BucketService{
+		BucketService: s,
+		holds:         holds,
+	}
+}
+
+// DeleteBucket removes a bucket by ID unless it has an active hold.
+func (s *BucketService) DeleteBucket(ctx context.Context, id influxdb.ID) error {
+	h, err := s.holds.findOverlapping(ctx, id, "", math.MinInt64, math.MaxInt64)
+	if err != nil {
+		return err
+	}
+	if h != nil {
+		if err := s.holds.RecordBlockedDelete(ctx, h, "bucket deletion refused"); err != nil {
+			return err
+		}
+		return ErrDataUnderHold(h)
+	}
+	return s.BucketService.DeleteBucket(ctx, id)
+}
+
+// findOverlapping returns the first active hold on the bucket covering any
+// timestamp in [min, max] of the measurement, or of any measurement if it is
+// empty, or nil if there is none.
+func (s *Service) findOverlapping(ctx context.Context, bucketID influxdb.ID, measurement string, min, max int64) (*influxdb.LegalHold, error) {
+	active := influxdb.LegalHoldActive
+	holds, _, err := s.FindLegalHolds(ctx, influxdb.LegalHoldFilter{
+		BucketID: &bucketID,
+		Status:   &active,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range holds {
+		if h.Overlaps(min, max) && (measurement == "" || h.Holds(measurement)) {
+			return h, nil
+		}
+	}
+	return nil, nil
+}
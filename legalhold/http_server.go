@@ -0,0 +1,239 @@
+package legalhold
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixLegalHolds is the prefix of the legal hold API.
+	PrefixLegalHolds = "/api/v2/legalholds"
+)
+
+// Handler is the HTTP API handler for legal holds.
+type Handler struct {
+	chi.Router
+	api     *kithttp.API
+	log     *zap.Logger
+	holdSvc influxdb.LegalHoldService
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, holdSvc influxdb.LegalHoldService) *Handler {
+	h := &Handler{
+		api:     kithttp.NewAPI(kithttp.WithLog(log)),
+		log:     log,
+		holdSvc: holdSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Post("/", h.handlePostHold)
+		r.Get("/", h.handleGetHolds)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetHold)
+			r.Post("/release", h.handlePostRelease)
+			r.Get("/events", h.handleGetEvents)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixLegalHolds
+}
+
+type holdResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.LegalHold
+}
+
+func newHoldResponse(hold *influxdb.LegalHold) *holdResponse {
+	self := fmt.Sprintf("%s/%s", PrefixLegalHolds, hold.ID)
+	return &holdResponse{
+		Links: map[string]string{
+			"self":   self,
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", hold.BucketID),
+			"events": self + "/events",
+		},
+		LegalHold: hold,
+	}
+}
+
+type holdsResponse struct {
+	Links map[string]string `json:"links"`
+	Holds []*holdResponse   `json:"legalHolds"`
+}
+
+type postHoldRequest struct {
+	OrgID        influxdb.ID `json:"orgID"`
+	BucketID     influxdb.ID `json:"bucketID"`
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	Measurements []string    `json:"measurements"`
+	Start        *time.Time  `json:"start"`
+	Stop         *time.Time  `json:"stop"`
+}
+
+func (h *Handler) handlePostHold(w http.ResponseWriter, r *http.Request) {
+	var req postHoldRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	hold := &influxdb.LegalHold{
+		OrgID:        req.OrgID,
+		BucketID:     req.BucketID,
+		Name:         req.Name,
+		Description:  req.Description,
+		Measurements: req.Measurements,
+		Start:        req.Start,
+		Stop:         req.Stop,
+	}
+	if err := h.holdSvc.CreateLegalHold(r.Context(), hold); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Legal hold created", zap.String("hold", fmt.Sprint(hold)))
+
+	h.api.Respond(w, r, http.StatusCreated, newHoldResponse(hold))
+}
+
+func (h *Handler) handleGetHolds(w http.ResponseWriter, r *http.Request) {
+	filter, err := decodeHoldFilter(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	holds, _, err := h.holdSvc.FindLegalHolds(r.Context(), filter)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	resp := holdsResponse{
+		Links: map[string]string{"self": PrefixLegalHolds},
+		Holds: make([]*holdResponse, 0, len(holds)),
+	}
+	for _, hold := range holds {
+		resp.Holds = append(resp.Holds, newHoldResponse(hold))
+	}
+	h.api.Respond(w, r, http.StatusOK, resp)
+}
+
+func decodeHoldFilter(r *http.Request) (influxdb.LegalHoldFilter, error) {
+	var filter influxdb.LegalHoldFilter
+	q := r.URL.Query()
+
+	if v := q.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return filter, influxdb.ErrCorruptID(err)
+		}
+		filter.OrgID = id
+	}
+	if v := q.Get("bucketID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return filter, influxdb.ErrCorruptID(err)
+		}
+		filter.BucketID = id
+	}
+	if v := q.Get("status"); v != "" {
+		status := influxdb.LegalHoldStatus(v)
+		if status != influxdb.LegalHoldActive && status != influxdb.LegalHoldReleased {
+			return filter, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("invalid status %q, must be active or released", v),
+			}
+		}
+		filter.Status = &status
+	}
+	return filter, nil
+}
+
+func (h *Handler) handleGetHold(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	hold, err := h.holdSvc.FindLegalHoldByID(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newHoldResponse(hold))
+}
+
+type postReleaseRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *Handler) handlePostRelease(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	var req postReleaseRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	hold, err := h.holdSvc.ReleaseLegalHold(r.Context(), *id, req.Reason)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Legal hold released", zap.String("hold", fmt.Sprint(hold)))
+
+	h.api.Respond(w, r, http.StatusOK, newHoldResponse(hold))
+}
+
+func (h *Handler) handleGetEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	events, err := h.holdSvc.FindLegalHoldEvents(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, struct {
+		Links  map[string]string          `json:"links"`
+		Events []*influxdb.LegalHoldEvent `json:"events"`
+	}{
+		Links: map[string]string{
+			"self": fmt.Sprintf("%s/%s/events", PrefixLegalHolds, id),
+			"hold": fmt.Sprintf("%s/%s", PrefixLegalHolds, id),
+		},
+		Events: events,
+	})
+}
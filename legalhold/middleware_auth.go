@@ -0,0 +1,72 @@
+package legalhold
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+var _ influxdb.LegalHoldService = (*AuthedService)(nil)
+
+// AuthedService requires read access to a bucket to see its holds and write
+// access to place or release them.
+type AuthedService struct {
+	s         influxdb.LegalHoldService
+	bucketSvc influxdb.BucketService
+}
+
+// NewAuthedService wraps s with bucket authorization. New holds are authorized
+// against the organization of their bucket in bucketSvc, which must not
+// perform authorization itself.
+func NewAuthedService(s influxdb.LegalHoldService, bucketSvc influxdb.BucketService) *AuthedService {
+	return &AuthedService{s: s, bucketSvc: bucketSvc}
+}
+
+func (s *AuthedService) CreateLegalHold(ctx context.Context, h *influxdb.LegalHold) error {
+	b, err := s.bucketSvc.FindBucketByID(ctx, h.BucketID)
+	if err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.BucketsResourceType, b.ID, b.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateLegalHold(ctx, h)
+}
+
+func (s *AuthedService) FindLegalHoldByID(ctx context.Context, id influxdb.ID) (*influxdb.LegalHold, error) {
+	h, err := s.s.FindLegalHoldByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, h.BucketID, h.OrgID); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (s *AuthedService) FindLegalHolds(ctx context.Context, filter influxdb.LegalHoldFilter, opt ...influxdb.FindOptions) ([]*influxdb.LegalHold, int, error) {
+	holds, _, err := s.s.FindLegalHolds(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return authorizer.AuthorizeFindLegalHolds(ctx, holds)
+}
+
+func (s *AuthedService) ReleaseLegalHold(ctx context.Context, id influxdb.ID, reason string) (*influxdb.LegalHold, error) {
+	h, err := s.s.FindLegalHoldByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.BucketsResourceType, h.BucketID, h.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.ReleaseLegalHold(ctx, id, reason)
+}
+
+func (s *AuthedService) FindLegalHoldEvents(ctx context.Context, id influxdb.ID) ([]*influxdb.LegalHoldEvent, error) {
+	if _, err := s.FindLegalHoldByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.s.FindLegalHoldEvents(ctx, id)
+}
@@ -0,0 +1,298 @@
+// Package legalhold implements legal holds, which exempt data in a bucket from
+// retention enforcement and the delete API until they are released.
+//
+// Holds are never updated or deleted. Creating and releasing a hold, as well
+// as every delete request refused because of one, is recorded in the hold's
+// audit trail.
+package legalhold
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/snowflake"
+)
+
+var (
+	holdBucket  = []byte("legalholdsv1")
+	eventBucket = []byte("legalholdeventsv1")
+)
+
+var _ influxdb.LegalHoldService = (*Service)(nil)
+
+// Service stores legal holds and their audit trail in a kv store.
+type Service struct {
+	store         kv.Store
+	bucketSvc     influxdb.BucketService
+	IDGen         influxdb.IDGenerator
+	TimeGenerator influxdb.TimeGenerator
+}
+
+// NewService constructs a legal hold service backed by st. Holds can only be
+// placed on buckets found in bucketSvc.
+func NewService(st kv.Store, bucketSvc influxdb.BucketService) *Service {
+	return &Service{
+		store:         st,
+		bucketSvc:     bucketSvc,
+		IDGen:         snowflake.NewDefaultIDGenerator(),
+		TimeGenerator: influxdb.RealTimeGenerator{},
+	}
+}
+
+// CreateLegalHold places a new active hold.
+func (s *Service) CreateLegalHold(ctx context.Context, h *influxdb.LegalHold) error {
+	if err := h.Valid(); err != nil {
+		return ErrInvalidHold(err)
+	}
+	b, err := s.bucketSvc.FindBucketByID(ctx, h.BucketID)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return ErrHoldBucket
+	}
+	if err != nil {
+		return err
+	}
+	if b.OrgID != h.OrgID {
+		return ErrHoldBucket
+	}
+
+	now := s.TimeGenerator.Now()
+	h.ID = s.IDGen.ID()
+	h.Status = influxdb.LegalHoldActive
+	h.CreatedAt = now
+	h.UpdatedAt = now
+
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		if err := s.putHold(tx, h); err != nil {
+			return err
+		}
+		return s.putEvent(ctx, tx, h.ID, influxdb.LegalHoldCreated, h.Description)
+	})
+}
+
+// FindLegalHoldByID returns a single hold by ID.
+func (s *Service) FindLegalHoldByID(ctx context.Context, id influxdb.ID) (*influxdb.LegalHold, error) {
+	var h *influxdb.LegalHold
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		h, err = s.getHold(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// FindLegalHolds returns a list of holds that match filter and the total count of matching holds.
+func (s *Service) FindLegalHolds(ctx context.Context, filter influxdb.LegalHoldFilter, opt ...influxdb.FindOptions) ([]*influxdb.LegalHold, int, error) {
+	holds := []*influxdb.LegalHold{}
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket(holdBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		return kv.WalkCursor(ctx, cur, func(k, v []byte) error {
+			h := &influxdb.LegalHold{}
+			if err := json.Unmarshal(v, h); err != nil {
+				return ErrInternalService(err)
+			}
+			if filterFunc(h, filter) {
+				holds = append(holds, h)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(holds)
+	if len(opt) > 0 {
+		holds = paginate(holds, opt[0])
+	}
+	return holds, total, nil
+}
+
+// ReleaseLegalHold releases an active hold, recording the reason in its audit trail.
+func (s *Service) ReleaseLegalHold(ctx context.Context, id influxdb.ID, reason string) (*influxdb.LegalHold, error) {
+	if reason == "" {
+		return nil, ErrReleaseReasonRequired
+	}
+
+	var h *influxdb.LegalHold
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		var err error
+		if h, err = s.getHold(tx, id); err != nil {
+			return err
+		}
+		if !h.Active() {
+			return ErrHoldReleased
+		}
+
+		h.Status = influxdb.LegalHoldReleased
+		h.UpdatedAt = s.TimeGenerator.Now()
+		if err := s.putHold(tx, h); err != nil {
+			return err
+		}
+		return s.putEvent(ctx, tx, h.ID, influxdb.LegalHoldReleasedAction, reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// FindLegalHoldEvents returns the audit trail of a hold, oldest first.
+func (s *Service) FindLegalHoldEvents(ctx context.Context, id influxdb.ID) ([]*influxdb.LegalHoldEvent, error) {
+	prefix, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidHoldID
+	}
+
+	events := []*influxdb.LegalHoldEvent{}
+	err = s.store.View(ctx, func(tx kv.Tx) error {
+		if _, err := s.getHold(tx, id); err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(eventBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		cur, err := b.ForwardCursor(prefix, kv.WithCursorPrefix(prefix))
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		return kv.WalkCursor(ctx, cur, func(k, v []byte) error {
+			e := &influxdb.LegalHoldEvent{}
+			if err := json.Unmarshal(v, e); err != nil {
+				return ErrInternalService(err)
+			}
+			events = append(events, e)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Event IDs are not guaranteed to be ordered, the timestamps are.
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events, nil
+}
+
+// RecordBlockedDelete adds a delete_blocked event to the audit trail of h.
+func (s *Service) RecordBlockedDelete(ctx context.Context, h *influxdb.LegalHold, reason string) error {
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		return s.putEvent(ctx, tx, h.ID, influxdb.LegalHoldDeleteBlocked, reason)
+	})
+}
+
+func (s *Service) getHold(tx kv.Tx, id influxdb.ID) (*influxdb.LegalHold, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidHoldID
+	}
+
+	b, err := tx.Bucket(holdBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(encodedID)
+	if kv.IsNotFound(err) {
+		return nil, ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+
+	h := &influxdb.LegalHold{}
+	if err := json.Unmarshal(v, h); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return h, nil
+}
+
+func (s *Service) putHold(tx kv.Tx, h *influxdb.LegalHold) error {
+	encodedID, err := h.ID.Encode()
+	if err != nil {
+		return ErrInvalidHoldID
+	}
+	v, err := json.Marshal(h)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+
+	b, err := tx.Bucket(holdBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(encodedID, v); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+// putEvent appends an event to the audit trail of the hold. Events are keyed
+// by the hold ID followed by the event ID so a hold's trail is a prefix scan.
+func (s *Service) putEvent(ctx context.Context, tx kv.Tx, holdID influxdb.ID, action influxdb.LegalHoldAction, reason string) error {
+	e := &influxdb.LegalHoldEvent{
+		ID:     s.IDGen.ID(),
+		HoldID: holdID,
+		Action: action,
+		Reason: reason,
+		Time:   s.TimeGenerator.Now(),
+	}
+	if userID, err := icontext.GetUserID(ctx); err == nil {
+		e.UserID = userID
+	}
+
+	holdKey, err := holdID.Encode()
+	if err != nil {
+		return ErrInvalidHoldID
+	}
+	eventKey, err := e.ID.Encode()
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	v, err := json.Marshal(e)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+
+	b, err := tx.Bucket(eventBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(append(holdKey, eventKey...), v); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+func filterFunc(h *influxdb.LegalHold, filter influxdb.LegalHoldFilter) bool {
+	return (filter.OrgID == nil || h.OrgID == *filter.OrgID) &&
+		(filter.BucketID == nil || h.BucketID == *filter.BucketID) &&
+		(filter.Status == nil || h.Status == *filter.Status)
+}
+
+func paginate(holds []*influxdb.LegalHold, opt influxdb.FindOptions) []*influxdb.LegalHold {
+	if opt.Offset > 0 {
+		if opt.Offset >= len(holds) {
+			return []*influxdb.LegalHold{}
+		}
+		holds = holds[opt.Offset:]
+	}
+	if opt.Limit > 0 && opt.Limit < len(holds) {
+		holds = holds[:opt.Limit]
+	}
+	return holds
+}
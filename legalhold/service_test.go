@@ -0,0 +1,201 @@
+package legalhold_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/legalhold"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+func newTestService(t *testing.T) (*legalhold.Service, func()) {
+	t.Helper()
+
+	s, done := influxdbtesting.NewTestBoltStore(t)
+	buckets := influxdbtesting.NewBucketFinder(
+		&influxdb.Bucket{ID: 2, OrgID: 1},
+		&influxdb.Bucket{ID: 3, OrgID: 4},
+	)
+
+	svc := legalhold.NewService(s, buckets)
+	svc.IDGen = mock.NewIncrementingIDGenerator(1)
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)}
+	return svc, done
+}
+
+func TestService_CreateAndRelease(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{UserID: 42})
+
+	h := &influxdb.LegalHold{OrgID: 1, BucketID: 2, Name: "investigation", Description: "case 1"}
+	if err := svc.CreateLegalHold(ctx, h); err != nil {
+		t.Fatal(err)
+	}
+	if !h.ID.Valid() || !h.Active() {
+		t.Fatalf("expected active hold with an ID, got %+v", h)
+	}
+
+	if _, err := svc.ReleaseLegalHold(ctx, h.ID, ""); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid error releasing without a reason, got %v", err)
+	}
+
+	released, err := svc.ReleaseLegalHold(ctx, h.ID, "case closed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released.Status != influxdb.LegalHoldReleased {
+		t.Fatalf("expected released hold, got %q", released.Status)
+	}
+
+	if _, err := svc.ReleaseLegalHold(ctx, h.ID, "again"); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected conflict releasing twice, got %v", err)
+	}
+
+	events, err := svc.FindLegalHoldEvents(ctx, h.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Action != influxdb.LegalHoldCreated || events[1].Action != influxdb.LegalHoldReleasedAction {
+		t.Fatalf("unexpected audit trail %+v %+v", events[0], events[1])
+	}
+	if events[1].UserID != 42 || events[1].Reason != "case closed" {
+		t.Fatalf("unexpected release event %+v", events[1])
+	}
+
+	active := influxdb.LegalHoldActive
+	holds, n, err := svc.FindLegalHolds(ctx, influxdb.LegalHoldFilter{Status: &active})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 || len(holds) != 0 {
+		t.Fatalf("expected no active holds, got %d", n)
+	}
+}
+
+func TestService_CreateInvalid(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+
+	start := time.Unix(100, 0)
+	stop := time.Unix(10, 0)
+	err := svc.CreateLegalHold(context.Background(), &influxdb.LegalHold{
+		OrgID:    1,
+		BucketID: 2,
+		Name:     "backwards",
+		Start:    &start,
+		Stop:     &stop,
+	})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid error, got %v", err)
+	}
+}
+
+func TestService_CreateOtherOrgBucket(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+
+	for _, bucketID := range []influxdb.ID{3, 5} {
+		err := svc.CreateLegalHold(context.Background(), &influxdb.LegalHold{OrgID: 1, BucketID: bucketID, Name: "hold"})
+		if err != legalhold.ErrHoldBucket {
+			t.Fatalf("bucket %s: expected %v, got %v", bucketID, legalhold.ErrHoldBucket, err)
+		}
+	}
+}
+
+func TestService_NotFound(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	if _, err := svc.FindLegalHoldByID(ctx, 99); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected not found error finding a missing hold, got %v", err)
+	}
+	if _, err := svc.ReleaseLegalHold(ctx, 99, "reason"); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected not found error releasing a missing hold, got %v", err)
+	}
+}
+
+func TestDeleteService(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	stop := time.Unix(0, 1000)
+	h := &influxdb.LegalHold{OrgID: 1, BucketID: 2, Name: "hold", Stop: &stop}
+	if err := svc.CreateLegalHold(ctx, h); err != nil {
+		t.Fatal(err)
+	}
+
+	var deleted bool
+	next := mock.NewDeleteService()
+	next.DeleteBucketRangePredicateF = func(context.Context, influxdb.ID, influxdb.ID, int64, int64, influxdb.Predicate) error {
+		deleted = true
+		return nil
+	}
+	ds := legalhold.NewDeleteService(next, svc)
+
+	if err := ds.DeleteBucketRangePredicate(ctx, 1, 2, 500, 2000, nil); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("expected forbidden error, got %v", err)
+	}
+	if deleted {
+		t.Fatal("expected held data not to be deleted")
+	}
+
+	events, err := svc.FindLegalHoldEvents(ctx, h.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := events[len(events)-1].Action; got != influxdb.LegalHoldDeleteBlocked {
+		t.Fatalf("expected blocked delete to be audited, got %q", got)
+	}
+
+	if err := ds.DeleteBucketRangePredicate(ctx, 1, 2, 1001, math.MaxInt64, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !deleted {
+		t.Fatal("expected data outside the hold to be deleted")
+	}
+}
+
+type measurementDeleteFunc func(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, min, max int64) error
+
+func (f measurementDeleteFunc) DeleteMeasurementRange(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, min, max int64) error {
+	return f(ctx, orgID, bucketID, measurement, min, max)
+}
+
+func TestMeasurementDeleteService(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	h := &influxdb.LegalHold{OrgID: 1, BucketID: 2, Name: "hold", Measurements: []string{"cpu"}}
+	if err := svc.CreateLegalHold(ctx, h); err != nil {
+		t.Fatal(err)
+	}
+
+	var deleted []string
+	next := measurementDeleteFunc(func(_ context.Context, _, _ influxdb.ID, measurement string, _, _ int64) error {
+		deleted = append(deleted, measurement)
+		return nil
+	})
+	ds := legalhold.NewMeasurementDeleteService(next, svc)
+
+	if err := ds.DeleteMeasurementRange(ctx, 1, 2, "cpu", math.MinInt64, math.MaxInt64); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("expected forbidden error, got %v", err)
+	}
+	if err := ds.DeleteMeasurementRange(ctx, 1, 2, "mem", math.MinInt64, math.MaxInt64); err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || deleted[0] != "mem" {
+		t.Fatalf("expected only the measurement outside the hold to be deleted, got %v", deleted)
+	}
+}
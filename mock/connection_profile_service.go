@@ -0,0 +1,63 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.ConnectionProfileService = (*ConnectionProfileService)(nil)
+
+// ConnectionProfileService is a mock implementation of
+// influxdb.ConnectionProfileService.
+type ConnectionProfileService struct {
+	CreateConnectionProfileFn   func(ctx context.Context, p *influxdb.ConnectionProfile, secret string) error
+	FindConnectionProfileByIDFn func(ctx context.Context, id influxdb.ID) (*influxdb.ConnectionProfile, error)
+	FindConnectionProfilesFn    func(ctx context.Context, filter influxdb.ConnectionProfileFilter, opt ...influxdb.FindOptions) ([]*influxdb.ConnectionProfile, int, error)
+	UpdateConnectionProfileFn   func(ctx context.Context, id influxdb.ID, upd influxdb.ConnectionProfileUpdate) (*influxdb.ConnectionProfile, error)
+	DeleteConnectionProfileFn   func(ctx context.Context, id influxdb.ID) error
+}
+
+// NewConnectionProfileService returns a mock of ConnectionProfileService
+// where its methods find no profiles.
+func NewConnectionProfileService() *ConnectionProfileService {
+	return &ConnectionProfileService{
+		CreateConnectionProfileFn: func(context.Context, *influxdb.ConnectionProfile, string) error { return nil },
+		FindConnectionProfileByIDFn: func(context.Context, influxdb.ID) (*influxdb.ConnectionProfile, error) {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "connection profile not found"}
+		},
+		FindConnectionProfilesFn: func(context.Context, influxdb.ConnectionProfileFilter, ...influxdb.FindOptions) ([]*influxdb.ConnectionProfile, int, error) {
+			return nil, 0, nil
+		},
+		UpdateConnectionProfileFn: func(context.Context, influxdb.ID, influxdb.ConnectionProfileUpdate) (*influxdb.ConnectionProfile, error) {
+			return nil, nil
+		},
+		DeleteConnectionProfileFn: func(context.Context, influxdb.ID) error { return nil },
+	}
+}
+
+// CreateConnectionProfile creates a connection profile.
+func (s *ConnectionProfileService) CreateConnectionProfile(ctx context.Context, p *influxdb.ConnectionProfile, secret string) error {
+	return s.CreateConnectionProfileFn(ctx, p, secret)
+}
+
+// FindConnectionProfileByID returns a single connection profile by ID.
+func (s *ConnectionProfileService) FindConnectionProfileByID(ctx context.Context, id influxdb.ID) (*influxdb.ConnectionProfile, error) {
+	return s.FindConnectionProfileByIDFn(ctx, id)
+}
+
+// FindConnectionProfiles returns a list of connection profiles that match
+// filter.
+func (s *ConnectionProfileService) FindConnectionProfiles(ctx context.Context, filter influxdb.ConnectionProfileFilter, opt ...influxdb.FindOptions) ([]*influxdb.ConnectionProfile, int, error) {
+	return s.FindConnectionProfilesFn(ctx, filter, opt...)
+}
+
+// UpdateConnectionProfile updates a single connection profile.
+func (s *ConnectionProfileService) UpdateConnectionProfile(ctx context.Context, id influxdb.ID, upd influxdb.ConnectionProfileUpdate) (*influxdb.ConnectionProfile, error) {
+	return s.UpdateConnectionProfileFn(ctx, id, upd)
+}
+
+// DeleteConnectionProfile removes a single connection profile.
+func (s *ConnectionProfileService) DeleteConnectionProfile(ctx context.Context, id influxdb.ID) error {
+	return s.DeleteConnectionProfileFn(ctx, id)
+}
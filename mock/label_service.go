@@ -26,6 +26,8 @@ type LabelService struct {
 	CreateLabelMappingCalls SafeCount
 	DeleteLabelMappingFn    func(context.Context, *platform.LabelMapping) error
 	DeleteLabelMappingCalls SafeCount
+	ApplyLabelMappingsFn    func(context.Context, *platform.LabelMappingBatch) error
+	ApplyLabelMappingsCalls SafeCount
 }
 
 // NewLabelService returns a mock of LabelService
@@ -46,6 +48,7 @@ func NewLabelService() *LabelService {
 		UpdateLabelFn:        func(context.Context, platform.ID, platform.LabelUpdate) (*platform.Label, error) { return nil, nil },
 		DeleteLabelFn:        func(context.Context, platform.ID) error { return nil },
 		DeleteLabelMappingFn: func(context.Context, *platform.LabelMapping) error { return nil },
+		ApplyLabelMappingsFn: func(context.Context, *platform.LabelMappingBatch) error { return nil },
 	}
 }
 
@@ -96,3 +99,9 @@ func (s *LabelService) DeleteLabelMapping(ctx context.Context, m *platform.Label
 	defer s.DeleteLabelMappingCalls.IncrFn()()
 	return s.DeleteLabelMappingFn(ctx, m)
 }
+
+// ApplyLabelMappings adds and removes many Label mappings.
+func (s *LabelService) ApplyLabelMappings(ctx context.Context, b *platform.LabelMappingBatch) error {
+	defer s.ApplyLabelMappingsCalls.IncrFn()()
+	return s.ApplyLabelMappingsFn(ctx, b)
+}
@@ -0,0 +1,43 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.WriteRoutingService = (*WriteRoutingService)(nil)
+
+// WriteRoutingService is a mock implementation of influxdb.WriteRoutingService.
+type WriteRoutingService struct {
+	FindWriteRoutingFn   func(ctx context.Context, orgID influxdb.ID) (*influxdb.WriteRouting, error)
+	PutWriteRoutingFn    func(ctx context.Context, r *influxdb.WriteRouting) error
+	DeleteWriteRoutingFn func(ctx context.Context, orgID influxdb.ID) error
+}
+
+// NewWriteRoutingService returns a mock of WriteRoutingService where its
+// methods return organizations without routing.
+func NewWriteRoutingService() *WriteRoutingService {
+	return &WriteRoutingService{
+		FindWriteRoutingFn: func(ctx context.Context, orgID influxdb.ID) (*influxdb.WriteRouting, error) {
+			return &influxdb.WriteRouting{OrgID: orgID, Routes: map[string]influxdb.ID{}}, nil
+		},
+		PutWriteRoutingFn:    func(context.Context, *influxdb.WriteRouting) error { return nil },
+		DeleteWriteRoutingFn: func(context.Context, influxdb.ID) error { return nil },
+	}
+}
+
+// FindWriteRouting returns the routing of the organization.
+func (s *WriteRoutingService) FindWriteRouting(ctx context.Context, orgID influxdb.ID) (*influxdb.WriteRouting, error) {
+	return s.FindWriteRoutingFn(ctx, orgID)
+}
+
+// PutWriteRouting replaces the routing of the organization.
+func (s *WriteRoutingService) PutWriteRouting(ctx context.Context, r *influxdb.WriteRouting) error {
+	return s.PutWriteRoutingFn(ctx, r)
+}
+
+// DeleteWriteRouting removes the routing of the organization.
+func (s *WriteRoutingService) DeleteWriteRouting(ctx context.Context, orgID influxdb.ID) error {
+	return s.DeleteWriteRoutingFn(ctx, orgID)
+}
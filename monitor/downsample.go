@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// downsampleFlux counts the statuses and notifications of the hour that
+// became older than the downsample age. The task runs hourly, so every hour
+// is counted once.
+const downsampleFlux = `option task = {name: "Downsample monitoring data", every: 1h}
+
+downsample = (measurement, field, columns) => from(bucket: %[1]q)
+	|> range(start: -%[3]s, stop: -%[2]s)
+	|> filter(fn: (r) => r._measurement == measurement and r._field == field)
+	|> group(columns: columns)
+	|> aggregateWindow(every: 1h, fn: count, createEmpty: false)
+	|> map(fn: (r) => ({r with _measurement: measurement, _field: "count"}))
+	|> to(bucket: %[4]q, orgID: %[5]q)
+
+downsample(measurement: "statuses", field: "_message", columns: ["_check_id", "_check_name", "_level"])
+downsample(measurement: "notifications", field: "_status_timestamp", columns: ["_notification_rule_id", "_notification_rule_name", "_notification_endpoint_id", "_check_id", "_level", "_sent"])
+`
+
+func downsampleScript(monitoringBucket string, orgID influxdb.ID, after time.Duration) string {
+	return fmt.Sprintf(downsampleFlux,
+		monitoringBucket,
+		fluxDuration(after),
+		fluxDuration(after+time.Hour),
+		influxdb.MonitoringDownsampledBucketName,
+		orgID.String(),
+	)
+}
+
+// fluxDuration returns d as a flux duration literal in its largest whole unit.
+func fluxDuration(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+}
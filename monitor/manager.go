@@ -0,0 +1,219 @@
+// Package monitor manages the retention of the _monitoring system bucket of
+// every organization and the downsampling of the statuses and notifications
+// written to it.
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+// DownsampleTaskType is the type of the tasks downsampling monitoring data.
+const DownsampleTaskType = "monitoring-downsample"
+
+// DefaultReconcileInterval is how often the monitoring buckets and tasks of
+// organizations are brought in line with the configuration.
+const DefaultReconcileInterval = time.Hour
+
+// Config configures the monitoring data of organizations.
+type Config struct {
+	// Retention is the retention period of the _monitoring buckets. When
+	// nil, the retention periods set on the buckets are left as they are.
+	Retention *time.Duration
+	// DownsampleAfter is the age after which statuses and notifications are
+	// counted by hour into the _monitoring_downsampled bucket. Zero disables
+	// downsampling.
+	DownsampleAfter time.Duration
+	// DownsampledRetention is the retention period of the downsampled
+	// data. Zero keeps it forever.
+	DownsampledRetention time.Duration
+}
+
+// Valid returns an error if data would be removed by retention before it is
+// downsampled.
+func (c Config) Valid() error {
+	if (c.Retention != nil && *c.Retention < 0) || c.DownsampleAfter < 0 || c.DownsampledRetention < 0 {
+		return fmt.Errorf("monitoring retention and downsampling durations must not be negative")
+	}
+	if c.Retention == nil || c.DownsampleAfter == 0 || *c.Retention == influxdb.InfiniteRetention {
+		return nil
+	}
+	if c.DownsampleAfter+time.Hour > *c.Retention {
+		return fmt.Errorf("monitoring data must be downsampled at least an hour before the %s retention of %s", *c.Retention, influxdb.MonitoringSystemBucketName)
+	}
+	return nil
+}
+
+// Manager reconciles the monitoring buckets and downsampling tasks of
+// organizations with a Config.
+type Manager struct {
+	log      *zap.Logger
+	cfg      Config
+	sb       influxdb.SystemBuckets
+	orgs     influxdb.OrganizationService
+	buckets  influxdb.BucketService
+	tasks    influxdb.TaskService
+	urms     influxdb.UserResourceMappingService
+	interval time.Duration
+}
+
+// NewManager returns a manager of the monitoring data of organizations.
+func NewManager(log *zap.Logger, cfg Config, sb influxdb.SystemBuckets, orgs influxdb.OrganizationService, buckets influxdb.BucketService, tasks influxdb.TaskService, urms influxdb.UserResourceMappingService) *Manager {
+	return &Manager{
+		log:      log,
+		cfg:      cfg,
+		sb:       sb,
+		orgs:     orgs,
+		buckets:  buckets,
+		tasks:    tasks,
+		urms:     urms,
+		interval: DefaultReconcileInterval,
+	}
+}
+
+// Run reconciles organizations until ctx is canceled.
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.reconcile(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Manager) reconcile(ctx context.Context) {
+	orgs, _, err := m.orgs.FindOrganizations(ctx, influxdb.OrganizationFilter{})
+	if err != nil {
+		m.log.Error("Failed to find organizations", zap.Error(err))
+		return
+	}
+
+	for _, o := range orgs {
+		if err := m.reconcileOrg(ctx, o); err != nil {
+			m.log.Error("Failed to manage monitoring data", zap.Stringer("orgID", o.ID), zap.Error(err))
+		}
+	}
+}
+
+func (m *Manager) reconcileOrg(ctx context.Context, o *influxdb.Organization) error {
+	if err := m.reconcileRetention(ctx, o.ID); err != nil {
+		return err
+	}
+
+	tasks, _, err := m.tasks.FindTasks(ctx, influxdb.TaskFilter{
+		OrganizationID: &o.ID,
+		Type:           strPtr(DownsampleTaskType),
+	})
+	if err != nil {
+		return err
+	}
+
+	if m.cfg.DownsampleAfter == 0 {
+		for _, t := range tasks {
+			if err := m.tasks.DeleteTask(ctx, t.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := m.reconcileDownsampledBucket(ctx, o.ID); err != nil {
+		return err
+	}
+
+	script := downsampleScript(m.sb.MonitoringName, o.ID, m.cfg.DownsampleAfter)
+	if len(tasks) > 0 {
+		if tasks[0].Flux == script {
+			return nil
+		}
+		_, err := m.tasks.UpdateTask(ctx, tasks[0].ID, influxdb.TaskUpdate{Flux: &script})
+		return err
+	}
+
+	owner, err := m.findOrgOwner(ctx, o.ID)
+	if err != nil {
+		return err
+	}
+	_, err = m.tasks.CreateTask(ctx, influxdb.TaskCreate{
+		Type:           DownsampleTaskType,
+		Flux:           script,
+		Description:    "Counts statuses and notifications by hour once they are downsampled",
+		OrganizationID: o.ID,
+		OwnerID:        owner,
+	})
+	return err
+}
+
+// reconcileRetention sets the retention period of the _monitoring bucket of
+// the organization, if one is configured.
+func (m *Manager) reconcileRetention(ctx context.Context, orgID influxdb.ID) error {
+	if m.cfg.Retention == nil {
+		return nil
+	}
+	b, err := m.buckets.FindBucketByName(ctx, orgID, m.sb.MonitoringName)
+	if err != nil {
+		return err
+	}
+	// Organizations without a stored _monitoring bucket are served a
+	// default one that cannot be updated.
+	if b.ID == m.sb.MonitoringID || b.RetentionPeriod == *m.cfg.Retention {
+		return nil
+	}
+
+	_, err = m.buckets.UpdateBucket(ctx, b.ID, influxdb.BucketUpdate{RetentionPeriod: m.cfg.Retention})
+	return err
+}
+
+func (m *Manager) reconcileDownsampledBucket(ctx context.Context, orgID influxdb.ID) error {
+	b, err := m.buckets.FindBucketByName(ctx, orgID, influxdb.MonitoringDownsampledBucketName)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return m.buckets.CreateBucket(ctx, &influxdb.Bucket{
+			OrgID:           orgID,
+			Type:            influxdb.BucketTypeSystem,
+			Name:            influxdb.MonitoringDownsampledBucketName,
+			Description:     "System bucket for downsampled monitoring logs",
+			RetentionPeriod: m.cfg.DownsampledRetention,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if b.RetentionPeriod == m.cfg.DownsampledRetention {
+		return nil
+	}
+
+	_, err = m.buckets.UpdateBucket(ctx, b.ID, influxdb.BucketUpdate{RetentionPeriod: &m.cfg.DownsampledRetention})
+	return err
+}
+
+func (m *Manager) findOrgOwner(ctx context.Context, orgID influxdb.ID) (influxdb.ID, error) {
+	urms, _, err := m.urms.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   orgID,
+		UserType:     influxdb.Owner,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(urms) == 0 {
+		return 0, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "organization has no owner to run the monitoring downsample task",
+		}
+	}
+	return urms[0].UserID, nil
+}
+
+func strPtr(s string) *string {
+	return &s
+}
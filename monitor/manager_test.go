@@ -0,0 +1,106 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func newKVService(t *testing.T) *kv.Service {
+	t.Helper()
+
+	store := inmem.NewKVStore()
+	if err := all.Up(context.Background(), zaptest.NewLogger(t), store); err != nil {
+		t.Fatal(err)
+	}
+	return kv.NewService(zaptest.NewLogger(t), store, kv.ServiceConfig{
+		FluxLanguageService: fluxlang.DefaultService,
+	})
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	svc := newKVService(t)
+
+	user := &influxdb.User{Name: "alice"}
+	require.NoError(t, svc.CreateUser(ctx, user))
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+	require.NoError(t, svc.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+		UserID:       user.ID,
+		UserType:     influxdb.Owner,
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   org.ID,
+	}))
+
+	cfg := Config{
+		Retention:            durationPtr(3 * 24 * time.Hour),
+		DownsampleAfter:      2 * 24 * time.Hour,
+		DownsampledRetention: 90 * 24 * time.Hour,
+	}
+	require.NoError(t, cfg.Valid())
+	m := NewManager(zaptest.NewLogger(t), cfg, influxdb.DefaultSystemBuckets(), svc, svc, svc, svc)
+	m.reconcile(ctx)
+
+	b, err := svc.FindBucketByName(ctx, org.ID, influxdb.MonitoringSystemBucketName)
+	require.NoError(t, err)
+	assert.Equal(t, *cfg.Retention, b.RetentionPeriod)
+
+	b, err = svc.FindBucketByName(ctx, org.ID, influxdb.MonitoringDownsampledBucketName)
+	require.NoError(t, err)
+	assert.Equal(t, influxdb.BucketTypeSystem, b.Type)
+	assert.Equal(t, cfg.DownsampledRetention, b.RetentionPeriod)
+
+	findTasks := func() []*influxdb.Task {
+		tasks, _, err := svc.FindTasks(ctx, influxdb.TaskFilter{OrganizationID: &org.ID, Type: strPtr(DownsampleTaskType)})
+		require.NoError(t, err)
+		return tasks
+	}
+	tasks := findTasks()
+	require.Len(t, tasks, 1)
+	assert.Equal(t, user.ID, tasks[0].OwnerID)
+	assert.True(t, strings.Contains(tasks[0].Flux, "range(start: -49h, stop: -48h)"), tasks[0].Flux)
+
+	// Reconciling again does not add tasks.
+	m.reconcile(ctx)
+	require.Len(t, findTasks(), 1)
+
+	// Disabling downsampling removes the task.
+	m.cfg.DownsampleAfter = 0
+	m.reconcile(ctx)
+	assert.Empty(t, findTasks())
+
+	// Without a configured retention, the retention set on the bucket is kept.
+	retention := 5 * 24 * time.Hour
+	b, err = svc.FindBucketByName(ctx, org.ID, influxdb.MonitoringSystemBucketName)
+	require.NoError(t, err)
+	_, err = svc.UpdateBucket(ctx, b.ID, influxdb.BucketUpdate{RetentionPeriod: &retention})
+	require.NoError(t, err)
+	m.cfg.Retention = nil
+	m.reconcile(ctx)
+	b, err = svc.FindBucketByName(ctx, org.ID, influxdb.MonitoringSystemBucketName)
+	require.NoError(t, err)
+	assert.Equal(t, retention, b.RetentionPeriod)
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
+
+func TestConfig_Valid(t *testing.T) {
+	assert.NoError(t, Config{Retention: durationPtr(7 * 24 * time.Hour)}.Valid())
+	assert.NoError(t, Config{Retention: durationPtr(0), DownsampleAfter: 24 * time.Hour}.Valid(), "infinite retention")
+	assert.NoError(t, Config{DownsampleAfter: 24 * time.Hour}.Valid(), "retention not configured")
+	assert.Error(t, Config{Retention: durationPtr(24 * time.Hour), DownsampleAfter: 24 * time.Hour}.Valid())
+	assert.Error(t, Config{Retention: durationPtr(-time.Hour)}.Valid())
+}
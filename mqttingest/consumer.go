@@ -0,0 +1,271 @@
+// Package mqttingest writes the messages of MQTT topics to buckets, so that
+// constrained IoT devices publishing to an MQTT broker are ingested without
+// the overhead of HTTP.
+//
+// influxd connects to the broker as a client and subscribes to the topics of
+// its routes. The levels of the topics of a route are mapped to the
+// measurement and tags of the points of their messages, whose payloads are
+// line protocol or JSON objects of fields.
+package mqttingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/ingest"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultClientID is the default client ID of influxd.
+	DefaultClientID = "influxd"
+
+	// maxWriteAttempts is the number of times the points of a message are
+	// written before it is dropped, as the messages of the broker are not
+	// delivered while they are written.
+	maxWriteAttempts = 5
+
+	minRetryInterval = time.Second
+	maxRetryInterval = time.Minute
+)
+
+// Config is the configuration of the broker and the topics consumed.
+type Config struct {
+	// Broker is the URL of the broker, such as tcp://localhost:1883 or
+	// ssl://broker.example.com:8883.
+	Broker   string
+	ClientID string
+	Username string
+	Password string
+	// QoS is the quality of service of the subscriptions: 0, 1 or 2.
+	QoS int
+	// Routes are the buckets the messages of each topic template are
+	// written to.
+	Routes map[string]influxdb.ID
+	// Format is the format of the payloads; JSON payloads are flat objects
+	// of the fields of a point, such as {"temperature": 21.5, "door": "open"},
+	// whose time is their time key, if any.
+	Format    ingest.Format
+	Precision string
+}
+
+// Valid returns an error if the configuration is incomplete.
+func (c *Config) Valid() error {
+	if c.Broker == "" {
+		return errors.New("broker is required")
+	}
+	if c.ClientID == "" {
+		return errors.New("client ID is required")
+	}
+	if c.QoS < 0 || c.QoS > 2 {
+		return fmt.Errorf("invalid QoS %d, must be 0, 1 or 2", c.QoS)
+	}
+	if len(c.Routes) == 0 {
+		return errors.New("at least one topic is required")
+	}
+	if err := c.Format.Valid(); err != nil {
+		return err
+	}
+	return ingest.ValidPrecision(c.Precision)
+}
+
+// BucketFinder finds the buckets the topics are written to.
+type BucketFinder interface {
+	FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error)
+}
+
+// Consumer writes the messages of the topics of the routes to their
+// buckets.
+type Consumer struct {
+	log     *zap.Logger
+	config  Config
+	routes  []*route
+	buckets BucketFinder
+	pw      storage.PointsWriter
+	now     func() time.Time
+
+	messages *prometheus.CounterVec
+	points   *prometheus.CounterVec
+}
+
+// NewConsumer returns a Consumer of the topics of config, writing their
+// points with pw to the buckets found with buckets.
+func NewConsumer(log *zap.Logger, config Config, buckets BucketFinder, pw storage.PointsWriter) (*Consumer, error) {
+	if err := config.Valid(); err != nil {
+		return nil, err
+	}
+	routes := make([]*route, 0, len(config.Routes))
+	for template, bucketID := range config.Routes {
+		r, err := newRoute(template, bucketID)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, r)
+	}
+
+	const namespace = "mqtt"
+	const subsystem = "ingest"
+	return &Consumer{
+		log:     log,
+		config:  config,
+		routes:  routes,
+		buckets: buckets,
+		pw:      pw,
+		now:     func() time.Time { return time.Now().UTC() },
+
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "messages_total",
+			Help:      "Number of messages consumed, by topic template and result: written, invalid, rejected or failed.",
+		}, []string{"route", "result"}),
+		points: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "points_total",
+			Help:      "Number of points written from the messages, by topic template.",
+		}, []string{"route"}),
+	}, nil
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (c *Consumer) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.messages,
+		c.points,
+	}
+}
+
+// Run consumes the topics until ctx is done. The subscriptions are made
+// again whenever the client reconnects to the broker.
+func (c *Consumer) Run(ctx context.Context) {
+	opts := mqtt.NewClientOptions().
+		AddBroker(c.config.Broker).
+		SetClientID(c.config.ClientID).
+		SetUsername(c.config.Username).
+		SetPassword(c.config.Password).
+		// The broker keeps the messages published while influxd is
+		// disconnected for the subscriptions of QoS 1 and 2.
+		SetCleanSession(false).
+		SetAutoReconnect(true).
+		SetOnConnectHandler(func(client mqtt.Client) {
+			c.subscribe(ctx, client)
+		}).
+		SetConnectionLostHandler(func(client mqtt.Client, err error) {
+			c.log.Warn("Lost connection to MQTT broker", zap.Error(err))
+		})
+	client := mqtt.NewClient(opts)
+
+	// The client only reconnects once it has connected.
+	retry := minRetryInterval
+	for {
+		token := client.Connect()
+		token.Wait()
+		if token.Error() == nil {
+			break
+		}
+		c.log.Warn("Failed to connect to MQTT broker", zap.String("broker", c.config.Broker), zap.Error(token.Error()), zap.Duration("retry", retry))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		if retry *= 2; retry > maxRetryInterval {
+			retry = maxRetryInterval
+		}
+	}
+
+	<-ctx.Done()
+	client.Disconnect(250)
+}
+
+func (c *Consumer) subscribe(ctx context.Context, client mqtt.Client) {
+	c.log.Info("Connected to MQTT broker", zap.String("broker", c.config.Broker))
+	for _, r := range c.routes {
+		r := r
+		token := client.Subscribe(r.filter, byte(c.config.QoS), func(_ mqtt.Client, msg mqtt.Message) {
+			c.handle(ctx, r, msg.Topic(), msg.Payload())
+		})
+		token.Wait()
+		if err := token.Error(); err != nil {
+			c.log.Error("Failed to subscribe to MQTT topic", zap.String("topic", r.filter), zap.Error(err))
+		}
+	}
+}
+
+// handle writes the points of the payload of a message of topic to the
+// bucket of r. The write is attempted again when it may succeed later, but
+// a message that cannot be written is dropped.
+func (c *Consumer) handle(ctx context.Context, r *route, topic string, payload []byte) {
+	log := c.log.With(zap.String("topic", topic), zap.String("bucketID", r.bucketID.String()))
+
+	retry := minRetryInterval
+	for attempt := 1; ; attempt++ {
+		n, err := c.write(ctx, r, topic, payload)
+		if err == nil {
+			c.messages.WithLabelValues(r.template, "written").Inc()
+			c.points.WithLabelValues(r.template).Add(float64(n))
+			return
+		}
+		if code := influxdb.ErrorCode(err); code == influxdb.EInvalid {
+			log.Warn("Dropping invalid message", zap.Error(err))
+			c.messages.WithLabelValues(r.template, "invalid").Inc()
+			return
+		} else if !retryable(code) {
+			log.Warn("Dropping message that cannot be written", zap.Error(err))
+			c.messages.WithLabelValues(r.template, "rejected").Inc()
+			return
+		}
+		if attempt == maxWriteAttempts || ctx.Err() != nil {
+			log.Error("Dropping message that failed to be written", zap.Error(err), zap.Int("attempts", attempt))
+			c.messages.WithLabelValues(r.template, "failed").Inc()
+			return
+		}
+
+		log.Warn("Failed to write message", zap.Error(err), zap.Duration("retry", retry))
+		select {
+		case <-ctx.Done():
+		case <-time.After(retry):
+		}
+		retry *= 2
+	}
+}
+
+// write writes the points of payload and returns their number.
+func (c *Consumer) write(ctx context.Context, r *route, topic string, payload []byte) (int, error) {
+	b, err := c.buckets.FindBucketByID(ctx, r.bucketID)
+	if err != nil {
+		return 0, err
+	}
+
+	measurement, tags := r.tags(topic)
+	points, err := parse(c.config.Format, payload, measurement, tags, b.OrgID, b.ID, c.config.Precision, c.now())
+	if err != nil {
+		return 0, &influxdb.Error{Code: influxdb.EInvalid, Err: err}
+	}
+	if len(points) == 0 {
+		return 0, nil
+	}
+	if err := c.pw.WritePoints(ctx, points); err != nil {
+		return 0, err
+	}
+	return len(points), nil
+}
+
+// retryable returns whether a write failing with the error code may succeed
+// later.
+func retryable(code string) bool {
+	switch code {
+	case influxdb.EInvalid, influxdb.EUnprocessableEntity, influxdb.ETooLarge,
+		influxdb.ENotFound, influxdb.EForbidden, influxdb.EUnauthorized:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,141 @@
+package mqttingest
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/ingest"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"go.uber.org/zap/zaptest"
+)
+
+// newTestConsumer returns a consumer writing the topics of template to the
+// bucket 10 of the organization 1 with pw.
+func newTestConsumer(t *testing.T, template string, format ingest.Format, pw *mock.PointsWriter) (*Consumer, *route) {
+	t.Helper()
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: id, OrgID: 1}, nil
+	}
+	config := Config{
+		Broker:    "tcp://localhost:1883",
+		ClientID:  DefaultClientID,
+		QoS:       1,
+		Routes:    map[string]influxdb.ID{template: 10},
+		Format:    format,
+		Precision: "s",
+	}
+	c, err := NewConsumer(zaptest.NewLogger(t), config, buckets, pw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return time.Unix(100, 0).UTC() }
+	return c, c.routes[0]
+}
+
+// seriesKeys returns the measurement, tags and field of the exploded points.
+func seriesKeys(points []models.Point) []string {
+	keys := make([]string, 0, len(points))
+	for _, p := range points {
+		tags := p.Tags()
+		var key string
+		for _, tag := range tags {
+			switch string(tag.Key) {
+			case models.MeasurementTagKey, models.FieldKeyTagKey:
+			default:
+				key += "," + string(tag.Key) + "=" + string(tag.Value)
+			}
+		}
+		keys = append(keys, string(tags.Get(models.MeasurementTagKeyBytes))+key+" "+string(tags.Get(models.FieldKeyTagKeyBytes)))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestConsumer_Handle(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		format   ingest.Format
+		topic    string
+		payload  string
+		want     []string
+	}{
+		{
+			name:     "line protocol",
+			template: "sites/{site}/#",
+			format:   ingest.FormatLineProtocol,
+			topic:    "sites/paris/d1",
+			payload:  "cpu,host=a usage=1,idle=2 10\nmem used=3",
+			want:     []string{"cpu,host=a,site=paris idle", "cpu,host=a,site=paris usage", "mem,site=paris used"},
+		},
+		{
+			name:     "line protocol with measurement level",
+			template: "{_measurement}/{device}",
+			format:   ingest.FormatLineProtocol,
+			topic:    "temperature/d1",
+			payload:  "t,device=other value=21.5",
+			want:     []string{"temperature,device=d1 value"},
+		},
+		{
+			name:     "JSON",
+			template: "sites/{site}/{_measurement}",
+			format:   ingest.FormatJSON,
+			topic:    "sites/paris/climate",
+			payload:  `{"temperature": 21.5, "door": "open", "time": 1600000000}`,
+			want:     []string{"climate,site=paris door", "climate,site=paris temperature"},
+		},
+		{
+			name:     "JSON without measurement level",
+			template: "sensors/{device}",
+			format:   ingest.FormatJSON,
+			topic:    "sensors/d1",
+			payload:  `{"temperature": 21.5}`,
+			want:     []string{"mqtt,device=d1 temperature"},
+		},
+		{
+			name:     "invalid",
+			template: "sensors/{device}",
+			format:   ingest.FormatJSON,
+			topic:    "sensors/d1",
+			payload:  `{"temperature": [1, 2]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pw := &mock.PointsWriter{}
+			c, r := newTestConsumer(t, tt.template, tt.format, pw)
+
+			c.handle(context.Background(), r, tt.topic, []byte(tt.payload))
+			if diff := cmp.Diff(tt.want, seriesKeys(pw.Points), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("unexpected points (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConsumer_HandleRejected(t *testing.T) {
+	pw := &mock.PointsWriter{WritePointsFn: func(ctx context.Context, points []models.Point) error {
+		return &influxdb.Error{Code: influxdb.EForbidden, Msg: "bucket is read only"}
+	}}
+	c, r := newTestConsumer(t, "sensors/{device}", ingest.FormatLineProtocol, pw)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.handle(context.Background(), r, "sensors/d1", []byte("t value=1"))
+	}()
+	// A rejected write is not attempted again.
+	select {
+	case <-done:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("expected the rejected message to be dropped")
+	}
+}
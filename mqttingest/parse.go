@@ -0,0 +1,86 @@
+package mqttingest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/ingest"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// DefaultMeasurement is the measurement of the JSON payloads of the routes
+// without a measurement level.
+const DefaultMeasurement = "mqtt"
+
+// timeField is the key of the time of the points of JSON payloads.
+const timeField = "time"
+
+// parse returns the points of payload, tagged with the tags of its topic and
+// exploded to be written to the bucket. The measurement of the topic, if
+// any, replaces the measurements of line protocol payloads.
+func parse(format ingest.Format, payload []byte, measurement string, tags map[string]string, orgID, bucketID influxdb.ID, precision string, now time.Time) ([]models.Point, error) {
+	if format == ingest.FormatJSON {
+		if measurement == "" {
+			measurement = DefaultMeasurement
+		}
+		p, err := parseJSON(payload, measurement, tags, precision, now)
+		if err != nil {
+			return nil, err
+		}
+		return tsdb.ExplodePoints(orgID, bucketID, []models.Point{p})
+	}
+
+	points, err := ingest.ParseLineProtocol(payload, orgID, bucketID, precision, now)
+	if err != nil {
+		return nil, err
+	}
+	if measurement == "" && len(tags) == 0 {
+		return points, nil
+	}
+	for _, p := range points {
+		pt := p.Tags().Clone()
+		if measurement != "" {
+			pt.SetString(models.MeasurementTagKey, measurement)
+		}
+		for k, v := range tags {
+			pt.SetString(k, v)
+		}
+		p.SetTags(pt)
+	}
+	return points, nil
+}
+
+// parseJSON returns the point of a flat JSON object of fields.
+func parseJSON(payload []byte, measurement string, tags map[string]string, precision string, now time.Time) (models.Point, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &obj); err != nil {
+		return nil, err
+	}
+
+	t, err := ingest.Time(obj[timeField], precision, now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time: %v", err)
+	}
+	delete(obj, timeField)
+
+	values := make(map[string]interface{}, len(obj))
+	for k, raw := range obj {
+		var v interface{}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		values[k] = v
+	}
+	if len(values) == 0 {
+		return nil, errors.New("payload has no fields")
+	}
+	fields, err := ingest.Fields(values)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewPoint(measurement, models.NewTags(tags), fields, t)
+}
@@ -0,0 +1,87 @@
+package mqttingest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// measurementLevel is the name of the level of a route that is the
+// measurement of the points of its messages.
+const measurementLevel = "_measurement"
+
+// route subscribes to the topics of a template and writes their messages to
+// a bucket. The levels of a template are either literal, a + or # wildcard,
+// or named, such as {device}, which matches any value of the level, as a +
+// wildcard, and tags the points of the messages with it. The level named
+// {_measurement} is the measurement of the points.
+type route struct {
+	template string
+	filter   string
+	names    []string
+	bucketID influxdb.ID
+}
+
+func newRoute(template string, bucketID influxdb.ID) (*route, error) {
+	if template == "" {
+		return nil, errors.New("topic template is required")
+	}
+	if !bucketID.Valid() {
+		return nil, fmt.Errorf("invalid bucket ID of topic template %q", template)
+	}
+
+	levels := strings.Split(template, "/")
+	r := &route{
+		template: template,
+		names:    make([]string, len(levels)),
+		bucketID: bucketID,
+	}
+	filter := make([]string, len(levels))
+	seen := make(map[string]bool)
+	for i, level := range levels {
+		switch {
+		case level == "#":
+			if i != len(levels)-1 {
+				return nil, fmt.Errorf("invalid topic template %q: # must be its last level", template)
+			}
+		case strings.HasPrefix(level, "{") && strings.HasSuffix(level, "}"):
+			name := level[1 : len(level)-1]
+			if name == "" || strings.ContainsAny(name, "{}+#") {
+				return nil, fmt.Errorf("invalid topic template %q: invalid level name %q", template, name)
+			}
+			if strings.HasPrefix(name, "_") && name != measurementLevel {
+				return nil, fmt.Errorf("invalid topic template %q: level name %q is reserved", template, name)
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("invalid topic template %q: level name %q is repeated", template, name)
+			}
+			seen[name] = true
+			r.names[i] = name
+			level = "+"
+		case level != "+" && strings.ContainsAny(level, "{}+#"):
+			return nil, fmt.Errorf("invalid topic template %q: invalid level %q", template, level)
+		}
+		filter[i] = level
+	}
+	r.filter = strings.Join(filter, "/")
+	return r, nil
+}
+
+// tags returns the measurement, if the template has a measurement level, and
+// the tags of the named levels of topic.
+func (r *route) tags(topic string) (measurement string, tags map[string]string) {
+	tags = make(map[string]string)
+	for i, level := range strings.Split(topic, "/") {
+		if i >= len(r.names) || r.names[i] == "" {
+			continue
+		}
+		if r.names[i] == measurementLevel {
+			measurement = level
+			continue
+		}
+		tags[r.names[i]] = level
+	}
+	return measurement, tags
+}
@@ -0,0 +1,68 @@
+package mqttingest
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewRoute(t *testing.T) {
+	tests := []struct {
+		template    string
+		wantFilter  string
+		wantErr     bool
+		topic       string
+		measurement string
+		tags        map[string]string
+	}{
+		{
+			template:    "sites/{site}/{device}/{_measurement}",
+			wantFilter:  "sites/+/+/+",
+			topic:       "sites/paris/d1/temperature",
+			measurement: "temperature",
+			tags:        map[string]string{"site": "paris", "device": "d1"},
+		},
+		{
+			template:   "sensors/{device}/#",
+			wantFilter: "sensors/+/#",
+			topic:      "sensors/d1/a/b",
+			tags:       map[string]string{"device": "d1"},
+		},
+		{
+			template:   "sensors/+/status",
+			wantFilter: "sensors/+/status",
+			topic:      "sensors/d1/status",
+			tags:       map[string]string{},
+		},
+		{template: "sensors/#/status", wantErr: true},
+		{template: "sensors/{}", wantErr: true},
+		{template: "sensors/{_field}", wantErr: true},
+		{template: "sensors/{device}/{device}", wantErr: true},
+		{template: "sensors/d+", wantErr: true},
+		{template: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			r, err := newRoute(tt.template, 1)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.filter != tt.wantFilter {
+				t.Errorf("unexpected filter %q, want %q", r.filter, tt.wantFilter)
+			}
+			measurement, tags := r.tags(tt.topic)
+			if measurement != tt.measurement {
+				t.Errorf("unexpected measurement %q, want %q", measurement, tt.measurement)
+			}
+			if diff := cmp.Diff(tt.tags, tags); diff != "" {
+				t.Errorf("unexpected tags (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
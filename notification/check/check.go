@@ -10,6 +10,11 @@ import (
 	"github.com/influxdata/influxdb/v2/notification/flux"
 )
 
+// StatusHeartbeatTag is the tag of the statuses of checks with a status
+// heartbeat holding the heartbeat, e.g. "1h0m0s". The statuses with the tag
+// are deduplicated when they are written to the _monitoring bucket.
+const StatusHeartbeatTag = "_status_heartbeat"
+
 // Base will embed inside a check.
 type Base struct {
 	ID          influxdb.ID             `json:"id,omitempty"`
@@ -30,6 +35,12 @@ type Base struct {
 	// Offset represents a delay before execution.
 	// It gets marshalled from a string duration, i.e.: "10s" is 10 seconds
 	Offset *notification.Duration `json:"offset,omitempty"`
+	// Sampling limits the series evaluated per interval.
+	Sampling *Sampling `json:"sampling,omitempty"`
+	// StatusHeartbeat, when set, persists only the statuses of a series
+	// whose level changed, and the statuses of unchanged levels once per
+	// heartbeat.
+	StatusHeartbeat *notification.Duration `json:"statusHeartbeat,omitempty"`
 
 	Tags []influxdb.Tag `json:"tags"`
 	influxdb.CRUDLog
@@ -85,10 +96,33 @@ func (b Base) Valid(lang influxdb.FluxLanguageService) error {
 			Msg:  "Offset should not be equal or greater than the interval",
 		}
 	}
+	if b.Sampling != nil {
+		if err := b.Sampling.Valid(); err != nil {
+			return err
+		}
+	}
+	if b.StatusHeartbeat != nil && len(b.StatusHeartbeat.Values) == 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Check StatusHeartbeat can't be empty",
+		}
+	}
+	if b.StatusHeartbeat != nil && b.StatusHeartbeat.TimeDuration() < b.Every.TimeDuration() {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Check StatusHeartbeat should not be less than the interval",
+		}
+	}
 	for _, tag := range b.Tags {
 		if err := tag.Valid(); err != nil {
 			return err
 		}
+		if tag.Key == StatusHeartbeatTag {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("Check tag %s is reserved", StatusHeartbeatTag),
+			}
+		}
 	}
 
 	return nil
@@ -125,6 +159,10 @@ func (b Base) generateFluxASTCheckDefinition(checkType string) ast.Statement {
 	for _, tag := range b.Tags {
 		tagProps = append(tagProps, flux.Property(tag.Key, flux.String(tag.Value)))
 	}
+	if b.StatusHeartbeat != nil {
+		heartbeat := b.StatusHeartbeat.TimeDuration().String()
+		tagProps = append(tagProps, flux.Property(StatusHeartbeatTag, flux.String(heartbeat)))
+	}
 
 	props = append(props, flux.Property("tags", flux.Object(tagProps...)))
 
@@ -140,6 +140,20 @@ func TestValidCheck(t *testing.T) {
 				Msg:  "tag must contain a key and a value",
 			},
 		},
+		{
+			name: "status heartbeat less than interval",
+			src: &check.Threshold{
+				Base: func() check.Base {
+					b := goodBase
+					b.StatusHeartbeat = mustDuration("30s")
+					return b
+				}(),
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Check StatusHeartbeat should not be less than the interval",
+			},
+		},
 		{
 			name: "bad thredshold",
 			src: &check.Threshold{
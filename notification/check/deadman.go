@@ -23,6 +23,64 @@ type Deadman struct {
 	// TODO(desa): Is this implemented in Flux?
 	ReportZero bool                    `json:"reportZero"`
 	Level      notification.CheckLevel `json:"level"`
+	// GraceOverrides replace TimeSince for the series matching their tags.
+	// The first matching override is used.
+	GraceOverrides []DeadmanGraceOverride `json:"graceOverrides,omitempty"`
+	// ReportOnce reports a series when it goes silent, but not in the
+	// following runs until it has reported data again.
+	ReportOnce bool `json:"reportOnce,omitempty"`
+	// SeriesTags name the series in the status message of a silent series,
+	// e.g. "host=h1 region=west" for the tags host and region.
+	SeriesTags []string `json:"seriesTags,omitempty"`
+}
+
+// DeadmanGraceOverride is how long the series matching all the tag rules can
+// be silent before they are dead.
+type DeadmanGraceOverride struct {
+	TagRules  []notification.TagRule `json:"tagRules"`
+	TimeSince *notification.Duration `json:"timeSince"`
+}
+
+// Valid returns err if the check is invalid.
+func (c Deadman) Valid(lang influxdb.FluxLanguageService) error {
+	if err := c.Base.Valid(lang); err != nil {
+		return err
+	}
+	for _, o := range c.GraceOverrides {
+		if len(o.TagRules) == 0 {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Deadman grace override must match at least one tag",
+			}
+		}
+		for _, r := range o.TagRules {
+			if err := r.Valid(); err != nil {
+				return err
+			}
+		}
+		if o.TimeSince == nil || len(o.TimeSince.Values) == 0 {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Deadman grace override timeSince can't be empty",
+			}
+		}
+		if c.StaleTime != nil && o.TimeSince.TimeDuration() >= c.StaleTime.TimeDuration() {
+			// Series silent for longer than the stale time are not queried.
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Deadman grace override timeSince should be less than the stale time",
+			}
+		}
+	}
+	for _, tag := range c.SeriesTags {
+		if tag == "" {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Deadman series tags can't be empty",
+			}
+		}
+	}
+	return nil
 }
 
 // Type returns the type of the check.
@@ -64,6 +122,9 @@ func (c Deadman) GenerateFluxAST(lang influxdb.FluxLanguageService) (*ast.Packag
 
 	f := p.Files[0]
 	assignPipelineToData(f)
+	if err := c.sampleData(f); err != nil {
+		return nil, err
+	}
 
 	f.Imports = append(f.Imports, flux.Imports("influxdata/influxdb/monitor", "experimental", "influxdata/influxdb/v1")...)
 	f.Body = append(f.Body, c.generateFluxASTBody()...)
@@ -76,10 +137,48 @@ func (c Deadman) generateFluxASTBody() []ast.Statement {
 	statements = append(statements, c.generateTaskOption())
 	statements = append(statements, c.generateFluxASTCheckDefinition("deadman"))
 	statements = append(statements, c.generateLevelFn())
-	statements = append(statements, c.generateFluxASTMessageFunction())
+	statements = append(statements, c.generateDeadmanMessageFunction())
+	if len(c.GraceOverrides) > 0 || c.ReportOnce {
+		statements = append(statements, c.generateDeadmanTimeFn())
+	}
 	return append(statements, c.generateFluxASTChecksFunction())
 }
 
+// generateDeadmanMessageFunction appends the silent series to the messages
+// of dead series when the check has series tags.
+func (c Deadman) generateDeadmanMessageFunction() ast.Statement {
+	if len(c.SeriesTags) == 0 {
+		return c.generateFluxASTMessageFunction()
+	}
+	series := flux.If(
+		flux.Member("r", "dead"),
+		flux.Add(flux.String(" silent series: "), flux.Member("r", "_series")),
+		flux.String(""),
+	)
+	fn := flux.Function(flux.FunctionParams("r"), flux.Add(flux.String(c.StatusMessageTemplate), series))
+	return flux.DefineVariable("messageFn", fn)
+}
+
+// generateDeadmanTimeFn defines deadmanTime, the time after which a series
+// is not dead.
+func (c Deadman) generateDeadmanTimeFn() ast.Statement {
+	var t ast.Expression = timeSince(c.TimeSince)
+	for i := len(c.GraceOverrides) - 1; i >= 0; i-- {
+		o := c.GraceOverrides[i]
+		test := o.TagRules[0].GenerateFluxAST()
+		for _, r := range o.TagRules[1:] {
+			test = flux.And(test, r.GenerateFluxAST())
+		}
+		t = flux.If(test, timeSince(o.TimeSince), t)
+	}
+	return flux.DefineVariable("deadmanTime", flux.Function(flux.FunctionParams("r"), t))
+}
+
+func timeSince(d *notification.Duration) ast.Expression {
+	now := flux.Call(flux.Identifier("now"), flux.Object())
+	return flux.Call(flux.Member("experimental", "subDuration"), flux.Object(flux.Property("from", now), flux.Property("d", (*ast.DurationLiteral)(d))))
+}
+
 func (c Deadman) generateLevelFn() ast.Statement {
 	fn := flux.Function(flux.FunctionParams("r"), flux.Member("r", "dead"))
 
@@ -89,15 +188,58 @@ func (c Deadman) generateLevelFn() ast.Statement {
 }
 
 func (c Deadman) generateFluxASTChecksFunction() ast.Statement {
-	dur := (*ast.DurationLiteral)(c.TimeSince)
-	now := flux.Call(flux.Identifier("now"), flux.Object())
-	sub := flux.Call(flux.Member("experimental", "subDuration"), flux.Object(flux.Property("from", now), flux.Property("d", dur)))
-	return flux.ExpressionStatement(flux.Pipe(
-		flux.Identifier("data"),
+	calls := []*ast.CallExpression{
 		flux.Call(flux.Member("v1", "fieldsAsCols"), flux.Object()),
-		flux.Call(flux.Member("monitor", "deadman"), flux.Object(flux.Property("t", sub))),
-		c.generateFluxASTChecksCall(),
-	))
+		flux.Call(flux.Member("monitor", "deadman"), flux.Object(flux.Property("t", timeSince(c.TimeSince)))),
+	}
+
+	deadmanTime := flux.Call(flux.Identifier("deadmanTime"), flux.Object(flux.Property("r", flux.Identifier("r"))))
+	if len(c.GraceOverrides) > 0 {
+		dead := flux.LessThan(flux.Member("r", "_time"), deadmanTime)
+		calls = append(calls, mapWith(flux.Property("dead", dead)))
+	}
+	if c.ReportOnce {
+		// Keep dead series only in the run after their last point.
+		lastRun := flux.Call(flux.Member("experimental", "subDuration"), flux.Object(
+			flux.Property("from", deadmanTime),
+			flux.Property("d", (*ast.DurationLiteral)(c.Every)),
+		))
+		fn := flux.Function(flux.FunctionParams("r"), flux.Or(
+			&ast.UnaryExpression{Operator: ast.NotOperator, Argument: flux.Member("r", "dead")},
+			&ast.BinaryExpression{Operator: ast.GreaterThanEqualOperator, Left: flux.Member("r", "_time"), Right: lastRun},
+		))
+		calls = append(calls, flux.Call(flux.Identifier("filter"), flux.Object(flux.Property("fn", fn))))
+	}
+	if len(c.SeriesTags) > 0 {
+		calls = append(calls, mapWith(flux.Property("_series", c.generateSeries())))
+	}
+
+	calls = append(calls, c.generateCoverageCalls()...)
+	calls = append(calls, c.generateFluxASTChecksCall())
+	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("data"), calls...))
+}
+
+// generateSeries joins the series tags of a record, e.g. host=h1 region=west.
+func (c Deadman) generateSeries() ast.Expression {
+	var series ast.Expression
+	for i, tag := range c.SeriesTags {
+		prefix := tag + "="
+		if i > 0 {
+			prefix = " " + prefix
+		}
+		if series == nil {
+			series = flux.String(prefix)
+		} else {
+			series = flux.Add(series, flux.String(prefix))
+		}
+		series = flux.Add(series, flux.Member("r", tag))
+	}
+	return series
+}
+
+func mapWith(ps ...*ast.Property) *ast.CallExpression {
+	fn := flux.Function(flux.FunctionParams("r"), flux.ObjectWith("r", ps...))
+	return flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", fn)))
 }
 
 func (c Deadman) generateFluxASTChecksCall() *ast.CallExpression {
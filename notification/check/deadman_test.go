@@ -1,6 +1,7 @@
 package check_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/influxdata/influxdb/v2"
@@ -229,3 +230,76 @@ data
 	}
 
 }
+
+func TestDeadman_GenerateFlux_perSeries(t *testing.T) {
+	deadman := check.Deadman{
+		Base: check.Base{
+			ID:                    10,
+			Name:                  "moo",
+			Every:                 mustDuration("1m"),
+			StatusMessageTemplate: "no data",
+			Query: influxdb.DashboardQuery{
+				Text: `from(bucket: "foo") |> range(start: -1d, stop: now()) |> yield()`,
+			},
+		},
+		TimeSince: mustDuration("60s"),
+		StaleTime: mustDuration("10m"),
+		Level:     notification.Critical,
+		GraceOverrides: []check.DeadmanGraceOverride{
+			{
+				TagRules: []notification.TagRule{
+					{Tag: influxdb.Tag{Key: "role", Value: "batch"}, Operator: influxdb.Equal},
+				},
+				TimeSince: mustDuration("5m"),
+			},
+		},
+		ReportOnce: true,
+		SeriesTags: []string{"host", "region"},
+	}
+
+	script, err := deadman.GenerateFlux(fluxlang.DefaultService)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`deadmanTime = (r) =>`,
+		`if r["role"] == "batch" then experimental["subDuration"](from: now(), d: 5m) else experimental["subDuration"](from: now(), d: 60s)`,
+		`dead: r["_time"] < deadmanTime(r: r)`,
+		`r["_time"] >= experimental["subDuration"](from: deadmanTime(r: r), d: 1m)`,
+		`_series: "host=" + r["host"] + " region=" + r["region"]`,
+		`" silent series: " + r["_series"]`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script does not contain %q:\n%s", want, script)
+		}
+	}
+}
+
+func TestDeadman_Valid_graceOverrides(t *testing.T) {
+	deadman := check.Deadman{
+		Base: check.Base{
+			ID:      10,
+			Name:    "moo",
+			OwnerID: 2,
+			OrgID:   3,
+			Every:   mustDuration("1m"),
+		},
+		TimeSince: mustDuration("60s"),
+		StaleTime: mustDuration("10m"),
+		Level:     notification.Critical,
+		GraceOverrides: []check.DeadmanGraceOverride{
+			{
+				TagRules: []notification.TagRule{
+					{Tag: influxdb.Tag{Key: "role", Value: "batch"}, Operator: influxdb.Equal},
+				},
+				TimeSince: mustDuration("1h"),
+			},
+		},
+	}
+
+	err := deadman.Valid(fluxlang.DefaultService)
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected a grace longer than the stale time to be invalid, got %v", err)
+	}
+}
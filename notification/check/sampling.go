@@ -0,0 +1,160 @@
+package check
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+)
+
+// seriesKeyColumn is the column of the hash of the group key of a series
+// added by pagerduty.dedupKey, the only function of flux hashing a series.
+const seriesKeyColumn = "_pagerdutyDedupKey"
+
+// CoverageColumn is the column of the statuses of a sampled check holding
+// the percentage of the series evaluated.
+const CoverageColumn = "_series_coverage"
+
+// Sampling limits the series a check evaluates per interval, so that a check
+// of a measurement with many series does not dominate the query engine.
+//
+// The series are selected by the hash of their group key, so that the same
+// series are evaluated at every interval.
+type Sampling struct {
+	// Percent of the series evaluated, in (0, 100]. 0 evaluates every series.
+	Percent float64 `json:"percent,omitempty"`
+	// MaxSeries is the maximum number of series evaluated. 0 means no limit.
+	MaxSeries int64 `json:"maxSeries,omitempty"`
+}
+
+// Valid returns err if the sampling is invalid.
+func (s Sampling) Valid() error {
+	if s.Percent < 0 || s.Percent > 100 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Check sampling percent must be between 0 and 100",
+		}
+	}
+	if s.MaxSeries < 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Check sampling maxSeries can't be negative",
+		}
+	}
+	if s.Percent == 0 && s.MaxSeries == 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Check sampling must set a percent or maxSeries",
+		}
+	}
+	return nil
+}
+
+// sampleData replaces the data of f, defined by assignPipelineToData, with
+// the series of the data selected by the sampling of the check, and defines
+// coverage, the percentage of the series selected.
+func (b Base) sampleData(f *ast.File) error {
+	if b.Sampling == nil {
+		return nil
+	}
+	if len(f.Body) == 0 {
+		return fmt.Errorf("expected the data of the check to be defined")
+	}
+	data, ok := f.Body[0].(*ast.VariableAssignment)
+	if !ok || data.ID.Name != "data" {
+		return fmt.Errorf("expected the data of the check to be defined, recieved %T", f.Body[0])
+	}
+	data.ID = flux.Identifier("unsampled")
+
+	keys := func(tables ast.Expression) ast.Expression {
+		return flux.Pipe(tables, flux.Call(flux.Identifier("findColumn"), flux.Object(
+			flux.Property("fn", flux.Function(flux.FunctionParams("key"), flux.Bool(true))),
+			flux.Property("column", flux.String(seriesKeyColumn)),
+		)))
+	}
+	length := func(arr ast.Expression) *ast.CallExpression {
+		return flux.Call(flux.Identifier("length"), flux.Object(flux.Property("arr", arr)))
+	}
+	toFloat := func(v ast.Expression) *ast.CallExpression {
+		return flux.Call(flux.Identifier("float"), flux.Object(flux.Property("v", v)))
+	}
+	dedupKey := func() *ast.CallExpression {
+		return flux.Call(flux.Member("pagerduty", "dedupKey"), flux.Object())
+	}
+
+	// series has a row per series with the hash of its key.
+	series := flux.Pipe(flux.Identifier("unsampled"),
+		dedupKey(),
+		flux.Call(flux.Identifier("last"), flux.Object()),
+		flux.Call(flux.Identifier("group"), flux.Object()),
+		flux.Call(flux.Identifier("keep"), flux.Object(flux.Property("columns", flux.Array(flux.String(seriesKeyColumn))))),
+	)
+
+	// The hashes are hex encoded: the series whose hash is lower than the
+	// percent of the hashes are selected, then the lowest hashes up to
+	// MaxSeries.
+	var selected []*ast.CallExpression
+	if b.Sampling.Percent > 0 && b.Sampling.Percent < 100 {
+		prefix := fmt.Sprintf("%04x", int(math.Floor(b.Sampling.Percent/100*0x10000)))
+		fn := flux.Function(flux.FunctionParams("r"), flux.LessThan(flux.Member("r", seriesKeyColumn), flux.String(prefix)))
+		selected = append(selected, flux.Call(flux.Identifier("filter"), flux.Object(flux.Property("fn", fn))))
+	}
+	if b.Sampling.MaxSeries > 0 {
+		selected = append(selected,
+			flux.Call(flux.Identifier("sort"), flux.Object(flux.Property("columns", flux.Array(flux.String(seriesKeyColumn))))),
+			flux.Call(flux.Identifier("limit"), flux.Object(flux.Property("n", flux.Integer(b.Sampling.MaxSeries)))),
+		)
+	}
+
+	var sampledSeries ast.Expression = flux.Identifier("series")
+	if len(selected) > 0 {
+		sampledSeries = flux.Pipe(sampledSeries, selected...)
+	}
+
+	coverage := flux.If(
+		flux.Equal(flux.Identifier("totalSeries"), flux.Integer(0)),
+		flux.Float(100),
+		&ast.BinaryExpression{
+			Operator: ast.DivisionOperator,
+			Left: &ast.BinaryExpression{
+				Operator: ast.MultiplicationOperator,
+				Left:     toFloat(length(flux.Identifier("sampledSeries"))),
+				Right:    flux.Float(100),
+			},
+			Right: toFloat(flux.Identifier("totalSeries")),
+		},
+	)
+
+	contains := flux.Call(flux.Identifier("contains"), flux.Object(
+		flux.Property("value", flux.Member("r", seriesKeyColumn)),
+		flux.Property("set", flux.Identifier("sampledSeries")),
+	))
+	sampled := flux.Pipe(flux.Identifier("unsampled"),
+		dedupKey(),
+		flux.Call(flux.Identifier("filter"), flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"), contains)))),
+		flux.Call(flux.Identifier("drop"), flux.Object(flux.Property("columns", flux.Array(flux.String(seriesKeyColumn))))),
+	)
+
+	body := []ast.Statement{
+		data,
+		flux.DefineVariable("series", series),
+		flux.DefineVariable("sampledSeries", keys(sampledSeries)),
+		flux.DefineVariable("totalSeries", length(keys(flux.Identifier("series")))),
+		flux.DefineVariable("coverage", coverage),
+		flux.DefineVariable("data", sampled),
+	}
+	f.Body = append(body, f.Body[1:]...)
+	f.Imports = append(f.Imports, flux.Imports("pagerduty")...)
+	return nil
+}
+
+// generateCoverageCalls reports the coverage of the sampling of the check in
+// its statuses.
+func (b Base) generateCoverageCalls() []*ast.CallExpression {
+	if b.Sampling == nil {
+		return nil
+	}
+	return []*ast.CallExpression{mapWith(flux.Property(CoverageColumn, flux.Identifier("coverage")))}
+}
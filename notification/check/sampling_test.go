@@ -0,0 +1,78 @@
+package check_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+)
+
+func TestThreshold_GenerateFlux_sampling(t *testing.T) {
+	threshold := check.Threshold{
+		Base: check.Base{
+			ID:                    10,
+			Name:                  "moo",
+			Every:                 mustDuration("1m"),
+			StatusMessageTemplate: "whoa! {r[\"usage_user\"]}",
+			Query: influxdb.DashboardQuery{
+				Text: `from(bucket: "foo") |> range(start: -1d, stop: now()) |> filter(fn: (r) => r._field == "usage_user") |> yield()`,
+			},
+			Sampling: &check.Sampling{Percent: 25, MaxSeries: 100},
+		},
+		Thresholds: []check.ThresholdConfig{
+			check.Greater{
+				ThresholdConfigBase: check.ThresholdConfigBase{
+					Level: notification.Critical,
+				},
+				Value: 90,
+			},
+		},
+	}
+
+	script, err := threshold.GenerateFlux(fluxlang.DefaultService)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`import "pagerduty"`,
+		`unsampled = from(bucket: "foo")`,
+		`pagerduty["dedupKey"]()`,
+		`r["_pagerdutyDedupKey"] < "4000"`,
+		`limit(n: 100)`,
+		`contains(value: r["_pagerdutyDedupKey"], set: sampledSeries)`,
+		`_series_coverage: coverage`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script does not contain %q:\n%s", want, script)
+		}
+	}
+}
+
+func TestSampling_Valid(t *testing.T) {
+	tests := []struct {
+		name     string
+		sampling check.Sampling
+		valid    bool
+	}{
+		{name: "percent", sampling: check.Sampling{Percent: 10}, valid: true},
+		{name: "max series", sampling: check.Sampling{MaxSeries: 1000}, valid: true},
+		{name: "empty", sampling: check.Sampling{}},
+		{name: "percent over 100", sampling: check.Sampling{Percent: 150}},
+		{name: "negative max series", sampling: check.Sampling{MaxSeries: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sampling.Valid()
+			if tt.valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.valid && influxdb.ErrorCode(err) != influxdb.EInvalid {
+				t.Fatalf("expected an invalid sampling, got %v", err)
+			}
+		})
+	}
+}
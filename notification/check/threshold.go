@@ -142,6 +142,9 @@ func (t Threshold) GenerateFluxAST(lang influxdb.FluxLanguageService) (*ast.Pack
 
 	f := p.Files[0]
 	assignPipelineToData(f)
+	if err := t.sampleData(f); err != nil {
+		return nil, err
+	}
 
 	f.Imports = append(f.Imports, flux.Imports("influxdata/influxdb/monitor", "influxdata/influxdb/v1")...)
 	f.Body = append(f.Body, t.generateFluxASTBody(fields[0])...)
@@ -280,11 +283,10 @@ func (t Threshold) generateFluxASTBody(field string) []ast.Statement {
 }
 
 func (t Threshold) generateFluxASTChecksFunction() ast.Statement {
-	return flux.ExpressionStatement(flux.Pipe(
-		flux.Identifier("data"),
-		flux.Call(flux.Member("v1", "fieldsAsCols"), flux.Object()),
-		t.generateFluxASTChecksCall(),
-	))
+	calls := []*ast.CallExpression{flux.Call(flux.Member("v1", "fieldsAsCols"), flux.Object())}
+	calls = append(calls, t.generateCoverageCalls()...)
+	calls = append(calls, t.generateFluxASTChecksCall())
+	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("data"), calls...))
 }
 
 func (t Threshold) generateFluxASTChecksCall() *ast.CallExpression {
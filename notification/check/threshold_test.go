@@ -1,6 +1,7 @@
 package check_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/influxdata/flux/ast"
@@ -407,3 +408,36 @@ data
 	}
 
 }
+
+func TestThreshold_GenerateFlux_statusHeartbeat(t *testing.T) {
+	threshold := check.Threshold{
+		Base: check.Base{
+			ID:                    10,
+			Name:                  "moo",
+			Every:                 mustDuration("1m"),
+			StatusHeartbeat:       mustDuration("1h"),
+			StatusMessageTemplate: "whoa! {r[\"usage_user\"]}",
+			Query: influxdb.DashboardQuery{
+				Text: `from(bucket: "foo") |> range(start: -1d, stop: now()) |> filter(fn: (r) => r._field == "usage_user") |> yield()`,
+			},
+			Tags: []influxdb.Tag{{Key: "aaa", Value: "vaaa"}},
+		},
+		Thresholds: []check.ThresholdConfig{
+			check.Greater{
+				ThresholdConfigBase: check.ThresholdConfigBase{
+					Level: notification.Critical,
+				},
+				Value: 90,
+			},
+		},
+	}
+
+	script, err := threshold.GenerateFlux(fluxlang.DefaultService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `tags: {aaa: "vaaa", _status_heartbeat: "1h0m0s"}`
+	if !strings.Contains(script, want) {
+		t.Errorf("script does not contain %q:\n%s", want, script)
+	}
+}
@@ -0,0 +1,140 @@
+package check
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification"
+)
+
+// levels of the thresholds seeded from the threshold colors of a view, from
+// the highest threshold down.
+var viewThresholdLevels = []notification.CheckLevel{
+	notification.Critical,
+	notification.Warn,
+	notification.Info,
+}
+
+// NewThresholdFromView returns a threshold check seeded with the first query
+// of a dashboard cell view and thresholds derived from its ranges:
+//
+// - the highest threshold colors of the view become greater thresholds of
+// the critical, warning and info levels;
+// - without threshold colors, the bounds of the y axis become a critical
+// threshold of the values out of the visible range.
+//
+// The returned check has no name, organization or schedule.
+func NewThresholdFromView(v *influxdb.View) (*Threshold, error) {
+	queries, colors, axes := viewRanges(v.Properties)
+	var q *influxdb.DashboardQuery
+	for i := range queries {
+		if queries[i].Text != "" {
+			q = &queries[i]
+			break
+		}
+	}
+	if q == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "cell has no query to check",
+		}
+	}
+
+	thresholds := colorThresholds(colors)
+	if len(thresholds) == 0 {
+		thresholds = axisThresholds(axes["y"])
+	}
+	if len(thresholds) == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "cell has no thresholds or y axis bounds to seed the check thresholds",
+		}
+	}
+
+	return &Threshold{
+		Base: Base{
+			Query: *q,
+		},
+		Thresholds: thresholds,
+	}, nil
+}
+
+func viewRanges(p influxdb.ViewProperties) ([]influxdb.DashboardQuery, []influxdb.ViewColor, map[string]influxdb.Axis) {
+	switch p := p.(type) {
+	case influxdb.XYViewProperties:
+		return p.Queries, p.ViewColors, p.Axes
+	case influxdb.LinePlusSingleStatProperties:
+		return p.Queries, p.ViewColors, p.Axes
+	case influxdb.BandViewProperties:
+		return p.Queries, p.ViewColors, p.Axes
+	case influxdb.SingleStatViewProperties:
+		return p.Queries, p.ViewColors, nil
+	case influxdb.GaugeViewProperties:
+		return p.Queries, p.ViewColors, nil
+	case influxdb.TableViewProperties:
+		return p.Queries, p.ViewColors, nil
+	case influxdb.HistogramViewProperties:
+		return p.Queries, nil, nil
+	case influxdb.HeatmapViewProperties:
+		return p.Queries, nil, nil
+	case influxdb.ScatterViewProperties:
+		return p.Queries, nil, nil
+	case influxdb.MosaicViewProperties:
+		return p.Queries, nil, nil
+	case influxdb.CheckViewProperties:
+		return p.Queries, nil, nil
+	}
+	return nil, nil, nil
+}
+
+// colorThresholds returns greater thresholds of the threshold colors of a
+// view. Single stats and tables color their thresholds as text or
+// background, with a base color that is not a threshold.
+func colorThresholds(colors []influxdb.ViewColor) []ThresholdConfig {
+	var values []float64
+	for _, c := range colors {
+		switch c.Type {
+		case "threshold":
+		case "text", "background":
+			if c.ID == "base" {
+				continue
+			}
+		default:
+			continue
+		}
+		values = append(values, c.Value)
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(values)))
+
+	var thresholds []ThresholdConfig
+	for i, value := range values {
+		if i == len(viewThresholdLevels) {
+			break
+		}
+		thresholds = append(thresholds, &Greater{
+			ThresholdConfigBase: ThresholdConfigBase{Level: viewThresholdLevels[i]},
+			Value:               value,
+		})
+	}
+	return thresholds
+}
+
+// axisThresholds returns a critical threshold of the values out of the bounds of axis.
+func axisThresholds(axis influxdb.Axis) []ThresholdConfig {
+	if len(axis.Bounds) != 2 {
+		return nil
+	}
+	min, minErr := strconv.ParseFloat(axis.Bounds[0], 64)
+	max, maxErr := strconv.ParseFloat(axis.Bounds[1], 64)
+	base := ThresholdConfigBase{Level: notification.Critical}
+	switch {
+	case minErr == nil && maxErr == nil:
+		return []ThresholdConfig{&Range{ThresholdConfigBase: base, Min: min, Max: max}}
+	case minErr == nil:
+		return []ThresholdConfig{&Lesser{ThresholdConfigBase: base, Value: min}}
+	case maxErr == nil:
+		return []ThresholdConfig{&Greater{ThresholdConfigBase: base, Value: max}}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package check_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+)
+
+func TestNewThresholdFromView(t *testing.T) {
+	query := influxdb.DashboardQuery{
+		Text: `from(bucket: "foo") |> range(start: v.timeRangeStart, stop: v.timeRangeStop) |> filter(fn: (r) => r._field == "usage_user")`,
+	}
+
+	tests := []struct {
+		name       string
+		properties influxdb.ViewProperties
+		want       []check.ThresholdConfig
+		wantErr    string
+	}{
+		{
+			name: "single stat threshold colors",
+			properties: influxdb.SingleStatViewProperties{
+				Queries: []influxdb.DashboardQuery{query},
+				ViewColors: []influxdb.ViewColor{
+					{ID: "base", Type: "text", Value: 0},
+					{ID: "1", Type: "text", Value: 50},
+					{ID: "2", Type: "text", Value: 90},
+					{ID: "3", Type: "text", Value: 20},
+					{ID: "4", Type: "text", Value: 10},
+				},
+			},
+			want: []check.ThresholdConfig{
+				&check.Greater{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Critical}, Value: 90},
+				&check.Greater{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Warn}, Value: 50},
+				&check.Greater{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Info}, Value: 20},
+			},
+		},
+		{
+			name: "xy axis bounds",
+			properties: influxdb.XYViewProperties{
+				Queries: []influxdb.DashboardQuery{{}, query},
+				ViewColors: []influxdb.ViewColor{
+					{ID: "1", Type: "scale", Value: 0},
+				},
+				Axes: map[string]influxdb.Axis{
+					"y": {Bounds: []string{"0", "100"}},
+				},
+			},
+			want: []check.ThresholdConfig{
+				&check.Range{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Critical}, Min: 0, Max: 100},
+			},
+		},
+		{
+			name: "upper axis bound",
+			properties: influxdb.XYViewProperties{
+				Queries: []influxdb.DashboardQuery{query},
+				Axes: map[string]influxdb.Axis{
+					"y": {Bounds: []string{"", "100"}},
+				},
+			},
+			want: []check.ThresholdConfig{
+				&check.Greater{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Critical}, Value: 100},
+			},
+		},
+		{
+			name: "no ranges",
+			properties: influxdb.XYViewProperties{
+				Queries: []influxdb.DashboardQuery{query},
+				Axes: map[string]influxdb.Axis{
+					"y": {Bounds: []string{"", ""}},
+				},
+			},
+			wantErr: "cell has no thresholds or y axis bounds to seed the check thresholds",
+		},
+		{
+			name:       "no query",
+			properties: influxdb.MarkdownViewProperties{Note: "hello"},
+			wantErr:    "cell has no query to check",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chk, err := check.NewThresholdFromView(&influxdb.View{Properties: tt.properties})
+			if tt.wantErr != "" {
+				if influxdb.ErrorCode(err) != influxdb.EInvalid || influxdb.ErrorMessage(err) != tt.wantErr {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if chk.Query.Text != query.Text {
+				t.Errorf("unexpected query %q", chk.Query.Text)
+			}
+			if diff := cmp.Diff(tt.want, chk.Thresholds); diff != "" {
+				t.Errorf("unexpected thresholds, -want/+got:\n%s", diff)
+			}
+		})
+	}
+}
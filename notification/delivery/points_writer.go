@@ -0,0 +1,168 @@
+// Package delivery records metrics of the delivery of the notifications sent
+// by notification rules, so that the reliability of alerting can itself be
+// monitored and alerted on.
+//
+// Notification rules log every notification they send, with whether it was
+// sent, to the notifications measurement of the _monitoring bucket of their
+// organization. The metrics are recorded from these writes.
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	notificationsMeasurement = []byte("notifications")
+	statusTimestampField     = []byte("_status_timestamp")
+	ruleIDTag                = []byte("_notification_rule_id")
+	endpointIDTag            = []byte("_notification_endpoint_id")
+	sentTag                  = []byte("_sent")
+)
+
+// unknownEndpointType labels the notifications of endpoints that were not
+// found.
+const unknownEndpointType = "unknown"
+
+var _ storage.PointsWriter = (*PointsWriter)(nil)
+
+// PointsWriter writes points with an underlying points writer and records
+// the outcome of the notifications among them.
+type PointsWriter struct {
+	storage.PointsWriter
+	endpoints influxdb.NotificationEndpointService
+
+	mu            sync.Mutex
+	endpointTypes map[string]string // the types of endpoints by ID, which never change.
+
+	sent    *prometheus.CounterVec
+	failed  *prometheus.CounterVec
+	latency *prometheus.HistogramVec
+}
+
+// NewPointsWriter returns a PointsWriter writing points with pw. The types of
+// the endpoints of notifications are found with endpoints.
+func NewPointsWriter(pw storage.PointsWriter, endpoints influxdb.NotificationEndpointService) *PointsWriter {
+	const namespace = "notification"
+	const subsystem = "delivery"
+	labels := []string{"rule_id", "endpoint_type"}
+
+	return &PointsWriter{
+		PointsWriter:  pw,
+		endpoints:     endpoints,
+		endpointTypes: make(map[string]string),
+
+		sent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "sent_total",
+			Help:      "Number of notifications sent to their endpoint, by notification rule and endpoint type.",
+		}, labels),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "failed_total",
+			Help:      "Number of notifications that failed to be sent to their endpoint, by notification rule and endpoint type.",
+		}, labels),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "latency_seconds",
+			Help:      "The duration in seconds between a status and the notification of it, by notification rule and endpoint type.",
+			// 14 buckets spaced exponentially between 1s and ~2.3 hours.
+			Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+		}, labels),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (w *PointsWriter) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		w.sent,
+		w.failed,
+		w.latency,
+	}
+}
+
+// WritePoints writes points and records the notifications among them once
+// they are written.
+func (w *PointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	if err := w.PointsWriter.WritePoints(ctx, points); err != nil {
+		return err
+	}
+
+	for _, p := range points {
+		w.record(ctx, p)
+	}
+	return nil
+}
+
+// record records the outcome of the notification p is the status timestamp
+// of. Every notification is logged with a single status timestamp field, so
+// that it is counted once.
+func (w *PointsWriter) record(ctx context.Context, p models.Point) {
+	tags := p.Tags()
+	if !bytes.Equal(tags.Get(models.MeasurementTagKeyBytes), notificationsMeasurement) ||
+		!bytes.Equal(tags.Get(models.FieldKeyTagKeyBytes), statusTimestampField) {
+		return
+	}
+	ruleID := tags.Get(ruleIDTag)
+	if len(ruleID) == 0 {
+		return
+	}
+
+	labels := prometheus.Labels{
+		"rule_id":       string(ruleID),
+		"endpoint_type": w.endpointType(ctx, string(tags.Get(endpointIDTag))),
+	}
+	if string(tags.Get(sentTag)) == "true" {
+		w.sent.With(labels).Inc()
+	} else {
+		w.failed.With(labels).Inc()
+	}
+
+	itr := p.FieldIterator()
+	if !itr.Next() || itr.Type() != models.Integer {
+		return
+	}
+	statusTime, err := itr.IntegerValue()
+	if err != nil {
+		return
+	}
+	if d := p.Time().Sub(time.Unix(0, statusTime)); d >= 0 {
+		w.latency.With(labels).Observe(d.Seconds())
+	}
+}
+
+// endpointType returns the type of the endpoint with the ID id.
+func (w *PointsWriter) endpointType(ctx context.Context, id string) string {
+	w.mu.Lock()
+	typ, ok := w.endpointTypes[id]
+	w.mu.Unlock()
+	if ok {
+		return typ
+	}
+
+	endpointID, err := influxdb.IDFromString(id)
+	if err != nil {
+		return unknownEndpointType
+	}
+	edp, err := w.endpoints.FindNotificationEndpointByID(ctx, *endpointID)
+	if err != nil {
+		// Not cached: the endpoint may be found later.
+		return unknownEndpointType
+	}
+	typ = edp.Type()
+
+	w.mu.Lock()
+	w.endpointTypes[id] = typ
+	w.mu.Unlock()
+	return typ
+}
@@ -0,0 +1,116 @@
+package delivery_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/prom"
+	"github.com/influxdata/influxdb/v2/kit/prom/promtest"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/notification/delivery"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap"
+)
+
+const (
+	ruleID     = "020f755c3c082000"
+	endpointID = "020f755c3c082001"
+)
+
+// notification returns the exploded points of a notification logged by a
+// notification rule for a status at statusTime.
+func notification(t *testing.T, sent bool, statusTime, now time.Time) []models.Point {
+	t.Helper()
+
+	sentTag := "false"
+	if sent {
+		sentTag = "true"
+	}
+	pt := models.MustNewPoint("notifications",
+		models.NewTags(map[string]string{
+			"_notification_rule_id":     ruleID,
+			"_notification_endpoint_id": endpointID,
+			"_sent":                     sentTag,
+		}),
+		models.Fields{
+			"_message":          "cpu is high",
+			"_status_timestamp": statusTime.UnixNano(),
+		},
+		now,
+	)
+	points, err := tsdb.ExplodePoints(1, 2, []models.Point{pt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return points
+}
+
+func TestPointsWriter_Metrics(t *testing.T) {
+	endpoints := mock.NewNotificationEndpointService()
+	endpoints.FindNotificationEndpointByIDF = func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+		if id.String() != endpointID {
+			t.Fatalf("unexpected endpoint ID %s", id)
+		}
+		return &endpoint.Slack{}, nil
+	}
+	pw := &mock.PointsWriter{}
+	w := delivery.NewPointsWriter(pw, endpoints)
+	reg := prom.NewRegistry(zap.NewNop())
+	reg.MustRegister(w.PrometheusCollectors()...)
+
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	for _, sent := range []bool{true, true, false} {
+		if err := w.WritePoints(ctx, notification(t, sent, now.Add(-10*time.Second), now)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	labels := map[string]string{"rule_id": ruleID, "endpoint_type": "slack"}
+	mfs := promtest.MustGather(t, reg)
+	m := promtest.MustFindMetric(t, mfs, "notification_delivery_sent_total", labels)
+	if got := m.GetCounter().GetValue(); got != 2 {
+		t.Fatalf("exp 2 notifications sent, got %v", got)
+	}
+	m = promtest.MustFindMetric(t, mfs, "notification_delivery_failed_total", labels)
+	if got := m.GetCounter().GetValue(); got != 1 {
+		t.Fatalf("exp 1 notification failed, got %v", got)
+	}
+	m = promtest.MustFindMetric(t, mfs, "notification_delivery_latency_seconds", labels)
+	if got := m.GetHistogram().GetSampleCount(); got != 3 {
+		t.Fatalf("exp 3 latencies observed, got %v", got)
+	}
+	if got := m.GetHistogram().GetSampleSum(); got != 30 {
+		t.Fatalf("exp total latency of 30s, got %v", got)
+	}
+
+	if got := len(pw.Points); got != 6 {
+		t.Fatalf("exp 6 points written, got %d", got)
+	}
+}
+
+func TestPointsWriter_WriteError(t *testing.T) {
+	pw := &mock.PointsWriter{}
+	pw.ForceError(errors.New("write failed"))
+	w := delivery.NewPointsWriter(pw, mock.NewNotificationEndpointService())
+	reg := prom.NewRegistry(zap.NewNop())
+	reg.MustRegister(w.PrometheusCollectors()...)
+
+	now := time.Unix(1000, 0)
+	if err := w.WritePoints(context.Background(), notification(t, true, now, now)); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// Notifications that were not logged are not recorded.
+	mfs := promtest.MustGather(t, reg)
+	for _, mf := range mfs {
+		if mf.GetName() == "notification_delivery_sent_total" {
+			t.Fatalf("exp no notifications sent, got %v", mf)
+		}
+	}
+}
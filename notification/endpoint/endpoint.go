@@ -51,6 +51,33 @@ func UnmarshalJSON(b []byte) (influxdb.NotificationEndpoint, error) {
 	return converted, nil
 }
 
+// SecretKeyOwner returns the endpoint the secret key k is namespaced by. The
+// keys generated for the secret fields of endpoints, e.g. <id>-token, are
+// prefixed with the ID of their endpoint, and their secrets are owned by it:
+// they are deleted with the endpoint.
+func SecretKeyOwner(k string) (influxdb.ID, bool) {
+	if len(k) <= influxdb.IDLength {
+		return 0, false
+	}
+	var id influxdb.ID
+	if err := id.DecodeFromString(k[:influxdb.IDLength]); err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// OwnedSecretKeys returns the keys of the secret fields of e that are owned
+// by it.
+func OwnedSecretKeys(e influxdb.NotificationEndpoint) []string {
+	var keys []string
+	for _, fld := range e.SecretFields() {
+		if id, ok := SecretKeyOwner(fld.Key); ok && id == e.GetID() {
+			keys = append(keys, fld.Key)
+		}
+	}
+	return keys
+}
+
 // Base is the embed struct of every notification endpoint.
 type Base struct {
 	ID          *influxdb.ID    `json:"id,omitempty"`
@@ -58,6 +85,9 @@ type Base struct {
 	Description string          `json:"description,omitempty"`
 	OrgID       *influxdb.ID    `json:"orgID,omitempty"`
 	Status      influxdb.Status `json:"status"`
+	// Locale is the locale of the catalog messages sent to the endpoint,
+	// e.g. en or pt-BR.
+	Locale string `json:"locale,omitempty"`
 	influxdb.CRUDLog
 }
 
@@ -91,6 +121,11 @@ func (b Base) valid() error {
 			Msg:  "invalid status",
 		}
 	}
+	if b.Locale != "" {
+		if err := influxdb.ValidLocale(b.Locale); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -127,6 +162,11 @@ func (b *Base) GetStatus() influxdb.Status {
 	return b.Status
 }
 
+// GetLocale returns the locale of the catalog messages sent to the endpoint.
+func (b *Base) GetLocale() string {
+	return b.Locale
+}
+
 // SetID will set the primary key.
 func (b *Base) SetID(id influxdb.ID) {
 	b.ID = &id
@@ -8,7 +8,10 @@ import (
 
 var _ influxdb.NotificationEndpoint = &PagerDuty{}
 
-const routingKeySuffix = "-routing-key"
+const (
+	routingKeySuffix = "-routing-key"
+	apiTokenSuffix   = "-api-token"
+)
 
 // PagerDuty is the notification endpoint config of pagerduty.
 type PagerDuty struct {
@@ -18,6 +21,10 @@ type PagerDuty struct {
 	// RoutingKey is a version 4 UUID expressed as a 32-digit hexadecimal number.
 	// This is the Integration Key for an integration on any given service.
 	RoutingKey influxdb.SecretField `json:"routingKey"`
+	// APIToken is an optional PagerDuty REST API key. When it is set, the
+	// acknowledgements and resolutions of incidents in PagerDuty are read
+	// back into the notification history.
+	APIToken influxdb.SecretField `json:"apiToken,omitempty"`
 }
 
 // BackfillSecretKeys fill back fill the secret field key during the unmarshalling
@@ -26,13 +33,20 @@ func (s *PagerDuty) BackfillSecretKeys() {
 	if s.RoutingKey.Key == "" && s.RoutingKey.Value != nil {
 		s.RoutingKey.Key = s.idStr() + routingKeySuffix
 	}
+	if s.APIToken.Key == "" && s.APIToken.Value != nil {
+		s.APIToken.Key = s.idStr() + apiTokenSuffix
+	}
 }
 
 // SecretFields return available secret fields.
 func (s PagerDuty) SecretFields() []influxdb.SecretField {
-	return []influxdb.SecretField{
+	arr := []influxdb.SecretField{
 		s.RoutingKey,
 	}
+	if s.APIToken.Key != "" {
+		arr = append(arr, s.APIToken)
+	}
+	return arr
 }
 
 // Valid returns error if some configuration is invalid
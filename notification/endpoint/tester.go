@@ -0,0 +1,265 @@
+package endpoint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// Default urls of the APIs test events are sent to.
+const (
+	DefaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+	DefaultTelegramAPIURL     = "https://api.telegram.org"
+)
+
+// DefaultTestMessage is the message of a test event without a message.
+const DefaultTestMessage = "This is a test notification from InfluxDB."
+
+// maxTestResponseSize is the size of the response of a provider read to
+// check whether it accepted a test event.
+const maxTestResponseSize = 4 * 1024
+
+// TestEvent is the event sent through an endpoint to test it.
+type TestEvent struct {
+	// Message is the text of the event.
+	Message string `json:"message,omitempty"`
+	// Channel is the slack channel the message is posted to. It is only
+	// required by the chat.postMessage API, incoming webhooks post to their own channel.
+	Channel string `json:"channel,omitempty"`
+}
+
+// TestResult is the response of the provider to a test event. The body of
+// the response is not kept, since the endpoint may point to any url.
+type TestResult struct {
+	// Success is whether the provider accepted the event.
+	Success    bool `json:"success"`
+	StatusCode int  `json:"statusCode"`
+}
+
+// Tester sends test events through notification endpoints with their
+// credentials, independently of any notification rule, so that endpoints
+// can be validated right after they are created.
+type Tester struct {
+	Secrets    influxdb.SecretService
+	HTTPClient *http.Client
+
+	PagerDutyEventsURL string
+	TelegramAPIURL     string
+
+	now func() time.Time
+}
+
+// NewTester returns a tester loading the credentials of endpoints from secrets.
+func NewTester(secrets influxdb.SecretService) *Tester {
+	return &Tester{
+		Secrets:            secrets,
+		HTTPClient:         &http.Client{Timeout: 30 * time.Second},
+		PagerDutyEventsURL: DefaultPagerDutyEventsURL,
+		TelegramAPIURL:     DefaultTelegramAPIURL,
+		now:                time.Now,
+	}
+}
+
+// Test sends ev through edp and returns the response of the provider. A
+// response of the provider rejecting the event is not an error, it is
+// reported by the result.
+func (t *Tester) Test(ctx context.Context, edp influxdb.NotificationEndpoint, ev TestEvent) (*TestResult, error) {
+	if ev.Message == "" {
+		ev.Message = DefaultTestMessage
+	}
+
+	var (
+		req *http.Request
+		err error
+	)
+	switch e := edp.(type) {
+	case *Slack:
+		req, err = t.slackRequest(ctx, e, ev)
+	case *PagerDuty:
+		req, err = t.pagerDutyRequest(ctx, e)
+	case *HTTP:
+		req, err = t.httpRequest(ctx, e, ev)
+	case *Telegram:
+		req, err = t.telegramRequest(ctx, e, ev)
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("testing %s notification endpoints is not supported", edp.Type()),
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t.do(ctx, req)
+}
+
+func (t *Tester) slackRequest(ctx context.Context, e *Slack, ev TestEvent) (*http.Request, error) {
+	token, err := t.loadSecret(ctx, e.GetOrgID(), e.Token)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]string{"text": ev.Message}
+	if ev.Channel != "" {
+		body["channel"] = ev.Channel
+	}
+	req, err := newJSONRequest(http.MethodPost, e.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// pagerDutyRequest resolves an incident that does not exist: PagerDuty
+// validates the routing key of the event without alerting anyone.
+func (t *Tester) pagerDutyRequest(ctx context.Context, e *PagerDuty) (*http.Request, error) {
+	routingKey, err := t.loadSecret(ctx, e.GetOrgID(), e.RoutingKey)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]string{
+		"routing_key":  routingKey,
+		"event_action": "resolve",
+		"dedup_key":    fmt.Sprintf("influxdb-test-%s-%d", e.idStr(), t.now().UnixNano()),
+	}
+	if e.ClientURL != "" {
+		body["client"] = "InfluxDB"
+		body["client_url"] = e.ClientURL
+	}
+	return newJSONRequest(http.MethodPost, t.PagerDutyEventsURL, body)
+}
+
+func (t *Tester) httpRequest(ctx context.Context, e *HTTP, ev TestEvent) (*http.Request, error) {
+	var body io.Reader
+	if e.Method != http.MethodGet {
+		b, err := json.Marshal(map[string]interface{}{
+			"_message":                    ev.Message,
+			"_level":                      "ok",
+			"_notification_endpoint_id":   e.idStr(),
+			"_notification_endpoint_name": e.Name,
+			"_time":                       t.now().UTC().Format(time.RFC3339Nano),
+			"_test":                       true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(e.Method, e.URL, body)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid http endpoint",
+			Err:  err,
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+
+	switch e.AuthMethod {
+	case "basic":
+		username, err := t.loadSecret(ctx, e.GetOrgID(), e.Username)
+		if err != nil {
+			return nil, err
+		}
+		password, err := t.loadSecret(ctx, e.GetOrgID(), e.Password)
+		if err != nil {
+			return nil, err
+		}
+		req.SetBasicAuth(username, password)
+	case "bearer":
+		token, err := t.loadSecret(ctx, e.GetOrgID(), e.Token)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+func (t *Tester) telegramRequest(ctx context.Context, e *Telegram, ev TestEvent) (*http.Request, error) {
+	token, err := t.loadSecret(ctx, e.GetOrgID(), e.Token)
+	if err != nil {
+		return nil, err
+	}
+	return newJSONRequest(http.MethodPost, strings.TrimSuffix(t.TelegramAPIURL, "/")+"/bot"+token+"/sendMessage", map[string]string{
+		"chat_id": e.Channel,
+		"text":    ev.Message,
+	})
+}
+
+func (t *Tester) loadSecret(ctx context.Context, orgID influxdb.ID, f influxdb.SecretField) (string, error) {
+	if f.Key == "" {
+		return "", nil
+	}
+	return t.Secrets.LoadSecret(ctx, orgID, f.Key)
+}
+
+func (t *Tester) do(ctx context.Context, req *http.Request) (*TestResult, error) {
+	resp, err := t.HTTPClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "failed to send test event",
+			Err:  err,
+		}
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxTestResponseSize))
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "failed to read response to test event",
+			Err:  err,
+		}
+	}
+	return &TestResult{
+		Success:    resp.StatusCode/100 == 2 && accepted(body),
+		StatusCode: resp.StatusCode,
+	}, nil
+}
+
+// accepted reports whether the body of a successful response does not
+// reject the event, as the slack and telegram APIs respond with an "ok"
+// field instead of an error status.
+func accepted(body []byte) bool {
+	var v struct {
+		OK *bool `json:"ok"`
+	}
+	if err := json.Unmarshal(body, &v); err != nil || v.OK == nil {
+		return true
+	}
+	return *v.OK
+}
+
+func newJSONRequest(method, url string, v interface{}) (*http.Request, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(b))
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid notification endpoint url",
+			Err:  err,
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
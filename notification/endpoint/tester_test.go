@@ -0,0 +1,140 @@
+package endpoint_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+)
+
+func newTestTester(t *testing.T, secrets map[string]string) *endpoint.Tester {
+	t.Helper()
+	svc := mock.NewSecretService()
+	svc.LoadSecretFn = func(ctx context.Context, orgID influxdb.ID, k string) (string, error) {
+		if orgID != *goodBase.OrgID {
+			t.Fatalf("unexpected org %s", orgID)
+		}
+		v, ok := secrets[k]
+		if !ok {
+			return "", &influxdb.Error{Code: influxdb.ENotFound, Msg: "secret not found"}
+		}
+		return v, nil
+	}
+	return endpoint.NewTester(svc)
+}
+
+// recordServer records the last request it receives and responds with status and body.
+func recordServer(t *testing.T, status int, body string) (*httptest.Server, *http.Request, map[string]interface{}) {
+	var (
+		last    http.Request
+		payload = make(map[string]interface{})
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = *r
+		if r.Body != nil && r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Errorf("unable to decode test event: %v", err)
+			}
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	return srv, &last, payload
+}
+
+func TestTester_Slack(t *testing.T) {
+	srv, req, payload := recordServer(t, http.StatusOK, `{"ok":false,"error":"channel_not_found"}`)
+	defer srv.Close()
+	tester := newTestTester(t, map[string]string{"slack-token": "xoxb"})
+
+	res, err := tester.Test(context.Background(), &endpoint.Slack{
+		Base:  goodBase,
+		URL:   srv.URL,
+		Token: influxdb.SecretField{Key: "slack-token"},
+	}, endpoint.TestEvent{Channel: "#alerts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := req.Header.Get("Authorization"); got != "Bearer xoxb" {
+		t.Errorf("unexpected authorization %q", got)
+	}
+	if payload["channel"] != "#alerts" || payload["text"] != endpoint.DefaultTestMessage {
+		t.Errorf("unexpected test event %v", payload)
+	}
+	if res.Success || res.StatusCode != http.StatusOK {
+		t.Errorf("expected the rejection of slack to be reported, got %+v", res)
+	}
+}
+
+func TestTester_PagerDuty(t *testing.T) {
+	srv, _, payload := recordServer(t, http.StatusAccepted, `{"status":"success","message":"Event processed"}`)
+	defer srv.Close()
+	tester := newTestTester(t, map[string]string{"pd-routing-key": "key"})
+	tester.PagerDutyEventsURL = srv.URL
+
+	res, err := tester.Test(context.Background(), &endpoint.PagerDuty{
+		Base:       goodBase,
+		RoutingKey: influxdb.SecretField{Key: "pd-routing-key"},
+	}, endpoint.TestEvent{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Resolving an incident that does not exist does not page anyone.
+	if payload["routing_key"] != "key" || payload["event_action"] != "resolve" || payload["dedup_key"] == "" {
+		t.Errorf("unexpected test event %v", payload)
+	}
+	if !res.Success || res.StatusCode != http.StatusAccepted {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
+
+func TestTester_HTTP(t *testing.T) {
+	srv, req, payload := recordServer(t, http.StatusOK, "ok")
+	defer srv.Close()
+	tester := newTestTester(t, map[string]string{"user": "u", "pass": "p"})
+
+	res, err := tester.Test(context.Background(), &endpoint.HTTP{
+		Base:       goodBase,
+		URL:        srv.URL,
+		Method:     http.MethodPut,
+		AuthMethod: "basic",
+		Username:   influxdb.SecretField{Key: "user"},
+		Password:   influxdb.SecretField{Key: "pass"},
+		Headers:    map[string]string{"X-Source": "influxdb"},
+	}, endpoint.TestEvent{Message: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Method != http.MethodPut || req.Header.Get("X-Source") != "influxdb" {
+		t.Errorf("unexpected request %s %v", req.Method, req.Header)
+	}
+	if u, p, ok := req.BasicAuth(); !ok || u != "u" || p != "p" {
+		t.Errorf("unexpected basic auth %q %q", u, p)
+	}
+	if payload["_message"] != "hello" || payload["_test"] != true {
+		t.Errorf("unexpected test event %v", payload)
+	}
+	if !res.Success || res.StatusCode != http.StatusOK {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
+
+func TestTester_MissingSecret(t *testing.T) {
+	tester := newTestTester(t, nil)
+	_, err := tester.Test(context.Background(), &endpoint.Telegram{
+		Base:    goodBase,
+		Token:   influxdb.SecretField{Key: "telegram-token"},
+		Channel: "channel",
+	}, endpoint.TestEvent{})
+	if influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected the missing secret to be reported, got %v", err)
+	}
+}
@@ -38,6 +38,15 @@ func Equal(lhs, rhs ast.Expression) *ast.BinaryExpression {
 	}
 }
 
+// NotEqual returns a not equal to *ast.BinaryExpression.
+func NotEqual(lhs, rhs ast.Expression) *ast.BinaryExpression {
+	return &ast.BinaryExpression{
+		Operator: ast.NotEqualOperator,
+		Left:     lhs,
+		Right:    rhs,
+	}
+}
+
 // Subtract returns a subtraction *ast.BinaryExpression.
 func Subtract(lhs, rhs ast.Expression) *ast.BinaryExpression {
 	return &ast.BinaryExpression{
@@ -82,6 +91,14 @@ func Or(lhs, rhs ast.Expression) *ast.LogicalExpression {
 	}
 }
 
+// Not returns a not *ast.UnaryExpression.
+func Not(e ast.Expression) *ast.UnaryExpression {
+	return &ast.UnaryExpression{
+		Operator: ast.NotOperator,
+		Argument: e,
+	}
+}
+
 // If returns an *ast.ConditionalExpression
 func If(test, consequent, alternate ast.Expression) *ast.ConditionalExpression {
 	return &ast.ConditionalExpression{
@@ -0,0 +1,136 @@
+package message
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixNotificationMessages is the prefix of the notification message
+	// catalog API.
+	PrefixNotificationMessages = "/api/v2/notificationMessages"
+)
+
+// Handler is the HTTP API handler for the notification message catalogs of
+// organizations.
+type Handler struct {
+	chi.Router
+	api        *kithttp.API
+	log        *zap.Logger
+	catalogSvc influxdb.NotificationMessageCatalogService
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, catalogSvc influxdb.NotificationMessageCatalogService) *Handler {
+	h := &Handler{
+		api:        kithttp.NewAPI(kithttp.WithLog(log)),
+		log:        log,
+		catalogSvc: catalogSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Get("/", h.handleGetCatalog)
+		r.Put("/", h.handlePutCatalog)
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixNotificationMessages
+}
+
+type catalogResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.NotificationMessageCatalog
+}
+
+func newCatalogResponse(c *influxdb.NotificationMessageCatalog) *catalogResponse {
+	return &catalogResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("%s?orgID=%s", PrefixNotificationMessages, c.OrgID),
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", c.OrgID),
+		},
+		NotificationMessageCatalog: c,
+	}
+}
+
+type putCatalogRequest struct {
+	DefaultLocale string                         `json:"defaultLocale"`
+	Messages      []influxdb.NotificationMessage `json:"messages"`
+}
+
+func decodeOrgID(r *http.Request) (influxdb.ID, error) {
+	v := r.URL.Query().Get("orgID")
+	if v == "" {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID is required",
+		}
+	}
+	id, err := influxdb.IDFromString(v)
+	if err != nil {
+		return 0, influxdb.ErrCorruptID(err)
+	}
+	return *id, nil
+}
+
+func (h *Handler) handleGetCatalog(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	c, err := h.catalogSvc.FindNotificationMessageCatalog(r.Context(), orgID)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newCatalogResponse(c))
+}
+
+func (h *Handler) handlePutCatalog(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var req putCatalogRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	c := &influxdb.NotificationMessageCatalog{
+		OrgID:         orgID,
+		DefaultLocale: req.DefaultLocale,
+		Messages:      req.Messages,
+	}
+	if c.Messages == nil {
+		c.Messages = []influxdb.NotificationMessage{}
+	}
+	if err := h.catalogSvc.PutNotificationMessageCatalog(r.Context(), c); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Notification messages updated", zap.String("orgID", orgID.String()), zap.Int("messages", len(c.Messages)))
+
+	h.api.Respond(w, r, http.StatusOK, newCatalogResponse(c))
+}
@@ -0,0 +1,112 @@
+// Package pagerduty reads the acknowledgements and resolutions of PagerDuty
+// incidents back into the notification history, closing the loop between
+// the events sent by notification rules and what happened to them.
+package pagerduty
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultAPIURL is the url of the PagerDuty REST API.
+const DefaultAPIURL = "https://api.pagerduty.com"
+
+// Log entry types of the incident changes read back from PagerDuty.
+const (
+	AcknowledgeLogEntry = "acknowledge_log_entry"
+	ResolveLogEntry     = "resolve_log_entry"
+)
+
+const pageLimit = 100
+
+// LogEntry is a change made to an incident.
+type LogEntry struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Agent     struct {
+		Summary string `json:"summary"`
+	} `json:"agent"`
+	Incident Incident `json:"incident"`
+}
+
+// Incident is a PagerDuty incident.
+type Incident struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	// IncidentKey is the dedup key of the events of the incident.
+	IncidentKey string `json:"incident_key"`
+}
+
+// Client reads from the PagerDuty REST API.
+type Client struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client of the REST API at apiURL.
+func NewClient(apiURL string) *Client {
+	return &Client{
+		URL:        apiURL,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ListLogEntries returns the acknowledgements and resolutions of incidents
+// made between since and until.
+func (c *Client) ListLogEntries(ctx context.Context, token string, since, until time.Time) ([]LogEntry, error) {
+	var entries []LogEntry
+	for offset := 0; ; offset += pageLimit {
+		var page struct {
+			LogEntries []LogEntry `json:"log_entries"`
+			More       bool       `json:"more"`
+		}
+		q := url.Values{
+			"since":       {since.UTC().Format(time.RFC3339)},
+			"until":       {until.UTC().Format(time.RFC3339)},
+			"include[]":   {"incidents"},
+			"is_overview": {"false"},
+			"limit":       {strconv.Itoa(pageLimit)},
+			"offset":      {strconv.Itoa(offset)},
+		}
+		if err := c.get(ctx, token, "/log_entries?"+q.Encode(), &page); err != nil {
+			return nil, err
+		}
+
+		for _, e := range page.LogEntries {
+			if e.Type == AcknowledgeLogEntry || e.Type == ResolveLogEntry {
+				entries = append(entries, e)
+			}
+		}
+		if !page.More {
+			return entries, nil
+		}
+	}
+}
+
+func (c *Client) get(ctx context.Context, token, path string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, c.URL+path, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/vnd.pagerduty+json;version=2")
+	req.Header.Set("Authorization", "Token token="+token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("pagerduty API responded with %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
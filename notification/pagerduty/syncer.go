@@ -0,0 +1,169 @@
+package pagerduty
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap"
+)
+
+// DefaultSyncInterval is how often incidents are read back from PagerDuty.
+const DefaultSyncInterval = time.Minute
+
+const (
+	notificationsMeasurement = "notifications"
+
+	endpointIDTag   = "_notification_endpoint_id"
+	endpointNameTag = "_notification_endpoint_name"
+	statusTag       = "_pagerduty_status"
+
+	incidentIDField = "_pagerduty_incident_id"
+	dedupKeyField   = "_pagerduty_dedup_key"
+	agentField      = "_pagerduty_agent"
+	messageField    = "_message"
+)
+
+var entryStatus = map[string]string{
+	AcknowledgeLogEntry: "acknowledged",
+	ResolveLogEntry:     "resolved",
+}
+
+// Syncer periodically records the acknowledgements and resolutions of the
+// incidents of pagerduty endpoints with an API token in the notifications
+// of the _monitoring bucket of their organization.
+type Syncer struct {
+	log       *zap.Logger
+	endpoints influxdb.NotificationEndpointService
+	secrets   influxdb.SecretService
+	buckets   influxdb.BucketService
+	sb        influxdb.SystemBuckets
+	pw        storage.PointsWriter
+	client    *Client
+	interval  time.Duration
+	now       func() time.Time
+
+	// synced is when the incidents of an endpoint were last read.
+	synced map[influxdb.ID]time.Time
+}
+
+// NewSyncer returns a syncer of the incidents of pagerduty endpoints.
+func NewSyncer(log *zap.Logger, endpoints influxdb.NotificationEndpointService, secrets influxdb.SecretService, buckets influxdb.BucketService, sb influxdb.SystemBuckets, pw storage.PointsWriter, interval time.Duration) *Syncer {
+	return &Syncer{
+		log:       log,
+		endpoints: endpoints,
+		secrets:   secrets,
+		buckets:   buckets,
+		sb:        sb,
+		pw:        pw,
+		client:    NewClient(DefaultAPIURL),
+		interval:  interval,
+		now:       time.Now,
+		synced:    make(map[influxdb.ID]time.Time),
+	}
+}
+
+// Run syncs incidents until ctx is canceled.
+func (s *Syncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sync(ctx)
+		}
+	}
+}
+
+func (s *Syncer) sync(ctx context.Context) {
+	edps, _, err := s.endpoints.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
+		UserResourceMappingFilter: influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.NotificationEndpointResourceType,
+		},
+	})
+	if err != nil {
+		s.log.Error("Failed to find notification endpoints", zap.Error(err))
+		return
+	}
+
+	for _, edp := range edps {
+		pd, ok := edp.(*endpoint.PagerDuty)
+		if !ok || pd.APIToken.Key == "" || pd.Status != influxdb.Active {
+			continue
+		}
+		if err := s.syncEndpoint(ctx, pd); err != nil {
+			s.log.Error("Failed to sync pagerduty incidents", zap.Stringer("endpointID", pd.ID), zap.Error(err))
+		}
+	}
+}
+
+func (s *Syncer) syncEndpoint(ctx context.Context, e *endpoint.PagerDuty) error {
+	now := s.now()
+	since, ok := s.synced[*e.ID]
+	if !ok {
+		since = now.Add(-s.interval)
+	}
+
+	token, err := s.secrets.LoadSecret(ctx, *e.OrgID, e.APIToken.Key)
+	if err != nil {
+		return err
+	}
+	entries, err := s.client.ListLogEntries(ctx, token, since, now)
+	if err != nil {
+		return err
+	}
+
+	var points models.Points
+	for _, entry := range entries {
+		// Only incidents opened by events have a dedup key.
+		if entry.Incident.IncidentKey == "" {
+			continue
+		}
+		p, err := models.NewPoint(notificationsMeasurement,
+			models.NewTags(map[string]string{
+				endpointIDTag:   e.ID.String(),
+				endpointNameTag: e.Name,
+				statusTag:       entryStatus[entry.Type],
+			}),
+			models.Fields{
+				incidentIDField: entry.Incident.ID,
+				dedupKeyField:   entry.Incident.IncidentKey,
+				agentField:      entry.Agent.Summary,
+				messageField:    entry.Incident.Title,
+			},
+			entry.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		points = append(points, p)
+	}
+
+	if len(points) > 0 {
+		if err := s.write(ctx, *e.OrgID, points); err != nil {
+			return err
+		}
+	}
+	s.synced[*e.ID] = now
+	return nil
+}
+
+func (s *Syncer) write(ctx context.Context, orgID influxdb.ID, points models.Points) error {
+	b, err := s.buckets.FindBucketByName(ctx, orgID, s.sb.MonitoringName)
+	if err != nil {
+		return err
+	}
+
+	exploded, err := tsdb.ExplodePoints(orgID, b.ID, points)
+	if err != nil {
+		return err
+	}
+	return s.pw.WritePoints(ctx, exploded)
+}
@@ -0,0 +1,104 @@
+package pagerduty
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const logEntries = `{
+	"log_entries": [
+		{
+			"id": "L1",
+			"type": "acknowledge_log_entry",
+			"created_at": "2020-06-01T11:59:30Z",
+			"agent": {"summary": "Alice"},
+			"incident": {"id": "I1", "title": "cpu is crit", "status": "acknowledged", "incident_key": "abc"}
+		},
+		{
+			"id": "L2",
+			"type": "annotate_log_entry",
+			"created_at": "2020-06-01T11:59:40Z",
+			"incident": {"id": "I1", "incident_key": "abc"}
+		},
+		{
+			"id": "L3",
+			"type": "resolve_log_entry",
+			"created_at": "2020-06-01T11:59:50Z",
+			"agent": {"summary": "Bob"},
+			"incident": {"id": "I2", "title": "disk is full", "status": "resolved"}
+		}
+	],
+	"more": false
+}`
+
+func TestSyncer(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	orgID, edpID, bucketID := influxdb.ID(1), influxdb.ID(2), influxdb.ID(3)
+
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/log_entries", r.URL.Path)
+		assert.Equal(t, "Token token=secret-token", r.Header.Get("Authorization"))
+		query = r.URL.RawQuery
+		w.Write([]byte(logEntries))
+	}))
+	defer srv.Close()
+
+	endpoints := mock.NewNotificationEndpointService()
+	endpoints.FindNotificationEndpointsF = func(ctx context.Context, filter influxdb.NotificationEndpointFilter, opt ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+		return []influxdb.NotificationEndpoint{
+			&endpoint.PagerDuty{
+				Base:     endpoint.Base{ID: &edpID, OrgID: &orgID, Name: "pd", Status: influxdb.Active},
+				APIToken: influxdb.SecretField{Key: "api-token"},
+			},
+			// Endpoints without an API token are not synced.
+			&endpoint.PagerDuty{
+				Base: endpoint.Base{ID: &bucketID, OrgID: &orgID, Name: "pd2", Status: influxdb.Active},
+			},
+		}, 2, nil
+	}
+	secrets := &mock.SecretService{
+		LoadSecretFn: func(ctx context.Context, id influxdb.ID, k string) (string, error) {
+			require.Equal(t, "api-token", k)
+			return "secret-token", nil
+		},
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByNameFn = func(ctx context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+		require.Equal(t, influxdb.MonitoringSystemBucketName, name)
+		return &influxdb.Bucket{ID: bucketID, OrgID: id, Name: name}, nil
+	}
+	pw := &mock.PointsWriter{}
+
+	s := NewSyncer(zaptest.NewLogger(t), endpoints, secrets, buckets, influxdb.DefaultSystemBuckets(), pw, time.Minute)
+	s.client = NewClient(srv.URL)
+	s.now = func() time.Time { return now }
+
+	s.sync(context.Background())
+	assert.Contains(t, query, "since=2020-06-01T11%3A59%3A00Z")
+	assert.Contains(t, query, "until=2020-06-01T12%3A00%3A00Z")
+	assert.Equal(t, now, s.synced[edpID])
+
+	// Only the acknowledgement of the incident opened by an event is recorded,
+	// exploded into a point per field.
+	require.Len(t, pw.Points, 4)
+	for _, p := range pw.Points {
+		assert.Equal(t, "acknowledged", string(p.Tags().Get([]byte(statusTag))))
+		assert.Equal(t, edpID.String(), string(p.Tags().Get([]byte(endpointIDTag))))
+		assert.Equal(t, time.Date(2020, 6, 1, 11, 59, 30, 0, time.UTC), p.Time().UTC())
+	}
+
+	s.now = func() time.Time { return now.Add(time.Minute) }
+	s.sync(context.Background())
+	assert.Contains(t, query, "since=2020-06-01T12%3A00%3A00Z", "syncs continue where the last one ended")
+}
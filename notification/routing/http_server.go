@@ -0,0 +1,130 @@
+package routing
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixNotificationRouting is the prefix of the notification routing API.
+	PrefixNotificationRouting = "/api/v2/notificationRouting"
+)
+
+// Handler is the HTTP API handler for the notification routing tables of
+// organizations.
+type Handler struct {
+	chi.Router
+	api        *kithttp.API
+	log        *zap.Logger
+	routingSvc influxdb.NotificationRoutingService
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, routingSvc influxdb.NotificationRoutingService) *Handler {
+	h := &Handler{
+		api:        kithttp.NewAPI(kithttp.WithLog(log)),
+		log:        log,
+		routingSvc: routingSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Get("/", h.handleGetRoutingTable)
+		r.Put("/", h.handlePutRoutingTable)
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixNotificationRouting
+}
+
+type routingTableResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.NotificationRoutingTable
+}
+
+func newRoutingTableResponse(t *influxdb.NotificationRoutingTable) *routingTableResponse {
+	return &routingTableResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("%s?orgID=%s", PrefixNotificationRouting, t.OrgID),
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", t.OrgID),
+		},
+		NotificationRoutingTable: t,
+	}
+}
+
+type putRoutingTableRequest struct {
+	Routes []influxdb.NotificationRoute `json:"routes"`
+}
+
+func decodeOrgID(r *http.Request) (influxdb.ID, error) {
+	v := r.URL.Query().Get("orgID")
+	if v == "" {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID is required",
+		}
+	}
+	id, err := influxdb.IDFromString(v)
+	if err != nil {
+		return 0, influxdb.ErrCorruptID(err)
+	}
+	return *id, nil
+}
+
+func (h *Handler) handleGetRoutingTable(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	t, err := h.routingSvc.FindNotificationRoutingTable(r.Context(), orgID)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newRoutingTableResponse(t))
+}
+
+func (h *Handler) handlePutRoutingTable(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var req putRoutingTableRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	t := &influxdb.NotificationRoutingTable{OrgID: orgID, Routes: req.Routes}
+	if t.Routes == nil {
+		t.Routes = []influxdb.NotificationRoute{}
+	}
+	if err := h.routingSvc.PutNotificationRoutingTable(r.Context(), t); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Notification routing updated", zap.String("orgID", orgID.String()), zap.Int("routes", len(t.Routes)))
+
+	h.api.Respond(w, r, http.StatusOK, newRoutingTableResponse(t))
+}
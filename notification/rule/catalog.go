@@ -0,0 +1,51 @@
+package rule
+
+import (
+	"github.com/influxdata/influxdb/v2"
+)
+
+// LocalizedRule is a notification rule whose message can be a message of
+// the message catalog of its organization.
+type LocalizedRule interface {
+	influxdb.NotificationRule
+	// CatalogMessage returns the name of the message of the rule in the
+	// catalog, or "" if the rule sends its own message template.
+	CatalogMessage() string
+	// SetMessageCatalog sets the catalog the message of the rule is
+	// resolved from when its flux is generated.
+	SetMessageCatalog(c *influxdb.NotificationMessageCatalog)
+}
+
+// CatalogMessage returns the name of the message of the rule in the catalog
+// of its organization, if any.
+func (b *Base) CatalogMessage() string {
+	return b.Message
+}
+
+// SetMessageCatalog sets the catalog the message of the rule is resolved
+// from.
+func (b *Base) SetMessageCatalog(c *influxdb.NotificationMessageCatalog) {
+	b.Catalog = c
+}
+
+// localizer is a notification endpoint with a locale.
+type localizer interface {
+	GetLocale() string
+}
+
+// messageTemplate returns the template of the catalog message of the rule in
+// the locale of e, or else of the rule, or tmpl if the rule has no catalog
+// message or its catalog is not set.
+func (b *Base) messageTemplate(tmpl string, e influxdb.NotificationEndpoint) string {
+	if b.Message == "" || b.Catalog == nil {
+		return tmpl
+	}
+	var locale string
+	if l, ok := e.(localizer); ok {
+		locale = l.GetLocale()
+	}
+	if t, ok := b.Catalog.Template(b.Message, locale, b.Locale); ok {
+		return t
+	}
+	return tmpl
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification"
 	"github.com/influxdata/influxdb/v2/notification/endpoint"
 	"github.com/influxdata/influxdb/v2/notification/flux"
 )
@@ -14,6 +15,9 @@ import (
 type PagerDuty struct {
 	Base
 	MessageTemplate string `json:"messageTemplate"`
+	// AutoResolve sends a resolve event when a status returns to ok, so the
+	// incident opened for it in PagerDuty is closed.
+	AutoResolve bool `json:"autoResolve,omitempty"`
 }
 
 type pagerDutyAlias PagerDuty
@@ -35,13 +39,13 @@ func (s PagerDuty) Valid() error {
 	if err := s.Base.valid(); err != nil {
 		return err
 	}
-	if s.MessageTemplate == "" {
+	if s.MessageTemplate == "" && s.Message == "" {
 		return &influxdb.Error{
 			Code: influxdb.EInvalid,
 			Msg:  "pagerduty invalid message template",
 		}
 	}
-	return nil
+	return ValidMessageTemplate(s.MessageTemplate)
 }
 
 // Type returns the type of the rule config.
@@ -79,8 +83,8 @@ func (s *PagerDuty) generateFluxASTBody(e *endpoint.PagerDuty) []ast.Statement {
 	statements = append(statements, s.generateFluxASTEndpoint(e))
 	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
 	statements = append(statements, s.generateFluxASTStatuses())
-	statements = append(statements, s.generateLevelChecks()...)
-	statements = append(statements, s.generateFluxASTNotifyPipe(e.ClientURL))
+	statements = append(statements, s.levelChecksBase().generateLevelChecks()...)
+	statements = append(statements, s.generateFluxASTNotifyPipe(e))
 
 	return statements
 }
@@ -99,7 +103,7 @@ func (s *PagerDuty) generateFluxASTEndpoint(e *endpoint.PagerDuty) ast.Statement
 	return flux.DefineVariable("pagerduty_endpoint", call)
 }
 
-func (s *PagerDuty) generateFluxASTNotifyPipe(url string) ast.Statement {
+func (s *PagerDuty) generateFluxASTNotifyPipe(e *endpoint.PagerDuty) ast.Statement {
 	endpointProps := []*ast.Property{}
 
 	// routing_key:
@@ -118,7 +122,7 @@ func (s *PagerDuty) generateFluxASTNotifyPipe(url string) ast.Statement {
 	// optional
 	// string
 	// url of the client sending the alert.
-	endpointProps = append(endpointProps, flux.Property("clientURL", flux.String(url)))
+	endpointProps = append(endpointProps, flux.Property("clientURL", flux.String(e.ClientURL)))
 
 	// class:
 	// optional
@@ -154,7 +158,13 @@ func (s *PagerDuty) generateFluxASTNotifyPipe(url string) ast.Statement {
 	// required
 	// string
 	// A brief text summary of the event, used to generate the summaries/titles of any associated alerts. The maximum permitted length of this property is 1024 characters.
-	endpointProps = append(endpointProps, flux.Property("summary", flux.Member("r", "_message")))
+	// The summary is the message of the status, unless the rule sends a
+	// message of the catalog of its organization.
+	var summary ast.Expression = flux.Member("r", "_message")
+	if tmpl := s.messageTemplate("", e); tmpl != "" {
+		summary = s.generateMessageTemplate(tmpl)
+	}
+	endpointProps = append(endpointProps, flux.Property("summary", summary))
 
 	// timestamp:
 	// optional
@@ -164,16 +174,69 @@ func (s *PagerDuty) generateFluxASTNotifyPipe(url string) ast.Statement {
 
 	endpointFn := flux.Function(flux.FunctionParams("r"), flux.Object(endpointProps...))
 
+	var notifyEndpoint ast.Expression = flux.Call(flux.Identifier("pagerduty_endpoint"), flux.Object(flux.Property("mapFn", endpointFn)))
+	if s.AutoResolve {
+		notifyEndpoint = levelIndependentDedupKey(notifyEndpoint)
+	}
+
 	props := []*ast.Property{}
 	props = append(props, flux.Property("data", flux.Identifier("notification")))
-	props = append(props, flux.Property("endpoint",
-		flux.Call(flux.Identifier("pagerduty_endpoint"), flux.Object(flux.Property("mapFn", endpointFn)))))
+	props = append(props, flux.Property("endpoint", notifyEndpoint))
 
 	call := flux.Call(flux.Member("monitor", "notify"), flux.Object(props...))
 
 	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), call))
 }
 
+// levelChecksBase returns the base whose status rules are notified. Rules
+// that resolve incidents are also notified of every change to ok.
+func (s *PagerDuty) levelChecksBase() *Base {
+	if !s.AutoResolve {
+		return &s.Base
+	}
+	for _, r := range s.StatusRules {
+		if r.CurrentLevel == notification.Ok && r.PreviousLevel != nil && *r.PreviousLevel == notification.Any {
+			return &s.Base
+		}
+	}
+
+	b := s.Base
+	anyLevel := notification.Any
+	b.StatusRules = append(append([]notification.StatusRule{}, s.StatusRules...), notification.StatusRule{
+		CurrentLevel:  notification.Ok,
+		PreviousLevel: &anyLevel,
+	})
+	return &b
+}
+
+// levelIndependentDedupKey wraps a pagerduty endpoint so the dedup key of an
+// event does not depend on its level. The dedup key is derived from the
+// group key of the statuses, which includes _level, so without it the resolve
+// event of an ok status would not match the incident triggered by the crit
+// status before it. _level is added back to the group key afterwards so the
+// notifications are recorded as before.
+func levelIndependentDedupKey(e ast.Expression) ast.Expression {
+	tables := &ast.Property{Key: flux.Identifier("tables"), Value: &ast.PipeLiteral{}}
+	return flux.Function([]*ast.Property{tables}, flux.Pipe(
+		flux.Identifier("tables"),
+		flux.Call(flux.Identifier("duplicate"), flux.Object(
+			flux.Property("column", flux.String("_level")),
+			flux.Property("as", flux.String("_pagerduty_level")),
+		)),
+		flux.Call(flux.Identifier("drop"), flux.Object(
+			flux.Property("columns", flux.Array(flux.String("_level"))),
+		)),
+		flux.Call(flux.Identifier("rename"), flux.Object(
+			flux.Property("columns", flux.Object(flux.Property("_pagerduty_level", flux.String("_level")))),
+		)),
+		&ast.CallExpression{Callee: e},
+		flux.Call(flux.Member("experimental", "group"), flux.Object(
+			flux.Property("mode", flux.String("extend")),
+			flux.Property("columns", flux.Array(flux.String("_level"))),
+		)),
+	))
+}
+
 func severityFromLevel() *ast.CallExpression {
 	return flux.Call(
 		flux.Member("pagerduty", "severityFromLevel"),
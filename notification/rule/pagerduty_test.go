@@ -1,6 +1,7 @@
 package rule_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/andreyvit/diff"
@@ -291,3 +292,49 @@ all_statuses
 	}
 
 }
+
+func TestPagerDuty_GenerateFlux_autoResolve(t *testing.T) {
+	e := &endpoint.PagerDuty{
+		Base: endpoint.Base{
+			ID:   idPtr(2),
+			Name: "foo",
+		},
+		RoutingKey: influxdb.SecretField{
+			Key: "pagerduty_token",
+		},
+	}
+	r := &rule.PagerDuty{
+		MessageTemplate: "blah",
+		AutoResolve:     true,
+		Base: rule.Base{
+			ID:         1,
+			EndpointID: 2,
+			Name:       "foo",
+			Every:      mustDuration("1h"),
+			StatusRules: []notification.StatusRule{
+				{
+					CurrentLevel: notification.Critical,
+				},
+			},
+		},
+	}
+
+	script, err := r.GenerateFlux(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`any_to_ok = statuses`,
+		`monitor["stateChanges"](fromLevel: "any", toLevel: "ok")`,
+		`duplicate(column: "_level", as: "_pagerduty_level")`,
+		`experimental["group"](mode: "extend", columns: ["_level"])`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script does not contain %q:\n%s", want, script)
+		}
+	}
+	if len(r.StatusRules) != 1 {
+		t.Errorf("generating flux must not change the status rules of the rule, got %d", len(r.StatusRules))
+	}
+}
@@ -0,0 +1,67 @@
+package rule
+
+import (
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+)
+
+// Route is a route of the routing table of an organization with its endpoint.
+type Route struct {
+	Matchers []influxdb.TagRule
+	Endpoint influxdb.NotificationEndpoint
+}
+
+// RoutedRule is a notification rule whose statuses can be routed to the
+// endpoints of the routing table of its organization.
+type RoutedRule interface {
+	influxdb.NotificationRule
+	// IsRouted returns true if the statuses of the rule are routed.
+	IsRouted() bool
+	// GenerateRoutedFlux generates a flux script sending the statuses to
+	// the endpoint of the first of routes they match, or else to e.
+	GenerateRoutedFlux(e influxdb.NotificationEndpoint, routes []Route) (string, error)
+}
+
+// routeMatchesAST returns the expression testing whether a status matches
+// all of the matchers of route.
+func routeMatchesAST(route Route) ast.Expression {
+	var body ast.Expression
+	for _, m := range route.Matchers {
+		var e ast.Expression = flux.Equal(flux.Member("r", m.Key), flux.String(m.Value))
+		if m.Operator == influxdb.NotEqual {
+			e = flux.NotEqual(flux.Member("r", m.Key), flux.String(m.Value))
+		}
+		if body == nil {
+			body = e
+			continue
+		}
+		body = flux.And(body, e)
+	}
+	return body
+}
+
+// routeFilters returns the filters of the statuses routed to each of routes
+// and, last, of the statuses matching none of them. A status is routed to
+// the first route it matches.
+func routeFilters(routes []Route) []*ast.CallExpression {
+	filter := func(body ast.Expression) *ast.CallExpression {
+		return flux.Call(flux.Identifier("filter"), flux.Object(
+			flux.Property("fn", flux.Function(flux.FunctionParams("r"), body)),
+		))
+	}
+
+	var filters []*ast.CallExpression
+	var unmatched ast.Expression
+	for _, route := range routes {
+		matches := routeMatchesAST(route)
+		if unmatched == nil {
+			filters = append(filters, filter(matches))
+			unmatched = flux.Not(matches)
+			continue
+		}
+		filters = append(filters, filter(flux.And(unmatched, matches)))
+		unmatched = flux.And(unmatched, flux.Not(matches))
+	}
+	return append(filters, filter(unmatched))
+}
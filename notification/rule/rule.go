@@ -54,10 +54,24 @@ type Base struct {
 	Every      *notification.Duration `json:"every,omitempty"`
 	// Offset represents a delay before execution.
 	// It gets marshalled from a string duration, i.e.: "10s" is 10 seconds
-	Offset      *notification.Duration    `json:"offset,omitempty"`
-	RunbookLink string                    `json:"runbookLink"`
-	TagRules    []notification.TagRule    `json:"tagRules,omitempty"`
-	StatusRules []notification.StatusRule `json:"statusRules,omitempty"`
+	Offset *notification.Duration `json:"offset,omitempty"`
+	// StatusLookback is how far back the statuses are read to find the
+	// previous level of a series. The checks with a status heartbeat persist
+	// only the changes of levels, so it should not be less than their
+	// heartbeat for the rule to see their state changes.
+	StatusLookback *notification.Duration    `json:"statusLookback,omitempty"`
+	RunbookLink    string                    `json:"runbookLink"`
+	TagRules       []notification.TagRule    `json:"tagRules,omitempty"`
+	StatusRules    []notification.StatusRule `json:"statusRules,omitempty"`
+	// Message names a message of the message catalog of the organization
+	// sent instead of the message template of the rule, in the locale of
+	// each endpoint.
+	Message string `json:"message,omitempty"`
+	// Locale is the locale of the message for the endpoints without one.
+	Locale string `json:"locale,omitempty"`
+	// Catalog is the message catalog Message is resolved from when the flux
+	// of the rule is generated. It is not stored with the rule.
+	Catalog *influxdb.NotificationMessageCatalog `json:"-"`
 	*influxdb.Limit
 	influxdb.CRUDLog
 }
@@ -99,6 +113,23 @@ func (b Base) valid() error {
 			Msg:  "Offset should not be equal or greater than the interval",
 		}
 	}
+	if b.StatusLookback != nil && len(b.StatusLookback.Values) == 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Notification Rule StatusLookback can't be empty",
+		}
+	}
+	if b.StatusLookback != nil && b.Every != nil && b.StatusLookback.TimeDuration() <= b.Every.TimeDuration() {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "StatusLookback should be greater than the interval",
+		}
+	}
+	if b.Locale != "" {
+		if err := influxdb.ValidLocale(b.Locale); err != nil {
+			return err
+		}
+	}
 	for _, tagRule := range b.TagRules {
 		if err := tagRule.Valid(); err != nil {
 			return err
@@ -124,6 +155,17 @@ func (b *Base) generateFluxASTNotificationDefinition(e influxdb.NotificationEndp
 	return flux.DefineVariable("notification", flux.Object(ruleID, ruleName, endpointID, endpointName))
 }
 
+// generateFluxASTRouteNotificationDefinition defines the notification of the
+// statuses routed to e, suffixed with suffix.
+func (b *Base) generateFluxASTRouteNotificationDefinition(e influxdb.NotificationEndpoint, suffix string) ast.Statement {
+	ruleID := flux.Property("_notification_rule_id", flux.String(b.ID.String()))
+	ruleName := flux.Property("_notification_rule_name", flux.String(b.Name))
+	endpointID := flux.Property("_notification_endpoint_id", flux.String(e.GetID().String()))
+	endpointName := flux.Property("_notification_endpoint_name", flux.String(e.GetName()))
+
+	return flux.DefineVariable("notification"+suffix, flux.Object(ruleID, ruleName, endpointID, endpointName))
+}
+
 func (b *Base) generateLevelChecks() []ast.Statement {
 	stmts := []ast.Statement{}
 	tables := []ast.Expression{}
@@ -289,8 +331,13 @@ func (b *Base) generateTaskOption() ast.Statement {
 func (b *Base) generateFluxASTStatuses() ast.Statement {
 	props := []*ast.Property{}
 
-	dur := (*ast.DurationLiteral)(b.Every)
-	props = append(props, flux.Property("start", flux.Negative(increaseDur(dur))))
+	dur := increaseDur((*ast.DurationLiteral)(b.Every))
+	if b.StatusLookback != nil {
+		// The statuses older than the interval are only read to find the
+		// previous levels, the statuses notified are filtered by time.
+		dur = (*ast.DurationLiteral)(b.StatusLookback)
+	}
+	props = append(props, flux.Property("start", flux.Negative(dur)))
 
 	if len(b.TagRules) > 0 {
 		r := b.TagRules[0]
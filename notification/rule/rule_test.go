@@ -119,6 +119,24 @@ func TestValidRule(t *testing.T) {
 				Msg:  "Offset should not be equal or greater than the interval",
 			},
 		},
+		{
+			name: "status lookback less than interval",
+			src: &rule.Slack{
+				Base: rule.Base{
+					ID:             influxTesting.MustIDBase16(id1),
+					Name:           "name1",
+					OwnerID:        influxTesting.MustIDBase16(id2),
+					OrgID:          influxTesting.MustIDBase16(id3),
+					EndpointID:     1,
+					Every:          mustDuration("1m"),
+					StatusLookback: mustDuration("30s"),
+				},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "StatusLookback should be greater than the interval",
+			},
+		},
 		{
 			name: "empty slack message",
 			src: &rule.Slack{
@@ -3,9 +3,12 @@ package rule
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification"
 	"github.com/influxdata/influxdb/v2/notification/endpoint"
 	"github.com/influxdata/influxdb/v2/notification/flux"
 )
@@ -15,14 +18,43 @@ type Slack struct {
 	Base
 	Channel         string `json:"channel"`
 	MessageTemplate string `json:"messageTemplate"`
+	// Mentions are prepended to the messages of statuses by level, e.g.
+	// {"crit": "@here", "warn": "@U024BE7LH"}.
+	Mentions map[string]string `json:"mentions,omitempty"`
+	// BlockKit formats messages with Slack Block Kit instead of plain text.
+	BlockKit bool `json:"blockKit,omitempty"`
+	// Threaded posts the updates of the alert of a check as replies in the
+	// thread of the message of its first status, until it is ok again. The
+	// messages are posted with the Slack Web API, so the endpoint must have a
+	// token.
+	Threaded bool `json:"threaded,omitempty"`
+	// Routed sends the statuses to the endpoints of the routing table of the
+	// organization by their tags, and only the unrouted ones to EndpointID.
+	Routed bool `json:"routed,omitempty"`
 }
 
+var _ RoutedRule = (*Slack)(nil)
+
+const (
+	// slackThreadTSColumn is the column of the notifications of a threaded
+	// rule recording the ts of the thread of the alert of their check, or
+	// an empty string once it is ok.
+	slackThreadTSColumn = "_slack_thread_ts"
+
+	// slackThreadLookback is how far back the notifications of a threaded
+	// rule are read to find the threads of alerts.
+	slackThreadLookback = 7 * 24
+)
+
 // GenerateFlux generates a flux script for the slack notification rule.
 func (s *Slack) GenerateFlux(e influxdb.NotificationEndpoint) (string, error) {
 	slackEndpoint, ok := e.(*endpoint.Slack)
 	if !ok {
 		return "", fmt.Errorf("endpoint provided is a %s, not an Slack endpoint", e.Type())
 	}
+	if err := s.validThreadEndpoint(slackEndpoint); err != nil {
+		return "", err
+	}
 	p, err := s.GenerateFluxAST(slackEndpoint)
 	if err != nil {
 		return "", err
@@ -30,66 +62,371 @@ func (s *Slack) GenerateFlux(e influxdb.NotificationEndpoint) (string, error) {
 	return ast.Format(p), nil
 }
 
+// IsRouted returns true if the statuses of the rule are routed by the
+// routing table of its organization.
+func (s *Slack) IsRouted() bool {
+	return s.Routed
+}
+
+// GenerateRoutedFlux generates a flux script for the slack notification rule
+// sending each status to the slack endpoint of the first of routes it
+// matches, or else to e.
+func (s *Slack) GenerateRoutedFlux(e influxdb.NotificationEndpoint, routes []Route) (string, error) {
+	slackEndpoint, ok := e.(*endpoint.Slack)
+	if !ok {
+		return "", fmt.Errorf("endpoint provided is a %s, not an Slack endpoint", e.Type())
+	}
+	if err := s.validThreadEndpoint(slackEndpoint); err != nil {
+		return "", err
+	}
+	routeEndpoints := make([]*endpoint.Slack, 0, len(routes))
+	for _, r := range routes {
+		re, ok := r.Endpoint.(*endpoint.Slack)
+		if !ok {
+			return "", &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("endpoint %s of a route is a %s, not an Slack endpoint", r.Endpoint.GetID(), r.Endpoint.Type()),
+			}
+		}
+		if err := s.validThreadEndpoint(re); err != nil {
+			return "", err
+		}
+		routeEndpoints = append(routeEndpoints, re)
+	}
+
+	f := flux.File(
+		s.Name,
+		s.imports(),
+		s.generateRoutedFluxASTBody(slackEndpoint, routes, routeEndpoints),
+	)
+	return ast.Format(&ast.Package{Package: "main", Files: []*ast.File{f}}), nil
+}
+
 // GenerateFluxAST generates a flux AST for the slack notification rule.
 func (s *Slack) GenerateFluxAST(e *endpoint.Slack) (*ast.Package, error) {
 	f := flux.File(
 		s.Name,
-		flux.Imports("influxdata/influxdb/monitor", "slack", "influxdata/influxdb/secrets", "experimental"),
+		s.imports(),
 		s.generateFluxASTBody(e),
 	)
 	return &ast.Package{Package: "main", Files: []*ast.File{f}}, nil
 }
 
+// validThreadEndpoint returns an error if the rule is threaded and e cannot
+// post messages with the Slack Web API.
+func (s *Slack) validThreadEndpoint(e *endpoint.Slack) error {
+	if !s.Threaded || e.Token.Key != "" {
+		return nil
+	}
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("threaded slack messages are posted with the Slack Web API, but endpoint %s has no token", e.GetID()),
+	}
+}
+
+func (s *Slack) imports() []*ast.ImportDeclaration {
+	if s.Threaded {
+		return append(
+			flux.Imports("influxdata/influxdb/monitor", "http", "json", "strings", "influxdata/influxdb/secrets", "experimental"),
+			&ast.ImportDeclaration{As: flux.Identifier("xhttp"), Path: flux.String("experimental/http")},
+			&ast.ImportDeclaration{As: flux.Identifier("xjson"), Path: flux.String("experimental/json")},
+		)
+	}
+	if s.BlockKit {
+		return flux.Imports("influxdata/influxdb/monitor", "http", "json", "influxdata/influxdb/secrets", "experimental")
+	}
+	return flux.Imports("influxdata/influxdb/monitor", "slack", "influxdata/influxdb/secrets", "experimental")
+}
+
 func (s *Slack) generateFluxASTBody(e *endpoint.Slack) []ast.Statement {
 	var statements []ast.Statement
 	statements = append(statements, s.generateTaskOption())
 	if e.Token.Key != "" {
-		statements = append(statements, s.generateFluxASTSecrets(e))
+		statements = append(statements, s.generateFluxASTSecrets(e, ""))
 	}
-	statements = append(statements, s.generateFluxASTEndpoint(e))
+	statements = append(statements, s.generateFluxASTEndpoint(e, ""))
 	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
 	statements = append(statements, s.generateFluxASTStatuses())
 	statements = append(statements, s.generateLevelChecks()...)
-	statements = append(statements, s.generateFluxASTNotifyPipe())
+	statements = append(statements, s.generateFluxASTThreads()...)
+	statements = append(statements, s.generateFluxASTNotifyPipe(s.statuses(), e, ""))
 
 	return statements
 }
 
-func (s *Slack) generateFluxASTSecrets(e *endpoint.Slack) ast.Statement {
+// generateRoutedFluxASTBody generates the statements of a routed rule. The
+// variables of the endpoint of route i are suffixed with _i.
+func (s *Slack) generateRoutedFluxASTBody(e *endpoint.Slack, routes []Route, routeEndpoints []*endpoint.Slack) []ast.Statement {
+	var statements []ast.Statement
+	statements = append(statements, s.generateTaskOption())
+	if e.Token.Key != "" {
+		statements = append(statements, s.generateFluxASTSecrets(e, ""))
+	}
+	statements = append(statements, s.generateFluxASTEndpoint(e, ""))
+	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
+	for i, re := range routeEndpoints {
+		suffix := fmt.Sprintf("_%d", i)
+		if re.Token.Key != "" {
+			statements = append(statements, s.generateFluxASTSecrets(re, suffix))
+		}
+		statements = append(statements, s.generateFluxASTEndpoint(re, suffix))
+		statements = append(statements, s.generateFluxASTRouteNotificationDefinition(re, suffix))
+	}
+	statements = append(statements, s.generateFluxASTStatuses())
+	statements = append(statements, s.generateLevelChecks()...)
+	statements = append(statements, s.generateFluxASTThreads()...)
+
+	filters := routeFilters(routes)
+	for i, re := range routeEndpoints {
+		statuses := flux.Pipe(s.statuses(), filters[i])
+		statements = append(statements, s.generateFluxASTNotifyPipe(statuses, re, fmt.Sprintf("_%d", i)))
+	}
+	unrouted := flux.Pipe(s.statuses(), filters[len(filters)-1])
+	statements = append(statements, s.generateFluxASTNotifyPipe(unrouted, e, ""))
+
+	return statements
+}
+
+func (s *Slack) generateFluxASTSecrets(e *endpoint.Slack, suffix string) ast.Statement {
 	call := flux.Call(flux.Member("secrets", "get"), flux.Object(flux.Property("key", flux.String(e.Token.Key))))
 
-	return flux.DefineVariable("slack_secret", call)
+	return flux.DefineVariable("slack_secret"+suffix, call)
+}
+
+// statuses returns the statuses to notify, which are joined with the
+// threads of their checks when the rule is threaded.
+func (s *Slack) statuses() ast.Expression {
+	if s.Threaded {
+		return flux.Identifier("threaded_statuses")
+	}
+	return flux.Identifier("all_statuses")
+}
+
+// generateFluxASTThreads generates the lookup of the thread of the alert of
+// the check of each status. The last thread recorded in the notifications of
+// the rule is kept for each check, defaulting to no thread.
+func (s *Slack) generateFluxASTThreads() []ast.Statement {
+	if !s.Threaded {
+		return nil
+	}
+
+	noThreads := flux.Pipe(
+		flux.Identifier("all_statuses"),
+		flux.Call(flux.Identifier("keep"), flux.Object(flux.Property("columns", flux.Array(flux.String("_check_id"))))),
+		flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"), flux.ObjectWith("r",
+			flux.Property("_time", flux.Call(flux.Identifier("time"), flux.Object(flux.Property("v", flux.Integer(0))))),
+			flux.Property(slackThreadTSColumn, flux.String("")),
+		))))),
+	)
+	notifiedThreads := flux.Pipe(
+		flux.Call(flux.Member("monitor", "logs"), flux.Object(
+			flux.Property("start", flux.Negative(flux.Duration(slackThreadLookback, "h"))),
+			flux.Property("fn", flux.Function(flux.FunctionParams("r"),
+				flux.Equal(flux.Member("r", "_notification_rule_id"), flux.String(s.ID.String())),
+			)),
+		)),
+		flux.Call(flux.Identifier("filter"), flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"),
+			&ast.UnaryExpression{Operator: ast.ExistsOperator, Argument: flux.Member("r", slackThreadTSColumn)},
+		)))),
+		flux.Call(flux.Identifier("keep"), flux.Object(flux.Property("columns", flux.Array(
+			flux.String("_check_id"), flux.String("_time"), flux.String(slackThreadTSColumn),
+		)))),
+	)
+	threads := flux.Pipe(
+		flux.Call(flux.Identifier("union"), flux.Object(flux.Property("tables", flux.Array(noThreads, notifiedThreads)))),
+		flux.Call(flux.Identifier("group"), flux.Object(flux.Property("columns", flux.Array(flux.String("_check_id"))))),
+		flux.Call(flux.Identifier("sort"), flux.Object(flux.Property("columns", flux.Array(flux.String("_time"))))),
+		flux.Call(flux.Identifier("last"), flux.Object(flux.Property("column", flux.String(slackThreadTSColumn)))),
+		flux.Call(flux.Identifier("keep"), flux.Object(flux.Property("columns", flux.Array(
+			flux.String("_check_id"), flux.String(slackThreadTSColumn),
+		)))),
+	)
+	joined := flux.Call(flux.Identifier("join"), flux.Object(
+		flux.Property("tables", flux.Object(
+			flux.Property("statuses", flux.Identifier("all_statuses")),
+			flux.Property("threads", flux.Identifier("slack_threads")),
+		)),
+		flux.Property("on", flux.Array(flux.String("_check_id"))),
+	))
+
+	return []ast.Statement{
+		flux.DefineVariable("slack_threads", threads),
+		flux.DefineVariable("threaded_statuses", joined),
+	}
 }
 
-func (s *Slack) generateFluxASTEndpoint(e *endpoint.Slack) ast.Statement {
+func (s *Slack) generateFluxASTEndpoint(e *endpoint.Slack, suffix string) ast.Statement {
+	if s.Threaded {
+		return s.generateFluxASTThreadedEndpoint(e, suffix)
+	}
+	if s.BlockKit {
+		return s.generateFluxASTBlockKitEndpoint(e, suffix)
+	}
+
 	props := []*ast.Property{}
 	if e.Token.Key != "" {
-		props = append(props, flux.Property("token", flux.Identifier("slack_secret")))
+		props = append(props, flux.Property("token", flux.Identifier("slack_secret"+suffix)))
 	}
 	if e.URL != "" {
 		props = append(props, flux.Property("url", flux.String(e.URL)))
 	}
 	call := flux.Call(flux.Member("slack", "endpoint"), flux.Object(props...))
 
-	return flux.DefineVariable("slack_endpoint", call)
+	return flux.DefineVariable("slack_endpoint"+suffix, call)
+}
+
+// generateFluxASTBlockKitEndpoint generates an endpoint posting the messages
+// returned by mapFn as JSON. slack.endpoint only sends plain text messages.
+func (s *Slack) generateFluxASTBlockKitEndpoint(e *endpoint.Slack, suffix string) ast.Statement {
+	headers := []*ast.Property{flux.Dictionary("Content-Type", flux.String("application/json"))}
+	if e.Token.Key != "" {
+		headers = append(headers, flux.Property("Authorization", flux.Add(flux.String("Bearer "), flux.Identifier("slack_secret"+suffix))))
+	}
+
+	post := flux.Call(flux.Member("http", "post"), flux.Object(
+		flux.Property("url", flux.String(e.URL)),
+		flux.Property("headers", flux.Object(headers...)),
+		flux.Property("data", flux.Call(flux.Member("json", "encode"), flux.Object(
+			flux.Property("v", flux.Call(flux.Identifier("mapFn"), flux.Object(flux.Property("r", flux.Identifier("r"))))),
+		))),
+	))
+	sent := flux.Equal(flux.Integer(2), &ast.BinaryExpression{
+		Operator: ast.DivisionOperator,
+		Left:     post,
+		Right:    flux.Integer(100),
+	})
+	mapFn := flux.Function(flux.FunctionParams("r"), flux.ObjectWith("r",
+		flux.Property("_sent", flux.Call(flux.Identifier("string"), flux.Object(flux.Property("v", sent)))),
+	))
+
+	tables := &ast.Property{Key: flux.Identifier("tables"), Value: &ast.PipeLiteral{}}
+	fn := flux.Function(flux.FunctionParams("mapFn"), flux.Function([]*ast.Property{tables}, flux.Pipe(
+		flux.Identifier("tables"),
+		flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", mapFn))),
+	)))
+
+	return flux.DefineVariable("slack_endpoint"+suffix, fn)
+}
+
+// generateFluxASTThreadedEndpoint generates an endpoint posting the messages
+// returned by mapFn with chat.postMessage of the Slack Web API, replying in
+// the thread of the status if it has one. Neither slack.endpoint nor
+// http.post return the ts of the posted message, so it is requested with
+// experimental/http.get and its arguments are passed in the query string.
+// The ts of the thread of the status, or of the posted message when it
+// starts one, is recorded for the next runs, unless the status is ok.
+func (s *Slack) generateFluxASTThreadedEndpoint(e *endpoint.Slack, suffix string) ast.Statement {
+	// pathEscape keeps the characters separating query parameters.
+	var escaped ast.Expression = flux.Call(flux.Member("http", "pathEscape"), flux.Object(flux.Property("inputString", flux.Identifier("v"))))
+	for _, c := range []struct{ t, u string }{{"&", "%26"}, {"=", "%3D"}, {"+", "%2B"}} {
+		escaped = flux.Call(flux.Member("strings", "replaceAll"), flux.Object(
+			flux.Property("v", escaped),
+			flux.Property("t", flux.String(c.t)),
+			flux.Property("u", flux.String(c.u)),
+		))
+	}
+	escape := func(v ast.Expression) ast.Expression {
+		return flux.Call(flux.Identifier("escape"), flux.Object(flux.Property("v", v)))
+	}
+	param := func(name string, v ast.Expression) ast.Expression {
+		return flux.Add(flux.String("&"+name+"="), escape(v))
+	}
+
+	threadTS := flux.Identifier("thread_ts")
+	var url ast.Expression = flux.Add(flux.String(e.URL+"?channel="), escape(flux.Member("message", "channel")))
+	if s.BlockKit {
+		url = flux.Add(url, param("text", flux.Member("message", "text")))
+	}
+	url = flux.Add(url, param("attachments", flux.Call(flux.Identifier("string"), flux.Object(flux.Property("v",
+		flux.Call(flux.Member("json", "encode"), flux.Object(flux.Property("v", flux.Member("message", "attachments")))),
+	)))))
+	url = flux.Add(url, flux.Identifier("thread"))
+
+	response := flux.Call(flux.Member("xhttp", "get"), flux.Object(
+		flux.Property("url", url),
+		flux.Property("headers", flux.Object(
+			flux.Property("Authorization", flux.Add(flux.String("Bearer "), flux.Identifier("slack_secret"+suffix))),
+		)),
+	))
+	body := func(field string) ast.Expression {
+		return &ast.MemberExpression{
+			Object:   flux.Call(flux.Member("xjson", "parse"), flux.Object(flux.Property("data", flux.Member("response", "body")))),
+			Property: flux.String(field),
+		}
+	}
+
+	mapFn := flux.FuncBlock(flux.FunctionParams("r"),
+		flux.DefineVariable("escape", flux.Function(flux.FunctionParams("v"), escaped)),
+		flux.DefineVariable("message", flux.Call(flux.Identifier("mapFn"), flux.Object(flux.Property("r", flux.Identifier("r"))))),
+		flux.DefineVariable("thread_ts", flux.Member("r", slackThreadTSColumn)),
+		flux.DefineVariable("thread", flux.If(flux.Equal(threadTS, flux.String("")), flux.String(""), flux.Add(flux.String("&thread_ts="), threadTS))),
+		flux.DefineVariable("response", response),
+		flux.DefineVariable("sent", flux.And(flux.Equal(flux.Member("response", "statusCode"), flux.Integer(200)), body("ok"))),
+		&ast.ReturnStatement{Argument: flux.ObjectWith("r",
+			flux.Property("_sent", flux.Call(flux.Identifier("string"), flux.Object(flux.Property("v", flux.Identifier("sent"))))),
+			flux.Property(slackThreadTSColumn, flux.If(
+				flux.Equal(flux.Member("r", "_level"), flux.String("ok")),
+				flux.String(""),
+				flux.If(flux.And(flux.Identifier("sent"), flux.Equal(threadTS, flux.String(""))), body("ts"), threadTS),
+			)),
+		)},
+	)
+
+	tables := &ast.Property{Key: flux.Identifier("tables"), Value: &ast.PipeLiteral{}}
+	fn := flux.Function(flux.FunctionParams("mapFn"), flux.Function([]*ast.Property{tables}, flux.Pipe(
+		flux.Identifier("tables"),
+		flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", mapFn))),
+	)))
+
+	return flux.DefineVariable("slack_endpoint"+suffix, fn)
 }
 
-func (s *Slack) generateFluxASTNotifyPipe() ast.Statement {
+// generateFluxASTNotifyPipe generates the notification of statuses with the
+// endpoint and notification definition of suffix. The message is in the
+// locale of e.
+func (s *Slack) generateFluxASTNotifyPipe(statuses ast.Expression, e *endpoint.Slack, suffix string) ast.Statement {
+	var text ast.Expression = s.generateMessageTemplate(s.messageTemplate(s.MessageTemplate, e))
+	if len(s.Mentions) > 0 {
+		text = flux.Add(s.generateSlackMentions(), text)
+	}
+
 	endpointProps := []*ast.Property{}
 	endpointProps = append(endpointProps, flux.Property("channel", flux.String(s.Channel)))
 	// TODO(desa): are these values correct?
-	endpointProps = append(endpointProps, flux.Property("text", flux.String(s.MessageTemplate)))
-	endpointProps = append(endpointProps, flux.Property("color", s.generateSlackColors()))
+	endpointProps = append(endpointProps, flux.Property("text", text))
+	if s.BlockKit {
+		endpointProps = append(endpointProps, flux.Property("attachments", flux.Array(flux.Object(
+			flux.Property("color", s.generateSlackColors()),
+			flux.Property("blocks", flux.Array(
+				slackSection(&ast.StringExpression{Parts: []ast.StringExpressionPart{
+					&ast.TextPart{Value: "*"},
+					&ast.InterpolatedPart{Expression: flux.Member("r", "_check_name")},
+					&ast.TextPart{Value: "* is *"},
+					&ast.InterpolatedPart{Expression: flux.Member("r", "_level")},
+					&ast.TextPart{Value: "*"},
+				}}),
+				slackSection(text),
+			)),
+		))))
+	} else if s.Threaded {
+		endpointProps = append(endpointProps, flux.Property("attachments", flux.Array(flux.Object(
+			flux.Property("color", s.generateSlackColors()),
+			flux.Property("text", text),
+			flux.Property("mrkdwn_in", flux.Array(flux.String("text"))),
+		))))
+	} else {
+		endpointProps = append(endpointProps, flux.Property("color", s.generateSlackColors()))
+	}
 	endpointFn := flux.Function(flux.FunctionParams("r"), flux.Object(endpointProps...))
 
 	props := []*ast.Property{}
-	props = append(props, flux.Property("data", flux.Identifier("notification")))
+	props = append(props, flux.Property("data", flux.Identifier("notification"+suffix)))
 	props = append(props, flux.Property("endpoint",
-		flux.Call(flux.Identifier("slack_endpoint"), flux.Object(flux.Property("mapFn", endpointFn)))))
+		flux.Call(flux.Identifier("slack_endpoint"+suffix), flux.Object(flux.Property("mapFn", endpointFn)))))
 
 	call := flux.Call(flux.Member("monitor", "notify"), flux.Object(props...))
 
-	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), call))
+	return flux.ExpressionStatement(flux.Pipe(statuses, call))
 }
 
 func (s *Slack) generateSlackColors() ast.Expression {
@@ -105,6 +442,80 @@ func (s *Slack) generateSlackColors() ast.Expression {
 	)
 }
 
+// slackSection returns a Block Kit section of markdown text.
+func slackSection(text ast.Expression) ast.Expression {
+	return flux.Object(
+		flux.Property("type", flux.String("section")),
+		flux.Property("text", flux.Object(
+			flux.Property("type", flux.String("mrkdwn")),
+			flux.Property("text", text),
+		)),
+	)
+}
+
+// slackMentionLevels are the levels that can be mentioned, most severe first.
+var slackMentionLevels = []notification.CheckLevel{
+	notification.Critical,
+	notification.Warn,
+	notification.Info,
+	notification.Ok,
+	notification.Unknown,
+}
+
+func (s *Slack) generateSlackMentions() ast.Expression {
+	var mentions ast.Expression = flux.String("")
+	for i := len(slackMentionLevels) - 1; i >= 0; i-- {
+		level := strings.ToLower(slackMentionLevels[i].String())
+		m, ok := s.Mentions[level]
+		if !ok {
+			continue
+		}
+		mention, _ := slackMention(m)
+		mentions = flux.If(
+			flux.Equal(flux.Member("r", "_level"), flux.String(level)),
+			flux.String(mention+" "),
+			mentions,
+		)
+	}
+	return mentions
+}
+
+var slackUserIDRE = regexp.MustCompile(`^[UW][A-Z0-9]+$`)
+
+// slackMention returns the Slack markup of a mention of @here, @channel,
+// @everyone or a user ID. Mentions already in Slack markup, e.g. of user
+// groups, are returned as they are.
+func slackMention(m string) (string, error) {
+	switch {
+	case strings.HasPrefix(m, "<") && strings.HasSuffix(m, ">"):
+		return m, nil
+	case m == "@here" || m == "@channel" || m == "@everyone":
+		return "<!" + m[1:] + ">", nil
+	case strings.HasPrefix(m, "@") && slackUserIDRE.MatchString(m[1:]):
+		return "<@" + m[1:] + ">", nil
+	}
+	return "", &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("slack mention %q must be @here, @channel, @everyone or @ followed by a user ID", m),
+	}
+}
+
+func (s Slack) validMentions() error {
+	for level, m := range s.Mentions {
+		cl := notification.ParseCheckLevel(strings.ToUpper(level))
+		if cl == notification.Any || level != strings.ToLower(cl.String()) {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("slack mentions are keyed by crit, warn, info, ok or unknown, not %q", level),
+			}
+		}
+		if _, err := slackMention(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type slackAlias Slack
 
 // MarshalJSON implement json.Marshaler interface.
@@ -124,13 +535,16 @@ func (s Slack) Valid() error {
 	if err := s.Base.valid(); err != nil {
 		return err
 	}
-	if s.MessageTemplate == "" {
+	if s.MessageTemplate == "" && s.Message == "" {
 		return &influxdb.Error{
 			Code: influxdb.EInvalid,
 			Msg:  "slack msg template is empty",
 		}
 	}
-	return nil
+	if err := s.validMentions(); err != nil {
+		return err
+	}
+	return ValidMessageTemplate(s.MessageTemplate)
 }
 
 // Type returns the type of the rule config.
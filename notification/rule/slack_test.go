@@ -1,6 +1,7 @@
 package rule_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/influxdata/flux/ast"
@@ -355,3 +356,291 @@ all_statuses
 		})
 	}
 }
+
+func TestSlack_GenerateFlux_blockKit(t *testing.T) {
+	r := &rule.Slack{
+		Channel:         "bar",
+		MessageTemplate: "blah",
+		Mentions:        map[string]string{"crit": "@here", "warn": "@U024BE7LH"},
+		BlockKit:        true,
+		Base: rule.Base{
+			ID:         1,
+			EndpointID: 2,
+			Name:       "foo",
+			Every:      mustDuration("1h"),
+			StatusRules: []notification.StatusRule{
+				{
+					CurrentLevel: notification.Any,
+				},
+			},
+		},
+	}
+	e := &endpoint.Slack{
+		Base: endpoint.Base{
+			ID:   idPtr(2),
+			Name: "foo",
+		},
+		URL: "https://slack.com/api/chat.postMessage",
+		Token: influxdb.SecretField{
+			Key: "slack_token",
+		},
+	}
+
+	f, err := r.GenerateFlux(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`import "http"`,
+		`import "json"`,
+		`http["post"](url: "https://slack.com/api/chat.postMessage"`,
+		`Authorization: "Bearer " + slack_secret`,
+		`json["encode"](v: mapFn(r: r))`,
+		`if r["_level"] == "crit" then "<!here> " else if r["_level"] == "warn" then "<@U024BE7LH> " else ""`,
+		`type: "section"`,
+		`type: "mrkdwn"`,
+	} {
+		if !strings.Contains(f, want) {
+			t.Errorf("script does not contain %q:\n%s", want, f)
+		}
+	}
+	if strings.Contains(f, `import "slack"`) {
+		t.Errorf("block kit messages are not sent with the slack package:\n%s", f)
+	}
+}
+
+func TestSlack_GenerateFlux_threaded(t *testing.T) {
+	r := &rule.Slack{
+		Channel:         "bar",
+		MessageTemplate: "blah",
+		Threaded:        true,
+		Base: rule.Base{
+			ID:         1,
+			EndpointID: 2,
+			Name:       "foo",
+			Every:      mustDuration("1h"),
+			StatusRules: []notification.StatusRule{
+				{
+					CurrentLevel: notification.Any,
+				},
+			},
+		},
+	}
+	e := &endpoint.Slack{
+		Base: endpoint.Base{
+			ID:   idPtr(2),
+			Name: "foo",
+		},
+		URL: "https://slack.com/api/chat.postMessage",
+		Token: influxdb.SecretField{
+			Key: "slack_token",
+		},
+	}
+
+	f, err := r.GenerateFlux(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`import xhttp "experimental/http"`,
+		`import xjson "experimental/json"`,
+		`monitor["logs"](start: -168h, fn: (r) =>
+	(r["_notification_rule_id"] == "0000000000000001"))`,
+		`|> last(column: "_slack_thread_ts")`,
+		`threaded_statuses = join(tables: {statuses: all_statuses, threads: slack_threads}, on: ["_check_id"])`,
+		`thread = if thread_ts == "" then "" else "&thread_ts=" + thread_ts`,
+		`xhttp["get"](url: "https://slack.com/api/chat.postMessage?channel=" + escape(v: message["channel"])`,
+		`_slack_thread_ts: if r["_level"] == "ok" then "" else if sent and thread_ts == "" then xjson["parse"](data: response["body"])["ts"] else thread_ts`,
+		`threaded_statuses
+	|> monitor["notify"](data: notification`,
+	} {
+		if !strings.Contains(f, want) {
+			t.Errorf("script does not contain %q:\n%s", want, f)
+		}
+	}
+
+	e.Token = influxdb.SecretField{}
+	if _, err := r.GenerateFlux(e); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected an invalid error for a threaded rule of an endpoint without a token, got %v", err)
+	}
+}
+
+func TestSlack_GenerateRoutedFlux(t *testing.T) {
+	r := &rule.Slack{
+		Channel:         "alerts",
+		MessageTemplate: "blah",
+		Routed:          true,
+		Base: rule.Base{
+			ID:         1,
+			EndpointID: 2,
+			Name:       "foo",
+			Every:      mustDuration("1h"),
+			StatusRules: []notification.StatusRule{
+				{
+					CurrentLevel: notification.Any,
+				},
+			},
+		},
+	}
+	e := &endpoint.Slack{
+		Base: endpoint.Base{
+			ID:   idPtr(2),
+			Name: "default",
+		},
+		URL: "https://hooks.slack.com/default",
+	}
+	routes := []rule.Route{
+		{
+			Matchers: []influxdb.TagRule{{Tag: influxdb.Tag{Key: "team", Value: "infra"}, Operator: influxdb.Equal}},
+			Endpoint: &endpoint.Slack{
+				Base: endpoint.Base{ID: idPtr(3), Name: "infra"},
+				URL:  "https://hooks.slack.com/infra",
+			},
+		},
+		{
+			Matchers: []influxdb.TagRule{{Tag: influxdb.Tag{Key: "team", Value: "db"}, Operator: influxdb.Equal}},
+			Endpoint: &endpoint.Slack{
+				Base:  endpoint.Base{ID: idPtr(4), Name: "db"},
+				URL:   "https://slack.com/api/chat.postMessage",
+				Token: influxdb.SecretField{Key: "db-token"},
+			},
+		},
+	}
+
+	f, err := r.GenerateRoutedFlux(e, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`slack_endpoint = slack["endpoint"](url: "https://hooks.slack.com/default")`,
+		`slack_endpoint_0 = slack["endpoint"](url: "https://hooks.slack.com/infra")`,
+		`slack_secret_1 = secrets["get"](key: "db-token")`,
+		`slack_endpoint_1 = slack["endpoint"](token: slack_secret_1, url: "https://slack.com/api/chat.postMessage")`,
+		`_notification_endpoint_id: "0000000000000003"`,
+		`_notification_endpoint_name: "db"`,
+		`r["team"] == "infra"`,
+		`r["team"] == "db"`,
+		`monitor["notify"](data: notification_0, endpoint: slack_endpoint_0(mapFn:`,
+		`monitor["notify"](data: notification_1, endpoint: slack_endpoint_1(mapFn:`,
+		`monitor["notify"](data: notification, endpoint: slack_endpoint(mapFn:`,
+	} {
+		if !strings.Contains(f, want) {
+			t.Errorf("script does not contain %q:\n%s", want, f)
+		}
+	}
+
+	routes = append(routes, rule.Route{
+		Matchers: []influxdb.TagRule{{Tag: influxdb.Tag{Key: "team", Value: "web"}, Operator: influxdb.Equal}},
+		Endpoint: &endpoint.PagerDuty{Base: endpoint.Base{ID: idPtr(5), Name: "web"}},
+	})
+	if _, err := r.GenerateRoutedFlux(e, routes); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected a route to a pagerduty endpoint to be invalid, got %v", err)
+	}
+}
+
+func TestSlack_Valid_mentions(t *testing.T) {
+	tests := []struct {
+		name     string
+		mentions map[string]string
+		wantErr  bool
+	}{
+		{name: "channel mentions", mentions: map[string]string{"crit": "@here", "warn": "@channel"}},
+		{name: "user and group mentions", mentions: map[string]string{"crit": "@U024BE7LH", "info": "<!subteam^SAZ94GDB8>"}},
+		{name: "unknown level", mentions: map[string]string{"critical": "@here"}, wantErr: true},
+		{name: "any level", mentions: map[string]string{"any": "@here"}, wantErr: true},
+		{name: "user name", mentions: map[string]string{"crit": "@alice"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &rule.Slack{
+				Channel:         "bar",
+				MessageTemplate: "blah",
+				Mentions:        tt.mentions,
+				Base: rule.Base{
+					ID:         1,
+					EndpointID: 2,
+					OwnerID:    3,
+					OrgID:      4,
+					Name:       "foo",
+					Every:      mustDuration("1h"),
+				},
+			}
+			err := r.Valid()
+			if tt.wantErr && influxdb.ErrorCode(err) != influxdb.EInvalid {
+				t.Fatalf("expected invalid error, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSlack_GenerateRoutedFlux_catalogMessage(t *testing.T) {
+	r := &rule.Slack{
+		Channel: "alerts",
+		Routed:  true,
+		Base: rule.Base{
+			ID:         1,
+			EndpointID: 2,
+			Name:       "foo",
+			Every:      mustDuration("1h"),
+			Message:    "down",
+			Locale:     "de",
+			StatusRules: []notification.StatusRule{
+				{
+					CurrentLevel: notification.Any,
+				},
+			},
+		},
+	}
+	r.SetMessageCatalog(&influxdb.NotificationMessageCatalog{
+		OrgID:         1,
+		DefaultLocale: "en",
+		Messages: []influxdb.NotificationMessage{
+			{
+				Name: "down",
+				Templates: map[string]string{
+					"en": "host is down",
+					"de": "Host ist ausgefallen",
+					"fr": "hote en panne",
+				},
+			},
+		},
+	})
+	if err := r.Valid(); err != nil {
+		t.Fatalf("expected a rule with a catalog message to be valid, got %v", err)
+	}
+
+	e := &endpoint.Slack{
+		Base: endpoint.Base{ID: idPtr(2), Name: "default"},
+		URL:  "https://hooks.slack.com/default",
+	}
+	routes := []rule.Route{
+		{
+			Matchers: []influxdb.TagRule{{Tag: influxdb.Tag{Key: "team", Value: "paris"}, Operator: influxdb.Equal}},
+			Endpoint: &endpoint.Slack{
+				Base: endpoint.Base{ID: idPtr(3), Name: "paris", Locale: "fr-FR"},
+				URL:  "https://hooks.slack.com/paris",
+			},
+		},
+	}
+
+	f, err := r.GenerateRoutedFlux(e, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`channel: "alerts", text: "hote en panne"`,
+		`channel: "alerts", text: "Host ist ausgefallen"`,
+	} {
+		if !strings.Contains(f, want) {
+			t.Errorf("script does not contain %q:\n%s", want, f)
+		}
+	}
+}
@@ -79,7 +79,7 @@ func (s *Telegram) generateFluxASTEndpoint(e *endpoint.Telegram) ast.Statement {
 func (s *Telegram) generateFluxASTNotifyPipe(e *endpoint.Telegram) ast.Statement {
 	endpointProps := []*ast.Property{}
 	endpointProps = append(endpointProps, flux.Property("channel", flux.String(e.Channel)))
-	endpointProps = append(endpointProps, flux.Property("text", flux.String(s.MessageTemplate)))
+	endpointProps = append(endpointProps, flux.Property("text", s.generateMessageTemplate(s.messageTemplate(s.MessageTemplate, e))))
 	endpointProps = append(endpointProps, flux.Property("silent", s.generateSilent()))
 	endpointFn := flux.Function(flux.FunctionParams("r"), flux.Object(endpointProps...))
 
@@ -125,13 +125,13 @@ func (s Telegram) Valid() error {
 	if err := s.Base.valid(); err != nil {
 		return err
 	}
-	if s.MessageTemplate == "" {
+	if s.MessageTemplate == "" && s.Message == "" {
 		return &influxdb.Error{
 			Code: influxdb.EInvalid,
 			Msg:  "Telegram MessageTemplate is invalid",
 		}
 	}
-	return nil
+	return ValidMessageTemplate(s.MessageTemplate)
 }
 
 // Type returns the type of the rule config.
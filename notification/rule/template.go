@@ -0,0 +1,198 @@
+package rule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+)
+
+// Message templates are plain text with ${...} placeholders. A placeholder
+// is one of:
+//
+//	${r.<column>}, ${r["<column>"]}     any status column or tag value
+//	${since(r.<column>)}                 time elapsed since a timestamp column, e.g. 5m30s
+//	${duration(r.<column>)}              an integer nanoseconds column as a duration
+//	${checkLink}                         UI path of the check that raised the status
+//	${dashboardLink("<dashboardID>")}    UI path of a dashboard in the rule's organization
+//
+// Links are paths relative to the UI, e.g. http://localhost:8086${checkLink}.
+var (
+	templateColumnIdent = `r\.([A-Za-z_][A-Za-z0-9_]*)`
+	templateColumnIndex = `r\["((?:[^"\\]|\\.)*)"\]`
+	templateColumn      = `(?:` + templateColumnIdent + `|` + templateColumnIndex + `)`
+
+	templateColumnRE        = regexp.MustCompile(`^` + templateColumn + `$`)
+	templateFuncRE          = regexp.MustCompile(`^(since|duration)\(\s*(` + templateColumn + `)\s*\)$`)
+	templateDashboardLinkRE = regexp.MustCompile(`^dashboardLink\(\s*"([^"]*)"\s*\)$`)
+)
+
+// ValidMessageTemplate checks every placeholder of a message template.
+func ValidMessageTemplate(tmpl string) error {
+	_, err := parseMessageTemplate(tmpl, 0)
+	return err
+}
+
+// generateMessageTemplate returns the flux string for a message template.
+func (b *Base) generateMessageTemplate(tmpl string) ast.Expression {
+	parts, err := parseMessageTemplate(tmpl, b.OrgID)
+	if err != nil || len(parts) == 0 {
+		// Templates are validated when the rule is saved, rules saved
+		// before that keep rendering the template as a plain flux string.
+		return flux.String(tmpl)
+	}
+	return &ast.StringExpression{Parts: parts}
+}
+
+func parseMessageTemplate(tmpl string, orgID influxdb.ID) ([]ast.StringExpressionPart, error) {
+	var (
+		parts        []ast.StringExpressionPart
+		text         strings.Builder
+		placeholders bool
+	)
+	appendText := func(s string) {
+		text.WriteString(s)
+	}
+	appendExpr := func(e ast.Expression) {
+		if text.Len() > 0 {
+			parts = append(parts, &ast.TextPart{Value: text.String()})
+			text.Reset()
+		}
+		parts = append(parts, &ast.InterpolatedPart{Expression: e})
+	}
+
+	for rest := tmpl; rest != ""; {
+		i := strings.Index(rest, "${")
+		if i < 0 {
+			appendText(rest)
+			break
+		}
+		appendText(rest[:i])
+
+		end := placeholderEnd(rest[i+2:])
+		if end < 0 {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("message template placeholder %q is not closed", rest[i:]),
+			}
+		}
+		placeholder := rest[i+2 : i+2+end]
+		if err := parsePlaceholder(strings.TrimSpace(placeholder), orgID, appendText, appendExpr); err != nil {
+			return nil, err
+		}
+		placeholders = true
+		rest = rest[i+2+end+1:]
+	}
+
+	if !placeholders {
+		return nil, nil
+	}
+	if text.Len() > 0 {
+		parts = append(parts, &ast.TextPart{Value: text.String()})
+	}
+	return parts, nil
+}
+
+// placeholderEnd returns the index of the brace closing a placeholder,
+// skipping braces in quoted strings.
+func placeholderEnd(s string) int {
+	var quoted, escaped bool
+	for i, c := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case c == '}' && !quoted:
+			return i
+		}
+	}
+	return -1
+}
+
+func parsePlaceholder(p string, orgID influxdb.ID, appendText func(string), appendExpr func(ast.Expression)) error {
+	if col, ok := parseTemplateColumn(p); ok {
+		appendExpr(col)
+		return nil
+	}
+
+	if m := templateFuncRE.FindStringSubmatch(p); m != nil {
+		col, _ := parseTemplateColumn(m[2])
+		switch m[1] {
+		case "since":
+			// Whole seconds elapsed since the column.
+			appendExpr(humanizeDuration(flux.Subtract(
+				flux.Call(flux.Identifier("int"), flux.Object(flux.Property("v", &ast.CallExpression{Callee: flux.Identifier("now")}))),
+				flux.Call(flux.Identifier("int"), flux.Object(flux.Property("v", col))),
+			)))
+		case "duration":
+			appendExpr(humanizeDuration(col))
+		}
+		return nil
+	}
+
+	if p == "checkLink" {
+		appendText(fmt.Sprintf("/orgs/%s/alerting/checks/", orgID))
+		appendExpr(flux.Member("r", "_check_id"))
+		appendText("/edit")
+		return nil
+	}
+
+	if m := templateDashboardLinkRE.FindStringSubmatch(p); m != nil {
+		id, err := influxdb.IDFromString(m[1])
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("message template placeholder ${%s} has an invalid dashboard ID", p),
+				Err:  err,
+			}
+		}
+		appendText(fmt.Sprintf("/orgs/%s/dashboards/%s", orgID, id))
+		return nil
+	}
+
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("message template placeholder ${%s} is not supported", p),
+	}
+}
+
+func parseTemplateColumn(p string) (ast.Expression, bool) {
+	m := templateColumnRE.FindStringSubmatch(p)
+	if m == nil {
+		return nil, false
+	}
+	if m[1] != "" {
+		return flux.Member("r", m[1]), true
+	}
+	name, err := strconv.Unquote(`"` + m[2] + `"`)
+	if err != nil {
+		return nil, false
+	}
+	return &ast.MemberExpression{
+		Object:   flux.Identifier("r"),
+		Property: flux.String(name),
+	}, true
+}
+
+// humanizeDuration formats nanoseconds as a duration truncated to seconds.
+func humanizeDuration(ns ast.Expression) ast.Expression {
+	second := flux.Integer(1000000000)
+	truncated := &ast.BinaryExpression{
+		Operator: ast.MultiplicationOperator,
+		Left: &ast.BinaryExpression{
+			Operator: ast.DivisionOperator,
+			Left:     ns,
+			Right:    second,
+		},
+		Right: second,
+	}
+	d := flux.Call(flux.Identifier("duration"), flux.Object(flux.Property("v", truncated)))
+	return flux.Call(flux.Identifier("string"), flux.Object(flux.Property("v", d)))
+}
@@ -0,0 +1,85 @@
+package rule_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTemplateRule(tmpl string) *rule.Slack {
+	return &rule.Slack{
+		Channel:         "bar",
+		MessageTemplate: tmpl,
+		Base: rule.Base{
+			ID:         1,
+			EndpointID: 2,
+			OwnerID:    3,
+			OrgID:      5,
+			Name:       "foo",
+			Every:      mustDuration("1h"),
+		},
+	}
+}
+
+func TestMessageTemplate_Valid(t *testing.T) {
+	cases := []struct {
+		name string
+		tmpl string
+		err  string
+	}{
+		{name: "plain text", tmpl: "cpu is high"},
+		{name: "status column", tmpl: "${ r._check_name } is ${r._level}: ${r._message}"},
+		{name: "tag value", tmpl: `host ${r["host.name"]} is down`},
+		{name: "humanized durations", tmpl: "down for ${since(r._source_timestamp)}, ${duration(r.elapsed)}"},
+		{name: "links", tmpl: `${checkLink} ${dashboardLink("0000000000000001")}`},
+		{
+			name: "unknown function",
+			tmpl: "${upper(r._level)}",
+			err:  "message template placeholder ${upper(r._level)} is not supported",
+		},
+		{
+			name: "unclosed placeholder",
+			tmpl: "level ${r._level",
+			err:  `message template placeholder "${r._level" is not closed`,
+		},
+		{
+			name: "invalid dashboard ID",
+			tmpl: `${dashboardLink("nope")}`,
+			err:  `message template placeholder ${dashboardLink("nope")} has an invalid dashboard ID`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := newTemplateRule(c.tmpl).Valid()
+			if c.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+			assert.Equal(t, c.err, influxdb.ErrorMessage(err))
+		})
+	}
+}
+
+func TestMessageTemplate_GenerateFlux(t *testing.T) {
+	e := &endpoint.Slack{
+		Base: endpoint.Base{ID: idPtr(2), Name: "foo"},
+		URL:  "http://localhost:7777",
+	}
+
+	f, err := newTemplateRule(`${r._check_name} failed, see ${checkLink} and ${dashboardLink("0000000000000009")}`).GenerateFlux(e)
+	require.NoError(t, err)
+	assert.Contains(t, f, `/orgs/0000000000000005/alerting/checks/${r["_check_id"]}/edit`)
+	assert.Contains(t, f, `/orgs/0000000000000005/dashboards/0000000000000009`)
+
+	f, err = newTemplateRule(`down for ${since(r._source_timestamp)}`).GenerateFlux(e)
+	require.NoError(t, err)
+	assert.Contains(t, f, `now()`)
+	assert.Contains(t, f, `r["_source_timestamp"]`)
+}
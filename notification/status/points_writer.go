@@ -0,0 +1,182 @@
+// Package status deduplicates the statuses checks write to the _monitoring
+// bucket.
+//
+// Checks write the status of every series they evaluate at every interval,
+// which bloats _monitoring with statuses of levels that did not change. The
+// statuses of checks with a status heartbeat are written only when the level
+// of their series changes, and when the heartbeat elapsed since the last
+// status written for the series.
+//
+// The last statuses are tracked in memory: the first status of every series
+// is written after a restart.
+package status
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	statusesMeasurement = []byte("statuses")
+	levelTag            = []byte("_level")
+	heartbeatTag        = []byte(check.StatusHeartbeatTag)
+)
+
+// sweepInterval is how often the series whose heartbeat elapsed are
+// forgotten.
+const sweepInterval = time.Minute
+
+var _ storage.PointsWriter = (*PointsWriter)(nil)
+
+// PointsWriter writes points with an underlying points writer, dropping the
+// statuses of checks with a status heartbeat that repeat the last level
+// written for their series within the heartbeat.
+type PointsWriter struct {
+	storage.PointsWriter
+
+	mu        sync.Mutex
+	last      map[string]lastStatus // the last status written by series.
+	lastSweep time.Time
+
+	dropped prometheus.Counter
+}
+
+// lastStatus is the last status written for a series.
+type lastStatus struct {
+	level     string
+	time      time.Time
+	heartbeat time.Duration
+}
+
+// NewPointsWriter returns a PointsWriter writing points with pw.
+func NewPointsWriter(pw storage.PointsWriter) *PointsWriter {
+	return &PointsWriter{
+		PointsWriter: pw,
+		last:         make(map[string]lastStatus),
+		lastSweep:    time.Now(),
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "notification",
+			Subsystem: "statuses",
+			Name:      "deduplicated_total",
+			Help:      "Number of status points of unchanged levels not written to _monitoring.",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (w *PointsWriter) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{w.dropped}
+}
+
+// WritePoints writes the points that are not deduplicated statuses. The
+// series whose statuses fail to be written are forgotten, so that their next
+// status is written.
+func (w *PointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	var (
+		kept    []models.Point // nil until a status is dropped.
+		updated []string
+	)
+	w.mu.Lock()
+	w.sweep(time.Now())
+	for i, p := range points {
+		key, keep := w.keep(p)
+		if key != "" {
+			updated = append(updated, key)
+		}
+		switch {
+		case keep && kept != nil:
+			kept = append(kept, p)
+		case !keep && kept == nil:
+			kept = append(make([]models.Point, 0, len(points)), points[:i]...)
+		}
+	}
+	w.mu.Unlock()
+
+	if kept == nil {
+		kept = points
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	err := w.PointsWriter.WritePoints(ctx, kept)
+	if err != nil && len(updated) > 0 {
+		w.mu.Lock()
+		for _, key := range updated {
+			delete(w.last, key)
+		}
+		w.mu.Unlock()
+	}
+	return err
+}
+
+// keep returns whether p is written, and the series whose last status p
+// becomes, if any. w.mu must be held.
+func (w *PointsWriter) keep(p models.Point) (string, bool) {
+	key, level, heartbeat, ok := deduplicated(p)
+	if !ok {
+		return "", true
+	}
+
+	t := p.Time()
+	st, seen := w.last[key]
+	switch {
+	case !seen || t.After(st.time) && (st.level != level || t.Sub(st.time) >= heartbeat):
+	case t.Equal(st.time) && st.level != level:
+	case t.After(st.time):
+		w.dropped.Inc()
+		return "", false
+	default:
+		// The other points of the last status, or an older status.
+		return "", true
+	}
+	w.last[key] = lastStatus{level: level, time: t, heartbeat: heartbeat}
+	return key, true
+}
+
+// sweep forgets the series whose heartbeat elapsed, since their next status
+// is written anyway. w.mu must be held.
+func (w *PointsWriter) sweep(now time.Time) {
+	if now.Sub(w.lastSweep) < sweepInterval {
+		return
+	}
+	w.lastSweep = now
+	for key, st := range w.last {
+		if now.Sub(st.time) >= st.heartbeat {
+			delete(w.last, key)
+		}
+	}
+}
+
+// deduplicated returns the series, the level and the heartbeat of p if it is
+// a status of a check with a status heartbeat. The series of the exploded
+// points of a status is its series key without its field and level.
+func deduplicated(p models.Point) (key, level string, heartbeat time.Duration, ok bool) {
+	tags := p.Tags()
+	if !bytes.Equal(tags.Get(models.MeasurementTagKeyBytes), statusesMeasurement) {
+		return "", "", 0, false
+	}
+	hb := tags.Get(heartbeatTag)
+	if len(hb) == 0 {
+		return "", "", 0, false
+	}
+	heartbeat, err := time.ParseDuration(string(hb))
+	if err != nil || heartbeat <= 0 {
+		return "", "", 0, false
+	}
+
+	series := make(models.Tags, 0, len(tags))
+	for _, t := range tags {
+		if bytes.Equal(t.Key, models.FieldKeyTagKeyBytes) || bytes.Equal(t.Key, levelTag) {
+			continue
+		}
+		series = append(series, t)
+	}
+	return string(models.MakeKey(p.Name(), series)), string(tags.Get(levelTag)), heartbeat, true
+}
@@ -0,0 +1,113 @@
+package status_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/notification/status"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// statusPoints returns the exploded points of a status of the host at t.
+func statusPoints(t *testing.T, host, level, heartbeat string, at time.Time) []models.Point {
+	t.Helper()
+
+	tags := map[string]string{
+		"_check_id": "020f755c3c082000",
+		"_level":    level,
+		"host":      host,
+	}
+	if heartbeat != "" {
+		tags["_status_heartbeat"] = heartbeat
+	}
+	pt := models.MustNewPoint("statuses",
+		models.NewTags(tags),
+		models.Fields{
+			"_message":          "cpu is " + level,
+			"_source_timestamp": at.UnixNano(),
+		},
+		at,
+	)
+	points, err := tsdb.ExplodePoints(1, 2, []models.Point{pt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return points
+}
+
+func TestPointsWriter_Deduplicate(t *testing.T) {
+	var written []models.Point
+	w := status.NewPointsWriter(&mock.PointsWriter{
+		WritePointsFn: func(ctx context.Context, p []models.Point) error {
+			written = append(written, p...)
+			return nil
+		},
+	})
+
+	now := time.Now()
+	tests := []struct {
+		name      string
+		host      string
+		level     string
+		heartbeat string
+		at        time.Duration
+		written   bool
+	}{
+		{name: "first status", host: "a", level: "ok", heartbeat: "1h0m0s", written: true},
+		{name: "unchanged level", host: "a", level: "ok", heartbeat: "1h0m0s", at: time.Minute},
+		{name: "other series", host: "b", level: "ok", heartbeat: "1h0m0s", at: time.Minute, written: true},
+		{name: "changed level", host: "a", level: "crit", heartbeat: "1h0m0s", at: 2 * time.Minute, written: true},
+		{name: "unchanged level after change", host: "a", level: "crit", heartbeat: "1h0m0s", at: 3 * time.Minute},
+		{name: "heartbeat", host: "a", level: "crit", heartbeat: "1h0m0s", at: 62 * time.Minute, written: true},
+		{name: "no heartbeat", host: "c", level: "ok", written: true},
+		{name: "unchanged level without heartbeat", host: "c", level: "ok", at: time.Minute, written: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			written = nil
+			points := statusPoints(t, tt.host, tt.level, tt.heartbeat, now.Add(tt.at))
+			if err := w.WritePoints(context.Background(), points); err != nil {
+				t.Fatal(err)
+			}
+			if tt.written && len(written) != len(points) {
+				t.Errorf("expected %d points written, got %d", len(points), len(written))
+			}
+			if !tt.written && len(written) != 0 {
+				t.Errorf("expected no points written, got %d", len(written))
+			}
+		})
+	}
+}
+
+func TestPointsWriter_FailedWrite(t *testing.T) {
+	fail := true
+	var written int
+	w := status.NewPointsWriter(&mock.PointsWriter{
+		WritePointsFn: func(ctx context.Context, p []models.Point) error {
+			if fail {
+				return errors.New("write failed")
+			}
+			written += len(p)
+			return nil
+		},
+	})
+
+	now := time.Now()
+	if err := w.WritePoints(context.Background(), statusPoints(t, "a", "crit", "1h0m0s", now)); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+
+	// The failed status is not the last status of the series.
+	fail = false
+	points := statusPoints(t, "a", "crit", "1h0m0s", now.Add(time.Minute))
+	if err := w.WritePoints(context.Background(), points); err != nil {
+		t.Fatal(err)
+	}
+	if written != len(points) {
+		t.Errorf("expected %d points written, got %d", len(points), written)
+	}
+}
@@ -0,0 +1,160 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// NotificationMessage is a message template of a message catalog with a
+// variant by locale, e.g. {"en": "${r._check_name} is down", "fr": ...}.
+type NotificationMessage struct {
+	Name      string            `json:"name"`
+	Templates map[string]string `json:"templates"`
+}
+
+// NotificationMessageCatalog holds the message templates the notification
+// rules of an organization can reference instead of their own, so that each
+// endpoint receives the messages of every rule in its own locale without
+// duplicating the rules by language.
+type NotificationMessageCatalog struct {
+	OrgID ID `json:"orgID"`
+	// DefaultLocale is the locale of the templates sent to endpoints and
+	// rules without a locale, or with a locale that has no template. Every
+	// message has a template in the default locale.
+	DefaultLocale string                `json:"defaultLocale"`
+	Messages      []NotificationMessage `json:"messages"`
+	CRUDLog
+}
+
+// Valid returns an error if the catalog is missing its organization or has
+// an invalid message.
+func (c *NotificationMessageCatalog) Valid() error {
+	if !c.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "orgID is required",
+		}
+	}
+	if len(c.Messages) == 0 {
+		return nil
+	}
+	if err := ValidLocale(c.DefaultLocale); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(c.Messages))
+	for i, m := range c.Messages {
+		if m.Name == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("message %d must have a name", i),
+			}
+		}
+		if names[m.Name] {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("message %q is defined more than once", m.Name),
+			}
+		}
+		names[m.Name] = true
+
+		for locale := range m.Templates {
+			if err := ValidLocale(locale); err != nil {
+				return err
+			}
+		}
+		if _, ok := lookupTemplate(m.Templates, c.DefaultLocale); !ok {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("message %q has no template in the default locale %q", m.Name, c.DefaultLocale),
+			}
+		}
+	}
+	return nil
+}
+
+// Message returns the message of the catalog with the name, if any.
+func (c *NotificationMessageCatalog) Message(name string) (NotificationMessage, bool) {
+	for _, m := range c.Messages {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return NotificationMessage{}, false
+}
+
+// Template returns the template of the message with the name in the first
+// of locales it has a template for, or else in the default locale. A locale
+// with a region, e.g. pt-BR, falls back to its language, e.g. pt. Empty
+// locales are skipped.
+func (c *NotificationMessageCatalog) Template(name string, locales ...string) (string, bool) {
+	m, ok := c.Message(name)
+	if !ok {
+		return "", false
+	}
+	for _, locale := range append(locales, c.DefaultLocale) {
+		if locale == "" {
+			continue
+		}
+		if t, ok := lookupTemplate(m.Templates, locale); ok {
+			return t, true
+		}
+		if i := strings.IndexByte(locale, '-'); i > 0 {
+			if t, ok := lookupTemplate(m.Templates, locale[:i]); ok {
+				return t, true
+			}
+		}
+	}
+	return "", false
+}
+
+// lookupTemplate returns the template of the locale, whose case is
+// insignificant.
+func lookupTemplate(templates map[string]string, locale string) (string, bool) {
+	if t, ok := templates[locale]; ok {
+		return t, true
+	}
+	for l, t := range templates {
+		if strings.EqualFold(l, locale) {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// ValidLocale returns an error if locale is not a language tag made of a
+// language of 2 or 3 letters and optional subtags, e.g. en, pt-BR or
+// zh-Hant-TW.
+func ValidLocale(locale string) error {
+	invalid := &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("locale %q is not a language tag, e.g. en or pt-BR", locale),
+	}
+	for i, tag := range strings.Split(locale, "-") {
+		if len(tag) == 0 || len(tag) > 8 || (i == 0 && (len(tag) < 2 || len(tag) > 3)) {
+			return invalid
+		}
+		for _, c := range tag {
+			letter := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+			digit := c >= '0' && c <= '9'
+			if !letter && !(digit && i > 0) {
+				return invalid
+			}
+		}
+	}
+	return nil
+}
+
+// NotificationMessageCatalogService stores the notification message catalogs
+// of organizations.
+type NotificationMessageCatalogService interface {
+	// FindNotificationMessageCatalog returns the message catalog of the
+	// organization. An organization without messages has an empty catalog.
+	FindNotificationMessageCatalog(ctx context.Context, orgID ID) (*NotificationMessageCatalog, error)
+
+	// PutNotificationMessageCatalog replaces the message catalog of the
+	// organization and regenerates the tasks of the rules referencing its
+	// messages.
+	PutNotificationMessageCatalog(ctx context.Context, c *NotificationMessageCatalog) error
+}
@@ -0,0 +1,147 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestNotificationMessageCatalog_Template(t *testing.T) {
+	c := &influxdb.NotificationMessageCatalog{
+		OrgID:         1,
+		DefaultLocale: "en",
+		Messages: []influxdb.NotificationMessage{
+			{
+				Name: "down",
+				Templates: map[string]string{
+					"en":    "${r._check_name} is down",
+					"fr":    "${r._check_name} est en panne",
+					"pt-BR": "${r._check_name} está fora do ar",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		locales []string
+		want    string
+		found   bool
+	}{
+		{name: "locale", message: "down", locales: []string{"fr"}, want: "${r._check_name} est en panne", found: true},
+		{name: "region", message: "down", locales: []string{"pt-BR"}, want: "${r._check_name} está fora do ar", found: true},
+		{name: "language of region", message: "down", locales: []string{"fr-CA"}, want: "${r._check_name} est en panne", found: true},
+		{name: "case insensitive", message: "down", locales: []string{"PT-br"}, want: "${r._check_name} está fora do ar", found: true},
+		{name: "first locale with template", message: "down", locales: []string{"de", "", "fr"}, want: "${r._check_name} est en panne", found: true},
+		{name: "default locale", message: "down", locales: []string{"de"}, want: "${r._check_name} is down", found: true},
+		{name: "no locale", message: "down", want: "${r._check_name} is down", found: true},
+		{name: "unknown message", message: "up", locales: []string{"en"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := c.Template(tt.message, tt.locales...)
+			if found != tt.found {
+				t.Fatalf("expected found %v, got %v", tt.found, found)
+			}
+			if got != tt.want {
+				t.Errorf("expected template %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNotificationMessageCatalog_Valid(t *testing.T) {
+	tests := []struct {
+		name    string
+		catalog influxdb.NotificationMessageCatalog
+		valid   bool
+	}{
+		{
+			name:    "empty catalog",
+			catalog: influxdb.NotificationMessageCatalog{OrgID: 1},
+			valid:   true,
+		},
+		{
+			name: "valid catalog",
+			catalog: influxdb.NotificationMessageCatalog{
+				OrgID:         1,
+				DefaultLocale: "en-US",
+				Messages: []influxdb.NotificationMessage{
+					{Name: "down", Templates: map[string]string{"en-us": "down", "zh-Hant-TW": "down"}},
+				},
+			},
+			valid: true,
+		},
+		{
+			name:    "missing organization",
+			catalog: influxdb.NotificationMessageCatalog{},
+		},
+		{
+			name: "missing default locale",
+			catalog: influxdb.NotificationMessageCatalog{
+				OrgID:    1,
+				Messages: []influxdb.NotificationMessage{{Name: "down", Templates: map[string]string{"en": "down"}}},
+			},
+		},
+		{
+			name: "missing name",
+			catalog: influxdb.NotificationMessageCatalog{
+				OrgID:         1,
+				DefaultLocale: "en",
+				Messages:      []influxdb.NotificationMessage{{Templates: map[string]string{"en": "down"}}},
+			},
+		},
+		{
+			name: "duplicate name",
+			catalog: influxdb.NotificationMessageCatalog{
+				OrgID:         1,
+				DefaultLocale: "en",
+				Messages: []influxdb.NotificationMessage{
+					{Name: "down", Templates: map[string]string{"en": "down"}},
+					{Name: "down", Templates: map[string]string{"en": "down"}},
+				},
+			},
+		},
+		{
+			name: "invalid locale",
+			catalog: influxdb.NotificationMessageCatalog{
+				OrgID:         1,
+				DefaultLocale: "en",
+				Messages:      []influxdb.NotificationMessage{{Name: "down", Templates: map[string]string{"en": "down", "english": "down"}}},
+			},
+		},
+		{
+			name: "missing default locale template",
+			catalog: influxdb.NotificationMessageCatalog{
+				OrgID:         1,
+				DefaultLocale: "en",
+				Messages:      []influxdb.NotificationMessage{{Name: "down", Templates: map[string]string{"fr": "en panne"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.catalog.Valid()
+			if tt.valid && err != nil {
+				t.Errorf("expected the catalog to be valid, got %v", err)
+			}
+			if !tt.valid && influxdb.ErrorCode(err) != influxdb.EInvalid {
+				t.Errorf("expected the catalog to be invalid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidLocale(t *testing.T) {
+	for _, locale := range []string{"en", "fil", "pt-BR", "zh-Hant-TW", "es-419"} {
+		if err := influxdb.ValidLocale(locale); err != nil {
+			t.Errorf("expected %q to be valid, got %v", locale, err)
+		}
+	}
+	for _, locale := range []string{"", "e", "engl", "en-", "-BR", "en_US", "1n", "en-toolongtag"} {
+		if err := influxdb.ValidLocale(locale); err == nil {
+			t.Errorf("expected %q to be invalid", locale)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// NotificationRoute routes the statuses whose tags match all of its matchers
+// to an endpoint.
+type NotificationRoute struct {
+	Matchers   []TagRule `json:"matchers"`
+	EndpointID ID        `json:"endpointID"`
+}
+
+// Matches returns true if tags match all of the matchers of the route.
+func (r NotificationRoute) Matches(tags map[string]string) bool {
+	for _, m := range r.Matchers {
+		v, ok := tags[m.Key]
+		switch m.Operator {
+		case Equal:
+			if !ok || v != m.Value {
+				return false
+			}
+		case NotEqual:
+			if ok && v == m.Value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// NotificationRoutingTable routes the statuses of the routed notification
+// rules of an organization to endpoints by their tags, so that a single rule
+// can notify the endpoint of each team, e.g. by the team tag. A status is
+// routed to the endpoint of the first route it matches; statuses matching no
+// route are sent to the endpoint of their rule.
+type NotificationRoutingTable struct {
+	OrgID  ID                  `json:"orgID"`
+	Routes []NotificationRoute `json:"routes"`
+	CRUDLog
+}
+
+// Valid returns an error if the table is missing its organization or has an
+// invalid route.
+func (t *NotificationRoutingTable) Valid() error {
+	if !t.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "orgID is required",
+		}
+	}
+	for i, r := range t.Routes {
+		if !r.EndpointID.Valid() {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("endpoint of route %d is invalid", i),
+			}
+		}
+		if len(r.Matchers) == 0 {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("route %d must have at least one matcher", i),
+			}
+		}
+		for _, m := range r.Matchers {
+			if err := m.Valid(); err != nil {
+				return err
+			}
+			if m.Operator != Equal && m.Operator != NotEqual {
+				return &Error{
+					Code: EInvalid,
+					Msg:  fmt.Sprintf("matchers of route %d must be equal or notequal", i),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Route returns the route of the statuses with tags, if any.
+func (t *NotificationRoutingTable) Route(tags map[string]string) (NotificationRoute, bool) {
+	for _, r := range t.Routes {
+		if r.Matches(tags) {
+			return r, true
+		}
+	}
+	return NotificationRoute{}, false
+}
+
+// NotificationRoutingService stores the notification routing tables of
+// organizations.
+type NotificationRoutingService interface {
+	// FindNotificationRoutingTable returns the routing table of the
+	// organization. An organization without routing has an empty table.
+	FindNotificationRoutingTable(ctx context.Context, orgID ID) (*NotificationRoutingTable, error)
+
+	// PutNotificationRoutingTable replaces the routing table of the
+	// organization and regenerates the tasks of its routed rules.
+	PutNotificationRoutingTable(ctx context.Context, t *NotificationRoutingTable) error
+}
@@ -31,6 +31,9 @@ type OnboardingRequest struct {
 	Bucket          string `json:"bucket"`
 	RetentionPeriod uint   `json:"retentionPeriodHrs,omitempty"`
 	Token           string `json:"token,omitempty"`
+	// Templates are the names of the templates configured by the operator
+	// to apply to the organization. All of them are applied when empty.
+	Templates []string `json:"templates,omitempty"`
 }
 
 func (r *OnboardingRequest) Valid() error {
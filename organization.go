@@ -3,6 +3,7 @@ package influxdb
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // Organization is an organization. 🎉
@@ -10,9 +11,38 @@ type Organization struct {
 	ID          ID     `json:"id,omitempty"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	// Freeze is set when the organization is frozen.
+	Freeze *OrgFreeze `json:"freeze,omitempty"`
 	CRUDLog
 }
 
+// Frozen returns true if the organization is frozen.
+func (o *Organization) Frozen() bool {
+	return o.Freeze != nil
+}
+
+// OrgFreeze is the freeze of an organization, which makes it read-only:
+// its resources can be read and its data queried, but the writes of points
+// and the changes to its resources are rejected. Organizations are frozen
+// for billing enforcement or to contain an incident.
+type OrgFreeze struct {
+	Reason   string    `json:"reason,omitempty"`
+	FrozenAt time.Time `json:"frozenAt"`
+}
+
+// ErrOrgFrozen is the error of the writes and changes rejected because their
+// organization is frozen.
+func ErrOrgFrozen(o *Organization) *Error {
+	msg := fmt.Sprintf("organization %q is frozen and read-only: writes and changes are rejected, queries are allowed", o.Name)
+	if o.Freeze != nil && o.Freeze.Reason != "" {
+		msg = fmt.Sprintf("%s; reason: %s", msg, o.Freeze.Reason)
+	}
+	return &Error{
+		Code: EForbidden,
+		Msg:  msg,
+	}
+}
+
 // errors of org
 var (
 	// ErrOrgNameisEmpty is error when org name is empty
@@ -56,6 +86,16 @@ type OrganizationService interface {
 	DeleteOrganization(ctx context.Context, id ID) error
 }
 
+// OrgFreezeService freezes and unfreezes organizations.
+type OrgFreezeService interface {
+	// FreezeOrganization freezes the organization for reason, or updates
+	// the reason of its freeze if it is frozen already.
+	FreezeOrganization(ctx context.Context, id ID, reason string) (*Organization, error)
+
+	// UnfreezeOrganization unfreezes the organization.
+	UnfreezeOrganization(ctx context.Context, id ID) (*Organization, error)
+}
+
 // OrganizationUpdate represents updates to a organization.
 // Only fields which are set are updated.
 type OrganizationUpdate struct {
@@ -69,6 +109,36 @@ var ErrInvalidOrgFilter = &Error{
 	Msg:  "Please provide either orgID or org",
 }
 
+// ResolveOrganization returns the organization identified by orgID, or else
+// by org, which is either the ID or the name of the organization. The API
+// resolves the organizations of requests with it, so that the org and orgID
+// parameters are interchangeable with the same errors everywhere.
+func ResolveOrganization(ctx context.Context, svc OrganizationService, orgID, org string) (*Organization, error) {
+	if orgID != "" {
+		id, err := IDFromString(orgID)
+		if err != nil {
+			return nil, &Error{
+				Code: EInvalid,
+				Msg:  "orgID is invalid",
+				Err:  err,
+			}
+		}
+		return svc.FindOrganization(ctx, OrganizationFilter{ID: id})
+	}
+	if org == "" {
+		return nil, ErrInvalidOrgFilter
+	}
+
+	if id, err := IDFromString(org); err == nil {
+		o, err := svc.FindOrganization(ctx, OrganizationFilter{ID: id})
+		if ErrorCode(err) != ENotFound {
+			return o, err
+		}
+		// The name of the organization may look like an ID.
+	}
+	return svc.FindOrganization(ctx, OrganizationFilter{Name: &org})
+}
+
 // OrganizationFilter represents a set of filter that restrict the returned results.
 type OrganizationFilter struct {
 	Name   *string
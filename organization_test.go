@@ -0,0 +1,54 @@
+package influxdb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+)
+
+func TestResolveOrganization(t *testing.T) {
+	orgs := []*influxdb.Organization{
+		{ID: 1, Name: "org1"},
+		// An organization named like the ID of another one.
+		{ID: 2, Name: "00000000000000ff"},
+	}
+	svc := mock.NewOrganizationService()
+	svc.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		for _, o := range orgs {
+			if (filter.ID != nil && *filter.ID == o.ID) || (filter.Name != nil && *filter.Name == o.Name) {
+				return o, nil
+			}
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "organization not found"}
+	}
+
+	tests := []struct {
+		name   string
+		orgID  string
+		org    string
+		wantID influxdb.ID
+		code   string
+	}{
+		{name: "orgID", orgID: "0000000000000001", wantID: 1},
+		{name: "org name", org: "org1", wantID: 1},
+		{name: "org ID", org: "0000000000000001", wantID: 1},
+		{name: "orgID before org", orgID: "0000000000000001", org: "missing", wantID: 1},
+		{name: "org name looking like an ID", org: "00000000000000ff", wantID: 2},
+		{name: "invalid orgID", orgID: "oops", code: influxdb.EInvalid},
+		{name: "missing org", code: influxdb.EInvalid},
+		{name: "unknown org", org: "missing", code: influxdb.ENotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := influxdb.ResolveOrganization(context.Background(), svc, tt.orgID, tt.org)
+			if code := influxdb.ErrorCode(err); code != tt.code {
+				t.Fatalf("expected error code %q, got %v", tt.code, err)
+			}
+			if tt.code == "" && o.ID != tt.wantID {
+				t.Fatalf("expected organization %s, got %s", tt.wantID, o.ID)
+			}
+		})
+	}
+}
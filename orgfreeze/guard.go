@@ -0,0 +1,118 @@
+// Package orgfreeze enforces the freezes of organizations on their data. The
+// points of frozen organizations cannot be written or deleted, whichever
+// path they take to the storage engine: the API, tasks, ingestion or
+// replication.
+package orgfreeze
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// OrgFinder finds the organizations whose freeze is enforced.
+type OrgFinder interface {
+	FindOrganizationByID(ctx context.Context, id influxdb.ID) (*influxdb.Organization, error)
+}
+
+// checkWritable returns an error if the organization is frozen.
+func checkWritable(ctx context.Context, orgs OrgFinder, orgID influxdb.ID) error {
+	o, err := orgs.FindOrganizationByID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if o.Frozen() {
+		return influxdb.ErrOrgFrozen(o)
+	}
+	return nil
+}
+
+var _ storage.PointsWriter = (*PointsWriter)(nil)
+
+// PointsWriter refuses the writes of points to the buckets of frozen
+// organizations.
+type PointsWriter struct {
+	next storage.PointsWriter
+	orgs OrgFinder
+}
+
+// NewPointsWriter wraps next so that frozen organizations cannot be written to.
+func NewPointsWriter(next storage.PointsWriter, orgs OrgFinder) *PointsWriter {
+	return &PointsWriter{
+		next: next,
+		orgs: orgs,
+	}
+}
+
+// WritePoints writes the points unless any of them is in a bucket of a
+// frozen organization, in which case none of them are written.
+func (w *PointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	checked := make(map[influxdb.ID]bool)
+	for _, p := range points {
+		orgID, _ := tsdb.DecodeNameSlice(p.Name())
+		if checked[orgID] {
+			continue
+		}
+		if err := checkWritable(ctx, w.orgs, orgID); err != nil {
+			return err
+		}
+		checked[orgID] = true
+	}
+	return w.next.WritePoints(ctx, points)
+}
+
+var _ influxdb.DeleteService = (*DeleteService)(nil)
+
+// DeleteService refuses the deletes of the data of frozen organizations.
+type DeleteService struct {
+	next influxdb.DeleteService
+	orgs OrgFinder
+}
+
+// NewDeleteService wraps next so that the data of frozen organizations cannot
+// be deleted.
+func NewDeleteService(next influxdb.DeleteService, orgs OrgFinder) *DeleteService {
+	return &DeleteService{
+		next: next,
+		orgs: orgs,
+	}
+}
+
+// DeleteBucketRangePredicate deletes data in [min, max] matching pred unless
+// the organization is frozen.
+func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
+	if err := checkWritable(ctx, s.orgs, orgID); err != nil {
+		return err
+	}
+	return s.next.DeleteBucketRangePredicate(ctx, orgID, bucketID, min, max, pred)
+}
+
+var _ influxdb.MeasurementDeleteService = (*MeasurementDeleteService)(nil)
+
+// MeasurementDeleteService refuses the deletes of the measurements of frozen
+// organizations.
+type MeasurementDeleteService struct {
+	next influxdb.MeasurementDeleteService
+	orgs OrgFinder
+}
+
+// NewMeasurementDeleteService wraps next so that the measurements of frozen
+// organizations cannot be deleted.
+func NewMeasurementDeleteService(next influxdb.MeasurementDeleteService, orgs OrgFinder) *MeasurementDeleteService {
+	return &MeasurementDeleteService{
+		next: next,
+		orgs: orgs,
+	}
+}
+
+// DeleteMeasurementRange deletes the measurement data in [min, max] unless the
+// organization is frozen.
+func (s *MeasurementDeleteService) DeleteMeasurementRange(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, min, max int64) error {
+	if err := checkWritable(ctx, s.orgs, orgID); err != nil {
+		return err
+	}
+	return s.next.DeleteMeasurementRange(ctx, orgID, bucketID, measurement, min, max)
+}
@@ -0,0 +1,75 @@
+package orgfreeze
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+const (
+	activeID influxdb.ID = 1
+	frozenID influxdb.ID = 2
+	bucketID influxdb.ID = 3
+)
+
+func newOrgService() *mock.OrganizationService {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.Organization, error) {
+		switch id {
+		case activeID:
+			return &influxdb.Organization{ID: id, Name: "active"}, nil
+		case frozenID:
+			return &influxdb.Organization{ID: id, Name: "frozen", Freeze: &influxdb.OrgFreeze{Reason: "unpaid"}}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "organization not found"}
+	}
+	return orgs
+}
+
+func point(orgID influxdb.ID) models.Point {
+	return models.MustNewPoint(tsdb.EncodeNameString(orgID, bucketID), nil, models.Fields{"f": 1.0}, time.Unix(0, 0))
+}
+
+func TestPointsWriter(t *testing.T) {
+	next := &mock.PointsWriter{}
+	w := NewPointsWriter(next, newOrgService())
+	ctx := context.Background()
+
+	if err := w.WritePoints(ctx, []models.Point{point(activeID)}); err != nil {
+		t.Fatalf("unexpected error writing to an active organization: %v", err)
+	}
+	err := w.WritePoints(ctx, []models.Point{point(activeID), point(frozenID)})
+	if influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("expected a forbidden error writing to a frozen organization, got %v", err)
+	}
+	if len(next.Points) != 1 {
+		t.Errorf("expected only the point of the active organization written, got %d points", len(next.Points))
+	}
+}
+
+func TestDeleteService(t *testing.T) {
+	deleted := 0
+	next := &mock.DeleteService{
+		DeleteBucketRangePredicateF: func(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
+			deleted++
+			return nil
+		},
+	}
+	s := NewDeleteService(next, newOrgService())
+	ctx := context.Background()
+
+	if err := s.DeleteBucketRangePredicate(ctx, activeID, bucketID, 0, 1, nil); err != nil {
+		t.Fatalf("unexpected error deleting from an active organization: %v", err)
+	}
+	if err := s.DeleteBucketRangePredicate(ctx, frozenID, bucketID, 0, 1, nil); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("expected a forbidden error deleting from a frozen organization, got %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected one delete, got %d", deleted)
+	}
+}
@@ -83,6 +83,95 @@ func constantSetupTable(table *CsvTable, row []string) error {
 	return nil
 }
 
+// concatSetupTable setups the supplied CSV table from #concat annotation
+func concatSetupTable(table *CsvTable, row []string) error {
+	// adds a virtual column with a value computed from other columns of data rows
+	// supported types of concat annotation rows are:
+	//  1. "#concat,datatype,label,template"
+	//  2. "#concat datatype,label,template"
+	// template contains ${label} references to values of other columns, for example
+	// "#concat,tag,address,${host}:${port}"
+	col := CsvTableColumn{}
+	col.Index = -1 // this is a virtual column that never extracts data from data rows
+	col.setupDataType(row[0])
+	dataTypeIndex := 0
+	if len(col.DataType) == 0 && col.LinePart == 0 {
+		// type 1
+		dataTypeIndex = 1
+		if len(row) > 1 {
+			col.setupDataType(row[1])
+		}
+	}
+	if len(row) <= dataTypeIndex+2 {
+		return fmt.Errorf("#concat annotation: missing label or template in %v", row)
+	}
+	col.Label = row[dataTypeIndex+1]
+	template := row[dataTypeIndex+2]
+	col.computeValue = func(row []string) string {
+		return expandTemplate(template, func(label string) string {
+			if c := table.Column(label); c != nil {
+				return c.Value(row)
+			}
+			return ""
+		})
+	}
+	// add a virtual column to the table
+	table.extraColumns = append(table.extraColumns, &col)
+	return nil
+}
+
+// expandTemplate replaces ${label} references in the template with values returned by the supplied function
+func expandTemplate(template string, value func(label string) string) string {
+	if !strings.Contains(template, "${") {
+		return template
+	}
+	var builder strings.Builder
+	for {
+		start := strings.Index(template, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		builder.WriteString(template[:start])
+		builder.WriteString(value(template[start+2 : start+end]))
+		template = template[start+end+1:]
+	}
+	builder.WriteString(template)
+	return builder.String()
+}
+
+// scaleSetupTable setups the supplied CSV table from #scale annotation
+func scaleSetupTable(table *CsvTable, row []string) error {
+	// multiplies numeric values of a column by a factor, supported types of scale annotation rows are:
+	//  1. "#scale,label,factor"
+	//  2. "#scale label,factor"
+	var label, value string
+	if label = ignoreLeadingComment(row[0]); label != "" {
+		// type 2
+		if len(row) > 1 {
+			value = row[1]
+		}
+	} else if len(row) > 2 {
+		// type 1
+		label, value = row[1], row[2]
+	}
+	if label == "" || value == "" {
+		return fmt.Errorf("#scale annotation: missing label or factor in %v", row)
+	}
+	factor, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("#scale annotation: invalid factor of column '%s': %v", label, err)
+	}
+	if table.scales == nil {
+		table.scales = make(map[string]float64)
+	}
+	table.scales[label] = factor
+	return nil
+}
+
 // supportedAnnotations contains all supported CSV annotations comments
 var supportedAnnotations = []annotationComment{
 	{
@@ -115,6 +204,14 @@ var supportedAnnotations = []annotationComment{
 		prefix:     "#constant",
 		setupTable: constantSetupTable,
 	},
+	{
+		prefix:     "#concat",
+		setupTable: concatSetupTable,
+	},
+	{
+		prefix:     "#scale",
+		setupTable: scaleSetupTable,
+	},
 	{
 		prefix: "#timezone",
 		setupTable: func(table *CsvTable, row []string) error {
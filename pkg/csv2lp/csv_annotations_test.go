@@ -140,6 +140,77 @@ func Test_ConstantAnnotation(t *testing.T) {
 	}
 }
 
+// Test_ConcatAnnotation tests #concat annotation
+func Test_ConcatAnnotation(t *testing.T) {
+	subject := annotation("#concat")
+	require.True(t, subject.matches("#Concat"))
+	require.True(t, subject.isTableAnnotation())
+	var tests = []struct {
+		value          []string
+		expectLabel    string
+		expectValue    string
+		expectLinePart int
+	}{
+		{[]string{"#concat tag", "address", "${host}:${port}"}, "address", "h1:80", linePartTag},
+		{[]string{"#concat", "tag", "address", "${host}:${port}"}, "address", "h1:80", linePartTag},
+		{[]string{"#concat", "string", "full", "${host} ${unknown}!"}, "full", "h1 !", 0},
+		{[]string{"#concat", "field", "constant", "value"}, "constant", "value", linePartField},
+		{[]string{"#concat", "tag", "broken", "${host"}, "broken", "${host", linePartTag},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			table := &CsvTable{}
+			table.AddRow([]string{"host", "port"})
+			require.Nil(t, subject.setupTable(table, test.value))
+			require.Equal(t, 1, len(table.extraColumns))
+			col := table.extraColumns[0]
+			require.Equal(t, test.expectLinePart, col.LinePart)
+			require.Greater(t, 0, col.Index)
+			require.Equal(t, test.expectLabel, col.Label)
+			require.Equal(t, test.expectValue, col.Value([]string{"h1", "80"}))
+		})
+	}
+
+	t.Run("missing template", func(t *testing.T) {
+		table := &CsvTable{}
+		err := subject.setupTable(table, []string{"#concat", "tag", "address"})
+		require.NotNil(t, err)
+		require.Equal(t, 0, len(table.extraColumns))
+	})
+}
+
+// Test_ScaleAnnotation tests #scale annotation
+func Test_ScaleAnnotation(t *testing.T) {
+	subject := annotation("#scale")
+	require.True(t, subject.matches("#Scale"))
+	require.True(t, subject.isTableAnnotation())
+	var tests = []struct {
+		value  string
+		label  string
+		factor float64
+		err    string
+	}{
+		{"#scale,used,0.001", "used", 0.001, ""},
+		{"#scale used,1e3", "used", 1000, ""},
+		{"#scale,used", "", 0, "missing label or factor"},
+		{"#scale ", "", 0, "missing label or factor"},
+		{"#scale,used,x", "", 0, "invalid factor"},
+	}
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			table := &CsvTable{}
+			err := subject.setupTable(table, strings.Split(test.value, ","))
+			if test.err == "" {
+				require.Nil(t, err)
+				require.Equal(t, test.factor, table.scales[test.label])
+			} else {
+				require.NotNil(t, err)
+				require.True(t, strings.Contains(fmt.Sprintf("%v", err), test.err))
+			}
+		})
+	}
+}
+
 // Test_TimeZoneAnnotation tests #timezone annotation
 func Test_TimeZoneAnnotation(t *testing.T) {
 	subject := annotation("#timezone")
@@ -50,6 +50,10 @@ type CsvTableColumn struct {
 
 	// escapedLabel contains escaped label that can be directly used in line protocol
 	escapedLabel string
+	// computeValue computes the value of a virtual column from a data row, see #concat annotation
+	computeValue func(row []string) string
+	// scale multiplies numeric values of the column when not zero, see #scale annotation
+	scale float64
 }
 
 // LineLabel returns escaped name of the column so it can be then used as a tag name or field name in line protocol
@@ -62,6 +66,12 @@ func (c *CsvTableColumn) LineLabel() string {
 
 // Value returns the value of the column for the supplied row
 func (c *CsvTableColumn) Value(row []string) string {
+	if c.computeValue != nil {
+		if val := c.computeValue(row); len(val) > 0 {
+			return val
+		}
+		return c.DefaultValue
+	}
 	if c.Index < 0 || c.Index >= len(row) {
 		return c.DefaultValue
 	}
@@ -163,6 +173,8 @@ type CsvTable struct {
 	ignoreDataTypeInColumnName bool
 	// timeZone of dateTime column(s), applied when parsing dateTime value without a time zone specified
 	timeZone *time.Location
+	// scales contains factors of numeric columns by column label, see #scale annotation
+	scales map[string]float64
 
 	/* cached columns are initialized before reading the data rows using the computeLineProtocolColumns fn */
 	// cachedMeasurement is a required column that read (line protocol) measurement
@@ -213,6 +225,7 @@ func (t *CsvTable) NextTable() {
 	t.readTableData = false
 	t.columns = []*CsvTableColumn{}
 	t.extraColumns = []*CsvTableColumn{}
+	t.scales = nil
 }
 
 // createColumns create a slice of CsvTableColumn for the supplied rowSize
@@ -338,6 +351,7 @@ func (t *CsvTable) recomputeLineProtocolColumns() {
 	copy(columns[len(t.columns):], t.extraColumns)
 	for i := 0; i < len(columns); i++ {
 		col := columns[i]
+		col.scale = t.scales[col.Label]
 		switch {
 		case col.Label == labelMeasurement || col.LinePart == linePartMeasurement:
 			t.cachedMeasurement = col
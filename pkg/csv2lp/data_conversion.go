@@ -30,8 +30,48 @@ const (
 	RFC3339          = "RFC3339"
 	RFC3339Nano      = "RFC3339Nano"
 	dataFormatNumber = "number" //the same as long, but serialized without i suffix, used for timestamps
+	// epoch time formats, the value can contain a fraction
+	dataFormatUnix      = "unix"
+	dataFormatUnixMilli = "unixMilli"
+	dataFormatUnixMicro = "unixMicro"
+	dataFormatUnixNano  = "unixNano"
 )
 
+// epochUnits contains duration of units of epoch time formats
+var epochUnits = map[string]time.Duration{
+	dataFormatUnix:      time.Second,
+	dataFormatUnixMilli: time.Millisecond,
+	dataFormatUnixMicro: time.Microsecond,
+	dataFormatUnixNano:  time.Nanosecond,
+}
+
+// parseEpoch parses the supplied time since epoch in units, the value can contain a fraction
+func parseEpoch(val string, unit time.Duration) (time.Time, error) {
+	whole, fraction := val, ""
+	if dot := strings.IndexByte(val, '.'); dot >= 0 {
+		whole, fraction = val[:dot], val[dot+1:]
+	}
+	t, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if t > math.MaxInt64/int64(unit) || t < math.MinInt64/int64(unit) {
+		return time.Time{}, fmt.Errorf("time %s is out of range", val)
+	}
+	nanos := t * int64(unit)
+	if fraction != "" {
+		f, err := strconv.ParseFloat("0."+fraction, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if strings.HasPrefix(whole, "-") {
+			f = -f
+		}
+		nanos += int64(math.Round(f * float64(unit)))
+	}
+	return time.Unix(0, nanos).UTC(), nil
+}
+
 var supportedDataTypes map[string]struct{}
 
 func init() {
@@ -150,6 +190,12 @@ func toTypedValue(val string, column *CsvTableColumn) (interface{}, error) {
 				return nil, err
 			}
 			return time.Unix(0, t).UTC(), nil
+		case dataFormatUnix, dataFormatUnixMilli, dataFormatUnixMicro, dataFormatUnixNano:
+			t, err := parseEpoch(val, epochUnits[dataFormat])
+			if err != nil {
+				return nil, err
+			}
+			return t, nil
 		default:
 			if column.TimeZone != nil {
 				return time.ParseInLocation(dataFormat, val, column.TimeZone)
@@ -238,9 +284,36 @@ func appendConverted(buffer []byte, val string, column *CsvTableColumn) ([]byte,
 	if err != nil {
 		return buffer, err
 	}
+	if column.scale != 0 {
+		if typedVal, err = scaleValue(typedVal, column.scale); err != nil {
+			return buffer, err
+		}
+	}
 	return appendProtocolValue(buffer, typedVal)
 }
 
+// scaleValue multiplies the supplied numeric value by a factor, integer values are rounded
+func scaleValue(value interface{}, factor float64) (interface{}, error) {
+	switch v := value.(type) {
+	case float64:
+		return v * factor, nil
+	case int64:
+		scaled := math.Round(float64(v) * factor)
+		if scaled >= math.MaxInt64 || scaled < math.MinInt64 {
+			return nil, fmt.Errorf("scaled value %v is out of range", scaled)
+		}
+		return int64(scaled), nil
+	case uint64:
+		scaled := math.Round(float64(v) * factor)
+		if scaled >= math.MaxUint64 || scaled < 0 {
+			return nil, fmt.Errorf("scaled value %v is out of range", scaled)
+		}
+		return uint64(scaled), nil
+	default:
+		return nil, fmt.Errorf("unable to scale value of type %T", v)
+	}
+}
+
 func decodeNop(reader io.Reader) io.Reader {
 	return reader
 }
@@ -102,6 +102,12 @@ func Test_ToTypedValue(t *testing.T) {
 		{"dateTime:number", "3", epochTime.Add(time.Duration(3))},
 		{"dateTime", "4", epochTime.Add(time.Duration(4))},
 		{"dateTime:2006-01-02", "1970-01-01", epochTime},
+		{"dateTime:unix", "5", epochTime.Add(5 * time.Second)},
+		{"dateTime:unix", "-1.5", epochTime.Add(-1500 * time.Millisecond)},
+		{"dateTime:unixMilli", "6.25", epochTime.Add(6250 * time.Microsecond)},
+		{"dateTime:unixMicro", "7", epochTime.Add(7 * time.Microsecond)},
+		{"dateTime:unixNano", "8", epochTime.Add(8)},
+		{"dateTime:unix", "a.5", nil},
 		{"dateTime", "1970-01-01T00:00:00Z", epochTime},
 		{"dateTime", "1970-01-01T00:00:00.000000001Z", epochTime.Add(time.Duration(1))},
 		{"double:, .", "200 100.299,0", float64(200100299.0)},
@@ -221,6 +227,37 @@ func Test_AppendConverted(t *testing.T) {
 	}
 }
 
+// Test_AppendConverted_scale tests appendConverted function with scaled columns
+func Test_AppendConverted_scale(t *testing.T) {
+	var tests = []struct {
+		dataType string
+		scale    float64
+		value    string
+		expect   string
+	}{
+		{"double", 0.5, "3", "1.5"},
+		{"long", 0.001, "1500", "2i"},
+		{"unsignedLong", 1000, "2", "2000u"},
+		{"unsignedLong", -1, "2", ""},
+		{"long", 1e19, "1", ""},
+		{"string", 2, "a", ""},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			column := &CsvTableColumn{scale: test.scale}
+			column.setupDataType(test.dataType)
+			val, err := appendConverted(nil, test.value, column)
+			if test.expect == "" {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, test.expect, string(val))
+		})
+	}
+}
+
 // Test_IsTypeSupported tests IsTypeSupported function
 func Test_IsTypeSupported(t *testing.T) {
 	require.True(t, IsTypeSupported(stringDatatype), true)
@@ -164,6 +164,21 @@ test available=false 2
 test available=false 3
 test available=false 4
 test available=true 5
+`,
+	},
+	{
+		"columnTransforms",
+		`
+#constant measurement,weather
+#concat,tag,location,${city}/${station}
+#scale,temp,0.1
+city|ignored,station|ignored,temp|double,ts|dateTime:unix
+Prague,P1,215,1600000000
+Brno,B2,198,1600000000.5
+`,
+		`
+weather,location=Prague/P1 temp=21.5 1600000000000000000
+weather,location=Brno/B2 temp=19.8 1600000000500000000
 `,
 	},
 }
@@ -0,0 +1,396 @@
+// Package durablequeue implements a FIFO of batches of bytes kept on disk,
+// for the batches of points that are delivered to another system once it is
+// available.
+package durablequeue
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// defaultSegmentSize is the size in bytes above which the segment file
+	// a queue appends to is rolled.
+	defaultSegmentSize = 16 << 20
+
+	segmentExt   = ".seg"
+	positionFile = "position"
+
+	// recordHeaderSize is the size of the header of the records of the
+	// segments: the length of the record and its CRC-32 checksum.
+	recordHeaderSize = 8
+)
+
+// Queue is a durable FIFO of batches. The batches are appended as records
+// of segment files, and the position of the oldest batch not yet delivered
+// is kept in a file, so that the batches not delivered before influxd stops
+// are delivered once it restarts. When the segments exceed the maximum size
+// of the queue, the oldest are dropped.
+//
+// A queue is appended to concurrently, but read from a single goroutine.
+type Queue struct {
+	dir         string
+	maxSize     int64
+	segmentSize int64
+
+	mu       sync.Mutex
+	segments []*segment
+	// size is the size of the segments, of which the records before the
+	// head of the first one are delivered.
+	size int64
+	head int64
+	// next is the offset after the record returned by Peek, in the segment
+	// peeked.
+	next     int64
+	peeked   uint64
+	tail     *os.File
+	reader   *os.File
+	position *os.File
+	notify   chan struct{}
+}
+
+type segment struct {
+	id   uint64
+	size int64
+}
+
+// Open opens the queue in dir, creating it if it does not exist.
+func Open(dir string, maxSize int64) (*Queue, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	q := &Queue{
+		dir:         dir,
+		maxSize:     maxSize,
+		segmentSize: defaultSegmentSize,
+		notify:      make(chan struct{}, 1),
+	}
+	// At least two segments fit in the queue, so that the oldest can be
+	// dropped in favor of the newest.
+	if q.segmentSize > maxSize/2 {
+		q.segmentSize = maxSize / 2
+	}
+
+	if err := q.loadSegments(); err != nil {
+		return nil, err
+	}
+	if err := q.loadPosition(); err != nil {
+		q.Close()
+		return nil, err
+	}
+	return q, nil
+}
+
+func (q *Queue) segmentPath(id uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", id, segmentExt))
+}
+
+func (q *Queue) loadSegments() error {
+	fis, err := ioutil.ReadDir(q.dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range fis {
+		if !strings.HasSuffix(fi.Name(), segmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(fi.Name(), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		q.segments = append(q.segments, &segment{id: id, size: fi.Size()})
+		q.size += fi.Size()
+	}
+	sort.Slice(q.segments, func(i, j int) bool { return q.segments[i].id < q.segments[j].id })
+
+	if len(q.segments) == 0 {
+		return q.roll()
+	}
+
+	// The last record of the last segment may be incomplete if influxd
+	// stopped while it was appended.
+	last := q.segments[len(q.segments)-1]
+	f, err := os.OpenFile(q.segmentPath(last.id), os.O_RDWR, 0600)
+	if err != nil {
+		return err
+	}
+	valid, err := validSize(f, last.size)
+	if err == nil && valid < last.size {
+		err = f.Truncate(valid)
+		q.size -= last.size - valid
+		last.size = valid
+	}
+	if err == nil {
+		_, err = f.Seek(last.size, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	q.tail = f
+	return nil
+}
+
+// validSize returns the size of the complete and valid records at the start
+// of the segment file f of size bytes.
+func validSize(f *os.File, size int64) (int64, error) {
+	var off int64
+	for off < size {
+		n, err := readRecord(f, off, size, nil)
+		if err == errCorruptRecord {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		off += n
+	}
+	return off, nil
+}
+
+// loadPosition reads the position of the oldest batch not delivered and
+// removes the segments delivered before it.
+func (q *Queue) loadPosition() error {
+	f, err := os.OpenFile(filepath.Join(q.dir, positionFile), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	q.position = f
+
+	var buf [16]byte
+	if _, err := f.ReadAt(buf[:], 0); err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	id, head := binary.BigEndian.Uint64(buf[:8]), int64(binary.BigEndian.Uint64(buf[8:]))
+	for len(q.segments) > 1 && q.segments[0].id < id {
+		if err := q.removeOldest(); err != nil {
+			return err
+		}
+	}
+	if q.segments[0].id == id && head <= q.segments[0].size {
+		q.head = head
+	}
+	return nil
+}
+
+// roll starts a new segment to append to.
+func (q *Queue) roll() error {
+	var id uint64 = 1
+	if len(q.segments) > 0 {
+		id = q.segments[len(q.segments)-1].id + 1
+	}
+	f, err := os.OpenFile(q.segmentPath(id), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if q.tail != nil {
+		if err := q.tail.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+		q.tail.Close()
+	}
+	q.tail = f
+	q.segments = append(q.segments, &segment{id: id})
+	return nil
+}
+
+// removeOldest removes the oldest segment.
+func (q *Queue) removeOldest() error {
+	if q.reader != nil {
+		q.reader.Close()
+		q.reader = nil
+	}
+	seg := q.segments[0]
+	if err := os.Remove(q.segmentPath(seg.id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	q.segments = q.segments[1:]
+	q.size -= seg.size
+	q.head = 0
+	return nil
+}
+
+// Append appends the batch b to the queue. If the queue is full, its oldest
+// segments are dropped, and the number of bytes of their batches that were
+// not delivered is returned.
+func (q *Queue) Append(b []byte) (dropped int64, err error) {
+	n := int64(recordHeaderSize + len(b))
+	if n > q.maxSize {
+		return 0, ErrFull
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.segments[len(q.segments)-1].size >= q.segmentSize {
+		if err := q.roll(); err != nil {
+			return 0, err
+		}
+	}
+	for q.size+n > q.maxSize && len(q.segments) > 1 {
+		dropped += q.segments[0].size - q.head
+		if err := q.removeOldest(); err != nil {
+			return dropped, err
+		}
+	}
+	if q.size+n > q.maxSize {
+		return dropped, ErrFull
+	}
+
+	rec := make([]byte, n)
+	binary.BigEndian.PutUint32(rec[0:4], uint32(len(b)))
+	binary.BigEndian.PutUint32(rec[4:8], crc32.ChecksumIEEE(b))
+	copy(rec[recordHeaderSize:], b)
+	last := q.segments[len(q.segments)-1]
+	if _, err := q.tail.Write(rec); err != nil {
+		// A partial record would be read as the start of the next one.
+		if terr := q.tail.Truncate(last.size); terr == nil {
+			_, _ = q.tail.Seek(last.size, io.SeekStart)
+		}
+		return dropped, err
+	}
+	last.size += n
+	q.size += n
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return dropped, nil
+}
+
+// Peek returns the oldest batch not delivered, or io.EOF if there is none.
+// The batch is removed from the queue by Advance once it is delivered.
+func (q *Queue) Peek() ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		seg := q.segments[0]
+		if q.head < seg.size {
+			if q.reader == nil {
+				f, err := os.Open(q.segmentPath(seg.id))
+				if err != nil {
+					return nil, err
+				}
+				q.reader = f
+			}
+			var b []byte
+			n, err := readRecord(q.reader, q.head, seg.size, &b)
+			if err == errCorruptRecord {
+				// The rest of a corrupt segment is skipped, as the records
+				// after the corruption cannot be found.
+				q.head = seg.size
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			q.peeked, q.next = seg.id, q.head+n
+			return b, nil
+		}
+
+		if len(q.segments) == 1 {
+			return nil, io.EOF
+		}
+		if err := q.removeOldest(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Advance removes the batch returned by Peek from the queue, unless it was
+// dropped since.
+func (q *Queue) Advance() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.segments[0].id != q.peeked {
+		return nil
+	}
+	q.head = q.next
+
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], q.peeked)
+	binary.BigEndian.PutUint64(buf[8:], uint64(q.head))
+	_, err := q.position.WriteAt(buf[:], 0)
+	return err
+}
+
+// Wait returns a channel receiving once a batch is appended.
+func (q *Queue) Wait() <-chan struct{} {
+	return q.notify
+}
+
+// Pending returns the number of bytes of the batches not delivered.
+func (q *Queue) Pending() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size - q.head
+}
+
+// Close closes the files of the queue, which can be opened again.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var err error
+	if q.tail != nil {
+		err = q.tail.Sync()
+		q.tail.Close()
+	}
+	if q.reader != nil {
+		q.reader.Close()
+	}
+	if q.position != nil {
+		q.position.Close()
+	}
+	return err
+}
+
+// ErrFull is returned when a batch does not fit in a queue.
+var ErrFull = errors.New("queue is full")
+
+// errCorruptRecord is returned when a record is incomplete or does not
+// match its checksum.
+var errCorruptRecord = errors.New("corrupt queue record")
+
+// readRecord reads the record of the segment file f of size bytes at off
+// into b, if it is not nil. It returns the size of the record.
+func readRecord(f *os.File, off, size int64, b *[]byte) (int64, error) {
+	var hdr [recordHeaderSize]byte
+	if off+recordHeaderSize > size {
+		return 0, errCorruptRecord
+	}
+	if _, err := f.ReadAt(hdr[:], off); err != nil {
+		return 0, err
+	}
+	n := int64(binary.BigEndian.Uint32(hdr[0:4]))
+	if off+recordHeaderSize+n > size {
+		return 0, errCorruptRecord
+	}
+	data := make([]byte, n)
+	if _, err := f.ReadAt(data, off+recordHeaderSize); err != nil {
+		return 0, err
+	}
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(hdr[4:8]) {
+		return 0, errCorruptRecord
+	}
+	if b != nil {
+		*b = data
+	}
+	return recordHeaderSize + n, nil
+}
@@ -0,0 +1,132 @@
+package durablequeue
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func newTestQueue(t *testing.T, maxSize int64) (*Queue, string) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "durablequeue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := Open(dir, maxSize)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return q, dir
+}
+
+func mustPeek(t *testing.T, q *Queue, want string) {
+	t.Helper()
+
+	b, err := q.Peek()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != want {
+		t.Fatalf("unexpected batch %q, want %q", b, want)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	q, dir := newTestQueue(t, 1<<20)
+	defer os.RemoveAll(dir)
+
+	if _, err := q.Peek(); err != io.EOF {
+		t.Fatalf("expected an empty queue, got %v", err)
+	}
+	for _, b := range []string{"a", "b", "c"} {
+		if _, err := q.Append([]byte(b)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mustPeek(t, q, "a")
+	// A batch not advanced past is peeked again.
+	mustPeek(t, q, "a")
+	if err := q.Advance(); err != nil {
+		t.Fatal(err)
+	}
+	mustPeek(t, q, "b")
+	if err := q.Advance(); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The batches not delivered are kept when the queue is opened again.
+	q, err := Open(dir, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	mustPeek(t, q, "c")
+	if err := q.Advance(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Peek(); err != io.EOF {
+		t.Fatalf("expected an empty queue, got %v", err)
+	}
+	if got := q.Pending(); got != 0 {
+		t.Errorf("expected no pending bytes, got %d", got)
+	}
+}
+
+func TestQueue_TornRecord(t *testing.T) {
+	q, dir := newTestQueue(t, 1<<20)
+	defer os.RemoveAll(dir)
+
+	if _, err := q.Append([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	// A record partially written when influxd stopped.
+	if _, err := q.tail.Write([]byte{0, 0, 0, 9, 1}); err != nil {
+		t.Fatal(err)
+	}
+	q.Close()
+
+	q, err := Open(dir, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	if _, err := q.Append([]byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	mustPeek(t, q, "a")
+	if err := q.Advance(); err != nil {
+		t.Fatal(err)
+	}
+	mustPeek(t, q, "b")
+}
+
+func TestQueue_Full(t *testing.T) {
+	// Segments of 32 bytes, each holding two records of 16 bytes.
+	q, dir := newTestQueue(t, 64)
+	defer os.RemoveAll(dir)
+	defer q.Close()
+
+	var dropped int64
+	for _, b := range []string{"11111111", "22222222", "33333333", "44444444", "55555555"} {
+		n, err := q.Append([]byte(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dropped += n
+	}
+	if dropped != 32 {
+		t.Errorf("expected the oldest segment to be dropped, got %d bytes dropped", dropped)
+	}
+	mustPeek(t, q, "33333333")
+
+	if _, err := q.Append(make([]byte, 64)); err != ErrFull {
+		t.Errorf("expected a batch larger than the queue not to fit, got %v", err)
+	}
+}
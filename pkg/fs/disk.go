@@ -0,0 +1,11 @@
+package fs
+
+// DiskStatus is the usage of the volume holding a path.
+type DiskStatus struct {
+	// Device identifies the volume, 0 when it is unknown.
+	Device uint64
+	// Total is the size of the volume in bytes.
+	Total uint64
+	// Free is the number of bytes available to unprivileged users.
+	Free uint64
+}
@@ -0,0 +1,13 @@
+// +build !darwin,!freebsd,!linux,!windows
+
+package fs
+
+import (
+	"errors"
+)
+
+// DiskUsage returns the usage of the volume holding path. It is not supported
+// on this platform.
+func DiskUsage(path string) (DiskStatus, error) {
+	return DiskStatus{}, errors.New("disk usage is not supported on this platform")
+}
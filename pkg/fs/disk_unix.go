@@ -0,0 +1,29 @@
+// +build darwin freebsd linux
+
+package fs
+
+import (
+	"os"
+	"syscall"
+)
+
+// DiskUsage returns the usage of the volume holding path.
+func DiskUsage(path string) (DiskStatus, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return DiskStatus{}, err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return DiskStatus{}, err
+	}
+
+	status := DiskStatus{
+		Total: uint64(st.Blocks) * uint64(st.Bsize),
+		Free:  uint64(st.Bavail) * uint64(st.Bsize),
+	}
+	if sys, ok := fi.Sys().(*syscall.Stat_t); ok {
+		status.Device = uint64(sys.Dev)
+	}
+	return status, nil
+}
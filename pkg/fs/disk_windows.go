@@ -0,0 +1,20 @@
+package fs
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// DiskUsage returns the usage of the volume holding path. The device of the
+// volume is unknown.
+func DiskUsage(path string) (DiskStatus, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return DiskStatus{}, err
+	}
+
+	var free, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &free, &total, &totalFree); err != nil {
+		return DiskStatus{}, err
+	}
+	return DiskStatus{Total: total, Free: free}, nil
+}
@@ -1,11 +1,13 @@
 package httpc
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"net/http"
 	"net/url"
 	"path"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 )
@@ -39,6 +41,11 @@ type Client struct {
 	authFn   func(*http.Request) error
 	respFn   func(*http.Response) error
 	statusFn func(*http.Response) error
+
+	retry    *RetryPolicy
+	breakers *circuitBreakers
+	metrics  *serviceMetrics
+	timeout  time.Duration
 }
 
 // New creates a new httpc client.
@@ -72,6 +79,10 @@ func New(opts ...ClientOptFn) (*Client, error) {
 		authFn:         opt.authFn,
 		statusFn:       opt.statusFn,
 		writerFns:      opt.writerFns,
+		retry:          opt.retry,
+		breakers:       opt.breakers,
+		metrics:        opt.metrics,
+		timeout:        opt.timeout,
 	}, nil
 }
 
@@ -160,9 +171,10 @@ func (c *Client) Req(method string, bFn BodyFn, urlPath ...string) *Req {
 		return &Req{err: err}
 	}
 
+	// A bytes.Reader lets the body be read again when the request is retried.
 	var body io.Reader
 	if buf.Len() > 0 {
-		body = &buf
+		body = bytes.NewReader(buf.Bytes())
 	}
 
 	req, err := http.NewRequest(method, c.buildURL(urlPath...), body)
@@ -176,6 +188,10 @@ func (c *Client) Req(method string, bFn BodyFn, urlPath ...string) *Req {
 		authFn:   c.authFn,
 		respFn:   c.respFn,
 		statusFn: c.statusFn,
+		retry:    c.retry,
+		breakers: c.breakers,
+		metrics:  c.metrics,
+		timeout:  c.timeout,
 	}
 	return cr.Headers(headers)
 }
@@ -192,6 +208,13 @@ func (c *Client) Clone(opts ...ClientOptFn) (*Client, error) {
 		withDoer(c.doer),
 		WithRespFn(c.respFn),
 		WithStatusFn(c.statusFn),
+		// Clones share the circuit breakers of the hosts.
+		withCircuitBreakers(c.breakers),
+		withServiceMetrics(c.metrics),
+		WithTimeout(c.timeout),
+	}
+	if c.retry != nil {
+		existingOpts = append(existingOpts, WithRetryPolicy(*c.retry))
 	}
 	for h, vals := range c.defaultHeaders {
 		for _, v := range vals {
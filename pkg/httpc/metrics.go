@@ -0,0 +1,110 @@
+package httpc
+
+import (
+	"net/http/httptrace"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ClientMetrics are the prometheus metrics of the requests made by clients.
+// The metrics of every client are labeled with the service the client was
+// given in WithMetrics, so a single ClientMetrics can be shared by all the
+// clients of a process.
+type ClientMetrics struct {
+	requestDuration *prometheus.HistogramVec
+	retries         *prometheus.CounterVec
+	inFlight        *prometheus.GaugeVec
+	conns           *prometheus.CounterVec
+}
+
+// NewClientMetrics returns the metrics of clients. They must be registered
+// with the collectors returned by PrometheusCollectors.
+func NewClientMetrics() *ClientMetrics {
+	const (
+		namespace = "http"
+		subsystem = "client"
+	)
+	return &ClientMetrics{
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the requests made by the client, including reading the response",
+		}, []string{"service", "method", "status"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "retries_total",
+			Help:      "Number of requests retried after a transient error",
+		}, []string{"service", "method"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "requests_in_flight",
+			Help:      "Number of requests waiting for a response, and so of connections in use",
+		}, []string{"service"}),
+		conns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "connections_total",
+			Help:      "Number of connections obtained for requests, by whether they were reused from the pool",
+		}, []string{"service", "reused"}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *ClientMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.requestDuration,
+		m.retries,
+		m.inFlight,
+		m.conns,
+	}
+}
+
+// serviceMetrics are the metrics of the clients of a service.
+type serviceMetrics struct {
+	*ClientMetrics
+	service string
+}
+
+// trace starts observing a request with method. The returned trace records
+// the connection the request gets and the returned func records the outcome
+// of the request, given the status of the response or 0 if there was none.
+func (m *serviceMetrics) trace(method string) (*httptrace.ClientTrace, func(status int)) {
+	start := time.Now()
+	inFlight := m.inFlight.With(prometheus.Labels{"service": m.service})
+	inFlight.Inc()
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			m.conns.With(prometheus.Labels{
+				"service": m.service,
+				"reused":  strconv.FormatBool(info.Reused),
+			}).Inc()
+		},
+	}
+	return trace, func(status int) {
+		inFlight.Dec()
+		m.requestDuration.With(prometheus.Labels{
+			"service": m.service,
+			"method":  method,
+			"status":  statusLabel(status),
+		}).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (m *serviceMetrics) retry(method string) {
+	m.retries.With(prometheus.Labels{"service": m.service, "method": method}).Inc()
+}
+
+// statusLabel returns the label of a response status, or "error" if the
+// request got no response.
+func statusLabel(status int) string {
+	if status == 0 {
+		return "error"
+	}
+	return strconv.Itoa(status)
+}
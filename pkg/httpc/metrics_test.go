@@ -0,0 +1,97 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/prom/promtest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Metrics(t *testing.T) {
+	metrics := NewClientMetrics()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(metrics.PrometheusCollectors()...)
+
+	statuses := []int{0, http.StatusOK, http.StatusNotFound}
+	doer := &fakeDoer{}
+	doer.doFn = func(r *http.Request) (*http.Response, error) {
+		if statuses[doer.callCount-1] == 0 {
+			return nil, errors.New("connection reset by peer")
+		}
+		return stubResp(statuses[doer.callCount-1], r)
+	}
+	client, err := New(
+		WithAddr("http://example.com"),
+		WithMetrics(metrics, "buckets"),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}),
+		WithStatusFn(StatusIn(http.StatusOK)),
+		withDoer(doer),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, client.Get("/foo").Do(ctx))
+	require.Error(t, client.Get("/foo").Do(ctx))
+
+	mfs := promtest.MustGather(t, reg)
+	for status, count := range map[string]uint64{"error": 1, "200": 1, "404": 1} {
+		m := promtest.MustFindMetric(t, mfs, "http_client_request_duration_seconds", map[string]string{
+			"service": "buckets",
+			"method":  http.MethodGet,
+			"status":  status,
+		})
+		assert.Equal(t, count, m.GetHistogram().GetSampleCount(), status)
+	}
+
+	m := promtest.MustFindMetric(t, mfs, "http_client_retries_total", map[string]string{
+		"service": "buckets",
+		"method":  http.MethodGet,
+	})
+	assert.Equal(t, float64(1), m.GetCounter().GetValue())
+
+	m = promtest.MustFindMetric(t, mfs, "http_client_requests_in_flight", map[string]string{"service": "buckets"})
+	assert.Equal(t, float64(0), m.GetGauge().GetValue())
+}
+
+func TestClient_Timeout(t *testing.T) {
+	var deadline time.Time
+	doer := &fakeDoer{
+		doFn: func(r *http.Request) (*http.Response, error) {
+			var ok bool
+			deadline, ok = r.Context().Deadline()
+			require.True(t, ok, "the request has a deadline")
+			return stubResp(http.StatusOK, r)
+		},
+	}
+	client, err := New(
+		WithAddr("http://example.com"),
+		WithTimeout(time.Hour),
+		withDoer(doer),
+	)
+	require.NoError(t, err)
+
+	t.Run("the client timeout applies to contexts without deadline", func(t *testing.T) {
+		require.NoError(t, client.Get("/foo").Do(context.Background()))
+		assert.WithinDuration(t, time.Now().Add(time.Hour), deadline, time.Minute)
+	})
+
+	t.Run("the deadline of the context takes precedence", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+		defer cancel()
+		require.NoError(t, client.Get("/foo").Do(ctx))
+		assert.WithinDuration(t, time.Now().Add(2*time.Hour), deadline, time.Minute)
+	})
+
+	t.Run("clones keep the timeout", func(t *testing.T) {
+		clone, err := client.Clone()
+		require.NoError(t, err)
+		require.NoError(t, clone.Get("/foo").Do(context.Background()))
+		assert.WithinDuration(t, time.Now().Add(time.Hour), deadline, time.Minute)
+	})
+}
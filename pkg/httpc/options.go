@@ -3,6 +3,7 @@ package httpc
 import (
 	"compress/gzip"
 	"crypto/tls"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
@@ -21,6 +22,10 @@ type clientOpt struct {
 	respFn             func(*http.Response) error
 	statusFn           func(*http.Response) error
 	writerFns          []WriteCloserFn
+	retry              *RetryPolicy
+	breakers           *circuitBreakers
+	metrics            *serviceMetrics
+	timeout            time.Duration
 }
 
 // WithAddr sets the host address on the client.
@@ -125,6 +130,73 @@ func WithStatusFn(fn func(*http.Response) error) ClientOptFn {
 	}
 }
 
+// WithRetryPolicy retries the idempotent requests of the client that fail
+// with transient errors according to p.
+func WithRetryPolicy(p RetryPolicy) ClientOptFn {
+	return func(opt *clientOpt) error {
+		if p.MaxAttempts < 1 {
+			return fmt.Errorf("retry policy must allow at least 1 attempt, got %d", p.MaxAttempts)
+		}
+		opt.retry = &p
+		return nil
+	}
+}
+
+// WithCircuitBreaker fails the requests of the client to hosts that keep
+// failing immediately, according to p.
+func WithCircuitBreaker(p CircuitBreakerPolicy) ClientOptFn {
+	return func(opt *clientOpt) error {
+		if p.FailureThreshold < 1 {
+			return fmt.Errorf("circuit breaker failure threshold must be at least 1, got %d", p.FailureThreshold)
+		}
+		opt.breakers = newCircuitBreakers(p)
+		return nil
+	}
+}
+
+func withCircuitBreakers(b *circuitBreakers) ClientOptFn {
+	return func(opt *clientOpt) error {
+		opt.breakers = b
+		return nil
+	}
+}
+
+// WithMetrics records the requests of the client in m, labeled with service.
+func WithMetrics(m *ClientMetrics, service string) ClientOptFn {
+	return func(opt *clientOpt) error {
+		if m == nil {
+			opt.metrics = nil
+			return nil
+		}
+		opt.metrics = &serviceMetrics{ClientMetrics: m, service: service}
+		return nil
+	}
+}
+
+func withServiceMetrics(m *serviceMetrics) ClientOptFn {
+	return func(opt *clientOpt) error {
+		opt.metrics = m
+		return nil
+	}
+}
+
+// DefaultTimeout is a timeout that only requests waiting on an unresponsive
+// host reach.
+const DefaultTimeout = 5 * time.Minute
+
+// WithTimeout sets the timeout of the requests of the client whose context
+// has no deadline. The deadline of the context of a request takes precedence
+// over d. A timeout of 0 disables it.
+func WithTimeout(d time.Duration) ClientOptFn {
+	return func(opt *clientOpt) error {
+		if d < 0 {
+			return fmt.Errorf("timeout must not be negative, got %s", d)
+		}
+		opt.timeout = d
+		return nil
+	}
+}
+
 // WithWriterFn applies the provided writer behavior to all the request bodies'
 // generated from the client.
 func WithWriterFn(fn WriteCloserFn) ClientOptFn {
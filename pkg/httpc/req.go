@@ -9,7 +9,9 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptrace"
 	"strings"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
@@ -31,6 +33,11 @@ type Req struct {
 	respFn   func(*http.Response) error
 	statusFn func(*http.Response) error
 
+	retry    *RetryPolicy
+	breakers *circuitBreakers
+	metrics  *serviceMetrics
+	timeout  time.Duration
+
 	err error
 }
 
@@ -135,23 +142,80 @@ func (r *Req) StatusFn(fn func(*http.Response) error) *Req {
 // Do makes the HTTP request. Any errors that had been encountered in
 // the lifetime of the Req type will be returned here first, in place of
 // the call. This makes it safe to call Do at anytime.
+//
+// The request, including its retries, times out at the deadline of ctx, or
+// after the timeout of the client if ctx has no deadline.
 func (r *Req) Do(ctx context.Context) error {
 	if r.err != nil {
 		return r.err
 	}
 
+	if _, ok := ctx.Deadline(); !ok && r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
 	if err := r.authFn(r.req); err != nil {
 		return err
 	}
 
-	// TODO(@jsteenb2): wrap do with retry/backoff policy.
-	return r.do(ctx)
+	attempts := 1
+	if r.retry != nil && isIdempotent(r.req.Method) && (r.req.Body == nil || r.req.GetBody != nil) {
+		attempts = r.retry.MaxAttempts
+	}
+
+	host := r.req.URL.Host
+	for attempt := 0; ; attempt++ {
+		if r.breakers != nil {
+			if err := r.breakers.allow(host); err != nil {
+				return err
+			}
+		}
+
+		status, err := r.do(ctx)
+		transient := IsTransient(ctx, status, err)
+		if r.breakers != nil {
+			r.breakers.record(host, transient)
+		}
+		if !transient || attempt+1 >= attempts {
+			return err
+		}
+
+		// There is no point in waiting for a retry that would not start
+		// before the deadline.
+		wait := r.retry.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			return err
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+		if r.metrics != nil {
+			r.metrics.retry(r.req.Method)
+		}
+		if r.req.GetBody != nil {
+			body, err := r.req.GetBody()
+			if err != nil {
+				return err
+			}
+			r.req.Body = body
+		}
+	}
 }
 
-func (r *Req) do(ctx context.Context) error {
+// do makes the HTTP request once, returning the status of the response or 0
+// if there was none.
+func (r *Req) do(ctx context.Context) (status int, err error) {
 	span, ctx := tracing.StartSpanFromContextWithOperationName(ctx, r.req.URL.String())
 	defer span.Finish()
 
+	if r.metrics != nil {
+		trace, done := r.metrics.trace(r.req.Method)
+		ctx = httptrace.WithClientTrace(ctx, trace)
+		defer func() { done(status) }()
+	}
+
 	u := r.req.URL
 	span.LogKV(
 		"scheme", u.Scheme,
@@ -164,7 +228,7 @@ func (r *Req) do(ctx context.Context) error {
 
 	resp, err := r.client.Do(r.req.WithContext(ctx))
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer func() {
 		io.Copy(ioutil.Discard, resp.Body) // drain body completely
@@ -178,25 +242,25 @@ func (r *Req) do(ctx context.Context) error {
 
 	if r.respFn != nil {
 		if err := r.respFn(resp); err != nil {
-			return err
+			return resp.StatusCode, err
 		}
 	}
 
 	if r.statusFn != nil {
 		if err := r.statusFn(resp); err != nil {
-			return err
+			return resp.StatusCode, err
 		}
 	}
 
 	if r.decodeFn != nil {
 		if err := r.decodeFn(resp); err != nil {
-			return &influxdb.Error{
+			return resp.StatusCode, &influxdb.Error{
 				Code: influxdb.EInvalid,
 				Err:  err,
 			}
 		}
 	}
-	return nil
+	return resp.StatusCode, nil
 }
 
 // StatusIn validates the status code matches one of the provided statuses.
@@ -0,0 +1,161 @@
+package httpc
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// RetryPolicy configures the retries of idempotent requests that fail with
+// a network error or a 429, 502, 503 or 504 response.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is made.
+	MaxAttempts int
+	// MinBackoff and MaxBackoff bound the wait before each retry. The wait
+	// doubles with every attempt and is jittered.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy retries requests twice within a few seconds.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  100 * time.Millisecond,
+	MaxBackoff:  2 * time.Second,
+}
+
+// backoff returns the jittered wait before the retry following attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff << uint(attempt)
+	if d <= 0 || d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// CircuitBreakerPolicy configures the circuit breakers of the hosts a client
+// makes requests to. Once FailureThreshold requests in a row to a host fail,
+// requests to it fail immediately for ResetTimeout. A single request is then
+// let through to probe the host.
+type CircuitBreakerPolicy struct {
+	FailureThreshold int
+	ResetTimeout     time.Duration
+}
+
+// DefaultCircuitBreakerPolicy opens the circuit of a host after 5 failures
+// in a row for 30 seconds.
+var DefaultCircuitBreakerPolicy = CircuitBreakerPolicy{
+	FailureThreshold: 5,
+	ResetTimeout:     30 * time.Second,
+}
+
+// circuitBreakers holds the circuit breaker of every host.
+type circuitBreakers struct {
+	policy CircuitBreakerPolicy
+	now    func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]*circuitBreaker
+}
+
+type circuitBreaker struct {
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreakers(policy CircuitBreakerPolicy) *circuitBreakers {
+	return &circuitBreakers{
+		policy: policy,
+		now:    time.Now,
+		hosts:  make(map[string]*circuitBreaker),
+	}
+}
+
+// allow returns an error if requests to host must fail immediately.
+func (c *circuitBreakers) allow(host string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, ok := c.hosts[host]
+	if !ok || b.failures < c.policy.FailureThreshold {
+		return nil
+	}
+	if !b.probing && c.now().Sub(b.openedAt) >= c.policy.ResetTimeout {
+		b.probing = true
+		return nil
+	}
+	return &influxdb.Error{
+		Code: influxdb.EUnavailable,
+		Msg:  fmt.Sprintf("requests to %s are failing, retry later", host),
+	}
+}
+
+// record records the outcome of a request to host.
+func (c *circuitBreakers) record(host string, failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, ok := c.hosts[host]
+	if !ok {
+		if !failed {
+			return
+		}
+		b = &circuitBreaker{}
+		c.hosts[host] = b
+	}
+	if !failed {
+		delete(c.hosts, host)
+		return
+	}
+
+	b.failures++
+	b.probing = false
+	if b.failures >= c.policy.FailureThreshold {
+		b.openedAt = c.now()
+	}
+}
+
+// isIdempotent returns true if requests with method can be retried safely.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// IsTransient returns true if a request that failed with err after it
+// received status, or no response if status is 0, may succeed when retried.
+func IsTransient(ctx context.Context, status int, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	switch status {
+	case 0:
+		return true
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// sleep waits for d or until ctx is canceled.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
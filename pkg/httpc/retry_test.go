@@ -0,0 +1,108 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Retries(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	newClient := func(t *testing.T, statuses ...int) (*Client, *fakeDoer) {
+		t.Helper()
+		doer := &fakeDoer{}
+		doer.doFn = func(r *http.Request) (*http.Response, error) {
+			if r.Body != nil {
+				b, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, `{"name":"a"}`+"\n", string(b), "the body is sent again")
+			}
+			if doer.callCount > len(statuses) {
+				return nil, errors.New("unexpected request")
+			}
+			if statuses[doer.callCount-1] == 0 {
+				return nil, errors.New("connection reset by peer")
+			}
+			return stubResp(statuses[doer.callCount-1], r)
+		}
+		client, err := New(
+			WithAddr("http://example.com"),
+			WithRetryPolicy(policy),
+			WithStatusFn(StatusIn(http.StatusOK)),
+			withDoer(doer),
+		)
+		require.NoError(t, err)
+		return client, doer
+	}
+
+	t.Run("idempotent requests are retried", func(t *testing.T) {
+		client, doer := newClient(t, 0, http.StatusServiceUnavailable, http.StatusOK)
+		require.NoError(t, client.PutJSON(map[string]string{"name": "a"}, "/foo").Do(context.Background()))
+		assert.Equal(t, 3, doer.callCount)
+	})
+
+	t.Run("retries give up after the maximum attempts", func(t *testing.T) {
+		client, doer := newClient(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+		require.Error(t, client.Get("/foo").Do(context.Background()))
+		assert.Equal(t, 3, doer.callCount)
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		client, doer := newClient(t, http.StatusNotFound)
+		require.Error(t, client.Get("/foo").Do(context.Background()))
+		assert.Equal(t, 1, doer.callCount)
+	})
+
+	t.Run("non idempotent requests are not retried", func(t *testing.T) {
+		client, doer := newClient(t, http.StatusServiceUnavailable)
+		require.Error(t, client.PostJSON(map[string]string{"name": "a"}, "/foo").Do(context.Background()))
+		assert.Equal(t, 1, doer.callCount)
+	})
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	failing := true
+	doer := &fakeDoer{
+		doFn: func(r *http.Request) (*http.Response, error) {
+			if failing {
+				return nil, errors.New("connection refused")
+			}
+			return stubResp(http.StatusOK, r)
+		},
+	}
+	client, err := New(
+		WithAddr("http://example.com"),
+		WithCircuitBreaker(CircuitBreakerPolicy{FailureThreshold: 2, ResetTimeout: time.Minute}),
+		withDoer(doer),
+	)
+	require.NoError(t, err)
+	client.breakers.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		require.Error(t, client.Get("/foo").Do(ctx))
+	}
+	assert.Equal(t, 2, doer.callCount)
+
+	// The circuit is open.
+	err = client.Get("/foo").Do(ctx)
+	assert.Equal(t, influxdb.EUnavailable, influxdb.ErrorCode(err))
+	assert.Equal(t, 2, doer.callCount)
+
+	// A probe is let through once the reset timeout passed, and closes the
+	// circuit if it succeeds.
+	now = now.Add(time.Minute)
+	failing = false
+	require.NoError(t, client.Get("/foo").Do(ctx))
+	require.NoError(t, client.Get("/foo").Do(ctx))
+	assert.Equal(t, 4, doer.callCount)
+}
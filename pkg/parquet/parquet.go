@@ -0,0 +1,441 @@
+// Package parquet writes tables in the Apache Parquet columnar file format,
+// see https://github.com/apache/parquet-format. Only what exporting flat
+// tables needs is supported: a single level of optional columns, written
+// uncompressed with the plain encoding.
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// magic starts and ends every Parquet file.
+const magic = "PAR1"
+
+// createdBy is the application reported as the writer of the files.
+const createdBy = "influxdb"
+
+// Type is the logical type of the values of a column.
+type Type int
+
+const (
+	Boolean Type = iota
+	Int64
+	Uint64
+	Double
+	String
+	// Timestamp is a time in nanoseconds since the Unix epoch, in UTC.
+	Timestamp
+)
+
+func (t Type) String() string {
+	switch t {
+	case Boolean:
+		return "boolean"
+	case Int64:
+		return "int64"
+	case Uint64:
+		return "uint64"
+	case Double:
+		return "double"
+	case String:
+		return "string"
+	case Timestamp:
+		return "timestamp"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// Physical types, encodings and other enums of the Parquet format.
+const (
+	physicalBoolean   = 0
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	repetitionOptional = 1
+
+	convertedUTF8   = 0
+	convertedUint64 = 14
+
+	codecUncompressed = 0
+	pageTypeData      = 0
+)
+
+func (t Type) physical() int32 {
+	switch t {
+	case Boolean:
+		return physicalBoolean
+	case Double:
+		return physicalDouble
+	case String:
+		return physicalByteArray
+	default:
+		return physicalInt64
+	}
+}
+
+// Column is a column of the schema of a file. Every column is optional.
+type Column struct {
+	Name string
+	Type Type
+}
+
+// ColumnBuffer holds the values of a column of a row group. The values are
+// appended with the method of the type of the column, or as nulls.
+type ColumnBuffer struct {
+	Column
+
+	valid   []bool
+	bools   []bool
+	ints    []int64
+	floats  []float64
+	strings []string
+}
+
+// NewColumnBuffer returns an empty buffer of the values of column c.
+func NewColumnBuffer(c Column) *ColumnBuffer {
+	return &ColumnBuffer{Column: c}
+}
+
+// Len returns the number of values of the buffer, nulls included.
+func (b *ColumnBuffer) Len() int {
+	return len(b.valid)
+}
+
+// AppendNull appends a null.
+func (b *ColumnBuffer) AppendNull() {
+	b.valid = append(b.valid, false)
+}
+
+// AppendBool appends a value to a Boolean column.
+func (b *ColumnBuffer) AppendBool(v bool) {
+	b.valid = append(b.valid, true)
+	b.bools = append(b.bools, v)
+}
+
+// AppendInt appends a value to an Int64 or Timestamp column.
+func (b *ColumnBuffer) AppendInt(v int64) {
+	b.valid = append(b.valid, true)
+	b.ints = append(b.ints, v)
+}
+
+// AppendUint appends a value to a Uint64 column.
+func (b *ColumnBuffer) AppendUint(v uint64) {
+	b.valid = append(b.valid, true)
+	b.ints = append(b.ints, int64(v))
+}
+
+// AppendFloat appends a value to a Double column.
+func (b *ColumnBuffer) AppendFloat(v float64) {
+	b.valid = append(b.valid, true)
+	b.floats = append(b.floats, v)
+}
+
+// AppendString appends a value to a String column.
+func (b *ColumnBuffer) AppendString(v string) {
+	b.valid = append(b.valid, true)
+	b.strings = append(b.strings, v)
+}
+
+// page returns the data page of the values of the buffer: their definition
+// levels, run-length encoded, followed by the values that are not null.
+func (b *ColumnBuffer) page() []byte {
+	levels := encodeLevels(b.valid)
+	page := make([]byte, 4, 4+len(levels))
+	binary.LittleEndian.PutUint32(page, uint32(len(levels)))
+	page = append(page, levels...)
+
+	switch b.Type {
+	case Boolean:
+		packed := make([]byte, (len(b.bools)+7)/8)
+		for i, v := range b.bools {
+			if v {
+				packed[i/8] |= 1 << uint(i%8)
+			}
+		}
+		page = append(page, packed...)
+	case Double:
+		var v [8]byte
+		for _, f := range b.floats {
+			binary.LittleEndian.PutUint64(v[:], math.Float64bits(f))
+			page = append(page, v[:]...)
+		}
+	case String:
+		var n [4]byte
+		for _, s := range b.strings {
+			binary.LittleEndian.PutUint32(n[:], uint32(len(s)))
+			page = append(page, n[:]...)
+			page = append(page, s...)
+		}
+	default:
+		var v [8]byte
+		for _, i := range b.ints {
+			binary.LittleEndian.PutUint64(v[:], uint64(i))
+			page = append(page, v[:]...)
+		}
+	}
+	return page
+}
+
+// encodeLevels encodes the definition levels of optional values, 1 for the
+// values and 0 for the nulls, with runs of the RLE/bit-packing hybrid
+// encoding.
+func encodeLevels(valid []bool) []byte {
+	var (
+		buf []byte
+		n   [binary.MaxVarintLen64]byte
+	)
+	for i := 0; i < len(valid); {
+		j := i + 1
+		for j < len(valid) && valid[j] == valid[i] {
+			j++
+		}
+		buf = append(buf, n[:binary.PutUvarint(n[:], uint64(j-i)<<1)]...)
+		if valid[i] {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		i = j
+	}
+	return buf
+}
+
+type columnChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+type rowGroup struct {
+	columns []columnChunk
+	size    int64
+	numRows int64
+}
+
+// Writer writes a Parquet file of row groups of the columns of a schema.
+// The file is complete once the writer is closed.
+type Writer struct {
+	w         io.Writer
+	n         int64
+	schema    []Column
+	rowGroups []rowGroup
+	numRows   int64
+	metadata  [][2]string
+	err       error
+}
+
+// NewWriter returns a writer of a file of the columns of schema to w.
+func NewWriter(w io.Writer, schema []Column) *Writer {
+	return &Writer{w: w, schema: schema}
+}
+
+// SetMetadata sets the value of key in the key-value metadata of the file.
+func (w *Writer) SetMetadata(key, value string) {
+	for i, kv := range w.metadata {
+		if kv[0] == key {
+			w.metadata[i][1] = value
+			return
+		}
+	}
+	w.metadata = append(w.metadata, [2]string{key, value})
+}
+
+func (w *Writer) write(b []byte) error {
+	if w.err != nil {
+		return w.err
+	}
+	var n int
+	n, w.err = w.w.Write(b)
+	w.n += int64(n)
+	return w.err
+}
+
+// begin writes the magic starting the file if nothing was written yet.
+func (w *Writer) begin() error {
+	if w.n == 0 {
+		return w.write([]byte(magic))
+	}
+	return w.err
+}
+
+// WriteRowGroup writes a row group of the values of the columns, one buffer
+// per column of the schema in its order, all of the same length.
+func (w *Writer) WriteRowGroup(columns []*ColumnBuffer) error {
+	if len(columns) != len(w.schema) {
+		return fmt.Errorf("row group has %d columns, expected %d", len(columns), len(w.schema))
+	}
+	var numRows int
+	for i, c := range columns {
+		if c.Column != w.schema[i] {
+			return fmt.Errorf("column %d of row group is %s %s, expected %s %s", i, c.Name, c.Type, w.schema[i].Name, w.schema[i].Type)
+		}
+		if i == 0 {
+			numRows = c.Len()
+		} else if c.Len() != numRows {
+			return fmt.Errorf("column %s of row group has %d values, expected %d", c.Name, c.Len(), numRows)
+		}
+	}
+
+	if err := w.begin(); err != nil {
+		return err
+	}
+
+	rg := rowGroup{numRows: int64(numRows)}
+	for _, c := range columns {
+		page := c.page()
+		header := pageHeader(c.Len(), len(page))
+		chunk := columnChunk{
+			offset:    w.n,
+			size:      int64(len(header) + len(page)),
+			numValues: int64(c.Len()),
+		}
+		if err := w.write(header); err != nil {
+			return err
+		}
+		if err := w.write(page); err != nil {
+			return err
+		}
+		rg.columns = append(rg.columns, chunk)
+		rg.size += chunk.size
+	}
+	w.rowGroups = append(w.rowGroups, rg)
+	w.numRows += rg.numRows
+	return nil
+}
+
+// Close writes the metadata of the file, completing it.
+func (w *Writer) Close() error {
+	if len(w.schema) == 0 {
+		return errors.New("schema has no columns")
+	}
+	if err := w.begin(); err != nil {
+		return err
+	}
+
+	footer := w.fileMetadata()
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(footer)))
+	footer = append(footer, n[:]...)
+	footer = append(footer, magic...)
+	return w.write(footer)
+}
+
+func pageHeader(numValues, size int) []byte {
+	t := &thriftWriter{}
+	t.structBegin()
+	t.i32(1, pageTypeData)
+	t.i32(2, int32(size))
+	t.i32(3, int32(size))
+	t.structField(5)
+	t.i32(1, int32(numValues))
+	t.i32(2, encodingPlain)
+	t.i32(3, encodingRLE)
+	t.i32(4, encodingRLE)
+	t.structEnd()
+	t.structEnd()
+	return t.buf
+}
+
+func (w *Writer) fileMetadata() []byte {
+	t := &thriftWriter{}
+	t.structBegin()
+	t.i32(1, 1)
+
+	t.list(2, thriftStruct, len(w.schema)+1)
+	t.structBegin()
+	t.string(4, "schema")
+	t.i32(5, int32(len(w.schema)))
+	t.structEnd()
+	for _, c := range w.schema {
+		schemaElement(t, c)
+	}
+
+	t.i64(3, w.numRows)
+
+	t.list(4, thriftStruct, len(w.rowGroups))
+	for _, rg := range w.rowGroups {
+		t.structBegin()
+		t.list(1, thriftStruct, len(rg.columns))
+		for i, chunk := range rg.columns {
+			c := w.schema[i]
+			t.structBegin()
+			t.i64(2, chunk.offset)
+			t.structField(3)
+			t.i32(1, c.Type.physical())
+			t.list(2, thriftI32, 2)
+			t.i32Element(encodingPlain)
+			t.i32Element(encodingRLE)
+			t.list(3, thriftBinary, 1)
+			t.stringElement(c.Name)
+			t.i32(4, codecUncompressed)
+			t.i64(5, chunk.numValues)
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset)
+			t.structEnd()
+			t.structEnd()
+		}
+		t.i64(2, rg.size)
+		t.i64(3, rg.numRows)
+		t.structEnd()
+	}
+
+	if len(w.metadata) > 0 {
+		t.list(5, thriftStruct, len(w.metadata))
+		for _, kv := range w.metadata {
+			t.structBegin()
+			t.string(1, kv[0])
+			t.string(2, kv[1])
+			t.structEnd()
+		}
+	}
+	t.string(6, createdBy)
+	t.structEnd()
+	return t.buf
+}
+
+// schemaElement writes the element of the schema of column c, with the
+// converted type older readers understand and the logical type of the
+// newer ones.
+func schemaElement(t *thriftWriter, c Column) {
+	t.structBegin()
+	t.i32(1, c.Type.physical())
+	t.i32(3, repetitionOptional)
+	t.string(4, c.Name)
+	switch c.Type {
+	case String:
+		t.i32(6, convertedUTF8)
+		t.structField(10)
+		t.structField(1) // STRING
+		t.structEnd()
+		t.structEnd()
+	case Uint64:
+		t.i32(6, convertedUint64)
+		t.structField(10)
+		t.structField(10) // INTEGER
+		t.byte(1, 64)
+		t.bool(2, false)
+		t.structEnd()
+		t.structEnd()
+	case Timestamp:
+		t.structField(10)
+		t.structField(8) // TIMESTAMP
+		t.bool(1, true)
+		t.structField(2)
+		t.structField(3) // NANOS
+		t.structEnd()
+		t.structEnd()
+		t.structEnd()
+		t.structEnd()
+	}
+	t.structEnd()
+}
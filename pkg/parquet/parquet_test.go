@@ -0,0 +1,129 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestEncodeLevels(t *testing.T) {
+	got := encodeLevels([]bool{true, true, true, false, true})
+	// Runs of 3 values, 1 null and 1 value, each a varint of the length of
+	// the run shifted left by one followed by the level.
+	want := []byte{3 << 1, 1, 1 << 1, 0, 1 << 1, 1}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("unexpected levels: got %v want %v", got, want)
+	}
+}
+
+func TestColumnBuffer_page(t *testing.T) {
+	tests := []struct {
+		name   string
+		append func(b *ColumnBuffer)
+		typ    Type
+		values []byte
+	}{
+		{
+			name: "booleans are bit-packed",
+			typ:  Boolean,
+			append: func(b *ColumnBuffer) {
+				b.AppendBool(true)
+				b.AppendNull()
+				b.AppendBool(false)
+				b.AppendBool(true)
+			},
+			values: []byte{0x5},
+		},
+		{
+			name: "integers are little-endian",
+			typ:  Int64,
+			append: func(b *ColumnBuffer) {
+				b.AppendInt(-2)
+				b.AppendNull()
+			},
+			values: []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		},
+		{
+			name: "strings are prefixed with their length",
+			typ:  String,
+			append: func(b *ColumnBuffer) {
+				b.AppendNull()
+				b.AppendString("ab")
+			},
+			values: []byte{2, 0, 0, 0, 'a', 'b'},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewColumnBuffer(Column{Name: "c", Type: tt.typ})
+			tt.append(b)
+
+			page := b.page()
+			levels := encodeLevels(b.valid)
+			if n := binary.LittleEndian.Uint32(page); int(n) != len(levels) {
+				t.Fatalf("unexpected length of levels: got %d want %d", n, len(levels))
+			}
+			if got := page[4+len(levels):]; !bytes.Equal(got, tt.values) {
+				t.Fatalf("unexpected values: got %v want %v", got, tt.values)
+			}
+		})
+	}
+}
+
+func TestWriter(t *testing.T) {
+	schema := []Column{{Name: "time", Type: Timestamp}, {Name: "value", Type: Double}}
+	time, value := NewColumnBuffer(schema[0]), NewColumnBuffer(schema[1])
+	time.AppendInt(1)
+	value.AppendFloat(1.5)
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, schema)
+	w.SetMetadata("key", "value")
+	if err := w.WriteRowGroup([]*ColumnBuffer{time, value}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file := buf.Bytes()
+	if !bytes.HasPrefix(file, []byte(magic)) || !bytes.HasSuffix(file, []byte(magic)) {
+		t.Fatalf("file is not framed by %q", magic)
+	}
+	footer := binary.LittleEndian.Uint32(file[len(file)-8:])
+	metadata := file[len(file)-8-int(footer) : len(file)-8]
+	for _, s := range []string{"time", "value", "key", createdBy} {
+		if !bytes.Contains(metadata, []byte(s)) {
+			t.Errorf("metadata does not contain %q", s)
+		}
+	}
+	if got, want := w.rowGroups[0].columns[0].offset, int64(len(magic)); got != want {
+		t.Errorf("unexpected offset of the first column chunk: got %d want %d", got, want)
+	}
+	if got, want := w.rowGroups[0].columns[1].offset, w.rowGroups[0].columns[0].offset+w.rowGroups[0].columns[0].size; got != want {
+		t.Errorf("unexpected offset of the second column chunk: got %d want %d", got, want)
+	}
+}
+
+func TestWriter_WriteRowGroup_Invalid(t *testing.T) {
+	schema := []Column{{Name: "a", Type: Int64}, {Name: "b", Type: Int64}}
+	a, b := NewColumnBuffer(schema[0]), NewColumnBuffer(Column{Name: "b", Type: String})
+	a.AppendInt(1)
+	b.AppendString("1")
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, schema)
+	if err := w.WriteRowGroup([]*ColumnBuffer{a}); err == nil {
+		t.Error("expected an error about the number of columns")
+	}
+	if err := w.WriteRowGroup([]*ColumnBuffer{a, b}); err == nil {
+		t.Error("expected an error about the type of a column")
+	}
+	b = NewColumnBuffer(schema[1])
+	if err := w.WriteRowGroup([]*ColumnBuffer{a, b}); err == nil {
+		t.Error("expected an error about the length of a column")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing written by invalid row groups, got %d bytes", buf.Len())
+	}
+}
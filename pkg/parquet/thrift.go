@@ -0,0 +1,117 @@
+package parquet
+
+import (
+	"encoding/binary"
+)
+
+// Types of the fields of the Thrift compact protocol.
+const (
+	thriftBoolTrue  = 1
+	thriftBoolFalse = 2
+	thriftByte      = 3
+	thriftI32       = 5
+	thriftI64       = 6
+	thriftBinary    = 8
+	thriftList      = 9
+	thriftStruct    = 12
+)
+
+// thriftWriter encodes the structures of the metadata of Parquet files with
+// the Thrift compact protocol. Every struct, including the top-level one, is
+// begun with structBegin or structField and ended with structEnd, and its
+// fields are written in the order of their IDs.
+type thriftWriter struct {
+	buf []byte
+	// last are the IDs of the last fields written to the structs being
+	// written, innermost last.
+	last []int16
+}
+
+func (w *thriftWriter) field(id int16, typ byte) {
+	last := &w.last[len(w.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.varint(int64(id))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) varint(v int64) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf = append(w.buf, b[:binary.PutVarint(b[:], v)]...)
+}
+
+func (w *thriftWriter) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf = append(w.buf, b[:binary.PutUvarint(b[:], v)]...)
+}
+
+func (w *thriftWriter) bool(id int16, v bool) {
+	if v {
+		w.field(id, thriftBoolTrue)
+	} else {
+		w.field(id, thriftBoolFalse)
+	}
+}
+
+func (w *thriftWriter) byte(id int16, v int8) {
+	w.field(id, thriftByte)
+	w.buf = append(w.buf, byte(v))
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.varint(int64(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.varint(v)
+}
+
+func (w *thriftWriter) string(id int16, v string) {
+	w.field(id, thriftBinary)
+	w.uvarint(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// list begins a list of n elements of type typ. The elements are written
+// with the element methods, or as structs with structBegin.
+func (w *thriftWriter) list(id int16, typ byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|typ)
+	} else {
+		w.buf = append(w.buf, 0xf0|typ)
+		w.uvarint(uint64(n))
+	}
+}
+
+// i32Element writes an i32 element of a list.
+func (w *thriftWriter) i32Element(v int32) {
+	w.varint(int64(v))
+}
+
+// stringElement writes a string element of a list.
+func (w *thriftWriter) stringElement(v string) {
+	w.uvarint(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// structField begins a struct field. It is ended with structEnd.
+func (w *thriftWriter) structField(id int16) {
+	w.field(id, thriftStruct)
+	w.structBegin()
+}
+
+// structBegin begins a struct element of a list or the top-level struct.
+func (w *thriftWriter) structBegin() {
+	w.last = append(w.last, 0)
+}
+
+func (w *thriftWriter) structEnd() {
+	w.buf = append(w.buf, 0)
+	w.last = w.last[:len(w.last)-1]
+}
@@ -0,0 +1,200 @@
+// Package proxyproto implements version 2 of the PROXY protocol, with which
+// the load balancers forwarding TCP connections send the addresses of their
+// clients, so that the servers behind them see the clients' addresses instead
+// of the load balancers' ones.
+package proxyproto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultHeaderTimeout is the default time the clients have to send the
+// header of a connection.
+const DefaultHeaderTimeout = 10 * time.Second
+
+// signature starts the headers of version 2 of the protocol.
+var signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+const (
+	cmdLocal = 0x0
+	cmdProxy = 0x1
+
+	familyInet  = 0x1
+	familyInet6 = 0x2
+
+	// maxHeaderLength bounds the addresses and extensions of a header.
+	maxHeaderLength = 1 << 12
+)
+
+// ErrInvalidHeader is returned when a connection does not start with a
+// header of version 2 of the protocol.
+var ErrInvalidHeader = errors.New("proxyproto: invalid PROXY protocol v2 header")
+
+// Listener accepts the connections of load balancers sending the addresses of
+// their clients with the PROXY protocol. The connections of the networks not
+// trusted are accepted as they are.
+type Listener struct {
+	net.Listener
+
+	// Trusted are the networks of the load balancers, which must send the
+	// header. All networks are trusted if empty.
+	Trusted []*net.IPNet
+
+	// HeaderTimeout is the time the clients have to send the header.
+	HeaderTimeout time.Duration
+}
+
+// NewListener wraps ln to read the header of the connections of the trusted
+// networks.
+func NewListener(ln net.Listener, trusted []*net.IPNet) *Listener {
+	return &Listener{
+		Listener:      ln,
+		Trusted:       trusted,
+		HeaderTimeout: DefaultHeaderTimeout,
+	}
+}
+
+// Accept returns the next connection. Its header is read on the first call
+// to Read, RemoteAddr or LocalAddr, so that a slow client does not block the
+// others.
+func (l *Listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if !l.trusted(c.RemoteAddr()) {
+		return c, nil
+	}
+	return &Conn{Conn: c, timeout: l.HeaderTimeout}, nil
+}
+
+func (l *Listener) trusted(addr net.Addr) bool {
+	if len(l.Trusted) == 0 {
+		return true
+	}
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, n := range l.Trusted {
+		if n.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// Conn is a connection starting with a header of the PROXY protocol.
+type Conn struct {
+	net.Conn
+	timeout time.Duration
+
+	once     sync.Once
+	src, dst net.Addr
+	err      error
+}
+
+func (c *Conn) init() {
+	c.once.Do(func() {
+		if c.timeout > 0 {
+			if c.err = c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); c.err != nil {
+				return
+			}
+		}
+		c.src, c.dst, c.err = readHeader(c.Conn)
+		if c.err != nil {
+			return
+		}
+		if c.timeout > 0 {
+			c.err = c.Conn.SetReadDeadline(time.Time{})
+		}
+	})
+}
+
+// Read reads the data following the header.
+func (c *Conn) Read(b []byte) (int, error) {
+	if c.init(); c.err != nil {
+		return 0, c.err
+	}
+	return c.Conn.Read(b)
+}
+
+// RemoteAddr returns the address of the client sent by the load balancer,
+// or the address of the load balancer if it sent none.
+func (c *Conn) RemoteAddr() net.Addr {
+	if c.init(); c.src != nil {
+		return c.src
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr returns the address the client connected to, or the local
+// address of the connection if the load balancer sent none.
+func (c *Conn) LocalAddr() net.Addr {
+	if c.init(); c.dst != nil {
+		return c.dst
+	}
+	return c.Conn.LocalAddr()
+}
+
+// readHeader reads a header of version 2 of the protocol from r, and returns
+// the source and destination addresses it carries. They are nil for the
+// connections of the load balancers themselves, such as health checks, and
+// for the families of addresses other than IPv4 and IPv6.
+func readHeader(r io.Reader) (src, dst net.Addr, err error) {
+	var hdr [16]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(hdr[:12], signature) || hdr[12]>>4 != 2 {
+		return nil, nil, ErrInvalidHeader
+	}
+	cmd, family := hdr[12]&0xf, hdr[13]>>4
+
+	n := binary.BigEndian.Uint16(hdr[14:])
+	if n > maxHeaderLength {
+		return nil, nil, fmt.Errorf("proxyproto: header of %d bytes is too large", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, nil, err
+	}
+
+	switch cmd {
+	case cmdLocal:
+		return nil, nil, nil
+	case cmdProxy:
+	default:
+		return nil, nil, fmt.Errorf("proxyproto: unknown command %#x", cmd)
+	}
+
+	var size int
+	switch family {
+	case familyInet:
+		size = net.IPv4len
+	case familyInet6:
+		size = net.IPv6len
+	default:
+		// The addresses of other families are not TCP addresses.
+		return nil, nil, nil
+	}
+	if len(b) < 2*size+4 {
+		return nil, nil, ErrInvalidHeader
+	}
+	src = &net.TCPAddr{
+		IP:   net.IP(b[:size]),
+		Port: int(binary.BigEndian.Uint16(b[2*size:])),
+	}
+	dst = &net.TCPAddr{
+		IP:   net.IP(b[size : 2*size]),
+		Port: int(binary.BigEndian.Uint16(b[2*size+2:])),
+	}
+	return src, dst, nil
+}
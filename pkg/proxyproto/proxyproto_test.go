@@ -0,0 +1,167 @@
+package proxyproto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+// header returns a header of the command cmd carrying the addresses src and
+// dst.
+func header(cmd byte, src, dst *net.TCPAddr) []byte {
+	var b bytes.Buffer
+	b.Write(signature)
+	b.WriteByte(0x20 | cmd)
+
+	var addrs []byte
+	family := byte(0)
+	if src != nil {
+		srcIP, dstIP := src.IP.To4(), dst.IP.To4()
+		family = familyInet
+		if srcIP == nil {
+			srcIP, dstIP = src.IP.To16(), dst.IP.To16()
+			family = familyInet6
+		}
+		addrs = append(append(addrs, srcIP...), dstIP...)
+		var ports [4]byte
+		binary.BigEndian.PutUint16(ports[:], uint16(src.Port))
+		binary.BigEndian.PutUint16(ports[2:], uint16(dst.Port))
+		addrs = append(addrs, ports[:]...)
+	}
+	// A TLV extension, which is skipped.
+	addrs = append(addrs, 0x04, 0x00, 0x01, 0xff)
+
+	b.WriteByte(family<<4 | 0x1)
+	var n [2]byte
+	binary.BigEndian.PutUint16(n[:], uint16(len(addrs)))
+	b.Write(n[:])
+	b.Write(addrs)
+	return b.Bytes()
+}
+
+func TestReadHeader(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		header   []byte
+		src, dst string
+		err      bool
+	}{
+		{
+			name:   "ipv4",
+			header: header(cmdProxy, &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 51234}, &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 8086}),
+			src:    "203.0.113.7:51234",
+			dst:    "10.0.0.1:8086",
+		},
+		{
+			name:   "ipv6",
+			header: header(cmdProxy, &net.TCPAddr{IP: net.ParseIP("2001:db8::7"), Port: 51234}, &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 8086}),
+			src:    "[2001:db8::7]:51234",
+			dst:    "[2001:db8::1]:8086",
+		},
+		{
+			name:   "local",
+			header: header(cmdLocal, nil, nil),
+		},
+		{
+			name:   "version 1",
+			header: []byte("PROXY TCP4 203.0.113.7 10.0.0.1 51234 8086\r\n"),
+			err:    true,
+		},
+		{
+			name:   "truncated",
+			header: header(cmdProxy, &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 51234}, &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 8086})[:20],
+			err:    true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			src, dst, err := readHeader(bytes.NewReader(tt.header))
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an invalid header")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := addrString(src); got != tt.src {
+				t.Errorf("unexpected source %q, want %q", got, tt.src)
+			}
+			if got := addrString(dst); got != tt.dst {
+				t.Errorf("unexpected destination %q, want %q", got, tt.dst)
+			}
+		})
+	}
+}
+
+func addrString(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	return addr.String()
+}
+
+func TestListener(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		trusted string
+		header  []byte
+		remote  string
+	}{
+		{
+			name:   "trusted",
+			header: header(cmdProxy, &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 51234}, &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 8086}),
+			remote: "203.0.113.7:51234",
+		},
+		{
+			name:    "not trusted",
+			trusted: "192.0.2.0/24",
+			remote:  "127.0.0.1",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var trusted []*net.IPNet
+			if tt.trusted != "" {
+				_, n, _ := net.ParseCIDR(tt.trusted)
+				trusted = append(trusted, n)
+			}
+			pln := NewListener(ln, trusted)
+			defer pln.Close()
+
+			go func() {
+				c, err := net.Dial("tcp", ln.Addr().String())
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer c.Close()
+				c.Write(append(tt.header, "hello"...))
+			}()
+
+			c, err := pln.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+			c.SetDeadline(time.Now().Add(10 * time.Second))
+
+			if addr, ok := c.RemoteAddr().(*net.TCPAddr); !ok || (addr.String() != tt.remote && addr.IP.String() != tt.remote) {
+				t.Errorf("unexpected remote address %v, want %s", c.RemoteAddr(), tt.remote)
+			}
+			b, err := ioutil.ReadAll(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != "hello" {
+				t.Errorf("unexpected data %q", b)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Package tlscert serves TLS certificates loaded from files, reloaded when
+// the files change so that certificates are rotated without restarting the
+// servers and dropping their connections.
+package tlscert
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultReloadInterval is the default interval the files of certificates
+// are checked for changes at.
+const DefaultReloadInterval = 10 * time.Second
+
+// fileState is the hash of the contents of a pair of certificate and key
+// files. The contents are compared since files rewritten in the same tick of
+// the clock may keep their modification time and size.
+type fileState [sha256.Size]byte
+
+// readFiles returns the contents of the files certPath and keyPath, and their
+// state.
+func readFiles(certPath, keyPath string) (certPEM, keyPEM []byte, state fileState, err error) {
+	if certPEM, err = ioutil.ReadFile(certPath); err != nil {
+		return nil, nil, state, err
+	}
+	if keyPEM, err = ioutil.ReadFile(keyPath); err != nil {
+		return nil, nil, state, err
+	}
+	h := sha256.New()
+	h.Write(certPEM)
+	h.Write(keyPEM)
+	copy(state[:], h.Sum(nil))
+	return certPEM, keyPEM, state, nil
+}
+
+// Reloader serves the certificate of a pair of certificate and key files.
+// The certificate is reloaded when the files change or on SIGHUP. The
+// certificate loaded last is served until the files hold a valid pair again,
+// since they are rarely both written at once.
+type Reloader struct {
+	log      *zap.Logger
+	certPath string
+	keyPath  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+	// state is the state of the files last loaded, successfully or not,
+	// so that files are only loaded again once they change.
+	state fileState
+}
+
+// NewReloader loads the certificate of the files certPath and keyPath.
+func NewReloader(log *zap.Logger, certPath, keyPath string) (*Reloader, error) {
+	r := &Reloader{
+		log:      log.With(zap.String("cert", certPath)),
+		certPath: certPath,
+		keyPath:  keyPath,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the certificate loaded last. It is set as the
+// GetCertificate of the tls.Config of servers.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Reload loads the certificate of the files. The certificate loaded before
+// is kept if they do not hold a valid pair.
+func (r *Reloader) Reload() error {
+	certPEM, keyPEM, state, err := readFiles(r.certPath, r.keyPath)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.state = state
+	r.mu.Unlock()
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+
+	r.log.Info("Loaded TLS certificate",
+		zap.Strings("dns_names", cert.Leaf.DNSNames),
+		zap.Time("not_after", cert.Leaf.NotAfter),
+	)
+	return nil
+}
+
+// changed returns whether the files changed since they were last loaded.
+func (r *Reloader) changed() bool {
+	_, _, state, err := readFiles(r.certPath, r.keyPath)
+	if err != nil {
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return state != r.state
+}
+
+// Run reloads the certificate when the files change, as checked every
+// interval, or when the process receives SIGHUP, until ctx is done. Files
+// are only reloaded on SIGHUP if interval is zero.
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		case <-tick:
+			if !r.changed() {
+				continue
+			}
+		}
+		if err := r.Reload(); err != nil {
+			r.log.Warn("Failed to reload TLS certificate, keeping the current one", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package tlscert
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// writeCertificate writes a self-signed certificate of name and its key to
+// the files certPath and keyPath.
+func writeCertificate(t *testing.T, name, certPath, keyPath string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func servedName(t *testing.T, r *Reloader) string {
+	t.Helper()
+
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert.Leaf.Subject.CommonName
+}
+
+func TestReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlscert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certPath, keyPath := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+	writeCertificate(t, "old.example.com", certPath, keyPath)
+	r, err := NewReloader(zaptest.NewLogger(t), certPath, keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := servedName(t, r); name != "old.example.com" {
+		t.Fatalf("unexpected certificate %q", name)
+	}
+
+	// A certificate whose key is not written yet is not loaded.
+	newDir := filepath.Join(dir, "new")
+	if err := os.Mkdir(newDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	newCertPath, newKeyPath := filepath.Join(newDir, "cert.pem"), filepath.Join(newDir, "key.pem")
+	writeCertificate(t, "new.example.com", newCertPath, newKeyPath)
+	if err := os.Rename(newCertPath, certPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reload(); err == nil {
+		t.Fatal("expected a certificate without its key not to be loaded")
+	}
+	if name := servedName(t, r); name != "old.example.com" {
+		t.Fatalf("expected the current certificate to be kept, got %q", name)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx, 10*time.Millisecond)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	if err := os.Rename(newKeyPath, keyPath); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for servedName(t, r) != "new.example.com" {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the certificate to be reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
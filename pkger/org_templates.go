@@ -0,0 +1,102 @@
+package pkger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// OrgTemplates are the templates an operator provides to the organizations
+// created by onboarding, such as system monitoring or usage dashboards.
+type OrgTemplates struct {
+	svc       SVC
+	templates map[string]orgTemplate
+}
+
+type orgTemplate struct {
+	encoding Encoding
+	source   string
+	contents []byte
+}
+
+// NewOrgTemplates returns the templates of sources, keyed by their names and
+// read from files or http(s) urls. The templates are read and validated once,
+// so that a broken template fails at startup rather than on onboarding.
+func NewOrgTemplates(svc SVC, sources map[string]string) (*OrgTemplates, error) {
+	t := &OrgTemplates{
+		svc:       svc,
+		templates: make(map[string]orgTemplate, len(sources)),
+	}
+	for name, source := range sources {
+		readerFn := FromFile(source)
+		if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+			readerFn = FromHTTPRequest(source)
+		}
+		r, _, err := readerFn()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %q: %v", name, err)
+		}
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %q: %v", name, err)
+		}
+
+		tmpl := orgTemplate{
+			encoding: convertEncoding("", source),
+			source:   source,
+			contents: b,
+		}
+		if _, err := tmpl.parse(); err != nil {
+			return nil, fmt.Errorf("invalid template %q: %v", name, err)
+		}
+		t.templates[name] = tmpl
+	}
+	return t, nil
+}
+
+// parse returns a new template on every call, as applying a template
+// mutates it.
+func (t orgTemplate) parse() (*Template, error) {
+	return Parse(t.encoding, FromReader(bytes.NewReader(t.contents), t.source))
+}
+
+// Names returns the sorted names of the templates.
+func (t *OrgTemplates) Names() []string {
+	names := make([]string, 0, len(t.templates))
+	for name := range t.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyOrgTemplates applies the templates with names to the organization
+// orgID on behalf of the user userID.
+func (t *OrgTemplates) ApplyOrgTemplates(ctx context.Context, orgID, userID influxdb.ID, names []string) error {
+	for _, name := range names {
+		tmpl, ok := t.templates[name]
+		if !ok {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("unknown template %q", name),
+			}
+		}
+
+		template, err := tmpl.parse()
+		if err != nil {
+			return err
+		}
+		if _, err := t.svc.Apply(ctx, orgID, userID, ApplyWithTemplate(template)); err != nil {
+			return &influxdb.Error{
+				Msg: fmt.Sprintf("failed to apply template %q", name),
+				Err: err,
+			}
+		}
+	}
+	return nil
+}
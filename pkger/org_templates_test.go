@@ -0,0 +1,46 @@
+package pkger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgTemplates(t *testing.T) {
+	var applied []string
+	svc := &fakeSVC{
+		applyFn: func(ctx context.Context, orgID, userID influxdb.ID, opts ...pkger.ApplyOptFn) (pkger.ImpactSummary, error) {
+			var opt pkger.ApplyOpt
+			for _, o := range opts {
+				o(&opt)
+			}
+			for _, tmpl := range opt.Templates {
+				for _, d := range tmpl.Summary().Dashboards {
+					applied = append(applied, d.MetaName)
+				}
+			}
+			return pkger.ImpactSummary{}, nil
+		},
+	}
+
+	templates, err := pkger.NewOrgTemplates(svc, map[string]string{
+		"dashboards": "testdata/dashboard.yml",
+		"buckets":    "testdata/bucket.json",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"buckets", "dashboards"}, templates.Names())
+
+	err = templates.ApplyOrgTemplates(context.Background(), 1, 2, []string{"dashboards"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dash-1", "dash-2"}, applied)
+
+	err = templates.ApplyOrgTemplates(context.Background(), 1, 2, []string{"system"})
+	assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+
+	_, err = pkger.NewOrgTemplates(svc, map[string]string{"missing": "testdata/missing.yml"})
+	assert.Error(t, err)
+}
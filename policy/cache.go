@@ -0,0 +1,91 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+// DefaultCacheSize is the number of decisions kept by the caches of the
+// engines opened by the launcher.
+const DefaultCacheSize = 10000
+
+// Cache is an engine caching the decisions of another for a time to live.
+// Errors are not cached, so that checks keep failing closed until the
+// engine decides them again.
+type Cache struct {
+	engine authorizer.PolicyEngine
+	ttl    time.Duration
+	size   int
+	now    func() time.Time
+
+	mu        sync.Mutex
+	decisions map[string]decision
+}
+
+type decision struct {
+	allowed bool
+	expires time.Time
+}
+
+// NewCache returns an engine caching up to size decisions of e for ttl.
+func NewCache(e authorizer.PolicyEngine, ttl time.Duration, size int) *Cache {
+	return &Cache{
+		engine:    e,
+		ttl:       ttl,
+		size:      size,
+		now:       time.Now,
+		decisions: make(map[string]decision),
+	}
+}
+
+// Allowed returns the cached decision of the permission check in, or else
+// the decision of the engine.
+func (c *Cache) Allowed(ctx context.Context, in authorizer.PolicyInput) (bool, error) {
+	key := cacheKey(in)
+	now := c.now()
+
+	c.mu.Lock()
+	d, ok := c.decisions[key]
+	c.mu.Unlock()
+	if ok && now.Before(d.expires) {
+		return d.allowed, nil
+	}
+
+	allowed, err := c.engine.Allowed(ctx, in)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.decisions) >= c.size {
+		c.evict(now)
+	}
+	c.decisions[key] = decision{allowed: allowed, expires: now.Add(c.ttl)}
+	return allowed, nil
+}
+
+// evict removes the expired decisions, or else an arbitrary one to make room
+// for another.
+func (c *Cache) evict(now time.Time) {
+	for k, d := range c.decisions {
+		if !now.Before(d.expires) {
+			delete(c.decisions, k)
+		}
+	}
+	for k := range c.decisions {
+		if len(c.decisions) < c.size {
+			break
+		}
+		delete(c.decisions, k)
+	}
+}
+
+func cacheKey(in authorizer.PolicyInput) string {
+	a := in.Authorizer
+	return fmt.Sprintf("%s/%s/%s/%s/%t", a.Kind(), idString(a.Identifier()), idString(a.GetUserID()), in.Permission, in.Allowed)
+}
@@ -0,0 +1,66 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	"github.com/influxdata/influxdb/v2/mock"
+)
+
+type countingEngine struct {
+	calls   int
+	allowed bool
+	err     error
+}
+
+func (e *countingEngine) Allowed(context.Context, authorizer.PolicyInput) (bool, error) {
+	e.calls++
+	return e.allowed, e.err
+}
+
+func TestCache(t *testing.T) {
+	engine := &countingEngine{allowed: true}
+	c := NewCache(engine, time.Minute, 2)
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	in := authorizer.PolicyInput{
+		Authorizer: mock.NewMockAuthorizer(false, nil),
+		Permission: influxdb.Permission{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.BucketsResourceType}},
+	}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if ok, err := c.Allowed(ctx, in); err != nil || !ok {
+			t.Fatalf("expected the check allowed, got %t, %v", ok, err)
+		}
+	}
+	if engine.calls != 1 {
+		t.Errorf("expected the decision cached, the engine was called %d times", engine.calls)
+	}
+
+	now = now.Add(time.Minute)
+	engine.err = errors.New("timeout")
+	if _, err := c.Allowed(ctx, in); err == nil {
+		t.Fatal("expected the expired decision not used when the engine fails")
+	}
+	engine.err = nil
+	engine.allowed = false
+	if ok, _ := c.Allowed(ctx, in); ok {
+		t.Error("expected the new decision of the engine")
+	}
+
+	for _, rt := range []influxdb.ResourceType{influxdb.OrgsResourceType, influxdb.UsersResourceType} {
+		in.Permission.Resource.Type = rt
+		if _, err := c.Allowed(ctx, in); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(c.decisions) > 2 {
+		t.Errorf("expected at most 2 decisions cached, got %d", len(c.decisions))
+	}
+}
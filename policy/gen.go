@@ -0,0 +1,3 @@
+package policy
+
+//go:generate protoc -I ../internal -I . --plugin ../scripts/protoc-gen-gogofaster --gogofaster_out=plugins=grpc:. policy.proto
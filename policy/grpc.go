@@ -0,0 +1,42 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2/authorizer"
+	"google.golang.org/grpc"
+)
+
+// GRPC is an engine calling the PolicyService of a policy service.
+type GRPC struct {
+	client PolicyServiceClient
+}
+
+// NewGRPC returns an engine calling the policy service on cc.
+func NewGRPC(cc *grpc.ClientConn) *GRPC {
+	return &GRPC{client: NewPolicyServiceClient(cc)}
+}
+
+// Allowed returns the decision of the policy service for the permission
+// check in.
+func (g *GRPC) Allowed(ctx context.Context, in authorizer.PolicyInput) (bool, error) {
+	i := newInput(in)
+	req := &DecideRequest{
+		Subject: &Subject{
+			Kind:   i.Subject.Kind,
+			ID:     i.Subject.ID,
+			UserID: i.Subject.UserID,
+			OrgID:  i.Subject.OrgID,
+		},
+		Action:        string(i.Action),
+		ResourceType:  string(i.Resource.Type),
+		ResourceID:    i.Resource.ID,
+		ResourceOrgID: i.Resource.OrgID,
+		Allowed:       i.Allowed,
+	}
+	out, err := g.client.Decide(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	return out.Allowed, nil
+}
@@ -0,0 +1,63 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+// OPA is an engine querying the decision of a rule of an Open Policy Agent
+// through its data API, such as http://localhost:8181/v1/data/influxdb/allow.
+// The rule must be a boolean; the permission is denied if it is undefined.
+type OPA struct {
+	url    string
+	client *http.Client
+}
+
+// NewOPA returns an engine querying the rule at url.
+func NewOPA(url string) *OPA {
+	return &OPA{
+		url:    url,
+		client: http.DefaultClient,
+	}
+}
+
+type opaRequest struct {
+	Input input `json:"input"`
+}
+
+type opaResponse struct {
+	Result *bool `json:"result"`
+}
+
+// Allowed returns the decision of the rule for the permission check in.
+func (o *OPA) Allowed(ctx context.Context, in authorizer.PolicyInput) (bool, error) {
+	body, err := json.Marshal(opaRequest{Input: newInput(in)})
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("policy agent responded %s", resp.Status)
+	}
+
+	var out opaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return false, fmt.Errorf("invalid decision of the policy agent: %v", err)
+	}
+	return out.Result != nil && *out.Result, nil
+}
@@ -0,0 +1,65 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+func TestOPA(t *testing.T) {
+	var got opaRequest
+	var result string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		if result == "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(result))
+	}))
+	defer srv.Close()
+
+	orgID, bucketID := influxdb.ID(1), influxdb.ID(2)
+	in := authorizer.PolicyInput{
+		Authorizer: &influxdb.Authorization{ID: 3, OrgID: orgID, UserID: 4},
+		Permission: influxdb.Permission{
+			Action:   influxdb.WriteAction,
+			Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, ID: &bucketID, OrgID: &orgID},
+		},
+		Allowed: true,
+	}
+	opa := NewOPA(srv.URL + "/v1/data/influxdb/allow")
+	ctx := context.Background()
+
+	result = `{"result": true}`
+	if ok, err := opa.Allowed(ctx, in); err != nil || !ok {
+		t.Fatalf("expected the check allowed, got %t, %v", ok, err)
+	}
+	want := input{
+		Subject:  subject{Kind: "authorization", ID: "0000000000000003", UserID: "0000000000000004", OrgID: "0000000000000001"},
+		Action:   influxdb.WriteAction,
+		Resource: resource{Type: influxdb.BucketsResourceType, ID: "0000000000000002", OrgID: "0000000000000001"},
+		Allowed:  true,
+	}
+	if got.Input != want {
+		t.Errorf("unexpected input %+v, want %+v", got.Input, want)
+	}
+
+	// An undefined decision denies the check.
+	result = `{}`
+	if ok, err := opa.Allowed(ctx, in); err != nil || ok {
+		t.Errorf("expected the check denied, got %t, %v", ok, err)
+	}
+
+	result = ""
+	if _, err := opa.Allowed(ctx, in); err == nil {
+		t.Error("expected an error when the agent fails")
+	}
+}
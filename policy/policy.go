@@ -0,0 +1,151 @@
+// Package policy implements engines deciding the permission checks of the
+// authorizer package with a policy external to InfluxDB.
+//
+// An engine is opened from an address: an http or https URL is the decision
+// endpoint of an Open Policy Agent, a grpc or grpcs address is a policy
+// service implementing the PolicyService of policy.proto, and a plugin path
+// is a Go plugin exporting a NewPolicyEngine function. The decisions are
+// cached and the checks fail closed when the engine cannot decide them.
+package policy
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"plugin"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// PluginSymbol is the name of the function of Go plugins returning their
+// engine. It must be a func(config string) (authorizer.PolicyEngine, error),
+// called with the query of the address of the plugin.
+const PluginSymbol = "NewPolicyEngine"
+
+// Open returns the engine at address, whose decisions time out after
+// timeout.
+func Open(address string, timeout time.Duration) (authorizer.PolicyEngine, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy engine address %q: %v", address, err)
+	}
+
+	var e authorizer.PolicyEngine
+	switch u.Scheme {
+	case "http", "https":
+		e = NewOPA(address)
+	case "grpc", "grpcs":
+		opt := grpc.WithInsecure()
+		if u.Scheme == "grpcs" {
+			opt = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))
+		}
+		cc, err := grpc.Dial(u.Host, opt)
+		if err != nil {
+			return nil, err
+		}
+		e = NewGRPC(cc)
+	case "plugin":
+		if e, err = openPlugin(u.Path, u.RawQuery); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported policy engine address %q: the scheme must be http, https, grpc, grpcs or plugin", address)
+	}
+	return WithTimeout(e, timeout), nil
+}
+
+func openPlugin(path, config string) (authorizer.PolicyEngine, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, err
+	}
+	newEngine, ok := sym.(func(string) (authorizer.PolicyEngine, error))
+	if !ok {
+		return nil, fmt.Errorf("%s of plugin %s is a %T, not a func(string) (authorizer.PolicyEngine, error)", PluginSymbol, path, sym)
+	}
+	return newEngine(config)
+}
+
+// WithTimeout returns an engine whose decisions time out after timeout, or
+// e if timeout is not positive.
+func WithTimeout(e authorizer.PolicyEngine, timeout time.Duration) authorizer.PolicyEngine {
+	if timeout <= 0 {
+		return e
+	}
+	return &timeoutEngine{engine: e, timeout: timeout}
+}
+
+type timeoutEngine struct {
+	engine  authorizer.PolicyEngine
+	timeout time.Duration
+}
+
+func (e *timeoutEngine) Allowed(ctx context.Context, in authorizer.PolicyInput) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	return e.engine.Allowed(ctx, in)
+}
+
+// input is the input of the policy of a permission check.
+type input struct {
+	Subject  subject         `json:"subject"`
+	Action   influxdb.Action `json:"action"`
+	Resource resource        `json:"resource"`
+	// Allowed is true if the permissions of the subject allow the action.
+	Allowed bool `json:"allowed"`
+}
+
+// subject is the authorizer of a permission check.
+type subject struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id,omitempty"`
+	UserID string `json:"userID,omitempty"`
+	OrgID  string `json:"orgID,omitempty"`
+}
+
+type resource struct {
+	Type  influxdb.ResourceType `json:"type"`
+	ID    string                `json:"id,omitempty"`
+	OrgID string                `json:"orgID,omitempty"`
+}
+
+func newInput(in authorizer.PolicyInput) input {
+	a, r := in.Authorizer, in.Permission.Resource
+	s := subject{
+		Kind:   a.Kind(),
+		ID:     idString(a.Identifier()),
+		UserID: idString(a.GetUserID()),
+	}
+	if auth, ok := a.(*influxdb.Authorization); ok {
+		s.OrgID = idString(auth.OrgID)
+	}
+	res := resource{Type: r.Type}
+	if r.ID != nil {
+		res.ID = idString(*r.ID)
+	}
+	if r.OrgID != nil {
+		res.OrgID = idString(*r.OrgID)
+	}
+	return input{
+		Subject:  s,
+		Action:   in.Permission.Action,
+		Resource: res,
+		Allowed:  in.Allowed,
+	}
+}
+
+func idString(id influxdb.ID) string {
+	if !id.Valid() {
+		return ""
+	}
+	return id.String()
+}
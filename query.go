@@ -27,6 +27,10 @@ type FluxLanguageService interface {
 	// but it may be null if parsing didn't even occur.
 	Parse(source string) (*ast.Package, error)
 
+	// Analyze will parse and type check flux source code.
+	// The first error found is returned.
+	Analyze(source string) error
+
 	// EvalAST will evaluate and run an AST.
 	EvalAST(ctx context.Context, astPkg *ast.Package) ([]interpreter.SideEffect, values.Scope, error)
 
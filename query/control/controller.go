@@ -50,7 +50,8 @@ type Controller struct {
 	lastID     uint64
 	queriesMu  sync.RWMutex
 	queries    map[QueryID]*Query
-	queryQueue chan *Query
+	queryQueue *fairQueue
+	queryReady chan struct{}
 	wg         sync.WaitGroup
 	shutdown   bool
 	done       chan struct{}
@@ -92,7 +93,19 @@ type Config struct {
 	// QueueSize is the number of queries that are allowed to be awaiting execution before new queries are
 	// rejected.
 	QueueSize int
-	Logger    *zap.Logger
+
+	// MaxQueueSizePerTenant is the number of queries of a single tenant that are allowed to be awaiting
+	// execution before new queries of the tenant are rejected. If this is unset, then QueueSize is used.
+	MaxQueueSizePerTenant int
+
+	// QueueShares is the share of the queue of each tenant. Queries awaiting execution are executed in
+	// proportion to the shares of their tenants. Tenants without a share have a share of DefaultQueueShare.
+	QueueShares map[string]int
+
+	// QueueByAuthorization queues queries by the ID of their authorization rather than their organization.
+	QueueByAuthorization bool
+
+	Logger *zap.Logger
 	// MetricLabelKeys is a list of labels to add to the metrics produced by the controller.
 	// The value for a given key will be read off the context.
 	// The context value must be a string or an implementation of the Stringer interface.
@@ -136,6 +149,14 @@ func (c *Config) validate(isComplete bool) error {
 	if c.QueueSize <= 0 {
 		return errors.New("QueueSize must be positive")
 	}
+	if c.MaxQueueSizePerTenant < 0 {
+		return errors.New("MaxQueueSizePerTenant must be positive")
+	}
+	for tenant, share := range c.QueueShares {
+		if share <= 0 {
+			return fmt.Errorf("QueueShares must be positive: %s has a share of %d", tenant, share)
+		}
+	}
 	return nil
 }
 
@@ -161,7 +182,9 @@ func New(config Config) (*Controller, error) {
 		zap.Int64("initial_memory_bytes_quota_per_query", c.InitialMemoryBytesQuotaPerQuery),
 		zap.Int64("memory_bytes_quota_per_query", c.MemoryBytesQuotaPerQuery),
 		zap.Int64("max_memory_bytes", c.MaxMemoryBytes),
-		zap.Int("queue_size", c.QueueSize))
+		zap.Int("queue_size", c.QueueSize),
+		zap.Int("max_queue_size_per_tenant", c.MaxQueueSizePerTenant),
+		zap.Bool("queue_by_authorization", c.QueueByAuthorization))
 
 	mm := &memoryManager{
 		initialBytesQuotaPerQuery: c.InitialMemoryBytesQuotaPerQuery,
@@ -172,15 +195,17 @@ func New(config Config) (*Controller, error) {
 	} else {
 		mm.unlimited = true
 	}
+	metrics := newControllerMetrics(c.MetricLabelKeys)
 	ctrl := &Controller{
 		config:       c,
 		queries:      make(map[QueryID]*Query),
-		queryQueue:   make(chan *Query, c.QueueSize),
+		queryQueue:   newFairQueue(c.QueueSize, c.MaxQueueSizePerTenant, c.QueueShares, metrics.queueDepth),
+		queryReady:   make(chan struct{}, c.QueueSize),
 		done:         make(chan struct{}),
 		abort:        make(chan struct{}),
 		memory:       mm,
 		log:          logger,
-		metrics:      newControllerMetrics(c.MetricLabelKeys),
+		metrics:      metrics,
 		labelKeys:    c.MetricLabelKeys,
 		dependencies: c.ExecutorDependencies,
 	}
@@ -203,6 +228,12 @@ func (c *Controller) Query(ctx context.Context, req *query.Request) (flux.Query,
 	ctx = query.ContextWithRequest(ctx, req)
 	// Set the org label value for controller metrics
 	ctx = context.WithValue(ctx, orgLabel, req.OrganizationID.String()) //lint:ignore SA1029 this is a temporary ignore until we have time to create an appropriate type
+	// Set the tenant the query is queued for.
+	tenant := req.OrganizationID.String()
+	if c.config.QueueByAuthorization && req.Authorization != nil {
+		tenant = req.Authorization.ID.String()
+	}
+	ctx = context.WithValue(ctx, queueTenantKey{}, tenant)
 	// The controller injects the dependencies for each incoming request.
 	for _, dep := range c.dependencies {
 		ctx = dep.Inject(ctx)
@@ -363,14 +394,15 @@ func (c *Controller) enqueueQuery(q *Query) error {
 		}
 	}
 
-	select {
-	case c.queryQueue <- q:
-	default:
+	tenant, _ := q.parentCtx.Value(queueTenantKey{}).(string)
+	if !c.queryQueue.push(tenant, q) {
 		return &flux.Error{
 			Code: codes.ResourceExhausted,
 			Msg:  "queue length exceeded",
 		}
 	}
+	// The queue holds at most as many queries as the channel, so this never blocks.
+	c.queryReady <- struct{}{}
 
 	return nil
 }
@@ -380,8 +412,8 @@ func (c *Controller) processQueryQueue() {
 		select {
 		case <-c.done:
 			return
-		case q := <-c.queryQueue:
-			c.executeQuery(q)
+		case <-c.queryReady:
+			c.executeQuery(c.queryQueue.pop())
 		}
 	}
 }
@@ -498,6 +530,13 @@ func (c *Controller) Shutdown(ctx context.Context) error {
 	}
 }
 
+// QueueDepth returns the number of queries awaiting execution and the number
+// of them queued before rejecting queries.
+func (c *Controller) QueueDepth() (depth, capacity int64) {
+	n, size := c.queryQueue.length()
+	return int64(n), int64(size)
+}
+
 // PrometheusCollectors satisfies the prom.PrometheusCollector interface.
 func (c *Controller) PrometheusCollectors() []prometheus.Collector {
 	collectors := c.metrics.PrometheusCollectors()
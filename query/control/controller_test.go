@@ -814,6 +814,10 @@ func TestController_QueueSize(t *testing.T) {
 		}()
 	}
 
+	if depth, capacity := ctrl.QueueDepth(); depth != queueSize || capacity != queueSize {
+		t.Fatalf("expected a queue depth of %d/%d, got %d/%d", queueSize, queueSize, depth, capacity)
+	}
+
 	_, err = ctrl.Query(context.Background(), makeRequest(compiler))
 	if err == nil {
 		t.Fatal("expected an error about queue length exceeded")
@@ -0,0 +1,133 @@
+package control
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultQueueShare is the share of the queue of tenants without a configured share.
+const DefaultQueueShare = 1
+
+// queueTenantKey is the context key of the tenant a query is queued for.
+type queueTenantKey struct{}
+
+// fairQueue holds the queries awaiting execution in a queue per tenant, and
+// dequeues them with start-time fair queuing: every tenant is given a virtual
+// time that advances by the inverse of its share each time one of its queries
+// is dequeued, and the next query is taken from the tenant with the smallest
+// virtual time. A tenant with twice the share of another gets twice as many
+// queries executed while both have queries waiting, and a tenant with a burst
+// of queries does not delay the queries of the others by more than its share.
+type fairQueue struct {
+	mu         sync.Mutex
+	size       int // maximum number of queries in all queues
+	tenantSize int // maximum number of queries in the queue of a tenant
+	shares     map[string]int
+	tenants    map[string]*tenantQueue
+	len        int
+	vtime      float64 // virtual time of the last dequeued query
+
+	depth *prometheus.GaugeVec
+}
+
+type tenantQueue struct {
+	queries []*Query
+	vtime   float64
+	share   float64
+}
+
+func newFairQueue(size, tenantSize int, shares map[string]int, depth *prometheus.GaugeVec) *fairQueue {
+	if tenantSize <= 0 || tenantSize > size {
+		tenantSize = size
+	}
+	return &fairQueue{
+		size:       size,
+		tenantSize: tenantSize,
+		shares:     shares,
+		tenants:    make(map[string]*tenantQueue),
+		depth:      depth,
+	}
+}
+
+// push adds q to the queue of tenant. It returns false if the queue of the
+// tenant or the whole queue is full.
+func (fq *fairQueue) push(tenant string, q *Query) bool {
+	fq.mu.Lock()
+	defer fq.mu.Unlock()
+
+	if fq.len >= fq.size {
+		return false
+	}
+	t := fq.tenants[tenant]
+	if t == nil {
+		share, ok := fq.shares[tenant]
+		if !ok {
+			share = DefaultQueueShare
+		}
+		t = &tenantQueue{vtime: fq.vtime, share: float64(share)}
+		fq.tenants[tenant] = t
+	} else if len(t.queries) == 0 && t.vtime < fq.vtime {
+		// An idle tenant does not accumulate credit.
+		t.vtime = fq.vtime
+	}
+	if len(t.queries) >= fq.tenantSize {
+		return false
+	}
+
+	t.queries = append(t.queries, q)
+	fq.len++
+	fq.depth.WithLabelValues(tenant).Set(float64(len(t.queries)))
+	return true
+}
+
+// length returns the number of queries in all queues and the maximum number
+// of them.
+func (fq *fairQueue) length() (n, size int) {
+	fq.mu.Lock()
+	defer fq.mu.Unlock()
+	return fq.len, fq.size
+}
+
+// pop removes and returns the next query to execute, or nil if the queue is empty.
+func (fq *fairQueue) pop() *Query {
+	fq.mu.Lock()
+	defer fq.mu.Unlock()
+
+	var (
+		next string
+		nt   *tenantQueue
+	)
+	for name, t := range fq.tenants {
+		if len(t.queries) == 0 {
+			continue
+		}
+		if nt == nil || t.vtime < nt.vtime || (t.vtime == nt.vtime && name < next) {
+			next, nt = name, t
+		}
+	}
+	if nt == nil {
+		return nil
+	}
+
+	q := nt.queries[0]
+	nt.queries[0] = nil
+	nt.queries = nt.queries[1:]
+	fq.len--
+	fq.vtime = nt.vtime
+	nt.vtime += 1 / nt.share
+	if len(nt.queries) > 0 {
+		fq.depth.WithLabelValues(next).Set(float64(len(nt.queries)))
+	} else {
+		fq.depth.DeleteLabelValues(next)
+	}
+
+	// Forget the idle tenants that are not ahead of the others anymore, or
+	// all of them once the queue is empty.
+	for name, t := range fq.tenants {
+		if len(t.queries) == 0 && (fq.len == 0 || t.vtime <= fq.vtime) {
+			delete(fq.tenants, name)
+		}
+	}
+	return q
+}
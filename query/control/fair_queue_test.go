@@ -0,0 +1,93 @@
+package control
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newTestFairQueue(size, tenantSize int, shares map[string]int) *fairQueue {
+	return newFairQueue(size, tenantSize, shares, newControllerMetrics(nil).queueDepth)
+}
+
+func TestFairQueue_Shares(t *testing.T) {
+	fq := newTestFairQueue(100, 0, map[string]int{"alerts": 2})
+
+	queries := make(map[*Query]string)
+	push := func(tenant string, n int) {
+		for i := 0; i < n; i++ {
+			q := &Query{}
+			if !fq.push(tenant, q) {
+				t.Fatalf("unable to queue query of %s", tenant)
+			}
+			queries[q] = tenant
+		}
+	}
+	// A burst of dashboard queries is queued before the alerts.
+	push("dashboards", 30)
+	push("alerts", 10)
+
+	counts := make(map[string]int)
+	for i := 0; i < 15; i++ {
+		counts[queries[fq.pop()]]++
+	}
+	if counts["alerts"] != 10 || counts["dashboards"] != 5 {
+		t.Fatalf("unexpected queries executed: %v", counts)
+	}
+
+	for fq.pop() != nil {
+	}
+	if len(fq.tenants) != 0 {
+		t.Fatalf("expected idle tenants to be removed, got %d", len(fq.tenants))
+	}
+}
+
+func TestFairQueue_Size(t *testing.T) {
+	fq := newTestFairQueue(3, 2, nil)
+	if !fq.push("a", &Query{}) || !fq.push("a", &Query{}) {
+		t.Fatal("unable to queue queries")
+	}
+	if fq.push("a", &Query{}) {
+		t.Fatal("expected the queue of the tenant to be full")
+	}
+	if !fq.push("b", &Query{}) {
+		t.Fatal("unable to queue query of another tenant")
+	}
+	if fq.push("c", &Query{}) {
+		t.Fatal("expected the queue to be full")
+	}
+	fq.pop()
+	if !fq.push("c", &Query{}) {
+		t.Fatal("unable to queue query after dequeueing")
+	}
+}
+
+func TestFairQueue_IdleTenant(t *testing.T) {
+	fq := newTestFairQueue(100, 0, nil)
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(fq.depth)
+
+	a, b := &Query{}, &Query{}
+	for i := 0; i < 5; i++ {
+		fq.push("busy", a)
+	}
+	fq.pop()
+	fq.pop()
+
+	// A tenant becoming active does not wait for the backlog of the others.
+	fq.push("idle", b)
+	if q := fq.pop(); q != b {
+		t.Fatal("expected the query of the idle tenant to be dequeued first")
+	}
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mfs) != 1 || len(mfs[0].Metric) != 1 {
+		t.Fatalf("expected the depth of a single tenant, got %v", mfs)
+	}
+	if got := mfs[0].Metric[0].GetGauge().GetValue(); got != 3 {
+		t.Fatalf("got depth %v, expected 3", got)
+	}
+}
@@ -12,6 +12,7 @@ type controllerMetrics struct {
 	queueing     *prometheus.GaugeVec
 	executing    *prometheus.GaugeVec
 	memoryUnused *prometheus.GaugeVec
+	queueDepth   *prometheus.GaugeVec
 
 	allDur       *prometheus.HistogramVec
 	compilingDur *prometheus.HistogramVec
@@ -84,6 +85,13 @@ func newControllerMetrics(labels []string) *controllerMetrics {
 			Help:      "The free memory as seen by the internal memory manager",
 		}, labels),
 
+		queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "queue_depth",
+			Help:      "Number of queries awaiting execution per queue tenant",
+		}, []string{"tenant"}),
+
 		allDur: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: namespace,
 			Subsystem: subsystem,
@@ -129,6 +137,7 @@ func (cm *controllerMetrics) PrometheusCollectors() []prometheus.Collector {
 		cm.queueing,
 		cm.executing,
 		cm.memoryUnused,
+		cm.queueDepth,
 
 		cm.allDur,
 		cm.compilingDur,
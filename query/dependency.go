@@ -2,6 +2,8 @@ package query
 
 import (
 	"context"
+	"sort"
+	"time"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
@@ -12,16 +14,73 @@ import (
 func FromBucketService(srv influxdb.BucketService) *BucketLookup {
 	return &BucketLookup{
 		BucketService: srv,
+		SystemBuckets: influxdb.DefaultSystemBuckets(),
 	}
 }
 
 // BucketLookup converts Flux bucket lookups into influxdb.BucketService calls.
 type BucketLookup struct {
 	BucketService influxdb.BucketService
+	// BucketHistoryService resolves the names of buckets for requests with
+	// AsOf set. Without it, names are resolved as they are now.
+	BucketHistoryService influxdb.BucketHistoryService
+	// SystemBuckets are the system buckets served by BucketService, found
+	// by their names at any time.
+	SystemBuckets influxdb.SystemBuckets
+}
+
+// asOf returns the time the request on the context resolves bucket names at,
+// or nil to resolve them as they are now.
+func (b *BucketLookup) asOf(ctx context.Context) *time.Time {
+	if b.BucketHistoryService == nil {
+		return nil
+	}
+	if req := RequestFromContext(ctx); req != nil {
+		return req.AsOf
+	}
+	return nil
+}
+
+// namesAsOf returns the buckets of the organization that still exist by the
+// names they had at t. Buckets are looked up by ID through the bucket service,
+// so that only the buckets that are found now are returned.
+func (b *BucketLookup) namesAsOf(ctx context.Context, orgID influxdb.ID, t time.Time) (map[string]*influxdb.Bucket, error) {
+	names, err := b.BucketHistoryService.FindBucketNamesAsOf(ctx, orgID, t)
+	if err != nil {
+		return nil, err
+	}
+	buckets := make(map[string]*influxdb.Bucket, len(names))
+	for name, id := range names {
+		id := id
+		bucket, err := b.BucketService.FindBucket(ctx, influxdb.BucketFilter{
+			OrganizationID: &orgID,
+			ID:             &id,
+		})
+		if err != nil {
+			continue
+		}
+		bucket.Name = name
+		buckets[name] = bucket
+	}
+	return buckets, nil
 }
 
 // Lookup returns the bucket id and its existence given an org id and bucket name.
 func (b *BucketLookup) Lookup(ctx context.Context, orgID influxdb.ID, name string) (influxdb.ID, bool) {
+	if t := b.asOf(ctx); t != nil {
+		buckets, err := b.namesAsOf(ctx, orgID, *t)
+		if err != nil {
+			return influxdb.InvalidID(), false
+		}
+		if bucket, ok := buckets[name]; ok {
+			return bucket.ID, true
+		}
+		// system buckets are found by their names at any time.
+		if _, ok := b.SystemBuckets.BucketByName(orgID, name); !ok {
+			return influxdb.InvalidID(), false
+		}
+	}
+
 	filter := influxdb.BucketFilter{
 		OrganizationID: &orgID,
 		Name:           &name,
@@ -35,6 +94,19 @@ func (b *BucketLookup) Lookup(ctx context.Context, orgID influxdb.ID, name strin
 
 // LookupName returns an bucket name given its organization ID and its bucket ID.
 func (b *BucketLookup) LookupName(ctx context.Context, orgID influxdb.ID, id influxdb.ID) string {
+	if t := b.asOf(ctx); t != nil {
+		buckets, err := b.namesAsOf(ctx, orgID, *t)
+		if err != nil {
+			return ""
+		}
+		for name, bucket := range buckets {
+			if bucket.ID == id {
+				return name
+			}
+		}
+		return ""
+	}
+
 	filter := influxdb.BucketFilter{
 		OrganizationID: &orgID,
 		ID:             &id,
@@ -47,6 +119,24 @@ func (b *BucketLookup) LookupName(ctx context.Context, orgID influxdb.ID, id inf
 }
 
 func (b *BucketLookup) FindAllBuckets(ctx context.Context, orgID influxdb.ID) ([]*influxdb.Bucket, int) {
+	if t := b.asOf(ctx); t != nil {
+		buckets, err := b.namesAsOf(ctx, orgID, *t)
+		if err != nil {
+			return nil, 0
+		}
+		names := make([]string, 0, len(buckets))
+		for name := range buckets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		allBuckets := make([]*influxdb.Bucket, 0, len(names))
+		for _, name := range names {
+			allBuckets = append(allBuckets, buckets[name])
+		}
+		return allBuckets, len(allBuckets)
+	}
+
 	filter := influxdb.BucketFilter{
 		OrganizationID: &orgID,
 	}
@@ -66,6 +156,26 @@ func (b *BucketLookup) FindAllBuckets(ctx context.Context, orgID influxdb.ID) ([
 	return allBuckets, len(allBuckets)
 }
 
+// FromBucketRollupService wraps an influxdb.BucketRollupService in the RollupLookup interface.
+func FromBucketRollupService(srv influxdb.BucketRollupService) *RollupLookup {
+	return &RollupLookup{BucketRollupService: srv}
+}
+
+// RollupLookup converts Flux rollup lookups into influxdb.BucketRollupService calls.
+type RollupLookup struct {
+	BucketRollupService influxdb.BucketRollupService
+}
+
+// LookupRollup returns the rollup of a raw bucket and its existence given the
+// bucket ID.
+func (r *RollupLookup) LookupRollup(ctx context.Context, bucketID influxdb.ID) (*influxdb.BucketRollup, bool) {
+	rollup, err := r.BucketRollupService.FindBucketRollup(ctx, bucketID)
+	if err != nil {
+		return nil, false
+	}
+	return rollup, true
+}
+
 // FromOrganizationService wraps a influxdb.OrganizationService in the OrganizationLookup interface.
 func FromOrganizationService(srv influxdb.OrganizationService) *OrganizationLookup {
 	return &OrganizationLookup{OrganizationService: srv}
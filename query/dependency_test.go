@@ -3,6 +3,7 @@ package query_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/mock"
@@ -31,3 +32,52 @@ func TestSecretLookup(t *testing.T) {
 		t.Errorf("unexpected secret value -want/+got:\n\t- %v\n\t+ %v", want, got)
 	}
 }
+
+type bucketHistoryService func(ctx context.Context, orgID influxdb.ID, t time.Time) (map[string]influxdb.ID, error)
+
+func (f bucketHistoryService) FindBucketNamesAsOf(ctx context.Context, orgID influxdb.ID, t time.Time) (map[string]influxdb.ID, error) {
+	return f(ctx, orgID, t)
+}
+
+func TestBucketLookup_asOf(t *testing.T) {
+	renamedAt := time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		if filter.Name != nil && *filter.Name == "new" || filter.ID != nil && *filter.ID == 1 {
+			return &influxdb.Bucket{ID: 1, OrgID: orgID, Name: "new"}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound}
+	}
+	lookup := query.FromBucketService(buckets)
+	lookup.BucketHistoryService = bucketHistoryService(func(ctx context.Context, id influxdb.ID, t time.Time) (map[string]influxdb.ID, error) {
+		if t.Before(renamedAt) {
+			// bucket 2 has been deleted since.
+			return map[string]influxdb.ID{"old": 1, "deleted": 2}, nil
+		}
+		return map[string]influxdb.ID{"new": 1}, nil
+	})
+
+	asOf := renamedAt.Add(-time.Hour)
+	ctx := query.ContextWithRequest(context.Background(), &query.Request{OrganizationID: orgID, AsOf: &asOf})
+	if id, ok := lookup.Lookup(ctx, orgID, "old"); !ok || id != 1 {
+		t.Errorf("expected the bucket to be found by its old name, got %v %v", id, ok)
+	}
+	for _, name := range []string{"new", "deleted"} {
+		if _, ok := lookup.Lookup(ctx, orgID, name); ok {
+			t.Errorf("expected no bucket named %s", name)
+		}
+	}
+	if got := lookup.LookupName(ctx, orgID, 1); got != "old" {
+		t.Errorf("expected the old name of the bucket, got %q", got)
+	}
+	all, n := lookup.FindAllBuckets(ctx, orgID)
+	if n != 1 || all[0].Name != "old" {
+		t.Errorf("expected only the old bucket, got %v", all)
+	}
+
+	// Requests without AsOf resolve names as they are now.
+	ctx = query.ContextWithRequest(context.Background(), &query.Request{OrganizationID: orgID})
+	if id, ok := lookup.Lookup(ctx, orgID, "new"); !ok || id != 1 {
+		t.Errorf("expected the bucket to be found by its name, got %v %v", id, ok)
+	}
+}
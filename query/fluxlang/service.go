@@ -26,6 +26,11 @@ func (d defaultService) Parse(source string) (pkg *ast.Package, err error) {
 	return pkg, err
 }
 
+func (d defaultService) Analyze(source string) error {
+	_, err := runtime.AnalyzeSource(source)
+	return err
+}
+
 func (d defaultService) EvalAST(ctx context.Context, astPkg *ast.Package) ([]interpreter.SideEffect, values.Scope, error) {
 	return runtime.EvalAST(ctx, astPkg)
 }
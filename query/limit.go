@@ -0,0 +1,307 @@
+package query
+
+import (
+	stdcsv "encoding/csv"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/arrow"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/iocounter"
+)
+
+const LimitedDialectType = "limited"
+
+const (
+	// PartialResultLabel is the label of the column holding the reason the
+	// results are partial in the partial result annotation.
+	PartialResultLabel = "partial"
+	// NextChunkLabel is the label of the column holding the index of the next
+	// chunk of results in the partial result annotation.
+	NextChunkLabel = "nextChunk"
+)
+
+// Reasons of partial results.
+const (
+	PartialResultMaxBytes  = "result size limit exceeded"
+	PartialResultChunkRows = "chunk row count reached"
+)
+
+// ResultLimits bounds the results encoded by a LimitedDialect.
+type ResultLimits struct {
+	// MaxBytes is the size of the encoded results after which no more rows are encoded.
+	// If this is unset, the size of the results is not limited.
+	MaxBytes int64 `json:"maxBytes,omitempty"`
+
+	// ChunkRows is the number of rows of a chunk of results.
+	// If this is unset, the results are not split into chunks.
+	ChunkRows int64 `json:"chunkRows,omitempty"`
+
+	// Chunk is the index of the chunk of results to encode. The rows of the
+	// previous chunks are skipped.
+	Chunk int64 `json:"chunk,omitempty"`
+}
+
+// LimitedDialect is a CSV dialect that stops encoding the results once they reach their limits,
+// and ends them with a partial result annotation instead of the remaining rows:
+// ```
+// #datatype,string,long
+// #group,true,false
+// #default,,
+// ,partial,nextChunk
+// ,chunk row count reached,2
+// ```
+// The index of the next chunk is only set if the results are split into chunks.
+type LimitedDialect struct {
+	csv.ResultEncoderConfig
+	Limits ResultLimits
+}
+
+func NewLimitedDialect() *LimitedDialect {
+	return &LimitedDialect{
+		ResultEncoderConfig: csv.DefaultEncoderConfig(),
+	}
+}
+
+func (d *LimitedDialect) Encoder() flux.MultiResultEncoder {
+	return &LimitedEncoder{
+		config:  d.ResultEncoderConfig,
+		encoder: csv.NewResultEncoder(d.ResultEncoderConfig),
+		limits:  d.Limits,
+	}
+}
+
+func (d *LimitedDialect) DialectType() flux.DialectType {
+	return LimitedDialectType
+}
+
+func (d *LimitedDialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+}
+
+// LimitedEncoder encodes results as CSV within their limits.
+type LimitedEncoder struct {
+	config  csv.ResultEncoderConfig
+	encoder *csv.ResultEncoder
+	limits  ResultLimits
+}
+
+func (e *LimitedEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	// Releasing the results cancels the query if the limits are reached.
+	defer results.Release()
+
+	wc := &iocounter.Writer{Writer: w}
+	l := &resultLimiter{limits: e.limits, written: wc.Count}
+	if e.limits.ChunkRows > 0 {
+		l.skip = e.limits.Chunk * e.limits.ChunkRows
+		l.rows = e.limits.ChunkRows
+	}
+
+	for results.More() {
+		result := results.Next()
+		if _, err := e.encoder.Encode(wc, &limitedResult{Result: result, l: l}); err != nil && l.reason == "" {
+			if wc.Count() == 0 {
+				return 0, err
+			}
+			return wc.Count(), e.encoder.EncodeError(wc, err)
+		}
+		if _, err := wc.Write([]byte("\r\n")); err != nil {
+			return wc.Count(), err
+		}
+		if l.reason != "" {
+			return wc.Count(), e.encodePartial(wc, l)
+		}
+	}
+
+	results.Release()
+	if err := results.Err(); err != nil {
+		if wc.Count() == 0 {
+			return 0, err
+		}
+		return wc.Count(), e.encoder.EncodeError(wc, err)
+	}
+	return wc.Count(), nil
+}
+
+// encodePartial writes the partial result annotation.
+func (e *LimitedEncoder) encodePartial(w io.Writer, l *resultLimiter) error {
+	writer := stdcsv.NewWriter(w)
+	if e.config.Delimiter != 0 {
+		writer.Comma = e.config.Delimiter
+	}
+	writer.UseCRLF = true
+	for _, anno := range e.config.Annotations {
+		switch anno {
+		case "datatype":
+			writer.Write([]string{"#datatype", "string", "long"})
+		case "group":
+			writer.Write([]string{"#group", "true", "false"})
+		case "default":
+			writer.Write([]string{"#default", "", ""})
+		}
+	}
+	var next string
+	if l.reason == PartialResultChunkRows {
+		next = strconv.FormatInt(e.limits.Chunk+1, 10)
+	}
+	writer.Write([]string{"", PartialResultLabel, NextChunkLabel})
+	writer.Write([]string{"", l.reason, next})
+	writer.Flush()
+	return writer.Error()
+}
+
+// resultLimiter tracks the rows and bytes encoded against the limits.
+type resultLimiter struct {
+	limits  ResultLimits
+	written func() int64
+
+	skip   int64 // rows left to skip before the chunk
+	rows   int64 // rows left in the chunk
+	reason string
+}
+
+// reached reports whether no more rows can be encoded, and records why.
+func (l *resultLimiter) reached() bool {
+	if l.reason != "" {
+		return true
+	}
+	if l.limits.MaxBytes > 0 && l.written() >= l.limits.MaxBytes {
+		l.reason = PartialResultMaxBytes
+	} else if l.limits.ChunkRows > 0 && l.skip == 0 && l.rows == 0 {
+		l.reason = PartialResultChunkRows
+	}
+	return l.reason != ""
+}
+
+// errResultLimit stops reading the tables once the limits are reached.
+type errResultLimit struct{}
+
+func (errResultLimit) Error() string { return "result limit reached" }
+
+type limitedResult struct {
+	flux.Result
+	l *resultLimiter
+}
+
+func (r *limitedResult) Tables() flux.TableIterator {
+	return &limitedTables{TableIterator: r.Result.Tables(), l: r.l}
+}
+
+type limitedTables struct {
+	flux.TableIterator
+	l *resultLimiter
+}
+
+func (t *limitedTables) Do(f func(flux.Table) error) error {
+	return t.TableIterator.Do(func(tbl flux.Table) error {
+		// Stop before the encoder writes the schema of a table without rows to encode.
+		if t.l.reason != "" || (!tbl.Empty() && t.l.reached()) {
+			tbl.Done()
+			return errResultLimit{}
+		}
+		return f(&limitedTable{Table: tbl, l: t.l})
+	})
+}
+
+type limitedTable struct {
+	flux.Table
+	l *resultLimiter
+}
+
+func (t *limitedTable) Do(f func(flux.ColReader) error) error {
+	l := t.l
+	return t.Table.Do(func(cr flux.ColReader) error {
+		n := int64(cr.Len())
+		if n == 0 {
+			return nil
+		} else if l.reached() {
+			return errResultLimit{}
+		}
+
+		if l.skip >= n {
+			l.skip -= n
+			return nil
+		}
+		start, stop := l.skip, n
+		l.skip = 0
+		if l.limits.ChunkRows > 0 {
+			if stop-start > l.rows {
+				stop = start + l.rows
+				// The rows left in the reader are in the next chunk.
+				l.reason = PartialResultChunkRows
+			}
+			l.rows -= stop - start
+		}
+		if start == 0 && stop == n {
+			return f(cr)
+		}
+
+		sliced := sliceColReader(cr, int(start), int(stop))
+		defer sliced.Release()
+		return f(sliced)
+	})
+}
+
+// slicedColReader is a ColReader with the rows i to j of another one.
+type slicedColReader struct {
+	flux.ColReader
+	cols []array.Interface
+	n    int
+}
+
+func sliceColReader(cr flux.ColReader, i, j int) *slicedColReader {
+	s := &slicedColReader{
+		ColReader: cr,
+		cols:      make([]array.Interface, len(cr.Cols())),
+		n:         j - i,
+	}
+	for k, c := range cr.Cols() {
+		var arr array.Interface
+		switch c.Type {
+		case flux.TBool:
+			arr = cr.Bools(k)
+		case flux.TInt:
+			arr = cr.Ints(k)
+		case flux.TUInt:
+			arr = cr.UInts(k)
+		case flux.TFloat:
+			arr = cr.Floats(k)
+		case flux.TString:
+			arr = cr.Strings(k)
+		case flux.TTime:
+			arr = cr.Times(k)
+		default:
+			continue
+		}
+		s.cols[k] = arrow.Slice(arr, int64(i), int64(j))
+	}
+	return s
+}
+
+func (s *slicedColReader) Len() int                    { return s.n }
+func (s *slicedColReader) Bools(j int) *array.Boolean  { return s.cols[j].(*array.Boolean) }
+func (s *slicedColReader) Ints(j int) *array.Int64     { return s.cols[j].(*array.Int64) }
+func (s *slicedColReader) UInts(j int) *array.Uint64   { return s.cols[j].(*array.Uint64) }
+func (s *slicedColReader) Floats(j int) *array.Float64 { return s.cols[j].(*array.Float64) }
+func (s *slicedColReader) Strings(j int) *array.Binary { return s.cols[j].(*array.Binary) }
+func (s *slicedColReader) Times(j int) *array.Int64    { return s.cols[j].(*array.Int64) }
+
+func (s *slicedColReader) Retain() {
+	for _, arr := range s.cols {
+		if arr != nil {
+			arr.Retain()
+		}
+	}
+}
+
+func (s *slicedColReader) Release() {
+	for _, arr := range s.cols {
+		if arr != nil {
+			arr.Release()
+		}
+	}
+}
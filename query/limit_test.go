@@ -0,0 +1,140 @@
+package query_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/mock"
+)
+
+func TestLimitedDialect(t *testing.T) {
+	getMockResult := func() flux.Result {
+		r := executetest.NewResult([]*executetest.Table{
+			{
+				KeyCols: []string{"t1"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "t1", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 1.0, "a"},
+					{execute.Time(10), 2.0, "a"},
+					{execute.Time(20), 3.0, "a"},
+				},
+			},
+			{
+				KeyCols: []string{"t1"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "t1", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 4.0, "b"},
+					{execute.Time(10), 5.0, "b"},
+				},
+			},
+		})
+		r.Nm = "_result"
+		return r
+	}
+
+	encode := func(t *testing.T, limits query.ResultLimits) string {
+		t.Helper()
+		mockAsyncSvc := &mock.AsyncQueryService{
+			QueryF: func(ctx context.Context, req *query.Request) (flux.Query, error) {
+				q := mock.NewQuery()
+				q.SetResults(getMockResult())
+				return q, nil
+			},
+		}
+		dialect := query.NewLimitedDialect()
+		dialect.Annotations = nil
+		dialect.Limits = limits
+
+		var w bytes.Buffer
+		bridge := query.ProxyQueryServiceAsyncBridge{
+			AsyncQueryService: mockAsyncSvc,
+		}
+		if _, err := bridge.Query(context.Background(), &w, &query.ProxyRequest{
+			Request: query.Request{},
+			Dialect: dialect,
+		}); err != nil {
+			t.Fatalf("unexpected error on query: %v", err)
+		}
+		return w.String()
+	}
+
+	// The complete results, as encoded by the CSV dialect.
+	var exp bytes.Buffer
+	enc := csv.NewMultiResultEncoder(csv.ResultEncoderConfig{Delimiter: ','})
+	if _, err := enc.Encode(&exp, flux.NewSliceResultIterator([]flux.Result{getMockResult()})); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name   string
+		limits query.ResultLimits
+		want   string
+	}{
+		{
+			name: "no limits",
+			want: exp.String(),
+		},
+		{
+			name:   "first chunk",
+			limits: query.ResultLimits{ChunkRows: 2},
+			want: ",result,table,_time,_value,t1\r\n" +
+				",_result,0,1970-01-01T00:00:00Z,1,a\r\n" +
+				",_result,0,1970-01-01T00:00:00.00000001Z,2,a\r\n" +
+				"\r\n" +
+				",partial,nextChunk\r\n" +
+				",chunk row count reached,1\r\n",
+		},
+		{
+			name:   "middle chunk",
+			limits: query.ResultLimits{ChunkRows: 2, Chunk: 1},
+			want: ",result,table,_time,_value,t1\r\n" +
+				",_result,0,1970-01-01T00:00:00.00000002Z,3,a\r\n" +
+				",_result,1,1970-01-01T00:00:00Z,4,b\r\n" +
+				"\r\n" +
+				",partial,nextChunk\r\n" +
+				",chunk row count reached,2\r\n",
+		},
+		{
+			name:   "last chunk",
+			limits: query.ResultLimits{ChunkRows: 2, Chunk: 2},
+			want: ",result,table,_time,_value,t1\r\n" +
+				",_result,1,1970-01-01T00:00:00.00000001Z,5,b\r\n" +
+				"\r\n",
+		},
+		{
+			name:   "max bytes",
+			limits: query.ResultLimits{MaxBytes: 1},
+			want: ",result,table,_time,_value,t1\r\n" +
+				",_result,0,1970-01-01T00:00:00Z,1,a\r\n" +
+				",_result,0,1970-01-01T00:00:00.00000001Z,2,a\r\n" +
+				",_result,0,1970-01-01T00:00:00.00000002Z,3,a\r\n" +
+				"\r\n" +
+				",partial,nextChunk\r\n" +
+				",result size limit exceeded,\r\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, encode(t, tc.limits)); diff != "" {
+				t.Fatalf("unexpected results, -want/+got:\n\t%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/influxdb/v2/pkg/parquet"
+)
+
+const ParquetDialectType = "parquet"
+
+// ParquetGroupKeyMetadata is the key of the metadata of the Parquet files of
+// results listing the columns of the group keys of their tables, as a JSON
+// array of column names.
+const ParquetGroupKeyMetadata = "influxdb.group_key"
+
+// Labels of the columns identifying the table of the rows of Parquet files,
+// as in CSV.
+const (
+	parquetResultLabel = "result"
+	parquetTableLabel  = "table"
+)
+
+// ParquetDialect encodes the results as a Parquet file with a row per row of
+// their tables, so that they are loaded with their types by data analysis
+// tools. The schema of the file is the union of the columns of the tables,
+// preceded by the result and table columns identifying the table of each
+// row, and the rows of the tables without a column are null in it. A column
+// of different types in different tables is split into a column per type,
+// the names of all but the first suffixed with their type.
+//
+// The schema of a file is only known once all of the tables are read, so the
+// results are held in memory until encoded.
+type ParquetDialect struct{}
+
+func NewParquetDialect() *ParquetDialect {
+	return &ParquetDialect{}
+}
+
+func (d *ParquetDialect) Encoder() flux.MultiResultEncoder {
+	return &ParquetEncoder{}
+}
+
+func (d *ParquetDialect) DialectType() flux.DialectType {
+	return ParquetDialectType
+}
+
+func (d *ParquetDialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/vnd.apache.parquet")
+}
+
+// ParquetEncoder encodes results as a Parquet file.
+type ParquetEncoder struct{}
+
+func (e *ParquetEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	defer results.Release()
+
+	t := newParquetTable()
+	for results.More() {
+		result := results.Next()
+		var id int64
+		if err := result.Tables().Do(func(tbl flux.Table) error {
+			if err := t.appendGroupKey(tbl.Key()); err != nil {
+				return err
+			}
+			table := id
+			id++
+			return tbl.Do(func(cr flux.ColReader) error {
+				return t.appendRows(result.Name(), table, cr)
+			})
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	results.Release()
+	if err := results.Err(); err != nil {
+		return 0, err
+	}
+
+	wc := &iocounter.Writer{Writer: w}
+	err := t.write(wc)
+	return wc.Count(), err
+}
+
+type parquetColumnKey struct {
+	label string
+	typ   flux.ColType
+}
+
+// parquetTable accumulates the rows of the tables of results into the
+// columns of a Parquet file.
+type parquetTable struct {
+	columns []*parquet.ColumnBuffer
+	index   map[parquetColumnKey]int
+	names   map[string]bool
+	rows    int
+
+	groupKey   []string
+	inGroupKey map[string]bool
+}
+
+func newParquetTable() *parquetTable {
+	t := &parquetTable{
+		index:      make(map[parquetColumnKey]int),
+		names:      make(map[string]bool),
+		groupKey:   []string{},
+		inGroupKey: make(map[string]bool),
+	}
+	for _, c := range []parquet.Column{
+		{Name: parquetResultLabel, Type: parquet.String},
+		{Name: parquetTableLabel, Type: parquet.Int64},
+	} {
+		t.columns = append(t.columns, parquet.NewColumnBuffer(c))
+		t.names[c.Name] = true
+	}
+	return t
+}
+
+// column returns the index of the column of c, adding it with nulls for the
+// rows already appended if it is new.
+func (t *parquetTable) column(c flux.ColMeta) (int, error) {
+	key := parquetColumnKey{label: c.Label, typ: c.Type}
+	if i, ok := t.index[key]; ok {
+		return i, nil
+	}
+
+	var typ parquet.Type
+	switch c.Type {
+	case flux.TBool:
+		typ = parquet.Boolean
+	case flux.TInt:
+		typ = parquet.Int64
+	case flux.TUInt:
+		typ = parquet.Uint64
+	case flux.TFloat:
+		typ = parquet.Double
+	case flux.TString:
+		typ = parquet.String
+	case flux.TTime:
+		typ = parquet.Timestamp
+	default:
+		return 0, fmt.Errorf("column %s has unsupported type %s", c.Label, c.Type)
+	}
+
+	name := c.Label
+	if t.names[name] {
+		name = fmt.Sprintf("%s_%s", c.Label, c.Type)
+	}
+	for i := 2; t.names[name]; i++ {
+		name = fmt.Sprintf("%s_%s%d", c.Label, c.Type, i)
+	}
+	b := parquet.NewColumnBuffer(parquet.Column{Name: name, Type: typ})
+	for i := 0; i < t.rows; i++ {
+		b.AppendNull()
+	}
+
+	t.columns = append(t.columns, b)
+	t.index[key] = len(t.columns) - 1
+	t.names[name] = true
+	return len(t.columns) - 1, nil
+}
+
+func (t *parquetTable) appendGroupKey(key flux.GroupKey) error {
+	for _, c := range key.Cols() {
+		i, err := t.column(c)
+		if err != nil {
+			return err
+		}
+		if name := t.columns[i].Name; !t.inGroupKey[name] {
+			t.groupKey = append(t.groupKey, name)
+			t.inGroupKey[name] = true
+		}
+	}
+	return nil
+}
+
+func (t *parquetTable) appendRows(result string, table int64, cr flux.ColReader) error {
+	n := cr.Len()
+	for i := 0; i < n; i++ {
+		t.columns[0].AppendString(result)
+		t.columns[1].AppendInt(table)
+	}
+
+	appended := map[int]bool{0: true, 1: true}
+	for j, c := range cr.Cols() {
+		idx, err := t.column(c)
+		if err != nil {
+			return err
+		}
+		appended[idx] = true
+
+		b := t.columns[idx]
+		switch c.Type {
+		case flux.TBool:
+			vs := cr.Bools(j)
+			for i := 0; i < n; i++ {
+				if vs.IsValid(i) {
+					b.AppendBool(vs.Value(i))
+				} else {
+					b.AppendNull()
+				}
+			}
+		case flux.TInt:
+			vs := cr.Ints(j)
+			for i := 0; i < n; i++ {
+				if vs.IsValid(i) {
+					b.AppendInt(vs.Value(i))
+				} else {
+					b.AppendNull()
+				}
+			}
+		case flux.TUInt:
+			vs := cr.UInts(j)
+			for i := 0; i < n; i++ {
+				if vs.IsValid(i) {
+					b.AppendUint(vs.Value(i))
+				} else {
+					b.AppendNull()
+				}
+			}
+		case flux.TFloat:
+			vs := cr.Floats(j)
+			for i := 0; i < n; i++ {
+				if vs.IsValid(i) {
+					b.AppendFloat(vs.Value(i))
+				} else {
+					b.AppendNull()
+				}
+			}
+		case flux.TString:
+			vs := cr.Strings(j)
+			for i := 0; i < n; i++ {
+				if vs.IsValid(i) {
+					b.AppendString(vs.ValueString(i))
+				} else {
+					b.AppendNull()
+				}
+			}
+		case flux.TTime:
+			vs := cr.Times(j)
+			for i := 0; i < n; i++ {
+				if vs.IsValid(i) {
+					b.AppendInt(vs.Value(i))
+				} else {
+					b.AppendNull()
+				}
+			}
+		}
+	}
+
+	// The columns of other tables are null in the rows of this one.
+	for idx, b := range t.columns {
+		if appended[idx] {
+			continue
+		}
+		for i := 0; i < n; i++ {
+			b.AppendNull()
+		}
+	}
+	t.rows += n
+	return nil
+}
+
+func (t *parquetTable) write(w io.Writer) error {
+	schema := make([]parquet.Column, len(t.columns))
+	for i, b := range t.columns {
+		schema[i] = b.Column
+	}
+	pw := parquet.NewWriter(w, schema)
+
+	groupKey, err := json.Marshal(t.groupKey)
+	if err != nil {
+		return err
+	}
+	pw.SetMetadata(ParquetGroupKeyMetadata, string(groupKey))
+
+	if t.rows > 0 {
+		if err := pw.WriteRowGroup(t.columns); err != nil {
+			return err
+		}
+	}
+	return pw.Close()
+}
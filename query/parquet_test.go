@@ -0,0 +1,82 @@
+package query_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+func TestParquetDialect(t *testing.T) {
+	r := executetest.NewResult([]*executetest.Table{
+		{
+			KeyCols: []string{"t1"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "t1", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{execute.Time(0), 1.0, "a"},
+				{execute.Time(10), nil, "a"},
+			},
+		},
+		{
+			KeyCols: []string{"t1", "t2"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TInt},
+				{Label: "t1", Type: flux.TString},
+				{Label: "t2", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{execute.Time(0), int64(4), "b", "x"},
+			},
+		},
+	})
+	r.Nm = "_result"
+
+	var w bytes.Buffer
+	n, err := query.NewParquetDialect().Encoder().Encode(&w, flux.NewSliceResultIterator([]flux.Result{r}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(w.Len()) {
+		t.Errorf("unexpected number of bytes written: got %d want %d", n, w.Len())
+	}
+
+	file := w.Bytes()
+	if !bytes.HasPrefix(file, []byte("PAR1")) || !bytes.HasSuffix(file, []byte("PAR1")) {
+		t.Fatal("response is not a parquet file")
+	}
+	for _, s := range []string{
+		"result", "table", "_time", "t1", "t2", "_result",
+		// The integer values are in a column of their own.
+		"_value_int",
+		query.ParquetGroupKeyMetadata, `["t1","t2"]`,
+	} {
+		if !bytes.Contains(file, []byte(s)) {
+			t.Errorf("parquet file does not contain %q", s)
+		}
+	}
+}
+
+func TestParquetDialect_Error(t *testing.T) {
+	r := executetest.NewResult([]*executetest.Table{{
+		ColMeta: []flux.ColMeta{{Label: "_value", Type: flux.TFloat}},
+		Data:    [][]interface{}{{1.0}},
+		Err:     errors.New("query failed"),
+	}})
+
+	var w bytes.Buffer
+	if _, err := query.NewParquetDialect().Encoder().Encode(&w, flux.NewSliceResultIterator([]flux.Result{r})); err == nil {
+		t.Fatal("expected the error of the results")
+	}
+	if w.Len() != 0 {
+		t.Errorf("expected nothing written on errors, got %d bytes", w.Len())
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/influxdata/flux"
 	platform "github.com/influxdata/influxdb/v2"
@@ -33,6 +34,10 @@ type Request struct {
 	// Source represents the ultimate source of the request.
 	Source string `json:"source"`
 
+	// AsOf, when set, resolves the names of buckets as they were at that
+	// time, e.g. to rerun a query the way it ran before buckets were renamed.
+	AsOf *time.Time `json:"as_of,omitempty"`
+
 	// compilerMappings maps compiler types to creation methods
 	compilerMappings flux.CompilerMappings
 
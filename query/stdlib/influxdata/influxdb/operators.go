@@ -61,6 +61,12 @@ type ReadRangePhysSpec struct {
 	Filter *datatypes.Predicate
 
 	Bounds flux.Bounds
+
+	// Federated is set when the bucket has a rollup bucket that serves the
+	// older part of the range. The reads of federated buckets are not
+	// pushed down further than filters, since the storage engine could only
+	// aggregate the parts of the range separately.
+	Federated bool
 }
 
 func (s *ReadRangePhysSpec) Kind() plan.ProcedureKind {
@@ -0,0 +1,61 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+// lookupRollup returns the rollup of the bucket read by spec, if the reads of
+// the bucket are federated with a rollup bucket.
+func lookupRollup(ctx context.Context, spec *ReadRangePhysSpec) (*platform.BucketRollup, bool) {
+	deps := GetStorageDependencies(ctx).FromDeps
+	if deps.RollupLookup == nil || deps.BucketLookup == nil {
+		return nil, false
+	}
+	req := query.RequestFromContext(ctx)
+	if req == nil {
+		return nil, false
+	}
+	bucketID, err := spec.LookupBucketID(ctx, req.OrganizationID, deps.BucketLookup)
+	if err != nil {
+		return nil, false
+	}
+	return deps.RollupLookup.LookupRollup(ctx, bucketID)
+}
+
+// federateReadFilterSpec splits the read of spec between the raw bucket and
+// its rollup at the cutoff of the rollup. It returns the read of the rollup
+// bucket, if any, and the read of the raw bucket, if any. The reads keep the
+// bounds of spec so that their tables have the same group keys.
+func federateReadFilterSpec(ctx context.Context, spec query.ReadFilterSpec, rollup *platform.BucketRollup, now time.Time) (rollupSpec, rawSpec *query.ReadFilterSpec, err error) {
+	cutoff := execute.Time(rollup.CutoffTime(now).UnixNano())
+	if spec.Bounds.Start >= cutoff {
+		return nil, &spec, nil
+	}
+
+	if _, _, err := authorizer.AuthorizeRead(ctx, platform.BucketsResourceType, rollup.RollupBucketID, spec.OrganizationID); err != nil {
+		return nil, nil, &flux.Error{
+			Code: codes.PermissionDenied,
+			Msg:  "reads of the bucket are served from its rollup bucket, which requires read permission",
+			Err:  err,
+		}
+	}
+
+	rs := spec
+	rs.BucketID = rollup.RollupBucketID
+	if spec.Bounds.Stop <= cutoff {
+		return &rs, nil, nil
+	}
+	rs.TimeRange = &execute.Bounds{Start: spec.Bounds.Start, Stop: cutoff}
+
+	raw := spec
+	raw.TimeRange = &execute.Bounds{Start: cutoff, Stop: spec.Bounds.Stop}
+	return &rs, &raw, nil
+}
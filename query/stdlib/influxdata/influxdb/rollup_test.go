@@ -0,0 +1,108 @@
+package influxdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/execute"
+	platform "github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+func TestFederateReadFilterSpec(t *testing.T) {
+	var (
+		orgID    = platform.ID(1)
+		rawID    = platform.ID(10)
+		rollupID = platform.ID(11)
+		now      = time.Date(2020, 6, 8, 0, 0, 0, 0, time.UTC)
+		cutoff   = execute.Time(now.Add(-7 * 24 * time.Hour).UnixNano())
+		day      = execute.Time(24 * time.Hour)
+	)
+	rollup := &platform.BucketRollup{
+		OrgID:          orgID,
+		BucketID:       rawID,
+		RollupBucketID: rollupID,
+		Cutoff:         platform.Duration{Duration: 7 * 24 * time.Hour},
+	}
+
+	readPermission := func(id platform.ID) platform.Permission {
+		return platform.Permission{
+			Action:   platform.ReadAction,
+			Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &orgID, ID: &id},
+		}
+	}
+	ctx := icontext.SetAuthorizer(context.Background(), &platform.Authorization{
+		Status:      platform.Active,
+		Permissions: []platform.Permission{readPermission(rawID), readPermission(rollupID)},
+	})
+
+	tests := []struct {
+		name       string
+		bounds     execute.Bounds
+		wantRollup *execute.Bounds
+		wantRaw    *execute.Bounds
+		rollupOnly bool
+	}{
+		{
+			name:   "recent range is read from the raw bucket",
+			bounds: execute.Bounds{Start: cutoff + day, Stop: cutoff + 2*day},
+		},
+		{
+			name:       "old range is read from the rollup bucket",
+			bounds:     execute.Bounds{Start: cutoff - 2*day, Stop: cutoff},
+			rollupOnly: true,
+		},
+		{
+			name:       "range across the cutoff is split",
+			bounds:     execute.Bounds{Start: cutoff - day, Stop: cutoff + day},
+			wantRollup: &execute.Bounds{Start: cutoff - day, Stop: cutoff},
+			wantRaw:    &execute.Bounds{Start: cutoff, Stop: cutoff + day},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := query.ReadFilterSpec{OrganizationID: orgID, BucketID: rawID, Bounds: tt.bounds}
+			rollupSpec, rawSpec, err := federateReadFilterSpec(ctx, spec, rollup, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			switch {
+			case tt.rollupOnly:
+				if rawSpec != nil || rollupSpec == nil || rollupSpec.BucketID != rollupID || rollupSpec.TimeRange != nil {
+					t.Fatalf("expected a single read of the rollup bucket, got %+v %+v", rollupSpec, rawSpec)
+				}
+			case tt.wantRollup == nil:
+				if rollupSpec != nil || rawSpec == nil || rawSpec.BucketID != rawID || rawSpec.TimeRange != nil {
+					t.Fatalf("expected a single read of the raw bucket, got %+v %+v", rollupSpec, rawSpec)
+				}
+			default:
+				if rollupSpec == nil || rawSpec == nil {
+					t.Fatalf("expected reads of both buckets, got %+v %+v", rollupSpec, rawSpec)
+				}
+				if rollupSpec.BucketID != rollupID || *rollupSpec.TimeRange != *tt.wantRollup {
+					t.Errorf("unexpected read of the rollup bucket %+v", rollupSpec)
+				}
+				if rawSpec.BucketID != rawID || *rawSpec.TimeRange != *tt.wantRaw {
+					t.Errorf("unexpected read of the raw bucket %+v", rawSpec)
+				}
+				if rollupSpec.Bounds != tt.bounds || rawSpec.Bounds != tt.bounds {
+					t.Error("expected the reads to keep the bounds of the range")
+				}
+			}
+		})
+	}
+
+	t.Run("rollup bucket requires read permission", func(t *testing.T) {
+		ctx := icontext.SetAuthorizer(context.Background(), &platform.Authorization{
+			Status:      platform.Active,
+			Permissions: []platform.Permission{readPermission(rawID)},
+		})
+		spec := query.ReadFilterSpec{OrganizationID: orgID, BucketID: rawID, Bounds: execute.Bounds{Start: cutoff - day, Stop: cutoff + day}}
+		if _, _, err := federateReadFilterSpec(ctx, spec, rollup, now); err == nil {
+			t.Fatal("expected an error reading the rollup bucket without permission")
+		}
+	})
+}
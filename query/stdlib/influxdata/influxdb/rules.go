@@ -79,6 +79,9 @@ func (rule PushDownGroupRule) Pattern() plan.Pattern {
 func (rule PushDownGroupRule) Rewrite(ctx context.Context, node plan.Node) (plan.Node, bool, error) {
 	src := node.Predecessors()[0].ProcedureSpec().(*ReadRangePhysSpec)
 	grp := node.ProcedureSpec().(*universe.GroupProcedureSpec)
+	if src.Federated {
+		return node, false, nil
+	}
 
 	switch grp.GroupMode {
 	case
@@ -120,11 +123,13 @@ func (rule PushDownRangeRule) Rewrite(ctx context.Context, node plan.Node) (plan
 	fromNode := node.Predecessors()[0]
 	fromSpec := fromNode.ProcedureSpec().(*FromStorageProcedureSpec)
 	rangeSpec := node.ProcedureSpec().(*universe.RangeProcedureSpec)
-	return plan.CreatePhysicalNode("ReadRange", &ReadRangePhysSpec{
+	spec := &ReadRangePhysSpec{
 		Bucket:   fromSpec.Bucket.Name,
 		BucketID: fromSpec.Bucket.ID,
 		Bounds:   rangeSpec.Bounds,
-	}), true, nil
+	}
+	_, spec.Federated = lookupRollup(ctx, spec)
+	return plan.CreatePhysicalNode("ReadRange", spec), true, nil
 }
 
 // PushDownFilterRule is a rule that pushes filters into from procedures to be evaluated in the storage layer.
@@ -249,6 +254,9 @@ func (rule PushDownReadTagKeysRule) Rewrite(ctx context.Context, pn plan.Node) (
 	keysSpec := keysNode.ProcedureSpec().(*universe.KeysProcedureSpec)
 	fromNode := keysNode.Predecessors()[0]
 	fromSpec := fromNode.ProcedureSpec().(*ReadRangePhysSpec)
+	if fromSpec.Federated {
+		return pn, false, nil
+	}
 
 	// A filter spec would have already been merged into the
 	// from spec if it existed so we will take that one when
@@ -312,6 +320,9 @@ func (rule PushDownReadTagValuesRule) Rewrite(ctx context.Context, pn plan.Node)
 	keepSpec := asSchemaMutationProcedureSpec(keepNode.ProcedureSpec())
 	fromNode := keepNode.Predecessors()[0]
 	fromSpec := fromNode.ProcedureSpec().(*ReadRangePhysSpec)
+	if fromSpec.Federated {
+		return pn, false, nil
+	}
 
 	// A filter spec would have already been merged into the
 	// from spec if it existed so we will take that one when
@@ -614,6 +625,12 @@ func (SortedPivotRule) Pattern() plan.Pattern {
 }
 
 func (SortedPivotRule) Rewrite(ctx context.Context, pn plan.Node) (plan.Node, bool, error) {
+	// The tables of the raw and rollup parts of a federated read are not
+	// sorted together.
+	if pn.Predecessors()[0].ProcedureSpec().(*ReadRangePhysSpec).Federated {
+		return pn, false, nil
+	}
+
 	pivotSpec := pn.ProcedureSpec().Copy().(*universe.PivotProcedureSpec)
 	pivotSpec.IsSortedByFunc = func(cols []string, desc bool) bool {
 		if desc {
@@ -789,7 +806,7 @@ func (PushDownWindowAggregateRule) Rewrite(ctx context.Context, pn plan.Node) (p
 	fromNode := windowNode.Predecessors()[0]
 	fromSpec := fromNode.ProcedureSpec().(*ReadRangePhysSpec)
 
-	if !isPushableWindow(windowSpec) {
+	if fromSpec.Federated || !isPushableWindow(windowSpec) {
 		return pn, false, nil
 	}
 
@@ -892,6 +909,9 @@ func (p PushDownBareAggregateRule) Rewrite(ctx context.Context, pn plan.Node) (p
 
 	fromNode := fnNode.Predecessors()[0]
 	fromSpec := fromNode.ProcedureSpec().(*ReadRangePhysSpec)
+	if fromSpec.Federated {
+		return pn, false, nil
+	}
 
 	return plan.CreatePhysicalNode("ReadWindowAggregate", &ReadWindowAggregatePhysSpec{
 		ReadRangePhysSpec: *fromSpec.Copy().(*ReadRangePhysSpec),
@@ -901,15 +921,20 @@ func (p PushDownBareAggregateRule) Rewrite(ctx context.Context, pn plan.Node) (p
 }
 
 // GroupWindowAggregateTransposeRule will match the given pattern.
-// ReadGroupPhys |> window |> { min, max, count, sum }
+// ReadGroupPhys |> window |> { min, max, count, sum, first, last }
 //
 // This pattern will use the PushDownWindowAggregateRule to determine
 // if the ReadWindowAggregatePhys operation is available before it will
 // rewrite the above. This rewrites the above to:
 //
-// ReadWindowAggregatePhys |> group(columns: ["_start", "_stop", ...]) |> { min, max, sum }
+// ReadWindowAggregatePhys |> group(columns: ["_start", "_stop", ...]) |> { min, max, sum, first, last }
 //
-// The count aggregate uses sum to merge the results.
+// The count aggregate uses sum to merge the results. The first and last
+// aggregates keep the row they select from each series, with its _time, and
+// the series are merged in the order ReadGroupPhys reads them, so the row
+// selected from the merged group is the one selected without the rewrite.
+// They are not rewritten with createEmpty, as the empty windows of a series
+// would hide the rows of the other series.
 type GroupWindowAggregateTransposeRule struct{}
 
 func (p GroupWindowAggregateTransposeRule) Name() string {
@@ -921,6 +946,8 @@ var windowMergeablePushAggs = []plan.ProcedureKind{
 	universe.MaxKind,
 	universe.CountKind,
 	universe.SumKind,
+	universe.FirstKind,
+	universe.LastKind,
 }
 
 func (p GroupWindowAggregateTransposeRule) Pattern() plan.Pattern {
@@ -949,6 +976,10 @@ func (p GroupWindowAggregateTransposeRule) Rewrite(ctx context.Context, pn plan.
 		return pn, false, nil
 	}
 
+	if kind := fnNode.Kind(); windowSpec.CreateEmpty && (kind == universe.FirstKind || kind == universe.LastKind) {
+		return pn, false, nil
+	}
+
 	fromNode := windowNode.Predecessors()[0]
 	fromSpec := fromNode.ProcedureSpec().(*ReadGroupPhysSpec)
 
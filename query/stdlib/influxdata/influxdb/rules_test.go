@@ -2158,6 +2158,63 @@ func TestTransposeGroupToWindowAggregateRule(t *testing.T) {
 		),
 	})
 
+	// ReadRange -> group -> window -> first => ReadWindowAggregate -> group -> first
+	tests = append(tests, plantest.RuleTestCase{
+		Context: haveCaps,
+		Name:    "SimplePassFirst",
+		Rules:   rules,
+		Before:  simplePlan(window1m, "first", firstProcedureSpec()),
+		After: simpleResult("first", dur1m, false,
+			plan.CreatePhysicalNode("group", groupResult()),
+			plan.CreatePhysicalNode("first", firstProcedureSpec()),
+		),
+	})
+
+	// ReadRange -> group -> window -> last => ReadWindowAggregate -> group -> last
+	tests = append(tests, plantest.RuleTestCase{
+		Context: haveCaps,
+		Name:    "SimplePassLast",
+		Rules:   rules,
+		Before:  simplePlan(window1m, "last", lastProcedureSpec()),
+		After: simpleResult("last", dur1m, false,
+			plan.CreatePhysicalNode("group", groupResult()),
+			plan.CreatePhysicalNode("last", lastProcedureSpec()),
+		),
+	})
+
+	// Grouped first and last with createEmpty are not transposed: the empty
+	// window of a series would be selected instead of the rows of the other
+	// series.
+	for _, agg := range []struct {
+		test string
+		name string
+		spec plan.ProcedureSpec
+	}{
+		{test: "NoPassFirstCreateEmpty", name: "first", spec: firstProcedureSpec()},
+		{test: "NoPassLastCreateEmpty", name: "last", spec: lastProcedureSpec()},
+	} {
+		tests = append(tests, plantest.RuleTestCase{
+			Context: haveCaps,
+			Name:    agg.test,
+			Rules:   rules,
+			Before:  simplePlan(window1mCreateEmpty, plan.NodeID(agg.name), agg.spec),
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadGroup", &influxdb.ReadGroupPhysSpec{
+						ReadRangePhysSpec: readRange,
+						GroupMode:         flux.GroupModeBy,
+					}),
+					plan.CreatePhysicalNode("window", &window1mCreateEmpty),
+					plan.CreatePhysicalNode(plan.NodeID(agg.name), agg.spec),
+				},
+				Edges: [][2]int{
+					{0, 1},
+					{1, 2},
+				},
+			},
+		})
+	}
+
 	// Rewrite with aggregate window
 	// ReadRange -> group -> window -> min -> duplicate -> window
 	tests = append(tests, plantest.RuleTestCase{
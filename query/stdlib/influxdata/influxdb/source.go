@@ -3,6 +3,7 @@ package influxdb
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/influxdata/flux"
@@ -41,6 +42,10 @@ type Source struct {
 	m     *metrics
 	orgID platform.ID
 	op    string
+
+	// bucketID is the bucket read, after the throttle lets it when set.
+	bucketID platform.ID
+	throttle ReadThrottle
 }
 
 func (s *Source) Run(ctx context.Context) {
@@ -49,10 +54,10 @@ func (s *Source) Run(ctx context.Context) {
 	var err error
 	if flux.IsExperimentalTracingEnabled() {
 		span, ctxWithSpan := tracing.StartSpanFromContextWithOperationName(ctx, "source-"+s.op)
-		err = s.runner.run(ctxWithSpan)
+		err = s.throttledRun(ctxWithSpan)
 		span.Finish()
 	} else {
-		err = s.runner.run(ctx)
+		err = s.throttledRun(ctx)
 	}
 	s.m.recordMetrics(labelValues, start)
 	for _, t := range s.ts {
@@ -60,6 +65,18 @@ func (s *Source) Run(ctx context.Context) {
 	}
 }
 
+// throttledRun runs the read once the throttle lets it.
+func (s *Source) throttledRun(ctx context.Context) error {
+	if s.throttle != nil {
+		release, err := s.throttle.Acquire(ctx, s.bucketID)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+	return s.runner.run(ctx)
+}
+
 func (s *Source) AddTransformation(t execute.Transformation) {
 	s.ts = append(s.ts, t)
 }
@@ -120,6 +137,11 @@ type readFilterSource struct {
 	Source
 	reader   query.StorageReader
 	readSpec query.ReadFilterSpec
+
+	// rollupSpec is the read of the rollup bucket of a federated read. If
+	// it spans the whole range, readSpec is not read at all. Otherwise the
+	// tables of both reads are merged into a single table per series.
+	rollupSpec *query.ReadFilterSpec
 }
 
 func ReadFilterSource(id execute.DatasetID, r query.StorageReader, readSpec query.ReadFilterSpec, a execute.Administration) execute.Source {
@@ -133,6 +155,8 @@ func ReadFilterSource(id execute.DatasetID, r query.StorageReader, readSpec quer
 
 	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
 	src.orgID = readSpec.OrganizationID
+	src.bucketID = readSpec.BucketID
+	src.throttle = GetStorageDependencies(a.Context()).FromDeps.ReadThrottle
 	src.op = "readFilter"
 
 	src.runner = src
@@ -140,10 +164,145 @@ func ReadFilterSource(id execute.DatasetID, r query.StorageReader, readSpec quer
 }
 
 func (s *readFilterSource) run(ctx context.Context) error {
-	stop := s.readSpec.Bounds.Stop
+	switch {
+	case s.rollupSpec == nil:
+		return s.read(ctx, s.readSpec)
+	case s.rollupSpec.TimeRange == nil:
+		return s.read(ctx, *s.rollupSpec)
+	default:
+		return s.readFederated(ctx)
+	}
+}
+
+// readFederated reads the part of the range older than the cutoff from the
+// rollup bucket and the rest from the raw bucket. Both reads have the same
+// bounds, so a series read from both buckets has the same group key in both;
+// its tables are merged, with the rows of the rollup bucket first.
+func (s *readFilterSource) readFederated(ctx context.Context) error {
+	rollupTables, err := s.reader.ReadFilter(ctx, *s.rollupSpec, s.alloc)
+	if err != nil {
+		return err
+	}
+
+	var (
+		keys     []string
+		buffered = make(map[string]flux.BufferedTable)
+	)
+	defer func() {
+		for _, tbl := range buffered {
+			tbl.Done()
+		}
+	}()
+	if err := rollupTables.Do(func(tbl flux.Table) error {
+		buf, err := execute.CopyTable(tbl)
+		if err != nil {
+			return err
+		}
+		key := buf.Key().String()
+		if prev, ok := buffered[key]; ok {
+			prev.Done()
+		} else {
+			keys = append(keys, key)
+		}
+		buffered[key] = buf
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	rawTables, err := s.reader.ReadFilter(ctx, s.readSpec, s.alloc)
+	if err != nil {
+		return err
+	}
+	if err := rawTables.Do(func(tbl flux.Table) error {
+		key := tbl.Key().String()
+		buf, ok := buffered[key]
+		if !ok {
+			return s.processTable(ctx, tbl)
+		}
+		delete(buffered, key)
+		defer buf.Done()
+
+		merged, err := mergeTables(buf.Copy(), tbl, s.alloc)
+		if err != nil {
+			return err
+		}
+		return s.processTable(ctx, merged)
+	}); err != nil {
+		return err
+	}
+
+	// The series only found in the rollup bucket.
+	for _, key := range keys {
+		buf, ok := buffered[key]
+		if !ok {
+			continue
+		}
+		if err := s.processTable(ctx, buf.Copy()); err != nil {
+			return err
+		}
+	}
+
+	for _, stats := range []cursors.CursorStats{rollupTables.Statistics(), rawTables.Statistics()} {
+		s.stats.ScannedValues += stats.ScannedValues
+		s.stats.ScannedBytes += stats.ScannedBytes
+	}
+	for _, t := range s.ts {
+		if err := t.UpdateWatermark(s.id, s.readSpec.Bounds.Stop); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeTables returns a table with the rows of first followed by the rows of
+// second, which has the same group key. Both tables are consumed.
+func mergeTables(first, second flux.Table, alloc *memory.Allocator) (flux.Table, error) {
+	if !sameCols(first.Cols(), second.Cols()) {
+		first.Done()
+		second.Done()
+		return nil, &flux.Error{
+			Code: codes.Invalid,
+			Msg:  fmt.Sprintf("series %v has different columns in the bucket and in its rollup bucket", first.Key()),
+		}
+	}
+
+	builder := execute.NewColListTableBuilder(first.Key(), alloc)
+	if err := execute.AddTableCols(first, builder); err != nil {
+		first.Done()
+		second.Done()
+		return nil, err
+	}
+	if err := execute.AppendTable(first, builder); err != nil {
+		second.Done()
+		return nil, err
+	}
+	if err := execute.AppendTable(second, builder); err != nil {
+		return nil, err
+	}
+	return builder.Table()
+}
+
+func sameCols(a, b []flux.ColMeta) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *readFilterSource) read(ctx context.Context, spec query.ReadFilterSpec) error {
+	stop := spec.Bounds.Stop
+	if spec.TimeRange != nil {
+		stop = spec.TimeRange.Stop
+	}
 	tables, err := s.reader.ReadFilter(
 		ctx,
-		s.readSpec,
+		spec,
 		s.alloc,
 	)
 	if err != nil {
@@ -182,17 +341,27 @@ func createReadFilterSource(s plan.ProcedureSpec, id execute.DatasetID, a execut
 		return nil, err
 	}
 
-	return ReadFilterSource(
-		id,
-		deps.Reader,
-		query.ReadFilterSpec{
-			OrganizationID: orgID,
-			BucketID:       bucketID,
-			Bounds:         *bounds,
-			Predicate:      spec.Filter,
-		},
-		a,
-	), nil
+	readSpec := query.ReadFilterSpec{
+		OrganizationID: orgID,
+		BucketID:       bucketID,
+		Bounds:         *bounds,
+		Predicate:      spec.Filter,
+	}
+	src := ReadFilterSource(id, deps.Reader, readSpec, a).(*readFilterSource)
+
+	if spec.Federated && deps.RollupLookup != nil {
+		if rollup, ok := deps.RollupLookup.LookupRollup(ctx, bucketID); ok {
+			rollupSpec, rawSpec, err := federateReadFilterSpec(ctx, readSpec, rollup, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			src.rollupSpec = rollupSpec
+			if rawSpec != nil {
+				src.readSpec = *rawSpec
+			}
+		}
+	}
+	return src, nil
 }
 
 type readGroupSource struct {
@@ -212,6 +381,8 @@ func ReadGroupSource(id execute.DatasetID, r query.StorageReader, readSpec query
 
 	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
 	src.orgID = readSpec.OrganizationID
+	src.bucketID = readSpec.BucketID
+	src.throttle = GetStorageDependencies(a.Context()).FromDeps.ReadThrottle
 	src.op = readSpec.Name()
 
 	src.runner = src
@@ -290,6 +461,8 @@ func ReadWindowAggregateSource(id execute.DatasetID, r query.WindowAggregateRead
 
 	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
 	src.orgID = readSpec.OrganizationID
+	src.bucketID = readSpec.BucketID
+	src.throttle = GetStorageDependencies(a.Context()).FromDeps.ReadThrottle
 	src.op = readSpec.Name()
 
 	src.runner = src
@@ -410,6 +583,8 @@ func ReadTagKeysSource(id execute.DatasetID, r query.StorageReader, readSpec que
 
 	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
 	src.orgID = readSpec.OrganizationID
+	src.bucketID = readSpec.BucketID
+	src.throttle = GetStorageDependencies(a.Context()).FromDeps.ReadThrottle
 	src.op = "readTagKeys"
 
 	src.runner = src
@@ -475,6 +650,8 @@ func ReadTagValuesSource(id execute.DatasetID, r query.StorageReader, readSpec q
 
 	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
 	src.orgID = readSpec.OrganizationID
+	src.bucketID = readSpec.BucketID
+	src.throttle = GetStorageDependencies(a.Context()).FromDeps.ReadThrottle
 	src.op = "readTagValues"
 
 	src.runner = src
@@ -8,3 +8,7 @@ import (
 func CreateReadWindowAggregateSource(s plan.ProcedureSpec, id execute.DatasetID, a execute.Administration) (execute.Source, error) {
 	return createReadWindowAggregateSource(s, id, a)
 }
+
+func CreateReadFilterSource(s plan.ProcedureSpec, id execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	return createReadFilterSource(s, id, a)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/stdlib/universe"
 	platform "github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kit/prom/promtest"
 	"github.com/influxdata/influxdb/v2/mock"
 	"github.com/influxdata/influxdb/v2/query"
@@ -286,3 +287,125 @@ func TestReadWindowAggregateSource(t *testing.T) {
 		},
 	)
 }
+
+type rollupLookup map[platform.ID]*platform.BucketRollup
+
+func (l rollupLookup) LookupRollup(ctx context.Context, bucketID platform.ID) (*platform.BucketRollup, bool) {
+	r, ok := l[bucketID]
+	return r, ok
+}
+
+func TestReadFilterSource_Federated(t *testing.T) {
+	var (
+		orgID    = platform.ID(1)
+		rawID    = platform.ID(10)
+		rollupID = platform.ID(11)
+		now      = time.Now()
+		start    = execute.Time(now.Add(-8 * 24 * time.Hour).UnixNano())
+		stop     = execute.Time(now.UnixNano())
+		hour     = execute.Time(time.Hour)
+	)
+
+	series := func(start, stop execute.Time, rows ...[]interface{}) *executetest.Table {
+		tbl := &executetest.Table{
+			ColMeta: []flux.ColMeta{
+				{Label: "_start", Type: flux.TTime},
+				{Label: "_stop", Type: flux.TTime},
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_value", Type: flux.TFloat},
+			},
+			KeyCols: []string{"_start", "_stop", "_measurement", "_field"},
+		}
+		for _, row := range rows {
+			tbl.Data = append(tbl.Data, append([]interface{}{start, stop}, row...))
+		}
+		return tbl
+	}
+
+	executetest.RunSourceHelper(t,
+		[]*executetest.Table{
+			series(start, stop,
+				[]interface{}{start + hour, "cpu", "usage", 1.0},
+				[]interface{}{stop - hour, "cpu", "usage", 2.0},
+			),
+			series(start, stop,
+				[]interface{}{start + hour, "mem", "used", 3.0},
+			),
+			series(start, stop,
+				[]interface{}{stop - hour, "disk", "free", 4.0},
+			),
+		},
+		nil,
+		func(id execute.DatasetID) execute.Source {
+			var cutoff execute.Time
+			reader := &mock.StorageReader{
+				ReadFilterFn: func(ctx context.Context, spec query.ReadFilterSpec, alloc *memory.Allocator) (query.TableIterator, error) {
+					if want, got := (execute.Bounds{Start: start, Stop: stop}), spec.Bounds; want != got {
+						t.Errorf("unexpected bounds -want/+got:\n%s", cmp.Diff(want, got))
+					}
+					if spec.TimeRange == nil {
+						t.Fatalf("expected the read of bucket %s to be restricted to a part of the range", spec.BucketID)
+					}
+
+					switch spec.BucketID {
+					case rollupID:
+						cutoff = spec.TimeRange.Stop
+						return &TableIterator{Tables: []*executetest.Table{
+							series(start, stop, []interface{}{start + hour, "cpu", "usage", 1.0}),
+							series(start, stop, []interface{}{start + hour, "mem", "used", 3.0}),
+						}}, nil
+					case rawID:
+						if spec.TimeRange.Start != cutoff {
+							t.Errorf("expected the raw bucket to be read from the cutoff %v, got %v", cutoff, spec.TimeRange.Start)
+						}
+						return &TableIterator{Tables: []*executetest.Table{
+							series(start, stop, []interface{}{stop - hour, "disk", "free", 4.0}),
+							series(start, stop, []interface{}{stop - hour, "cpu", "usage", 2.0}),
+						}}, nil
+					}
+					t.Fatalf("unexpected read of bucket %s", spec.BucketID)
+					return nil, nil
+				},
+			}
+
+			deps := influxdb.StorageDependencies{
+				FromDeps: influxdb.FromDependencies{
+					Reader: reader,
+					RollupLookup: rollupLookup{rawID: {
+						OrgID:          orgID,
+						BucketID:       rawID,
+						RollupBucketID: rollupID,
+						Cutoff:         platform.Duration{Duration: 7 * 24 * time.Hour},
+					}},
+					Metrics: influxdb.NewMetrics(nil),
+				},
+			}
+			ctx := deps.Inject(context.Background())
+			ctx = query.ContextWithRequest(ctx, &query.Request{
+				OrganizationID: orgID,
+			})
+			ctx = icontext.SetAuthorizer(ctx, &platform.Authorization{
+				Status: platform.Active,
+				Permissions: []platform.Permission{{
+					Action:   platform.ReadAction,
+					Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &orgID, ID: &rollupID},
+				}},
+			})
+			a := mockAdministration{
+				Ctx:          ctx,
+				StreamBounds: &execute.Bounds{Start: start, Stop: stop},
+			}
+
+			s, err := influxdb.CreateReadFilterSource(&influxdb.ReadRangePhysSpec{
+				BucketID:  rawID.String(),
+				Federated: true,
+			}, id, a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+	)
+}
@@ -23,11 +23,27 @@ type OrganizationLookup interface {
 	Lookup(ctx context.Context, name string) (platform.ID, bool)
 }
 
+type RollupLookup interface {
+	LookupRollup(ctx context.Context, bucketID platform.ID) (*platform.BucketRollup, bool)
+}
+
+// ReadThrottle delays the reads of the buckets read at a lower priority.
+type ReadThrottle interface {
+	// Acquire blocks until bucketID can be read. release must be called
+	// once the read is done.
+	Acquire(ctx context.Context, bucketID platform.ID) (release func(), err error)
+}
+
 type FromDependencies struct {
 	Reader             query.StorageReader
 	BucketLookup       BucketLookup
 	OrganizationLookup OrganizationLookup
-	Metrics            *metrics
+	// RollupLookup is optional. Reads are not federated across raw and
+	// rollup buckets without it.
+	RollupLookup RollupLookup
+	// ReadThrottle is optional. Reads are not throttled without it.
+	ReadThrottle ReadThrottle
+	Metrics      *metrics
 }
 
 func (d FromDependencies) Validate() error {
@@ -65,6 +65,11 @@ type ReadFilterSpec struct {
 
 	Bounds    execute.Bounds
 	Predicate *datatypes.Predicate
+
+	// TimeRange restricts the points read to a part of Bounds if it is set.
+	// The tables still report Bounds as their start and stop, so the tables
+	// of the reads of the parts of a range have the same group keys.
+	TimeRange *execute.Bounds
 }
 
 type ReadGroupSpec struct {
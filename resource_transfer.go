@@ -0,0 +1,44 @@
+package influxdb
+
+import (
+	"context"
+)
+
+// ops for resource transfer errors.
+var (
+	OpTransferResourceOwnership = "TransferResourceOwnership"
+	OpMoveResource              = "MoveResource"
+)
+
+// ResourceTransfer identifies a resource and its new owner or organization.
+type ResourceTransfer struct {
+	ResourceType ResourceType `json:"resourceType"`
+	ResourceID   ID           `json:"resourceID"`
+	// UserID is the new owner of the resource.
+	UserID ID `json:"userID,omitempty"`
+	// OrgID is the organization the resource is moved to.
+	OrgID ID `json:"orgID,omitempty"`
+}
+
+// ResourceTransferService changes the owner or the organization of tasks,
+// dashboards, checks and notification rules.
+type ResourceTransferService interface {
+	// TransferResourceOwnership makes the user the owner of the resource. The
+	// user must be a member of the organization of the resource.
+	TransferResourceOwnership(ctx context.Context, rt ResourceType, id ID, userID ID) error
+
+	// MoveResource moves a resource to another organization. Its labels are
+	// replaced by the labels of the same name in the organization, and its
+	// user resource mappings by those of the members of the organization.
+	MoveResource(ctx context.Context, rt ResourceType, id ID, orgID ID) error
+}
+
+// IsTransferableResource reports whether the owner and organization of
+// resources of the type can be changed.
+func IsTransferableResource(rt ResourceType) bool {
+	switch rt {
+	case TasksResourceType, DashboardsResourceType, ChecksResourceType, NotificationRuleResourceType:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package rollup
+
+import (
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrRollupNotFound is used when the bucket has no rollup.
+	ErrRollupNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "bucket rollup not found",
+	}
+
+	// ErrInvalidBucketID is used when the ID of the bucket cannot be encoded.
+	ErrInvalidBucketID = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "bucket ID is invalid",
+	}
+
+	// ErrRollupIsRaw is used when the rollup bucket of a rollup has a rollup
+	// itself, or a raw bucket is the rollup of another bucket.
+	ErrRollupIsRaw = &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  "rollups cannot be chained",
+	}
+)
+
+// ErrInvalidRollup is used when a service was provided an invalid rollup.
+func ErrInvalidRollup(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "bucket rollup provided is invalid",
+		Err:  err,
+	}
+}
+
+// ErrRollupBucket is used when a bucket of a rollup is not a bucket of the
+// organization of the rollup.
+func ErrRollupBucket(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "buckets of a rollup must belong to its organization",
+		Err:  err,
+	}
+}
+
+// ErrInternalService is used when the error comes from an internal system.
+func ErrInternalService(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
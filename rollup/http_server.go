@@ -0,0 +1,189 @@
+package rollup
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixRollups is the prefix of the bucket rollup API.
+	PrefixRollups = "/api/v2/rollups"
+)
+
+// Handler is the HTTP API handler for bucket rollups.
+type Handler struct {
+	chi.Router
+	api       *kithttp.API
+	log       *zap.Logger
+	rollupSvc influxdb.BucketRollupService
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, rollupSvc influxdb.BucketRollupService) *Handler {
+	h := &Handler{
+		api:       kithttp.NewAPI(kithttp.WithLog(log)),
+		log:       log,
+		rollupSvc: rollupSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Get("/", h.handleGetRollups)
+
+		r.Route("/{bucketID}", func(r chi.Router) {
+			r.Get("/", h.handleGetRollup)
+			r.Put("/", h.handlePutRollup)
+			r.Delete("/", h.handleDeleteRollup)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixRollups
+}
+
+type rollupResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.BucketRollup
+}
+
+func newRollupResponse(r *influxdb.BucketRollup) *rollupResponse {
+	return &rollupResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("%s/%s", PrefixRollups, r.BucketID),
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", r.BucketID),
+			"rollup": fmt.Sprintf("/api/v2/buckets/%s", r.RollupBucketID),
+		},
+		BucketRollup: r,
+	}
+}
+
+type rollupsResponse struct {
+	Links   map[string]string `json:"links"`
+	Rollups []*rollupResponse `json:"rollups"`
+}
+
+type putRollupRequest struct {
+	OrgID          influxdb.ID       `json:"orgID"`
+	RollupBucketID influxdb.ID       `json:"rollupBucketID"`
+	Cutoff         influxdb.Duration `json:"cutoff"`
+}
+
+func (h *Handler) handleGetRollups(w http.ResponseWriter, r *http.Request) {
+	filter, err := decodeRollupFilter(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	rollups, err := h.rollupSvc.FindBucketRollups(r.Context(), filter)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	resp := rollupsResponse{
+		Links:   map[string]string{"self": PrefixRollups},
+		Rollups: make([]*rollupResponse, 0, len(rollups)),
+	}
+	for _, rollup := range rollups {
+		resp.Rollups = append(resp.Rollups, newRollupResponse(rollup))
+	}
+	h.api.Respond(w, r, http.StatusOK, resp)
+}
+
+func decodeRollupFilter(r *http.Request) (influxdb.BucketRollupFilter, error) {
+	var filter influxdb.BucketRollupFilter
+	q := r.URL.Query()
+
+	if v := q.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return filter, influxdb.ErrCorruptID(err)
+		}
+		filter.OrgID = id
+	}
+	if v := q.Get("bucketID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return filter, influxdb.ErrCorruptID(err)
+		}
+		filter.BucketID = id
+	}
+	return filter, nil
+}
+
+func (h *Handler) handleGetRollup(w http.ResponseWriter, r *http.Request) {
+	bucketID, err := influxdb.IDFromString(chi.URLParam(r, "bucketID"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	rollup, err := h.rollupSvc.FindBucketRollup(r.Context(), *bucketID)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newRollupResponse(rollup))
+}
+
+func (h *Handler) handlePutRollup(w http.ResponseWriter, r *http.Request) {
+	bucketID, err := influxdb.IDFromString(chi.URLParam(r, "bucketID"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	var req putRollupRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	rollup := &influxdb.BucketRollup{
+		OrgID:          req.OrgID,
+		BucketID:       *bucketID,
+		RollupBucketID: req.RollupBucketID,
+		Cutoff:         req.Cutoff,
+	}
+	if err := h.rollupSvc.PutBucketRollup(r.Context(), rollup); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Bucket rollup updated", zap.String("rollup", fmt.Sprint(rollup)))
+
+	h.api.Respond(w, r, http.StatusOK, newRollupResponse(rollup))
+}
+
+func (h *Handler) handleDeleteRollup(w http.ResponseWriter, r *http.Request) {
+	bucketID, err := influxdb.IDFromString(chi.URLParam(r, "bucketID"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	if err := h.rollupSvc.DeleteBucketRollup(r.Context(), *bucketID); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Bucket rollup deleted", zap.String("bucketID", bucketID.String()))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
@@ -0,0 +1,63 @@
+package rollup
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+var _ influxdb.BucketRollupService = (*AuthedService)(nil)
+
+// AuthedService requires read access to a raw bucket to see its rollup and
+// write access to change it. Setting a rollup also requires read access to
+// the rollup bucket, since reads of the raw bucket return its data.
+type AuthedService struct {
+	s influxdb.BucketRollupService
+}
+
+// NewAuthedService wraps s with bucket authorization.
+func NewAuthedService(s influxdb.BucketRollupService) *AuthedService {
+	return &AuthedService{s: s}
+}
+
+func (s *AuthedService) FindBucketRollup(ctx context.Context, bucketID influxdb.ID) (*influxdb.BucketRollup, error) {
+	r, err := s.s.FindBucketRollup(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, r.BucketID, r.OrgID); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *AuthedService) FindBucketRollups(ctx context.Context, filter influxdb.BucketRollupFilter) ([]*influxdb.BucketRollup, error) {
+	rollups, err := s.s.FindBucketRollups(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	rollups, _, err = authorizer.AuthorizeFindBucketRollups(ctx, rollups)
+	return rollups, err
+}
+
+func (s *AuthedService) PutBucketRollup(ctx context.Context, r *influxdb.BucketRollup) error {
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.BucketsResourceType, r.BucketID, r.OrgID); err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, r.RollupBucketID, r.OrgID); err != nil {
+		return err
+	}
+	return s.s.PutBucketRollup(ctx, r)
+}
+
+func (s *AuthedService) DeleteBucketRollup(ctx context.Context, bucketID influxdb.ID) error {
+	r, err := s.s.FindBucketRollup(ctx, bucketID)
+	if err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.BucketsResourceType, r.BucketID, r.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteBucketRollup(ctx, bucketID)
+}
@@ -0,0 +1,197 @@
+// Package rollup maps raw buckets to the buckets their data is downsampled
+// into, so that reads of a raw bucket can be served from its rollup bucket
+// for data older than the raw bucket retains.
+package rollup
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+var rollupBucket = []byte("bucketrollupsv1")
+
+var _ influxdb.BucketRollupService = (*Service)(nil)
+
+// Service stores bucket rollups in a kv store.
+type Service struct {
+	store         kv.Store
+	bucketSvc     influxdb.BucketService
+	TimeGenerator influxdb.TimeGenerator
+}
+
+// NewService constructs a bucket rollup service backed by st. The buckets of
+// the rollups are looked up in bucketSvc.
+func NewService(st kv.Store, bucketSvc influxdb.BucketService) *Service {
+	return &Service{
+		store:         st,
+		bucketSvc:     bucketSvc,
+		TimeGenerator: influxdb.RealTimeGenerator{},
+	}
+}
+
+// FindBucketRollup returns the rollup of the raw bucket.
+func (s *Service) FindBucketRollup(ctx context.Context, bucketID influxdb.ID) (*influxdb.BucketRollup, error) {
+	var r *influxdb.BucketRollup
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		r, err = s.findRollup(tx, bucketID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// FindBucketRollups returns the rollups matching the filter.
+func (s *Service) FindBucketRollups(ctx context.Context, filter influxdb.BucketRollupFilter) ([]*influxdb.BucketRollup, error) {
+	rollups := []*influxdb.BucketRollup{}
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		return s.walkRollups(ctx, tx, func(r *influxdb.BucketRollup) {
+			if filter.OrgID != nil && r.OrgID != *filter.OrgID {
+				return
+			}
+			if filter.BucketID != nil && r.BucketID != *filter.BucketID {
+				return
+			}
+			rollups = append(rollups, r)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rollups, nil
+}
+
+// PutBucketRollup creates or replaces the rollup of a raw bucket. Both
+// buckets must belong to the organization of the rollup, and rollups cannot
+// be chained.
+func (s *Service) PutBucketRollup(ctx context.Context, r *influxdb.BucketRollup) error {
+	if err := r.Valid(); err != nil {
+		return ErrInvalidRollup(err)
+	}
+	for _, id := range []influxdb.ID{r.BucketID, r.RollupBucketID} {
+		id := id
+		if _, err := s.bucketSvc.FindBucket(ctx, influxdb.BucketFilter{ID: &id, OrganizationID: &r.OrgID}); err != nil {
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				return ErrRollupBucket(err)
+			}
+			return err
+		}
+	}
+
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		var existing *influxdb.BucketRollup
+		var chained bool
+		err := s.walkRollups(ctx, tx, func(o *influxdb.BucketRollup) {
+			switch {
+			case o.BucketID == r.BucketID:
+				existing = o
+			case o.BucketID == r.RollupBucketID, o.RollupBucketID == r.BucketID:
+				chained = true
+			}
+		})
+		if err != nil {
+			return err
+		}
+		if chained {
+			return ErrRollupIsRaw
+		}
+
+		now := s.TimeGenerator.Now()
+		r.CreatedAt = now
+		if existing != nil {
+			r.CreatedAt = existing.CreatedAt
+		}
+		r.UpdatedAt = now
+		return s.putRollup(tx, r)
+	})
+}
+
+// DeleteBucketRollup removes the rollup of the raw bucket.
+func (s *Service) DeleteBucketRollup(ctx context.Context, bucketID influxdb.ID) error {
+	key, err := bucketID.Encode()
+	if err != nil {
+		return ErrInvalidBucketID
+	}
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		if _, err := s.findRollup(tx, bucketID); err != nil {
+			return err
+		}
+		b, err := tx.Bucket(rollupBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		if err := b.Delete(key); err != nil {
+			return ErrInternalService(err)
+		}
+		return nil
+	})
+}
+
+func (s *Service) findRollup(tx kv.Tx, bucketID influxdb.ID) (*influxdb.BucketRollup, error) {
+	key, err := bucketID.Encode()
+	if err != nil {
+		return nil, ErrInvalidBucketID
+	}
+
+	b, err := tx.Bucket(rollupBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(key)
+	if kv.IsNotFound(err) {
+		return nil, ErrRollupNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+
+	r := &influxdb.BucketRollup{}
+	if err := json.Unmarshal(v, r); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return r, nil
+}
+
+func (s *Service) walkRollups(ctx context.Context, tx kv.Tx, fn func(*influxdb.BucketRollup)) error {
+	b, err := tx.Bucket(rollupBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	return kv.WalkCursor(ctx, cur, func(k, v []byte) error {
+		r := &influxdb.BucketRollup{}
+		if err := json.Unmarshal(v, r); err != nil {
+			return ErrInternalService(err)
+		}
+		fn(r)
+		return nil
+	})
+}
+
+func (s *Service) putRollup(tx kv.Tx, r *influxdb.BucketRollup) error {
+	key, err := r.BucketID.Encode()
+	if err != nil {
+		return ErrInvalidBucketID
+	}
+	v, err := json.Marshal(r)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+
+	b, err := tx.Bucket(rollupBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(key, v); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
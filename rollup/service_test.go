@@ -0,0 +1,122 @@
+package rollup_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/rollup"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+func newTestService(t *testing.T) (*rollup.Service, func()) {
+	t.Helper()
+
+	s, done := influxdbtesting.NewTestBoltStore(t)
+	// Buckets 10 to 12 belong to organization 1.
+	buckets := influxdbtesting.NewBucketFinder(
+		&influxdb.Bucket{ID: 10, OrgID: 1},
+		&influxdb.Bucket{ID: 11, OrgID: 1},
+		&influxdb.Bucket{ID: 12, OrgID: 1},
+	)
+
+	svc := rollup.NewService(s, buckets)
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)}
+	return svc, done
+}
+
+func TestService_PutFindDelete(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	if _, err := svc.FindBucketRollup(ctx, 10); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	r := &influxdb.BucketRollup{
+		OrgID:          1,
+		BucketID:       10,
+		RollupBucketID: 11,
+		Cutoff:         influxdb.Duration{Duration: 7 * 24 * time.Hour},
+	}
+	if err := svc.PutBucketRollup(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := svc.FindBucketRollup(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.RollupBucketID != 11 || found.Cutoff.Duration != 7*24*time.Hour || found.CreatedAt.IsZero() {
+		t.Fatalf("unexpected rollup %+v", found)
+	}
+
+	orgID := influxdb.ID(1)
+	rollups, err := svc.FindBucketRollups(ctx, influxdb.BucketRollupFilter{OrgID: &orgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rollups) != 1 {
+		t.Fatalf("expected 1 rollup, got %d", len(rollups))
+	}
+
+	if err := svc.DeleteBucketRollup(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DeleteBucketRollup(ctx, 10); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected not found error deleting twice, got %v", err)
+	}
+}
+
+func TestService_PutInvalid(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+	week := influxdb.Duration{Duration: 7 * 24 * time.Hour}
+
+	if err := svc.PutBucketRollup(ctx, &influxdb.BucketRollup{OrgID: 1, BucketID: 10, RollupBucketID: 11, Cutoff: week}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		rollup *influxdb.BucketRollup
+		code   string
+	}{
+		{
+			name:   "own rollup",
+			rollup: &influxdb.BucketRollup{OrgID: 1, BucketID: 12, RollupBucketID: 12, Cutoff: week},
+			code:   influxdb.EInvalid,
+		},
+		{
+			name:   "missing cutoff",
+			rollup: &influxdb.BucketRollup{OrgID: 1, BucketID: 12, RollupBucketID: 11},
+			code:   influxdb.EInvalid,
+		},
+		{
+			name:   "bucket of another org",
+			rollup: &influxdb.BucketRollup{OrgID: 1, BucketID: 12, RollupBucketID: 20, Cutoff: week},
+			code:   influxdb.EInvalid,
+		},
+		{
+			name:   "rollup of a rollup bucket",
+			rollup: &influxdb.BucketRollup{OrgID: 1, BucketID: 11, RollupBucketID: 12, Cutoff: week},
+			code:   influxdb.EConflict,
+		},
+		{
+			name:   "raw bucket as rollup",
+			rollup: &influxdb.BucketRollup{OrgID: 1, BucketID: 12, RollupBucketID: 10, Cutoff: week},
+			code:   influxdb.EConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := svc.PutBucketRollup(ctx, tt.rollup); influxdb.ErrorCode(err) != tt.code {
+				t.Fatalf("expected %s error, got %v", tt.code, err)
+			}
+		})
+	}
+}
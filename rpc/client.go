@@ -0,0 +1,137 @@
+package rpc
+
+import (
+	"context"
+	"io"
+
+	"github.com/influxdata/influxdb/v2"
+	kitgrpc "github.com/influxdata/influxdb/v2/kit/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// Client is a gRPC client for the bucket, write, query and storage services.
+type Client struct {
+	cc      *grpc.ClientConn
+	buckets BucketServiceClient
+	writes  WriteServiceClient
+	queries QueryServiceClient
+}
+
+// NewClient returns a client using the provided connection.
+func NewClient(cc *grpc.ClientConn) *Client {
+	return &Client{
+		cc:      cc,
+		buckets: NewBucketServiceClient(cc),
+		writes:  NewWriteServiceClient(cc),
+		queries: NewQueryServiceClient(cc),
+	}
+}
+
+// WithToken returns a dial option that authenticates every call with token.
+func WithToken(token string, insecure bool) grpc.DialOption {
+	return grpc.WithPerRPCCredentials(tokenCredentials{token: token, insecure: insecure})
+}
+
+type tokenCredentials struct {
+	token    string
+	insecure bool
+}
+
+var _ credentials.PerRPCCredentials = tokenCredentials{}
+
+func (c tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{tokenMetadataKey: tokenScheme + c.token}, nil
+}
+
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return !c.insecure
+}
+
+// FindBucketByID returns a single bucket by ID.
+func (c *Client) FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+	out, err := c.buckets.FindBucketByID(ctx, &FindBucketByIDRequest{ID: uint64(id)})
+	if err != nil {
+		return nil, fromStatusError(err)
+	}
+	return out.toInfluxDB(), nil
+}
+
+// FindBuckets returns the buckets of an organization, optionally filtered by name.
+func (c *Client) FindBuckets(ctx context.Context, orgID influxdb.ID, name string, opts influxdb.FindOptions) ([]*influxdb.Bucket, error) {
+	req := &FindBucketsRequest{
+		OrgID:  uint64(orgID),
+		Name:   name,
+		Offset: int32(opts.Offset),
+		Limit:  int32(opts.Limit),
+	}
+	out, err := c.buckets.FindBuckets(ctx, req)
+	if err != nil {
+		return nil, fromStatusError(err)
+	}
+	bs := make([]*influxdb.Bucket, 0, len(out.Buckets))
+	for _, b := range out.Buckets {
+		bs = append(bs, b.toInfluxDB())
+	}
+	return bs, nil
+}
+
+// CreateBucket creates b and sets its ID.
+func (c *Client) CreateBucket(ctx context.Context, b *influxdb.Bucket) error {
+	out, err := c.buckets.CreateBucket(ctx, &CreateBucketRequest{Bucket: newBucket(b)})
+	if err != nil {
+		return fromStatusError(err)
+	}
+	*b = *out.toInfluxDB()
+	return nil
+}
+
+// DeleteBucket removes a bucket by ID.
+func (c *Client) DeleteBucket(ctx context.Context, id influxdb.ID) error {
+	_, err := c.buckets.DeleteBucket(ctx, &DeleteBucketRequest{ID: uint64(id)})
+	return fromStatusError(err)
+}
+
+// Write writes line protocol data into a bucket.
+func (c *Client) Write(ctx context.Context, orgID, bucketID influxdb.ID, precision string, data []byte) error {
+	req := &WriteRequest{
+		OrgID:     uint64(orgID),
+		BucketID:  uint64(bucketID),
+		Precision: precision,
+		Data:      data,
+	}
+	_, err := c.writes.Write(ctx, req)
+	return fromStatusError(err)
+}
+
+// Query executes a Flux query and calls fn with every streamed frame.
+func (c *Client) Query(ctx context.Context, orgID influxdb.ID, q string, fn func(*QueryResponse) error) error {
+	stream, err := c.queries.Query(ctx, &QueryRequest{OrgID: uint64(orgID), Query: q})
+	if err != nil {
+		return fromStatusError(err)
+	}
+
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fromStatusError(err)
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+}
+
+// fromStatusError converts a gRPC status error back into a platform error.
+func fromStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if perr := kitgrpc.FromStatus(status.Convert(err)); perr != nil {
+		return perr
+	}
+	return err
+}
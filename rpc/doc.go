@@ -0,0 +1,3 @@
+// Package rpc exposes a subset of the platform services over gRPC for
+// internal consumers that want to avoid the overhead of HTTP and annotated CSV.
+package rpc
@@ -0,0 +1,31 @@
+package rpc
+
+import (
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// newBucket returns the wire representation of b.
+func newBucket(b *influxdb.Bucket) *Bucket {
+	return &Bucket{
+		ID:                uint64(b.ID),
+		OrgID:             uint64(b.OrgID),
+		Name:              b.Name,
+		Description:       b.Description,
+		RetentionPeriodNs: int64(b.RetentionPeriod),
+		Type:              b.Type.String(),
+	}
+}
+
+// toInfluxDB returns the bucket represented by m.
+func (m *Bucket) toInfluxDB() *influxdb.Bucket {
+	return &influxdb.Bucket{
+		ID:              influxdb.ID(m.ID),
+		OrgID:           influxdb.ID(m.OrgID),
+		Name:            m.Name,
+		Description:     m.Description,
+		RetentionPeriod: time.Duration(m.RetentionPeriodNs),
+		Type:            influxdb.ParseBucketType(m.Type),
+	}
+}
@@ -0,0 +1,214 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+// maxResponseSize is the size in bytes of the frames of a ReadResponse
+// above which they are sent.
+const maxResponseSize = 64 << 10
+
+// readResponseWriter encodes the series of a result set into ReadResponse
+// frames: a series frame with the tags of each series, followed by the
+// frames of its points. A ReadGroup response precedes the series of each
+// group with a group frame.
+type readResponseWriter struct {
+	send func(*datatypes.ReadResponse) error
+	res  datatypes.ReadResponse
+	size int
+}
+
+func newReadResponseWriter(send func(*datatypes.ReadResponse) error) *readResponseWriter {
+	return &readResponseWriter{send: send}
+}
+
+// WriteResultSet writes the series of rs. The series without points are
+// left out.
+func (w *readResponseWriter) WriteResultSet(rs reads.ResultSet) error {
+	for rs.Next() {
+		if err := w.writeSeries(rs.Tags(), rs.Cursor(), false); err != nil {
+			return err
+		}
+	}
+	if err := rs.Err(); err != nil {
+		return err
+	}
+	return w.flush()
+}
+
+// WriteGroupResultSet writes the groups of rs and their series. The series
+// without points are kept, so that the tags of the groups are complete.
+func (w *readResponseWriter) WriteGroupResultSet(rs reads.GroupResultSet) error {
+	for gc := rs.Next(); gc != nil; gc = rs.Next() {
+		err := w.writeGroup(gc)
+		gc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if err := rs.Err(); err != nil {
+		return err
+	}
+	return w.flush()
+}
+
+func (w *readResponseWriter) writeGroup(gc reads.GroupCursor) error {
+	if err := w.append(datatypes.ReadResponse_Frame{Data: &datatypes.ReadResponse_Frame_Group{Group: &datatypes.ReadResponse_GroupFrame{
+		TagKeys:          gc.Keys(),
+		PartitionKeyVals: gc.PartitionKeyVals(),
+	}}}); err != nil {
+		return err
+	}
+	for gc.Next() {
+		if err := w.writeSeries(gc.Tags(), gc.Cursor(), true); err != nil {
+			return err
+		}
+	}
+	return gc.Err()
+}
+
+// writeSeries writes the series frame of tags and the points of cur. Unless
+// keepEmpty is set, the series frame is removed if cur has no points.
+func (w *readResponseWriter) writeSeries(tags models.Tags, cur cursors.Cursor, keepEmpty bool) error {
+	if cur == nil {
+		return nil
+	}
+	defer cur.Close()
+
+	dataType, err := cursorDataType(cur)
+	if err != nil {
+		return err
+	}
+	frame := &datatypes.ReadResponse_SeriesFrame{
+		Tags:     make([]datatypes.Tag, len(tags)),
+		DataType: dataType,
+	}
+	for i, t := range tags {
+		frame.Tags[i] = datatypes.Tag{Key: t.Key, Value: t.Value}
+	}
+	series := datatypes.ReadResponse_Frame{Data: &datatypes.ReadResponse_Frame_Series{Series: frame}}
+	// Unless keepEmpty is set, the series frame is appended with the first
+	// frame of points, so that it is left out if there is none.
+	pending := !keepEmpty
+	if keepEmpty {
+		if err := w.append(series); err != nil {
+			return err
+		}
+	}
+
+	for {
+		points, ok := nextPoints(cur)
+		if !ok {
+			break
+		}
+		if pending {
+			if err := w.append(series); err != nil {
+				return err
+			}
+			pending = false
+		}
+		if err := w.append(points); err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
+
+// append adds f to the response, and sends it once it exceeds
+// maxResponseSize.
+func (w *readResponseWriter) append(f datatypes.ReadResponse_Frame) error {
+	w.res.Frames = append(w.res.Frames, f)
+	w.size += f.Size()
+	if w.size < maxResponseSize {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *readResponseWriter) flush() error {
+	if len(w.res.Frames) == 0 {
+		return nil
+	}
+	err := w.send(&w.res)
+	w.res.Frames = nil
+	w.size = 0
+	return err
+}
+
+func cursorDataType(cur cursors.Cursor) (datatypes.ReadResponse_DataType, error) {
+	switch cur.(type) {
+	case cursors.FloatArrayCursor:
+		return datatypes.DataTypeFloat, nil
+	case cursors.IntegerArrayCursor:
+		return datatypes.DataTypeInteger, nil
+	case cursors.UnsignedArrayCursor:
+		return datatypes.DataTypeUnsigned, nil
+	case cursors.BooleanArrayCursor:
+		return datatypes.DataTypeBoolean, nil
+	case cursors.StringArrayCursor:
+		return datatypes.DataTypeString, nil
+	default:
+		return 0, fmt.Errorf("unsupported cursor type %T", cur)
+	}
+}
+
+// nextPoints returns the frame of the next points of cur, and false if there
+// are no more. The points are copied, as cur may reuse its arrays.
+func nextPoints(cur cursors.Cursor) (datatypes.ReadResponse_Frame, bool) {
+	var f datatypes.ReadResponse_Frame
+	switch c := cur.(type) {
+	case cursors.FloatArrayCursor:
+		a := c.Next()
+		if a.Len() == 0 {
+			return f, false
+		}
+		f.Data = &datatypes.ReadResponse_Frame_FloatPoints{FloatPoints: &datatypes.ReadResponse_FloatPointsFrame{
+			Timestamps: append([]int64(nil), a.Timestamps...),
+			Values:     append([]float64(nil), a.Values...),
+		}}
+	case cursors.IntegerArrayCursor:
+		a := c.Next()
+		if a.Len() == 0 {
+			return f, false
+		}
+		f.Data = &datatypes.ReadResponse_Frame_IntegerPoints{IntegerPoints: &datatypes.ReadResponse_IntegerPointsFrame{
+			Timestamps: append([]int64(nil), a.Timestamps...),
+			Values:     append([]int64(nil), a.Values...),
+		}}
+	case cursors.UnsignedArrayCursor:
+		a := c.Next()
+		if a.Len() == 0 {
+			return f, false
+		}
+		f.Data = &datatypes.ReadResponse_Frame_UnsignedPoints{UnsignedPoints: &datatypes.ReadResponse_UnsignedPointsFrame{
+			Timestamps: append([]int64(nil), a.Timestamps...),
+			Values:     append([]uint64(nil), a.Values...),
+		}}
+	case cursors.BooleanArrayCursor:
+		a := c.Next()
+		if a.Len() == 0 {
+			return f, false
+		}
+		f.Data = &datatypes.ReadResponse_Frame_BooleanPoints{BooleanPoints: &datatypes.ReadResponse_BooleanPointsFrame{
+			Timestamps: append([]int64(nil), a.Timestamps...),
+			Values:     append([]bool(nil), a.Values...),
+		}}
+	case cursors.StringArrayCursor:
+		a := c.Next()
+		if a.Len() == 0 {
+			return f, false
+		}
+		f.Data = &datatypes.ReadResponse_Frame_StringPoints{StringPoints: &datatypes.ReadResponse_StringPointsFrame{
+			Timestamps: append([]int64(nil), a.Timestamps...),
+			Values:     append([]string(nil), a.Values...),
+		}}
+	default:
+		return f, false
+	}
+	return f, true
+}
@@ -0,0 +1,394 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/http"
+	kitgrpc "github.com/influxdata/influxdb/v2/kit/grpc"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/storage"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	tokenMetadataKey = "authorization"
+	tokenScheme      = "Token "
+
+	// maxRowsPerFrame bounds the number of rows sent in a single QueryResponse.
+	maxRowsPerFrame = 1000
+)
+
+var (
+	// ErrTokenMissing is returned when a call does not carry a token.
+	ErrTokenMissing = &influxdb.Error{
+		Code: influxdb.EUnauthorized,
+		Msg:  "token required",
+	}
+
+	// ErrUnauthorizedToken is returned when the token is unknown or inactive.
+	ErrUnauthorizedToken = &influxdb.Error{
+		Code: influxdb.EUnauthorized,
+		Msg:  "unauthorized access",
+	}
+)
+
+// Server implements the gRPC services on top of the platform services.
+// All calls are authenticated with an authorization token passed as
+// "authorization: Token <token>" metadata.
+type Server struct {
+	log *zap.Logger
+
+	AuthorizationService influxdb.AuthorizationService
+	BucketService        influxdb.BucketService
+	PointsWriter         storage.PointsWriter
+	QueryService         query.QueryService
+
+	// ParserOptions are applied when parsing line protocol on Write.
+	ParserOptions []models.ParserOption
+
+	// PolicyEngine decides the permission checks of the calls, which are
+	// decided by the permissions of their tokens when it is nil.
+	PolicyEngine authorizer.PolicyEngine
+
+	// bucketFinder resolves the bucket of a write without authorization,
+	// since writing only requires write access to the bucket.
+	bucketFinder influxdb.BucketService
+}
+
+// NewServer constructs a new gRPC server. The provided bucket service,
+// serving the system buckets sb, is wrapped with authorization checks.
+func NewServer(log *zap.Logger, authSvc influxdb.AuthorizationService, bucketSvc influxdb.BucketService, sb influxdb.SystemBuckets, pw storage.PointsWriter, qs query.QueryService) *Server {
+	return &Server{
+		log:                  log,
+		AuthorizationService: authSvc,
+		BucketService:        authorizer.NewBucketService(bucketSvc, sb),
+		PointsWriter:         pw,
+		QueryService:         qs,
+		bucketFinder:         bucketSvc,
+	}
+}
+
+// GRPCServer returns a *grpc.Server with every service registered and
+// the authentication interceptors installed.
+func (s *Server) GRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.UnaryInterceptor(s.unaryInterceptor),
+		grpc.StreamInterceptor(s.streamInterceptor),
+	)
+	srv := grpc.NewServer(opts...)
+	RegisterBucketServiceServer(srv, &bucketServer{Server: s})
+	RegisterWriteServiceServer(srv, &writeServer{Server: s})
+	RegisterQueryServiceServer(srv, &queryServer{Server: s})
+	return srv
+}
+
+func (s *Server) authenticate(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, ErrTokenMissing
+	}
+	vals := md.Get(tokenMetadataKey)
+	if len(vals) == 0 || !strings.HasPrefix(vals[0], tokenScheme) {
+		return nil, ErrTokenMissing
+	}
+
+	auth, err := s.AuthorizationService.FindAuthorizationByToken(ctx, vals[0][len(tokenScheme):])
+	if err != nil {
+		s.log.Info("Unauthorized", zap.Error(err))
+		return nil, ErrUnauthorizedToken
+	}
+	if !auth.IsActive() {
+		return nil, ErrUnauthorizedToken
+	}
+
+	return authorizer.WithPolicyEngine(icontext.SetAuthorizer(ctx, auth), s.PolicyEngine), nil
+}
+
+func (s *Server) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	resp, err := handler(ctx, req)
+	return resp, toStatusError(err)
+}
+
+func (s *Server) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context())
+	if err != nil {
+		return toStatusError(err)
+	}
+	return toStatusError(handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx}))
+}
+
+// authenticatedStream overrides the stream context with one carrying the authorizer.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// toStatusError converts a platform error into a gRPC status error.
+func toStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	perr, ok := err.(*influxdb.Error)
+	if !ok {
+		perr = &influxdb.Error{
+			Code: influxdb.ErrorCode(err),
+			Msg:  influxdb.ErrorMessage(err),
+		}
+	}
+	st, serr := kitgrpc.ToStatus(perr)
+	if serr != nil {
+		return serr
+	}
+	return st.Err()
+}
+
+type bucketServer struct {
+	*Server
+}
+
+func (s *bucketServer) FindBucketByID(ctx context.Context, req *FindBucketByIDRequest) (*Bucket, error) {
+	b, err := s.BucketService.FindBucketByID(ctx, influxdb.ID(req.ID))
+	if err != nil {
+		return nil, err
+	}
+	return newBucket(b), nil
+}
+
+func (s *bucketServer) FindBuckets(ctx context.Context, req *FindBucketsRequest) (*FindBucketsResponse, error) {
+	var filter influxdb.BucketFilter
+	if req.OrgID != 0 {
+		orgID := influxdb.ID(req.OrgID)
+		filter.OrganizationID = &orgID
+	}
+	if req.Name != "" {
+		filter.Name = &req.Name
+	}
+
+	bs, _, err := s.BucketService.FindBuckets(ctx, filter, influxdb.FindOptions{
+		Offset: int(req.Offset),
+		Limit:  int(req.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &FindBucketsResponse{
+		Buckets: make([]*Bucket, 0, len(bs)),
+	}
+	for _, b := range bs {
+		resp.Buckets = append(resp.Buckets, newBucket(b))
+	}
+	return resp, nil
+}
+
+func (s *bucketServer) CreateBucket(ctx context.Context, req *CreateBucketRequest) (*Bucket, error) {
+	if req.Bucket == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bucket is required",
+		}
+	}
+	b := req.Bucket.toInfluxDB()
+	b.ID = 0
+	b.Type = influxdb.BucketTypeUser
+	if err := s.BucketService.CreateBucket(ctx, b); err != nil {
+		return nil, err
+	}
+	return newBucket(b), nil
+}
+
+func (s *bucketServer) DeleteBucket(ctx context.Context, req *DeleteBucketRequest) (*Empty, error) {
+	if err := s.BucketService.DeleteBucket(ctx, influxdb.ID(req.ID)); err != nil {
+		return nil, err
+	}
+	return &Empty{}, nil
+}
+
+type writeServer struct {
+	*Server
+}
+
+// Write writes the points of req to its bucket, which must belong to the
+// organization of req.
+func (s *writeServer) Write(ctx context.Context, req *WriteRequest) (*Empty, error) {
+	orgID, bucketID := influxdb.ID(req.OrgID), influxdb.ID(req.BucketID)
+	b, err := s.bucketFinder.FindBucketByID(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+	if b.OrgID != orgID {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  fmt.Sprintf("bucket %s not found in organization %s", bucketID, orgID),
+		}
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.BucketsResourceType, b.ID, b.OrgID); err != nil {
+		return nil, err
+	}
+
+	opts := append([]models.ParserOption{}, s.ParserOptions...)
+	if req.Precision != "" {
+		opts = append(opts, models.WithParserPrecision(req.Precision))
+	}
+	parsed, err := http.NewPointsParser(opts...).ParsePoints(ctx, b.OrgID, b.ID, ioutil.NopCloser(bytes.NewReader(req.Data)))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.PointsWriter.WritePoints(ctx, parsed.Points); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "unexpected error writing points to database",
+			Err:  err,
+		}
+	}
+	return &Empty{}, nil
+}
+
+type queryServer struct {
+	*Server
+}
+
+func (s *queryServer) Query(req *QueryRequest, stream QueryService_QueryServer) error {
+	ctx := stream.Context()
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+	auth, ok := a.(*influxdb.Authorization)
+	if !ok {
+		return &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  "queries require a token authorization",
+		}
+	}
+
+	results, err := s.QueryService.Query(ctx, &query.Request{
+		Authorization:  auth,
+		OrganizationID: influxdb.ID(req.OrgID),
+		Compiler: lang.FluxCompiler{
+			Now:   time.Now(),
+			Query: req.Query,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	defer results.Release()
+
+	for results.More() {
+		res := results.Next()
+		var table int64
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			defer func() { table++ }()
+			return streamTable(stream, res.Name(), table, tbl)
+		}); err != nil {
+			return err
+		}
+	}
+	return results.Err()
+}
+
+// streamTable sends a single table as one or more QueryResponse frames.
+func streamTable(stream QueryService_QueryServer, result string, table int64, tbl flux.Table) error {
+	cols := tbl.Cols()
+	header := &QueryResponse{
+		Result:  result,
+		Table:   table,
+		Columns: make([]*Column, len(cols)),
+	}
+	for j, c := range cols {
+		header.Columns[j] = &Column{
+			Label:    c.Label,
+			Type:     c.Type.String(),
+			GroupKey: tbl.Key().HasCol(c.Label),
+		}
+	}
+
+	// An empty table is still sent so the consumer sees its group key.
+	sentHeader := false
+	err := tbl.Do(func(cr flux.ColReader) error {
+		for start := 0; start < cr.Len(); start += maxRowsPerFrame {
+			end := start + maxRowsPerFrame
+			if end > cr.Len() {
+				end = cr.Len()
+			}
+
+			frame := &QueryResponse{Result: result, Table: table}
+			if !sentHeader {
+				frame = header
+				sentHeader = true
+			}
+			rows, err := readRows(cr, cols, start, end)
+			if err != nil {
+				return err
+			}
+			frame.Rows = rows
+			if err := stream.Send(frame); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !sentHeader {
+		return stream.Send(header)
+	}
+	return nil
+}
+
+func readRows(cr flux.ColReader, cols []flux.ColMeta, start, end int) ([]*Row, error) {
+	rows := make([]*Row, 0, end-start)
+	for i := start; i < end; i++ {
+		row := &Row{Values: make([]*Value, len(cols))}
+		for j, c := range cols {
+			v := execute.ValueForRow(cr, i, j)
+			if v.IsNull() {
+				row.Values[j] = &Value{Null: true}
+				continue
+			}
+			switch c.Type {
+			case flux.TBool:
+				row.Values[j] = &Value{BoolValue: v.Bool()}
+			case flux.TInt:
+				row.Values[j] = &Value{IntValue: v.Int()}
+			case flux.TUInt:
+				row.Values[j] = &Value{UintValue: v.UInt()}
+			case flux.TFloat:
+				row.Values[j] = &Value{FloatValue: v.Float()}
+			case flux.TString:
+				row.Values[j] = &Value{StringValue: v.Str()}
+			case flux.TTime:
+				row.Values[j] = &Value{TimeValue: int64(v.Time())}
+			default:
+				return nil, fmt.Errorf("unsupported column type: %s", c.Type)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,120 @@
+package rpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/rpc"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testToken = "sometoken"
+
+func newTestClient(t *testing.T, bs influxdb.BucketService, pw *mock.PointsWriter, token string) (*rpc.Client, func()) {
+	t.Helper()
+	srv := rpc.NewServer(zaptest.NewLogger(t), newTestAuthorizationService(), bs, influxdb.DefaultSystemBuckets(), pw, nil).GRPCServer()
+	return dialTestServer(t, srv, token)
+}
+
+func newTestAuthorizationService() *mock.AuthorizationService {
+	orgID := influxdb.ID(1)
+	authSvc := mock.NewAuthorizationService()
+	authSvc.FindAuthorizationByTokenFn = func(ctx context.Context, tok string) (*influxdb.Authorization, error) {
+		if tok != testToken {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "authorization not found"}
+		}
+		return &influxdb.Authorization{
+			ID:          2,
+			OrgID:       orgID,
+			UserID:      3,
+			Status:      influxdb.Active,
+			Permissions: influxdb.OperPermissions(),
+		}, nil
+	}
+	return authSvc
+}
+
+func dialTestServer(t *testing.T, srv *grpc.Server, token string) (*rpc.Client, func()) {
+	t.Helper()
+	ln := bufconn.Listen(1 << 20)
+	go srv.Serve(ln)
+
+	cc, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return ln.Dial() }),
+		grpc.WithInsecure(),
+		rpc.WithToken(token, true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rpc.NewClient(cc), func() {
+		cc.Close()
+		srv.Stop()
+	}
+}
+
+func TestServer_FindBucketByID(t *testing.T) {
+	bs := mock.NewBucketService()
+	bs.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: id, OrgID: 1, Name: "b1"}, nil
+	}
+
+	client, done := newTestClient(t, bs, &mock.PointsWriter{}, testToken)
+	defer done()
+
+	b, err := client.FindBucketByID(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ID != 10 || b.Name != "b1" || b.OrgID != 1 {
+		t.Fatalf("unexpected bucket %+v", b)
+	}
+}
+
+func TestServer_Unauthorized(t *testing.T) {
+	client, done := newTestClient(t, mock.NewBucketService(), &mock.PointsWriter{}, "bad")
+	defer done()
+
+	_, err := client.FindBucketByID(context.Background(), 10)
+	if got := influxdb.ErrorCode(err); got != influxdb.EUnauthorized {
+		t.Fatalf("expected unauthorized error, got %q: %v", got, err)
+	}
+}
+
+func TestServer_Write(t *testing.T) {
+	bs := mock.NewBucketService()
+	bs.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		if id != 10 {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+		}
+		return &influxdb.Bucket{ID: id, OrgID: 1, Name: "b1"}, nil
+	}
+	pw := &mock.PointsWriter{}
+	client, done := newTestClient(t, bs, pw, testToken)
+	defer done()
+
+	data := []byte("m,t=v f=1 1000000000\nm,t=v f=2 2000000000")
+	if err := client.Write(context.Background(), 1, 10, "ns", data); err != nil {
+		t.Fatal(err)
+	}
+	if len(pw.Points) != 2 {
+		t.Fatalf("expected 2 points written, got %d", len(pw.Points))
+	}
+
+	// The bucket must belong to the organization of the write.
+	if err := client.Write(context.Background(), 2, 10, "ns", data); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected not found writing to the bucket of another organization, got %v", err)
+	}
+	if err := client.Write(context.Background(), 1, 11, "ns", data); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected not found writing to a missing bucket, got %v", err)
+	}
+	if len(pw.Points) != 2 {
+		t.Fatalf("expected no more points written, got %d", len(pw.Points))
+	}
+}
@@ -0,0 +1,345 @@
+package rpc
+
+import (
+	"context"
+	"io"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/storage/readservice"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"google.golang.org/grpc"
+)
+
+const storageServiceName = "influxdata.platform.storage.Storage"
+
+// maxValuesPerFrame bounds the number of tag keys or values sent in a single
+// StringValuesResponse.
+const maxValuesPerFrame = 1000
+
+// StorageServer is the server API for the Storage service, which streams
+// the raw series of the storage engine.
+type StorageServer interface {
+	ReadFilter(*datatypes.ReadFilterRequest, Storage_ReadServer) error
+	ReadGroup(*datatypes.ReadGroupRequest, Storage_ReadServer) error
+	TagKeys(*datatypes.TagKeysRequest, Storage_StringValuesServer) error
+	TagValues(*datatypes.TagValuesRequest, Storage_StringValuesServer) error
+}
+
+// Storage_ReadServer is the server side stream of ReadResponse frames.
+type Storage_ReadServer interface {
+	Send(*datatypes.ReadResponse) error
+	grpc.ServerStream
+}
+
+// Storage_StringValuesServer is the server side stream of the tag keys or
+// values of a TagKeys or TagValues call.
+type Storage_StringValuesServer interface {
+	Send(*datatypes.StringValuesResponse) error
+	grpc.ServerStream
+}
+
+// RegisterStorageServer registers srv with s.
+func RegisterStorageServer(s *grpc.Server, srv StorageServer) {
+	s.RegisterService(&storageServiceDesc, srv)
+}
+
+var storageServiceDesc = grpc.ServiceDesc{
+	ServiceName: storageServiceName,
+	HandlerType: (*StorageServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: "ReadFilter",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				m := new(datatypes.ReadFilterRequest)
+				if err := stream.RecvMsg(m); err != nil {
+					return err
+				}
+				return srv.(StorageServer).ReadFilter(m, &storageReadServer{stream})
+			},
+			ServerStreams: true,
+		},
+		{
+			StreamName: "ReadGroup",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				m := new(datatypes.ReadGroupRequest)
+				if err := stream.RecvMsg(m); err != nil {
+					return err
+				}
+				return srv.(StorageServer).ReadGroup(m, &storageReadServer{stream})
+			},
+			ServerStreams: true,
+		},
+		{
+			StreamName: "TagKeys",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				m := new(datatypes.TagKeysRequest)
+				if err := stream.RecvMsg(m); err != nil {
+					return err
+				}
+				return srv.(StorageServer).TagKeys(m, &storageStringValuesServer{stream})
+			},
+			ServerStreams: true,
+		},
+		{
+			StreamName: "TagValues",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				m := new(datatypes.TagValuesRequest)
+				if err := stream.RecvMsg(m); err != nil {
+					return err
+				}
+				return srv.(StorageServer).TagValues(m, &storageStringValuesServer{stream})
+			},
+			ServerStreams: true,
+		},
+	},
+	Metadata: "storage.proto",
+}
+
+type storageReadServer struct {
+	grpc.ServerStream
+}
+
+func (x *storageReadServer) Send(m *datatypes.ReadResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+type storageStringValuesServer struct {
+	grpc.ServerStream
+}
+
+func (x *storageStringValuesServer) Send(m *datatypes.StringValuesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// StorageGRPCServer returns a *grpc.Server with only the Storage service
+// registered, reading from store, and the authentication interceptors
+// installed. It is meant to be served on its own listener, so that the raw
+// series are only exposed where it is configured.
+func (s *Server) StorageGRPCServer(store reads.Store, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.UnaryInterceptor(s.unaryInterceptor),
+		grpc.StreamInterceptor(s.streamInterceptor),
+	)
+	srv := grpc.NewServer(opts...)
+	RegisterStorageServer(srv, &storageServer{Server: s, store: store})
+	return srv
+}
+
+type storageServer struct {
+	*Server
+	store reads.Store
+}
+
+// authorizeSource checks that the caller can read the bucket of the read
+// source of a request.
+func (s *storageServer) authorizeSource(ctx context.Context, source *types.Any) error {
+	if source == nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "read source is required",
+		}
+	}
+	orgID, bucketID, err := readservice.ReadSourceIDs(*source)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid read source",
+			Err:  err,
+		}
+	}
+
+	// The bucket service is wrapped with authorization checks, so the bucket
+	// is only found if the caller can read it.
+	b, err := s.BucketService.FindBucketByID(ctx, bucketID)
+	if err != nil {
+		return err
+	}
+	if b.OrgID != orgID {
+		return &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "bucket not found",
+		}
+	}
+	return nil
+}
+
+func (s *storageServer) ReadFilter(req *datatypes.ReadFilterRequest, stream Storage_ReadServer) error {
+	ctx := stream.Context()
+	if err := s.authorizeSource(ctx, req.ReadSource); err != nil {
+		return err
+	}
+
+	rs, err := s.store.ReadFilter(ctx, req)
+	if err != nil {
+		return err
+	}
+	if rs == nil {
+		return nil
+	}
+	defer rs.Close()
+
+	return newReadResponseWriter(stream.Send).WriteResultSet(rs)
+}
+
+func (s *storageServer) ReadGroup(req *datatypes.ReadGroupRequest, stream Storage_ReadServer) error {
+	ctx := stream.Context()
+	if err := s.authorizeSource(ctx, req.ReadSource); err != nil {
+		return err
+	}
+
+	rs, err := s.store.ReadGroup(ctx, req)
+	if err != nil {
+		return err
+	}
+	if rs == nil {
+		return nil
+	}
+	defer rs.Close()
+
+	return newReadResponseWriter(stream.Send).WriteGroupResultSet(rs)
+}
+
+func (s *storageServer) TagKeys(req *datatypes.TagKeysRequest, stream Storage_StringValuesServer) error {
+	ctx := stream.Context()
+	if err := s.authorizeSource(ctx, req.TagsSource); err != nil {
+		return err
+	}
+
+	iter, err := s.store.TagKeys(ctx, req)
+	if err != nil {
+		return err
+	}
+	return sendStringValues(stream, iter)
+}
+
+func (s *storageServer) TagValues(req *datatypes.TagValuesRequest, stream Storage_StringValuesServer) error {
+	ctx := stream.Context()
+	if err := s.authorizeSource(ctx, req.TagsSource); err != nil {
+		return err
+	}
+
+	iter, err := s.store.TagValues(ctx, req)
+	if err != nil {
+		return err
+	}
+	return sendStringValues(stream, iter)
+}
+
+// sendStringValues sends the values of iter in frames of at most
+// maxValuesPerFrame values.
+func sendStringValues(stream Storage_StringValuesServer, iter cursors.StringIterator) error {
+	if iter == nil {
+		return nil
+	}
+	values := make([][]byte, 0, maxValuesPerFrame)
+	for iter.Next() {
+		values = append(values, []byte(iter.Value()))
+		if len(values) == maxValuesPerFrame {
+			if err := stream.Send(&datatypes.StringValuesResponse{Values: values}); err != nil {
+				return err
+			}
+			values = make([][]byte, 0, maxValuesPerFrame)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return stream.Send(&datatypes.StringValuesResponse{Values: values})
+}
+
+// ReadFilter streams the series of the bucket bucketID of the organization
+// orgID matching req, and calls fn with every frame. The read source of req
+// is set from orgID and bucketID.
+func (c *Client) ReadFilter(ctx context.Context, orgID, bucketID influxdb.ID, req *datatypes.ReadFilterRequest, fn func(*datatypes.ReadResponse) error) error {
+	source, err := readservice.NewReadSource(orgID, bucketID)
+	if err != nil {
+		return err
+	}
+	r := *req
+	r.ReadSource = source
+	return c.readStream(ctx, 0, &r, func() interface{} { return new(datatypes.ReadResponse) }, func(m interface{}) error {
+		return fn(m.(*datatypes.ReadResponse))
+	})
+}
+
+// ReadGroup streams the series of the bucket bucketID of the organization
+// orgID matching req, grouped as req requests, and calls fn with every
+// frame. The read source of req is set from orgID and bucketID.
+func (c *Client) ReadGroup(ctx context.Context, orgID, bucketID influxdb.ID, req *datatypes.ReadGroupRequest, fn func(*datatypes.ReadResponse) error) error {
+	source, err := readservice.NewReadSource(orgID, bucketID)
+	if err != nil {
+		return err
+	}
+	r := *req
+	r.ReadSource = source
+	return c.readStream(ctx, 1, &r, func() interface{} { return new(datatypes.ReadResponse) }, func(m interface{}) error {
+		return fn(m.(*datatypes.ReadResponse))
+	})
+}
+
+// TagKeys returns the tag keys of the series of the bucket bucketID of the
+// organization orgID matching req.
+func (c *Client) TagKeys(ctx context.Context, orgID, bucketID influxdb.ID, req *datatypes.TagKeysRequest) ([]string, error) {
+	source, err := readservice.NewReadSource(orgID, bucketID)
+	if err != nil {
+		return nil, err
+	}
+	r := *req
+	r.TagsSource = source
+	return c.stringValues(ctx, 2, &r)
+}
+
+// TagValues returns the values of the tag req.TagKey of the series of the
+// bucket bucketID of the organization orgID matching req.
+func (c *Client) TagValues(ctx context.Context, orgID, bucketID influxdb.ID, req *datatypes.TagValuesRequest) ([]string, error) {
+	source, err := readservice.NewReadSource(orgID, bucketID)
+	if err != nil {
+		return nil, err
+	}
+	r := *req
+	r.TagsSource = source
+	return c.stringValues(ctx, 3, &r)
+}
+
+func (c *Client) stringValues(ctx context.Context, stream int, req interface{}) ([]string, error) {
+	var values []string
+	err := c.readStream(ctx, stream, req, func() interface{} { return new(datatypes.StringValuesResponse) }, func(m interface{}) error {
+		for _, v := range m.(*datatypes.StringValuesResponse).Values {
+			values = append(values, string(v))
+		}
+		return nil
+	})
+	return values, err
+}
+
+// readStream calls the stream method of the Storage service at index stream
+// of its description with req, and calls fn with every message received.
+func (c *Client) readStream(ctx context.Context, stream int, req interface{}, newResp func() interface{}, fn func(interface{}) error) error {
+	desc := &storageServiceDesc.Streams[stream]
+	cs, err := c.cc.NewStream(ctx, desc, "/"+storageServiceName+"/"+desc.StreamName)
+	if err != nil {
+		return fromStatusError(err)
+	}
+	if err := cs.SendMsg(req); err != nil {
+		return fromStatusError(err)
+	}
+	if err := cs.CloseSend(); err != nil {
+		return fromStatusError(err)
+	}
+
+	for {
+		m := newResp()
+		if err := cs.RecvMsg(m); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fromStatusError(err)
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/rpc"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"go.uber.org/zap/zaptest"
+)
+
+// testStore is a reads.Store of float series, those without a host tag
+// having no points.
+type testStore struct {
+	reads.Store
+	series []models.Tags
+	values []string
+}
+
+func (s *testStore) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
+	return &testResultSet{series: s.series, i: -1}, nil
+}
+
+func (s *testStore) TagValues(ctx context.Context, req *datatypes.TagValuesRequest) (cursors.StringIterator, error) {
+	return cursors.NewStringSliceIterator(s.values), nil
+}
+
+func (s *testStore) GetSource(orgID, bucketID uint64) proto.Message {
+	return nil
+}
+
+type testResultSet struct {
+	reads.ResultSet
+	series []models.Tags
+	i      int
+}
+
+func (rs *testResultSet) Next() bool {
+	rs.i++
+	return rs.i < len(rs.series)
+}
+
+func (rs *testResultSet) Tags() models.Tags { return rs.series[rs.i] }
+func (rs *testResultSet) Close()            {}
+func (rs *testResultSet) Err() error        { return nil }
+
+func (rs *testResultSet) Cursor() cursors.Cursor {
+	c := &floatCursor{}
+	if rs.series[rs.i].Get([]byte("host")) != nil {
+		c.a = &cursors.FloatArray{Timestamps: []int64{10, 20}, Values: []float64{1.5, 2.5}}
+	}
+	return c
+}
+
+type floatCursor struct {
+	a *cursors.FloatArray
+}
+
+func (c *floatCursor) Next() *cursors.FloatArray {
+	a := c.a
+	if a == nil {
+		a = &cursors.FloatArray{}
+	}
+	c.a = nil
+	return a
+}
+
+func (c *floatCursor) Close()                     {}
+func (c *floatCursor) Err() error                 { return nil }
+func (c *floatCursor) Stats() cursors.CursorStats { return cursors.CursorStats{} }
+
+func newStorageTestClient(t *testing.T, store reads.Store, token string) (*rpc.Client, func()) {
+	t.Helper()
+
+	bs := mock.NewBucketService()
+	bs.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: id, OrgID: 1, Name: "b1"}, nil
+	}
+	srv := rpc.NewServer(zaptest.NewLogger(t), newTestAuthorizationService(), bs, influxdb.DefaultSystemBuckets(), nil, nil).StorageGRPCServer(store)
+	return dialTestServer(t, srv, token)
+}
+
+func TestStorage_ReadFilter(t *testing.T) {
+	store := &testStore{series: []models.Tags{
+		models.NewTags(map[string]string{"_m": "cpu", "host": "a"}),
+		models.NewTags(map[string]string{"_m": "mem"}),
+	}}
+	client, done := newStorageTestClient(t, store, testToken)
+	defer done()
+
+	var frames []datatypes.ReadResponse_Frame
+	if err := client.ReadFilter(context.Background(), 1, 10, &datatypes.ReadFilterRequest{}, func(res *datatypes.ReadResponse) error {
+		frames = append(frames, res.Frames...)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The series without points is left out.
+	want := []datatypes.ReadResponse_Frame{
+		{Data: &datatypes.ReadResponse_Frame_Series{Series: &datatypes.ReadResponse_SeriesFrame{
+			Tags: []datatypes.Tag{
+				{Key: []byte("_m"), Value: []byte("cpu")},
+				{Key: []byte("host"), Value: []byte("a")},
+			},
+			DataType: datatypes.DataTypeFloat,
+		}}},
+		{Data: &datatypes.ReadResponse_Frame_FloatPoints{FloatPoints: &datatypes.ReadResponse_FloatPointsFrame{
+			Timestamps: []int64{10, 20},
+			Values:     []float64{1.5, 2.5},
+		}}},
+	}
+	if diff := cmp.Diff(want, frames); diff != "" {
+		t.Fatalf("unexpected frames (-want +got):\n%s", diff)
+	}
+}
+
+func TestStorage_TagValues(t *testing.T) {
+	client, done := newStorageTestClient(t, &testStore{values: []string{"a", "b"}}, testToken)
+	defer done()
+
+	values, err := client.TagValues(context.Background(), 1, 10, &datatypes.TagValuesRequest{TagKey: "host"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"a", "b"}, values); diff != "" {
+		t.Fatalf("unexpected tag values (-want +got):\n%s", diff)
+	}
+}
+
+func TestStorage_Unauthorized(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		orgID    influxdb.ID
+		wantCode string
+	}{
+		{name: "bad token", token: "bad", orgID: 1, wantCode: influxdb.EUnauthorized},
+		{name: "bucket of another organization", token: testToken, orgID: 2, wantCode: influxdb.ENotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, done := newStorageTestClient(t, &testStore{values: []string{"a"}}, tt.token)
+			defer done()
+
+			_, err := client.TagValues(context.Background(), tt.orgID, 10, &datatypes.TagValuesRequest{TagKey: "host"})
+			if got := influxdb.ErrorCode(err); got != tt.wantCode {
+				t.Fatalf("expected %s error, got %q: %v", tt.wantCode, got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package influxdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// SavedQueryScope is the scope of the users a saved query is shared with.
+type SavedQueryScope string
+
+const (
+	// SavedQueryScopeOrg shares a query with the organization: it is read
+	// and written with the permissions on the saved queries of the
+	// organization.
+	SavedQueryScopeOrg SavedQueryScope = "org"
+	// SavedQueryScopeUser restricts a query to the users it is shared with:
+	// its members, who can read it, and its owners, who can edit and share
+	// it. Its creator is its first owner.
+	SavedQueryScopeUser SavedQueryScope = "user"
+)
+
+// Valid returns an error if the scope is unknown.
+func (s SavedQueryScope) Valid() error {
+	switch s {
+	case SavedQueryScopeOrg, SavedQueryScopeUser:
+		return nil
+	default:
+		return fmt.Errorf("invalid scope %q, must be %q or %q", s, SavedQueryScopeOrg, SavedQueryScopeUser)
+	}
+}
+
+// SavedQuery is a named Flux query of an organization, so that commonly used
+// queries are referenced by the CLI and dashboards rather than copied.
+type SavedQuery struct {
+	ID          ID              `json:"id,omitempty"`
+	OrgID       ID              `json:"orgID"`
+	OwnerID     ID              `json:"ownerID,omitempty"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Query       string          `json:"query"`
+	Scope       SavedQueryScope `json:"scope"`
+	CRUDLog
+}
+
+// Restricted returns true if the query is only accessible to the users it
+// is shared with.
+func (q *SavedQuery) Restricted() bool {
+	return q.Scope == SavedQueryScopeUser
+}
+
+// Valid returns an error if the query is missing required fields.
+func (q *SavedQuery) Valid() error {
+	if !q.OrgID.Valid() {
+		return errors.New("orgID is required")
+	}
+	if q.Name == "" {
+		return errors.New("name is required")
+	}
+	if q.Query == "" {
+		return errors.New("query is required")
+	}
+	return q.Scope.Valid()
+}
+
+// SavedQueryFilter represents a set of filters that restrict the returned
+// saved queries.
+type SavedQueryFilter struct {
+	OrgID   *ID
+	OwnerID *ID
+	Name    *string
+	Scope   *SavedQueryScope
+}
+
+// SavedQueryUpdate is the changeset of a saved query.
+type SavedQueryUpdate struct {
+	Name        *string          `json:"name,omitempty"`
+	Description *string          `json:"description,omitempty"`
+	Query       *string          `json:"query,omitempty"`
+	Scope       *SavedQueryScope `json:"scope,omitempty"`
+}
+
+// Apply applies the changeset to q.
+func (u SavedQueryUpdate) Apply(q *SavedQuery) {
+	if u.Name != nil {
+		q.Name = *u.Name
+	}
+	if u.Description != nil {
+		q.Description = *u.Description
+	}
+	if u.Query != nil {
+		q.Query = *u.Query
+	}
+	if u.Scope != nil {
+		q.Scope = *u.Scope
+	}
+}
+
+// SavedQueryService stores the saved queries of organizations.
+type SavedQueryService interface {
+	// CreateSavedQuery creates a saved query owned by q.OwnerID, or by the
+	// user of the context if it is not set. Names are unique in an
+	// organization.
+	CreateSavedQuery(ctx context.Context, q *SavedQuery) error
+
+	// FindSavedQueryByID returns a single saved query by ID.
+	FindSavedQueryByID(ctx context.Context, id ID) (*SavedQuery, error)
+
+	// FindSavedQueries returns a list of saved queries that match filter
+	// and the total count of matching queries.
+	FindSavedQueries(ctx context.Context, filter SavedQueryFilter, opt ...FindOptions) ([]*SavedQuery, int, error)
+
+	// UpdateSavedQuery updates a single saved query with changeset.
+	UpdateSavedQuery(ctx context.Context, id ID, upd SavedQueryUpdate) (*SavedQuery, error)
+
+	// DeleteSavedQuery removes a single saved query by ID, along with the
+	// users it is shared with.
+	DeleteSavedQuery(ctx context.Context, id ID) error
+}
@@ -0,0 +1,43 @@
+package savedquery
+
+import (
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrSavedQueryNotFound is used when the specified saved query cannot be found.
+	ErrSavedQueryNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "saved query not found",
+	}
+
+	// ErrInvalidSavedQueryID is used when the ID of the saved query cannot be encoded.
+	ErrInvalidSavedQueryID = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "saved query ID is invalid",
+	}
+
+	// ErrSavedQueryNameConflict is used when the name of a saved query is
+	// already used in its organization.
+	ErrSavedQueryNameConflict = &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  "saved query name is not unique in the organization",
+	}
+)
+
+// ErrInvalidSavedQuery is used when a service was provided an invalid saved query.
+func ErrInvalidSavedQuery(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "saved query provided is invalid",
+		Err:  err,
+	}
+}
+
+// ErrInternalService is used when the error comes from an internal system.
+func ErrInternalService(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
@@ -0,0 +1,284 @@
+package savedquery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixSavedQueries is the prefix of the saved query API.
+	PrefixSavedQueries = "/api/v2/savedqueries"
+)
+
+// Handler is the HTTP API handler for saved queries.
+type Handler struct {
+	chi.Router
+	api      *kithttp.API
+	log      *zap.Logger
+	querySvc influxdb.SavedQueryService
+	labelSvc influxdb.LabelService
+	orgSvc   influxdb.OrganizationService
+}
+
+// NewHTTPHandler constructs a new http server. The members and owners a
+// query is shared with are managed by urmHandler, and its labels by
+// labelHandler.
+func NewHTTPHandler(log *zap.Logger, querySvc influxdb.SavedQueryService, labelSvc influxdb.LabelService, orgSvc influxdb.OrganizationService, urmHandler, labelHandler http.Handler) *Handler {
+	h := &Handler{
+		api:      kithttp.NewAPI(kithttp.WithLog(log)),
+		log:      log,
+		querySvc: querySvc,
+		labelSvc: labelSvc,
+		orgSvc:   orgSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Post("/", h.handlePostSavedQuery)
+		r.Get("/", h.handleGetSavedQueries)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetSavedQuery)
+			r.Patch("/", h.handlePatchSavedQuery)
+			r.Delete("/", h.handleDeleteSavedQuery)
+
+			// mount embedded resources
+			mountableRouter := r.With(h.validSavedQuery)
+			mountableRouter.Mount("/members", urmHandler)
+			mountableRouter.Mount("/owners", urmHandler)
+			mountableRouter.Mount("/labels", labelHandler)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixSavedQueries
+}
+
+// validSavedQuery serves the embedded resources of the saved queries the
+// user can read. The organization of a query shared with its organization
+// is embedded into the context, so that its members and owners are managed
+// with the permissions of the organization. Those of a restricted query are
+// only managed with the permissions on the query, which its owners have.
+func (h *Handler) validSavedQuery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+		if err != nil {
+			h.api.Err(w, r, influxdb.ErrCorruptID(err))
+			return
+		}
+
+		ctx := r.Context()
+		q, err := h.querySvc.FindSavedQueryByID(ctx, *id)
+		if err != nil {
+			h.api.Err(w, r, ErrSavedQueryNotFound)
+			return
+		}
+		if !q.Restricted() {
+			ctx = context.WithValue(ctx, kithttp.CtxOrgKey, q.OrgID)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+type savedQueryResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.SavedQuery
+	Labels []influxdb.Label `json:"labels"`
+}
+
+func (h *Handler) newSavedQueryResponse(ctx context.Context, q *influxdb.SavedQuery) *savedQueryResponse {
+	res := &savedQueryResponse{
+		Links: map[string]string{
+			"self":    fmt.Sprintf("%s/%s", PrefixSavedQueries, q.ID),
+			"org":     fmt.Sprintf("/api/v2/orgs/%s", q.OrgID),
+			"members": fmt.Sprintf("%s/%s/members", PrefixSavedQueries, q.ID),
+			"owners":  fmt.Sprintf("%s/%s/owners", PrefixSavedQueries, q.ID),
+			"labels":  fmt.Sprintf("%s/%s/labels", PrefixSavedQueries, q.ID),
+		},
+		SavedQuery: q,
+		Labels:     []influxdb.Label{},
+	}
+	if h.labelSvc != nil { // allow for no label svc
+		labels, _ := h.labelSvc.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: q.ID, ResourceType: influxdb.SavedQueriesResourceType})
+		for _, l := range labels {
+			res.Labels = append(res.Labels, *l)
+		}
+	}
+	return res
+}
+
+type savedQueriesResponse struct {
+	Links   map[string]string     `json:"links"`
+	Queries []*savedQueryResponse `json:"queries"`
+}
+
+type postSavedQueryRequest struct {
+	OrgID       influxdb.ID              `json:"orgID"`
+	Org         string                   `json:"org,omitempty"` // the ID or name of the organization, when OrgID is not set
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Query       string                   `json:"query"`
+	Scope       influxdb.SavedQueryScope `json:"scope"`
+}
+
+// resolveOrgID returns the ID of the organization identified by orgID, or
+// else by org.
+func (h *Handler) resolveOrgID(ctx context.Context, orgID, org string) (influxdb.ID, error) {
+	o, err := influxdb.ResolveOrganization(ctx, h.orgSvc, orgID, org)
+	if err != nil {
+		return 0, err
+	}
+	return o.ID, nil
+}
+
+func (h *Handler) handlePostSavedQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req postSavedQueryRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if !req.OrgID.Valid() {
+		orgID, err := h.resolveOrgID(ctx, "", req.Org)
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		req.OrgID = orgID
+	}
+
+	q := &influxdb.SavedQuery{
+		OrgID:       req.OrgID,
+		Name:        req.Name,
+		Description: req.Description,
+		Query:       req.Query,
+		Scope:       req.Scope,
+	}
+	if err := h.querySvc.CreateSavedQuery(ctx, q); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Saved query created", zap.String("query", q.Name), zap.String("orgID", q.OrgID.String()))
+
+	h.api.Respond(w, r, http.StatusCreated, h.newSavedQueryResponse(ctx, q))
+}
+
+func (h *Handler) handleGetSavedQueries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var filter influxdb.SavedQueryFilter
+	q := r.URL.Query()
+	if orgID, org := q.Get("orgID"), q.Get("org"); orgID != "" || org != "" {
+		id, err := h.resolveOrgID(ctx, orgID, org)
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		filter.OrgID = &id
+	}
+	if v := q.Get("ownerID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			h.api.Err(w, r, influxdb.ErrCorruptID(err))
+			return
+		}
+		filter.OwnerID = id
+	}
+	if v := q.Get("name"); v != "" {
+		filter.Name = &v
+	}
+	if v := q.Get("scope"); v != "" {
+		scope := influxdb.SavedQueryScope(v)
+		if err := scope.Valid(); err != nil {
+			h.api.Err(w, r, &influxdb.Error{Code: influxdb.EInvalid, Err: err})
+			return
+		}
+		filter.Scope = &scope
+	}
+
+	queries, _, err := h.querySvc.FindSavedQueries(ctx, filter)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	resp := savedQueriesResponse{
+		Links:   map[string]string{"self": PrefixSavedQueries},
+		Queries: make([]*savedQueryResponse, 0, len(queries)),
+	}
+	for _, q := range queries {
+		resp.Queries = append(resp.Queries, h.newSavedQueryResponse(ctx, q))
+	}
+	h.api.Respond(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleGetSavedQuery(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	q, err := h.querySvc.FindSavedQueryByID(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, h.newSavedQueryResponse(r.Context(), q))
+}
+
+func (h *Handler) handlePatchSavedQuery(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	var upd influxdb.SavedQueryUpdate
+	if err := h.api.DecodeJSON(r.Body, &upd); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	q, err := h.querySvc.UpdateSavedQuery(r.Context(), *id, upd)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Saved query updated", zap.String("queryID", q.ID.String()))
+
+	h.api.Respond(w, r, http.StatusOK, h.newSavedQueryResponse(r.Context(), q))
+}
+
+func (h *Handler) handleDeleteSavedQuery(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	if err := h.querySvc.DeleteSavedQuery(r.Context(), *id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Saved query deleted", zap.String("queryID", id.String()))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
@@ -0,0 +1,78 @@
+package savedquery
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+var _ influxdb.SavedQueryService = (*AuthedService)(nil)
+
+// AuthedService requires read access to a saved query to view it and write
+// access to edit or delete it. The access to the queries restricted to
+// users is only granted by permissions on the queries themselves, which are
+// given to their members and owners.
+type AuthedService struct {
+	s influxdb.SavedQueryService
+}
+
+// NewAuthedService wraps s with saved query authorization.
+func NewAuthedService(s influxdb.SavedQueryService) *AuthedService {
+	return &AuthedService{s: s}
+}
+
+func (s *AuthedService) CreateSavedQuery(ctx context.Context, q *influxdb.SavedQuery) error {
+	if _, _, err := authorizer.AuthorizeCreate(ctx, influxdb.SavedQueriesResourceType, q.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateSavedQuery(ctx, q)
+}
+
+func (s *AuthedService) FindSavedQueryByID(ctx context.Context, id influxdb.ID) (*influxdb.SavedQuery, error) {
+	q, err := s.s.FindSavedQueryByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeReadRestricted(ctx, influxdb.SavedQueriesResourceType, q.ID, q.OrgID, q.Restricted()); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (s *AuthedService) FindSavedQueries(ctx context.Context, filter influxdb.SavedQueryFilter, opt ...influxdb.FindOptions) ([]*influxdb.SavedQuery, int, error) {
+	queries, _, err := s.s.FindSavedQueries(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return authorizer.AuthorizeFindSavedQueries(ctx, queries)
+}
+
+func (s *AuthedService) UpdateSavedQuery(ctx context.Context, id influxdb.ID, upd influxdb.SavedQueryUpdate) (*influxdb.SavedQuery, error) {
+	q, err := s.s.FindSavedQueryByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.SavedQueriesResourceType, q.ID, q.OrgID); err != nil {
+		return nil, err
+	}
+	// Sharing a restricted query with its organization publishes it there,
+	// which takes the permission to create the queries of the organization.
+	if q.Restricted() && upd.Scope != nil && *upd.Scope == influxdb.SavedQueryScopeOrg {
+		if _, _, err := authorizer.AuthorizeCreate(ctx, influxdb.SavedQueriesResourceType, q.OrgID); err != nil {
+			return nil, err
+		}
+	}
+	return s.s.UpdateSavedQuery(ctx, id, upd)
+}
+
+func (s *AuthedService) DeleteSavedQuery(ctx context.Context, id influxdb.ID) error {
+	q, err := s.s.FindSavedQueryByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.SavedQueriesResourceType, q.ID, q.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteSavedQuery(ctx, id)
+}
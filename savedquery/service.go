@@ -0,0 +1,430 @@
+// Package savedquery implements saved queries: named Flux queries stored per
+// organization that the CLI and dashboards reference instead of copies of
+// their text. A query is either shared with its organization or restricted
+// to the users it is shared with through its members and owners.
+package savedquery
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/snowflake"
+)
+
+var (
+	savedQueryBucket = []byte("savedqueriesv1")
+	indexBucket      = []byte("savedqueryindexv1")
+)
+
+var _ influxdb.SavedQueryService = (*Service)(nil)
+
+// Service stores saved queries in a kv store, and their owners and members
+// as user resource mappings.
+type Service struct {
+	store         kv.Store
+	urmSvc        influxdb.UserResourceMappingService
+	IDGen         influxdb.IDGenerator
+	TimeGenerator influxdb.TimeGenerator
+}
+
+// NewService constructs a saved query service backed by st.
+func NewService(st kv.Store, urmSvc influxdb.UserResourceMappingService) *Service {
+	return &Service{
+		store:         st,
+		urmSvc:        urmSvc,
+		IDGen:         snowflake.NewDefaultIDGenerator(),
+		TimeGenerator: influxdb.RealTimeGenerator{},
+	}
+}
+
+// CreateSavedQuery creates a saved query and makes its owner the first
+// owner of its user resource mappings. Queries without a scope are shared
+// with their organization.
+func (s *Service) CreateSavedQuery(ctx context.Context, q *influxdb.SavedQuery) error {
+	if q.Scope == "" {
+		q.Scope = influxdb.SavedQueryScopeOrg
+	}
+	if !q.OwnerID.Valid() {
+		if userID, err := icontext.GetUserID(ctx); err == nil {
+			q.OwnerID = userID
+		}
+	}
+	if err := valid(q); err != nil {
+		return err
+	}
+	if !q.OwnerID.Valid() {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "saved query provided is invalid: ownerID is required",
+		}
+	}
+
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		if err := s.checkNameAvailable(tx, q.OrgID, q.Name); err != nil {
+			return err
+		}
+
+		now := s.TimeGenerator.Now()
+		q.ID = s.IDGen.ID()
+		q.CreatedAt = now
+		q.UpdatedAt = now
+
+		if err := s.putSavedQuery(tx, q); err != nil {
+			return err
+		}
+		return s.putIndex(tx, q)
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.urmSvc.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+		ResourceID:   q.ID,
+		ResourceType: influxdb.SavedQueriesResourceType,
+		UserID:       q.OwnerID,
+		UserType:     influxdb.Owner,
+		Restricted:   q.Restricted(),
+	})
+}
+
+// FindSavedQueryByID returns a single saved query by ID.
+func (s *Service) FindSavedQueryByID(ctx context.Context, id influxdb.ID) (*influxdb.SavedQuery, error) {
+	var q *influxdb.SavedQuery
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		q, err = s.getSavedQuery(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// FindSavedQueries returns a list of saved queries that match filter and the
+// total count of matching queries, ordered by name.
+func (s *Service) FindSavedQueries(ctx context.Context, filter influxdb.SavedQueryFilter, opt ...influxdb.FindOptions) ([]*influxdb.SavedQuery, int, error) {
+	queries := []*influxdb.SavedQuery{}
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		if filter.OrgID != nil && filter.Name != nil {
+			q, err := s.findByName(tx, *filter.OrgID, *filter.Name)
+			if err == ErrSavedQueryNotFound {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if filterFunc(q, filter) {
+				queries = append(queries, q)
+			}
+			return nil
+		}
+
+		b, err := tx.Bucket(savedQueryBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		return kv.WalkCursor(ctx, cur, func(k, v []byte) error {
+			q := &influxdb.SavedQuery{}
+			if err := json.Unmarshal(v, q); err != nil {
+				return ErrInternalService(err)
+			}
+			if filterFunc(q, filter) {
+				queries = append(queries, q)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.SliceStable(queries, func(i, j int) bool {
+		return queries[i].Name < queries[j].Name
+	})
+
+	total := len(queries)
+	if len(opt) > 0 {
+		queries = paginate(queries, opt[0])
+	}
+	return queries, total, nil
+}
+
+// UpdateSavedQuery updates a single saved query with changeset.
+func (s *Service) UpdateSavedQuery(ctx context.Context, id influxdb.ID, upd influxdb.SavedQueryUpdate) (*influxdb.SavedQuery, error) {
+	var (
+		q          *influxdb.SavedQuery
+		restricted bool
+	)
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		var err error
+		q, err = s.getSavedQuery(tx, id)
+		if err != nil {
+			return err
+		}
+		prevName := q.Name
+		restricted = q.Restricted()
+
+		upd.Apply(q)
+		if err := valid(q); err != nil {
+			return err
+		}
+
+		if q.Name != prevName {
+			if err := s.checkNameAvailable(tx, q.OrgID, q.Name); err != nil {
+				return err
+			}
+			if err := s.deleteIndex(tx, q.OrgID, prevName); err != nil {
+				return err
+			}
+			if err := s.putIndex(tx, q); err != nil {
+				return err
+			}
+		}
+
+		q.UpdatedAt = s.TimeGenerator.Now()
+		return s.putSavedQuery(tx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Restricted() != restricted {
+		if err := s.restrictMappings(ctx, q.ID, q.Restricted()); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+// restrictMappings marks the user resource mappings of the saved query id as
+// restricted or not, so that they give permissions on the query only while it
+// is restricted to its members and owners.
+func (s *Service) restrictMappings(ctx context.Context, id influxdb.ID, restricted bool) error {
+	mappings, _, err := s.urmSvc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceID:   id,
+		ResourceType: influxdb.SavedQueriesResourceType,
+	})
+	if err != nil {
+		return err
+	}
+	// The owners are mapped again right after they are removed.
+	ctx = icontext.SetForceOwnerRemoval(ctx)
+	for _, m := range mappings {
+		if err := s.urmSvc.DeleteUserResourceMapping(ctx, m.ResourceID, m.UserID); err != nil {
+			return err
+		}
+		m.Restricted = restricted
+		if err := s.urmSvc.CreateUserResourceMapping(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteSavedQuery removes a single saved query by ID, and then the user
+// resource mappings of its owners and members.
+func (s *Service) DeleteSavedQuery(ctx context.Context, id influxdb.ID) error {
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		q, err := s.getSavedQuery(tx, id)
+		if err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return ErrInvalidSavedQueryID
+		}
+		b, err := tx.Bucket(savedQueryBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		if err := b.Delete(encodedID); err != nil {
+			return ErrInternalService(err)
+		}
+		return s.deleteIndex(tx, q.OrgID, q.Name)
+	})
+	if err != nil {
+		return err
+	}
+
+	mappings, _, err := s.urmSvc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceID:   id,
+		ResourceType: influxdb.SavedQueriesResourceType,
+	})
+	if err != nil {
+		return err
+	}
+	// The owners of a deleted saved query are removed with it.
+	ctx = icontext.SetForceOwnerRemoval(ctx)
+	for _, m := range mappings {
+		if err := s.urmSvc.DeleteUserResourceMapping(ctx, m.ResourceID, m.UserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// valid returns an error if q is invalid or its query does not parse.
+func valid(q *influxdb.SavedQuery) error {
+	if err := q.Valid(); err != nil {
+		return ErrInvalidSavedQuery(err)
+	}
+	if pkg := parser.ParseSource(q.Query); ast.Check(pkg) > 0 {
+		return ErrInvalidSavedQuery(ast.GetError(pkg))
+	}
+	return nil
+}
+
+func (s *Service) checkNameAvailable(tx kv.Tx, orgID influxdb.ID, name string) error {
+	_, err := s.findByName(tx, orgID, name)
+	if err == nil {
+		return ErrSavedQueryNameConflict
+	}
+	if err != ErrSavedQueryNotFound {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) findByName(tx kv.Tx, orgID influxdb.ID, name string) (*influxdb.SavedQuery, error) {
+	key, err := indexKey(orgID, name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := tx.Bucket(indexBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(key)
+	if kv.IsNotFound(err) {
+		return nil, ErrSavedQueryNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	var id influxdb.ID
+	if err := id.Decode(v); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return s.getSavedQuery(tx, id)
+}
+
+func (s *Service) getSavedQuery(tx kv.Tx, id influxdb.ID) (*influxdb.SavedQuery, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidSavedQueryID
+	}
+
+	b, err := tx.Bucket(savedQueryBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(encodedID)
+	if kv.IsNotFound(err) {
+		return nil, ErrSavedQueryNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+
+	q := &influxdb.SavedQuery{}
+	if err := json.Unmarshal(v, q); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return q, nil
+}
+
+func (s *Service) putSavedQuery(tx kv.Tx, q *influxdb.SavedQuery) error {
+	encodedID, err := q.ID.Encode()
+	if err != nil {
+		return ErrInvalidSavedQueryID
+	}
+	v, err := json.Marshal(q)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+
+	b, err := tx.Bucket(savedQueryBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(encodedID, v); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+func (s *Service) putIndex(tx kv.Tx, q *influxdb.SavedQuery) error {
+	key, err := indexKey(q.OrgID, q.Name)
+	if err != nil {
+		return err
+	}
+	encodedID, err := q.ID.Encode()
+	if err != nil {
+		return ErrInvalidSavedQueryID
+	}
+	b, err := tx.Bucket(indexBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(key, encodedID); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+func (s *Service) deleteIndex(tx kv.Tx, orgID influxdb.ID, name string) error {
+	key, err := indexKey(orgID, name)
+	if err != nil {
+		return err
+	}
+	b, err := tx.Bucket(indexBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Delete(key); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+// indexKey returns the key of the index of the saved query named name in the
+// organization.
+func indexKey(orgID influxdb.ID, name string) ([]byte, error) {
+	encodedOrgID, err := orgID.Encode()
+	if err != nil {
+		return nil, ErrInvalidSavedQuery(err)
+	}
+	return append(encodedOrgID, name...), nil
+}
+
+func filterFunc(q *influxdb.SavedQuery, filter influxdb.SavedQueryFilter) bool {
+	return (filter.OrgID == nil || q.OrgID == *filter.OrgID) &&
+		(filter.OwnerID == nil || q.OwnerID == *filter.OwnerID) &&
+		(filter.Name == nil || q.Name == *filter.Name) &&
+		(filter.Scope == nil || q.Scope == *filter.Scope)
+}
+
+func paginate(queries []*influxdb.SavedQuery, opt influxdb.FindOptions) []*influxdb.SavedQuery {
+	if opt.Offset > 0 {
+		if opt.Offset >= len(queries) {
+			return []*influxdb.SavedQuery{}
+		}
+		queries = queries[opt.Offset:]
+	}
+	if opt.Limit > 0 && opt.Limit < len(queries) {
+		queries = queries[:opt.Limit]
+	}
+	return queries
+}
@@ -0,0 +1,193 @@
+package savedquery_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/savedquery"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+// newTestService returns a service whose user resource mappings are kept in
+// the returned map by resource ID, and the function closing its store.
+func newTestService(t *testing.T) (*savedquery.Service, map[influxdb.ID][]*influxdb.UserResourceMapping, func()) {
+	t.Helper()
+
+	s, done := influxdbtesting.NewTestBoltStore(t)
+
+	mappings := map[influxdb.ID][]*influxdb.UserResourceMapping{}
+	urmSvc := mock.NewUserResourceMappingService()
+	urmSvc.CreateMappingFn = func(_ context.Context, m *influxdb.UserResourceMapping) error {
+		mappings[m.ResourceID] = append(mappings[m.ResourceID], m)
+		return nil
+	}
+	urmSvc.FindMappingsFn = func(_ context.Context, f influxdb.UserResourceMappingFilter) ([]*influxdb.UserResourceMapping, int, error) {
+		return mappings[f.ResourceID], len(mappings[f.ResourceID]), nil
+	}
+	urmSvc.DeleteMappingFn = func(_ context.Context, resourceID, userID influxdb.ID) error {
+		var ms []*influxdb.UserResourceMapping
+		for _, m := range mappings[resourceID] {
+			if m.UserID != userID {
+				ms = append(ms, m)
+			}
+		}
+		mappings[resourceID] = ms
+		return nil
+	}
+
+	svc := savedquery.NewService(s, urmSvc)
+	svc.IDGen = mock.NewIncrementingIDGenerator(1)
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)}
+	return svc, mappings, done
+}
+
+const query = `from(bucket: "telegraf") |> range(start: -1h)`
+
+func TestService_CreateSavedQuery(t *testing.T) {
+	svc, mappings, done := newTestService(t)
+	defer done()
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{UserID: 10})
+
+	q := &influxdb.SavedQuery{OrgID: 1, Name: "cpu", Query: query}
+	if err := svc.CreateSavedQuery(ctx, q); err != nil {
+		t.Fatal(err)
+	}
+	if q.Scope != influxdb.SavedQueryScopeOrg || q.OwnerID != 10 {
+		t.Errorf("expected an org query owned by the user of the context, got scope %q and owner %v", q.Scope, q.OwnerID)
+	}
+	if ms := mappings[q.ID]; len(ms) != 1 || ms[0].UserID != 10 || ms[0].UserType != influxdb.Owner {
+		t.Errorf("expected the creator to own the query, got %v", ms)
+	}
+
+	err := svc.CreateSavedQuery(ctx, &influxdb.SavedQuery{OrgID: 1, Name: "cpu", Query: query})
+	if influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Errorf("expected a conflict for a duplicate name, got %v", err)
+	}
+	if err := svc.CreateSavedQuery(ctx, &influxdb.SavedQuery{OrgID: 2, Name: "cpu", Query: query}); err != nil {
+		t.Errorf("expected names to be unique per organization: %v", err)
+	}
+
+	err = svc.CreateSavedQuery(ctx, &influxdb.SavedQuery{OrgID: 1, Name: "broken", Query: `from(bucket: "telegraf") |>`})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected an invalid Flux query to be rejected, got %v", err)
+	}
+	err = svc.CreateSavedQuery(context.Background(), &influxdb.SavedQuery{OrgID: 1, Name: "ownerless", Query: query})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected a query without owner to be rejected, got %v", err)
+	}
+}
+
+func TestService_UpdateSavedQuery(t *testing.T) {
+	svc, _, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	q := &influxdb.SavedQuery{OrgID: 1, OwnerID: 10, Name: "cpu", Query: query}
+	if err := svc.CreateSavedQuery(ctx, q); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.CreateSavedQuery(ctx, &influxdb.SavedQuery{OrgID: 1, OwnerID: 10, Name: "mem", Query: query}); err != nil {
+		t.Fatal(err)
+	}
+
+	taken := "mem"
+	if _, err := svc.UpdateSavedQuery(ctx, q.ID, influxdb.SavedQueryUpdate{Name: &taken}); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Errorf("expected a conflict renaming to a used name, got %v", err)
+	}
+
+	name, scope := "cpu usage", influxdb.SavedQueryScopeUser
+	if _, err := svc.UpdateSavedQuery(ctx, q.ID, influxdb.SavedQueryUpdate{Name: &name, Scope: &scope}); err != nil {
+		t.Fatal(err)
+	}
+
+	orgID := influxdb.ID(1)
+	queries, _, err := svc.FindSavedQueries(ctx, influxdb.SavedQueryFilter{OrgID: &orgID, Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 1 || queries[0].ID != q.ID || !queries[0].Restricted() {
+		t.Errorf("expected the restricted query found by its new name, got %v", queries)
+	}
+
+	old := "cpu"
+	queries, _, err = svc.FindSavedQueries(ctx, influxdb.SavedQueryFilter{OrgID: &orgID, Name: &old})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 0 {
+		t.Errorf("expected the previous name to be released, got %v", queries)
+	}
+}
+
+func TestService_DeleteSavedQuery(t *testing.T) {
+	svc, mappings, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	q := &influxdb.SavedQuery{OrgID: 1, OwnerID: 10, Name: "cpu", Query: query, Scope: influxdb.SavedQueryScopeUser}
+	if err := svc.CreateSavedQuery(ctx, q); err != nil {
+		t.Fatal(err)
+	}
+	mappings[q.ID] = append(mappings[q.ID], &influxdb.UserResourceMapping{
+		ResourceID:   q.ID,
+		ResourceType: influxdb.SavedQueriesResourceType,
+		UserID:       11,
+		UserType:     influxdb.Member,
+	})
+
+	if err := svc.DeleteSavedQuery(ctx, q.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindSavedQueryByID(ctx, q.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected the query to be deleted, got %v", err)
+	}
+	if ms := mappings[q.ID]; len(ms) != 0 {
+		t.Errorf("expected the members and owners to be removed, got %v", ms)
+	}
+	if err := svc.CreateSavedQuery(ctx, &influxdb.SavedQuery{OrgID: 1, OwnerID: 10, Name: "cpu", Query: query}); err != nil {
+		t.Errorf("expected the name of the deleted query to be released: %v", err)
+	}
+}
+
+func TestService_UpdateSavedQuery_Scope(t *testing.T) {
+	svc, mappings, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	q := &influxdb.SavedQuery{OrgID: 1, OwnerID: 10, Name: "cpu", Query: query, Scope: influxdb.SavedQueryScopeUser}
+	if err := svc.CreateSavedQuery(ctx, q); err != nil {
+		t.Fatal(err)
+	}
+	if ms := mappings[q.ID]; len(ms) != 1 || !ms[0].Restricted {
+		t.Fatalf("expected the owner of a restricted query to get permissions on it, got %v", ms)
+	}
+
+	scope := influxdb.SavedQueryScopeOrg
+	if _, err := svc.UpdateSavedQuery(ctx, q.ID, influxdb.SavedQueryUpdate{Scope: &scope}); err != nil {
+		t.Fatal(err)
+	}
+	if ms := mappings[q.ID]; len(ms) != 1 || ms[0].Restricted || ms[0].UserID != 10 || ms[0].UserType != influxdb.Owner {
+		t.Errorf("expected the owner of a query shared with its organization to keep permissions from the organization only, got %v", ms)
+	}
+}
+
+func TestService_NotFound(t *testing.T) {
+	svc, mappings, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	name := "cpu"
+	if _, err := svc.UpdateSavedQuery(ctx, 99, influxdb.SavedQueryUpdate{Name: &name}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error updating a missing query, got %v", err)
+	}
+	if err := svc.DeleteSavedQuery(ctx, 99); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error deleting a missing query, got %v", err)
+	}
+	if len(mappings) != 0 {
+		t.Errorf("expected no mappings changed, got %v", mappings)
+	}
+}
@@ -0,0 +1,27 @@
+package schema
+
+import (
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrMissingTagKey is used when a tag value lookup does not specify the tag key.
+	ErrMissingTagKey = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "tag key is required",
+	}
+
+	// ErrMissingMeasurement is used when a measurement delete does not specify the measurement.
+	ErrMissingMeasurement = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "measurement is required",
+	}
+)
+
+// ErrInternalService is used when the error comes from an internal system.
+func ErrInternalService(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
@@ -0,0 +1,252 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+type handler struct {
+	log *zap.Logger
+	api *kithttp.API
+	svc influxdb.BucketSchemaService
+}
+
+// NewHTTPEmbeddedHandler creates the schema handler mounted beneath
+// /api/v2/buckets/:id/schema. The bucket ID is read from the "id" url
+// parameter and the org ID from the context set by kithttp.ValidResource.
+func NewHTTPEmbeddedHandler(log *zap.Logger, svc influxdb.BucketSchemaService) http.Handler {
+	h := &handler{
+		log: log,
+		api: kithttp.NewAPI(kithttp.WithLog(log)),
+		svc: svc,
+	}
+
+	r := chi.NewRouter()
+	r.Get("/measurements", h.handleGetMeasurements)
+	r.Get("/tag-keys", h.handleGetTagKeys)
+	r.Get("/tag-values", h.handleGetTagValues)
+	r.Get("/field-keys", h.handleGetFieldKeys)
+	return r
+}
+
+type schemaRequest struct {
+	orgID       influxdb.ID
+	bucketID    influxdb.ID
+	measurement string
+	rng         influxdb.SchemaRange
+}
+
+func decodeSchemaRequest(r *http.Request) (*schemaRequest, error) {
+	bucketID, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		return nil, influxdb.ErrCorruptID(err)
+	}
+	orgID := kithttp.OrgIDFromContext(r.Context())
+	if orgID == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to determine the bucket's organization",
+		}
+	}
+
+	req := &schemaRequest{
+		orgID:       *orgID,
+		bucketID:    *bucketID,
+		measurement: r.URL.Query().Get("measurement"),
+	}
+
+	if req.rng.Start, err = parseTime(r, "start"); err != nil {
+		return nil, err
+	}
+	if req.rng.Stop, err = parseTime(r, "stop"); err != nil {
+		return nil, err
+	}
+	if !req.rng.Start.IsZero() && !req.rng.Stop.IsZero() && req.rng.Stop.Before(req.rng.Start) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "stop must not be before start",
+		}
+	}
+	return req, nil
+}
+
+func parseTime(r *http.Request, param string) (time.Time, error) {
+	v := r.URL.Query().Get(param)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return time.Time{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid %s time, must be RFC3339", param),
+			Err:  err,
+		}
+	}
+	return t, nil
+}
+
+func schemaLinks(bucketID influxdb.ID, resource string) map[string]string {
+	return map[string]string{
+		"bucket": fmt.Sprintf("/api/v2/buckets/%s", bucketID),
+		"self":   fmt.Sprintf("/api/v2/buckets/%s/schema/%s", bucketID, resource),
+	}
+}
+
+// handleGetMeasurements is the HTTP handler for the GET /api/v2/buckets/:id/schema/measurements route.
+func (h *handler) handleGetMeasurements(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeSchemaRequest(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	ms, err := h.svc.Measurements(r.Context(), req.orgID, req.bucketID, req.rng)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, struct {
+		Links        map[string]string `json:"links"`
+		Measurements []string          `json:"measurements"`
+	}{
+		Links:        schemaLinks(req.bucketID, "measurements"),
+		Measurements: ms,
+	})
+}
+
+// handleGetTagKeys is the HTTP handler for the GET /api/v2/buckets/:id/schema/tag-keys route.
+func (h *handler) handleGetTagKeys(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeSchemaRequest(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	keys, err := h.svc.TagKeys(r.Context(), req.orgID, req.bucketID, req.measurement, req.rng)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, struct {
+		Links   map[string]string `json:"links"`
+		TagKeys []string          `json:"tagKeys"`
+	}{
+		Links:   schemaLinks(req.bucketID, "tag-keys"),
+		TagKeys: keys,
+	})
+}
+
+// handleGetTagValues is the HTTP handler for the GET /api/v2/buckets/:id/schema/tag-values route.
+func (h *handler) handleGetTagValues(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeSchemaRequest(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	tagKey := r.URL.Query().Get("tag")
+	if tagKey == "" {
+		h.api.Err(w, r, ErrMissingTagKey)
+		return
+	}
+
+	values, err := h.svc.TagValues(r.Context(), req.orgID, req.bucketID, req.measurement, tagKey, req.rng)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, struct {
+		Links     map[string]string `json:"links"`
+		TagValues []string          `json:"tagValues"`
+	}{
+		Links:     schemaLinks(req.bucketID, "tag-values"),
+		TagValues: values,
+	})
+}
+
+// handleGetFieldKeys is the HTTP handler for the GET /api/v2/buckets/:id/schema/field-keys route.
+func (h *handler) handleGetFieldKeys(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeSchemaRequest(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	keys, err := h.svc.FieldKeys(r.Context(), req.orgID, req.bucketID, req.measurement, req.rng)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, struct {
+		Links     map[string]string   `json:"links"`
+		FieldKeys []influxdb.FieldKey `json:"fieldKeys"`
+	}{
+		Links:     schemaLinks(req.bucketID, "field-keys"),
+		FieldKeys: keys,
+	})
+}
+
+type measurementHandler struct {
+	log *zap.Logger
+	api *kithttp.API
+	svc influxdb.MeasurementDeleteService
+}
+
+// NewHTTPMeasurementHandler creates the measurement handler mounted beneath
+// /api/v2/buckets/:id/measurements. Like the schema handler, it reads the
+// bucket ID from the "id" url parameter and the org ID from the context.
+func NewHTTPMeasurementHandler(log *zap.Logger, svc influxdb.MeasurementDeleteService) http.Handler {
+	h := &measurementHandler{
+		log: log,
+		api: kithttp.NewAPI(kithttp.WithLog(log)),
+		svc: svc,
+	}
+
+	r := chi.NewRouter()
+	r.Delete("/{measurement}", h.handleDeleteMeasurement)
+	return r
+}
+
+// handleDeleteMeasurement is the HTTP handler for the DELETE /api/v2/buckets/:id/measurements/:measurement route.
+func (h *measurementHandler) handleDeleteMeasurement(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeSchemaRequest(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	req.measurement = chi.URLParam(r, "measurement")
+	if req.measurement == "" {
+		h.api.Err(w, r, ErrMissingMeasurement)
+		return
+	}
+
+	min, max := int64(math.MinInt64), int64(math.MaxInt64)
+	if !req.rng.Start.IsZero() {
+		min = req.rng.Start.UnixNano()
+	}
+	if !req.rng.Stop.IsZero() {
+		max = req.rng.Stop.UnixNano()
+	}
+
+	if err := h.svc.DeleteMeasurementRange(r.Context(), req.orgID, req.bucketID, req.measurement, min, max); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.log.Debug("Measurement deleted",
+		zap.String("bucketID", req.bucketID.String()),
+		zap.String("measurement", req.measurement))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,67 @@
+package schema
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+var _ influxdb.BucketSchemaService = (*AuthedService)(nil)
+
+// AuthedService requires read access to a bucket before exposing its schema.
+type AuthedService struct {
+	s influxdb.BucketSchemaService
+}
+
+// NewAuthedService wraps s with bucket read authorization.
+func NewAuthedService(s influxdb.BucketSchemaService) *AuthedService {
+	return &AuthedService{s: s}
+}
+
+func (s *AuthedService) Measurements(ctx context.Context, orgID, bucketID influxdb.ID, rng influxdb.SchemaRange) ([]string, error) {
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.Measurements(ctx, orgID, bucketID, rng)
+}
+
+func (s *AuthedService) TagKeys(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, rng influxdb.SchemaRange) ([]string, error) {
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.TagKeys(ctx, orgID, bucketID, measurement, rng)
+}
+
+func (s *AuthedService) TagValues(ctx context.Context, orgID, bucketID influxdb.ID, measurement, tagKey string, rng influxdb.SchemaRange) ([]string, error) {
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.TagValues(ctx, orgID, bucketID, measurement, tagKey, rng)
+}
+
+func (s *AuthedService) FieldKeys(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, rng influxdb.SchemaRange) ([]influxdb.FieldKey, error) {
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FieldKeys(ctx, orgID, bucketID, measurement, rng)
+}
+
+var _ influxdb.MeasurementDeleteService = (*AuthedDeleteService)(nil)
+
+// AuthedDeleteService requires write access to a bucket before deleting its measurements.
+type AuthedDeleteService struct {
+	s influxdb.MeasurementDeleteService
+}
+
+// NewAuthedDeleteService wraps s with bucket write authorization.
+func NewAuthedDeleteService(s influxdb.MeasurementDeleteService) *AuthedDeleteService {
+	return &AuthedDeleteService{s: s}
+}
+
+func (s *AuthedDeleteService) DeleteMeasurementRange(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, min, max int64) error {
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return err
+	}
+	return s.s.DeleteMeasurementRange(ctx, orgID, bucketID, measurement, min, max)
+}
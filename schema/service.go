@@ -0,0 +1,170 @@
+// Package schema implements the bucket schema catalog, answering
+// measurement, tag and field lookups directly from the storage index.
+package schema
+
+import (
+	"context"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"github.com/influxdata/influxql"
+)
+
+const (
+	// MeasurementTagKey is the user facing name of the measurement tag key.
+	MeasurementTagKey = "_measurement"
+	// FieldTagKey is the user facing name of the field tag key.
+	FieldTagKey = "_field"
+)
+
+// Reader is the subset of the storage engine used to read schema information.
+type Reader interface {
+	MeasurementNames(ctx context.Context, orgID, bucketID influxdb.ID, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error)
+	MeasurementTagKeys(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error)
+	MeasurementTagValues(ctx context.Context, orgID, bucketID influxdb.ID, measurement, tagKey string, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error)
+	MeasurementFields(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, start, end int64, predicate influxql.Expr) (cursors.MeasurementFieldsIterator, error)
+	TagKeys(ctx context.Context, orgID, bucketID influxdb.ID, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error)
+	TagValues(ctx context.Context, orgID, bucketID influxdb.ID, tagKey string, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error)
+}
+
+var _ influxdb.BucketSchemaService = (*Service)(nil)
+
+// Service reads bucket schema from the storage engine.
+type Service struct {
+	reader Reader
+}
+
+// NewService constructs a schema service backed by the provided reader.
+func NewService(r Reader) *Service {
+	return &Service{reader: r}
+}
+
+// Measurements returns the measurement names in a bucket.
+func (s *Service) Measurements(ctx context.Context, orgID, bucketID influxdb.ID, rng influxdb.SchemaRange) ([]string, error) {
+	start, end := timeRange(rng)
+	itr, err := s.reader.MeasurementNames(ctx, orgID, bucketID, start, end, nil)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return toSlice(itr), nil
+}
+
+// TagKeys returns the tag keys in a bucket, optionally restricted to a measurement.
+// The internal measurement and field keys are reported as _measurement and _field.
+func (s *Service) TagKeys(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, rng influxdb.SchemaRange) ([]string, error) {
+	start, end := timeRange(rng)
+
+	var (
+		itr cursors.StringIterator
+		err error
+	)
+	if measurement == "" {
+		itr, err = s.reader.TagKeys(ctx, orgID, bucketID, start, end, nil)
+	} else {
+		itr, err = s.reader.MeasurementTagKeys(ctx, orgID, bucketID, measurement, start, end, nil)
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+
+	keys := toSlice(itr)
+	for i, k := range keys {
+		switch k {
+		case models.MeasurementTagKey:
+			keys[i] = MeasurementTagKey
+		case models.FieldKeyTagKey:
+			keys[i] = FieldTagKey
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// TagValues returns the values of a tag key, optionally restricted to a measurement.
+func (s *Service) TagValues(ctx context.Context, orgID, bucketID influxdb.ID, measurement, tagKey string, rng influxdb.SchemaRange) ([]string, error) {
+	if tagKey == "" {
+		return nil, ErrMissingTagKey
+	}
+
+	switch tagKey {
+	case MeasurementTagKey:
+		tagKey = models.MeasurementTagKey
+	case FieldTagKey:
+		tagKey = models.FieldKeyTagKey
+	}
+
+	start, end := timeRange(rng)
+
+	var (
+		itr cursors.StringIterator
+		err error
+	)
+	if measurement == "" {
+		itr, err = s.reader.TagValues(ctx, orgID, bucketID, tagKey, start, end, nil)
+	} else {
+		itr, err = s.reader.MeasurementTagValues(ctx, orgID, bucketID, measurement, tagKey, start, end, nil)
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return toSlice(itr), nil
+}
+
+// FieldKeys returns the field keys and their types. When no measurement is
+// provided the fields of every measurement in the range are combined.
+func (s *Service) FieldKeys(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, rng influxdb.SchemaRange) ([]influxdb.FieldKey, error) {
+	measurements := []string{measurement}
+	if measurement == "" {
+		ms, err := s.Measurements(ctx, orgID, bucketID, rng)
+		if err != nil {
+			return nil, err
+		}
+		measurements = ms
+	}
+
+	start, end := timeRange(rng)
+
+	var fields cursors.MeasurementFieldSlice
+	for _, m := range measurements {
+		itr, err := s.reader.MeasurementFields(ctx, orgID, bucketID, m, start, end, nil)
+		if err != nil {
+			return nil, ErrInternalService(err)
+		}
+		fields = append(fields, cursors.MeasurementFieldsIteratorFlatMap(itr)...)
+	}
+
+	// When the same key was written with different types, keep the most recent one.
+	sort.Sort(sort.Reverse(fields))
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	fields.UniqueByKey()
+
+	keys := make([]influxdb.FieldKey, 0, len(fields))
+	for _, f := range fields {
+		keys = append(keys, influxdb.FieldKey{
+			Name: f.Key,
+			Type: cursors.FieldTypeToDataType(f.Type).String(),
+		})
+	}
+	return keys, nil
+}
+
+func timeRange(rng influxdb.SchemaRange) (start, end int64) {
+	start, end = models.MinNanoTime, models.MaxNanoTime
+	if !rng.Start.IsZero() {
+		start = rng.Start.UnixNano()
+	}
+	if !rng.Stop.IsZero() {
+		end = rng.Stop.UnixNano()
+	}
+	return start, end
+}
+
+func toSlice(itr cursors.StringIterator) []string {
+	vs := cursors.StringIteratorToSlice(itr)
+	if vs == nil {
+		vs = []string{}
+	}
+	return vs
+}
@@ -0,0 +1,250 @@
+package schema_test
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/schema"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"github.com/influxdata/influxql"
+	"go.uber.org/zap/zaptest"
+)
+
+type fakeReader struct {
+	measurements []string
+	tagKeys      map[string][]string
+	fields       map[string][]cursors.MeasurementField
+
+	start, end int64
+}
+
+func (r *fakeReader) MeasurementNames(ctx context.Context, orgID, bucketID influxdb.ID, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error) {
+	r.start, r.end = start, end
+	return cursors.NewStringSliceIterator(r.measurements), nil
+}
+
+func (r *fakeReader) MeasurementTagKeys(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error) {
+	return cursors.NewStringSliceIterator(r.tagKeys[measurement]), nil
+}
+
+func (r *fakeReader) MeasurementTagValues(ctx context.Context, orgID, bucketID influxdb.ID, measurement, tagKey string, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error) {
+	return cursors.NewStringSliceIterator(nil), nil
+}
+
+func (r *fakeReader) MeasurementFields(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, start, end int64, predicate influxql.Expr) (cursors.MeasurementFieldsIterator, error) {
+	return cursors.NewMeasurementFieldsSliceIterator([]cursors.MeasurementFields{{Fields: r.fields[measurement]}}), nil
+}
+
+func (r *fakeReader) TagKeys(ctx context.Context, orgID, bucketID influxdb.ID, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error) {
+	return cursors.NewStringSliceIterator(r.tagKeys[""]), nil
+}
+
+func (r *fakeReader) TagValues(ctx context.Context, orgID, bucketID influxdb.ID, tagKey string, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error) {
+	if tagKey == models.MeasurementTagKey {
+		return cursors.NewStringSliceIterator(r.measurements), nil
+	}
+	return cursors.NewStringSliceIterator(nil), nil
+}
+
+func TestService_TagKeys(t *testing.T) {
+	r := &fakeReader{
+		tagKeys: map[string][]string{
+			"": {models.MeasurementTagKey, "host", "region", models.FieldKeyTagKey},
+		},
+	}
+
+	keys, err := schema.NewService(r).TagKeys(context.Background(), 1, 2, "", influxdb.SchemaRange{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"_field", "_measurement", "host", "region"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("unexpected tag keys: got %v, want %v", keys, want)
+	}
+}
+
+func TestService_TagValues(t *testing.T) {
+	r := &fakeReader{measurements: []string{"cpu", "mem"}}
+	svc := schema.NewService(r)
+
+	values, err := svc.TagValues(context.Background(), 1, 2, "", schema.MeasurementTagKey, influxdb.SchemaRange{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"cpu", "mem"}; !reflect.DeepEqual(values, want) {
+		t.Fatalf("unexpected tag values: got %v, want %v", values, want)
+	}
+
+	if _, err := svc.TagValues(context.Background(), 1, 2, "", "", influxdb.SchemaRange{}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid error for missing tag key, got %v", err)
+	}
+}
+
+func TestService_FieldKeys(t *testing.T) {
+	r := &fakeReader{
+		measurements: []string{"cpu", "mem"},
+		fields: map[string][]cursors.MeasurementField{
+			"cpu": {
+				{Key: "usage", Type: cursors.Integer, Timestamp: 10},
+				{Key: "idle", Type: cursors.Float, Timestamp: 10},
+			},
+			"mem": {
+				{Key: "usage", Type: cursors.Float, Timestamp: 20},
+			},
+		},
+	}
+
+	keys, err := schema.NewService(r).FieldKeys(context.Background(), 1, 2, "", influxdb.SchemaRange{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []influxdb.FieldKey{
+		{Name: "idle", Type: "float"},
+		{Name: "usage", Type: "float"},
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("unexpected field keys: got %v, want %v", keys, want)
+	}
+	if r.start != models.MinNanoTime || r.end != models.MaxNanoTime {
+		t.Fatalf("expected unbounded range, got [%d, %d]", r.start, r.end)
+	}
+}
+
+func TestHTTPEmbeddedHandler(t *testing.T) {
+	r := &fakeReader{measurements: []string{"cpu", "mem"}}
+	h := schema.NewHTTPEmbeddedHandler(zaptest.NewLogger(t), schema.NewService(r))
+
+	orgID := influxdb.ID(1)
+	router := chi.NewRouter()
+	router.Route("/api/v2/buckets/{id}", func(cr chi.Router) {
+		cr.With(kithttp.ValidResource(kithttp.NewAPI(), func(ctx context.Context, id influxdb.ID) (influxdb.ID, error) {
+			return orgID, nil
+		})).Mount("/schema", h)
+	})
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+		body   string
+	}{
+		{
+			name:   "measurements",
+			url:    "/api/v2/buckets/0000000000000002/schema/measurements?start=2020-01-01T00:00:00Z",
+			status: http.StatusOK,
+			body:   `{"links":{"bucket":"/api/v2/buckets/0000000000000002","self":"/api/v2/buckets/0000000000000002/schema/measurements"},"measurements":["cpu","mem"]}`,
+		},
+		{
+			name:   "invalid start",
+			url:    "/api/v2/buckets/0000000000000002/schema/measurements?start=yesterday",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "missing tag",
+			url:    "/api/v2/buckets/0000000000000002/schema/tag-values",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("unexpected status: got %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.body != "" {
+				var got, want interface{}
+				if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+					t.Fatal(err)
+				}
+				if err := json.Unmarshal([]byte(tt.body), &want); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("unexpected body: got %s, want %s", w.Body.String(), tt.body)
+				}
+			}
+		})
+	}
+}
+
+type measurementDeleter struct {
+	measurement string
+	min, max    int64
+}
+
+func (d *measurementDeleter) DeleteMeasurementRange(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, min, max int64) error {
+	d.measurement, d.min, d.max = measurement, min, max
+	return nil
+}
+
+func TestHTTPMeasurementHandler(t *testing.T) {
+	d := &measurementDeleter{}
+	h := schema.NewHTTPMeasurementHandler(zaptest.NewLogger(t), d)
+
+	orgID := influxdb.ID(1)
+	router := chi.NewRouter()
+	router.Route("/api/v2/buckets/{id}", func(cr chi.Router) {
+		cr.With(kithttp.ValidResource(kithttp.NewAPI(), func(ctx context.Context, id influxdb.ID) (influxdb.ID, error) {
+			return orgID, nil
+		})).Mount("/measurements", h)
+	})
+
+	tests := []struct {
+		name     string
+		url      string
+		status   int
+		min, max int64
+	}{
+		{
+			name:   "whole measurement",
+			url:    "/api/v2/buckets/0000000000000002/measurements/cpu",
+			status: http.StatusNoContent,
+			min:    math.MinInt64,
+			max:    math.MaxInt64,
+		},
+		{
+			name:   "time range",
+			url:    "/api/v2/buckets/0000000000000002/measurements/cpu?start=1970-01-01T00:00:00.000000001Z&stop=1970-01-01T00:00:00.000000002Z",
+			status: http.StatusNoContent,
+			min:    1,
+			max:    2,
+		},
+		{
+			name:   "inverted time range",
+			url:    "/api/v2/buckets/0000000000000002/measurements/cpu?start=2020-01-02T00:00:00Z&stop=2020-01-01T00:00:00Z",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*d = measurementDeleter{}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, tt.url, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("unexpected status: got %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status != http.StatusNoContent {
+				if d.measurement != "" {
+					t.Fatalf("unexpected delete of %q", d.measurement)
+				}
+				return
+			}
+			if d.measurement != "cpu" || d.min != tt.min || d.max != tt.max {
+				t.Fatalf("unexpected delete: got %+v", *d)
+			}
+		})
+	}
+}
@@ -2,6 +2,10 @@ package influxdb
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"regexp"
 )
 
 // ErrScraperTargetNotFound is the error msg for a missing scraper target.
@@ -24,6 +28,220 @@ type ScraperTarget struct {
 	URL      string      `json:"url"`
 	OrgID    ID          `json:"orgID,omitempty"`
 	BucketID ID          `json:"bucketID,omitempty"`
+
+	// Auth authenticates scrape requests.
+	Auth *ScraperAuth `json:"auth,omitempty"`
+	// Headers are added to every scrape request.
+	Headers map[string]string `json:"headers,omitempty"`
+	// TLS configures the connection to https targets.
+	TLS *ScraperTLSConfig `json:"tls,omitempty"`
+	// MetricRelabelConfigs are applied, in order, to every scraped metric before it is written.
+	MetricRelabelConfigs []ScraperRelabelConfig `json:"metricRelabelConfigs,omitempty"`
+	// Discovery resolves the endpoints to scrape dynamically. When set, URL is
+	// only used for its default scheme and path.
+	Discovery *ScraperDiscovery `json:"discovery,omitempty"`
+}
+
+// Valid returns an error if the authentication, TLS or relabel configuration is invalid.
+func (t *ScraperTarget) Valid() error {
+	if t.Auth != nil {
+		if err := t.Auth.Valid(); err != nil {
+			return err
+		}
+	}
+	if t.TLS != nil {
+		if err := t.TLS.Valid(); err != nil {
+			return err
+		}
+	}
+	for i, c := range t.MetricRelabelConfigs {
+		if err := c.Valid(); err != nil {
+			return fmt.Errorf("metric relabel config %d: %v", i, err)
+		}
+	}
+	if t.Discovery != nil {
+		if err := t.Discovery.Valid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Redacted returns a copy of the target with its secrets removed.
+func (t ScraperTarget) Redacted() ScraperTarget {
+	if t.Auth != nil {
+		auth := *t.Auth
+		auth.Password, auth.Token = "", ""
+		t.Auth = &auth
+	}
+	if t.TLS != nil {
+		cfg := *t.TLS
+		cfg.ClientKey = ""
+		t.TLS = &cfg
+	}
+	return t
+}
+
+// KeepSecrets copies the secrets of prev into t where t leaves them empty.
+// It allows a redacted target to be updated without resending its secrets.
+func (t *ScraperTarget) KeepSecrets(prev *ScraperTarget) {
+	if t.Auth != nil && prev.Auth != nil && t.Auth.Type == prev.Auth.Type {
+		if t.Auth.Password == "" {
+			t.Auth.Password = prev.Auth.Password
+		}
+		if t.Auth.Token == "" {
+			t.Auth.Token = prev.Auth.Token
+		}
+	}
+	if t.TLS != nil && prev.TLS != nil && t.TLS.ClientKey == "" && t.TLS.ClientCert == prev.TLS.ClientCert {
+		t.TLS.ClientKey = prev.TLS.ClientKey
+	}
+}
+
+// Scraper discovery types
+const (
+	ScraperDiscoveryKubernetes = "kubernetes"
+	ScraperDiscoveryFile       = "file"
+)
+
+// Kubernetes discovery roles
+const (
+	KubernetesRolePod       = "pod"
+	KubernetesRoleEndpoints = "endpoints"
+)
+
+// ScraperDiscovery configures dynamic discovery of the endpoints of a scraper target.
+//
+// Kubernetes discovery scrapes the pods, or the endpoints of services, that
+// are annotated with prometheus.io/scrape: "true". The prometheus.io/port,
+// prometheus.io/path and prometheus.io/scheme annotations override the defaults.
+//
+// File discovery reads prometheus file_sd files, in JSON or YAML, which are
+// reloaded when they change. Files are relative to the subdirectory of the
+// server's discovery directory named by the ID of the organization of the
+// target. Only operators may configure kubernetes discovery, since the
+// workloads of the cluster are not restricted by organization.
+type ScraperDiscovery struct {
+	Type string `json:"type"`
+
+	Role          string   `json:"role,omitempty"`
+	Namespaces    []string `json:"namespaces,omitempty"`
+	LabelSelector string   `json:"labelSelector,omitempty"`
+
+	Files []string `json:"files,omitempty"`
+}
+
+// Valid returns an error if the discovery type or its options are invalid.
+func (d *ScraperDiscovery) Valid() error {
+	switch d.Type {
+	case ScraperDiscoveryKubernetes:
+		switch d.Role {
+		case KubernetesRolePod, KubernetesRoleEndpoints:
+		default:
+			return fmt.Errorf("invalid kubernetes role %q, must be %s or %s", d.Role, KubernetesRolePod, KubernetesRoleEndpoints)
+		}
+	case ScraperDiscoveryFile:
+		if len(d.Files) == 0 {
+			return errors.New("file discovery requires at least one file")
+		}
+	default:
+		return fmt.Errorf("invalid discovery type %q, must be %s or %s", d.Type, ScraperDiscoveryKubernetes, ScraperDiscoveryFile)
+	}
+	return nil
+}
+
+// Scraper authentication types
+const (
+	ScraperAuthBasic  = "basic"
+	ScraperAuthBearer = "bearer"
+)
+
+// ScraperAuth is the authentication used when scraping a target.
+type ScraperAuth struct {
+	Type     string `json:"type"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// Valid returns an error if the authentication type is unknown.
+func (a *ScraperAuth) Valid() error {
+	switch a.Type {
+	case ScraperAuthBasic:
+		if a.Username == "" {
+			return errors.New("basic auth requires a username")
+		}
+	case ScraperAuthBearer:
+	default:
+		return fmt.Errorf("invalid auth type %q, must be %s or %s", a.Type, ScraperAuthBasic, ScraperAuthBearer)
+	}
+	return nil
+}
+
+// ScraperTLSConfig configures TLS for a scrape target. Certificates and keys are PEM encoded.
+type ScraperTLSConfig struct {
+	CACert             string `json:"caCert,omitempty"`
+	ClientCert         string `json:"clientCert,omitempty"`
+	ClientKey          string `json:"clientKey,omitempty"`
+	ServerName         string `json:"serverName,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+// Valid returns an error if only one of the client certificate and key is set.
+func (c *ScraperTLSConfig) Valid() error {
+	if (c.ClientCert == "") != (c.ClientKey == "") {
+		return errors.New("tls client certificate and key must be provided together")
+	}
+	if c.ClientCert != "" {
+		if _, err := tls.X509KeyPair([]byte(c.ClientCert), []byte(c.ClientKey)); err != nil {
+			return fmt.Errorf("invalid tls client certificate: %v", err)
+		}
+	}
+	return nil
+}
+
+// RelabelAction is the action taken by a relabel config.
+type RelabelAction string
+
+// Relabel actions, matching the prometheus actions of the same name.
+const (
+	RelabelReplace   RelabelAction = "replace"
+	RelabelKeep      RelabelAction = "keep"
+	RelabelDrop      RelabelAction = "drop"
+	RelabelLabelDrop RelabelAction = "labeldrop"
+	RelabelLabelKeep RelabelAction = "labelkeep"
+)
+
+// ScraperRelabelConfig is a prometheus style relabel rule. The metric name is
+// available as the __name__ label. Empty fields take the prometheus defaults.
+type ScraperRelabelConfig struct {
+	SourceLabels []string      `json:"sourceLabels,omitempty"`
+	Separator    string        `json:"separator,omitempty"`
+	Regex        string        `json:"regex,omitempty"`
+	TargetLabel  string        `json:"targetLabel,omitempty"`
+	Replacement  string        `json:"replacement,omitempty"`
+	Action       RelabelAction `json:"action,omitempty"`
+}
+
+// Valid returns an error if the action is unknown or the regex does not compile.
+func (c ScraperRelabelConfig) Valid() error {
+	switch c.Action {
+	case "", RelabelReplace:
+		if c.TargetLabel == "" {
+			return errors.New("replace requires a target label")
+		}
+	case RelabelKeep, RelabelDrop:
+		if len(c.SourceLabels) == 0 {
+			return fmt.Errorf("%s requires source labels", c.Action)
+		}
+	case RelabelLabelDrop, RelabelLabelKeep:
+	default:
+		return fmt.Errorf("invalid relabel action %q", c.Action)
+	}
+	if _, err := regexp.Compile(c.Regex); err != nil {
+		return fmt.Errorf("invalid regex: %v", err)
+	}
+	return nil
 }
 
 // ScraperTargetStoreService defines the crud service for ScraperTarget.
@@ -3,7 +3,9 @@ package influxdb
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"strings"
+	"time"
 )
 
 // ErrSecretNotFound is the error msg for a missing secret.
@@ -30,6 +32,66 @@ type SecretService interface {
 	DeleteSecret(ctx context.Context, orgID ID, ks ...string) error
 }
 
+// SecretEncryptionKey is the key of a key management service (KMS) that an
+// organization registers to encrypt its secrets, and optionally the archives
+// of its buckets, so that they cannot be read without access to its key.
+type SecretEncryptionKey struct {
+	OrgID ID `json:"orgID"`
+	// KeyID is the name of the key in the KMS.
+	KeyID string `json:"keyID"`
+	// EncryptBackups encrypts the archives of the buckets of the
+	// organization with the key.
+	EncryptBackups bool `json:"encryptBackups"`
+	// RotatedAt is the time the key was last rotated.
+	RotatedAt *time.Time `json:"rotatedAt,omitempty"`
+	CRUDLog
+}
+
+// Valid returns an error if the key is missing required fields.
+func (k *SecretEncryptionKey) Valid() error {
+	if !k.OrgID.Valid() {
+		return errors.New("orgID is required")
+	}
+	if k.KeyID == "" {
+		return errors.New("keyID is required")
+	}
+	return nil
+}
+
+// SecretEncryptionKeyService registers the keys organizations encrypt their
+// secrets with.
+type SecretEncryptionKeyService interface {
+	// FindSecretEncryptionKey returns the key of the organization orgID.
+	FindSecretEncryptionKey(ctx context.Context, orgID ID) (*SecretEncryptionKey, error)
+
+	// PutSecretEncryptionKey registers the key of its organization, and
+	// encrypts the secrets of the organization with it.
+	PutSecretEncryptionKey(ctx context.Context, k *SecretEncryptionKey) error
+
+	// RotateSecretEncryptionKey rotates the key of the organization orgID in
+	// the KMS, and encrypts the secrets of the organization with its new
+	// version.
+	RotateSecretEncryptionKey(ctx context.Context, orgID ID) (*SecretEncryptionKey, error)
+
+	// DeleteSecretEncryptionKey unregisters the key of the organization
+	// orgID, whose secrets are then stored as if it never had one.
+	DeleteSecretEncryptionKey(ctx context.Context, orgID ID) error
+}
+
+// KeyManager encrypts and decrypts data with the keys of a key management
+// service (KMS), which never leave it.
+type KeyManager interface {
+	// Encrypt encrypts plaintext with the latest version of the key keyID.
+	Encrypt(ctx context.Context, keyID string, plaintext []byte) ([]byte, error)
+
+	// Decrypt decrypts ciphertext, encrypted with any version of the key
+	// keyID that was not retired.
+	Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error)
+
+	// RotateKey creates a new version of the key keyID.
+	RotateKey(ctx context.Context, keyID string) error
+}
+
 // SecretField contains a key string, and value pointer.
 type SecretField struct {
 	Key   string  `json:"key"`
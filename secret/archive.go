@@ -0,0 +1,309 @@
+package secret
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+const (
+	// archiveChunkSize is the size of the plaintext of the chunks of
+	// encrypted archives, each sealed on its own so that archives are
+	// encrypted and decrypted as they are streamed.
+	archiveChunkSize = 64 << 10
+
+	// finalChunk flags the length of the last chunk of an archive, so that
+	// truncated archives are detected.
+	finalChunk = 1 << 31
+
+	// maxArchiveHeaderField is the maximum length of the key ID and the
+	// encrypted data key of the header of an archive.
+	maxArchiveHeaderField = 1 << 16
+)
+
+// archiveMagic starts the archives encrypted by the key of their
+// organization. Archives are otherwise tar streams, which cannot start with
+// it.
+var archiveMagic = []byte("influxdb encrypted archive v1\n")
+
+var _ influxdb.BucketArchiveService = (*BucketArchiveService)(nil)
+
+// BucketArchiveService encrypts the archives of the buckets of the
+// organizations whose encryption key encrypts their backups.
+//
+// Each archive is encrypted with AES-256-GCM by a random data key, which is
+// encrypted by the key of the organization and stored in the header of the
+// archive, so that the KMS is only called once per archive.
+type BucketArchiveService struct {
+	s    influxdb.BucketArchiveService
+	keys influxdb.SecretEncryptionKeyService
+	km   influxdb.KeyManager
+}
+
+// NewBucketArchiveService wraps s so that the archives of the organizations
+// of keys whose key encrypts backups are encrypted with km.
+func NewBucketArchiveService(s influxdb.BucketArchiveService, keys influxdb.SecretEncryptionKeyService, km influxdb.KeyManager) *BucketArchiveService {
+	return &BucketArchiveService{
+		s:    s,
+		keys: keys,
+		km:   km,
+	}
+}
+
+// ExportBucket writes an archive of the data of a bucket to w, encrypted if
+// the key of its organization encrypts backups.
+func (s *BucketArchiveService) ExportBucket(ctx context.Context, orgID, bucketID influxdb.ID, w io.Writer) error {
+	k, err := s.keys.FindSecretEncryptionKey(ctx, orgID)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return s.s.ExportBucket(ctx, orgID, bucketID, w)
+	} else if err != nil {
+		return err
+	}
+	if !k.EncryptBackups {
+		return s.s.ExportBucket(ctx, orgID, bucketID, w)
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return err
+	}
+	encryptedKey, err := s.km.Encrypt(ctx, k.KeyID, dataKey)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "failed to encrypt archive key",
+			Err:  err,
+		}
+	}
+	aead, err := newArchiveAEAD(dataKey)
+	if err != nil {
+		return err
+	}
+
+	var hdr bytes.Buffer
+	hdr.Write(archiveMagic)
+	writeHeaderField(&hdr, []byte(k.KeyID))
+	writeHeaderField(&hdr, encryptedKey)
+	if _, err := w.Write(hdr.Bytes()); err != nil {
+		return err
+	}
+
+	ew := &encryptWriter{w: w, aead: aead}
+	if err := s.s.ExportBucket(ctx, orgID, bucketID, ew); err != nil {
+		return err
+	}
+	return ew.Close()
+}
+
+// ImportBucket loads an archive read from r into a bucket. An encrypted
+// archive is only decrypted if it is encrypted by the key of the
+// organization of the bucket.
+func (s *BucketArchiveService) ImportBucket(ctx context.Context, orgID, bucketID influxdb.ID, r io.Reader) error {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(len(archiveMagic)); err != nil || !bytes.Equal(magic, archiveMagic) {
+		return s.s.ImportBucket(ctx, orgID, bucketID, br)
+	}
+	if _, err := br.Discard(len(archiveMagic)); err != nil {
+		return err
+	}
+
+	keyID, err := readHeaderField(br)
+	if err != nil {
+		return invalidArchive(err)
+	}
+	encryptedKey, err := readHeaderField(br)
+	if err != nil {
+		return invalidArchive(err)
+	}
+
+	k, err := s.keys.FindSecretEncryptionKey(ctx, orgID)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	if k == nil || k.KeyID != string(keyID) {
+		return &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  "archive is encrypted by a key the organization does not have",
+		}
+	}
+
+	dataKey, err := s.km.Decrypt(ctx, k.KeyID, encryptedKey)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "failed to decrypt archive key",
+			Err:  err,
+		}
+	}
+	aead, err := newArchiveAEAD(dataKey)
+	if err != nil {
+		return invalidArchive(err)
+	}
+	return s.s.ImportBucket(ctx, orgID, bucketID, &decryptReader{r: br, aead: aead})
+}
+
+func invalidArchive(err error) error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "invalid encrypted archive",
+		Err:  err,
+	}
+}
+
+func newArchiveAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func writeHeaderField(w *bytes.Buffer, b []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(b)))
+	w.Write(n[:])
+	w.Write(b)
+}
+
+func readHeaderField(r io.Reader) ([]byte, error) {
+	var n [4]byte
+	if _, err := io.ReadFull(r, n[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(n[:])
+	if size > maxArchiveHeaderField {
+		return nil, fmt.Errorf("header field of %d bytes is too large", size)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// chunkNonce returns the nonce of the chunk of index i. The final chunk has
+// its own nonce, so that a chunk cannot be moved to the end of an archive
+// to truncate it.
+func chunkNonce(size int, i uint64, final bool) []byte {
+	nonce := make([]byte, size)
+	binary.BigEndian.PutUint64(nonce, i)
+	if final {
+		nonce[size-1] = 1
+	}
+	return nonce
+}
+
+// encryptWriter seals the data written to it in chunks, the last of which
+// is sealed by Close.
+type encryptWriter struct {
+	w     io.Writer
+	aead  cipher.AEAD
+	buf   []byte
+	index uint64
+}
+
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		m := archiveChunkSize - len(w.buf)
+		if m > len(p) {
+			m = len(p)
+		}
+		w.buf = append(w.buf, p[:m]...)
+		p = p[m:]
+		if len(w.buf) == archiveChunkSize {
+			if err := w.seal(false); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close seals the last chunk, which may be empty.
+func (w *encryptWriter) Close() error {
+	return w.seal(true)
+}
+
+func (w *encryptWriter) seal(final bool) error {
+	sealed := w.aead.Seal(nil, chunkNonce(w.aead.NonceSize(), w.index, final), w.buf, nil)
+	w.index++
+	w.buf = w.buf[:0]
+
+	hdr := uint32(len(sealed))
+	if final {
+		hdr |= finalChunk
+	}
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], hdr)
+	if _, err := w.w.Write(n[:]); err != nil {
+		return err
+	}
+	_, err := w.w.Write(sealed)
+	return err
+}
+
+// errTruncatedArchive is returned when an encrypted archive ends before its
+// final chunk.
+var errTruncatedArchive = errors.New("encrypted archive is truncated")
+
+// decryptReader opens the chunks of an encrypted archive.
+type decryptReader struct {
+	r     io.Reader
+	aead  cipher.AEAD
+	buf   []byte
+	index uint64
+	done  bool
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *decryptReader) open() error {
+	var n [4]byte
+	if _, err := io.ReadFull(r.r, n[:]); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errTruncatedArchive
+	} else if err != nil {
+		return err
+	}
+	hdr := binary.BigEndian.Uint32(n[:])
+	final := hdr&finalChunk != 0
+	size := int(hdr &^ finalChunk)
+	if size > archiveChunkSize+r.aead.Overhead() {
+		return fmt.Errorf("encrypted archive chunk of %d bytes is too large", size)
+	}
+
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(r.r, sealed); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errTruncatedArchive
+	} else if err != nil {
+		return err
+	}
+	b, err := r.aead.Open(sealed[:0], chunkNonce(r.aead.NonceSize(), r.index, final), sealed, nil)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt archive: %v", err)
+	}
+	r.index++
+	r.buf, r.done = b, final
+	return nil
+}
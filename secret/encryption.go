@@ -0,0 +1,286 @@
+package secret
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+var _ influxdb.SecretEncryptionKeyService = (*Service)(nil)
+
+var (
+	// ErrEncryptionKeyNotFound is used when an organization has no
+	// encryption key.
+	ErrEncryptionKeyNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "secret encryption key not found",
+	}
+
+	// ErrEncryptionKeyOwned is used when registering a key of the KMS that
+	// is owned by another organization.
+	ErrEncryptionKeyOwned = &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  "secret encryption key is owned by another organization",
+	}
+
+	// ErrSecretsChanged is used when the secrets of an organization, or its
+	// encryption key, changed while they were encrypted.
+	ErrSecretsChanged = &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  "secrets of the organization changed while they were encrypted, try again",
+	}
+
+	// errNoKeyManager is used when secrets are encrypted, but no key
+	// management service is configured.
+	errNoKeyManager = &influxdb.Error{
+		Code: influxdb.EUnavailable,
+		Msg:  "no key management service is configured to encrypt secrets",
+	}
+
+	// errKeyRemoved is used when a secret is encrypted, but its organization
+	// has no encryption key anymore.
+	errKeyRemoved = &influxdb.Error{
+		Code: influxdb.EInternal,
+		Msg:  "secret is encrypted by a key the organization does not have",
+	}
+)
+
+// findEncryptionKey returns the key of the organization orgID, or nil if it
+// has none.
+func (s *Service) findEncryptionKey(ctx context.Context, tx kv.Tx, orgID influxdb.ID) (*influxdb.SecretEncryptionKey, error) {
+	k, err := s.s.GetEncryptionKey(ctx, tx, orgID)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return nil, nil
+	}
+	return k, err
+}
+
+// sameEncryptionKey returns whether the values encrypted by the key a are
+// encrypted by the key b.
+func sameEncryptionKey(a, b *influxdb.SecretEncryptionKey) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.KeyID == b.KeyID
+}
+
+// encrypt returns the value of the secret v, encrypted by key if it is not
+// nil.
+func (s *Service) encrypt(ctx context.Context, key *influxdb.SecretEncryptionKey, v string) (Value, error) {
+	if key == nil {
+		return Value{Plaintext: v}, nil
+	}
+	if s.keys == nil {
+		return Value{}, errNoKeyManager
+	}
+	ciphertext, err := s.keys.Encrypt(ctx, key.KeyID, []byte(v))
+	if err != nil {
+		return Value{}, &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "failed to encrypt secret",
+			Err:  err,
+		}
+	}
+	return Value{Ciphertext: ciphertext}, nil
+}
+
+// decrypt returns the secret of the value v, decrypted by key if it is
+// encrypted.
+func (s *Service) decrypt(ctx context.Context, key *influxdb.SecretEncryptionKey, v Value) (string, error) {
+	if !v.Encrypted() {
+		return v.Plaintext, nil
+	}
+	if key == nil {
+		return "", errKeyRemoved
+	}
+	if s.keys == nil {
+		return "", errNoKeyManager
+	}
+	plaintext, err := s.keys.Decrypt(ctx, key.KeyID, v.Ciphertext)
+	if err != nil {
+		return "", &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "failed to decrypt secret",
+			Err:  err,
+		}
+	}
+	return string(plaintext), nil
+}
+
+// FindSecretEncryptionKey returns the key of the organization orgID.
+func (s *Service) FindSecretEncryptionKey(ctx context.Context, orgID influxdb.ID) (*influxdb.SecretEncryptionKey, error) {
+	var k *influxdb.SecretEncryptionKey
+	err := s.s.View(ctx, func(tx kv.Tx) error {
+		var err error
+		k, err = s.s.GetEncryptionKey(ctx, tx, orgID)
+		return err
+	})
+	return k, err
+}
+
+// PutSecretEncryptionKey registers the key of its organization, and encrypts
+// the secrets of the organization with it. The secrets encrypted by the
+// previous key of the organization are decrypted by it first.
+func (s *Service) PutSecretEncryptionKey(ctx context.Context, k *influxdb.SecretEncryptionKey) error {
+	if err := k.Valid(); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "secret encryption key provided is invalid",
+			Err:  err,
+		}
+	}
+	if s.keys == nil {
+		return errNoKeyManager
+	}
+
+	// The KMS is shared by every organization, so a key is never used by
+	// an organization other than the one that registered it first.
+	var current *influxdb.SecretEncryptionKey
+	err := s.s.View(ctx, func(tx kv.Tx) error {
+		owner, err := s.s.GetEncryptionKeyOwner(ctx, tx, k.KeyID)
+		if err != nil {
+			return err
+		}
+		if owner.Valid() && owner != k.OrgID {
+			return ErrEncryptionKeyOwned
+		}
+		current, err = s.findEncryptionKey(ctx, tx, k.OrgID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// The key is checked before any secret is encrypted by it.
+	if _, err := s.keys.Encrypt(ctx, k.KeyID, []byte(k.OrgID.String())); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to encrypt with the key " + k.KeyID,
+			Err:  err,
+		}
+	}
+
+	now := s.TimeGenerator.Now()
+	k.CreatedAt, k.UpdatedAt = now, now
+	if current != nil {
+		k.CreatedAt = current.CreatedAt
+		if current.KeyID == k.KeyID {
+			k.RotatedAt = current.RotatedAt
+			return s.s.Update(ctx, func(tx kv.Tx) error {
+				return s.s.PutEncryptionKey(ctx, tx, k)
+			})
+		}
+		k.RotatedAt = nil
+	}
+	return s.reencrypt(ctx, k.OrgID, current, k)
+}
+
+// RotateSecretEncryptionKey rotates the key of the organization orgID, and
+// encrypts its secrets with the new version of the key.
+func (s *Service) RotateSecretEncryptionKey(ctx context.Context, orgID influxdb.ID) (*influxdb.SecretEncryptionKey, error) {
+	k, err := s.FindSecretEncryptionKey(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if s.keys == nil {
+		return nil, errNoKeyManager
+	}
+	if err := s.keys.RotateKey(ctx, k.KeyID); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "failed to rotate the key " + k.KeyID,
+			Err:  err,
+		}
+	}
+
+	current := *k
+	now := s.TimeGenerator.Now()
+	k.RotatedAt, k.UpdatedAt = &now, now
+	if err := s.reencrypt(ctx, orgID, &current, k); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// DeleteSecretEncryptionKey unregisters the key of the organization orgID,
+// whose secrets are decrypted and stored as plaintext. The archives of its
+// buckets encrypted by the key cannot be imported anymore.
+func (s *Service) DeleteSecretEncryptionKey(ctx context.Context, orgID influxdb.ID) error {
+	k, err := s.FindSecretEncryptionKey(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	return s.reencrypt(ctx, orgID, k, nil)
+}
+
+// reencrypt decrypts the secrets of the organization orgID by the key from,
+// encrypts them by the key to, or stores them as plaintext if it is nil, and
+// stores to as the key of the organization. The KMS is called outside of
+// transactions, which therefore fail if the secrets or the key changed
+// meanwhile.
+func (s *Service) reencrypt(ctx context.Context, orgID influxdb.ID, from, to *influxdb.SecretEncryptionKey) error {
+	stored := make(map[string]Value)
+	err := s.s.View(ctx, func(tx kv.Tx) error {
+		keys, err := s.s.ListSecret(ctx, tx, orgID)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if stored[k], err = s.s.GetSecret(ctx, tx, orgID, k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]Value, len(stored))
+	for k, v := range stored {
+		secret, err := s.decrypt(ctx, from, v)
+		if err != nil {
+			return err
+		}
+		if values[k], err = s.encrypt(ctx, to, secret); err != nil {
+			return err
+		}
+	}
+
+	return s.s.Update(ctx, func(tx kv.Tx) error {
+		current, err := s.findEncryptionKey(ctx, tx, orgID)
+		if err != nil {
+			return err
+		}
+		if !sameEncryptionKey(from, current) {
+			return ErrSecretsChanged
+		}
+		keys, err := s.s.ListSecret(ctx, tx, orgID)
+		if err != nil {
+			return err
+		}
+		if len(keys) != len(stored) {
+			return ErrSecretsChanged
+		}
+		for _, k := range keys {
+			v, err := s.s.GetSecret(ctx, tx, orgID, k)
+			if err != nil {
+				return err
+			}
+			if prev, ok := stored[k]; !ok || !prev.equal(v) {
+				return ErrSecretsChanged
+			}
+		}
+
+		for k, v := range values {
+			if err := s.s.PutSecret(ctx, tx, orgID, k, v); err != nil {
+				return err
+			}
+		}
+		if to == nil {
+			return s.s.DeleteEncryptionKey(ctx, tx, orgID)
+		}
+		return s.s.PutEncryptionKey(ctx, tx, to)
+	})
+}
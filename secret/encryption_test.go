@@ -0,0 +1,254 @@
+package secret_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/secret"
+	"go.uber.org/zap/zaptest"
+)
+
+// keyManager encrypts by prefixing the plaintext with the name and version
+// of the key.
+type keyManager struct {
+	versions map[string]int
+}
+
+func newKeyManager(keyIDs ...string) *keyManager {
+	km := &keyManager{versions: make(map[string]int)}
+	for _, id := range keyIDs {
+		km.versions[id] = 1
+	}
+	return km
+}
+
+func (km *keyManager) Encrypt(ctx context.Context, keyID string, plaintext []byte) ([]byte, error) {
+	v, ok := km.versions[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found", keyID)
+	}
+	return []byte(fmt.Sprintf("%s:v%d:%s", keyID, v, plaintext)), nil
+}
+
+func (km *keyManager) Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	parts := strings.SplitN(string(ciphertext), ":", 3)
+	if len(parts) != 3 || parts[0] != keyID {
+		return nil, errors.New("ciphertext not encrypted by the key")
+	}
+	return []byte(parts[2]), nil
+}
+
+func (km *keyManager) RotateKey(ctx context.Context, keyID string) error {
+	km.versions[keyID]++
+	return nil
+}
+
+func newEncryptionService(t *testing.T, km influxdb.KeyManager) (*secret.Service, *secret.Storage) {
+	t.Helper()
+
+	s := inmem.NewKVStore()
+	if err := all.Up(context.Background(), zaptest.NewLogger(t), s); err != nil {
+		t.Fatal(err)
+	}
+
+	storage, err := secret.NewStore(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return secret.NewService(storage, secret.WithKeyManager(km)), storage
+}
+
+func storedValue(t *testing.T, storage *secret.Storage, orgID influxdb.ID, k string) secret.Value {
+	t.Helper()
+
+	var v secret.Value
+	err := storage.View(context.Background(), func(tx kv.Tx) error {
+		var err error
+		v, err = storage.GetSecret(context.Background(), tx, orgID, k)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestService_SecretEncryptionKey(t *testing.T) {
+	ctx := context.Background()
+	orgID := influxdb.ID(1)
+	km := newKeyManager("org-key", "other-key")
+	svc, storage := newEncryptionService(t, km)
+
+	if err := svc.PutSecret(ctx, orgID, "token", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindSecretEncryptionKey(ctx, orgID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected no key, got %v", err)
+	}
+
+	// A key the KMS does not have is refused.
+	err := svc.PutSecretEncryptionKey(ctx, &influxdb.SecretEncryptionKey{OrgID: orgID, KeyID: "missing"})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected an invalid key, got %v", err)
+	}
+
+	// The secrets stored before the key are encrypted by it.
+	if err := svc.PutSecretEncryptionKey(ctx, &influxdb.SecretEncryptionKey{OrgID: orgID, KeyID: "org-key"}); err != nil {
+		t.Fatal(err)
+	}
+	if v := storedValue(t, storage, orgID, "token"); string(v.Ciphertext) != "org-key:v1:abc" {
+		t.Fatalf("expected the secret to be encrypted, got %+v", v)
+	}
+
+	if err := svc.PutSecret(ctx, orgID, "password", "xyz"); err != nil {
+		t.Fatal(err)
+	}
+	if v := storedValue(t, storage, orgID, "password"); string(v.Ciphertext) != "org-key:v1:xyz" {
+		t.Fatalf("expected the secret to be encrypted, got %+v", v)
+	}
+
+	k, err := svc.RotateSecretEncryptionKey(ctx, orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.RotatedAt == nil {
+		t.Error("expected the rotation time of the key")
+	}
+	for k, want := range map[string]string{"token": "org-key:v2:abc", "password": "org-key:v2:xyz"} {
+		if v := storedValue(t, storage, orgID, k); string(v.Ciphertext) != want {
+			t.Errorf("expected %s to be encrypted by the new version of the key, got %+v", k, v)
+		}
+	}
+
+	// Changing the key decrypts the secrets with the previous one.
+	if err := svc.PutSecretEncryptionKey(ctx, &influxdb.SecretEncryptionKey{OrgID: orgID, KeyID: "other-key"}); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := svc.LoadSecret(ctx, orgID, "token"); err != nil || v != "abc" {
+		t.Fatalf("expected the secret to be decrypted, got %q, %v", v, err)
+	}
+
+	// The secrets are stored as plaintext once the key is removed.
+	if err := svc.DeleteSecretEncryptionKey(ctx, orgID); err != nil {
+		t.Fatal(err)
+	}
+	if v := storedValue(t, storage, orgID, "password"); v.Encrypted() || v.Plaintext != "xyz" {
+		t.Fatalf("expected the secret to be stored as plaintext, got %+v", v)
+	}
+	if v, err := svc.LoadSecret(ctx, orgID, "password"); err != nil || v != "xyz" {
+		t.Fatalf("expected the secret, got %q, %v", v, err)
+	}
+}
+
+func TestService_SecretEncryptionKeyOwner(t *testing.T) {
+	ctx := context.Background()
+	km := newKeyManager("org-key")
+	svc, _ := newEncryptionService(t, km)
+
+	if err := svc.PutSecretEncryptionKey(ctx, &influxdb.SecretEncryptionKey{OrgID: 1, KeyID: "org-key"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.PutSecretEncryptionKey(ctx, &influxdb.SecretEncryptionKey{OrgID: 2, KeyID: "org-key"}); err != secret.ErrEncryptionKeyOwned {
+		t.Fatalf("expected %v, got %v", secret.ErrEncryptionKeyOwned, err)
+	}
+
+	// The key stays owned by its organization once it is removed.
+	if err := svc.DeleteSecretEncryptionKey(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.PutSecretEncryptionKey(ctx, &influxdb.SecretEncryptionKey{OrgID: 2, KeyID: "org-key"}); err != secret.ErrEncryptionKeyOwned {
+		t.Fatalf("expected %v, got %v", secret.ErrEncryptionKeyOwned, err)
+	}
+	if err := svc.PutSecretEncryptionKey(ctx, &influxdb.SecretEncryptionKey{OrgID: 1, KeyID: "org-key"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// archiveService stores the last archive exported and imported.
+type archiveService struct {
+	exported []byte
+	imported []byte
+}
+
+func (s *archiveService) ExportBucket(ctx context.Context, orgID, bucketID influxdb.ID, w io.Writer) error {
+	_, err := w.Write(s.exported)
+	return err
+}
+
+func (s *archiveService) ImportBucket(ctx context.Context, orgID, bucketID influxdb.ID, r io.Reader) error {
+	b, err := ioutil.ReadAll(r)
+	s.imported = b
+	return err
+}
+
+func TestBucketArchiveService(t *testing.T) {
+	ctx := context.Background()
+	km := newKeyManager("org-key", "other-key")
+	svc, _ := newEncryptionService(t, km)
+
+	// The archive spans several chunks.
+	data := make([]byte, 200<<10)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	archives := &archiveService{exported: data}
+	s := secret.NewBucketArchiveService(archives, svc, km)
+
+	var plain bytes.Buffer
+	if err := s.ExportBucket(ctx, 1, 10, &plain); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(plain.Bytes(), data) {
+		t.Fatal("expected the archive of an organization without a key not to be encrypted")
+	}
+
+	if err := svc.PutSecretEncryptionKey(ctx, &influxdb.SecretEncryptionKey{OrgID: 1, KeyID: "org-key", EncryptBackups: true}); err != nil {
+		t.Fatal(err)
+	}
+	var encrypted bytes.Buffer
+	if err := s.ExportBucket(ctx, 1, 10, &encrypted); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(encrypted.Bytes(), data[:1024]) {
+		t.Fatal("expected the archive to be encrypted")
+	}
+
+	if err := s.ImportBucket(ctx, 1, 11, bytes.NewReader(encrypted.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(archives.imported, data) {
+		t.Fatal("expected the archive to be decrypted")
+	}
+
+	// The archives not encrypted are imported as they are.
+	if err := s.ImportBucket(ctx, 1, 11, bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(archives.imported, data) {
+		t.Fatal("expected the archive to be imported as it is")
+	}
+
+	truncated := encrypted.Bytes()[:encrypted.Len()-100]
+	if err := s.ImportBucket(ctx, 1, 11, bytes.NewReader(truncated)); err == nil {
+		t.Fatal("expected a truncated archive not to be imported")
+	}
+
+	// Another organization cannot decrypt the archive.
+	if err := svc.PutSecretEncryptionKey(ctx, &influxdb.SecretEncryptionKey{OrgID: 2, KeyID: "other-key"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ImportBucket(ctx, 2, 12, bytes.NewReader(encrypted.Bytes())); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("expected the archive to be forbidden, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package secret
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -11,15 +12,44 @@ import (
 )
 
 type handler struct {
-	log *zap.Logger
-	svc influxdb.SecretService
-	api *kithttp.API
+	log    *zap.Logger
+	svc    influxdb.SecretService
+	keySvc influxdb.SecretEncryptionKeyService
+	orphan OrphanedSecretFinder
+	api    *kithttp.API
 
 	idLookupKey string
 }
 
+// HandlerOption configures the handler of the secret service.
+type HandlerOption func(*handler)
+
+// WithEncryptionKeyService serves the encryption keys of the organizations
+// of svc.
+func WithEncryptionKeyService(svc influxdb.SecretEncryptionKeyService) HandlerOption {
+	return func(h *handler) {
+		h.keySvc = svc
+	}
+}
+
+// OrphanedSecretFinder finds the secrets owned by resources that do not
+// exist anymore.
+type OrphanedSecretFinder interface {
+	// OrphanedSecrets returns the orphaned secrets among the secret keys of
+	// the organization orgID.
+	OrphanedSecrets(ctx context.Context, orgID influxdb.ID, keys []string) ([]string, error)
+}
+
+// WithOrphanedSecretFinder reports the orphaned secrets of the
+// organizations, as found by f.
+func WithOrphanedSecretFinder(f OrphanedSecretFinder) HandlerOption {
+	return func(h *handler) {
+		h.orphan = f
+	}
+}
+
 // NewHandler creates a new handler for the secret service
-func NewHandler(log *zap.Logger, idLookupKey string, svc influxdb.SecretService) http.Handler {
+func NewHandler(log *zap.Logger, idLookupKey string, svc influxdb.SecretService, opts ...HandlerOption) http.Handler {
 	h := &handler{
 		log: log,
 		svc: svc,
@@ -27,6 +57,9 @@ func NewHandler(log *zap.Logger, idLookupKey string, svc influxdb.SecretService)
 
 		idLookupKey: idLookupKey,
 	}
+	for _, o := range opts {
+		o(h)
+	}
 
 	r := chi.NewRouter()
 
@@ -34,6 +67,17 @@ func NewHandler(log *zap.Logger, idLookupKey string, svc influxdb.SecretService)
 	r.Patch("/", h.handlePatchSecrets)
 	// TODO: this shouldn't be a post to delete
 	r.Post("/delete", h.handleDeleteSecrets)
+	if h.orphan != nil {
+		r.Get("/orphaned", h.handleGetOrphanedSecrets)
+	}
+	if h.keySvc != nil {
+		r.Route("/encryption-key", func(r chi.Router) {
+			r.Get("/", h.handleGetEncryptionKey)
+			r.Put("/", h.handlePutEncryptionKey)
+			r.Delete("/", h.handleDeleteEncryptionKey)
+			r.Post("/rotate", h.handleRotateEncryptionKey)
+		})
+	}
 	return r
 }
 
@@ -71,6 +115,31 @@ func newSecretsResponse(orgID influxdb.ID, ks []string) *secretsResponse {
 	}
 }
 
+// handleGetOrphanedSecrets is the HTTP handler for the GET /api/v2/orgs/:id/secrets/orphaned route.
+func (h *handler) handleGetOrphanedSecrets(w http.ResponseWriter, r *http.Request) {
+	orgID, err := h.decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	ks, err := h.svc.GetSecretKeys(r.Context(), orgID)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	orphaned, err := h.orphan.OrphanedSecrets(r.Context(), orgID, ks)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	res := newSecretsResponse(orgID, orphaned)
+	res.Links["self"] = fmt.Sprintf("/api/v2/orgs/%s/secrets/orphaned", orgID)
+	h.api.Respond(w, r, http.StatusOK, res)
+}
+
 // handleGetPatchSecrets is the HTTP handler for the PATCH /api/v2/orgs/:id/secrets route.
 func (h *handler) handlePatchSecrets(w http.ResponseWriter, r *http.Request) {
 	orgID, err := h.decodeOrgID(r)
@@ -118,6 +187,104 @@ func (h *handler) handleDeleteSecrets(w http.ResponseWriter, r *http.Request) {
 	h.api.Respond(w, r, http.StatusNoContent, nil)
 }
 
+type encryptionKeyResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.SecretEncryptionKey
+}
+
+func newEncryptionKeyResponse(k *influxdb.SecretEncryptionKey) *encryptionKeyResponse {
+	return &encryptionKeyResponse{
+		Links: map[string]string{
+			"org":     fmt.Sprintf("/api/v2/orgs/%s", k.OrgID),
+			"secrets": fmt.Sprintf("/api/v2/orgs/%s/secrets", k.OrgID),
+			"self":    fmt.Sprintf("/api/v2/orgs/%s/secrets/encryption-key", k.OrgID),
+		},
+		SecretEncryptionKey: k,
+	}
+}
+
+// handleGetEncryptionKey is the HTTP handler for the GET /api/v2/orgs/:id/secrets/encryption-key route.
+func (h *handler) handleGetEncryptionKey(w http.ResponseWriter, r *http.Request) {
+	orgID, err := h.decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	k, err := h.keySvc.FindSecretEncryptionKey(r.Context(), orgID)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, newEncryptionKeyResponse(k))
+}
+
+type encryptionKeyRequest struct {
+	KeyID          string `json:"keyID"`
+	EncryptBackups bool   `json:"encryptBackups"`
+}
+
+// handlePutEncryptionKey is the HTTP handler for the PUT /api/v2/orgs/:id/secrets/encryption-key route.
+func (h *handler) handlePutEncryptionKey(w http.ResponseWriter, r *http.Request) {
+	orgID, err := h.decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var req encryptionKeyRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	k := &influxdb.SecretEncryptionKey{
+		OrgID:          orgID,
+		KeyID:          req.KeyID,
+		EncryptBackups: req.EncryptBackups,
+	}
+	if err := h.keySvc.PutSecretEncryptionKey(r.Context(), k); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, newEncryptionKeyResponse(k))
+}
+
+// handleDeleteEncryptionKey is the HTTP handler for the DELETE /api/v2/orgs/:id/secrets/encryption-key route.
+func (h *handler) handleDeleteEncryptionKey(w http.ResponseWriter, r *http.Request) {
+	orgID, err := h.decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.keySvc.DeleteSecretEncryptionKey(r.Context(), orgID); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
+
+// handleRotateEncryptionKey is the HTTP handler for the POST /api/v2/orgs/:id/secrets/encryption-key/rotate route.
+func (h *handler) handleRotateEncryptionKey(w http.ResponseWriter, r *http.Request) {
+	orgID, err := h.decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	k, err := h.keySvc.RotateSecretEncryptionKey(r.Context(), orgID)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, newEncryptionKeyResponse(k))
+}
+
 func (h *handler) decodeOrgID(r *http.Request) (influxdb.ID, error) {
 	org := chi.URLParam(r, h.idLookupKey)
 	if org == "" {
@@ -98,3 +98,60 @@ func (s *AuthedSvc) DeleteSecret(ctx context.Context, orgID influxdb.ID, keys ..
 	}
 	return nil
 }
+
+var _ influxdb.SecretEncryptionKeyService = (*AuthedEncryptionKeySvc)(nil)
+
+// AuthedEncryptionKeySvc wraps a influxdb.SecretEncryptionKeyService and
+// authorizes actions against it appropriately.
+type AuthedEncryptionKeySvc struct {
+	s influxdb.SecretEncryptionKeyService
+}
+
+// NewAuthedEncryptionKeyService constructs an instance of an authorizing
+// secret encryption key service.
+func NewAuthedEncryptionKeyService(s influxdb.SecretEncryptionKeyService) *AuthedEncryptionKeySvc {
+	return &AuthedEncryptionKeySvc{
+		s: s,
+	}
+}
+
+// FindSecretEncryptionKey checks to see if the authorizer on context has read access to the secrets of orgID.
+func (s *AuthedEncryptionKeySvc) FindSecretEncryptionKey(ctx context.Context, orgID influxdb.ID) (*influxdb.SecretEncryptionKey, error) {
+	if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.SecretsResourceType, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindSecretEncryptionKey(ctx, orgID)
+}
+
+// PutSecretEncryptionKey checks to see if the authorizer on context has read and write access to the secrets of the organization of k.
+func (s *AuthedEncryptionKeySvc) PutSecretEncryptionKey(ctx context.Context, k *influxdb.SecretEncryptionKey) error {
+	if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.SecretsResourceType, k.OrgID); err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.SecretsResourceType, k.OrgID); err != nil {
+		return err
+	}
+	return s.s.PutSecretEncryptionKey(ctx, k)
+}
+
+// RotateSecretEncryptionKey checks to see if the authorizer on context has read and write access to the secrets of orgID.
+func (s *AuthedEncryptionKeySvc) RotateSecretEncryptionKey(ctx context.Context, orgID influxdb.ID) (*influxdb.SecretEncryptionKey, error) {
+	if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.SecretsResourceType, orgID); err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.SecretsResourceType, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.RotateSecretEncryptionKey(ctx, orgID)
+}
+
+// DeleteSecretEncryptionKey checks to see if the authorizer on context has read and write access to the secrets of orgID.
+func (s *AuthedEncryptionKeySvc) DeleteSecretEncryptionKey(ctx context.Context, orgID influxdb.ID) error {
+	if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.SecretsResourceType, orgID); err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.SecretsResourceType, orgID); err != nil {
+		return err
+	}
+	return s.s.DeleteSecretEncryptionKey(ctx, orgID)
+}
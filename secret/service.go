@@ -8,23 +8,53 @@ import (
 )
 
 type Service struct {
-	s *Storage
+	s    *Storage
+	keys influxdb.KeyManager
+
+	TimeGenerator influxdb.TimeGenerator
+}
+
+// ServiceOption configures a Service.
+type ServiceOption func(*Service)
+
+// WithKeyManager encrypts the secrets of the organizations that register a
+// key with the keys of km.
+func WithKeyManager(km influxdb.KeyManager) ServiceOption {
+	return func(s *Service) {
+		s.keys = km
+	}
 }
 
 // NewService creates a new service implementaiton for secrets
-func NewService(s *Storage) *Service {
-	return &Service{s}
+func NewService(s *Storage, opts ...ServiceOption) *Service {
+	svc := &Service{
+		s:             s,
+		TimeGenerator: influxdb.RealTimeGenerator{},
+	}
+	for _, o := range opts {
+		o(svc)
+	}
+	return svc
 }
 
 // LoadSecret retrieves the secret value v found at key k for organization orgID.
 func (s *Service) LoadSecret(ctx context.Context, orgID influxdb.ID, k string) (string, error) {
-	var v string
+	var (
+		v   Value
+		key *influxdb.SecretEncryptionKey
+	)
 	err := s.s.View(ctx, func(tx kv.Tx) error {
 		var err error
-		v, err = s.s.GetSecret(ctx, tx, orgID, k)
+		if v, err = s.s.GetSecret(ctx, tx, orgID, k); err != nil || !v.Encrypted() {
+			return err
+		}
+		key, err = s.findEncryptionKey(ctx, tx, orgID)
 		return err
 	})
-	return v, err
+	if err != nil {
+		return "", err
+	}
+	return s.decrypt(ctx, key, v)
 }
 
 // GetSecretKeys retrieves all secret keys that are stored for the organization orgID.
@@ -40,10 +70,7 @@ func (s *Service) GetSecretKeys(ctx context.Context, orgID influxdb.ID) ([]strin
 
 // PutSecret stores the secret pair (k,v) for the organization orgID.
 func (s *Service) PutSecret(ctx context.Context, orgID influxdb.ID, k, v string) error {
-	err := s.s.Update(ctx, func(tx kv.Tx) error {
-		return s.s.PutSecret(ctx, tx, orgID, k, v)
-	})
-	return err
+	return s.PatchSecrets(ctx, orgID, map[string]string{k: v})
 }
 
 // PutSecrets puts all provided secrets and overwrites any previous values.
@@ -61,9 +88,38 @@ func (s *Service) PutSecrets(ctx context.Context, orgID influxdb.ID, m map[strin
 }
 
 // PatchSecrets patches all provided secrets and updates any previous values.
+// The values are encrypted by the key of the organization, if it has one,
+// before they are stored.
 func (s *Service) PatchSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
-	err := s.s.Update(ctx, func(tx kv.Tx) error {
-		for k, v := range m {
+	var key *influxdb.SecretEncryptionKey
+	err := s.s.View(ctx, func(tx kv.Tx) error {
+		var err error
+		key, err = s.findEncryptionKey(ctx, tx, orgID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]Value, len(m))
+	for k, v := range m {
+		if values[k], err = s.encrypt(ctx, key, v); err != nil {
+			return err
+		}
+	}
+
+	err = s.s.Update(ctx, func(tx kv.Tx) error {
+		// The values would not be encrypted as the secrets of the
+		// organization are, had its key changed since.
+		current, err := s.findEncryptionKey(ctx, tx, orgID)
+		if err != nil {
+			return err
+		}
+		if !sameEncryptionKey(key, current) {
+			return ErrSecretsChanged
+		}
+
+		for k, v := range values {
 			err := s.s.PutSecret(ctx, tx, orgID, k, v)
 			if err != nil {
 				return err
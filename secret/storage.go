@@ -1,15 +1,43 @@
 package secret
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kv"
 )
 
-var secretBucket = []byte("secretsv1")
+var (
+	secretBucket              = []byte("secretsv1")
+	secretEncryptionKeyBucket = []byte("secretencryptionkeysv1")
+	// secretEncryptionKeyOwnerBucket maps the IDs of the keys in the KMS to
+	// the organizations they were first registered by.
+	secretEncryptionKeyOwnerBucket = []byte("secretencryptionkeyownersv1")
+)
+
+// encryptedPrefix is the prefix of the stored values of encrypted secrets,
+// which cannot start the base64 encoding of plaintext values.
+const encryptedPrefix = "kms:"
+
+// Value is the stored value of a secret: its plaintext, or its ciphertext if
+// it is encrypted by the key of its organization.
+type Value struct {
+	Plaintext  string
+	Ciphertext []byte
+}
+
+// Encrypted returns whether the value is encrypted.
+func (v Value) Encrypted() bool {
+	return v.Ciphertext != nil
+}
+
+func (v Value) equal(o Value) bool {
+	return v.Plaintext == o.Plaintext && bytes.Equal(v.Ciphertext, o.Ciphertext) && v.Encrypted() == o.Encrypted()
+}
 
 // Storage is a store translation layer between the data storage unit and the
 // service layer.
@@ -31,32 +59,32 @@ func (s *Storage) Update(ctx context.Context, fn func(kv.Tx) error) error {
 }
 
 // GetSecret Returns the value of a secret
-func (s *Storage) GetSecret(ctx context.Context, tx kv.Tx, orgID influxdb.ID, k string) (string, error) {
+func (s *Storage) GetSecret(ctx context.Context, tx kv.Tx, orgID influxdb.ID, k string) (Value, error) {
 	key, err := encodeSecretKey(orgID, k)
 	if err != nil {
-		return "", err
+		return Value{}, err
 	}
 
 	b, err := tx.Bucket(secretBucket)
 	if err != nil {
-		return "", err
+		return Value{}, err
 	}
 
 	val, err := b.Get(key)
 	if kv.IsNotFound(err) {
-		return "", &influxdb.Error{
+		return Value{}, &influxdb.Error{
 			Code: influxdb.ENotFound,
 			Msg:  influxdb.ErrSecretNotFound,
 		}
 	}
 
 	if err != nil {
-		return "", err
+		return Value{}, err
 	}
 
 	v, err := decodeSecretValue(val)
 	if err != nil {
-		return "", err
+		return Value{}, err
 	}
 
 	return v, nil
@@ -103,7 +131,7 @@ func (s *Storage) ListSecret(ctx context.Context, tx kv.Tx, orgID influxdb.ID) (
 }
 
 // PutSecret sets a secret in the db.
-func (s *Storage) PutSecret(ctx context.Context, tx kv.Tx, orgID influxdb.ID, k, v string) error {
+func (s *Storage) PutSecret(ctx context.Context, tx kv.Tx, orgID influxdb.ID, k string, v Value) error {
 	key, err := encodeSecretKey(orgID, k)
 	if err != nil {
 		return err
@@ -167,18 +195,135 @@ func decodeSecretKey(key []byte) (influxdb.ID, string, error) {
 	return id, k, nil
 }
 
-func decodeSecretValue(val []byte) (string, error) {
+func decodeSecretValue(val []byte) (Value, error) {
+	if bytes.HasPrefix(val, []byte(encryptedPrefix)) {
+		ciphertext, err := base64.StdEncoding.DecodeString(string(val[len(encryptedPrefix):]))
+		if err != nil {
+			return Value{}, err
+		}
+		return Value{Ciphertext: ciphertext}, nil
+	}
+
 	// store the secret value base64 encoded so that it's marginally better than plaintext
 	v, err := base64.StdEncoding.DecodeString(string(val))
 	if err != nil {
-		return "", err
+		return Value{}, err
 	}
 
-	return string(v), nil
+	return Value{Plaintext: string(v)}, nil
 }
 
-func encodeSecretValue(v string) []byte {
-	val := make([]byte, base64.StdEncoding.EncodedLen(len(v)))
-	base64.StdEncoding.Encode(val, []byte(v))
+func encodeSecretValue(v Value) []byte {
+	if v.Encrypted() {
+		return []byte(encryptedPrefix + base64.StdEncoding.EncodeToString(v.Ciphertext))
+	}
+
+	val := make([]byte, base64.StdEncoding.EncodedLen(len(v.Plaintext)))
+	base64.StdEncoding.Encode(val, []byte(v.Plaintext))
 	return val
 }
+
+// GetEncryptionKey returns the key the secrets of the organization orgID are
+// encrypted with.
+func (s *Storage) GetEncryptionKey(ctx context.Context, tx kv.Tx, orgID influxdb.ID) (*influxdb.SecretEncryptionKey, error) {
+	key, err := orgID.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := tx.Bucket(secretEncryptionKeyBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := b.Get(key)
+	if kv.IsNotFound(err) {
+		return nil, ErrEncryptionKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	k := &influxdb.SecretEncryptionKey{}
+	if err := json.Unmarshal(val, k); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// PutEncryptionKey sets the key the secrets of its organization are
+// encrypted with. The first organization to register a key owns it, and no
+// other organization can register it afterwards, even once it is removed.
+func (s *Storage) PutEncryptionKey(ctx context.Context, tx kv.Tx, k *influxdb.SecretEncryptionKey) error {
+	key, err := k.OrgID.Encode()
+	if err != nil {
+		return err
+	}
+
+	owner, err := s.GetEncryptionKeyOwner(ctx, tx, k.KeyID)
+	if err != nil {
+		return err
+	}
+	if owner.Valid() && owner != k.OrgID {
+		return ErrEncryptionKeyOwned
+	}
+	if !owner.Valid() {
+		owners, err := tx.Bucket(secretEncryptionKeyOwnerBucket)
+		if err != nil {
+			return err
+		}
+		if err := owners.Put([]byte(k.KeyID), key); err != nil {
+			return err
+		}
+	}
+
+	val, err := json.Marshal(k)
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(secretEncryptionKeyBucket)
+	if err != nil {
+		return err
+	}
+
+	return b.Put(key, val)
+}
+
+// GetEncryptionKeyOwner returns the organization owning the key keyID of the
+// KMS, or an invalid ID if it was never registered.
+func (s *Storage) GetEncryptionKeyOwner(ctx context.Context, tx kv.Tx, keyID string) (influxdb.ID, error) {
+	b, err := tx.Bucket(secretEncryptionKeyOwnerBucket)
+	if err != nil {
+		return 0, err
+	}
+
+	val, err := b.Get([]byte(keyID))
+	if kv.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var owner influxdb.ID
+	if err := owner.Decode(val); err != nil {
+		return 0, err
+	}
+	return owner, nil
+}
+
+// DeleteEncryptionKey removes the key of the organization orgID.
+func (s *Storage) DeleteEncryptionKey(ctx context.Context, tx kv.Tx, orgID influxdb.ID) error {
+	key, err := orgID.Encode()
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(secretEncryptionKeyBucket)
+	if err != nil {
+		return err
+	}
+
+	return b.Delete(key)
+}
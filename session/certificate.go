@@ -0,0 +1,83 @@
+package session
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// CertificateAuthenticator authenticates the clients of the API by their
+// verified certificates, for the machine integrations of the environments
+// that forbid long-lived tokens. A certificate acts for the user its identity
+// is mapped to, with the same permissions as a session of the user.
+type CertificateAuthenticator struct {
+	sessions *Service
+
+	// users are the names of the users of the identities of certificates.
+	users map[string]string
+}
+
+// NewCertificateAuthenticator returns an authenticator of the certificates
+// whose common name or subject alternative names are keys of users, mapped
+// to the names of their users.
+func NewCertificateAuthenticator(s *Service, users map[string]string) *CertificateAuthenticator {
+	return &CertificateAuthenticator{
+		sessions: s,
+		users:    users,
+	}
+}
+
+// AuthenticateCertificate returns a session of the user of cert. The session
+// is not stored, it is only valid for the request and expires with cert.
+func (a *CertificateAuthenticator) AuthenticateCertificate(ctx context.Context, cert *x509.Certificate) (*influxdb.Session, error) {
+	name, ok := a.user(cert)
+	if !ok {
+		return nil, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "client certificate is not mapped to a user",
+		}
+	}
+
+	u, err := a.sessions.userService.FindUser(ctx, influxdb.UserFilter{
+		Name: &name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := a.sessions.getPermissionSet(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &influxdb.Session{
+		ID:          a.sessions.idGen.ID(),
+		CreatedAt:   time.Now(),
+		ExpiresAt:   cert.NotAfter,
+		UserID:      u.ID,
+		Permissions: permissions,
+	}, nil
+}
+
+// user returns the name of the user of the common name of cert, or else of
+// the first of its subject alternative names mapped to a user.
+func (a *CertificateAuthenticator) user(cert *x509.Certificate) (string, bool) {
+	identities := []string{cert.Subject.CommonName}
+	identities = append(identities, cert.DNSNames...)
+	identities = append(identities, cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		identities = append(identities, u.String())
+	}
+
+	for _, id := range identities {
+		if id == "" {
+			continue
+		}
+		if name, ok := a.users[id]; ok {
+			return name, true
+		}
+	}
+	return "", false
+}
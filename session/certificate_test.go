@@ -0,0 +1,64 @@
+package session
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/tenant"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCertificateAuthenticator(t *testing.T) {
+	ctx := context.Background()
+	kvStore := inmem.NewKVStore()
+	if err := all.Up(ctx, zaptest.NewLogger(t), kvStore); err != nil {
+		t.Fatal(err)
+	}
+	ten := tenant.NewService(tenant.NewStore(kvStore))
+
+	svc := NewService(NewStorage(inmem.NewSessionStore()), ten, ten, &mock.AuthorizationService{
+		FindAuthorizationsFn: func(context.Context, influxdb.AuthorizationFilter, ...influxdb.FindOptions) ([]*influxdb.Authorization, int, error) {
+			return []*influxdb.Authorization{}, 0, nil
+		},
+	})
+
+	u := &influxdb.User{Name: "telegraf"}
+	if err := ten.CreateUser(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewCertificateAuthenticator(svc, map[string]string{
+		"agent.example.com": "telegraf",
+	})
+
+	notAfter := time.Now().Add(time.Hour).Truncate(time.Second)
+	s, err := a.AuthenticateCertificate(ctx, &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "Telegraf Agent"},
+		DNSNames: []string{"agent.example.com"},
+		NotAfter: notAfter,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.UserID != u.ID || !s.ExpiresAt.Equal(notAfter) {
+		t.Errorf("unexpected session %+v", s)
+	}
+	if _, err := s.PermissionSet(); err != nil {
+		t.Errorf("expected the session to be valid: %v", err)
+	}
+
+	_, err = a.AuthenticateCertificate(ctx, &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "unknown.example.com"},
+		NotAfter: notAfter,
+	})
+	if influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+		t.Errorf("expected an unmapped certificate to be unauthorized, got %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+)
+
+// BucketExportIndexFile is the name of the file listing the series of an
+// exported bucket.
+const BucketExportIndexFile = "index"
+
+// importSeriesBatchSize is the number of series added to the index at once
+// when importing a bucket.
+const importSeriesBatchSize = 10000
+
+// BucketExport describes the files of a bucket exported by ExportBucket. The
+// keys of the TSM files and of the index file do not include the organization
+// and bucket, so they can be imported into any bucket.
+type BucketExport struct {
+	Dir    string   // directory holding the files
+	Index  string   // name of the index file
+	Files  []string // names of the TSM files
+	Series int      // number of series in the index file
+}
+
+// ExportBucket writes the data of a bucket to TSM files in a temporary directory
+// and calls fn with them. The files are removed once fn returns.
+func (e *Engine) ExportBucket(ctx context.Context, orgID, bucketID influxdb.ID, fn func(BucketExport) error) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	dir, err := ioutil.TempDir(e.path, "export")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	export, err := e.exportBucket(ctx, orgID, bucketID, dir)
+	if err != nil {
+		return err
+	}
+	return fn(export)
+}
+
+func (e *Engine) exportBucket(ctx context.Context, orgID, bucketID influxdb.ID, dir string) (BucketExport, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return BucketExport{}, ErrEngineClosed
+	}
+
+	// Snapshot the cache to ensure the export includes all data written before now.
+	if err := e.engine.WriteSnapshot(ctx, tsm1.CacheStatusBackup); err != nil {
+		return BucketExport{}, err
+	}
+
+	encoded := tsdb.EncodeName(orgID, bucketID)
+	name := models.EscapeMeasurement(encoded[:])
+	return writeBucketExport(dir, func(fn func(key []byte, typ byte) error) ([]string, error) {
+		return e.engine.ExportPrefix(ctx, name, dir, fn)
+	})
+}
+
+// ExportBackupBucket writes the data of a bucket in the TSM files of a backup
+// at paths to dir, in the format of ExportBucket, so that the bucket of a
+// backup can be imported into a bucket of a running instance with
+// ImportBucket. The paths are sorted by generation, oldest first.
+func ExportBackupBucket(ctx context.Context, paths []string, orgID, bucketID influxdb.ID, dir string) (BucketExport, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	encoded := tsdb.EncodeName(orgID, bucketID)
+	name := models.EscapeMeasurement(encoded[:])
+	return writeBucketExport(dir, func(fn func(key []byte, typ byte) error) ([]string, error) {
+		return tsm1.ExportFilesPrefix(ctx, name, paths, dir, fn)
+	})
+}
+
+// writeBucketExport writes the index file of an export to dir with the keys
+// passed to the callback of export, which writes the TSM files of the export.
+func writeBucketExport(dir string, export func(fn func(key []byte, typ byte) error) ([]string, error)) (BucketExport, error) {
+	f, err := os.Create(filepath.Join(dir, BucketExportIndexFile))
+	if err != nil {
+		return BucketExport{}, err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+
+	be := BucketExport{Dir: dir, Index: BucketExportIndexFile}
+
+	var buf [binary.MaxVarintLen64]byte
+	paths, err := export(func(key []byte, typ byte) error {
+		// Each series is written as the length of its key, the key and its block type.
+		n := binary.PutUvarint(buf[:], uint64(len(key)))
+		if _, err := w.Write(buf[:n]); err != nil {
+			return err
+		} else if _, err := w.Write(key); err != nil {
+			return err
+		}
+		be.Series++
+		return w.WriteByte(typ)
+	})
+	if err != nil {
+		return BucketExport{}, err
+	}
+	if err := w.Flush(); err != nil {
+		return BucketExport{}, err
+	} else if err := f.Close(); err != nil {
+		return BucketExport{}, err
+	}
+
+	for _, p := range paths {
+		be.Files = append(be.Files, filepath.Base(p))
+	}
+	return be, nil
+}
+
+// ImportBucket calls fn with a temporary directory to write the files of an
+// exported bucket to, and imports them into the bucket. The series of the index
+// file are created before any data is loaded, and data already in the bucket
+// is overwritten by imported data with the same timestamps.
+func (e *Engine) ImportBucket(ctx context.Context, orgID, bucketID influxdb.ID, fn func(dir string) error) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	dir, err := ioutil.TempDir(e.path, "import")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := fn(dir); err != nil {
+		return err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	encoded := tsdb.EncodeName(orgID, bucketID)
+	name := models.EscapeMeasurement(encoded[:])
+
+	if err := e.importSeries(name, filepath.Join(dir, BucketExportIndexFile)); err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := e.engine.ImportPrefix(ctx, name, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importSeries adds the series of the index file to the index with the prefix name.
+func (e *Engine) importSeries(name []byte, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+
+	collection := new(tsdb.SeriesCollection)
+	flush := func() error {
+		if collection.Length() == 0 {
+			return nil
+		}
+		defer func() { collection = new(tsdb.SeriesCollection) }()
+
+		if err := e.index.CreateSeriesListIfNotExists(collection); err != nil {
+			return err
+		}
+		// Series conflicting with the types of existing series are dropped.
+		return collection.PartialWriteError()
+	}
+
+	for {
+		n, err := binary.ReadUvarint(r)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("invalid index file: %v", err)
+		}
+		key := make([]byte, len(name)+int(n))
+		copy(key, name)
+		if _, err := io.ReadFull(r, key[len(name):]); err != nil {
+			return fmt.Errorf("invalid index file: %v", err)
+		}
+		typ, err := r.ReadByte()
+		if err != nil {
+			return fmt.Errorf("invalid index file: %v", err)
+		}
+
+		seriesKey, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
+		seriesName, tags := models.ParseKeyBytes(seriesKey)
+		collection.Keys = append(collection.Keys, seriesKey)
+		collection.Names = append(collection.Names, seriesName)
+		collection.Tags = append(collection.Tags, tags)
+		collection.Types = append(collection.Types, blockTypeFieldType[typ&7])
+
+		if collection.Length() == importSeriesBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+var blockTypeFieldType = [8]models.FieldType{
+	tsm1.BlockFloat64:   models.Float,
+	tsm1.BlockInteger:   models.Integer,
+	tsm1.BlockBoolean:   models.Boolean,
+	tsm1.BlockString:    models.String,
+	tsm1.BlockUnsigned:  models.Unsigned,
+	tsm1.BlockUndefined: models.Empty,
+	6:                   models.Empty,
+	7:                   models.Empty,
+}
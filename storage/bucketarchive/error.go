@@ -0,0 +1,25 @@
+package bucketarchive
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// ErrInvalidArchive is used when an imported archive is malformed.
+func ErrInvalidArchive(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "bucket archive is invalid",
+		Err:  err,
+	}
+}
+
+// ErrUnsupportedVersion is used when an imported archive was written by an
+// incompatible version of the archive format.
+func ErrUnsupportedVersion(version int) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("bucket archive version %d is not supported, expected version %d", version, ManifestVersion),
+	}
+}
@@ -0,0 +1,82 @@
+package bucketarchive
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/influxdb/v2"
+	ihttp "github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.BucketArchiveService = (*Client)(nil)
+
+// Client sends and receives bucket archives through the HTTP API of an
+// instance. Archives are streamed, so requests are not retried.
+type Client struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+func archivePath(bucketID influxdb.ID) string {
+	return path.Join("/api/v2/buckets", bucketID.String(), "archive")
+}
+
+// ExportBucket writes the archive of a bucket of the instance to w. The
+// organization is the one of the bucket.
+func (c *Client) ExportBucket(ctx context.Context, orgID, bucketID influxdb.ID, w io.Writer) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	resp, err := c.do(ctx, http.MethodGet, archivePath(bucketID), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// ImportBucket loads the archive read from r into a bucket of the instance.
+// The organization is the one of the bucket.
+func (c *Client) ImportBucket(ctx context.Context, orgID, bucketID influxdb.ID, r io.Reader) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	resp, err := c.do(ctx, http.MethodPost, archivePath(bucketID), r)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (c *Client) do(ctx context.Context, method, urlPath string, body io.Reader) (*http.Response, error) {
+	u, err := ihttp.NewURL(c.Addr, urlPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-tar")
+	}
+	ihttp.SetToken(c.Token, req)
+	req = req.WithContext(ctx)
+
+	resp, err := ihttp.NewClient(u.Scheme, c.InsecureSkipVerify).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := ihttp.CheckError(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,102 @@
+package bucketarchive
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+type handler struct {
+	log *zap.Logger
+	api *kithttp.API
+	svc influxdb.BucketArchiveService
+}
+
+// NewHTTPEmbeddedHandler creates the archive handler mounted beneath
+// /api/v2/buckets/:id/archive. The bucket ID is read from the "id" url
+// parameter and the org ID from the context set by kithttp.ValidResource.
+func NewHTTPEmbeddedHandler(log *zap.Logger, svc influxdb.BucketArchiveService) http.Handler {
+	h := &handler{
+		log: log,
+		api: kithttp.NewAPI(kithttp.WithLog(log)),
+		svc: svc,
+	}
+
+	r := chi.NewRouter()
+	r.Get("/", h.handleGetArchive)
+	r.Post("/", h.handlePostArchive)
+	return r
+}
+
+func decodeIDs(r *http.Request) (orgID, bucketID influxdb.ID, err error) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		return 0, 0, influxdb.ErrCorruptID(err)
+	}
+	org := kithttp.OrgIDFromContext(r.Context())
+	if org == nil {
+		return 0, 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to determine the bucket's organization",
+		}
+	}
+	return *org, *id, nil
+}
+
+// archiveWriter sends the archive headers with the first write, so that errors
+// occurring before the archive is written are sent as regular errors.
+type archiveWriter struct {
+	w        http.ResponseWriter
+	bucketID influxdb.ID
+	written  bool
+}
+
+func (w *archiveWriter) Write(p []byte) (int, error) {
+	if !w.written {
+		w.written = true
+		w.w.Header().Set("Content-Type", "application/x-tar")
+		w.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.tar", w.bucketID))
+		w.w.WriteHeader(http.StatusOK)
+	}
+	return w.w.Write(p)
+}
+
+// handleGetArchive is the HTTP handler for the GET /api/v2/buckets/:id/archive route.
+func (h *handler) handleGetArchive(w http.ResponseWriter, r *http.Request) {
+	orgID, bucketID, err := decodeIDs(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	aw := &archiveWriter{w: w, bucketID: bucketID}
+	if err := h.svc.ExportBucket(r.Context(), orgID, bucketID, aw); err != nil {
+		if !aw.written {
+			h.api.Err(w, r, err)
+			return
+		}
+		// The response has started, so the client only sees a truncated archive.
+		h.log.Error("Failed to write bucket archive", zap.String("bucketID", bucketID.String()), zap.Error(err))
+	}
+}
+
+// handlePostArchive is the HTTP handler for the POST /api/v2/buckets/:id/archive route.
+func (h *handler) handlePostArchive(w http.ResponseWriter, r *http.Request) {
+	orgID, bucketID, err := decodeIDs(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.svc.ImportBucket(r.Context(), orgID, bucketID, r.Body); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.log.Debug("Bucket archive imported", zap.String("bucketID", bucketID.String()))
+	w.WriteHeader(http.StatusNoContent)
+}
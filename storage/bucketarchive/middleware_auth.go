@@ -0,0 +1,36 @@
+package bucketarchive
+
+import (
+	"context"
+	"io"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+var _ influxdb.BucketArchiveService = (*AuthedService)(nil)
+
+// AuthedService requires read access to a bucket to export it and write access
+// to import into it.
+type AuthedService struct {
+	s influxdb.BucketArchiveService
+}
+
+// NewAuthedService wraps s with bucket authorization.
+func NewAuthedService(s influxdb.BucketArchiveService) *AuthedService {
+	return &AuthedService{s: s}
+}
+
+func (s *AuthedService) ExportBucket(ctx context.Context, orgID, bucketID influxdb.ID, w io.Writer) error {
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return err
+	}
+	return s.s.ExportBucket(ctx, orgID, bucketID, w)
+}
+
+func (s *AuthedService) ImportBucket(ctx context.Context, orgID, bucketID influxdb.ID, r io.Reader) error {
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.BucketsResourceType, bucketID, orgID); err != nil {
+		return err
+	}
+	return s.s.ImportBucket(ctx, orgID, bucketID, r)
+}
@@ -0,0 +1,262 @@
+// Package bucketarchive exports the data of a bucket as an archive of TSM files,
+// an index of its series and a manifest, and imports such archives into buckets
+// of other instances. The keys in the archive do not include the organization
+// and bucket IDs, which are assigned by the importing instance. Archives are
+// also written from the files of backups, to restore a bucket of a backup into
+// a running instance.
+package bucketarchive
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+)
+
+const (
+	// ManifestFileName is the name of the manifest, the first file of an archive.
+	ManifestFileName = "manifest.json"
+
+	// ManifestVersion is the version of the archive format.
+	ManifestVersion = 1
+)
+
+// Manifest describes the contents of an archive.
+type Manifest struct {
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"createdAt"`
+	OrgID     influxdb.ID    `json:"orgID"`
+	BucketID  influxdb.ID    `json:"bucketID"`
+	Series    int            `json:"series"`
+	Index     ManifestFile   `json:"index"`
+	Files     []ManifestFile `json:"files"`
+}
+
+// ManifestFile is a file of an archive.
+type ManifestFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// Engine exports the data of buckets to files and imports them.
+type Engine interface {
+	ExportBucket(ctx context.Context, orgID, bucketID influxdb.ID, fn func(storage.BucketExport) error) error
+	ImportBucket(ctx context.Context, orgID, bucketID influxdb.ID, fn func(dir string) error) error
+}
+
+var _ influxdb.BucketArchiveService = (*Service)(nil)
+
+// Service writes and reads bucket archives as tar streams.
+type Service struct {
+	engine Engine
+
+	TimeGenerator influxdb.TimeGenerator
+}
+
+// NewService returns a service archiving the buckets of engine.
+func NewService(engine Engine) *Service {
+	return &Service{
+		engine:        engine,
+		TimeGenerator: influxdb.RealTimeGenerator{},
+	}
+}
+
+// ExportBucket writes an archive of the data of a bucket to w.
+func (s *Service) ExportBucket(ctx context.Context, orgID, bucketID influxdb.ID, w io.Writer) error {
+	return s.engine.ExportBucket(ctx, orgID, bucketID, func(export storage.BucketExport) error {
+		return writeArchive(w, orgID, bucketID, export, s.TimeGenerator.Now().UTC())
+	})
+}
+
+// ExportBackupBucket writes an archive of the data of a bucket of a backup to
+// w. paths are the TSM files of the backup, which keep the IDs of the
+// organization and bucket of the backed-up instance. The archive is imported
+// into a bucket of a running instance, under other IDs, like the archives of
+// ExportBucket.
+func ExportBackupBucket(ctx context.Context, paths []string, orgID, bucketID influxdb.ID, w io.Writer) error {
+	dir, err := ioutil.TempDir("", "restore")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	export, err := storage.ExportBackupBucket(ctx, paths, orgID, bucketID, dir)
+	if err != nil {
+		return err
+	}
+	return writeArchive(w, orgID, bucketID, export, time.Now().UTC())
+}
+
+func writeArchive(w io.Writer, orgID, bucketID influxdb.ID, export storage.BucketExport, createdAt time.Time) error {
+	m := Manifest{
+		Version:   ManifestVersion,
+		CreatedAt: createdAt,
+		OrgID:     orgID,
+		BucketID:  bucketID,
+		Series:    export.Series,
+		Files:     []ManifestFile{},
+	}
+
+	var err error
+	if m.Index, err = manifestFile(export.Dir, export.Index); err != nil {
+		return err
+	}
+	for _, name := range export.Files {
+		f, err := manifestFile(export.Dir, name)
+		if err != nil {
+			return err
+		}
+		m.Files = append(m.Files, f)
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     ManifestFileName,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  m.CreatedAt,
+	}); err != nil {
+		return err
+	} else if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	for _, f := range append([]ManifestFile{m.Index}, m.Files...) {
+		if err := writeFile(tw, export.Dir, f, m.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func manifestFile(dir, name string) (ManifestFile, error) {
+	fi, err := os.Stat(filepath.Join(dir, name))
+	if err != nil {
+		return ManifestFile{}, err
+	}
+	return ManifestFile{Name: name, Size: fi.Size()}, nil
+}
+
+func writeFile(tw *tar.Writer, dir string, f ManifestFile, modTime time.Time) error {
+	fd, err := os.Open(filepath.Join(dir, f.Name))
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     f.Name,
+		Mode:     0644,
+		Size:     f.Size,
+		ModTime:  modTime,
+	}); err != nil {
+		return err
+	}
+	_, err = io.CopyN(tw, fd, f.Size)
+	return err
+}
+
+// ImportBucket loads an archive read from r into a bucket. Data already in the
+// bucket is kept, but overwritten by imported data with the same timestamps.
+func (s *Service) ImportBucket(ctx context.Context, orgID, bucketID influxdb.ID, r io.Reader) error {
+	tr := tar.NewReader(r)
+	m, err := readManifest(tr)
+	if err != nil {
+		return err
+	}
+
+	return s.engine.ImportBucket(ctx, orgID, bucketID, func(dir string) error {
+		return extractFiles(tr, m, dir)
+	})
+}
+
+func readManifest(tr *tar.Reader) (*Manifest, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, ErrInvalidArchive(err)
+	} else if hdr.Name != ManifestFileName {
+		return nil, ErrInvalidArchive(fmt.Errorf("expected %s as the first file, got %q", ManifestFileName, hdr.Name))
+	}
+
+	var m Manifest
+	if err := json.NewDecoder(tr).Decode(&m); err != nil {
+		return nil, ErrInvalidArchive(err)
+	}
+	if m.Version != ManifestVersion {
+		return nil, ErrUnsupportedVersion(m.Version)
+	}
+
+	// The files are written to a directory of the engine, so only plain file
+	// names of the kinds the engine imports are accepted.
+	if m.Index.Name != storage.BucketExportIndexFile {
+		return nil, ErrInvalidArchive(fmt.Errorf("unexpected index file %q", m.Index.Name))
+	}
+	for _, f := range m.Files {
+		if f.Name != filepath.Base(f.Name) || filepath.Ext(f.Name) != "."+tsm1.TSMFileExtension {
+			return nil, ErrInvalidArchive(fmt.Errorf("unexpected data file %q", f.Name))
+		}
+	}
+	return &m, nil
+}
+
+func extractFiles(tr *tar.Reader, m *Manifest, dir string) error {
+	expected := map[string]int64{m.Index.Name: m.Index.Size}
+	for _, f := range m.Files {
+		expected[f.Name] = f.Size
+	}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return ErrInvalidArchive(err)
+		}
+
+		size, ok := expected[hdr.Name]
+		if !ok {
+			return ErrInvalidArchive(fmt.Errorf("file %q is not in the manifest", hdr.Name))
+		} else if hdr.Typeflag != tar.TypeReg || hdr.Size != size {
+			return ErrInvalidArchive(fmt.Errorf("file %q does not match the manifest", hdr.Name))
+		}
+		delete(expected, hdr.Name)
+
+		if err := extractFile(tr, filepath.Join(dir, hdr.Name), size); err != nil {
+			return err
+		}
+	}
+
+	for name := range expected {
+		return ErrInvalidArchive(fmt.Errorf("file %q of the manifest is missing", name))
+	}
+	return nil
+}
+
+func extractFile(r io.Reader, path string, size int64) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.CopyN(f, r, size); err != nil {
+		return ErrInvalidArchive(err)
+	}
+	return f.Close()
+}
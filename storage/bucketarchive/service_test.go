@@ -0,0 +1,193 @@
+package bucketarchive_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/bucketarchive"
+)
+
+// fakeEngine exports files and records the files imported.
+type fakeEngine struct {
+	t        *testing.T
+	files    map[string]string
+	series   int
+	imported map[string]string
+}
+
+func (e *fakeEngine) ExportBucket(ctx context.Context, orgID, bucketID influxdb.ID, fn func(storage.BucketExport) error) error {
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	export := storage.BucketExport{Dir: dir, Index: storage.BucketExportIndexFile, Series: e.series}
+	for name, data := range e.files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0666); err != nil {
+			e.t.Fatal(err)
+		}
+		if name != storage.BucketExportIndexFile {
+			export.Files = append(export.Files, name)
+		}
+	}
+	return fn(export)
+}
+
+func (e *fakeEngine) ImportBucket(ctx context.Context, orgID, bucketID influxdb.ID, fn func(dir string) error) error {
+	dir, err := ioutil.TempDir("", "import")
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := fn(dir); err != nil {
+		return err
+	}
+
+	e.imported = make(map[string]string)
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	for _, fi := range fis {
+		data, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			e.t.Fatal(err)
+		}
+		e.imported[fi.Name()] = string(data)
+	}
+	return nil
+}
+
+func TestService_ExportImport(t *testing.T) {
+	src := &fakeEngine{
+		t:      t,
+		files:  map[string]string{"index": "series", "000000001.tsm": "data"},
+		series: 3,
+	}
+	svc := bucketarchive.NewService(src)
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)}
+
+	var buf bytes.Buffer
+	if err := svc.ExportBucket(context.Background(), 1, 2, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// The manifest is the first file of the archive.
+	tr := tar.NewReader(bytes.NewReader(buf.Bytes()))
+	if hdr, err := tr.Next(); err != nil {
+		t.Fatal(err)
+	} else if hdr.Name != bucketarchive.ManifestFileName {
+		t.Fatalf("expected the manifest first, got %q", hdr.Name)
+	}
+	var m bucketarchive.Manifest
+	if err := json.NewDecoder(tr).Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	exp := bucketarchive.Manifest{
+		Version:   bucketarchive.ManifestVersion,
+		CreatedAt: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC),
+		OrgID:     1,
+		BucketID:  2,
+		Series:    3,
+		Index:     bucketarchive.ManifestFile{Name: "index", Size: 6},
+		Files:     []bucketarchive.ManifestFile{{Name: "000000001.tsm", Size: 4}},
+	}
+	if !reflect.DeepEqual(m, exp) {
+		t.Fatalf("unexpected manifest: got %+v, expected %+v", m, exp)
+	}
+
+	// The archive is imported into a bucket of another organization.
+	dst := &fakeEngine{t: t}
+	if err := bucketarchive.NewService(dst).ImportBucket(context.Background(), 3, 4, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dst.imported, src.files) {
+		t.Fatalf("unexpected imported files: got %v, expected %v", dst.imported, src.files)
+	}
+}
+
+func TestService_ImportInvalid(t *testing.T) {
+	archive := func(m bucketarchive.Manifest, files map[string]string) *bytes.Buffer {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[bucketarchive.ManifestFileName] = string(data)
+		for _, name := range append([]string{bucketarchive.ManifestFileName}, "index", "000000001.tsm", "../000000001.tsm") {
+			data, ok := files[name]
+			if !ok {
+				continue
+			}
+			if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: int64(len(data))}); err != nil {
+				t.Fatal(err)
+			} else if _, err := tw.Write([]byte(data)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return &buf
+	}
+
+	index := bucketarchive.ManifestFile{Name: "index", Size: 6}
+	tests := []struct {
+		name  string
+		m     bucketarchive.Manifest
+		files map[string]string
+	}{
+		{
+			name:  "unsupported version",
+			m:     bucketarchive.Manifest{Version: 2, Index: index},
+			files: map[string]string{"index": "series"},
+		},
+		{
+			name: "file outside of the archive directory",
+			m: bucketarchive.Manifest{
+				Version: bucketarchive.ManifestVersion,
+				Index:   index,
+				Files:   []bucketarchive.ManifestFile{{Name: "../000000001.tsm", Size: 4}},
+			},
+			files: map[string]string{"index": "series", "../000000001.tsm": "data"},
+		},
+		{
+			name: "missing file",
+			m: bucketarchive.Manifest{
+				Version: bucketarchive.ManifestVersion,
+				Index:   index,
+				Files:   []bucketarchive.ManifestFile{{Name: "000000001.tsm", Size: 4}},
+			},
+			files: map[string]string{"index": "series"},
+		},
+		{
+			name:  "file not in the manifest",
+			m:     bucketarchive.Manifest{Version: bucketarchive.ManifestVersion, Index: index},
+			files: map[string]string{"index": "series", "000000001.tsm": "data"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := bucketarchive.NewService(&fakeEngine{t: t})
+			err := svc.ImportBucket(context.Background(), 1, 2, archive(tt.m, tt.files))
+			if influxdb.ErrorCode(err) != influxdb.EInvalid {
+				t.Fatalf("expected invalid archive error, got %v", err)
+			}
+		})
+	}
+}
@@ -24,6 +24,18 @@ type Config struct {
 	// Frequency of retention in seconds.
 	RetentionInterval toml.Duration `toml:"retention-interval"`
 
+	// RetentionDeleteRate paces the deletion of expired data to about this
+	// many bytes freed on disk per second, one bucket at a time, instead of
+	// deleting the data of every bucket in a burst. 0 disables pacing.
+	RetentionDeleteRate toml.Size `toml:"retention-delete-rate"`
+
+	// RetentionWindow restricts the deletion of expired data to a daily
+	// window in UTC, such as "01:00-05:00". The window may wrap midnight.
+	// Checks outside of the window are skipped, and a deletion still running
+	// when the window closes stops after the current bucket. Empty allows
+	// deletion at any time.
+	RetentionWindow string `toml:"retention-window"`
+
 	// Series file config.
 	SeriesFilePath string `toml:"series-file-path"` // Overrides the default path.
 
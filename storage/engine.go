@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/influxdb/v2/logger"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/pkg/limiter"
+	"github.com/influxdata/influxdb/v2/predicate"
 	"github.com/influxdata/influxdb/v2/storage/wal"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	"github.com/influxdata/influxdb/v2/tsdb/cursors"
@@ -64,6 +65,7 @@ type Engine struct {
 	retentionEnforcerLimiter runnable
 
 	defaultMetricLabels prometheus.Labels
+	writeTracker        *writeTracker
 
 	writePointsValidationEnabled bool
 
@@ -114,7 +116,20 @@ func WithNodeID(id int) Option {
 // metrics are labelled correctly.
 func WithRetentionEnforcer(finder BucketFinder) Option {
 	return func(e *Engine) {
-		e.retentionEnforcer = newRetentionEnforcer(e, e.engine, finder)
+		r := newRetentionEnforcer(e, e.engine, finder)
+		r.DeleteRate = int64(e.config.RetentionDeleteRate)
+		r.Window, r.windowErr = parseRetentionWindow(e.config.RetentionWindow)
+		e.retentionEnforcer = r
+	}
+}
+
+// WithLegalHolds exempts data covered by an active legal hold from retention.
+// WithLegalHolds must be called after WithRetentionEnforcer.
+func WithLegalHolds(finder LegalHoldFinder) Option {
+	return func(e *Engine) {
+		if r, ok := e.retentionEnforcer.(*retentionEnforcer); ok {
+			r.HoldFinder = finder
+		}
 	}
 }
 
@@ -183,6 +198,7 @@ func NewEngine(path string, c Config, options ...Option) *Engine {
 		config:              c,
 		path:                path,
 		defaultMetricLabels: prometheus.Labels{},
+		writeTracker:        newWriteTracker(newWriteMetrics(nil), nil),
 		logger:              zap.NewNop(),
 
 		writePointsValidationEnabled: true,
@@ -214,6 +230,7 @@ func NewEngine(path string, c Config, options ...Option) *Engine {
 	e.sfile.SetDefaultMetricLabels(e.defaultMetricLabels)
 	e.index.SetDefaultMetricLabels(e.defaultMetricLabels)
 	e.wal.SetDefaultMetricLabels(e.defaultMetricLabels)
+	e.setWriteMetricLabels(e.defaultMetricLabels)
 	if r, ok := e.retentionEnforcer.(*retentionEnforcer); ok {
 		r.SetDefaultMetricLabels(e.defaultMetricLabels)
 	}
@@ -252,6 +269,7 @@ func (e *Engine) PrometheusCollectors() []prometheus.Collector {
 	metrics = append(metrics, tsm1.PrometheusCollectors()...)
 	metrics = append(metrics, wal.PrometheusCollectors()...)
 	metrics = append(metrics, RetentionPrometheusCollectors()...)
+	metrics = append(metrics, WritePrometheusCollectors()...)
 	return metrics
 }
 
@@ -287,6 +305,9 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 	// TODO(edd) background tasks will be run in priority order via a scheduler.
 	// For now we will just run on an interval as we only have the retention
 	// policy enforcer.
+	if r, ok := e.retentionEnforcer.(*retentionEnforcer); ok {
+		r.closing = e.closing
+	}
 	if e.retentionEnforcer != nil {
 		e.runRetentionEnforcer()
 	}
@@ -504,6 +525,13 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 	}
 
 	for iter := collection.Iterator(); iter.Next(); {
+		// Stop validating large batches whose writer went away.
+		if iter.Index()%writeCancelCheckInterval == 0 {
+			if err := e.checkWriteCanceled(ctx); err != nil {
+				return err
+			}
+		}
+
 		// Skip validation if it has already been performed previously in the call stack.
 		if e.writePointsValidationEnabled {
 			tags := iter.Tags()
@@ -553,6 +581,11 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 	}
 	collection.Truncate(j)
 
+	atomic := AtomicWriteFromContext(ctx)
+	if err := collection.PartialWriteError(); err != nil && atomic {
+		return AtomicWriteError{Err: err}
+	}
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -560,12 +593,24 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 		return ErrEngineClosed
 	}
 
+	// The lock may have been awaited for a while. Once the write is in the
+	// WAL it is applied regardless of the context, as it would be on replay.
+	if err := e.checkWriteCanceled(ctx); err != nil {
+		return err
+	}
+
 	// Convert the collection to values for adding to the WAL/Cache.
 	values, err := tsm1.CollectionToValues(collection)
 	if err != nil {
 		return err
 	}
 
+	if atomic {
+		if err := e.checkAtomicWriteLocked(collection, values); err != nil {
+			return err
+		}
+	}
+
 	// Add the write to the WAL to be replayed if there is a crash or shutdown.
 	if _, err := e.wal.WriteMulti(ctx, values); err != nil {
 		return err
@@ -574,6 +619,38 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 	return e.writePointsLocked(ctx, collection, values)
 }
 
+// checkAtomicWriteLocked returns an AtomicWriteError if any point of the
+// collection would be dropped once in the WAL, by the index or the cache, so
+// that an atomic write never reaches the WAL partially applicable. The series
+// of the points are created, which is harmless if the write is rejected.
+func (e *Engine) checkAtomicWriteLocked(collection *tsdb.SeriesCollection, values map[string][]value.Value) error {
+	if err := e.index.CreateSeriesListIfNotExists(collection); err != nil {
+		return err
+	}
+	if err := collection.PartialWriteError(); err != nil {
+		return AtomicWriteError{Err: err}
+	}
+	if err := e.engine.Cache.CheckMulti(values); err != nil {
+		return AtomicWriteError{Err: err}
+	}
+	return nil
+}
+
+// writeCancelCheckInterval is the number of points validated between checks
+// of the cancellation of a write.
+const writeCancelCheckInterval = 1000
+
+// checkWriteCanceled returns the error of ctx, recording it, if the write was
+// canceled or its deadline was exceeded, so that writes whose client went
+// away or timed out stop consuming the resources of the engine.
+func (e *Engine) checkWriteCanceled(ctx context.Context) error {
+	err := ctx.Err()
+	if err != nil {
+		e.writeTracker.IncCanceled(err)
+	}
+	return err
+}
+
 // writePointsLocked does the work of writing points and must be called under some sort of lock.
 func (e *Engine) writePointsLocked(ctx context.Context, collection *tsdb.SeriesCollection, values map[string][]value.Value) error {
 	span, _ := tracing.StartSpanFromContext(ctx)
@@ -603,7 +680,14 @@ func (e *Engine) writePointsLocked(ctx context.Context, collection *tsdb.SeriesC
 		return err
 	}
 
-	return collection.PartialWriteError()
+	err := collection.PartialWriteError()
+	if id := BatchIDFromContext(ctx); err != nil && id != "" {
+		e.logger.Warn("Dropped points of write batch",
+			zap.String("batch_id", id),
+			zap.Uint64("dropped", collection.Dropped),
+			zap.String("reason", collection.Reason))
+	}
+	return err
 }
 
 // AcquireSegments closes the current WAL segment, gets the set of all the currently closed
@@ -640,6 +724,12 @@ func (e *Engine) CommitSegments(ctx context.Context, segs []string, fn func() er
 	return e.wal.Remove(ctx, segs)
 }
 
+// DiskSizeBytes returns the number of bytes used on disk by the TSM files of
+// the engine.
+func (e *Engine) DiskSizeBytes() int64 {
+	return e.engine.FileStore.DiskSizeBytes()
+}
+
 // DeleteBucket deletes an entire bucket from the storage engine.
 func (e *Engine) DeleteBucket(ctx context.Context, orgID, bucketID influxdb.ID) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
@@ -707,6 +797,45 @@ func (e *Engine) deleteBucketRangeLocked(ctx context.Context, orgID, bucketID in
 	return e.engine.DeletePrefixRange(ctx, name, min, max, pred)
 }
 
+// DeleteMeasurementRange deletes the data of a measurement within a bucket from the
+// storage engine. Any data deleted must be in [min, max]. Only the keys of the
+// measurement are read, which is much cheaper than a delete with a predicate on the
+// measurement.
+func (e *Engine) DeleteMeasurementRange(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, min, max int64) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	// The WAL only records bucket deletes, so the measurement is replayed as a
+	// predicate.
+	pred, err := predicate.New(predicate.TagRuleNode{
+		Tag:      influxdb.Tag{Key: "_measurement", Value: measurement},
+		Operator: influxdb.Equal,
+	})
+	if err != nil {
+		return err
+	}
+	predData, err := pred.Marshal()
+	if err != nil {
+		return err
+	}
+
+	// Add the delete to the WAL to be replayed if there is a crash or shutdown.
+	if _, err := e.wal.DeleteBucketRange(orgID, bucketID, min, max, predData); err != nil {
+		return err
+	}
+
+	encoded := tsdb.EncodeName(orgID, bucketID)
+	name := models.EscapeMeasurement(encoded[:])
+
+	return e.engine.DeleteMeasurementRange(ctx, name, []byte(measurement), min, max)
+}
+
 // CreateBackup creates a "snapshot" of all TSM data in the Engine.
 //   1) Snapshot the cache to ensure the backup includes all data written before now.
 //   2) Create hard links to all TSM files, in a new directory within the engine root directory.
@@ -820,6 +949,18 @@ func (e *Engine) SeriesCardinality() int64 {
 	return e.index.SeriesN()
 }
 
+// QueueDepth returns the number of bytes of written points held in the cache
+// awaiting a snapshot to disk, and the number of bytes the cache holds before
+// rejecting writes.
+func (e *Engine) QueueDepth() (depth, capacity int64) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return 0, 0
+	}
+	return int64(e.engine.Cache.Size()), int64(e.engine.Cache.MaxSize())
+}
+
 // Path returns the path of the engine's base directory.
 func (e *Engine) Path() string {
 	return e.path
@@ -844,3 +985,68 @@ func (e *Engine) MeasurementStats() (tsm1.MeasurementStats, error) {
 	}
 	return e.engine.MeasurementStats()
 }
+
+// IndexCompactionStatus describes the compactions of the index and series file
+// of an engine, by partition.
+type IndexCompactionStatus struct {
+	Index      []tsi1.PartitionCompactionStatus       `json:"index"`
+	SeriesFile []seriesfile.PartitionCompactionStatus `json:"seriesFile"`
+}
+
+// IndexCompactionStatus returns the compaction status of the index and series
+// file of the engine.
+func (e *Engine) IndexCompactionStatus() (IndexCompactionStatus, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return IndexCompactionStatus{}, ErrEngineClosed
+	}
+	return IndexCompactionStatus{
+		Index:      e.index.CompactionStatus(),
+		SeriesFile: e.sfile.CompactionStatus(),
+	}, nil
+}
+
+// setWriteMetricLabels sets the default labels for the write metrics.
+func (e *Engine) setWriteMetricLabels(defaultLabels prometheus.Labels) {
+	mmu.Lock()
+	if wms == nil {
+		wms = newWriteMetrics(defaultLabels)
+	}
+	mmu.Unlock()
+
+	e.writeTracker = newWriteTracker(wms, defaultLabels)
+}
+
+// writeTracker tracks the writes of points to the engine.
+type writeTracker struct {
+	metrics *writeMetrics
+	labels  prometheus.Labels
+}
+
+func newWriteTracker(metrics *writeMetrics, defaultLabels prometheus.Labels) *writeTracker {
+	return &writeTracker{metrics: metrics, labels: defaultLabels}
+}
+
+// Labels returns a copy of labels for use with write metrics.
+func (t *writeTracker) Labels() prometheus.Labels {
+	l := make(map[string]string, len(t.labels))
+	for k, v := range t.labels {
+		l[k] = v
+	}
+	return l
+}
+
+// IncCanceled signals that a write stopped because of err, the error of its
+// context.
+func (t *writeTracker) IncCanceled(err error) {
+	labels := t.Labels()
+
+	if err == context.DeadlineExceeded {
+		labels["reason"] = "deadline_exceeded"
+	} else {
+		labels["reason"] = "canceled"
+	}
+
+	t.metrics.Canceled.With(labels).Inc()
+}
@@ -71,6 +71,49 @@ func TestEngine_WriteAndIndex(t *testing.T) {
 	}
 }
 
+func TestEngine_WriteCanceled(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	pt := models.MustNewPoint(
+		"cpu",
+		models.Tags{
+			{Key: models.MeasurementTagKeyBytes, Value: []byte("cpu")},
+			{Key: []byte("host"), Value: []byte("server")},
+			{Key: models.FieldKeyTagKeyBytes, Value: []byte("value")},
+		},
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1, 2),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got, exp := engine.Engine.WritePoints(ctx, []models.Point{pt}), context.Canceled; got != exp {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+
+	if got, exp := engine.SeriesCardinality(), int64(0); got != exp {
+		t.Fatalf("got %v series, exp %v series in index", got, exp)
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(engine.PrometheusCollectors()...)
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	canceled := promtest.MustFindMetric(t, mfs, "storage_writes_canceled_total", prometheus.Labels{
+		"node_id":   fmt.Sprint(engine.nodeID),
+		"engine_id": fmt.Sprint(engine.engineID),
+		"reason":    "canceled",
+	})
+	if m, got, exp := canceled, canceled.GetCounter().GetValue(), 1.0; got < exp {
+		t.Errorf("[%s] got %v, expected at least %v", m, got, exp)
+	}
+}
+
 func TestEngine_TimeTag(t *testing.T) {
 	engine := NewDefaultEngine()
 	defer engine.Close()
@@ -490,6 +533,50 @@ func TestEngine_WriteConflictingBatch(t *testing.T) {
 	}
 }
 
+func TestEngine_WriteAtomic(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+	other := tsdb.EncodeNameString(engine.org, engine.bucket+1)
+	points := []models.Point{
+		models.MustNewPoint(
+			other,
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "mem", "host": "server"}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		),
+		models.MustNewPoint(
+			name,
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		),
+		models.MustNewPoint(
+			name,
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
+			map[string]interface{}{"value": 2},
+			time.Unix(1, 2),
+		),
+	}
+
+	err := engine.Engine.WritePoints(storage.WithAtomicWrite(context.Background()), points)
+	if _, ok := err.(storage.AtomicWriteError); !ok {
+		t.Fatal("expected atomic write error. got:", err)
+	}
+	if depth, _ := engine.QueueDepth(); depth != 0 {
+		t.Fatalf("expected no points written, got %d bytes in the cache", depth)
+	}
+
+	if err := engine.Engine.WritePoints(storage.WithAtomicWrite(context.Background()), points[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if depth, _ := engine.QueueDepth(); depth == 0 {
+		t.Fatal("expected points written to both buckets")
+	}
+}
+
 // BenchmarkWritePoints_100K demonstrates the impact that batch size has on
 // writing a fixed number of points into storage. In this case 100K points are
 // written according to varying batch sizes.
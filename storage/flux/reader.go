@@ -158,6 +158,10 @@ func (fi *filterIterator) Do(f func(flux.Table) error) error {
 	req.Predicate = fi.spec.Predicate
 	req.Range.Start = int64(fi.spec.Bounds.Start)
 	req.Range.End = int64(fi.spec.Bounds.Stop)
+	if tr := fi.spec.TimeRange; tr != nil {
+		req.Range.Start = int64(tr.Start)
+		req.Range.End = int64(tr.Stop)
+	}
 
 	rs, err := fi.s.ReadFilter(fi.ctx, &req)
 	if err != nil {
@@ -0,0 +1,89 @@
+// Package indexstatus serves the compaction status of the index and series
+// file of the storage engine, for operators to correlate query latency with
+// index maintenance.
+package indexstatus
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixIndexStatus is the prefix of the index status API.
+	PrefixIndexStatus = "/api/v2/storage/index"
+)
+
+// StatusReader reads the compaction status of the index and series file.
+type StatusReader interface {
+	IndexCompactionStatus() (storage.IndexCompactionStatus, error)
+}
+
+// Handler is the HTTP API handler of the index status. Only operators can
+// use it.
+type Handler struct {
+	chi.Router
+	api    *kithttp.API
+	log    *zap.Logger
+	reader StatusReader
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, reader StatusReader) *Handler {
+	h := &Handler{
+		api:    kithttp.NewAPI(kithttp.WithLog(log)),
+		log:    log,
+		reader: reader,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+		h.requireOperator,
+	)
+
+	r.Get("/compactions", h.handleGetCompactions)
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixIndexStatus
+}
+
+func (h *Handler) requireOperator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := authorizer.IsAllowedAll(r.Context(), influxdb.OperPermissions()); err != nil {
+			h.api.Err(w, r, &influxdb.Error{
+				Code: influxdb.EForbidden,
+				Msg:  "only operators can read the index status",
+				Err:  err,
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *Handler) handleGetCompactions(w http.ResponseWriter, r *http.Request) {
+	status, err := h.reader.IndexCompactionStatus()
+	if err != nil {
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "unable to read the index status",
+			Err:  err,
+		})
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, status)
+}
@@ -0,0 +1,98 @@
+package indexstatus_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/indexstatus"
+	"github.com/influxdata/influxdb/v2/tsdb/seriesfile"
+	"github.com/influxdata/influxdb/v2/tsdb/tsi1"
+	"go.uber.org/zap/zaptest"
+)
+
+type statusReader struct {
+	status storage.IndexCompactionStatus
+	err    error
+}
+
+func (r statusReader) IndexCompactionStatus() (storage.IndexCompactionStatus, error) {
+	return r.status, r.err
+}
+
+func TestHandler_handleGetCompactions(t *testing.T) {
+	reader := statusReader{
+		status: storage.IndexCompactionStatus{
+			Index: []tsi1.PartitionCompactionStatus{{
+				ID:                 "0",
+				ActiveCompactions:  1,
+				PendingCompactions: 1,
+				Levels: []tsi1.CompactionLevelStatus{
+					{Level: 0, Files: 1, Size: 10},
+					{Level: 1, Files: 3, Size: 30, Compacting: true, Pending: true},
+				},
+			}},
+			SeriesFile: []seriesfile.PartitionCompactionStatus{{ID: 0, InMemSeries: 5, CompactThreshold: 10}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		reader statusReader
+		auth   *influxdb.Authorization
+		code   int
+	}{
+		{
+			name:   "operators read the status",
+			reader: reader,
+			auth:   &influxdb.Authorization{Status: influxdb.Active, Permissions: influxdb.OperPermissions()},
+			code:   http.StatusOK,
+		},
+		{
+			name:   "other users are forbidden",
+			reader: reader,
+			auth:   &influxdb.Authorization{Status: influxdb.Active},
+			code:   http.StatusForbidden,
+		},
+		{
+			name:   "closed engines are unavailable",
+			reader: statusReader{err: errors.New("engine is closed")},
+			auth:   &influxdb.Authorization{Status: influxdb.Active, Permissions: influxdb.OperPermissions()},
+			code:   http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := indexstatus.NewHTTPHandler(zaptest.NewLogger(t), tt.reader)
+
+			r := httptest.NewRequest(http.MethodGet, "/compactions", nil)
+			r = r.WithContext(icontext.SetAuthorizer(r.Context(), tt.auth))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Fatalf("got status %d, expected %d: %s", w.Code, tt.code, w.Body.String())
+			}
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var got storage.IndexCompactionStatus
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Index) != 1 || got.Index[0].PendingCompactions != 1 || len(got.Index[0].Levels) != 2 || !got.Index[0].Levels[1].Compacting {
+				t.Errorf("unexpected index status %+v", got.Index)
+			}
+			if len(got.SeriesFile) != 1 || got.SeriesFile[0].InMemSeries != 5 {
+				t.Errorf("unexpected series file status %+v", got.SeriesFile)
+			}
+		})
+	}
+}
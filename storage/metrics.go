@@ -12,6 +12,7 @@ import (
 // monitored within the same process.
 var (
 	rms *retentionMetrics
+	wms *writeMetrics
 	mmu sync.RWMutex
 )
 
@@ -27,10 +28,23 @@ func RetentionPrometheusCollectors() []prometheus.Collector {
 	return collectors
 }
 
+// WritePrometheusCollectors returns all prometheus metrics for writes.
+func WritePrometheusCollectors() []prometheus.Collector {
+	mmu.RLock()
+	defer mmu.RUnlock()
+
+	var collectors []prometheus.Collector
+	if wms != nil {
+		collectors = append(collectors, wms.PrometheusCollectors()...)
+	}
+	return collectors
+}
+
 // namespace is the leading part of all published metrics for the Storage service.
 const namespace = "storage"
 
 const retentionSubsystem = "retention" // sub-system associated with metrics for writing points.
+const writeSubsystem = "writes"        // sub-system associated with metrics for writing points.
 
 // retentionMetrics is a set of metrics concerned with tracking data about retention policies.
 type retentionMetrics struct {
@@ -88,3 +102,36 @@ func (rm *retentionMetrics) PrometheusCollectors() []prometheus.Collector {
 		rm.CheckDuration,
 	}
 }
+
+// writeMetrics is a set of metrics concerned with tracking the writes of
+// points to the engine.
+type writeMetrics struct {
+	labels   prometheus.Labels
+	Canceled *prometheus.CounterVec
+}
+
+func newWriteMetrics(labels prometheus.Labels) *writeMetrics {
+	var names []string
+	for k := range labels {
+		names = append(names, k)
+	}
+	names = append(names, "reason")
+	sort.Strings(names)
+
+	return &writeMetrics{
+		labels: labels,
+		Canceled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: writeSubsystem,
+			Name:      "canceled_total",
+			Help:      "Number of writes stopped because their request was canceled or timed out.",
+		}, names),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *writeMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.Canceled,
+	}
+}
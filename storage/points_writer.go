@@ -15,6 +15,69 @@ type PointsWriter interface {
 	WritePoints(context.Context, []models.Point) error
 }
 
+type atomicWriteContext struct{}
+
+// WithAtomicWrite returns a context writing points atomically: either all of
+// the points of a write, whatever their bucket, are written, or none of them
+// are and an AtomicWriteError is returned.
+func WithAtomicWrite(ctx context.Context) context.Context {
+	return context.WithValue(ctx, atomicWriteContext{}, true)
+}
+
+// AtomicWriteFromContext returns true if the points written with ctx must be
+// written atomically.
+func AtomicWriteFromContext(ctx context.Context) bool {
+	atomic, _ := ctx.Value(atomicWriteContext{}).(bool)
+	return atomic
+}
+
+type batchIDContext struct{}
+
+// WithBatchID returns a context writing the points of the batch with the ID
+// provided by the client, so that the batch can be traced through the logs
+// of the write path.
+func WithBatchID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, batchIDContext{}, id)
+}
+
+// BatchIDFromContext returns the ID of the batch of the points written with
+// ctx, or "" if the client did not provide one.
+func BatchIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(batchIDContext{}).(string)
+	return id
+}
+
+type mirrorWriteTimeContext struct{}
+
+// WithMirrorWriteTime returns a context writing points mirrored from another
+// instance, where they were written at t. The time resolves the conflicts
+// with the points written locally at the same timestamps.
+func WithMirrorWriteTime(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, mirrorWriteTimeContext{}, t)
+}
+
+// MirrorWriteTimeFromContext returns the time the points written with ctx
+// were written at by the instance mirroring them, and false if they were not
+// mirrored.
+func MirrorWriteTimeFromContext(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(mirrorWriteTimeContext{}).(time.Time)
+	return t, ok
+}
+
+// AtomicWriteError is the error of an atomic write rejected because some of
+// its points could not be written. None of its points were written.
+type AtomicWriteError struct {
+	Err error
+}
+
+func (e AtomicWriteError) Error() string {
+	return fmt.Sprintf("atomic write rejected, no points were written: %v", e.Err)
+}
+
+func (e AtomicWriteError) Unwrap() error {
+	return e.Err
+}
+
 // LoggingPointsWriter wraps an underlying points writer but writes logs to
 // another bucket when an error occurs.
 type LoggingPointsWriter struct {
@@ -40,6 +103,12 @@ func (w *LoggingPointsWriter) WritePoints(ctx context.Context, p []models.Point)
 		return nil
 	}
 
+	// Writes canceled by their caller are not errors of the points, and the
+	// log write would be canceled as well.
+	if ctx.Err() != nil {
+		return err
+	}
+
 	// Find organizationID from points
 	orgID, _ := tsdb.DecodeNameSlice(p[0].Name())
 
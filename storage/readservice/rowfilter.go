@@ -0,0 +1,154 @@
+package readservice
+
+import (
+	"context"
+	"errors"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+type rowFilterStore struct {
+	reads.Store
+}
+
+// NewRowFilterStore returns a store restricting the series read from s to
+// the ones matching the row filters of the authorization of the context of
+// each read, so that the tokens of a tenant only read its rows of a bucket
+// shared by several tenants.
+func NewRowFilterStore(s reads.Store) reads.Store {
+	return &rowFilterStore{Store: s}
+}
+
+func (s *rowFilterStore) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
+	r := *req
+	r.Predicate = rowFilterPredicate(ctx, req.Predicate)
+	return s.Store.ReadFilter(ctx, &r)
+}
+
+func (s *rowFilterStore) ReadGroup(ctx context.Context, req *datatypes.ReadGroupRequest) (reads.GroupResultSet, error) {
+	r := *req
+	r.Predicate = rowFilterPredicate(ctx, req.Predicate)
+	return s.Store.ReadGroup(ctx, &r)
+}
+
+func (s *rowFilterStore) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cursors.StringIterator, error) {
+	r := *req
+	r.Predicate = rowFilterPredicate(ctx, req.Predicate)
+	return s.Store.TagKeys(ctx, &r)
+}
+
+func (s *rowFilterStore) TagValues(ctx context.Context, req *datatypes.TagValuesRequest) (cursors.StringIterator, error) {
+	r := *req
+	r.Predicate = rowFilterPredicate(ctx, req.Predicate)
+	return s.Store.TagValues(ctx, &r)
+}
+
+func (s *rowFilterStore) GetGroupCapability(ctx context.Context) reads.GroupCapability {
+	if gs, ok := s.Store.(reads.GroupStore); ok {
+		return gs.GetGroupCapability(ctx)
+	}
+	return nil
+}
+
+func (s *rowFilterStore) GetWindowAggregateCapability(ctx context.Context) reads.WindowAggregateCapability {
+	if ws, ok := s.Store.(reads.WindowAggregateStore); ok {
+		return ws.GetWindowAggregateCapability(ctx)
+	}
+	return nil
+}
+
+func (s *rowFilterStore) WindowAggregate(ctx context.Context, req *datatypes.ReadWindowAggregateRequest) (reads.ResultSet, error) {
+	ws, ok := s.Store.(reads.WindowAggregateStore)
+	if !ok {
+		return nil, errors.New("window aggregate not supported")
+	}
+	r := *req
+	r.Predicate = rowFilterPredicate(ctx, req.Predicate)
+	return ws.WindowAggregate(ctx, &r)
+}
+
+// rowFilterPredicate returns the predicate p restricted to the row filters of
+// the authorization of ctx. p is returned as is if there are none.
+func rowFilterPredicate(ctx context.Context, p *datatypes.Predicate) *datatypes.Predicate {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return p
+	}
+	auth, ok := a.(*influxdb.Authorization)
+	if !ok || len(auth.RowFilters) == 0 {
+		return p
+	}
+
+	// Nest the filters backwards, as in a AND (b AND c), starting from the
+	// predicate of the read.
+	var root *datatypes.Node
+	if p.GetRoot() != nil {
+		root = &datatypes.Node{
+			NodeType: datatypes.NodeTypeParenExpression,
+			Children: []*datatypes.Node{p.Root},
+		}
+	}
+	for i := len(auth.RowFilters) - 1; i >= 0; i-- {
+		n := tagRuleNode(auth.RowFilters[i])
+		if root == nil {
+			root = n
+			continue
+		}
+		root = &datatypes.Node{
+			NodeType: datatypes.NodeTypeLogicalExpression,
+			Value:    &datatypes.Node_Logical_{Logical: datatypes.LogicalAnd},
+			Children: []*datatypes.Node{n, root},
+		}
+	}
+	return &datatypes.Predicate{Root: root}
+}
+
+// tagRuleNode returns the comparison of the tag rule r. Rules on the
+// _measurement and _field columns compare the keys the storage engine keeps
+// them under.
+func tagRuleNode(r influxdb.TagRule) *datatypes.Node {
+	key := r.Key
+	switch key {
+	case "_measurement":
+		key = models.MeasurementTagKey
+	case "_field":
+		key = models.FieldKeyTagKey
+	}
+
+	var (
+		comparison datatypes.Node_Comparison
+		literal    = &datatypes.Node{
+			NodeType: datatypes.NodeTypeLiteral,
+			Value:    &datatypes.Node_StringValue{StringValue: r.Value},
+		}
+	)
+	switch r.Operator {
+	case influxdb.Equal:
+		comparison = datatypes.ComparisonEqual
+	case influxdb.NotEqual:
+		comparison = datatypes.ComparisonNotEqual
+	case influxdb.RegexEqual:
+		comparison = datatypes.ComparisonRegex
+		literal.Value = &datatypes.Node_RegexValue{RegexValue: r.Value}
+	case influxdb.NotRegexEqual:
+		comparison = datatypes.ComparisonNotRegex
+		literal.Value = &datatypes.Node_RegexValue{RegexValue: r.Value}
+	}
+
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeComparisonExpression,
+		Value:    &datatypes.Node_Comparison_{Comparison: comparison},
+		Children: []*datatypes.Node{
+			{
+				NodeType: datatypes.NodeTypeTagRef,
+				Value:    &datatypes.Node_TagRefValue{TagRefValue: key},
+			},
+			literal,
+		},
+	}
+}
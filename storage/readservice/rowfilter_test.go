@@ -0,0 +1,117 @@
+package readservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+// predicateStore records the predicates of the reads.
+type predicateStore struct {
+	reads.Store
+	predicates []string
+}
+
+func (s *predicateStore) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
+	s.predicates = append(s.predicates, reads.PredicateToExprString(req.Predicate))
+	return nil, nil
+}
+
+func (s *predicateStore) TagValues(ctx context.Context, req *datatypes.TagValuesRequest) (cursors.StringIterator, error) {
+	s.predicates = append(s.predicates, reads.PredicateToExprString(req.Predicate))
+	return nil, nil
+}
+
+func TestRowFilterStore(t *testing.T) {
+	measurement := &datatypes.Predicate{
+		Root: &datatypes.Node{
+			NodeType: datatypes.NodeTypeComparisonExpression,
+			Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonEqual},
+			Children: []*datatypes.Node{
+				{NodeType: datatypes.NodeTypeTagRef, Value: &datatypes.Node_TagRefValue{TagRefValue: "_m"}},
+				{NodeType: datatypes.NodeTypeLiteral, Value: &datatypes.Node_StringValue{StringValue: "cpu"}},
+			},
+		},
+	}
+	auth := &influxdb.Authorization{
+		RowFilters: []influxdb.TagRule{
+			{Tag: influxdb.Tag{Key: "tenant", Value: "acme"}, Operator: influxdb.Equal},
+			{Tag: influxdb.Tag{Key: "region", Value: "^eu-"}, Operator: influxdb.RegexEqual},
+		},
+	}
+
+	for _, tt := range []struct {
+		name       string
+		authorizer influxdb.Authorizer
+		predicate  *datatypes.Predicate
+		want       string
+	}{
+		{
+			name:      "no authorizer",
+			predicate: measurement,
+			want:      `'_m' = "cpu"`,
+		},
+		{
+			name:       "no row filters",
+			authorizer: &influxdb.Authorization{},
+			predicate:  measurement,
+			want:       `'_m' = "cpu"`,
+		},
+		{
+			name:       "session",
+			authorizer: &influxdb.Session{},
+			want:       "[none]",
+		},
+		{
+			name:       "row filters",
+			authorizer: auth,
+			predicate:  measurement,
+			want:       `'tenant' = "acme" AND 'region' =~ /^eu-/ AND ( '_m' = "cpu" )`,
+		},
+		{
+			name:       "row filters without predicate",
+			authorizer: auth,
+			want:       `'tenant' = "acme" AND 'region' =~ /^eu-/`,
+		},
+		{
+			name: "row filters on measurement and field",
+			authorizer: &influxdb.Authorization{
+				RowFilters: []influxdb.TagRule{
+					{Tag: influxdb.Tag{Key: "_measurement", Value: "cpu"}, Operator: influxdb.Equal},
+					{Tag: influxdb.Tag{Key: "_field", Value: "secret"}, Operator: influxdb.NotEqual},
+				},
+			},
+			want: "'\x00' = \"cpu\" AND '\xff' != \"secret\"",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorizer != nil {
+				ctx = icontext.SetAuthorizer(ctx, tt.authorizer)
+			}
+			store := &predicateStore{}
+			s := NewRowFilterStore(store)
+
+			req := &datatypes.ReadFilterRequest{Predicate: tt.predicate}
+			if _, err := s.ReadFilter(ctx, req); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.TagValues(ctx, &datatypes.TagValuesRequest{Predicate: tt.predicate}); err != nil {
+				t.Fatal(err)
+			}
+			for _, got := range store.predicates {
+				if got != tt.want {
+					t.Errorf("unexpected predicate %s, want %s", got, tt.want)
+				}
+			}
+			if req.Predicate != tt.predicate {
+				t.Error("expected the request not to be modified")
+			}
+		})
+	}
+}
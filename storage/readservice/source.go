@@ -32,3 +32,22 @@ func (r *readSource) GetOrgID() influxdb.ID {
 func (r *readSource) GetBucketID() influxdb.ID {
 	return influxdb.ID(r.BucketID)
 }
+
+// NewReadSource returns the read source of the requests of the bucket
+// bucketID of the organization orgID.
+func NewReadSource(orgID, bucketID influxdb.ID) (*types.Any, error) {
+	return types.MarshalAny(&readSource{
+		BucketID:       uint64(bucketID),
+		OrganizationID: uint64(orgID),
+	})
+}
+
+// ReadSourceIDs returns the organization and bucket of the read source of
+// a request.
+func ReadSourceIDs(any types.Any) (orgID, bucketID influxdb.ID, err error) {
+	source, err := getReadSource(any)
+	if err != nil {
+		return 0, 0, err
+	}
+	return source.GetOrgID(), source.GetBucketID(), nil
+}
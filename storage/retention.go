@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"math"
+	"sort"
 	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/logger"
+	"github.com/influxdata/influxdb/v2/predicate"
 	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -34,6 +36,18 @@ type BucketFinder interface {
 	FindBuckets(context.Context, influxdb.BucketFilter, ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error)
 }
 
+// A LegalHoldFinder provides access to legal holds via a filter. Data covered
+// by an active hold is exempt from retention enforcement.
+type LegalHoldFinder interface {
+	FindLegalHolds(context.Context, influxdb.LegalHoldFilter, ...influxdb.FindOptions) ([]*influxdb.LegalHold, int, error)
+}
+
+// A PredicateDeleter implementation is capable of deleting data matching a
+// predicate from a storage engine.
+type PredicateDeleter interface {
+	DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error
+}
+
 // ErrServiceClosed is returned when the service is unavailable.
 var ErrServiceClosed = errors.New("service is currently closed")
 
@@ -49,6 +63,22 @@ type retentionEnforcer struct {
 	// organisations.
 	BucketService BucketFinder
 
+	// HoldFinder provides the legal holds exempting data from retention. It
+	// is optional; without it all expired data is deleted.
+	HoldFinder LegalHoldFinder
+
+	// DeleteRate is the number of bytes per second the deletions are paced
+	// to. 0 disables pacing.
+	DeleteRate int64
+
+	// Window is the daily window in which data is deleted. nil allows
+	// deletion at any time.
+	Window    *retentionWindow
+	windowErr error
+
+	// closing is closed when the engine closes, interrupting pacing.
+	closing <-chan struct{}
+
 	logger *zap.Logger
 
 	tracker *retentionTracker
@@ -104,6 +134,13 @@ func (s *retentionEnforcer) run() {
 	defer logEnd()
 
 	now := time.Now().UTC()
+	if s.windowErr != nil {
+		log.Error("Invalid retention window, deleting expired data at any time", zap.Error(s.windowErr))
+	} else if !s.Window.contains(now) {
+		log.Info("Skipping data retention check outside of the retention window")
+		return
+	}
+
 	buckets, err := s.getBucketInformation(ctx)
 	if err != nil {
 		log.Error("Unable to determine bucket information", zap.Error(err))
@@ -127,8 +164,14 @@ func (s *retentionEnforcer) expireData(ctx context.Context, buckets []*influxdb.
 		logger.Warn("Unable to snapshot cache before retention", zap.Error(err))
 	}
 
-	var skipInf, skipInvalid int
-	for _, b := range buckets {
+	var skipInf, skipInvalid, skipArchived int
+	for i, b := range buckets {
+		if i > 0 && !s.Window.contains(time.Now()) {
+			logger.Info("Retention window closed, deferring the remaining buckets to the next check",
+				zap.Int("remaining", len(buckets)-i))
+			break
+		}
+
 		bucketFields := []zapcore.Field{
 			zap.String("org_id", b.OrgID.String()),
 			zap.String("bucket_id", b.ID.String()),
@@ -140,6 +183,11 @@ func (s *retentionEnforcer) expireData(ctx context.Context, buckets []*influxdb.
 			logger.Debug("Skipping bucket with infinite retention", bucketFields...)
 			skipInf++
 			continue
+		} else if b.Archived() {
+			// The data of archived buckets is kept until they are active again.
+			logger.Debug("Skipping archived bucket", bucketFields...)
+			skipArchived++
+			continue
 		} else if !b.OrgID.Valid() || !b.ID.Valid() {
 			skipInvalid++
 			logger.Warn("Skipping bucket with invalid fields", bucketFields...)
@@ -160,7 +208,8 @@ func (s *retentionEnforcer) expireData(ctx context.Context, buckets []*influxdb.
 			"to", time.Unix(0, max).UTC(),
 		)
 
-		err := s.Engine.DeleteBucketRange(ctx, b.OrgID, b.ID, min, max)
+		start, size := time.Now(), s.diskSize()
+		err := s.deleteBucketRange(ctx, b, min, max)
 		if err != nil {
 			logger.Info("Unable to delete bucket range",
 				append(bucketFields, zap.Time("min", time.Unix(0, min)), zap.Time("max", time.Unix(0, max)), zap.Error(err))...)
@@ -168,11 +217,137 @@ func (s *retentionEnforcer) expireData(ctx context.Context, buckets []*influxdb.
 		}
 		s.tracker.IncChecks(err == nil)
 		span.Finish()
+
+		if !s.pace(size-s.diskSize(), time.Since(start)) {
+			logger.Info("Engine closing, deferring the remaining buckets to the next check")
+			break
+		}
+	}
+
+	if skipInf > 0 || skipInvalid > 0 || skipArchived > 0 {
+		logger.Info("Skipped buckets", zap.Int("infinite_retention_total", skipInf), zap.Int("invalid_total", skipInvalid), zap.Int("archived_total", skipArchived))
+	}
+}
+
+// deleteBucketRange deletes the data of b in [min, max], except for data
+// covered by an active legal hold. If the holds cannot be determined nothing
+// is deleted.
+func (s *retentionEnforcer) deleteBucketRange(ctx context.Context, b *influxdb.Bucket, min, max int64) error {
+	if s.HoldFinder == nil {
+		return s.Engine.DeleteBucketRange(ctx, b.OrgID, b.ID, min, max)
+	}
+
+	active := influxdb.LegalHoldActive
+	holds, _, err := s.HoldFinder.FindLegalHolds(ctx, influxdb.LegalHoldFilter{
+		BucketID: &b.ID,
+		Status:   &active,
+	})
+	if err != nil {
+		return err
 	}
 
-	if skipInf > 0 || skipInvalid > 0 {
-		logger.Info("Skipped buckets", zap.Int("infinite_retention_total", skipInf), zap.Int("invalid_total", skipInvalid))
+	for _, seg := range retentionSegments(holds, min, max) {
+		if len(seg.exclude) == 0 {
+			if err := s.Engine.DeleteBucketRange(ctx, b.OrgID, b.ID, seg.min, seg.max); err != nil {
+				return err
+			}
+			continue
+		}
+
+		pd, ok := s.Engine.(PredicateDeleter)
+		if !ok {
+			// Unable to spare the held measurements, so leave the range alone.
+			continue
+		}
+		pred, err := excludeMeasurements(seg.exclude)
+		if err != nil {
+			return err
+		}
+		if err := pd.DeleteBucketRangePredicate(ctx, b.OrgID, b.ID, seg.min, seg.max, pred); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retentionSegment is a range of a bucket that may be expired, except for
+// the measurements in exclude.
+type retentionSegment struct {
+	min, max int64
+	exclude  []string
+}
+
+// retentionSegments splits [min, max] at the edges of the holds so that every
+// segment is either fully inside or fully outside each hold. Segments covered
+// by a hold on the whole bucket are dropped; the others exclude the
+// measurements of the holds covering them.
+func retentionSegments(holds []*influxdb.LegalHold, min, max int64) []retentionSegment {
+	bounds := []int64{min}
+	for _, h := range holds {
+		if !h.Overlaps(min, max) {
+			continue
+		}
+		hmin, hmax := h.Bounds()
+		if hmin > min {
+			bounds = append(bounds, hmin)
+		}
+		if hmax < max {
+			bounds = append(bounds, hmax+1)
+		}
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+
+	var segs []retentionSegment
+	for i, lo := range bounds {
+		if i > 0 && lo == bounds[i-1] {
+			continue
+		}
+		hi := max
+		for _, next := range bounds[i+1:] {
+			if next != lo {
+				hi = next - 1
+				break
+			}
+		}
+
+		seg := retentionSegment{min: lo, max: hi}
+		held := false
+		for _, h := range holds {
+			if !h.Overlaps(lo, hi) {
+				continue
+			}
+			if len(h.Measurements) == 0 {
+				held = true
+				break
+			}
+			seg.exclude = append(seg.exclude, h.Measurements...)
+		}
+		if !held {
+			segs = append(segs, seg)
+		}
+	}
+	return segs
+}
+
+// excludeMeasurements returns a predicate matching every series that is not
+// in one of the measurements.
+func excludeMeasurements(measurements []string) (influxdb.Predicate, error) {
+	var node predicate.Node
+	for _, m := range measurements {
+		rule := predicate.TagRuleNode{
+			Tag:      influxdb.Tag{Key: "_measurement", Value: m},
+			Operator: influxdb.NotEqual,
+		}
+		if node == nil {
+			node = rule
+			continue
+		}
+		node = predicate.LogicalNode{
+			Operator: predicate.LogicalAnd,
+			Children: [2]predicate.Node{node, rule},
+		}
 	}
+	return predicate.New(node)
 }
 
 // getBucketInformation returns a slice of buckets to run retention on.
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A DiskSizer reports the number of bytes a storage engine uses on disk. The
+// retention enforcer paces its deletions by the bytes they free when its
+// engine is a DiskSizer.
+type DiskSizer interface {
+	DiskSizeBytes() int64
+}
+
+// retentionWindow is a daily window of time, in UTC, in which the retention
+// enforcer deletes expired data. The window wraps midnight when end is before
+// start.
+type retentionWindow struct {
+	start, end time.Duration // offsets from midnight
+}
+
+// parseRetentionWindow parses a window formatted as "HH:MM-HH:MM". An empty
+// window is nil, which contains any time.
+func parseRetentionWindow(s string) (*retentionWindow, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid retention window %q: expected HH:MM-HH:MM", s)
+	}
+	var w retentionWindow
+	for i, dst := range []*time.Duration{&w.start, &w.end} {
+		t, err := time.Parse("15:04", strings.TrimSpace(parts[i]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid retention window %q: %v", s, err)
+		}
+		*dst = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if w.start == w.end {
+		return nil, fmt.Errorf("invalid retention window %q: start and end are equal", s)
+	}
+	return &w, nil
+}
+
+// contains returns true if t is in the window.
+func (w *retentionWindow) contains(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+
+	t = t.UTC()
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if w.start < w.end {
+		return offset >= w.start && offset < w.end
+	}
+	return offset >= w.start || offset < w.end
+}
+
+// diskSize returns the number of bytes the engine uses on disk, or 0 if it
+// does not report it.
+func (s *retentionEnforcer) diskSize() int64 {
+	if ds, ok := s.Engine.(DiskSizer); ok {
+		return ds.DiskSizeBytes()
+	}
+	return 0
+}
+
+// pace waits after a deletion that freed freed bytes and took took, so that
+// deletions free about DeleteRate bytes per second instead of stalling the
+// disks in a burst. It returns false if the engine closed while waiting.
+func (s *retentionEnforcer) pace(freed int64, took time.Duration) bool {
+	if s.DeleteRate <= 0 || freed <= 0 {
+		return true
+	}
+
+	wait := time.Duration(float64(freed)/float64(s.DeleteRate)*float64(time.Second)) - took
+	if wait <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.closing:
+		return false
+	}
+}
@@ -214,6 +214,21 @@ func TestRetentionService(t *testing.T) {
 		}
 	}
 
+	// The data of archived buckets is kept.
+	{
+		var n [16]byte
+		name := genMeasurementName()
+		copy(n[:], name)
+		orgID, bucketID := tsdb.DecodeName(n)
+		buckets = append(buckets, &influxdb.Bucket{
+			OrgID:           orgID,
+			ID:              bucketID,
+			RetentionPeriod: 3 * time.Hour,
+			State:           influxdb.BucketStateArchived,
+		})
+		expRejected[string(name)] = struct{}{}
+	}
+
 	gotMatched := map[string]struct{}{}
 	engine.DeleteBucketRangeFn = func(ctx context.Context, orgID, bucketID influxdb.ID, from, to int64) error {
 		if from != math.MinInt64 {
@@ -240,6 +255,161 @@ func TestRetentionService(t *testing.T) {
 	})
 }
 
+func TestRetentionService_Pacing(t *testing.T) {
+	engine := &SizedTestEngine{TestEngine: NewTestEngine(), size: 1 << 20}
+	engine.DeleteBucketRangeFn = func(context.Context, influxdb.ID, influxdb.ID, int64, int64) error {
+		atomic.AddInt64(&engine.size, -1000)
+		return nil
+	}
+
+	service := newRetentionEnforcer(engine, &TestSnapshotter{}, NewTestBucketFinder())
+	service.DeleteRate = 20000 // 50ms per deletion of 1000 bytes.
+	now := time.Date(2018, 4, 10, 23, 12, 33, 0, time.UTC)
+
+	var buckets []*influxdb.Bucket
+	for i := 1; i <= 3; i++ {
+		buckets = append(buckets, &influxdb.Bucket{
+			OrgID:           influxdb.ID(i),
+			ID:              influxdb.ID(i),
+			RetentionPeriod: time.Hour,
+		})
+	}
+
+	start := time.Now()
+	service.expireData(context.Background(), buckets, now)
+	if took := time.Since(start); took < 150*time.Millisecond {
+		t.Fatalf("deleting 3 buckets took %s, expected them to be paced over at least 150ms", took)
+	}
+	if got, exp := atomic.LoadInt64(&engine.size), int64(1<<20-3000); got != exp {
+		t.Fatalf("got size %d, expected %d", got, exp)
+	}
+
+	// Closing the engine interrupts pacing, deferring the remaining buckets.
+	closing := make(chan struct{})
+	close(closing)
+	service.closing = closing
+	service.DeleteRate = 1
+	service.expireData(context.Background(), buckets, now)
+	if got, exp := atomic.LoadInt64(&engine.size), int64(1<<20-4000); got != exp {
+		t.Fatalf("got size %d, expected %d", got, exp)
+	}
+}
+
+func TestRetentionWindow(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2020, 6, 1, hour, min, 0, 0, time.UTC)
+	}
+
+	w, err := parseRetentionWindow("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !w.contains(at(12, 0)) {
+		t.Error("expected an empty window to contain any time")
+	}
+
+	for _, s := range []string{"01:00", "1-5", "25:00-05:00", "02:00-02:00"} {
+		if _, err := parseRetentionWindow(s); err == nil {
+			t.Errorf("expected %q to be invalid", s)
+		}
+	}
+
+	tests := []struct {
+		window string
+		t      time.Time
+		exp    bool
+	}{
+		{"01:00-05:00", at(0, 59), false},
+		{"01:00-05:00", at(1, 0), true},
+		{"01:00-05:00", at(4, 59), true},
+		{"01:00-05:00", at(5, 0), false},
+		{"22:00-06:00", at(23, 30), true},
+		{"22:00-06:00", at(3, 0), true},
+		{"22:00-06:00", at(12, 0), false},
+	}
+	for _, tt := range tests {
+		w, err := parseRetentionWindow(tt.window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := w.contains(tt.t); got != tt.exp {
+			t.Errorf("window %s contains %s: got %v, expected %v", tt.window, tt.t.Format("15:04"), got, tt.exp)
+		}
+	}
+}
+
+func TestRetentionSegments(t *testing.T) {
+	at := func(ns int64) *time.Time {
+		ts := time.Unix(0, ns)
+		return &ts
+	}
+
+	tests := []struct {
+		name  string
+		holds []*influxdb.LegalHold
+		want  []retentionSegment
+	}{
+		{
+			name: "no holds",
+			want: []retentionSegment{{min: 0, max: 100}},
+		},
+		{
+			name: "hold outside range",
+			holds: []*influxdb.LegalHold{
+				{Start: at(200), Stop: at(300)},
+			},
+			want: []retentionSegment{{min: 0, max: 100}},
+		},
+		{
+			name: "bucket hold in the middle",
+			holds: []*influxdb.LegalHold{
+				{Start: at(20), Stop: at(30)},
+			},
+			want: []retentionSegment{{min: 0, max: 19}, {min: 31, max: 100}},
+		},
+		{
+			name: "unbounded bucket hold",
+			holds: []*influxdb.LegalHold{
+				{},
+			},
+		},
+		{
+			name: "measurement hold",
+			holds: []*influxdb.LegalHold{
+				{Measurements: []string{"cpu"}, Stop: at(50)},
+			},
+			want: []retentionSegment{
+				{min: 0, max: 50, exclude: []string{"cpu"}},
+				{min: 51, max: 100},
+			},
+		},
+		{
+			name: "overlapping holds",
+			holds: []*influxdb.LegalHold{
+				{Measurements: []string{"cpu"}, Start: at(10), Stop: at(60)},
+				{Start: at(40), Stop: at(50)},
+				{Measurements: []string{"mem"}, Start: at(10), Stop: at(20)},
+			},
+			want: []retentionSegment{
+				{min: 0, max: 9},
+				{min: 10, max: 20, exclude: []string{"cpu", "mem"}},
+				{min: 21, max: 39, exclude: []string{"cpu"}},
+				{min: 51, max: 60, exclude: []string{"cpu"}},
+				{min: 61, max: 100},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := retentionSegments(tt.holds, 0, 100)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got\n%+v\nexpected\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestMetrics_Retention(t *testing.T) {
 	t.Parallel()
 	// metrics to be shared by multiple file stores.
@@ -327,6 +497,16 @@ func (e *TestEngine) DeleteBucketRange(ctx context.Context, orgID, bucketID infl
 	return e.DeleteBucketRangeFn(ctx, orgID, bucketID, min, max)
 }
 
+// SizedTestEngine is a TestEngine reporting its size on disk.
+type SizedTestEngine struct {
+	*TestEngine
+	size int64
+}
+
+func (e *SizedTestEngine) DiskSizeBytes() int64 {
+	return atomic.LoadInt64(&e.size)
+}
+
 type TestSnapshotter struct{}
 
 func (s *TestSnapshotter) WriteSnapshot(ctx context.Context, status tsm1.CacheStatus) error {
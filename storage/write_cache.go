@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// DefaultWriteCacheTTL is the default time the buckets and organizations of
+// the points written are cached for.
+const DefaultWriteCacheTTL = time.Second
+
+// WriteCache caches the buckets and organizations of the points written for
+// a short time, so that the points writers of the write path that check them
+// do not each look them up for every batch of points. The changes to a
+// bucket or an organization are enforced on the writes once their cached
+// copy expires. The cached buckets and organizations must not be modified.
+type WriteCache struct {
+	buckets influxdb.BucketService
+	orgs    influxdb.OrganizationService
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu          sync.Mutex
+	bucketsByID map[influxdb.ID]cachedBucket
+	orgsByID    map[influxdb.ID]cachedOrg
+	nextExpire  time.Time
+}
+
+type cachedBucket struct {
+	bucket  *influxdb.Bucket
+	expires time.Time
+}
+
+type cachedOrg struct {
+	org     *influxdb.Organization
+	expires time.Time
+}
+
+// NewWriteCache returns a cache of the buckets and organizations found with
+// buckets and orgs, which are kept for ttl.
+func NewWriteCache(buckets influxdb.BucketService, orgs influxdb.OrganizationService, ttl time.Duration) *WriteCache {
+	return &WriteCache{
+		buckets:     buckets,
+		orgs:        orgs,
+		ttl:         ttl,
+		now:         time.Now,
+		bucketsByID: make(map[influxdb.ID]cachedBucket),
+		orgsByID:    make(map[influxdb.ID]cachedOrg),
+	}
+}
+
+// FindBucketByID returns the bucket id, from the cache if it has not expired.
+// Errors are not cached.
+func (c *WriteCache) FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+	now := c.now()
+	c.mu.Lock()
+	e, ok := c.bucketsByID[id]
+	c.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.bucket, nil
+	}
+
+	b, err := c.buckets.FindBucketByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire(now)
+	c.bucketsByID[id] = cachedBucket{bucket: b, expires: now.Add(c.ttl)}
+	return b, nil
+}
+
+// FindOrganizationByID returns the organization id, from the cache if it has
+// not expired. Errors are not cached.
+func (c *WriteCache) FindOrganizationByID(ctx context.Context, id influxdb.ID) (*influxdb.Organization, error) {
+	now := c.now()
+	c.mu.Lock()
+	e, ok := c.orgsByID[id]
+	c.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.org, nil
+	}
+
+	o, err := c.orgs.FindOrganizationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire(now)
+	c.orgsByID[id] = cachedOrg{org: o, expires: now.Add(c.ttl)}
+	return o, nil
+}
+
+// expire removes the expired entries at most once per ttl, so that the cache
+// only holds the buckets and organizations written to recently. It must be
+// called with mu held.
+func (c *WriteCache) expire(now time.Time) {
+	if now.Before(c.nextExpire) {
+		return
+	}
+	c.nextExpire = now.Add(c.ttl)
+	for id, e := range c.bucketsByID {
+		if !now.Before(e.expires) {
+			delete(c.bucketsByID, id)
+		}
+	}
+	for id, e := range c.orgsByID {
+		if !now.Before(e.expires) {
+			delete(c.orgsByID, id)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/bucketstate"
+	"github.com/influxdata/influxdb/v2/dedup"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/orgfreeze"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/writewindow"
+)
+
+func newWriteCache(ttl time.Duration) (*storage.WriteCache, *int, *int) {
+	var bucketLookups, orgLookups int
+	bs := mock.NewBucketService()
+	bs.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		bucketLookups++
+		if id != 2 {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound}
+		}
+		return &influxdb.Bucket{ID: id, OrgID: 1}, nil
+	}
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.Organization, error) {
+		orgLookups++
+		return &influxdb.Organization{ID: id}, nil
+	}
+	return storage.NewWriteCache(bs, orgs, ttl), &bucketLookups, &orgLookups
+}
+
+func TestWriteCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("caches buckets and organizations", func(t *testing.T) {
+		c, bucketLookups, orgLookups := newWriteCache(time.Hour)
+		for i := 0; i < 3; i++ {
+			if b, err := c.FindBucketByID(ctx, 2); err != nil || b.ID != 2 {
+				t.Fatalf("unexpected bucket %v, error %v", b, err)
+			}
+			if o, err := c.FindOrganizationByID(ctx, 1); err != nil || o.ID != 1 {
+				t.Fatalf("unexpected organization %v, error %v", o, err)
+			}
+		}
+		if *bucketLookups != 1 || *orgLookups != 1 {
+			t.Errorf("expected one lookup of each, got %d bucket and %d organization lookups", *bucketLookups, *orgLookups)
+		}
+	})
+
+	t.Run("expires entries", func(t *testing.T) {
+		c, bucketLookups, _ := newWriteCache(0)
+		for i := 0; i < 3; i++ {
+			if _, err := c.FindBucketByID(ctx, 2); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if *bucketLookups != 3 {
+			t.Errorf("expected 3 lookups, got %d", *bucketLookups)
+		}
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		c, bucketLookups, _ := newWriteCache(time.Hour)
+		for i := 0; i < 2; i++ {
+			if _, err := c.FindBucketByID(ctx, 3); influxdb.ErrorCode(err) != influxdb.ENotFound {
+				t.Fatalf("expected not found, got %v", err)
+			}
+		}
+		if *bucketLookups != 2 {
+			t.Errorf("expected 2 lookups, got %d", *bucketLookups)
+		}
+	})
+}
+
+// BenchmarkWritePath measures the points writers checking the buckets and
+// organizations of the points on the write path, with and without a cache.
+func BenchmarkWritePath(b *testing.B) {
+	var lookups int
+	bs := mock.NewBucketService()
+	bs.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		lookups++
+		return &influxdb.Bucket{ID: id, OrgID: 1, DedupWindow: time.Minute, WritePastWindow: time.Hour}, nil
+	}
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.Organization, error) {
+		lookups++
+		return &influxdb.Organization{ID: id}, nil
+	}
+
+	// chain is the write path of the launcher above the sharded writer.
+	chain := func(f interface {
+		bucketstate.BucketFinder
+		orgfreeze.OrgFinder
+	}) storage.PointsWriter {
+		next := &mock.PointsWriter{WritePointsFn: func(ctx context.Context, points []models.Point) error { return nil }}
+		return orgfreeze.NewPointsWriter(bucketstate.NewPointsWriter(writewindow.NewPointsWriter(dedup.NewPointsWriter(next, f, 0), f), f), f)
+	}
+
+	for _, bm := range []struct {
+		name   string
+		writer storage.PointsWriter
+	}{
+		{name: "uncached", writer: chain(struct {
+			*mock.BucketService
+			*mock.OrganizationService
+		}{bs, orgs})},
+		{name: "cached", writer: chain(storage.NewWriteCache(bs, orgs, storage.DefaultWriteCacheTTL))},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			ctx := context.Background()
+			now := time.Now()
+			points := make([]models.Point, 1000)
+			name := tsdb.EncodeNameString(1, 2)
+			tags := models.NewTags(map[string]string{"host": "a"})
+			lookups = 0
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// The points differ from a batch to the next, so that none
+				// of them is dropped as a duplicate.
+				b.StopTimer()
+				for j := range points {
+					points[j] = models.MustNewPoint(name, tags, models.Fields{"f": float64(j)}, now.Add(time.Duration(i*len(points)+j)))
+				}
+				b.StartTimer()
+				if err := bm.writer.WritePoints(ctx, points); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(lookups)/float64(b.N), "lookups/op")
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+var _ PointsWriter = (*ShardedPointsWriter)(nil)
+
+// BucketByIDFinder finds the buckets of the points written. The lookups
+// happen on every write, so it should be cached, such as by a WriteCache.
+type BucketByIDFinder interface {
+	FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error)
+}
+
+// ShardedPointsWriter partitions the points written to the buckets with a
+// write sharding by their shard, and writes the partitions to the underlying
+// PointsWriter in parallel. The points of buckets without a write sharding
+// are written with the first partition. Atomic writes are written in one
+// partition, so that they stay atomic.
+type ShardedPointsWriter struct {
+	next    PointsWriter
+	buckets BucketByIDFinder
+}
+
+// NewShardedPointsWriter wraps next so that the writes to buckets with a
+// write sharding are partitioned.
+func NewShardedPointsWriter(next PointsWriter, buckets BucketByIDFinder) *ShardedPointsWriter {
+	return &ShardedPointsWriter{
+		next:    next,
+		buckets: buckets,
+	}
+}
+
+// WritePoints writes the partitions of the points in parallel. The points of
+// the partitions that fail are not written; when other partitions are
+// written, the write is partial and the points dropped are counted in a
+// PartialWriteError.
+func (w *ShardedPointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	if len(points) < 2 || AtomicWriteFromContext(ctx) {
+		return w.next.WritePoints(ctx, points)
+	}
+
+	parts, err := w.partition(ctx, points)
+	if err != nil {
+		return err
+	}
+	if len(parts) < 2 {
+		return w.next.WritePoints(ctx, points)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(parts))
+	)
+	for i, part := range parts {
+		if len(part) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, part []models.Point) {
+			defer wg.Done()
+			errs[i] = w.next.WritePoints(ctx, part)
+		}(i, part)
+	}
+	wg.Wait()
+	return mergeWriteErrors(parts, errs)
+}
+
+// partition returns the points by shard, in the order they are written
+// within each shard.
+func (w *ShardedPointsWriter) partition(ctx context.Context, points []models.Point) ([][]models.Point, error) {
+	shardings := make(map[string]*influxdb.WriteSharding)
+	var sharded bool
+	for _, p := range points {
+		name := string(p.Name())
+		if _, ok := shardings[name]; ok {
+			continue
+		}
+		ws, err := w.writeSharding(ctx, p.Name())
+		if err != nil {
+			return nil, err
+		}
+		shardings[name] = ws
+		sharded = sharded || ws != nil
+	}
+	if !sharded {
+		return nil, nil
+	}
+
+	var parts [][]models.Point
+	for _, p := range points {
+		var shard int
+		if ws := shardings[string(p.Name())]; ws != nil {
+			if v := p.Tags().Get([]byte(ws.Tag)); v != nil {
+				h := fnv.New32a()
+				h.Write(v)
+				shard = int(h.Sum32() % uint32(ws.Shards))
+			}
+		}
+		for len(parts) <= shard {
+			parts = append(parts, nil)
+		}
+		parts[shard] = append(parts[shard], p)
+	}
+	return parts, nil
+}
+
+// writeSharding returns the write sharding of the bucket of the encoded
+// name, or nil if the points of the bucket are not partitioned. Buckets that
+// are not found are not partitioned, their writes fail downstream.
+func (w *ShardedPointsWriter) writeSharding(ctx context.Context, name []byte) (*influxdb.WriteSharding, error) {
+	_, bucketID := tsdb.DecodeNameSlice(name)
+	b, err := w.buckets.FindBucketByID(ctx, bucketID)
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !b.WriteSharding.Enabled() {
+		return nil, nil
+	}
+	return b.WriteSharding, nil
+}
+
+// mergeWriteErrors returns the error of the writes of the partitions. If no
+// partition is written, it is the first error. Otherwise the points of the
+// partitions that failed and the points dropped by partial writes are merged
+// into one partial write, whose reason is the first failure.
+func mergeWriteErrors(parts [][]models.Point, errs []error) error {
+	var (
+		partial *tsdb.PartialWriteError
+		failed  error
+		written bool
+	)
+	for i, err := range errs {
+		if len(parts[i]) == 0 {
+			continue
+		}
+		if partial == nil && err != nil {
+			partial = &tsdb.PartialWriteError{}
+		}
+		switch e := err.(type) {
+		case nil:
+			written = true
+		case tsdb.PartialWriteError:
+			written = true
+			if partial.Reason == "" {
+				partial.Reason = e.Reason
+			}
+			partial.Dropped += e.Dropped
+			partial.DroppedKeys = append(partial.DroppedKeys, e.DroppedKeys...)
+		default:
+			if failed == nil {
+				failed = err
+			}
+			partial.Dropped += len(parts[i])
+			for _, p := range parts[i] {
+				partial.DroppedKeys = append(partial.DroppedKeys, p.Key())
+			}
+		}
+	}
+	if partial == nil {
+		return nil
+	}
+	if !written {
+		return failed
+	}
+	if failed != nil {
+		partial.Reason = failed.Error()
+	}
+	sort.Slice(partial.DroppedKeys, func(i, j int) bool {
+		return bytes.Compare(partial.DroppedKeys[i], partial.DroppedKeys[j]) < 0
+	})
+	return *partial
+}
@@ -0,0 +1,178 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+func newShardingBucketFinder() *mock.BucketService {
+	bs := mock.NewBucketService()
+	bs.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		switch id {
+		case 2:
+			return &influxdb.Bucket{ID: 2, WriteSharding: &influxdb.WriteSharding{Tag: "host", Shards: 4}}, nil
+		case 3:
+			return &influxdb.Bucket{ID: 3}, nil
+		default:
+			return nil, &influxdb.Error{Code: influxdb.ENotFound}
+		}
+	}
+	return bs
+}
+
+func newShardingPoint(bucketID influxdb.ID, host string) models.Point {
+	tags := map[string]string{models.MeasurementTagKey: "cpu", models.FieldKeyTagKey: "f"}
+	if host != "" {
+		tags["host"] = host
+	}
+	return models.MustNewPoint(
+		tsdb.EncodeNameString(1, bucketID),
+		models.NewTags(tags),
+		models.Fields{"f": float64(1)},
+		time.Unix(0, 0),
+	)
+}
+
+func TestShardedPointsWriter(t *testing.T) {
+	var points []models.Point
+	for _, host := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "a", ""} {
+		points = append(points, newShardingPoint(2, host))
+	}
+	points = append(points, newShardingPoint(3, "a"), newShardingPoint(4, "b"))
+
+	t.Run("partitions by tag", func(t *testing.T) {
+		var (
+			mu     sync.Mutex
+			writes [][]models.Point
+		)
+		w := storage.NewShardedPointsWriter(&mock.PointsWriter{
+			WritePointsFn: func(ctx context.Context, p []models.Point) error {
+				mu.Lock()
+				defer mu.Unlock()
+				writes = append(writes, p)
+				return nil
+			},
+		}, newShardingBucketFinder())
+
+		if err := w.WritePoints(context.Background(), points); err != nil {
+			t.Fatal(err)
+		}
+		if len(writes) < 2 || len(writes) > 4 {
+			t.Fatalf("expected between 2 and 4 partitions, got %d", len(writes))
+		}
+
+		var n int
+		hosts := make(map[string]int)
+		for i, write := range writes {
+			n += len(write)
+			for _, p := range write {
+				host := string(p.Tags().Get([]byte("host")))
+				if _, bucketID := tsdb.DecodeNameSlice(p.Name()); bucketID != 2 {
+					continue
+				}
+				if j, ok := hosts[host]; ok && j != i {
+					t.Errorf("host %q written in partitions %d and %d", host, j, i)
+				}
+				hosts[host] = i
+			}
+		}
+		if n != len(points) {
+			t.Errorf("expected %d points written, got %d", len(points), n)
+		}
+	})
+
+	t.Run("atomic writes are not partitioned", func(t *testing.T) {
+		var n int
+		w := storage.NewShardedPointsWriter(&mock.PointsWriter{
+			WritePointsFn: func(ctx context.Context, p []models.Point) error {
+				n++
+				if len(p) != len(points) {
+					t.Errorf("expected %d points, got %d", len(points), len(p))
+				}
+				return nil
+			},
+		}, newShardingBucketFinder())
+
+		if err := w.WritePoints(storage.WithAtomicWrite(context.Background()), points); err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 {
+			t.Errorf("expected one write, got %d", n)
+		}
+	})
+
+	t.Run("merges partial writes", func(t *testing.T) {
+		w := storage.NewShardedPointsWriter(&mock.PointsWriter{
+			WritePointsFn: func(ctx context.Context, p []models.Point) error {
+				return tsdb.PartialWriteError{
+					Reason:      "dropped",
+					Dropped:     1,
+					DroppedKeys: [][]byte{p[0].Key()},
+				}
+			},
+		}, newShardingBucketFinder())
+
+		err := w.WritePoints(context.Background(), points)
+		var partial tsdb.PartialWriteError
+		if !errors.As(err, &partial) {
+			t.Fatalf("expected a partial write error, got %v", err)
+		}
+		if partial.Dropped < 2 || partial.Dropped != len(partial.DroppedKeys) {
+			t.Errorf("unexpected partial write error %+v", partial)
+		}
+	})
+
+	t.Run("reports the points of failed partitions as a partial write", func(t *testing.T) {
+		var (
+			mu      sync.Mutex
+			failed  int
+			written int
+		)
+		w := storage.NewShardedPointsWriter(&mock.PointsWriter{
+			WritePointsFn: func(ctx context.Context, p []models.Point) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if failed == 0 {
+					failed = len(p)
+					return errors.New("disk full")
+				}
+				written += len(p)
+				return nil
+			},
+		}, newShardingBucketFinder())
+
+		err := w.WritePoints(context.Background(), points)
+		var partial tsdb.PartialWriteError
+		if !errors.As(err, &partial) {
+			t.Fatalf("expected a partial write error, got %v", err)
+		}
+		if partial.Dropped != failed || partial.Reason != "disk full" {
+			t.Errorf("unexpected partial write error %+v, %d points failed", partial, failed)
+		}
+		if failed+written != len(points) {
+			t.Errorf("expected %d points, got %d failed and %d written", len(points), failed, written)
+		}
+	})
+
+	t.Run("fails when no partition is written", func(t *testing.T) {
+		w := storage.NewShardedPointsWriter(&mock.PointsWriter{
+			WritePointsFn: func(ctx context.Context, p []models.Point) error {
+				return errors.New("disk full")
+			},
+		}, newShardingBucketFinder())
+
+		err := w.WritePoints(context.Background(), points)
+		if err == nil || err.Error() != "disk full" {
+			t.Fatalf("expected the error of the partitions, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,242 @@
+package influxdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// SubscriptionDestination is the kind of the system a subscription sends
+// the points written to its buckets to.
+type SubscriptionDestination string
+
+const (
+	// SubscriptionDestinationHTTP subscriptions POST the line protocol of the
+	// points to a URL, such as the write API of another InfluxDB.
+	SubscriptionDestinationHTTP SubscriptionDestination = "http"
+	// SubscriptionDestinationKafka subscriptions produce the line protocol of
+	// the points as messages of a Kafka topic.
+	SubscriptionDestinationKafka SubscriptionDestination = "kafka"
+	// SubscriptionDestinationNATS subscriptions publish the line protocol of
+	// the points as messages of a NATS subject.
+	SubscriptionDestinationNATS SubscriptionDestination = "nats"
+)
+
+// Valid returns an error if the destination is unknown.
+func (d SubscriptionDestination) Valid() error {
+	switch d {
+	case SubscriptionDestinationHTTP, SubscriptionDestinationKafka, SubscriptionDestinationNATS:
+		return nil
+	default:
+		return fmt.Errorf("invalid destination %q, must be %q, %q or %q", d, SubscriptionDestinationHTTP, SubscriptionDestinationKafka, SubscriptionDestinationNATS)
+	}
+}
+
+// Subscription receives a copy of the points written to a set of buckets,
+// optionally restricted to some of their measurements. The points are
+// buffered on disk until the destination accepts them, so that they are
+// delivered once it is available again.
+type Subscription struct {
+	ID          ID     `json:"id,omitempty"`
+	OrgID       ID     `json:"orgID"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Status      Status `json:"status"`
+	// BucketIDs are the buckets whose writes are copied.
+	BucketIDs []ID `json:"bucketIDs"`
+	// Measurements restricts the points copied to those of the
+	// measurements; all the points are copied when it is empty.
+	Measurements []string                `json:"measurements,omitempty"`
+	Destination  SubscriptionDestination `json:"destination"`
+	// URL is the URL the points are posted to for http subscriptions, and
+	// the URL of the server for nats subscriptions.
+	URL string `json:"url,omitempty"`
+	// Brokers are the addresses of the brokers of kafka subscriptions.
+	Brokers []string `json:"brokers,omitempty"`
+	// Topic is the topic of kafka subscriptions, or the subject of nats
+	// subscriptions.
+	Topic string `json:"topic,omitempty"`
+	// Mirror makes the subscription of an http destination the mirror of
+	// all the points of its buckets to the write API of another instance,
+	// which mirrors them back for active/active deployments. The points
+	// written at the same timestamps by both instances are resolved in favor
+	// of the last written, and the points received from the other instance
+	// are not sent back.
+	Mirror bool `json:"mirror,omitempty"`
+	CRUDLog
+}
+
+// Valid returns an error if the subscription is missing required fields or
+// its destination is incomplete.
+func (s *Subscription) Valid() error {
+	if !s.OrgID.Valid() {
+		return errors.New("orgID is required")
+	}
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	if err := s.Status.Valid(); err != nil {
+		return err
+	}
+	if len(s.BucketIDs) == 0 {
+		return errors.New("bucketIDs is required")
+	}
+	for _, id := range s.BucketIDs {
+		if !id.Valid() {
+			return errors.New("bucketIDs contains an invalid ID")
+		}
+	}
+	for _, m := range s.Measurements {
+		if m == "" {
+			return errors.New("measurements must not be empty")
+		}
+	}
+	if err := s.Destination.Valid(); err != nil {
+		return err
+	}
+
+	if s.Mirror && s.Destination != SubscriptionDestinationHTTP {
+		return errors.New("mirror is only supported by http subscriptions")
+	}
+	if s.Mirror && len(s.Measurements) > 0 {
+		return errors.New("mirror subscriptions copy all the points of their buckets, measurements is not allowed")
+	}
+
+	switch s.Destination {
+	case SubscriptionDestinationHTTP:
+		if err := validSubscriptionURL(s.URL, "http", "https"); err != nil {
+			return err
+		}
+		if len(s.Brokers) > 0 || s.Topic != "" {
+			return errors.New("brokers and topic are not allowed for http subscriptions")
+		}
+	case SubscriptionDestinationKafka:
+		if len(s.Brokers) == 0 {
+			return errors.New("brokers is required for kafka subscriptions")
+		}
+		if s.Topic == "" {
+			return errors.New("topic is required for kafka subscriptions")
+		}
+		if s.URL != "" {
+			return errors.New("url is not allowed for kafka subscriptions")
+		}
+	case SubscriptionDestinationNATS:
+		if err := validSubscriptionURL(s.URL, "nats", "tls"); err != nil {
+			return err
+		}
+		if s.Topic == "" {
+			return errors.New("topic is required for nats subscriptions")
+		}
+		if len(s.Brokers) > 0 {
+			return errors.New("brokers is not allowed for nats subscriptions")
+		}
+	}
+	return nil
+}
+
+func validSubscriptionURL(s string, schemes ...string) error {
+	if s == "" {
+		return errors.New("url is required")
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid url: %v", err)
+	}
+	if !containsString(schemes, u.Scheme) || u.Host == "" {
+		return fmt.Errorf("invalid url %q, must be an absolute %s url", s, schemes[0])
+	}
+	return nil
+}
+
+// Matches returns true if the points of the measurement written to the
+// bucket are copied to the subscription.
+func (s *Subscription) Matches(bucketID ID, measurement string) bool {
+	found := false
+	for _, id := range s.BucketIDs {
+		if id == bucketID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+	return len(s.Measurements) == 0 || containsString(s.Measurements, measurement)
+}
+
+// SubscriptionFilter represents a set of filters that restrict the returned
+// subscriptions.
+type SubscriptionFilter struct {
+	OrgID    *ID
+	Name     *string
+	BucketID *ID
+}
+
+// SubscriptionUpdate is the changeset of a subscription.
+type SubscriptionUpdate struct {
+	Name         *string                  `json:"name,omitempty"`
+	Description  *string                  `json:"description,omitempty"`
+	Status       *Status                  `json:"status,omitempty"`
+	BucketIDs    *[]ID                    `json:"bucketIDs,omitempty"`
+	Measurements *[]string                `json:"measurements,omitempty"`
+	Destination  *SubscriptionDestination `json:"destination,omitempty"`
+	URL          *string                  `json:"url,omitempty"`
+	Brokers      *[]string                `json:"brokers,omitempty"`
+	Topic        *string                  `json:"topic,omitempty"`
+	Mirror       *bool                    `json:"mirror,omitempty"`
+}
+
+// Apply applies the changeset to s.
+func (u SubscriptionUpdate) Apply(s *Subscription) {
+	if u.Name != nil {
+		s.Name = *u.Name
+	}
+	if u.Description != nil {
+		s.Description = *u.Description
+	}
+	if u.Status != nil {
+		s.Status = *u.Status
+	}
+	if u.BucketIDs != nil {
+		s.BucketIDs = *u.BucketIDs
+	}
+	if u.Measurements != nil {
+		s.Measurements = *u.Measurements
+	}
+	if u.Destination != nil {
+		s.Destination = *u.Destination
+	}
+	if u.URL != nil {
+		s.URL = *u.URL
+	}
+	if u.Brokers != nil {
+		s.Brokers = *u.Brokers
+	}
+	if u.Topic != nil {
+		s.Topic = *u.Topic
+	}
+	if u.Mirror != nil {
+		s.Mirror = *u.Mirror
+	}
+}
+
+// SubscriptionService stores the subscriptions of organizations.
+type SubscriptionService interface {
+	// CreateSubscription creates a subscription. Names are unique in an
+	// organization.
+	CreateSubscription(ctx context.Context, s *Subscription) error
+
+	// FindSubscriptionByID returns a single subscription by ID.
+	FindSubscriptionByID(ctx context.Context, id ID) (*Subscription, error)
+
+	// FindSubscriptions returns a list of subscriptions that match filter
+	// and the total count of matching subscriptions.
+	FindSubscriptions(ctx context.Context, filter SubscriptionFilter, opt ...FindOptions) ([]*Subscription, int, error)
+
+	// UpdateSubscription updates a single subscription with changeset.
+	UpdateSubscription(ctx context.Context, id ID, upd SubscriptionUpdate) (*Subscription, error)
+
+	// DeleteSubscription removes a single subscription by ID.
+	DeleteSubscription(ctx context.Context, id ID) error
+}
@@ -0,0 +1,159 @@
+package subscription
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	nats "github.com/nats-io/go-nats"
+	kafka "github.com/segmentio/kafka-go"
+)
+
+const (
+	// sendTimeout is the time after which a batch that was not accepted by
+	// its destination is sent again.
+	sendTimeout = 30 * time.Second
+)
+
+// destination sends the batches of line protocol of a subscription.
+type destination interface {
+	send(ctx context.Context, data []byte) error
+	close() error
+}
+
+// permanentError is returned by destinations that will never accept a
+// batch, which is dropped rather than sent again.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+// newDestination returns the destination of sub. The writes of mirror
+// subscriptions are authorized with mirrorToken.
+func newDestination(sub *influxdb.Subscription, mirrorToken string) (destination, error) {
+	switch sub.Destination {
+	case influxdb.SubscriptionDestinationHTTP:
+		d := &httpDestination{
+			url:    sub.URL,
+			client: &http.Client{Timeout: sendTimeout},
+		}
+		if sub.Mirror {
+			d.token = mirrorToken
+		}
+		return d, nil
+	case influxdb.SubscriptionDestinationKafka:
+		return &kafkaDestination{
+			w: kafka.NewWriter(kafka.WriterConfig{
+				Brokers: sub.Brokers,
+				Topic:   sub.Topic,
+			}),
+		}, nil
+	case influxdb.SubscriptionDestinationNATS:
+		return &natsDestination{url: sub.URL, subject: sub.Topic}, nil
+	default:
+		return nil, fmt.Errorf("unknown subscription destination %q", sub.Destination)
+	}
+}
+
+// httpDestination posts the batches to a URL, such as the write API of
+// another InfluxDB. The write time of the batches of mirror subscriptions is
+// sent in the mirror time header.
+type httpDestination struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func (d *httpDestination) send(ctx context.Context, data []byte) error {
+	written, data, mirrored := splitMirrorTime(data)
+	req, err := http.NewRequest(http.MethodPost, d.url, bytes.NewReader(data))
+	if err != nil {
+		return &permanentError{err: err}
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if mirrored {
+		req.Header.Set(mirrorTimeHeader, written.Format(time.RFC3339Nano))
+	}
+	if d.token != "" {
+		req.Header.Set("Authorization", "Token "+d.token)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<10))
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("subscription endpoint responded %s", resp.Status)
+	case resp.StatusCode/100 == 4:
+		// The batch is rejected, and would be rejected again.
+		return &permanentError{err: fmt.Errorf("subscription endpoint responded %s", resp.Status)}
+	default:
+		return fmt.Errorf("subscription endpoint responded %s", resp.Status)
+	}
+}
+
+func (d *httpDestination) close() error {
+	d.client.CloseIdleConnections()
+	return nil
+}
+
+// kafkaDestination produces each batch as a message of a Kafka topic.
+type kafkaDestination struct {
+	w *kafka.Writer
+}
+
+func (d *kafkaDestination) send(ctx context.Context, data []byte) error {
+	return d.w.WriteMessages(ctx, kafka.Message{Value: data})
+}
+
+func (d *kafkaDestination) close() error {
+	return d.w.Close()
+}
+
+// natsDestination publishes each batch as a message of a NATS subject. It
+// connects on the first batch, and again after a batch fails.
+type natsDestination struct {
+	url     string
+	subject string
+	conn    *nats.Conn
+}
+
+func (d *natsDestination) send(ctx context.Context, data []byte) error {
+	if d.conn == nil {
+		conn, err := nats.Connect(d.url)
+		if err != nil {
+			return err
+		}
+		d.conn = conn
+	}
+	err := d.conn.Publish(d.subject, data)
+	if err == nil {
+		err = d.conn.FlushTimeout(sendTimeout)
+	}
+	if err != nil {
+		d.conn.Close()
+		d.conn = nil
+	}
+	return err
+}
+
+func (d *natsDestination) close() error {
+	if d.conn != nil {
+		d.conn.Close()
+		d.conn = nil
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package subscription
+
+import (
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrSubscriptionNotFound is used when the specified subscription cannot
+	// be found.
+	ErrSubscriptionNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "subscription not found",
+	}
+
+	// ErrInvalidSubscriptionID is used when the ID of the subscription
+	// cannot be encoded.
+	ErrInvalidSubscriptionID = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "subscription ID is invalid",
+	}
+
+	// ErrSubscriptionNameConflict is used when the name of a subscription is
+	// already used in its organization.
+	ErrSubscriptionNameConflict = &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  "subscription name is not unique in the organization",
+	}
+)
+
+// ErrInvalidSubscription is used when a service was provided an invalid
+// subscription.
+func ErrInvalidSubscription(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "subscription provided is invalid",
+		Err:  err,
+	}
+}
+
+// ErrInternalService is used when the error comes from an internal system.
+func ErrInternalService(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
@@ -0,0 +1,240 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixSubscriptions is the prefix of the subscription API.
+	PrefixSubscriptions = "/api/v2/subscriptions"
+)
+
+// Handler is the HTTP API handler for subscriptions.
+type Handler struct {
+	chi.Router
+	api              *kithttp.API
+	log              *zap.Logger
+	subscriptionsSvc influxdb.SubscriptionService
+	orgSvc           influxdb.OrganizationService
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, subscriptionsSvc influxdb.SubscriptionService, orgSvc influxdb.OrganizationService) *Handler {
+	h := &Handler{
+		api:              kithttp.NewAPI(kithttp.WithLog(log)),
+		log:              log,
+		subscriptionsSvc: subscriptionsSvc,
+		orgSvc:           orgSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Post("/", h.handlePostSubscription)
+		r.Get("/", h.handleGetSubscriptions)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetSubscription)
+			r.Patch("/", h.handlePatchSubscription)
+			r.Delete("/", h.handleDeleteSubscription)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixSubscriptions
+}
+
+type subscriptionResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.Subscription
+}
+
+func newSubscriptionResponse(s *influxdb.Subscription) *subscriptionResponse {
+	return &subscriptionResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("%s/%s", PrefixSubscriptions, s.ID),
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", s.OrgID),
+		},
+		Subscription: s,
+	}
+}
+
+type subscriptionsResponse struct {
+	Links         map[string]string       `json:"links"`
+	Subscriptions []*subscriptionResponse `json:"subscriptions"`
+}
+
+type postSubscriptionRequest struct {
+	OrgID        influxdb.ID                      `json:"orgID"`
+	Org          string                           `json:"org,omitempty"` // the ID or name of the organization, when OrgID is not set
+	Name         string                           `json:"name"`
+	Description  string                           `json:"description"`
+	Status       influxdb.Status                  `json:"status"`
+	BucketIDs    []influxdb.ID                    `json:"bucketIDs"`
+	Measurements []string                         `json:"measurements"`
+	Destination  influxdb.SubscriptionDestination `json:"destination"`
+	URL          string                           `json:"url"`
+	Brokers      []string                         `json:"brokers"`
+	Topic        string                           `json:"topic"`
+	Mirror       bool                             `json:"mirror"`
+}
+
+// resolveOrgID returns the ID of the organization identified by orgID, or
+// else by org.
+func (h *Handler) resolveOrgID(ctx context.Context, orgID, org string) (influxdb.ID, error) {
+	o, err := influxdb.ResolveOrganization(ctx, h.orgSvc, orgID, org)
+	if err != nil {
+		return 0, err
+	}
+	return o.ID, nil
+}
+
+func (h *Handler) handlePostSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req postSubscriptionRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if !req.OrgID.Valid() {
+		orgID, err := h.resolveOrgID(ctx, "", req.Org)
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		req.OrgID = orgID
+	}
+
+	s := &influxdb.Subscription{
+		OrgID:        req.OrgID,
+		Name:         req.Name,
+		Description:  req.Description,
+		Status:       req.Status,
+		BucketIDs:    req.BucketIDs,
+		Measurements: req.Measurements,
+		Destination:  req.Destination,
+		URL:          req.URL,
+		Brokers:      req.Brokers,
+		Topic:        req.Topic,
+		Mirror:       req.Mirror,
+	}
+	if err := h.subscriptionsSvc.CreateSubscription(ctx, s); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Subscription created", zap.String("subscription", s.Name), zap.String("orgID", s.OrgID.String()))
+
+	h.api.Respond(w, r, http.StatusCreated, newSubscriptionResponse(s))
+}
+
+func (h *Handler) handleGetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var filter influxdb.SubscriptionFilter
+	q := r.URL.Query()
+	if orgID, org := q.Get("orgID"), q.Get("org"); orgID != "" || org != "" {
+		id, err := h.resolveOrgID(ctx, orgID, org)
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		filter.OrgID = &id
+	}
+	if v := q.Get("name"); v != "" {
+		filter.Name = &v
+	}
+	if v := q.Get("bucketID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			h.api.Err(w, r, influxdb.ErrCorruptID(err))
+			return
+		}
+		filter.BucketID = id
+	}
+
+	subs, _, err := h.subscriptionsSvc.FindSubscriptions(ctx, filter)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	resp := subscriptionsResponse{
+		Links:         map[string]string{"self": PrefixSubscriptions},
+		Subscriptions: make([]*subscriptionResponse, 0, len(subs)),
+	}
+	for _, s := range subs {
+		resp.Subscriptions = append(resp.Subscriptions, newSubscriptionResponse(s))
+	}
+	h.api.Respond(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleGetSubscription(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	s, err := h.subscriptionsSvc.FindSubscriptionByID(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newSubscriptionResponse(s))
+}
+
+func (h *Handler) handlePatchSubscription(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	var upd influxdb.SubscriptionUpdate
+	if err := h.api.DecodeJSON(r.Body, &upd); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	s, err := h.subscriptionsSvc.UpdateSubscription(r.Context(), *id, upd)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Subscription updated", zap.String("subscriptionID", s.ID.String()))
+
+	h.api.Respond(w, r, http.StatusOK, newSubscriptionResponse(s))
+}
+
+func (h *Handler) handleDeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	if err := h.subscriptionsSvc.DeleteSubscription(r.Context(), *id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Subscription deleted", zap.String("subscriptionID", id.String()))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
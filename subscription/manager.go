@@ -0,0 +1,503 @@
+package subscription
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/durablequeue"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMaxQueueSize is the default maximum size in bytes of the queue
+	// of each subscription.
+	DefaultMaxQueueSize = 1 << 30
+
+	// DefaultSyncInterval is the default interval at which the changes to
+	// subscriptions are applied.
+	DefaultSyncInterval = 10 * time.Second
+
+	minRetryInterval = time.Second
+	maxRetryInterval = time.Minute
+)
+
+// Manager copies the points written to the buckets of the active
+// subscriptions to their queues, and sends the queued points to the
+// destinations of the subscriptions.
+type Manager struct {
+	log          *zap.Logger
+	svc          influxdb.SubscriptionService
+	dir          string
+	maxQueueSize int64
+
+	// SyncInterval is the interval at which the subscriptions are listed
+	// to start, restart and stop their delivery.
+	SyncInterval time.Duration
+	// MirrorToken is the token authorizing the writes of the mirror
+	// subscriptions to the other instance.
+	MirrorToken string
+	// MirrorWindow is the time during which the writes of the points of
+	// mirrored buckets are remembered to resolve their conflicts.
+	MirrorWindow time.Duration
+	// MirrorMaxPoints is the maximum number of writes of the points of
+	// mirrored buckets remembered, the oldest being forgotten first.
+	MirrorMaxPoints int
+
+	mu          sync.RWMutex
+	subscribers map[influxdb.ID]*subscriber
+	mirror      *mirrorIndex
+
+	sent       *prometheus.CounterVec
+	failed     *prometheus.CounterVec
+	dropped    *prometheus.CounterVec
+	queueBytes *prometheus.GaugeVec
+
+	mirrorLag         *prometheus.GaugeVec
+	mirrorConflicts   *prometheus.CounterVec
+	mirrorDivergences prometheus.Counter
+	mirrorPoints      prometheus.Gauge
+}
+
+// NewManager returns a Manager of the subscriptions of svc, which keeps the
+// queues of the subscriptions in dir, each at most maxQueueSize bytes.
+func NewManager(log *zap.Logger, svc influxdb.SubscriptionService, dir string, maxQueueSize int64) *Manager {
+	const namespace = "subscription"
+	labels := []string{"subscription_id"}
+
+	return &Manager{
+		log:             log,
+		svc:             svc,
+		dir:             dir,
+		maxQueueSize:    maxQueueSize,
+		SyncInterval:    DefaultSyncInterval,
+		MirrorWindow:    DefaultMirrorWindow,
+		MirrorMaxPoints: DefaultMirrorMaxPoints,
+		subscribers:     make(map[influxdb.ID]*subscriber),
+		mirror:          newMirrorIndex(),
+
+		sent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sent_total",
+			Help:      "Number of batches of points sent to the destination of the subscription.",
+		}, labels),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "failed_total",
+			Help:      "Number of attempts to send a batch of points to the destination of the subscription that failed.",
+		}, labels),
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dropped_bytes_total",
+			Help:      "Number of bytes of line protocol dropped because the queue of the subscription was full or the destination rejected them.",
+		}, labels),
+		queueBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "queue_bytes",
+			Help:      "Number of bytes of the queue of the subscription not yet sent to its destination.",
+		}, labels),
+
+		mirrorLag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "mirror_lag_seconds",
+			Help:      "Time between the write of the last batch of points sent by the mirror subscription and its acceptance by the other instance.",
+		}, labels),
+		mirrorConflicts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "mirror_conflicts_total",
+			Help:      "Number of points mirrored from another instance with the timestamp of a different point written locally, by the instance whose point was written last and kept.",
+		}, []string{"resolution"}),
+		mirrorDivergences: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "mirror_divergences_total",
+			Help:      "Number of points written locally over a point mirrored from another instance that was written later, on which the instances disagree until it is written again.",
+		}),
+		mirrorPoints: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "mirror_index_points",
+			Help:      "Number of points of mirrored buckets whose write is remembered to resolve their conflicts.",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *Manager) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.sent,
+		m.failed,
+		m.dropped,
+		m.queueBytes,
+		m.mirrorLag,
+		m.mirrorConflicts,
+		m.mirrorDivergences,
+		m.mirrorPoints,
+	}
+}
+
+// Run delivers the points of the subscriptions until ctx is done, applying
+// the changes to the subscriptions every SyncInterval.
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		m.sync(ctx)
+		select {
+		case <-ctx.Done():
+			m.stopAll()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sync starts the delivery of the active subscriptions, restarts it for
+// those updated and stops it for the others. The queues of the deleted
+// subscriptions are removed, while those of inactive ones are kept for when
+// they are activated again.
+func (m *Manager) sync(ctx context.Context) {
+	subs, _, err := m.svc.FindSubscriptions(ctx, influxdb.SubscriptionFilter{})
+	if err != nil {
+		if ctx.Err() == nil {
+			m.log.Error("Failed to list subscriptions", zap.Error(err))
+		}
+		return
+	}
+
+	// The subscribers are removed under the lock, but stopped after it is
+	// released, so that writes are not blocked while they stop.
+	var started []*influxdb.Subscription
+	var stopping []*subscriber
+	exists := make(map[influxdb.ID]bool, len(subs))
+	m.mu.Lock()
+	for _, sub := range subs {
+		exists[sub.ID] = true
+
+		s, ok := m.subscribers[sub.ID]
+		if ok && (sub.Status != influxdb.Active || !sub.UpdatedAt.Equal(s.sub.UpdatedAt)) {
+			delete(m.subscribers, sub.ID)
+			stopping = append(stopping, s)
+			ok = false
+		}
+		if !ok && sub.Status == influxdb.Active {
+			started = append(started, sub)
+		}
+	}
+	for id, s := range m.subscribers {
+		if !exists[id] {
+			delete(m.subscribers, id)
+			stopping = append(stopping, s)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, s := range stopping {
+		s.stop()
+	}
+	for _, sub := range started {
+		if err := m.start(sub); err != nil {
+			m.log.Error("Failed to start subscription", zap.String("subscriptionID", sub.ID.String()), zap.Error(err))
+		}
+	}
+
+	// The queues of the subscriptions deleted, including while influxd was
+	// stopped, are removed.
+	fis, err := ioutil.ReadDir(m.dir)
+	if err != nil && !os.IsNotExist(err) {
+		m.log.Error("Failed to list subscription queues", zap.Error(err))
+		return
+	}
+	for _, fi := range fis {
+		id, err := influxdb.IDFromString(fi.Name())
+		if err != nil || exists[*id] {
+			continue
+		}
+		m.log.Info("Removing queue of deleted subscription", zap.String("subscriptionID", fi.Name()))
+		if err := os.RemoveAll(filepath.Join(m.dir, fi.Name())); err != nil {
+			m.log.Error("Failed to remove subscription queue", zap.String("subscriptionID", fi.Name()), zap.Error(err))
+		}
+		m.deleteMetrics(fi.Name())
+	}
+}
+
+// start starts the delivery of sub.
+func (m *Manager) start(sub *influxdb.Subscription) error {
+	q, err := durablequeue.Open(filepath.Join(m.dir, sub.ID.String()), m.maxQueueSize)
+	if err != nil {
+		return err
+	}
+	dest, err := newDestination(sub, m.MirrorToken)
+	if err != nil {
+		q.Close()
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &subscriber{
+		m:      m,
+		log:    m.log.With(zap.String("subscriptionID", sub.ID.String())),
+		sub:    sub,
+		id:     sub.ID.String(),
+		queue:  q,
+		dest:   dest,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	m.mu.Lock()
+	m.subscribers[sub.ID] = s
+	m.mu.Unlock()
+	m.queueBytes.WithLabelValues(s.id).Set(float64(q.Pending()))
+
+	go s.run(ctx)
+	s.log.Info("Started subscription", zap.String("destination", string(sub.Destination)))
+	return nil
+}
+
+func (m *Manager) stopAll() {
+	m.mu.Lock()
+	subscribers := m.subscribers
+	m.subscribers = make(map[influxdb.ID]*subscriber)
+	m.mu.Unlock()
+
+	for _, s := range subscribers {
+		s.stop()
+	}
+}
+
+func (m *Manager) deleteMetrics(id string) {
+	m.sent.DeleteLabelValues(id)
+	m.failed.DeleteLabelValues(id)
+	m.dropped.DeleteLabelValues(id)
+	m.queueBytes.DeleteLabelValues(id)
+	m.mirrorLag.DeleteLabelValues(id)
+}
+
+// mirroredBuckets returns the buckets of the points mirrored by the active
+// subscriptions.
+func (m *Manager) mirroredBuckets(points []models.Point) map[influxdb.ID]bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var mirrors []*influxdb.Subscription
+	for _, s := range m.subscribers {
+		if s.sub.Mirror {
+			mirrors = append(mirrors, s.sub)
+		}
+	}
+	if len(mirrors) == 0 {
+		return nil
+	}
+
+	var buckets map[influxdb.ID]bool
+	seen := make(map[influxdb.ID]bool)
+	for _, p := range points {
+		_, bucketID := tsdb.DecodeNameSlice(p.Name())
+		if seen[bucketID] {
+			continue
+		}
+		seen[bucketID] = true
+		for _, sub := range mirrors {
+			if sub.Matches(bucketID, "") {
+				if buckets == nil {
+					buckets = make(map[influxdb.ID]bool)
+				}
+				buckets[bucketID] = true
+				break
+			}
+		}
+	}
+	return buckets
+}
+
+// RecordWritten records the points written locally at written, to resolve
+// their conflicts with the points mirrored from other instances.
+func (m *Manager) RecordWritten(points []models.Point, written time.Time) {
+	buckets := m.mirroredBuckets(points)
+	if len(buckets) == 0 {
+		return
+	}
+	if n := m.mirror.record(points, buckets, written, m.MirrorWindow, m.MirrorMaxPoints); n > 0 {
+		m.mirrorDivergences.Add(float64(n))
+	}
+	m.mirrorPoints.Set(float64(m.mirror.len()))
+}
+
+// ResolveMirrored returns the points mirrored from another instance, where
+// they were written at written, without those that lose to a different
+// point written later at the same timestamp. Ties are broken by the values
+// of the points, so that both instances keep the same point.
+func (m *Manager) ResolveMirrored(points []models.Point, written time.Time) []models.Point {
+	buckets := m.mirroredBuckets(points)
+	if len(buckets) == 0 {
+		return points
+	}
+	kept, local, remote := m.mirror.resolve(points, buckets, written, m.MirrorWindow, m.MirrorMaxPoints)
+	m.mirrorPoints.Set(float64(m.mirror.len()))
+	if local > 0 {
+		m.mirrorConflicts.WithLabelValues("local").Add(float64(local))
+	}
+	if remote > 0 {
+		m.mirrorConflicts.WithLabelValues("remote").Add(float64(remote))
+	}
+	return kept
+}
+
+// Publish copies the points, written to the storage engine at written, to
+// the queues of the subscriptions of their buckets and measurements. Points
+// that do not fit in the queue of a subscription are dropped for it. The
+// points mirrored from another instance are not copied to the mirror
+// subscriptions, so that they are not sent back.
+func (m *Manager) Publish(points []models.Point, written time.Time, mirrored bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.subscribers) == 0 {
+		return
+	}
+
+	batches := make(map[*subscriber][]byte)
+	for _, p := range points {
+		_, bucketID := tsdb.DecodeNameSlice(p.Name())
+		measurement := p.Tags().Get(models.MeasurementTagKeyBytes)
+		var line []byte
+		for _, s := range m.subscribers {
+			if !s.sub.Matches(bucketID, string(measurement)) || (mirrored && s.sub.Mirror) {
+				continue
+			}
+			if line == nil {
+				var err error
+				if line, err = lineProtocol(p, measurement); err != nil {
+					m.log.Warn("Failed to copy point to subscriptions", zap.Error(err))
+					break
+				}
+			}
+			b, ok := batches[s]
+			if !ok && s.sub.Mirror {
+				b = appendMirrorTime(b, written)
+			}
+			batches[s] = append(b, line...)
+		}
+	}
+
+	for s, b := range batches {
+		dropped, err := s.queue.Append(b)
+		if err == durablequeue.ErrFull {
+			dropped += int64(len(b))
+		} else if err != nil {
+			s.log.Error("Failed to queue points of subscription", zap.Error(err))
+			dropped += int64(len(b))
+		}
+		if dropped > 0 {
+			m.dropped.WithLabelValues(s.id).Add(float64(dropped))
+		}
+		m.queueBytes.WithLabelValues(s.id).Set(float64(s.queue.Pending()))
+	}
+}
+
+// lineProtocol returns the line of the point p, written to the storage
+// engine, as it was written to its bucket.
+func lineProtocol(p models.Point, measurement []byte) ([]byte, error) {
+	fields, err := p.Fields()
+	if err != nil {
+		return nil, err
+	}
+	var tags models.Tags
+	for _, t := range p.Tags() {
+		if string(t.Key) == models.MeasurementTagKey || string(t.Key) == models.FieldKeyTagKey {
+			continue
+		}
+		tags = append(tags, t)
+	}
+	pt, err := models.NewPoint(string(measurement), tags, fields, p.Time())
+	if err != nil {
+		return nil, err
+	}
+	return append(pt.AppendString(nil), '\n'), nil
+}
+
+// subscriber sends the queued points of a subscription to its destination.
+type subscriber struct {
+	m      *Manager
+	log    *zap.Logger
+	sub    *influxdb.Subscription
+	id     string
+	queue  *durablequeue.Queue
+	dest   destination
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (s *subscriber) run(ctx context.Context) {
+	defer close(s.done)
+
+	retry := minRetryInterval
+	for {
+		b, err := s.queue.Peek()
+		if err == io.EOF {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.queue.Wait():
+				continue
+			}
+		}
+		if err == nil {
+			err = s.dest.send(ctx, b)
+			if ctx.Err() != nil {
+				return
+			}
+			if perr, ok := err.(*permanentError); ok {
+				s.log.Warn("Subscription destination rejected points", zap.Error(perr.err))
+				s.m.dropped.WithLabelValues(s.id).Add(float64(len(b)))
+				err = nil
+			} else if err == nil {
+				s.m.sent.WithLabelValues(s.id).Inc()
+				if written, _, ok := splitMirrorTime(b); ok {
+					s.m.mirrorLag.WithLabelValues(s.id).Set(time.Since(written).Seconds())
+				}
+			} else {
+				s.m.failed.WithLabelValues(s.id).Inc()
+			}
+		}
+		if err == nil {
+			err = s.queue.Advance()
+			s.m.queueBytes.WithLabelValues(s.id).Set(float64(s.queue.Pending()))
+		}
+		if err == nil {
+			retry = minRetryInterval
+			continue
+		}
+
+		s.log.Warn("Failed to send points to subscription destination", zap.Error(err), zap.Duration("retry", retry))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		if retry *= 2; retry > maxRetryInterval {
+			retry = maxRetryInterval
+		}
+	}
+}
+
+// stop stops the delivery of s. The batch it is sending stays in its queue,
+// to be sent once it is started again. It must be removed from the
+// subscribers of the manager first.
+func (s *subscriber) stop() {
+	s.cancel()
+	<-s.done
+	if err := s.dest.close(); err != nil {
+		s.log.Warn("Failed to close subscription destination", zap.Error(err))
+	}
+	if err := s.queue.Close(); err != nil {
+		s.log.Error("Failed to close subscription queue", zap.Error(err))
+	}
+	s.log.Info("Stopped subscription")
+}
@@ -0,0 +1,115 @@
+package subscription
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+var _ influxdb.SubscriptionService = (*AuthedService)(nil)
+
+// AuthedService authorizes subscriptions with the permissions on their
+// buckets, as they receive the points written to them: a subscription is
+// visible to those who can read all of its buckets, and managed by those
+// who can also write them.
+type AuthedService struct {
+	s influxdb.SubscriptionService
+}
+
+// NewAuthedService wraps s with subscription authorization.
+func NewAuthedService(s influxdb.SubscriptionService) *AuthedService {
+	return &AuthedService{s: s}
+}
+
+func authorizeRead(ctx context.Context, sub *influxdb.Subscription) error {
+	for _, id := range sub.BucketIDs {
+		if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, id, sub.OrgID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func authorizeWrite(ctx context.Context, sub *influxdb.Subscription) error {
+	if err := authorizeRead(ctx, sub); err != nil {
+		return err
+	}
+	for _, id := range sub.BucketIDs {
+		if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.BucketsResourceType, id, sub.OrgID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AuthedService) CreateSubscription(ctx context.Context, sub *influxdb.Subscription) error {
+	if err := authorizeWrite(ctx, sub); err != nil {
+		return err
+	}
+	return s.s.CreateSubscription(ctx, sub)
+}
+
+func (s *AuthedService) FindSubscriptionByID(ctx context.Context, id influxdb.ID) (*influxdb.Subscription, error) {
+	sub, err := s.s.FindSubscriptionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeRead(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *AuthedService) FindSubscriptions(ctx context.Context, filter influxdb.SubscriptionFilter, opt ...influxdb.FindOptions) ([]*influxdb.Subscription, int, error) {
+	subs, _, err := s.s.FindSubscriptions(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	authorized := subs[:0]
+	for _, sub := range subs {
+		err := authorizeRead(ctx, sub)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		authorized = append(authorized, sub)
+	}
+	return authorized, len(authorized), nil
+}
+
+// UpdateSubscription authorizes the buckets of the subscription before and
+// after the update, so that buckets cannot be added to it without their
+// permissions.
+func (s *AuthedService) UpdateSubscription(ctx context.Context, id influxdb.ID, upd influxdb.SubscriptionUpdate) (*influxdb.Subscription, error) {
+	sub, err := s.s.FindSubscriptionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWrite(ctx, sub); err != nil {
+		return nil, err
+	}
+	if upd.BucketIDs != nil {
+		updated := *sub
+		upd.Apply(&updated)
+		if err := authorizeWrite(ctx, &updated); err != nil {
+			return nil, err
+		}
+	}
+	return s.s.UpdateSubscription(ctx, id, upd)
+}
+
+func (s *AuthedService) DeleteSubscription(ctx context.Context, id influxdb.ID) error {
+	sub, err := s.s.FindSubscriptionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorizeWrite(ctx, sub); err != nil {
+		return err
+	}
+	return s.s.DeleteSubscription(ctx, id)
+}
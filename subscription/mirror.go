@@ -0,0 +1,197 @@
+package subscription
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+const (
+	// DefaultMirrorWindow is the default time during which the writes of
+	// the points of mirrored buckets are remembered to resolve conflicts.
+	DefaultMirrorWindow = time.Hour
+
+	// DefaultMirrorMaxPoints is the default maximum number of writes of the
+	// points of mirrored buckets remembered to resolve conflicts.
+	DefaultMirrorMaxPoints = 1000000
+
+	// mirrorTimeHeader is the request header of the write time of the
+	// batches of mirror subscriptions, http.HeaderMirrorTime.
+	mirrorTimeHeader = "X-Influxdb-Mirror-Time"
+)
+
+// mirrorTimePrefix starts the comment line prepended to the batches queued
+// for mirror subscriptions, which holds the time their points were written.
+var mirrorTimePrefix = []byte("#mirror-time ")
+
+// appendMirrorTime appends the comment line of the write time t to b.
+func appendMirrorTime(b []byte, t time.Time) []byte {
+	b = append(b, mirrorTimePrefix...)
+	b = t.UTC().AppendFormat(b, time.RFC3339Nano)
+	return append(b, '\n')
+}
+
+// splitMirrorTime returns the write time of a batch of a mirror subscription
+// and its line protocol, or false if the batch does not start with a write
+// time.
+func splitMirrorTime(b []byte) (time.Time, []byte, bool) {
+	if !bytes.HasPrefix(b, mirrorTimePrefix) {
+		return time.Time{}, b, false
+	}
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		return time.Time{}, b, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, string(b[len(mirrorTimePrefix):i]))
+	if err != nil {
+		return time.Time{}, b, false
+	}
+	return t, b[i+1:], true
+}
+
+// mirrorIndex remembers when the points of mirrored buckets were written,
+// by series, field and timestamp, so that the point written last wins
+// whichever instance receives it first. The points are forgotten once their
+// write is older than the window, or once more than a maximum of writes are
+// remembered, oldest first. The index is kept in memory: the conflicts with
+// the points written before a restart are resolved in favor of the point
+// received last.
+type mirrorIndex struct {
+	mu      sync.Mutex
+	entries map[string]mirrorEntry
+	// queue holds the writes of the entries in the order they were
+	// remembered, from head on.
+	queue []mirrorWrite
+	head  int
+}
+
+type mirrorWrite struct {
+	key     string
+	written int64
+}
+
+type mirrorEntry struct {
+	// written is the time the point was written at, in nanoseconds, and
+	// value the hash of its field, which breaks the ties between points
+	// written at the same time.
+	written int64
+	value   uint64
+}
+
+func newMirrorIndex() *mirrorIndex {
+	return &mirrorIndex{entries: make(map[string]mirrorEntry)}
+}
+
+// len returns the number of points remembered.
+func (x *mirrorIndex) len() int {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return len(x.entries)
+}
+
+// set remembers the write e of the point key.
+func (x *mirrorIndex) set(key string, e mirrorEntry) {
+	x.entries[key] = e
+	x.queue = append(x.queue, mirrorWrite{key: key, written: e.written})
+}
+
+// record records the points of the mirrored buckets written locally at
+// written, remembering at most max writes. It returns the number of points
+// that replaced a point mirrored from another instance that was written
+// later, which the other instance keeps: the clocks of the instances are
+// skewed, and they diverge until the point is written again.
+func (x *mirrorIndex) record(points []models.Point, buckets map[influxdb.ID]bool, written time.Time, window time.Duration, max int) (diverged int) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	defer x.prune(written, window, max)
+
+	for _, p := range points {
+		if !mirrored(p, buckets) {
+			continue
+		}
+		key, e := mirrorKey(p), mirrorEntry{written: written.UnixNano(), value: mirrorValue(p)}
+		if cur, ok := x.entries[key]; ok && cur.value != e.value && cur.written > e.written {
+			diverged++
+		}
+		x.set(key, e)
+	}
+	return diverged
+}
+
+// resolve returns the points mirrored from another instance, where they
+// were written at written, without the points of the mirrored buckets that
+// lose to the points written at the same timestamps, remembering at most
+// max writes. It also returns the number of conflicts resolved in favor of
+// the points written locally and of the mirrored points.
+func (x *mirrorIndex) resolve(points []models.Point, buckets map[influxdb.ID]bool, written time.Time, window time.Duration, max int) (kept []models.Point, local, remote int) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	defer x.prune(time.Now(), window, max)
+
+	kept = points[:0:0]
+	for _, p := range points {
+		if !mirrored(p, buckets) {
+			kept = append(kept, p)
+			continue
+		}
+		key, e := mirrorKey(p), mirrorEntry{written: written.UnixNano(), value: mirrorValue(p)}
+		if cur, ok := x.entries[key]; ok && cur.value != e.value {
+			if cur.written > e.written || (cur.written == e.written && cur.value > e.value) {
+				local++
+				continue
+			}
+			remote++
+		}
+		x.set(key, e)
+		kept = append(kept, p)
+	}
+	return kept, local, remote
+}
+
+// prune forgets the oldest writes remembered while they were written before
+// now minus window or more than max writes are remembered.
+func (x *mirrorIndex) prune(now time.Time, window time.Duration, max int) {
+	cutoff := now.Add(-window).UnixNano()
+	for x.head < len(x.queue) && (x.queue[x.head].written < cutoff || len(x.queue)-x.head > max) {
+		w := x.queue[x.head]
+		// The entry was written again if its write is not the one queued.
+		if e, ok := x.entries[w.key]; ok && e.written == w.written {
+			delete(x.entries, w.key)
+		}
+		x.queue[x.head] = mirrorWrite{}
+		x.head++
+	}
+	if x.head > len(x.queue)/2 {
+		x.queue = append(x.queue[:0], x.queue[x.head:]...)
+		x.head = 0
+	}
+}
+
+func mirrored(p models.Point, buckets map[influxdb.ID]bool) bool {
+	_, bucketID := tsdb.DecodeNameSlice(p.Name())
+	return buckets[bucketID]
+}
+
+// mirrorKey returns the key of the series, field and timestamp of p.
+func mirrorKey(p models.Point) string {
+	key := p.Key()
+	b := make([]byte, len(key)+8)
+	copy(b, key)
+	binary.BigEndian.PutUint64(b[len(key):], uint64(p.UnixNano()))
+	return string(b)
+}
+
+// mirrorValue returns the hash of the fields of p.
+func mirrorValue(p models.Point) uint64 {
+	h := fnv.New64a()
+	if fields, err := p.Fields(); err == nil {
+		_, _ = h.Write(fields.MarshalBinary())
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,156 @@
+package subscription
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+const (
+	mirrorOrgID    = influxdb.ID(0x100)
+	mirrorBucketID = influxdb.ID(0x200)
+	otherBucketID  = influxdb.ID(0x300)
+)
+
+func mirrorPoint(t *testing.T, bucketID influxdb.ID, value float64) models.Point {
+	t.Helper()
+	tags := models.NewTags(map[string]string{
+		models.MeasurementTagKey: "cpu",
+		models.FieldKeyTagKey:    "usage",
+		"host":                   "a",
+	})
+	p, err := models.NewPoint(tsdb.EncodeNameString(mirrorOrgID, bucketID), tags, models.Fields{"usage": value}, time.Unix(0, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestMirrorIndex_Resolve(t *testing.T) {
+	var (
+		buckets = map[influxdb.ID]bool{mirrorBucketID: true}
+		t0      = time.Now()
+	)
+
+	tests := []struct {
+		name       string
+		local      float64
+		remote     float64
+		remoteTime time.Time
+		kept       bool
+		conflicts  [2]int
+	}{
+		{name: "remote written later wins", local: 1, remote: 2, remoteTime: t0.Add(time.Second), kept: true, conflicts: [2]int{0, 1}},
+		{name: "local written later wins", local: 1, remote: 2, remoteTime: t0.Add(-time.Second), kept: false, conflicts: [2]int{1, 0}},
+		{name: "same value is no conflict", local: 1, remote: 1, remoteTime: t0.Add(-time.Second), kept: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := newMirrorIndex()
+			if n := x.record([]models.Point{mirrorPoint(t, mirrorBucketID, tt.local)}, buckets, t0, time.Hour, DefaultMirrorMaxPoints); n != 0 {
+				t.Fatalf("unexpected divergences %d", n)
+			}
+
+			points := []models.Point{mirrorPoint(t, mirrorBucketID, tt.remote), mirrorPoint(t, otherBucketID, tt.remote)}
+			kept, local, remote := x.resolve(points, buckets, tt.remoteTime, time.Hour, DefaultMirrorMaxPoints)
+			if [2]int{local, remote} != tt.conflicts {
+				t.Errorf("unexpected conflicts %d local, %d remote, want %v", local, remote, tt.conflicts)
+			}
+			want := 1
+			if tt.kept {
+				want = 2
+			}
+			if len(kept) != want {
+				t.Fatalf("expected %d points kept, got %d", want, len(kept))
+			}
+			if _, bucketID := tsdb.DecodeNameSlice(kept[len(kept)-1].Name()); bucketID != otherBucketID {
+				t.Errorf("expected the point of the bucket not mirrored to be kept")
+			}
+		})
+	}
+}
+
+func TestMirrorIndex_ResolveConverges(t *testing.T) {
+	// both instances write a different point at the same time, and keep the
+	// same one once they receive the point of the other.
+	var (
+		buckets = map[influxdb.ID]bool{mirrorBucketID: true}
+		written = time.Now()
+		a, b    = newMirrorIndex(), newMirrorIndex()
+		pa, pb  = mirrorPoint(t, mirrorBucketID, 1), mirrorPoint(t, mirrorBucketID, 2)
+	)
+	a.record([]models.Point{pa}, buckets, written, time.Hour, DefaultMirrorMaxPoints)
+	b.record([]models.Point{pb}, buckets, written, time.Hour, DefaultMirrorMaxPoints)
+
+	keptA, _, _ := a.resolve([]models.Point{pb}, buckets, written, time.Hour, DefaultMirrorMaxPoints)
+	keptB, _, _ := b.resolve([]models.Point{pa}, buckets, written, time.Hour, DefaultMirrorMaxPoints)
+	if len(keptA)+len(keptB) != 1 {
+		t.Fatalf("expected exactly one instance to keep the point of the other, got %d and %d", len(keptA), len(keptB))
+	}
+	if a.entries[mirrorKey(pa)] != b.entries[mirrorKey(pb)] {
+		t.Errorf("the instances diverged: %+v and %+v", a.entries[mirrorKey(pa)], b.entries[mirrorKey(pb)])
+	}
+}
+
+func TestMirrorIndex_RecordDivergence(t *testing.T) {
+	buckets := map[influxdb.ID]bool{mirrorBucketID: true}
+	written := time.Now()
+	x := newMirrorIndex()
+	x.resolve([]models.Point{mirrorPoint(t, mirrorBucketID, 2)}, buckets, written.Add(time.Second), time.Hour, DefaultMirrorMaxPoints)
+	if n := x.record([]models.Point{mirrorPoint(t, mirrorBucketID, 1)}, buckets, written, time.Hour, DefaultMirrorMaxPoints); n != 1 {
+		t.Errorf("expected a divergence, got %d", n)
+	}
+}
+
+func TestMirrorIndex_Prune(t *testing.T) {
+	buckets := map[influxdb.ID]bool{mirrorBucketID: true}
+	written := time.Now()
+	x := newMirrorIndex()
+
+	points := make([]models.Point, 0, 3)
+	for i := 0; i < 3; i++ {
+		p, err := models.NewPoint(tsdb.EncodeNameString(mirrorOrgID, mirrorBucketID), nil, models.Fields{"usage": 1.0}, time.Unix(0, int64(i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		points = append(points, p)
+	}
+	x.record(points, buckets, written, time.Hour, 2)
+	if n := x.len(); n != 2 {
+		t.Fatalf("expected the writes remembered capped to 2, got %d", n)
+	}
+	if _, ok := x.entries[mirrorKey(points[0])]; ok {
+		t.Errorf("expected the oldest write forgotten")
+	}
+
+	// Writing a point again does not forget it with its previous write.
+	x.record(points[1:2], buckets, written.Add(time.Second), time.Hour, 2)
+	if _, ok := x.entries[mirrorKey(points[1])]; !ok {
+		t.Errorf("expected the point written again remembered")
+	}
+
+	x.record(nil, buckets, written.Add(3*time.Hour), time.Hour, 2)
+	if n := x.len(); n != 0 {
+		t.Errorf("expected the writes older than the window forgotten, got %d", n)
+	}
+}
+
+func TestSplitMirrorTime(t *testing.T) {
+	written := time.Date(2020, 6, 1, 12, 0, 0, 5, time.UTC)
+	b := appendMirrorTime(nil, written)
+	b = append(b, "cpu usage=1 10\n"...)
+
+	got, lines, ok := splitMirrorTime(b)
+	if !ok || !got.Equal(written) {
+		t.Fatalf("unexpected write time %v %v", got, ok)
+	}
+	if string(lines) != "cpu usage=1 10\n" {
+		t.Errorf("unexpected line protocol %q", lines)
+	}
+	if _, lines, ok := splitMirrorTime([]byte("cpu usage=1 10\n")); ok || string(lines) != "cpu usage=1 10\n" {
+		t.Errorf("expected a batch without write time to be sent unchanged")
+	}
+}
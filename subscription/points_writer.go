@@ -0,0 +1,51 @@
+package subscription
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+)
+
+var _ storage.PointsWriter = (*PointsWriter)(nil)
+
+// PointsWriter writes points with an underlying points writer and copies
+// them to the subscriptions of their buckets.
+type PointsWriter struct {
+	storage.PointsWriter
+	manager *Manager
+}
+
+// NewPointsWriter returns a PointsWriter writing points with pw and
+// publishing them to manager.
+func NewPointsWriter(pw storage.PointsWriter, manager *Manager) *PointsWriter {
+	return &PointsWriter{
+		PointsWriter: pw,
+		manager:      manager,
+	}
+}
+
+// WritePoints writes points and copies them to the subscriptions once they
+// are written, so that subscriptions only receive the points stored. The
+// points mirrored from another instance that lose to the points written
+// locally at the same timestamps are not written.
+func (w *PointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	written, mirrored := storage.MirrorWriteTimeFromContext(ctx)
+	if mirrored {
+		if points = w.manager.ResolveMirrored(points, written); len(points) == 0 {
+			return nil
+		}
+	} else {
+		written = time.Now()
+	}
+
+	if err := w.PointsWriter.WritePoints(ctx, points); err != nil {
+		return err
+	}
+	if !mirrored {
+		w.manager.RecordWritten(points, written)
+	}
+	w.manager.Publish(points, written, mirrored)
+	return nil
+}
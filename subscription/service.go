@@ -0,0 +1,385 @@
+// Package subscription copies the points written to buckets to external
+// systems: the write APIs of HTTP services, Kafka topics and NATS subjects.
+// A subscription selects the buckets and measurements whose points it
+// receives. The points are buffered on disk in the queue of the
+// subscription until its destination accepts them, so that processing
+// pipelines do not lose the writes made while they were unavailable.
+package subscription
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/snowflake"
+)
+
+var (
+	subscriptionBucket = []byte("subscriptionsv1")
+	indexBucket        = []byte("subscriptionindexv1")
+)
+
+var _ influxdb.SubscriptionService = (*Service)(nil)
+
+// BucketFinder finds the buckets of subscriptions.
+type BucketFinder interface {
+	FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error)
+}
+
+// Service stores subscriptions in a kv store.
+type Service struct {
+	store         kv.Store
+	buckets       BucketFinder
+	IDGen         influxdb.IDGenerator
+	TimeGenerator influxdb.TimeGenerator
+}
+
+// NewService constructs a subscription service backed by st. The buckets of
+// the subscriptions are checked to be buckets of their organization with
+// buckets.
+func NewService(st kv.Store, buckets BucketFinder) *Service {
+	return &Service{
+		store:         st,
+		buckets:       buckets,
+		IDGen:         snowflake.NewDefaultIDGenerator(),
+		TimeGenerator: influxdb.RealTimeGenerator{},
+	}
+}
+
+// CreateSubscription creates a subscription. Subscriptions without a status
+// are active.
+func (s *Service) CreateSubscription(ctx context.Context, sub *influxdb.Subscription) error {
+	if sub.Status == "" {
+		sub.Status = influxdb.Active
+	}
+	if err := sub.Valid(); err != nil {
+		return ErrInvalidSubscription(err)
+	}
+	if err := s.checkBuckets(ctx, sub); err != nil {
+		return err
+	}
+
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		if err := s.checkNameAvailable(tx, sub.OrgID, sub.Name); err != nil {
+			return err
+		}
+
+		now := s.TimeGenerator.Now()
+		sub.ID = s.IDGen.ID()
+		sub.CreatedAt = now
+		sub.UpdatedAt = now
+
+		if err := s.putSubscription(tx, sub); err != nil {
+			return err
+		}
+		return s.putIndex(tx, sub)
+	})
+}
+
+// FindSubscriptionByID returns a single subscription by ID.
+func (s *Service) FindSubscriptionByID(ctx context.Context, id influxdb.ID) (*influxdb.Subscription, error) {
+	var sub *influxdb.Subscription
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		sub, err = s.getSubscription(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// FindSubscriptions returns a list of subscriptions that match filter and
+// the total count of matching subscriptions, ordered by name.
+func (s *Service) FindSubscriptions(ctx context.Context, filter influxdb.SubscriptionFilter, opt ...influxdb.FindOptions) ([]*influxdb.Subscription, int, error) {
+	subs := []*influxdb.Subscription{}
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		if filter.OrgID != nil && filter.Name != nil {
+			sub, err := s.findByName(tx, *filter.OrgID, *filter.Name)
+			if err == ErrSubscriptionNotFound {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if filterFunc(sub, filter) {
+				subs = append(subs, sub)
+			}
+			return nil
+		}
+
+		b, err := tx.Bucket(subscriptionBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		return kv.WalkCursor(ctx, cur, func(k, v []byte) error {
+			sub := &influxdb.Subscription{}
+			if err := json.Unmarshal(v, sub); err != nil {
+				return ErrInternalService(err)
+			}
+			if filterFunc(sub, filter) {
+				subs = append(subs, sub)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.SliceStable(subs, func(i, j int) bool {
+		return subs[i].Name < subs[j].Name
+	})
+
+	total := len(subs)
+	if len(opt) > 0 {
+		subs = paginate(subs, opt[0])
+	}
+	return subs, total, nil
+}
+
+// UpdateSubscription updates a single subscription with changeset.
+func (s *Service) UpdateSubscription(ctx context.Context, id influxdb.ID, upd influxdb.SubscriptionUpdate) (*influxdb.Subscription, error) {
+	sub, err := s.FindSubscriptionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upd.BucketIDs != nil {
+		upd.Apply(sub)
+		if err := s.checkBuckets(ctx, sub); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.store.Update(ctx, func(tx kv.Tx) error {
+		var err error
+		sub, err = s.getSubscription(tx, id)
+		if err != nil {
+			return err
+		}
+		prevName := sub.Name
+
+		upd.Apply(sub)
+		if err := sub.Valid(); err != nil {
+			return ErrInvalidSubscription(err)
+		}
+
+		if sub.Name != prevName {
+			if err := s.checkNameAvailable(tx, sub.OrgID, sub.Name); err != nil {
+				return err
+			}
+			if err := s.deleteIndex(tx, sub.OrgID, prevName); err != nil {
+				return err
+			}
+			if err := s.putIndex(tx, sub); err != nil {
+				return err
+			}
+		}
+
+		sub.UpdatedAt = s.TimeGenerator.Now()
+		return s.putSubscription(tx, sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// DeleteSubscription removes a single subscription by ID.
+func (s *Service) DeleteSubscription(ctx context.Context, id influxdb.ID) error {
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		sub, err := s.getSubscription(tx, id)
+		if err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return ErrInvalidSubscriptionID
+		}
+		b, err := tx.Bucket(subscriptionBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		if err := b.Delete(encodedID); err != nil {
+			return ErrInternalService(err)
+		}
+		return s.deleteIndex(tx, sub.OrgID, sub.Name)
+	})
+}
+
+// checkBuckets returns an error if a bucket of sub is not a bucket of its
+// organization.
+func (s *Service) checkBuckets(ctx context.Context, sub *influxdb.Subscription) error {
+	for _, id := range sub.BucketIDs {
+		b, err := s.buckets.FindBucketByID(ctx, id)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound || (err == nil && b.OrgID != sub.OrgID) {
+			return ErrInvalidSubscription(fmt.Errorf("bucket %s is not a bucket of the organization", id))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) checkNameAvailable(tx kv.Tx, orgID influxdb.ID, name string) error {
+	_, err := s.findByName(tx, orgID, name)
+	if err == nil {
+		return ErrSubscriptionNameConflict
+	}
+	if err != ErrSubscriptionNotFound {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) findByName(tx kv.Tx, orgID influxdb.ID, name string) (*influxdb.Subscription, error) {
+	key, err := indexKey(orgID, name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := tx.Bucket(indexBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(key)
+	if kv.IsNotFound(err) {
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	var id influxdb.ID
+	if err := id.Decode(v); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return s.getSubscription(tx, id)
+}
+
+func (s *Service) getSubscription(tx kv.Tx, id influxdb.ID) (*influxdb.Subscription, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidSubscriptionID
+	}
+
+	b, err := tx.Bucket(subscriptionBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(encodedID)
+	if kv.IsNotFound(err) {
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+
+	sub := &influxdb.Subscription{}
+	if err := json.Unmarshal(v, sub); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return sub, nil
+}
+
+func (s *Service) putSubscription(tx kv.Tx, sub *influxdb.Subscription) error {
+	encodedID, err := sub.ID.Encode()
+	if err != nil {
+		return ErrInvalidSubscriptionID
+	}
+	v, err := json.Marshal(sub)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+
+	b, err := tx.Bucket(subscriptionBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(encodedID, v); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+func (s *Service) putIndex(tx kv.Tx, sub *influxdb.Subscription) error {
+	key, err := indexKey(sub.OrgID, sub.Name)
+	if err != nil {
+		return err
+	}
+	encodedID, err := sub.ID.Encode()
+	if err != nil {
+		return ErrInvalidSubscriptionID
+	}
+	b, err := tx.Bucket(indexBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(key, encodedID); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+func (s *Service) deleteIndex(tx kv.Tx, orgID influxdb.ID, name string) error {
+	key, err := indexKey(orgID, name)
+	if err != nil {
+		return err
+	}
+	b, err := tx.Bucket(indexBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Delete(key); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+// indexKey returns the key of the index of the subscription named name in
+// the organization.
+func indexKey(orgID influxdb.ID, name string) ([]byte, error) {
+	encodedOrgID, err := orgID.Encode()
+	if err != nil {
+		return nil, ErrInvalidSubscription(err)
+	}
+	return append(encodedOrgID, name...), nil
+}
+
+func filterFunc(sub *influxdb.Subscription, filter influxdb.SubscriptionFilter) bool {
+	if filter.BucketID != nil && !containsID(sub.BucketIDs, *filter.BucketID) {
+		return false
+	}
+	return (filter.OrgID == nil || sub.OrgID == *filter.OrgID) &&
+		(filter.Name == nil || sub.Name == *filter.Name)
+}
+
+func containsID(ids []influxdb.ID, id influxdb.ID) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func paginate(subs []*influxdb.Subscription, opt influxdb.FindOptions) []*influxdb.Subscription {
+	if opt.Offset > 0 {
+		if opt.Offset >= len(subs) {
+			return []*influxdb.Subscription{}
+		}
+		subs = subs[opt.Offset:]
+	}
+	if opt.Limit > 0 && opt.Limit < len(subs) {
+		subs = subs[:opt.Limit]
+	}
+	return subs
+}
@@ -0,0 +1,166 @@
+package subscription_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/subscription"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+// newTestService returns a subscription service whose buckets 10 and 11 are
+// buckets of the organization 1, and 20 a bucket of the organization 2.
+func newTestService(t *testing.T) (*subscription.Service, func()) {
+	t.Helper()
+
+	s, done := influxdbtesting.NewTestBoltStore(t)
+	buckets := influxdbtesting.NewBucketFinder(
+		&influxdb.Bucket{ID: 10, OrgID: 1},
+		&influxdb.Bucket{ID: 11, OrgID: 1},
+		&influxdb.Bucket{ID: 20, OrgID: 2},
+	)
+
+	svc := subscription.NewService(s, buckets)
+	svc.IDGen = mock.NewIncrementingIDGenerator(1)
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)}
+	return svc, done
+}
+
+func newTestSubscription(name string, bucketIDs ...influxdb.ID) *influxdb.Subscription {
+	return &influxdb.Subscription{
+		OrgID:       1,
+		Name:        name,
+		BucketIDs:   bucketIDs,
+		Destination: influxdb.SubscriptionDestinationHTTP,
+		URL:         "http://localhost:8086/api/v2/write?org=o&bucket=b",
+	}
+}
+
+func TestService_CreateSubscription(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	sub := newTestSubscription("pipeline", 10)
+	if err := svc.CreateSubscription(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+	if sub.ID != 1 || sub.Status != influxdb.Active {
+		t.Errorf("expected an active subscription with ID 1, got %s %q", sub.ID, sub.Status)
+	}
+
+	if err := svc.CreateSubscription(ctx, newTestSubscription("pipeline", 11)); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Errorf("expected a conflict on a duplicate name, got %v", err)
+	}
+	if err := svc.CreateSubscription(ctx, newTestSubscription("other", 20)); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected a bucket of another organization to be invalid, got %v", err)
+	}
+	if err := svc.CreateSubscription(ctx, newTestSubscription("missing", 30)); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected a missing bucket to be invalid, got %v", err)
+	}
+
+	kafka := newTestSubscription("kafka", 10)
+	kafka.Destination = influxdb.SubscriptionDestinationKafka
+	if err := svc.CreateSubscription(ctx, kafka); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected a kafka subscription without brokers to be invalid, got %v", err)
+	}
+}
+
+func TestService_FindSubscriptions(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	for _, sub := range []*influxdb.Subscription{
+		newTestSubscription("b", 10),
+		newTestSubscription("a", 10, 11),
+	} {
+		if err := svc.CreateSubscription(ctx, sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bucketID := influxdb.ID(11)
+	subs, n, err := svc.FindSubscriptions(ctx, influxdb.SubscriptionFilter{BucketID: &bucketID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || subs[0].Name != "a" {
+		t.Errorf("expected subscription a of bucket 11, got %d subscriptions", n)
+	}
+
+	subs, n, err = svc.FindSubscriptions(ctx, influxdb.SubscriptionFilter{}, influxdb.FindOptions{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || len(subs) != 1 || subs[0].Name != "a" {
+		t.Errorf("expected the first of 2 subscriptions by name, got %d of %d", len(subs), n)
+	}
+}
+
+func TestService_UpdateSubscription(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	sub := newTestSubscription("pipeline", 10)
+	if err := svc.CreateSubscription(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+
+	name, status := "renamed", influxdb.Inactive
+	updated, err := svc.UpdateSubscription(ctx, sub.ID, influxdb.SubscriptionUpdate{Name: &name, Status: &status})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != name || updated.Status != status {
+		t.Errorf("unexpected subscription %q %q", updated.Name, updated.Status)
+	}
+	orgID := influxdb.ID(1)
+	if subs, _, err := svc.FindSubscriptions(ctx, influxdb.SubscriptionFilter{OrgID: &orgID, Name: &name}); err != nil || len(subs) != 1 {
+		t.Errorf("expected to find the subscription by its new name, got %d, %v", len(subs), err)
+	}
+
+	bucketIDs := []influxdb.ID{20}
+	if _, err := svc.UpdateSubscription(ctx, sub.ID, influxdb.SubscriptionUpdate{BucketIDs: &bucketIDs}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected a bucket of another organization to be invalid, got %v", err)
+	}
+}
+
+func TestService_DeleteSubscription(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	sub := newTestSubscription("pipeline", 10)
+	if err := svc.CreateSubscription(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DeleteSubscription(ctx, sub.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindSubscriptionByID(ctx, sub.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected the subscription to be deleted, got %v", err)
+	}
+	// The name is available again.
+	if err := svc.CreateSubscription(ctx, newTestSubscription("pipeline", 10)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestService_NotFound(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	name := "pipeline"
+	if _, err := svc.UpdateSubscription(ctx, 99, influxdb.SubscriptionUpdate{Name: &name}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error updating a missing subscription, got %v", err)
+	}
+	if err := svc.DeleteSubscription(ctx, 99); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error deleting a missing subscription, got %v", err)
+	}
+}
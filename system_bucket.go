@@ -0,0 +1,157 @@
+package influxdb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SystemBucketRole is the purpose of a system bucket.
+type SystemBucketRole string
+
+const (
+	// SystemBucketRoleTasks is the role of the bucket of the logs of task runs.
+	SystemBucketRoleTasks SystemBucketRole = "tasks"
+	// SystemBucketRoleMonitoring is the role of the bucket of the statuses
+	// of checks and the notifications sent.
+	SystemBucketRoleMonitoring SystemBucketRole = "monitoring"
+)
+
+// SystemBuckets are the IDs and names of the system buckets of a deployment.
+// The IDs are those of the system buckets served to organizations that have
+// none stored, and the names those system buckets are created and looked up
+// with. Operators relocate and rename them so that restores into existing
+// instances do not collide with their system buckets. They are given to the
+// services that serve, create or look up the system buckets.
+type SystemBuckets struct {
+	TasksID        ID
+	TasksName      string
+	MonitoringID   ID
+	MonitoringName string
+
+	// Redirects maps the IDs buckets were relocated from to their current
+	// IDs, so that the references to the old IDs keep working.
+	Redirects map[ID]ID
+}
+
+// DefaultSystemBuckets returns the built-in IDs and names of the system
+// buckets.
+func DefaultSystemBuckets() SystemBuckets {
+	return SystemBuckets{
+		TasksID:        TasksSystemBucketID,
+		TasksName:      TasksSystemBucketName,
+		MonitoringID:   MonitoringSystemBucketID,
+		MonitoringName: MonitoringSystemBucketName,
+	}
+}
+
+// Valid returns an error if the IDs or names of the system buckets are
+// invalid or collide, or if a redirect is chained.
+func (s SystemBuckets) Valid() error {
+	if !s.TasksID.Valid() || !s.MonitoringID.Valid() {
+		return fmt.Errorf("system bucket IDs must be valid")
+	}
+	if s.TasksID == s.MonitoringID {
+		return fmt.Errorf("system buckets must have distinct IDs, got %s twice", s.TasksID)
+	}
+	for _, name := range []string{s.TasksName, s.MonitoringName} {
+		// Names starting with an underscore are reserved for system buckets.
+		if !strings.HasPrefix(name, "_") || strings.Contains(name, "\"") {
+			return fmt.Errorf("system bucket name %q must start with an underscore and not include quotation marks", name)
+		}
+	}
+	if s.TasksName == s.MonitoringName {
+		return fmt.Errorf("system buckets must have distinct names, got %q twice", s.TasksName)
+	}
+	if s.TasksName == MonitoringSystemBucketName || s.MonitoringName == TasksSystemBucketName {
+		return fmt.Errorf("system buckets cannot be renamed to the built-in name of another system bucket")
+	}
+	for from, to := range s.Redirects {
+		if !from.Valid() || !to.Valid() {
+			return fmt.Errorf("system bucket redirects must map valid IDs")
+		}
+		if from == s.TasksID || from == s.MonitoringID {
+			return fmt.Errorf("system bucket %s cannot be redirected", from)
+		}
+		if _, ok := s.Redirects[to]; ok {
+			return fmt.Errorf("system bucket redirect of %s to %s is chained", from, to)
+		}
+	}
+	return nil
+}
+
+// RedirectID returns the ID the bucket with id was relocated to, or id if it
+// was not relocated.
+func (s SystemBuckets) RedirectID(id ID) ID {
+	if to, ok := s.Redirects[id]; ok {
+		return to
+	}
+	return id
+}
+
+// IsSystemBucketID reports whether id is the ID of a system bucket served to
+// organizations that have none stored, or an ID it was relocated from.
+func (s SystemBuckets) IsSystemBucketID(id ID) bool {
+	id = s.RedirectID(id)
+	return id == s.TasksID || id == s.MonitoringID
+}
+
+// NameAlias returns the other name a renamed system bucket is found by: its
+// built-in name for its current name and the other way around. Buckets
+// stored before the rename, and scripts referencing the built-in names,
+// keep working.
+func (s SystemBuckets) NameAlias(name string) (string, bool) {
+	for _, names := range [][2]string{
+		{TasksSystemBucketName, s.TasksName},
+		{MonitoringSystemBucketName, s.MonitoringName},
+	} {
+		if names[0] == names[1] {
+			continue
+		}
+		switch name {
+		case names[0]:
+			return names[1], true
+		case names[1]:
+			return names[0], true
+		}
+	}
+	return "", false
+}
+
+// TasksBucket returns the tasks system bucket served to an organization that
+// has none stored.
+func (s SystemBuckets) TasksBucket(orgID ID) *Bucket {
+	return &Bucket{
+		ID:              s.TasksID,
+		Type:            BucketTypeSystem,
+		Name:            s.TasksName,
+		RetentionPeriod: TasksSystemBucketRetention,
+		Description:     "System bucket for task logs",
+		OrgID:           orgID,
+	}
+}
+
+// MonitoringBucket returns the monitoring system bucket served to an
+// organization that has none stored.
+func (s SystemBuckets) MonitoringBucket(orgID ID) *Bucket {
+	return &Bucket{
+		ID:              s.MonitoringID,
+		Type:            BucketTypeSystem,
+		Name:            s.MonitoringName,
+		RetentionPeriod: MonitoringSystemBucketRetention,
+		Description:     "System bucket for monitoring logs",
+		OrgID:           orgID,
+	}
+}
+
+// BucketByName returns the system bucket served to an organization that has
+// none stored under name, which may be the current or built-in name of a
+// system bucket.
+func (s SystemBuckets) BucketByName(orgID ID, name string) (*Bucket, bool) {
+	switch name {
+	case s.TasksName, TasksSystemBucketName:
+		return s.TasksBucket(orgID), true
+	case s.MonitoringName, MonitoringSystemBucketName:
+		return s.MonitoringBucket(orgID), true
+	}
+	return nil, false
+}
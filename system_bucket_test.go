@@ -0,0 +1,97 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestSystemBuckets_Valid(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		update func(*influxdb.SystemBuckets)
+		valid  bool
+	}{
+		{
+			name:   "default",
+			update: func(*influxdb.SystemBuckets) {},
+			valid:  true,
+		},
+		{
+			name: "relocated",
+			update: func(sb *influxdb.SystemBuckets) {
+				sb.TasksID = 0x100
+				sb.Redirects = map[influxdb.ID]influxdb.ID{influxdb.TasksSystemBucketID: 0x100}
+			},
+			valid: true,
+		},
+		{
+			name: "same IDs",
+			update: func(sb *influxdb.SystemBuckets) {
+				sb.TasksID = sb.MonitoringID
+			},
+		},
+		{
+			name: "user bucket name",
+			update: func(sb *influxdb.SystemBuckets) {
+				sb.TasksName = "tasks"
+			},
+		},
+		{
+			name: "built-in name of another system bucket",
+			update: func(sb *influxdb.SystemBuckets) {
+				sb.TasksName = influxdb.MonitoringSystemBucketName
+				sb.MonitoringName = "_sys_monitoring"
+			},
+		},
+		{
+			name: "redirected system bucket",
+			update: func(sb *influxdb.SystemBuckets) {
+				sb.Redirects = map[influxdb.ID]influxdb.ID{sb.TasksID: 0x100}
+			},
+		},
+		{
+			name: "chained redirects",
+			update: func(sb *influxdb.SystemBuckets) {
+				sb.Redirects = map[influxdb.ID]influxdb.ID{0x100: 0x200, 0x200: 0x300}
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := influxdb.DefaultSystemBuckets()
+			tt.update(&sb)
+			if err := sb.Valid(); (err == nil) != tt.valid {
+				t.Errorf("expected valid %t, got %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestSystemBuckets_Lookup(t *testing.T) {
+	sb := influxdb.DefaultSystemBuckets()
+	sb.MonitoringID = 0x100
+	sb.MonitoringName = "_sys_monitoring"
+	sb.Redirects = map[influxdb.ID]influxdb.ID{influxdb.MonitoringSystemBucketID: 0x100}
+
+	if id := sb.RedirectID(influxdb.MonitoringSystemBucketID); id != 0x100 {
+		t.Errorf("expected the default ID to redirect, got %s", id)
+	}
+	if !sb.IsSystemBucketID(influxdb.MonitoringSystemBucketID) || !sb.IsSystemBucketID(0x100) || sb.IsSystemBucketID(0x200) {
+		t.Error("unexpected system bucket IDs")
+	}
+
+	if alias, ok := sb.NameAlias(influxdb.MonitoringSystemBucketName); !ok || alias != "_sys_monitoring" {
+		t.Errorf("unexpected alias of the default name %q", alias)
+	}
+	if alias, ok := sb.NameAlias("_sys_monitoring"); !ok || alias != influxdb.MonitoringSystemBucketName {
+		t.Errorf("unexpected alias of the new name %q", alias)
+	}
+	if _, ok := sb.NameAlias(influxdb.TasksSystemBucketName); ok {
+		t.Error("expected the tasks bucket not to be renamed")
+	}
+
+	b, ok := sb.BucketByName(1, influxdb.MonitoringSystemBucketName)
+	if !ok || b.ID != 0x100 || b.Name != "_sys_monitoring" || b.OrgID != 1 {
+		t.Errorf("unexpected bucket %+v", b)
+	}
+}
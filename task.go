@@ -149,6 +149,32 @@ type TaskService interface {
 	ForceRun(ctx context.Context, taskID ID, scheduledFor int64) (*Run, error)
 }
 
+// TaskSLA is the scheduling service level of a task, telling whether its runs,
+// and so the alerts of a check, start late because the scheduler or the
+// executor is saturated.
+type TaskSLA struct {
+	TaskID           ID
+	LastScheduledFor time.Time
+	// Runs is the number of runs dispatched since the task was scheduled.
+	Runs int64
+	// Lateness is the duration between the time the last run was due and the
+	// time it was dispatched to the executor, and MaxLateness the longest one.
+	Lateness    time.Duration
+	MaxLateness time.Duration
+	// MissedIntervals is the number of intervals that were already due when
+	// a previous run was dispatched.
+	MissedIntervals int64
+	// QueueWait is the duration the last run waited in the queue of the
+	// executor before starting.
+	QueueWait time.Duration
+}
+
+// TaskSLAService reports the scheduling service level of tasks.
+type TaskSLAService interface {
+	// FindTaskSLA returns the scheduling service level of the task id.
+	FindTaskSLA(ctx context.Context, id ID) (*TaskSLA, error)
+}
+
 // TaskCreate is the set of values to create a task.
 type TaskCreate struct {
 	Type           string                 `json:"type,omitempty"`
@@ -185,6 +211,7 @@ type TaskUpdate struct {
 	LastRunStatus   *string                `json:"-"`
 	LastRunError    *string                `json:"-"`
 	Metadata        map[string]interface{} `json:"-"` // not to be set through a web request but rather used by a http service using tasks backend.
+	AuthorizationID *ID                    `json:"-"` // the service token of the task, set by the task service.
 
 	// Options gets unmarshalled from json as if it was flat, with the same level as Flux and Status.
 	Options options.Options // when we unmarshal this gets unmarshalled from flat key-values
@@ -471,6 +498,84 @@ type LogFilter struct {
 
 	// The optional Run ID limits logs to a single run.
 	Run *ID
+
+	// Offset and Limit page the logs; all the logs are returned when Limit
+	// is 0.
+	Offset int
+	Limit  int
+}
+
+// Page returns the page of logs of the filter.
+func (f LogFilter) Page(logs []*Log) []*Log {
+	if f.Offset >= len(logs) {
+		return []*Log{}
+	}
+	if f.Offset > 0 {
+		logs = logs[f.Offset:]
+	}
+	if f.Limit > 0 && f.Limit < len(logs) {
+		logs = logs[:f.Limit]
+	}
+	return logs
+}
+
+// TaskRunLogSettings are the settings of the run logs of the tasks of an
+// organization.
+type TaskRunLogSettings struct {
+	OrgID ID `json:"orgID"`
+	// Retention is how long the logs of the runs in progress are kept in
+	// the store; they are kept until their run finishes when it is 0. The
+	// logs of finished runs are kept in the tasks system bucket for its
+	// retention period.
+	Retention time.Duration `json:"retention"`
+	// ExportBucketID is the bucket the logs of finished runs, and the
+	// logs removed past the retention, are written to as points, if set.
+	ExportBucketID *ID `json:"exportBucketID,omitempty"`
+	CRUDLog
+}
+
+// Valid returns an error if the settings are missing their organization or
+// have a negative retention.
+func (s *TaskRunLogSettings) Valid() error {
+	if !s.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "orgID is required",
+		}
+	}
+	if s.Retention < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "retention must not be negative",
+		}
+	}
+	if s.ExportBucketID != nil && !s.ExportBucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "exportBucketID is invalid",
+		}
+	}
+	return nil
+}
+
+// TaskRunLogSettingsService manages the run log settings of organizations.
+type TaskRunLogSettingsService interface {
+	// FindTaskRunLogSettings returns the run log settings of the
+	// organization; an organization without settings keeps the logs
+	// without exporting them.
+	FindTaskRunLogSettings(ctx context.Context, orgID ID) (*TaskRunLogSettings, error)
+
+	// PutTaskRunLogSettings replaces the run log settings of the
+	// organization.
+	PutTaskRunLogSettings(ctx context.Context, s *TaskRunLogSettings) error
+}
+
+// RunLogs are the logs of a run of a task of an organization.
+type RunLogs struct {
+	OrgID  ID
+	TaskID ID
+	RunID  ID
+	Logs   []Log
 }
 
 type TaskStatus string
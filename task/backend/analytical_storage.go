@@ -46,12 +46,13 @@ func NewAnalyticalRunStorage(log *zap.Logger, ts influxdb.TaskService, bs influx
 }
 
 // NewAnalyticalStorage creates a new analytical store with access to the necessary systems for storing data and to act as a middleware (deprecated)
-func NewAnalyticalStorage(log *zap.Logger, ts influxdb.TaskService, bs influxdb.BucketService, tcs TaskControlService, pw storage.PointsWriter, qs query.QueryService) *AnalyticalStorage {
+func NewAnalyticalStorage(log *zap.Logger, ts influxdb.TaskService, bs influxdb.BucketService, sb influxdb.SystemBuckets, tcs TaskControlService, pw storage.PointsWriter, qs query.QueryService) *AnalyticalStorage {
 	return &AnalyticalStorage{
 		log:                log,
 		TaskService:        ts,
 		BucketService:      bs,
 		TaskControlService: tcs,
+		sb:                 sb,
 		rr:                 NewStoragePointsWriterRecorder(log, pw),
 		qs:                 qs,
 	}
@@ -62,6 +63,7 @@ type AnalyticalStorage struct {
 	influxdb.BucketService
 	TaskControlService
 
+	sb  influxdb.SystemBuckets
 	rr  RunRecorder
 	qs  query.QueryService
 	log *zap.Logger
@@ -75,12 +77,12 @@ func (as *AnalyticalStorage) FinishRun(ctx context.Context, taskID, runID influx
 			return run, err
 		}
 
-		sb, err := as.BucketService.FindBucketByName(ctx, task.OrganizationID, influxdb.TasksSystemBucketName)
+		sb, err := as.BucketService.FindBucketByName(ctx, task.OrganizationID, as.sb.TasksName)
 		if err != nil {
 			return run, err
 		}
 
-		return run, as.rr.Record(ctx, task.OrganizationID, task.Organization, sb.ID, influxdb.TasksSystemBucketName, run)
+		return run, as.rr.Record(ctx, task.OrganizationID, task.Organization, sb.ID, sb.Name, run)
 	}
 
 	return run, err
@@ -98,6 +100,7 @@ func (as *AnalyticalStorage) FindLogs(ctx context.Context, filter influxdb.LogFi
 		for i := 0; i < len(run.Log); i++ {
 			logs = append(logs, &run.Log[i])
 		}
+		logs = filter.Page(logs)
 		return logs, len(logs), nil
 	}
 
@@ -112,6 +115,10 @@ func (as *AnalyticalStorage) FindLogs(ctx context.Context, filter influxdb.LogFi
 			logs = append(logs, &run.Log[i])
 		}
 	}
+	if filter.Offset > 0 || filter.Limit > 0 {
+		logs = filter.Page(logs)
+		n = len(logs)
+	}
 
 	return logs, n, err
 }
@@ -142,7 +149,7 @@ func (as *AnalyticalStorage) FindRuns(ctx context.Context, filter influxdb.RunFi
 		return runs, n, err
 	}
 
-	sb, err := as.BucketService.FindBucketByName(ctx, task.OrganizationID, influxdb.TasksSystemBucketName)
+	sb, err := as.BucketService.FindBucketByName(ctx, task.OrganizationID, as.sb.TasksName)
 	if err != nil {
 		return runs, n, err
 	}
@@ -246,7 +253,7 @@ func (as *AnalyticalStorage) FindRunByID(ctx context.Context, taskID, runID infl
 		return run, err
 	}
 
-	sb, err := as.BucketService.FindBucketByName(ctx, task.OrganizationID, influxdb.TasksSystemBucketName)
+	sb, err := as.BucketService.FindBucketByName(ctx, task.OrganizationID, as.sb.TasksName)
 	if err != nil {
 		return run, err
 	}
@@ -113,7 +113,7 @@ func TestDeduplicateRuns(t *testing.T) {
 	}
 	mockBS := mock.NewBucketService()
 
-	svcStack := backend.NewAnalyticalStorage(zaptest.NewLogger(t), mockTS, mockBS, mockTCS, ab.PointsWriter(), ab.QueryService())
+	svcStack := backend.NewAnalyticalStorage(zaptest.NewLogger(t), mockTS, mockBS, influxdb.DefaultSystemBuckets(), mockTCS, ab.PointsWriter(), ab.QueryService())
 
 	_, err := svcStack.FinishRun(context.Background(), 1, 2)
 	if err != nil {
@@ -10,12 +10,14 @@ import (
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/flux/runtime"
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
 	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kit/feature"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/query"
 	"github.com/influxdata/influxdb/v2/task/backend"
 	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/opentracing/opentracing-go"
 	"go.uber.org/zap"
 )
 
@@ -52,11 +54,23 @@ func MultiLimit(limits ...LimitFunc) LimitFunc {
 // LimitFunc is a function the executor will use to
 type LimitFunc func(*influxdb.Task, *influxdb.Run) error
 
+// RunDispatcher executes the queries of runs apart from the executor, such as
+// on remote workers.
+type RunDispatcher interface {
+	// Dispatch executes the query of the run with the permissions of auth,
+	// and blocks until its execution is finished. It returns the logs of the
+	// run, even if its execution failed.
+	Dispatch(ctx context.Context, run *influxdb.Run, task *influxdb.Task, auth *influxdb.Authorization) ([]string, error)
+}
+
 type executorConfig struct {
 	maxWorkers             int
 	systemBuildCompiler    CompilerBuilderFunc
 	nonSystemBuildCompiler CompilerBuilderFunc
 	flagger                feature.Flagger
+	dispatcher             RunDispatcher
+	sla                    *scheduler.SLATracker
+	policyEngine           authorizer.PolicyEngine
 }
 
 type executorOption func(*executorConfig)
@@ -96,6 +110,31 @@ func WithFlagger(flagger feature.Flagger) executorOption {
 	}
 }
 
+// WithRunDispatcher is an Executor option that dispatches the runs of tasks,
+// but not of checks and notification rules, to d instead of executing their
+// queries.
+func WithRunDispatcher(d RunDispatcher) executorOption {
+	return func(o *executorConfig) {
+		o.dispatcher = d
+	}
+}
+
+// WithSLATracker is an Executor option that records the time the runs of
+// tasks wait in the queue of the executor to sla.
+func WithSLATracker(sla *scheduler.SLATracker) executorOption {
+	return func(o *executorConfig) {
+		o.sla = sla
+	}
+}
+
+// WithPolicyEngine is an Executor option that delegates the permission
+// checks of the runs of tasks to e.
+func WithPolicyEngine(e authorizer.PolicyEngine) executorOption {
+	return func(o *executorConfig) {
+		o.policyEngine = e
+	}
+}
+
 // NewExecutor creates a new task executor
 func NewExecutor(log *zap.Logger, qs query.QueryService, us PermissionService, ts influxdb.TaskService, tcs backend.TaskControlService, opts ...executorOption) (*Executor, *ExecutorMetrics) {
 	cfg := &executorConfig{
@@ -121,6 +160,9 @@ func NewExecutor(log *zap.Logger, qs query.QueryService, us PermissionService, t
 		systemBuildCompiler:    cfg.systemBuildCompiler,
 		nonSystemBuildCompiler: cfg.nonSystemBuildCompiler,
 		flagger:                cfg.flagger,
+		dispatcher:             cfg.dispatcher,
+		sla:                    cfg.sla,
+		policyEngine:           cfg.policyEngine,
 	}
 
 	e.metrics = NewExecutorMetrics(e)
@@ -159,6 +201,9 @@ type Executor struct {
 	nonSystemBuildCompiler CompilerBuilderFunc
 	systemBuildCompiler    CompilerBuilderFunc
 	flagger                feature.Flagger
+	dispatcher             RunDispatcher
+	sla                    *scheduler.SLATracker
+	policyEngine           authorizer.PolicyEngine
 }
 
 // SetLimitFunc sets the limit func for this task executor
@@ -292,8 +337,14 @@ func (e *Executor) createPromise(ctx context.Context, run *influxdb.Run) (*promi
 		return nil, err
 	}
 
+	// Tasks with a service token always run with the permissions of the
+	// token, and not at all once it is deleted or deactivated.
+	if t.AuthorizationID.Valid() && (t.Authorization == nil || !t.Authorization.IsActive()) {
+		return nil, influxdb.ErrTaskTokenInactive
+	}
+
 	var perm influxdb.PermissionSet
-	if e.flagger != nil && feature.UseUserPermission().Enabled(ctx, e.flagger) {
+	if !t.AuthorizationID.Valid() && e.flagger != nil && feature.UseUserPermission().Enabled(ctx, e.flagger) {
 		perm, err = e.ps.FindPermissionForUser(ctx, t.OwnerID)
 		if err != nil {
 			return nil, err
@@ -303,6 +354,10 @@ func (e *Executor) createPromise(ctx context.Context, run *influxdb.Run) (*promi
 	if perm == nil {
 		perm = t.Authorization.Permissions
 	}
+	var rowFilters []influxdb.TagRule
+	if t.Authorization != nil {
+		rowFilters = t.Authorization.RowFilters
+	}
 
 	ctx, cancel := context.WithCancel(ctx)
 	// create promise
@@ -315,6 +370,7 @@ func (e *Executor) createPromise(ctx context.Context, run *influxdb.Run) (*promi
 			ID:          influxdb.ID(1),
 			OrgID:       t.OrganizationID,
 			Permissions: perm,
+			RowFilters:  rowFilters,
 		},
 		createdAt:  time.Now().UTC(),
 		done:       make(chan struct{}),
@@ -418,7 +474,11 @@ func (w *worker) start(p *promise) {
 	w.e.tcs.UpdateRunState(ctx, p.task.ID, p.run.ID, time.Now().UTC(), influxdb.RunStarted)
 
 	// add to metrics
-	w.e.metrics.StartRun(p.task, time.Since(p.createdAt), time.Since(p.run.RunAt))
+	queueWait := time.Since(p.createdAt)
+	w.e.metrics.StartRun(p.task, queueWait, time.Since(p.run.RunAt))
+	if w.e.sla != nil {
+		w.e.sla.ObserveQueueWait(scheduler.ID(p.task.ID), queueWait)
+	}
 	p.startedAt = time.Now()
 }
 
@@ -472,7 +532,12 @@ func (w *worker) executeQuery(p *promise) {
 	// start
 	w.start(p)
 
-	ctx = icontext.SetAuthorizer(ctx, p.auth)
+	ctx = authorizer.WithPolicyEngine(icontext.SetAuthorizer(ctx, p.auth), w.e.policyEngine)
+
+	if w.e.dispatcher != nil && isSystemTask(p.task) {
+		w.dispatchQuery(ctx, p, span)
+		return
+	}
 
 	buildCompiler := w.systemBuildCompiler
 	if p.task.Type != influxdb.TaskSystemType {
@@ -509,11 +574,7 @@ func (w *worker) executeQuery(p *promise) {
 
 	it.Release()
 
-	// log the trace id and whether or not it was sampled into the run log
-	if traceID, isSampled, ok := tracing.InfoFromSpan(span); ok {
-		msg := fmt.Sprintf("trace_id=%s is_sampled=%t", traceID, isSampled)
-		w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), msg)
-	}
+	w.logTraceID(p, span)
 
 	if runErr != nil {
 		w.finish(p, influxdb.RunFail, influxdb.ErrRunExecutionError(runErr))
@@ -528,6 +589,41 @@ func (w *worker) executeQuery(p *promise) {
 	w.finish(p, influxdb.RunSuccess, nil)
 }
 
+// dispatchQuery executes the query of the run with the dispatcher of the
+// executor, keeping the logs of the run reported by the dispatcher.
+func (w *worker) dispatchQuery(ctx context.Context, p *promise, span opentracing.Span) {
+	logs, err := w.e.dispatcher.Dispatch(ctx, p.run, p.task, p.auth)
+	for _, msg := range logs {
+		w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), msg)
+	}
+	w.logTraceID(p, span)
+
+	if err != nil {
+		// The errors of the dispatcher already describe the failed run.
+		if _, ok := err.(*influxdb.Error); !ok {
+			err = influxdb.ErrRunExecutionError(err)
+		}
+		w.finish(p, influxdb.RunFail, err)
+		return
+	}
+	w.finish(p, influxdb.RunSuccess, nil)
+}
+
+// logTraceID logs the trace id of span and whether or not it was sampled
+// into the run log.
+func (w *worker) logTraceID(p *promise, span opentracing.Span) {
+	if traceID, isSampled, ok := tracing.InfoFromSpan(span); ok {
+		msg := fmt.Sprintf("trace_id=%s is_sampled=%t", traceID, isSampled)
+		w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), msg)
+	}
+}
+
+// isSystemTask reports whether t is a system task; tasks created without a
+// type are system tasks.
+func isSystemTask(t *influxdb.Task) bool {
+	return t.Type == "" || t.Type == influxdb.TaskSystemType
+}
+
 // RunsActive returns the current number of workers, which is equivalent to
 // the number of runs actively running
 func (e *Executor) RunsActive() int {
@@ -0,0 +1,308 @@
+// Package remote executes task runs on worker processes apart from influxd.
+//
+// The Dispatcher queues the runs of the executor until a worker claims them
+// over the internal API served by Handler. Each worker sends heartbeats while
+// it executes runs; the runs of a worker that stops sending them are
+// reassigned to other workers. Workers execute the queries of the runs with a
+// token created for each run with the permissions of its task.
+package remote
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultHeartbeatTimeout is the time after which a worker that has not
+	// sent a heartbeat is considered lost.
+	DefaultHeartbeatTimeout = 30 * time.Second
+
+	// DefaultMaxAttempts is the number of workers a run is assigned to before
+	// it fails because of lost workers.
+	DefaultMaxAttempts = 3
+)
+
+// Job is a run assigned to a worker.
+type Job struct {
+	RunID        influxdb.ID `json:"runID"`
+	TaskID       influxdb.ID `json:"taskID"`
+	OrgID        influxdb.ID `json:"orgID"`
+	Flux         string      `json:"flux"`
+	ScheduledFor time.Time   `json:"scheduledFor"`
+	// Token is the token the query of the run is executed with.
+	Token string `json:"token"`
+}
+
+// Result is the result of a run reported by the worker that executed it.
+type Result struct {
+	// Error is the error the run failed with, empty if it succeeded.
+	Error string `json:"error,omitempty"`
+	// Logs are the run logs of its execution on the worker.
+	Logs []string `json:"logs,omitempty"`
+}
+
+// job is a dispatched run, awaiting or under execution.
+type job struct {
+	Job
+
+	worker   string // the worker executing it, empty while it awaits one
+	attempts int
+
+	done chan struct{}
+	logs []string
+	err  error
+}
+
+func (j *job) finish(logs []string, err error) {
+	j.logs = logs
+	j.err = err
+	close(j.done)
+}
+
+// Option configures a Dispatcher.
+type Option func(*Dispatcher)
+
+// WithHeartbeatTimeout sets the time after which a worker that has not sent a
+// heartbeat is considered lost.
+func WithHeartbeatTimeout(d time.Duration) Option {
+	return func(s *Dispatcher) {
+		s.heartbeatTimeout = d
+	}
+}
+
+// WithMaxAttempts sets the number of workers a run is assigned to before it
+// fails because of lost workers.
+func WithMaxAttempts(n int) Option {
+	return func(s *Dispatcher) {
+		s.maxAttempts = n
+	}
+}
+
+// WithTimeGenerator sets the generator of the time heartbeats are received at.
+func WithTimeGenerator(gen influxdb.TimeGenerator) Option {
+	return func(s *Dispatcher) {
+		s.timeGenerator = gen
+	}
+}
+
+// Dispatcher dispatches runs to remote workers.
+type Dispatcher struct {
+	log     *zap.Logger
+	authSvc influxdb.AuthorizationService
+
+	heartbeatTimeout time.Duration
+	maxAttempts      int
+	timeGenerator    influxdb.TimeGenerator
+
+	mu      sync.Mutex
+	queue   []*job               // jobs awaiting a worker, in order
+	jobs    map[influxdb.ID]*job // all jobs by run ID
+	workers map[string]time.Time // time of the last heartbeat by worker
+	notify  chan struct{}        // closed when a job is queued
+}
+
+// NewDispatcher constructs a dispatcher creating the tokens of the runs with
+// authSvc.
+func NewDispatcher(log *zap.Logger, authSvc influxdb.AuthorizationService, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		log:              log,
+		authSvc:          authSvc,
+		heartbeatTimeout: DefaultHeartbeatTimeout,
+		maxAttempts:      DefaultMaxAttempts,
+		timeGenerator:    influxdb.RealTimeGenerator{},
+		jobs:             make(map[influxdb.ID]*job),
+		workers:          make(map[string]time.Time),
+		notify:           make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Dispatch executes the query of the run on a worker with the permissions of
+// auth, and blocks until the worker reports its result or ctx is done. It
+// returns the run logs reported by the worker.
+func (d *Dispatcher) Dispatch(ctx context.Context, run *influxdb.Run, task *influxdb.Task, auth *influxdb.Authorization) ([]string, error) {
+	d.mu.Lock()
+	d.reapWorkers()
+	workers := len(d.workers)
+	d.mu.Unlock()
+	if workers == 0 {
+		return nil, ErrNoWorkers
+	}
+
+	token := &influxdb.Authorization{
+		OrgID:       auth.OrgID,
+		UserID:      auth.UserID,
+		Permissions: auth.Permissions,
+		RowFilters:  auth.RowFilters,
+		Description: fmt.Sprintf("remote execution of run %s of task %s", run.ID, task.ID),
+	}
+	if err := d.authSvc.CreateAuthorization(ctx, token); err != nil {
+		return nil, err
+	}
+	defer func() {
+		// The run context may be done already.
+		if err := d.authSvc.DeleteAuthorization(context.Background(), token.ID); err != nil {
+			d.log.Error("Failed to delete the token of a remote run", zap.String("runID", run.ID.String()), zap.Error(err))
+		}
+	}()
+
+	j := &job{
+		Job: Job{
+			RunID:        run.ID,
+			TaskID:       task.ID,
+			OrgID:        task.OrganizationID,
+			Flux:         task.Flux,
+			ScheduledFor: run.ScheduledFor,
+			Token:        token.Token,
+		},
+		done: make(chan struct{}),
+	}
+
+	d.mu.Lock()
+	d.jobs[j.RunID] = j
+	d.enqueue(j, false)
+	d.mu.Unlock()
+
+	select {
+	case <-j.done:
+		return j.logs, j.err
+	case <-ctx.Done():
+		d.mu.Lock()
+		d.remove(j)
+		d.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// Claim assigns the next queued run to the worker. It waits until a run is
+// queued or ctx is done, in which case it returns a nil job.
+func (d *Dispatcher) Claim(ctx context.Context, worker string) (*Job, error) {
+	if worker == "" {
+		return nil, ErrInvalidWorkerID
+	}
+
+	for {
+		d.mu.Lock()
+		d.workers[worker] = d.timeGenerator.Now()
+		d.reapWorkers()
+		if len(d.queue) > 0 {
+			j := d.queue[0]
+			d.queue = d.queue[1:]
+			j.worker = worker
+			j.attempts++
+			d.mu.Unlock()
+
+			d.log.Debug("Run assigned to worker", zap.String("runID", j.RunID.String()), zap.String("worker", worker), zap.Int("attempt", j.attempts))
+			jj := j.Job
+			return &jj, nil
+		}
+		notify := d.notify
+		d.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return nil, nil
+		}
+	}
+}
+
+// Heartbeat records that the worker is alive and executing the runs. It
+// returns the runs the worker must stop executing, because they were
+// canceled or reassigned.
+func (d *Dispatcher) Heartbeat(ctx context.Context, worker string, runs []influxdb.ID) ([]influxdb.ID, error) {
+	if worker == "" {
+		return nil, ErrInvalidWorkerID
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.workers[worker] = d.timeGenerator.Now()
+	d.reapWorkers()
+
+	var cancel []influxdb.ID
+	for _, id := range runs {
+		if j, ok := d.jobs[id]; !ok || j.worker != worker {
+			cancel = append(cancel, id)
+		}
+	}
+	return cancel, nil
+}
+
+// Complete records the result of a run executed by the worker.
+func (d *Dispatcher) Complete(ctx context.Context, worker string, runID influxdb.ID, res Result) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	j, ok := d.jobs[runID]
+	if !ok || j.worker != worker {
+		return ErrRunNotAssigned
+	}
+	d.workers[worker] = d.timeGenerator.Now()
+	d.remove(j)
+
+	var err error
+	if res.Error != "" {
+		err = ErrRemoteExecution(worker, res.Error)
+	}
+	j.finish(res.Logs, err)
+	return nil
+}
+
+// enqueue queues the job to be claimed by a worker, at the front of the
+// queue when it is reassigned.
+func (d *Dispatcher) enqueue(j *job, front bool) {
+	j.worker = ""
+	if front {
+		d.queue = append([]*job{j}, d.queue...)
+	} else {
+		d.queue = append(d.queue, j)
+	}
+	close(d.notify)
+	d.notify = make(chan struct{})
+}
+
+// remove removes the job from the queue and the jobs.
+func (d *Dispatcher) remove(j *job) {
+	delete(d.jobs, j.RunID)
+	for i, q := range d.queue {
+		if q == j {
+			d.queue = append(d.queue[:i], d.queue[i+1:]...)
+			break
+		}
+	}
+}
+
+// reapWorkers forgets the workers that have not sent a heartbeat in time and
+// reassigns their runs, or fails the runs assigned too many times already.
+func (d *Dispatcher) reapWorkers() {
+	deadline := d.timeGenerator.Now().Add(-d.heartbeatTimeout)
+	for worker, last := range d.workers {
+		if !last.Before(deadline) {
+			continue
+		}
+		delete(d.workers, worker)
+		d.log.Info("Task worker lost", zap.String("worker", worker), zap.Time("lastHeartbeat", last))
+
+		for _, j := range d.jobs {
+			if j.worker != worker {
+				continue
+			}
+			if j.attempts >= d.maxAttempts {
+				d.remove(j)
+				j.finish(nil, ErrWorkerLost(j.attempts))
+				continue
+			}
+			d.enqueue(j, true)
+		}
+	}
+}
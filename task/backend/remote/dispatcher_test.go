@@ -0,0 +1,227 @@
+package remote_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/task/backend/remote"
+	"go.uber.org/zap/zaptest"
+)
+
+// clock is a time generator whose time is advanced by the tests.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+var (
+	task = &influxdb.Task{ID: 1, OrganizationID: 2, Flux: `option task = {name: "t", every: 1h} from(bucket: "b") |> range(start: -1h)`}
+	run  = &influxdb.Run{ID: 10, TaskID: 1, ScheduledFor: time.Date(2020, 6, 8, 0, 0, 0, 0, time.UTC)}
+	auth = &influxdb.Authorization{OrgID: 2, UserID: 3}
+)
+
+func newDispatcher(t *testing.T, opts ...remote.Option) (*remote.Dispatcher, *clock, *mock.AuthorizationService) {
+	t.Helper()
+
+	c := &clock{now: time.Date(2020, 6, 8, 0, 0, 0, 0, time.UTC)}
+	authSvc := mock.NewAuthorizationService()
+	authSvc.CreateAuthorizationFn = func(_ context.Context, a *influxdb.Authorization) error {
+		a.ID, a.Token = 100, "run-token"
+		return nil
+	}
+	opts = append(opts, remote.WithTimeGenerator(c))
+	return remote.NewDispatcher(zaptest.NewLogger(t), authSvc, opts...), c, authSvc
+}
+
+// result is the result of a dispatched run.
+type result struct {
+	logs []string
+	err  error
+}
+
+// dispatch dispatches the run in the background and returns the channel
+// its result is sent to.
+func dispatch(ctx context.Context, d *remote.Dispatcher) <-chan result {
+	results := make(chan result, 1)
+	go func() {
+		logs, err := d.Dispatch(ctx, run, task, auth)
+		results <- result{logs: logs, err: err}
+	}()
+	return results
+}
+
+func claim(t *testing.T, d *remote.Dispatcher, worker string) *remote.Job {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	job, err := d.Claim(ctx, worker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job == nil {
+		t.Fatal("expected a run to be claimed")
+	}
+	return job
+}
+
+func wait(t *testing.T, results <-chan result) result {
+	t.Helper()
+
+	select {
+	case res := <-results:
+		return res
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the dispatched run")
+		return result{}
+	}
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("runs are executed by workers", func(t *testing.T) {
+		d, _, authSvc := newDispatcher(t)
+		var deleted influxdb.ID
+		authSvc.DeleteAuthorizationFn = func(_ context.Context, id influxdb.ID) error {
+			deleted = id
+			return nil
+		}
+
+		if _, err := d.Heartbeat(ctx, "a", nil); err != nil {
+			t.Fatal(err)
+		}
+		results := dispatch(ctx, d)
+
+		job := claim(t, d, "a")
+		if job.RunID != run.ID || job.OrgID != task.OrganizationID || job.Flux != task.Flux || job.Token != "run-token" || !job.ScheduledFor.Equal(run.ScheduledFor) {
+			t.Fatalf("unexpected job %+v", job)
+		}
+		if err := d.Complete(ctx, "a", job.RunID, remote.Result{}); err != nil {
+			t.Fatal(err)
+		}
+		if res := wait(t, results); res.err != nil {
+			t.Fatal(res.err)
+		}
+		if deleted != 100 {
+			t.Errorf("expected the token of the run to be deleted, got %v", deleted)
+		}
+	})
+
+	t.Run("failures of workers fail the run", func(t *testing.T) {
+		d, _, _ := newDispatcher(t)
+		if _, err := d.Heartbeat(ctx, "a", nil); err != nil {
+			t.Fatal(err)
+		}
+		results := dispatch(ctx, d)
+
+		job := claim(t, d, "a")
+		if err := d.Complete(ctx, "a", job.RunID, remote.Result{Error: "query failed", Logs: []string{"trace_id=1 is_sampled=true"}}); err != nil {
+			t.Fatal(err)
+		}
+		res := wait(t, results)
+		if influxdb.ErrorCode(res.err) != influxdb.EInternal {
+			t.Fatalf("expected an internal error, got %v", res.err)
+		}
+		if len(res.logs) != 1 || res.logs[0] != "trace_id=1 is_sampled=true" {
+			t.Errorf("expected the logs of the run to be returned, got %v", res.logs)
+		}
+	})
+
+	t.Run("runs are not dispatched without workers", func(t *testing.T) {
+		d, _, _ := newDispatcher(t)
+		if _, err := d.Dispatch(ctx, run, task, auth); err != remote.ErrNoWorkers {
+			t.Fatalf("expected %v, got %v", remote.ErrNoWorkers, err)
+		}
+	})
+
+	t.Run("runs of lost workers are reassigned", func(t *testing.T) {
+		d, c, _ := newDispatcher(t)
+		if _, err := d.Heartbeat(ctx, "a", nil); err != nil {
+			t.Fatal(err)
+		}
+		results := dispatch(ctx, d)
+		claim(t, d, "a")
+
+		c.Add(remote.DefaultHeartbeatTimeout + time.Second)
+		job := claim(t, d, "b")
+		if job.RunID != run.ID {
+			t.Fatalf("expected run %s to be reassigned, got %s", run.ID, job.RunID)
+		}
+
+		// The lost worker stops the run once it is back.
+		cancel, err := d.Heartbeat(ctx, "a", []influxdb.ID{run.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cancel) != 1 || cancel[0] != run.ID {
+			t.Fatalf("expected run %s to be canceled, got %v", run.ID, cancel)
+		}
+		if err := d.Complete(ctx, "a", run.ID, remote.Result{}); err != remote.ErrRunNotAssigned {
+			t.Fatalf("expected %v, got %v", remote.ErrRunNotAssigned, err)
+		}
+
+		if err := d.Complete(ctx, "b", run.ID, remote.Result{}); err != nil {
+			t.Fatal(err)
+		}
+		if res := wait(t, results); res.err != nil {
+			t.Fatal(res.err)
+		}
+	})
+
+	t.Run("runs fail after too many lost workers", func(t *testing.T) {
+		d, c, _ := newDispatcher(t, remote.WithMaxAttempts(1))
+		if _, err := d.Heartbeat(ctx, "a", nil); err != nil {
+			t.Fatal(err)
+		}
+		results := dispatch(ctx, d)
+		claim(t, d, "a")
+
+		c.Add(remote.DefaultHeartbeatTimeout + time.Second)
+		if _, err := d.Heartbeat(ctx, "b", nil); err != nil {
+			t.Fatal(err)
+		}
+		if res := wait(t, results); influxdb.ErrorCode(res.err) != influxdb.EUnavailable {
+			t.Fatalf("expected an unavailable error, got %v", res.err)
+		}
+	})
+
+	t.Run("canceled runs are stopped", func(t *testing.T) {
+		d, _, _ := newDispatcher(t)
+		if _, err := d.Heartbeat(ctx, "a", nil); err != nil {
+			t.Fatal(err)
+		}
+		runCtx, cancelRun := context.WithCancel(ctx)
+		results := dispatch(runCtx, d)
+		claim(t, d, "a")
+
+		cancelRun()
+		if res := wait(t, results); res.err != context.Canceled {
+			t.Fatalf("expected %v, got %v", context.Canceled, res.err)
+		}
+
+		cancel, err := d.Heartbeat(ctx, "a", []influxdb.ID{run.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cancel) != 1 || cancel[0] != run.ID {
+			t.Fatalf("expected run %s to be canceled, got %v", run.ID, cancel)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package remote
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrNoWorkers is used when a run is dispatched while no worker is
+	// available to execute it.
+	ErrNoWorkers = &influxdb.Error{
+		Code: influxdb.EUnavailable,
+		Msg:  "no task worker available",
+	}
+
+	// ErrRunNotAssigned is used when a worker reports the result of a run
+	// that is not assigned to it, because it was canceled or reassigned.
+	ErrRunNotAssigned = &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  "run is not assigned to the worker",
+	}
+
+	// ErrInvalidWorkerID is used when a worker is identified by an empty ID.
+	ErrInvalidWorkerID = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "worker ID is required",
+	}
+)
+
+// ErrWorkerLost is used when a run was assigned to a worker that stopped
+// sending heartbeats too many times.
+func ErrWorkerLost(attempts int) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EUnavailable,
+		Msg:  fmt.Sprintf("run failed after losing the worker executing it %d times", attempts),
+	}
+}
+
+// ErrRemoteExecution is used when a worker failed to execute a run.
+func ErrRemoteExecution(worker, msg string) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Msg:  fmt.Sprintf("worker %s failed to execute the run: %s", worker, msg),
+	}
+}
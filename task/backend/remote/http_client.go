@@ -0,0 +1,76 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+)
+
+// Client connects to influxd via HTTP using an operator token to claim runs
+// and report their results.
+type Client struct {
+	Client *httpc.Client
+	Prefix string
+	// Worker is the ID the worker is identified with.
+	Worker string
+}
+
+// NewClient constructs a client identifying the worker with worker.
+func NewClient(client *httpc.Client, worker string) *Client {
+	return &Client{
+		Client: client,
+		Prefix: PrefixTaskWorkers,
+		Worker: worker,
+	}
+}
+
+func (c *Client) workerURL(elem ...string) string {
+	return path.Join(append([]string{c.Prefix, c.Worker}, elem...)...)
+}
+
+// Claim claims the next run, waiting for one up to timeout. It returns a nil
+// job when no run was queued in time.
+func (c *Client) Claim(ctx context.Context, timeout time.Duration) (*Job, error) {
+	var job *Job
+	err := c.Client.
+		PostJSON(struct{}{}, c.workerURL("claim")).
+		QueryParams([2]string{"timeout", timeout.String()}).
+		Decode(func(resp *http.Response) error {
+			if resp.StatusCode == http.StatusNoContent {
+				return nil
+			}
+			job = new(Job)
+			return json.NewDecoder(resp.Body).Decode(job)
+		}).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Heartbeat reports the runs the worker is executing and returns the ones it
+// must stop executing.
+func (c *Client) Heartbeat(ctx context.Context, runs []influxdb.ID) ([]influxdb.ID, error) {
+	var resp heartbeatResponse
+	err := c.Client.
+		PostJSON(heartbeatRequest{Runs: runs}, c.workerURL("heartbeat")).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Cancel, nil
+}
+
+// Complete reports the result of a run.
+func (c *Client) Complete(ctx context.Context, runID influxdb.ID, res Result) error {
+	return c.Client.
+		PostJSON(res, c.workerURL("runs", runID.String())).
+		Do(ctx)
+}
@@ -0,0 +1,157 @@
+package remote
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixTaskWorkers is the prefix of the internal API of task workers.
+	PrefixTaskWorkers = "/api/v2/taskWorkers"
+
+	// defaultClaimTimeout is the time a claim waits for a run by default.
+	defaultClaimTimeout = 30 * time.Second
+	// maxClaimTimeout is the longest time a claim can wait for a run.
+	maxClaimTimeout = time.Minute
+)
+
+// Handler is the internal HTTP API handler workers claim runs and report
+// their results with. Only operators can use it.
+type Handler struct {
+	chi.Router
+	api        *kithttp.API
+	log        *zap.Logger
+	dispatcher *Dispatcher
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, dispatcher *Dispatcher) *Handler {
+	h := &Handler{
+		api:        kithttp.NewAPI(kithttp.WithLog(log)),
+		log:        log,
+		dispatcher: dispatcher,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+		h.requireOperator,
+	)
+
+	r.Route("/{workerID}", func(r chi.Router) {
+		r.Post("/claim", h.handlePostClaim)
+		r.Post("/heartbeat", h.handlePostHeartbeat)
+		r.Post("/runs/{runID}", h.handlePostRunResult)
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixTaskWorkers
+}
+
+func (h *Handler) requireOperator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := authorizer.IsAllowedAll(r.Context(), influxdb.OperPermissions()); err != nil {
+			h.api.Err(w, r, &influxdb.Error{
+				Code: influxdb.EForbidden,
+				Msg:  "only operators can execute task runs",
+				Err:  err,
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *Handler) handlePostClaim(w http.ResponseWriter, r *http.Request) {
+	timeout := defaultClaimTimeout
+	if v := r.URL.Query().Get("timeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			h.api.Err(w, r, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "timeout must be a positive duration",
+				Err:  err,
+			})
+			return
+		}
+		timeout = d
+	}
+	if timeout > maxClaimTimeout {
+		timeout = maxClaimTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	job, err := h.dispatcher.Claim(ctx, chi.URLParam(r, "workerID"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if job == nil {
+		h.api.Respond(w, r, http.StatusNoContent, nil)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, job)
+}
+
+type heartbeatRequest struct {
+	Runs []influxdb.ID `json:"runs"`
+}
+
+type heartbeatResponse struct {
+	Cancel []influxdb.ID `json:"cancel"`
+}
+
+func (h *Handler) handlePostHeartbeat(w http.ResponseWriter, r *http.Request) {
+	var req heartbeatRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	cancel, err := h.dispatcher.Heartbeat(r.Context(), chi.URLParam(r, "workerID"), req.Runs)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, heartbeatResponse{Cancel: cancel})
+}
+
+func (h *Handler) handlePostRunResult(w http.ResponseWriter, r *http.Request) {
+	runID, err := influxdb.IDFromString(chi.URLParam(r, "runID"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	var req Result
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	worker := chi.URLParam(r, "workerID")
+	if err := h.dispatcher.Complete(r.Context(), worker, *runID, req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Remote run completed", zap.String("runID", runID.String()), zap.String("worker", worker))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
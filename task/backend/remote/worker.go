@@ -0,0 +1,213 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/task/backend/executor"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultHeartbeatInterval is the interval between the heartbeats of a
+	// worker, well below DefaultHeartbeatTimeout.
+	DefaultHeartbeatInterval = 10 * time.Second
+
+	// claimTimeout is the time a worker waits for a run per claim.
+	claimTimeout = 30 * time.Second
+	// retryInterval is the time a worker waits after failing to claim a run.
+	retryInterval = time.Second
+)
+
+// QueryServiceFunc returns the query service executing queries with token.
+type QueryServiceFunc func(token string) query.QueryService
+
+// Worker executes the runs it claims from influxd.
+type Worker struct {
+	log               *zap.Logger
+	client            *Client
+	querySvc          QueryServiceFunc
+	concurrency       int
+	heartbeatInterval time.Duration
+
+	mu      sync.Mutex
+	running map[influxdb.ID]context.CancelFunc
+}
+
+// NewWorker constructs a worker executing up to concurrency runs at a time,
+// claimed with client, with the query services returned by querySvc.
+func NewWorker(log *zap.Logger, client *Client, querySvc QueryServiceFunc, concurrency int) *Worker {
+	return &Worker{
+		log:               log,
+		client:            client,
+		querySvc:          querySvc,
+		concurrency:       concurrency,
+		heartbeatInterval: DefaultHeartbeatInterval,
+		running:           make(map[influxdb.ID]context.CancelFunc),
+	}
+}
+
+// Run claims and executes runs until ctx is done.
+func (w *Worker) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.heartbeat(ctx)
+	}()
+
+	slots := make(chan struct{}, w.concurrency)
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil
+		}
+
+		job, err := w.client.Claim(ctx, claimTimeout)
+		if err != nil || job == nil {
+			<-slots
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				w.log.Warn("Failed to claim a run", zap.Error(err))
+				select {
+				case <-time.After(retryInterval):
+				case <-ctx.Done():
+					return nil
+				}
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			w.execute(ctx, job)
+		}()
+	}
+}
+
+// heartbeat sends heartbeats until ctx is done, and cancels the runs the
+// worker must stop executing.
+func (w *Worker) heartbeat(ctx context.Context) {
+	ticker := time.NewTicker(w.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		w.mu.Lock()
+		runs := make([]influxdb.ID, 0, len(w.running))
+		for id := range w.running {
+			runs = append(runs, id)
+		}
+		w.mu.Unlock()
+
+		cancel, err := w.client.Heartbeat(ctx, runs)
+		if err != nil {
+			w.log.Warn("Failed to send heartbeat", zap.Error(err))
+			continue
+		}
+
+		w.mu.Lock()
+		for _, id := range cancel {
+			if fn, ok := w.running[id]; ok {
+				w.log.Info("Run canceled", zap.String("runID", id.String()))
+				fn()
+			}
+		}
+		w.mu.Unlock()
+	}
+}
+
+func (w *Worker) execute(ctx context.Context, job *Job) {
+	log := w.log.With(zap.String("taskID", job.TaskID.String()), zap.String("runID", job.RunID.String()))
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w.mu.Lock()
+	w.running[job.RunID] = cancel
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		delete(w.running, job.RunID)
+		w.mu.Unlock()
+	}()
+
+	res := w.run(runCtx, job)
+
+	// A canceled run was either canceled by influxd, which does not expect a
+	// result anymore, or interrupted by the shutdown of the worker, in which
+	// case influxd reassigns it once the worker stops sending heartbeats.
+	if runCtx.Err() != nil {
+		log.Debug("Run interrupted")
+		return
+	}
+	if err := w.client.Complete(ctx, job.RunID, res); err != nil {
+		log.Warn("Failed to report the result of the run", zap.Error(err))
+		return
+	}
+	log.Debug("Run completed", zap.String("error", res.Error))
+}
+
+// run executes the query of the run and returns its result, with the trace
+// ID of its execution in the run logs as influxd logs it for local runs.
+func (w *Worker) run(ctx context.Context, job *Job) Result {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res Result
+	if err := w.query(ctx, job); err != nil {
+		res.Error = err.Error()
+	}
+	if traceID, isSampled, ok := tracing.InfoFromSpan(span); ok {
+		res.Logs = append(res.Logs, fmt.Sprintf("trace_id=%s is_sampled=%t", traceID, isSampled))
+	}
+	return res
+}
+
+func (w *Worker) query(ctx context.Context, job *Job) error {
+	compiler, err := executor.NewASTCompiler(ctx, job.Flux, job.ScheduledFor)
+	if err != nil {
+		return influxdb.ErrFluxParseError(err)
+	}
+
+	it, err := w.querySvc(job.Token).Query(ctx, &query.Request{
+		OrganizationID: job.OrgID,
+		Compiler:       compiler,
+	})
+	if err != nil {
+		return influxdb.ErrQueryError(err)
+	}
+	defer it.Release()
+
+	for it.More() {
+		if err := it.Next().Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(flux.ColReader) error { return nil })
+		}); err != nil {
+			return influxdb.ErrRunExecutionError(err)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return influxdb.ErrResultIteratorError(err)
+	}
+	return nil
+}
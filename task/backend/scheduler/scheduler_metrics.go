@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -16,6 +17,9 @@ type SchedulerMetrics struct {
 	executingTasks *executingTasks
 	scheduleDelay  prometheus.Summary
 	executeDelta   prometheus.Summary
+
+	lateness        *prometheus.GaugeVec
+	missedIntervals *prometheus.CounterVec
 }
 
 type executingTasks struct {
@@ -76,6 +80,20 @@ func NewSchedulerMetrics(te *TreeScheduler) *SchedulerMetrics {
 			Help:       "The duration in seconds between a run starting and finishing.",
 			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
 		}),
+
+		lateness: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "lateness_seconds",
+			Help:      "The duration in seconds between when the last run of a task was due and when it was dispatched to the executor, by task ID.",
+		}, []string{"taskID"}),
+
+		missedIntervals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "missed_intervals_total",
+			Help:      "Total number of intervals of a task already due when a previous run was dispatched, by task ID.",
+		}, []string{"taskID"}),
 	}
 }
 
@@ -90,6 +108,8 @@ func (em *SchedulerMetrics) PrometheusCollectors() []prometheus.Collector {
 		em.executingTasks,
 		em.scheduleDelay,
 		em.executeDelta,
+		em.lateness,
+		em.missedIntervals,
 	}
 }
 
@@ -103,12 +123,19 @@ func (em *SchedulerMetrics) scheduleFail(taskID ID) {
 
 func (em *SchedulerMetrics) release(taskID ID) {
 	em.releaseCalls.Inc()
+	em.lateness.DeleteLabelValues(taskIDLabel(taskID))
+	em.missedIntervals.DeleteLabelValues(taskIDLabel(taskID))
 }
 
 func (em *SchedulerMetrics) reportScheduleDelay(d time.Duration) {
 	em.scheduleDelay.Observe(d.Seconds())
 }
 
+func (em *SchedulerMetrics) reportDispatch(taskID ID, lateness time.Duration, missed int64) {
+	em.lateness.WithLabelValues(taskIDLabel(taskID)).Set(lateness.Seconds())
+	em.missedIntervals.WithLabelValues(taskIDLabel(taskID)).Add(float64(missed))
+}
+
 func (em *SchedulerMetrics) reportExecution(err error, d time.Duration) {
 	em.totalExecuteCalls.Inc()
 	em.executeDelta.Observe(d.Seconds())
@@ -139,3 +166,12 @@ func (r *executingTasks) Collect(ch chan<- prometheus.Metric) {
 	// TODO(docmerlin): fix this metric
 	ch <- prometheus.MustNewConstMetric(r.desc, prometheus.GaugeValue, float64(len(r.ts.workchans)))
 }
+
+// taskIDLabel formats taskID as the task IDs of the executor metrics.
+func taskIDLabel(taskID ID) string {
+	s := strconv.FormatUint(uint64(taskID), 16)
+	for len(s) < 16 {
+		s = "0" + s
+	}
+	return s
+}
@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// maxMissedIntervals bounds the intervals counted as missed by a single run,
+// so that a task with a short interval far behind its schedule does not stall
+// the scheduler.
+const maxMissedIntervals = 10000
+
+// SLA is the scheduling service level of a task, telling whether its runs,
+// and so the alerts of a check, are late because the scheduler or the
+// executor is saturated.
+type SLA struct {
+	// LastScheduledFor is the time the last run of the task was scheduled for.
+	LastScheduledFor time.Time
+	// Runs is the number of runs dispatched since the task was scheduled.
+	Runs int64
+	// Lateness is the duration between the time the last run was due and the
+	// time it was dispatched to the executor, and MaxLateness the longest one.
+	Lateness    time.Duration
+	MaxLateness time.Duration
+	// MissedIntervals is the number of intervals that were already due when
+	// a previous run was dispatched, so that their runs could not start on time.
+	MissedIntervals int64
+	// QueueWait is the duration the last run waited in the queue of the
+	// executor before starting.
+	QueueWait time.Duration
+}
+
+// SLATracker tracks the scheduling service level of the tasks.
+type SLATracker struct {
+	mu    sync.RWMutex
+	tasks map[ID]*SLA
+}
+
+// NewSLATracker returns a tracker of the scheduling service level of tasks.
+func NewSLATracker() *SLATracker {
+	return &SLATracker{
+		tasks: map[ID]*SLA{},
+	}
+}
+
+// SLA returns the scheduling service level of the task id, if a run of the
+// task has been dispatched since it was scheduled.
+func (t *SLATracker) SLA(id ID) (SLA, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	sla, ok := t.tasks[id]
+	if !ok {
+		return SLA{}, false
+	}
+	return *sla, true
+}
+
+// ObserveQueueWait records that the last run of the task id waited d in the
+// queue of the executor.
+func (t *SLATracker) ObserveQueueWait(id ID, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if sla, ok := t.tasks[id]; ok {
+		sla.QueueWait = d
+	}
+}
+
+// observeDispatch records the dispatch of a run of the task id scheduled for
+// scheduledFor, lateness after it was due, with missed intervals already due.
+func (t *SLATracker) observeDispatch(id ID, scheduledFor time.Time, lateness time.Duration, missed int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sla, ok := t.tasks[id]
+	if !ok {
+		sla = &SLA{}
+		t.tasks[id] = sla
+	}
+	sla.LastScheduledFor = scheduledFor
+	sla.Runs++
+	sla.Lateness = lateness
+	if lateness > sla.MaxLateness {
+		sla.MaxLateness = lateness
+	}
+	sla.MissedIntervals += missed
+}
+
+// release forgets the task id.
+func (t *SLATracker) release(id ID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.tasks, id)
+}
+
+// missedIntervals returns the number of the intervals of it after the one it
+// is due for that are already due at now.
+func missedIntervals(it Item, now time.Time) int64 {
+	var missed int64
+	next := it.Next()
+	offset := time.Duration(it.Offset) * time.Second
+	for missed < maxMissedIntervals {
+		n, err := it.cron.Next(next)
+		if err != nil || n.Add(offset).After(now) {
+			break
+		}
+		next = n
+		missed++
+	}
+	return missed
+}
@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMissedIntervals(t *testing.T) {
+	schedule, _, err := NewSchedule("@every 1m", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	it := Item{cron: schedule, next: next.Unix(), Offset: 10}
+
+	for _, tt := range []struct {
+		now  time.Time
+		want int64
+	}{
+		{now: next.Add(30 * time.Second), want: 0},
+		{now: next.Add(time.Minute), want: 0},
+		{now: next.Add(time.Minute + 10*time.Second), want: 1},
+		{now: next.Add(5*time.Minute + 30*time.Second), want: 5},
+	} {
+		if got := missedIntervals(it, tt.now); got != tt.want {
+			t.Errorf("missedIntervals at %s = %d, want %d", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestSLATracker(t *testing.T) {
+	tracker := NewSLATracker()
+	scheduledFor := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tracker.ObserveQueueWait(1, time.Second)
+	if _, ok := tracker.SLA(1); ok {
+		t.Fatal("expected no service level before a run is dispatched")
+	}
+
+	tracker.observeDispatch(1, scheduledFor, 3*time.Second, 2)
+	tracker.observeDispatch(1, scheduledFor.Add(time.Minute), time.Second, 1)
+	tracker.ObserveQueueWait(1, 500*time.Millisecond)
+
+	sla, ok := tracker.SLA(1)
+	if !ok {
+		t.Fatal("expected a service level")
+	}
+	want := SLA{
+		LastScheduledFor: scheduledFor.Add(time.Minute),
+		Runs:             2,
+		Lateness:         time.Second,
+		MaxLateness:      3 * time.Second,
+		MissedIntervals:  3,
+		QueueWait:        500 * time.Millisecond,
+	}
+	if sla != want {
+		t.Errorf("unexpected service level %+v, want %+v", sla, want)
+	}
+
+	tracker.release(1)
+	if _, ok := tracker.SLA(1); ok {
+		t.Error("expected a released task to be forgotten")
+	}
+}
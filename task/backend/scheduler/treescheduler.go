@@ -74,6 +74,7 @@ type TreeScheduler struct {
 	wg            sync.WaitGroup
 	checkpointer  SchedulableService
 	items         *itemList
+	sla           *SLATracker
 
 	sm *SchedulerMetrics
 }
@@ -108,6 +109,14 @@ func WithTime(t clock.Clock) treeSchedulerOptFunc {
 	}
 }
 
+// WithSLATracker is an option that sets the tracker of the scheduling service level of the tasks of a TreeScheduler.
+func WithSLATracker(sla *SLATracker) treeSchedulerOptFunc {
+	return func(sch *TreeScheduler) error {
+		sch.sla = sla
+		return nil
+	}
+}
+
 // NewScheduler gives us a new TreeScheduler and SchedulerMetrics when given an  Executor, a SchedulableService, and zero or more options.
 // Schedulers should be initialized with this function.
 func NewScheduler(executor Executor, checkpointer SchedulableService, opts ...treeSchedulerOptFunc) (*TreeScheduler, *SchedulerMetrics, error) {
@@ -120,6 +129,7 @@ func NewScheduler(executor Executor, checkpointer SchedulableService, opts ...tr
 		done:          make(chan struct{}, 1),
 		checkpointer:  checkpointer,
 		items:         &itemList{},
+		sla:           NewSLATracker(),
 	}
 
 	// apply options
@@ -297,6 +307,7 @@ func (s *TreeScheduler) release(taskID ID) {
 // Task deletion would be faster if the tree supported deleting ranges.
 func (s *TreeScheduler) Release(taskID ID) error {
 	s.sm.release(taskID)
+	s.sla.release(taskID)
 	s.mu.Lock()
 	s.release(taskID)
 	s.mu.Unlock()
@@ -319,6 +330,7 @@ func (s *TreeScheduler) work(ctx context.Context, ch chan Item) {
 			}()
 			// report the difference between when the item was supposed to be scheduled and now
 			s.sm.reportScheduleDelay(time.Since(it.Next()))
+			s.reportDispatch(it)
 			preExec := time.Now()
 			// execute
 			err = s.executor.Execute(ctx, it.id, t, it.When())
@@ -336,6 +348,19 @@ func (s *TreeScheduler) work(ctx context.Context, ch chan Item) {
 	}
 }
 
+// reportDispatch reports the lateness of the dispatch of it and the intervals
+// of its task that are already due.
+func (s *TreeScheduler) reportDispatch(it Item) {
+	now := s.time.Now()
+	lateness := now.Sub(it.When())
+	if lateness < 0 {
+		lateness = 0
+	}
+	missed := missedIntervals(it, now)
+	s.sla.observeDispatch(it.id, it.Next(), lateness, missed)
+	s.sm.reportDispatch(it.id, lateness, missed)
+}
+
 // Schedule put puts a Schedulable on the TreeScheduler.
 func (s *TreeScheduler) Schedule(sch Schedulable) error {
 	s.sm.schedule(sch.ID())
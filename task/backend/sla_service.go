@@ -0,0 +1,43 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+)
+
+var _ influxdb.TaskSLAService = (*SLAService)(nil)
+
+// SLAService reports the scheduling service level of the tasks tracked by
+// the scheduler and executor.
+type SLAService struct {
+	tracker *scheduler.SLATracker
+}
+
+// NewSLAService returns a service reporting the service level of the tasks
+// tracked by tracker.
+func NewSLAService(tracker *scheduler.SLATracker) *SLAService {
+	return &SLAService{tracker: tracker}
+}
+
+// FindTaskSLA returns the scheduling service level of the task id. It is not
+// found until a run of the task is dispatched by the scheduler.
+func (s *SLAService) FindTaskSLA(ctx context.Context, id influxdb.ID) (*influxdb.TaskSLA, error) {
+	sla, ok := s.tracker.SLA(scheduler.ID(id))
+	if !ok {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "no run of the task has been scheduled",
+		}
+	}
+	return &influxdb.TaskSLA{
+		TaskID:           id,
+		LastScheduledFor: sla.LastScheduledFor,
+		Runs:             sla.Runs,
+		Lateness:         sla.Lateness,
+		MaxLateness:      sla.MaxLateness,
+		MissedIntervals:  sla.MissedIntervals,
+		QueueWait:        sla.QueueWait,
+	}, nil
+}
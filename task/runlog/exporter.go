@@ -0,0 +1,116 @@
+// Package runlog keeps the run logs of tasks within the retention of their
+// organizations and exports them to buckets as points.
+package runlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/task/backend"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap"
+)
+
+const (
+	// Measurement is the measurement of the exported run logs. The points
+	// have a taskID tag and runID and message fields, at the time of the
+	// log.
+	Measurement = "run_logs"
+
+	taskIDTag    = "taskID"
+	runIDField   = "runID"
+	messageField = "message"
+)
+
+var _ backend.RunRecorder = (*Exporter)(nil)
+
+// Exporter is a backend.RunRecorder that, after recording a finished run,
+// writes its logs to the export bucket of its organization, if it has one.
+type Exporter struct {
+	backend.RunRecorder
+
+	log      *zap.Logger
+	settings influxdb.TaskRunLogSettingsService
+	pw       storage.PointsWriter
+}
+
+// NewExporter returns an exporter of the logs of the runs recorded by rr.
+func NewExporter(log *zap.Logger, rr backend.RunRecorder, settings influxdb.TaskRunLogSettingsService, pw storage.PointsWriter) *Exporter {
+	return &Exporter{
+		RunRecorder: rr,
+		log:         log,
+		settings:    settings,
+		pw:          pw,
+	}
+}
+
+// Record records the run and exports its logs. A failed export is logged
+// rather than failing the run.
+func (e *Exporter) Record(ctx context.Context, orgID influxdb.ID, org string, bucketID influxdb.ID, bucket string, run *influxdb.Run) error {
+	if err := e.RunRecorder.Record(ctx, orgID, org, bucketID, bucket, run); err != nil {
+		return err
+	}
+
+	if err := e.Export(ctx, &influxdb.RunLogs{
+		OrgID:  orgID,
+		TaskID: run.TaskID,
+		RunID:  run.ID,
+		Logs:   run.Log,
+	}); err != nil {
+		e.log.Error("Failed to export run logs", zap.Stringer("taskID", run.TaskID), zap.Stringer("runID", run.ID), zap.Error(err))
+	}
+	return nil
+}
+
+// Export writes the logs to the export bucket of their organization, if it
+// has one.
+func (e *Exporter) Export(ctx context.Context, logs *influxdb.RunLogs) error {
+	if len(logs.Logs) == 0 {
+		return nil
+	}
+
+	settings, err := e.settings.FindTaskRunLogSettings(ctx, logs.OrgID)
+	if err != nil {
+		return err
+	}
+	if settings.ExportBucketID == nil {
+		return nil
+	}
+
+	points, err := logPoints(logs)
+	if err != nil {
+		return err
+	}
+	exploded, err := tsdb.ExplodePoints(logs.OrgID, *settings.ExportBucketID, points)
+	if err != nil {
+		return err
+	}
+	return e.pw.WritePoints(ctx, exploded)
+}
+
+// logPoints returns a point by log, skipping the logs without a valid time.
+func logPoints(logs *influxdb.RunLogs) (models.Points, error) {
+	tags := models.NewTags(map[string]string{
+		taskIDTag: logs.TaskID.String(),
+	})
+
+	points := make(models.Points, 0, len(logs.Logs))
+	for _, l := range logs.Logs {
+		t, err := time.Parse(time.RFC3339Nano, l.Time)
+		if err != nil {
+			continue
+		}
+		p, err := models.NewPoint(Measurement, tags, models.Fields{
+			runIDField:   logs.RunID.String(),
+			messageField: l.Message,
+		}, t)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
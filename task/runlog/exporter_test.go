@@ -0,0 +1,95 @@
+package runlog_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/task/runlog"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+type settingsService map[influxdb.ID]*influxdb.TaskRunLogSettings
+
+func (s settingsService) FindTaskRunLogSettings(ctx context.Context, orgID influxdb.ID) (*influxdb.TaskRunLogSettings, error) {
+	if settings, ok := s[orgID]; ok {
+		return settings, nil
+	}
+	return &influxdb.TaskRunLogSettings{OrgID: orgID}, nil
+}
+
+func (s settingsService) PutTaskRunLogSettings(ctx context.Context, settings *influxdb.TaskRunLogSettings) error {
+	s[settings.OrgID] = settings
+	return nil
+}
+
+type recorder struct {
+	runs []*influxdb.Run
+}
+
+func (r *recorder) Record(ctx context.Context, orgID influxdb.ID, org string, bucketID influxdb.ID, bucket string, run *influxdb.Run) error {
+	r.runs = append(r.runs, run)
+	return nil
+}
+
+func TestExporter_Record(t *testing.T) {
+	const (
+		orgID       = influxdb.ID(1)
+		otherOrgID  = influxdb.ID(2)
+		exportID    = influxdb.ID(10)
+		tasksBucket = influxdb.ID(11)
+	)
+	exportBucketID := exportID
+	settings := settingsService{
+		orgID: {OrgID: orgID, ExportBucketID: &exportBucketID},
+	}
+	rr := &recorder{}
+	pw := &mock.PointsWriter{}
+	e := runlog.NewExporter(zaptest.NewLogger(t), rr, settings, pw)
+
+	now := time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+	run := &influxdb.Run{
+		ID:     3,
+		TaskID: 4,
+		Log: []influxdb.Log{
+			{RunID: 3, Time: now.Format(time.RFC3339Nano), Message: "Started task"},
+			{RunID: 3, Time: "not a time", Message: "skipped"},
+			{RunID: 3, Time: now.Add(time.Second).Format(time.RFC3339Nano), Message: "Completed(success)"},
+		},
+	}
+
+	if err := e.Record(context.Background(), otherOrgID, "other", tasksBucket, "_tasks", run); err != nil {
+		t.Fatal(err)
+	}
+	if pw.WritePointsCalled() != 0 {
+		t.Fatal("expected no export without an export bucket")
+	}
+
+	if err := e.Record(context.Background(), orgID, "org", tasksBucket, "_tasks", run); err != nil {
+		t.Fatal(err)
+	}
+	if len(rr.runs) != 2 {
+		t.Fatalf("expected the runs to be recorded, got %d", len(rr.runs))
+	}
+	if pw.WritePointsCalled() != 1 {
+		t.Fatalf("expected the logs to be exported once, got %d writes", pw.WritePointsCalled())
+	}
+
+	// the points are exploded by field, runID and message.
+	if got := len(pw.Points); got != 4 {
+		t.Fatalf("expected 4 points for 2 logs with valid times, got %d", got)
+	}
+	name := tsdb.EncodeNameString(orgID, exportID)
+	for _, p := range pw.Points {
+		if string(p.Name()) != name {
+			t.Errorf("expected the point to be written to the export bucket, got %q", p.Name())
+		}
+		if got := string(p.Tags().Get(models.MeasurementTagKeyBytes)); got != runlog.Measurement {
+			t.Errorf("expected measurement %s, got %s", runlog.Measurement, got)
+		}
+	}
+}
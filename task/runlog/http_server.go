@@ -0,0 +1,151 @@
+package runlog
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixTaskRunLogSettings is the prefix of the task run log settings
+	// API.
+	PrefixTaskRunLogSettings = "/api/v2/taskRunLogSettings"
+)
+
+// Handler is the HTTP API handler for the run log settings of the tasks of
+// organizations.
+type Handler struct {
+	chi.Router
+	api         *kithttp.API
+	log         *zap.Logger
+	settingsSvc influxdb.TaskRunLogSettingsService
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, settingsSvc influxdb.TaskRunLogSettingsService) *Handler {
+	h := &Handler{
+		api:         kithttp.NewAPI(kithttp.WithLog(log)),
+		log:         log,
+		settingsSvc: settingsSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Get("/", h.handleGetSettings)
+		r.Put("/", h.handlePutSettings)
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixTaskRunLogSettings
+}
+
+// settings is the run log settings with the retention in seconds.
+type settings struct {
+	OrgID            influxdb.ID  `json:"orgID"`
+	RetentionSeconds int64        `json:"retentionSeconds"`
+	ExportBucketID   *influxdb.ID `json:"exportBucketID,omitempty"`
+	influxdb.CRUDLog
+}
+
+type settingsResponse struct {
+	Links map[string]string `json:"links"`
+	settings
+}
+
+func newSettingsResponse(s *influxdb.TaskRunLogSettings) *settingsResponse {
+	links := map[string]string{
+		"self": fmt.Sprintf("%s?orgID=%s", PrefixTaskRunLogSettings, s.OrgID),
+		"org":  fmt.Sprintf("/api/v2/orgs/%s", s.OrgID),
+	}
+	if s.ExportBucketID != nil {
+		links["exportBucket"] = fmt.Sprintf("/api/v2/buckets/%s", s.ExportBucketID)
+	}
+	return &settingsResponse{
+		Links: links,
+		settings: settings{
+			OrgID:            s.OrgID,
+			RetentionSeconds: int64(s.Retention / time.Second),
+			ExportBucketID:   s.ExportBucketID,
+			CRUDLog:          s.CRUDLog,
+		},
+	}
+}
+
+type putSettingsRequest struct {
+	RetentionSeconds int64        `json:"retentionSeconds"`
+	ExportBucketID   *influxdb.ID `json:"exportBucketID,omitempty"`
+}
+
+func decodeOrgID(r *http.Request) (influxdb.ID, error) {
+	v := r.URL.Query().Get("orgID")
+	if v == "" {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID is required",
+		}
+	}
+	id, err := influxdb.IDFromString(v)
+	if err != nil {
+		return 0, influxdb.ErrCorruptID(err)
+	}
+	return *id, nil
+}
+
+func (h *Handler) handleGetSettings(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	s, err := h.settingsSvc.FindTaskRunLogSettings(r.Context(), orgID)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newSettingsResponse(s))
+}
+
+func (h *Handler) handlePutSettings(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var req putSettingsRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	s := &influxdb.TaskRunLogSettings{
+		OrgID:          orgID,
+		Retention:      time.Duration(req.RetentionSeconds) * time.Second,
+		ExportBucketID: req.ExportBucketID,
+	}
+	if err := h.settingsSvc.PutTaskRunLogSettings(r.Context(), s); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Task run log settings updated", zap.String("orgID", orgID.String()), zap.Duration("retention", s.Retention))
+
+	h.api.Respond(w, r, http.StatusOK, newSettingsResponse(s))
+}
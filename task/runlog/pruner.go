@@ -0,0 +1,76 @@
+package runlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+// DefaultPruneInterval is how often the run logs past their retention are
+// removed.
+const DefaultPruneInterval = 10 * time.Minute
+
+// PruneService removes the logs of the runs in progress past the retention
+// of their organizations.
+type PruneService interface {
+	PruneRunLogs(ctx context.Context, now time.Time) ([]*influxdb.RunLogs, error)
+}
+
+// Pruner periodically removes the run logs past their retention, exporting
+// them first.
+type Pruner struct {
+	log      *zap.Logger
+	svc      PruneService
+	exporter *Exporter
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewPruner returns a pruner of the run logs that exports the removed logs
+// with exporter.
+func NewPruner(log *zap.Logger, svc PruneService, exporter *Exporter, interval time.Duration) *Pruner {
+	return &Pruner{
+		log:      log,
+		svc:      svc,
+		exporter: exporter,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Run prunes the run logs until ctx is canceled.
+func (p *Pruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.prune(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Pruner) prune(ctx context.Context) {
+	pruned, err := p.svc.PruneRunLogs(ctx, p.now())
+	if err != nil {
+		p.log.Error("Failed to prune run logs", zap.Error(err))
+		return
+	}
+
+	n := 0
+	for _, logs := range pruned {
+		n += len(logs.Logs)
+		if err := p.exporter.Export(ctx, logs); err != nil {
+			p.log.Error("Failed to export pruned run logs", zap.Stringer("taskID", logs.TaskID), zap.Stringer("runID", logs.RunID), zap.Error(err))
+		}
+	}
+	if n > 0 {
+		p.log.Info("Pruned run logs past their retention", zap.Int("count", n))
+	}
+}
@@ -0,0 +1,218 @@
+package taskauth
+
+import (
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+)
+
+// Import paths of the packages whose functions connect to external systems.
+const (
+	sqlPath              = "sql"
+	httpPath             = "http"
+	experimentalHTTPPath = "experimental/http"
+)
+
+// ExternalCall is a call of a function connecting to a system outside of
+// InfluxDB. The values of its arguments are only set if they are known
+// before the script runs.
+type ExternalCall struct {
+	// Func is the name of the function qualified by its package, such as
+	// sql.from.
+	Func string
+	Kind influxdb.ConnectionKind
+	// Driver is the driver of sql functions.
+	Driver string
+	// Target is the data source name of sql functions or the URL of http
+	// functions, and TargetSecret the key of the secret it is read from.
+	Target       string
+	TargetSecret string
+	// Headers are the literal values of the headers of http functions, and
+	// HeaderSecrets the keys of the secrets the values of the others are
+	// read from.
+	Headers       map[string]string
+	HeaderSecrets map[string]string
+}
+
+// External is the set of calls of a script to external systems.
+type External struct {
+	Calls []ExternalCall
+	// StraySecretKeys are the keys of the secrets read by the script that
+	// are not passed to the arguments of external calls.
+	StraySecretKeys []string
+}
+
+// AnalyzeExternal returns the calls of the script in pkg to external
+// systems, and where the secrets it reads go.
+func AnalyzeExternal(pkg *ast.Package) *External {
+	x := &externalAnalyzer{
+		analyzer: &analyzer{},
+		used:     make(map[ast.Node]bool),
+		ignored:  make(map[ast.Node]bool),
+		ext:      &External{},
+	}
+	stray := make(map[string]bool)
+	for _, file := range pkg.Files {
+		x.bind(file)
+		x.bindSecrets(file)
+		x.reads = nil
+		ast.Walk(ast.CreateVisitor(x.visit), file)
+		for _, r := range x.reads {
+			if !x.used[r.node] && !x.ignored[r.node] {
+				stray[r.key] = true
+			}
+		}
+	}
+	x.ext.StraySecretKeys = sortedKeys(stray)
+	return x.ext
+}
+
+type externalAnalyzer struct {
+	*analyzer
+	// secretVars maps the identifiers assigned exactly once to secrets read
+	// with a known key to the keys.
+	secretVars map[string]string
+	// reads are the secret reads of the file, used those passed to the
+	// arguments of external calls, and ignored the identifiers that do not
+	// reference variables.
+	reads   []secretRead
+	used    map[ast.Node]bool
+	ignored map[ast.Node]bool
+	ext     *External
+}
+
+// secretRead is a node whose value is the secret with key.
+type secretRead struct {
+	node ast.Node
+	key  string
+}
+
+// bindSecrets records the identifiers file assigns secrets to.
+func (x *externalAnalyzer) bindSecrets(file *ast.File) {
+	x.secretVars = make(map[string]string)
+	assigned := make(map[string]int)
+	for _, stmt := range file.Body {
+		va, ok := stmt.(*ast.VariableAssignment)
+		if !ok {
+			continue
+		}
+		assigned[va.ID.Name]++
+		if call, ok := va.Init.(*ast.CallExpression); ok {
+			if k, ok := x.secretGet(call); ok {
+				x.secretVars[va.ID.Name] = k
+			}
+		}
+	}
+	for name, n := range assigned {
+		if n > 1 {
+			delete(x.secretVars, name)
+		}
+	}
+}
+
+func (x *externalAnalyzer) visit(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.VariableAssignment:
+		x.ignored[n.ID] = true
+		if _, ok := x.secretVars[n.ID.Name]; ok {
+			// The uses of the variable are read instead.
+			x.used[n.Init] = true
+		}
+	case *ast.Property:
+		// The keys of shorthand properties reference variables.
+		if n.Value != nil {
+			x.ignored[n.Key] = true
+		}
+	case *ast.MemberExpression:
+		x.ignored[n.Property] = true
+	case *ast.Identifier:
+		if k, ok := x.secretVars[n.Name]; ok {
+			x.reads = append(x.reads, secretRead{node: n, key: k})
+		}
+	case *ast.CallExpression:
+		if k, ok := x.secretGet(n); ok {
+			x.reads = append(x.reads, secretRead{node: n, key: k})
+		}
+		x.external(n)
+	}
+}
+
+// secretGet returns the key of the secret read by call, if it is a call of
+// secrets.get with a key known statically.
+func (x *externalAnalyzer) secretGet(call *ast.CallExpression) (string, bool) {
+	if pkg, fn := x.callee(call.Callee); pkg != secretsPath || fn != "get" {
+		return "", false
+	}
+	return x.staticSecretKey(call)
+}
+
+// external records call if it connects to an external system.
+func (x *externalAnalyzer) external(call *ast.CallExpression) {
+	var c ExternalCall
+	switch pkg, fn := x.callee(call.Callee); {
+	case pkg == sqlPath && (fn == "from" || fn == "to"):
+		c = ExternalCall{Func: "sql." + fn, Kind: influxdb.ConnectionKindSQL}
+	case pkg == httpPath && fn == "post", pkg == experimentalHTTPPath && fn == "get":
+		c = ExternalCall{
+			Func:          "http." + fn,
+			Kind:          influxdb.ConnectionKindHTTP,
+			Headers:       make(map[string]string),
+			HeaderSecrets: make(map[string]string),
+		}
+	default:
+		return
+	}
+
+	var args *ast.ObjectExpression
+	if len(call.Arguments) > 0 {
+		args, _ = call.Arguments[0].(*ast.ObjectExpression)
+	}
+	if args == nil {
+		x.ext.Calls = append(x.ext.Calls, c)
+		return
+	}
+	for _, p := range args.Properties {
+		switch propertyKey(p.Key) {
+		case "driverName":
+			c.Driver, _ = x.stringValue(p.Value)
+		case "dataSourceName", "url":
+			c.Target, c.TargetSecret = x.value(p.Value)
+		case "headers":
+			headers, ok := p.Value.(*ast.ObjectExpression)
+			if !ok || c.Kind != influxdb.ConnectionKindHTTP {
+				continue
+			}
+			for _, h := range headers.Properties {
+				v, k := x.value(h.Value)
+				if v != "" {
+					c.Headers[propertyKey(h.Key)] = v
+				}
+				if k != "" {
+					c.HeaderSecrets[propertyKey(h.Key)] = k
+				}
+			}
+		}
+	}
+	x.ext.Calls = append(x.ext.Calls, c)
+}
+
+// value returns the value of e if it is a string known statically, or else
+// the key of the secret it is if it is known statically. The secret is
+// marked used.
+func (x *externalAnalyzer) value(e ast.Expression) (literal, secretKey string) {
+	if v, ok := x.stringValue(e); ok {
+		return v, ""
+	}
+	switch e := e.(type) {
+	case *ast.CallExpression:
+		if k, ok := x.secretGet(e); ok {
+			x.used[e] = true
+			return "", k
+		}
+	case *ast.Identifier:
+		if k, ok := x.secretVars[e.Name]; ok {
+			x.used[e] = true
+			return "", k
+		}
+	}
+	return "", ""
+}
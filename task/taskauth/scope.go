@@ -0,0 +1,297 @@
+// Package taskauth issues each task a service token scoped to the buckets its
+// script reads and writes, so that tasks no longer run with every permission
+// of their owner.
+//
+// The buckets are derived from the Flux AST of the script. Scripts whose
+// buckets cannot be determined statically, for example because a bucket name
+// is computed at run time, keep running with the permissions of their owner.
+package taskauth
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+)
+
+// Import paths of the packages whose functions access buckets.
+const (
+	influxdbPath     = "influxdata/influxdb"
+	v1Path           = "influxdata/influxdb/v1"
+	monitorPath      = "influxdata/influxdb/monitor"
+	secretsPath      = "influxdata/influxdb/secrets"
+	experimentalPath = "experimental"
+)
+
+// ErrUnscopable is returned when the buckets accessed by a script cannot be
+// determined statically.
+var ErrUnscopable = errors.New("the buckets accessed by the script cannot be determined")
+
+// BucketRef identifies a bucket by name or, if Name is empty, by ID.
+type BucketRef struct {
+	Name string
+	ID   string
+}
+
+// Scope is the set of resources a script accesses.
+type Scope struct {
+	Read    []BucketRef
+	Write   []BucketRef
+	Secrets bool
+	// SecretKeys are the keys of the secrets read with a key known before
+	// the script runs.
+	SecretKeys []string
+}
+
+type unscopableError struct {
+	msg string
+}
+
+func (e *unscopableError) Error() string { return ErrUnscopable.Error() + ": " + e.msg }
+func (e *unscopableError) Unwrap() error { return ErrUnscopable }
+
+// Analyze returns the buckets read and written by the script in pkg. It returns
+// an error wrapping ErrUnscopable if they cannot be determined. The monitor
+// package reads and writes the monitoring bucket of the system buckets sb.
+func Analyze(pkg *ast.Package, sb influxdb.SystemBuckets) (*Scope, error) {
+	a := &analyzer{
+		monitoringBucket: sb.MonitoringName,
+		read:             make(map[BucketRef]bool),
+		write:            make(map[BucketRef]bool),
+		secretKeys:       make(map[string]bool),
+	}
+	for _, file := range pkg.Files {
+		a.file(file)
+		if a.err != nil {
+			return nil, a.err
+		}
+	}
+
+	return &Scope{
+		Read:       sortedRefs(a.read),
+		Write:      sortedRefs(a.write),
+		Secrets:    a.secrets,
+		SecretKeys: sortedKeys(a.secretKeys),
+	}, nil
+}
+
+type analyzer struct {
+	// imports maps the names packages are imported as to their paths.
+	imports map[string]string
+	// strings maps identifiers assigned exactly once to string literals to their values.
+	strings map[string]string
+	// monitoringBucket is the bucket the monitor package reads and writes.
+	monitoringBucket string
+
+	read       map[BucketRef]bool
+	write      map[BucketRef]bool
+	secrets    bool
+	secretKeys map[string]bool
+	err        error
+}
+
+func (a *analyzer) file(file *ast.File) {
+	a.bind(file)
+	ast.Walk(ast.CreateVisitor(func(node ast.Node) {
+		if call, ok := node.(*ast.CallExpression); ok && a.err == nil {
+			a.call(call)
+		}
+	}), file)
+}
+
+// bind records the imports of file and the identifiers it assigns string
+// literals to.
+func (a *analyzer) bind(file *ast.File) {
+	a.imports = make(map[string]string)
+	for _, imp := range file.Imports {
+		name := path.Base(imp.Path.Value)
+		if imp.As != nil {
+			name = imp.As.Name
+		}
+		a.imports[name] = imp.Path.Value
+		if imp.Path.Value == secretsPath {
+			a.secrets = true
+		}
+	}
+
+	a.strings = make(map[string]string)
+	assigned := make(map[string]int)
+	for _, stmt := range file.Body {
+		va, ok := stmt.(*ast.VariableAssignment)
+		if !ok {
+			continue
+		}
+		assigned[va.ID.Name]++
+		if lit, ok := va.Init.(*ast.StringLiteral); ok {
+			a.strings[va.ID.Name] = lit.Value
+		}
+	}
+	for name, n := range assigned {
+		if n > 1 {
+			delete(a.strings, name)
+		}
+	}
+}
+
+// call records the buckets accessed by a function call.
+func (a *analyzer) call(call *ast.CallExpression) {
+	pkg, fn := a.callee(call.Callee)
+	switch {
+	case pkg == "" && fn == "from", pkg == influxdbPath && fn == "from":
+		a.bucketArg(call, a.read)
+	case pkg == "" && fn == "to", pkg == influxdbPath && fn == "to", pkg == experimentalPath && fn == "to":
+		a.bucketArg(call, a.write)
+	case pkg == "" && fn == "buckets", pkg == influxdbPath && fn == "buckets", pkg == v1Path:
+		a.fail("%s lists every bucket of the organization", fn)
+	case pkg == secretsPath && fn == "get":
+		a.secretKey(call)
+	case pkg == monitorPath:
+		ref := BucketRef{Name: a.monitoringBucket}
+		a.read[ref] = true
+		a.write[ref] = true
+	}
+}
+
+// callee returns the import path and name of the called function. The path
+// is empty for functions that are not package members.
+func (a *analyzer) callee(e ast.Expression) (pkg, fn string) {
+	switch e := e.(type) {
+	case *ast.Identifier:
+		return "", e.Name
+	case *ast.MemberExpression:
+		obj, ok := e.Object.(*ast.Identifier)
+		if !ok {
+			return "", ""
+		}
+		var prop string
+		switch p := e.Property.(type) {
+		case *ast.Identifier:
+			prop = p.Name
+		case *ast.StringLiteral:
+			prop = p.Value
+		}
+		return a.imports[obj.Name], prop
+	}
+	return "", ""
+}
+
+// bucketArg records the bucket named by the arguments of call in refs.
+func (a *analyzer) bucketArg(call *ast.CallExpression, refs map[BucketRef]bool) {
+	if len(call.Arguments) == 0 {
+		a.fail("call without a bucket")
+		return
+	}
+	args, ok := call.Arguments[0].(*ast.ObjectExpression)
+	if !ok {
+		a.fail("call with computed arguments")
+		return
+	}
+
+	var ref BucketRef
+	for _, p := range args.Properties {
+		key := propertyKey(p.Key)
+		switch key {
+		case "host", "token":
+			// The data is accessed with other credentials.
+			return
+		case "org", "orgID":
+			a.fail("access to the buckets of another organization")
+			return
+		case "bucket", "bucketID":
+			v, ok := a.stringValue(p.Value)
+			if !ok {
+				a.fail("%s is not a string literal", key)
+				return
+			}
+			if key == "bucket" {
+				ref.Name = v
+			} else {
+				ref.ID = v
+			}
+		}
+	}
+	if ref == (BucketRef{}) {
+		a.fail("call without a bucket")
+		return
+	}
+	refs[ref] = true
+}
+
+// secretKey records the key of the secret read by call, if it is known
+// statically.
+func (a *analyzer) secretKey(call *ast.CallExpression) {
+	if k, ok := a.staticSecretKey(call); ok {
+		a.secretKeys[k] = true
+	}
+}
+
+// staticSecretKey returns the key of the secret read by the secrets.get
+// call, if it is known statically.
+func (a *analyzer) staticSecretKey(call *ast.CallExpression) (string, bool) {
+	if len(call.Arguments) == 0 {
+		return "", false
+	}
+	args, ok := call.Arguments[0].(*ast.ObjectExpression)
+	if !ok {
+		return "", false
+	}
+	for _, p := range args.Properties {
+		if propertyKey(p.Key) == "key" {
+			return a.stringValue(p.Value)
+		}
+	}
+	return "", false
+}
+
+func (a *analyzer) stringValue(e ast.Expression) (string, bool) {
+	switch e := e.(type) {
+	case *ast.StringLiteral:
+		return e.Value, true
+	case *ast.Identifier:
+		v, ok := a.strings[e.Name]
+		return v, ok
+	}
+	return "", false
+}
+
+func (a *analyzer) fail(format string, args ...interface{}) {
+	if a.err == nil {
+		a.err = &unscopableError{msg: fmt.Sprintf(format, args...)}
+	}
+}
+
+func propertyKey(k ast.PropertyKey) string {
+	switch k := k.(type) {
+	case *ast.Identifier:
+		return k.Name
+	case *ast.StringLiteral:
+		return k.Value
+	}
+	return ""
+}
+
+func sortedRefs(m map[BucketRef]bool) []BucketRef {
+	refs := make([]BucketRef, 0, len(m))
+	for r := range m {
+		refs = append(refs, r)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Name != refs[j].Name {
+			return refs[i].Name < refs[j].Name
+		}
+		return refs[i].ID < refs[j].ID
+	})
+	return refs
+}
+
+func sortedKeys(m map[string]bool) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
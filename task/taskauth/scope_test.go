@@ -0,0 +1,108 @@
+package taskauth_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/task/taskauth"
+)
+
+func TestAnalyze(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		src  string
+		want *taskauth.Scope
+	}{
+		{
+			name: "from and to",
+			src: `
+option task = {name: "downsample", every: 1h}
+
+from(bucket: "raw")
+	|> range(start: -task.every)
+	|> aggregateWindow(every: 1m, fn: mean)
+	|> to(bucket: "downsampled")
+`,
+			want: &taskauth.Scope{
+				Read:  []taskauth.BucketRef{{Name: "raw"}},
+				Write: []taskauth.BucketRef{{Name: "downsampled"}},
+			},
+		},
+		{
+			name: "variables and bucket IDs",
+			src: `
+import "influxdata/influxdb"
+
+src = "raw"
+
+influxdb.from(bucket: src)
+	|> range(start: -1h)
+	|> to(bucketID: "000000000000000a")
+`,
+			want: &taskauth.Scope{
+				Read:  []taskauth.BucketRef{{Name: "raw"}},
+				Write: []taskauth.BucketRef{{ID: "000000000000000a"}},
+			},
+		},
+		{
+			name: "monitor and secrets",
+			src: `
+import "influxdata/influxdb/monitor"
+import "influxdata/influxdb/secrets"
+
+key = secrets.get(key: "KEY")
+
+monitor.from(start: -1h)
+`,
+			want: &taskauth.Scope{
+				Read:       []taskauth.BucketRef{{Name: "_monitoring"}},
+				Write:      []taskauth.BucketRef{{Name: "_monitoring"}},
+				Secrets:    true,
+				SecretKeys: []string{"KEY"},
+			},
+		},
+		{
+			name: "remote source",
+			src: `
+from(bucket: "remote", host: "https://example.com", token: "t")
+	|> range(start: -1h)
+	|> to(bucket: "local")
+`,
+			want: &taskauth.Scope{
+				Read:  []taskauth.BucketRef{},
+				Write: []taskauth.BucketRef{{Name: "local"}},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := taskauth.Analyze(parser.ParseSource(tt.src), influxdb.DefaultSystemBuckets())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("unexpected scope:\n got: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyze_Unscopable(t *testing.T) {
+	for _, src := range []string{
+		`buckets()`,
+		`from(bucket: "raw", org: "other") |> range(start: -1h)`,
+		`b = "a"
+b = "c"
+from(bucket: b) |> range(start: -1h)`,
+		`import "strings"
+from(bucket: strings.toLower(v: "RAW")) |> range(start: -1h)`,
+		`import "influxdata/influxdb/v1"
+v1.measurements(bucket: "raw")`,
+	} {
+		if _, err := taskauth.Analyze(parser.ParseSource(src), influxdb.DefaultSystemBuckets()); !errors.Is(err, taskauth.ErrUnscopable) {
+			t.Errorf("expected %q to be unscopable, got %v", src, err)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package taskauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/fluxpkg"
+	"github.com/influxdata/influxdb/v2/query"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.TaskService = (*TaskService)(nil)
+
+// TaskService issues a scoped service token to every task it creates and
+// reissues it whenever the script of the task changes.
+type TaskService struct {
+	influxdb.TaskService
+
+	log      *zap.Logger
+	auths    influxdb.AuthorizationService
+	buckets  influxdb.BucketService
+	sb       influxdb.SystemBuckets
+	lang     influxdb.FluxLanguageService
+	resolver *fluxpkg.Resolver
+}
+
+// NewTaskService wraps s. The authorization, bucket and package services
+// must not perform authorization themselves; the permissions granted to a
+// token are checked against the authorizer of the request instead. The
+// bucket service serves the system buckets sb.
+func NewTaskService(log *zap.Logger, s influxdb.TaskService, auths influxdb.AuthorizationService, buckets influxdb.BucketService, sb influxdb.SystemBuckets, lang influxdb.FluxLanguageService, pkgs influxdb.FluxPackageService) *TaskService {
+	return &TaskService{
+		TaskService: s,
+		log:         log,
+		auths:       auths,
+		buckets:     buckets,
+		sb:          sb,
+		lang:        lang,
+		resolver:    fluxpkg.NewResolver(pkgs),
+	}
+}
+
+// CreateTask creates a task and its service token.
+func (s *TaskService) CreateTask(ctx context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+	t, err := s.TaskService.CreateTask(ctx, tc)
+	if err != nil {
+		return nil, err
+	}
+
+	authID, err := s.issueToken(ctx, t)
+	if err != nil {
+		if derr := s.TaskService.DeleteTask(ctx, t.ID); derr != nil {
+			s.log.Error("Failed to remove task without a service token", zap.Stringer("task_id", t.ID), zap.Error(derr))
+		}
+		return nil, err
+	}
+	if !authID.Valid() {
+		return t, nil
+	}
+	return s.TaskService.UpdateTask(ctx, t.ID, influxdb.TaskUpdate{AuthorizationID: &authID})
+}
+
+// UpdateTask updates a task, replacing its service token if its script changed.
+func (s *TaskService) UpdateTask(ctx context.Context, id influxdb.ID, upd influxdb.TaskUpdate) (*influxdb.Task, error) {
+	if upd.Flux == nil && upd.Options.IsZero() {
+		return s.TaskService.UpdateTask(ctx, id, upd)
+	}
+
+	prev, err := s.TaskService.FindTaskByID(influxdb.FindTaskWithoutAuth(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := s.TaskService.UpdateTask(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+
+	authID, err := s.issueToken(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	if t, err = s.TaskService.UpdateTask(ctx, id, influxdb.TaskUpdate{AuthorizationID: &authID}); err != nil {
+		return nil, err
+	}
+
+	s.revokeToken(ctx, prev)
+	return t, nil
+}
+
+// DeleteTask removes a task and its service token.
+func (s *TaskService) DeleteTask(ctx context.Context, id influxdb.ID) error {
+	t, err := s.TaskService.FindTaskByID(influxdb.FindTaskWithoutAuth(ctx), id)
+	if err != nil {
+		return err
+	}
+	if err := s.TaskService.DeleteTask(ctx, id); err != nil {
+		return err
+	}
+	s.revokeToken(ctx, t)
+	return nil
+}
+
+// ReissueToken replaces the service token of the task id with one issued to
+// its current owner in its current organization, as when the task was
+// created.
+func (s *TaskService) ReissueToken(ctx context.Context, id influxdb.ID) (*influxdb.Task, error) {
+	prev, err := s.TaskService.FindTaskByID(influxdb.FindTaskWithoutAuth(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	authID, err := s.issueToken(ctx, prev)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.TaskService.UpdateTask(ctx, id, influxdb.TaskUpdate{AuthorizationID: &authID})
+	if err != nil {
+		return nil, err
+	}
+
+	s.revokeToken(ctx, prev)
+	return t, nil
+}
+
+// issueToken creates a service token for the task, returning its ID. If the
+// buckets accessed by the task cannot be determined, no token is created and
+// the returned ID is invalid. The token keeps the row filters of the
+// authorization of the request, and a task created with row filters must be
+// scoped, since its owner reads every row.
+func (s *TaskService) issueToken(ctx context.Context, t *influxdb.Task) (influxdb.ID, error) {
+	rowFilters := requestRowFilters(ctx)
+
+	perms, err := s.permissions(ctx, t)
+	if errors.Is(err, ErrUnscopable) && len(rowFilters) > 0 {
+		return 0, &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  "a task created with a token restricted by row filters must only access buckets known before it runs",
+			Err:  err,
+		}
+	}
+	if errors.Is(err, ErrUnscopable) {
+		s.log.Info("Task runs with the permissions of its owner", zap.Stringer("task_id", t.ID), zap.Error(err))
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// A token must not grant more than its creator is allowed to do.
+	if _, aerr := icontext.GetAuthorizer(ctx); aerr == nil {
+		if err := authorizer.IsAllowedAll(ctx, perms); err != nil {
+			return 0, err
+		}
+	}
+
+	auth := &influxdb.Authorization{
+		OrgID:       t.OrganizationID,
+		UserID:      t.OwnerID,
+		Status:      influxdb.Active,
+		Description: fmt.Sprintf("service token for task %s (%s)", t.Name, t.ID),
+		Permissions: perms,
+		RowFilters:  rowFilters,
+	}
+	if err := s.auths.CreateAuthorization(ctx, auth); err != nil {
+		return 0, err
+	}
+	return auth.ID, nil
+}
+
+// requestRowFilters returns the row filters of the authorization of the
+// request, if any.
+func requestRowFilters(ctx context.Context) []influxdb.TagRule {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil
+	}
+	if auth, ok := a.(*influxdb.Authorization); ok {
+		return auth.RowFilters
+	}
+	return nil
+}
+
+// revokeToken deletes the service token of t, if it has one.
+func (s *TaskService) revokeToken(ctx context.Context, t *influxdb.Task) {
+	if !t.AuthorizationID.Valid() {
+		return
+	}
+	if err := s.auths.DeleteAuthorization(ctx, t.AuthorizationID); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		s.log.Error("Failed to delete task service token", zap.Stringer("task_id", t.ID), zap.Stringer("authorization_id", t.AuthorizationID), zap.Error(err))
+	}
+}
+
+// permissions returns the permissions needed to run the script of t,
+// including the buckets accessed by the user-defined packages it imports.
+func (s *TaskService) permissions(ctx context.Context, t *influxdb.Task) ([]influxdb.Permission, error) {
+	pkg, err := query.Parse(s.lang, t.Flux)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.resolver.Resolve(ctx, t.OrganizationID, pkg); err != nil {
+		return nil, &unscopableError{msg: err.Error()}
+	}
+	scope, err := Analyze(pkg, s.sb)
+	if err != nil {
+		return nil, err
+	}
+
+	orgID := t.OrganizationID
+	perms := []influxdb.Permission{{
+		Action:   influxdb.ReadAction,
+		Resource: influxdb.Resource{Type: influxdb.OrgsResourceType, ID: &orgID},
+	}}
+	add := func(a influxdb.Action, refs []BucketRef) error {
+		for _, ref := range refs {
+			id, err := s.bucketID(ctx, orgID, ref)
+			if err != nil {
+				return err
+			}
+			p, err := influxdb.NewPermissionAtID(id, a, influxdb.BucketsResourceType, orgID)
+			if err != nil {
+				return err
+			}
+			perms = append(perms, *p)
+		}
+		return nil
+	}
+	if err := add(influxdb.ReadAction, scope.Read); err != nil {
+		return nil, err
+	}
+	if err := add(influxdb.WriteAction, scope.Write); err != nil {
+		return nil, err
+	}
+	if scope.Secrets {
+		p, err := influxdb.NewPermission(influxdb.ReadAction, influxdb.SecretsResourceType, orgID)
+		if err != nil {
+			return nil, err
+		}
+		perms = append(perms, *p)
+	}
+	return perms, nil
+}
+
+// bucketID returns the ID of the referenced bucket in the organization.
+// Buckets that do not exist yet cannot be scoped.
+func (s *TaskService) bucketID(ctx context.Context, orgID influxdb.ID, ref BucketRef) (influxdb.ID, error) {
+	var (
+		b   *influxdb.Bucket
+		err error
+	)
+	if ref.Name != "" {
+		b, err = s.buckets.FindBucketByName(ctx, orgID, ref.Name)
+	} else {
+		var id influxdb.ID
+		if err := id.DecodeFromString(ref.ID); err != nil {
+			return 0, &unscopableError{msg: fmt.Sprintf("invalid bucket ID %q", ref.ID)}
+		}
+		b, err = s.buckets.FindBucketByID(ctx, id)
+		if err == nil && b.OrgID != orgID {
+			return 0, &unscopableError{msg: fmt.Sprintf("bucket %s belongs to another organization", ref.ID)}
+		}
+	}
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return 0, &unscopableError{msg: fmt.Sprintf("bucket %s%s does not exist", ref.Name, ref.ID)}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return b.ID, nil
+}
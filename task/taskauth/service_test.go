@@ -0,0 +1,114 @@
+package taskauth_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	"github.com/influxdata/influxdb/v2/task/taskauth"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTaskService_ReissueToken(t *testing.T) {
+	const (
+		orgID     = influxdb.ID(1)
+		taskID    = influxdb.ID(2)
+		bucketID  = influxdb.ID(3)
+		revokedID = influxdb.ID(4)
+		issuedID  = influxdb.ID(5)
+	)
+	rowFilters := []influxdb.TagRule{{Tag: influxdb.Tag{Key: "tenant", Value: "acme"}, Operator: influxdb.Equal}}
+
+	tests := []struct {
+		name       string
+		flux       string
+		rowFilters []influxdb.TagRule
+		wantErr    bool
+	}{
+		{
+			name: "scoped",
+			flux: `option task = {name: "t", every: 1h}
+from(bucket: "raw") |> range(start: -1h)`,
+		},
+		{
+			name: "scoped with row filters",
+			flux: `option task = {name: "t", every: 1h}
+from(bucket: "raw") |> range(start: -1h)`,
+			rowFilters: rowFilters,
+		},
+		{
+			name: "unscopable with row filters",
+			flux: `option task = {name: "t", every: 1h}
+b = "ra" + "w"
+from(bucket: b) |> range(start: -1h)`,
+			rowFilters: rowFilters,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				created *influxdb.Authorization
+				deleted influxdb.ID
+				updated *influxdb.ID
+			)
+			tasks := mock.NewTaskService()
+			tasks.FindTaskByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Task, error) {
+				return &influxdb.Task{ID: id, OrganizationID: orgID, OwnerID: 6, Flux: tt.flux, AuthorizationID: revokedID}, nil
+			}
+			tasks.UpdateTaskFn = func(ctx context.Context, id influxdb.ID, upd influxdb.TaskUpdate) (*influxdb.Task, error) {
+				updated = upd.AuthorizationID
+				return &influxdb.Task{ID: id, AuthorizationID: *upd.AuthorizationID}, nil
+			}
+			auths := &mock.AuthorizationService{
+				CreateAuthorizationFn: func(ctx context.Context, a *influxdb.Authorization) error {
+					a.ID = issuedID
+					created = a
+					return nil
+				},
+				DeleteAuthorizationFn: func(ctx context.Context, id influxdb.ID) error {
+					deleted = id
+					return nil
+				},
+			}
+			buckets := &mock.BucketService{
+				FindBucketByNameFn: func(ctx context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+					return &influxdb.Bucket{ID: bucketID, OrgID: orgID, Name: name}, nil
+				},
+			}
+			s := taskauth.NewTaskService(zaptest.NewLogger(t), tasks, auths, buckets, influxdb.DefaultSystemBuckets(), fluxlang.DefaultService, nil)
+
+			ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+				Status:      influxdb.Active,
+				Permissions: influxdb.OperPermissions(),
+				RowFilters:  tt.rowFilters,
+			})
+			_, err := s.ReissueToken(ctx, taskID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if updated != nil || deleted.Valid() {
+					t.Error("expected the task and its token to be left as they were")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if created == nil || updated == nil || *updated != issuedID {
+				t.Fatalf("expected the task to be updated with a new token, got %v", updated)
+			}
+			if len(created.RowFilters) != len(tt.rowFilters) {
+				t.Errorf("expected the token to keep the row filters of the request, got %v", created.RowFilters)
+			}
+			if deleted != revokedID {
+				t.Errorf("expected the previous token to be revoked, got %s", deleted)
+			}
+		})
+	}
+}
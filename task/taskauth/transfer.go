@@ -0,0 +1,56 @@
+package taskauth
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.ResourceTransferService = (*ResourceTransferService)(nil)
+
+// ResourceTransferService reissues the service token of a task whose owner or
+// organization changes. The token of the task is revoked by the transfer, and
+// the task does not run until it is reissued.
+type ResourceTransferService struct {
+	influxdb.ResourceTransferService
+
+	tasks *TaskService
+}
+
+// NewResourceTransferService wraps s, reissuing the tokens of tasks with tasks.
+func NewResourceTransferService(s influxdb.ResourceTransferService, tasks *TaskService) *ResourceTransferService {
+	return &ResourceTransferService{
+		ResourceTransferService: s,
+		tasks:                   tasks,
+	}
+}
+
+// TransferResourceOwnership makes the user the owner of the resource.
+func (s *ResourceTransferService) TransferResourceOwnership(ctx context.Context, rt influxdb.ResourceType, id, userID influxdb.ID) error {
+	if err := s.ResourceTransferService.TransferResourceOwnership(ctx, rt, id, userID); err != nil {
+		return err
+	}
+	return s.reissueToken(ctx, rt, id)
+}
+
+// MoveResource moves the resource to the organization.
+func (s *ResourceTransferService) MoveResource(ctx context.Context, rt influxdb.ResourceType, id, orgID influxdb.ID) error {
+	if err := s.ResourceTransferService.MoveResource(ctx, rt, id, orgID); err != nil {
+		return err
+	}
+	return s.reissueToken(ctx, rt, id)
+}
+
+func (s *ResourceTransferService) reissueToken(ctx context.Context, rt influxdb.ResourceType, id influxdb.ID) error {
+	if rt != influxdb.TasksResourceType {
+		return nil
+	}
+	if _, err := s.tasks.ReissueToken(ctx, id); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.ErrorCode(err),
+			Msg:  "the task was transferred, but it cannot run until its service token is reissued by updating it",
+			Err:  err,
+		}
+	}
+	return nil
+}
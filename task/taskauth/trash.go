@@ -0,0 +1,44 @@
+package taskauth
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.TrashService = (*TrashService)(nil)
+
+// TrashService issues a service token to a task restored from the trash, its
+// token having been revoked when it was deleted.
+type TrashService struct {
+	influxdb.TrashService
+
+	tasks *TaskService
+}
+
+// NewTrashService wraps s, issuing the tokens of restored tasks with tasks.
+func NewTrashService(s influxdb.TrashService, tasks *TaskService) *TrashService {
+	return &TrashService{
+		TrashService: s,
+		tasks:        tasks,
+	}
+}
+
+// RestoreResource restores a trashed resource.
+func (s *TrashService) RestoreResource(ctx context.Context, id influxdb.ID) (*influxdb.TrashedResource, error) {
+	tr, err := s.TrashService.RestoreResource(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tr.ResourceType != influxdb.TasksResourceType {
+		return tr, nil
+	}
+	if _, err := s.tasks.ReissueToken(ctx, tr.ResourceID); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.ErrorCode(err),
+			Msg:  "the task was restored, but it cannot run until its service token is reissued by updating it",
+			Err:  err,
+		}
+	}
+	return tr, nil
+}
@@ -47,6 +47,13 @@ var (
 		Msg:  "run not found",
 	}
 
+	// ErrTaskTokenInactive is returned when running a task whose service
+	// token was deleted or deactivated.
+	ErrTaskTokenInactive = &Error{
+		Code: EForbidden,
+		Msg:  "task service token is inactive or deleted",
+	}
+
 	ErrRunKeyNotFound = &Error{
 		Code: ENotFound,
 		Msg:  "run key not found",
@@ -166,3 +166,26 @@ func TestParseRequestStillQueuedError(t *testing.T) {
 		t.Fatalf("%q should have parsed to %v, but got %v", validMsg, e, err)
 	}
 }
+
+func TestLogFilter_Page(t *testing.T) {
+	logs := []*platform.Log{{Message: "0"}, {Message: "1"}, {Message: "2"}}
+	for _, tt := range []struct {
+		offset, limit int
+		want          []string
+	}{
+		{want: []string{"0", "1", "2"}},
+		{offset: 1, want: []string{"1", "2"}},
+		{limit: 2, want: []string{"0", "1"}},
+		{offset: 1, limit: 1, want: []string{"1"}},
+		{offset: 3, want: []string{}},
+	} {
+		f := platform.LogFilter{Offset: tt.offset, Limit: tt.limit}
+		got := []string{}
+		for _, l := range f.Page(logs) {
+			got = append(got, l.Message)
+		}
+		if !cmp.Equal(got, tt.want) {
+			t.Errorf("unexpected page at offset %d with limit %d: %s", tt.offset, tt.limit, cmp.Diff(tt.want, got))
+		}
+	}
+}
@@ -23,6 +23,11 @@ var (
 		Msg:  "system buckets cannot be deleted",
 	}
 
+	errArchiveSystemBucket = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "system buckets cannot be archived",
+	}
+
 	ErrBucketNotFound = &influxdb.Error{
 		Code: influxdb.ENotFound,
 		Msg:  "bucket not found",
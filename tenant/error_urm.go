@@ -49,6 +49,14 @@ func ErrUnprocessableMapping(err error) *influxdb.Error {
 	}
 }
 
+// ErrLastOwner is used when the last owner of a resource would be removed.
+func ErrLastOwner(m *influxdb.UserResourceMapping) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  fmt.Sprintf("user %s is the last owner of %s %s, add another owner before removing it", m.UserID, m.ResourceType, m.ResourceID),
+	}
+}
+
 // NonUniqueMappingError is an internal error when a user already has
 // been mapped to a resource
 func NonUniqueMappingError(userID influxdb.ID) error {
@@ -112,6 +112,9 @@ func (s *BucketClientService) FindBuckets(ctx context.Context, filter influxdb.B
 	if filter.Name != nil {
 		params = append(params, [2]string{"name", (*filter.Name)})
 	}
+	for _, m := range filter.QueryParams()["metadata"] {
+		params = append(params, [2]string{"metadata", m})
+	}
 
 	var bs bucketsResponse
 	err := s.Client.
@@ -10,6 +10,9 @@ import (
 
 type UserResourceMappingClient struct {
 	Client *httpc.Client
+	// ResourceType is the type of the resources of the mappings deleted by
+	// DeleteUserResourceMapping. It defaults to organizations.
+	ResourceType influxdb.ResourceType
 }
 
 // CreateUserResourceMapping will create a user resource mapping
@@ -48,9 +51,32 @@ func (s *UserResourceMappingClient) FindUserResourceMappings(ctx context.Context
 	return urs, len(urs), nil
 }
 
-// DeleteUserResourceMapping will delete user resource mapping based in criteria.
+// DeleteUserResourceMapping deletes the mapping of the user to the resource
+// of type s.ResourceType. Use DeleteMapping to delete the mappings of
+// resources of other types.
 func (s *UserResourceMappingClient) DeleteUserResourceMapping(ctx context.Context, resourceID influxdb.ID, userID influxdb.ID) error {
-	urlPath := resourceIDUserPath(influxdb.OrgsResourceType, resourceID, influxdb.Member, userID)
+	rt := s.ResourceType
+	if rt == "" {
+		rt = influxdb.OrgsResourceType
+	}
+	// The mapping is deleted whatever the user type of the path, so owners
+	// are deleted through the members path too.
+	return s.DeleteMapping(ctx, &influxdb.UserResourceMapping{
+		ResourceType: rt,
+		ResourceID:   resourceID,
+		UserType:     influxdb.Member,
+		UserID:       userID,
+	})
+}
+
+// DeleteMapping deletes the mapping m of the user to the resource, using its
+// resource type and user type.
+func (s *UserResourceMappingClient) DeleteMapping(ctx context.Context, m *influxdb.UserResourceMapping) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	urlPath := resourceIDUserPath(m.ResourceType, m.ResourceID, m.UserType, m.UserID)
 	return s.Client.
 		Delete(urlPath).
 		Do(ctx)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-chi/chi"
 	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"go.uber.org/zap"
 )
@@ -182,6 +183,9 @@ func (h *urmHandler) deleteURM(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.force {
+		ctx = icontext.SetForceOwnerRemoval(ctx)
+	}
 	if err := h.svc.DeleteUserResourceMapping(ctx, req.resourceID, req.userID); err != nil {
 		h.api.Err(w, r, err)
 		return
@@ -194,6 +198,7 @@ func (h *urmHandler) deleteURM(w http.ResponseWriter, r *http.Request) {
 type deleteRequest struct {
 	userID     influxdb.ID
 	resourceID influxdb.ID
+	force      bool
 }
 
 func (h *urmHandler) decodeDeleteRequest(ctx context.Context, r *http.Request) (*deleteRequest, error) {
@@ -226,6 +231,7 @@ func (h *urmHandler) decodeDeleteRequest(ctx context.Context, r *http.Request) (
 	return &deleteRequest{
 		userID:     uid,
 		resourceID: rid,
+		force:      r.URL.Query().Get("force") == "true",
 	}, nil
 }
 
@@ -491,3 +491,67 @@ func TestUserResourceMappingService_Client(t *testing.T) {
 		}
 	}
 }
+
+func TestUserResourceMappingService_ClientDelete(t *testing.T) {
+	resourceID := itesting.MustIDBase16("0000000000000099")
+	userID := itesting.MustIDBase16("0000000000000001")
+
+	var deleted []string
+	urmSvc := &mock.UserResourceMappingService{
+		DeleteMappingFn: func(ctx context.Context, rid, uid influxdb.ID) error {
+			deleted = append(deleted, fmt.Sprintf("%s/%s", rid, uid))
+			return nil
+		},
+	}
+
+	router := chi.NewRouter()
+	for _, rt := range []influxdb.ResourceType{influxdb.BucketsResourceType, influxdb.OrgsResourceType} {
+		h := tenant.NewURMHandler(zaptest.NewLogger(t), rt, "id", mock.NewUserService(), urmSvc)
+		router.Mount(fmt.Sprintf("/api/v2/%s/{id}/members", rt), h)
+		router.Mount(fmt.Sprintf("/api/v2/%s/{id}/owners", rt), h)
+	}
+	s := httptest.NewServer(router)
+	defer s.Close()
+	ctx := context.Background()
+
+	httpClient, err := ihttp.NewHTTPClient(s.URL, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("delete mapping uses the resource and user types", func(t *testing.T) {
+		deleted = nil
+		c := tenant.UserResourceMappingClient{Client: httpClient}
+		err := c.DeleteMapping(ctx, &influxdb.UserResourceMapping{
+			ResourceType: influxdb.BucketsResourceType,
+			ResourceID:   resourceID,
+			UserType:     influxdb.Owner,
+			UserID:       userID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{resourceID.String() + "/" + userID.String()}; !cmp.Equal(want, deleted) {
+			t.Errorf("unexpected deleted mappings:\n%s", cmp.Diff(want, deleted))
+		}
+	})
+
+	t.Run("delete user resource mapping uses the resource type of the client", func(t *testing.T) {
+		deleted = nil
+		c := tenant.UserResourceMappingClient{Client: httpClient, ResourceType: influxdb.BucketsResourceType}
+		if err := c.DeleteUserResourceMapping(ctx, resourceID, userID); err != nil {
+			t.Fatal(err)
+		}
+		if len(deleted) != 1 {
+			t.Errorf("expected 1 deleted mapping, got %d", len(deleted))
+		}
+	})
+
+	t.Run("delete mapping requires the types", func(t *testing.T) {
+		c := tenant.UserResourceMappingClient{Client: httpClient}
+		err := c.DeleteMapping(ctx, &influxdb.UserResourceMapping{ResourceID: resourceID, UserID: userID})
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
@@ -20,19 +20,66 @@ type BucketHandler struct {
 	log       *zap.Logger
 	bucketSvc influxdb.BucketService
 	labelSvc  influxdb.LabelService // we may need this for now but we dont want it perminantly
+	orgSvc    influxdb.OrganizationService
+	// systemBuckets are listed by the /api/v2/buckets/system route.
+	systemBuckets influxdb.SystemBuckets
 }
 
 const (
 	prefixBuckets = "/api/v2/buckets"
 )
 
+// BucketHandlerOption configures optional routes of the bucket handler.
+type BucketHandlerOption func(*bucketHandlerOptions)
+
+type bucketHandlerOptions struct {
+	embedded      map[string]http.Handler
+	orgSvc        influxdb.OrganizationService
+	systemBuckets influxdb.SystemBuckets
+}
+
+// WithBucketOrganizationService resolves the organizations of the buckets
+// created with the name of their organization instead of its ID.
+func WithBucketOrganizationService(orgSvc influxdb.OrganizationService) BucketHandlerOption {
+	return func(o *bucketHandlerOptions) {
+		o.orgSvc = orgSvc
+	}
+}
+
+// WithBucketSystemBuckets sets the system buckets listed by the
+// /api/v2/buckets/system route, the built-in ones by default.
+func WithBucketSystemBuckets(sb influxdb.SystemBuckets) BucketHandlerOption {
+	return func(o *bucketHandlerOptions) {
+		o.systemBuckets = sb
+	}
+}
+
+// WithEmbeddedBucketHandler mounts h beneath /api/v2/buckets/:id/<path>.
+// The mounted handler has the bucket's organization ID set on its context.
+func WithEmbeddedBucketHandler(path string, h http.Handler) BucketHandlerOption {
+	return func(o *bucketHandlerOptions) {
+		o.embedded[path] = h
+	}
+}
+
 // NewHTTPBucketHandler constructs a new http server.
-func NewHTTPBucketHandler(log *zap.Logger, bucketSvc influxdb.BucketService, labelSvc influxdb.LabelService, urmHandler, labelHandler http.Handler) *BucketHandler {
+func NewHTTPBucketHandler(log *zap.Logger, bucketSvc influxdb.BucketService, labelSvc influxdb.LabelService, urmHandler, labelHandler http.Handler, opts ...BucketHandlerOption) *BucketHandler {
+	opt := bucketHandlerOptions{
+		embedded:      make(map[string]http.Handler),
+		systemBuckets: influxdb.DefaultSystemBuckets(),
+	}
+	for _, o := range opts {
+		o(&opt)
+	}
+
 	svr := &BucketHandler{
 		api:       kithttp.NewAPI(kithttp.WithLog(log)),
 		log:       log,
 		bucketSvc: bucketSvc,
 		labelSvc:  labelSvc,
+		orgSvc:    opt.orgSvc,
+
+		systemBuckets: opt.systemBuckets,
 	}
 
 	r := chi.NewRouter()
@@ -46,6 +93,7 @@ func NewHTTPBucketHandler(log *zap.Logger, bucketSvc influxdb.BucketService, lab
 	r.Route("/", func(r chi.Router) {
 		r.Post("/", svr.handlePostBucket)
 		r.Get("/", svr.handleGetBuckets)
+		r.Get("/system", svr.handleGetSystemBuckets)
 
 		r.Route("/{id}", func(r chi.Router) {
 			r.Get("/", svr.handleGetBucket)
@@ -57,6 +105,9 @@ func NewHTTPBucketHandler(log *zap.Logger, bucketSvc influxdb.BucketService, lab
 			mountableRouter.Mount("/members", urmHandler)
 			mountableRouter.Mount("/owners", urmHandler)
 			mountableRouter.Mount("/labels", labelHandler)
+			for path, h := range opt.embedded {
+				mountableRouter.Mount(path, h)
+			}
 		})
 	})
 
@@ -70,13 +121,18 @@ func (h *BucketHandler) Prefix() string {
 
 // bucket is used for serialization/deserialization with duration string syntax.
 type bucket struct {
-	ID                  influxdb.ID     `json:"id,omitempty"`
-	OrgID               influxdb.ID     `json:"orgID,omitempty"`
-	Type                string          `json:"type"`
-	Description         string          `json:"description,omitempty"`
-	Name                string          `json:"name"`
-	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
-	RetentionRules      []retentionRule `json:"retentionRules"`
+	ID                  influxdb.ID             `json:"id,omitempty"`
+	OrgID               influxdb.ID             `json:"orgID,omitempty"`
+	Type                string                  `json:"type"`
+	Description         string                  `json:"description,omitempty"`
+	Name                string                  `json:"name"`
+	RetentionPolicyName string                  `json:"rp,omitempty"` // This to support v1 sources
+	RetentionRules      []retentionRule         `json:"retentionRules"`
+	WriteWindow         *writeWindow            `json:"writeWindow,omitempty"`
+	DedupWindowSeconds  int64                   `json:"dedupWindowSeconds,omitempty"`
+	WriteSharding       *influxdb.WriteSharding `json:"writeSharding,omitempty"`
+	State               influxdb.BucketState    `json:"state,omitempty"`
+	Metadata            map[string]string       `json:"metadata,omitempty"`
 	influxdb.CRUDLog
 }
 
@@ -98,6 +154,42 @@ func (rr *retentionRule) RetentionPeriod() (time.Duration, error) {
 	return t, nil
 }
 
+// writeWindow is the range of timestamps, around the time of a write, of the
+// points that can be written to a bucket. A zero bound accepts any point.
+type writeWindow struct {
+	PastSeconds   int64 `json:"pastSeconds,omitempty"`
+	FutureSeconds int64 `json:"futureSeconds,omitempty"`
+}
+
+func newWriteWindow(past, future time.Duration) *writeWindow {
+	if past <= 0 && future <= 0 {
+		return nil
+	}
+	return &writeWindow{
+		PastSeconds:   int64(past.Round(time.Second) / time.Second),
+		FutureSeconds: int64(future.Round(time.Second) / time.Second),
+	}
+}
+
+func (ww *writeWindow) OK() error {
+	if ww != nil && (ww.PastSeconds < 0 || ww.FutureSeconds < 0) {
+		return &influxdb.Error{
+			Code: influxdb.EUnprocessableEntity,
+			Msg:  "write window seconds must not be negative",
+		}
+	}
+	return nil
+}
+
+// Windows returns the past and future windows, which are zero for a nil
+// write window.
+func (ww *writeWindow) Windows() (past, future time.Duration) {
+	if ww == nil {
+		return 0, 0
+	}
+	return time.Duration(ww.PastSeconds) * time.Second, time.Duration(ww.FutureSeconds) * time.Second
+}
+
 func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 	if b == nil {
 		return nil, nil
@@ -116,6 +208,14 @@ func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 		}
 	}
 
+	if err := b.WriteWindow.OK(); err != nil {
+		return nil, err
+	}
+	if err := influxdb.ValidBucketOptions(time.Duration(b.DedupWindowSeconds)*time.Second, b.WriteSharding, b.Metadata); err != nil {
+		return nil, err
+	}
+	past, future := b.WriteWindow.Windows()
+
 	return &influxdb.Bucket{
 		ID:                  b.ID,
 		OrgID:               b.OrgID,
@@ -124,6 +224,12 @@ func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 		Name:                b.Name,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     d,
+		WritePastWindow:     past,
+		WriteFutureWindow:   future,
+		DedupWindow:         time.Duration(b.DedupWindowSeconds) * time.Second,
+		WriteSharding:       b.WriteSharding.IfEnabled(),
+		State:               b.State,
+		Metadata:            b.Metadata,
 		CRUDLog:             b.CRUDLog,
 	}, nil
 }
@@ -150,6 +256,11 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 		Description:         pb.Description,
 		RetentionPolicyName: pb.RetentionPolicyName,
 		RetentionRules:      rules,
+		WriteWindow:         newWriteWindow(pb.WritePastWindow, pb.WriteFutureWindow),
+		DedupWindowSeconds:  int64(pb.DedupWindow.Round(time.Second) / time.Second),
+		WriteSharding:       pb.WriteSharding,
+		State:               pb.State,
+		Metadata:            pb.Metadata,
 		CRUDLog:             pb.CRUDLog,
 	}
 }
@@ -159,6 +270,38 @@ type bucketUpdate struct {
 	Name           *string         `json:"name,omitempty"`
 	Description    *string         `json:"description,omitempty"`
 	RetentionRules []retentionRule `json:"retentionRules,omitempty"`
+	// WriteWindow replaces the bounds of the write window of the bucket
+	// that are set.
+	WriteWindow *writeWindowUpdate `json:"writeWindow,omitempty"`
+	// DedupWindowSeconds replaces the dedup window of the bucket when set.
+	DedupWindowSeconds *int64 `json:"dedupWindowSeconds,omitempty"`
+	// WriteSharding replaces the write sharding of the bucket when set.
+	WriteSharding *influxdb.WriteSharding `json:"writeSharding,omitempty"`
+	// State archives the bucket, or makes it active again, when set.
+	State *influxdb.BucketState `json:"state,omitempty"`
+	// Metadata replaces the metadata of the bucket when set; an empty
+	// object removes it.
+	Metadata map[string]string `json:"metadata"`
+}
+
+// writeWindowUpdate updates the bounds of a write window that are set; a
+// zero bound removes it.
+type writeWindowUpdate struct {
+	PastSeconds   *int64 `json:"pastSeconds,omitempty"`
+	FutureSeconds *int64 `json:"futureSeconds,omitempty"`
+}
+
+func (ww *writeWindowUpdate) OK() error {
+	if ww == nil {
+		return nil
+	}
+	if (ww.PastSeconds != nil && *ww.PastSeconds < 0) || (ww.FutureSeconds != nil && *ww.FutureSeconds < 0) {
+		return &influxdb.Error{
+			Code: influxdb.EUnprocessableEntity,
+			Msg:  "write window seconds must not be negative",
+		}
+	}
+	return nil
 }
 
 func (b *bucketUpdate) OK() error {
@@ -168,7 +311,22 @@ func (b *bucketUpdate) OK() error {
 			return err
 		}
 	}
-	return nil
+	var dedup time.Duration
+	if b.DedupWindowSeconds != nil {
+		dedup = time.Duration(*b.DedupWindowSeconds) * time.Second
+	}
+	if err := influxdb.ValidBucketOptions(dedup, b.WriteSharding, b.Metadata); err != nil {
+		return err
+	}
+	if b.State != nil {
+		if err := b.State.Valid(); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EUnprocessableEntity,
+				Msg:  err.Error(),
+			}
+		}
+	}
+	return b.WriteWindow.OK()
 }
 
 func (b *bucketUpdate) toInfluxDB() *influxdb.BucketUpdate {
@@ -182,11 +340,29 @@ func (b *bucketUpdate) toInfluxDB() *influxdb.BucketUpdate {
 		d, _ = b.RetentionRules[0].RetentionPeriod()
 	}
 
-	return &influxdb.BucketUpdate{
+	upd := &influxdb.BucketUpdate{
 		Name:            b.Name,
 		Description:     b.Description,
 		RetentionPeriod: &d,
 	}
+	if ww := b.WriteWindow; ww != nil {
+		if ww.PastSeconds != nil {
+			past := time.Duration(*ww.PastSeconds) * time.Second
+			upd.WritePastWindow = &past
+		}
+		if ww.FutureSeconds != nil {
+			future := time.Duration(*ww.FutureSeconds) * time.Second
+			upd.WriteFutureWindow = &future
+		}
+	}
+	if b.DedupWindowSeconds != nil {
+		dedup := time.Duration(*b.DedupWindowSeconds) * time.Second
+		upd.DedupWindow = &dedup
+	}
+	upd.WriteSharding = b.WriteSharding
+	upd.State = b.State
+	upd.Metadata = b.Metadata
+	return upd
 }
 
 func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
@@ -207,6 +383,25 @@ func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
 			EverySeconds: d,
 		})
 	}
+
+	if pb.WritePastWindow != nil || pb.WriteFutureWindow != nil {
+		up.WriteWindow = &writeWindowUpdate{}
+		if pb.WritePastWindow != nil {
+			past := int64(pb.WritePastWindow.Round(time.Second) / time.Second)
+			up.WriteWindow.PastSeconds = &past
+		}
+		if pb.WriteFutureWindow != nil {
+			future := int64(pb.WriteFutureWindow.Round(time.Second) / time.Second)
+			up.WriteWindow.FutureSeconds = &future
+		}
+	}
+	if pb.DedupWindow != nil {
+		seconds := int64(pb.DedupWindow.Round(time.Second) / time.Second)
+		up.DedupWindowSeconds = &seconds
+	}
+	up.WriteSharding = pb.WriteSharding
+	up.State = pb.State
+	up.Metadata = pb.Metadata
 	return up
 }
 
@@ -267,6 +462,18 @@ func (h *BucketHandler) handlePostBucket(w http.ResponseWriter, r *http.Request)
 		h.api.Err(w, r, err)
 		return
 	}
+	if !b.OrgID.Valid() {
+		if h.orgSvc == nil {
+			h.api.Err(w, r, errOrgIDRequired)
+			return
+		}
+		org, err := influxdb.ResolveOrganization(r.Context(), h.orgSvc, "", b.Org)
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		b.OrgID = org.ID
+	}
 
 	bucket := b.toInfluxDB()
 
@@ -280,19 +487,26 @@ func (h *BucketHandler) handlePostBucket(w http.ResponseWriter, r *http.Request)
 }
 
 type postBucketRequest struct {
-	OrgID               influxdb.ID     `json:"orgID,omitempty"`
-	Name                string          `json:"name"`
-	Description         string          `json:"description"`
-	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
-	RetentionRules      []retentionRule `json:"retentionRules"`
+	OrgID               influxdb.ID             `json:"orgID,omitempty"`
+	Org                 string                  `json:"org,omitempty"` // the ID or name of the organization, when OrgID is not set
+	Name                string                  `json:"name"`
+	Description         string                  `json:"description"`
+	RetentionPolicyName string                  `json:"rp,omitempty"` // This to support v1 sources
+	RetentionRules      []retentionRule         `json:"retentionRules"`
+	WriteWindow         *writeWindow            `json:"writeWindow,omitempty"`
+	DedupWindowSeconds  int64                   `json:"dedupWindowSeconds,omitempty"`
+	WriteSharding       *influxdb.WriteSharding `json:"writeSharding,omitempty"`
+	Metadata            map[string]string       `json:"metadata,omitempty"`
+}
+
+var errOrgIDRequired = &influxdb.Error{
+	Code: influxdb.EInvalid,
+	Msg:  "organization id must be provided",
 }
 
 func (b *postBucketRequest) OK() error {
-	if !b.OrgID.Valid() {
-		return &influxdb.Error{
-			Code: influxdb.EInvalid,
-			Msg:  "organization id must be provided",
-		}
+	if !b.OrgID.Valid() && b.Org == "" {
+		return influxdb.ErrInvalidOrgFilter
 	}
 
 	// Only support a single retention period for the moment
@@ -305,6 +519,13 @@ func (b *postBucketRequest) OK() error {
 		}
 	}
 
+	if err := b.WriteWindow.OK(); err != nil {
+		return err
+	}
+	if err := influxdb.ValidBucketOptions(time.Duration(b.DedupWindowSeconds)*time.Second, b.WriteSharding, b.Metadata); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -314,6 +535,7 @@ func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
 	if len(b.RetentionRules) > 0 {
 		dur, _ = b.RetentionRules[0].RetentionPeriod()
 	}
+	past, future := b.WriteWindow.Windows()
 
 	return &influxdb.Bucket{
 		OrgID:               b.OrgID,
@@ -322,6 +544,11 @@ func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
 		Type:                influxdb.BucketTypeUser,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     dur,
+		WritePastWindow:     past,
+		WriteFutureWindow:   future,
+		DedupWindow:         time.Duration(b.DedupWindowSeconds) * time.Second,
+		WriteSharding:       b.WriteSharding.IfEnabled(),
+		Metadata:            b.Metadata,
 	}
 }
 
@@ -369,7 +596,7 @@ func (h *BucketHandler) handleDeleteBucket(w http.ResponseWriter, r *http.Reques
 
 // handleGetBuckets is the HTTP handler for the GET /api/v2/buckets route.
 func (h *BucketHandler) handleGetBuckets(w http.ResponseWriter, r *http.Request) {
-	bucketsRequest, err := decodeGetBucketsRequest(r)
+	bucketsRequest, err := decodeGetBucketsRequest(r, h.orgSvc)
 	if err != nil {
 		h.api.Err(w, r, err)
 		return
@@ -390,7 +617,10 @@ type getBucketsRequest struct {
 	opts   influxdb.FindOptions
 }
 
-func decodeGetBucketsRequest(r *http.Request) (*getBucketsRequest, error) {
+// decodeGetBucketsRequest decodes the filter of the buckets to list. Their
+// organization is identified by orgID, or else by org, its ID or name, and
+// is resolved with orgs when it is set.
+func decodeGetBucketsRequest(r *http.Request, orgs influxdb.OrganizationService) (*getBucketsRequest, error) {
 	qp := r.URL.Query()
 	req := &getBucketsRequest{}
 
@@ -401,22 +631,39 @@ func decodeGetBucketsRequest(r *http.Request) (*getBucketsRequest, error) {
 
 	req.opts = *opts
 
-	if orgID := qp.Get("orgID"); orgID != "" {
-		id, err := influxdb.IDFromString(orgID)
+	orgID, org := qp.Get("orgID"), qp.Get("org")
+	switch {
+	case orgID == "" && org == "":
+	case orgs != nil:
+		o, err := influxdb.ResolveOrganization(r.Context(), orgs, orgID, org)
 		if err != nil {
 			return nil, err
 		}
-		req.filter.OrganizationID = id
-	}
-
-	if org := qp.Get("org"); org != "" {
-		req.filter.Org = &org
+		req.filter.OrganizationID = &o.ID
+		req.filter.Org = &o.Name
+	default:
+		if orgID != "" {
+			id, err := influxdb.IDFromString(orgID)
+			if err != nil {
+				return nil, err
+			}
+			req.filter.OrganizationID = id
+		}
+		if org != "" {
+			req.filter.Org = &org
+		}
 	}
 
 	if name := qp.Get("name"); name != "" {
 		req.filter.Name = &name
 	}
 
+	metadata, err := influxdb.ParseBucketMetadataFilter(qp["metadata"])
+	if err != nil {
+		return nil, err
+	}
+	req.filter.Metadata = metadata
+
 	if bucketID := qp.Get("id"); bucketID != "" {
 		id, err := influxdb.IDFromString(bucketID)
 		if err != nil {
@@ -428,6 +675,65 @@ func decodeGetBucketsRequest(r *http.Request) (*getBucketsRequest, error) {
 	return req, nil
 }
 
+type systemBucketResponse struct {
+	Role influxdb.SystemBucketRole `json:"role"`
+	// Stored is false for the system buckets served to organizations that
+	// have none stored.
+	Stored bool `json:"stored"`
+	*bucketResponse
+}
+
+type systemBucketsResponse struct {
+	Links     map[string]string           `json:"links"`
+	Buckets   []*systemBucketResponse     `json:"buckets"`
+	Redirects map[influxdb.ID]influxdb.ID `json:"redirects,omitempty"`
+}
+
+// handleGetSystemBuckets is the HTTP handler for the GET /api/v2/buckets/system route.
+// It returns the system buckets of an organization, under the IDs and names
+// of the deployment.
+func (h *BucketHandler) handleGetSystemBuckets(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID, err := influxdb.IDFromString(r.URL.Query().Get("orgID"))
+	if err != nil {
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID is required",
+			Err:  err,
+		})
+		return
+	}
+
+	sb := h.systemBuckets
+	resp := systemBucketsResponse{
+		Links:     map[string]string{"self": fmt.Sprintf("%s/system?orgID=%s", prefixBuckets, orgID)},
+		Buckets:   []*systemBucketResponse{},
+		Redirects: sb.Redirects,
+	}
+	for _, sys := range []struct {
+		role influxdb.SystemBucketRole
+		name string
+		id   influxdb.ID
+	}{
+		{influxdb.SystemBucketRoleTasks, sb.TasksName, sb.TasksID},
+		{influxdb.SystemBucketRoleMonitoring, sb.MonitoringName, sb.MonitoringID},
+	} {
+		b, err := h.bucketSvc.FindBucketByName(ctx, *orgID, sys.name)
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		resp.Buckets = append(resp.Buckets, &systemBucketResponse{
+			Role:           sys.role,
+			Stored:         b.ID != sys.id,
+			bucketResponse: NewBucketResponse(b),
+		})
+	}
+
+	h.api.Respond(w, r, http.StatusOK, resp)
+}
+
 // handlePatchBucket is the HTTP handler for the PATCH /api/v2/buckets route.
 func (h *BucketHandler) handlePatchBucket(w http.ResponseWriter, r *http.Request) {
 	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
@@ -0,0 +1,75 @@
+package tenant
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const prefixMeResources = "/api/v2/me/resources"
+
+// MeResourcesHandler is the HTTP API handler of the resources the
+// authenticated user can access.
+type MeResourcesHandler struct {
+	chi.Router
+	api         *kithttp.API
+	log         *zap.Logger
+	resourceSvc influxdb.AccessibleResourceService
+}
+
+// NewHTTPMeResourcesHandler constructs a new http server.
+func NewHTTPMeResourcesHandler(log *zap.Logger, resourceSvc influxdb.AccessibleResourceService) *MeResourcesHandler {
+	h := &MeResourcesHandler{
+		api:         kithttp.NewAPI(kithttp.WithLog(log)),
+		log:         log,
+		resourceSvc: resourceSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+	r.Get("/", h.handleGetMeResources)
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *MeResourcesHandler) Prefix() string {
+	return prefixMeResources
+}
+
+type meResourcesResponse struct {
+	Resources map[influxdb.ResourceType][]*influxdb.AccessibleResource `json:"resources"`
+}
+
+// handleGetMeResources is the HTTP handler for the GET /api/v2/me/resources route.
+func (h *MeResourcesHandler) handleGetMeResources(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	ps, err := a.PermissionSet()
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	resources, err := h.resourceSvc.FindAccessibleResources(ctx, a.GetUserID(), ps)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, meResourcesResponse{Resources: resources})
+}
@@ -15,9 +15,21 @@ import (
 // OrgHandler represents an HTTP API handler for organizations.
 type OrgHandler struct {
 	chi.Router
-	api    *kithttp.API
-	log    *zap.Logger
-	orgSvc influxdb.OrganizationService
+	api       *kithttp.API
+	log       *zap.Logger
+	orgSvc    influxdb.OrganizationService
+	freezeSvc influxdb.OrgFreezeService
+}
+
+// OrgHandlerOption configures optional routes of the org handler.
+type OrgHandlerOption func(*OrgHandler)
+
+// WithOrgFreezeService serves the routes freezing and unfreezing the
+// organizations with svc.
+func WithOrgFreezeService(svc influxdb.OrgFreezeService) OrgHandlerOption {
+	return func(h *OrgHandler) {
+		h.freezeSvc = svc
+	}
 }
 
 const (
@@ -29,12 +41,15 @@ func (h *OrgHandler) Prefix() string {
 }
 
 // NewHTTPOrgHandler constructs a new http server.
-func NewHTTPOrgHandler(log *zap.Logger, orgService influxdb.OrganizationService, urm http.Handler, secretHandler http.Handler) *OrgHandler {
+func NewHTTPOrgHandler(log *zap.Logger, orgService influxdb.OrganizationService, urm http.Handler, secretHandler http.Handler, opts ...OrgHandlerOption) *OrgHandler {
 	svr := &OrgHandler{
 		api:    kithttp.NewAPI(kithttp.WithLog(log)),
 		log:    log,
 		orgSvc: orgService,
 	}
+	for _, opt := range opts {
+		opt(svr)
+	}
 
 	r := chi.NewRouter()
 	r.Use(
@@ -51,6 +66,10 @@ func NewHTTPOrgHandler(log *zap.Logger, orgService influxdb.OrganizationService,
 			r.Get("/", svr.handleGetOrg)
 			r.Patch("/", svr.handlePatchOrg)
 			r.Delete("/", svr.handleDeleteOrg)
+			if svr.freezeSvc != nil {
+				r.Post("/freeze", svr.handlePostFreeze)
+				r.Post("/unfreeze", svr.handlePostUnfreeze)
+			}
 
 			// mount embedded resources
 			mountableRouter := r.With(kithttp.ValidResource(svr.api, svr.lookupOrgByID))
@@ -213,6 +232,54 @@ func (h *OrgHandler) handleDeleteOrg(w http.ResponseWriter, r *http.Request) {
 	h.api.Respond(w, r, http.StatusNoContent, nil)
 }
 
+type postFreezeRequest struct {
+	Reason string `json:"reason"`
+}
+
+// handlePostFreeze is the HTTP handler for the POST /api/v2/orgs/:id/freeze route.
+func (h *OrgHandler) handlePostFreeze(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var req postFreezeRequest
+	if r.ContentLength != 0 {
+		if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+	}
+
+	org, err := h.freezeSvc.FreezeOrganization(r.Context(), *id, req.Reason)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Info("Org frozen", zap.String("orgID", org.ID.String()), zap.String("reason", req.Reason))
+
+	h.api.Respond(w, r, http.StatusOK, newOrgResponse(*org))
+}
+
+// handlePostUnfreeze is the HTTP handler for the POST /api/v2/orgs/:id/unfreeze route.
+func (h *OrgHandler) handlePostUnfreeze(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	org, err := h.freezeSvc.UnfreezeOrganization(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Info("Org unfrozen", zap.String("orgID", org.ID.String()))
+
+	h.api.Respond(w, r, http.StatusOK, newOrgResponse(*org))
+}
+
 func (h *OrgHandler) lookupOrgByID(ctx context.Context, id influxdb.ID) (influxdb.ID, error) {
 	_, err := h.orgSvc.FindOrganizationByID(ctx, id)
 	if err != nil {
@@ -22,6 +22,18 @@ type UserHandler struct {
 	log         *zap.Logger
 	userSvc     influxdb.UserService
 	passwordSvc influxdb.PasswordsService
+	settingsSvc influxdb.UserSettingsService
+}
+
+// UserHandlerOption configures a UserHandler.
+type UserHandlerOption func(*UserHandler)
+
+// WithUserSettingsService returns the settings of the authenticated user
+// along with the user from /api/v2/me.
+func WithUserSettingsService(s influxdb.UserSettingsService) UserHandlerOption {
+	return func(h *UserHandler) {
+		h.settingsSvc = s
+	}
 }
 
 const (
@@ -30,13 +42,16 @@ const (
 )
 
 // NewHTTPUserHandler constructs a new http server.
-func NewHTTPUserHandler(log *zap.Logger, userService influxdb.UserService, passwordService influxdb.PasswordsService) *UserHandler {
+func NewHTTPUserHandler(log *zap.Logger, userService influxdb.UserService, passwordService influxdb.PasswordsService, opts ...UserHandlerOption) *UserHandler {
 	svr := &UserHandler{
 		api:         kithttp.NewAPI(kithttp.WithLog(log)),
 		log:         log,
 		userSvc:     userService,
 		passwordSvc: passwordService,
 	}
+	for _, o := range opts {
+		o(svr)
+	}
 
 	r := chi.NewRouter()
 	r.Use(
@@ -236,7 +251,26 @@ func (h *UserHandler) handleGetMe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.api.Respond(w, r, http.StatusOK, newUserResponse(user))
+	if h.settingsSvc == nil {
+		h.api.Respond(w, r, http.StatusOK, newUserResponse(user))
+		return
+	}
+
+	settings, err := h.settingsSvc.FindUserSettings(ctx, id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, &meResponse{
+		UserResponse: newUserResponse(user),
+		Settings:     settings,
+	})
+}
+
+// meResponse is the authenticated user along with their settings.
+type meResponse struct {
+	*UserResponse
+	Settings *influxdb.UserSettings `json:"settings"`
 }
 
 // handleGetUser is the HTTP handler for the GET /api/v2/users/:id route.
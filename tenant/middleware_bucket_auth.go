@@ -15,13 +15,17 @@ var _ influxdb.BucketService = (*AuthedBucketService)(nil)
 // AuthedBucketService wraps a influxdb.BucketService and authorizes actions
 // against it appropriately.
 type AuthedBucketService struct {
-	s influxdb.BucketService
+	s             influxdb.BucketService
+	systemBuckets influxdb.SystemBuckets
 }
 
 // NewAuthedBucketService constructs an instance of an authorizing bucket serivce.
-func NewAuthedBucketService(s influxdb.BucketService) *AuthedBucketService {
+// The system buckets sb are those served by s to organizations that have
+// none stored.
+func NewAuthedBucketService(s influxdb.BucketService, sb influxdb.SystemBuckets) *AuthedBucketService {
 	return &AuthedBucketService{
-		s: s,
+		s:             s,
+		systemBuckets: sb,
 	}
 }
 
@@ -34,7 +38,7 @@ func (s *AuthedBucketService) FindBucketByID(ctx context.Context, id influxdb.ID
 	if err != nil {
 		return nil, err
 	}
-	if _, _, err := authorizer.AuthorizeReadBucket(ctx, b.Type, b.ID, b.OrgID); err != nil {
+	if _, _, err := authorizer.AuthorizeReadBucket(ctx, s.systemBuckets, b.Type, b.ID, b.OrgID); err != nil {
 		return nil, err
 	}
 	return b, nil
@@ -49,7 +53,7 @@ func (s *AuthedBucketService) FindBucketByName(ctx context.Context, orgID influx
 	if err != nil {
 		return nil, err
 	}
-	if _, _, err := authorizer.AuthorizeReadBucket(ctx, b.Type, b.ID, b.OrgID); err != nil {
+	if _, _, err := authorizer.AuthorizeReadBucket(ctx, s.systemBuckets, b.Type, b.ID, b.OrgID); err != nil {
 		return nil, err
 	}
 	return b, nil
@@ -64,7 +68,7 @@ func (s *AuthedBucketService) FindBucket(ctx context.Context, filter influxdb.Bu
 	if err != nil {
 		return nil, err
 	}
-	if _, _, err := authorizer.AuthorizeReadBucket(ctx, b.Type, b.ID, b.OrgID); err != nil {
+	if _, _, err := authorizer.AuthorizeReadBucket(ctx, s.systemBuckets, b.Type, b.ID, b.OrgID); err != nil {
 		return nil, err
 	}
 	return b, nil
@@ -81,7 +85,7 @@ func (s *AuthedBucketService) FindBuckets(ctx context.Context, filter influxdb.B
 	if err != nil {
 		return nil, 0, err
 	}
-	return authorizer.AuthorizeFindBuckets(ctx, bs)
+	return authorizer.AuthorizeFindBuckets(ctx, s.systemBuckets, bs)
 }
 
 // CreateBucket checks to see if the authorizer on context has write access to the global buckets resource.
@@ -89,3 +89,37 @@ func (s *AuthedOrgService) DeleteOrganization(ctx context.Context, id influxdb.I
 	}
 	return s.s.DeleteOrganization(ctx, id)
 }
+
+var _ influxdb.OrgFreezeService = (*AuthedOrgFreezeService)(nil)
+
+// AuthedOrgFreezeService wraps a influxdb.OrgFreezeService so that only
+// operators freeze and unfreeze organizations.
+type AuthedOrgFreezeService struct {
+	s influxdb.OrgFreezeService
+}
+
+// NewAuthedOrgFreezeService constructs an instance of an authorizing org
+// freeze service.
+func NewAuthedOrgFreezeService(s influxdb.OrgFreezeService) *AuthedOrgFreezeService {
+	return &AuthedOrgFreezeService{
+		s: s,
+	}
+}
+
+// FreezeOrganization checks to see if the authorizer on context has operator
+// permissions.
+func (s *AuthedOrgFreezeService) FreezeOrganization(ctx context.Context, id influxdb.ID, reason string) (*influxdb.Organization, error) {
+	if err := authorizer.IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.FreezeOrganization(ctx, id, reason)
+}
+
+// UnfreezeOrganization checks to see if the authorizer on context has
+// operator permissions.
+func (s *AuthedOrgFreezeService) UnfreezeOrganization(ctx context.Context, id influxdb.ID) (*influxdb.Organization, error) {
+	if err := authorizer.IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.UnfreezeOrganization(ctx, id)
+}
@@ -94,6 +94,10 @@ func (s *AuthedURMService) DeleteUserResourceMapping(ctx context.Context, resour
 			}
 		}
 
+		if err := authorizer.AuthorizeForceOwnerRemoval(ctx); err != nil {
+			return err
+		}
+
 		if err := s.s.DeleteUserResourceMapping(ctx, urm.ResourceID, urm.UserID); err != nil {
 			return err
 		}
@@ -32,6 +32,7 @@ type Service struct {
 	influxdb.PasswordsService
 	influxdb.UserResourceMappingService
 	influxdb.OrganizationService
+	influxdb.OrgFreezeService
 	influxdb.BucketService
 }
 
@@ -42,7 +43,9 @@ func NewService(st *Store) *Service {
 	svc.UserService = userSvc
 	svc.PasswordsService = userSvc
 	svc.UserResourceMappingService = NewUserResourceMappingSvc(st, svc)
-	svc.OrganizationService = NewOrganizationSvc(st, svc)
+	orgSvc := NewOrganizationSvc(st, svc)
+	svc.OrganizationService = orgSvc
+	svc.OrgFreezeService = orgSvc
 	svc.BucketService = NewBucketSvc(st, svc)
 
 	return svc
@@ -60,18 +63,23 @@ func NewSystem(store *Store, log *zap.Logger, reg prometheus.Registerer, metricO
 	return ts
 }
 
-func (ts *Service) NewOrgHTTPHandler(log *zap.Logger, secretSvc influxdb.SecretService) *OrgHandler {
-	secretHandler := secret.NewHandler(log, "id", secret.NewAuthedService(secretSvc))
+func (ts *Service) NewOrgHTTPHandler(log *zap.Logger, secretSvc influxdb.SecretService, secretOpts ...secret.HandlerOption) *OrgHandler {
+	secretHandler := secret.NewHandler(log, "id", secret.NewAuthedService(secretSvc), secretOpts...)
 	urmHandler := NewURMHandler(log.With(zap.String("handler", "urm")), influxdb.OrgsResourceType, "id", ts.UserService, NewAuthedURMService(ts.OrganizationService, ts.UserResourceMappingService))
-	return NewHTTPOrgHandler(log.With(zap.String("handler", "org")), NewAuthedOrgService(ts.OrganizationService), urmHandler, secretHandler)
+	return NewHTTPOrgHandler(log.With(zap.String("handler", "org")), NewAuthedOrgService(ts.OrganizationService), urmHandler, secretHandler,
+		WithOrgFreezeService(NewAuthedOrgFreezeService(ts.OrgFreezeService)))
 }
 
-func (ts *Service) NewBucketHTTPHandler(log *zap.Logger, labelSvc influxdb.LabelService) *BucketHandler {
+func (ts *Service) NewBucketHTTPHandler(log *zap.Logger, labelSvc influxdb.LabelService, opts ...BucketHandlerOption) *BucketHandler {
 	urmHandler := NewURMHandler(log.With(zap.String("handler", "urm")), influxdb.BucketsResourceType, "id", ts.UserService, NewAuthedURMService(ts.OrganizationService, ts.UserResourceMappingService))
 	labelHandler := label.NewHTTPEmbeddedHandler(log.With(zap.String("handler", "label")), influxdb.BucketsResourceType, labelSvc)
-	return NewHTTPBucketHandler(log.With(zap.String("handler", "bucket")), NewAuthedBucketService(ts.BucketService), labelSvc, urmHandler, labelHandler)
+	opts = append([]BucketHandlerOption{
+		WithBucketOrganizationService(NewAuthedOrgService(ts.OrganizationService)),
+		WithBucketSystemBuckets(ts.store.systemBuckets),
+	}, opts...)
+	return NewHTTPBucketHandler(log.With(zap.String("handler", "bucket")), NewAuthedBucketService(ts.BucketService, ts.store.systemBuckets), labelSvc, urmHandler, labelHandler, opts...)
 }
 
-func (ts *Service) NewUserHTTPHandler(log *zap.Logger) *UserHandler {
-	return NewHTTPUserHandler(log.With(zap.String("handler", "user")), NewAuthedUserService(ts.UserService), NewAuthedPasswordService(ts.PasswordsService))
+func (ts *Service) NewUserHTTPHandler(log *zap.Logger, opts ...UserHandlerOption) *UserHandler {
+	return NewHTTPUserHandler(log.With(zap.String("handler", "user")), NewAuthedUserService(ts.UserService), NewAuthedPasswordService(ts.PasswordsService), opts...)
 }
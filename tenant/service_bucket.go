@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kv"
 )
 
@@ -92,6 +94,9 @@ func (s *BucketSvc) FindBuckets(ctx context.Context, filter influxdb.BucketFilte
 		if err != nil {
 			return nil, 0, err
 		}
+		if !b.HasMetadata(filter.Metadata) {
+			return []*influxdb.Bucket{}, 0, nil
+		}
 		return []*influxdb.Bucket{b}, 1, nil
 	}
 	if filter.OrganizationID == nil && filter.Org != nil {
@@ -109,13 +114,17 @@ func (s *BucketSvc) FindBuckets(ctx context.Context, filter influxdb.BucketFilte
 			if err != nil {
 				return err
 			}
-			buckets = []*influxdb.Bucket{b}
+			buckets = []*influxdb.Bucket{}
+			if b.HasMetadata(filter.Metadata) {
+				buckets = append(buckets, b)
+			}
 			return nil
 		}
 
 		bs, err := s.store.ListBuckets(ctx, tx, BucketFilter{
 			Name:           filter.Name,
 			OrganizationID: filter.OrganizationID,
+			Metadata:       filter.Metadata,
 		}, opt...)
 		if err != nil {
 			return err
@@ -133,8 +142,8 @@ func (s *BucketSvc) FindBuckets(ctx context.Context, filter influxdb.BucketFilte
 		return buckets, len(buckets), nil
 	}
 
-	// if a name is provided dont fill in system buckets
-	if filter.Name != nil {
+	// if a name or metadata is provided dont fill in system buckets
+	if filter.Name != nil || len(filter.Metadata) > 0 {
 		return buckets, len(buckets), nil
 	}
 
@@ -149,28 +158,29 @@ func (s *BucketSvc) FindBuckets(ctx context.Context, filter influxdb.BucketFilte
 	}
 
 	if needsSystemBuckets {
-		tb := &influxdb.Bucket{
-			ID:              influxdb.TasksSystemBucketID,
-			Type:            influxdb.BucketTypeSystem,
-			Name:            influxdb.TasksSystemBucketName,
-			RetentionPeriod: influxdb.TasksSystemBucketRetention,
-			Description:     "System bucket for task logs",
-		}
+		sb := s.store.systemBuckets
+		buckets = append(buckets, sb.TasksBucket(0), sb.MonitoringBucket(0))
+	}
 
-		buckets = append(buckets, tb)
+	return buckets, len(buckets), nil
+}
 
-		mb := &influxdb.Bucket{
-			ID:              influxdb.MonitoringSystemBucketID,
-			Type:            influxdb.BucketTypeSystem,
-			Name:            influxdb.MonitoringSystemBucketName,
-			RetentionPeriod: influxdb.MonitoringSystemBucketRetention,
-			Description:     "System bucket for monitoring logs",
+// FindBucketNamesAsOf returns the IDs of the buckets of the organization by
+// the names they had at t.
+func (s *BucketSvc) FindBucketNamesAsOf(ctx context.Context, orgID influxdb.ID, t time.Time) (map[string]influxdb.ID, error) {
+	var names map[string]influxdb.ID
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		n, err := s.store.GetBucketNamesAsOf(ctx, tx, orgID, t)
+		if err != nil {
+			return err
 		}
-
-		buckets = append(buckets, mb)
+		names = n
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return buckets, len(buckets), nil
+	return names, nil
 }
 
 // CreateBucket creates a new bucket and sets b.ID with the new identifier.
@@ -184,6 +194,10 @@ func (s *BucketSvc) CreateBucket(ctx context.Context, b *influxdb.Bucket) error
 		return err
 	}
 
+	if err := influxdb.ValidBucketMetadata(b.Metadata); err != nil {
+		return &influxdb.Error{Code: influxdb.EInvalid, Err: err}
+	}
+
 	// make sure the org exists
 	if _, err := s.svc.FindOrganizationByID(ctx, b.OrgID); err != nil {
 		return err
@@ -245,6 +259,8 @@ func (s *BucketSvc) removeResourceRelations(ctx context.Context, resourceID infl
 	if err != nil {
 		return err
 	}
+	// The owners of a deleted resource are removed with it.
+	ctx = icontext.SetForceOwnerRemoval(ctx)
 	for _, urm := range urms {
 		err := s.svc.DeleteUserResourceMapping(ctx, urm.ResourceID, urm.UserID)
 		if err != nil && err != ErrURMNotFound {
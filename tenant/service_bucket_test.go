@@ -2,6 +2,8 @@ package tenant_test
 
 import (
 	"context"
+	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/influxdata/influxdb/v2"
@@ -139,3 +141,89 @@ func TestSystemBucketsInNameFind(t *testing.T) {
 		t.Fatal("failed to return a single bucket when doing a bucket lookup by name")
 	}
 }
+
+func TestBucketFindMetadata(t *testing.T) {
+	s, close, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close()
+
+	ctx := context.Background()
+	storage := tenant.NewStore(s)
+	svc := tenant.NewService(storage)
+	o := &influxdb.Organization{
+		Name: "theorg",
+	}
+	if err := svc.CreateOrganization(ctx, o); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, b := range []*influxdb.Bucket{
+		{OrgID: o.ID, Name: "a", Metadata: map[string]string{"owner": "ops", "classification": "internal"}},
+		{OrgID: o.ID, Name: "b", Metadata: map[string]string{"owner": "ops"}},
+		{OrgID: o.ID, Name: "c", Metadata: map[string]string{"owner": "data", "classification": "internal"}},
+		{OrgID: o.ID, Name: "d"},
+	} {
+		if err := svc.CreateBucket(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names := func(filter influxdb.BucketFilter, opts ...influxdb.FindOptions) []string {
+		t.Helper()
+		bs, _, err := svc.FindBuckets(ctx, filter, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, b := range bs {
+			names = append(names, b.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+	for _, tt := range []struct {
+		filter influxdb.BucketFilter
+		opts   []influxdb.FindOptions
+		want   []string
+	}{
+		{
+			filter: influxdb.BucketFilter{OrganizationID: &o.ID, Metadata: map[string]string{"owner": "ops"}},
+			want:   []string{"a", "b"},
+		},
+		{
+			filter: influxdb.BucketFilter{Metadata: map[string]string{"classification": "internal"}},
+			want:   []string{"a", "c"},
+		},
+		{
+			filter: influxdb.BucketFilter{OrganizationID: &o.ID, Metadata: map[string]string{"classification": "internal"}},
+			opts:   []influxdb.FindOptions{{Offset: 1, Limit: 1}},
+			want:   []string{"c"},
+		},
+		{
+			filter: influxdb.BucketFilter{OrganizationID: &o.ID, Metadata: map[string]string{"owner": "nobody"}},
+		},
+	} {
+		if got := names(tt.filter, tt.opts...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expected buckets %v with metadata %v, got %v", tt.want, tt.filter.Metadata, got)
+		}
+	}
+
+	name := "b"
+	bs, _, err := svc.FindBuckets(ctx, influxdb.BucketFilter{OrganizationID: &o.ID, Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.UpdateBucket(ctx, bs[0].ID, influxdb.BucketUpdate{Metadata: map[string]string{}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(influxdb.BucketFilter{OrganizationID: &o.ID, Name: &name, Metadata: map[string]string{"owner": "ops"}}); len(got) != 0 {
+		t.Errorf("expected the metadata of the bucket to be removed, got buckets %v", got)
+	}
+
+	err = svc.CreateBucket(ctx, &influxdb.Bucket{OrgID: o.ID, Name: "e", Metadata: map[string]string{"a:b": "c"}})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected invalid metadata to be rejected, got %v", err)
+	}
+}
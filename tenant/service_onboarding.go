@@ -6,19 +6,46 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kv"
 )
 
+// OrgTemplater applies the templates configured by the operator to the
+// organizations created by onboarding, giving them a working starting point.
+type OrgTemplater interface {
+	// Names returns the names of the templates.
+	Names() []string
+	// ApplyOrgTemplates applies the templates with names to the organization
+	// orgID on behalf of the user userID.
+	ApplyOrgTemplates(ctx context.Context, orgID, userID influxdb.ID, names []string) error
+}
+
 type OnboardService struct {
-	service *Service
-	authSvc influxdb.AuthorizationService
+	service   *Service
+	authSvc   influxdb.AuthorizationService
+	templater OrgTemplater
 }
 
-func NewOnboardService(svc *Service, as influxdb.AuthorizationService) influxdb.OnboardingService {
-	return &OnboardService{
+// OnboardServiceOption configures an OnboardService.
+type OnboardServiceOption func(*OnboardService)
+
+// WithOrgTemplater applies the templates of t to the organizations created
+// by onboarding.
+func WithOrgTemplater(t OrgTemplater) OnboardServiceOption {
+	return func(s *OnboardService) {
+		s.templater = t
+	}
+}
+
+func NewOnboardService(svc *Service, as influxdb.AuthorizationService, opts ...OnboardServiceOption) influxdb.OnboardingService {
+	s := &OnboardService{
 		service: svc,
 		authSvc: as,
 	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
 }
 
 // IsOnboarding determine if onboarding request is allowed.
@@ -61,6 +88,11 @@ func (s *OnboardService) onboardUser(ctx context.Context, req *influxdb.Onboardi
 		return nil, ErrOnboardInvalid
 	}
 
+	templates, err := s.templates(req.Templates)
+	if err != nil {
+		return nil, err
+	}
+
 	result := &influxdb.OnboardingResults{}
 
 	// create a user
@@ -88,7 +120,7 @@ func (s *OnboardService) onboardUser(ctx context.Context, req *influxdb.Onboardi
 	}
 
 	// create urm
-	err := s.service.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+	err = s.service.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
 		UserID:       user.ID,
 		UserType:     influxdb.Owner,
 		MappingType:  influxdb.UserMappingType,
@@ -126,5 +158,55 @@ func (s *OnboardService) onboardUser(ctx context.Context, req *influxdb.Onboardi
 		OrgID:       result.Org.ID,
 	}
 
-	return result, s.authSvc.CreateAuthorization(ctx, result.Auth)
+	if err := s.authSvc.CreateAuthorization(ctx, result.Auth); err != nil {
+		return result, err
+	}
+
+	if len(templates) > 0 {
+		// the templates are applied with the permissions of the new user
+		ctx = icontext.SetAuthorizer(ctx, result.Auth)
+		if err := s.templater.ApplyOrgTemplates(ctx, org.ID, user.ID, templates); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// templates returns the names of the templates to apply to the organization
+// of an onboarding request for names. All the templates are applied when the
+// request does not name any.
+func (s *OnboardService) templates(names []string) ([]string, error) {
+	if s.templater == nil {
+		if len(names) > 0 {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "no onboarding templates are configured",
+			}
+		}
+		return nil, nil
+	}
+
+	available := s.templater.Names()
+	if len(names) == 0 {
+		return available, nil
+	}
+	for _, name := range names {
+		if !containsString(available, name) {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("unknown onboarding template %q", name),
+			}
+		}
+	}
+	return names, nil
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	influxdb "github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kv"
 	"github.com/influxdata/influxdb/v2/tenant"
 	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
@@ -47,3 +48,64 @@ func initOnboardingService(s kv.Store, f influxdbtesting.OnboardingFields, t *te
 
 	return svc
 }
+
+type fakeOrgTemplater struct {
+	names   []string
+	applied []string
+	authed  bool
+}
+
+func (f *fakeOrgTemplater) Names() []string { return f.names }
+
+func (f *fakeOrgTemplater) ApplyOrgTemplates(ctx context.Context, orgID, userID influxdb.ID, names []string) error {
+	_, err := icontext.GetAuthorizer(ctx)
+	f.authed = err == nil
+	f.applied = append(f.applied, names...)
+	return nil
+}
+
+func TestOnboardService_Templates(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+
+	templater := &fakeOrgTemplater{names: []string{"system", "usage"}}
+	svc := tenant.NewOnboardService(tenant.NewService(tenant.NewStore(s)), kv.NewService(zaptest.NewLogger(t), s), tenant.WithOrgTemplater(templater))
+
+	ctx := context.Background()
+	_, err = svc.OnboardUser(ctx, &influxdb.OnboardingRequest{
+		User:      "user1",
+		Org:       "org1",
+		Bucket:    "bucket1",
+		Templates: []string{"unknown"},
+	})
+	if influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected an unknown template to be invalid, got %v", err)
+	}
+
+	if _, err := svc.OnboardUser(ctx, &influxdb.OnboardingRequest{
+		User:   "user1",
+		Org:    "org1",
+		Bucket: "bucket1",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(templater.applied) != 2 || !templater.authed {
+		t.Errorf("expected all templates to be applied as the new user, got %v", templater.applied)
+	}
+
+	templater.applied = nil
+	if _, err := svc.OnboardUser(ctx, &influxdb.OnboardingRequest{
+		User:      "user2",
+		Org:       "org2",
+		Bucket:    "bucket2",
+		Templates: []string{"usage"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(templater.applied) != 1 || templater.applied[0] != "usage" {
+		t.Errorf("expected only the usage template to be applied, got %v", templater.applied)
+	}
+}
@@ -119,6 +119,9 @@ func (s *OrgSvc) FindOrganizations(ctx context.Context, filter influxdb.Organiza
 
 // Creates a new organization and sets b.ID with the new identifier.
 func (s *OrgSvc) CreateOrganization(ctx context.Context, o *influxdb.Organization) error {
+	// Organizations are created unfrozen, they are frozen by
+	// FreezeOrganization.
+	o.Freeze = nil
 	err := s.store.Update(ctx, func(tx kv.Tx) error {
 		return s.store.CreateOrg(ctx, tx, o)
 	})
@@ -126,10 +129,11 @@ func (s *OrgSvc) CreateOrganization(ctx context.Context, o *influxdb.Organizatio
 		return err
 	}
 
+	sb := s.store.systemBuckets
 	tb := &influxdb.Bucket{
 		OrgID:           o.ID,
 		Type:            influxdb.BucketTypeSystem,
-		Name:            influxdb.TasksSystemBucketName,
+		Name:            sb.TasksName,
 		RetentionPeriod: influxdb.TasksSystemBucketRetention,
 		Description:     "System bucket for task logs",
 	}
@@ -141,7 +145,7 @@ func (s *OrgSvc) CreateOrganization(ctx context.Context, o *influxdb.Organizatio
 	mb := &influxdb.Bucket{
 		OrgID:           o.ID,
 		Type:            influxdb.BucketTypeSystem,
-		Name:            influxdb.MonitoringSystemBucketName,
+		Name:            sb.MonitoringName,
 		RetentionPeriod: influxdb.MonitoringSystemBucketRetention,
 		Description:     "System bucket for monitoring logs",
 	}
@@ -186,6 +190,45 @@ func (s *OrgSvc) UpdateOrganization(ctx context.Context, id influxdb.ID, upd inf
 	return org, nil
 }
 
+// FreezeOrganization freezes the organization for reason. The time it was
+// frozen at is kept when only its reason is updated.
+func (s *OrgSvc) FreezeOrganization(ctx context.Context, id influxdb.ID, reason string) (*influxdb.Organization, error) {
+	var org *influxdb.Organization
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		o, err := s.store.GetOrg(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		freeze := &influxdb.OrgFreeze{
+			Reason:   reason,
+			FrozenAt: s.store.now(),
+		}
+		if o.Freeze != nil {
+			freeze.FrozenAt = o.Freeze.FrozenAt
+		}
+		org, err = s.store.SetOrgFreeze(ctx, tx, id, freeze)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// UnfreezeOrganization unfreezes the organization.
+func (s *OrgSvc) UnfreezeOrganization(ctx context.Context, id influxdb.ID) (*influxdb.Organization, error) {
+	var org *influxdb.Organization
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		var err error
+		org, err = s.store.SetOrgFreeze(ctx, tx, id, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
 // DeleteOrganization removes a organization by ID and its dependent resources.
 func (s *OrgSvc) DeleteOrganization(ctx context.Context, id influxdb.ID) error {
 	// clean up the buckets for this organization
@@ -222,6 +265,8 @@ func (s *OrgSvc) removeResourceRelations(ctx context.Context, resourceID influxd
 	if err != nil {
 		return err
 	}
+	// The owners of a deleted resource are removed with it.
+	ctx = icontext.SetForceOwnerRemoval(ctx)
 	for _, urm := range urms {
 		err := s.svc.DeleteUserResourceMapping(ctx, urm.ResourceID, urm.UserID)
 		if err != nil && err != ErrURMNotFound {
@@ -63,3 +63,58 @@ func initOrganizationService(s kv.Store, f influxdbtesting.OrganizationFields, t
 		}
 	}
 }
+
+func TestOrganizationService_Freeze(t *testing.T) {
+	s, closeS, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeS()
+
+	svc := tenant.NewService(tenant.NewStore(s))
+	ctx := context.Background()
+	org := &influxdb.Organization{Name: "org", Freeze: &influxdb.OrgFreeze{Reason: "created frozen"}}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	if org.Frozen() {
+		t.Fatal("expected the organization to be created unfrozen")
+	}
+
+	frozen, err := svc.FreezeOrganization(ctx, org.ID, "unpaid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !frozen.Frozen() || frozen.Freeze.Reason != "unpaid" || frozen.Freeze.FrozenAt.IsZero() {
+		t.Errorf("unexpected freeze %+v", frozen.Freeze)
+	}
+
+	refrozen, err := svc.FreezeOrganization(ctx, org.ID, "incident")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refrozen.Freeze.Reason != "incident" || !refrozen.Freeze.FrozenAt.Equal(frozen.Freeze.FrozenAt) {
+		t.Errorf("unexpected freeze %+v", refrozen.Freeze)
+	}
+
+	// The freeze is kept by the updates of the organization.
+	desc := "updated"
+	updated, err := svc.UpdateOrganization(ctx, org.ID, influxdb.OrganizationUpdate{Description: &desc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.Frozen() {
+		t.Error("expected the organization to stay frozen")
+	}
+
+	if _, err := svc.UnfreezeOrganization(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	}
+	found, err := svc.FindOrganizationByID(ctx, org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Frozen() {
+		t.Error("expected the organization to be unfrozen")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kv"
 )
 
@@ -44,14 +45,41 @@ func (s *URMSvc) CreateUserResourceMapping(ctx context.Context, m *influxdb.User
 	return err
 }
 
-// DeleteUserResourceMapping deletes a user resource mapping.
+// DeleteUserResourceMapping deletes a user resource mapping. The last owner
+// of a resource is only removed when the removal is forced, see
+// icontext.SetForceOwnerRemoval.
 func (s *URMSvc) DeleteUserResourceMapping(ctx context.Context, resourceID, userID influxdb.ID) error {
 	err := s.store.Update(ctx, func(tx kv.Tx) error {
-		_, err := s.store.GetURM(ctx, tx, resourceID, userID)
+		m, err := s.store.GetURM(ctx, tx, resourceID, userID)
 		if err != nil {
 			return ErrURMNotFound
 		}
+		if err := s.checkLastOwner(ctx, tx, m); err != nil {
+			return err
+		}
 		return s.store.DeleteURM(ctx, tx, resourceID, userID)
 	})
 	return err
 }
+
+// checkLastOwner returns an error if m maps the last owner of its resource,
+// unless its removal is forced.
+func (s *URMSvc) checkLastOwner(ctx context.Context, tx kv.Tx, m *influxdb.UserResourceMapping) error {
+	if m.UserType != influxdb.Owner || icontext.ForceOwnerRemoval(ctx) {
+		return nil
+	}
+	owners, err := s.store.ListURMs(ctx, tx, influxdb.UserResourceMappingFilter{
+		ResourceType: m.ResourceType,
+		ResourceID:   m.ResourceID,
+		UserType:     influxdb.Owner,
+	})
+	if err != nil {
+		return err
+	}
+	for _, o := range owners {
+		if o.UserID != m.UserID {
+			return nil
+		}
+	}
+	return ErrLastOwner(m)
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kv"
 	"github.com/influxdata/influxdb/v2/mock"
 	"github.com/influxdata/influxdb/v2/tenant"
@@ -97,3 +98,36 @@ func initUserResourceMappingService(s kv.Store, f influxdbtesting.UserResourceFi
 		}
 	}
 }
+
+func TestURMService_DeleteLastOwner(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+	svc := tenant.NewService(tenant.NewStore(s))
+	ctx := context.Background()
+
+	for _, m := range []*influxdb.UserResourceMapping{
+		{ResourceID: 1, ResourceType: influxdb.BucketsResourceType, UserID: 100, UserType: influxdb.Owner},
+		{ResourceID: 1, ResourceType: influxdb.BucketsResourceType, UserID: 101, UserType: influxdb.Owner},
+		{ResourceID: 1, ResourceType: influxdb.BucketsResourceType, UserID: 102, UserType: influxdb.Member},
+	} {
+		if err := svc.CreateUserResourceMapping(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := svc.DeleteUserResourceMapping(ctx, 1, 100); err != nil {
+		t.Fatalf("unexpected error removing an owner while another remains: %v", err)
+	}
+	if err := svc.DeleteUserResourceMapping(ctx, 1, 101); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected a conflict removing the last owner, got %v", err)
+	}
+	if err := svc.DeleteUserResourceMapping(ctx, 1, 102); err != nil {
+		t.Fatalf("unexpected error removing a member: %v", err)
+	}
+	if err := svc.DeleteUserResourceMapping(icontext.SetForceOwnerRemoval(ctx), 1, 101); err != nil {
+		t.Fatalf("unexpected error forcing the removal of the last owner: %v", err)
+	}
+}
@@ -21,11 +21,28 @@ type Store struct {
 
 	now func() time.Time
 
+	systemBuckets influxdb.SystemBuckets
+
 	urmByUserIndex *kv.Index
 }
 
 type StoreOption func(*Store)
 
+// WithNow sets the clock that timestamps the changes to the store.
+func WithNow(now func() time.Time) StoreOption {
+	return func(s *Store) {
+		s.now = now
+	}
+}
+
+// WithSystemBuckets sets the system buckets created for and served to
+// organizations, the built-in ones by default.
+func WithSystemBuckets(sb influxdb.SystemBuckets) StoreOption {
+	return func(s *Store) {
+		s.systemBuckets = sb
+	}
+}
+
 func NewStore(kvStore kv.Store, opts ...StoreOption) *Store {
 	store := &Store{
 		kvStore:     kvStore,
@@ -36,6 +53,7 @@ func NewStore(kvStore kv.Store, opts ...StoreOption) *Store {
 			return time.Now().UTC()
 		},
 		urmByUserIndex: kv.NewIndex(kv.URMByUserIndexMapping, kv.WithIndexReadPathEnabled),
+		systemBuckets:  influxdb.DefaultSystemBuckets(),
 	}
 
 	for _, opt := range opts {
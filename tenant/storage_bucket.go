@@ -2,7 +2,9 @@ package tenant
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/json"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kv"
@@ -11,6 +13,8 @@ import (
 var (
 	bucketBucket = []byte("bucketsv1")
 	bucketIndex  = []byte("bucketindexv1")
+
+	bucketNameHistory = []byte("bucketnamehistoryv1")
 )
 
 func bucketIndexKey(o influxdb.ID, name string) ([]byte, error) {
@@ -28,6 +32,91 @@ func bucketIndexKey(o influxdb.ID, name string) ([]byte, error) {
 	return k, nil
 }
 
+// bucketNameHistoryKey is a combination of the orgID, the time the name was
+// taken or released and the name, so that the names of the buckets of an
+// organization are replayed in order.
+func bucketNameHistoryKey(o influxdb.ID, t time.Time, name string) ([]byte, error) {
+	orgID, err := o.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	k := make([]byte, influxdb.IDLength+8+len(name))
+	copy(k, orgID)
+	binary.BigEndian.PutUint64(k[influxdb.IDLength:], uint64(t.UnixNano()))
+	copy(k[influxdb.IDLength+8:], name)
+	return k, nil
+}
+
+// putBucketName records that the name of a bucket of the organization was
+// taken by the bucket id at t, or released if id is invalid.
+func (s *Store) putBucketName(ctx context.Context, tx kv.Tx, orgID influxdb.ID, name string, id influxdb.ID, t time.Time) error {
+	key, err := bucketNameHistoryKey(orgID, t, name)
+	if err != nil {
+		return err
+	}
+
+	v := []byte{}
+	if id.Valid() {
+		if v, err = id.Encode(); err != nil {
+			return err
+		}
+	}
+
+	b, err := tx.Bucket(bucketNameHistory)
+	if err != nil {
+		return err
+	}
+	if err := b.Put(key, v); err != nil {
+		return ErrInternalServiceError(err)
+	}
+	return nil
+}
+
+// GetBucketNamesAsOf returns the IDs of the buckets of the organization by
+// the names they had at t.
+func (s *Store) GetBucketNamesAsOf(ctx context.Context, tx kv.Tx, orgID influxdb.ID, t time.Time) (map[string]influxdb.ID, error) {
+	prefix, err := orgID.Encode()
+	if err != nil {
+		return nil, InvalidOrgIDError(err)
+	}
+
+	b, err := tx.Bucket(bucketNameHistory)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := b.ForwardCursor(prefix, kv.WithCursorPrefix(prefix))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	names := make(map[string]influxdb.ID)
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		if len(k) < influxdb.IDLength+8 {
+			continue
+		}
+		at := int64(binary.BigEndian.Uint64(k[influxdb.IDLength:]))
+		if at > t.UnixNano() {
+			break
+		}
+
+		name := string(k[influxdb.IDLength+8:])
+		if len(v) == 0 {
+			delete(names, name)
+			continue
+		}
+		var id influxdb.ID
+		if err := id.Decode(v); err != nil {
+			return nil, ErrCorruptBucket(err)
+		}
+		names[name] = id
+	}
+	return names, cur.Err()
+}
+
 // uniqueBucketName ensures this bucket is unique for this organization
 func (s *Store) uniqueBucketName(ctx context.Context, tx kv.Tx, oid influxdb.ID, uname string) error {
 	key, err := bucketIndexKey(oid, uname)
@@ -89,6 +178,10 @@ func (s *Store) GetBucket(ctx context.Context, tx kv.Tx, id influxdb.ID) (*influ
 
 	v, err := b.Get(encodedID)
 	if kv.IsNotFound(err) {
+		// follow the redirect of a relocated system bucket
+		if to := s.systemBuckets.RedirectID(id); to != id {
+			return s.GetBucket(ctx, tx, to)
+		}
 		return nil, ErrBucketNotFound
 	}
 
@@ -115,30 +208,24 @@ func (s *Store) GetBucketByName(ctx context.Context, tx kv.Tx, orgID influxdb.ID
 
 	buf, err := idx.Get(key)
 
+	// a renamed system bucket is also found by its other name
+	sb := s.systemBuckets
+	if alias, ok := sb.NameAlias(n); ok && kv.IsNotFound(err) {
+		if key, err = bucketIndexKey(orgID, alias); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Err:  err,
+			}
+		}
+		buf, err = idx.Get(key)
+	}
+
 	// allow for hard coded bucket names that dont exist in the system
 	if kv.IsNotFound(err) {
-		switch n {
-		case influxdb.TasksSystemBucketName:
-			return &influxdb.Bucket{
-				ID:              influxdb.TasksSystemBucketID,
-				Type:            influxdb.BucketTypeSystem,
-				Name:            influxdb.TasksSystemBucketName,
-				RetentionPeriod: influxdb.TasksSystemBucketRetention,
-				Description:     "System bucket for task logs",
-				OrgID:           orgID,
-			}, nil
-		case influxdb.MonitoringSystemBucketName:
-			return &influxdb.Bucket{
-				ID:              influxdb.MonitoringSystemBucketID,
-				Type:            influxdb.BucketTypeSystem,
-				Name:            influxdb.MonitoringSystemBucketName,
-				RetentionPeriod: influxdb.MonitoringSystemBucketRetention,
-				Description:     "System bucket for monitoring logs",
-				OrgID:           orgID,
-			}, nil
-		default:
-			return nil, ErrBucketNotFoundByName(n)
+		if b, ok := sb.BucketByName(orgID, n); ok {
+			return b, nil
 		}
+		return nil, ErrBucketNotFoundByName(n)
 	}
 
 	if err != nil {
@@ -157,6 +244,7 @@ func (s *Store) GetBucketByName(ctx context.Context, tx kv.Tx, orgID influxdb.ID
 type BucketFilter struct {
 	Name           *string
 	OrganizationID *influxdb.ID
+	Metadata       map[string]string
 }
 
 func (s *Store) ListBuckets(ctx context.Context, tx kv.Tx, filter BucketFilter, opt ...influxdb.FindOptions) ([]*influxdb.Bucket, error) {
@@ -178,7 +266,7 @@ func (s *Store) ListBuckets(ctx context.Context, tx kv.Tx, filter BucketFilter,
 
 	// if an organization is passed we need to use the index
 	if filter.OrganizationID != nil {
-		return s.listBucketsByOrg(ctx, tx, *filter.OrganizationID, o)
+		return s.listBucketsByOrg(ctx, tx, *filter.OrganizationID, filter.Metadata, o)
 	}
 
 	b, err := tx.Bucket(bucketBucket)
@@ -199,14 +287,17 @@ func (s *Store) ListBuckets(ctx context.Context, tx kv.Tx, filter BucketFilter,
 	count := 0
 	bs := []*influxdb.Bucket{}
 	for k, v := cursor.Next(); k != nil; k, v = cursor.Next() {
-		if o.Offset != 0 && count < o.Offset {
-			count++
-			continue
-		}
 		b, err := unmarshalBucket(v)
 		if err != nil {
 			return nil, err
 		}
+		if !b.HasMetadata(filter.Metadata) {
+			continue
+		}
+		if o.Offset != 0 && count < o.Offset {
+			count++
+			continue
+		}
 
 		// check to see if it matches the filter
 		if filter.Name == nil || (*filter.Name == b.Name) {
@@ -221,7 +312,7 @@ func (s *Store) ListBuckets(ctx context.Context, tx kv.Tx, filter BucketFilter,
 	return bs, cursor.Err()
 }
 
-func (s *Store) listBucketsByOrg(ctx context.Context, tx kv.Tx, orgID influxdb.ID, o influxdb.FindOptions) ([]*influxdb.Bucket, error) {
+func (s *Store) listBucketsByOrg(ctx context.Context, tx kv.Tx, orgID influxdb.ID, metadata map[string]string, o influxdb.FindOptions) ([]*influxdb.Bucket, error) {
 	// get the prefix key (org id with an empty name)
 	key, err := bucketIndexKey(orgID, "")
 	if err != nil {
@@ -245,7 +336,7 @@ func (s *Store) listBucketsByOrg(ctx context.Context, tx kv.Tx, orgID influxdb.I
 	count := 0
 	bs := []*influxdb.Bucket{}
 	for k, v := cursor.Next(); k != nil; k, v = cursor.Next() {
-		if o.Offset != 0 && count < o.Offset {
+		if len(metadata) == 0 && o.Offset != 0 && count < o.Offset {
 			count++
 			continue
 		}
@@ -265,6 +356,17 @@ func (s *Store) listBucketsByOrg(ctx context.Context, tx kv.Tx, orgID influxdb.I
 			return nil, err
 		}
 
+		// with a metadata filter only the matching buckets are offset
+		if len(metadata) > 0 {
+			if !b.HasMetadata(metadata) {
+				continue
+			}
+			if o.Offset != 0 && count < o.Offset {
+				count++
+				continue
+			}
+		}
+
 		bs = append(bs, b)
 
 		if len(bs) >= o.Limit {
@@ -321,7 +423,7 @@ func (s *Store) CreateBucket(ctx context.Context, tx kv.Tx, bucket *influxdb.Buc
 		return ErrInternalServiceError(err)
 	}
 
-	return nil
+	return s.putBucketName(ctx, tx, bucket.OrgID, bucket.Name, bucket.ID, bucket.CreatedAt)
 }
 
 func (s *Store) UpdateBucket(ctx context.Context, tx kv.Tx, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
@@ -364,6 +466,10 @@ func (s *Store) UpdateBucket(ctx context.Context, tx kv.Tx, id influxdb.ID, upd
 			return nil, ErrInternalServiceError(err)
 		}
 
+		if err := s.putBucketName(ctx, tx, bucket.OrgID, bucket.Name, influxdb.InvalidID(), bucket.UpdatedAt); err != nil {
+			return nil, err
+		}
+
 		bucket.Name = *upd.Name
 		newIkey, err := bucketIndexKey(bucket.OrgID, bucket.Name)
 		if err != nil {
@@ -373,6 +479,10 @@ func (s *Store) UpdateBucket(ctx context.Context, tx kv.Tx, id influxdb.ID, upd
 		if err := idx.Put(newIkey, encodedID); err != nil {
 			return nil, ErrInternalServiceError(err)
 		}
+
+		if err := s.putBucketName(ctx, tx, bucket.OrgID, bucket.Name, bucket.ID, bucket.UpdatedAt); err != nil {
+			return nil, err
+		}
 	}
 
 	if upd.Description != nil {
@@ -383,6 +493,49 @@ func (s *Store) UpdateBucket(ctx context.Context, tx kv.Tx, id influxdb.ID, upd
 		bucket.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.WritePastWindow != nil {
+		bucket.WritePastWindow = *upd.WritePastWindow
+	}
+
+	if upd.WriteFutureWindow != nil {
+		bucket.WriteFutureWindow = *upd.WriteFutureWindow
+	}
+
+	if upd.DedupWindow != nil {
+		bucket.DedupWindow = *upd.DedupWindow
+	}
+
+	if upd.WriteSharding != nil {
+		if err := upd.WriteSharding.Valid(); err != nil {
+			return nil, &influxdb.Error{Code: influxdb.EInvalid, Err: err}
+		}
+		bucket.WriteSharding = nil
+		if upd.WriteSharding.Enabled() {
+			ws := *upd.WriteSharding
+			bucket.WriteSharding = &ws
+		}
+	}
+
+	if upd.State != nil {
+		if err := upd.State.Valid(); err != nil {
+			return nil, &influxdb.Error{Code: influxdb.EInvalid, Err: err}
+		}
+		if *upd.State == influxdb.BucketStateArchived && bucket.Type == influxdb.BucketTypeSystem {
+			return nil, errArchiveSystemBucket
+		}
+		bucket.State = *upd.State
+	}
+
+	if upd.Metadata != nil {
+		if err := influxdb.ValidBucketMetadata(upd.Metadata); err != nil {
+			return nil, &influxdb.Error{Code: influxdb.EInvalid, Err: err}
+		}
+		bucket.Metadata = nil
+		if len(upd.Metadata) > 0 {
+			bucket.Metadata = upd.Metadata
+		}
+	}
+
 	v, err := marshalBucket(bucket)
 	if err != nil {
 		return nil, err
@@ -423,6 +576,10 @@ func (s *Store) DeleteBucket(ctx context.Context, tx kv.Tx, id influxdb.ID) erro
 		return ErrInternalServiceError(err)
 	}
 
+	if err := s.putBucketName(ctx, tx, bucket.OrgID, bucket.Name, influxdb.InvalidID(), s.now()); err != nil {
+		return err
+	}
+
 	b, err := tx.Bucket(bucketBucket)
 	if err != nil {
 		return err
@@ -287,3 +287,126 @@ func TestBucket(t *testing.T) {
 		})
 	}
 }
+
+func TestBucket_SystemBuckets(t *testing.T) {
+	s, closeS, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeS()
+
+	sb := influxdb.DefaultSystemBuckets()
+	sb.TasksID = 0x100
+	sb.TasksName = "_sys_tasks"
+	sb.Redirects = map[influxdb.ID]influxdb.ID{
+		influxdb.TasksSystemBucketID: 0x100,
+		0x200:                        firstBucketID,
+	}
+	if err := sb.Valid(); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := tenant.NewStore(s, tenant.WithSystemBuckets(sb))
+	ts.BucketIDGen = mock.NewIncrementingIDGenerator(firstBucketID)
+	ctx := context.Background()
+
+	// The system bucket of the first organization is stored before the rename.
+	err = ts.Update(ctx, func(tx kv.Tx) error {
+		return ts.CreateBucket(ctx, tx, &influxdb.Bucket{
+			OrgID: firstOrgID,
+			Type:  influxdb.BucketTypeSystem,
+			Name:  influxdb.TasksSystemBucketName,
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ts.View(ctx, func(tx kv.Tx) error {
+		b, err := ts.GetBucketByName(ctx, tx, firstOrgID, "_sys_tasks")
+		require.NoError(t, err)
+		assert.Equal(t, firstBucketID, b.ID, "expected the bucket stored with the default name")
+
+		b, err = ts.GetBucketByName(ctx, tx, secondOrgID, influxdb.TasksSystemBucketName)
+		require.NoError(t, err)
+		assert.Equal(t, influxdb.ID(0x100), b.ID, "expected the relocated bucket served to organizations without one")
+		assert.Equal(t, "_sys_tasks", b.Name)
+
+		b, err = ts.GetBucket(ctx, tx, 0x200)
+		require.NoError(t, err)
+		assert.Equal(t, firstBucketID, b.ID, "expected the redirect to be followed")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBucket_NamesAsOf(t *testing.T) {
+	s, closeS, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeS()
+
+	start := time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	ts := tenant.NewStore(s, tenant.WithNow(func() time.Time { return now }))
+	ts.BucketIDGen = mock.NewIncrementingIDGenerator(firstBucketID)
+	ctx := context.Background()
+
+	update := func(fn func(tx kv.Tx) error) {
+		t.Helper()
+		if err := ts.Update(ctx, fn); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Hour)
+	}
+	update(func(tx kv.Tx) error {
+		if err := ts.CreateBucket(ctx, tx, &influxdb.Bucket{OrgID: firstOrgID, Name: "metrics"}); err != nil {
+			return err
+		}
+		return ts.CreateBucket(ctx, tx, &influxdb.Bucket{OrgID: firstOrgID, Name: "logs"})
+	})
+	update(func(tx kv.Tx) error {
+		name := "metrics_old"
+		_, err := ts.UpdateBucket(ctx, tx, firstBucketID, influxdb.BucketUpdate{Name: &name})
+		return err
+	})
+	update(func(tx kv.Tx) error {
+		if err := ts.CreateBucket(ctx, tx, &influxdb.Bucket{OrgID: firstOrgID, Name: "metrics"}); err != nil {
+			return err
+		}
+		return ts.DeleteBucket(ctx, tx, secondBucketID)
+	})
+
+	for _, tt := range []struct {
+		at   time.Time
+		want map[string]influxdb.ID
+	}{
+		{at: start.Add(-time.Minute), want: map[string]influxdb.ID{}},
+		{at: start, want: map[string]influxdb.ID{"metrics": firstBucketID, "logs": secondBucketID}},
+		{at: start.Add(90 * time.Minute), want: map[string]influxdb.ID{"metrics_old": firstBucketID, "logs": secondBucketID}},
+		{at: now, want: map[string]influxdb.ID{"metrics_old": firstBucketID, "metrics": thirdBucketID}},
+	} {
+		err := ts.View(ctx, func(tx kv.Tx) error {
+			names, err := ts.GetBucketNamesAsOf(ctx, tx, firstOrgID, tt.at)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, names, "unexpected names as of %v", tt.at)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = ts.View(ctx, func(tx kv.Tx) error {
+		names, err := ts.GetBucketNamesAsOf(ctx, tx, secondOrgID, now)
+		require.NoError(t, err)
+		assert.Empty(t, names, "expected the names of other organizations to be separate")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
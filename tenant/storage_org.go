@@ -251,6 +251,37 @@ func (s *Store) UpdateOrg(ctx context.Context, tx kv.Tx, id influxdb.ID, upd inf
 	return u, nil
 }
 
+// SetOrgFreeze freezes the organization with freeze, or unfreezes it if
+// freeze is nil.
+func (s *Store) SetOrgFreeze(ctx context.Context, tx kv.Tx, id influxdb.ID, freeze *influxdb.OrgFreeze) (*influxdb.Organization, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := s.GetOrg(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	o.Freeze = freeze
+	o.SetUpdatedAt(s.now())
+
+	v, err := marshalOrg(o)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := tx.Bucket(organizationBucket)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Put(encodedID, v); err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+
+	return o, nil
+}
+
 func (s *Store) DeleteOrg(ctx context.Context, tx kv.Tx, id influxdb.ID) error {
 	u, err := s.GetOrg(ctx, tx, id)
 	if err != nil {
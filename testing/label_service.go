@@ -79,6 +79,10 @@ func LabelService(
 			name: "DeleteLabelMapping",
 			fn:   DeleteLabelMapping,
 		},
+		{
+			name: "ApplyLabelMappings",
+			fn:   ApplyLabelMappings,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1039,3 +1043,103 @@ func DeleteLabelMapping(
 		})
 	}
 }
+
+func ApplyLabelMappings(
+	init func(LabelFields, *testing.T) (influxdb.LabelService, string, func()),
+	t *testing.T,
+) {
+	type wants struct {
+		err    error
+		labels map[influxdb.ID][]*influxdb.Label
+	}
+
+	label := &influxdb.Label{
+		ID:    MustIDBase16(labelOneID),
+		Name:  "Tag1",
+		OrgID: idOne,
+	}
+	mapping := func(resourceID influxdb.ID) influxdb.LabelMapping {
+		return influxdb.LabelMapping{
+			ResourceID:   resourceID,
+			ResourceType: influxdb.DashboardsResourceType,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		fields LabelFields
+		batch  *influxdb.LabelMappingBatch
+		wants  wants
+	}{
+		{
+			name: "add and remove label mappings",
+			fields: LabelFields{
+				Labels: []*influxdb.Label{label},
+				Mappings: []*influxdb.LabelMapping{
+					{
+						LabelID:      label.ID,
+						ResourceID:   idOne,
+						ResourceType: influxdb.DashboardsResourceType,
+					},
+					{
+						LabelID:      label.ID,
+						ResourceID:   idTwo,
+						ResourceType: influxdb.DashboardsResourceType,
+					},
+				},
+			},
+			batch: &influxdb.LabelMappingBatch{
+				// Resources that already have the label are skipped.
+				Add:    []influxdb.LabelMapping{mapping(idOne), mapping(idThree)},
+				Remove: []influxdb.LabelMapping{mapping(idTwo)},
+			},
+			wants: wants{
+				labels: map[influxdb.ID][]*influxdb.Label{
+					idOne:   {label},
+					idTwo:   {},
+					idThree: {label},
+				},
+			},
+		},
+		{
+			name: "missing label",
+			batch: &influxdb.LabelMappingBatch{
+				Add: []influxdb.LabelMapping{mapping(idOne)},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.ENotFound,
+					Op:   influxdb.OpFindLabelByID,
+					Msg:  influxdb.ErrLabelNotFound,
+				},
+				labels: map[influxdb.ID][]*influxdb.Label{
+					idOne: {},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, opPrefix, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+			tt.batch.SetLabelID(label.ID)
+			err := s.ApplyLabelMappings(ctx, tt.batch)
+			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
+
+			for id, want := range tt.wants.labels {
+				labels, err := s.FindResourceLabels(ctx, influxdb.LabelMappingFilter{
+					ResourceID:   id,
+					ResourceType: influxdb.DashboardsResourceType,
+				})
+				if err != nil {
+					t.Fatalf("failed to retrieve labels: %v", err)
+				}
+				if diff := cmp.Diff(labels, want, labelCmpOptions...); diff != "" {
+					t.Errorf("labels of %s are different -got/+want\ndiff %s", id, diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package testing
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+// NewTestBoltStore returns a store in a temporary bolt file with every
+// migration applied, for the tests of the services storing their resources
+// in the kv store, and the function closing and removing it.
+func NewTestBoltStore(t *testing.T) (kv.SchemaStore, func()) {
+	t.Helper()
+
+	f, err := ioutil.TempFile("", "influxdata-bolt-")
+	if err != nil {
+		t.Fatalf("unable to open temporary boltdb file: %v", err)
+	}
+	f.Close()
+
+	path := f.Name()
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+	s := bolt.NewKVStore(logger, path)
+	if err := s.Open(ctx); err != nil {
+		os.Remove(path)
+		t.Fatal(err)
+	}
+	closeStore := func() {
+		s.Close()
+		os.Remove(path)
+	}
+
+	if err := all.Up(ctx, logger, s); err != nil {
+		closeStore()
+		t.Fatal(err)
+	}
+	return s, closeStore
+}
+
+// NewBucketFinder returns a bucket service finding the buckets, by ID and by
+// the ID, name and organization of the filters. The other buckets are not
+// found.
+func NewBucketFinder(buckets ...*influxdb.Bucket) *mock.BucketService {
+	notFound := &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+
+	s := mock.NewBucketService()
+	s.FindBucketByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		for _, b := range buckets {
+			if b.ID == id {
+				return b, nil
+			}
+		}
+		return nil, notFound
+	}
+	s.FindBucketFn = func(_ context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		for _, b := range buckets {
+			switch {
+			case filter.ID != nil && *filter.ID != b.ID,
+				filter.Name != nil && *filter.Name != b.Name,
+				filter.OrganizationID != nil && *filter.OrganizationID != b.OrgID:
+				continue
+			}
+			return b, nil
+		}
+		return nil, notFound
+	}
+	return s
+}
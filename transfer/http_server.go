@@ -0,0 +1,121 @@
+// Package transfer exposes the API to change the owner or the organization of
+// tasks, dashboards, checks and notification rules.
+package transfer
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixTransfers is the prefix of the resource transfer API.
+	PrefixTransfers = "/api/v2/transfers"
+)
+
+// Handler is the HTTP API handler for resource transfers.
+type Handler struct {
+	chi.Router
+	api         *kithttp.API
+	log         *zap.Logger
+	transferSvc influxdb.ResourceTransferService
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, transferSvc influxdb.ResourceTransferService) *Handler {
+	h := &Handler{
+		api:         kithttp.NewAPI(kithttp.WithLog(log)),
+		log:         log,
+		transferSvc: transferSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Post("/owner", h.handlePostOwner)
+		r.Post("/org", h.handlePostOrg)
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixTransfers
+}
+
+func (h *Handler) decodeTransfer(r *http.Request) (*influxdb.ResourceTransfer, error) {
+	var t influxdb.ResourceTransfer
+	if err := h.api.DecodeJSON(r.Body, &t); err != nil {
+		return nil, err
+	}
+	if !influxdb.IsTransferableResource(t.ResourceType) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "resourceType must be one of tasks, dashboards, checks or notificationRules",
+		}
+	}
+	if !t.ResourceID.Valid() {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "resourceID is required",
+		}
+	}
+	return &t, nil
+}
+
+func (h *Handler) handlePostOwner(w http.ResponseWriter, r *http.Request) {
+	t, err := h.decodeTransfer(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if !t.UserID.Valid() {
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "userID is required",
+		})
+		return
+	}
+
+	if err := h.transferSvc.TransferResourceOwnership(r.Context(), t.ResourceType, t.ResourceID, t.UserID); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Resource ownership transferred", zap.String("resourceType", string(t.ResourceType)), zap.String("resourceID", t.ResourceID.String()), zap.String("userID", t.UserID.String()))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
+
+func (h *Handler) handlePostOrg(w http.ResponseWriter, r *http.Request) {
+	t, err := h.decodeTransfer(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if !t.OrgID.Valid() {
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID is required",
+		})
+		return
+	}
+
+	if err := h.transferSvc.MoveResource(r.Context(), t.ResourceType, t.ResourceID, t.OrgID); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Resource moved", zap.String("resourceType", string(t.ResourceType)), zap.String("resourceID", t.ResourceID.String()), zap.String("orgID", t.OrgID.String()))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
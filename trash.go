@@ -0,0 +1,72 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// TrashRetention is how long deleted resources remain in the trash before they
+// are permanently removed.
+const TrashRetention = 30 * 24 * time.Hour
+
+// ops for trash errors.
+var (
+	OpFindTrashedResourceByID = "FindTrashedResourceByID"
+	OpFindTrashedResources    = "FindTrashedResources"
+	OpTrashResource           = "TrashResource"
+	OpRestoreResource         = "RestoreResource"
+	OpDeleteTrashedResource   = "DeleteTrashedResource"
+)
+
+// TrashedResource is a deleted resource that can still be restored.
+type TrashedResource struct {
+	ID           ID           `json:"id"`
+	OrgID        ID           `json:"orgID"`
+	ResourceType ResourceType `json:"resourceType"`
+	ResourceID   ID           `json:"resourceID"`
+	Name         string       `json:"name"`
+	DeletedBy    ID           `json:"deletedBy,omitempty"`
+	DeletedAt    time.Time    `json:"deletedAt"`
+	ExpiresAt    time.Time    `json:"expiresAt"`
+}
+
+// TrashFilter represents a set of filters that restrict the returned trashed resources.
+type TrashFilter struct {
+	OrgID        *ID
+	ResourceType *ResourceType
+}
+
+// TrashService keeps deleted dashboards, tasks, checks, notification rules
+// and variables so they can be restored.
+type TrashService interface {
+	// TrashResource saves a copy of a resource that is about to be deleted.
+	TrashResource(ctx context.Context, rt ResourceType, id ID) (*TrashedResource, error)
+
+	// FindTrashedResourceByID returns a single trashed resource by ID.
+	FindTrashedResourceByID(ctx context.Context, id ID) (*TrashedResource, error)
+
+	// FindTrashedResources returns the trashed resources that match the filter,
+	// most recently deleted first, and their count.
+	FindTrashedResources(ctx context.Context, filter TrashFilter) ([]*TrashedResource, int, error)
+
+	// RestoreResource recreates a trashed resource with its original ID and
+	// removes it from the trash.
+	RestoreResource(ctx context.Context, id ID) (*TrashedResource, error)
+
+	// DeleteTrashedResource permanently removes a resource from the trash.
+	DeleteTrashedResource(ctx context.Context, id ID) error
+
+	// PurgeTrash permanently removes the resources that expired before t and
+	// returns how many were removed.
+	PurgeTrash(ctx context.Context, t time.Time) (int, error)
+}
+
+// IsTrashableResource reports whether deleted resources of the type are kept
+// in the trash.
+func IsTrashableResource(rt ResourceType) bool {
+	switch rt {
+	case DashboardsResourceType, TasksResourceType, ChecksResourceType, NotificationRuleResourceType, VariablesResourceType:
+		return true
+	}
+	return false
+}
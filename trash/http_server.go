@@ -0,0 +1,166 @@
+package trash
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixTrash is the prefix of the trash API.
+	PrefixTrash = "/api/v2/trash"
+)
+
+// Handler is the HTTP API handler for the trash.
+type Handler struct {
+	chi.Router
+	api      *kithttp.API
+	log      *zap.Logger
+	trashSvc influxdb.TrashService
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, trashSvc influxdb.TrashService) *Handler {
+	h := &Handler{
+		api:      kithttp.NewAPI(kithttp.WithLog(log)),
+		log:      log,
+		trashSvc: trashSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Get("/", h.handleGetTrash)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetTrashedResource)
+			r.Delete("/", h.handleDeleteTrashedResource)
+			r.Post("/restore", h.handlePostRestore)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixTrash
+}
+
+type trashedResourceResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.TrashedResource
+}
+
+func newTrashedResourceResponse(tr *influxdb.TrashedResource) *trashedResourceResponse {
+	return &trashedResourceResponse{
+		Links: map[string]string{
+			"self":    fmt.Sprintf("%s/%s", PrefixTrash, tr.ID),
+			"restore": fmt.Sprintf("%s/%s/restore", PrefixTrash, tr.ID),
+		},
+		TrashedResource: tr,
+	}
+}
+
+type trashResponse struct {
+	Links     map[string]string          `json:"links"`
+	Resources []*trashedResourceResponse `json:"resources"`
+}
+
+func (h *Handler) handleGetTrash(w http.ResponseWriter, r *http.Request) {
+	var filter influxdb.TrashFilter
+	q := r.URL.Query()
+	if v := q.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			h.api.Err(w, r, influxdb.ErrCorruptID(err))
+			return
+		}
+		filter.OrgID = id
+	}
+	if v := q.Get("resourceType"); v != "" {
+		rt := influxdb.ResourceType(v)
+		if !influxdb.IsTrashableResource(rt) {
+			h.api.Err(w, r, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("resources of type %q are not kept in the trash", v),
+			})
+			return
+		}
+		filter.ResourceType = &rt
+	}
+
+	trs, _, err := h.trashSvc.FindTrashedResources(r.Context(), filter)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	resp := trashResponse{
+		Links:     map[string]string{"self": PrefixTrash},
+		Resources: make([]*trashedResourceResponse, 0, len(trs)),
+	}
+	for _, tr := range trs {
+		resp.Resources = append(resp.Resources, newTrashedResourceResponse(tr))
+	}
+	h.api.Respond(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleGetTrashedResource(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	tr, err := h.trashSvc.FindTrashedResourceByID(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newTrashedResourceResponse(tr))
+}
+
+func (h *Handler) handlePostRestore(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	tr, err := h.trashSvc.RestoreResource(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Resource restored", zap.String("resourceType", string(tr.ResourceType)), zap.String("resourceID", tr.ResourceID.String()))
+
+	h.api.Respond(w, r, http.StatusOK, tr)
+}
+
+func (h *Handler) handleDeleteTrashedResource(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrCorruptID(err))
+		return
+	}
+
+	if err := h.trashSvc.DeleteTrashedResource(r.Context(), *id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Trashed resource deleted", zap.String("trashedResourceID", id.String()))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
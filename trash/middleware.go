@@ -0,0 +1,134 @@
+// Package trash keeps deleted dashboards, tasks, checks, notification rules
+// and variables for influxdb.TrashRetention so they can be restored.
+//
+// The services in this package wrap the services of those resources and save
+// a copy of every resource before it is deleted.
+package trash
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+// trashThenDelete saves the resource in the trash and deletes it. The copy
+// is discarded if the resource cannot be deleted.
+func trashThenDelete(ctx context.Context, log *zap.Logger, trash influxdb.TrashService, rt influxdb.ResourceType, id influxdb.ID, del func() error) error {
+	tr, err := trash.TrashResource(ctx, rt, id)
+	if err != nil {
+		return err
+	}
+	if err := del(); err != nil {
+		if terr := trash.DeleteTrashedResource(ctx, tr.ID); terr != nil {
+			log.Error("Failed to discard trashed copy of resource", zap.String("resourceType", string(rt)), zap.Stringer("resourceID", id), zap.Error(terr))
+		}
+		return err
+	}
+	return nil
+}
+
+var _ influxdb.DashboardService = (*DashboardService)(nil)
+
+// DashboardService trashes dashboards before deleting them.
+type DashboardService struct {
+	influxdb.DashboardService
+	log   *zap.Logger
+	trash influxdb.TrashService
+}
+
+// NewDashboardService wraps s.
+func NewDashboardService(log *zap.Logger, s influxdb.DashboardService, trash influxdb.TrashService) *DashboardService {
+	return &DashboardService{DashboardService: s, log: log, trash: trash}
+}
+
+// DeleteDashboard moves a dashboard to the trash.
+func (s *DashboardService) DeleteDashboard(ctx context.Context, id influxdb.ID) error {
+	return trashThenDelete(ctx, s.log, s.trash, influxdb.DashboardsResourceType, id, func() error {
+		return s.DashboardService.DeleteDashboard(ctx, id)
+	})
+}
+
+var _ influxdb.TaskService = (*TaskService)(nil)
+
+// TaskService trashes tasks before deleting them.
+type TaskService struct {
+	influxdb.TaskService
+	log   *zap.Logger
+	trash influxdb.TrashService
+}
+
+// NewTaskService wraps s.
+func NewTaskService(log *zap.Logger, s influxdb.TaskService, trash influxdb.TrashService) *TaskService {
+	return &TaskService{TaskService: s, log: log, trash: trash}
+}
+
+// DeleteTask moves a task to the trash.
+func (s *TaskService) DeleteTask(ctx context.Context, id influxdb.ID) error {
+	return trashThenDelete(ctx, s.log, s.trash, influxdb.TasksResourceType, id, func() error {
+		return s.TaskService.DeleteTask(ctx, id)
+	})
+}
+
+var _ influxdb.CheckService = (*CheckService)(nil)
+
+// CheckService trashes checks before deleting them.
+type CheckService struct {
+	influxdb.CheckService
+	log   *zap.Logger
+	trash influxdb.TrashService
+}
+
+// NewCheckService wraps s.
+func NewCheckService(log *zap.Logger, s influxdb.CheckService, trash influxdb.TrashService) *CheckService {
+	return &CheckService{CheckService: s, log: log, trash: trash}
+}
+
+// DeleteCheck moves a check to the trash.
+func (s *CheckService) DeleteCheck(ctx context.Context, id influxdb.ID) error {
+	return trashThenDelete(ctx, s.log, s.trash, influxdb.ChecksResourceType, id, func() error {
+		return s.CheckService.DeleteCheck(ctx, id)
+	})
+}
+
+var _ influxdb.NotificationRuleStore = (*NotificationRuleStore)(nil)
+
+// NotificationRuleStore trashes notification rules before deleting them.
+type NotificationRuleStore struct {
+	influxdb.NotificationRuleStore
+	log   *zap.Logger
+	trash influxdb.TrashService
+}
+
+// NewNotificationRuleStore wraps s.
+func NewNotificationRuleStore(log *zap.Logger, s influxdb.NotificationRuleStore, trash influxdb.TrashService) *NotificationRuleStore {
+	return &NotificationRuleStore{NotificationRuleStore: s, log: log, trash: trash}
+}
+
+// DeleteNotificationRule moves a notification rule to the trash.
+func (s *NotificationRuleStore) DeleteNotificationRule(ctx context.Context, id influxdb.ID) error {
+	return trashThenDelete(ctx, s.log, s.trash, influxdb.NotificationRuleResourceType, id, func() error {
+		return s.NotificationRuleStore.DeleteNotificationRule(ctx, id)
+	})
+}
+
+var _ influxdb.VariableService = (*VariableService)(nil)
+
+// VariableService trashes variables before deleting them.
+type VariableService struct {
+	influxdb.VariableService
+	log   *zap.Logger
+	trash influxdb.TrashService
+}
+
+// NewVariableService wraps s.
+func NewVariableService(log *zap.Logger, s influxdb.VariableService, trash influxdb.TrashService) *VariableService {
+	return &VariableService{VariableService: s, log: log, trash: trash}
+}
+
+// DeleteVariable moves a variable to the trash.
+func (s *VariableService) DeleteVariable(ctx context.Context, id influxdb.ID) error {
+	return trashThenDelete(ctx, s.log, s.trash, influxdb.VariablesResourceType, id, func() error {
+		return s.VariableService.DeleteVariable(ctx, id)
+	})
+}
@@ -0,0 +1,92 @@
+package trash
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+var _ influxdb.TrashService = (*AuthedService)(nil)
+
+// AuthedService authorizes access to trashed resources with the permissions
+// of the resources themselves: reading a trashed resource requires read
+// access to it, restoring it requires create access to its type and deleting
+// it permanently requires write access to it.
+type AuthedService struct {
+	s influxdb.TrashService
+}
+
+// NewAuthedService wraps s with trash authorization.
+func NewAuthedService(s influxdb.TrashService) *AuthedService {
+	return &AuthedService{s: s}
+}
+
+// TrashResource is only performed by deleting a resource.
+func (s *AuthedService) TrashResource(ctx context.Context, rt influxdb.ResourceType, id influxdb.ID) (*influxdb.TrashedResource, error) {
+	return nil, &influxdb.Error{
+		Code: influxdb.EForbidden,
+		Msg:  "resources are moved to the trash by deleting them",
+	}
+}
+
+func (s *AuthedService) FindTrashedResourceByID(ctx context.Context, id influxdb.ID) (*influxdb.TrashedResource, error) {
+	tr, err := s.s.FindTrashedResourceByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, tr.ResourceType, tr.ResourceID, tr.OrgID); err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+func (s *AuthedService) FindTrashedResources(ctx context.Context, filter influxdb.TrashFilter) ([]*influxdb.TrashedResource, int, error) {
+	trs, _, err := s.s.FindTrashedResources(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	authed := trs[:0]
+	for _, tr := range trs {
+		if _, _, err := authorizer.AuthorizeRead(ctx, tr.ResourceType, tr.ResourceID, tr.OrgID); err != nil {
+			if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+				continue
+			}
+			return nil, 0, err
+		}
+		authed = append(authed, tr)
+	}
+	return authed, len(authed), nil
+}
+
+func (s *AuthedService) RestoreResource(ctx context.Context, id influxdb.ID) (*influxdb.TrashedResource, error) {
+	tr, err := s.s.FindTrashedResourceByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeCreate(ctx, tr.ResourceType, tr.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.RestoreResource(ctx, id)
+}
+
+func (s *AuthedService) DeleteTrashedResource(ctx context.Context, id influxdb.ID) error {
+	tr, err := s.s.FindTrashedResourceByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, tr.ResourceType, tr.ResourceID, tr.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteTrashedResource(ctx, id)
+}
+
+// PurgeTrash requires write access to every organization.
+func (s *AuthedService) PurgeTrash(ctx context.Context, t time.Time) (int, error) {
+	if _, _, err := authorizer.AuthorizeWriteGlobal(ctx, influxdb.OrgsResourceType); err != nil {
+		return 0, err
+	}
+	return s.s.PurgeTrash(ctx, t)
+}
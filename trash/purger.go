@@ -0,0 +1,57 @@
+package trash
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+// DefaultPurgeInterval is how often expired resources are removed from the trash.
+const DefaultPurgeInterval = time.Hour
+
+// Purger periodically removes expired resources from the trash.
+type Purger struct {
+	log      *zap.Logger
+	trash    influxdb.TrashService
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewPurger returns a purger for the trash.
+func NewPurger(log *zap.Logger, trash influxdb.TrashService, interval time.Duration) *Purger {
+	return &Purger{
+		log:      log,
+		trash:    trash,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Run purges the trash until ctx is canceled.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Purger) purge(ctx context.Context) {
+	n, err := p.trash.PurgeTrash(ctx, p.now())
+	if err != nil {
+		p.log.Error("Failed to purge trash", zap.Error(err))
+		return
+	}
+	if n > 0 {
+		p.log.Info("Purged expired resources from trash", zap.Int("count", n))
+	}
+}
@@ -60,6 +60,21 @@ type Config struct {
 
 	DryRun bool
 
+	// Selectors restricts the migration to the shards of the selected
+	// databases and retention policies. Empty, the shards of all of them,
+	// except the `_internal` database, are migrated.
+	Selectors []Selector
+
+	// BucketNames maps a database and retention policy, formatted as
+	// "db/rp", to the name of the bucket its shards are migrated to. The
+	// bucket of an unmapped retention policy is named "db/rp".
+	BucketNames map[string]string
+
+	// StatePath is the path of the file recording the shards already
+	// migrated. When set, the shards it records are skipped, so that an
+	// interrupted migration can be resumed where it stopped.
+	StatePath string
+
 	// Optional if you want to emit logs
 	Stdout         io.Writer
 	VerboseLogging bool
@@ -110,17 +125,28 @@ func NewMigrator(c Config) *Migrator {
 type shardMapping struct {
 	path     string
 	bucketID influxdb.ID
+	size     int64 // size of the TSM data in the shard
 }
 
-// Process1xShards migrates the contents of any matching 1.x shards.
+// Process1xShards migrates the contents of the 1.x shards selected by the
+// configured selectors.
 //
-// The caller can filter shards only belonging to a retention policy and database.
-// Providing the zero value for the filters will result in all shards being
-// migrated, with the exception of the `_internal` database, which is never
-// migrated unless explicitly filtered on.
-func (m *Migrator) Process1xShards(dbFilter, rpFilter string) error {
+// Shards recorded in the state file as already migrated are skipped, and each
+// shard is recorded in it once migrated. A shard interrupted during its
+// migration is migrated again in its entirety.
+func (m *Migrator) Process1xShards() error {
 	defer m.store.Close()
 
+	st, err := loadState(m.StatePath)
+	if err != nil {
+		return fmt.Errorf("unable to load migration state from %q: %v", m.StatePath, err)
+	}
+
+	// Remove files left over by an interrupted migration.
+	if err := m.removeTempFiles(); err != nil {
+		return err
+	}
+
 	// determine current gen
 	fs := tsm1.NewFileStore(m.DestPath)
 	if err := fs.Open(context.Background()); err != nil {
@@ -131,30 +157,35 @@ func (m *Migrator) Process1xShards(dbFilter, rpFilter string) error {
 
 	var (
 		toProcessShards []shardMapping
-		curDB, curRP    string      // track current db and rp
-		bucketID        influxdb.ID // track current bucket ID
+		totalSize       int64
+		buckets         = make(map[string]influxdb.ID) // bucket IDs by db/rp
 	)
 
-	err := walkShardDirs(filepath.Join(m.SourcePath, dataDirName1x), func(db string, rp string, path string) error {
-		if dbFilter == "" && db == internalDBName1x {
-			return nil // Don't import TSM data from _internal unless explicitly instructed to
+	err = walkShardDirs(filepath.Join(m.SourcePath, dataDirName1x), func(db string, rp string, path string) error {
+		if !m.selected(db, rp) {
+			return nil
 		}
-
-		// A database or retention policy filter has been specified and this
-		// shard path does not match it.
-		if (dbFilter != "" && db != dbFilter) || (rpFilter != "" && rp != rpFilter) {
+		if bucketID, ok := st.Shards[path]; ok {
+			fmt.Fprintf(m.verboseStdout, "Skipping shard %s already migrated to bucket %s\n", path, bucketID.String())
 			return nil
 		}
 
-		var err error
-		if db != curDB || rp != curRP {
+		bucketID, ok := buckets[db+"/"+rp]
+		if !ok {
+			var err error
 			if bucketID, err = m.createBucket(db, rp); err != nil {
 				return err
 			}
-			curDB, curRP = db, rp
+			buckets[db+"/"+rp] = bucketID
 		}
 
-		toProcessShards = append(toProcessShards, shardMapping{path: path, bucketID: bucketID})
+		size, err := tsmSize(path)
+		if err != nil {
+			return err
+		}
+		totalSize += size
+
+		toProcessShards = append(toProcessShards, shardMapping{path: path, bucketID: bucketID, size: size})
 		return nil
 	})
 	if err != nil {
@@ -163,14 +194,29 @@ func (m *Migrator) Process1xShards(dbFilter, rpFilter string) error {
 
 	// Sort shards so that for each database and retention policy, we deal handle
 	// them in the order they were created.
-	sortShardDirs(toProcessShards)
+	if err := sortShardDirs(toProcessShards); err != nil {
+		return err
+	}
 
-	for _, shard := range toProcessShards {
+	fmt.Fprintf(m.Stdout, "Migrating %d shards (%d bytes of TSM data), %d already migrated\n",
+		len(toProcessShards), totalSize, len(st.Shards))
+
+	var migratedSize int64
+	for i, shard := range toProcessShards {
 		now := time.Now()
 		if err := m.Process1xShard(shard.path, shard.bucketID); err != nil {
 			return err
 		}
-		fmt.Fprintf(m.Stdout, "Migrated shard %s to bucket %s in %v\n", shard.path, shard.bucketID.String(), time.Since(now))
+		migratedSize += shard.size
+
+		if !m.DryRun {
+			st.Shards[shard.path] = shard.bucketID
+			if err := st.save(m.StatePath); err != nil {
+				return fmt.Errorf("unable to save migration state to %q: %v", m.StatePath, err)
+			}
+		}
+		fmt.Fprintf(m.Stdout, "[%d/%d] Migrated shard %s to bucket %s in %v (%s complete)\n",
+			i+1, len(toProcessShards), shard.path, shard.bucketID.String(), time.Since(now), progress(migratedSize, totalSize))
 	}
 
 	fmt.Fprintln(m.Stdout, "Building TSI index")
@@ -254,8 +300,51 @@ func sortShardDirs(shards []shardMapping) error {
 	return err2
 }
 
+// progress formats the proportion of the total size migrated.
+func progress(migrated, total int64) string {
+	if total == 0 {
+		return "100.0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(migrated)*100/float64(total))
+}
+
+// tsmSize returns the size of the TSM files in the shard directory.
+func tsmSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && filepath.Ext(p) == "."+tsm1.TSMFileExtension {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// removeTempFiles removes the partially written TSM files left in the
+// destination by an interrupted migration.
+func (m *Migrator) removeTempFiles() error {
+	paths, err := filepath.Glob(filepath.Join(m.DestPath, "*"+importTempExtension))
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		if m.DryRun {
+			fmt.Fprintf(m.Stdout, "Would remove incomplete file %q\n", p)
+			continue
+		}
+		if err := os.Remove(p); err != nil {
+			return err
+		}
+		fmt.Fprintf(m.verboseStdout, "Removed incomplete file %q\n", p)
+	}
+	return nil
+}
+
 func (m *Migrator) createBucket(db, rp string) (influxdb.ID, error) {
-	name := filepath.Join(db, rp)
+	name := m.bucketName(db, rp)
 
 	bucket, err := m.metaSvc.FindBucketByName(context.Background(), m.DestOrg, name)
 	if err != nil {
@@ -294,6 +383,7 @@ func (m *Migrator) createBucket(db, rp string) (influxdb.ID, error) {
 		fmt.Fprintf(m.verboseStdout, "Created bucket %q with ID %s\n", name, bucket.ID.String())
 	} else {
 		fmt.Fprintf(m.Stdout, "Would create bucket %q\n", name)
+		return 0, nil
 	}
 
 	return bucket.ID, nil
@@ -689,7 +779,7 @@ func walkShardDirs(root string, fn func(db, rp, path string) error) error {
 		return dirsSlice[i].id < dirsSlice[j].id
 	})
 
-	for _, shard := range dirs {
+	for _, shard := range dirsSlice {
 		if err := fn(shard.db, shard.rp, shard.path); err != nil {
 			return err
 		}
@@ -1,9 +1,13 @@
 package migrate
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
+	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/pkg/slices"
 )
 
@@ -108,3 +112,98 @@ func Test_sort1xTSMKeys(t *testing.T) {
 		}
 	}
 }
+
+func Test_Config_selected(t *testing.T) {
+	cases := []struct {
+		selectors []string
+		db, rp    string
+		expected  bool
+	}{
+		{db: "db0", rp: "autogen", expected: true},
+		{db: "_internal", rp: "monitor", expected: false},
+		{selectors: []string{"_internal"}, db: "_internal", rp: "monitor", expected: true},
+		{selectors: []string{"db0"}, db: "db0", rp: "rp0", expected: true},
+		{selectors: []string{"db0"}, db: "db1", rp: "rp0", expected: false},
+		{selectors: []string{"db0/rp0", "db1"}, db: "db0", rp: "rp0", expected: true},
+		{selectors: []string{"db0/rp0", "db1"}, db: "db0", rp: "autogen", expected: false},
+		{selectors: []string{"db0/rp0", "db1"}, db: "db1", rp: "autogen", expected: true},
+	}
+
+	for _, tc := range cases {
+		var c Config
+		for _, s := range tc.selectors {
+			sel, err := ParseSelector(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.Selectors = append(c.Selectors, sel)
+		}
+		if got := c.selected(tc.db, tc.rp); got != tc.expected {
+			t.Errorf("selectors %v: got %v for %s/%s, expected %v", tc.selectors, got, tc.db, tc.rp, tc.expected)
+		}
+	}
+
+	for _, s := range []string{"", "/rp0", "db0/"} {
+		if _, err := ParseSelector(s); err == nil {
+			t.Errorf("expected error parsing selector %q, got <nil>", s)
+		}
+	}
+}
+
+func Test_Config_bucketName(t *testing.T) {
+	names, err := ParseBucketNames([]string{"db0/autogen=metrics", "db1/rp0=a=b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := Config{BucketNames: names}
+	if got, exp := c.bucketName("db0", "autogen"), "metrics"; got != exp {
+		t.Errorf("got %q, expected %q", got, exp)
+	}
+	if got, exp := c.bucketName("db1", "rp0=a"), "b"; got != exp {
+		t.Errorf("got %q, expected %q", got, exp)
+	}
+	if got, exp := c.bucketName("db0", "rp0"), filepath.Join("db0", "rp0"); got != exp {
+		t.Errorf("got %q, expected %q", got, exp)
+	}
+
+	for _, m := range []string{"db0/autogen", "db0=metrics", "db0/autogen="} {
+		if _, err := ParseBucketNames([]string{m}); err == nil {
+			t.Errorf("expected error parsing bucket mapping %q, got <nil>", m)
+		}
+	}
+}
+
+func Test_state(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.json")
+
+	st, err := loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Shards) != 0 {
+		t.Fatalf("got %v, expected no migrated shard", st.Shards)
+	}
+
+	st.Shards["/influxdb/data/db0/autogen/1"] = influxdb.ID(10)
+	if err := st.save(path); err != nil {
+		t.Fatal(err)
+	}
+	st.Shards["/influxdb/data/db0/autogen/2"] = influxdb.ID(10)
+	if err := st.save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, st) {
+		t.Fatalf("got %v, expected %v", got, st)
+	}
+}
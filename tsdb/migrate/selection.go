@@ -0,0 +1,93 @@
+package migrate
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// A Selector selects the shards of a 1.x database, or of only one of its
+// retention policies when RetentionPolicy is set.
+type Selector struct {
+	Database        string
+	RetentionPolicy string
+}
+
+// ParseSelector parses a selector formatted as "db" or "db/rp".
+func ParseSelector(s string) (Selector, error) {
+	parts := strings.SplitN(s, "/", 2)
+	sel := Selector{Database: parts[0]}
+	if len(parts) == 2 {
+		if parts[1] == "" {
+			return Selector{}, fmt.Errorf("invalid selector %q: empty retention policy", s)
+		}
+		sel.RetentionPolicy = parts[1]
+	}
+	if sel.Database == "" {
+		return Selector{}, fmt.Errorf("invalid selector %q: empty database", s)
+	}
+	return sel, nil
+}
+
+// Matches returns true if the shards of the retention policy rp of the
+// database db are selected.
+func (s Selector) Matches(db, rp string) bool {
+	return s.Database == db && (s.RetentionPolicy == "" || s.RetentionPolicy == rp)
+}
+
+func (s Selector) String() string {
+	if s.RetentionPolicy == "" {
+		return s.Database
+	}
+	return s.Database + "/" + s.RetentionPolicy
+}
+
+// ParseBucketNames parses a bucket name mapping where each mapping is
+// formatted as "db/rp=bucket".
+func ParseBucketNames(mappings []string) (map[string]string, error) {
+	names := make(map[string]string, len(mappings))
+	for _, m := range mappings {
+		i := strings.LastIndex(m, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid bucket mapping %q: expected db/rp=bucket", m)
+		}
+
+		sel, err := ParseSelector(m[:i])
+		if err != nil {
+			return nil, err
+		}
+		if sel.RetentionPolicy == "" {
+			return nil, fmt.Errorf("invalid bucket mapping %q: a retention policy is required", m)
+		}
+		if m[i+1:] == "" {
+			return nil, fmt.Errorf("invalid bucket mapping %q: empty bucket name", m)
+		}
+		names[sel.String()] = m[i+1:]
+	}
+	return names, nil
+}
+
+// selected returns true if the shards of the retention policy rp of the
+// database db are to be migrated. Without any selector, all shards are
+// migrated with the exception of the `_internal` database, which is never
+// migrated unless explicitly selected.
+func (c *Config) selected(db, rp string) bool {
+	if len(c.Selectors) == 0 {
+		return db != internalDBName1x
+	}
+	for _, s := range c.Selectors {
+		if s.Matches(db, rp) {
+			return true
+		}
+	}
+	return false
+}
+
+// bucketName returns the name of the bucket the shards of the retention
+// policy rp of the database db are migrated to.
+func (c *Config) bucketName(db, rp string) string {
+	if name, ok := c.BucketNames[db+"/"+rp]; ok {
+		return name
+	}
+	return filepath.Join(db, rp)
+}
@@ -0,0 +1,60 @@
+package migrate
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/fs"
+)
+
+// state records the progress of a migration, so that an interrupted
+// migration can be resumed without migrating its shards again.
+type state struct {
+	// Shards maps the path of each migrated 1.x shard to the ID of the
+	// bucket it was migrated to.
+	Shards map[string]influxdb.ID `json:"shards"`
+}
+
+// loadState loads the state stored at path. A missing file is the state of a
+// migration that has not started.
+func loadState(path string) (*state, error) {
+	s := &state{Shards: make(map[string]influxdb.ID)}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Shards == nil {
+		s.Shards = make(map[string]influxdb.ID)
+	}
+	return s, nil
+}
+
+// save atomically replaces the state stored at path.
+func (s *state) save(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + importTempExtension
+	if err := ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return fs.RenameFileWithReplacement(tmpPath, path)
+}
@@ -48,6 +48,8 @@ type seriesFileMetrics struct {
 	CompactionDuration *prometheus.HistogramVec // Duration of compactions.
 	// The following metrics include a ``"status" = {ok, error}` label
 	Compactions *prometheus.CounterVec // Total number of compactions.
+
+	LastCompaction *prometheus.GaugeVec // Time of the last successful compaction.
 }
 
 // newSeriesFileMetrics initialises the prometheus metrics for tracking the Series File.
@@ -109,6 +111,12 @@ func newSeriesFileMetrics(labels prometheus.Labels) *seriesFileMetrics {
 			Name:      "compactions_total",
 			Help:      "Number of compactions.",
 		}, totalCompactions),
+		LastCompaction: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: seriesFileSubsystem,
+			Name:      "last_compaction_timestamp_seconds",
+			Help:      "Unix time of the last successful compaction of index.",
+		}, names),
 	}
 }
 
@@ -122,5 +130,6 @@ func (m *seriesFileMetrics) PrometheusCollectors() []prometheus.Collector {
 		m.CompactionsActive,
 		m.CompactionDuration,
 		m.Compactions,
+		m.LastCompaction,
 	}
 }
@@ -223,6 +223,15 @@ func (f *SeriesFile) DisableCompactions() {
 	}
 }
 
+// CompactionStatus returns the compaction status of each partition.
+func (f *SeriesFile) CompactionStatus() []PartitionCompactionStatus {
+	status := make([]PartitionCompactionStatus, 0, len(f.partitions))
+	for _, p := range f.partitions {
+		status = append(status, p.CompactionStatus())
+	}
+	return status
+}
+
 // FileSize returns the size of all partitions, in bytes.
 func (f *SeriesFile) FileSize() (n int64, err error) {
 	for _, p := range f.partitions {
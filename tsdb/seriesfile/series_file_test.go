@@ -361,6 +361,42 @@ func TestSeriesFile_Compaction(t *testing.T) {
 	t.Logf("original size: %d, new size: %d", origSize, newSize)
 }
 
+func TestSeriesFile_CompactionStatus(t *testing.T) {
+	const n = 100
+
+	sfile := MustOpenSeriesFile()
+	defer sfile.Close()
+
+	sfile.DisableCompactions()
+	for _, p := range sfile.Partitions() {
+		p.CompactThreshold = 1
+	}
+
+	var collection tsdb.SeriesCollection
+	for i := 0; i < n; i++ {
+		collection.Names = append(collection.Names, []byte("cpu"))
+		collection.Tags = append(collection.Tags, models.NewTags(map[string]string{"region": fmt.Sprintf("r%d", i)}))
+		collection.Types = append(collection.Types, models.Integer)
+	}
+	if err := sfile.CreateSeriesListIfNotExists(&collection); err != nil {
+		t.Fatal(err)
+	}
+
+	var inMem uint64
+	for _, status := range sfile.CompactionStatus() {
+		if !status.CompactionsDisabled || status.Compacting || status.LastCompaction != nil {
+			t.Fatalf("unexpected status %+v", status)
+		}
+		if got, exp := status.Pending, status.InMemSeries > 0; got != exp {
+			t.Fatalf("partition %d: got pending %v, expected %v", status.ID, got, exp)
+		}
+		inMem += status.InMemSeries
+	}
+	if inMem != n {
+		t.Fatalf("got %d in-memory series, expected %d", inMem, n)
+	}
+}
+
 var cachedCompactionSeriesFile *SeriesFile
 
 func BenchmarkSeriesFile_Compaction(b *testing.B) {
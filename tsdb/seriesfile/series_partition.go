@@ -48,6 +48,7 @@ type SeriesPartition struct {
 
 	compacting          bool
 	compactionsDisabled int
+	lastCompaction      time.Time // time of the last successful compaction
 
 	pageFaultLimiter *rate.Limiter // Limits page faults by the partition
 
@@ -358,6 +359,10 @@ func (p *SeriesPartition) CreateSeriesListIfNotExists(collection *tsdb.SeriesCol
 			// Clear compaction flag.
 			p.mu.Lock()
 			p.compacting = false
+			if err == nil {
+				p.lastCompaction = time.Now()
+				p.tracker.SetLastCompaction(p.lastCompaction)
+			}
 			p.mu.Unlock()
 			p.tracker.DecCompactionsActive()
 
@@ -376,6 +381,43 @@ func (p *SeriesPartition) Compacting() bool {
 	return p.compacting
 }
 
+// PartitionCompactionStatus describes the compactions of a series partition.
+type PartitionCompactionStatus struct {
+	ID                  int  `json:"id"`
+	Compacting          bool `json:"compacting"`
+	CompactionsDisabled bool `json:"compactionsDisabled"`
+	// Pending is true when the in-memory series are over the compaction
+	// threshold but no compaction is running.
+	Pending bool `json:"pending"`
+	// InMemSeries is the number of series in the in-memory index, rebuilt on
+	// disk once it reaches CompactThreshold.
+	InMemSeries      uint64 `json:"inMemSeries"`
+	CompactThreshold int    `json:"compactThreshold"`
+	// LastCompaction is the time of the last compaction since the partition
+	// was opened.
+	LastCompaction *time.Time `json:"lastCompaction,omitempty"`
+}
+
+// CompactionStatus returns the compaction status of the partition.
+func (p *SeriesPartition) CompactionStatus() PartitionCompactionStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	status := PartitionCompactionStatus{
+		ID:                  p.id,
+		Compacting:          p.compacting,
+		CompactionsDisabled: !p.compactionsEnabled(),
+		InMemSeries:         p.index.InMemCount(),
+		CompactThreshold:    p.CompactThreshold,
+	}
+	status.Pending = !p.compacting && p.CompactThreshold != 0 && status.InMemSeries >= uint64(p.CompactThreshold)
+	if !p.lastCompaction.IsZero() {
+		t := p.lastCompaction
+		status.LastCompaction = &t
+	}
+	return status
+}
+
 // DeleteSeriesID flags a list of series as permanently deleted.
 // If a series is reintroduced later then it must create a new id.
 func (p *SeriesPartition) DeleteSeriesIDs(ids []tsdb.SeriesID) error {
@@ -742,6 +784,16 @@ func (t *seriesPartitionTracker) IncCompactionOK(duration time.Duration) {
 // IncCompactionErr increments the number of failed compactions for the partition.
 func (t *seriesPartitionTracker) IncCompactionErr() { t.incCompactions("error", 0) }
 
+// SetLastCompaction sets the time of the last successful compaction.
+func (t *seriesPartitionTracker) SetLastCompaction(tm time.Time) {
+	if !t.enabled {
+		return
+	}
+
+	labels := t.Labels()
+	t.metrics.LastCompaction.With(labels).Set(float64(tm.Unix()))
+}
+
 // SeriesPartitionCompactor represents an object reindexes a series partition and optionally compacts segments.
 type SeriesPartitionCompactor struct {
 	cancel <-chan struct{}
@@ -0,0 +1,103 @@
+package tsi1
+
+import "time"
+
+// CompactionLevelStatus describes the files of a compaction level of an index
+// partition. Level 0 holds the log files.
+type CompactionLevelStatus struct {
+	Level      int   `json:"level"`
+	Files      int   `json:"files"`
+	Size       int64 `json:"size"`
+	Compacting bool  `json:"compacting"`
+	// Pending is true when files of the level await a compaction into the
+	// next level.
+	Pending bool `json:"pending"`
+	// LastCompaction is the time of the last compaction into the level since
+	// the partition was opened.
+	LastCompaction *time.Time `json:"lastCompaction,omitempty"`
+}
+
+// PartitionCompactionStatus describes the compactions of an index partition.
+type PartitionCompactionStatus struct {
+	ID                  string                  `json:"id"`
+	CompactionsDisabled bool                    `json:"compactionsDisabled"`
+	ActiveCompactions   int                     `json:"activeCompactions"`
+	PendingCompactions  int                     `json:"pendingCompactions"`
+	Levels              []CompactionLevelStatus `json:"levels"`
+	// LastFullCompaction is the time of the last compaction of index files,
+	// as opposed to log files, since the partition was opened.
+	LastFullCompaction *time.Time `json:"lastFullCompaction,omitempty"`
+}
+
+// CompactionStatus returns the compaction status of the partition.
+func (p *Partition) CompactionStatus() PartitionCompactionStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	status := PartitionCompactionStatus{
+		ID:                  p.id,
+		CompactionsDisabled: p.compactionsDisabled > 0,
+		ActiveCompactions:   p.currentCompactionN,
+		Levels:              make([]CompactionLevelStatus, len(p.levels)),
+		LastFullCompaction:  timeOrNil(p.lastFullCompaction),
+	}
+	if p.fileSet == nil {
+		return status
+	}
+
+	for level := range status.Levels {
+		status.Levels[level] = CompactionLevelStatus{
+			Level:          level,
+			Compacting:     p.levelCompacting[level],
+			Pending:        p.levelPending(p.fileSet, level),
+			LastCompaction: timeOrNil(p.lastCompaction[level]),
+		}
+	}
+
+	// Log files other than the active one are being compacted, and the active
+	// one awaits a compaction once it is over the size threshold.
+	if len(status.Levels) > 0 {
+		logFiles := p.fileSet.LogFiles()
+		lvl := &status.Levels[0]
+		lvl.Files = len(logFiles)
+		for _, f := range logFiles {
+			lvl.Size += f.Size()
+		}
+		lvl.Compacting = len(logFiles) > 1
+		lvl.Pending = p.activeLogFile != nil && p.activeLogFile.Size() >= p.MaxLogFileSize
+	}
+
+	for _, f := range p.fileSet.IndexFiles() {
+		if level := f.Level(); level < len(status.Levels) {
+			status.Levels[level].Files++
+			status.Levels[level].Size += f.Size()
+		}
+	}
+
+	for _, lvl := range status.Levels {
+		if lvl.Pending {
+			status.PendingCompactions++
+		}
+	}
+	return status
+}
+
+// CompactionStatus returns the compaction status of each partition.
+func (i *Index) CompactionStatus() []PartitionCompactionStatus {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	status := make([]PartitionCompactionStatus, 0, len(i.partitions))
+	for _, p := range i.partitions {
+		status = append(status, p.CompactionStatus())
+	}
+	return status
+}
+
+// timeOrNil returns nil for the zero time.
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
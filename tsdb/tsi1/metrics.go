@@ -115,12 +115,15 @@ type partitionMetrics struct {
 	FilesTotal *prometheus.GaugeVec // files on disk.
 
 	// This metric has a "level" metric.
-	CompactionsActive *prometheus.GaugeVec // Number of active compactions.
+	CompactionsActive  *prometheus.GaugeVec // Number of active compactions.
+	CompactionsPending *prometheus.GaugeVec // Whether files await a compaction.
 
 	// These metrics have a "level" metric.
 	// The following metrics include a "status" = {ok, error}` label
 	CompactionDuration *prometheus.HistogramVec // Duration of compactions.
 	Compactions        *prometheus.CounterVec   // Total number of compactions.
+
+	LastFullCompaction *prometheus.GaugeVec // Time of the last compaction of index files.
 }
 
 // newPartitionMetrics initialises the prometheus metrics for tracking the TSI partitions.
@@ -194,6 +197,12 @@ func newPartitionMetrics(labels prometheus.Labels) *partitionMetrics {
 			Name:      "compactions_active",
 			Help:      "Number of active partition compactions.",
 		}, compactionNames),
+		CompactionsPending: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: partitionSubsystem,
+			Name:      "compactions_pending",
+			Help:      "Whether index files of the level await a compaction (0 or 1).",
+		}, compactionNames),
 		CompactionDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: namespace,
 			Subsystem: partitionSubsystem,
@@ -208,6 +217,12 @@ func newPartitionMetrics(labels prometheus.Labels) *partitionMetrics {
 			Name:      "compactions_total",
 			Help:      "Number of compactions.",
 		}, attemptedCompactionNames),
+		LastFullCompaction: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: partitionSubsystem,
+			Name:      "last_full_compaction_timestamp_seconds",
+			Help:      "Unix time of the last compaction of index files in the partition.",
+		}, names),
 	}
 }
 
@@ -222,7 +237,9 @@ func (m *partitionMetrics) PrometheusCollectors() []prometheus.Collector {
 		m.FilesTotal,
 		m.DiskSize,
 		m.CompactionsActive,
+		m.CompactionsPending,
 		m.CompactionDuration,
 		m.Compactions,
+		m.LastFullCompaction,
 	}
 }
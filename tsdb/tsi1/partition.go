@@ -78,6 +78,8 @@ type Partition struct {
 	levelCompacting     []bool            // level compaction status
 	compactionsDisabled int               // counter of disables
 	currentCompactionN  int               // counter of in-progress compactions
+	lastCompaction      []time.Time       // time of the last compaction into each level
+	lastFullCompaction  time.Time         // time of the last compaction of index files
 
 	// Directory of the Partition's index files.
 	path string
@@ -208,6 +210,7 @@ func (p *Partition) Open() (err error) {
 
 	// Set up flags to track whether a level is compacting.
 	p.levelCompacting = make([]bool, len(p.levels))
+	p.lastCompaction = make([]time.Time, len(p.levels))
 
 	// Open each file in the manifest.
 	files, err := func() (files []File, err error) {
@@ -1019,6 +1022,21 @@ func (p *Partition) compact() {
 			p.Compact()
 		}(level)
 	}
+
+	// Report the levels still awaiting a compaction, e.g. because of
+	// contiguous files beyond the ones being compacted.
+	for level := minLevel; level <= maxLevel; level++ {
+		p.tracker.SetPendingCompaction(level, p.levelPending(fs, level))
+	}
+}
+
+// levelPending returns true if index files of the level await a compaction
+// into the next level.
+func (p *Partition) levelPending(fs *FileSet, level int) bool {
+	if level < 1 || level > len(p.levels)-2 || p.levelCompacting[level] {
+		return false
+	}
+	return len(fs.LastContiguousIndexFilesByLevel(level)) >= 2
 }
 
 // compactToLevel compacts a set of files into a new file. Replaces old files with
@@ -1123,6 +1141,9 @@ func (p *Partition) compactToLevel(files []*IndexFile, frefs lifecycle.Reference
 		// Now that we can no longer error, update the local state.
 		p.replaceFileSet(fileSet)
 		p.manifestSize = manifestSize
+		p.lastCompaction[level] = time.Now()
+		p.lastFullCompaction = p.lastCompaction[level]
+		p.tracker.SetLastFullCompaction(p.lastFullCompaction)
 
 		return nil
 	}(); err != nil {
@@ -1291,6 +1312,7 @@ func (p *Partition) compactLogFile(ctx context.Context, logFile *LogFile, interr
 		// Now that we can no longer error, update the local state.
 		p.replaceFileSet(fileSet)
 		p.manifestSize = manifestSize
+		p.lastCompaction[1] = time.Now()
 
 		return nil
 	}(); err != nil {
@@ -1541,6 +1563,32 @@ func (t *partitionTracker) DecActiveCompaction(level int) {
 	t.metrics.CompactionsActive.With(labels).Dec()
 }
 
+// SetPendingCompaction sets whether files of the provided level await a compaction.
+func (t *partitionTracker) SetPendingCompaction(level int, pending bool) {
+	if !t.enabled {
+		return
+	}
+
+	labels := t.Labels()
+	labels["level"] = fmt.Sprint(level)
+
+	var n float64
+	if pending {
+		n = 1
+	}
+	t.metrics.CompactionsPending.With(labels).Set(n)
+}
+
+// SetLastFullCompaction sets the time of the last compaction of index files.
+func (t *partitionTracker) SetLastFullCompaction(tm time.Time) {
+	if !t.enabled {
+		return
+	}
+
+	labels := t.Labels()
+	t.metrics.LastFullCompaction.With(labels).Set(float64(tm.Unix()))
+}
+
 // CompactionAttempted updates the number of compactions attempted for the provided level.
 func (t *partitionTracker) CompactionAttempted(level int, success bool, d time.Duration) {
 	if !t.enabled {
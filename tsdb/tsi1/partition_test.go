@@ -107,6 +107,38 @@ func TestPartition_Manifest(t *testing.T) {
 	})
 }
 
+func TestPartition_CompactionStatus(t *testing.T) {
+	sfile := MustOpenSeriesFile()
+	defer sfile.Close()
+
+	p := MustOpenPartition(sfile.SeriesFile)
+	defer p.Close()
+
+	status := p.CompactionStatus()
+	if got, exp := status.ID, filepath.Base(p.Path()); got != exp {
+		t.Fatalf("got partition %q, expected %q", got, exp)
+	}
+	if got, exp := len(status.Levels), len(tsi1.DefaultCompactionLevels); got != exp {
+		t.Fatalf("got %d levels, expected %d", got, exp)
+	}
+	for level, lvl := range status.Levels {
+		if lvl.Level != level || lvl.Compacting || lvl.Pending || lvl.LastCompaction != nil {
+			t.Fatalf("unexpected status of level %d: %+v", level, lvl)
+		}
+	}
+	if got, exp := status.Levels[0].Files, 1; got != exp {
+		t.Fatalf("got %d log files, expected %d", got, exp)
+	}
+	if status.PendingCompactions != 0 || status.ActiveCompactions != 0 || status.LastFullCompaction != nil {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	p.DisableCompactions()
+	if !p.CompactionStatus().CompactionsDisabled {
+		t.Fatal("expected compactions to be disabled")
+	}
+}
+
 // Partition is a test wrapper for tsi1.Partition.
 type Partition struct {
 	*tsi1.Partition
@@ -42,10 +42,12 @@ func ErrCacheMemorySizeLimitExceeded(n, limit uint64) error {
 
 // Cache maintains an in-memory store of Values for a set of keys.
 type Cache struct {
-	mu      sync.RWMutex
-	store   *ring
+	// maxSize is accessed atomically and must be 64-bit aligned.
 	maxSize uint64
 
+	mu    sync.RWMutex
+	store *ring
+
 	// snapshots are the cache objects that are currently being written to tsm files
 	// they're kept in memory while flushing so they can be queried along with the cache.
 	// they are read only and should never be modified
@@ -74,7 +76,7 @@ func (c *Cache) Write(key []byte, values []Value) error {
 	addedSize := uint64(Values(values).Size())
 
 	// Enough room in the cache?
-	limit := c.maxSize
+	limit := c.MaxSize()
 	n := c.Size() + addedSize
 
 	if limit > 0 && n > limit {
@@ -114,7 +116,7 @@ func (c *Cache) WriteMulti(values map[string][]Value) error {
 	}
 
 	// Enough room in the cache?
-	limit := c.maxSize // maxSize is safe for reading without a lock.
+	limit := c.MaxSize()
 	n := c.Size() + addedSize
 	if limit > 0 && n > limit {
 		c.tracker.IncWritesErr()
@@ -165,6 +167,46 @@ func (c *Cache) WriteMulti(values map[string][]Value) error {
 	return werr
 }
 
+// CheckMulti returns an error if WriteMulti would not write all of values to
+// the cache, because the cache would exceed its max size or because of field
+// type conflicts, without writing them.
+func (c *Cache) CheckMulti(values map[string][]Value) error {
+	var addedSize uint64
+	for _, v := range values {
+		addedSize += uint64(Values(v).Size())
+	}
+	limit := c.MaxSize()
+	if n := c.Size() + addedSize; limit > 0 && n > limit {
+		return ErrCacheMemorySizeLimitExceeded(n, limit)
+	}
+
+	c.mu.RLock()
+	store := c.store
+	c.mu.RUnlock()
+
+	for k, v := range values {
+		if len(v) == 0 {
+			continue
+		}
+		vtype := valueType(v[0])
+		for _, value := range v {
+			if valueType(value) != vtype {
+				return errFieldTypeConflict
+			}
+		}
+
+		if e := store.entry([]byte(k)); e != nil {
+			e.mu.RLock()
+			conflict := e.vtype != 0 && e.vtype != vtype
+			e.mu.RUnlock()
+			if conflict {
+				return errFieldTypeConflict
+			}
+		}
+	}
+	return nil
+}
+
 // Snapshot takes a snapshot of the current cache, adds it to the slice of caches that
 // are being flushed, and resets the current cache with new values.
 func (c *Cache) Snapshot() (*Cache, error) {
@@ -263,7 +305,7 @@ func (c *Cache) Size() uint64 {
 
 // MaxSize returns the maximum number of bytes the cache may consume.
 func (c *Cache) MaxSize() uint64 {
-	return c.maxSize
+	return atomic.LoadUint64(&c.maxSize)
 }
 
 func (c *Cache) Count() int {
@@ -481,9 +523,14 @@ func (c *Cache) DeleteBucketRange(ctx context.Context, name string, min, max int
 
 // SetMaxSize updates the memory limit of the cache.
 func (c *Cache) SetMaxSize(size uint64) {
-	c.mu.Lock()
-	c.maxSize = size
-	c.mu.Unlock()
+	atomic.StoreUint64(&c.maxSize, size)
+	c.tracker.SetMaxSize(size)
+}
+
+// IncomingBytes returns the number of bytes written to the cache or dropped
+// because it was full, since it was created.
+func (c *Cache) IncomingBytes() uint64 {
+	return c.tracker.IncomingBytes()
 }
 
 // values returns the values for the key. It assumes the data is already sorted.
@@ -594,6 +641,7 @@ type cacheTracker struct {
 	snapshotsActive uint64
 	snapshotSize    uint64
 	cacheSize       uint64
+	incomingBytes   uint64 // bytes written or dropped
 
 	// Used in testing.
 	memSizeBytes     uint64
@@ -684,13 +732,22 @@ func (t *cacheTracker) AddWrittenBytes(status string, bytes uint64) {
 }
 
 // AddWrittenBytesOK increments the number of successful writes.
-func (t *cacheTracker) AddWrittenBytesOK(bytes uint64) { t.AddWrittenBytes("ok", bytes) }
+func (t *cacheTracker) AddWrittenBytesOK(bytes uint64) {
+	atomic.AddUint64(&t.incomingBytes, bytes)
+	t.AddWrittenBytes("ok", bytes)
+}
 
 // AddWrittenBytesError increments the number of writes that encountered an error.
 func (t *cacheTracker) AddWrittenBytesErr(bytes uint64) { t.AddWrittenBytes("error", bytes) }
 
 // AddWrittenBytesDrop increments the number of writes that were dropped.
-func (t *cacheTracker) AddWrittenBytesDrop(bytes uint64) { t.AddWrittenBytes("dropped", bytes) }
+func (t *cacheTracker) AddWrittenBytesDrop(bytes uint64) {
+	atomic.AddUint64(&t.incomingBytes, bytes)
+	t.AddWrittenBytes("dropped", bytes)
+}
+
+// IncomingBytes returns the number of bytes written or dropped.
+func (t *cacheTracker) IncomingBytes() uint64 { return atomic.LoadUint64(&t.incomingBytes) }
 
 // IncWrites increments the number of writes to the cache, with a required status.
 func (t *cacheTracker) IncWrites(status string) {
@@ -740,6 +797,18 @@ func (t *cacheTracker) SetAge(d time.Duration) {
 	t.metrics.Age.With(labels).Set(d.Seconds())
 }
 
+// SetMaxSize sets the size of the cache at which writes are rejected.
+func (t *cacheTracker) SetMaxSize(sz uint64) {
+	labels := t.Labels()
+	t.metrics.MaxSize.With(labels).Set(float64(sz))
+}
+
+// SetSnapshotThreshold sets the size of the cache at which it is snapshotted.
+func (t *cacheTracker) SetSnapshotThreshold(sz uint64) {
+	labels := t.Labels()
+	t.metrics.SnapshotThreshold.With(labels).Set(float64(sz))
+}
+
 const (
 	valueTypeUndefined = 0
 	valueTypeFloat64   = 1
@@ -157,6 +157,41 @@ func TestCache_CacheWriteMulti_TypeConflict(t *testing.T) {
 	}
 }
 
+func TestCache_CheckMulti(t *testing.T) {
+	vf := NewValue(1, 1.0)
+	vi := NewValue(2, int64(2))
+	c := NewCache(uint64(10 * vf.Size()))
+
+	if err := c.WriteMulti(map[string][]Value{"foo": {vf}}); err != nil {
+		t.Fatalf("failed to write key foo to cache: %s", err.Error())
+	}
+	size := c.Size()
+
+	if err := c.CheckMulti(map[string][]Value{"foo": {vf}, "bar": {vi}}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := c.CheckMulti(map[string][]Value{"foo": {vi}}); err != errFieldTypeConflict {
+		t.Fatalf("expected field type conflict with the cache, got %v", err)
+	}
+	if err := c.CheckMulti(map[string][]Value{"bar": {vf, vi}}); err != errFieldTypeConflict {
+		t.Fatalf("expected field type conflict within the values, got %v", err)
+	}
+	values := make(Values, 10)
+	for i := range values {
+		values[i] = NewValue(int64(i), 1.0)
+	}
+	if err := c.CheckMulti(map[string][]Value{"bar": values}); err == nil {
+		t.Fatal("expected the cache to exceed its max size")
+	}
+
+	if got := c.Size(); got != size {
+		t.Fatalf("cache size changed by checks, exp %d, got %d", size, got)
+	}
+	if exp, keys := [][]byte{[]byte("foo")}, c.Keys(); !reflect.DeepEqual(keys, exp) {
+		t.Fatalf("cache keys changed by checks, exp %v, got %v", exp, keys)
+	}
+}
+
 func TestCache_Cache_DeleteBucketRange(t *testing.T) {
 	v0 := NewValue(1, 1.0)
 	v1 := NewValue(2, 2.0)
@@ -0,0 +1,105 @@
+package tsm1
+
+import "time"
+
+const (
+	// cacheTuneInterval is the interval between two adjustments of the cache.
+	cacheTuneInterval = 10 * time.Second
+
+	// cacheSnapshotWindow is the duration of incoming writes, at the current
+	// rate, the cache is snapshotted after.
+	cacheSnapshotWindow = 10 * time.Second
+
+	// cacheBurstWindow is the duration of incoming writes, at the current rate,
+	// the cache absorbs beyond its snapshot size while a snapshot is written.
+	cacheBurstWindow = 30 * time.Second
+
+	// cacheMemoryFraction is the fraction of the available memory the cache
+	// can grow into.
+	cacheMemoryFraction = 0.5
+
+	// cacheRateSmoothing is the weight of the write rate of the last interval
+	// against the rate of the previous ones.
+	cacheRateSmoothing = 0.5
+)
+
+// cacheTuner adjusts the maximum size of the cache and its snapshot size to
+// the incoming write rate and the available memory.
+type cacheTuner struct {
+	minSize, maxSize uint64 // bounds of the maximum size of the cache
+	minSnapshotSize  uint64
+
+	// availableMemory returns the memory available to the process, or false
+	// if it is unknown.
+	availableMemory func() (uint64, bool)
+
+	lastTune     time.Time
+	lastIncoming uint64
+	rate         float64 // smoothed incoming bytes per second
+}
+
+func newCacheTuner(c CacheConfig) *cacheTuner {
+	return &cacheTuner{
+		minSize:         uint64(c.AutoTuneMinMemorySize),
+		maxSize:         uint64(c.AutoTuneMaxMemorySize),
+		minSnapshotSize: uint64(c.SnapshotMemorySize),
+		availableMemory: availableMemory,
+	}
+}
+
+// initialSize returns the maximum size of the cache before the first
+// adjustment, within the bounds of the tuner.
+func (t *cacheTuner) initialSize(size uint64) uint64 {
+	if size > t.maxSize {
+		size = t.maxSize
+	}
+	if size < t.minSize {
+		size = t.minSize
+	}
+	return size
+}
+
+// tune returns the maximum size of the cache and its snapshot size at now,
+// given the current size of the cache and the bytes that came in so far. It
+// returns false until cacheTuneInterval has passed since the last adjustment.
+func (t *cacheTuner) tune(now time.Time, size, incoming uint64) (maxSize, snapshotSize uint64, ok bool) {
+	if t.lastTune.IsZero() {
+		t.lastTune, t.lastIncoming = now, incoming
+		return 0, 0, false
+	}
+	elapsed := now.Sub(t.lastTune)
+	if elapsed < cacheTuneInterval {
+		return 0, 0, false
+	}
+
+	rate := float64(incoming-t.lastIncoming) / elapsed.Seconds()
+	t.rate = cacheRateSmoothing*rate + (1-cacheRateSmoothing)*t.rate
+	t.lastTune, t.lastIncoming = now, incoming
+
+	snapshotSize = uint64(t.rate * cacheSnapshotWindow.Seconds())
+	if snapshotSize < t.minSnapshotSize {
+		snapshotSize = t.minSnapshotSize
+	}
+
+	// The cache grows into the available memory up to the upper bound, but
+	// keeps the lower bound regardless of it.
+	maxSize = snapshotSize + uint64(t.rate*cacheBurstWindow.Seconds())
+	upper := t.maxSize
+	if avail, ok := t.availableMemory(); ok {
+		if limit := size + uint64(float64(avail)*cacheMemoryFraction); limit < upper {
+			upper = limit
+		}
+	}
+	if maxSize > upper {
+		maxSize = upper
+	}
+	if maxSize < t.minSize {
+		maxSize = t.minSize
+	}
+
+	// Snapshots must start well before the cache is full.
+	if snapshotSize > maxSize/2 {
+		snapshotSize = maxSize / 2
+	}
+	return maxSize, snapshotSize, true
+}
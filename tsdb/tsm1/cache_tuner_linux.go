@@ -0,0 +1,32 @@
+package tsm1
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strconv"
+)
+
+// availableMemory returns the MemAvailable estimate of /proc/meminfo.
+func availableMemory() (uint64, bool) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// MemAvailable:    1234567 kB
+		fields := bytes.Fields(scanner.Bytes())
+		if len(fields) < 2 || string(fields[0]) != "MemAvailable:" {
+			continue
+		}
+		kb, err := strconv.ParseUint(string(fields[1]), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return kb << 10, true
+	}
+	return 0, false
+}
@@ -0,0 +1,89 @@
+package tsm1
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheTuner_tune(t *testing.T) {
+	const mb = 1 << 20
+
+	newTuner := func(avail uint64, known bool) *cacheTuner {
+		return &cacheTuner{
+			minSize:         256 * mb,
+			maxSize:         4096 * mb,
+			minSnapshotSize: 25 * mb,
+			availableMemory: func() (uint64, bool) { return avail, known },
+		}
+	}
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("first call only records the incoming bytes", func(t *testing.T) {
+		tuner := newTuner(0, false)
+		if _, _, ok := tuner.tune(start, 0, 100*mb); ok {
+			t.Fatal("expected no adjustment")
+		}
+		if _, _, ok := tuner.tune(start.Add(time.Second), 0, 100*mb); ok {
+			t.Fatal("expected no adjustment before the tune interval")
+		}
+	})
+
+	t.Run("idle caches keep the minimum sizes", func(t *testing.T) {
+		tuner := newTuner(0, false)
+		tuner.tune(start, 0, 0)
+		maxSize, snapshotSize, ok := tuner.tune(start.Add(cacheTuneInterval), 0, 0)
+		if !ok {
+			t.Fatal("expected an adjustment")
+		}
+		if maxSize != 256*mb || snapshotSize != 25*mb {
+			t.Fatalf("got max size %d and snapshot size %d", maxSize, snapshotSize)
+		}
+	})
+
+	t.Run("caches grow with the write rate", func(t *testing.T) {
+		tuner := newTuner(0, false)
+		tuner.tune(start, 0, 0)
+		// 100MB/s, smoothed to 50MB/s.
+		maxSize, snapshotSize, _ := tuner.tune(start.Add(cacheTuneInterval), 0, 1000*mb)
+		if exp := uint64(500 * mb); snapshotSize != exp {
+			t.Fatalf("got snapshot size %d, expected %d", snapshotSize, exp)
+		}
+		if exp := uint64(2000 * mb); maxSize != exp {
+			t.Fatalf("got max size %d, expected %d", maxSize, exp)
+		}
+	})
+
+	t.Run("caches are bounded by the maximum size", func(t *testing.T) {
+		tuner := newTuner(0, false)
+		tuner.tune(start, 0, 0)
+		maxSize, snapshotSize, _ := tuner.tune(start.Add(cacheTuneInterval), 0, 10000*mb)
+		if maxSize != 4096*mb || snapshotSize != 2048*mb {
+			t.Fatalf("got max size %d and snapshot size %d", maxSize, snapshotSize)
+		}
+	})
+
+	t.Run("caches are bounded by the available memory", func(t *testing.T) {
+		tuner := newTuner(1000*mb, true)
+		tuner.tune(start, 0, 0)
+		maxSize, _, _ := tuner.tune(start.Add(cacheTuneInterval), 100*mb, 10000*mb)
+		if exp := uint64(600 * mb); maxSize != exp {
+			t.Fatalf("got max size %d, expected %d", maxSize, exp)
+		}
+
+		tuner = newTuner(100*mb, true)
+		tuner.tune(start, 0, 0)
+		maxSize, _, _ = tuner.tune(start.Add(cacheTuneInterval), 0, 10000*mb)
+		if exp := uint64(256 * mb); maxSize != exp {
+			t.Fatalf("got max size %d, expected the minimum size %d", maxSize, exp)
+		}
+	})
+}
+
+func TestCacheTuner_initialSize(t *testing.T) {
+	tuner := &cacheTuner{minSize: 10, maxSize: 100}
+	for _, tt := range []struct{ size, exp uint64 }{{5, 10}, {50, 50}, {500, 100}} {
+		if got := tuner.initialSize(tt.size); got != tt.exp {
+			t.Errorf("initialSize(%d) = %d, expected %d", tt.size, got, tt.exp)
+		}
+	}
+}
@@ -1031,11 +1031,19 @@ func (c *Compactor) writeNewFiles(generation, sequence int, src []string, iter K
 	// These are the new TSM files written
 	var files []string
 
+	// Compactions write their files next to the last of their inputs, of the
+	// latest generation, so that they stay on the volume their inputs were
+	// moved to. Snapshots are written to the directory of the engine.
+	dir := c.Dir
+	if len(src) > 0 {
+		dir = filepath.Dir(src[len(src)-1])
+	}
+
 	for {
 		sequence++
 
 		// New TSM files are written to a temp file and renamed when fully completed.
-		fileName := filepath.Join(c.Dir, c.formatFileName(generation, sequence)+"."+TSMFileExtension+"."+TmpTSMFileExtension)
+		fileName := filepath.Join(dir, c.formatFileName(generation, sequence)+"."+TSMFileExtension+"."+TmpTSMFileExtension)
 		statsFileName := StatsFilename(fileName)
 
 		// Write as much as possible to this file
@@ -578,6 +578,40 @@ func TestCompactor_Compact_UnsortedBlocksOverlapping(t *testing.T) {
 }
 
 // Ensures that a compaction will properly merge multiple TSM files
+// Ensures that compactions write their files next to their inputs when they
+// are in another data directory than the engine.
+func TestCompactor_CompactFull_DataDir(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	dataDir := MustTempDir()
+	defer os.RemoveAll(dataDir)
+
+	f1 := MustWriteTSM(dataDir, 1, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": {tsm1.NewValue(1, 1.1)},
+	})
+	f2 := MustWriteTSM(dataDir, 2, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": {tsm1.NewValue(2, 1.2)},
+	})
+
+	fs := &fakeFileStore{}
+	defer fs.Close()
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = fs
+	compactor.Open()
+
+	files, err := compactor.CompactFull([]string{f1, f2})
+	if err != nil {
+		t.Fatalf("unexpected error compacting: %v", err)
+	}
+	if got, exp := len(files), 1; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+	if got, exp := filepath.Dir(files[0]), dataDir; got != exp {
+		t.Fatalf("directory mismatch: got %v, exp %v", got, exp)
+	}
+}
+
 func TestCompactor_CompactFull_SkipFullBlocks(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
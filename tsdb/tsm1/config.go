@@ -12,6 +12,10 @@ var DefaultMaxConcurrentOpens = runtime.GOMAXPROCS(0)
 const (
 	DefaultMADVWillNeed = false
 
+	// DefaultMaxOpenFiles is the default limit of file descriptors held open
+	// by TSM files. 0 means no limit.
+	DefaultMaxOpenFiles = 0
+
 	// DefaultLargeSeriesWriteThreshold is the number of series per write
 	// that requires the series index be pregrown before insert.
 	DefaultLargeSeriesWriteThreshold = 10000
@@ -29,10 +33,24 @@ type Config struct {
 	// slow disks.
 	MADVWillNeed bool `toml:"use-madv-willneed"`
 
+	// MaxOpenFiles limits the number of file descriptors held open by TSM
+	// files. Once it is reached, the descriptors of the least recently used
+	// files are closed; their memory maps remain and keep serving reads. It
+	// should be set below the limit of open files of the process when there
+	// are thousands of TSM files. 0 means no limit.
+	MaxOpenFiles int `toml:"max-open-files"`
+
 	// LargeSeriesWriteThreshold is the threshold before a write requires
 	// preallocation to improve throughput. Currently used in the series file.
 	LargeSeriesWriteThreshold int `toml:"large-series-write-threshold"`
 
+	// DataDirs are additional directories TSM files are loaded from, such as
+	// directories on other volumes, for installs outgrowing the volume of the
+	// engine. Files are moved between them by "influxd inspect rebalance".
+	// Snapshots of the cache are written to the data directory of the
+	// engine, and compactions write their files next to their inputs.
+	DataDirs []string `toml:"data-dirs"`
+
 	Compaction CompactionConfig `toml:"compaction"`
 	Cache      CacheConfig      `toml:"cache"`
 }
@@ -42,6 +60,7 @@ func NewConfig() Config {
 	return Config{
 		MaxConcurrentOpens:        DefaultMaxConcurrentOpens,
 		MADVWillNeed:              DefaultMADVWillNeed,
+		MaxOpenFiles:              DefaultMaxOpenFiles,
 		LargeSeriesWriteThreshold: DefaultLargeSeriesWriteThreshold,
 
 		Cache: NewCacheConfig(),
@@ -90,6 +109,8 @@ const (
 	DefaultCacheSnapshotMemorySize        = toml.Size(25 << 20)             // 25MB
 	DefaultCacheSnapshotAgeDuration       = toml.Duration(0)                // Defaults to off.
 	DefaultCacheSnapshotWriteColdDuration = toml.Duration(10 * time.Minute) // Ten minutes
+	DefaultCacheAutoTuneMinMemorySize     = toml.Size(256 << 20)            // 256MB
+	DefaultCacheAutoTuneMaxMemorySize     = toml.Size(4096 << 20)           // 4GB
 )
 
 // CacheConfig holds all of the configuration for the in memory cache of values that
@@ -113,6 +134,18 @@ type CacheConfig struct {
 	//
 	// SnapshotWriteColdDuration should not be larger than SnapshotAgeDuration
 	SnapshotWriteColdDuration toml.Duration `toml:"snapshot-write-cold-duration"`
+
+	// AutoTune, when set, adjusts the maximum size of the cache and its snapshot
+	// size to the incoming write rate and the available memory, instead of
+	// using MaxMemorySize and SnapshotMemorySize as is. SnapshotMemorySize is
+	// then the smallest snapshot size.
+	AutoTune bool `toml:"auto-tune"`
+
+	// AutoTuneMinMemorySize and AutoTuneMaxMemorySize bound the maximum size
+	// of the cache when AutoTune is set. AutoTuneMinMemorySize is kept even
+	// when less memory is available.
+	AutoTuneMinMemorySize toml.Size `toml:"auto-tune-min-memory-size"`
+	AutoTuneMaxMemorySize toml.Size `toml:"auto-tune-max-memory-size"`
 }
 
 // NewCacheConfig initialises a new CacheConfig with default values.
@@ -122,6 +155,8 @@ func NewCacheConfig() CacheConfig {
 		SnapshotMemorySize:        DefaultCacheSnapshotMemorySize,
 		SnapshotAgeDuration:       DefaultCacheSnapshotAgeDuration,
 		SnapshotWriteColdDuration: DefaultCacheSnapshotWriteColdDuration,
+		AutoTuneMinMemorySize:     DefaultCacheAutoTuneMinMemorySize,
+		AutoTuneMaxMemorySize:     DefaultCacheAutoTuneMaxMemorySize,
 	}
 }
 
@@ -156,6 +156,10 @@ type Engine struct {
 	// a snapshot of the cache to a TSM file
 	CacheFlushWriteColdDuration time.Duration
 
+	// cacheTuner adjusts the maximum size of the cache and
+	// CacheFlushMemorySizeThreshold, when auto-tuning is enabled.
+	cacheTuner *cacheTuner
+
 	// Invoked when creating a backup file "as new".
 	formatFileName FormatFileNameFunc
 
@@ -182,6 +186,8 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 	fs := NewFileStore(path)
 	fs.openLimiter = limiter.NewFixed(config.MaxConcurrentOpens)
 	fs.tsmMMAPWillNeed = config.MADVWillNeed
+	fs.handles = newFileHandles(config.MaxOpenFiles, fs.tracker)
+	fs.WithDataDirs(config.DataDirs)
 
 	cache := NewCache(uint64(config.Cache.MaxMemorySize))
 
@@ -237,6 +243,11 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 		snapshotter:                    new(noSnapshotter),
 	}
 
+	if config.Cache.AutoTune {
+		e.cacheTuner = newCacheTuner(config.Cache)
+		cache.SetMaxSize(e.cacheTuner.initialSize(uint64(config.Cache.MaxMemorySize)))
+	}
+
 	for _, option := range options {
 		option(e)
 	}
@@ -523,7 +534,10 @@ func (e *Engine) initTrackers() {
 	// Propagate prometheus metrics down into trackers.
 	e.compactionTracker = newCompactionTracker(bms.compactionMetrics, e.defaultMetricLabels)
 	e.FileStore.tracker = newFileTracker(bms.fileMetrics, e.defaultMetricLabels)
+	e.FileStore.handles.setTracker(e.FileStore.tracker)
 	e.Cache.tracker = newCacheTracker(bms.cacheMetrics, e.defaultMetricLabels)
+	e.Cache.tracker.SetMaxSize(e.Cache.MaxSize())
+	e.Cache.tracker.SetSnapshotThreshold(e.CacheFlushMemorySizeThreshold)
 	e.readTracker = newReadTracker(bms.readMetrics, e.defaultMetricLabels)
 
 	e.scheduler.setCompactionTracker(e.compactionTracker)
@@ -914,6 +928,9 @@ func (e *Engine) compactCache() {
 
 		case <-t.C:
 			e.Cache.UpdateAge()
+			if e.cacheTuner != nil {
+				e.tuneCache(time.Now())
+			}
 			status := e.ShouldCompactCache(time.Now())
 			if status == CacheStatusOkay {
 				continue
@@ -932,6 +949,24 @@ func (e *Engine) compactCache() {
 	}
 }
 
+// tuneCache adjusts the maximum size of the cache and its snapshot threshold
+// to the incoming write rate and the available memory.
+func (e *Engine) tuneCache(now time.Time) {
+	maxSize, snapshotSize, ok := e.cacheTuner.tune(now, e.Cache.Size(), e.Cache.IncomingBytes())
+	if !ok {
+		return
+	}
+	if maxSize != e.Cache.MaxSize() || snapshotSize != e.CacheFlushMemorySizeThreshold {
+		e.logger.Debug("Cache tuned",
+			zap.Uint64("max_size", maxSize),
+			zap.Uint64("snapshot_size", snapshotSize),
+			zap.Float64("write_rate", e.cacheTuner.rate))
+	}
+	e.Cache.SetMaxSize(maxSize)
+	e.CacheFlushMemorySizeThreshold = snapshotSize
+	e.Cache.tracker.SetSnapshotThreshold(snapshotSize)
+}
+
 // CacheStatus describes the current state of the cache, with respect to whether
 // it is ready to be snapshotted or not.
 type CacheStatus int
@@ -1327,10 +1362,20 @@ func (e *Engine) fullCompactionStrategy(group CompactionGroup, optimize bool) *c
 	return s
 }
 
-// cleanup removes all temp files and dirs that exist on disk.  This is should only be run at startup to avoid
-// removing tmp files that are still in use.
+// cleanup removes all temp files and dirs that exist on disk, in every data
+// directory.  This is should only be run at startup to avoid removing tmp
+// files that are still in use.
 func (e *Engine) cleanup() error {
-	allfiles, err := ioutil.ReadDir(e.path)
+	for _, dir := range e.FileStore.Dirs() {
+		if err := e.cleanupDir(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Engine) cleanupDir(dir string) error {
+	allfiles, err := ioutil.ReadDir(dir)
 	if os.IsNotExist(err) {
 		return nil
 	} else if err != nil {
@@ -1341,17 +1386,17 @@ func (e *Engine) cleanup() error {
 	for _, f := range allfiles {
 		// Check to see if there are any `.tmp` directories that were left over from failed shard snapshots
 		if f.IsDir() && strings.HasSuffix(f.Name(), ext) {
-			if err := os.RemoveAll(filepath.Join(e.path, f.Name())); err != nil {
+			if err := os.RemoveAll(filepath.Join(dir, f.Name())); err != nil {
 				return fmt.Errorf("error removing tmp snapshot directory %q: %s", f.Name(), err)
 			}
 		}
 	}
 
-	return e.cleanupTempTSMFiles()
+	return cleanupTempTSMFiles(dir)
 }
 
-func (e *Engine) cleanupTempTSMFiles() error {
-	files, err := filepath.Glob(filepath.Join(e.path, fmt.Sprintf("*.%s", CompactionTempExtension)))
+func cleanupTempTSMFiles(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("*.%s", CompactionTempExtension)))
 	if err != nil {
 		return fmt.Errorf("error getting compaction temp files: %s", err.Error())
 	}
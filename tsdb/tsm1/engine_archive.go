@@ -0,0 +1,320 @@
+package tsm1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+// errExportDone stops walking the keys once they no longer have the exported prefix.
+var errExportDone = errors.New("export done")
+
+// ExportPrefix writes the data of the keys with the prefix name to new TSM files
+// in dir, with the prefix removed from the keys, and returns the paths of the files.
+// fn is called with every key written, without the prefix, and its block type.
+//
+// Only the data of the TSM files is exported, so the cache should be snapshotted
+// beforehand for the export to include recent writes.
+func (e *Engine) ExportPrefix(ctx context.Context, name []byte, dir string, fn func(key []byte, typ byte) error) ([]string, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	x := &prefixExporter{dir: dir}
+	err := e.FileStore.WalkKeys(name, func(key []byte, typ byte) error {
+		if !bytes.HasPrefix(key, name) {
+			return errExportDone
+		}
+
+		values, err := e.readAllValues(key)
+		if err != nil {
+			return err
+		} else if len(values) == 0 {
+			// Every value of the key has been deleted.
+			return nil
+		}
+
+		if err := x.write(key[len(name):], values); err != nil {
+			return err
+		}
+		return fn(key[len(name):], typ)
+	})
+	if err == errExportDone {
+		err = nil
+	}
+	if cerr := x.close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		x.remove()
+		return nil, err
+	}
+
+	span.LogKV("files", len(x.files))
+	return x.files, nil
+}
+
+// readAllValues returns the values of the key in all TSM files, without the
+// deleted values. Values of newer files replace values with the same timestamp
+// in older ones.
+func (e *Engine) readAllValues(key []byte) (Values, error) {
+	var (
+		values Values
+		err    error
+	)
+	e.FileStore.ForEachFile(func(f TSMFile) bool {
+		var fileValues Values
+		if fileValues, err = readFileValues(f, key); err != nil {
+			return false
+		}
+		values = values.Merge(fileValues)
+		return true
+	})
+	return values, err
+}
+
+// readFileValues returns the values of the key in the TSM file f, without the
+// deleted values.
+func readFileValues(f TSMFile, key []byte) (Values, error) {
+	if !f.Contains(key) {
+		return nil, nil
+	}
+
+	entries, err := f.ReadEntries(key, nil)
+	if err != nil {
+		return nil, err
+	}
+	tombstones := f.TombstoneRange(key, nil)
+
+	var values Values
+	for i := range entries {
+		v, err := f.ReadAt(&entries[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tombstones {
+			v = Values(v).Exclude(t.Min, t.Max)
+		}
+		values = append(values, v...)
+	}
+	return values.Deduplicate(), nil
+}
+
+// ExportFilesPrefix writes the data of the keys with the prefix name in the TSM
+// files at paths, such as the files of a backup, to new TSM files in dir like
+// ExportPrefix. The files are read in the order of paths, values of later files
+// replacing values with the same timestamp in earlier ones.
+func ExportFilesPrefix(ctx context.Context, name []byte, paths []string, dir string, fn func(key []byte, typ byte) error) ([]string, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	files := make([]TSMFile, 0, len(paths))
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	for _, path := range paths {
+		fd, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		r, err := NewTSMReader(fd)
+		if err != nil {
+			fd.Close()
+			return nil, fmt.Errorf("error opening TSM file %s: %v", path, err)
+		}
+		files = append(files, r)
+	}
+
+	x := &prefixExporter{dir: dir}
+	err := func() error {
+		ki := newMergeKeyIterator(files, name)
+		for ki.Next() {
+			key, typ := ki.Read()
+			if !bytes.HasPrefix(key, name) {
+				break
+			}
+
+			var values Values
+			for _, f := range files {
+				fileValues, err := readFileValues(f, key)
+				if err != nil {
+					return err
+				}
+				values = values.Merge(fileValues)
+			}
+			if len(values) == 0 {
+				continue
+			}
+
+			if err := x.write(key[len(name):], values); err != nil {
+				return err
+			} else if err := fn(key[len(name):], typ); err != nil {
+				return err
+			}
+		}
+		return ki.Err()
+	}()
+	if cerr := x.close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		x.remove()
+		return nil, err
+	}
+
+	span.LogKV("files", len(x.files))
+	return x.files, nil
+}
+
+// prefixExporter writes exported keys to TSM files, starting a new file when
+// the current one is full.
+type prefixExporter struct {
+	dir   string
+	files []string
+	w     TSMWriter
+}
+
+func (x *prefixExporter) write(key []byte, values Values) error {
+	for len(values) > 0 {
+		if x.w == nil || x.w.Size() > maxTSMFileSize {
+			if err := x.next(); err != nil {
+				return err
+			}
+		}
+
+		n := MaxPointsPerBlock
+		if n > len(values) {
+			n = len(values)
+		}
+		err := x.w.Write(key, values[:n])
+		if err != nil && err != ErrMaxBlocksExceeded {
+			return err
+		}
+		values = values[n:]
+
+		if err == ErrMaxBlocksExceeded && len(values) > 0 {
+			// The block was written, but the key continues in the next file.
+			if err := x.next(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// next finishes the current file and starts a new one.
+func (x *prefixExporter) next() error {
+	if err := x.close(); err != nil {
+		return err
+	}
+
+	path := filepath.Join(x.dir, fmt.Sprintf("%09d.%s", len(x.files)+1, TSMFileExtension))
+	fd, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	x.files = append(x.files, path)
+
+	if x.w, err = NewTSMWriterWithDiskBuffer(fd); err != nil {
+		fd.Close()
+		return err
+	}
+	return nil
+}
+
+func (x *prefixExporter) close() error {
+	if x.w == nil {
+		return nil
+	}
+	err := x.w.WriteIndex()
+	if cerr := x.w.Close(); err == nil {
+		err = cerr
+	}
+	x.w = nil
+	return err
+}
+
+func (x *prefixExporter) remove() {
+	for _, f := range x.files {
+		os.Remove(f)
+		os.Remove(StatsFilename(f))
+	}
+}
+
+// ImportPrefix loads the TSM file at path, written by ExportPrefix, into the engine
+// with the prefix name added to its keys. The blocks are copied without decoding
+// them. The series of the keys must already exist in the index.
+func (e *Engine) ImportPrefix(ctx context.Context, name []byte, path string) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	fd, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	r, err := NewTSMReader(fd)
+	if err != nil {
+		fd.Close()
+		return err
+	}
+	defer r.Close()
+
+	// The file is written as the first file of a new generation, like a
+	// snapshot, and left to the compactor.
+	newPath := filepath.Join(e.path, e.formatFileName(e.FileStore.NextGeneration(), 1)+"."+TSMFileExtension+"."+TmpTSMFileExtension)
+	if err := importPrefix(name, r, newPath); err != nil {
+		os.Remove(newPath)
+		os.Remove(StatsFilename(newPath))
+		return err
+	}
+
+	span.LogKV("path", newPath, "keys", r.KeyCount())
+	return e.FileStore.Replace(nil, []string{newPath})
+}
+
+func importPrefix(name []byte, r *TSMReader, path string) (err error) {
+	fd, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	w, err := NewTSMWriterWithDiskBuffer(fd)
+	if err != nil {
+		fd.Close()
+		return err
+	}
+	defer func() {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	var key []byte
+	iter := r.Iterator(nil)
+	for iter.Next() {
+		key = append(append(key[:0], name...), iter.Key()...)
+
+		entries := iter.Entries()
+		for i := range entries {
+			_, block, err := r.ReadBytes(&entries[i], nil)
+			if err != nil {
+				return err
+			}
+			// The exported file holds at most the maximum number of blocks of a
+			// key, which is reported when the last one is written.
+			err = w.WriteBlock(key, entries[i].MinTime, entries[i].MaxTime, block)
+			if err != nil && !(err == ErrMaxBlocksExceeded && i == len(entries)-1) {
+				return err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return w.WriteIndex()
+}
@@ -0,0 +1,190 @@
+package tsm1_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+)
+
+func TestEngine_ExportImportPrefix(t *testing.T) {
+	p1 := MustParsePointString("cpu,host=A value=1.1 1", "mm0")
+	p2 := MustParsePointString("cpu,host=A value=1.2 2", "mm0")
+	p3 := MustParsePointString("mem,host=A value=1i 1", "mm0")
+	p4 := MustParsePointString("cpu,host=A value=1.3 1", "mm1")
+
+	src, err := NewEngine(tsm1.NewConfig(), t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	if err := src.writePoints(p1, p2, p3, p4); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	if err := src.WriteSnapshot(context.Background(), tsm1.CacheStatusColdNoWrites); err != nil {
+		t.Fatalf("failed to snapshot: %s", err.Error())
+	}
+
+	// Deleted values are not exported.
+	if err := src.DeleteMeasurementRange(context.Background(), []byte("mm0"), []byte("cpu"), 2, 2); err != nil {
+		t.Fatalf("failed to delete measurement: %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "tsm1-export-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keys := map[string]byte{}
+	files, err := src.ExportPrefix(context.Background(), []byte("mm0"), dir, func(key []byte, typ byte) error {
+		keys[string(key)] = typ
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected a single file, got %v", files)
+	}
+
+	exp := map[string]byte{
+		",\x00=cpu,host=A,\xff=value#!~#value": tsm1.BlockFloat64,
+		",\x00=mem,host=A,\xff=value#!~#value": tsm1.BlockInteger,
+	}
+	if !reflect.DeepEqual(keys, exp) {
+		t.Fatalf("unexpected exported keys: %v != %v", keys, exp)
+	}
+
+	dst, err := NewEngine(tsm1.NewConfig(), t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+
+	if err := dst.ImportPrefix(context.Background(), []byte("mm2"), files[0]); err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+
+	exp = map[string]byte{
+		"mm2,\x00=cpu,host=A,\xff=value#!~#value": tsm1.BlockFloat64,
+		"mm2,\x00=mem,host=A,\xff=value#!~#value": tsm1.BlockInteger,
+	}
+	if got := dst.FileStore.Keys(); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected series in file store: %v != %v", got, exp)
+	}
+
+	values, err := dst.FileStore.Read([]byte("mm2,\x00=cpu,host=A,\xff=value#!~#value"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 || values[0].UnixNano() != 1 || values[0].Value() != 1.1 {
+		t.Fatalf("unexpected imported values: %v", values)
+	}
+}
+
+func TestExportFilesPrefix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsm1-export-files-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeFile := func(name string, data map[string][]tsm1.Value) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := tsm1.NewTSMWriter(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := w.Write([]byte(k), data[k]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.WriteIndex(); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// The second file holds newer values of the first one, as after a compaction.
+	paths := []string{
+		writeFile("000000001-000000001.tsm", map[string][]tsm1.Value{
+			"mm0,\x00=cpu,host=A,\xff=value#!~#value": {tsm1.NewValue(1, 1.1), tsm1.NewValue(2, 1.2)},
+			"mm1,\x00=cpu,host=A,\xff=value#!~#value": {tsm1.NewValue(1, 2.1)},
+		}),
+		writeFile("000000002-000000001.tsm", map[string][]tsm1.Value{
+			"mm0,\x00=cpu,host=A,\xff=value#!~#value": {tsm1.NewValue(2, 1.3)},
+			"mm0,\x00=mem,host=A,\xff=value#!~#value": {tsm1.NewValue(1, int64(1))},
+		}),
+	}
+
+	out, err := ioutil.TempDir("", "tsm1-export-files-out-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(out)
+
+	keys := map[string]byte{}
+	files, err := tsm1.ExportFilesPrefix(context.Background(), []byte("mm0"), paths, out, func(key []byte, typ byte) error {
+		keys[string(key)] = typ
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected a single file, got %v", files)
+	}
+
+	exp := map[string]byte{
+		",\x00=cpu,host=A,\xff=value#!~#value": tsm1.BlockFloat64,
+		",\x00=mem,host=A,\xff=value#!~#value": tsm1.BlockInteger,
+	}
+	if !reflect.DeepEqual(keys, exp) {
+		t.Fatalf("unexpected exported keys: %v != %v", keys, exp)
+	}
+
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	values, err := r.ReadAll([]byte(",\x00=cpu,host=A,\xff=value#!~#value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || values[0].Value() != 1.1 || values[1].Value() != 1.3 {
+		t.Fatalf("unexpected exported values: %v", values)
+	}
+}
@@ -20,6 +20,31 @@ import (
 // and series file data associated with the bucket. The provided time range ensures
 // that only bucket data for that range is removed.
 func (e *Engine) DeletePrefixRange(rootCtx context.Context, name []byte, min, max int64, pred Predicate) error {
+	return e.deletePrefixRange(rootCtx, name, min, max, pred, true)
+}
+
+// DeleteMeasurementRange removes the TSM data of a measurement of the bucket with
+// the escaped name within the provided time range, and removes the series of the
+// measurement left without data from the index and series file. The keys of the
+// measurement share a prefix, so unlike a predicate delete it only reads the keys
+// of the measurement.
+func (e *Engine) DeleteMeasurementRange(rootCtx context.Context, name, measurement []byte, min, max int64) error {
+	return e.deletePrefixRange(rootCtx, measurementPrefix(name, measurement), min, max, nil, false)
+}
+
+// measurementPrefix returns the prefix of the keys of a measurement of the bucket
+// with the escaped name.
+func measurementPrefix(name, measurement []byte) []byte {
+	prefix := models.AppendMakeKey(nil, name, models.Tags{models.NewTag(models.MeasurementTagKeyBytes, measurement)})
+	// Every key has a field key tag after the measurement tag, so the separator
+	// keeps the prefix from matching measurements sharing a prefix.
+	return append(prefix, ',')
+}
+
+// deletePrefixRange removes the data of the keys with the prefix name. When bucket
+// is true, the prefix is the name of a whole bucket, which is the measurement of
+// the index and can be dropped at once.
+func (e *Engine) deletePrefixRange(rootCtx context.Context, name []byte, min, max int64, pred Predicate, bucket bool) error {
 	span, ctx := tracing.StartSpanFromContext(rootCtx)
 	span.LogKV("name_prefix", fmt.Sprintf("%x", name),
 		"min", time.Unix(0, min), "max", time.Unix(0, max),
@@ -208,7 +233,7 @@ func (e *Engine) DeletePrefixRange(rootCtx context.Context, name []byte, min, ma
 		// the deletes of the data in the tsm files.
 
 		// In this case the entire measurement (bucket) can be removed from the index.
-		if min == math.MinInt64 && max == math.MaxInt64 && pred == nil {
+		if bucket && min == math.MinInt64 && max == math.MaxInt64 && pred == nil {
 			// The TSI index and Series File do not store series data in escaped form.
 			name = models.UnescapeMeasurement(name)
 
@@ -272,11 +297,11 @@ func (e *Engine) DeletePrefixRange(rootCtx context.Context, name []byte, min, ma
 			isLastBatch := i+batchSize > len(possiblyDeadKeysSlice)
 			batch, ids = batch[:0], ids[:0]
 
-			for j := 0; (i*batchSize)+j < len(possiblyDeadKeysSlice) && j < batchSize; j++ {
+			for j := 0; i+j < len(possiblyDeadKeysSlice) && j < batchSize; j++ {
 				var item tsi1.DropSeriesItem
 
 				// TODO(jeff): ugh reduce copies here
-				key := possiblyDeadKeysSlice[(i*batchSize)+j]
+				key := possiblyDeadKeysSlice[i+j]
 				item.Key = []byte(key)
 				item.Key, _ = SeriesAndFieldFromCompositeKey(item.Key)
 
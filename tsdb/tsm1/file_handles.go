@@ -0,0 +1,104 @@
+package tsm1
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// fileHandles limits the number of file descriptors held open by the TSM
+// readers of a FileStore, so that instances with many TSM files do not
+// exhaust the limit of open files of the process.
+//
+// The memory map of a TSM file stays valid after its descriptor is closed,
+// so once the limit is reached the descriptors of the least recently used
+// files are closed and their readers keep serving reads from the map.
+type fileHandles struct {
+	clock uint64 // incremented on every access, ordering the accesses of the files.
+
+	maxOpen int // maximum number of open descriptors; <= 0 means no limit.
+	tracker *fileTracker
+
+	mu   sync.Mutex
+	open map[*mmapAccessor]struct{}
+}
+
+func newFileHandles(maxOpen int, tracker *fileTracker) *fileHandles {
+	return &fileHandles{
+		maxOpen: maxOpen,
+		tracker: tracker,
+		open:    make(map[*mmapAccessor]struct{}),
+	}
+}
+
+// setTracker sets the tracker the number of open descriptors is reported to.
+func (h *fileHandles) setTracker(tracker *fileTracker) {
+	h.mu.Lock()
+	h.tracker = tracker
+	n := len(h.open)
+	h.mu.Unlock()
+
+	tracker.SetMaxOpenHandles(h.maxOpen)
+	tracker.SetOpenHandles(n)
+}
+
+// touch records an access to the file of m.
+func (h *fileHandles) touch(m *mmapAccessor) {
+	if h == nil {
+		return
+	}
+	atomic.StoreUint64(&m.lastAccess, atomic.AddUint64(&h.clock, 1))
+}
+
+// add records the open descriptor of m and closes the descriptors of the
+// least recently used files over the limit.
+func (h *fileHandles) add(m *mmapAccessor) {
+	if h == nil {
+		return
+	}
+	h.touch(m)
+
+	h.mu.Lock()
+	h.open[m] = struct{}{}
+	var evicted []*mmapAccessor
+	for h.maxOpen > 0 && len(h.open) > h.maxOpen {
+		lru := h.leastRecentlyUsed()
+		delete(h.open, lru)
+		evicted = append(evicted, lru)
+	}
+	n, tracker := len(h.open), h.tracker
+	h.mu.Unlock()
+
+	for _, e := range evicted {
+		if e.releaseFile() {
+			tracker.IncClosedHandles()
+		}
+	}
+	tracker.SetOpenHandles(n)
+}
+
+// remove forgets the descriptor of m once its file is closed.
+func (h *fileHandles) remove(m *mmapAccessor) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	delete(h.open, m)
+	n, tracker := len(h.open), h.tracker
+	h.mu.Unlock()
+
+	tracker.SetOpenHandles(n)
+}
+
+// leastRecentlyUsed returns the open file accessed the longest time ago.
+// h.mu must be held.
+func (h *fileHandles) leastRecentlyUsed() *mmapAccessor {
+	var lru *mmapAccessor
+	var oldest uint64
+	for m := range h.open {
+		if last := atomic.LoadUint64(&m.lastAccess); lru == nil || last < oldest {
+			lru, oldest = m, last
+		}
+	}
+	return lru
+}
@@ -0,0 +1,95 @@
+package tsm1
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+func TestFileHandles_ClosesLeastRecentlyUsed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsm1-file-handles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tracker := newFileTracker(newFileMetrics(nil), nil)
+	h := newFileHandles(2, tracker)
+
+	open := func() *mmapAccessor {
+		f, err := ioutil.TempFile(dir, "tsm")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write(make([]byte, 8)); err != nil {
+			t.Fatal(err)
+		}
+		b, err := mmap(f, 0, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := &mmapAccessor{logger: zap.NewNop(), b: b, f: f, _path: f.Name(), handles: h}
+		h.add(m)
+		return m
+	}
+
+	a, b := open(), open()
+	a.incAccess() // b is now the least recently used file.
+	c := open()
+
+	if a.f == nil || c.f == nil {
+		t.Fatal("expected the descriptors of recently used files to be open")
+	}
+	if b.f != nil {
+		t.Fatal("expected the descriptor of the least recently used file to be closed")
+	}
+	if got := len(h.open); got != 2 {
+		t.Fatalf("got %d open descriptors, expected 2", got)
+	}
+
+	// Closing a file whose descriptor was closed is not an error.
+	if err := b.close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(h.open); got != 1 {
+		t.Fatalf("got %d open descriptors, expected 1", got)
+	}
+	if err := c.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(tracker.metrics.PrometheusCollectors()...)
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		switch mf.GetName() {
+		case namespace + "_" + fileStoreSubsystem + "_open_handles":
+			if got := mf.GetMetric()[0].GetGauge().GetValue(); got != 0 {
+				t.Errorf("got %v open handles, expected 0", got)
+			}
+		case namespace + "_" + fileStoreSubsystem + "_closed_handles_total":
+			if got := mf.GetMetric()[0].GetCounter().GetValue(); got != 1 {
+				t.Errorf("got %v closed handles, expected 1", got)
+			}
+		}
+	}
+}
+
+func TestFileHandles_NoLimit(t *testing.T) {
+	h := newFileHandles(0, newFileTracker(newFileMetrics(nil), nil))
+	for i := 0; i < 10; i++ {
+		h.add(&mmapAccessor{logger: zap.NewNop()})
+	}
+	if got := len(h.open); got != 10 {
+		t.Fatalf("got %d open descriptors, expected 10", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
 	"os"
@@ -209,10 +210,12 @@ type FileStore struct {
 	currentGeneration     int        // internally maintained generation
 	currentGenerationFunc func() int // external generation
 	dir                   string
+	dataDirs              []string // additional directories of TSM files, such as on other volumes
 
 	files           []TSMFile
 	tsmMMAPWillNeed bool          // If true then the kernel will be advised MMAP_WILLNEED for TSM files.
 	openLimiter     limiter.Fixed // limit the number of concurrent opening TSM files.
+	handles         *fileHandles  // limit the number of file descriptors held open by TSM files.
 
 	logger *zap.Logger // Logger to be used for important messages
 
@@ -271,9 +274,24 @@ func NewFileStore(dir string) *FileStore {
 		tracker:       newFileTracker(newFileMetrics(nil), nil),
 	}
 	fs.purger.fileStore = fs
+	fs.handles = newFileHandles(0, fs.tracker)
 	return fs
 }
 
+// WithDataDirs sets the additional directories the TSM files of the file store
+// are loaded from, such as directories on other volumes the files were moved
+// to by "influxd inspect rebalance". Snapshots are written to the directory of
+// the file store, and compactions write their files next to their inputs.
+func (f *FileStore) WithDataDirs(dirs []string) {
+	f.dataDirs = dirs
+}
+
+// Dirs returns the directory of the file store followed by its additional
+// data directories.
+func (f *FileStore) Dirs() []string {
+	return append([]string{f.dir}, f.dataDirs...)
+}
+
 // WithObserver sets the observer for the file store.
 func (f *FileStore) WithObserver(obs FileStoreObserver) {
 	if obs == nil {
@@ -383,6 +401,25 @@ func (t *fileTracker) SetFileCount(files map[int]uint64) {
 	}
 }
 
+// SetOpenHandles sets the number of file descriptors held open by TSM readers.
+func (t *fileTracker) SetOpenHandles(n int) {
+	t.metrics.OpenHandles.With(t.labels).Set(float64(n))
+}
+
+// SetMaxOpenHandles sets the limit of open file descriptors.
+func (t *fileTracker) SetMaxOpenHandles(n int) {
+	if n < 0 {
+		n = 0
+	}
+	t.metrics.MaxOpenHandles.With(t.labels).Set(float64(n))
+}
+
+// IncClosedHandles increments the number of descriptors closed to stay under
+// the limit.
+func (t *fileTracker) IncClosedHandles() {
+	t.metrics.ClosedHandles.With(t.labels).Inc()
+}
+
 func (t *fileTracker) ClearFileCounts() {
 	labels := t.Labels()
 	for i := uint64(1); i <= 4; i++ {
@@ -640,7 +677,7 @@ func (f *FileStore) Open(ctx context.Context) error {
 		}
 	}
 
-	files, err := filepath.Glob(filepath.Join(f.dir, fmt.Sprintf("*.%s", TSMFileExtension)))
+	files, err := f.tsmFiles()
 	if err != nil {
 		return err
 	}
@@ -679,7 +716,8 @@ func (f *FileStore) Open(ctx context.Context) error {
 			df, err := NewTSMReader(file,
 				WithMadviseWillNeed(f.tsmMMAPWillNeed),
 				WithTSMReaderPageFaultLimiter(f.pageFaultLimiter),
-				WithTSMReaderLogger(f.logger))
+				WithTSMReaderLogger(f.logger),
+				WithTSMReaderFileHandles(f.handles))
 			f.logger.Info("Opened file",
 				zap.String("path", file.Name()),
 				zap.Int("id", idx),
@@ -745,6 +783,31 @@ func (f *FileStore) Open(ctx context.Context) error {
 	return nil
 }
 
+// tsmFiles returns the paths of the TSM files of the directory of the store
+// and of its additional data directories. A file found in several
+// directories, as left by an interrupted move between them, is only returned
+// from the first directory it is found in.
+func (f *FileStore) tsmFiles() ([]string, error) {
+	var files []string
+	seen := make(map[string]string)
+	for _, dir := range f.Dirs() {
+		paths, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("*.%s", TSMFileExtension)))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			name := filepath.Base(path)
+			if first, ok := seen[name]; ok {
+				f.logger.Warn("Ignoring TSM file found in several data directories", zap.String("path", path), zap.String("loaded_path", first))
+				continue
+			}
+			seen[name] = path
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
 // Close closes the file store.
 func (f *FileStore) Close() error {
 	// Make the object appear closed to other method calls.
@@ -877,6 +940,13 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 	updated := make([]TSMFile, 0, len(newFiles))
 	tsmTmpExt := fmt.Sprintf("%s.%s", TSMFileExtension, TmpTSMFileExtension)
 
+	// The directories whose entries change, which may be any of the data
+	// directories the files are in.
+	dirs := map[string]bool{f.dir: true}
+	for _, file := range append(newFiles, oldFiles...) {
+		dirs[filepath.Dir(file)] = true
+	}
+
 	// Rename all the new files to make them live on restart
 	for _, file := range newFiles {
 		if !strings.HasSuffix(file, tsmTmpExt) && !strings.HasSuffix(file, TSMFileExtension) {
@@ -921,7 +991,8 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 		tsm, err := NewTSMReader(fd,
 			WithMadviseWillNeed(f.tsmMMAPWillNeed),
 			WithTSMReaderPageFaultLimiter(f.pageFaultLimiter),
-			WithTSMReaderLogger(f.logger))
+			WithTSMReaderLogger(f.logger),
+			WithTSMReaderFileHandles(f.handles))
 		if err != nil {
 			return err
 		}
@@ -1022,8 +1093,10 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 		}
 	}
 
-	if err := fs.SyncDir(f.dir); err != nil {
-		return err
+	for dir := range dirs {
+		if err := fs.SyncDir(dir); err != nil {
+			return err
+		}
 	}
 
 	// Tell the purger about our in-use files we need to remove
@@ -1260,12 +1333,12 @@ func (f *FileStore) CreateSnapshot(ctx context.Context) (backupID int, backupDir
 	}
 	for _, tsmf := range files {
 		newpath := filepath.Join(backupDirFullPath, filepath.Base(tsmf.Path()))
-		if err := os.Link(tsmf.Path(), newpath); err != nil {
+		if err := linkOrCopy(tsmf.Path(), newpath); err != nil {
 			return 0, "", fmt.Errorf("error creating tsm hard link: %q", err)
 		}
 		for _, tf := range tsmf.TombstoneFiles() {
 			newpath := filepath.Join(backupDirFullPath, filepath.Base(tf.Path))
-			if err := os.Link(tf.Path, newpath); err != nil {
+			if err := linkOrCopy(tf.Path, newpath); err != nil {
 				return 0, "", fmt.Errorf("error creating tombstone hard link: %q", err)
 			}
 		}
@@ -1274,6 +1347,31 @@ func (f *FileStore) CreateSnapshot(ctx context.Context) (backupID int, backupDir
 	return backupID, backupDirFullPath, nil
 }
 
+// linkOrCopy creates a hard link to the file at src, or a copy of it when the
+// link fails, as when src is in a data directory on another volume than dst.
+func linkOrCopy(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
 func (f *FileStore) InternalBackupPath(backupID int) string {
 	return filepath.Join(f.dir, fmt.Sprintf("%d.%s", backupID, TmpTSMFileExtension))
 }
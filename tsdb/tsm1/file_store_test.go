@@ -2378,6 +2378,61 @@ func TestFileStore_Open(t *testing.T) {
 	}
 }
 
+func TestFileStore_Open_DataDirs(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	dataDir := MustTempDir()
+	defer os.RemoveAll(dataDir)
+
+	// Create 3 TSM files...
+	data := []keyValues{
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(0, 1.0)}},
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(1, 2.0)}},
+		keyValues{"mem", []tsm1.Value{tsm1.NewValue(0, 1.0)}},
+	}
+
+	files, err := newFileDir(dir, data...)
+	if err != nil {
+		fatal(t, "creating test files", err)
+	}
+
+	// ...move the second to the additional data directory, and copy the third,
+	// as an interrupted move would.
+	if err := os.Rename(files[1], filepath.Join(dataDir, filepath.Base(files[1]))); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(files[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dataDir, filepath.Base(files[2])), b, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := tsm1.NewFileStore(dir)
+	fs.WithDataDirs([]string{dataDir})
+	if err := fs.Open(context.Background()); err != nil {
+		fatal(t, "opening file store", err)
+	}
+	defer fs.Close()
+
+	if got, exp := fs.Count(), 3; got != exp {
+		t.Fatalf("file count mismatch: got %v, exp %v", got, exp)
+	}
+
+	if got, exp := fs.CurrentGeneration(), 4; got != exp {
+		t.Fatalf("current ID mismatch: got %v, exp %v", got, exp)
+	}
+
+	values, err := fs.Read([]byte("cpu"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 || values[0].Value() != 2.0 {
+		t.Fatalf("unexpected values of the moved file: %v", values)
+	}
+}
+
 func TestFileStore_Remove(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
@@ -133,15 +133,21 @@ func (m *compactionMetrics) PrometheusCollectors() []prometheus.Collector {
 type fileMetrics struct {
 	DiskSize *prometheus.GaugeVec
 	Files    *prometheus.GaugeVec
+
+	OpenHandles    *prometheus.GaugeVec   // Number of file descriptors held open by TSM readers.
+	MaxOpenHandles *prometheus.GaugeVec   // Limit of open file descriptors, 0 if unlimited.
+	ClosedHandles  *prometheus.CounterVec // Descriptors closed to stay under the limit.
 }
 
 // newFileMetrics initialises the prometheus metrics for tracking files on disk.
 func newFileMetrics(labels prometheus.Labels) *fileMetrics {
-	var names []string
+	var handleNames []string
 	for k := range labels {
-		names = append(names, k)
+		handleNames = append(handleNames, k)
 	}
-	names = append(names, "level")
+	sort.Strings(handleNames)
+
+	names := append(append([]string(nil), handleNames...), "level")
 	sort.Strings(names)
 
 	return &fileMetrics{
@@ -157,6 +163,24 @@ func newFileMetrics(labels prometheus.Labels) *fileMetrics {
 			Name:      "total",
 			Help:      "Number of files.",
 		}, names),
+		OpenHandles: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: fileStoreSubsystem,
+			Name:      "open_handles",
+			Help:      "Number of file descriptors held open by TSM readers.",
+		}, handleNames),
+		MaxOpenHandles: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: fileStoreSubsystem,
+			Name:      "max_open_handles",
+			Help:      "Maximum number of file descriptors held open by TSM readers, 0 if unlimited.",
+		}, handleNames),
+		ClosedHandles: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: fileStoreSubsystem,
+			Name:      "closed_handles_total",
+			Help:      "Number of file descriptors of least recently used TSM files closed to stay under the limit.",
+		}, handleNames),
 	}
 }
 
@@ -165,6 +189,9 @@ func (m *fileMetrics) PrometheusCollectors() []prometheus.Collector {
 	return []prometheus.Collector{
 		m.DiskSize,
 		m.Files,
+		m.OpenHandles,
+		m.MaxOpenHandles,
+		m.ClosedHandles,
 	}
 }
 
@@ -176,6 +203,9 @@ type cacheMetrics struct {
 	Age              *prometheus.GaugeVec
 	SnapshottedBytes *prometheus.CounterVec
 
+	MaxSize           *prometheus.GaugeVec // Size at which writes are rejected.
+	SnapshotThreshold *prometheus.GaugeVec // Size at which the cache is snapshotted.
+
 	// The following metrics include a ``"status" = {ok, error, dropped}` label
 	WrittenBytes *prometheus.CounterVec
 	Writes       *prometheus.CounterVec
@@ -223,6 +253,18 @@ func newCacheMetrics(labels prometheus.Labels) *cacheMetrics {
 			Name:      "snapshot_bytes",
 			Help:      "Number of bytes snapshotted.",
 		}, names),
+		MaxSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: cacheSubsystem,
+			Name:      "max_bytes",
+			Help:      "In-memory size of cache at which writes are rejected.",
+		}, names),
+		SnapshotThreshold: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: cacheSubsystem,
+			Name:      "snapshot_threshold_bytes",
+			Help:      "In-memory size of cache at which it is snapshotted.",
+		}, names),
 		WrittenBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace: namespace,
 			Subsystem: cacheSubsystem,
@@ -246,6 +288,8 @@ func (m *cacheMetrics) PrometheusCollectors() []prometheus.Collector {
 		m.SnapshotsActive,
 		m.Age,
 		m.SnapshottedBytes,
+		m.MaxSize,
+		m.SnapshotThreshold,
 		m.WrittenBytes,
 		m.Writes,
 	}
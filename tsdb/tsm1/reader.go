@@ -45,6 +45,9 @@ type TSMReader struct {
 
 	// limiter rate limits page faults by the underlying memory maps.
 	pageFaultLimiter *rate.Limiter
+
+	// handles limits the number of file descriptors held open by readers.
+	handles *fileHandles
 }
 
 type tsmReaderOption func(*TSMReader)
@@ -68,6 +71,15 @@ var WithTSMReaderLogger = func(logger *zap.Logger) tsmReaderOption {
 	}
 }
 
+// WithTSMReaderFileHandles is an option for limiting the number of file
+// descriptors held open by readers. The descriptor of the reader may be closed
+// once its file is mapped.
+var WithTSMReaderFileHandles = func(handles *fileHandles) tsmReaderOption {
+	return func(r *TSMReader) {
+		r.handles = handles
+	}
+}
+
 // NewTSMReader returns a new TSMReader from the given file.
 func NewTSMReader(f *os.File, options ...tsmReaderOption) (*TSMReader, error) {
 	t := &TSMReader{
@@ -107,6 +119,11 @@ func NewTSMReader(f *os.File, options ...tsmReaderOption) (*TSMReader, error) {
 		return nil, err
 	}
 
+	// Register the descriptor once the reader is usable, which may close the
+	// descriptors of other readers.
+	accessor.handles = t.handles
+	t.handles.add(accessor)
+
 	return t, nil
 }
 
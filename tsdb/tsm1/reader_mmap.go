@@ -18,6 +18,7 @@ import (
 type mmapAccessor struct {
 	accessCount uint64 // Counter incremented everytime the mmapAccessor is accessed
 	freeCount   uint64 // Counter to determine whether the accessor can free its resources
+	lastAccess  uint64 // Clock of handles at the last access, ordering the accesses of files
 
 	logger       *zap.Logger
 	mmapWillNeed bool // If true then mmap advise value MADV_WILLNEED will be provided the kernel for b.
@@ -29,6 +30,8 @@ type mmapAccessor struct {
 
 	pageFaultLimiter *mincore.Limiter // limits page fault accesses
 
+	handles *fileHandles // limits the number of open descriptors; f is nil once closed by it
+
 	index *indirectIndex
 }
 
@@ -119,6 +122,23 @@ func (m *mmapAccessor) free() error {
 
 func (m *mmapAccessor) incAccess() {
 	atomic.AddUint64(&m.accessCount, 1)
+	m.handles.touch(m)
+}
+
+// releaseFile closes the descriptor of the file, keeping its memory map.
+// It returns true if the descriptor was open.
+func (m *mmapAccessor) releaseFile() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.f == nil {
+		return false
+	}
+	if err := m.f.Close(); err != nil {
+		m.logger.Warn("Failed to close TSM file descriptor", zap.String("path", m._path), zap.Error(err))
+	}
+	m.f = nil
+	return true
 }
 
 func (m *mmapAccessor) rename(path string) error {
@@ -261,7 +281,16 @@ func (m *mmapAccessor) close() error {
 	}
 
 	m.b = nil
-	return m.f.Close()
+	m.handles.remove(m)
+
+	// The descriptor may have been closed already to stay under the limit
+	// of open files.
+	f := m.f
+	m.f = nil
+	if f == nil {
+		return nil
+	}
+	return f.Close()
 }
 
 // wait rate limits page faults to the underlying data. Skipped if limiter is not set.
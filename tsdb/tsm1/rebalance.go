@@ -0,0 +1,272 @@
+package tsm1
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2/pkg/fs"
+)
+
+// rebalanceTmpExtension is the extension of the copies of files being moved
+// by Rebalance, renamed once complete.
+const rebalanceTmpExtension = "rebalance"
+
+// Rebalance moves TSM files between the data directories of an engine, so
+// that the volumes of the directories are left with about the same free
+// space. The engine must not be running. Files are moved with their
+// tombstone and stats files, largest first, which favors the fully compacted
+// files that are rarely rewritten; files rewritten by compactions are written
+// back to the data directory of the engine.
+type Rebalance struct {
+	Stdout io.Writer
+
+	// Dirs are the data directories, the data directory of the engine first,
+	// each on another volume.
+	Dirs []string
+
+	// DryRun only reports the files that would be moved.
+	DryRun bool
+
+	// DiskUsage returns the usage of the volume of a directory, fs.DiskUsage
+	// when nil.
+	DiskUsage func(path string) (fs.DiskStatus, error)
+}
+
+// rebalanceFile is a TSM file of a data directory, with the total size of
+// its files.
+type rebalanceFile struct {
+	name string
+	size int64
+}
+
+// Run moves the files.
+func (r *Rebalance) Run() error {
+	if len(r.Dirs) < 2 {
+		return fmt.Errorf("at least two data directories are required, got %d", len(r.Dirs))
+	}
+	diskUsage := r.DiskUsage
+	if diskUsage == nil {
+		diskUsage = fs.DiskUsage
+	}
+
+	if !r.DryRun {
+		if err := r.cleanup(); err != nil {
+			return err
+		}
+	}
+
+	free := make([]int64, len(r.Dirs))
+	files := make([][]rebalanceFile, len(r.Dirs))
+	devices := make(map[uint64]string)
+	for i, dir := range r.Dirs {
+		status, err := diskUsage(dir)
+		if err != nil {
+			return fmt.Errorf("unable to determine the free space of %s: %v", dir, err)
+		}
+		if status.Device != 0 {
+			if other, ok := devices[status.Device]; ok {
+				return fmt.Errorf("data directories %s and %s are on the same volume", other, dir)
+			}
+			devices[status.Device] = dir
+		}
+		free[i] = int64(status.Free)
+
+		if files[i], err = rebalanceFiles(dir); err != nil {
+			return err
+		}
+	}
+
+	var moved, movedSize int64
+	for {
+		src, dst := 0, 0
+		for i := range r.Dirs {
+			if free[i] < free[src] {
+				src = i
+			}
+			if free[i] > free[dst] {
+				dst = i
+			}
+		}
+
+		// The largest file of at most half the difference of free space
+		// makes it smaller, until no file does.
+		gap := free[dst] - free[src]
+		idx := sort.Search(len(files[src]), func(i int) bool { return 2*files[src][i].size <= gap })
+		if idx == len(files[src]) {
+			break
+		}
+		f := files[src][idx]
+
+		fmt.Fprintf(r.Stdout, "Moving %s (%d bytes) from %s to %s\n", f.name, f.size, r.Dirs[src], r.Dirs[dst])
+		if !r.DryRun {
+			if err := moveTSMFile(f.name, r.Dirs[src], r.Dirs[dst]); err != nil {
+				return fmt.Errorf("unable to move %s: %v", f.name, err)
+			}
+		}
+
+		files[src] = append(files[src][:idx], files[src][idx+1:]...)
+		files[dst] = insertRebalanceFile(files[dst], f)
+		free[src] += f.size
+		free[dst] -= f.size
+		moved++
+		movedSize += f.size
+	}
+
+	fmt.Fprintf(r.Stdout, "Moved %d files (%d bytes)\n", moved, movedSize)
+	for i, dir := range r.Dirs {
+		fmt.Fprintf(r.Stdout, "%s: %d files, %d bytes free\n", dir, len(files[i]), free[i])
+	}
+	return nil
+}
+
+// cleanup removes what interrupted moves left in the data directories: the
+// incomplete copies, the copies of files also in a previous directory, which
+// the engine ignores, and the tombstone and stats files without a TSM file.
+func (r *Rebalance) cleanup() error {
+	seen := make(map[string]bool)
+	for _, dir := range r.Dirs {
+		tmps, err := filepath.Glob(filepath.Join(dir, "*."+rebalanceTmpExtension))
+		if err != nil {
+			return err
+		}
+		for _, path := range tmps {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+
+		paths, err := filepath.Glob(filepath.Join(dir, "*."+TSMFileExtension))
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			name := filepath.Base(path)
+			if seen[name] {
+				fmt.Fprintf(r.Stdout, "Removing %s, a copy of a file of a previous data directory\n", path)
+				if err := removeTSMFile(path); err != nil {
+					return err
+				}
+			}
+			seen[name] = true
+		}
+
+		for _, pattern := range []string{"*.tombstone", "*." + TSSFileExtension} {
+			paths, err := filepath.Glob(filepath.Join(dir, pattern))
+			if err != nil {
+				return err
+			}
+			for _, path := range paths {
+				tsmPath := path[:len(path)-len(filepath.Ext(path))] + "." + TSMFileExtension
+				if _, err := os.Stat(tsmPath); os.IsNotExist(err) {
+					if err := os.Remove(path); err != nil {
+						return err
+					}
+				} else if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// rebalanceFiles returns the TSM files of dir, largest first.
+func rebalanceFiles(dir string) ([]rebalanceFile, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*."+TSMFileExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]rebalanceFile, 0, len(paths))
+	for _, path := range paths {
+		f := rebalanceFile{name: filepath.Base(path)}
+		for _, p := range tsmFileSet(path) {
+			fi, err := os.Stat(p)
+			if os.IsNotExist(err) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			f.size += fi.Size()
+		}
+		files = append(files, f)
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].size > files[j].size })
+	return files, nil
+}
+
+func insertRebalanceFile(files []rebalanceFile, f rebalanceFile) []rebalanceFile {
+	i := sort.Search(len(files), func(i int) bool { return files[i].size <= f.size })
+	files = append(files, rebalanceFile{})
+	copy(files[i+1:], files[i:])
+	files[i] = f
+	return files
+}
+
+// tsmFileSet returns the paths of the files of the TSM file at path, the
+// TSM file last.
+func tsmFileSet(path string) []string {
+	return []string{(&Tombstoner{Path: path}).tombstonePath(), StatsFilename(path), path}
+}
+
+// moveTSMFile moves the TSM file name and its other files from the directory
+// src to dst. The TSM file is renamed into dst once all its files are
+// copied, and removed from src after, so that an interrupted move leaves a
+// complete file in at least one of the directories.
+func moveTSMFile(name, src, dst string) error {
+	for _, path := range tsmFileSet(filepath.Join(src, name)) {
+		if err := copyFileSync(path, filepath.Join(dst, filepath.Base(path))); os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+	}
+	if err := fs.SyncDir(dst); err != nil {
+		return err
+	}
+
+	if err := removeTSMFile(filepath.Join(src, name)); err != nil {
+		return err
+	}
+	return fs.SyncDir(src)
+}
+
+// removeTSMFile removes the TSM file at path and then its other files.
+func removeTSMFile(path string) error {
+	paths := tsmFileSet(path)
+	for i := len(paths) - 1; i >= 0; i-- {
+		if err := os.Remove(paths[i]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFileSync copies the file at src to a temporary file, synced and then
+// renamed to dst.
+func copyFileSync(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + "." + rebalanceTmpExtension
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	} else if err := out.Sync(); err != nil {
+		return err
+	} else if err := out.Close(); err != nil {
+		return err
+	}
+	return fs.RenameFileWithReplacement(tmp, dst)
+}
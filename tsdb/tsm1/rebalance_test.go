@@ -0,0 +1,87 @@
+package tsm1_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/pkg/fs"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+)
+
+func TestRebalance(t *testing.T) {
+	root, err := ioutil.TempDir("", "tsm1-rebalance-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	dirs := []string{filepath.Join(root, "data"), filepath.Join(root, "data2")}
+	for _, dir := range dirs {
+		if err := os.Mkdir(dir, 0777); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeFile := func(path string, size int) {
+		t.Helper()
+		if err := ioutil.WriteFile(path, make([]byte, size), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(filepath.Join(dirs[0], "000000001-000000004.tsm"), 250)
+	writeFile(filepath.Join(dirs[0], "000000001-000000004.tombstone"), 50)
+	writeFile(filepath.Join(dirs[0], "000000002-000000004.tsm"), 200)
+	writeFile(filepath.Join(dirs[0], "000000003-000000001.tsm"), 100)
+	// left by an interrupted move.
+	writeFile(filepath.Join(dirs[1], "000000003-000000001.tsm.rebalance"), 10)
+	writeFile(filepath.Join(dirs[1], "000000004-000000001.tombstone"), 10)
+
+	free := map[string]uint64{dirs[0]: 100, dirs[1]: 1000}
+	var out bytes.Buffer
+	r := tsm1.Rebalance{
+		Stdout: &out,
+		Dirs:   dirs,
+		DiskUsage: func(path string) (fs.DiskStatus, error) {
+			return fs.DiskStatus{Device: uint64(len(path)), Free: free[path]}, nil
+		},
+	}
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	list := func(dir string) []string {
+		t.Helper()
+		fis, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, fi := range fis {
+			names = append(names, fi.Name())
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	// The largest file with its tombstone, then the smallest, even out the free space.
+	exp := []string{"000000002-000000004.tsm"}
+	if got := list(dirs[0]); !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected files of %s: %v != %v\n%s", dirs[0], got, exp, out.String())
+	}
+	exp = []string{"000000001-000000004.tombstone", "000000001-000000004.tsm", "000000003-000000001.tsm"}
+	if got := list(dirs[1]); !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected files of %s: %v != %v\n%s", dirs[1], got, exp, out.String())
+	}
+
+	r.DiskUsage = func(path string) (fs.DiskStatus, error) {
+		return fs.DiskStatus{Device: 1}, nil
+	}
+	if err := r.Run(); err == nil {
+		t.Error("expected directories on the same volume to be rejected")
+	}
+}
@@ -107,6 +107,10 @@ type UserResourceMapping struct {
 	MappingType  MappingType  `json:"mappingType"`
 	ResourceType ResourceType `json:"resourceType"`
 	ResourceID   ID           `json:"resourceID"`
+	// Restricted is set on the mappings of the resources restricted to
+	// their members and owners, who are only then given permissions on the
+	// resources themselves.
+	Restricted bool `json:"restricted,omitempty"`
 }
 
 // Validate reports any validation errors for the mapping.
@@ -142,11 +146,40 @@ type UserResourceMappingFilter struct {
 	UserType     UserType
 }
 
+// restrictableResourceTypes are the types of the resources that can be
+// restricted to their members and owners, who are given permissions on the
+// resources themselves while they are restricted.
+var restrictableResourceTypes = map[ResourceType]bool{
+	DashboardsResourceType:   true,
+	VariablesResourceType:    true,
+	SavedQueriesResourceType: true,
+}
+
+// resourcePerms returns the permissions to act on the resource of the mapping.
+func (m *UserResourceMapping) resourcePerms(actions ...Action) []Permission {
+	id := m.ResourceID
+	ps := make([]Permission, 0, len(actions))
+	for _, a := range actions {
+		ps = append(ps, Permission{
+			Action: a,
+			Resource: Resource{
+				Type: m.ResourceType,
+				ID:   &id,
+			},
+		})
+	}
+	return ps
+}
+
 func (m *UserResourceMapping) ownerPerms() ([]Permission, error) {
 	if m.ResourceType == OrgsResourceType {
 		return OwnerPermissions(m.ResourceID), nil
 	}
 
+	if m.Restricted && restrictableResourceTypes[m.ResourceType] {
+		return m.resourcePerms(ReadAction, WriteAction), nil
+	}
+
 	ps := []Permission{
 		// TODO: Uncomment these once the URM system is no longer being used for find lookups for:
 		// 	Telegraf
@@ -180,6 +213,10 @@ func (m *UserResourceMapping) memberPerms() ([]Permission, error) {
 		return []Permission{MemberBucketPermission(m.ResourceID)}, nil
 	}
 
+	if m.Restricted && restrictableResourceTypes[m.ResourceType] {
+		return m.resourcePerms(ReadAction), nil
+	}
+
 	ps := []Permission{
 		// TODO: Uncomment these once the URM system is no longer being used for find lookups for:
 		// 	Telegraf
@@ -162,6 +162,45 @@ func TestOwnerMappingToPermissions(t *testing.T) {
 				err:   false,
 				perms: influxdb.Permission{Action: "read", Resource: influxdb.Resource{Type: "buckets", ID: ResourceID}}},
 		},
+		{
+			name: "Dashboard Member User Has Permission To Read Dashboard",
+			urm: influxdb.UserResourceMapping{
+				UserID:       influxdbtesting.MustIDBase16("debac1e0deadbeef"),
+				UserType:     influxdb.Member,
+				ResourceType: influxdb.DashboardsResourceType,
+				ResourceID:   influxdbtesting.MustIDBase16("020f755c3c082000"),
+				Restricted:   true,
+			},
+			wants: wants{
+				err:   false,
+				perms: influxdb.Permission{Action: "read", Resource: influxdb.Resource{Type: "dashboards", ID: ResourceID}}},
+		},
+		{
+			name: "Dashboard Owner User Has Permission To Write Dashboard",
+			urm: influxdb.UserResourceMapping{
+				UserID:       influxdbtesting.MustIDBase16("debac1e0deadbeef"),
+				UserType:     influxdb.Owner,
+				ResourceType: influxdb.DashboardsResourceType,
+				ResourceID:   influxdbtesting.MustIDBase16("020f755c3c082000"),
+				Restricted:   true,
+			},
+			wants: wants{
+				err:   false,
+				perms: influxdb.Permission{Action: "write", Resource: influxdb.Resource{Type: "dashboards", ID: ResourceID}}},
+		},
+		{
+			name: "Variable Owner User Has Permission To Write Variable",
+			urm: influxdb.UserResourceMapping{
+				UserID:       influxdbtesting.MustIDBase16("debac1e0deadbeef"),
+				UserType:     influxdb.Owner,
+				ResourceType: influxdb.VariablesResourceType,
+				ResourceID:   influxdbtesting.MustIDBase16("020f755c3c082000"),
+				Restricted:   true,
+			},
+			wants: wants{
+				err:   false,
+				perms: influxdb.Permission{Action: "write", Resource: influxdb.Resource{Type: "variables", ID: ResourceID}}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -172,3 +211,17 @@ func TestOwnerMappingToPermissions(t *testing.T) {
 		})
 	}
 }
+
+func TestUnrestrictedMappingToPermissions(t *testing.T) {
+	for _, userType := range []influxdb.UserType{influxdb.Owner, influxdb.Member} {
+		urm := influxdb.UserResourceMapping{
+			UserID:       influxdbtesting.MustIDBase16("debac1e0deadbeef"),
+			UserType:     userType,
+			ResourceType: influxdb.DashboardsResourceType,
+			ResourceID:   influxdbtesting.MustIDBase16("020f755c3c082000"),
+		}
+		perms, err := urm.ToPermissions()
+		require.NoError(t, err)
+		require.Empty(t, perms, "the %s of a dashboard that is not restricted only gets permissions from its organization", userType)
+	}
+}
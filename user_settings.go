@@ -0,0 +1,61 @@
+package influxdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Result formats of the queries of a user.
+const (
+	ResultFormatCSV   = "csv"
+	ResultFormatTable = "table"
+)
+
+// UserSettings are the preferences of a user, stored server-side so that the
+// CLI and UI follow the user across machines.
+type UserSettings struct {
+	UserID ID `json:"userID"`
+	// DefaultOrgID and DefaultBucketID are the organization and bucket of
+	// the requests that do not name one.
+	DefaultOrgID    ID `json:"defaultOrgID,omitempty"`
+	DefaultBucketID ID `json:"defaultBucketID,omitempty"`
+	// Timezone is the IANA name of the timezone times are displayed in.
+	Timezone string `json:"timezone,omitempty"`
+	// ResultFormat is the format query results are displayed in.
+	ResultFormat string `json:"resultFormat,omitempty"`
+	CRUDLog
+}
+
+// Valid returns an error if the settings are missing their user or have an
+// invalid preference.
+func (s *UserSettings) Valid() error {
+	if !s.UserID.Valid() {
+		return errors.New("userID is required")
+	}
+	if s.DefaultBucketID.Valid() && !s.DefaultOrgID.Valid() {
+		return errors.New("defaultBucketID requires defaultOrgID")
+	}
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("unknown timezone %q", s.Timezone)
+		}
+	}
+	switch s.ResultFormat {
+	case "", ResultFormatCSV, ResultFormatTable:
+	default:
+		return fmt.Errorf("result format %q must be %s or %s", s.ResultFormat, ResultFormatCSV, ResultFormatTable)
+	}
+	return nil
+}
+
+// UserSettingsService stores the settings of users.
+type UserSettingsService interface {
+	// FindUserSettings returns the settings of the user. A user without
+	// settings has empty ones.
+	FindUserSettings(ctx context.Context, userID ID) (*UserSettings, error)
+
+	// PutUserSettings replaces the settings of the user.
+	PutUserSettings(ctx context.Context, s *UserSettings) error
+}
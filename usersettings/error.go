@@ -0,0 +1,40 @@
+package usersettings
+
+import (
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrInvalidUserID is used when the ID of the user cannot be encoded.
+	ErrInvalidUserID = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "user ID is invalid",
+	}
+)
+
+// ErrInvalidSettings is used when a service was provided invalid settings.
+func ErrInvalidSettings(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "user settings provided are invalid",
+		Err:  err,
+	}
+}
+
+// ErrDefaultBucket is used when the default bucket is not a bucket of the
+// default organization.
+func ErrDefaultBucket(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "default bucket is not a bucket of the default organization",
+		Err:  err,
+	}
+}
+
+// ErrInternalService is used when the error comes from an internal system.
+func ErrInternalService(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
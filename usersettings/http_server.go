@@ -0,0 +1,138 @@
+package usersettings
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixMeSettings is the prefix of the API of the settings of the
+	// authenticated user.
+	PrefixMeSettings = "/api/v2/me/settings"
+)
+
+// Handler is the HTTP API handler for the settings of the authenticated user.
+type Handler struct {
+	chi.Router
+	api         *kithttp.API
+	log         *zap.Logger
+	settingsSvc influxdb.UserSettingsService
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, settingsSvc influxdb.UserSettingsService) *Handler {
+	h := &Handler{
+		api:         kithttp.NewAPI(kithttp.WithLog(log)),
+		log:         log,
+		settingsSvc: settingsSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Get("/", h.handleGetSettings)
+		r.Put("/", h.handlePutSettings)
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixMeSettings
+}
+
+type settingsResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.UserSettings
+}
+
+func newSettingsResponse(us *influxdb.UserSettings) *settingsResponse {
+	return &settingsResponse{
+		Links: map[string]string{
+			"self": PrefixMeSettings,
+			"user": fmt.Sprintf("/api/v2/users/%s", us.UserID),
+		},
+		UserSettings: us,
+	}
+}
+
+type putSettingsRequest struct {
+	DefaultOrgID    influxdb.ID `json:"defaultOrgID"`
+	DefaultBucketID influxdb.ID `json:"defaultBucketID"`
+	Timezone        string      `json:"timezone"`
+	ResultFormat    string      `json:"resultFormat"`
+}
+
+// decodeUserID returns the ID of the authenticated user of r.
+func decodeUserID(r *http.Request) (influxdb.ID, error) {
+	a, err := icontext.GetAuthorizer(r.Context())
+	if err != nil {
+		return 0, err
+	}
+	id := a.GetUserID()
+	if !id.Valid() {
+		return 0, &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  "settings are only available to users",
+		}
+	}
+	return id, nil
+}
+
+func (h *Handler) handleGetSettings(w http.ResponseWriter, r *http.Request) {
+	userID, err := decodeUserID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	us, err := h.settingsSvc.FindUserSettings(r.Context(), userID)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newSettingsResponse(us))
+}
+
+func (h *Handler) handlePutSettings(w http.ResponseWriter, r *http.Request) {
+	userID, err := decodeUserID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var req putSettingsRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	us := &influxdb.UserSettings{
+		UserID:          userID,
+		DefaultOrgID:    req.DefaultOrgID,
+		DefaultBucketID: req.DefaultBucketID,
+		Timezone:        req.Timezone,
+		ResultFormat:    req.ResultFormat,
+	}
+	if err := h.settingsSvc.PutUserSettings(r.Context(), us); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("User settings updated", zap.String("settings", fmt.Sprint(us)))
+
+	h.api.Respond(w, r, http.StatusOK, newSettingsResponse(us))
+}
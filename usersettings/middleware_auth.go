@@ -0,0 +1,41 @@
+package usersettings
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+var _ influxdb.UserSettingsService = (*AuthedService)(nil)
+
+// AuthedService requires read access to a user to see its settings and write
+// access to change them. The default organization of the settings must be
+// readable by the user changing them.
+type AuthedService struct {
+	s influxdb.UserSettingsService
+}
+
+// NewAuthedService wraps s with user authorization.
+func NewAuthedService(s influxdb.UserSettingsService) *AuthedService {
+	return &AuthedService{s: s}
+}
+
+func (s *AuthedService) FindUserSettings(ctx context.Context, userID influxdb.ID) (*influxdb.UserSettings, error) {
+	if _, _, err := authorizer.AuthorizeReadResource(ctx, influxdb.UsersResourceType, userID); err != nil {
+		return nil, err
+	}
+	return s.s.FindUserSettings(ctx, userID)
+}
+
+func (s *AuthedService) PutUserSettings(ctx context.Context, us *influxdb.UserSettings) error {
+	if _, _, err := authorizer.AuthorizeWriteResource(ctx, influxdb.UsersResourceType, us.UserID); err != nil {
+		return err
+	}
+	if us.DefaultOrgID.Valid() {
+		if _, _, err := authorizer.AuthorizeReadOrg(ctx, us.DefaultOrgID); err != nil {
+			return err
+		}
+	}
+	return s.s.PutUserSettings(ctx, us)
+}
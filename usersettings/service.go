@@ -0,0 +1,125 @@
+// Package usersettings implements the settings of users, such as their
+// default organization and bucket, stored server-side so that the CLI and UI
+// follow a user across machines.
+package usersettings
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+var settingsBucket = []byte("usersettingsv1")
+
+var _ influxdb.UserSettingsService = (*Service)(nil)
+
+// Service stores the settings of users in a kv store.
+type Service struct {
+	store         kv.Store
+	bucketSvc     influxdb.BucketService
+	TimeGenerator influxdb.TimeGenerator
+}
+
+// NewService constructs a user settings service backed by st. The default
+// buckets of the settings are looked up in bucketSvc.
+func NewService(st kv.Store, bucketSvc influxdb.BucketService) *Service {
+	return &Service{
+		store:         st,
+		bucketSvc:     bucketSvc,
+		TimeGenerator: influxdb.RealTimeGenerator{},
+	}
+}
+
+// FindUserSettings returns the settings of the user.
+func (s *Service) FindUserSettings(ctx context.Context, userID influxdb.ID) (*influxdb.UserSettings, error) {
+	var us *influxdb.UserSettings
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		us, err = s.getSettings(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return us, nil
+}
+
+// PutUserSettings replaces the settings of the user. The default bucket
+// must be a bucket of the default organization.
+func (s *Service) PutUserSettings(ctx context.Context, us *influxdb.UserSettings) error {
+	if err := us.Valid(); err != nil {
+		return ErrInvalidSettings(err)
+	}
+	if us.DefaultBucketID.Valid() {
+		if _, err := s.bucketSvc.FindBucket(ctx, influxdb.BucketFilter{ID: &us.DefaultBucketID, OrganizationID: &us.DefaultOrgID}); err != nil {
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				return ErrDefaultBucket(err)
+			}
+			return err
+		}
+	}
+
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		existing, err := s.getSettings(tx, us.UserID)
+		if err != nil {
+			return err
+		}
+
+		now := s.TimeGenerator.Now()
+		us.CreatedAt = existing.CreatedAt
+		if us.CreatedAt.IsZero() {
+			us.CreatedAt = now
+		}
+		us.UpdatedAt = now
+		return s.putSettings(tx, us)
+	})
+}
+
+// getSettings returns the settings of the user, or empty settings if it has
+// none.
+func (s *Service) getSettings(tx kv.Tx, userID influxdb.ID) (*influxdb.UserSettings, error) {
+	key, err := userID.Encode()
+	if err != nil {
+		return nil, ErrInvalidUserID
+	}
+
+	b, err := tx.Bucket(settingsBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(key)
+	if kv.IsNotFound(err) {
+		return &influxdb.UserSettings{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+
+	us := &influxdb.UserSettings{}
+	if err := json.Unmarshal(v, us); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return us, nil
+}
+
+func (s *Service) putSettings(tx kv.Tx, us *influxdb.UserSettings) error {
+	key, err := us.UserID.Encode()
+	if err != nil {
+		return ErrInvalidUserID
+	}
+	v, err := json.Marshal(us)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+
+	b, err := tx.Bucket(settingsBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(key, v); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
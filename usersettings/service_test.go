@@ -0,0 +1,108 @@
+package usersettings_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/influxdata/influxdb/v2/usersettings"
+)
+
+func newTestService(t *testing.T) (*usersettings.Service, func()) {
+	t.Helper()
+
+	s, done := influxdbtesting.NewTestBoltStore(t)
+	// Only bucket 10 belongs to organization 1.
+	buckets := influxdbtesting.NewBucketFinder(&influxdb.Bucket{ID: 10, OrgID: 1})
+
+	svc := usersettings.NewService(s, buckets)
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)}
+	return svc, done
+}
+
+func TestService_PutAndFind(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	us, err := svc.FindUserSettings(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if us.UserID != 5 || us.DefaultOrgID.Valid() || us.Timezone != "" {
+		t.Fatalf("expected empty settings of user 5, got %+v", us)
+	}
+
+	put := &influxdb.UserSettings{
+		UserID:          5,
+		DefaultOrgID:    1,
+		DefaultBucketID: 10,
+		Timezone:        "UTC",
+		ResultFormat:    influxdb.ResultFormatTable,
+	}
+	if err := svc.PutUserSettings(ctx, put); err != nil {
+		t.Fatal(err)
+	}
+
+	us, err = svc.FindUserSettings(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if us.DefaultOrgID != 1 || us.DefaultBucketID != 10 || us.Timezone != "UTC" || us.ResultFormat != influxdb.ResultFormatTable {
+		t.Fatalf("unexpected settings %+v", us)
+	}
+	if us.CreatedAt.IsZero() || us.UpdatedAt.IsZero() {
+		t.Fatalf("expected timestamps to be set, got %+v", us.CRUDLog)
+	}
+}
+
+func TestService_PutInvalid(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		settings *influxdb.UserSettings
+	}{
+		{
+			name:     "missing user",
+			settings: &influxdb.UserSettings{Timezone: "UTC"},
+		},
+		{
+			name:     "unknown timezone",
+			settings: &influxdb.UserSettings{UserID: 5, Timezone: "Mars/Olympus_Mons"},
+		},
+		{
+			name:     "unknown result format",
+			settings: &influxdb.UserSettings{UserID: 5, ResultFormat: "xml"},
+		},
+		{
+			name:     "bucket without org",
+			settings: &influxdb.UserSettings{UserID: 5, DefaultBucketID: 10},
+		},
+		{
+			name:     "bucket of another org",
+			settings: &influxdb.UserSettings{UserID: 5, DefaultOrgID: 1, DefaultBucketID: 20},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := svc.PutUserSettings(ctx, tt.settings); influxdb.ErrorCode(err) != influxdb.EInvalid {
+				t.Fatalf("expected invalid error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestService_FindInvalidUser(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+
+	if _, err := svc.FindUserSettings(context.Background(), influxdb.InvalidID()); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid error finding the settings of an invalid user, got %v", err)
+	}
+}
@@ -50,6 +50,9 @@ type Variable struct {
 	Description    string             `json:"description"`
 	Selected       []string           `json:"selected"`
 	Arguments      *VariableArguments `json:"arguments"`
+	// Restricted variables are only accessible to their members, who can
+	// read them, and their owners, who can edit them, instead of to their whole organization.
+	Restricted bool `json:"restricted,omitempty"`
 	CRUDLog
 }
 
@@ -89,6 +92,7 @@ type VariableUpdate struct {
 	Selected    []string           `json:"selected"`
 	Description string             `json:"description"`
 	Arguments   *VariableArguments `json:"arguments"`
+	Restricted  *bool              `json:"restricted"`
 }
 
 // A VariableArguments contains arguments used when expanding a Variable
@@ -141,7 +145,7 @@ func (m *Variable) Valid() error {
 
 // Valid returns an error if a Variable changeset is not valid
 func (u *VariableUpdate) Valid() error {
-	if u.Name == "" && u.Description == "" && u.Selected == nil && u.Arguments == nil {
+	if u.Name == "" && u.Description == "" && u.Selected == nil && u.Arguments == nil && u.Restricted == nil {
 		return fmt.Errorf("no fields supplied in update")
 	}
 
@@ -165,6 +169,10 @@ func (u *VariableUpdate) Apply(m *Variable) {
 	if u.Description != "" {
 		m.Description = u.Description
 	}
+
+	if u.Restricted != nil {
+		m.Restricted = *u.Restricted
+	}
 }
 
 // UnmarshalJSON unmarshals json into a VariableArguments struct, using the `Type`
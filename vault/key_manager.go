@@ -0,0 +1,94 @@
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	platform "github.com/influxdata/influxdb/v2"
+)
+
+// DefaultTransitMount is the default path of the transit secrets engine.
+const DefaultTransitMount = "transit"
+
+var _ platform.KeyManager = (*KeyManager)(nil)
+
+// KeyManager encrypts and decrypts data with the keys of the transit secrets
+// engine of vault, so that organizations can bring their own keys.
+type KeyManager struct {
+	Client *api.Client
+	// Mount is the path the transit secrets engine is mounted at.
+	Mount string
+}
+
+// NewKeyManager creates an instance of a KeyManager of the keys of the
+// transit secrets engine mounted at mount. The client is configured as the
+// one of NewSecretService.
+func NewKeyManager(mount string, cfgOpts ...ConfigOptFn) (*KeyManager, error) {
+	c, err := newClient(cfgOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyManager{
+		Client: c,
+		Mount:  strings.Trim(mount, "/"),
+	}, nil
+}
+
+func (m *KeyManager) path(op, keyID string) string {
+	return fmt.Sprintf("/%s/%s/%s", m.Mount, op, url.PathEscape(keyID))
+}
+
+// Encrypt encrypts plaintext with the latest version of the key keyID. The
+// ciphertext is prefixed with the version of the key, as vault:v1:.
+func (m *KeyManager) Encrypt(ctx context.Context, keyID string, plaintext []byte) ([]byte, error) {
+	sec, err := m.Client.Logical().Write(m.path("encrypt", keyID), map[string]interface{}{
+		"plaintext": base64.StdEncoding.EncodeToString(plaintext),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := stringData(sec, "ciphertext")
+	if err != nil {
+		return nil, err
+	}
+	return []byte(ciphertext), nil
+}
+
+// Decrypt decrypts ciphertext with the version of the key keyID it was
+// encrypted with.
+func (m *KeyManager) Decrypt(ctx context.Context, keyID string, ciphertext []byte) ([]byte, error) {
+	sec, err := m.Client.Logical().Write(m.path("decrypt", keyID), map[string]interface{}{
+		"ciphertext": string(ciphertext),
+	})
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := stringData(sec, "plaintext")
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(plaintext)
+}
+
+// RotateKey creates a new version of the key keyID, which encrypts the data
+// from then on.
+func (m *KeyManager) RotateKey(ctx context.Context, keyID string) error {
+	_, err := m.Client.Logical().Write(fmt.Sprintf("/%s/keys/%s/rotate", m.Mount, url.PathEscape(keyID)), nil)
+	return err
+}
+
+func stringData(sec *api.Secret, key string) (string, error) {
+	if sec == nil {
+		return "", fmt.Errorf("no data returned by vault")
+	}
+	v, ok := sec.Data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("value found in %s is %T not a string", key, sec.Data[key])
+	}
+	return v, nil
+}
@@ -95,6 +95,19 @@ func WithTLSConfig(tlsCFG TLSConfig) ConfigOptFn {
 // The service is configured using the standard vault environment variables.
 // https://www.vaultproject.io/docs/commands/index.html#environment-variables
 func NewSecretService(cfgOpts ...ConfigOptFn) (*SecretService, error) {
+	c, err := newClient(cfgOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecretService{
+		Client: c,
+	}, nil
+}
+
+// newClient creates a vault client configured by the standard vault
+// environment variables, overridden by cfgOpts.
+func newClient(cfgOpts ...ConfigOptFn) (*api.Client, error) {
 	explicitConfig := Config{}
 	for _, o := range cfgOpts {
 		explicitConfig = o(explicitConfig)
@@ -119,9 +132,7 @@ func NewSecretService(cfgOpts ...ConfigOptFn) (*SecretService, error) {
 		c.SetToken(explicitConfig.Token)
 	}
 
-	return &SecretService{
-		Client: c,
-	}, nil
+	return c, nil
 }
 
 // LoadSecret retrieves the secret value v found at key k for organization orgID.
@@ -0,0 +1,55 @@
+package influxdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// WriteRouting routes the points written to an organization to buckets by
+// measurement. Writes opt into the routing with the route=measurement
+// parameter of the write API; points of measurements without a route are
+// written to the bucket of the request.
+type WriteRouting struct {
+	OrgID ID `json:"orgID"`
+	// Routes maps measurements to the IDs of the buckets their points are
+	// written to.
+	Routes map[string]ID `json:"routes"`
+	CRUDLog
+}
+
+// Valid returns an error if the routing is missing its organization or has
+// an invalid route.
+func (r *WriteRouting) Valid() error {
+	if !r.OrgID.Valid() {
+		return errors.New("orgID is required")
+	}
+	for m, id := range r.Routes {
+		if m == "" {
+			return errors.New("measurement of a route must not be empty")
+		}
+		if !id.Valid() {
+			return fmt.Errorf("bucket of measurement %q is invalid", m)
+		}
+	}
+	return nil
+}
+
+// BucketOf returns the bucket the points of measurement are routed to.
+func (r *WriteRouting) BucketOf(measurement string) (ID, bool) {
+	id, ok := r.Routes[measurement]
+	return id, ok
+}
+
+// WriteRoutingService stores the write routing of organizations.
+type WriteRoutingService interface {
+	// FindWriteRouting returns the routing of the organization. An
+	// organization without routing has an empty one.
+	FindWriteRouting(ctx context.Context, orgID ID) (*WriteRouting, error)
+
+	// PutWriteRouting replaces the routing of the organization.
+	PutWriteRouting(ctx context.Context, r *WriteRouting) error
+
+	// DeleteWriteRouting removes the routing of the organization.
+	DeleteWriteRouting(ctx context.Context, orgID ID) error
+}
@@ -0,0 +1,40 @@
+package writerouting
+
+import (
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrInvalidOrgID is used when the ID of the organization cannot be encoded.
+	ErrInvalidOrgID = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "organization ID is invalid",
+	}
+)
+
+// ErrInvalidRouting is used when a service was provided an invalid routing.
+func ErrInvalidRouting(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "write routing provided is invalid",
+		Err:  err,
+	}
+}
+
+// ErrRouteBucket is used when a route refers to a bucket that is not in the
+// organization of the routing.
+func ErrRouteBucket(measurement string, err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "bucket of measurement " + measurement + " is not a bucket of the organization",
+		Err:  err,
+	}
+}
+
+// ErrInternalService is used when the error comes from an internal system.
+func ErrInternalService(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
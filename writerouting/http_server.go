@@ -0,0 +1,146 @@
+package writerouting
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixWriteRouting is the prefix of the write routing API.
+	PrefixWriteRouting = "/api/v2/writeRouting"
+)
+
+// Handler is the HTTP API handler for the write routing of organizations.
+type Handler struct {
+	chi.Router
+	api        *kithttp.API
+	log        *zap.Logger
+	routingSvc influxdb.WriteRoutingService
+}
+
+// NewHTTPHandler constructs a new http server.
+func NewHTTPHandler(log *zap.Logger, routingSvc influxdb.WriteRoutingService) *Handler {
+	h := &Handler{
+		api:        kithttp.NewAPI(kithttp.WithLog(log)),
+		log:        log,
+		routingSvc: routingSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Get("/", h.handleGetRouting)
+		r.Put("/", h.handlePutRouting)
+		r.Delete("/", h.handleDeleteRouting)
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the prefix the handler is mounted under.
+func (h *Handler) Prefix() string {
+	return PrefixWriteRouting
+}
+
+type routingResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.WriteRouting
+}
+
+func newRoutingResponse(r *influxdb.WriteRouting) *routingResponse {
+	return &routingResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("%s?orgID=%s", PrefixWriteRouting, r.OrgID),
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", r.OrgID),
+		},
+		WriteRouting: r,
+	}
+}
+
+type putRoutingRequest struct {
+	Routes map[string]influxdb.ID `json:"routes"`
+}
+
+func decodeOrgID(r *http.Request) (influxdb.ID, error) {
+	v := r.URL.Query().Get("orgID")
+	if v == "" {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID is required",
+		}
+	}
+	id, err := influxdb.IDFromString(v)
+	if err != nil {
+		return 0, influxdb.ErrCorruptID(err)
+	}
+	return *id, nil
+}
+
+func (h *Handler) handleGetRouting(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	routing, err := h.routingSvc.FindWriteRouting(r.Context(), orgID)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newRoutingResponse(routing))
+}
+
+func (h *Handler) handlePutRouting(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var req putRoutingRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	routing := &influxdb.WriteRouting{OrgID: orgID, Routes: req.Routes}
+	if routing.Routes == nil {
+		routing.Routes = map[string]influxdb.ID{}
+	}
+	if err := h.routingSvc.PutWriteRouting(r.Context(), routing); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Write routing updated", zap.String("routing", fmt.Sprint(routing)))
+
+	h.api.Respond(w, r, http.StatusOK, newRoutingResponse(routing))
+}
+
+func (h *Handler) handleDeleteRouting(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.routingSvc.DeleteWriteRouting(r.Context(), orgID); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Write routing deleted", zap.String("orgID", orgID.String()))
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
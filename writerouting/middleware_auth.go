@@ -0,0 +1,42 @@
+package writerouting
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+var _ influxdb.WriteRoutingService = (*AuthedService)(nil)
+
+// AuthedService requires read access to an organization to see its routing
+// and write access to change it.
+type AuthedService struct {
+	s influxdb.WriteRoutingService
+}
+
+// NewAuthedService wraps s with organization authorization.
+func NewAuthedService(s influxdb.WriteRoutingService) *AuthedService {
+	return &AuthedService{s: s}
+}
+
+func (s *AuthedService) FindWriteRouting(ctx context.Context, orgID influxdb.ID) (*influxdb.WriteRouting, error) {
+	if _, _, err := authorizer.AuthorizeReadOrg(ctx, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindWriteRouting(ctx, orgID)
+}
+
+func (s *AuthedService) PutWriteRouting(ctx context.Context, r *influxdb.WriteRouting) error {
+	if _, _, err := authorizer.AuthorizeWriteOrg(ctx, r.OrgID); err != nil {
+		return err
+	}
+	return s.s.PutWriteRouting(ctx, r)
+}
+
+func (s *AuthedService) DeleteWriteRouting(ctx context.Context, orgID influxdb.ID) error {
+	if _, _, err := authorizer.AuthorizeWriteOrg(ctx, orgID); err != nil {
+		return err
+	}
+	return s.s.DeleteWriteRouting(ctx, orgID)
+}
@@ -0,0 +1,147 @@
+// Package writerouting implements the routing of the points written to an
+// organization to buckets by measurement, so a single writer can feed buckets
+// with different retention policies.
+package writerouting
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+var routingBucket = []byte("writeroutingsv1")
+
+var _ influxdb.WriteRoutingService = (*Service)(nil)
+
+// Service stores the write routing of organizations in a kv store.
+type Service struct {
+	store         kv.Store
+	bucketSvc     influxdb.BucketService
+	TimeGenerator influxdb.TimeGenerator
+}
+
+// NewService constructs a write routing service backed by st. The buckets
+// of the routes are looked up in bucketSvc.
+func NewService(st kv.Store, bucketSvc influxdb.BucketService) *Service {
+	return &Service{
+		store:         st,
+		bucketSvc:     bucketSvc,
+		TimeGenerator: influxdb.RealTimeGenerator{},
+	}
+}
+
+// FindWriteRouting returns the routing of the organization.
+func (s *Service) FindWriteRouting(ctx context.Context, orgID influxdb.ID) (*influxdb.WriteRouting, error) {
+	var r *influxdb.WriteRouting
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		r, err = s.getRouting(tx, orgID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// PutWriteRouting replaces the routing of the organization. Every route must
+// refer to a bucket of the organization.
+func (s *Service) PutWriteRouting(ctx context.Context, r *influxdb.WriteRouting) error {
+	if err := r.Valid(); err != nil {
+		return ErrInvalidRouting(err)
+	}
+	for m, id := range r.Routes {
+		id := id
+		if _, err := s.bucketSvc.FindBucket(ctx, influxdb.BucketFilter{ID: &id, OrganizationID: &r.OrgID}); err != nil {
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				return ErrRouteBucket(m, err)
+			}
+			return err
+		}
+	}
+
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		existing, err := s.getRouting(tx, r.OrgID)
+		if err != nil {
+			return err
+		}
+
+		now := s.TimeGenerator.Now()
+		r.CreatedAt = existing.CreatedAt
+		if r.CreatedAt.IsZero() {
+			r.CreatedAt = now
+		}
+		r.UpdatedAt = now
+		return s.putRouting(tx, r)
+	})
+}
+
+// DeleteWriteRouting removes the routing of the organization.
+func (s *Service) DeleteWriteRouting(ctx context.Context, orgID influxdb.ID) error {
+	key, err := orgID.Encode()
+	if err != nil {
+		return ErrInvalidOrgID
+	}
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket(routingBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		if err := b.Delete(key); err != nil && !kv.IsNotFound(err) {
+			return ErrInternalService(err)
+		}
+		return nil
+	})
+}
+
+// getRouting returns the routing of the organization, or an empty routing if
+// it has none.
+func (s *Service) getRouting(tx kv.Tx, orgID influxdb.ID) (*influxdb.WriteRouting, error) {
+	key, err := orgID.Encode()
+	if err != nil {
+		return nil, ErrInvalidOrgID
+	}
+
+	b, err := tx.Bucket(routingBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(key)
+	if kv.IsNotFound(err) {
+		return &influxdb.WriteRouting{OrgID: orgID, Routes: map[string]influxdb.ID{}}, nil
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+
+	r := &influxdb.WriteRouting{}
+	if err := json.Unmarshal(v, r); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	if r.Routes == nil {
+		r.Routes = map[string]influxdb.ID{}
+	}
+	return r, nil
+}
+
+func (s *Service) putRouting(tx kv.Tx, r *influxdb.WriteRouting) error {
+	key, err := r.OrgID.Encode()
+	if err != nil {
+		return ErrInvalidOrgID
+	}
+	v, err := json.Marshal(r)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+
+	b, err := tx.Bucket(routingBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(key, v); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package writerouting_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/influxdata/influxdb/v2/writerouting"
+)
+
+func newTestService(t *testing.T) (*writerouting.Service, func()) {
+	t.Helper()
+
+	s, done := influxdbtesting.NewTestBoltStore(t)
+	// Only buckets 10 and 11 belong to organization 1.
+	buckets := influxdbtesting.NewBucketFinder(
+		&influxdb.Bucket{ID: 10, OrgID: 1},
+		&influxdb.Bucket{ID: 11, OrgID: 1},
+	)
+
+	svc := writerouting.NewService(s, buckets)
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)}
+	return svc, done
+}
+
+func TestService_PutAndFind(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	r, err := svc.FindWriteRouting(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.OrgID != 1 || len(r.Routes) != 0 {
+		t.Fatalf("expected empty routing of org 1, got %+v", r)
+	}
+
+	put := &influxdb.WriteRouting{OrgID: 1, Routes: map[string]influxdb.ID{"cpu": 10, "mem": 11}}
+	if err := svc.PutWriteRouting(ctx, put); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = svc.FindWriteRouting(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := r.BucketOf("cpu"); !ok || id != 10 {
+		t.Fatalf("expected cpu to be routed to bucket 10, got %v %v", id, ok)
+	}
+	if _, ok := r.BucketOf("disk"); ok {
+		t.Fatal("expected disk not to be routed")
+	}
+	if r.CreatedAt.IsZero() || r.UpdatedAt.IsZero() {
+		t.Fatalf("expected timestamps to be set, got %+v", r.CRUDLog)
+	}
+
+	if err := svc.DeleteWriteRouting(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	r, err = svc.FindWriteRouting(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Routes) != 0 {
+		t.Fatalf("expected routing to be deleted, got %+v", r.Routes)
+	}
+}
+
+func TestService_PutInvalid(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		routing *influxdb.WriteRouting
+	}{
+		{
+			name:    "missing org",
+			routing: &influxdb.WriteRouting{Routes: map[string]influxdb.ID{"cpu": 10}},
+		},
+		{
+			name:    "empty measurement",
+			routing: &influxdb.WriteRouting{OrgID: 1, Routes: map[string]influxdb.ID{"": 10}},
+		},
+		{
+			name:    "bucket of another org",
+			routing: &influxdb.WriteRouting{OrgID: 1, Routes: map[string]influxdb.ID{"cpu": 20}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := svc.PutWriteRouting(ctx, tt.routing); influxdb.ErrorCode(err) != influxdb.EInvalid {
+				t.Fatalf("expected invalid error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestService_InvalidOrg(t *testing.T) {
+	svc, done := newTestService(t)
+	defer done()
+	ctx := context.Background()
+
+	if _, err := svc.FindWriteRouting(ctx, influxdb.InvalidID()); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid error finding the routing of an invalid org, got %v", err)
+	}
+	if err := svc.DeleteWriteRouting(ctx, influxdb.InvalidID()); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid error deleting the routing of an invalid org, got %v", err)
+	}
+}
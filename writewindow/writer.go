@@ -0,0 +1,90 @@
+// Package writewindow enforces the write windows of buckets. The points whose
+// timestamps are outside the write window of their bucket are rejected,
+// whichever path they take to the storage engine: the API, tasks, ingestion
+// or replication.
+package writewindow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// msgPointsOutsideWriteWindow is the category of the errors of writes
+// rejected because of timestamps outside the write window of a bucket.
+const msgPointsOutsideWriteWindow = "points outside write window"
+
+// BucketFinder finds the buckets whose write windows are enforced.
+type BucketFinder interface {
+	FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error)
+}
+
+var _ storage.PointsWriter = (*PointsWriter)(nil)
+
+// PointsWriter refuses the writes of points outside the write windows of
+// their buckets.
+type PointsWriter struct {
+	next    storage.PointsWriter
+	buckets BucketFinder
+	now     func() time.Time
+}
+
+// NewPointsWriter wraps next so that the points outside the write windows of
+// their buckets are not written.
+func NewPointsWriter(next storage.PointsWriter, buckets BucketFinder) *PointsWriter {
+	return &PointsWriter{
+		next:    next,
+		buckets: buckets,
+		now:     time.Now,
+	}
+}
+
+// WritePoints writes the points unless any of them is outside the write
+// window of its bucket, in which case none of them are written.
+func (w *PointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	var (
+		now      = w.now()
+		buckets  = make(map[string]*influxdb.Bucket)
+		rejected int
+		first    models.Point
+		firstOf  *influxdb.Bucket
+	)
+	for _, p := range points {
+		b, ok := buckets[string(p.Name())]
+		if !ok {
+			_, bucketID := tsdb.DecodeNameSlice(p.Name())
+			var err error
+			if b, err = w.buckets.FindBucketByID(ctx, bucketID); err != nil {
+				return err
+			}
+			buckets[string(p.Name())] = b
+		}
+		if min, max := b.WriteWindow(now); p.UnixNano() < min || p.UnixNano() > max {
+			if rejected == 0 {
+				first, firstOf = p, b
+			}
+			rejected++
+		}
+	}
+	if rejected > 0 {
+		return &influxdb.Error{
+			Code: influxdb.EUnprocessableEntity,
+			Msg: fmt.Sprintf("%s: %d points rejected, first at %s is outside the write window of bucket %q (past %s, future %s)",
+				msgPointsOutsideWriteWindow, rejected, first.Time().UTC().Format(time.RFC3339Nano), firstOf.Name,
+				formatWriteWindow(firstOf.WritePastWindow), formatWriteWindow(firstOf.WriteFutureWindow)),
+		}
+	}
+	return w.next.WritePoints(ctx, points)
+}
+
+func formatWriteWindow(d time.Duration) string {
+	if d <= 0 {
+		return "unlimited"
+	}
+	return d.String()
+}
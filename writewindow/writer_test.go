@@ -0,0 +1,70 @@
+package writewindow
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+const (
+	orgID       influxdb.ID = 1
+	windowedID  influxdb.ID = 2
+	unlimitedID influxdb.ID = 3
+)
+
+func newBucketService() *mock.BucketService {
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		switch id {
+		case windowedID:
+			return &influxdb.Bucket{ID: id, OrgID: orgID, Name: "windowed", WritePastWindow: time.Hour, WriteFutureWindow: time.Minute}, nil
+		case unlimitedID:
+			return &influxdb.Bucket{ID: id, OrgID: orgID, Name: "unlimited"}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+	}
+	return buckets
+}
+
+func point(bucketID influxdb.ID, t time.Time) models.Point {
+	return models.MustNewPoint(tsdb.EncodeNameString(orgID, bucketID), nil, models.Fields{"f": 1.0}, t)
+}
+
+func TestPointsWriter(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	next := &mock.PointsWriter{}
+	w := NewPointsWriter(next, newBucketService())
+	w.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	inside := []models.Point{
+		point(windowedID, now.Add(-30*time.Minute)),
+		point(windowedID, now.Add(30*time.Second)),
+		point(unlimitedID, now.Add(-24*time.Hour)),
+	}
+	if err := w.WritePoints(ctx, inside); err != nil {
+		t.Fatalf("unexpected error writing points inside the write windows: %v", err)
+	}
+
+	outside := []models.Point{
+		point(windowedID, now),
+		point(windowedID, now.Add(-2*time.Hour)),
+		point(windowedID, now.Add(time.Hour)),
+	}
+	err := w.WritePoints(ctx, outside)
+	if influxdb.ErrorCode(err) != influxdb.EUnprocessableEntity {
+		t.Fatalf("expected an unprocessable entity error writing points outside the write window, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "2 points rejected") {
+		t.Errorf("expected the rejected points to be counted, got %v", err)
+	}
+	if len(next.Points) != len(inside) {
+		t.Errorf("expected only the points inside the write windows written, got %d points", len(next.Points))
+	}
+}